@@ -0,0 +1,83 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/siglens/siglens/pkg/config"
+	"github.com/siglens/siglens/pkg/segment/inspect"
+	"github.com/siglens/siglens/pkg/segment/writer"
+	log "github.com/sirupsen/logrus"
+)
+
+/*
+
+	Dumps the metadata of the rotated segments of a siglens data dir as json, run it from the working dir of the
+	server with its config, for example: seginspect --config server.yaml --index my-index --blocks
+
+*/
+
+func main() {
+	segKey := flag.String("segkey", "", "Key of the segment to inspect")
+	indexName := flag.String("index", "", "Inspect all the segments of this index")
+	orgid := flag.Uint64("orgid", 0, "Org of the segments")
+	withBlocks := flag.Bool("blocks", false, "Also dump the time range and size of every block")
+	limit := flag.Int("limit", 0, "Inspect only this many of the largest segments of the index, 0 inspects all of them")
+
+	log.SetLevel(log.WarnLevel)
+	err := config.InitConfigurationData()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "seginspect: failed to read the config, err=%v\n", err)
+		os.Exit(1)
+	}
+	if *segKey == "" && *indexName == "" {
+		fmt.Fprintln(os.Stderr, "seginspect: --segkey or --index is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	segMetas := inspect.FindSegments(writer.ReadAllSegmetas(), *segKey, *indexName, *orgid)
+	if len(segMetas) == 0 {
+		fmt.Fprintf(os.Stderr, "seginspect: no rotated segment found in %v\n", config.GetIngestNodeBaseDir())
+		os.Exit(1)
+	}
+
+	response := &inspect.SegmentInspectResponse{TotalSegments: len(segMetas)}
+	if *limit > 0 && len(segMetas) > *limit {
+		segMetas = segMetas[:*limit]
+	}
+	response.Segments = make([]*inspect.SegmentInspection, 0, len(segMetas))
+	for _, segMeta := range segMetas {
+		inspection, err := inspect.InspectSegment(segMeta, *withBlocks)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "seginspect: failed to inspect segment %v, err=%v\n", segMeta.SegmentKey, err)
+			os.Exit(1)
+		}
+		response.Segments = append(response.Segments, inspection)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(response); err != nil {
+		fmt.Fprintf(os.Stderr, "seginspect: failed to write the response, err=%v\n", err)
+		os.Exit(1)
+	}
+}
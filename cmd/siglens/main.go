@@ -114,18 +114,26 @@ func main() {
 	}
 
 	ch := make(chan os.Signal, 1)
-	signal.Notify(ch, os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
-
-	switch <-ch {
-	case os.Interrupt, os.Kill, syscall.SIGTERM, syscall.SIGINT:
-		log.Errorf("Interrupt signal received. Exiting server...")
-		startup.ShutdownSiglensServer()
-		log.Errorf("Server shutdown")
-		os.Exit(0)
-	default:
-		log.Errorf("Something went wrong. Exiting server...")
-		startup.ShutdownSiglensServer()
-		log.Errorf("Server shutdown")
-		os.Exit(1)
+	signal.Notify(ch, os.Interrupt, syscall.SIGTERM, syscall.SIGINT, syscall.SIGHUP)
+
+	for {
+		switch <-ch {
+		case syscall.SIGHUP:
+			log.Infof("SIGHUP received. Reloading the config...")
+			_, err := config.ReloadConfig()
+			if err != nil {
+				log.Errorf("siglens main: failed to reload the config, keeping the running one, err=%v", err)
+			}
+		case os.Interrupt, os.Kill, syscall.SIGTERM, syscall.SIGINT:
+			log.Errorf("Interrupt signal received. Exiting server...")
+			startup.ShutdownSiglensServer()
+			log.Errorf("Server shutdown")
+			os.Exit(0)
+		default:
+			log.Errorf("Something went wrong. Exiting server...")
+			startup.ShutdownSiglensServer()
+			log.Errorf("Server shutdown")
+			os.Exit(1)
+		}
 	}
 }
@@ -23,11 +23,16 @@ import (
 	"os"
 
 	"github.com/siglens/siglens/pkg/alerts/alertsHandler"
+	"github.com/siglens/siglens/pkg/apikeys"
 	"github.com/siglens/siglens/pkg/blob"
 	local "github.com/siglens/siglens/pkg/blob/local"
+	"github.com/siglens/siglens/pkg/cluster"
 	"github.com/siglens/siglens/pkg/config"
 	"github.com/siglens/siglens/pkg/dashboards"
+	"github.com/siglens/siglens/pkg/health"
 	"github.com/siglens/siglens/pkg/instrumentation"
+	"github.com/siglens/siglens/pkg/integrity"
+	"github.com/siglens/siglens/pkg/otlp"
 	"github.com/siglens/siglens/pkg/querytracker"
 	"github.com/siglens/siglens/pkg/retention"
 	"github.com/siglens/siglens/pkg/scroll"
@@ -35,6 +40,7 @@ import (
 	tracinghandler "github.com/siglens/siglens/pkg/segment/tracing/handler"
 	"github.com/siglens/siglens/pkg/segment/writer"
 	"github.com/siglens/siglens/pkg/segment/writer/metrics"
+	"github.com/siglens/siglens/pkg/segment/writer/metrics/rollup"
 	ingestserver "github.com/siglens/siglens/pkg/server/ingest"
 	queryserver "github.com/siglens/siglens/pkg/server/query"
 	"github.com/siglens/siglens/pkg/ssa"
@@ -74,12 +80,14 @@ func StartSiglensServer(nodeType config.DeploymentType, nodeID string) error {
 	}
 
 	usageStats.StartUsageStats()
+	usageStats.SetStoredBytesFunc(health.GetStoredBytes)
 	ingestNode := config.IsIngestNode()
 	queryNode := config.IsQueryNode()
 	ingestServer := "0.0.0.0:" + fmt.Sprintf("%d", config.GetIngestPort())
 	queryServer := "0.0.0.0:" + fmt.Sprintf("%d", config.GetQueryPort())
 
-	if config.IsTlsEnabled() && config.GetQueryPort() != 443 {
+	// the acme challenges are answered on port 443, the certificate files can be served on any port
+	if config.IsTlsAcmeEnabled() && config.GetQueryPort() != 443 {
 		fmt.Printf("Error starting Query/UI server with TLS, QueryPort should be set to 443 ")
 		log.Errorf("Error starting Query/UI server with TLS, QueryPort should be set to 443 ")
 		return errors.New("error starting Query/UI server with TLS, QueryPort should be set to 443 ")
@@ -109,11 +117,27 @@ func StartSiglensServer(nodeType config.DeploymentType, nodeID string) error {
 		log.Errorf("error in init retention cleaner: %v", err)
 		return err
 	}
+	err = integrity.InitIntegrityChecker()
+	if err != nil {
+		log.Errorf("error in init integrity checker: %v", err)
+		return err
+	}
+	rollup.InitMetricsDownsampler()
 	err = dashboards.InitDashboards()
 	if err != nil {
 		log.Errorf("error in init Dashboards: %v", err)
 		return err
 	}
+	err = apikeys.InitApiKeys()
+	if err != nil {
+		log.Errorf("error in init ApiKeys: %v", err)
+		return err
+	}
+	err = cluster.InitCluster()
+	if err != nil {
+		log.Errorf("error in init Cluster: %v", err)
+		return err
+	}
 
 	siglensStartupLog := fmt.Sprintf("----- Siglens server type %s starting up ----- \n", nodeType)
 	if config.GetLogPrefix() != "" {
@@ -148,11 +172,14 @@ func StartSiglensServer(nodeType config.DeploymentType, nodeID string) error {
 	alertsHandler.InitMinionSearchService()
 	go tracinghandler.MonitorSpansHealth()
 	go tracinghandler.DependencyGraphThread()
+	go tracinghandler.RedMetricsFlushThread()
 
 	return nil
 }
 
 func ShutdownSiglensServer() {
+	// write the buffered spans of the traces kept by sampling
+	otlp.FlushTailSampler()
 	// force write unsaved data to segfile and flush bloom, range, updates to meta
 	writer.ForcedFlushToSegfile()
 	metrics.ForceFlushMetricsBlock()
@@ -0,0 +1,137 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alertsHandler
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/siglens/siglens/pkg/alerts/alertutils"
+	"github.com/siglens/siglens/pkg/utils"
+	log "github.com/sirupsen/logrus"
+	"github.com/valyala/fasthttp"
+)
+
+const DEFAULT_ALERT_HISTORY_LIMIT = 1000
+const MAX_ALERT_HISTORY_LIMIT = 10_000
+
+/*
+Handles /api/alerts/history. All the query parameters are optional: alertId, event, startEpoch and endEpoch in ms,
+and limit. The latest entries are returned first
+*/
+func ProcessGetAlertHistoryRequest(ctx *fasthttp.RequestCtx) {
+	responseBody := make(map[string]interface{})
+	if databaseObj == nil {
+		log.Errorf("ProcessGetAlertHistoryRequest: failed to get alert history, err = %+v", invalidDatabaseProvider)
+		ctx.SetStatusCode(fasthttp.StatusBadRequest)
+		responseBody["error"] = invalidDatabaseProvider
+		utils.WriteJsonResponse(ctx, responseBody)
+		return
+	}
+
+	historyQuery, err := parseAlertHistoryQuery(ctx.QueryArgs(), utils.GetCurrentTimeInMs())
+	if err != nil {
+		log.Errorf("ProcessGetAlertHistoryRequest: invalid query parameters, err = %+v", err)
+		ctx.SetStatusCode(fasthttp.StatusBadRequest)
+		responseBody["error"] = err.Error()
+		utils.WriteJsonResponse(ctx, responseBody)
+		return
+	}
+	history, err := databaseObj.GetAlertHistory(historyQuery)
+	if err != nil {
+		log.Errorf("ProcessGetAlertHistoryRequest: could not get alert history, err = %+v", err)
+		ctx.SetStatusCode(fasthttp.StatusBadRequest)
+		responseBody["error"] = err.Error()
+		utils.WriteJsonResponse(ctx, responseBody)
+		return
+	}
+
+	responseBody["history"] = history
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	utils.WriteJsonResponse(ctx, responseBody)
+}
+
+func parseAlertHistoryQuery(args *fasthttp.Args, nowTs uint64) (*alertutils.AlertHistoryQuery, error) {
+	historyQuery := &alertutils.AlertHistoryQuery{
+		AlertId:  string(args.Peek("alertId")),
+		Event:    alertutils.AlertHistoryEvent(args.Peek("event")),
+		EndEpoch: nowTs,
+		Limit:    DEFAULT_ALERT_HISTORY_LIMIT,
+	}
+	var err error
+	if startStr := string(args.Peek("startEpoch")); startStr != "" {
+		historyQuery.StartEpoch, err = strconv.ParseUint(startStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid startEpoch %v", startStr)
+		}
+	}
+	if endStr := string(args.Peek("endEpoch")); endStr != "" {
+		historyQuery.EndEpoch, err = strconv.ParseUint(endStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid endEpoch %v", endStr)
+		}
+	}
+	if historyQuery.StartEpoch > historyQuery.EndEpoch {
+		return nil, fmt.Errorf("startEpoch %v is after endEpoch %v", historyQuery.StartEpoch, historyQuery.EndEpoch)
+	}
+	if limitStr := string(args.Peek("limit")); limitStr != "" {
+		historyQuery.Limit, err = strconv.ParseUint(limitStr, 10, 64)
+		if err != nil || historyQuery.Limit == 0 {
+			return nil, fmt.Errorf("invalid limit %v", limitStr)
+		}
+		if historyQuery.Limit > MAX_ALERT_HISTORY_LIMIT {
+			historyQuery.Limit = MAX_ALERT_HISTORY_LIMIT
+		}
+	}
+	switch historyQuery.Event {
+	case "", alertutils.EventPending, alertutils.EventFiring, alertutils.EventResolved, alertutils.EventInactive,
+		alertutils.EventNotificationSent, alertutils.EventNotificationFailed:
+	default:
+		return nil, fmt.Errorf("invalid event %v", historyQuery.Event)
+	}
+	return historyQuery, nil
+}
+
+// Returns the history event of a change of state
+func getTransitionEvent(oldState alertutils.AlertState, newState alertutils.AlertState) alertutils.AlertHistoryEvent {
+	switch newState {
+	case alertutils.Pending:
+		return alertutils.EventPending
+	case alertutils.Firing:
+		return alertutils.EventFiring
+	default:
+		if oldState == alertutils.Firing {
+			return alertutils.EventResolved
+		}
+		return alertutils.EventInactive
+	}
+}
+
+// The history is best effort, failing to record it does not fail the evaluation or the notification
+func recordAlertHistory(alertId string, event alertutils.AlertHistoryEvent, value float64, details string) {
+	entry := &alertutils.AlertHistoryEntry{
+		AlertId:   alertId,
+		Event:     event,
+		Value:     value,
+		Details:   details,
+		Timestamp: utils.GetCurrentTimeInMs(),
+	}
+	err := databaseObj.AddAlertHistory(entry)
+	if err != nil {
+		log.Errorf("recordAlertHistory: could not add %v to the history of alert id- %s, err=%v", event, alertId, err)
+	}
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alertsHandler
+
+import (
+	"testing"
+
+	"github.com/siglens/siglens/pkg/alerts/alertutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func Test_parseAlertHistoryQuery(t *testing.T) {
+	args := &fasthttp.Args{}
+	args.Parse("")
+	historyQuery, err := parseAlertHistoryQuery(args, 5000)
+	assert.Nil(t, err)
+	assert.Equal(t, &alertutils.AlertHistoryQuery{EndEpoch: 5000, Limit: DEFAULT_ALERT_HISTORY_LIMIT}, historyQuery)
+
+	args.Parse("alertId=a1&event=firing&startEpoch=100&endEpoch=200&limit=50000")
+	historyQuery, err = parseAlertHistoryQuery(args, 5000)
+	assert.Nil(t, err)
+	assert.Equal(t, &alertutils.AlertHistoryQuery{AlertId: "a1", Event: alertutils.EventFiring, StartEpoch: 100,
+		EndEpoch: 200, Limit: MAX_ALERT_HISTORY_LIMIT}, historyQuery)
+
+	for _, query := range []string{"startEpoch=abc", "startEpoch=300&endEpoch=200", "limit=0", "event=unknown"} {
+		args.Parse(query)
+		_, err = parseAlertHistoryQuery(args, 5000)
+		assert.NotNil(t, err, query)
+	}
+}
+
+func Test_getTransitionEvent(t *testing.T) {
+	assert.Equal(t, alertutils.EventPending, getTransitionEvent(alertutils.Inactive, alertutils.Pending))
+	assert.Equal(t, alertutils.EventFiring, getTransitionEvent(alertutils.Pending, alertutils.Firing))
+	assert.Equal(t, alertutils.EventFiring, getTransitionEvent(alertutils.Inactive, alertutils.Firing))
+	assert.Equal(t, alertutils.EventResolved, getTransitionEvent(alertutils.Firing, alertutils.Inactive))
+	assert.Equal(t, alertutils.EventInactive, getTransitionEvent(alertutils.Pending, alertutils.Inactive))
+}
@@ -53,11 +53,12 @@ type database interface {
 	GetAllContactPoints() ([]alertutils.Contact, error)
 	UpdateContactPoint(contact *alertutils.Contact) error
 	GetCoolDownDetails(alert_id string) (uint64, time.Time, error)
-	GetContactDetails(alert_id string) (string, string, string, error)
-	GetEmailAndChannelID(contact_id string) ([]string, []alertutils.SlackTokenConfig, []string, error)
+	GetContactPoint(contact_id string) (*alertutils.Contact, error)
 	UpdateLastSentTime(alert_id string) error
-	UpdateAlertStateByAlertID(alertId string, alertState alertutils.AlertState) error
+	UpdateAlertStateByAlertID(alertId string, alertState alertutils.AlertState, pendingSince time.Time) error
 	DeleteContactPoint(contact_id string) error
+	AddAlertHistory(entry *alertutils.AlertHistoryEntry) error
+	GetAlertHistory(historyQuery *alertutils.AlertHistoryQuery) ([]alertutils.AlertHistoryEntry, error)
 }
 
 var databaseObj database
@@ -0,0 +1,89 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alertsHandler
+
+import (
+	"math"
+
+	"github.com/siglens/siglens/pkg/alerts/alertutils"
+	"github.com/siglens/siglens/pkg/ast/pipesearch"
+	log "github.com/sirupsen/logrus"
+)
+
+const DEFAULT_BASELINE_DAYS = 7
+const MAX_BASELINE_DAYS = 30
+const DEFAULT_ANOMALY_SIGMAS = 3
+
+// Fewer baseline values than this say too little about the usual value to call anything an anomaly
+const MIN_BASELINE_VALUES = 2
+
+const DAY_IN_MS = uint64(24 * 60 * 60 * 1000)
+
+/*
+Returns the values of the alert query over the same window of each of the past baseline days, e.g. the same hour
+of the day for a query over the last hour. The days whose query fails are skipped
+*/
+func getBaselineValues(alert *alertutils.AlertDetails, nowTs uint64) []float64 {
+	baselineDays := alert.BaselineDays
+	if baselineDays == 0 {
+		baselineDays = DEFAULT_BASELINE_DAYS
+	} else if baselineDays > MAX_BASELINE_DAYS {
+		baselineDays = MAX_BASELINE_DAYS
+	}
+
+	values := make([]float64, 0, baselineDays)
+	for day := uint64(1); day <= baselineDays; day++ {
+		if day*DAY_IN_MS > nowTs {
+			break
+		}
+		value, err := pipesearch.ProcessAlertsPipeSearchRequest(alert.QueryParams, alert.ValueType, nowTs-day*DAY_IN_MS)
+		if err != nil {
+			log.Errorf("ALERTSERVICE: getBaselineValues: could not evaluate alert=%v %v days ago, err=%v", alert.AlertInfo.AlertName, day, err)
+			continue
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+// Returns true if the value is at least sigmas standard deviations away from the mean of the baseline values
+func isAnomalous(value float64, baseline []float64, sigmas float32) bool {
+	if len(baseline) < MIN_BASELINE_VALUES {
+		return false
+	}
+	if sigmas <= 0 {
+		sigmas = DEFAULT_ANOMALY_SIGMAS
+	}
+
+	mean := 0.0
+	for _, baselineValue := range baseline {
+		mean += baselineValue
+	}
+	mean /= float64(len(baseline))
+	variance := 0.0
+	for _, baselineValue := range baseline {
+		variance += (baselineValue - mean) * (baselineValue - mean)
+	}
+	stdDev := math.Sqrt(variance / float64(len(baseline)))
+
+	deviation := math.Abs(value - mean)
+	// a baseline that never changed makes any change an anomaly
+	if stdDev == 0 {
+		return deviation > 0
+	}
+	return deviation >= float64(sigmas)*stdDev
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alertsHandler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_isAnomalous(t *testing.T) {
+	// mean 100, standard deviation 10
+	baseline := []float64{90, 110, 90, 110}
+
+	assert.False(t, isAnomalous(100, baseline, 3))
+	assert.False(t, isAnomalous(129, baseline, 3))
+	assert.True(t, isAnomalous(130, baseline, 3))
+	assert.True(t, isAnomalous(70, baseline, 3))
+	assert.True(t, isAnomalous(121, baseline, 2))
+
+	// the default is 3 sigma
+	assert.False(t, isAnomalous(129, baseline, 0))
+	assert.True(t, isAnomalous(131, baseline, 0))
+
+	assert.False(t, isAnomalous(50, []float64{50, 50, 50}, 3))
+	assert.True(t, isAnomalous(51, []float64{50, 50, 50}, 3))
+
+	// not enough history
+	assert.False(t, isAnomalous(1000, []float64{10}, 3))
+	assert.False(t, isAnomalous(1000, nil, 3))
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/go-co-op/gocron"
 	"github.com/siglens/siglens/pkg/alerts/alertutils"
 	"github.com/siglens/siglens/pkg/ast/pipesearch"
+	"github.com/siglens/siglens/pkg/utils"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -65,47 +66,76 @@ func RemoveCronJob(alertId string) error {
 }
 
 func evaluate(alertToEvaluate *alertutils.AlertDetails, job gocron.Job) {
-	serResVal := pipesearch.ProcessAlertsPipeSearchRequest(alertToEvaluate.QueryParams)
-	if serResVal == -1 {
-		log.Errorf("ALERTSERVICE: evaluate: Empty response returned by server.")
+	nowTs := utils.GetCurrentTimeInMs()
+	serResVal, err := pipesearch.ProcessAlertsPipeSearchRequest(alertToEvaluate.QueryParams, alertToEvaluate.ValueType, nowTs)
+	if err != nil {
+		log.Errorf("ALERTSERVICE: evaluate: could not evaluate the query of alert=%+v, err=%+v.", alertToEvaluate.AlertInfo.AlertName, err)
 		return
 	}
-	isFiring := evaluateConditions(serResVal, &alertToEvaluate.Condition, alertToEvaluate.Value)
-	if isFiring {
-		err := updateAlertState(alertToEvaluate.AlertInfo.AlertId, alertutils.Firing)
-		if err != nil {
-			log.Errorf("ALERTSERVICE: evaluate: could not update the state to FIRING. Alert=%+v & err=%+v.", alertToEvaluate.AlertInfo.AlertName, err)
-		}
+	var isConditionMet bool
+	if alertToEvaluate.Condition == alertutils.IsAnomalous {
+		isConditionMet = isAnomalous(serResVal, getBaselineValues(alertToEvaluate, nowTs), alertToEvaluate.Value)
+	} else {
+		isConditionMet = evaluateConditions(serResVal, &alertToEvaluate.Condition, alertToEvaluate.Value)
+	}
+	newState, pendingSince := getNextAlertState(alertToEvaluate.AlertInfo.State, alertToEvaluate.AlertInfo.PendingSince,
+		isConditionMet, alertToEvaluate.EvalFor, time.Now())
 
-		err = NotifyAlertHandlerRequest(alertToEvaluate.AlertInfo.AlertId)
+	if newState != alertToEvaluate.AlertInfo.State || !pendingSince.Equal(alertToEvaluate.AlertInfo.PendingSince) {
+		err = updateAlertState(alertToEvaluate.AlertInfo.AlertId, newState, pendingSince)
 		if err != nil {
-			log.Errorf("ALERTSERVICE: evaluate: could not setup the notification handler. found error = %v", err)
+			log.Errorf("ALERTSERVICE: evaluate: could not update the state to %v. Alert=%+v & err=%+v.", newState, alertToEvaluate.AlertInfo.AlertName, err)
 			return
 		}
-	} else {
-		err := updateAlertState(alertToEvaluate.AlertInfo.AlertId, alertutils.Inactive)
-		if err != nil {
-			log.Errorf("ALERTSERVICE: evaluate: could not update the state to INACTIVE. Alert=%+v & err=%+v.", alertToEvaluate.AlertInfo.AlertName, err)
-
+		if newState != alertToEvaluate.AlertInfo.State {
+			recordAlertHistory(alertToEvaluate.AlertInfo.AlertId, getTransitionEvent(alertToEvaluate.AlertInfo.State, newState), serResVal, "")
 		}
+		if alertToEvaluate.AlertInfo.State == alertutils.Firing && newState == alertutils.Inactive {
+			notifyAlertResolved(alertToEvaluate.AlertInfo.AlertId, serResVal)
+		}
+		alertToEvaluate.AlertInfo.State = newState
+		alertToEvaluate.AlertInfo.PendingSince = pendingSince
+	}
+
+	if newState == alertutils.Firing {
+		notifyAlertFiring(alertToEvaluate.AlertInfo.AlertId, serResVal)
 	}
 }
 
-func updateAlertState(alertId string, alertState alertutils.AlertState) error {
-	err := databaseObj.UpdateAlertStateByAlertID(alertId, alertState)
+/*
+Returns the state of an alert after an evaluation and since when its condition has been holding. An alert whose
+condition is met is pending until the condition has held for evalFor minutes, then it fires. An alert whose
+condition is not met is inactive
+*/
+func getNextAlertState(state alertutils.AlertState, pendingSince time.Time, isConditionMet bool, evalFor uint64,
+	now time.Time) (alertutils.AlertState, time.Time) {
+	if !isConditionMet {
+		return alertutils.Inactive, time.Time{}
+	}
+	if state == alertutils.Inactive || pendingSince.IsZero() {
+		pendingSince = now
+	}
+	if state == alertutils.Firing || now.Sub(pendingSince) >= time.Duration(evalFor)*time.Minute {
+		return alertutils.Firing, pendingSince
+	}
+	return alertutils.Pending, pendingSince
+}
+
+func updateAlertState(alertId string, alertState alertutils.AlertState, pendingSince time.Time) error {
+	err := databaseObj.UpdateAlertStateByAlertID(alertId, alertState, pendingSince)
 	return err
 }
 
-func evaluateConditions(serResVal int, queryCond *alertutils.AlertQueryCondition, val float32) bool {
+func evaluateConditions(serResVal float64, queryCond *alertutils.AlertQueryCondition, val float32) bool {
 	switch *queryCond {
 	case alertutils.IsAbove:
-		return serResVal > int(val)
+		return serResVal > float64(val)
 	case alertutils.IsBelow:
-		return serResVal < int(val)
+		return serResVal < float64(val)
 	case alertutils.IsEqualTo:
-		return serResVal == int(val)
+		return serResVal == float64(val)
 	case alertutils.IsNotEqualTo:
-		return serResVal != int(val)
+		return serResVal != float64(val)
 	case alertutils.HasNoValue:
 		return serResVal == 0
 	default:
@@ -114,25 +144,21 @@ func evaluateConditions(serResVal int, queryCond *alertutils.AlertQueryCondition
 }
 
 func evaluateMinionSearch(msToEvaluate *alertutils.MinionSearch, job gocron.Job) {
-	serResVal := pipesearch.ProcessAlertsPipeSearchRequest(msToEvaluate.QueryParams)
-	if serResVal == -1 {
-		log.Errorf("MinionSearch: evaluate: Empty response returned by server.")
+	serResVal, err := pipesearch.ProcessAlertsPipeSearchRequest(msToEvaluate.QueryParams, alertutils.AggregateValue, utils.GetCurrentTimeInMs())
+	if err != nil {
+		log.Errorf("MinionSearch: evaluate: could not evaluate the query of alert=%+v, err=%+v.", msToEvaluate.AlertInfo.AlertName, err)
 		return
 	}
 	isFiring := evaluateConditions(serResVal, &msToEvaluate.Condition, msToEvaluate.Value1)
 	if isFiring {
-		err := updateMinionSearchState(msToEvaluate.AlertInfo.AlertId, alertutils.Firing)
+		err = updateMinionSearchState(msToEvaluate.AlertInfo.AlertId, alertutils.Firing)
 		if err != nil {
 			log.Errorf("MinionSearch: evaluate: could not update the state to FIRING. Alert=%+v & err=%+v.", msToEvaluate.AlertInfo.AlertName, err)
 		}
 
-		err = NotifyAlertHandlerRequest(msToEvaluate.AlertInfo.AlertId)
-		if err != nil {
-			log.Errorf("MinionSearch: evaluate: could not setup the notification handler. found error = %v", err)
-			return
-		}
+		notifyAlertFiring(msToEvaluate.AlertInfo.AlertId, serResVal)
 	} else {
-		err := updateMinionSearchState(msToEvaluate.AlertInfo.AlertId, alertutils.Inactive)
+		err = updateMinionSearchState(msToEvaluate.AlertInfo.AlertId, alertutils.Inactive)
 		if err != nil {
 			log.Errorf("MinionSearch: evaluate: could not update the state to INACTIVE. Alert=%+v & err=%+v.", msToEvaluate.AlertInfo.AlertName, err)
 
@@ -0,0 +1,82 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alertsHandler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/siglens/siglens/pkg/alerts/alertutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_getNextAlertState(t *testing.T) {
+	start := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	state, pendingSince := getNextAlertState(alertutils.Inactive, time.Time{}, true, 5, start)
+	assert.Equal(t, alertutils.Pending, state)
+	assert.Equal(t, start, pendingSince)
+
+	state, pendingSince = getNextAlertState(state, pendingSince, true, 5, start.Add(4*time.Minute))
+	assert.Equal(t, alertutils.Pending, state)
+	assert.Equal(t, start, pendingSince)
+
+	state, pendingSince = getNextAlertState(state, pendingSince, true, 5, start.Add(5*time.Minute))
+	assert.Equal(t, alertutils.Firing, state)
+	assert.Equal(t, start, pendingSince)
+
+	state, pendingSince = getNextAlertState(state, pendingSince, true, 5, start.Add(6*time.Minute))
+	assert.Equal(t, alertutils.Firing, state)
+	assert.Equal(t, start, pendingSince)
+
+	state, pendingSince = getNextAlertState(state, pendingSince, false, 5, start.Add(7*time.Minute))
+	assert.Equal(t, alertutils.Inactive, state)
+	assert.True(t, pendingSince.IsZero())
+
+	// a pending alert whose condition stops holding starts over
+	state, pendingSince = getNextAlertState(alertutils.Pending, start, false, 5, start.Add(time.Minute))
+	assert.Equal(t, alertutils.Inactive, state)
+	state, pendingSince = getNextAlertState(state, pendingSince, true, 5, start.Add(2*time.Minute))
+	assert.Equal(t, alertutils.Pending, state)
+	assert.Equal(t, start.Add(2*time.Minute), pendingSince)
+
+	// without a for duration the alert fires right away
+	state, pendingSince = getNextAlertState(alertutils.Inactive, time.Time{}, true, 0, start)
+	assert.Equal(t, alertutils.Firing, state)
+	assert.Equal(t, start, pendingSince)
+}
+
+func Test_evaluateConditions(t *testing.T) {
+	cond := alertutils.IsAbove
+	assert.True(t, evaluateConditions(10.5, &cond, 10))
+	assert.False(t, evaluateConditions(10, &cond, 10))
+
+	cond = alertutils.IsBelow
+	assert.True(t, evaluateConditions(9.5, &cond, 10))
+	assert.False(t, evaluateConditions(10, &cond, 10))
+
+	cond = alertutils.IsEqualTo
+	assert.True(t, evaluateConditions(2.5, &cond, 2.5))
+	assert.False(t, evaluateConditions(2.4, &cond, 2.5))
+
+	cond = alertutils.IsNotEqualTo
+	assert.True(t, evaluateConditions(2.4, &cond, 2.5))
+
+	cond = alertutils.HasNoValue
+	assert.True(t, evaluateConditions(0, &cond, 10))
+	assert.False(t, evaluateConditions(1, &cond, 10))
+}
@@ -24,7 +24,10 @@ import (
 	"net/http"
 	"net/smtp"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/slack-go/slack"
 
@@ -34,7 +37,99 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-func NotifyAlertHandlerRequest(alertID string) error {
+const NOTIFICATION_MAX_ATTEMPTS = 3
+const NOTIFICATION_HTTP_TIMEOUT = 10 * time.Second
+
+// Wait before retrying a failed delivery, doubled after every failed attempt
+var notificationRetryBackoff = time.Second
+
+var pagerDutyEventsUrl = "https://events.pagerduty.com/v2/enqueue"
+
+var notificationHttpClient = &http.Client{Timeout: NOTIFICATION_HTTP_TIMEOUT}
+
+// PagerDuty rejects summaries longer than this many characters
+const PAGERDUTY_MAX_SUMMARY_LEN = 1024
+
+// map of alert id => lock held while the notifications of the alert are sent
+var alertNotifyLocks = make(map[string]*sync.Mutex)
+var alertNotifyLocksLock = &sync.Mutex{}
+
+func getAlertNotifyLock(alertID string) *sync.Mutex {
+	alertNotifyLocksLock.Lock()
+	defer alertNotifyLocksLock.Unlock()
+	lock, ok := alertNotifyLocks[alertID]
+	if !ok {
+		lock = &sync.Mutex{}
+		alertNotifyLocks[alertID] = lock
+	}
+	return lock
+}
+
+/*
+Sends the notifications of a firing alert in the background, so that the retries of its deliveries do not hold up
+the evaluations of the other alerts. It is skipped while the last notification of the alert is still being sent
+*/
+func notifyAlertFiring(alertID string, queryValue float64) {
+	lock := getAlertNotifyLock(alertID)
+	if !lock.TryLock() {
+		log.Infof("notifyAlertFiring: the last notification of alert id- %s is still being sent, skipping this one", alertID)
+		return
+	}
+	go func() {
+		defer lock.Unlock()
+		err := NotifyAlertHandlerRequest(alertID, queryValue)
+		if err != nil {
+			log.Errorf("notifyAlertFiring: could not send the notifications of alert id- %s, err=%v", alertID, err)
+		}
+	}()
+}
+
+// Resolves the notifications of an alert that stopped firing in the background, after the ones being sent
+func notifyAlertResolved(alertID string, queryValue float64) {
+	lock := getAlertNotifyLock(alertID)
+	go func() {
+		lock.Lock()
+		defer lock.Unlock()
+		err := ResolveAlertNotifications(alertID, queryValue)
+		if err != nil {
+			log.Errorf("notifyAlertResolved: could not resolve the notifications of alert id- %s, err=%v", alertID, err)
+		}
+	}()
+}
+
+/*
+Resolves the PagerDuty incident that the alert triggered, the other channels have nothing to resolve. It is not
+held back by the cooldown and the silence of the alert, so that the incident is closed as soon as the alert is
+*/
+func ResolveAlertNotifications(alertID string, queryValue float64) error {
+	alert, err := databaseObj.GetAlert(alertID)
+	if err != nil {
+		log.Errorf("ResolveAlertNotifications: Error retrieving alert id- %s, err=%v", alertID, err)
+		return err
+	}
+	if !isChannelEnabled(alert.AlertInfo.Channels, alertutils.ChannelPagerDuty) {
+		return nil
+	}
+	contact, err := processGetContactPoint(alert.AlertInfo.ContactId)
+	if err != nil {
+		log.Errorf("ResolveAlertNotifications: Error retrieving contact point- %s for alert id- %s, err=%v", alert.AlertInfo.ContactId, alertID, err)
+		return err
+	}
+	if contact.PagerDuty == "" {
+		return nil
+	}
+	err = sendWithRetry(alertutils.ChannelPagerDuty, contact.ContactName, func() error {
+		return resolvePagerDuty(contact.PagerDuty, alertID)
+	})
+	recordNotification(alertID, alertutils.ChannelPagerDuty, contact.ContactName, queryValue, err)
+	if err != nil {
+		log.Errorf("ResolveAlertNotifications: Error resolving PagerDuty incident for alert id- %s, err=%v", alertID, err)
+		return err
+	}
+	return nil
+}
+
+func NotifyAlertHandlerRequest(alertID string, queryValue float64) error {
 	if alertID == "" {
 		log.Errorf("NotifyAlertHandlerRequest: Missing alert_id")
 		return errors.New("Alert ID is empty")
@@ -55,53 +150,75 @@ func NotifyAlertHandlerRequest(alertID string) error {
 	if !silenceMinutesOver {
 		return nil
 	}
-	contact_id, message, subject, err := processGetContactDetails(alertID)
+	alert, err := databaseObj.GetAlert(alertID)
 	if err != nil {
-		log.Errorf("NotifyAlertHandlerRequest:Error retrieving contact and message for alert id- %s, err=%v", alertID, err)
+		log.Errorf("NotifyAlertHandlerRequest:Error retrieving alert id- %s, err=%v", alertID, err)
 		return err
 	}
-	emailIDs, channelIDs, webhooks, err := processGetEmailAndChannelID(contact_id)
+	contact, err := processGetContactPoint(alert.AlertInfo.ContactId)
 	if err != nil {
-		log.Errorf("NotifyAlertHandlerRequest:Error retrieving emails or channelIds of slack for contact_id- %s and alert id- %s, err=%v", contact_id, alertID, err)
+		log.Errorf("NotifyAlertHandlerRequest:Error retrieving contact point- %s for alert id- %s, err=%v", alert.AlertInfo.ContactId, alertID, err)
 		return err
 	}
-	emailSent := false
-	slackSent := false
-	webhookSent := false
-	if len(emailIDs) > 0 {
-		for _, emailID := range emailIDs {
-			err = sendAlertEmail(emailID, subject, message)
+
+	templateValues := getTemplateValues(alert, queryValue)
+	subject := alert.AlertInfo.AlertName
+	message := renderTemplate(alert.Message, templateValues, nil)
+	channels := alert.AlertInfo.Channels
+	numSent := 0
+	if isChannelEnabled(channels, alertutils.ChannelEmail) {
+		for _, emailID := range contact.Email {
+			err = sendWithRetry(alertutils.ChannelEmail, emailID, func() error {
+				return sendAlertEmail(emailID, subject, message)
+			})
+			recordNotification(alertID, alertutils.ChannelEmail, emailID, queryValue, err)
 			if err != nil {
 				log.Errorf("NotifyAlertHandlerRequest: Error sending email to- %s for alert id- %s, err=%v", emailID, alertID, err)
 			} else {
-				emailSent = true
+				numSent++
 			}
 		}
 	}
-	if len(channelIDs) > 0 {
-		for _, channelID := range channelIDs {
-			err = sendSlack(subject, message, channelID)
+	if isChannelEnabled(channels, alertutils.ChannelSlack) {
+		for _, channelID := range contact.Slack {
+			err = sendWithRetry(alertutils.ChannelSlack, channelID.ChannelId, func() error {
+				return sendSlack(subject, message, channelID)
+			})
+			recordNotification(alertID, alertutils.ChannelSlack, channelID.ChannelId, queryValue, err)
 			if err != nil {
-				log.Errorf("NotifyAlertHandlerRequest: Error sending Slack message to channelID- %s for alert id- %s, err=%v", channelID, alertID, err)
+				log.Errorf("NotifyAlertHandlerRequest: Error sending Slack message to channelID- %s for alert id- %s, err=%v", channelID.ChannelId, alertID, err)
 			} else {
-				slackSent = true
+				numSent++
 			}
 		}
 	}
-	if len(webhooks) > 0 {
-		for _, webhook := range webhooks {
-			err = sendWebhooks(webhook, subject, message)
+	if isChannelEnabled(channels, alertutils.ChannelPagerDuty) && contact.PagerDuty != "" {
+		err = sendWithRetry(alertutils.ChannelPagerDuty, contact.ContactName, func() error {
+			return sendPagerDuty(contact.PagerDuty, alert, subject, message, queryValue)
+		})
+		recordNotification(alertID, alertutils.ChannelPagerDuty, contact.ContactName, queryValue, err)
+		if err != nil {
+			log.Errorf("NotifyAlertHandlerRequest: Error sending PagerDuty event for alert id- %s, err=%v", alertID, err)
+		} else {
+			numSent++
+		}
+	}
+	if isChannelEnabled(channels, alertutils.ChannelWebhook) {
+		for _, webhook := range contact.Webhook {
+			err = sendWithRetry(alertutils.ChannelWebhook, webhook, func() error {
+				return sendWebhooks(webhook, subject, message, contact.WebhookTemplate, templateValues)
+			})
+			recordNotification(alertID, alertutils.ChannelWebhook, webhook, queryValue, err)
 			if err != nil {
 				log.Errorf("NotifyAlertHandlerRequest: Error sending Webhook message to webhook- %s for alert id- %s, err=%v", webhook, alertID, err)
 			} else {
-				webhookSent = true
+				numSent++
 			}
 		}
 	}
 
-	if !emailSent && !slackSent && !webhookSent {
-		return errors.New("Neither emails or slack message or webhook sent for this notification")
-
+	if numSent == 0 {
+		return errors.New("Neither emails or slack message or pagerduty event or webhook sent for this notification")
 	}
 
 	err = processUpdateLastSentTime(alertID)
@@ -112,6 +229,107 @@ func NotifyAlertHandlerRequest(alertID string) error {
 	return nil
 }
 
+// Adds the outcome of a delivery to the history of the alert
+func recordNotification(alertID string, channel string, target string, queryValue float64, err error) {
+	if err != nil {
+		recordAlertHistory(alertID, alertutils.EventNotificationFailed, queryValue, fmt.Sprintf("%v to %v: %v", channel, target, err))
+		return
+	}
+	recordAlertHistory(alertID, alertutils.EventNotificationSent, queryValue, fmt.Sprintf("%v to %v", channel, target))
+}
+
+// An alert with no channels set is sent to all the channels of its contact point
+func isChannelEnabled(channels []string, channel string) bool {
+	if len(channels) == 0 {
+		return true
+	}
+	for _, enabledChannel := range channels {
+		if enabledChannel == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// Retries a failed delivery with an exponential backoff, returns the error of the last attempt
+func sendWithRetry(channel string, target string, send func() error) error {
+	backoff := notificationRetryBackoff
+	var err error
+	for attempt := 1; attempt <= NOTIFICATION_MAX_ATTEMPTS; attempt++ {
+		err = send()
+		if err == nil {
+			return nil
+		}
+		if attempt < NOTIFICATION_MAX_ATTEMPTS {
+			log.Warnf("sendWithRetry: attempt %v of sending %v notification to %v failed, retrying in %v, err=%v", attempt, channel, target, backoff, err)
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+	return err
+}
+
+// Values of the placeholders that alert messages and webhook templates can use
+func getTemplateValues(alert *alertutils.AlertDetails, queryValue float64) map[string]string {
+	labels := make([]string, 0, len(alert.AlertInfo.Labels))
+	for _, label := range alert.AlertInfo.Labels {
+		labels = append(labels, label.LabelName+"="+label.LabelValue)
+	}
+	return map[string]string{
+		"{{alert_rule_name}}": alert.AlertInfo.AlertName,
+		"{{alert_id}}":        alert.AlertInfo.AlertId,
+		"{{query_string}}":    alert.QueryParams.QueryText,
+		"{{queryLanguage}}":   alert.QueryParams.QueryLanguage,
+		"{{condition}}":       getConditionText(alert.Condition, alert.Value),
+		"{{value}}":           strconv.FormatFloat(queryValue, 'f', -1, 64),
+		"{{labels}}":          strings.Join(labels, ", "),
+		"{{timestamp}}":       time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+func getConditionText(condition alertutils.AlertQueryCondition, value float32) string {
+	switch condition {
+	case alertutils.IsAbove:
+		return "above " + fmt.Sprintf("%1.0f", value)
+	case alertutils.IsBelow:
+		return "below " + fmt.Sprintf("%1.0f", value)
+	case alertutils.IsEqualTo:
+		return "is equal to " + fmt.Sprintf("%1.0f", value)
+	case alertutils.IsNotEqualTo:
+		return "is not equal to " + fmt.Sprintf("%1.0f", value)
+	case alertutils.HasNoValue:
+		return "has no value"
+	case alertutils.IsAnomalous:
+		if value <= 0 {
+			value = DEFAULT_ANOMALY_SIGMAS
+		}
+		return "deviates from its baseline by " + fmt.Sprintf("%g", value) + " sigma"
+	default:
+		return ""
+	}
+}
+
+// Replaces the placeholders of the template, the values are passed through escape if it is set
+func renderTemplate(template string, values map[string]string, escape func(string) string) string {
+	oldNew := make([]string, 0, 2*len(values))
+	for placeholder, value := range values {
+		if escape != nil {
+			value = escape(value)
+		}
+		oldNew = append(oldNew, placeholder, value)
+	}
+	return strings.NewReplacer(oldNew...).Replace(template)
+}
+
+// Escapes a value so that it can be placed inside a JSON string
+func escapeJsonString(value string) string {
+	escaped, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	return string(escaped[1 : len(escaped)-1])
+}
+
 func sendAlertEmail(emailID, subject, message string) error {
 	host, port, senderEmail, senderPassword := config.GetEmailConfig()
 	auth := smtp.PlainAuth("", senderEmail, senderPassword, host)
@@ -122,33 +340,99 @@ func sendAlertEmail(emailID, subject, message string) error {
 	err := smtp.SendMail(host+":"+strconv.Itoa(port), auth, senderEmail, []string{emailID}, []byte(body))
 	return err
 }
-func sendWebhooks(webhookUrl, subject, message string) error {
-	webhookBody := alertutils.WebhookBody{
-		Receiver: "My Super Webhook",
-		Status:   "firing",
-		Title:    subject,
-		Body:     message,
-		Alerts: []alertutils.Alert{
-			{
-				Status: "firing",
+
+// Posts the webhook template of the contact point with its placeholders replaced, or the default body if it has none
+func sendWebhooks(webhookUrl, subject, message string, webhookTemplate string, templateValues map[string]string) error {
+	var data []byte
+	if webhookTemplate != "" {
+		data = []byte(renderTemplate(webhookTemplate, templateValues, escapeJsonString))
+	} else {
+		webhookBody := alertutils.WebhookBody{
+			Receiver: "My Super Webhook",
+			Status:   "firing",
+			Title:    subject,
+			Body:     message,
+			Alerts: []alertutils.Alert{
+				{
+					Status: "firing",
+				},
 			},
-		},
+		}
+		var err error
+		data, err = json.Marshal(webhookBody)
+		if err != nil {
+			log.Errorf("sendWebhooks: Error marshaling webhook body: %v", err)
+			return err
+		}
 	}
+	return postNotification(webhookUrl, data)
+}
 
-	data, _ := json.Marshal(webhookBody)
+// Triggers a PagerDuty incident, later triggers of the same alert are grouped into it until it is resolved
+func sendPagerDuty(routingKey string, alert *alertutils.AlertDetails, subject string, message string, queryValue float64) error {
+	event := alertutils.PagerDutyEvent{
+		RoutingKey:  routingKey,
+		EventAction: "trigger",
+		DedupKey:    alert.AlertInfo.AlertId,
+		Payload: &alertutils.PagerDutyEventPayload{
+			Summary:  subject + ": " + message,
+			Source:   "siglens",
+			Severity: "critical",
+			CustomDetails: map[string]interface{}{
+				"query":     alert.QueryParams.QueryText,
+				"condition": getConditionText(alert.Condition, alert.Value),
+				"value":     queryValue,
+			},
+		},
+	}
+	event.Payload.Summary = truncateRunes(event.Payload.Summary, PAGERDUTY_MAX_SUMMARY_LEN)
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Errorf("sendPagerDuty: Error marshaling event: %v", err)
+		return err
+	}
+	return postNotification(pagerDutyEventsUrl, data)
+}
 
-	r, err := http.NewRequest("POST", webhookUrl, bytes.NewBuffer(data))
+// Resolves the PagerDuty incident of the alert, which has the alert id as its dedup key
+func resolvePagerDuty(routingKey string, alertID string) error {
+	event := alertutils.PagerDutyEvent{
+		RoutingKey:  routingKey,
+		EventAction: "resolve",
+		DedupKey:    alertID,
+	}
+	data, err := json.Marshal(event)
 	if err != nil {
-		log.Errorf("Error creating request: %v", err)
+		log.Errorf("resolvePagerDuty: Error marshaling event: %v", err)
+		return err
+	}
+	return postNotification(pagerDutyEventsUrl, data)
+}
+
+// Returns the first maxLen characters of the string, without splitting a multi-byte character
+func truncateRunes(str string, maxLen int) string {
+	if utf8.RuneCountInString(str) <= maxLen {
+		return str
 	}
+	return string([]rune(str)[:maxLen])
+}
 
+func postNotification(url string, data []byte) error {
+	r, err := http.NewRequest("POST", url, bytes.NewBuffer(data))
+	if err != nil {
+		log.Errorf("postNotification: Error creating request: %v", err)
+		return err
+	}
 	r.Header.Add("Content-Type", "application/json")
-	client := &http.Client{}
-	_, err1 := client.Do(r)
-	if err1 != nil {
-		log.Errorf("Error sending request: %v", err)
+	resp, err := notificationHttpClient.Do(r)
+	if err != nil {
+		return err
 	}
-	return err
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("postNotification: received status code %v", resp.StatusCode)
+	}
+	return nil
 }
 
 func isSilenceMinutesOver(alertID string) (bool, error) {
@@ -241,23 +525,13 @@ func processGetSilenceMinutesRequest(alert_id string) (uint64, time.Time, error)
 
 	return alertDataObj.AlertInfo.SilenceMinutes, last_time, nil
 }
-func processGetContactDetails(alert_id string) (string, string, string, error) {
-	id, message, subject, err := databaseObj.GetContactDetails(alert_id)
-	if err != nil {
-		log.Errorf("ProcessGetContactDetails: Error getting contact details for alert id- %s, err=%v", alert_id, err)
-		return "", "", "", err
-	}
-	return id, message, subject, nil
-}
-
-func processGetEmailAndChannelID(contact_id string) ([]string, []alertutils.SlackTokenConfig, []string, error) {
-	emails, slacks, webhook, err := databaseObj.GetEmailAndChannelID(contact_id)
+func processGetContactPoint(contact_id string) (*alertutils.Contact, error) {
+	contact, err := databaseObj.GetContactPoint(contact_id)
 	if err != nil {
-		log.Errorf("ProcessGetEmailAndChannelID: Error in getting emails and channel_ids for contact_id- %s, err=%v", contact_id, err)
-		return nil, nil, nil, err
+		log.Errorf("ProcessGetContactPoint: Error in getting the contact point for contact_id- %s, err=%v", contact_id, err)
+		return nil, err
 	}
-
-	return emails, slacks, webhook, nil
+	return contact, nil
 }
 
 func processUpdateLastSentTime(alert_id string) error {
@@ -0,0 +1,143 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alertsHandler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/siglens/siglens/pkg/alerts/alertutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_renderTemplate(t *testing.T) {
+	alert := &alertutils.AlertDetails{
+		AlertInfo: alertutils.AlertInfo{
+			AlertId:   "id1",
+			AlertName: "High errors",
+			Labels:    []alertutils.AlertLabel{{LabelName: "team", LabelValue: "payments"}},
+		},
+		QueryParams: alertutils.QueryParams{QueryText: `status="error" | stats count`, QueryLanguage: "Splunk QL"},
+		Condition:   alertutils.IsAbove,
+		Value:       100,
+	}
+	values := getTemplateValues(alert, 123.5)
+
+	message := renderTemplate("{{alert_rule_name}} is {{condition}}: {{value}} for {{query_string}} ({{labels}})", values, nil)
+	assert.Equal(t, `High errors is above 100: 123.5 for status="error" | stats count (team=payments)`, message)
+
+	body := renderTemplate(`{"text": "{{alert_rule_name}} {{query_string}}", "id": "{{alert_id}}"}`, values, escapeJsonString)
+	decoded := make(map[string]string)
+	assert.Nil(t, json.Unmarshal([]byte(body), &decoded))
+	assert.Equal(t, `High errors status="error" | stats count`, decoded["text"])
+	assert.Equal(t, "id1", decoded["id"])
+}
+
+func Test_isChannelEnabled(t *testing.T) {
+	assert.True(t, isChannelEnabled(nil, alertutils.ChannelEmail))
+	assert.True(t, isChannelEnabled([]string{alertutils.ChannelSlack, alertutils.ChannelPagerDuty}, alertutils.ChannelPagerDuty))
+	assert.False(t, isChannelEnabled([]string{alertutils.ChannelSlack}, alertutils.ChannelWebhook))
+}
+
+func Test_sendWithRetry(t *testing.T) {
+	notificationRetryBackoff = time.Millisecond
+
+	numAttempts := 0
+	err := sendWithRetry(alertutils.ChannelWebhook, "test", func() error {
+		numAttempts++
+		if numAttempts < 2 {
+			return errors.New("unavailable")
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, numAttempts)
+
+	numAttempts = 0
+	err = sendWithRetry(alertutils.ChannelWebhook, "test", func() error {
+		numAttempts++
+		return errors.New("unavailable")
+	})
+	assert.NotNil(t, err)
+	assert.Equal(t, NOTIFICATION_MAX_ATTEMPTS, numAttempts)
+}
+
+func Test_sendWebhooksAndPagerDuty(t *testing.T) {
+	bodies := make([]string, 0)
+	statusCode := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		w.WriteHeader(statusCode)
+	}))
+	defer server.Close()
+
+	values := map[string]string{"{{alert_rule_name}}": "High errors"}
+	err := sendWebhooks(server.URL, "High errors", "msg", `{"alert": "{{alert_rule_name}}"}`, values)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"alert": "High errors"}`, bodies[0])
+
+	err = sendWebhooks(server.URL, "High errors", "msg", "", values)
+	assert.Nil(t, err)
+	webhookBody := alertutils.WebhookBody{}
+	assert.Nil(t, json.Unmarshal([]byte(bodies[1]), &webhookBody))
+	assert.Equal(t, "High errors", webhookBody.Title)
+	assert.Equal(t, "msg", webhookBody.Body)
+
+	pagerDutyEventsUrl = server.URL
+	alert := &alertutils.AlertDetails{AlertInfo: alertutils.AlertInfo{AlertId: "id1"}, Condition: alertutils.IsBelow, Value: 5}
+	err = sendPagerDuty("key", alert, "High errors", "msg", 2)
+	assert.Nil(t, err)
+	event := alertutils.PagerDutyEvent{}
+	assert.Nil(t, json.Unmarshal([]byte(bodies[2]), &event))
+	assert.Equal(t, "key", event.RoutingKey)
+	assert.Equal(t, "trigger", event.EventAction)
+	assert.Equal(t, "id1", event.DedupKey)
+	assert.Equal(t, "High errors: msg", event.Payload.Summary)
+	assert.Equal(t, "below 5", event.Payload.CustomDetails["condition"])
+
+	// a long summary is cut on a character boundary
+	err = sendPagerDuty("key", alert, "High errors", strings.Repeat("é", 2000), 2)
+	assert.Nil(t, err)
+	event = alertutils.PagerDutyEvent{}
+	assert.Nil(t, json.Unmarshal([]byte(bodies[3]), &event))
+	assert.Equal(t, PAGERDUTY_MAX_SUMMARY_LEN, utf8.RuneCountInString(event.Payload.Summary))
+	assert.True(t, utf8.ValidString(event.Payload.Summary))
+
+	err = resolvePagerDuty("key", "id1")
+	assert.Nil(t, err)
+	resolveEvent := make(map[string]interface{})
+	assert.Nil(t, json.Unmarshal([]byte(bodies[4]), &resolveEvent))
+	assert.Equal(t, map[string]interface{}{"routing_key": "key", "event_action": "resolve", "dedup_key": "id1"}, resolveEvent)
+
+	statusCode = http.StatusInternalServerError
+	err = sendWebhooks(server.URL, "High errors", "msg", "", values)
+	assert.NotNil(t, err)
+}
+
+func Test_truncateRunes(t *testing.T) {
+	assert.Equal(t, "abc", truncateRunes("abc", 5))
+	assert.Equal(t, "日本", truncateRunes("日本語", 2))
+	assert.Equal(t, "", truncateRunes("日本語", 0))
+}
@@ -22,7 +22,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -55,9 +54,21 @@ const allAlertsTableQuery = `CREATE TABLE IF NOT EXISTS siglens.all_alerts (
 	cron_job JSONB,
 	labels JSONB,
 	node_id INT,
-	silence_minutes INT DEFAULT 0
+	silence_minutes INT DEFAULT 0,
+	value_type INT DEFAULT 0,
+	pending_since TIMESTAMP,
+	channels JSONB,
+	baseline_days INT DEFAULT 0
   );`
 
+// Columns added to all_alerts after its first release, they are added to the tables created before them
+var allAlertsAddedColumns = map[string]string{
+	"value_type":    "INT DEFAULT 0",
+	"pending_since": "TIMESTAMP",
+	"channels":      "JSONB",
+	"baseline_days": "INT DEFAULT 0",
+}
+
 const minionSearchesTableQuery = `CREATE TABLE IF NOT EXISTS siglens.minion_searches (
 	alert_id TEXT NOT NULL PRIMARY KEY UNIQUE,
 	alert_name TEXT NOT NULL UNIQUE,
@@ -83,9 +94,15 @@ const allContactsTableQuery = `CREATE TABLE IF NOT EXISTS siglens.all_contacts (
 	email JSONB,
 	slack JSONB,
 	pager_duty TEXT,
-	webhook JSONB
+	webhook JSONB,
+	webhook_template TEXT
   );`
 
+// Columns added to all_contacts after its first release
+var allContactsAddedColumns = map[string]string{
+	"webhook_template": "TEXT",
+}
+
 const allNotifsTableQuery = `CREATE TABLE IF NOT EXISTS siglens.notification_details (
 	alert_id TEXT NOT NULL PRIMARY KEY,
 	cooldown_period INT NOT NULL,
@@ -93,6 +110,18 @@ const allNotifsTableQuery = `CREATE TABLE IF NOT EXISTS siglens.notification_det
 	FOREIGN KEY (alert_id) REFERENCES all_alerts(alert_id) ON DELETE CASCADE
   );`
 
+const alertHistoryTableQuery = `CREATE TABLE IF NOT EXISTS siglens.alert_history (
+	history_id INTEGER PRIMARY KEY AUTOINCREMENT,
+	alert_id TEXT NOT NULL,
+	event TEXT NOT NULL,
+	value FLOAT,
+	details TEXT,
+	event_epoch INT NOT NULL,
+	FOREIGN KEY (alert_id) REFERENCES all_alerts(alert_id) ON DELETE CASCADE
+  );`
+
+const alertHistoryIndexQuery = `CREATE INDEX IF NOT EXISTS siglens.alert_history_alert_id_epoch ON alert_history(alert_id, event_epoch);`
+
 func (p *Sqlite) SetDB(dbConnection *sql.DB) {
 	p.db = dbConnection
 }
@@ -133,12 +162,22 @@ func (p *Sqlite) InitializeDB() error {
 		_ = tx.Rollback()
 		return err
 	}
+	err = p.addMissingColumns(tx, "all_alerts", allAlertsAddedColumns)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
 	_, err = tx.ExecContext(p.ctx, allContactsTableQuery)
 	if err != nil {
 		log.Errorf("initializeDB: unable to execute query: %v, err: %+v", allContactsTableQuery, err)
 		_ = tx.Rollback()
 		return err
 	}
+	err = p.addMissingColumns(tx, "all_contacts", allContactsAddedColumns)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
 	_, err = tx.ExecContext(p.ctx, allNotifsTableQuery)
 	if err != nil {
 		log.Errorf("initializeDB: unable to execute query: %v, err: %+v", allNotifsTableQuery, err)
@@ -151,6 +190,18 @@ func (p *Sqlite) InitializeDB() error {
 		_ = tx.Rollback()
 		return err
 	}
+	_, err = tx.ExecContext(p.ctx, alertHistoryTableQuery)
+	if err != nil {
+		log.Errorf("initializeDB: unable to execute query: %v, err: %+v", alertHistoryTableQuery, err)
+		_ = tx.Rollback()
+		return err
+	}
+	_, err = tx.ExecContext(p.ctx, alertHistoryIndexQuery)
+	if err != nil {
+		log.Errorf("initializeDB: unable to execute query: %v, err: %+v", alertHistoryIndexQuery, err)
+		_ = tx.Rollback()
+		return err
+	}
 	err = tx.Commit()
 	if err != nil {
 		log.Errorf("initializeDB: unable to execute transaction, err: %+v", err)
@@ -159,6 +210,46 @@ func (p *Sqlite) InitializeDB() error {
 	return nil
 }
 
+func (p *Sqlite) addMissingColumns(tx *sql.Tx, tableName string, columns map[string]string) error {
+	rows, err := tx.QueryContext(p.ctx, fmt.Sprintf("PRAGMA siglens.table_info(%v);", tableName))
+	if err != nil {
+		log.Errorf("addMissingColumns: unable to read the columns of %v, err: %+v", tableName, err)
+		return err
+	}
+	existingColumns := make(map[string]bool)
+	for rows.Next() {
+		var (
+			cid        int
+			name       string
+			colType    string
+			notNull    int
+			defaultVal sql.NullString
+			pk         int
+		)
+		err = rows.Scan(&cid, &name, &colType, &notNull, &defaultVal, &pk)
+		if err != nil {
+			log.Errorf("addMissingColumns: unable to scan row: %+v", err)
+			rows.Close()
+			return err
+		}
+		existingColumns[name] = true
+	}
+	rows.Close()
+
+	for column, definition := range columns {
+		if existingColumns[column] {
+			continue
+		}
+		sqlStatement := fmt.Sprintf("ALTER TABLE siglens.%v ADD COLUMN %v %v;", tableName, column, definition)
+		_, err = tx.ExecContext(p.ctx, sqlStatement)
+		if err != nil {
+			log.Errorf("addMissingColumns: unable to execute query: %v, err: %+v", sqlStatement, err)
+			return err
+		}
+	}
+	return nil
+}
+
 func (p *Sqlite) CloseDb() {
 	p.db.Close()
 }
@@ -167,6 +258,15 @@ func isValid(str string) bool {
 	return str != "" && str != "*"
 }
 
+func validateChannels(channels []string) error {
+	for _, channel := range channels {
+		if !alertutils.IsValidChannel(channel) {
+			return fmt.Errorf("invalid notification channel %v", channel)
+		}
+	}
+	return nil
+}
+
 // checks whether the alert name exists
 func (p Sqlite) isNewAlertName(alertName string) (bool, error) {
 	if !isValid(alertName) {
@@ -257,6 +357,11 @@ func (p Sqlite) CreateAlert(alertDetails *alertutils.AlertDetails) (alertutils.A
 		log.Errorf("createAlert: alert name already exists")
 		return alertutils.AlertDetails{}, errors.New("alert name already exists")
 	}
+	err = validateChannels(alertDetails.AlertInfo.Channels)
+	if err != nil {
+		log.Errorf("createAlert: %v", err)
+		return alertutils.AlertDetails{}, err
+	}
 	alert_id := CreateUniqId()
 	create_timestamp := time.Now()
 	state := alertutils.Inactive
@@ -275,8 +380,13 @@ func (p Sqlite) CreateAlert(alertDetails *alertutils.AlertDetails) (alertutils.A
 		log.Errorf("createAlert: unable to marshal Labels to JSON, err: %+v", err)
 		return alertutils.AlertDetails{}, err
 	}
-	sqlStatement := "INSERT INTO all_alerts(alert_name, alert_id, query_params, condition, value, eval_for, eval_interval, state, create_timestamp, message, contact_id, contact_name, labels) VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13);"
-	_, err = tx.ExecContext(p.ctx, sqlStatement, alertDetails.AlertInfo.AlertName, alert_id, queryParamsJSON, alertDetails.Condition, alertDetails.Value, alertDetails.EvalFor, alertDetails.EvalInterval, state, create_timestamp, alertDetails.Message, alertDetails.AlertInfo.ContactId, alertDetails.AlertInfo.ContactName, labelJson)
+	channelsJson, err := json.Marshal(alertDetails.AlertInfo.Channels)
+	if err != nil {
+		log.Errorf("createAlert: unable to marshal Channels to JSON, err: %+v", err)
+		return alertutils.AlertDetails{}, err
+	}
+	sqlStatement := "INSERT INTO all_alerts(alert_name, alert_id, query_params, condition, value, eval_for, eval_interval, state, create_timestamp, message, contact_id, contact_name, labels, value_type, channels, baseline_days) VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16);"
+	_, err = tx.ExecContext(p.ctx, sqlStatement, alertDetails.AlertInfo.AlertName, alert_id, queryParamsJSON, alertDetails.Condition, alertDetails.Value, alertDetails.EvalFor, alertDetails.EvalInterval, state, create_timestamp, alertDetails.Message, alertDetails.AlertInfo.ContactId, alertDetails.AlertInfo.ContactName, labelJson, alertDetails.ValueType, channelsJson, alertDetails.BaselineDays)
 	if err != nil {
 		log.Errorf("createAlert: unable to execute query: %v, with parameters: %v %+v %v %v, err: %v ", sqlStatement, alert_id, alertDetails, state, create_timestamp, err)
 		_ = tx.Rollback()
@@ -288,6 +398,8 @@ func (p Sqlite) CreateAlert(alertDetails *alertutils.AlertDetails) (alertutils.A
 		return alertutils.AlertDetails{}, err
 	}
 	alertDetails.AlertInfo.AlertId = alert_id
+	alertDetails.AlertInfo.State = state
+	alertDetails.AlertInfo.PendingSince = time.Time{}
 
 	var notification alertutils.Notification
 	notification.CooldownPeriod = 0
@@ -324,11 +436,15 @@ func (p Sqlite) GetAlert(alert_id string) (*alertutils.AlertDetails, error) {
 		contact_id       string
 		contact_name     string
 		labels           []byte
+		value_type       alertutils.AlertValueType
+		pending_since    sql.NullTime
+		channels         []byte
+		baseline_days    uint64
 	)
 
-	sqlStatement := "SELECT alert_name, query_params,  condition, value, eval_for, eval_interval, state, create_timestamp, message, contact_id, contact_name, labels FROM all_alerts WHERE alert_id=$1;"
+	sqlStatement := "SELECT alert_name, query_params,  condition, value, eval_for, eval_interval, state, create_timestamp, message, contact_id, contact_name, labels, value_type, pending_since, channels, baseline_days FROM all_alerts WHERE alert_id=$1;"
 	row := tx.QueryRow(sqlStatement, alert_id)
-	err = row.Scan(&alert_name, &queryParams, &condition, &value, &eval_for, &eval_interval, &state, &create_timestamp, &message, &contact_id, &contact_name, &labels)
+	err = row.Scan(&alert_name, &queryParams, &condition, &value, &eval_for, &eval_interval, &state, &create_timestamp, &message, &contact_id, &contact_name, &labels, &value_type, &pending_since, &channels, &baseline_days)
 	if err != nil {
 		log.Errorf("getAlert: unable to execute query: %v, err: %+v", sqlStatement, err)
 		_ = tx.Rollback()
@@ -354,19 +470,30 @@ func (p Sqlite) GetAlert(alert_id string) (*alertutils.AlertDetails, error) {
 		log.Errorf("getAlert: unable to Unmarshal alertLabels, err: %+v", err)
 		return nil, err
 	}
+	var alertChannels []string
+	// alerts created before the channels column have it NULL and are sent to all the channels
+	if len(channels) > 0 {
+		err = json.Unmarshal(channels, &alertChannels)
+		if err != nil {
+			log.Errorf("getAlert: unable to Unmarshal alertChannels, err: %+v", err)
+			return nil, err
+		}
+	}
 	alertInfoObj := alertutils.AlertInfo{
 		AlertName:       alert_name,
 		AlertId:         alert_id,
 		State:           state,
+		PendingSince:    pending_since.Time,
 		CreateTimestamp: create_timestamp,
 		ContactId:       contact_id,
 		ContactName:     contact_name,
 		Labels:          alertLabels,
+		Channels:        alertChannels,
 	}
 
 	return &alertutils.AlertDetails{AlertInfo: alertInfoObj, QueryParams: queryParamsStruct,
-		Condition: condition, Value: value,
-		EvalFor: eval_for, EvalInterval: eval_interval, Message: message}, nil
+		Condition: condition, ValueType: value_type, Value: value,
+		EvalFor: eval_for, EvalInterval: eval_interval, BaselineDays: baseline_days, Message: message}, nil
 }
 
 func (p Sqlite) GetAllAlerts() ([]alertutils.AlertInfo, error) {
@@ -461,7 +588,7 @@ func (p Sqlite) UpdateSilenceMinutes(updatedSilenceMinutes *alertutils.AlertDeta
 // Deletes cron job associated with the alert
 // Updates the db
 // Starts a new cron job with a new cron job id
-// updates alert details except state & cron_job_id
+// updates alert details except cron_job_id, the state is reset to inactive as the rule may have changed
 func (p Sqlite) UpdateAlert(editedAlert *alertutils.AlertDetails) error {
 	// update alert can update alert name -> still id will remain same
 	// todo: check if contact_id exists
@@ -479,6 +606,11 @@ func (p Sqlite) UpdateAlert(editedAlert *alertutils.AlertDetails) error {
 		log.Errorf("updateAlert: alert does not exist")
 		return errors.New("alert does not exist")
 	}
+	err = validateChannels(editedAlert.AlertInfo.Channels)
+	if err != nil {
+		log.Errorf("updateAlert: %v", err)
+		return err
+	}
 	// if alert name in request body is same as that present in db, allow update
 	if alertName != editedAlert.AlertInfo.AlertName {
 		isNewAlertName, err := p.isNewAlertName(editedAlert.AlertInfo.AlertName)
@@ -507,8 +639,13 @@ func (p Sqlite) UpdateAlert(editedAlert *alertutils.AlertDetails) error {
 		log.Errorf("createAlert: unable to marshal Labels to JSON, err: %+v", err)
 		return err
 	}
-	sqlStatement := "UPDATE all_alerts SET alert_name=$1, query_params=$2, condition=$3, value=$4, eval_for=$5, eval_interval=$6, message=$7, contact_id=$8, contact_name=$9, labels=$10 WHERE alert_id=$11;"
-	_, err = tx.ExecContext(p.ctx, sqlStatement, editedAlert.AlertInfo.AlertName, queryParamsJSON, editedAlert.Condition, editedAlert.Value, editedAlert.EvalFor, editedAlert.EvalInterval, editedAlert.Message, editedAlert.AlertInfo.ContactId, editedAlert.AlertInfo.ContactName, labelJson, editedAlert.AlertInfo.AlertId)
+	channelsJson, err := json.Marshal(editedAlert.AlertInfo.Channels)
+	if err != nil {
+		log.Errorf("updateAlert: unable to marshal Channels to JSON, err: %+v", err)
+		return err
+	}
+	sqlStatement := "UPDATE all_alerts SET alert_name=$1, query_params=$2, condition=$3, value=$4, eval_for=$5, eval_interval=$6, message=$7, contact_id=$8, contact_name=$9, labels=$10, value_type=$11, state=$12, pending_since=NULL, channels=$13, baseline_days=$14 WHERE alert_id=$15;"
+	_, err = tx.ExecContext(p.ctx, sqlStatement, editedAlert.AlertInfo.AlertName, queryParamsJSON, editedAlert.Condition, editedAlert.Value, editedAlert.EvalFor, editedAlert.EvalInterval, editedAlert.Message, editedAlert.AlertInfo.ContactId, editedAlert.AlertInfo.ContactName, labelJson, editedAlert.ValueType, alertutils.Inactive, channelsJson, editedAlert.BaselineDays, editedAlert.AlertInfo.AlertId)
 	if err != nil {
 		log.Errorf("updateAlert: unable to execute query: %v, with alert name: %v, err: %+v", sqlStatement, editedAlert.AlertInfo.AlertName, err)
 		_ = tx.Rollback()
@@ -519,6 +656,8 @@ func (p Sqlite) UpdateAlert(editedAlert *alertutils.AlertDetails) error {
 		log.Errorf("updateAlert: unable to execute transaction, err: %+v", err)
 		return err
 	}
+	editedAlert.AlertInfo.State = alertutils.Inactive
+	editedAlert.AlertInfo.PendingSince = time.Time{}
 	return nil
 }
 
@@ -543,12 +682,18 @@ func (p Sqlite) DeleteAlert(alert_id string) error {
 		return err
 	}
 
-	sqlStatement := "DELETE FROM all_alerts WHERE alert_id=$1;"
-	_, err = tx.ExecContext(p.ctx, sqlStatement, alert_id)
-	if err != nil {
-		log.Errorf("deleteAlert: unable to execute query: %v, with parameters: %v, err: %+v", sqlStatement, alert_id, err)
-		_ = tx.Rollback()
-		return err
+	// the foreign keys are not enforced on the connection, so the rows that reference the alert are deleted here
+	for _, sqlStatement := range []string{
+		"DELETE FROM alert_history WHERE alert_id=$1;",
+		"DELETE FROM notification_details WHERE alert_id=$1;",
+		"DELETE FROM all_alerts WHERE alert_id=$1;",
+	} {
+		_, err = tx.ExecContext(p.ctx, sqlStatement, alert_id)
+		if err != nil {
+			log.Errorf("deleteAlert: unable to execute query: %v, with parameters: %v, err: %+v", sqlStatement, alert_id, err)
+			_ = tx.Rollback()
+			return err
+		}
 	}
 
 	err = tx.Commit()
@@ -576,9 +721,9 @@ func (p Sqlite) CreateContact(newContact *alertutils.Contact) error {
 			log.Errorf("createContact: unable to marshal email to JSON, err: %+v", err)
 			return err
 		}
-		if len(newContact.Email) == 0 && len(newContact.Slack) == 0 && len(newContact.Webhook) == 0 {
-			log.Errorf("createContact: Please set contact email / Slack channel")
-			return errors.New("Please set contact email / Slack channel")
+		if len(newContact.Email) == 0 && len(newContact.Slack) == 0 && len(newContact.Webhook) == 0 && newContact.PagerDuty == "" {
+			log.Errorf("createContact: Please set contact email / Slack channel / PagerDuty / webhook")
+			return errors.New("Please set contact email / Slack channel / PagerDuty / webhook")
 		}
 		slackJSON, err := json.Marshal(newContact.Slack)
 		if err != nil {
@@ -595,8 +740,8 @@ func (p Sqlite) CreateContact(newContact *alertutils.Contact) error {
 			log.Errorf("createContact: unable to begin transaction, err: %+v", err)
 			return err
 		}
-		sqlStatement := "INSERT INTO all_contacts(contact_name, contact_id, email, slack, pager_duty, webhook) VALUES($1, $2, $3::jsonb, $4::jsonb, $5, $6::jsonb);"
-		_, err = tx.ExecContext(p.ctx, sqlStatement, newContact.ContactName, newContact.ContactId, emailJSON, slackJSON, newContact.PagerDuty, webhookJSON)
+		sqlStatement := "INSERT INTO all_contacts(contact_name, contact_id, email, slack, pager_duty, webhook, webhook_template) VALUES($1, $2, $3::jsonb, $4::jsonb, $5, $6::jsonb, $7);"
+		_, err = tx.ExecContext(p.ctx, sqlStatement, newContact.ContactName, newContact.ContactId, emailJSON, slackJSON, newContact.PagerDuty, webhookJSON, newContact.WebhookTemplate)
 		if err != nil {
 			log.Errorf("createContact: unable to execute query: %v, with parameters: %v %v %v %v %v, err: %+v", sqlStatement, newContact.ContactName, newContact.ContactId, newContact.Email, newContact.Slack, newContact.PagerDuty, err)
 			_ = tx.Rollback()
@@ -691,7 +836,7 @@ func (p Sqlite) GetAllContactPoints() ([]alertutils.Contact, error) {
 		log.Errorf("getAllContactPoints: unable to begin transaction, err: %+v", err)
 		return nil, err
 	}
-	sqlStatement := "SELECT contact_id, contact_name, email, slack, pager_duty,webhook FROM all_contacts;"
+	sqlStatement := "SELECT contact_id, contact_name, email, slack, pager_duty, webhook, webhook_template FROM all_contacts;"
 	rows, err := tx.Query(sqlStatement)
 	if err != nil {
 		log.Errorf("getAllContactPoints: unable to execute query: %v, err: %+v", sqlStatement, err)
@@ -701,14 +846,15 @@ func (p Sqlite) GetAllContactPoints() ([]alertutils.Contact, error) {
 	defer rows.Close()
 	for rows.Next() {
 		var (
-			contact_id   string
-			contact_name string
-			email        []byte
-			slack        []byte
-			webhook      []byte
-			pager_duty   string
+			contact_id       string
+			contact_name     string
+			email            []byte
+			slack            []byte
+			webhook          []byte
+			pager_duty       string
+			webhook_template sql.NullString
 		)
-		err := rows.Scan(&contact_id, &contact_name, &email, &slack, &pager_duty, &webhook)
+		err := rows.Scan(&contact_id, &contact_name, &email, &slack, &pager_duty, &webhook, &webhook_template)
 		if err != nil {
 			log.Errorf("getAllContactPoints: unable to scan row: %+v", err)
 			_ = tx.Rollback()
@@ -735,7 +881,7 @@ func (p Sqlite) GetAllContactPoints() ([]alertutils.Contact, error) {
 			_ = tx.Rollback()
 			return nil, err
 		}
-		contacts = append(contacts, alertutils.Contact{ContactName: contact_name, ContactId: contact_id, Email: emailArray, Slack: slackArray, PagerDuty: pager_duty, Webhook: webhookArray})
+		contacts = append(contacts, alertutils.Contact{ContactName: contact_name, ContactId: contact_id, Email: emailArray, Slack: slackArray, PagerDuty: pager_duty, Webhook: webhookArray, WebhookTemplate: webhook_template.String})
 	}
 	err = tx.Commit()
 	if err != nil {
@@ -775,110 +921,64 @@ func (p Sqlite) GetCoolDownDetails(alert_id string) (uint64, time.Time, error) {
 	return cooldown_period, last_sent_time, nil
 }
 
-// get contact_id and message from all_alerts table using alert_id
-func (p Sqlite) GetContactDetails(alert_id string) (string, string, string, error) {
+// get the contact point, with all its notification channels, from all_contacts table using contact_id
+func (p Sqlite) GetContactPoint(contact_id string) (*alertutils.Contact, error) {
 	tx, err := p.db.BeginTx(p.ctx, nil)
 	if err != nil {
-		log.Errorf("getContactDetails: unable to begin transaction, err: %+v", err)
-		return "", "", "", err
+		log.Errorf("GetContactPoint: unable to begin transaction, err: %+v", err)
+		return nil, err
 	}
 
-	var contact_id string
-	var message string
-	var alert_name string
-	var queryParams string
-	var condition int
-	var value float32
+	var (
+		contact_name     string
+		email            []byte
+		slack            []byte
+		pager_duty       sql.NullString
+		webhook          []byte
+		webhook_template sql.NullString
+	)
+	sqlStatement := "SELECT contact_name, email, slack, pager_duty, webhook, webhook_template FROM all_contacts WHERE contact_id=$1;"
 
-	sqlStatement := "SELECT contact_id, message, query_params, alert_name, condition, value FROM all_alerts WHERE alert_id=$1;"
-	row := tx.QueryRow(sqlStatement, alert_id)
-	err = row.Scan(&contact_id, &message, &queryParams, &alert_name, &condition, &value)
+	row := tx.QueryRow(sqlStatement, contact_id)
+	err = row.Scan(&contact_name, &email, &slack, &pager_duty, &webhook, &webhook_template)
 	if err != nil {
-		log.Errorf("getContactDetails: unable to execute query: %v, err: %+v", sqlStatement, err)
+		log.Errorf("GetContactPoint: unable to execute query: %v, err: %+v", sqlStatement, err)
 		_ = tx.Rollback()
-		return "", "", "", err
+		return nil, err
 	}
-
 	err = tx.Commit()
 	if err != nil {
-		log.Errorf("getContactDetails: unable to execute transaction, err: %+v", err)
-		return "", "", "", err
-	}
-
-	var queryParamsStruct alertutils.QueryParams
-	err = json.Unmarshal([]byte(queryParams), &queryParamsStruct)
-	if err != nil {
-		log.Errorf("GetAlert: unable to unmarshal queryParams: %v", err)
-		_ = tx.Rollback()
-		return "", "", "", err
-	}
-	newMessage := strings.ReplaceAll(message, "{{alert_rule_name}}", alert_name)
-	newMessage = strings.ReplaceAll(newMessage, "{{query_string}}", queryParamsStruct.QueryText)
-	if condition == 0 {
-		val := "above " + fmt.Sprintf("%1.0f", value)
-		newMessage = strings.ReplaceAll(newMessage, "{{condition}}", val)
-	} else if condition == 1 {
-		val := "below " + fmt.Sprintf("%1.0f", value)
-		newMessage = strings.ReplaceAll(newMessage, "{{condition}}", val)
-	} else if condition == 2 {
-		val := "is equal to " + fmt.Sprintf("%1.0f", value)
-		newMessage = strings.ReplaceAll(newMessage, "{{condition}}", val)
-	} else if condition == 3 {
-		val := "is not equal to " + fmt.Sprintf("%1.0f", value)
-		newMessage = strings.ReplaceAll(newMessage, "{{condition}}", val)
-	} else if condition == 4 {
-		newMessage = strings.ReplaceAll(newMessage, "{{condition}}", "has no value")
-	}
-	newMessage = strings.ReplaceAll(newMessage, "{{queryLanguage}}", queryParamsStruct.QueryLanguage)
-	return contact_id, newMessage, alert_name, nil
-}
-
-// get all emails and slack channelIDs from all_contacts table using contact_id
-func (p Sqlite) GetEmailAndChannelID(contact_id string) ([]string, []alertutils.SlackTokenConfig, []string, error) {
-	tx, err := p.db.BeginTx(p.ctx, nil)
-	if err != nil {
-		log.Errorf("GetEmailAndChannelID: unable to begin transaction, err: %+v", err)
-		return nil, nil, nil, err
+		log.Errorf("GetContactPoint: unable to execute transaction, err: %+v", err)
+		return nil, err
 	}
 
-	var email, slack, webhook []byte
-	sqlStatement := "SELECT email, slack, webhook FROM all_contacts WHERE contact_id=$1;"
-
-	row := tx.QueryRow(sqlStatement, contact_id)
-	err = row.Scan(&email, &slack, &webhook)
-	if err != nil {
-		log.Errorf("GetEmailAndChannelID: unable to execute query: %v, err: %+v", sqlStatement, err)
-		_ = tx.Rollback()
-		return nil, nil, nil, err
-	}
 	var emailArray []string
 	err = json.Unmarshal(email, &emailArray)
 	if err != nil {
-		log.Errorf("GetEmailAndChannelID: unable to unmarshal email: %+v", err)
-		_ = tx.Rollback()
-		return nil, nil, nil, err
+		log.Errorf("GetContactPoint: unable to unmarshal email: %+v", err)
+		return nil, err
 	}
 	var slackArray []alertutils.SlackTokenConfig
 	err = json.Unmarshal(slack, &slackArray)
 	if err != nil {
-		log.Errorf("GetEmailAndChannelID: unable to unmarshal slack: %+v", err)
-		_ = tx.Rollback()
-		return nil, nil, nil, err
+		log.Errorf("GetContactPoint: unable to unmarshal slack: %+v", err)
+		return nil, err
 	}
 	var webhookArray []string
 	err = json.Unmarshal(webhook, &webhookArray)
 	if err != nil {
-		log.Errorf("GetEmailAndChannelID: unable to unmarshal webhook: %+v", err)
-		_ = tx.Rollback()
-		return nil, nil, nil, err
-	}
-
-	err = tx.Commit()
-	if err != nil {
-		log.Errorf("GetEmailAndChannelID: unable to execute transaction, err: %+v", err)
-		return nil, nil, nil, err
+		log.Errorf("GetContactPoint: unable to unmarshal webhook: %+v", err)
+		return nil, err
 	}
-	return emailArray, slackArray, webhookArray, nil
+	return &alertutils.Contact{
+		ContactName:     contact_name,
+		ContactId:       contact_id,
+		Email:           emailArray,
+		Slack:           slackArray,
+		PagerDuty:       pager_duty.String,
+		Webhook:         webhookArray,
+		WebhookTemplate: webhook_template.String,
+	}, nil
 }
 
 // update last_sent_time in notification_details table
@@ -939,8 +1039,8 @@ func (p Sqlite) UpdateContactPoint(contact *alertutils.Contact) error {
 		log.Errorf("updateContactPoint: unable to begin transaction, err: %+v", err)
 		return err
 	}
-	sqlStatement := "UPDATE all_contacts SET contact_name=$1, email=$2, slack=$3, pager_duty=$4, webhook=$5 WHERE contact_id=$6;"
-	_, err = tx.ExecContext(p.ctx, sqlStatement, contact.ContactName, emailJSON, slackJSON, contact.PagerDuty, webhookJSON, contact.ContactId)
+	sqlStatement := "UPDATE all_contacts SET contact_name=$1, email=$2, slack=$3, pager_duty=$4, webhook=$5, webhook_template=$6 WHERE contact_id=$7;"
+	_, err = tx.ExecContext(p.ctx, sqlStatement, contact.ContactName, emailJSON, slackJSON, contact.PagerDuty, webhookJSON, contact.WebhookTemplate, contact.ContactId)
 	if err != nil {
 		log.Errorf("updateContactPoint: unable to execute query: %v, with contact name: %v, err: %+v", sqlStatement, contact.ContactName, err)
 		_ = tx.Rollback()
@@ -993,7 +1093,8 @@ func (p Sqlite) DeleteContactPoint(contact_id string) error {
 	return nil
 }
 
-func (p Sqlite) UpdateAlertStateByAlertID(alertId string, alertState alertutils.AlertState) error {
+// Updates the state of the alert and since when its condition has been holding, which is stored as NULL when zero
+func (p Sqlite) UpdateAlertStateByAlertID(alertId string, alertState alertutils.AlertState, pendingSince time.Time) error {
 	if !isValid(alertId) {
 		log.Errorf("UpdateAlertStateByAlertID: data validation check failed")
 		return errors.New("UpdateAlertStateByAlertID: data validation check failed")
@@ -1013,8 +1114,8 @@ func (p Sqlite) UpdateAlertStateByAlertID(alertId string, alertState alertutils.
 		log.Errorf("UpdateAlertStateByAlertID: unable to begin transaction, err: %+v", err)
 		return err
 	}
-	sqlStatement := "UPDATE all_alerts SET state=$1 WHERE alert_id=$2;"
-	_, err = tx.ExecContext(p.ctx, sqlStatement, alertState, alertId)
+	sqlStatement := "UPDATE all_alerts SET state=$1, pending_since=$2 WHERE alert_id=$3;"
+	_, err = tx.ExecContext(p.ctx, sqlStatement, alertState, sql.NullTime{Time: pendingSince, Valid: !pendingSince.IsZero()}, alertId)
 	if err != nil {
 		log.Errorf("UpdateAlertStateByAlertID: unable to execute query: %v, with alert id: %v, err: %+v", sqlStatement, alertId, err)
 		_ = tx.Rollback()
@@ -1294,3 +1395,89 @@ func (p Sqlite) verifyMinionSearchExists(alert_id string) (bool, string, error)
 	}
 	return searchExists, alert_name, nil
 }
+
+// Adds an entry to the history of an alert, and deletes its oldest entries past MAX_ALERT_HISTORY_ENTRIES
+func (p Sqlite) AddAlertHistory(entry *alertutils.AlertHistoryEntry) error {
+	if !isValid(entry.AlertId) {
+		log.Errorf("AddAlertHistory: data validation check failed")
+		return errors.New("AddAlertHistory: data validation check failed")
+	}
+	tx, err := p.db.BeginTx(p.ctx, nil)
+	if err != nil {
+		log.Errorf("AddAlertHistory: unable to begin transaction, err: %+v", err)
+		return err
+	}
+	sqlStatement := "INSERT INTO alert_history(alert_id, event, value, details, event_epoch) VALUES($1, $2, $3, $4, $5);"
+	_, err = tx.ExecContext(p.ctx, sqlStatement, entry.AlertId, entry.Event, entry.Value, entry.Details, entry.Timestamp)
+	if err != nil {
+		log.Errorf("AddAlertHistory: unable to execute query: %v, with alert id: %v, err: %+v", sqlStatement, entry.AlertId, err)
+		_ = tx.Rollback()
+		return err
+	}
+	sqlStatement = `DELETE FROM alert_history WHERE alert_id=$1 AND history_id <= (SELECT history_id FROM alert_history
+		WHERE alert_id=$1 ORDER BY history_id DESC LIMIT 1 OFFSET $2);`
+	_, err = tx.ExecContext(p.ctx, sqlStatement, entry.AlertId, alertutils.MAX_ALERT_HISTORY_ENTRIES)
+	if err != nil {
+		log.Errorf("AddAlertHistory: unable to execute query: %v, with alert id: %v, err: %+v", sqlStatement, entry.AlertId, err)
+		_ = tx.Rollback()
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Errorf("AddAlertHistory: unable to execute transaction, err: %+v", err)
+		return err
+	}
+	return nil
+}
+
+// Returns the history entries that match the query, the latest first
+func (p Sqlite) GetAlertHistory(historyQuery *alertutils.AlertHistoryQuery) ([]alertutils.AlertHistoryEntry, error) {
+	sqlStatement := "SELECT alert_id, event, value, details, event_epoch FROM alert_history WHERE event_epoch >= $1 AND event_epoch <= $2"
+	args := []interface{}{historyQuery.StartEpoch, historyQuery.EndEpoch}
+	if historyQuery.AlertId != "" {
+		args = append(args, historyQuery.AlertId)
+		sqlStatement += fmt.Sprintf(" AND alert_id=$%v", len(args))
+	}
+	if historyQuery.Event != "" {
+		args = append(args, historyQuery.Event)
+		sqlStatement += fmt.Sprintf(" AND event=$%v", len(args))
+	}
+	args = append(args, historyQuery.Limit)
+	sqlStatement += fmt.Sprintf(" ORDER BY event_epoch DESC, history_id DESC LIMIT $%v;", len(args))
+
+	tx, err := p.db.BeginTx(p.ctx, nil)
+	if err != nil {
+		log.Errorf("GetAlertHistory: unable to begin transaction, err: %+v", err)
+		return nil, err
+	}
+	rows, err := tx.Query(sqlStatement, args...)
+	if err != nil {
+		log.Errorf("GetAlertHistory: unable to execute query: %v, err: %+v", sqlStatement, err)
+		_ = tx.Rollback()
+		return nil, err
+	}
+	defer rows.Close()
+	history := make([]alertutils.AlertHistoryEntry, 0)
+	for rows.Next() {
+		var (
+			entry   alertutils.AlertHistoryEntry
+			value   sql.NullFloat64
+			details sql.NullString
+		)
+		err := rows.Scan(&entry.AlertId, &entry.Event, &value, &details, &entry.Timestamp)
+		if err != nil {
+			log.Errorf("GetAlertHistory: unable to scan row: %+v", err)
+			_ = tx.Rollback()
+			return nil, err
+		}
+		entry.Value = value.Float64
+		entry.Details = details.String
+		history = append(history, entry)
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Errorf("GetAlertHistory: unable to execute transaction, err: %+v", err)
+		return nil, err
+	}
+	return history, nil
+}
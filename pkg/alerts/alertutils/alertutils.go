@@ -26,9 +26,11 @@ type AlertDetails struct {
 	AlertInfo    AlertInfo           `json:"alertInfo"`
 	QueryParams  QueryParams         `json:"queryParams"`
 	Condition    AlertQueryCondition `json:"condition"`
+	ValueType    AlertValueType      `json:"value_type"`
 	Value        float32             `json:"value"`
-	EvalFor      uint64              `json:"eval_for"`
+	EvalFor      uint64              `json:"eval_for"` // minutes the condition must hold before the alert fires
 	EvalInterval uint64              `json:"eval_interval"`
+	BaselineDays uint64              `json:"baseline_days"` // days of the IsAnomalous baseline
 	Message      string              `json:"message"`
 	CronJob      gocron.Job          `json:"cron_job"`
 	NodeId       uint64              `json:"node_id"`
@@ -43,11 +45,13 @@ type AlertInfo struct {
 	AlertId         string       `json:"alert_id"`
 	AlertName       string       `json:"alert_name"`
 	State           AlertState   `json:"state"`
+	PendingSince    time.Time    `json:"pending_since"` // when the condition started holding, zero unless pending or firing
 	CreateTimestamp time.Time    `json:"create_timestamp"`
 	ContactId       string       `json:"contact_id"`
 	ContactName     string       `json:"contact_name"`
 	Labels          []AlertLabel `json:"labels"`
 	SilenceMinutes  uint64       `json:"silence_minutes"`
+	Channels        []string     `json:"channels"` // channels of the contact point the alert is sent to, all of them if empty
 }
 
 type QueryParams struct {
@@ -70,12 +74,44 @@ type WebhookBody struct {
 }
 
 type Contact struct {
-	ContactName string             `json:"contact_name"`
-	ContactId   string             `json:"contact_id"`
-	Email       []string           `json:"email"`
-	Slack       []SlackTokenConfig `json:"slack"`
-	PagerDuty   string             `json:"pager_duty"`
-	Webhook     []string           `json:"webhook"`
+	ContactName     string             `json:"contact_name"`
+	ContactId       string             `json:"contact_id"`
+	Email           []string           `json:"email"`
+	Slack           []SlackTokenConfig `json:"slack"`
+	PagerDuty       string             `json:"pager_duty"` // routing key of a PagerDuty Events API v2 integration
+	Webhook         []string           `json:"webhook"`
+	WebhookTemplate string             `json:"webhook_template"` // body posted to the webhooks, takes the same placeholders as the alert message
+}
+
+// notification channels of a contact point
+const (
+	ChannelEmail     = "email"
+	ChannelSlack     = "slack"
+	ChannelPagerDuty = "pagerduty"
+	ChannelWebhook   = "webhook"
+)
+
+func IsValidChannel(channel string) bool {
+	switch channel {
+	case ChannelEmail, ChannelSlack, ChannelPagerDuty, ChannelWebhook:
+		return true
+	default:
+		return false
+	}
+}
+
+type PagerDutyEvent struct {
+	RoutingKey  string                 `json:"routing_key"`
+	EventAction string                 `json:"event_action"`
+	DedupKey    string                 `json:"dedup_key"`
+	Payload     *PagerDutyEventPayload `json:"payload,omitempty"` // only sent with the trigger events
+}
+
+type PagerDutyEventPayload struct {
+	Summary       string                 `json:"summary"`
+	Source        string                 `json:"source"`
+	Severity      string                 `json:"severity"`
+	CustomDetails map[string]interface{} `json:"custom_details,omitempty"`
 }
 
 type SlackTokenConfig struct {
@@ -83,6 +119,36 @@ type SlackTokenConfig struct {
 	SlackToken string `json:"slack_token"`
 }
 
+type AlertHistoryEvent string // entry of the history of an alert
+const (
+	EventPending            AlertHistoryEvent = "pending"
+	EventFiring             AlertHistoryEvent = "firing"
+	EventResolved           AlertHistoryEvent = "resolved" // firing alert whose condition stopped holding
+	EventInactive           AlertHistoryEvent = "inactive" // pending alert whose condition stopped holding before it fired
+	EventNotificationSent   AlertHistoryEvent = "notification_sent"
+	EventNotificationFailed AlertHistoryEvent = "notification_failed"
+)
+
+// Entries kept in the history of an alert, the oldest ones are deleted when an entry is added past it
+const MAX_ALERT_HISTORY_ENTRIES = 10_000
+
+type AlertHistoryEntry struct {
+	AlertId   string            `json:"alert_id"`
+	Event     AlertHistoryEvent `json:"event"`
+	Value     float64           `json:"value"`   // evaluated value of the alert query
+	Details   string            `json:"details"` // channel, target and error of notifications
+	Timestamp uint64            `json:"timestamp"`
+}
+
+// Filters of the alert history, the epochs are in ms
+type AlertHistoryQuery struct {
+	AlertId    string
+	Event      AlertHistoryEvent
+	StartEpoch uint64
+	EndEpoch   uint64
+	Limit      uint64
+}
+
 type Notification struct {
 	AlertId        string    `json:"alert_id"`
 	CooldownPeriod uint64    `json:"cooldown_period"`
@@ -96,6 +162,13 @@ const (
 	IsEqualTo
 	IsNotEqualTo
 	HasNoValue
+	IsAnomalous // deviates from the same window of the past days by at least Value standard deviations
+)
+
+type AlertValueType uint8 // value of the alert query that is compared against the threshold
+const (
+	AggregateValue AlertValueType = iota // first aggregate of the query, e.g. the count in "| stats count"
+	ResultCount                          // number of matched records, or of groups for group by queries
 )
 
 type AlertState uint8 // state of the alerts
@@ -0,0 +1,434 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apikeys
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/siglens/siglens/pkg/blob"
+	"github.com/siglens/siglens/pkg/config"
+	"github.com/siglens/siglens/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+/*
+API keys authenticate the http requests of agents and other clients. Only the sha256 hashes of the keys are stored,
+a key is shown once, when it is created or rotated. A rotated key can stay valid for a grace period, so that the
+clients can move to the new key without downtime. The keys are stored in the querynodes dir of each node, the nodes
+of a cluster pass the key of the caller on in their requests to each other, so they need to have the same keys.
+*/
+
+const (
+	SCOPE_INGEST = "ingest"
+	SCOPE_QUERY  = "query"
+	SCOPE_ADMIN  = "admin" // every endpoint, including the management of the api keys
+)
+
+const API_KEY_PREFIX = "slk_"
+const API_KEY_SECRET_BYTES = 32
+
+// Number of characters of a key that are kept to tell the keys apart
+const API_KEY_DISPLAY_PREFIX_LEN = 12
+
+const MAX_ROTATION_GRACE_SECS = 7 * 24 * 3600
+
+type ApiKey struct {
+	Id                string   `json:"id"`
+	Name              string   `json:"name"`
+	Prefix            string   `json:"prefix"`
+	Hash              string   `json:"hash"` // hex encoded sha256 of the key
+	Scopes            []string `json:"scopes"`
+	Roles             []string `json:"roles,omitempty"` // names of the roles, a key without roles can access every index
+	OrgId             uint64   `json:"orgId"`
+	CreatedAt         uint64   `json:"createdAt"` // in ms
+	RotatedAt         uint64   `json:"rotatedAt,omitempty"`
+	RevokedAt         uint64   `json:"revokedAt,omitempty"`
+	PreviousHash      string   `json:"previousHash,omitempty"`      // hash of the key before the last rotation
+	PreviousExpiresAt uint64   `json:"previousExpiresAt,omitempty"` // the previous key is valid until then, in ms
+}
+
+// What the api returns about a key, without its hashes
+type ApiKeyInfo struct {
+	Id        string   `json:"id"`
+	Name      string   `json:"name"`
+	Prefix    string   `json:"prefix"`
+	Scopes    []string `json:"scopes"`
+	Roles     []string `json:"roles,omitempty"`
+	OrgId     uint64   `json:"orgId"`
+	CreatedAt uint64   `json:"createdAt"`
+	RotatedAt uint64   `json:"rotatedAt,omitempty"`
+	RevokedAt uint64   `json:"revokedAt,omitempty"`
+}
+
+var apiKeysFname string
+var apiKeysLock = &sync.RWMutex{}
+
+// map of key id => key
+var apiKeys = make(map[string]*ApiKey)
+
+// map of key hash => key, including the previous hashes of the rotated keys
+var apiKeysByHash = make(map[string]*ApiKey)
+
+var ErrInvalidApiKey = errors.New("invalid api key")
+
+func InitApiKeys() error {
+	baseDir := config.GetDataPath() + "querynodes/" + config.GetHostID() + "/apikeys"
+	apiKeysFname = baseDir + "/apikeys.json"
+	err := os.MkdirAll(baseDir, 0764)
+	if err != nil {
+		log.Errorf("InitApiKeys: failed to create basedir=%v, err=%v", baseDir, err)
+		return err
+	}
+
+	apiKeysLock.Lock()
+	err = readApiKeys()
+	apiKeysLock.Unlock()
+	if err != nil {
+		log.Errorf("InitApiKeys: failed to read the api keys, err=%v", err)
+		return err
+	}
+	err = initRoles(baseDir)
+	if err != nil {
+		log.Errorf("InitApiKeys: failed to read the roles, err=%v", err)
+		return err
+	}
+	err = initMaskingPolicies(baseDir)
+	if err != nil {
+		log.Errorf("InitApiKeys: failed to read the masking policies, err=%v", err)
+		return err
+	}
+
+	if config.GetApiKeysConfig().Enabled && !hasActiveAdminKey() {
+		return createBootstrapKey()
+	}
+	return nil
+}
+
+// Needs the write lock to be held
+func readApiKeys() error {
+	data, err := os.ReadFile(apiKeysFname)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	keys := make(map[string]*ApiKey)
+	err = json.Unmarshal(data, &keys)
+	if err != nil {
+		return err
+	}
+	apiKeys = keys
+	rebuildHashIndex()
+	return nil
+}
+
+// Needs the write lock to be held
+func writeApiKeys() error {
+	data, err := json.Marshal(apiKeys)
+	if err != nil {
+		return err
+	}
+	// only the owner can read the hashes
+	err = os.WriteFile(apiKeysFname, data, 0600)
+	if err != nil {
+		log.Errorf("writeApiKeys: failed to write file=%v, err=%v", apiKeysFname, err)
+		return err
+	}
+	err = blob.UploadQueryNodeDir()
+	if err != nil {
+		log.Errorf("writeApiKeys: failed to upload query nodes dir, err=%v", err)
+		return err
+	}
+	return nil
+}
+
+// Needs the write lock to be held
+func rebuildHashIndex() {
+	apiKeysByHash = make(map[string]*ApiKey, len(apiKeys))
+	for _, key := range apiKeys {
+		apiKeysByHash[key.Hash] = key
+		if key.PreviousHash != "" {
+			apiKeysByHash[key.PreviousHash] = key
+		}
+	}
+}
+
+func hasActiveAdminKey() bool {
+	apiKeysLock.RLock()
+	defer apiKeysLock.RUnlock()
+	for _, key := range apiKeys {
+		if key.RevokedAt == 0 && key.HasScope(SCOPE_ADMIN) {
+			return true
+		}
+	}
+	return false
+}
+
+// Creates an admin key so that the first keys can be created, it is written to a file only the owner can read
+func createBootstrapKey() error {
+	token, _, err := CreateApiKey("bootstrap", []string{SCOPE_ADMIN}, nil, 0)
+	if err != nil {
+		log.Errorf("createBootstrapKey: failed to create the key, err=%v", err)
+		return err
+	}
+	tokenFname := config.GetDataPath() + "apikeys_bootstrap_token"
+	err = os.WriteFile(tokenFname, []byte(token+"\n"), 0600)
+	if err != nil {
+		log.Errorf("createBootstrapKey: failed to write file=%v, err=%v", tokenFname, err)
+		return err
+	}
+	log.Infof("createBootstrapKey: created an admin api key, it has been written to %v", tokenFname)
+	return nil
+}
+
+func hashApiKey(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+func generateApiKey() (string, error) {
+	secret := make([]byte, API_KEY_SECRET_BYTES)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return "", err
+	}
+	return API_KEY_PREFIX + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+func validateScopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return errors.New("an api key needs at least one scope")
+	}
+	for _, scope := range scopes {
+		switch scope {
+		case SCOPE_INGEST, SCOPE_QUERY, SCOPE_ADMIN:
+		default:
+			return fmt.Errorf("invalid scope %q, the scopes are %v, %v and %v", scope, SCOPE_INGEST, SCOPE_QUERY, SCOPE_ADMIN)
+		}
+	}
+	return nil
+}
+
+// Admin keys have every scope
+func (key *ApiKey) HasScope(scope string) bool {
+	for _, keyScope := range key.Scopes {
+		if keyScope == scope || keyScope == SCOPE_ADMIN {
+			return true
+		}
+	}
+	return false
+}
+
+func (key *ApiKey) toInfo() *ApiKeyInfo {
+	return &ApiKeyInfo{
+		Id:        key.Id,
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		Scopes:    key.Scopes,
+		Roles:     key.Roles,
+		OrgId:     key.OrgId,
+		CreatedAt: key.CreatedAt,
+		RotatedAt: key.RotatedAt,
+		RevokedAt: key.RevokedAt,
+	}
+}
+
+// Returns the key, which is not stored and can not be retrieved later
+func CreateApiKey(name string, scopes []string, roleNames []string, orgid uint64) (string, *ApiKeyInfo, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", nil, errors.New("an api key needs a name")
+	}
+	err := validateScopes(scopes)
+	if err != nil {
+		return "", nil, err
+	}
+	err = validateRoleNames(roleNames, orgid)
+	if err != nil {
+		return "", nil, err
+	}
+	token, err := generateApiKey()
+	if err != nil {
+		log.Errorf("CreateApiKey: failed to generate a key, err=%v", err)
+		return "", nil, err
+	}
+
+	key := &ApiKey{
+		Id:        uuid.New().String(),
+		Name:      name,
+		Prefix:    token[:API_KEY_DISPLAY_PREFIX_LEN],
+		Hash:      hashApiKey(token),
+		Scopes:    scopes,
+		Roles:     roleNames,
+		OrgId:     orgid,
+		CreatedAt: utils.GetCurrentTimeInMs(),
+	}
+
+	apiKeysLock.Lock()
+	defer apiKeysLock.Unlock()
+	apiKeys[key.Id] = key
+	rebuildHashIndex()
+	err = writeApiKeys()
+	if err != nil {
+		delete(apiKeys, key.Id)
+		rebuildHashIndex()
+		return "", nil, err
+	}
+	return token, key.toInfo(), nil
+}
+
+// Replaces the key of id by a new one, the old key stays valid for graceSecs
+func RotateApiKey(id string, graceSecs uint64, orgid uint64) (string, *ApiKeyInfo, error) {
+	if graceSecs > MAX_ROTATION_GRACE_SECS {
+		return "", nil, fmt.Errorf("the grace period can be at most %v seconds", MAX_ROTATION_GRACE_SECS)
+	}
+	token, err := generateApiKey()
+	if err != nil {
+		log.Errorf("RotateApiKey: failed to generate a key, err=%v", err)
+		return "", nil, err
+	}
+
+	apiKeysLock.Lock()
+	defer apiKeysLock.Unlock()
+	key, ok := apiKeys[id]
+	if !ok || key.OrgId != orgid {
+		return "", nil, fmt.Errorf("api key %v does not exist", id)
+	}
+	if key.RevokedAt != 0 {
+		return "", nil, fmt.Errorf("api key %v has been revoked", id)
+	}
+	rotated := *key
+	nowMs := utils.GetCurrentTimeInMs()
+	rotated.Prefix = token[:API_KEY_DISPLAY_PREFIX_LEN]
+	rotated.Hash = hashApiKey(token)
+	rotated.RotatedAt = nowMs
+	rotated.PreviousHash = ""
+	rotated.PreviousExpiresAt = 0
+	if graceSecs > 0 {
+		rotated.PreviousHash = key.Hash
+		rotated.PreviousExpiresAt = nowMs + graceSecs*1000
+	}
+
+	apiKeys[id] = &rotated
+	rebuildHashIndex()
+	err = writeApiKeys()
+	if err != nil {
+		apiKeys[id] = key
+		rebuildHashIndex()
+		return "", nil, err
+	}
+	return token, rotated.toInfo(), nil
+}
+
+// Replaces the roles of the key, no roles gives it access to every index of its org
+func SetApiKeyRoles(id string, roleNames []string, orgid uint64) (*ApiKeyInfo, error) {
+	apiKeysLock.Lock()
+	defer apiKeysLock.Unlock()
+	key, ok := apiKeys[id]
+	if !ok || key.OrgId != orgid {
+		return nil, fmt.Errorf("api key %v does not exist", id)
+	}
+	if key.RevokedAt != 0 {
+		return nil, fmt.Errorf("api key %v has been revoked", id)
+	}
+	err := validateRoleNames(roleNames, orgid)
+	if err != nil {
+		return nil, err
+	}
+	updated := *key
+	updated.Roles = roleNames
+	apiKeys[id] = &updated
+	rebuildHashIndex()
+	err = writeApiKeys()
+	if err != nil {
+		apiKeys[id] = key
+		rebuildHashIndex()
+		return nil, err
+	}
+	return updated.toInfo(), nil
+}
+
+// The revoked keys are kept, so that they still show up in the list of keys
+func RevokeApiKey(id string, orgid uint64) error {
+	apiKeysLock.Lock()
+	defer apiKeysLock.Unlock()
+	key, ok := apiKeys[id]
+	if !ok || key.OrgId != orgid {
+		return fmt.Errorf("api key %v does not exist", id)
+	}
+	if key.RevokedAt != 0 {
+		return nil
+	}
+	revoked := *key
+	revoked.RevokedAt = utils.GetCurrentTimeInMs()
+	apiKeys[id] = &revoked
+	rebuildHashIndex()
+	err := writeApiKeys()
+	if err != nil {
+		apiKeys[id] = key
+		rebuildHashIndex()
+		return err
+	}
+	return nil
+}
+
+// Returns the keys of the org, the oldest first
+func ListApiKeys(orgid uint64) []*ApiKeyInfo {
+	apiKeysLock.RLock()
+	defer apiKeysLock.RUnlock()
+	keys := make([]*ApiKeyInfo, 0, len(apiKeys))
+	for _, key := range apiKeys {
+		if key.OrgId == orgid {
+			keys = append(keys, key.toInfo())
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].CreatedAt != keys[j].CreatedAt {
+			return keys[i].CreatedAt < keys[j].CreatedAt
+		}
+		return keys[i].Id < keys[j].Id
+	})
+	return keys
+}
+
+// Returns the key the token belongs to, or ErrInvalidApiKey if it is unknown, revoked or an expired previous key
+func Authenticate(token string, nowMs uint64) (*ApiKey, error) {
+	hash := hashApiKey(token)
+	apiKeysLock.RLock()
+	defer apiKeysLock.RUnlock()
+	key, ok := apiKeysByHash[hash]
+	if !ok || key.RevokedAt != 0 {
+		return nil, ErrInvalidApiKey
+	}
+	if hash == key.PreviousHash && nowMs >= key.PreviousExpiresAt {
+		return nil, ErrInvalidApiKey
+	}
+	return key, nil
+}
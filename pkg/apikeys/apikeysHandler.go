@@ -0,0 +1,169 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apikeys
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/siglens/siglens/pkg/config"
+	"github.com/siglens/siglens/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+const API_KEY_HEADER = "X-API-Key"
+
+// The authenticated key of a request is stored under this user value
+const API_KEY_CTX_KEY = "apiKey"
+
+func setErrMsg(ctx *fasthttp.RequestCtx, statusCode int, message string) {
+	var httpResp utils.HttpServerResponse
+	ctx.SetStatusCode(statusCode)
+	httpResp.Message = message
+	httpResp.StatusCode = statusCode
+	utils.WriteResponse(ctx, httpResp)
+}
+
+// The key is sent in the X-API-Key header or as a bearer token
+func getRequestApiKey(ctx *fasthttp.RequestCtx) string {
+	if token := string(ctx.Request.Header.Peek(API_KEY_HEADER)); token != "" {
+		return token
+	}
+	token, err := utils.ExtractBearerToken(ctx)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+/*
+Checks that the request has an api key with the scope, when the api keys are enabled. Otherwise it responds with a
+401 or 403 and returns false. An empty scope lets every request through
+*/
+func AuthorizeRequest(ctx *fasthttp.RequestCtx, scope string) bool {
+	if scope == "" || !config.GetApiKeysConfig().Enabled {
+		return true
+	}
+	token := getRequestApiKey(ctx)
+	if token == "" {
+		ctx.Response.Header.Set("WWW-Authenticate", "Bearer")
+		setErrMsg(ctx, fasthttp.StatusUnauthorized, "Unauthorized: an api key is required")
+		return false
+	}
+	key, err := Authenticate(token, utils.GetCurrentTimeInMs())
+	if err != nil {
+		ctx.Response.Header.Set("WWW-Authenticate", "Bearer")
+		setErrMsg(ctx, fasthttp.StatusUnauthorized, "Unauthorized: invalid api key")
+		return false
+	}
+	if !key.HasScope(scope) {
+		setErrMsg(ctx, fasthttp.StatusForbidden, "Forbidden: the api key does not have the "+scope+" scope")
+		return false
+	}
+	ctx.SetUserValue(API_KEY_CTX_KEY, key)
+	return true
+}
+
+// Handles /api/apikeys/create, the body is {"name": "...", "scopes": ["ingest"], "roles": ["team-a"]}
+func ProcessCreateApiKeyRequest(ctx *fasthttp.RequestCtx, myid uint64) {
+	request := struct {
+		Name   string   `json:"name"`
+		Scopes []string `json:"scopes"`
+		Roles  []string `json:"roles"`
+	}{}
+	err := json.Unmarshal(ctx.PostBody(), &request)
+	if err != nil {
+		log.Errorf("ProcessCreateApiKeyRequest: could not unmarshal the request, err=%v", err)
+		setErrMsg(ctx, fasthttp.StatusBadRequest, "Bad Request")
+		return
+	}
+	token, keyInfo, err := CreateApiKey(request.Name, request.Scopes, request.Roles, myid)
+	if err != nil {
+		log.Errorf("ProcessCreateApiKeyRequest: could not create the api key, err=%v", err)
+		setErrMsg(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	utils.WriteJsonResponse(ctx, map[string]interface{}{"key": token, "apiKey": keyInfo})
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
+
+// Handles /api/apikeys/listall
+func ProcessListApiKeysRequest(ctx *fasthttp.RequestCtx, myid uint64) {
+	utils.WriteJsonResponse(ctx, ListApiKeys(myid))
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
+
+// Handles /api/apikeys/rotate/{key-id}, the optional body {"gracePeriodSecs": 3600} keeps the old key valid for a while
+func ProcessRotateApiKeyRequest(ctx *fasthttp.RequestCtx, myid uint64) {
+	keyId := utils.ExtractParamAsString(ctx.UserValue("key-id"))
+	request := struct {
+		GracePeriodSecs uint64 `json:"gracePeriodSecs"`
+	}{}
+	if len(ctx.PostBody()) > 0 {
+		err := json.Unmarshal(ctx.PostBody(), &request)
+		if err != nil {
+			log.Errorf("ProcessRotateApiKeyRequest: could not unmarshal the request, err=%v", err)
+			setErrMsg(ctx, fasthttp.StatusBadRequest, "Bad Request")
+			return
+		}
+	}
+	token, keyInfo, err := RotateApiKey(keyId, request.GracePeriodSecs, myid)
+	if err != nil {
+		log.Errorf("ProcessRotateApiKeyRequest: could not rotate api key %v, err=%v", keyId, err)
+		setErrMsg(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	utils.WriteJsonResponse(ctx, map[string]interface{}{"key": token, "apiKey": keyInfo})
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
+
+// Handles /api/apikeys/{key-id}/roles, the body is {"roles": ["team-a"]}
+func ProcessSetApiKeyRolesRequest(ctx *fasthttp.RequestCtx, myid uint64) {
+	keyId := utils.ExtractParamAsString(ctx.UserValue("key-id"))
+	request := struct {
+		Roles []string `json:"roles"`
+	}{}
+	err := json.Unmarshal(ctx.PostBody(), &request)
+	if err != nil {
+		log.Errorf("ProcessSetApiKeyRolesRequest: could not unmarshal the request, err=%v", err)
+		setErrMsg(ctx, fasthttp.StatusBadRequest, "Bad Request")
+		return
+	}
+	keyInfo, err := SetApiKeyRoles(keyId, request.Roles, myid)
+	if err != nil {
+		log.Errorf("ProcessSetApiKeyRolesRequest: could not set the roles of api key %v, err=%v", keyId, err)
+		setErrMsg(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	utils.WriteJsonResponse(ctx, keyInfo)
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
+
+// Handles /api/apikeys/{key-id}
+func ProcessRevokeApiKeyRequest(ctx *fasthttp.RequestCtx, myid uint64) {
+	keyId := utils.ExtractParamAsString(ctx.UserValue("key-id"))
+	err := RevokeApiKey(keyId, myid)
+	if err != nil {
+		log.Errorf("ProcessRevokeApiKeyRequest: could not revoke api key %v, err=%v", keyId, err)
+		setErrMsg(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	utils.WriteJsonResponse(ctx, "Api key revoked successfully")
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apikeys
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/siglens/siglens/pkg/config"
+	"github.com/siglens/siglens/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func initTestApiKeys(t *testing.T) {
+	config.InitializeDefaultConfig()
+	dataPath := t.TempDir() + "/"
+	runningConfig := config.GetRunningConfig()
+	runningConfig.DataPath = dataPath
+	config.SetConfig(*runningConfig)
+	apiKeys = make(map[string]*ApiKey)
+	apiKeysByHash = make(map[string]*ApiKey)
+	roles = make(map[string]*Role)
+	maskingPolicies = make(map[string]*MaskingPolicy)
+	assert.Nil(t, InitApiKeys())
+}
+
+func Test_ApiKeyLifecycle(t *testing.T) {
+	initTestApiKeys(t)
+
+	token, info, err := CreateApiKey("agent", []string{SCOPE_INGEST}, nil, 0)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(token, API_KEY_PREFIX))
+	assert.Equal(t, token[:API_KEY_DISPLAY_PREFIX_LEN], info.Prefix)
+
+	key, err := Authenticate(token, utils.GetCurrentTimeInMs())
+	assert.Nil(t, err)
+	assert.Equal(t, info.Id, key.Id)
+	assert.True(t, key.HasScope(SCOPE_INGEST))
+	assert.False(t, key.HasScope(SCOPE_QUERY))
+	assert.False(t, key.HasScope(SCOPE_ADMIN))
+
+	// only the hash is stored
+	data, err := os.ReadFile(apiKeysFname)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(data), token)
+
+	_, err = Authenticate(token+"x", utils.GetCurrentTimeInMs())
+	assert.Equal(t, ErrInvalidApiKey, err)
+
+	// the old key stays valid during the grace period
+	newToken, _, err := RotateApiKey(info.Id, 60, 0)
+	assert.Nil(t, err)
+	nowMs := utils.GetCurrentTimeInMs()
+	_, err = Authenticate(newToken, nowMs)
+	assert.Nil(t, err)
+	_, err = Authenticate(token, nowMs)
+	assert.Nil(t, err)
+	_, err = Authenticate(token, nowMs+61_000)
+	assert.Equal(t, ErrInvalidApiKey, err)
+
+	// the keys are read back from the file
+	apiKeysLock.Lock()
+	apiKeys = make(map[string]*ApiKey)
+	assert.Nil(t, readApiKeys())
+	apiKeysLock.Unlock()
+	_, err = Authenticate(newToken, nowMs)
+	assert.Nil(t, err)
+
+	assert.Nil(t, RevokeApiKey(info.Id, 0))
+	_, err = Authenticate(newToken, nowMs)
+	assert.Equal(t, ErrInvalidApiKey, err)
+	_, _, err = RotateApiKey(info.Id, 0, 0)
+	assert.NotNil(t, err)
+
+	keys := ListApiKeys(0)
+	assert.Len(t, keys, 1)
+	assert.NotZero(t, keys[0].RevokedAt)
+	assert.Empty(t, ListApiKeys(1))
+}
+
+func Test_CreateApiKeyValidation(t *testing.T) {
+	initTestApiKeys(t)
+
+	_, _, err := CreateApiKey("", []string{SCOPE_QUERY}, nil, 0)
+	assert.NotNil(t, err)
+	_, _, err = CreateApiKey("dashboards", []string{}, nil, 0)
+	assert.NotNil(t, err)
+	_, _, err = CreateApiKey("dashboards", []string{"write"}, nil, 0)
+	assert.NotNil(t, err)
+	_, _, err = RotateApiKey("missing", 0, 0)
+	assert.NotNil(t, err)
+	assert.NotNil(t, RevokeApiKey("missing", 0))
+
+	_, info, err := CreateApiKey("dashboards", []string{SCOPE_QUERY}, nil, 0)
+	assert.Nil(t, err)
+	_, _, err = RotateApiKey(info.Id, MAX_ROTATION_GRACE_SECS+1, 0)
+	assert.NotNil(t, err)
+	_, _, err = RotateApiKey(info.Id, 0, 1)
+	assert.NotNil(t, err)
+}
+
+func Test_AuthorizeRequest(t *testing.T) {
+	initTestApiKeys(t)
+	runningConfig := config.GetRunningConfig()
+	runningConfig.ApiKeys.Enabled = true
+	config.SetConfig(*runningConfig)
+	defer func() {
+		runningConfig.ApiKeys.Enabled = false
+		config.SetConfig(*runningConfig)
+	}()
+
+	token, _, err := CreateApiKey("dashboards", []string{SCOPE_QUERY}, nil, 0)
+	assert.Nil(t, err)
+	adminToken, _, err := CreateApiKey("admin", []string{SCOPE_ADMIN}, nil, 0)
+	assert.Nil(t, err)
+
+	ctx := &fasthttp.RequestCtx{}
+	assert.True(t, AuthorizeRequest(ctx, ""))
+	assert.False(t, AuthorizeRequest(ctx, SCOPE_QUERY))
+	assert.Equal(t, fasthttp.StatusUnauthorized, ctx.Response.StatusCode())
+
+	ctx = &fasthttp.RequestCtx{}
+	ctx.Request.Header.Set("Authorization", "Bearer "+token)
+	assert.True(t, AuthorizeRequest(ctx, SCOPE_QUERY))
+	assert.NotNil(t, ctx.UserValue(API_KEY_CTX_KEY))
+
+	ctx = &fasthttp.RequestCtx{}
+	ctx.Request.Header.Set(API_KEY_HEADER, token)
+	assert.False(t, AuthorizeRequest(ctx, SCOPE_INGEST))
+	assert.Equal(t, fasthttp.StatusForbidden, ctx.Response.StatusCode())
+
+	ctx = &fasthttp.RequestCtx{}
+	ctx.Request.Header.Set(API_KEY_HEADER, "slk_unknown")
+	assert.False(t, AuthorizeRequest(ctx, SCOPE_QUERY))
+	assert.Equal(t, fasthttp.StatusUnauthorized, ctx.Response.StatusCode())
+
+	ctx = &fasthttp.RequestCtx{}
+	ctx.Request.Header.Set(API_KEY_HEADER, adminToken)
+	assert.True(t, AuthorizeRequest(ctx, SCOPE_INGEST))
+}
+
+func Test_BootstrapKey(t *testing.T) {
+	initTestApiKeys(t)
+	assert.Empty(t, ListApiKeys(0))
+
+	runningConfig := config.GetRunningConfig()
+	runningConfig.ApiKeys.Enabled = true
+	config.SetConfig(*runningConfig)
+	defer func() {
+		runningConfig.ApiKeys.Enabled = false
+		config.SetConfig(*runningConfig)
+	}()
+
+	assert.Nil(t, InitApiKeys())
+	token, err := os.ReadFile(config.GetDataPath() + "apikeys_bootstrap_token")
+	assert.Nil(t, err)
+	key, err := Authenticate(strings.TrimSpace(string(token)), utils.GetCurrentTimeInMs())
+	assert.Nil(t, err)
+	assert.True(t, key.HasScope(SCOPE_ADMIN))
+
+	// there is an admin key now, so no other one is created
+	assert.Nil(t, InitApiKeys())
+	assert.Len(t, ListApiKeys(0), 1)
+}
@@ -0,0 +1,801 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apikeys
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/siglens/siglens/pkg/blob"
+	"github.com/siglens/siglens/pkg/segment/structs"
+	segutils "github.com/siglens/siglens/pkg/segment/utils"
+	"github.com/siglens/siglens/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+/*
+Masking policies hash, partially mask or drop the sensitive fields of the indices that match their patterns in the
+query results. They apply to every request except the ones with an admin api key or with one of the exempt roles of
+the policy, so they also apply to every request while the api keys are disabled.
+*/
+
+const (
+	MASK_ACTION_HASH = "hash" // replaced by a hash, so that equal values still look equal
+	MASK_ACTION_MASK = "mask" // all but the last characters are replaced by *
+	MASK_ACTION_DROP = "drop" // the field is removed from the records
+)
+
+const (
+	MASK_MATCH_EMAIL       = "email"
+	MASK_MATCH_CARD_NUMBER = "card_number"
+	MASK_MATCH_REGEX       = "regex"
+)
+
+// Number of trailing characters a partial mask keeps
+const MASK_KEEP_CHARS = 4
+
+// Group by values can not be removed from their bucket, dropped ones are replaced by this
+const REDACTED_VALUE = "[redacted]"
+
+var emailRegex = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+var cardNumberRegex = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+
+type MaskingRule struct {
+	Fields []string `json:"fields,omitempty"` // field names where * matches any characters, no fields is every field
+	Match  string   `json:"match,omitempty"`  // only the parts of the values that match are masked, empty is the whole value
+	Regex  string   `json:"regex,omitempty"`  // pattern of the regex match
+	Action string   `json:"action"`
+
+	fieldRegexes []*regexp.Regexp
+	matchRegex   *regexp.Regexp
+}
+
+type MaskingPolicy struct {
+	Name         string         `json:"name"`
+	OrgId        uint64         `json:"orgId"`
+	IndexPattern string         `json:"indexPattern"` // index name where * matches any characters
+	Rules        []*MaskingRule `json:"rules"`
+	ExemptRoles  []string       `json:"exemptRoles,omitempty"` // api keys with one of these roles see the values as they are
+	CreatedAt    uint64         `json:"createdAt"`             // in ms
+	UpdatedAt    uint64         `json:"updatedAt,omitempty"`
+
+	indexRegex *regexp.Regexp
+}
+
+var maskingPoliciesFname string
+var maskingPoliciesLock = &sync.RWMutex{}
+
+// map of orgid/policy name => policy
+var maskingPolicies = make(map[string]*MaskingPolicy)
+
+var maskingPolicyHooksLock sync.Mutex
+var maskingPolicyHooks = make([]func(), 0)
+
+// Adds a func that is called after a masking policy is created, updated or deleted, e.g. to drop the cached results
+func AddMaskingPolicyHook(hook func()) {
+	maskingPolicyHooksLock.Lock()
+	defer maskingPolicyHooksLock.Unlock()
+	maskingPolicyHooks = append(maskingPolicyHooks, hook)
+}
+
+func callMaskingPolicyHooks() {
+	maskingPolicyHooksLock.Lock()
+	defer maskingPolicyHooksLock.Unlock()
+	for _, hook := range maskingPolicyHooks {
+		hook()
+	}
+}
+
+// Is called by InitApiKeys, once the apikeys dir exists
+func initMaskingPolicies(baseDir string) error {
+	maskingPoliciesFname = baseDir + "/masking_policies.json"
+	maskingPoliciesLock.Lock()
+	defer maskingPoliciesLock.Unlock()
+	data, err := os.ReadFile(maskingPoliciesFname)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		log.Errorf("initMaskingPolicies: failed to read file=%v, err=%v", maskingPoliciesFname, err)
+		return err
+	}
+	allPolicies := make(map[string]*MaskingPolicy)
+	err = json.Unmarshal(data, &allPolicies)
+	if err != nil {
+		log.Errorf("initMaskingPolicies: failed to unmarshal file=%v, err=%v", maskingPoliciesFname, err)
+		return err
+	}
+	for _, policy := range allPolicies {
+		err = compileMaskingPolicy(policy)
+		if err != nil {
+			log.Errorf("initMaskingPolicies: invalid masking policy %v, err=%v", policy.Name, err)
+			return err
+		}
+	}
+	maskingPolicies = allPolicies
+	return nil
+}
+
+// Needs the write lock to be held
+func writeMaskingPolicies() error {
+	data, err := json.Marshal(maskingPolicies)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(maskingPoliciesFname, data, 0644)
+	if err != nil {
+		log.Errorf("writeMaskingPolicies: failed to write file=%v, err=%v", maskingPoliciesFname, err)
+		return err
+	}
+	err = blob.UploadQueryNodeDir()
+	if err != nil {
+		log.Errorf("writeMaskingPolicies: failed to upload query nodes dir, err=%v", err)
+		return err
+	}
+	return nil
+}
+
+// Validates the policy and compiles its patterns
+func compileMaskingPolicy(policy *MaskingPolicy) error {
+	if !roleNameRegex.MatchString(policy.Name) {
+		return fmt.Errorf("invalid policy name %q, it has to start with a letter and can contain letters, digits, _, . and -", policy.Name)
+	}
+	policy.IndexPattern = strings.TrimSpace(policy.IndexPattern)
+	if policy.IndexPattern == "" {
+		return errors.New("a masking policy needs an index pattern")
+	}
+	indexRegex, err := getPatternRegex(policy.IndexPattern)
+	if err != nil {
+		return fmt.Errorf("invalid index pattern %v, err=%v", policy.IndexPattern, err)
+	}
+	policy.indexRegex = indexRegex
+
+	if len(policy.Rules) == 0 {
+		return errors.New("a masking policy needs at least one rule")
+	}
+	for _, rule := range policy.Rules {
+		if rule == nil {
+			return errors.New("a masking rule can not be null")
+		}
+		err = compileMaskingRule(rule)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func compileMaskingRule(rule *MaskingRule) error {
+	switch rule.Action {
+	case MASK_ACTION_HASH, MASK_ACTION_MASK, MASK_ACTION_DROP:
+	default:
+		return fmt.Errorf("invalid action %q, the action is %v, %v or %v", rule.Action, MASK_ACTION_HASH, MASK_ACTION_MASK, MASK_ACTION_DROP)
+	}
+	if len(rule.Fields) == 0 && rule.Match == "" {
+		return errors.New("a masking rule needs fields or a match")
+	}
+
+	rule.fieldRegexes = make([]*regexp.Regexp, 0, len(rule.Fields))
+	for _, field := range rule.Fields {
+		fieldRegex, err := getPatternRegex(strings.TrimSpace(field))
+		if err != nil {
+			return fmt.Errorf("invalid field %v, err=%v", field, err)
+		}
+		rule.fieldRegexes = append(rule.fieldRegexes, fieldRegex)
+	}
+
+	switch rule.Match {
+	case "":
+		rule.matchRegex = nil
+	case MASK_MATCH_EMAIL:
+		rule.matchRegex = emailRegex
+	case MASK_MATCH_CARD_NUMBER:
+		rule.matchRegex = cardNumberRegex
+	case MASK_MATCH_REGEX:
+		if rule.Regex == "" {
+			return errors.New("a regex match needs a regex")
+		}
+		matchRegex, err := regexp.Compile(rule.Regex)
+		if err != nil {
+			return fmt.Errorf("invalid regex %v, err=%v", rule.Regex, err)
+		}
+		rule.matchRegex = matchRegex
+	default:
+		return fmt.Errorf("invalid match %q, the match is %v, %v or %v", rule.Match, MASK_MATCH_EMAIL, MASK_MATCH_CARD_NUMBER, MASK_MATCH_REGEX)
+	}
+	return nil
+}
+
+func CreateMaskingPolicy(policy *MaskingPolicy, orgid uint64) (*MaskingPolicy, error) {
+	err := compileMaskingPolicy(policy)
+	if err != nil {
+		return nil, err
+	}
+	err = validateRoleNames(policy.ExemptRoles, orgid)
+	if err != nil {
+		return nil, err
+	}
+
+	maskingPoliciesLock.Lock()
+	defer maskingPoliciesLock.Unlock()
+	policyKey := getRoleKey(policy.Name, orgid)
+	if _, ok := maskingPolicies[policyKey]; ok {
+		return nil, fmt.Errorf("masking policy %v already exists", policy.Name)
+	}
+	policy.OrgId = orgid
+	policy.CreatedAt = utils.GetCurrentTimeInMs()
+	policy.UpdatedAt = 0
+	maskingPolicies[policyKey] = policy
+	err = writeMaskingPolicies()
+	if err != nil {
+		delete(maskingPolicies, policyKey)
+		return nil, err
+	}
+	callMaskingPolicyHooks()
+	return policy, nil
+}
+
+// Replaces the index pattern, rules and exempt roles of the policy
+func UpdateMaskingPolicy(policy *MaskingPolicy, orgid uint64) (*MaskingPolicy, error) {
+	err := compileMaskingPolicy(policy)
+	if err != nil {
+		return nil, err
+	}
+	err = validateRoleNames(policy.ExemptRoles, orgid)
+	if err != nil {
+		return nil, err
+	}
+
+	maskingPoliciesLock.Lock()
+	defer maskingPoliciesLock.Unlock()
+	policyKey := getRoleKey(policy.Name, orgid)
+	existing, ok := maskingPolicies[policyKey]
+	if !ok {
+		return nil, fmt.Errorf("masking policy %v does not exist", policy.Name)
+	}
+	policy.OrgId = orgid
+	policy.CreatedAt = existing.CreatedAt
+	policy.UpdatedAt = utils.GetCurrentTimeInMs()
+	maskingPolicies[policyKey] = policy
+	err = writeMaskingPolicies()
+	if err != nil {
+		maskingPolicies[policyKey] = existing
+		return nil, err
+	}
+	callMaskingPolicyHooks()
+	return policy, nil
+}
+
+func DeleteMaskingPolicy(name string, orgid uint64) error {
+	maskingPoliciesLock.Lock()
+	defer maskingPoliciesLock.Unlock()
+	policyKey := getRoleKey(name, orgid)
+	policy, ok := maskingPolicies[policyKey]
+	if !ok {
+		return fmt.Errorf("masking policy %v does not exist", name)
+	}
+	delete(maskingPolicies, policyKey)
+	err := writeMaskingPolicies()
+	if err != nil {
+		maskingPolicies[policyKey] = policy
+		return err
+	}
+	callMaskingPolicyHooks()
+	return nil
+}
+
+// Returns the masking policies of the org, sorted by name
+func ListMaskingPolicies(orgid uint64) []*MaskingPolicy {
+	maskingPoliciesLock.RLock()
+	defer maskingPoliciesLock.RUnlock()
+	orgPolicies := make([]*MaskingPolicy, 0)
+	for _, policy := range maskingPolicies {
+		if policy.OrgId == orgid {
+			orgPolicies = append(orgPolicies, policy)
+		}
+	}
+	sort.Slice(orgPolicies, func(i, j int) bool {
+		return orgPolicies[i].Name < orgPolicies[j].Name
+	})
+	return orgPolicies
+}
+
+func (policy *MaskingPolicy) isExempt(key *ApiKey) bool {
+	if key == nil {
+		return false
+	}
+	if key.HasScope(SCOPE_ADMIN) {
+		return true
+	}
+	for _, name := range policy.ExemptRoles {
+		if key.hasRole(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (rule *MaskingRule) appliesToField(field string) bool {
+	if len(rule.fieldRegexes) == 0 {
+		return true
+	}
+	for _, fieldRegex := range rule.fieldRegexes {
+		if fieldRegex.MatchString(field) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+Returns the masked value and false if the rule drops the field. Values the match of the rule is not found in are
+returned as they are
+*/
+func (rule *MaskingRule) apply(value interface{}) (interface{}, bool) {
+	if rule.matchRegex == nil {
+		if rule.Action == MASK_ACTION_DROP {
+			return nil, false
+		}
+		if value == nil {
+			return value, true
+		}
+		return maskString(fmt.Sprintf("%v", value), rule.Action, ""), true
+	}
+
+	strValue, ok := value.(string)
+	if !ok {
+		return value, true
+	}
+	matchesCard := rule.Match == MASK_MATCH_CARD_NUMBER
+	found := false
+	masked := rule.matchRegex.ReplaceAllStringFunc(strValue, func(match string) string {
+		if matchesCard && !isLuhnValid(match) {
+			return match
+		}
+		found = true
+		return maskString(match, rule.Action, rule.Match)
+	})
+	if found && rule.Action == MASK_ACTION_DROP {
+		return nil, false
+	}
+	return masked, true
+}
+
+func maskString(value string, action string, match string) string {
+	switch action {
+	case MASK_ACTION_HASH:
+		hash := sha256.Sum256([]byte(value))
+		return "hash:" + hex.EncodeToString(hash[:8])
+	case MASK_ACTION_MASK:
+		return partiallyMask(value, match)
+	default:
+		return REDACTED_VALUE
+	}
+}
+
+// Emails keep the first character and the domain, card numbers their last digits and separators
+func partiallyMask(value string, match string) string {
+	switch match {
+	case MASK_MATCH_EMAIL:
+		at := strings.LastIndex(value, "@")
+		if at > 0 {
+			return value[:1] + strings.Repeat("*", at-1) + value[at:]
+		}
+	case MASK_MATCH_CARD_NUMBER:
+		masked := []byte(value)
+		digitsToKeep := MASK_KEEP_CHARS
+		for i := len(masked) - 1; i >= 0; i-- {
+			if masked[i] < '0' || masked[i] > '9' {
+				continue
+			}
+			if digitsToKeep > 0 {
+				digitsToKeep--
+				continue
+			}
+			masked[i] = '*'
+		}
+		return string(masked)
+	}
+	runes := []rune(value)
+	if len(runes) <= MASK_KEEP_CHARS {
+		return strings.Repeat("*", len(runes))
+	}
+	return strings.Repeat("*", len(runes)-MASK_KEEP_CHARS) + string(runes[len(runes)-MASK_KEEP_CHARS:])
+}
+
+func isLuhnValid(cardNumber string) bool {
+	sum := 0
+	numDigits := 0
+	for i := len(cardNumber) - 1; i >= 0; i-- {
+		c := cardNumber[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		digit := int(c - '0')
+		if numDigits%2 == 1 {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		numDigits++
+	}
+	return numDigits >= 13 && sum%10 == 0
+}
+
+// Masks the query results of a request with the policies that apply to it
+type ResultMasker struct {
+	policies []*MaskingPolicy
+}
+
+/*
+Returns the masker of the policies that apply to the api key, which is nil for requests without one, and to one of
+the searched indices. Returns nil if there are none
+*/
+func GetResultMasker(key *ApiKey, indexNames []string, orgid uint64) *ResultMasker {
+	maskingPoliciesLock.RLock()
+	defer maskingPoliciesLock.RUnlock()
+	policies := make([]*MaskingPolicy, 0)
+	for _, policy := range maskingPolicies {
+		if policy.OrgId != orgid || policy.isExempt(key) {
+			continue
+		}
+		for _, indexName := range indexNames {
+			if policy.indexRegex.MatchString(indexName) {
+				policies = append(policies, policy)
+				break
+			}
+		}
+	}
+	if len(policies) == 0 {
+		return nil
+	}
+	sort.Slice(policies, func(i, j int) bool {
+		return policies[i].Name < policies[j].Name
+	})
+	return &ResultMasker{policies: policies}
+}
+
+// Masks a record of the index in place, a record of an unknown index is masked by every policy of the masker
+func (masker *ResultMasker) MaskRecord(indexName string, record map[string]interface{}) {
+	if masker == nil {
+		return
+	}
+	for _, policy := range masker.policies {
+		if indexName != "" && !policy.indexRegex.MatchString(indexName) {
+			continue
+		}
+		for field, value := range record {
+			if field == "_index" {
+				continue
+			}
+			for _, rule := range policy.Rules {
+				if !rule.appliesToField(field) {
+					continue
+				}
+				var keep bool
+				value, keep = rule.apply(value)
+				if !keep {
+					delete(record, field)
+					break
+				}
+				record[field] = value
+			}
+		}
+	}
+}
+
+// Masks the records in place, the index of each record is its _index field unless it was not selected
+func (masker *ResultMasker) MaskRecords(records []map[string]interface{}) {
+	if masker == nil {
+		return
+	}
+	for _, record := range records {
+		indexName, _ := record["_index"].(string)
+		masker.MaskRecord(indexName, record)
+	}
+}
+
+/*
+Masks the group by values and the measures of the buckets in place. The buckets do not tell which index they come from,
+so every policy of the masker applies. A measure like values(email) is masked by the rules of the field it aggregates
+*/
+func (masker *ResultMasker) MaskBuckets(buckets []*structs.BucketHolder, groupByCols []string) {
+	if masker == nil {
+		return
+	}
+	for _, bucket := range buckets {
+		for i, value := range bucket.GroupByValues {
+			if i >= len(groupByCols) {
+				break
+			}
+			masked, keep := masker.maskValue(groupByCols[i], value)
+			if !keep {
+				bucket.GroupByValues[i] = REDACTED_VALUE
+			} else if maskedStr, ok := masked.(string); ok {
+				bucket.GroupByValues[i] = maskedStr
+			}
+		}
+		for measure, value := range bucket.MeasureVal {
+			if _, ok := value.(string); !ok {
+				continue
+			}
+			masked, keep := masker.maskValue(getMeasureField(measure), value)
+			if !keep {
+				delete(bucket.MeasureVal, measure)
+			} else {
+				bucket.MeasureVal[measure] = masked
+			}
+		}
+	}
+}
+
+/*
+Masks the bucket keys and the statistics of the aggregation results in place, like MaskBuckets does for the group by
+buckets. The keys of the date histograms are timestamps, so only their statistics are masked
+*/
+func (masker *ResultMasker) MaskAggregationResults(histogram map[string]*structs.AggregationResult) {
+	if masker == nil {
+		return
+	}
+	for _, aggRes := range histogram {
+		for _, bucket := range aggRes.Results {
+			if !aggRes.IsDateHistogram {
+				bucket.BucketKey = masker.maskBucketKey(bucket.BucketKey, bucket.GroupByKeys)
+			}
+			for name, value := range bucket.StatRes {
+				if _, ok := value.CVal.(string); !ok {
+					continue
+				}
+				masked, keep := masker.maskValue(getMeasureField(name), value.CVal)
+				if !keep {
+					delete(bucket.StatRes, name)
+				} else {
+					value.CVal = masked
+					bucket.StatRes[name] = value
+				}
+			}
+		}
+	}
+}
+
+/*
+Returns an error if the query derives a field from a masked field, with rename, eval, rex, lookup or an aggregation,
+under a name that the rules of the masked field do not apply to. The results are masked by the names of their fields,
+so the derived field would be returned as it is
+*/
+func (masker *ResultMasker) CheckDerivedFields(aggs *structs.QueryAggregators) error {
+	if masker == nil {
+		return nil
+	}
+	for agg := aggs; agg != nil; agg = agg.Next {
+		err := masker.checkMeasures(agg.MeasureOperations)
+		if err != nil {
+			return err
+		}
+		if agg.GroupByRequest != nil {
+			err = masker.checkMeasures(agg.GroupByRequest.MeasureOperations)
+			if err != nil {
+				return err
+			}
+		}
+		if agg.OutputTransforms == nil {
+			continue
+		}
+		err = masker.checkColumnsRequest(agg.OutputTransforms.OutputColumns)
+		if err != nil {
+			return err
+		}
+		err = masker.checkLetColumns(agg.OutputTransforms.LetColumns)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (masker *ResultMasker) checkMeasures(measures []*structs.MeasureAggregator) error {
+	for _, measure := range measures {
+		// counts do not return the values of the field
+		if measure.MeasureFunc == segutils.Count || measure.MeasureFunc == segutils.Cardinality {
+			continue
+		}
+		measureField := getMeasureField(measure.String())
+		err := masker.checkDerivedField(measure.MeasureCol, measureField)
+		if err != nil {
+			return err
+		}
+		if measure.ValueColRequest != nil {
+			err = masker.checkDerivedFields(measure.ValueColRequest.GetFields(), measureField)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (masker *ResultMasker) checkColumnsRequest(colReq *structs.ColumnsRequest) error {
+	if colReq == nil {
+		return nil
+	}
+	for oldCName, newCName := range colReq.RenameColumns {
+		err := masker.checkDerivedField(oldCName, newCName)
+		if err != nil {
+			return err
+		}
+	}
+	for measure, newCName := range colReq.RenameAggregationColumns {
+		err := masker.checkDerivedField(getMeasureField(measure), newCName)
+		if err != nil {
+			return err
+		}
+	}
+	for _, includeValue := range colReq.IncludeValues {
+		err := masker.checkDerivedField(includeValue.ColName, includeValue.Label)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (masker *ResultMasker) checkLetColumns(letReq *structs.LetColumnsRequest) error {
+	if letReq == nil {
+		return nil
+	}
+	switch {
+	case letReq.MultiColsRequest != nil:
+		return masker.checkDerivedFields([]string{letReq.MultiColsRequest.LeftCName, letReq.MultiColsRequest.RightCName},
+			letReq.NewColName)
+	case letReq.SingleColRequest != nil:
+		return masker.checkDerivedField(letReq.SingleColRequest.CName, letReq.NewColName)
+	case letReq.ValueColRequest != nil:
+		return masker.checkDerivedFields(letReq.ValueColRequest.GetFields(), letReq.NewColName)
+	case letReq.RexColRequest != nil:
+		for _, rexColName := range letReq.RexColRequest.RexColNames {
+			err := masker.checkDerivedField(letReq.RexColRequest.FieldName, rexColName)
+			if err != nil {
+				return err
+			}
+		}
+	case letReq.RenameColRequest != nil:
+		renameReq := letReq.RenameColRequest
+		if renameReq.RenameExprMode != structs.REMRegex {
+			return masker.checkDerivedField(renameReq.OriginalPattern, renameReq.NewPattern)
+		}
+		// the renamed fields are only known once the records are read
+		if masker.hasFieldRules() {
+			return fmt.Errorf("fields can not be renamed with a wildcard while fields of the index are masked")
+		}
+	case letReq.LookupColRequest != nil:
+		lookupReq := letReq.LookupColRequest
+		// without output fields every column of the lookup table is added
+		if len(lookupReq.OutputFields) == 0 && masker.checkDerivedField(lookupReq.EventField, "") != nil {
+			return fmt.Errorf("field %v is masked, it can not be used to look up every column of lookup %v",
+				lookupReq.EventField, lookupReq.LookupName)
+		}
+		for _, outputField := range lookupReq.OutputFields {
+			err := masker.checkDerivedField(lookupReq.EventField, outputField)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (masker *ResultMasker) checkDerivedFields(fields []string, newField string) error {
+	for _, field := range fields {
+		err := masker.checkDerivedField(field, newField)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (masker *ResultMasker) checkDerivedField(field string, newField string) error {
+	if field == "" || field == newField {
+		return nil
+	}
+	for _, policy := range masker.policies {
+		for _, rule := range policy.Rules {
+			if rule.appliesToField(field) && !rule.appliesToField(newField) {
+				return fmt.Errorf("field %v is masked, it can not be used to derive field %v", field, newField)
+			}
+		}
+	}
+	return nil
+}
+
+// Returns true if a rule of the masker only applies to some fields
+func (masker *ResultMasker) hasFieldRules() bool {
+	for _, policy := range masker.policies {
+		for _, rule := range policy.Rules {
+			if len(rule.fieldRegexes) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// The key of a bucket is the value of its only group by column, or the values of all of them
+func (masker *ResultMasker) maskBucketKey(bucketKey interface{}, groupByCols []string) interface{} {
+	switch key := bucketKey.(type) {
+	case string:
+		if len(groupByCols) != 1 {
+			return key
+		}
+		masked, keep := masker.maskValue(groupByCols[0], key)
+		if !keep {
+			return REDACTED_VALUE
+		}
+		return masked
+	case []string:
+		for i, value := range key {
+			if i >= len(groupByCols) {
+				break
+			}
+			masked, keep := masker.maskValue(groupByCols[i], value)
+			if !keep {
+				key[i] = REDACTED_VALUE
+			} else if maskedStr, ok := masked.(string); ok {
+				key[i] = maskedStr
+			}
+		}
+		return key
+	default:
+		return bucketKey
+	}
+}
+
+func (masker *ResultMasker) maskValue(field string, value interface{}) (interface{}, bool) {
+	for _, policy := range masker.policies {
+		for _, rule := range policy.Rules {
+			if !rule.appliesToField(field) {
+				continue
+			}
+			var keep bool
+			value, keep = rule.apply(value)
+			if !keep {
+				return nil, false
+			}
+		}
+	}
+	return value, true
+}
+
+// Returns the field inside a measure name like values(email), or the name itself
+func getMeasureField(measure string) string {
+	start := strings.Index(measure, "(")
+	end := strings.LastIndex(measure, ")")
+	if start < 0 || end <= start {
+		return measure
+	}
+	return measure[start+1 : end]
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apikeys
+
+import (
+	"encoding/json"
+
+	"github.com/siglens/siglens/pkg/utils"
+	log "github.com/sirupsen/logrus"
+	"github.com/valyala/fasthttp"
+)
+
+func readMaskingPolicyRequest(ctx *fasthttp.RequestCtx, fnName string) (*MaskingPolicy, bool) {
+	policy := &MaskingPolicy{}
+	err := json.Unmarshal(ctx.PostBody(), policy)
+	if err != nil {
+		log.Errorf("%v: could not unmarshal the request, err=%v", fnName, err)
+		setErrMsg(ctx, fasthttp.StatusBadRequest, "Bad Request")
+		return nil, false
+	}
+	return policy, true
+}
+
+/*
+Handles /api/masking/create, the body is {"name": "pii", "indexPattern": "web-*", "exemptRoles": ["security"],
+"rules": [{"fields": ["user.email"], "action": "hash"}, {"match": "card_number", "action": "mask"}]}
+*/
+func ProcessCreateMaskingPolicyRequest(ctx *fasthttp.RequestCtx, myid uint64) {
+	request, ok := readMaskingPolicyRequest(ctx, "ProcessCreateMaskingPolicyRequest")
+	if !ok {
+		return
+	}
+	policy, err := CreateMaskingPolicy(request, myid)
+	if err != nil {
+		log.Errorf("ProcessCreateMaskingPolicyRequest: could not create masking policy %v, err=%v", request.Name, err)
+		setErrMsg(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	utils.WriteJsonResponse(ctx, policy)
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
+
+// Handles /api/masking/update, the body is the same as for /api/masking/create
+func ProcessUpdateMaskingPolicyRequest(ctx *fasthttp.RequestCtx, myid uint64) {
+	request, ok := readMaskingPolicyRequest(ctx, "ProcessUpdateMaskingPolicyRequest")
+	if !ok {
+		return
+	}
+	policy, err := UpdateMaskingPolicy(request, myid)
+	if err != nil {
+		log.Errorf("ProcessUpdateMaskingPolicyRequest: could not update masking policy %v, err=%v", request.Name, err)
+		setErrMsg(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	utils.WriteJsonResponse(ctx, policy)
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
+
+// Handles /api/masking/listall
+func ProcessListMaskingPoliciesRequest(ctx *fasthttp.RequestCtx, myid uint64) {
+	utils.WriteJsonResponse(ctx, ListMaskingPolicies(myid))
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
+
+// Handles /api/masking/{policy-name}
+func ProcessDeleteMaskingPolicyRequest(ctx *fasthttp.RequestCtx, myid uint64) {
+	name := utils.ExtractParamAsString(ctx.UserValue("policy-name"))
+	err := DeleteMaskingPolicy(name, myid)
+	if err != nil {
+		log.Errorf("ProcessDeleteMaskingPolicyRequest: could not delete masking policy %v, err=%v", name, err)
+		setErrMsg(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	utils.WriteJsonResponse(ctx, "Masking policy deleted successfully")
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
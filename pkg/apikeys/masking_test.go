@@ -0,0 +1,253 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apikeys
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/siglens/siglens/pkg/segment/structs"
+	segutils "github.com/siglens/siglens/pkg/segment/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_MaskingPolicyValidation(t *testing.T) {
+	initTestApiKeys(t)
+	numChanges := 0
+	AddMaskingPolicyHook(func() { numChanges++ })
+
+	rule := &MaskingRule{Fields: []string{"email"}, Action: MASK_ACTION_HASH}
+	_, err := CreateMaskingPolicy(&MaskingPolicy{Name: "pii", IndexPattern: "web-*", Rules: []*MaskingRule{rule}}, 0)
+	assert.Nil(t, err)
+	_, err = CreateMaskingPolicy(&MaskingPolicy{Name: "pii", IndexPattern: "web-*", Rules: []*MaskingRule{rule}}, 0)
+	assert.NotNil(t, err)
+
+	invalidPolicies := []*MaskingPolicy{
+		{Name: "1pii", IndexPattern: "web-*", Rules: []*MaskingRule{rule}},
+		{Name: "nopattern", Rules: []*MaskingRule{rule}},
+		{Name: "norules", IndexPattern: "web-*"},
+		{Name: "badaction", IndexPattern: "web-*", Rules: []*MaskingRule{{Fields: []string{"email"}, Action: "erase"}}},
+		{Name: "nofields", IndexPattern: "web-*", Rules: []*MaskingRule{{Action: MASK_ACTION_DROP}}},
+		{Name: "badmatch", IndexPattern: "web-*", Rules: []*MaskingRule{{Match: "phone", Action: MASK_ACTION_MASK}}},
+		{Name: "noregex", IndexPattern: "web-*", Rules: []*MaskingRule{{Match: MASK_MATCH_REGEX, Action: MASK_ACTION_MASK}}},
+		{Name: "badregex", IndexPattern: "web-*", Rules: []*MaskingRule{{Match: MASK_MATCH_REGEX, Regex: "(", Action: MASK_ACTION_MASK}}},
+		{Name: "badrole", IndexPattern: "web-*", Rules: []*MaskingRule{rule}, ExemptRoles: []string{"missing"}},
+	}
+	for _, policy := range invalidPolicies {
+		_, err = CreateMaskingPolicy(policy, 0)
+		assert.NotNil(t, err, policy.Name)
+	}
+
+	_, err = UpdateMaskingPolicy(&MaskingPolicy{Name: "missing", IndexPattern: "web-*", Rules: []*MaskingRule{rule}}, 0)
+	assert.NotNil(t, err)
+	_, err = UpdateMaskingPolicy(&MaskingPolicy{Name: "pii", IndexPattern: "app-*", Rules: []*MaskingRule{rule}}, 0)
+	assert.Nil(t, err)
+	assert.Len(t, ListMaskingPolicies(0), 1)
+	assert.Len(t, ListMaskingPolicies(1), 0)
+
+	// the policies are read back from the file
+	maskingPolicies = make(map[string]*MaskingPolicy)
+	assert.Nil(t, initMaskingPolicies(filepath.Dir(maskingPoliciesFname)))
+	assert.Len(t, ListMaskingPolicies(0), 1)
+
+	assert.Nil(t, DeleteMaskingPolicy("pii", 0))
+	assert.NotNil(t, DeleteMaskingPolicy("pii", 0))
+	// only the successful create, update and delete are reported
+	assert.Equal(t, 3, numChanges)
+}
+
+func Test_MaskRecord(t *testing.T) {
+	initTestApiKeys(t)
+
+	_, err := CreateRole("security", []*IndexPermission{{IndexPattern: "*", Access: ACCESS_READ}}, 0)
+	assert.Nil(t, err)
+	_, err = CreateMaskingPolicy(&MaskingPolicy{
+		Name:         "pii",
+		IndexPattern: "web-*",
+		ExemptRoles:  []string{"security"},
+		Rules: []*MaskingRule{
+			{Fields: []string{"user.email"}, Match: MASK_MATCH_EMAIL, Action: MASK_ACTION_MASK},
+			{Fields: []string{"user.id"}, Action: MASK_ACTION_HASH},
+			{Fields: []string{"password*"}, Action: MASK_ACTION_DROP},
+			{Match: MASK_MATCH_CARD_NUMBER, Action: MASK_ACTION_MASK},
+			{Fields: []string{"ssn"}, Match: MASK_MATCH_REGEX, Regex: `\d{3}-\d{2}-\d{4}`, Action: MASK_ACTION_HASH},
+		},
+	}, 0)
+	assert.Nil(t, err)
+
+	masker := GetResultMasker(nil, []string{"web-logs"}, 0)
+	assert.NotNil(t, masker)
+	record := map[string]interface{}{
+		"_index":        "web-logs",
+		"user.email":    "alice@example.com",
+		"user.id":       "u-123",
+		"password_hash": "secret",
+		"message":       "paid with 4111 1111 1111 1111, order 1234 5678 9012 3456",
+		"ssn":           "ssn is 123-45-6789",
+		"status":        float64(200),
+	}
+	masker.MaskRecords([]map[string]interface{}{record})
+	assert.Equal(t, "a****@example.com", record["user.email"])
+	assert.Equal(t, maskString("u-123", MASK_ACTION_HASH, ""), record["user.id"])
+	assert.NotContains(t, record, "password_hash")
+	// only the numbers that pass the luhn check are card numbers
+	assert.Equal(t, "paid with **** **** **** 1111, order 1234 5678 9012 3456", record["message"])
+	assert.Equal(t, "ssn is "+maskString("123-45-6789", MASK_ACTION_HASH, MASK_MATCH_REGEX), record["ssn"])
+	assert.Equal(t, float64(200), record["status"])
+	assert.Equal(t, "web-logs", record["_index"])
+
+	// the records of other indices are not masked
+	record = map[string]interface{}{"_index": "app-logs", "user.id": "u-123"}
+	masker.MaskRecords([]map[string]interface{}{record})
+	assert.Equal(t, "u-123", record["user.id"])
+	assert.Nil(t, GetResultMasker(nil, []string{"app-logs"}, 0))
+	// the records without an _index field could be from any searched index
+	record = map[string]interface{}{"user.id": "u-123"}
+	masker.MaskRecords([]map[string]interface{}{record})
+	assert.Equal(t, maskString("u-123", MASK_ACTION_HASH, ""), record["user.id"])
+	assert.Nil(t, GetResultMasker(nil, []string{"web-logs"}, 1))
+
+	// admin keys and the exempt roles see the values as they are
+	_, info, err := CreateApiKey("admin", []string{SCOPE_ADMIN}, nil, 0)
+	assert.Nil(t, err)
+	assert.Nil(t, GetResultMasker(apiKeys[info.Id], []string{"web-logs"}, 0))
+	_, info, err = CreateApiKey("security", []string{SCOPE_QUERY}, []string{"security"}, 0)
+	assert.Nil(t, err)
+	assert.Nil(t, GetResultMasker(apiKeys[info.Id], []string{"web-logs"}, 0))
+	_, info, err = CreateApiKey("viewer", []string{SCOPE_QUERY}, nil, 0)
+	assert.Nil(t, err)
+	assert.NotNil(t, GetResultMasker(apiKeys[info.Id], []string{"web-logs"}, 0))
+
+	// the role can not be deleted while a policy exempts it
+	assert.NotNil(t, DeleteRole("security", 0))
+}
+
+func Test_MaskBuckets(t *testing.T) {
+	initTestApiKeys(t)
+
+	_, err := CreateMaskingPolicy(&MaskingPolicy{
+		Name:         "pii",
+		IndexPattern: "*",
+		Rules: []*MaskingRule{
+			{Fields: []string{"email"}, Action: MASK_ACTION_HASH},
+			{Fields: []string{"secret"}, Action: MASK_ACTION_DROP},
+		},
+	}, 0)
+	assert.Nil(t, err)
+
+	buckets := []*structs.BucketHolder{
+		{
+			GroupByValues: []string{"alice@example.com", "s3cr3t", "web"},
+			MeasureVal: map[string]interface{}{
+				"count(*)":       uint64(3),
+				"values(email)":  "alice@example.com",
+				"values(secret)": "s3cr3t",
+			},
+		},
+	}
+	GetResultMasker(nil, []string{"web-logs"}, 0).MaskBuckets(buckets, []string{"email", "secret", "app"})
+	hashed := maskString("alice@example.com", MASK_ACTION_HASH, "")
+	assert.Equal(t, []string{hashed, REDACTED_VALUE, "web"}, buckets[0].GroupByValues)
+	assert.Equal(t, map[string]interface{}{"count(*)": uint64(3), "values(email)": hashed}, buckets[0].MeasureVal)
+
+	histogram := map[string]*structs.AggregationResult{
+		"by_email": {Results: []*structs.BucketResult{
+			{
+				BucketKey:   "alice@example.com",
+				GroupByKeys: []string{"email"},
+				StatRes: map[string]segutils.CValueEnclosure{
+					"count(*)":       {Dtype: segutils.SS_DT_UNSIGNED_NUM, CVal: uint64(3)},
+					"values(secret)": {Dtype: segutils.SS_DT_STRING, CVal: "s3cr3t"},
+				},
+			},
+		}},
+		"by_secret_and_app": {Results: []*structs.BucketResult{
+			{BucketKey: []string{"s3cr3t", "web"}, GroupByKeys: []string{"secret", "app"}},
+		}},
+	}
+	GetResultMasker(nil, []string{"web-logs"}, 0).MaskAggregationResults(histogram)
+	emailBucket := histogram["by_email"].Results[0]
+	assert.Equal(t, hashed, emailBucket.BucketKey)
+	assert.Equal(t, map[string]segutils.CValueEnclosure{
+		"count(*)": {Dtype: segutils.SS_DT_UNSIGNED_NUM, CVal: uint64(3)},
+	}, emailBucket.StatRes)
+	assert.Equal(t, []string{REDACTED_VALUE, "web"}, histogram["by_secret_and_app"].Results[0].BucketKey)
+
+	// a nil masker leaves the results as they are
+	var masker *ResultMasker
+	masker.MaskBuckets(buckets, []string{"email", "secret", "app"})
+	masker.MaskAggregationResults(histogram)
+	masker.MaskRecord("web-logs", map[string]interface{}{"email": "bob@example.com"})
+}
+
+func Test_CheckDerivedFields(t *testing.T) {
+	initTestApiKeys(t)
+
+	_, err := CreateMaskingPolicy(&MaskingPolicy{
+		Name:         "pii",
+		IndexPattern: "web-*",
+		Rules: []*MaskingRule{
+			{Fields: []string{"*email*"}, Action: MASK_ACTION_HASH},
+			{Fields: []string{"secret"}, Action: MASK_ACTION_DROP},
+		},
+	}, 0)
+	assert.Nil(t, err)
+	masker := GetResultMasker(nil, []string{"web-logs"}, 0)
+
+	renameAgg := func(renameExpr *structs.RenameExpr) *structs.QueryAggregators {
+		return &structs.QueryAggregators{OutputTransforms: &structs.OutputTransforms{
+			LetColumns: &structs.LetColumnsRequest{RenameColRequest: renameExpr},
+		}}
+	}
+	evalAgg := &structs.QueryAggregators{OutputTransforms: &structs.OutputTransforms{
+		LetColumns: &structs.LetColumnsRequest{
+			SingleColRequest: &structs.SingleColLetRequest{CName: "secret"},
+			NewColName:       "x",
+		},
+	}}
+	statsAgg := &structs.QueryAggregators{
+		GroupByRequest: &structs.GroupByRequest{
+			GroupByColumns:    []string{"secret"},
+			MeasureOperations: []*structs.MeasureAggregator{{MeasureCol: "secret", MeasureFunc: segutils.Count}},
+		},
+		Next: &structs.QueryAggregators{OutputTransforms: &structs.OutputTransforms{
+			OutputColumns: &structs.ColumnsRequest{RenameColumns: map[string]string{"secret": "x"}},
+		}},
+	}
+	valuesAgg := &structs.QueryAggregators{
+		MeasureOperations: []*structs.MeasureAggregator{{MeasureCol: "email", MeasureFunc: segutils.Values, StrEnc: "x"}},
+	}
+
+	assert.NotNil(t, masker.CheckDerivedFields(renameAgg(&structs.RenameExpr{RenameExprMode: structs.REMPhrase,
+		OriginalPattern: "secret", NewPattern: "x"})))
+	assert.NotNil(t, masker.CheckDerivedFields(renameAgg(&structs.RenameExpr{RenameExprMode: structs.REMRegex,
+		OriginalPattern: "sec*", NewPattern: "x*"})))
+	assert.NotNil(t, masker.CheckDerivedFields(evalAgg))
+	assert.NotNil(t, masker.CheckDerivedFields(statsAgg))
+	assert.NotNil(t, masker.CheckDerivedFields(valuesAgg))
+
+	// the rules of the masked field still apply to the new name, and counts do not return the values
+	assert.Nil(t, masker.CheckDerivedFields(renameAgg(&structs.RenameExpr{RenameExprMode: structs.REMPhrase,
+		OriginalPattern: "email", NewPattern: "user_email"})))
+	assert.Nil(t, masker.CheckDerivedFields(renameAgg(&structs.RenameExpr{RenameExprMode: structs.REMPhrase,
+		OriginalPattern: "app", NewPattern: "x"})))
+	statsAgg.Next = nil
+	assert.Nil(t, masker.CheckDerivedFields(statsAgg))
+
+	// the policy does not apply to other indices
+	assert.Nil(t, GetResultMasker(nil, []string{"app-logs"}, 0).CheckDerivedFields(evalAgg))
+}
@@ -0,0 +1,307 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apikeys
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/siglens/siglens/pkg/blob"
+	"github.com/siglens/siglens/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+/*
+Roles grant access to the indices that match their patterns, so that the teams of an org can share a deployment
+without seeing each other's logs. An api key with roles can only read and write the indices its roles grant, an api
+key without roles can access every index of its org.
+*/
+
+const (
+	ACCESS_READ  = "read"
+	ACCESS_WRITE = "write"
+	ACCESS_ADMIN = "admin" // implies read and write
+)
+
+var roleNameRegex = regexp.MustCompile(`^[A-Za-z][\w.-]*$`)
+
+type IndexPermission struct {
+	IndexPattern string `json:"indexPattern"` // index name where * matches any characters
+	Access       string `json:"access"`
+	patternRegex *regexp.Regexp
+}
+
+type Role struct {
+	Name        string             `json:"name"`
+	OrgId       uint64             `json:"orgId"`
+	Permissions []*IndexPermission `json:"permissions"`
+	CreatedAt   uint64             `json:"createdAt"` // in ms
+	UpdatedAt   uint64             `json:"updatedAt,omitempty"`
+}
+
+var rolesFname string
+var rolesLock = &sync.RWMutex{}
+
+// map of orgid/role name => role
+var roles = make(map[string]*Role)
+
+func getRoleKey(name string, orgid uint64) string {
+	return fmt.Sprintf("%v/%v", orgid, name)
+}
+
+// Is called by InitApiKeys, once the apikeys dir exists
+func initRoles(baseDir string) error {
+	rolesFname = baseDir + "/roles.json"
+	rolesLock.Lock()
+	defer rolesLock.Unlock()
+	data, err := os.ReadFile(rolesFname)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		log.Errorf("initRoles: failed to read file=%v, err=%v", rolesFname, err)
+		return err
+	}
+	allRoles := make(map[string]*Role)
+	err = json.Unmarshal(data, &allRoles)
+	if err != nil {
+		log.Errorf("initRoles: failed to unmarshal file=%v, err=%v", rolesFname, err)
+		return err
+	}
+	for _, role := range allRoles {
+		err = compilePermissions(role.Permissions)
+		if err != nil {
+			log.Errorf("initRoles: invalid permissions in role %v, err=%v", role.Name, err)
+			return err
+		}
+	}
+	roles = allRoles
+	return nil
+}
+
+// Needs the write lock to be held
+func writeRoles() error {
+	data, err := json.Marshal(roles)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(rolesFname, data, 0644)
+	if err != nil {
+		log.Errorf("writeRoles: failed to write file=%v, err=%v", rolesFname, err)
+		return err
+	}
+	err = blob.UploadQueryNodeDir()
+	if err != nil {
+		log.Errorf("writeRoles: failed to upload query nodes dir, err=%v", err)
+		return err
+	}
+	return nil
+}
+
+func getPatternRegex(pattern string) (*regexp.Regexp, error) {
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return regexp.Compile("^" + strings.Join(parts, ".*") + "$")
+}
+
+// Validates the permissions and compiles their patterns
+func compilePermissions(permissions []*IndexPermission) error {
+	if len(permissions) == 0 {
+		return errors.New("a role needs at least one permission")
+	}
+	for _, permission := range permissions {
+		if permission == nil {
+			return errors.New("a permission can not be null")
+		}
+		permission.IndexPattern = strings.TrimSpace(permission.IndexPattern)
+		if permission.IndexPattern == "" {
+			return errors.New("a permission needs an index pattern")
+		}
+		switch permission.Access {
+		case ACCESS_READ, ACCESS_WRITE, ACCESS_ADMIN:
+		default:
+			return fmt.Errorf("invalid access %q, the access is %v, %v or %v", permission.Access, ACCESS_READ, ACCESS_WRITE, ACCESS_ADMIN)
+		}
+		patternRegex, err := getPatternRegex(permission.IndexPattern)
+		if err != nil {
+			return fmt.Errorf("invalid index pattern %v, err=%v", permission.IndexPattern, err)
+		}
+		permission.patternRegex = patternRegex
+	}
+	return nil
+}
+
+func (permission *IndexPermission) grants(indexName string, access string) bool {
+	if permission.Access != access && permission.Access != ACCESS_ADMIN {
+		return false
+	}
+	return permission.patternRegex.MatchString(indexName)
+}
+
+func CreateRole(name string, permissions []*IndexPermission, orgid uint64) (*Role, error) {
+	if !roleNameRegex.MatchString(name) {
+		return nil, fmt.Errorf("invalid role name %q, it has to start with a letter and can contain letters, digits, _, . and -", name)
+	}
+	err := compilePermissions(permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	rolesLock.Lock()
+	defer rolesLock.Unlock()
+	roleKey := getRoleKey(name, orgid)
+	if _, ok := roles[roleKey]; ok {
+		return nil, fmt.Errorf("role %v already exists", name)
+	}
+	role := &Role{
+		Name:        name,
+		OrgId:       orgid,
+		Permissions: permissions,
+		CreatedAt:   utils.GetCurrentTimeInMs(),
+	}
+	roles[roleKey] = role
+	err = writeRoles()
+	if err != nil {
+		delete(roles, roleKey)
+		return nil, err
+	}
+	return role, nil
+}
+
+// Replaces the permissions of the role, the keys that have it get the new permissions right away
+func UpdateRole(name string, permissions []*IndexPermission, orgid uint64) (*Role, error) {
+	err := compilePermissions(permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	rolesLock.Lock()
+	defer rolesLock.Unlock()
+	roleKey := getRoleKey(name, orgid)
+	role, ok := roles[roleKey]
+	if !ok {
+		return nil, fmt.Errorf("role %v does not exist", name)
+	}
+	updated := *role
+	updated.Permissions = permissions
+	updated.UpdatedAt = utils.GetCurrentTimeInMs()
+	roles[roleKey] = &updated
+	err = writeRoles()
+	if err != nil {
+		roles[roleKey] = role
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// A role can only be deleted once no active api key has it and no masking policy exempts it
+func DeleteRole(name string, orgid uint64) error {
+	apiKeysLock.RLock()
+	defer apiKeysLock.RUnlock()
+	for _, key := range apiKeys {
+		if key.RevokedAt == 0 && key.OrgId == orgid && key.hasRole(name) {
+			return fmt.Errorf("role %v is used by api key %v", name, key.Id)
+		}
+	}
+	maskingPoliciesLock.RLock()
+	defer maskingPoliciesLock.RUnlock()
+	for _, policy := range maskingPolicies {
+		if policy.OrgId == orgid && utils.SliceContainsString(policy.ExemptRoles, name) {
+			return fmt.Errorf("role %v is exempt from masking policy %v", name, policy.Name)
+		}
+	}
+
+	rolesLock.Lock()
+	defer rolesLock.Unlock()
+	roleKey := getRoleKey(name, orgid)
+	role, ok := roles[roleKey]
+	if !ok {
+		return fmt.Errorf("role %v does not exist", name)
+	}
+	delete(roles, roleKey)
+	err := writeRoles()
+	if err != nil {
+		roles[roleKey] = role
+		return err
+	}
+	return nil
+}
+
+// Returns the roles of the org, sorted by name
+func ListRoles(orgid uint64) []*Role {
+	rolesLock.RLock()
+	defer rolesLock.RUnlock()
+	orgRoles := make([]*Role, 0)
+	for _, role := range roles {
+		if role.OrgId == orgid {
+			orgRoles = append(orgRoles, role)
+		}
+	}
+	sort.Slice(orgRoles, func(i, j int) bool {
+		return orgRoles[i].Name < orgRoles[j].Name
+	})
+	return orgRoles
+}
+
+func validateRoleNames(roleNames []string, orgid uint64) error {
+	rolesLock.RLock()
+	defer rolesLock.RUnlock()
+	for _, name := range roleNames {
+		if _, ok := roles[getRoleKey(name, orgid)]; !ok {
+			return fmt.Errorf("role %v does not exist", name)
+		}
+	}
+	return nil
+}
+
+func (key *ApiKey) hasRole(name string) bool {
+	for _, keyRole := range key.Roles {
+		if keyRole == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Keys without roles can access every index, roles that have been deleted grant nothing
+func (key *ApiKey) CanAccessIndex(indexName string, access string) bool {
+	if len(key.Roles) == 0 {
+		return true
+	}
+	rolesLock.RLock()
+	defer rolesLock.RUnlock()
+	for _, name := range key.Roles {
+		role, ok := roles[getRoleKey(name, key.OrgId)]
+		if !ok {
+			continue
+		}
+		for _, permission := range role.Permissions {
+			if permission.grants(indexName, access) {
+				return true
+			}
+		}
+	}
+	return false
+}
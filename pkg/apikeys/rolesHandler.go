@@ -0,0 +1,138 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apikeys
+
+import (
+	"encoding/json"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/siglens/siglens/pkg/utils"
+	vtable "github.com/siglens/siglens/pkg/virtualtable"
+	log "github.com/sirupsen/logrus"
+)
+
+type roleRequest struct {
+	Name        string             `json:"name"`
+	Permissions []*IndexPermission `json:"permissions"`
+}
+
+// Returns the api key the request was authorized with, or nil
+func GetRequestApiKey(ctx *fasthttp.RequestCtx) *ApiKey {
+	if ctx == nil {
+		return nil
+	}
+	key, ok := ctx.UserValue(API_KEY_CTX_KEY).(*ApiKey)
+	if !ok {
+		return nil
+	}
+	return key
+}
+
+/*
+Returns a func that tells whether the api key of the request can access an index, or nil when every index can be
+accessed, which is when the request has no api key or its key has no roles
+*/
+func GetIndexFilter(ctx *fasthttp.RequestCtx, access string) func(indexName string) bool {
+	key := GetRequestApiKey(ctx)
+	if key == nil || len(key.Roles) == 0 {
+		return nil
+	}
+	return func(indexName string) bool {
+		return key.CanAccessIndex(indexName, access)
+	}
+}
+
+// Returns false and responds with a 403 if the api key of the request does not have the access to the index
+func AuthorizeIndexAccess(ctx *fasthttp.RequestCtx, indexName string, access string) bool {
+	indexFilter := GetIndexFilter(ctx, access)
+	if indexFilter == nil {
+		return true
+	}
+	// the roles grant access to the indices, not to the aliases that point to them
+	realIndexName := indexName
+	if pres, idxName := vtable.IsAlias(indexName, GetRequestApiKey(ctx).OrgId); pres {
+		realIndexName = idxName
+	}
+	if indexFilter(realIndexName) {
+		return true
+	}
+	setErrMsg(ctx, fasthttp.StatusForbidden, "Forbidden: the api key does not have "+access+" access to index "+indexName)
+	return false
+}
+
+func readRoleRequest(ctx *fasthttp.RequestCtx, fnName string) (*roleRequest, bool) {
+	request := &roleRequest{}
+	err := json.Unmarshal(ctx.PostBody(), request)
+	if err != nil {
+		log.Errorf("%v: could not unmarshal the request, err=%v", fnName, err)
+		setErrMsg(ctx, fasthttp.StatusBadRequest, "Bad Request")
+		return nil, false
+	}
+	return request, true
+}
+
+// Handles /api/roles/create, the body is {"name": "team-a", "permissions": [{"indexPattern": "team-a-*", "access": "read"}]}
+func ProcessCreateRoleRequest(ctx *fasthttp.RequestCtx, myid uint64) {
+	request, ok := readRoleRequest(ctx, "ProcessCreateRoleRequest")
+	if !ok {
+		return
+	}
+	role, err := CreateRole(request.Name, request.Permissions, myid)
+	if err != nil {
+		log.Errorf("ProcessCreateRoleRequest: could not create role %v, err=%v", request.Name, err)
+		setErrMsg(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	utils.WriteJsonResponse(ctx, role)
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
+
+// Handles /api/roles/update, the body is the same as for /api/roles/create
+func ProcessUpdateRoleRequest(ctx *fasthttp.RequestCtx, myid uint64) {
+	request, ok := readRoleRequest(ctx, "ProcessUpdateRoleRequest")
+	if !ok {
+		return
+	}
+	role, err := UpdateRole(request.Name, request.Permissions, myid)
+	if err != nil {
+		log.Errorf("ProcessUpdateRoleRequest: could not update role %v, err=%v", request.Name, err)
+		setErrMsg(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	utils.WriteJsonResponse(ctx, role)
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
+
+// Handles /api/roles/listall
+func ProcessListRolesRequest(ctx *fasthttp.RequestCtx, myid uint64) {
+	utils.WriteJsonResponse(ctx, ListRoles(myid))
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
+
+// Handles /api/roles/{role-name}
+func ProcessDeleteRoleRequest(ctx *fasthttp.RequestCtx, myid uint64) {
+	name := utils.ExtractParamAsString(ctx.UserValue("role-name"))
+	err := DeleteRole(name, myid)
+	if err != nil {
+		log.Errorf("ProcessDeleteRoleRequest: could not delete role %v, err=%v", name, err)
+		setErrMsg(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	utils.WriteJsonResponse(ctx, "Role deleted successfully")
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
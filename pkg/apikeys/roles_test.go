@@ -0,0 +1,121 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apikeys
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func Test_RoleLifecycle(t *testing.T) {
+	initTestApiKeys(t)
+
+	_, err := CreateRole("team-a", []*IndexPermission{
+		{IndexPattern: "team-a-*", Access: ACCESS_READ},
+		{IndexPattern: "team-a-ingest", Access: ACCESS_WRITE},
+	}, 0)
+	assert.Nil(t, err)
+	_, err = CreateRole("team-a", []*IndexPermission{{IndexPattern: "*", Access: ACCESS_READ}}, 0)
+	assert.NotNil(t, err)
+	// the role names are per org
+	_, err = CreateRole("team-a", []*IndexPermission{{IndexPattern: "*", Access: ACCESS_ADMIN}}, 1)
+	assert.Nil(t, err)
+
+	_, info, err := CreateApiKey("team-a-agent", []string{SCOPE_QUERY, SCOPE_INGEST}, []string{"team-a"}, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"team-a"}, info.Roles)
+	key := apiKeys[info.Id]
+	assert.True(t, key.CanAccessIndex("team-a-logs", ACCESS_READ))
+	assert.False(t, key.CanAccessIndex("team-a-logs", ACCESS_WRITE))
+	assert.False(t, key.CanAccessIndex("team-b-logs", ACCESS_READ))
+	assert.False(t, key.CanAccessIndex("xteam-a-logs", ACCESS_READ))
+	assert.True(t, key.CanAccessIndex("team-a-ingest", ACCESS_WRITE))
+
+	_, err = UpdateRole("team-a", []*IndexPermission{{IndexPattern: "team-*", Access: ACCESS_ADMIN}}, 0)
+	assert.Nil(t, err)
+	assert.True(t, key.CanAccessIndex("team-b-logs", ACCESS_READ))
+	assert.True(t, key.CanAccessIndex("team-b-logs", ACCESS_WRITE))
+
+	// the role is in use
+	assert.NotNil(t, DeleteRole("team-a", 0))
+	_, err = SetApiKeyRoles(info.Id, nil, 0)
+	assert.Nil(t, err)
+	assert.True(t, apiKeys[info.Id].CanAccessIndex("anything", ACCESS_WRITE))
+	assert.Nil(t, DeleteRole("team-a", 0))
+	assert.NotNil(t, DeleteRole("team-a", 0))
+	assert.Len(t, ListRoles(0), 0)
+	assert.Len(t, ListRoles(1), 1)
+
+	// the roles are read back from the file
+	roles = make(map[string]*Role)
+	assert.Nil(t, InitApiKeys())
+	assert.Len(t, ListRoles(1), 1)
+	assert.True(t, ListRoles(1)[0].Permissions[0].grants("team-b-logs", ACCESS_READ))
+}
+
+func Test_RoleValidation(t *testing.T) {
+	initTestApiKeys(t)
+
+	_, err := CreateRole("", []*IndexPermission{{IndexPattern: "*", Access: ACCESS_READ}}, 0)
+	assert.NotNil(t, err)
+	_, err = CreateRole("team a", []*IndexPermission{{IndexPattern: "*", Access: ACCESS_READ}}, 0)
+	assert.NotNil(t, err)
+	_, err = CreateRole("team-a", []*IndexPermission{}, 0)
+	assert.NotNil(t, err)
+	_, err = CreateRole("team-a", []*IndexPermission{{IndexPattern: " ", Access: ACCESS_READ}}, 0)
+	assert.NotNil(t, err)
+	_, err = CreateRole("team-a", []*IndexPermission{{IndexPattern: "*", Access: "delete"}}, 0)
+	assert.NotNil(t, err)
+	_, err = UpdateRole("missing", []*IndexPermission{{IndexPattern: "*", Access: ACCESS_READ}}, 0)
+	assert.NotNil(t, err)
+
+	_, _, err = CreateApiKey("agent", []string{SCOPE_INGEST}, []string{"missing"}, 0)
+	assert.NotNil(t, err)
+	_, info, err := CreateApiKey("agent", []string{SCOPE_INGEST}, nil, 0)
+	assert.Nil(t, err)
+	_, err = SetApiKeyRoles(info.Id, []string{"missing"}, 0)
+	assert.NotNil(t, err)
+
+	// a literal pattern only matches its index
+	patternRegex, err := getPatternRegex("logs.v1")
+	assert.Nil(t, err)
+	assert.True(t, patternRegex.MatchString("logs.v1"))
+	assert.False(t, patternRegex.MatchString("logsxv1"))
+}
+
+func Test_GetIndexFilter(t *testing.T) {
+	initTestApiKeys(t)
+
+	ctx := &fasthttp.RequestCtx{}
+	assert.Nil(t, GetIndexFilter(ctx, ACCESS_READ))
+	assert.True(t, AuthorizeIndexAccess(ctx, "team-b-logs", ACCESS_WRITE))
+
+	_, err := CreateRole("team-a", []*IndexPermission{{IndexPattern: "team-a-*", Access: ACCESS_WRITE}}, 0)
+	assert.Nil(t, err)
+	_, info, err := CreateApiKey("team-a-agent", []string{SCOPE_INGEST}, []string{"team-a"}, 0)
+	assert.Nil(t, err)
+	ctx.SetUserValue(API_KEY_CTX_KEY, apiKeys[info.Id])
+
+	readFilter := GetIndexFilter(ctx, ACCESS_READ)
+	assert.NotNil(t, readFilter)
+	assert.False(t, readFilter("team-a-logs"))
+	assert.True(t, AuthorizeIndexAccess(ctx, "team-a-logs", ACCESS_WRITE))
+	assert.False(t, AuthorizeIndexAccess(ctx, "team-b-logs", ACCESS_WRITE))
+	assert.Equal(t, fasthttp.StatusForbidden, ctx.Response.StatusCode())
+}
@@ -20,10 +20,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"regexp"
 	"strings"
 
 	dtu "github.com/siglens/siglens/pkg/common/dtypeutils"
+	"github.com/siglens/siglens/pkg/config"
 	"github.com/siglens/siglens/pkg/es/query"
 	rutils "github.com/siglens/siglens/pkg/readerUtils"
 	"github.com/siglens/siglens/pkg/segment"
@@ -121,7 +123,7 @@ func ProcessSingleFilter(colName string, colValue interface{}, compOpr string, v
 		}
 	case GrepValue:
 		cleanedColVal := strings.ReplaceAll(strings.TrimSpace(t.Field), "\"", "")
-		criteria := CreateTermFilterCriteria("*", cleanedColVal, opr, qid)
+		criteria := CreateTermFilterCriteria("*", regexp.MustCompile(regexp.QuoteMeta(cleanedColVal)), opr, qid)
 		andFilterCondition = append(andFilterCondition, criteria)
 	default:
 		log.Errorf("ProcessSingleFilter: Invalid colValue type %v", t)
@@ -130,6 +132,38 @@ func ProcessSingleFilter(colName string, colValue interface{}, compOpr string, v
 	return andFilterCondition, nil
 }
 
+// Has the LogQL line filters of the node, the regex filters on all the columns, search the log lines of the Loki index
+func TargetLokiLineFilters(node *ASTNode) {
+	if node == nil {
+		return
+	}
+	for _, condition := range []*Condition{node.AndFilterCondition, node.OrFilterCondition, node.ExclusionFilterCondition} {
+		if condition == nil {
+			continue
+		}
+		for _, criteria := range condition.FilterCriteria {
+			if criteria.ExpressionFilter == nil || criteria.ExpressionFilter.LeftInput == nil ||
+				criteria.ExpressionFilter.RightInput == nil {
+				continue
+			}
+			left := criteria.ExpressionFilter.LeftInput.Expression
+			right := criteria.ExpressionFilter.RightInput.Expression
+			if left == nil || left.LeftInput == nil || left.LeftInput.ColumnName != "*" ||
+				right == nil || right.LeftInput == nil || right.LeftInput.ColumnValue == nil {
+				continue
+			}
+			// the match all filter has a wildcard regex
+			colValue := right.LeftInput.ColumnValue
+			if colValue.GetRegexp() != nil && colValue.StringVal != "*" {
+				left.LeftInput.ColumnName = LogLineColumn
+			}
+		}
+		for _, nestedNode := range condition.NestedNodes {
+			TargetLokiLineFilters(nestedNode)
+		}
+	}
+}
+
 func createMatchPhraseFilterCriteria(k, v interface{}, opr LogicalOperator, negateMatch bool, qid uint64) *FilterCriteria {
 	//match_phrase value will always be string
 	var rtInput = strings.TrimSpace(v.(string))
@@ -241,3 +275,128 @@ func ParseTimeRange(startEpoch, endEpoch uint64, aggs *QueryAggregators, qid uin
 	tRange.EndEpochMs = endEpoch
 	return tRange, nil
 }
+
+/*
+Narrows the time range to the comparisons of the timestamp column that every record has to pass, like
+timestamp>=1700000000000 in the search text, and removes them from the node. These comparisons only need the time
+range of a record, so applying them to the time range of the query lets the segments and blocks outside of it be
+skipped using their time ranges
+*/
+func NarrowTimeRangeByTimestampFilters(node *ASTNode, tRange *dtu.TimeRange, qid uint64) {
+	if node == nil || node.AndFilterCondition == nil || tRange == nil {
+		return
+	}
+	andCondition := node.AndFilterCondition
+	remainingCriteria := make([]*FilterCriteria, 0, len(andCondition.FilterCriteria))
+	for _, criteria := range andCondition.FilterCriteria {
+		if criteria.ExpressionFilter == nil {
+			remainingCriteria = append(remainingCriteria, criteria)
+			continue
+		}
+		numVal, op, ok := getTimestampComparison(criteria.ExpressionFilter)
+		if !ok {
+			remainingCriteria = append(remainingCriteria, criteria)
+			continue
+		}
+		narrowTimeRange(tRange, op, numVal)
+	}
+	if len(remainingCriteria) == 0 && len(andCondition.NestedNodes) == 0 && len(andCondition.FilterCriteria) > 0 {
+		// the records in the time range match
+		remainingCriteria = append(remainingCriteria, CreateTermFilterCriteria("*", "*", Equals, qid))
+	}
+	andCondition.FilterCriteria = remainingCriteria
+	for _, nestedNode := range andCondition.NestedNodes {
+		NarrowTimeRangeByTimestampFilters(nestedNode, tRange, qid)
+	}
+}
+
+// Returns the value the timestamp column is compared to and the operator, with the timestamp column on the left
+func getTimestampComparison(filter *ExpressionFilter) (float64, FilterOperator, bool) {
+	if filter.LeftInput == nil || filter.RightInput == nil {
+		return 0, filter.FilterOperator, false
+	}
+	leftCol, leftVal, ok := getSimpleExpressionInput(filter.LeftInput)
+	if !ok {
+		return 0, filter.FilterOperator, false
+	}
+	rightCol, rightVal, ok := getSimpleExpressionInput(filter.RightInput)
+	if !ok {
+		return 0, filter.FilterOperator, false
+	}
+
+	tsKey := config.GetTimeStampKey()
+	if leftCol == tsKey && rightVal != nil {
+		numVal, ok := getNumericValue(rightVal)
+		return numVal, filter.FilterOperator, ok
+	} else if rightCol == tsKey && leftVal != nil {
+		numVal, ok := getNumericValue(leftVal)
+		return numVal, ReflectFilterOperator[filter.FilterOperator], ok
+	}
+	return 0, filter.FilterOperator, false
+}
+
+// Returns the column name or the literal of a filter input that is not a subtree or an arithmetic expression
+func getSimpleExpressionInput(input *FilterInput) (string, *DtypeEnclosure, bool) {
+	if input.SubTree != nil || input.Expression == nil || input.Expression.RightInput != nil ||
+		input.Expression.LeftInput == nil {
+		return "", nil, false
+	}
+	return input.Expression.LeftInput.ColumnName, input.Expression.LeftInput.ColumnValue, true
+}
+
+func getNumericValue(dte *DtypeEnclosure) (float64, bool) {
+	switch dte.Dtype {
+	case SS_DT_UNSIGNED_NUM:
+		return float64(dte.UnsignedVal), true
+	case SS_DT_SIGNED_NUM:
+		return float64(dte.SignedVal), true
+	case SS_DT_FLOAT:
+		return dte.FloatVal, true
+	default:
+		return 0, false
+	}
+}
+
+// Intersects the time range with the timestamps t for which "t op val" holds. An empty result has a start after its end
+func narrowTimeRange(tRange *dtu.TimeRange, op FilterOperator, val float64) {
+	lowest, highest := math.Inf(-1), math.Inf(1)
+	switch op {
+	case Equals:
+		if val != math.Trunc(val) {
+			lowest, highest = math.Inf(1), math.Inf(-1)
+		} else {
+			lowest, highest = val, val
+		}
+	case GreaterThan:
+		lowest = math.Floor(val) + 1
+	case GreaterThanOrEqualTo:
+		lowest = math.Ceil(val)
+	case LessThan:
+		highest = math.Ceil(val) - 1
+	case LessThanOrEqualTo:
+		highest = math.Floor(val)
+	default:
+		return
+	}
+
+	if lowest > highest || highest < 0 || lowest > float64(math.MaxUint64) {
+		setEmptyTimeRange(tRange)
+		return
+	}
+	if lowest > float64(tRange.StartEpochMs) {
+		tRange.StartEpochMs = uint64(lowest)
+	}
+	if highest < float64(tRange.EndEpochMs) {
+		tRange.EndEpochMs = uint64(highest)
+	}
+	if tRange.StartEpochMs > tRange.EndEpochMs {
+		setEmptyTimeRange(tRange)
+	}
+}
+
+func setEmptyTimeRange(tRange *dtu.TimeRange) {
+	if tRange.EndEpochMs == math.MaxUint64 {
+		tRange.EndEpochMs--
+	}
+	tRange.StartEpochMs = tRange.EndEpochMs + 1
+}
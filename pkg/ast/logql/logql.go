@@ -57,94 +57,113 @@ func getParseError(err error) error {
 	return err
 }
 
+// Regex values are quoted LogQL strings, in which the backslashes of the regex are escaped
+func getRegexPattern(value interface{}) (string, error) {
+	pattern, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("regex %v is not a string", value)
+	}
+	if strings.HasPrefix(pattern, "\"") {
+		unquoted, err := strconv.Unquote(pattern)
+		if err != nil {
+			return "", fmt.Errorf("invalid regex %v, err=%v", pattern, err)
+		}
+		pattern = unquoted
+	}
+	if _, err := regexp.Compile(pattern); err != nil {
+		return "", fmt.Errorf("invalid regex %v, err=%v", pattern, err)
+	}
+	return pattern, nil
+}
+
 var g = &grammar{
 	rules: []*rule{
 		{
 			name: "Start",
-			pos:  position{line: 35, col: 1, offset: 630},
+			pos:  position{line: 54, col: 1, offset: 1287},
 			expr: &choiceExpr{
-				pos: position{line: 35, col: 10, offset: 639},
+				pos: position{line: 54, col: 10, offset: 1296},
 				alternatives: []interface{}{
 					&actionExpr{
-						pos: position{line: 35, col: 10, offset: 639},
+						pos: position{line: 54, col: 10, offset: 1296},
 						run: (*parser).callonStart2,
 						expr: &seqExpr{
-							pos: position{line: 35, col: 10, offset: 639},
+							pos: position{line: 54, col: 10, offset: 1296},
 							exprs: []interface{}{
 								&labeledExpr{
-									pos:   position{line: 35, col: 10, offset: 639},
+									pos:   position{line: 54, col: 10, offset: 1296},
 									label: "query",
 									expr: &zeroOrOneExpr{
-										pos: position{line: 35, col: 16, offset: 645},
+										pos: position{line: 54, col: 16, offset: 1302},
 										expr: &ruleRefExpr{
-											pos:  position{line: 35, col: 16, offset: 645},
+											pos:  position{line: 54, col: 16, offset: 1302},
 											name: "Stream",
 										},
 									},
 								},
 								&labeledExpr{
-									pos:   position{line: 35, col: 24, offset: 653},
+									pos:   position{line: 54, col: 24, offset: 1310},
 									label: "jf",
 									expr: &zeroOrOneExpr{
-										pos: position{line: 35, col: 27, offset: 656},
+										pos: position{line: 54, col: 27, offset: 1313},
 										expr: &ruleRefExpr{
-											pos:  position{line: 35, col: 27, offset: 656},
+											pos:  position{line: 54, col: 27, offset: 1313},
 											name: "JSONFilter",
 										},
 									},
 								},
 								&labeledExpr{
-									pos:   position{line: 35, col: 39, offset: 668},
+									pos:   position{line: 54, col: 39, offset: 1325},
 									label: "fs",
 									expr: &zeroOrOneExpr{
-										pos: position{line: 35, col: 42, offset: 671},
+										pos: position{line: 54, col: 42, offset: 1328},
 										expr: &ruleRefExpr{
-											pos:  position{line: 35, col: 42, offset: 671},
+											pos:  position{line: 54, col: 42, offset: 1328},
 											name: "FilterStream",
 										},
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 35, col: 56, offset: 685},
+									pos:  position{line: 54, col: 56, offset: 1342},
 									name: "EOF",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 62, col: 5, offset: 1337},
+						pos: position{line: 81, col: 5, offset: 1994},
 						run: (*parser).callonStart14,
 						expr: &seqExpr{
-							pos: position{line: 62, col: 5, offset: 1337},
+							pos: position{line: 81, col: 5, offset: 1994},
 							exprs: []interface{}{
 								&ruleRefExpr{
-									pos:  position{line: 62, col: 5, offset: 1337},
+									pos:  position{line: 81, col: 5, offset: 1994},
 									name: "COUNT_OVER_TIME",
 								},
 								&litMatcher{
-									pos:        position{line: 62, col: 21, offset: 1353},
+									pos:        position{line: 81, col: 21, offset: 2010},
 									val:        "(",
 									ignoreCase: false,
 									want:       "\"(\"",
 								},
 								&labeledExpr{
-									pos:   position{line: 62, col: 25, offset: 1357},
+									pos:   position{line: 81, col: 25, offset: 2014},
 									label: "query",
 									expr: &ruleRefExpr{
-										pos:  position{line: 62, col: 31, offset: 1363},
+										pos:  position{line: 81, col: 31, offset: 2020},
 										name: "Stream",
 									},
 								},
 								&labeledExpr{
-									pos:   position{line: 62, col: 38, offset: 1370},
+									pos:   position{line: 81, col: 38, offset: 2027},
 									label: "duration",
 									expr: &ruleRefExpr{
-										pos:  position{line: 62, col: 47, offset: 1379},
+										pos:  position{line: 81, col: 47, offset: 2036},
 										name: "Duration",
 									},
 								},
 								&litMatcher{
-									pos:        position{line: 62, col: 56, offset: 1388},
+									pos:        position{line: 81, col: 56, offset: 2045},
 									val:        ")",
 									ignoreCase: false,
 									want:       "\")\"",
@@ -157,44 +176,44 @@ var g = &grammar{
 		},
 		{
 			name: "Stream",
-			pos:  position{line: 75, col: 1, offset: 1940},
+			pos:  position{line: 94, col: 1, offset: 2597},
 			expr: &actionExpr{
-				pos: position{line: 75, col: 11, offset: 1950},
+				pos: position{line: 94, col: 11, offset: 2607},
 				run: (*parser).callonStream1,
 				expr: &seqExpr{
-					pos: position{line: 75, col: 11, offset: 1950},
+					pos: position{line: 94, col: 11, offset: 2607},
 					exprs: []interface{}{
 						&ruleRefExpr{
-							pos:  position{line: 75, col: 11, offset: 1950},
+							pos:  position{line: 94, col: 11, offset: 2607},
 							name: "Delimiter",
 						},
 						&labeledExpr{
-							pos:   position{line: 75, col: 21, offset: 1960},
+							pos:   position{line: 94, col: 21, offset: 2617},
 							label: "q1",
 							expr: &ruleRefExpr{
-								pos:  position{line: 75, col: 24, offset: 1963},
-								name: "Query",
+								pos:  position{line: 94, col: 24, offset: 2620},
+								name: "StreamMatcher",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 75, col: 30, offset: 1969},
+							pos:   position{line: 94, col: 38, offset: 2634},
 							label: "rest",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 75, col: 35, offset: 1974},
+								pos: position{line: 94, col: 43, offset: 2639},
 								expr: &ruleRefExpr{
-									pos:  position{line: 75, col: 36, offset: 1975},
-									name: "Query",
+									pos:  position{line: 94, col: 44, offset: 2640},
+									name: "StreamMatcher",
 								},
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 75, col: 44, offset: 1983},
+							pos:  position{line: 94, col: 60, offset: 2656},
 							name: "Delimiter",
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 75, col: 54, offset: 1993},
+							pos: position{line: 94, col: 70, offset: 2666},
 							expr: &ruleRefExpr{
-								pos:  position{line: 75, col: 54, offset: 1993},
+								pos:  position{line: 94, col: 70, offset: 2666},
 								name: "space",
 							},
 						},
@@ -204,27 +223,27 @@ var g = &grammar{
 		},
 		{
 			name: "FilterStream",
-			pos:  position{line: 104, col: 1, offset: 2685},
+			pos:  position{line: 123, col: 1, offset: 3358},
 			expr: &actionExpr{
-				pos: position{line: 104, col: 17, offset: 2701},
+				pos: position{line: 123, col: 17, offset: 3374},
 				run: (*parser).callonFilterStream1,
 				expr: &seqExpr{
-					pos: position{line: 104, col: 17, offset: 2701},
+					pos: position{line: 123, col: 17, offset: 3374},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 104, col: 17, offset: 2701},
+							pos:   position{line: 123, col: 17, offset: 3374},
 							label: "rest",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 104, col: 22, offset: 2706},
+								pos: position{line: 123, col: 22, offset: 3379},
 								expr: &choiceExpr{
-									pos: position{line: 104, col: 23, offset: 2707},
+									pos: position{line: 123, col: 23, offset: 3380},
 									alternatives: []interface{}{
 										&ruleRefExpr{
-											pos:  position{line: 104, col: 23, offset: 2707},
+											pos:  position{line: 123, col: 23, offset: 3380},
 											name: "LogFilter",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 104, col: 35, offset: 2719},
+											pos:  position{line: 123, col: 35, offset: 3392},
 											name: "LabelFilter",
 										},
 									},
@@ -232,9 +251,9 @@ var g = &grammar{
 							},
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 104, col: 49, offset: 2733},
+							pos: position{line: 123, col: 49, offset: 3406},
 							expr: &ruleRefExpr{
-								pos:  position{line: 104, col: 49, offset: 2733},
+								pos:  position{line: 123, col: 49, offset: 3406},
 								name: "space",
 							},
 						},
@@ -244,62 +263,129 @@ var g = &grammar{
 		},
 		{
 			name: "Query",
-			pos:  position{line: 134, col: 1, offset: 3460},
+			pos:  position{line: 153, col: 1, offset: 4133},
 			expr: &actionExpr{
-				pos: position{line: 134, col: 11, offset: 3470},
+				pos: position{line: 153, col: 11, offset: 4143},
 				run: (*parser).callonQuery1,
 				expr: &seqExpr{
-					pos: position{line: 134, col: 11, offset: 3470},
+					pos: position{line: 153, col: 11, offset: 4143},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 134, col: 11, offset: 3470},
+							pos:   position{line: 153, col: 11, offset: 4143},
 							label: "field",
 							expr: &ruleRefExpr{
-								pos:  position{line: 134, col: 17, offset: 3476},
+								pos:  position{line: 153, col: 17, offset: 4149},
 								name: "Field",
 							},
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 134, col: 23, offset: 3482},
+							pos: position{line: 153, col: 23, offset: 4155},
 							expr: &ruleRefExpr{
-								pos:  position{line: 134, col: 23, offset: 3482},
+								pos:  position{line: 153, col: 23, offset: 4155},
 								name: "space",
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 134, col: 30, offset: 3489},
+							pos:        position{line: 153, col: 30, offset: 4162},
 							val:        "=",
 							ignoreCase: false,
 							want:       "\"=\"",
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 134, col: 34, offset: 3493},
+							pos: position{line: 153, col: 34, offset: 4166},
+							expr: &ruleRefExpr{
+								pos:  position{line: 153, col: 34, offset: 4166},
+								name: "space",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 153, col: 41, offset: 4173},
+							label: "field1",
+							expr: &ruleRefExpr{
+								pos:  position{line: 153, col: 48, offset: 4180},
+								name: "Field",
+							},
+						},
+						&zeroOrOneExpr{
+							pos: position{line: 153, col: 54, offset: 4186},
+							expr: &litMatcher{
+								pos:        position{line: 153, col: 54, offset: 4186},
+								val:        ",",
+								ignoreCase: false,
+								want:       "\",\"",
+							},
+						},
+						&zeroOrOneExpr{
+							pos: position{line: 153, col: 59, offset: 4191},
+							expr: &ruleRefExpr{
+								pos:  position{line: 153, col: 59, offset: 4191},
+								name: "space",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "StreamMatcher",
+			pos:  position{line: 165, col: 1, offset: 4481},
+			expr: &actionExpr{
+				pos: position{line: 165, col: 18, offset: 4498},
+				run: (*parser).callonStreamMatcher1,
+				expr: &seqExpr{
+					pos: position{line: 165, col: 18, offset: 4498},
+					exprs: []interface{}{
+						&labeledExpr{
+							pos:   position{line: 165, col: 18, offset: 4498},
+							label: "field",
+							expr: &ruleRefExpr{
+								pos:  position{line: 165, col: 24, offset: 4504},
+								name: "LabelName",
+							},
+						},
+						&zeroOrOneExpr{
+							pos: position{line: 165, col: 34, offset: 4514},
+							expr: &ruleRefExpr{
+								pos:  position{line: 165, col: 34, offset: 4514},
+								name: "space",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 165, col: 41, offset: 4521},
+							label: "op",
+							expr: &ruleRefExpr{
+								pos:  position{line: 165, col: 44, offset: 4524},
+								name: "MatchOp",
+							},
+						},
+						&zeroOrOneExpr{
+							pos: position{line: 165, col: 52, offset: 4532},
 							expr: &ruleRefExpr{
-								pos:  position{line: 134, col: 34, offset: 3493},
+								pos:  position{line: 165, col: 52, offset: 4532},
 								name: "space",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 134, col: 41, offset: 3500},
+							pos:   position{line: 165, col: 59, offset: 4539},
 							label: "field1",
 							expr: &ruleRefExpr{
-								pos:  position{line: 134, col: 48, offset: 3507},
+								pos:  position{line: 165, col: 66, offset: 4546},
 								name: "Field",
 							},
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 134, col: 54, offset: 3513},
+							pos: position{line: 165, col: 72, offset: 4552},
 							expr: &litMatcher{
-								pos:        position{line: 134, col: 54, offset: 3513},
+								pos:        position{line: 165, col: 72, offset: 4552},
 								val:        ",",
 								ignoreCase: false,
 								want:       "\",\"",
 							},
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 134, col: 59, offset: 3518},
+							pos: position{line: 165, col: 77, offset: 4557},
 							expr: &ruleRefExpr{
-								pos:  position{line: 134, col: 59, offset: 3518},
+								pos:  position{line: 165, col: 77, offset: 4557},
 								name: "space",
 							},
 						},
@@ -307,43 +393,80 @@ var g = &grammar{
 				},
 			},
 		},
+		{
+			name: "MatchOp",
+			pos:  position{line: 189, col: 1, offset: 5176},
+			expr: &actionExpr{
+				pos: position{line: 189, col: 12, offset: 5187},
+				run: (*parser).callonMatchOp1,
+				expr: &choiceExpr{
+					pos: position{line: 189, col: 13, offset: 5188},
+					alternatives: []interface{}{
+						&litMatcher{
+							pos:        position{line: 189, col: 13, offset: 5188},
+							val:        "=~",
+							ignoreCase: false,
+							want:       "\"=~\"",
+						},
+						&litMatcher{
+							pos:        position{line: 189, col: 20, offset: 5195},
+							val:        "!~",
+							ignoreCase: false,
+							want:       "\"!~\"",
+						},
+						&litMatcher{
+							pos:        position{line: 189, col: 27, offset: 5202},
+							val:        "!=",
+							ignoreCase: false,
+							want:       "\"!=\"",
+						},
+						&litMatcher{
+							pos:        position{line: 189, col: 34, offset: 5209},
+							val:        "=",
+							ignoreCase: false,
+							want:       "\"=\"",
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "SingleField",
-			pos:  position{line: 145, col: 1, offset: 3729},
+			pos:  position{line: 193, col: 1, offset: 5250},
 			expr: &actionExpr{
-				pos: position{line: 145, col: 17, offset: 3745},
+				pos: position{line: 193, col: 17, offset: 5266},
 				run: (*parser).callonSingleField1,
 				expr: &seqExpr{
-					pos: position{line: 145, col: 17, offset: 3745},
+					pos: position{line: 193, col: 17, offset: 5266},
 					exprs: []interface{}{
 						&zeroOrOneExpr{
-							pos: position{line: 145, col: 17, offset: 3745},
+							pos: position{line: 193, col: 17, offset: 5266},
 							expr: &litMatcher{
-								pos:        position{line: 145, col: 17, offset: 3745},
+								pos:        position{line: 193, col: 17, offset: 5266},
 								val:        ",",
 								ignoreCase: false,
 								want:       "\",\"",
 							},
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 145, col: 22, offset: 3750},
+							pos: position{line: 193, col: 22, offset: 5271},
 							expr: &ruleRefExpr{
-								pos:  position{line: 145, col: 22, offset: 3750},
+								pos:  position{line: 193, col: 22, offset: 5271},
 								name: "space",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 145, col: 29, offset: 3757},
+							pos:   position{line: 193, col: 29, offset: 5278},
 							label: "field",
 							expr: &ruleRefExpr{
-								pos:  position{line: 145, col: 35, offset: 3763},
+								pos:  position{line: 193, col: 35, offset: 5284},
 								name: "Field",
 							},
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 145, col: 41, offset: 3769},
+							pos: position{line: 193, col: 41, offset: 5290},
 							expr: &litMatcher{
-								pos:        position{line: 145, col: 41, offset: 3769},
+								pos:        position{line: 193, col: 41, offset: 5290},
 								val:        ",",
 								ignoreCase: false,
 								want:       "\",\"",
@@ -355,47 +478,47 @@ var g = &grammar{
 		},
 		{
 			name: "LogFilter",
-			pos:  position{line: 155, col: 1, offset: 3956},
+			pos:  position{line: 203, col: 1, offset: 5477},
 			expr: &actionExpr{
-				pos: position{line: 155, col: 14, offset: 3969},
+				pos: position{line: 203, col: 14, offset: 5490},
 				run: (*parser).callonLogFilter1,
 				expr: &seqExpr{
-					pos: position{line: 155, col: 14, offset: 3969},
+					pos: position{line: 203, col: 14, offset: 5490},
 					exprs: []interface{}{
 						&zeroOrOneExpr{
-							pos: position{line: 155, col: 14, offset: 3969},
+							pos: position{line: 203, col: 14, offset: 5490},
 							expr: &ruleRefExpr{
-								pos:  position{line: 155, col: 14, offset: 3969},
+								pos:  position{line: 203, col: 14, offset: 5490},
 								name: "space",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 155, col: 21, offset: 3976},
+							pos:   position{line: 203, col: 21, offset: 5497},
 							label: "grep",
 							expr: &ruleRefExpr{
-								pos:  position{line: 155, col: 26, offset: 3981},
+								pos:  position{line: 203, col: 26, offset: 5502},
 								name: "GrepFilter",
 							},
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 155, col: 37, offset: 3992},
+							pos: position{line: 203, col: 37, offset: 5513},
 							expr: &ruleRefExpr{
-								pos:  position{line: 155, col: 37, offset: 3992},
+								pos:  position{line: 203, col: 37, offset: 5513},
 								name: "space",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 155, col: 44, offset: 3999},
+							pos:   position{line: 203, col: 44, offset: 5520},
 							label: "field",
 							expr: &ruleRefExpr{
-								pos:  position{line: 155, col: 50, offset: 4005},
+								pos:  position{line: 203, col: 50, offset: 5526},
 								name: "Field",
 							},
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 155, col: 56, offset: 4011},
+							pos: position{line: 203, col: 56, offset: 5532},
 							expr: &ruleRefExpr{
-								pos:  position{line: 155, col: 56, offset: 4011},
+								pos:  position{line: 203, col: 56, offset: 5532},
 								name: "space",
 							},
 						},
@@ -405,84 +528,84 @@ var g = &grammar{
 		},
 		{
 			name: "LabelFilter",
-			pos:  position{line: 166, col: 1, offset: 4228},
+			pos:  position{line: 232, col: 1, offset: 6231},
 			expr: &actionExpr{
-				pos: position{line: 166, col: 16, offset: 4243},
+				pos: position{line: 232, col: 16, offset: 6246},
 				run: (*parser).callonLabelFilter1,
 				expr: &seqExpr{
-					pos: position{line: 166, col: 16, offset: 4243},
+					pos: position{line: 232, col: 16, offset: 6246},
 					exprs: []interface{}{
 						&zeroOrOneExpr{
-							pos: position{line: 166, col: 16, offset: 4243},
+							pos: position{line: 232, col: 16, offset: 6246},
 							expr: &ruleRefExpr{
-								pos:  position{line: 166, col: 16, offset: 4243},
+								pos:  position{line: 232, col: 16, offset: 6246},
 								name: "space",
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 166, col: 23, offset: 4250},
+							pos:        position{line: 232, col: 23, offset: 6253},
 							val:        "|",
 							ignoreCase: false,
 							want:       "\"|\"",
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 166, col: 27, offset: 4254},
+							pos: position{line: 232, col: 27, offset: 6257},
 							expr: &ruleRefExpr{
-								pos:  position{line: 166, col: 27, offset: 4254},
+								pos:  position{line: 232, col: 27, offset: 6257},
 								name: "space",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 166, col: 34, offset: 4261},
+							pos:   position{line: 232, col: 34, offset: 6264},
 							label: "field",
 							expr: &ruleRefExpr{
-								pos:  position{line: 166, col: 40, offset: 4267},
+								pos:  position{line: 232, col: 40, offset: 6270},
 								name: "Field",
 							},
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 166, col: 46, offset: 4273},
+							pos: position{line: 232, col: 46, offset: 6276},
 							expr: &ruleRefExpr{
-								pos:  position{line: 166, col: 46, offset: 4273},
+								pos:  position{line: 232, col: 46, offset: 6276},
 								name: "space",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 166, col: 53, offset: 4280},
+							pos:   position{line: 232, col: 53, offset: 6283},
 							label: "op",
 							expr: &ruleRefExpr{
-								pos:  position{line: 166, col: 56, offset: 4283},
+								pos:  position{line: 232, col: 56, offset: 6286},
 								name: "opCOMP",
 							},
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 166, col: 63, offset: 4290},
+							pos: position{line: 232, col: 63, offset: 6293},
 							expr: &ruleRefExpr{
-								pos:  position{line: 166, col: 63, offset: 4290},
+								pos:  position{line: 232, col: 63, offset: 6293},
 								name: "space",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 166, col: 70, offset: 4297},
+							pos:   position{line: 232, col: 70, offset: 6300},
 							label: "field1",
 							expr: &ruleRefExpr{
-								pos:  position{line: 166, col: 77, offset: 4304},
+								pos:  position{line: 232, col: 77, offset: 6307},
 								name: "Field",
 							},
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 166, col: 83, offset: 4310},
+							pos: position{line: 232, col: 83, offset: 6313},
 							expr: &litMatcher{
-								pos:        position{line: 166, col: 83, offset: 4310},
+								pos:        position{line: 232, col: 83, offset: 6313},
 								val:        ",",
 								ignoreCase: false,
 								want:       "\",\"",
 							},
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 166, col: 88, offset: 4315},
+							pos: position{line: 232, col: 88, offset: 6318},
 							expr: &ruleRefExpr{
-								pos:  position{line: 166, col: 88, offset: 4315},
+								pos:  position{line: 232, col: 88, offset: 6318},
 								name: "space",
 							},
 						},
@@ -492,53 +615,53 @@ var g = &grammar{
 		},
 		{
 			name: "JSONFilter",
-			pos:  position{line: 177, col: 1, offset: 4533},
+			pos:  position{line: 243, col: 1, offset: 6536},
 			expr: &choiceExpr{
-				pos: position{line: 177, col: 15, offset: 4547},
+				pos: position{line: 243, col: 15, offset: 6550},
 				alternatives: []interface{}{
 					&actionExpr{
-						pos: position{line: 177, col: 15, offset: 4547},
+						pos: position{line: 243, col: 15, offset: 6550},
 						run: (*parser).callonJSONFilter2,
 						expr: &seqExpr{
-							pos: position{line: 177, col: 15, offset: 4547},
+							pos: position{line: 243, col: 15, offset: 6550},
 							exprs: []interface{}{
 								&zeroOrOneExpr{
-									pos: position{line: 177, col: 15, offset: 4547},
+									pos: position{line: 243, col: 15, offset: 6550},
 									expr: &ruleRefExpr{
-										pos:  position{line: 177, col: 15, offset: 4547},
+										pos:  position{line: 243, col: 15, offset: 6550},
 										name: "space",
 									},
 								},
 								&litMatcher{
-									pos:        position{line: 177, col: 22, offset: 4554},
+									pos:        position{line: 243, col: 22, offset: 6557},
 									val:        "|",
 									ignoreCase: false,
 									want:       "\"|\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 177, col: 26, offset: 4558},
+									pos:  position{line: 243, col: 26, offset: 6561},
 									name: "space",
 								},
 								&litMatcher{
-									pos:        position{line: 177, col: 32, offset: 4564},
+									pos:        position{line: 243, col: 32, offset: 6567},
 									val:        "json",
 									ignoreCase: false,
 									want:       "\"json\"",
 								},
 								&zeroOrOneExpr{
-									pos: position{line: 177, col: 39, offset: 4571},
+									pos: position{line: 243, col: 39, offset: 6574},
 									expr: &ruleRefExpr{
-										pos:  position{line: 177, col: 39, offset: 4571},
+										pos:  position{line: 243, col: 39, offset: 6574},
 										name: "space",
 									},
 								},
 								&labeledExpr{
-									pos:   position{line: 177, col: 46, offset: 4578},
+									pos:   position{line: 243, col: 46, offset: 6581},
 									label: "rest",
 									expr: &zeroOrMoreExpr{
-										pos: position{line: 177, col: 51, offset: 4583},
+										pos: position{line: 243, col: 51, offset: 6586},
 										expr: &ruleRefExpr{
-											pos:  position{line: 177, col: 52, offset: 4584},
+											pos:  position{line: 243, col: 52, offset: 6587},
 											name: "Query",
 										},
 									},
@@ -547,55 +670,55 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 208, col: 5, offset: 5974},
+						pos: position{line: 274, col: 5, offset: 7977},
 						run: (*parser).callonJSONFilter14,
 						expr: &seqExpr{
-							pos: position{line: 208, col: 5, offset: 5974},
+							pos: position{line: 274, col: 5, offset: 7977},
 							exprs: []interface{}{
 								&zeroOrOneExpr{
-									pos: position{line: 208, col: 5, offset: 5974},
+									pos: position{line: 274, col: 5, offset: 7977},
 									expr: &ruleRefExpr{
-										pos:  position{line: 208, col: 5, offset: 5974},
+										pos:  position{line: 274, col: 5, offset: 7977},
 										name: "space",
 									},
 								},
 								&litMatcher{
-									pos:        position{line: 208, col: 12, offset: 5981},
+									pos:        position{line: 274, col: 12, offset: 7984},
 									val:        "|",
 									ignoreCase: false,
 									want:       "\"|\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 208, col: 16, offset: 5985},
+									pos:  position{line: 274, col: 16, offset: 7988},
 									name: "space",
 								},
 								&litMatcher{
-									pos:        position{line: 208, col: 22, offset: 5991},
+									pos:        position{line: 274, col: 22, offset: 7994},
 									val:        "logfmt",
 									ignoreCase: false,
 									want:       "\"logfmt\"",
 								},
 								&zeroOrOneExpr{
-									pos: position{line: 208, col: 31, offset: 6000},
+									pos: position{line: 274, col: 31, offset: 8003},
 									expr: &ruleRefExpr{
-										pos:  position{line: 208, col: 31, offset: 6000},
+										pos:  position{line: 274, col: 31, offset: 8003},
 										name: "space",
 									},
 								},
 								&labeledExpr{
-									pos:   position{line: 208, col: 38, offset: 6007},
+									pos:   position{line: 274, col: 38, offset: 8010},
 									label: "rest",
 									expr: &zeroOrMoreExpr{
-										pos: position{line: 208, col: 43, offset: 6012},
+										pos: position{line: 274, col: 43, offset: 8015},
 										expr: &choiceExpr{
-											pos: position{line: 208, col: 44, offset: 6013},
+											pos: position{line: 274, col: 44, offset: 8016},
 											alternatives: []interface{}{
 												&ruleRefExpr{
-													pos:  position{line: 208, col: 44, offset: 6013},
+													pos:  position{line: 274, col: 44, offset: 8016},
 													name: "Query",
 												},
 												&ruleRefExpr{
-													pos:  position{line: 208, col: 52, offset: 6021},
+													pos:  position{line: 274, col: 52, offset: 8024},
 													name: "SingleField",
 												},
 											},
@@ -610,37 +733,37 @@ var g = &grammar{
 		},
 		{
 			name: "Duration",
-			pos:  position{line: 221, col: 1, offset: 6683},
+			pos:  position{line: 287, col: 1, offset: 8686},
 			expr: &actionExpr{
-				pos: position{line: 221, col: 13, offset: 6695},
+				pos: position{line: 287, col: 13, offset: 8698},
 				run: (*parser).callonDuration1,
 				expr: &seqExpr{
-					pos: position{line: 221, col: 13, offset: 6695},
+					pos: position{line: 287, col: 13, offset: 8698},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 221, col: 13, offset: 6695},
+							pos:        position{line: 287, col: 13, offset: 8698},
 							val:        "[",
 							ignoreCase: false,
 							want:       "\"[\"",
 						},
 						&labeledExpr{
-							pos:   position{line: 221, col: 17, offset: 6699},
+							pos:   position{line: 287, col: 17, offset: 8702},
 							label: "val",
 							expr: &ruleRefExpr{
-								pos:  position{line: 221, col: 21, offset: 6703},
+								pos:  position{line: 287, col: 21, offset: 8706},
 								name: "Integer",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 221, col: 29, offset: 6711},
+							pos:   position{line: 287, col: 29, offset: 8714},
 							label: "timeUnit",
 							expr: &ruleRefExpr{
-								pos:  position{line: 221, col: 38, offset: 6720},
+								pos:  position{line: 287, col: 38, offset: 8723},
 								name: "TIME_UNIT",
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 221, col: 48, offset: 6730},
+							pos:        position{line: 287, col: 48, offset: 8733},
 							val:        "]",
 							ignoreCase: false,
 							want:       "\"]\"",
@@ -651,75 +774,75 @@ var g = &grammar{
 		},
 		{
 			name: "TIME_UNIT",
-			pos:  position{line: 234, col: 1, offset: 7055},
+			pos:  position{line: 300, col: 1, offset: 9058},
 			expr: &choiceExpr{
-				pos: position{line: 234, col: 14, offset: 7068},
+				pos: position{line: 300, col: 14, offset: 9071},
 				alternatives: []interface{}{
 					&actionExpr{
-						pos: position{line: 234, col: 14, offset: 7068},
+						pos: position{line: 300, col: 14, offset: 9071},
 						run: (*parser).callonTIME_UNIT2,
 						expr: &litMatcher{
-							pos:        position{line: 234, col: 14, offset: 7068},
+							pos:        position{line: 300, col: 14, offset: 9071},
 							val:        "ms",
 							ignoreCase: false,
 							want:       "\"ms\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 236, col: 5, offset: 7105},
+						pos: position{line: 302, col: 5, offset: 9108},
 						run: (*parser).callonTIME_UNIT4,
 						expr: &litMatcher{
-							pos:        position{line: 236, col: 5, offset: 7105},
+							pos:        position{line: 302, col: 5, offset: 9108},
 							val:        "s",
 							ignoreCase: false,
 							want:       "\"s\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 238, col: 5, offset: 7144},
+						pos: position{line: 304, col: 5, offset: 9147},
 						run: (*parser).callonTIME_UNIT6,
 						expr: &litMatcher{
-							pos:        position{line: 238, col: 5, offset: 7144},
+							pos:        position{line: 304, col: 5, offset: 9147},
 							val:        "m",
 							ignoreCase: false,
 							want:       "\"m\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 240, col: 5, offset: 7184},
+						pos: position{line: 306, col: 5, offset: 9187},
 						run: (*parser).callonTIME_UNIT8,
 						expr: &litMatcher{
-							pos:        position{line: 240, col: 5, offset: 7184},
+							pos:        position{line: 306, col: 5, offset: 9187},
 							val:        "h",
 							ignoreCase: false,
 							want:       "\"h\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 242, col: 5, offset: 7229},
+						pos: position{line: 308, col: 5, offset: 9232},
 						run: (*parser).callonTIME_UNIT10,
 						expr: &litMatcher{
-							pos:        position{line: 242, col: 5, offset: 7229},
+							pos:        position{line: 308, col: 5, offset: 9232},
 							val:        "d",
 							ignoreCase: false,
 							want:       "\"d\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 244, col: 5, offset: 7279},
+						pos: position{line: 310, col: 5, offset: 9282},
 						run: (*parser).callonTIME_UNIT12,
 						expr: &litMatcher{
-							pos:        position{line: 244, col: 5, offset: 7279},
+							pos:        position{line: 310, col: 5, offset: 9282},
 							val:        "w",
 							ignoreCase: false,
 							want:       "\"w\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 246, col: 5, offset: 7333},
+						pos: position{line: 312, col: 5, offset: 9336},
 						run: (*parser).callonTIME_UNIT14,
 						expr: &litMatcher{
-							pos:        position{line: 246, col: 5, offset: 7333},
+							pos:        position{line: 312, col: 5, offset: 9336},
 							val:        "y",
 							ignoreCase: false,
 							want:       "\"y\"",
@@ -730,99 +853,99 @@ var g = &grammar{
 		},
 		{
 			name: "opCOMP",
-			pos:  position{line: 250, col: 1, offset: 7388},
+			pos:  position{line: 316, col: 1, offset: 9391},
 			expr: &choiceExpr{
-				pos: position{line: 250, col: 11, offset: 7398},
+				pos: position{line: 316, col: 11, offset: 9401},
 				alternatives: []interface{}{
 					&ruleRefExpr{
-						pos:  position{line: 250, col: 11, offset: 7398},
+						pos:  position{line: 316, col: 11, offset: 9401},
 						name: "opCustom",
 					},
 					&actionExpr{
-						pos: position{line: 251, col: 3, offset: 7409},
+						pos: position{line: 317, col: 3, offset: 9412},
 						run: (*parser).callonopCOMP3,
 						expr: &litMatcher{
-							pos:        position{line: 251, col: 3, offset: 7409},
+							pos:        position{line: 317, col: 3, offset: 9412},
 							val:        "==",
 							ignoreCase: false,
 							want:       "\"==\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 253, col: 5, offset: 7440},
+						pos: position{line: 319, col: 5, offset: 9443},
 						run: (*parser).callonopCOMP5,
 						expr: &litMatcher{
-							pos:        position{line: 253, col: 5, offset: 7440},
+							pos:        position{line: 319, col: 5, offset: 9443},
 							val:        "=~",
 							ignoreCase: false,
 							want:       "\"=~\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 255, col: 5, offset: 7471},
+						pos: position{line: 321, col: 5, offset: 9474},
 						run: (*parser).callonopCOMP7,
 						expr: &litMatcher{
-							pos:        position{line: 255, col: 5, offset: 7471},
+							pos:        position{line: 321, col: 5, offset: 9474},
 							val:        "<=",
 							ignoreCase: false,
 							want:       "\"<=\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 257, col: 5, offset: 7513},
+						pos: position{line: 323, col: 5, offset: 9516},
 						run: (*parser).callonopCOMP9,
 						expr: &litMatcher{
-							pos:        position{line: 257, col: 5, offset: 7513},
+							pos:        position{line: 323, col: 5, offset: 9516},
 							val:        ">=",
 							ignoreCase: false,
 							want:       "\">=\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 259, col: 5, offset: 7555},
+						pos: position{line: 325, col: 5, offset: 9558},
 						run: (*parser).callonopCOMP11,
 						expr: &litMatcher{
-							pos:        position{line: 259, col: 5, offset: 7555},
+							pos:        position{line: 325, col: 5, offset: 9558},
 							val:        "=",
 							ignoreCase: false,
 							want:       "\"=\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 261, col: 5, offset: 7596},
+						pos: position{line: 327, col: 5, offset: 9599},
 						run: (*parser).callonopCOMP13,
 						expr: &litMatcher{
-							pos:        position{line: 261, col: 5, offset: 7596},
+							pos:        position{line: 327, col: 5, offset: 9599},
 							val:        "<",
 							ignoreCase: false,
 							want:       "\"<\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 263, col: 5, offset: 7637},
+						pos: position{line: 329, col: 5, offset: 9640},
 						run: (*parser).callonopCOMP15,
 						expr: &litMatcher{
-							pos:        position{line: 263, col: 5, offset: 7637},
+							pos:        position{line: 329, col: 5, offset: 9640},
 							val:        ">",
 							ignoreCase: false,
 							want:       "\">\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 265, col: 5, offset: 7678},
+						pos: position{line: 331, col: 5, offset: 9681},
 						run: (*parser).callonopCOMP17,
 						expr: &litMatcher{
-							pos:        position{line: 265, col: 5, offset: 7678},
+							pos:        position{line: 331, col: 5, offset: 9681},
 							val:        "!=",
 							ignoreCase: false,
 							want:       "\"!=\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 267, col: 5, offset: 7720},
+						pos: position{line: 333, col: 5, offset: 9723},
 						run: (*parser).callonopCOMP19,
 						expr: &litMatcher{
-							pos:        position{line: 267, col: 5, offset: 7720},
+							pos:        position{line: 333, col: 5, offset: 9723},
 							val:        "!~",
 							ignoreCase: false,
 							want:       "\"!~\"",
@@ -833,45 +956,33 @@ var g = &grammar{
 		},
 		{
 			name: "GrepFilter",
-			pos:  position{line: 271, col: 1, offset: 7752},
-			expr: &choiceExpr{
-				pos: position{line: 271, col: 15, offset: 7766},
-				alternatives: []interface{}{
-					&actionExpr{
-						pos: position{line: 271, col: 15, offset: 7766},
-						run: (*parser).callonGrepFilter2,
-						expr: &litMatcher{
-							pos:        position{line: 271, col: 15, offset: 7766},
+			pos:  position{line: 337, col: 1, offset: 9755},
+			expr: &actionExpr{
+				pos: position{line: 337, col: 15, offset: 9769},
+				run: (*parser).callonGrepFilter1,
+				expr: &choiceExpr{
+					pos: position{line: 337, col: 16, offset: 9770},
+					alternatives: []interface{}{
+						&litMatcher{
+							pos:        position{line: 337, col: 16, offset: 9770},
 							val:        "|=",
 							ignoreCase: false,
 							want:       "\"|=\"",
 						},
-					},
-					&actionExpr{
-						pos: position{line: 273, col: 5, offset: 7797},
-						run: (*parser).callonGrepFilter4,
-						expr: &litMatcher{
-							pos:        position{line: 273, col: 5, offset: 7797},
+						&litMatcher{
+							pos:        position{line: 337, col: 23, offset: 9777},
 							val:        "!=",
 							ignoreCase: false,
 							want:       "\"!=\"",
 						},
-					},
-					&actionExpr{
-						pos: position{line: 275, col: 5, offset: 7839},
-						run: (*parser).callonGrepFilter6,
-						expr: &litMatcher{
-							pos:        position{line: 275, col: 5, offset: 7839},
+						&litMatcher{
+							pos:        position{line: 337, col: 30, offset: 9784},
 							val:        "|~",
 							ignoreCase: false,
 							want:       "\"|~\"",
 						},
-					},
-					&actionExpr{
-						pos: position{line: 277, col: 5, offset: 7871},
-						run: (*parser).callonGrepFilter8,
-						expr: &litMatcher{
-							pos:        position{line: 277, col: 5, offset: 7871},
+						&litMatcher{
+							pos:        position{line: 337, col: 37, offset: 9791},
 							val:        "!~",
 							ignoreCase: false,
 							want:       "\"!~\"",
@@ -882,26 +993,26 @@ var g = &grammar{
 		},
 		{
 			name: "opCustom",
-			pos:  position{line: 282, col: 1, offset: 7904},
+			pos:  position{line: 342, col: 1, offset: 9834},
 			expr: &actionExpr{
-				pos: position{line: 282, col: 13, offset: 7916},
+				pos: position{line: 342, col: 13, offset: 9846},
 				run: (*parser).callonopCustom1,
 				expr: &seqExpr{
-					pos: position{line: 282, col: 13, offset: 7916},
+					pos: position{line: 342, col: 13, offset: 9846},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 282, col: 13, offset: 7916},
+							pos:        position{line: 342, col: 13, offset: 9846},
 							val:        "=",
 							ignoreCase: false,
 							want:       "\"=\"",
 						},
 						&labeledExpr{
-							pos:   position{line: 282, col: 17, offset: 7920},
+							pos:   position{line: 342, col: 17, offset: 9850},
 							label: "opname",
 							expr: &oneOrMoreExpr{
-								pos: position{line: 282, col: 24, offset: 7927},
+								pos: position{line: 342, col: 24, offset: 9857},
 								expr: &charClassMatcher{
-									pos:        position{line: 282, col: 24, offset: 7927},
+									pos:        position{line: 342, col: 24, offset: 9857},
 									val:        "[a-z]i",
 									ranges:     []rune{'a', 'z'},
 									ignoreCase: true,
@@ -910,7 +1021,7 @@ var g = &grammar{
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 282, col: 32, offset: 7935},
+							pos:        position{line: 342, col: 32, offset: 9865},
 							val:        "=",
 							ignoreCase: false,
 							want:       "\"=\"",
@@ -921,25 +1032,25 @@ var g = &grammar{
 		},
 		{
 			name: "LetOpr",
-			pos:  position{line: 287, col: 1, offset: 7982},
+			pos:  position{line: 347, col: 1, offset: 9912},
 			expr: &choiceExpr{
-				pos: position{line: 287, col: 11, offset: 7992},
+				pos: position{line: 347, col: 11, offset: 9922},
 				alternatives: []interface{}{
 					&actionExpr{
-						pos: position{line: 287, col: 11, offset: 7992},
+						pos: position{line: 347, col: 11, offset: 9922},
 						run: (*parser).callonLetOpr2,
 						expr: &seqExpr{
-							pos: position{line: 287, col: 11, offset: 7992},
+							pos: position{line: 347, col: 11, offset: 9922},
 							exprs: []interface{}{
 								&charClassMatcher{
-									pos:        position{line: 287, col: 11, offset: 7992},
+									pos:        position{line: 347, col: 11, offset: 9922},
 									val:        "[>]",
 									chars:      []rune{'>'},
 									ignoreCase: false,
 									inverted:   false,
 								},
 								&litMatcher{
-									pos:        position{line: 287, col: 15, offset: 7996},
+									pos:        position{line: 347, col: 15, offset: 9926},
 									val:        "=",
 									ignoreCase: false,
 									want:       "\"=\"",
@@ -948,30 +1059,30 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 289, col: 5, offset: 8052},
+						pos: position{line: 349, col: 5, offset: 9982},
 						run: (*parser).callonLetOpr6,
 						expr: &litMatcher{
-							pos:        position{line: 289, col: 5, offset: 8052},
+							pos:        position{line: 349, col: 5, offset: 9982},
 							val:        ">",
 							ignoreCase: false,
 							want:       "\">\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 291, col: 5, offset: 8099},
+						pos: position{line: 351, col: 5, offset: 10029},
 						run: (*parser).callonLetOpr8,
 						expr: &seqExpr{
-							pos: position{line: 291, col: 5, offset: 8099},
+							pos: position{line: 351, col: 5, offset: 10029},
 							exprs: []interface{}{
 								&charClassMatcher{
-									pos:        position{line: 291, col: 5, offset: 8099},
+									pos:        position{line: 351, col: 5, offset: 10029},
 									val:        "[<]",
 									chars:      []rune{'<'},
 									ignoreCase: false,
 									inverted:   false,
 								},
 								&litMatcher{
-									pos:        position{line: 291, col: 9, offset: 8103},
+									pos:        position{line: 351, col: 9, offset: 10033},
 									val:        "=",
 									ignoreCase: false,
 									want:       "\"=\"",
@@ -980,30 +1091,30 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 293, col: 5, offset: 8156},
+						pos: position{line: 353, col: 5, offset: 10086},
 						run: (*parser).callonLetOpr12,
 						expr: &litMatcher{
-							pos:        position{line: 293, col: 5, offset: 8156},
+							pos:        position{line: 353, col: 5, offset: 10086},
 							val:        "<",
 							ignoreCase: false,
 							want:       "\"<\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 295, col: 5, offset: 8200},
+						pos: position{line: 355, col: 5, offset: 10130},
 						run: (*parser).callonLetOpr14,
 						expr: &seqExpr{
-							pos: position{line: 295, col: 5, offset: 8200},
+							pos: position{line: 355, col: 5, offset: 10130},
 							exprs: []interface{}{
 								&charClassMatcher{
-									pos:        position{line: 295, col: 5, offset: 8200},
+									pos:        position{line: 355, col: 5, offset: 10130},
 									val:        "[=]",
 									chars:      []rune{'='},
 									ignoreCase: false,
 									inverted:   false,
 								},
 								&litMatcher{
-									pos:        position{line: 295, col: 9, offset: 8204},
+									pos:        position{line: 355, col: 9, offset: 10134},
 									val:        "=",
 									ignoreCase: false,
 									want:       "\"=\"",
@@ -1012,20 +1123,20 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 297, col: 5, offset: 8246},
+						pos: position{line: 357, col: 5, offset: 10176},
 						run: (*parser).callonLetOpr18,
 						expr: &seqExpr{
-							pos: position{line: 297, col: 5, offset: 8246},
+							pos: position{line: 357, col: 5, offset: 10176},
 							exprs: []interface{}{
 								&charClassMatcher{
-									pos:        position{line: 297, col: 5, offset: 8246},
+									pos:        position{line: 357, col: 5, offset: 10176},
 									val:        "[!]",
 									chars:      []rune{'!'},
 									ignoreCase: false,
 									inverted:   false,
 								},
 								&litMatcher{
-									pos:        position{line: 297, col: 9, offset: 8250},
+									pos:        position{line: 357, col: 9, offset: 10180},
 									val:        "=",
 									ignoreCase: false,
 									want:       "\"=\"",
@@ -1034,50 +1145,50 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 299, col: 5, offset: 8295},
+						pos: position{line: 359, col: 5, offset: 10225},
 						run: (*parser).callonLetOpr22,
 						expr: &litMatcher{
-							pos:        position{line: 299, col: 5, offset: 8295},
+							pos:        position{line: 359, col: 5, offset: 10225},
 							val:        "+",
 							ignoreCase: false,
 							want:       "\"+\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 301, col: 5, offset: 8334},
+						pos: position{line: 361, col: 5, offset: 10264},
 						run: (*parser).callonLetOpr24,
 						expr: &litMatcher{
-							pos:        position{line: 301, col: 5, offset: 8334},
+							pos:        position{line: 361, col: 5, offset: 10264},
 							val:        "-",
 							ignoreCase: false,
 							want:       "\"-\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 303, col: 5, offset: 8378},
+						pos: position{line: 363, col: 5, offset: 10308},
 						run: (*parser).callonLetOpr26,
 						expr: &litMatcher{
-							pos:        position{line: 303, col: 5, offset: 8378},
+							pos:        position{line: 363, col: 5, offset: 10308},
 							val:        "/",
 							ignoreCase: false,
 							want:       "\"/\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 305, col: 5, offset: 8420},
+						pos: position{line: 365, col: 5, offset: 10350},
 						run: (*parser).callonLetOpr28,
 						expr: &litMatcher{
-							pos:        position{line: 305, col: 5, offset: 8420},
+							pos:        position{line: 365, col: 5, offset: 10350},
 							val:        "*",
 							ignoreCase: false,
 							want:       "\"*\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 307, col: 5, offset: 8464},
+						pos: position{line: 367, col: 5, offset: 10394},
 						run: (*parser).callonLetOpr30,
 						expr: &litMatcher{
-							pos:        position{line: 307, col: 5, offset: 8464},
+							pos:        position{line: 367, col: 5, offset: 10394},
 							val:        "%",
 							ignoreCase: false,
 							want:       "\"%\"",
@@ -1088,67 +1199,67 @@ var g = &grammar{
 		},
 		{
 			name: "LetIdentifier",
-			pos:  position{line: 311, col: 1, offset: 8505},
+			pos:  position{line: 371, col: 1, offset: 10435},
 			expr: &choiceExpr{
-				pos: position{line: 311, col: 18, offset: 8522},
+				pos: position{line: 371, col: 18, offset: 10452},
 				alternatives: []interface{}{
 					&actionExpr{
-						pos: position{line: 311, col: 18, offset: 8522},
+						pos: position{line: 371, col: 18, offset: 10452},
 						run: (*parser).callonLetIdentifier2,
 						expr: &seqExpr{
-							pos: position{line: 311, col: 18, offset: 8522},
+							pos: position{line: 371, col: 18, offset: 10452},
 							exprs: []interface{}{
 								&zeroOrOneExpr{
-									pos: position{line: 311, col: 18, offset: 8522},
+									pos: position{line: 371, col: 18, offset: 10452},
 									expr: &litMatcher{
-										pos:        position{line: 311, col: 18, offset: 8522},
+										pos:        position{line: 371, col: 18, offset: 10452},
 										val:        "-",
 										ignoreCase: false,
 										want:       "\"-\"",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 311, col: 23, offset: 8527},
+									pos:  position{line: 371, col: 23, offset: 10457},
 									name: "Float",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 317, col: 5, offset: 8722},
+						pos: position{line: 377, col: 5, offset: 10652},
 						run: (*parser).callonLetIdentifier7,
 						expr: &seqExpr{
-							pos: position{line: 317, col: 5, offset: 8722},
+							pos: position{line: 377, col: 5, offset: 10652},
 							exprs: []interface{}{
 								&oneOrMoreExpr{
-									pos: position{line: 317, col: 5, offset: 8722},
+									pos: position{line: 377, col: 5, offset: 10652},
 									expr: &litMatcher{
-										pos:        position{line: 317, col: 5, offset: 8722},
+										pos:        position{line: 377, col: 5, offset: 10652},
 										val:        "-",
 										ignoreCase: false,
 										want:       "\"-\"",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 317, col: 10, offset: 8727},
+									pos:  position{line: 377, col: 10, offset: 10657},
 									name: "Integer",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 323, col: 6, offset: 8932},
+						pos: position{line: 383, col: 6, offset: 10862},
 						run: (*parser).callonLetIdentifier12,
 						expr: &ruleRefExpr{
-							pos:  position{line: 323, col: 6, offset: 8932},
+							pos:  position{line: 383, col: 6, offset: 10862},
 							name: "Integer",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 329, col: 5, offset: 9141},
+						pos: position{line: 389, col: 5, offset: 11071},
 						run: (*parser).callonLetIdentifier14,
 						expr: &ruleRefExpr{
-							pos:  position{line: 329, col: 5, offset: 9141},
+							pos:  position{line: 389, col: 5, offset: 11071},
 							name: "QuotedValue",
 						},
 					},
@@ -1157,18 +1268,18 @@ var g = &grammar{
 		},
 		{
 			name: "BoolValue",
-			pos:  position{line: 337, col: 1, offset: 9302},
+			pos:  position{line: 397, col: 1, offset: 11232},
 			expr: &choiceExpr{
-				pos: position{line: 337, col: 14, offset: 9315},
+				pos: position{line: 397, col: 14, offset: 11245},
 				alternatives: []interface{}{
 					&litMatcher{
-						pos:        position{line: 337, col: 14, offset: 9315},
+						pos:        position{line: 397, col: 14, offset: 11245},
 						val:        "false",
 						ignoreCase: false,
 						want:       "\"false\"",
 					},
 					&litMatcher{
-						pos:        position{line: 337, col: 24, offset: 9325},
+						pos:        position{line: 397, col: 24, offset: 11255},
 						val:        "true",
 						ignoreCase: false,
 						want:       "\"true\"",
@@ -1178,9 +1289,9 @@ var g = &grammar{
 		},
 		{
 			name: "COUNT_OVER_TIME",
-			pos:  position{line: 339, col: 1, offset: 9333},
+			pos:  position{line: 399, col: 1, offset: 11263},
 			expr: &litMatcher{
-				pos:        position{line: 339, col: 20, offset: 9352},
+				pos:        position{line: 399, col: 20, offset: 11282},
 				val:        "count_over_time",
 				ignoreCase: false,
 				want:       "\"count_over_time\"",
@@ -1188,22 +1299,22 @@ var g = &grammar{
 		},
 		{
 			name: "Field",
-			pos:  position{line: 341, col: 1, offset: 9372},
+			pos:  position{line: 401, col: 1, offset: 11302},
 			expr: &choiceExpr{
-				pos: position{line: 341, col: 10, offset: 9381},
+				pos: position{line: 401, col: 10, offset: 11311},
 				alternatives: []interface{}{
 					&ruleRefExpr{
-						pos:  position{line: 341, col: 10, offset: 9381},
+						pos:  position{line: 401, col: 10, offset: 11311},
 						name: "Value",
 					},
 					&actionExpr{
-						pos: position{line: 341, col: 18, offset: 9389},
+						pos: position{line: 401, col: 18, offset: 11319},
 						run: (*parser).callonField3,
 						expr: &labeledExpr{
-							pos:   position{line: 341, col: 18, offset: 9389},
+							pos:   position{line: 401, col: 18, offset: 11319},
 							label: "pieces",
 							expr: &ruleRefExpr{
-								pos:  position{line: 341, col: 25, offset: 9396},
+								pos:  position{line: 401, col: 25, offset: 11326},
 								name: "FieldPiece",
 							},
 						},
@@ -1211,16 +1322,48 @@ var g = &grammar{
 				},
 			},
 		},
+		{
+			name: "LabelName",
+			pos:  position{line: 409, col: 1, offset: 11418},
+			expr: &actionExpr{
+				pos: position{line: 409, col: 14, offset: 11431},
+				run: (*parser).callonLabelName1,
+				expr: &seqExpr{
+					pos: position{line: 409, col: 14, offset: 11431},
+					exprs: []interface{}{
+						&charClassMatcher{
+							pos:        position{line: 409, col: 14, offset: 11431},
+							val:        "[a-zA-Z_]",
+							chars:      []rune{'_'},
+							ranges:     []rune{'a', 'z', 'A', 'Z'},
+							ignoreCase: false,
+							inverted:   false,
+						},
+						&zeroOrMoreExpr{
+							pos: position{line: 409, col: 23, offset: 11440},
+							expr: &charClassMatcher{
+								pos:        position{line: 409, col: 23, offset: 11440},
+								val:        "[a-zA-Z0-9_.]",
+								chars:      []rune{'_', '.'},
+								ranges:     []rune{'a', 'z', 'A', 'Z', '0', '9'},
+								ignoreCase: false,
+								inverted:   false,
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "Identifier",
-			pos:  position{line: 349, col: 1, offset: 9488},
+			pos:  position{line: 413, col: 1, offset: 11491},
 			expr: &actionExpr{
-				pos: position{line: 350, col: 4, offset: 9505},
+				pos: position{line: 414, col: 4, offset: 11508},
 				run: (*parser).callonIdentifier1,
 				expr: &oneOrMoreExpr{
-					pos: position{line: 350, col: 4, offset: 9505},
+					pos: position{line: 414, col: 4, offset: 11508},
 					expr: &charClassMatcher{
-						pos:        position{line: 350, col: 4, offset: 9505},
+						pos:        position{line: 414, col: 4, offset: 11508},
 						val:        "[a-zA-Z0-9_@./*]i",
 						chars:      []rune{'_', '@', '.', '/', '*'},
 						ranges:     []rune{'a', 'z', 'a', 'z', '0', '9'},
@@ -1232,22 +1375,22 @@ var g = &grammar{
 		},
 		{
 			name: "Value",
-			pos:  position{line: 354, col: 1, offset: 9563},
+			pos:  position{line: 418, col: 1, offset: 11566},
 			expr: &actionExpr{
-				pos: position{line: 354, col: 10, offset: 9572},
+				pos: position{line: 418, col: 10, offset: 11575},
 				run: (*parser).callonValue1,
 				expr: &labeledExpr{
-					pos:   position{line: 354, col: 10, offset: 9572},
+					pos:   position{line: 418, col: 10, offset: 11575},
 					label: "val",
 					expr: &choiceExpr{
-						pos: position{line: 355, col: 5, offset: 9582},
+						pos: position{line: 419, col: 5, offset: 11585},
 						alternatives: []interface{}{
 							&ruleRefExpr{
-								pos:  position{line: 355, col: 5, offset: 9582},
+								pos:  position{line: 419, col: 5, offset: 11585},
 								name: "Float",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 356, col: 7, offset: 9594},
+								pos:  position{line: 420, col: 7, offset: 11597},
 								name: "Integer",
 							},
 						},
@@ -1257,17 +1400,17 @@ var g = &grammar{
 		},
 		{
 			name: "Integer",
-			pos:  position{line: 362, col: 1, offset: 9649},
+			pos:  position{line: 426, col: 1, offset: 11652},
 			expr: &actionExpr{
-				pos: position{line: 362, col: 12, offset: 9660},
+				pos: position{line: 426, col: 12, offset: 11663},
 				run: (*parser).callonInteger1,
 				expr: &seqExpr{
-					pos: position{line: 362, col: 12, offset: 9660},
+					pos: position{line: 426, col: 12, offset: 11663},
 					exprs: []interface{}{
 						&zeroOrOneExpr{
-							pos: position{line: 362, col: 12, offset: 9660},
+							pos: position{line: 426, col: 12, offset: 11663},
 							expr: &charClassMatcher{
-								pos:        position{line: 362, col: 12, offset: 9660},
+								pos:        position{line: 426, col: 12, offset: 11663},
 								val:        "[+-]",
 								chars:      []rune{'+', '-'},
 								ignoreCase: false,
@@ -1275,9 +1418,9 @@ var g = &grammar{
 							},
 						},
 						&oneOrMoreExpr{
-							pos: position{line: 362, col: 18, offset: 9666},
+							pos: position{line: 426, col: 18, offset: 11669},
 							expr: &charClassMatcher{
-								pos:        position{line: 362, col: 18, offset: 9666},
+								pos:        position{line: 426, col: 18, offset: 11669},
 								val:        "[0-9]",
 								ranges:     []rune{'0', '9'},
 								ignoreCase: false,
@@ -1290,17 +1433,17 @@ var g = &grammar{
 		},
 		{
 			name: "Float",
-			pos:  position{line: 366, col: 1, offset: 9724},
+			pos:  position{line: 430, col: 1, offset: 11727},
 			expr: &actionExpr{
-				pos: position{line: 366, col: 10, offset: 9733},
+				pos: position{line: 430, col: 10, offset: 11736},
 				run: (*parser).callonFloat1,
 				expr: &seqExpr{
-					pos: position{line: 366, col: 10, offset: 9733},
+					pos: position{line: 430, col: 10, offset: 11736},
 					exprs: []interface{}{
 						&zeroOrOneExpr{
-							pos: position{line: 366, col: 10, offset: 9733},
+							pos: position{line: 430, col: 10, offset: 11736},
 							expr: &charClassMatcher{
-								pos:        position{line: 366, col: 10, offset: 9733},
+								pos:        position{line: 430, col: 10, offset: 11736},
 								val:        "[+-]",
 								chars:      []rune{'+', '-'},
 								ignoreCase: false,
@@ -1308,12 +1451,12 @@ var g = &grammar{
 							},
 						},
 						&seqExpr{
-							pos: position{line: 366, col: 17, offset: 9740},
+							pos: position{line: 430, col: 17, offset: 11743},
 							exprs: []interface{}{
 								&zeroOrMoreExpr{
-									pos: position{line: 366, col: 17, offset: 9740},
+									pos: position{line: 430, col: 17, offset: 11743},
 									expr: &charClassMatcher{
-										pos:        position{line: 366, col: 17, offset: 9740},
+										pos:        position{line: 430, col: 17, offset: 11743},
 										val:        "[0-9]",
 										ranges:     []rune{'0', '9'},
 										ignoreCase: false,
@@ -1321,15 +1464,15 @@ var g = &grammar{
 									},
 								},
 								&litMatcher{
-									pos:        position{line: 366, col: 24, offset: 9747},
+									pos:        position{line: 430, col: 24, offset: 11750},
 									val:        ".",
 									ignoreCase: false,
 									want:       "\".\"",
 								},
 								&oneOrMoreExpr{
-									pos: position{line: 366, col: 28, offset: 9751},
+									pos: position{line: 430, col: 28, offset: 11754},
 									expr: &charClassMatcher{
-										pos:        position{line: 366, col: 28, offset: 9751},
+										pos:        position{line: 430, col: 28, offset: 11754},
 										val:        "[0-9]",
 										ranges:     []rune{'0', '9'},
 										ignoreCase: false,
@@ -1344,20 +1487,20 @@ var g = &grammar{
 		},
 		{
 			name: "FieldPiece",
-			pos:  position{line: 370, col: 1, offset: 9815},
+			pos:  position{line: 434, col: 1, offset: 11818},
 			expr: &choiceExpr{
-				pos: position{line: 370, col: 15, offset: 9829},
+				pos: position{line: 434, col: 15, offset: 11832},
 				alternatives: []interface{}{
 					&ruleRefExpr{
-						pos:  position{line: 370, col: 15, offset: 9829},
+						pos:  position{line: 434, col: 15, offset: 11832},
 						name: "QuotedFieldPiece",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 370, col: 34, offset: 9848},
+						pos:  position{line: 434, col: 34, offset: 11851},
 						name: "UnquotedFieldPiece",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 370, col: 55, offset: 9869},
+						pos:  position{line: 434, col: 55, offset: 11872},
 						name: "Star",
 					},
 				},
@@ -1365,14 +1508,14 @@ var g = &grammar{
 		},
 		{
 			name: "UnquotedFieldPiece",
-			pos:  position{line: 372, col: 1, offset: 9875},
+			pos:  position{line: 436, col: 1, offset: 11878},
 			expr: &actionExpr{
-				pos: position{line: 372, col: 23, offset: 9897},
+				pos: position{line: 436, col: 23, offset: 11900},
 				run: (*parser).callonUnquotedFieldPiece1,
 				expr: &oneOrMoreExpr{
-					pos: position{line: 372, col: 23, offset: 9897},
+					pos: position{line: 436, col: 23, offset: 11900},
 					expr: &charClassMatcher{
-						pos:        position{line: 372, col: 23, offset: 9897},
+						pos:        position{line: 436, col: 23, offset: 11900},
 						val:        "[-a-zA-Z0-9$&,?#%_@;[\\]{}+-./*:]i",
 						chars:      []rune{'-', '$', '&', ',', '?', '#', '%', '_', '@', ';', '[', ']', '{', '}', '/', '*', ':'},
 						ranges:     []rune{'a', 'z', 'a', 'z', '0', '9', '+', '.'},
@@ -1384,20 +1527,20 @@ var g = &grammar{
 		},
 		{
 			name: "QuotedFieldPiece",
-			pos:  position{line: 376, col: 1, offset: 9968},
+			pos:  position{line: 440, col: 1, offset: 11971},
 			expr: &ruleRefExpr{
-				pos:  position{line: 376, col: 21, offset: 9988},
+				pos:  position{line: 440, col: 21, offset: 11991},
 				name: "QuotedValue",
 			},
 		},
 		{
 			name: "Star",
-			pos:  position{line: 378, col: 1, offset: 10001},
+			pos:  position{line: 442, col: 1, offset: 12004},
 			expr: &actionExpr{
-				pos: position{line: 378, col: 9, offset: 10009},
+				pos: position{line: 442, col: 9, offset: 12012},
 				run: (*parser).callonStar1,
 				expr: &litMatcher{
-					pos:        position{line: 378, col: 9, offset: 10009},
+					pos:        position{line: 442, col: 9, offset: 12012},
 					val:        "*",
 					ignoreCase: false,
 					want:       "\"*\"",
@@ -1406,50 +1549,50 @@ var g = &grammar{
 		},
 		{
 			name: "QuotedValue",
-			pos:  position{line: 381, col: 1, offset: 10037},
+			pos:  position{line: 445, col: 1, offset: 12040},
 			expr: &actionExpr{
-				pos: position{line: 381, col: 16, offset: 10052},
+				pos: position{line: 445, col: 16, offset: 12055},
 				run: (*parser).callonQuotedValue1,
 				expr: &seqExpr{
-					pos: position{line: 381, col: 16, offset: 10052},
+					pos: position{line: 445, col: 16, offset: 12055},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 381, col: 16, offset: 10052},
+							pos:        position{line: 445, col: 16, offset: 12055},
 							val:        "\"",
 							ignoreCase: false,
 							want:       "\"\\\"\"",
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 381, col: 20, offset: 10056},
+							pos: position{line: 445, col: 20, offset: 12059},
 							expr: &choiceExpr{
-								pos: position{line: 381, col: 22, offset: 10058},
+								pos: position{line: 445, col: 22, offset: 12061},
 								alternatives: []interface{}{
 									&seqExpr{
-										pos: position{line: 381, col: 22, offset: 10058},
+										pos: position{line: 445, col: 22, offset: 12061},
 										exprs: []interface{}{
 											&notExpr{
-												pos: position{line: 381, col: 22, offset: 10058},
+												pos: position{line: 445, col: 22, offset: 12061},
 												expr: &ruleRefExpr{
-													pos:  position{line: 381, col: 23, offset: 10059},
+													pos:  position{line: 445, col: 23, offset: 12062},
 													name: "EscapedChar",
 												},
 											},
 											&anyMatcher{
-												line: 381, col: 35, offset: 10071,
+												line: 445, col: 35, offset: 12074,
 											},
 										},
 									},
 									&seqExpr{
-										pos: position{line: 381, col: 39, offset: 10075},
+										pos: position{line: 445, col: 39, offset: 12078},
 										exprs: []interface{}{
 											&litMatcher{
-												pos:        position{line: 381, col: 39, offset: 10075},
+												pos:        position{line: 445, col: 39, offset: 12078},
 												val:        "\\",
 												ignoreCase: false,
 												want:       "\"\\\\\"",
 											},
 											&ruleRefExpr{
-												pos:  position{line: 381, col: 44, offset: 10080},
+												pos:  position{line: 445, col: 44, offset: 12083},
 												name: "EscapeSequence",
 											},
 										},
@@ -1458,7 +1601,7 @@ var g = &grammar{
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 381, col: 62, offset: 10098},
+							pos:        position{line: 445, col: 62, offset: 12101},
 							val:        "\"",
 							ignoreCase: false,
 							want:       "\"\\\"\"",
@@ -1469,9 +1612,9 @@ var g = &grammar{
 		},
 		{
 			name: "EscapedChar",
-			pos:  position{line: 385, col: 1, offset: 10215},
+			pos:  position{line: 449, col: 1, offset: 12218},
 			expr: &charClassMatcher{
-				pos:        position{line: 385, col: 16, offset: 10230},
+				pos:        position{line: 449, col: 16, offset: 12233},
 				val:        "[\\x00-\\x1f\"\\\\]",
 				chars:      []rune{'"', '\\'},
 				ranges:     []rune{'\x00', '\x1f'},
@@ -1481,16 +1624,16 @@ var g = &grammar{
 		},
 		{
 			name: "EscapeSequence",
-			pos:  position{line: 387, col: 1, offset: 10246},
+			pos:  position{line: 451, col: 1, offset: 12249},
 			expr: &choiceExpr{
-				pos: position{line: 387, col: 19, offset: 10264},
+				pos: position{line: 451, col: 19, offset: 12267},
 				alternatives: []interface{}{
 					&ruleRefExpr{
-						pos:  position{line: 387, col: 19, offset: 10264},
+						pos:  position{line: 451, col: 19, offset: 12267},
 						name: "SingleCharEscape",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 387, col: 38, offset: 10283},
+						pos:  position{line: 451, col: 38, offset: 12286},
 						name: "UnicodeEscape",
 					},
 				},
@@ -1498,9 +1641,9 @@ var g = &grammar{
 		},
 		{
 			name: "SingleCharEscape",
-			pos:  position{line: 389, col: 1, offset: 10298},
+			pos:  position{line: 453, col: 1, offset: 12301},
 			expr: &charClassMatcher{
-				pos:        position{line: 389, col: 21, offset: 10318},
+				pos:        position{line: 453, col: 21, offset: 12321},
 				val:        "[\"\\\\/bfnrt]",
 				chars:      []rune{'"', '\\', '/', 'b', 'f', 'n', 'r', 't'},
 				ignoreCase: false,
@@ -1509,30 +1652,30 @@ var g = &grammar{
 		},
 		{
 			name: "UnicodeEscape",
-			pos:  position{line: 391, col: 1, offset: 10331},
+			pos:  position{line: 455, col: 1, offset: 12334},
 			expr: &seqExpr{
-				pos: position{line: 391, col: 18, offset: 10348},
+				pos: position{line: 455, col: 18, offset: 12351},
 				exprs: []interface{}{
 					&litMatcher{
-						pos:        position{line: 391, col: 18, offset: 10348},
+						pos:        position{line: 455, col: 18, offset: 12351},
 						val:        "u",
 						ignoreCase: false,
 						want:       "\"u\"",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 391, col: 22, offset: 10352},
+						pos:  position{line: 455, col: 22, offset: 12355},
 						name: "HexDigit",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 391, col: 31, offset: 10361},
+						pos:  position{line: 455, col: 31, offset: 12364},
 						name: "HexDigit",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 391, col: 40, offset: 10370},
+						pos:  position{line: 455, col: 40, offset: 12373},
 						name: "HexDigit",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 391, col: 49, offset: 10379},
+						pos:  position{line: 455, col: 49, offset: 12382},
 						name: "HexDigit",
 					},
 				},
@@ -1540,9 +1683,9 @@ var g = &grammar{
 		},
 		{
 			name: "HexDigit",
-			pos:  position{line: 393, col: 1, offset: 10389},
+			pos:  position{line: 457, col: 1, offset: 12392},
 			expr: &charClassMatcher{
-				pos:        position{line: 393, col: 13, offset: 10401},
+				pos:        position{line: 457, col: 13, offset: 12404},
 				val:        "[0-9a-f]i",
 				ranges:     []rune{'0', '9', 'a', 'f'},
 				ignoreCase: true,
@@ -1551,22 +1694,22 @@ var g = &grammar{
 		},
 		{
 			name: "EOF",
-			pos:  position{line: 398, col: 1, offset: 10485},
+			pos:  position{line: 462, col: 1, offset: 12488},
 			expr: &notExpr{
-				pos: position{line: 398, col: 7, offset: 10491},
+				pos: position{line: 462, col: 7, offset: 12494},
 				expr: &anyMatcher{
-					line: 398, col: 8, offset: 10492,
+					line: 462, col: 8, offset: 12495,
 				},
 			},
 		},
 		{
 			name:        "_",
 			displayName: "\"whitespace\"",
-			pos:         position{line: 400, col: 1, offset: 10495},
+			pos:         position{line: 464, col: 1, offset: 12498},
 			expr: &zeroOrMoreExpr{
-				pos: position{line: 400, col: 19, offset: 10513},
+				pos: position{line: 464, col: 19, offset: 12516},
 				expr: &charClassMatcher{
-					pos:        position{line: 400, col: 19, offset: 10513},
+					pos:        position{line: 464, col: 19, offset: 12516},
 					val:        "[ \\n\\t\\r]",
 					chars:      []rune{' ', '\n', '\t', '\r'},
 					ignoreCase: false,
@@ -1576,11 +1719,11 @@ var g = &grammar{
 		},
 		{
 			name: "space",
-			pos:  position{line: 402, col: 1, offset: 10525},
+			pos:  position{line: 466, col: 1, offset: 12528},
 			expr: &oneOrMoreExpr{
-				pos: position{line: 402, col: 10, offset: 10534},
+				pos: position{line: 466, col: 10, offset: 12537},
 				expr: &charClassMatcher{
-					pos:        position{line: 402, col: 10, offset: 10534},
+					pos:        position{line: 466, col: 10, offset: 12537},
 					val:        "[ \\n\\t\\r]",
 					chars:      []rune{' ', '\n', '\t', '\r'},
 					ignoreCase: false,
@@ -1590,18 +1733,18 @@ var g = &grammar{
 		},
 		{
 			name: "Delimiter",
-			pos:  position{line: 404, col: 1, offset: 10546},
+			pos:  position{line: 468, col: 1, offset: 12549},
 			expr: &choiceExpr{
-				pos: position{line: 404, col: 14, offset: 10559},
+				pos: position{line: 468, col: 14, offset: 12562},
 				alternatives: []interface{}{
 					&litMatcher{
-						pos:        position{line: 404, col: 14, offset: 10559},
+						pos:        position{line: 468, col: 14, offset: 12562},
 						val:        "{",
 						ignoreCase: false,
 						want:       "\"{\"",
 					},
 					&litMatcher{
-						pos:        position{line: 404, col: 20, offset: 10565},
+						pos:        position{line: 468, col: 20, offset: 12568},
 						val:        "}",
 						ignoreCase: false,
 						want:       "\"}\"",
@@ -1754,6 +1897,46 @@ func (p *parser) callonQuery1() (interface{}, error) {
 	return p.cur.onQuery1(stack["field"], stack["field1"])
 }
 
+func (c *current) onStreamMatcher1(field, op, field1 interface{}) (interface{}, error) {
+	node := &ast.Node{
+		NodeType: ast.NodeTerminal,
+		Comparison: ast.Comparison{
+			Op:     op.(string),
+			Field:  field.(string),
+			Values: field1,
+		},
+	}
+	if op.(string) == "=~" || op.(string) == "!~" {
+		pattern, err := getRegexPattern(field1)
+		if err != nil {
+			return nil, err
+		}
+		node.Comparison.Op = "="
+		if op.(string) == "!~" {
+			node.Comparison.Op = "!="
+		}
+		node.Comparison.Values = "^(?:" + pattern + ")$"
+		node.Comparison.ValueIsRegex = true
+	}
+	return node, nil
+}
+
+func (p *parser) callonStreamMatcher1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onStreamMatcher1(stack["field"], stack["op"], stack["field1"])
+}
+
+func (c *current) onMatchOp1() (interface{}, error) {
+	return string(c.text), nil
+}
+
+func (p *parser) callonMatchOp1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onMatchOp1()
+}
+
 func (c *current) onSingleField1(field interface{}) (interface{}, error) {
 	return &ast.Node{
 		NodeType: ast.NodeTerminal,
@@ -1771,10 +1954,28 @@ func (p *parser) callonSingleField1() (interface{}, error) {
 }
 
 func (c *current) onLogFilter1(grep, field interface{}) (interface{}, error) {
+	op := "="
+	if grep.(string) == "!=" || grep.(string) == "!~" {
+		op = "!="
+	}
+	if grep.(string) == "|~" || grep.(string) == "!~" {
+		pattern, err := getRegexPattern(field)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.Node{
+			NodeType: ast.NodeTerminal,
+			Comparison: ast.Comparison{
+				Op:           op,
+				Values:       pattern,
+				ValueIsRegex: true,
+			},
+		}, nil
+	}
 	return &ast.Node{
 		NodeType: ast.NodeTerminal,
 		Comparison: ast.Comparison{
-			Op:     grep.(string),
+			Op:     op,
 			Values: ast.GrepValue{Field: field.(string)},
 		},
 	}, nil
@@ -2041,44 +2242,14 @@ func (p *parser) callonopCOMP19() (interface{}, error) {
 	return p.cur.onopCOMP19()
 }
 
-func (c *current) onGrepFilter2() (interface{}, error) {
-	return "=", nil
-}
-
-func (p *parser) callonGrepFilter2() (interface{}, error) {
-	stack := p.vstack[len(p.vstack)-1]
-	_ = stack
-	return p.cur.onGrepFilter2()
-}
-
-func (c *current) onGrepFilter4() (interface{}, error) {
+func (c *current) onGrepFilter1() (interface{}, error) {
 	return string(c.text), nil
 }
 
-func (p *parser) callonGrepFilter4() (interface{}, error) {
+func (p *parser) callonGrepFilter1() (interface{}, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onGrepFilter4()
-}
-
-func (c *current) onGrepFilter6() (interface{}, error) {
-	return "=", nil
-}
-
-func (p *parser) callonGrepFilter6() (interface{}, error) {
-	stack := p.vstack[len(p.vstack)-1]
-	_ = stack
-	return p.cur.onGrepFilter6()
-}
-
-func (c *current) onGrepFilter8() (interface{}, error) {
-	return "!=", nil
-}
-
-func (p *parser) callonGrepFilter8() (interface{}, error) {
-	stack := p.vstack[len(p.vstack)-1]
-	_ = stack
-	return p.cur.onGrepFilter8()
+	return p.cur.onGrepFilter1()
 }
 
 func (c *current) onopCustom1(opname interface{}) (interface{}, error) {
@@ -2271,6 +2442,16 @@ func (p *parser) callonField3() (interface{}, error) {
 	return p.cur.onField3(stack["pieces"])
 }
 
+func (c *current) onLabelName1() (interface{}, error) {
+	return string(c.text), nil
+}
+
+func (p *parser) callonLabelName1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onLabelName1()
+}
+
 func (c *current) onIdentifier1() (interface{}, error) {
 
 	return string(c.text), nil
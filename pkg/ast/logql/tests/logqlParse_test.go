@@ -190,3 +190,25 @@ func Test_ParseJSONKeywordAndFilters(t *testing.T) {
 	assert.Equal(t, queryJson.Right.Right.Comparison.Values, "thing")
 
 }
+
+func Test_ParseRegexMatchersAndFilters(t *testing.T) {
+	astNode := &structs.ASTNode{}
+	json_body := []byte(`{job=~"var.*", level!="debug", host!~"dev-\\d+"} |~ "err(or)?" !~ "timeout"`)
+	res, err := logql.Parse("", json_body)
+	assert.Nil(t, err)
+	queryJson := res.(ast.QueryStruct).SearchFilter
+	err = pipesearch.SearchQueryToASTnode(queryJson, astNode, 0)
+	assert.Nil(t, err)
+
+	stream := queryJson.Left
+	assert.Equal(t, ast.Comparison{Op: "=", Field: "job", Values: "^(?:var.*)$", ValueIsRegex: true}, stream.Left.Comparison)
+	assert.Equal(t, ast.Comparison{Op: "!=", Field: "level", Values: "\"debug\""}, stream.Right.Left.Comparison)
+	assert.Equal(t, ast.Comparison{Op: "!=", Field: "host", Values: `^(?:dev-\d+)$`, ValueIsRegex: true}, stream.Right.Right.Comparison)
+
+	filters := queryJson.Right
+	assert.Equal(t, ast.Comparison{Op: "=", Values: "err(or)?", ValueIsRegex: true}, filters.Left.Comparison)
+	assert.Equal(t, ast.Comparison{Op: "!=", Values: "timeout", ValueIsRegex: true}, filters.Right.Comparison)
+
+	_, err = logql.Parse("", []byte(`{job=~"var(.*"}`))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipesearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+/*
+Dashboard panels send the selected values of the dashboard variables with their queries, under "variables":
+
+	"variables": {"service": ["frontend", "backend"], "interval": "5m"}
+
+The $name and ${name} tokens of the query are replaced by the values before the query is parsed. A token compared
+to a field, as in service=$service, is expanded into an OR of the values for multi value variables, e.g.
+(service="frontend" OR service="backend"). Other tokens are replaced by the values joined with commas.
+The built-in $__interval variable is a span that gives about AUTO_INTERVAL_BUCKETS buckets over the time range
+*/
+
+const AUTO_INTERVAL_BUCKETS = 100
+
+// Matches a variable token, with the field it is compared to if any, e.g. $service, service=$service or
+// service!=${service}
+var variableTokenRegex = regexp.MustCompile(`(?:([A-Za-z0-9_.\-]+)\s*(!=|=)\s*)?\$(?:\{(\w+)\}|(\w+))`)
+
+// Values that can be compared to a field without quotes
+var unquotedValueRegex = regexp.MustCompile(`^[A-Za-z0-9_.*:/\-]+$`)
+
+var autoIntervals = []time.Duration{
+	time.Second, 5 * time.Second, 10 * time.Second, 30 * time.Second,
+	time.Minute, 5 * time.Minute, 10 * time.Minute, 30 * time.Minute,
+	time.Hour, 3 * time.Hour, 6 * time.Hour, 12 * time.Hour, 24 * time.Hour,
+}
+
+// Reads the values of the variables of a search request, a variable has one value or a list of them
+func parseVariableValues(rawVariables interface{}) (map[string][]string, error) {
+	variablesMap, ok := rawVariables.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("parseVariableValues: variables must be an object, got %T", rawVariables)
+	}
+	values := make(map[string][]string, len(variablesMap))
+	for name, rawValue := range variablesMap {
+		switch val := rawValue.(type) {
+		case []interface{}:
+			multiValues := make([]string, 0, len(val))
+			for _, v := range val {
+				strVal, err := variableValueToString(v)
+				if err != nil {
+					return nil, fmt.Errorf("parseVariableValues: variable %v: %v", name, err)
+				}
+				multiValues = append(multiValues, strVal)
+			}
+			values[name] = multiValues
+		default:
+			strVal, err := variableValueToString(val)
+			if err != nil {
+				return nil, fmt.Errorf("parseVariableValues: variable %v: %v", name, err)
+			}
+			values[name] = []string{strVal}
+		}
+	}
+	return values, nil
+}
+
+func variableValueToString(value interface{}) (string, error) {
+	switch val := value.(type) {
+	case string:
+		return val, nil
+	case json.Number:
+		return val.String(), nil
+	case float64, bool:
+		return fmt.Sprint(val), nil
+	default:
+		return "", fmt.Errorf("unsupported value type %T", value)
+	}
+}
+
+/*
+Replaces the variable tokens of the search text by their values. Tokens of variables that have no value are left
+as they are, since a $ can also be part of the query itself
+*/
+func SubstituteDashboardVariables(searchText string, rawVariables interface{}, startEpoch uint64, endEpoch uint64) (string, error) {
+	values, err := parseVariableValues(rawVariables)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := values["__interval"]; !ok {
+		values["__interval"] = []string{getAutoInterval(startEpoch, endEpoch)}
+	}
+	for name, varValues := range values {
+		if len(varValues) == 0 {
+			return "", fmt.Errorf("SubstituteDashboardVariables: variable %v has no value", name)
+		}
+	}
+
+	// a single pass, so that the values are not searched for tokens
+	searchText = variableTokenRegex.ReplaceAllStringFunc(searchText, func(token string) string {
+		match := variableTokenRegex.FindStringSubmatch(token)
+		field, op, name := match[1], match[2], match[3]+match[4]
+		varValues, ok := values[name]
+		if !ok {
+			return token
+		}
+		if field == "" {
+			return strings.Join(varValues, ",")
+		}
+		if len(varValues) == 1 {
+			return field + op + formatFilterValue(varValues[0])
+		}
+		joinOp := " OR "
+		if op == "!=" {
+			joinOp = " AND "
+		}
+		filters := make([]string, 0, len(varValues))
+		for _, value := range varValues {
+			filters = append(filters, field+op+formatFilterValue(value))
+		}
+		return "(" + strings.Join(filters, joinOp) + ")"
+	})
+	return searchText, nil
+}
+
+func formatFilterValue(value string) string {
+	if unquotedValueRegex.MatchString(value) {
+		return value
+	}
+	return `"` + strings.ReplaceAll(value, `"`, `\"`) + `"`
+}
+
+// Returns the smallest of the auto intervals that splits the time range into at most AUTO_INTERVAL_BUCKETS buckets
+func getAutoInterval(startEpoch uint64, endEpoch uint64) string {
+	timeRange := time.Duration(0)
+	if endEpoch > startEpoch {
+		timeRange = time.Duration(endEpoch-startEpoch) * time.Millisecond
+	}
+	interval := autoIntervals[len(autoIntervals)-1]
+	for _, autoInterval := range autoIntervals {
+		if timeRange/autoInterval <= AUTO_INTERVAL_BUCKETS {
+			interval = autoInterval
+			break
+		}
+	}
+	switch {
+	case interval >= time.Hour:
+		return fmt.Sprintf("%vh", int(interval/time.Hour))
+	case interval >= time.Minute:
+		return fmt.Sprintf("%vm", int(interval/time.Minute))
+	default:
+		return fmt.Sprintf("%vs", int(interval/time.Second))
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipesearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SubstituteDashboardVariables(t *testing.T) {
+	variables := map[string]interface{}{
+		"service": []interface{}{"frontend", "backend"},
+		"env":     "prod",
+		"path":    "/api/v1 users",
+		"code":    float64(500),
+		"span":    "5m",
+	}
+
+	cases := map[string]string{
+		`service=$service | stats count`:         `(service=frontend OR service=backend) | stats count`,
+		`service!=$service`:                      `(service!=frontend AND service!=backend)`,
+		`env=${env} AND status_code=$code`:       `env=prod AND status_code=500`,
+		`path=$path`:                             `path="/api/v1 users"`,
+		`* | timechart span=$span count`:         `* | timechart span=5m count`,
+		`* | stats count BY $service`:            `* | stats count BY frontend,backend`,
+		`price=$unknown AND env = $env`:          `price=$unknown AND env=prod`,
+		`* | timechart span=$__interval count`:   `* | timechart span=1m count`,
+		`* | timechart span=${__interval} count`: `* | timechart span=1m count`,
+	}
+	for searchText, expected := range cases {
+		actual, err := SubstituteDashboardVariables(searchText, variables, 0, 3_600_000)
+		assert.Nil(t, err, searchText)
+		assert.Equal(t, expected, actual, searchText)
+	}
+
+	actual, err := SubstituteDashboardVariables(`span=$__interval`, map[string]interface{}{"__interval": "1h"}, 0, 3_600_000)
+	assert.Nil(t, err)
+	assert.Equal(t, `span=1h`, actual)
+
+	_, err = SubstituteDashboardVariables(`service=$service`, map[string]interface{}{"service": []interface{}{}}, 0, 1)
+	assert.NotNil(t, err)
+	_, err = SubstituteDashboardVariables(`service=$service`, []interface{}{"service"}, 0, 1)
+	assert.NotNil(t, err)
+	_, err = SubstituteDashboardVariables(`service=$service`, map[string]interface{}{"service": map[string]interface{}{}}, 0, 1)
+	assert.NotNil(t, err)
+}
+
+func Test_getAutoInterval(t *testing.T) {
+	assert.Equal(t, "1s", getAutoInterval(0, 0))
+	assert.Equal(t, "1s", getAutoInterval(0, 100_000))
+	assert.Equal(t, "5s", getAutoInterval(0, 300_000))
+	assert.Equal(t, "1m", getAutoInterval(0, 3_600_000))
+	assert.Equal(t, "3h", getAutoInterval(0, 7*24*3_600_000))
+	assert.Equal(t, "24h", getAutoInterval(0, 365*24*3_600_000))
+}
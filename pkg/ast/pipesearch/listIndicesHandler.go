@@ -19,6 +19,7 @@ package pipesearch
 import (
 	"sort"
 
+	"github.com/siglens/siglens/pkg/apikeys"
 	"github.com/siglens/siglens/pkg/utils"
 	vtable "github.com/siglens/siglens/pkg/virtualtable"
 	log "github.com/sirupsen/logrus"
@@ -44,13 +45,19 @@ func ListIndicesHandler(ctx *fasthttp.RequestCtx, myid uint64) {
 		utils.WriteJsonResponse(ctx, httpResp)
 		return
 	}
-	var listIndices []*IndexInfo
+	// the indices the api key of the request can not read are not listed
+	indexFilter := apikeys.GetIndexFilter(ctx, apikeys.ACCESS_READ)
+	listIndices := []*IndexInfo{}
+	httpResp = listIndices
 	for _, indexName := range allVirtualTableNames {
 		IndexInfo := IndexInfo{}
 		if indexName == "" || indexName == "*" {
 			log.Debugf("ListIndicesHandler: one of empty/wildcard indexName=%v", indexName)
 			continue
 		}
+		if indexFilter != nil && !indexFilter(indexName) {
+			continue
+		}
 		IndexInfo.Name = indexName
 		listIndices = append(listIndices, &IndexInfo)
 		httpResp = listIndices
@@ -0,0 +1,277 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipesearch
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/siglens/siglens/pkg/apikeys"
+	"github.com/siglens/siglens/pkg/config"
+	"github.com/siglens/siglens/pkg/instrumentation"
+	"github.com/siglens/siglens/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+/*
+Cache of the results of the dashboard panel queries, so that a dashboard opened by many users runs each of its
+queries once per ttl. The results are keyed by the panel, the query with its variables substituted and the time
+range as it was requested, so a relative range like now-1h shares the results for the ttl.
+Results past the ttl are served while they are refreshed in the background, and the concurrent requests for a
+result that is not cached wait for a single query to run.
+*/
+
+const DEFAULT_PANEL_CACHE_TTL_SECS = 30
+const DEFAULT_PANEL_CACHE_STALE_SECS = 300
+const DEFAULT_PANEL_CACHE_MAX_ENTRIES = 1000
+
+// Set on the requests the cache runs, so that they are not served from the cache
+const PANEL_CACHE_BYPASS_KEY = "panelCacheBypass"
+
+type panelCacheResult struct {
+	statusCode int
+	body       []byte
+}
+
+type panelCacheEntry struct {
+	result       *panelCacheResult
+	createdMs    uint64
+	lastAccessMs uint64
+	refreshing   bool
+}
+
+type panelCacheLoad struct {
+	done   chan struct{}
+	result *panelCacheResult
+}
+
+type panelCache struct {
+	lock       sync.Mutex
+	ttlMs      uint64
+	staleMs    uint64
+	maxEntries int
+	entries    map[string]*panelCacheEntry
+	loading    map[string]*panelCacheLoad
+	generation uint64 // incremented by clear, so that the loads started before it are not cached
+}
+
+var panelCacheOnce sync.Once
+var globalPanelCache *panelCache
+
+// Returns nil if the panel cache is disabled
+func getPanelCache() *panelCache {
+	panelCacheOnce.Do(func() {
+		cacheCfg := config.GetPanelCacheConfig()
+		if !cacheCfg.Enabled {
+			return
+		}
+		globalPanelCache = newPanelCache(cacheCfg)
+		// the cached results were masked with the policies that applied when they were loaded
+		apikeys.AddMaskingPolicyHook(globalPanelCache.clear)
+	})
+	return globalPanelCache
+}
+
+func newPanelCache(cacheCfg config.PanelCacheConfig) *panelCache {
+	pc := &panelCache{
+		ttlMs:      cacheCfg.TTLSecs * 1000,
+		staleMs:    cacheCfg.StaleSecs * 1000,
+		maxEntries: int(cacheCfg.MaxEntries),
+		entries:    make(map[string]*panelCacheEntry),
+		loading:    make(map[string]*panelCacheLoad),
+	}
+	if pc.ttlMs == 0 {
+		pc.ttlMs = DEFAULT_PANEL_CACHE_TTL_SECS * 1000
+	}
+	if pc.staleMs == 0 {
+		pc.staleMs = DEFAULT_PANEL_CACHE_STALE_SECS * 1000
+	}
+	if pc.maxEntries == 0 {
+		pc.maxEntries = DEFAULT_PANEL_CACHE_MAX_ENTRIES
+	}
+	return pc
+}
+
+/*
+Returns the cached result of the key, running load when it is not cached or has been stale for too long. Only the
+successful results are cached
+*/
+func (pc *panelCache) get(key string, nowMs uint64, load func() *panelCacheResult) *panelCacheResult {
+	pc.lock.Lock()
+	entry, ok := pc.entries[key]
+	if ok && nowMs < entry.createdMs+pc.ttlMs+pc.staleMs {
+		entry.lastAccessMs = nowMs
+		if nowMs >= entry.createdMs+pc.ttlMs && !entry.refreshing {
+			entry.refreshing = true
+			go pc.refresh(key, pc.generation, load)
+		}
+		pc.lock.Unlock()
+		instrumentation.IncrementCacheLookupsCount("panel", true, 1)
+		return entry.result
+	}
+	instrumentation.IncrementCacheLookupsCount("panel", false, 1)
+	if inProgress, ok := pc.loading[key]; ok {
+		pc.lock.Unlock()
+		<-inProgress.done
+		return inProgress.result
+	}
+	inProgress := &panelCacheLoad{done: make(chan struct{})}
+	pc.loading[key] = inProgress
+	generation := pc.generation
+	pc.lock.Unlock()
+
+	// the waiters are released even if load panics, they get an internal error then
+	defer func() {
+		pc.lock.Lock()
+		if pc.loading[key] == inProgress {
+			delete(pc.loading, key)
+		}
+		if inProgress.result == nil {
+			inProgress.result = &panelCacheResult{statusCode: fasthttp.StatusInternalServerError}
+		} else if generation == pc.generation {
+			pc.add(key, inProgress.result, utils.GetCurrentTimeInMs())
+		}
+		pc.lock.Unlock()
+		close(inProgress.done)
+	}()
+	inProgress.result = load()
+	return inProgress.result
+}
+
+func (pc *panelCache) refresh(key string, generation uint64, load func() *panelCacheResult) {
+	var result *panelCacheResult
+	defer func() {
+		if r := recover(); r != nil {
+			log.Errorf("panelCache.refresh: failed to refresh the result of a panel query, err=%v", r)
+		}
+		pc.lock.Lock()
+		defer pc.lock.Unlock()
+		if entry, ok := pc.entries[key]; ok {
+			entry.refreshing = false
+		}
+		if result != nil && generation == pc.generation {
+			pc.add(key, result, utils.GetCurrentTimeInMs())
+		}
+	}()
+	result = load()
+}
+
+// Drops every cached result and the loads in progress, the waiters of those loads still get their result
+func (pc *panelCache) clear() {
+	pc.lock.Lock()
+	defer pc.lock.Unlock()
+	pc.entries = make(map[string]*panelCacheEntry)
+	pc.loading = make(map[string]*panelCacheLoad)
+	pc.generation++
+}
+
+// Needs the lock to be held
+func (pc *panelCache) add(key string, result *panelCacheResult, nowMs uint64) {
+	if result.statusCode != fasthttp.StatusOK {
+		return
+	}
+	pc.entries[key] = &panelCacheEntry{result: result, createdMs: nowMs, lastAccessMs: nowMs}
+	if len(pc.entries) <= pc.maxEntries {
+		return
+	}
+	lruKey := ""
+	lruAccessMs := uint64(0)
+	for entryKey, entry := range pc.entries {
+		if lruKey == "" || entry.lastAccessMs < lruAccessMs {
+			lruKey = entryKey
+			lruAccessMs = entry.lastAccessMs
+		}
+	}
+	delete(pc.entries, lruKey)
+}
+
+/*
+The api keys with the same roles can see the same indices and get the same masking, so they share the cached results.
+Admin keys are exempt from the masking policies, so they do not share them with the other keys
+*/
+func getPanelCacheAccessKey(ctx *fasthttp.RequestCtx) string {
+	key := apikeys.GetRequestApiKey(ctx)
+	if key == nil {
+		return ""
+	}
+	roleNames := append([]string(nil), key.Roles...)
+	sort.Strings(roleNames)
+	if key.HasScope(apikeys.SCOPE_ADMIN) {
+		return apikeys.SCOPE_ADMIN + ":" + strings.Join(roleNames, ",")
+	}
+	return strings.Join(roleNames, ",")
+}
+
+// The resolved search text, which the variables have been substituted in, and the time range as it was requested
+func getPanelCacheKey(dbPanelId string, myid uint64, accessKey string, readJSON map[string]interface{}, searchText string,
+	startEpoch uint64, endEpoch uint64, sizeLimit uint64, indexName string, scrollFrom int) (string, error) {
+	if variables, ok := readJSON["variables"]; ok {
+		var err error
+		searchText, err = SubstituteDashboardVariables(searchText, variables, startEpoch, endEpoch)
+		if err != nil {
+			return "", err
+		}
+	}
+	keyParts := []string{
+		dbPanelId,
+		fmt.Sprint(myid),
+		accessKey,
+		fmt.Sprint(readJSON["queryLanguage"]),
+		indexName,
+		searchText,
+		fmt.Sprint(readJSON["startEpoch"]),
+		fmt.Sprint(readJSON["endEpoch"]),
+		fmt.Sprint(sizeLimit),
+		fmt.Sprint(scrollFrom),
+	}
+	return strings.Join(keyParts, "\x00"), nil
+}
+
+// Serves the request from the panel cache, the request runs through ProcessPipeSearchRequest when it is not cached
+func servePanelFromCache(ctx *fasthttp.RequestCtx, pc *panelCache, key string, dbPanelId string, myid uint64) {
+	// the request may be refreshed after it has been responded to, so it can not be used by load
+	requestUri := ctx.Request.URI().String()
+	requestBody := append([]byte(nil), ctx.PostBody()...)
+	apiKey := apikeys.GetRequestApiKey(ctx)
+	load := func() *panelCacheResult {
+		searchCtx := &fasthttp.RequestCtx{}
+		searchCtx.Request.Header.SetMethod("POST")
+		searchCtx.Request.SetRequestURI(requestUri)
+		searchCtx.Request.SetBody(requestBody)
+		searchCtx.SetUserValue("dbPanel-id", dbPanelId)
+		searchCtx.SetUserValue(PANEL_CACHE_BYPASS_KEY, true)
+		if apiKey != nil {
+			searchCtx.SetUserValue(apikeys.API_KEY_CTX_KEY, apiKey)
+		}
+		ProcessPipeSearchRequest(searchCtx, myid)
+		return &panelCacheResult{
+			statusCode: searchCtx.Response.StatusCode(),
+			body:       append([]byte(nil), searchCtx.Response.Body()...),
+		}
+	}
+
+	result := pc.get(key, utils.GetCurrentTimeInMs(), load)
+	ctx.SetStatusCode(result.statusCode)
+	if result.statusCode == fasthttp.StatusOK {
+		ctx.SetContentType(utils.ContentJson)
+	}
+	ctx.SetBody(result.body)
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipesearch
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/siglens/siglens/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func Test_panelCache_get(t *testing.T) {
+	pc := newPanelCache(config.PanelCacheConfig{TTLSecs: 10, StaleSecs: 20})
+	var numLoads int32
+	load := func() *panelCacheResult {
+		n := atomic.AddInt32(&numLoads, 1)
+		return &panelCacheResult{statusCode: fasthttp.StatusOK, body: []byte{byte(n)}}
+	}
+
+	result := pc.get("a", 0, load)
+	assert.Equal(t, []byte{1}, result.body)
+	createdMs := pc.entries["a"].createdMs
+
+	// fresh
+	result = pc.get("a", createdMs+9_000, load)
+	assert.Equal(t, []byte{1}, result.body)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&numLoads))
+
+	// stale, served while it is refreshed in the background
+	result = pc.get("a", createdMs+15_000, load)
+	assert.Equal(t, []byte{1}, result.body)
+	assert.Eventually(t, func() bool {
+		pc.lock.Lock()
+		defer pc.lock.Unlock()
+		return pc.entries["a"].result.body[0] == 2 && !pc.entries["a"].refreshing
+	}, time.Second, time.Millisecond)
+
+	// stale for too long, loaded again
+	createdMs = pc.entries["a"].createdMs
+	result = pc.get("a", createdMs+30_000, load)
+	assert.Equal(t, []byte{3}, result.body)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&numLoads))
+}
+
+func Test_panelCache_errorsNotCached(t *testing.T) {
+	pc := newPanelCache(config.PanelCacheConfig{})
+	numLoads := 0
+	load := func() *panelCacheResult {
+		numLoads++
+		return &panelCacheResult{statusCode: fasthttp.StatusBadRequest, body: []byte("bad query")}
+	}
+	for i := 0; i < 3; i++ {
+		result := pc.get("a", 0, load)
+		assert.Equal(t, fasthttp.StatusBadRequest, result.statusCode)
+	}
+	assert.Equal(t, 3, numLoads)
+	assert.Empty(t, pc.entries)
+}
+
+func Test_panelCache_concurrentLoads(t *testing.T) {
+	pc := newPanelCache(config.PanelCacheConfig{})
+	var numLoads int32
+	release := make(chan struct{})
+	load := func() *panelCacheResult {
+		atomic.AddInt32(&numLoads, 1)
+		<-release
+		return &panelCacheResult{statusCode: fasthttp.StatusOK, body: []byte("result")}
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := pc.get("a", 0, load)
+			assert.Equal(t, []byte("result"), result.body)
+		}()
+	}
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&numLoads) == 1 }, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&numLoads))
+}
+
+func Test_panelCache_loadPanics(t *testing.T) {
+	pc := newPanelCache(config.PanelCacheConfig{})
+	var startOnce sync.Once
+	started := make(chan struct{})
+	release := make(chan struct{})
+	load := func() *panelCacheResult {
+		startOnce.Do(func() { close(started) })
+		<-release
+		panic("load failed")
+	}
+
+	loaderDone := make(chan struct{})
+	go func() {
+		defer close(loaderDone)
+		assert.Panics(t, func() { pc.get("a", 0, load) })
+	}()
+	<-started
+
+	waiterDone := make(chan *panelCacheResult)
+	go func() {
+		waiterDone <- pc.get("a", 0, load)
+	}()
+	// lets the waiter block on the load in progress before it panics
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+
+	select {
+	case result := <-waiterDone:
+		assert.Equal(t, fasthttp.StatusInternalServerError, result.statusCode)
+	case <-time.After(time.Second):
+		t.Fatal("the waiter was not released after load panicked")
+	}
+	<-loaderDone
+	assert.Len(t, pc.loading, 0)
+	assert.Len(t, pc.entries, 0)
+}
+
+func Test_panelCache_clear(t *testing.T) {
+	pc := newPanelCache(config.PanelCacheConfig{})
+	load := func() *panelCacheResult {
+		return &panelCacheResult{statusCode: fasthttp.StatusOK, body: []byte("result")}
+	}
+	pc.get("a", 0, load)
+	assert.Len(t, pc.entries, 1)
+
+	pc.clear()
+	assert.Len(t, pc.entries, 0)
+
+	// a load that was in progress when the cache was cleared is not cached
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		result := pc.get("b", 0, func() *panelCacheResult {
+			<-release
+			return load()
+		})
+		assert.Equal(t, []byte("result"), result.body)
+	}()
+	assert.Eventually(t, func() bool {
+		pc.lock.Lock()
+		defer pc.lock.Unlock()
+		return len(pc.loading) == 1
+	}, time.Second, time.Millisecond)
+	pc.clear()
+	close(release)
+	<-done
+	assert.Len(t, pc.entries, 0)
+	assert.Len(t, pc.loading, 0)
+}
+
+func Test_panelCache_eviction(t *testing.T) {
+	pc := newPanelCache(config.PanelCacheConfig{MaxEntries: 2})
+	for _, key := range []string{"a", "b"} {
+		pc.add(key, &panelCacheResult{statusCode: fasthttp.StatusOK}, 100)
+	}
+	pc.entries["a"].lastAccessMs = 200
+	pc.add("c", &panelCacheResult{statusCode: fasthttp.StatusOK}, 300)
+	assert.Len(t, pc.entries, 2)
+	assert.Contains(t, pc.entries, "a")
+	assert.Contains(t, pc.entries, "c")
+}
+
+func Test_getPanelCacheKey(t *testing.T) {
+	readJSON := map[string]interface{}{"queryLanguage": "Splunk QL", "startEpoch": "now-1h", "endEpoch": "now",
+		"variables": map[string]interface{}{"service": "frontend"}}
+	key1, err := getPanelCacheKey("p1", 0, "", readJSON, "service=$service", 0, 3_600_000, 100, "*", 0)
+	assert.Nil(t, err)
+	key2, err := getPanelCacheKey("p1", 0, "", readJSON, "service=frontend", 10, 3_600_010, 100, "*", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, key1, key2)
+
+	readJSON["variables"] = map[string]interface{}{"service": "backend"}
+	key3, err := getPanelCacheKey("p1", 0, "", readJSON, "service=$service", 0, 3_600_000, 100, "*", 0)
+	assert.Nil(t, err)
+	assert.NotEqual(t, key1, key3)
+
+	key4, err := getPanelCacheKey("p2", 0, "", readJSON, "service=$service", 0, 3_600_000, 100, "*", 0)
+	assert.Nil(t, err)
+	assert.NotEqual(t, key3, key4)
+
+	// the keys with roles do not share the results of the keys with other roles
+	key6, err := getPanelCacheKey("p1", 0, "team-a", readJSON, "service=$service", 0, 3_600_000, 100, "*", 0)
+	assert.Nil(t, err)
+	assert.NotEqual(t, key3, key6)
+
+	readJSON["startEpoch"] = "now-24h"
+	key5, err := getPanelCacheKey("p1", 0, "", readJSON, "service=$service", 0, 3_600_000, 100, "*", 0)
+	assert.Nil(t, err)
+	assert.NotEqual(t, key3, key5)
+
+	readJSON["variables"] = map[string]interface{}{"service": []interface{}{}}
+	_, err = getPanelCacheKey("p1", 0, "", readJSON, "service=$service", 0, 3_600_000, 100, "*", 0)
+	assert.NotNil(t, err)
+}
@@ -54,6 +54,7 @@ type PipeSearchWSUpdateResponse struct {
 	GroupByCols         []string                `json:"groupByCols,omitempty"`
 	Qtype               string                  `json:"qtype,omitempty"`
 	BucketCount         int                     `json:"bucketCount,omitempty"`
+	IsTimechart         bool                    `json:"isTimechart,omitempty"`
 }
 
 type PipeSearchCompleteResponse struct {
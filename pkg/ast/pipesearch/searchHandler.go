@@ -29,6 +29,7 @@ import (
 
 	jsoniter "github.com/json-iterator/go"
 	"github.com/siglens/siglens/pkg/alerts/alertutils"
+	"github.com/siglens/siglens/pkg/apikeys"
 	rutils "github.com/siglens/siglens/pkg/readerUtils"
 	"github.com/siglens/siglens/pkg/segment"
 	"github.com/siglens/siglens/pkg/segment/query"
@@ -211,7 +212,32 @@ func ParseSearchBody(jsonSource map[string]interface{}, nowTs uint64) (string, u
 	return searchText, startEpoch, endEpoch, finalSize, indexName, scrollFrom
 }
 
-func ProcessAlertsPipeSearchRequest(queryParams alertutils.QueryParams) int {
+// Returns the parallelism hint of the search body, the block workers of each segment search. 0 if it has none
+func parseParallelism(jsonSource map[string]interface{}) int64 {
+	switch val := jsonSource["parallelism"].(type) {
+	case nil:
+		return 0
+	case json.Number:
+		parallelism, err := val.Int64()
+		if err != nil {
+			log.Errorf("parseParallelism: parallelism %v is not an integer, err=%v", val, err)
+			return 0
+		}
+		return parallelism
+	case float64:
+		return int64(val)
+	default:
+		log.Errorf("parseParallelism: unknown type for parallelism=%T", val)
+		return 0
+	}
+}
+
+/*
+Runs the query of an alert and returns the value that is compared against its threshold. For AggregateValue it is
+the first aggregate of the query. For ResultCount it is the number of groups of a group by query, else the exact
+number of matched records. The relative times of the query, such as now-15m, are relative to nowTs
+*/
+func ProcessAlertsPipeSearchRequest(queryParams alertutils.QueryParams, valueType alertutils.AlertValueType, nowTs uint64) (float64, error) {
 
 	queryData := fmt.Sprintf(`{
 		"from": "0",
@@ -226,93 +252,72 @@ func ProcessAlertsPipeSearchRequest(queryParams alertutils.QueryParams) int {
 	dbPanelId := "-1"
 	queryStart := time.Now()
 
-	rawJSON := []byte(queryData)
-	if rawJSON == nil {
-		log.Errorf("ALERTSERVICE: ProcessAlertsPipeSearchRequest: received empty search request body ")
-		return -1
-	}
-
 	qid := rutils.GetNextQid()
 	readJSON := make(map[string]interface{})
 	var jsonc = jsoniter.ConfigCompatibleWithStandardLibrary
-	decoder := jsonc.NewDecoder(bytes.NewReader(rawJSON))
+	decoder := jsonc.NewDecoder(bytes.NewReader([]byte(queryData)))
 	decoder.UseNumber()
 	err := decoder.Decode(&readJSON)
 	if err != nil {
 		log.Errorf("qid=%v, ALERTSERVICE: ProcessAlertsPipeSearchRequest: failed to decode search request body! Err=%+v", qid, err)
+		return 0, err
 	}
 
-	nowTs := utils.GetCurrentTimeInMs()
 	searchText, startEpoch, endEpoch, sizeLimit, indexNameIn, scrollFrom := ParseSearchBody(readJSON, nowTs)
 
-	if scrollFrom > 10_000 {
-		return -1
-	}
-
 	ti := structs.InitTableInfo(indexNameIn, orgid, false)
 	log.Infof("qid=%v, ALERTSERVICE: ProcessAlertsPipeSearchRequest: index=[%s], searchString=[%v] ",
 		qid, ti.String(), searchText)
 
 	queryLanguageType := readJSON["queryLanguage"]
-	var simpleNode *structs.ASTNode
-	var aggs *structs.QueryAggregators
-	if queryLanguageType == "Pipe QL" {
-		simpleNode, aggs, err = ParseRequest(searchText, startEpoch, endEpoch, qid, "Pipe QL", indexNameIn)
-		if err != nil {
-			log.Errorf("qid=%v, ALERTSERVICE: ProcessAlertsPipeSearchRequest: Error parsing query err=%+v", qid, err)
-			return -1
-		}
+	if queryLanguageType != "Pipe QL" && queryLanguageType != "Splunk QL" {
+		log.Errorf("qid=%v, ALERTSERVICE: ProcessAlertsPipeSearchRequest: unknown queryLanguageType: %v", qid, queryLanguageType)
+		return 0, fmt.Errorf("ProcessAlertsPipeSearchRequest: unknown query language %v", queryLanguageType)
+	}
+	simpleNode, aggs, err := ParseRequest(searchText, startEpoch, endEpoch, qid, queryLanguageType.(string), indexNameIn)
+	if err != nil {
+		log.Errorf("qid=%v, ALERTSERVICE: ProcessAlertsPipeSearchRequest: Error parsing query err=%+v", qid, err)
+		return 0, err
+	}
 
-		if aggs != nil && (aggs.GroupByRequest != nil || aggs.MeasureOperations != nil) {
-			sizeLimit = 0
-		}
-		qc := structs.InitQueryContextWithTableInfo(ti, sizeLimit, scrollFrom, orgid, false)
-		result := segment.ExecuteQuery(simpleNode, aggs, qid, qc)
-		httpRespOuter := getQueryResponseJson(result, indexNameIn, queryStart, sizeLimit, qid, aggs, result.TotalRRCCount, dbPanelId)
-
-		if httpRespOuter.MeasureResults != nil && len(httpRespOuter.MeasureResults) > 0 && httpRespOuter.MeasureResults[0].MeasureVal != nil {
-			measureVal, ok := httpRespOuter.MeasureResults[0].MeasureVal[queryParams.QueryText].(string)
-			if ok {
-				measureVal = strings.ReplaceAll(measureVal, ",", "")
-
-				measureNum, err := strconv.Atoi(measureVal)
-				if err != nil {
-					log.Errorf("ALERTSERVICE: ProcessAlertsPipeSearchRequest Error parsing int from a string: %s", err)
-					return -1
-				}
-				return measureNum
-			}
-		}
-	} else if queryLanguageType == "Splunk QL" {
-		simpleNode, aggs, err = ParseRequest(searchText, startEpoch, endEpoch, qid, "Splunk QL", indexNameIn)
-		if err != nil {
-			log.Errorf("qid=%v, ALERTSERVICE: ProcessAlertsPipeSearchRequest: Error parsing query err=%+v", qid, err)
-			return -1
+	isAggQuery := aggs != nil && (aggs.GroupByRequest != nil || aggs.MeasureOperations != nil)
+	if isAggQuery {
+		sizeLimit = 0
+	} else if valueType == alertutils.ResultCount {
+		// the records are not needed, but all of them must be counted
+		sizeLimit = 0
+		if aggs != nil {
+			aggs.EarlyExit = false
 		}
+	} else {
+		return 0, fmt.Errorf("ProcessAlertsPipeSearchRequest: query %v has no aggregation", queryParams.QueryText)
+	}
 
-		if aggs != nil && (aggs.GroupByRequest != nil || aggs.MeasureOperations != nil) {
-			sizeLimit = 0
+	qc := structs.InitQueryContextWithTableInfo(ti, sizeLimit, scrollFrom, orgid, false)
+	result := segment.ExecuteQuery(simpleNode, aggs, qid, qc)
+	if valueType == alertutils.ResultCount {
+		if aggs != nil && aggs.GroupByRequest != nil {
+			return float64(len(result.MeasureResults)), nil
 		}
-		qc := structs.InitQueryContextWithTableInfo(ti, sizeLimit, scrollFrom, orgid, false)
-		result := segment.ExecuteQuery(simpleNode, aggs, qid, qc)
-		httpRespOuter := getQueryResponseJson(result, indexNameIn, queryStart, sizeLimit, qid, aggs, result.TotalRRCCount, dbPanelId)
-		if httpRespOuter.MeasureResults != nil && len(httpRespOuter.MeasureResults) > 0 && httpRespOuter.MeasureResults[0].MeasureVal != nil {
-			measureVal, ok := httpRespOuter.MeasureResults[0].MeasureVal[httpRespOuter.MeasureFunctions[0]].(string)
-			if ok {
-				measureVal = strings.ReplaceAll(measureVal, ",", "")
-				measureNum, err := strconv.ParseFloat(measureVal, 64)
-				if err != nil {
-					log.Errorf("ALERTSERVICE: ProcessAlertsPipeSearchRequest Error parsing int from a string: %s", err)
-					return -1
-				}
-				return int(measureNum)
-			}
+		if result.TotalResults == nil {
+			return 0, fmt.Errorf("ProcessAlertsPipeSearchRequest: no result count for query %v", queryParams.QueryText)
 		}
-	} else {
-		log.Infof("ProcessAlertsPipeSearchRequest: unknown queryLanguageType: %v;", queryLanguageType)
+		return float64(result.TotalResults.TotalCount), nil
 	}
 
-	return -1
+	httpRespOuter := getQueryResponseJson(result, indexNameIn, queryStart, sizeLimit, qid, aggs, result.TotalRRCCount, dbPanelId)
+	// no results means no value, which is what the HasNoValue condition checks for
+	if len(httpRespOuter.MeasureResults) == 0 || len(httpRespOuter.MeasureFunctions) == 0 ||
+		httpRespOuter.MeasureResults[0].MeasureVal == nil {
+		return 0, nil
+	}
+	measureVal := fmt.Sprint(httpRespOuter.MeasureResults[0].MeasureVal[httpRespOuter.MeasureFunctions[0]])
+	measureNum, err := strconv.ParseFloat(strings.ReplaceAll(measureVal, ",", ""), 64)
+	if err != nil {
+		log.Errorf("ALERTSERVICE: ProcessAlertsPipeSearchRequest Error parsing float from a string: %s", err)
+		return 0, err
+	}
+	return measureNum, nil
 }
 
 func ProcessPipeSearchRequest(ctx *fasthttp.RequestCtx, myid uint64) {
@@ -349,10 +354,34 @@ func ProcessPipeSearchRequest(ctx *fasthttp.RequestCtx, myid uint64) {
 		return
 	}
 
+	// the panels of the dashboards are served from the panel cache, but not the panel that is being edited
+	if pc := getPanelCache(); pc != nil && dbPanelId != "" && dbPanelId != "-1" && ctx.UserValue(PANEL_CACHE_BYPASS_KEY) == nil {
+		key, err := getPanelCacheKey(dbPanelId, myid, getPanelCacheAccessKey(ctx), readJSON, searchText, startEpoch, endEpoch, sizeLimit, indexNameIn, scrollFrom)
+		if err == nil {
+			servePanelFromCache(ctx, pc, key, dbPanelId, myid)
+			return
+		}
+	}
+
+	if variables, ok := readJSON["variables"]; ok {
+		searchText, err = SubstituteDashboardVariables(searchText, variables, startEpoch, endEpoch)
+		if err != nil {
+			ctx.SetStatusCode(fasthttp.StatusBadRequest)
+			_, wErr := ctx.WriteString(err.Error())
+			if wErr != nil {
+				log.Errorf("qid=%v, ProcessPipeSearchRequest: could not write error message err=%v", qid, wErr)
+			}
+			log.Errorf("qid=%v, ProcessPipeSearchRequest: Error substituting dashboard variables err=%+v", qid, err)
+			return
+		}
+	}
+
 	ti := structs.InitTableInfo(indexNameIn, myid, false)
 	log.Infof("qid=%v, ProcessPipeSearchRequest: index=[%s], searchString=[%v] ",
 		qid, ti.String(), searchText)
 
+	// resolved before the parsing, as the spansearch command searches the spans while it is parsed
+	indexFilter := apikeys.GetIndexFilter(ctx, apikeys.ACCESS_READ)
 	queryLanguageType := readJSON["queryLanguage"]
 	var simpleNode *structs.ASTNode
 	var aggs *structs.QueryAggregators
@@ -364,6 +393,14 @@ func ProcessPipeSearchRequest(ctx *fasthttp.RequestCtx, myid uint64) {
 		simpleNode, aggs, err = ParseRequest(searchText, startEpoch, endEpoch, qid, "Log QL", indexNameIn)
 	} else if queryLanguageType == "Splunk QL" {
 		simpleNode, aggs, err = ParseRequest(searchText, startEpoch, endEpoch, qid, "Splunk QL", indexNameIn)
+		if err == nil && aggs.SpanSearch != nil {
+			indexNameIn = SPANS_INDEX
+			ti = structs.InitTableInfo(indexNameIn, myid, false)
+			err = applySpanSearch(simpleNode, aggs, indexFilter, qid, myid)
+		}
+	} else if queryLanguageType == "PPL" {
+		simpleNode, aggs, indexNameIn, err = ParsePPLRequest(searchText, startEpoch, endEpoch, qid)
+		ti = structs.InitTableInfo(indexNameIn, myid, false)
 	} else {
 		log.Infof("ProcessPipeSearchRequest: unknown queryLanguageType: %v; using Pipe QL instead", queryLanguageType)
 		simpleNode, aggs, err = ParseRequest(searchText, startEpoch, endEpoch, qid, "Pipe QL", indexNameIn)
@@ -389,9 +426,25 @@ func ProcessPipeSearchRequest(ctx *fasthttp.RequestCtx, myid uint64) {
 		sizeLimit = aggs.OutputTransforms.MaxRows
 	}
 
+	masker := apikeys.GetResultMasker(apikeys.GetRequestApiKey(ctx), ti.GetQueryTables(), myid)
+	err = masker.CheckDerivedFields(aggs)
+	if err != nil {
+		ctx.SetStatusCode(fasthttp.StatusForbidden)
+		_, wErr := ctx.WriteString(err.Error())
+		if wErr != nil {
+			log.Errorf("qid=%v, ProcessPipeSearchRequest: could not write error message err=%v", qid, wErr)
+		}
+		log.Errorf("qid=%v, ProcessPipeSearchRequest: query derives a masked field err=%v", qid, err)
+		return
+	}
+
 	qc := structs.InitQueryContextWithTableInfo(ti, sizeLimit, scrollFrom, myid, false)
+	qc.IndexFilter = indexFilter
+	qc.Parallelism = parseParallelism(readJSON)
 	result := segment.ExecuteQuery(simpleNode, aggs, qid, qc)
 	httpRespOuter := getQueryResponseJson(result, indexNameIn, queryStart, sizeLimit, qid, aggs, result.TotalRRCCount, dbPanelId)
+	masker.MaskRecords(httpRespOuter.Hits.Hits)
+	masker.MaskBuckets(httpRespOuter.MeasureResults, httpRespOuter.GroupByCols)
 	utils.WriteJsonResponse(ctx, httpRespOuter)
 
 	ctx.SetStatusCode(fasthttp.StatusOK)
@@ -17,6 +17,7 @@ limitations under the License.
 package pipesearch
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -47,6 +48,14 @@ func Test_parseSearchBody(t *testing.T) {
 	assert.Equal(t, 500, scroll, "expected=%v, actual=%v", 500, scroll)
 }
 
+func Test_parseParallelism(t *testing.T) {
+	assert.Equal(t, int64(0), parseParallelism(map[string]interface{}{}))
+	assert.Equal(t, int64(8), parseParallelism(map[string]interface{}{"parallelism": json.Number("8")}))
+	assert.Equal(t, int64(2), parseParallelism(map[string]interface{}{"parallelism": float64(2)}))
+	assert.Equal(t, int64(0), parseParallelism(map[string]interface{}{"parallelism": json.Number("1.5")}))
+	assert.Equal(t, int64(0), parseParallelism(map[string]interface{}{"parallelism": "8"}))
+}
+
 func Test_parseAlphaNumTime(t *testing.T) {
 
 	nowTs := uint64(1659874108987)
@@ -22,6 +22,7 @@ import (
 
 	"github.com/siglens/siglens/pkg/ast"
 	"github.com/siglens/siglens/pkg/ast/logql"
+	"github.com/siglens/siglens/pkg/ast/ppl"
 	"github.com/siglens/siglens/pkg/ast/spl"
 	"github.com/siglens/siglens/pkg/ast/sql"
 	"github.com/siglens/siglens/pkg/config"
@@ -42,13 +43,47 @@ func ParseRequest(searchText string, startEpoch, endEpoch uint64, qid uint64, qu
 	if parsingError != nil {
 		return nil, nil, parsingError
 	}
+	if queryAggs != nil && queryAggs.SpanSearch != nil {
+		indexName = SPANS_INDEX
+	}
+	queryAggs, err := prepareQuery(boolNode, queryAggs, startEpoch, endEpoch, qid, queryLanguageType, indexName)
+	if err != nil {
+		return nil, nil, err
+	}
+	return boolNode, queryAggs, nil
+}
 
+// Parses a PPL query, which names the indices it reads with source= instead of taking them from the request.
+// Returns the indices along with the query
+func ParsePPLRequest(searchText string, startEpoch, endEpoch uint64, qid uint64) (*ASTNode, *QueryAggregators, string, error) {
+	boolNode, queryAggs, err := ParseQuery(searchText, qid, "PPL")
+	if err != nil {
+		return nil, nil, "", err
+	}
+	if queryAggs == nil || queryAggs.TableName == "" {
+		return nil, nil, "", errors.New("a PPL query must start with source=<index>")
+	}
+	indexName := queryAggs.TableName
+	queryAggs, err = prepareQuery(boolNode, queryAggs, startEpoch, endEpoch, qid, "PPL", indexName)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return boolNode, queryAggs, indexName, nil
+}
+
+// Sets the time range of the parsed query and how its aggregations are run
+func prepareQuery(boolNode *ASTNode, queryAggs *QueryAggregators, startEpoch, endEpoch uint64, qid uint64,
+	queryLanguageType string, indexName string) (*QueryAggregators, error) {
 	tRange, err := ast.ParseTimeRange(startEpoch, endEpoch, queryAggs, qid)
 	if err != nil {
 		log.Errorf("qid=%d, Search ParseRequest: parseTimeRange error: %v", qid, err)
-		return nil, nil, err
+		return nil, err
 	}
+	ast.NarrowTimeRangeByTimestampFilters(boolNode, tRange, qid)
 	boolNode.TimeRange = tRange
+	if queryLanguageType == "Log QL" && indexName == ast.LokiIndexName {
+		ast.TargetLokiLineFilters(boolNode)
+	}
 
 	//aggs
 	if queryAggs != nil {
@@ -82,7 +117,7 @@ func ParseRequest(searchText string, startEpoch, endEpoch uint64, qid uint64, qu
 
 	segment.LogASTNode(queryLanguageType+"query parser", boolNode, qid)
 	segment.LogQueryAggsNode(queryLanguageType+"aggs parser", queryAggs, qid)
-	return boolNode, queryAggs, nil
+	return queryAggs, nil
 }
 
 func ParseQuery(searchText string, qid uint64, queryLanguageType string) (*ASTNode, *QueryAggregators, error) {
@@ -130,6 +165,8 @@ func parsePipeSearch(searchText string, queryLanguage string, qid uint64) (*ASTN
 		res, err = logql.Parse("", []byte(searchText))
 	case "Splunk QL":
 		res, err = spl.Parse("", []byte(searchText))
+	case "PPL":
+		res, err = ppl.Parse("", []byte(searchText))
 	default:
 		log.Errorf("qid=%d, parsePipeSearch: Unknown queryLanguage: %v", qid, queryLanguage)
 	}
@@ -156,7 +193,15 @@ func parsePipeSearch(searchText string, queryLanguage string, qid uint64) (*ASTN
 		log.Errorf("qid=%d, parsePipeSearch: SearchQueryToASTnode error: %v", qid, err)
 		return nil, nil, err
 	}
+	tableName := res.(ast.QueryStruct).TableName
+	spanSearch := res.(ast.QueryStruct).SpanSearch
 	if pipeCommandsJson == nil {
+		if tableName != "" || spanSearch != nil {
+			pipeCommands := structs.InitDefaultQueryAggregations()
+			pipeCommands.TableName = tableName
+			pipeCommands.SpanSearch = spanSearch
+			return boolNode, pipeCommands, nil
+		}
 		return boolNode, nil, nil
 	}
 	pipeCommands, err := searchPipeCommandsToASTnode(pipeCommandsJson, qid)
@@ -165,6 +210,8 @@ func parsePipeSearch(searchText string, queryLanguage string, qid uint64) (*ASTN
 		log.Errorf("qid=%d, parsePipeSearch: SearchQueryToASTnode error: %v", qid, err)
 		return nil, nil, err
 	}
+	pipeCommands.TableName = tableName
+	pipeCommands.SpanSearch = spanSearch
 	return boolNode, pipeCommands, nil
 }
 
@@ -372,10 +419,16 @@ func parseColumnsCmd(node *structs.OutputTransforms, qid uint64) (*QueryAggregat
 		if node.LetColumns.RenameColRequest != nil {
 			aggNode.OutputTransforms.LetColumns.RenameColRequest = node.LetColumns.RenameColRequest
 		}
+		if node.LetColumns.LookupColRequest != nil {
+			aggNode.OutputTransforms.LetColumns.LookupColRequest = node.LetColumns.LookupColRequest
+		}
 	}
 	if node.FilterRows != nil {
 		aggNode.OutputTransforms.FilterRows = node.FilterRows
 	}
+	if node.SimilarTo != nil {
+		aggNode.OutputTransforms.SimilarTo = node.SimilarTo
+	}
 
 	aggNode.OutputTransforms.MaxRows = node.MaxRows
 
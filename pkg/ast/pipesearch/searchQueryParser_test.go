@@ -0,0 +1,101 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipesearch
+
+import (
+	"testing"
+
+	"github.com/siglens/siglens/pkg/ast"
+	"github.com/siglens/siglens/pkg/config"
+	"github.com/siglens/siglens/pkg/segment/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_timestampFiltersNarrowTimeRange(t *testing.T) {
+	config.InitializeTestingConfig()
+
+	node, _, err := ParseRequest("timestamp>=1000 timestamp<2000 latency>5 | stats count", 500, 5000, 0, "Splunk QL", "test")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1000), node.TimeRange.StartEpochMs)
+	assert.Equal(t, uint64(1999), node.TimeRange.EndEpochMs)
+	// only the latency filter is left to check on the records
+	criteria := node.AndFilterCondition.FilterCriteria
+	assert.Len(t, criteria, 1)
+	assert.Equal(t, "latency", criteria[0].ExpressionFilter.LeftInput.Expression.LeftInput.ColumnName)
+
+	node, _, err = ParseRequest("timestamp=3000", 500, 5000, 0, "Splunk QL", "test")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(3000), node.TimeRange.StartEpochMs)
+	assert.Equal(t, uint64(3000), node.TimeRange.EndEpochMs)
+	criteria = node.AndFilterCondition.FilterCriteria
+	assert.Len(t, criteria, 1)
+	assert.Equal(t, "*", criteria[0].ExpressionFilter.LeftInput.Expression.LeftInput.ColumnName)
+
+	// the search range is not widened
+	node, _, err = ParseRequest("timestamp>100 timestamp<=9000.5", 500, 5000, 0, "Splunk QL", "test")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(500), node.TimeRange.StartEpochMs)
+	assert.Equal(t, uint64(5000), node.TimeRange.EndEpochMs)
+
+	node, _, err = ParseRequest("timestamp>6000 | stats count", 500, 5000, 0, "Splunk QL", "test")
+	assert.Nil(t, err)
+	assert.Greater(t, node.TimeRange.StartEpochMs, node.TimeRange.EndEpochMs, "no time passes the filter")
+
+	// a comparison that not every record has to pass does not change the time range
+	node, _, err = ParseRequest("timestamp>3000 OR latency>5", 500, 5000, 0, "Splunk QL", "test")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(500), node.TimeRange.StartEpochMs)
+	assert.Equal(t, uint64(5000), node.TimeRange.EndEpochMs)
+
+	node, _, err = ParseRequest("timestamp!=3000", 500, 5000, 0, "Splunk QL", "test")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(500), node.TimeRange.StartEpochMs)
+	assert.Equal(t, uint64(5000), node.TimeRange.EndEpochMs)
+}
+
+func getFilterColumns(node *structs.ASTNode, columns []string) []string {
+	for _, condition := range []*structs.Condition{node.AndFilterCondition, node.OrFilterCondition, node.ExclusionFilterCondition} {
+		if condition == nil {
+			continue
+		}
+		for _, criteria := range condition.FilterCriteria {
+			if criteria.ExpressionFilter != nil {
+				columns = append(columns, criteria.ExpressionFilter.LeftInput.Expression.LeftInput.ColumnName)
+			} else if criteria.MatchFilter != nil {
+				columns = append(columns, criteria.MatchFilter.MatchColumn)
+			}
+		}
+		for _, nestedNode := range condition.NestedNodes {
+			columns = getFilterColumns(nestedNode, columns)
+		}
+	}
+	return columns
+}
+
+func Test_lokiLineFilters(t *testing.T) {
+	config.InitializeTestingConfig()
+	query := `{job="varlogs"} |= "error" |~ "time(out)?"`
+
+	// the line filters search all the columns of the other indices
+	node, _, err := ParseRequest(query, 500, 5000, 0, "Log QL", "test")
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{"job", "*", "*"}, getFilterColumns(node, nil))
+
+	node, _, err = ParseRequest(query, 500, 5000, 0, "Log QL", ast.LokiIndexName)
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{"job", ast.LogLineColumn, ast.LogLineColumn}, getFilterColumns(node, nil))
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipesearch
+
+import (
+	"sort"
+
+	"github.com/siglens/siglens/pkg/ast"
+	rutils "github.com/siglens/siglens/pkg/readerUtils"
+	"github.com/siglens/siglens/pkg/segment"
+	"github.com/siglens/siglens/pkg/segment/structs"
+	. "github.com/siglens/siglens/pkg/segment/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+// The spansearch command of Splunk QL searches the spans of this index, whatever the index of the request is
+const SPANS_INDEX = "traces"
+
+/*
+Replaces the filter of a spansearch that returns whole traces with the trace ids of its matching spans, which takes a
+first pass over the spans. The first pass searches only the indices that indexFilter allows, like the query itself.
+Other queries are left as they are
+*/
+func applySpanSearch(simpleNode *structs.ASTNode, aggs *structs.QueryAggregators, indexFilter func(indexName string) bool,
+	qid uint64, orgid uint64) error {
+	if aggs == nil || aggs.SpanSearch == nil || !aggs.SpanSearch.ReturnTraces {
+		return nil
+	}
+
+	traceIds, err := getMatchingTraceIds(simpleNode, aggs.SpanSearch.MaxTraces, indexFilter, orgid)
+	if err != nil {
+		log.Errorf("qid=%v, applySpanSearch: failed to get the matching traces, err=%v", qid, err)
+		return err
+	}
+	if len(traceIds) == 0 {
+		// no span matches, so the filter itself returns no spans
+		return nil
+	}
+
+	criteria := make([]*structs.FilterCriteria, 0, len(traceIds))
+	for _, traceId := range traceIds {
+		criteria = append(criteria, ast.CreateTermFilterCriteria("trace_id", traceId, Equals, qid))
+	}
+	simpleNode.AndFilterCondition = nil
+	simpleNode.OrFilterCondition = &structs.Condition{FilterCriteria: criteria}
+	simpleNode.ExclusionFilterCondition = nil
+	return nil
+}
+
+// Returns up to maxTraces ids of the traces that have a span matching the filter, in sorted order
+func getMatchingTraceIds(simpleNode *structs.ASTNode, maxTraces int, indexFilter func(indexName string) bool,
+	orgid uint64) ([]string, error) {
+	qid := rutils.GetNextQid()
+	traceNode := &structs.ASTNode{
+		AndFilterCondition:       simpleNode.AndFilterCondition,
+		OrFilterCondition:        simpleNode.OrFilterCondition,
+		ExclusionFilterCondition: simpleNode.ExclusionFilterCondition,
+		TimeRange:                simpleNode.TimeRange,
+	}
+	aggs := &structs.QueryAggregators{
+		PipeCommandType: structs.GroupByType,
+		GroupByRequest: &structs.GroupByRequest{
+			GroupByColumns:    []string{"trace_id"},
+			MeasureOperations: []*structs.MeasureAggregator{{MeasureCol: "*", MeasureFunc: Count}},
+			BucketCount:       10_000,
+		},
+	}
+
+	ti := structs.InitTableInfo(SPANS_INDEX, orgid, false)
+	qc := structs.InitQueryContextWithTableInfo(ti, 0, 0, orgid, false)
+	qc.IndexFilter = indexFilter
+	result := segment.ExecuteQuery(traceNode, aggs, qid, qc)
+	if len(result.ErrList) > 0 {
+		log.Errorf("qid=%v, getMatchingTraceIds: the trace id search failed, errs=%v", qid, result.ErrList)
+		return nil, result.ErrList[0]
+	}
+
+	traceIds := make([]string, 0)
+	for _, bucket := range result.MeasureResults {
+		if len(bucket.GroupByValues) > 0 && bucket.GroupByValues[0] != "" {
+			traceIds = append(traceIds, bucket.GroupByValues[0])
+		}
+	}
+	sort.Strings(traceIds)
+	if len(traceIds) > maxTraces {
+		log.Infof("qid=%v, getMatchingTraceIds: %v traces match, returning the spans of the first %v",
+			qid, len(traceIds), maxTraces)
+		traceIds = traceIds[:maxTraces]
+	}
+	return traceIds, nil
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipesearch
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/siglens/siglens/pkg/config"
+	"github.com/siglens/siglens/pkg/segment/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseSpanSearch(t *testing.T) {
+	node, aggs, err := ParseQuery(`spansearch service=frontend operation="GET /cart" tag.http.status_code=500 `+
+		`minduration=100ms maxduration=2s error=true | stats count BY name`, 0, "Splunk QL")
+	assert.Nil(t, err)
+	assert.Equal(t, &structs.SpanSearchRequest{MaxTraces: structs.DEFAULT_SPAN_SEARCH_MAX_TRACES}, aggs.SpanSearch)
+	assert.NotNil(t, aggs.GroupByRequest)
+	columns := getFilterColumns(node, nil)
+	sort.Strings(columns)
+	assert.Equal(t, []string{"duration", "duration", "http.status_code", "name", "service", "status"}, columns)
+
+	node, aggs, err = ParseQuery(`spansearch return=traces maxtraces=20`, 0, "Splunk QL")
+	assert.Nil(t, err)
+	assert.Equal(t, &structs.SpanSearchRequest{ReturnTraces: true, MaxTraces: 20}, aggs.SpanSearch)
+	assert.Equal(t, []string{"*"}, getFilterColumns(node, nil))
+
+	// a search for the word is not a span search
+	_, aggs, err = ParseQuery(`spansearches=1`, 0, "Splunk QL")
+	assert.Nil(t, err)
+	assert.Nil(t, aggs)
+
+	for _, query := range []string{
+		"spansearch service",
+		"spansearch foo=bar",
+		"spansearch minduration=abc",
+		"spansearch error=maybe",
+		"spansearch return=logs",
+		"spansearch maxtraces=0",
+		`spansearch tag.bad*key=1`,
+		`spansearch service="frontend`,
+		"service=frontend | spansearch",
+	} {
+		_, _, err = ParseQuery(query, 0, "Splunk QL")
+		assert.NotNil(t, err, query)
+	}
+}
+
+func Test_applySpanSearch(t *testing.T) {
+	config.InitializeTestingConfig()
+	node, aggs, err := ParseRequest(`spansearch service=frontend`, 500, 5000, 0, "Splunk QL", "logs")
+	assert.Nil(t, err)
+
+	// the matching spans are returned as they are, without a trace id pass
+	err = applySpanSearch(node, aggs, nil, 0, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"service"}, getFilterColumns(node, nil))
+}
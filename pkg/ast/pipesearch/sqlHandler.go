@@ -0,0 +1,397 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipesearch
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/siglens/siglens/pkg/apikeys"
+	"github.com/siglens/siglens/pkg/ast/sql"
+	rutils "github.com/siglens/siglens/pkg/readerUtils"
+	"github.com/siglens/siglens/pkg/segment"
+	"github.com/siglens/siglens/pkg/segment/query/metadata"
+	"github.com/siglens/siglens/pkg/segment/structs"
+	"github.com/siglens/siglens/pkg/utils"
+	vtable "github.com/siglens/siglens/pkg/virtualtable"
+	log "github.com/sirupsen/logrus"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	SQL_TYPE_TEXT    = "text"
+	SQL_TYPE_LONG    = "long"
+	SQL_TYPE_DOUBLE  = "double"
+	SQL_TYPE_BOOLEAN = "boolean"
+)
+
+/*
+Handles /api/sql, the body is {"query": "SELECT host, count(*) FROM web-logs WHERE status >= 500 GROUP BY host
+ORDER BY count(*) DESC LIMIT 10", "startEpoch": "now-1h", "endEpoch": "now"}. The table of the FROM clause is the
+index, every index is searched without one. SHOW TABLES and SHOW COLUMNS IN <index> are supported as well.
+The results are returned as a schema and rows
+*/
+func ProcessSqlQueryRequest(ctx *fasthttp.RequestCtx, myid uint64) {
+	rawJSON := ctx.PostBody()
+	if rawJSON == nil {
+		log.Errorf("ProcessSqlQueryRequest: received empty request body")
+		SetBadMsg(ctx)
+		return
+	}
+	qid := rutils.GetNextQid()
+
+	readJSON := make(map[string]interface{})
+	var jsonc = jsoniter.ConfigCompatibleWithStandardLibrary
+	decoder := jsonc.NewDecoder(bytes.NewReader(rawJSON))
+	decoder.UseNumber()
+	err := decoder.Decode(&readJSON)
+	if err != nil {
+		log.Errorf("qid=%v, ProcessSqlQueryRequest: failed to decode request body, err=%v", qid, err)
+		writeSqlErrorResponse(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	sqlText, _ := readJSON["query"].(string)
+	if strings.TrimSpace(sqlText) == "" {
+		writeSqlErrorResponse(ctx, fasthttp.StatusBadRequest, "the query is missing")
+		return
+	}
+	_, startEpoch, endEpoch, _, _, _ := ParseSearchBody(readJSON, utils.GetCurrentTimeInMs())
+
+	boolNode, aggs, err := ParseQuery(sqlText, qid, "SQL")
+	if err != nil {
+		log.Errorf("qid=%v, ProcessSqlQueryRequest: failed to parse query=%v, err=%v", qid, sqlText, err)
+		writeSqlErrorResponse(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	if aggs.ShowRequest != nil {
+		processSqlShowRequest(ctx, aggs.ShowRequest, myid)
+		return
+	}
+
+	// the engine does not sort the buckets, so the aggregations are sorted and limited once all of them are computed
+	isAggsQuery := aggs.GroupByRequest != nil || aggs.MeasureOperations != nil
+	orderBy := aggs.Sort
+	rowLimit := aggs.BucketLimit
+	if isAggsQuery && orderBy != nil {
+		aggs.BucketLimit = 0
+	}
+	indexName := aggs.TableName
+	aggs, err = prepareQuery(boolNode, aggs, startEpoch, endEpoch, qid, "SQL", indexName)
+	if err != nil {
+		writeSqlErrorResponse(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	sizeLimit := uint64(rowLimit)
+	if isAggsQuery {
+		sizeLimit = 0
+	}
+	log.Infof("qid=%v, ProcessSqlQueryRequest: index=[%v], query=[%v]", qid, indexName, sqlText)
+
+	ti := structs.InitTableInfo(indexName, myid, false)
+	masker := apikeys.GetResultMasker(apikeys.GetRequestApiKey(ctx), ti.GetQueryTables(), myid)
+	err = masker.CheckDerivedFields(aggs)
+	if err != nil {
+		writeSqlErrorResponse(ctx, fasthttp.StatusForbidden, err.Error())
+		return
+	}
+	qc := structs.InitQueryContextWithTableInfo(ti, sizeLimit, 0, myid, false)
+	qc.IndexFilter = apikeys.GetIndexFilter(ctx, apikeys.ACCESS_READ)
+	result := segment.ExecuteQuery(boolNode, aggs, qid, qc)
+
+	var resp *sql.JDBCElasticSQLSearchResponse
+	if isAggsQuery {
+		masker.MaskBuckets(result.MeasureResults, result.GroupByCols)
+		resp, err = getSqlAggsResponse(result.MeasureResults, result.GroupByCols, result.MeasureFunctions, orderBy, rowLimit)
+		if err != nil {
+			writeSqlErrorResponse(ctx, fasthttp.StatusBadRequest, err.Error())
+			return
+		}
+	} else {
+		records, allCols, err := convertRRCsToJSONResponse(result.AllRecords, sizeLimit, qid, result.SegEncToKey, aggs)
+		if err != nil {
+			result.ErrList = append(result.ErrList, err)
+		}
+		masker.MaskRecords(records)
+		resp = getSqlRecordsResponse(records, getSqlSelectedColumns(aggs, allCols))
+	}
+	for _, err := range result.ErrList {
+		resp.Errors = append(resp.Errors, err.Error())
+	}
+	if len(resp.Errors) > 0 {
+		resp.Status = fasthttp.StatusInternalServerError
+	}
+	ctx.SetStatusCode(resp.Status)
+	utils.WriteJsonResponse(ctx, resp)
+}
+
+func writeSqlErrorResponse(ctx *fasthttp.RequestCtx, statusCode int, errMsg string) {
+	resp := getSqlResponse([]string{}, [][]interface{}{})
+	resp.Status = statusCode
+	resp.Errors = []string{errMsg}
+	ctx.SetStatusCode(statusCode)
+	utils.WriteJsonResponse(ctx, resp)
+}
+
+// Lists the indices the request can read, or the columns of one of them
+func processSqlShowRequest(ctx *fasthttp.RequestCtx, showRequest *structs.ShowRequest, myid uint64) {
+	indexFilter := apikeys.GetIndexFilter(ctx, apikeys.ACCESS_READ)
+	if showRequest.ColumnsRequest != nil {
+		indexName := strings.TrimSpace(strings.Trim(showRequest.ColumnsRequest.InTable, "`;"))
+		if indexFilter != nil && !indexFilter(indexName) {
+			writeSqlErrorResponse(ctx, fasthttp.StatusForbidden, fmt.Sprintf("can not read index %v", indexName))
+			return
+		}
+		columns := metadata.GetAllColNames([]string{indexName})
+		sort.Strings(columns)
+		rows := make([][]interface{}, 0, len(columns))
+		for _, column := range columns {
+			rows = append(rows, []interface{}{column})
+		}
+		utils.WriteJsonResponse(ctx, getSqlResponse([]string{"column"}, rows))
+		ctx.SetStatusCode(fasthttp.StatusOK)
+		return
+	}
+
+	likeRegex := regexp.MustCompile(".*")
+	if showRequest.ShowFilter != nil && showRequest.ShowFilter.Like != ".*" {
+		likeRegex = getSqlLikeRegex(showRequest.ShowFilter.Like)
+	}
+	allIndexNames := vtable.ExpandAndReturnIndexNames("*", myid, false)
+	sort.Strings(allIndexNames)
+	rows := make([][]interface{}, 0, len(allIndexNames))
+	for _, indexName := range allIndexNames {
+		if indexName == "" || indexName == "*" || !likeRegex.MatchString(indexName) {
+			continue
+		}
+		if indexFilter != nil && !indexFilter(indexName) {
+			continue
+		}
+		rows = append(rows, []interface{}{indexName})
+	}
+	utils.WriteJsonResponse(ctx, getSqlResponse([]string{"table"}, rows))
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
+
+// Converts a LIKE pattern, where % matches any characters and _ one character, to a regex
+func getSqlLikeRegex(pattern string) *regexp.Regexp {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for _, c := range pattern {
+		switch c {
+		case '%':
+			sb.WriteString(".*")
+		case '_':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.MustCompile(sb.String())
+}
+
+// The columns of the select list in their order, or all the columns of the records for SELECT *
+func getSqlSelectedColumns(aggs *structs.QueryAggregators, allCols []string) []string {
+	if aggs == nil || aggs.OutputTransforms == nil || aggs.OutputTransforms.OutputColumns == nil ||
+		len(aggs.OutputTransforms.OutputColumns.IncludeColumns) == 0 {
+		return allCols
+	}
+	outputColumns := aggs.OutputTransforms.OutputColumns
+	columns := make([]string, 0, len(outputColumns.IncludeColumns))
+	for _, column := range outputColumns.IncludeColumns {
+		if newName, ok := outputColumns.RenameColumns[column]; ok {
+			column = newName
+		}
+		columns = append(columns, column)
+	}
+	return columns
+}
+
+func getSqlRecordsResponse(records []map[string]interface{}, columns []string) *sql.JDBCElasticSQLSearchResponse {
+	rows := make([][]interface{}, 0, len(records))
+	for _, record := range records {
+		row := make([]interface{}, len(columns))
+		for i, column := range columns {
+			row[i] = record[column]
+		}
+		rows = append(rows, row)
+	}
+	return getSqlResponse(columns, rows)
+}
+
+/*
+The rows are the group by values followed by the measures of each bucket. They are sorted by the ORDER BY column,
+which has to be one of them, and limited to rowLimit
+*/
+func getSqlAggsResponse(buckets []*structs.BucketHolder, groupByCols []string, measureFunctions []string,
+	orderBy *structs.SortRequest, rowLimit int) (*sql.JDBCElasticSQLSearchResponse, error) {
+	columns := make([]string, 0, len(groupByCols)+len(measureFunctions))
+	columns = append(columns, groupByCols...)
+	columns = append(columns, measureFunctions...)
+
+	rows := make([][]interface{}, 0, len(buckets))
+	for _, bucket := range buckets {
+		row := make([]interface{}, 0, len(columns))
+		for i := range groupByCols {
+			var value interface{}
+			if i < len(bucket.GroupByValues) {
+				value = bucket.GroupByValues[i]
+			}
+			row = append(row, value)
+		}
+		for _, measureFunc := range measureFunctions {
+			row = append(row, getSqlNumericValue(bucket.MeasureVal[measureFunc]))
+		}
+		rows = append(rows, row)
+	}
+
+	if orderBy != nil {
+		orderByIdx := -1
+		for i, column := range columns {
+			if strings.EqualFold(strings.ReplaceAll(column, " ", ""), strings.ReplaceAll(orderBy.ColName, " ", "")) {
+				orderByIdx = i
+				break
+			}
+		}
+		if orderByIdx < 0 {
+			return nil, fmt.Errorf("the ORDER BY column %v is not a group by column or an aggregation", orderBy.ColName)
+		}
+		sort.SliceStable(rows, func(i, j int) bool {
+			cmp := compareSqlValues(rows[i][orderByIdx], rows[j][orderByIdx])
+			if orderBy.Ascending {
+				return cmp < 0
+			}
+			return cmp > 0
+		})
+	}
+	if rowLimit > 0 && len(rows) > rowLimit {
+		rows = rows[:rowLimit]
+	}
+	return getSqlResponse(columns, rows), nil
+}
+
+func getSqlResponse(columns []string, rows [][]interface{}) *sql.JDBCElasticSQLSearchResponse {
+	schema := make([]sql.JdbcField, len(columns))
+	for i, column := range columns {
+		schema[i] = sql.JdbcField{Name: column, Type: getSqlColumnType(rows, i)}
+	}
+	return &sql.JDBCElasticSQLSearchResponse{
+		Schema:   schema,
+		DataRows: rows,
+		Size:     len(rows),
+		Status:   fasthttp.StatusOK,
+		Columns:  schema,
+	}
+}
+
+// The type of the values of the column that are not null, a column of longs and doubles is double
+func getSqlColumnType(rows [][]interface{}, columnIdx int) string {
+	columnType := ""
+	for _, row := range rows {
+		valueType := ""
+		switch row[columnIdx].(type) {
+		case nil:
+			continue
+		case bool:
+			valueType = SQL_TYPE_BOOLEAN
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+			valueType = SQL_TYPE_LONG
+		case float32, float64:
+			valueType = SQL_TYPE_DOUBLE
+		default:
+			return SQL_TYPE_TEXT
+		}
+		switch {
+		case columnType == "" || columnType == valueType:
+			columnType = valueType
+		case (columnType == SQL_TYPE_LONG && valueType == SQL_TYPE_DOUBLE) || (columnType == SQL_TYPE_DOUBLE && valueType == SQL_TYPE_LONG):
+			columnType = SQL_TYPE_DOUBLE
+		default:
+			return SQL_TYPE_TEXT
+		}
+	}
+	if columnType == "" {
+		return SQL_TYPE_TEXT
+	}
+	return columnType
+}
+
+// The measures can be formatted numbers like "1,234.5", they are returned as numbers
+func getSqlNumericValue(value interface{}) interface{} {
+	strValue, ok := value.(string)
+	if !ok {
+		return value
+	}
+	numStr := strings.ReplaceAll(strValue, ",", "")
+	if intValue, err := strconv.ParseInt(numStr, 10, 64); err == nil {
+		return intValue
+	}
+	if floatValue, err := strconv.ParseFloat(numStr, 64); err == nil {
+		return floatValue
+	}
+	return value
+}
+
+// Numbers are compared by value and before the other values, nulls are last
+func compareSqlValues(a interface{}, b interface{}) int {
+	if a == nil || b == nil {
+		if a == nil && b == nil {
+			return 0
+		} else if a == nil {
+			return 1
+		}
+		return -1
+	}
+	aNum, aIsNum := getSqlFloat(a)
+	bNum, bIsNum := getSqlFloat(b)
+	switch {
+	case aIsNum && bIsNum:
+		if aNum < bNum {
+			return -1
+		} else if aNum > bNum {
+			return 1
+		}
+		return 0
+	case aIsNum:
+		return -1
+	case bIsNum:
+		return 1
+	}
+	return strings.Compare(fmt.Sprintf("%v", a), fmt.Sprintf("%v", b))
+}
+
+func getSqlFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case string:
+		floatValue, err := strconv.ParseFloat(v, 64)
+		return floatValue, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipesearch
+
+import (
+	"testing"
+
+	"github.com/siglens/siglens/pkg/ast/sql"
+	"github.com/siglens/siglens/pkg/segment/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_getSqlAggsResponse(t *testing.T) {
+	buckets := []*structs.BucketHolder{
+		{GroupByValues: []string{"web-1"}, MeasureVal: map[string]interface{}{"count(*)": uint64(5), "avg(latency)": "1,250.5"}},
+		{GroupByValues: []string{"web-2"}, MeasureVal: map[string]interface{}{"count(*)": uint64(40), "avg(latency)": "12"}},
+		{GroupByValues: []string{"web-3"}, MeasureVal: map[string]interface{}{"count(*)": uint64(12)}},
+	}
+	orderBy := &structs.SortRequest{ColName: "COUNT(*)", Ascending: false}
+	resp, err := getSqlAggsResponse(buckets, []string{"host"}, []string{"count(*)", "avg(latency)"}, orderBy, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, []sql.JdbcField{
+		{Name: "host", Type: SQL_TYPE_TEXT},
+		{Name: "count(*)", Type: SQL_TYPE_LONG},
+		{Name: "avg(latency)", Type: SQL_TYPE_LONG},
+	}, resp.Schema)
+	assert.Equal(t, [][]interface{}{
+		{"web-2", uint64(40), int64(12)},
+		{"web-3", uint64(12), nil},
+	}, resp.DataRows)
+	assert.Equal(t, 2, resp.Size)
+
+	// nulls are last in both directions
+	orderBy = &structs.SortRequest{ColName: "avg(latency)", Ascending: true}
+	resp, err = getSqlAggsResponse(buckets, []string{"host"}, []string{"count(*)", "avg(latency)"}, orderBy, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, [][]interface{}{
+		{"web-2", uint64(40), int64(12)},
+		{"web-1", uint64(5), 1250.5},
+		{"web-3", uint64(12), nil},
+	}, resp.DataRows)
+	assert.Equal(t, SQL_TYPE_DOUBLE, resp.Schema[2].Type)
+
+	orderBy = &structs.SortRequest{ColName: "region", Ascending: true}
+	_, err = getSqlAggsResponse(buckets, []string{"host"}, []string{"count(*)"}, orderBy, 0)
+	assert.NotNil(t, err)
+}
+
+func Test_getSqlRecordsResponse(t *testing.T) {
+	_, aggs, err := ParseQuery("SELECT host, status AS code FROM `web-logs` WHERE status >= 500 LIMIT 5", 0, "SQL")
+	assert.Nil(t, err)
+	assert.Equal(t, "web-logs", aggs.TableName)
+	assert.Equal(t, 5, aggs.BucketLimit)
+
+	records := []map[string]interface{}{
+		{"host": "web-1", "code": float64(500), "_index": "web-logs"},
+		{"host": "web-2", "code": float64(503), "_index": "web-logs"},
+	}
+	resp := getSqlRecordsResponse(records, getSqlSelectedColumns(aggs, []string{"_index", "code", "host"}))
+	assert.Equal(t, []sql.JdbcField{{Name: "host", Type: SQL_TYPE_TEXT}, {Name: "code", Type: SQL_TYPE_DOUBLE}}, resp.Schema)
+	assert.Equal(t, [][]interface{}{{"web-1", float64(500)}, {"web-2", float64(503)}}, resp.DataRows)
+
+	// SELECT * returns every column
+	_, aggs, err = ParseQuery("SELECT * FROM `web-logs`", 0, "SQL")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"_index", "code", "host"}, getSqlSelectedColumns(aggs, []string{"_index", "code", "host"}))
+}
+
+func Test_getSqlLikeRegex(t *testing.T) {
+	likeRegex := getSqlLikeRegex("web_%.logs")
+	assert.True(t, likeRegex.MatchString("web-prod.logs"))
+	assert.False(t, likeRegex.MatchString("web.logs"))
+	assert.False(t, likeRegex.MatchString("web-prodxlogs"))
+	assert.False(t, likeRegex.MatchString("my-web-prod.logs"))
+}
@@ -21,6 +21,7 @@ import (
 
 	"github.com/dustin/go-humanize"
 	"github.com/fasthttp/websocket"
+	"github.com/siglens/siglens/pkg/apikeys"
 	rutils "github.com/siglens/siglens/pkg/readerUtils"
 	"github.com/siglens/siglens/pkg/segment"
 	"github.com/siglens/siglens/pkg/segment/query"
@@ -30,7 +31,7 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-func ProcessPipeSearchWebsocket(conn *websocket.Conn, orgid uint64) {
+func ProcessPipeSearchWebsocket(conn *websocket.Conn, orgid uint64, indexFilter func(indexName string) bool, apiKey *apikeys.ApiKey) {
 	qid := rutils.GetNextQid()
 	event, err := readInitialEvent(qid, conn)
 	if err != nil {
@@ -67,6 +68,18 @@ func ProcessPipeSearchWebsocket(conn *websocket.Conn, orgid uint64) {
 		return
 	}
 
+	if variables, ok := event["variables"]; ok {
+		searchText, err = SubstituteDashboardVariables(searchText, variables, startEpoch, endEpoch)
+		if err != nil {
+			log.Errorf("qid=%d, ProcessPipeSearchWebsocket: failed to substitute dashboard variables err=%v", qid, err)
+			wErr := conn.WriteJSON(createErrorResponse(err.Error()))
+			if wErr != nil {
+				log.Errorf("qid=%d, ProcessPipeSearchWebsocket: failed to write error response to websocket! %+v", qid, wErr)
+			}
+			return
+		}
+	}
+
 	ti := structs.InitTableInfo(indexNameIn, orgid, false)
 	log.Infof("qid=%v, ProcessPipeSearchWebsocket: index=[%v] searchString=[%v] scrollFrom=[%v]",
 		qid, ti.String(), searchText, scrollFrom)
@@ -83,6 +96,14 @@ func ProcessPipeSearchWebsocket(conn *websocket.Conn, orgid uint64) {
 		simpleNode, aggs, err = ParseRequest(searchText, startEpoch, endEpoch, qid, "Log QL", indexNameIn)
 	} else if queryLanguageType == "Splunk QL" {
 		simpleNode, aggs, err = ParseRequest(searchText, startEpoch, endEpoch, qid, "Splunk QL", indexNameIn)
+		if err == nil && aggs.SpanSearch != nil {
+			indexNameIn = SPANS_INDEX
+			ti = structs.InitTableInfo(indexNameIn, orgid, false)
+			err = applySpanSearch(simpleNode, aggs, indexFilter, qid, orgid)
+		}
+	} else if queryLanguageType == "PPL" {
+		simpleNode, aggs, indexNameIn, err = ParsePPLRequest(searchText, startEpoch, endEpoch, qid)
+		ti = structs.InitTableInfo(indexNameIn, orgid, false)
 	} else {
 		log.Infof("ProcessPipeSearchWebsocket: unknown queryLanguageType: %v; using Pipe QL instead", queryLanguageType)
 		simpleNode, aggs, err = ParseRequest(searchText, startEpoch, endEpoch, qid, "Pipe QL", indexNameIn)
@@ -107,7 +128,20 @@ func ProcessPipeSearchWebsocket(conn *websocket.Conn, orgid uint64) {
 		sizeLimit = aggs.OutputTransforms.MaxRows
 	}
 
+	masker := apikeys.GetResultMasker(apiKey, ti.GetQueryTables(), orgid)
+	err = masker.CheckDerivedFields(aggs)
+	if err != nil {
+		log.Errorf("qid=%d, ProcessPipeSearchWebsocket: query derives a masked field err=%v", qid, err)
+		wErr := conn.WriteJSON(createErrorResponse(err.Error()))
+		if wErr != nil {
+			log.Errorf("qid=%d, ProcessPipeSearchWebsocket: failed to write error response to websocket! %+v", qid, wErr)
+		}
+		return
+	}
+
 	qc := structs.InitQueryContextWithTableInfo(ti, sizeLimit, scrollFrom, orgid, false)
+	qc.IndexFilter = indexFilter
+	qc.Parallelism = parseParallelism(event)
 	eventC, err := segment.ExecuteAsyncQuery(simpleNode, aggs, qid, qc)
 	if err != nil {
 		log.Errorf("qid=%d, ProcessPipeSearchWebsocket: failed to execute query err=%v", qid, err)
@@ -126,12 +160,19 @@ func ProcessPipeSearchWebsocket(conn *websocket.Conn, orgid uint64) {
 			case query.RUNNING:
 				processRunningUpdate(conn, qid)
 			case query.QUERY_UPDATE:
-				processQueryUpdate(conn, qid, sizeLimit, scrollFrom, qscd, aggs)
+				processQueryUpdate(conn, qid, sizeLimit, scrollFrom, qscd, aggs, masker)
 			case query.TIMEOUT:
 				processTimeoutUpdate(conn, qid)
 				return
 			case query.COMPLETE:
-				processCompleteUpdate(conn, sizeLimit, qid, aggs)
+				processCompleteUpdate(conn, sizeLimit, qid, aggs, masker)
+				query.DeleteQuery(qid)
+				return
+			case query.ERROR:
+				wErr := conn.WriteJSON(createErrorResponse(qscd.Error.Error()))
+				if wErr != nil {
+					log.Errorf("qid=%d, ProcessPipeSearchWebsocket: failed to write error response to websocket! %+v", qid, wErr)
+				}
 				query.DeleteQuery(qid)
 				return
 			default:
@@ -212,7 +253,7 @@ func processRunningUpdate(conn *websocket.Conn, qid uint64) {
 }
 
 func processQueryUpdate(conn *websocket.Conn, qid uint64, sizeLimit uint64, scrollFrom int, qscd *query.QueryStateChanData,
-	aggs *structs.QueryAggregators) {
+	aggs *structs.QueryAggregators, masker *apikeys.ResultMasker) {
 	searchPercent := qscd.PercentComplete
 	totalEventsSearched, err := query.GetTotalsRecsSearchedForQid(qid)
 	if err != nil {
@@ -234,7 +275,7 @@ func processQueryUpdate(conn *websocket.Conn, qid uint64, sizeLimit uint64, scro
 		return
 	}
 
-	wsResponse, err = createRecsWsResp(qid, sizeLimit, searchPercent, scrollFrom, totalEventsSearched, qscd.QueryUpdate, aggs)
+	wsResponse, err = createRecsWsResp(qid, sizeLimit, searchPercent, scrollFrom, totalEventsSearched, qscd.QueryUpdate, aggs, masker)
 	if err != nil {
 		wErr := conn.WriteJSON(createErrorResponse(err.Error()))
 		if wErr != nil {
@@ -249,7 +290,7 @@ func processQueryUpdate(conn *websocket.Conn, qid uint64, sizeLimit uint64, scro
 	}
 }
 
-func processCompleteUpdate(conn *websocket.Conn, sizeLimit, qid uint64, aggs *structs.QueryAggregators) {
+func processCompleteUpdate(conn *websocket.Conn, sizeLimit, qid uint64, aggs *structs.QueryAggregators, masker *apikeys.ResultMasker) {
 	queryC := query.GetQueryCountInfoForQid(qid)
 	totalEventsSearched, err := query.GetTotalsRecsSearchedForQid(qid)
 	if err != nil {
@@ -261,6 +302,7 @@ func processCompleteUpdate(conn *websocket.Conn, sizeLimit, qid uint64, aggs *st
 	}
 
 	aggMeasureRes, aggMeasureFunctions, aggGroupByCols, bucketCount := query.GetMeasureResultsForQid(qid, true, 0, aggs.BucketLimit) //aggs.BucketLimit
+	masker.MaskBuckets(aggMeasureRes, aggGroupByCols)
 
 	var canScrollMore bool
 	if numRRCs == sizeLimit {
@@ -310,7 +352,7 @@ func processMaxScrollComplete(conn *websocket.Conn, qid uint64) {
 }
 
 func createRecsWsResp(qid uint64, sizeLimit uint64, searchPercent float64, scrollFrom int,
-	totalEventsSearched uint64, qUpdate *query.QueryUpdate, aggs *structs.QueryAggregators) (*PipeSearchWSUpdateResponse, error) {
+	totalEventsSearched uint64, qUpdate *query.QueryUpdate, aggs *structs.QueryAggregators, masker *apikeys.ResultMasker) (*PipeSearchWSUpdateResponse, error) {
 
 	qType := query.GetQueryType(qid)
 	wsResponse := &PipeSearchWSUpdateResponse{
@@ -324,15 +366,17 @@ func createRecsWsResp(qid uint64, sizeLimit uint64, searchPercent float64, scrol
 	case structs.SegmentStatsCmd, structs.GroupByCmd:
 		if aggs.Next == nil { // We'll do chained aggs after all segments are searched.
 			var doPull bool
-			if qUpdate.RemoteID != "" {
+			if qUpdate.RemoteID != "" || qUpdate.PulledBucks {
 				doPull = true
 			}
 			aggMeasureRes, aggMeasureFunctions, aggGroupByCols, bucketCount := query.GetMeasureResultsForQid(qid, doPull, qUpdate.SegKeyEnc, aggs.BucketLimit)
+			masker.MaskBuckets(aggMeasureRes, aggGroupByCols)
 			wsResponse.MeasureResults = aggMeasureRes
 			wsResponse.MeasureFunctions = aggMeasureFunctions
 			wsResponse.GroupByCols = aggGroupByCols
 			wsResponse.Qtype = qType.String()
 			wsResponse.BucketCount = bucketCount
+			wsResponse.IsTimechart = aggs.UsedByTimechart()
 		}
 	case structs.RRCCmd:
 		useAnySegKey := false
@@ -377,6 +421,7 @@ func createRecsWsResp(qid uint64, sizeLimit uint64, searchPercent float64, scrol
 			return nil, err
 		}
 
+		masker.MaskRecords(allJson)
 		wsResponse.Hits = PipeSearchResponse{
 			Hits:         allJson,
 			TotalMatched: qc,
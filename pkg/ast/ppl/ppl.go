@@ -0,0 +1,3833 @@
+// Code generated by pigeon; DO NOT EDIT.
+
+package ppl
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/siglens/siglens/pkg/ast"
+	"github.com/siglens/siglens/pkg/segment/query"
+	"github.com/siglens/siglens/pkg/segment/structs"
+	"github.com/siglens/siglens/pkg/segment/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+func getParseError(err error) error {
+	switch ev := err.(type) {
+	case errList:
+		if pe, ok := ev[0].(*parserError); ok {
+			return &ast.ParseError{
+				Inner:    pe.Inner,
+				Line:     pe.pos.line,
+				Column:   pe.pos.col,
+				Offset:   pe.pos.offset,
+				Prefix:   pe.prefix,
+				Expected: pe.expected,
+			}
+		}
+	}
+	return err
+}
+
+// Check if it rename fields with similar names using a wildcard
+func isRegexRename(originalPattern, newPattern string) (bool, error) {
+	oldWildcards := strings.Count(originalPattern, "*")
+	newWildcards := strings.Count(newPattern, "*")
+
+	if oldWildcards == 0 && newWildcards == 0 {
+		return false, nil
+	} else if oldWildcards > 0 && oldWildcards == newWildcards {
+		return true, nil
+	} else {
+		return false, errors.New("Patterns do not match")
+	}
+}
+
+func deMorgansLaw(node *ast.Node) {
+	switch node.NodeType {
+	case ast.NodeTerminal:
+		switch node.Comparison.Op {
+		case "=":
+			node.Comparison.Op = "!="
+		case "!=":
+			node.Comparison.Op = "="
+		case ">":
+			node.Comparison.Op = "<="
+		case "<":
+			node.Comparison.Op = ">="
+		case ">=":
+			node.Comparison.Op = "<"
+		case "<=":
+			node.Comparison.Op = ">"
+		default:
+			log.Errorf("deMorgansLaw: unexpected node comparison op: %v", node.Comparison.Op)
+		}
+	case ast.NodeAnd:
+		node.NodeType = ast.NodeOr
+		deMorgansLaw(node.Left)
+		deMorgansLaw(node.Right)
+	case ast.NodeOr:
+		node.NodeType = ast.NodeAnd
+		deMorgansLaw(node.Left)
+		deMorgansLaw(node.Right)
+	default:
+		log.Errorf("deMorgansLaw: unexpected NodeType: %v", node.NodeType)
+	}
+}
+
+func andNodes(left *ast.Node, right *ast.Node) *ast.Node {
+	if left == nil {
+		return right
+	}
+
+	return &ast.Node{
+		NodeType: ast.NodeAnd,
+		Left:     left,
+		Right:    right,
+	}
+}
+
+// Converts a where condition into the expression that filters the rows of the
+// aggregation results, the same one a Splunk QL where would give.
+func toBoolExpr(node *ast.Node) (*structs.BoolExpr, error) {
+	switch node.NodeType {
+	case ast.NodeTerminal:
+		left := &structs.ValueExpr{
+			ValueExprMode: structs.VEMNumericExpr,
+			NumericExpr: &structs.NumericExpr{
+				IsTerminal:      true,
+				ValueIsField:    true,
+				Value:           node.Comparison.Field,
+				NumericExprMode: structs.NEMNumberField,
+			},
+		}
+
+		right := &structs.ValueExpr{}
+		switch value := node.Comparison.Values.(type) {
+		case json.Number:
+			right.ValueExprMode = structs.VEMNumericExpr
+			right.NumericExpr = &structs.NumericExpr{
+				IsTerminal:      true,
+				ValueIsField:    false,
+				Value:           string(value),
+				NumericExprMode: structs.NEMNumber,
+			}
+		case string:
+			right.ValueExprMode = structs.VEMStringExpr
+			right.StringExpr = &structs.StringExpr{
+				StringExprMode: structs.SEMRawString,
+				RawString:      value[1 : len(value)-1],
+			}
+		default:
+			return nil, fmt.Errorf("toBoolExpr: unexpected value: %v", node.Comparison.Values)
+		}
+
+		return &structs.BoolExpr{
+			IsTerminal: true,
+			LeftValue:  left,
+			RightValue: right,
+			ValueOp:    node.Comparison.Op,
+		}, nil
+	case ast.NodeAnd, ast.NodeOr:
+		left, err := toBoolExpr(node.Left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := toBoolExpr(node.Right)
+		if err != nil {
+			return nil, err
+		}
+
+		boolOp := structs.BoolOpAnd
+		if node.NodeType == ast.NodeOr {
+			boolOp = structs.BoolOpOr
+		}
+
+		return &structs.BoolExpr{
+			IsTerminal: false,
+			BoolOp:     boolOp,
+			LeftBool:   left,
+			RightBool:  right,
+		}, nil
+	default:
+		return nil, fmt.Errorf("toBoolExpr: unexpected NodeType: %v", node.NodeType)
+	}
+}
+
+type aggregator struct {
+	measureAgg         *structs.MeasureAggregator
+	renameOutputField  bool
+	outputFieldNewName string
+}
+
+var g = &grammar{
+	rules: []*rule{
+		{
+			name: "Start",
+			pos:  position{line: 163, col: 1, offset: 4827},
+			expr: &actionExpr{
+				pos: position{line: 163, col: 10, offset: 4836},
+				run: (*parser).callonStart1,
+				expr: &seqExpr{
+					pos: position{line: 163, col: 10, offset: 4836},
+					exprs: []any{
+						&zeroOrOneExpr{
+							pos: position{line: 163, col: 10, offset: 4836},
+							expr: &ruleRefExpr{
+								pos:  position{line: 163, col: 10, offset: 4836},
+								name: "SPACE",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 163, col: 17, offset: 4843},
+							label: "source",
+							expr: &ruleRefExpr{
+								pos:  position{line: 163, col: 24, offset: 4850},
+								name: "SourceCommand",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 163, col: 38, offset: 4864},
+							label: "search",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 163, col: 45, offset: 4871},
+								expr: &seqExpr{
+									pos: position{line: 163, col: 46, offset: 4872},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 163, col: 46, offset: 4872},
+											name: "SPACE",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 163, col: 52, offset: 4878},
+											name: "SearchExpr",
+										},
+									},
+								},
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 163, col: 65, offset: 4891},
+							label: "pipeBlocks",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 163, col: 76, offset: 4902},
+								expr: &ruleRefExpr{
+									pos:  position{line: 163, col: 77, offset: 4903},
+									name: "PipeBlock",
+								},
+							},
+						},
+						&zeroOrOneExpr{
+							pos: position{line: 163, col: 89, offset: 4915},
+							expr: &ruleRefExpr{
+								pos:  position{line: 163, col: 89, offset: 4915},
+								name: "SPACE",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 163, col: 96, offset: 4922},
+							name: "EOF",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "SourceCommand",
+			pos:  position{line: 220, col: 1, offset: 7002},
+			expr: &actionExpr{
+				pos: position{line: 220, col: 18, offset: 7019},
+				run: (*parser).callonSourceCommand1,
+				expr: &seqExpr{
+					pos: position{line: 220, col: 18, offset: 7019},
+					exprs: []any{
+						&zeroOrOneExpr{
+							pos: position{line: 220, col: 18, offset: 7019},
+							expr: &ruleRefExpr{
+								pos:  position{line: 220, col: 18, offset: 7019},
+								name: "CMD_SEARCH",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 220, col: 30, offset: 7031},
+							name: "CMD_SOURCE",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 220, col: 41, offset: 7042},
+							name: "EQUAL",
+						},
+						&labeledExpr{
+							pos:   position{line: 220, col: 47, offset: 7048},
+							label: "indices",
+							expr: &ruleRefExpr{
+								pos:  position{line: 220, col: 55, offset: 7056},
+								name: "IndexNameList",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "IndexNameList",
+			pos:  position{line: 224, col: 1, offset: 7129},
+			expr: &actionExpr{
+				pos: position{line: 224, col: 18, offset: 7146},
+				run: (*parser).callonIndexNameList1,
+				expr: &seqExpr{
+					pos: position{line: 224, col: 18, offset: 7146},
+					exprs: []any{
+						&labeledExpr{
+							pos:   position{line: 224, col: 18, offset: 7146},
+							label: "first",
+							expr: &ruleRefExpr{
+								pos:  position{line: 224, col: 24, offset: 7152},
+								name: "IndexName",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 224, col: 34, offset: 7162},
+							label: "rest",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 224, col: 39, offset: 7167},
+								expr: &seqExpr{
+									pos: position{line: 224, col: 40, offset: 7168},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 224, col: 40, offset: 7168},
+											name: "COMMA",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 224, col: 46, offset: 7174},
+											name: "IndexName",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "IndexName",
+			pos:  position{line: 237, col: 1, offset: 7471},
+			expr: &choiceExpr{
+				pos: position{line: 237, col: 14, offset: 7484},
+				alternatives: []any{
+					&actionExpr{
+						pos: position{line: 237, col: 14, offset: 7484},
+						run: (*parser).callonIndexName2,
+						expr: &oneOrMoreExpr{
+							pos: position{line: 237, col: 14, offset: 7484},
+							expr: &charClassMatcher{
+								pos:        position{line: 237, col: 14, offset: 7484},
+								val:        "[a-zA-Z0-9_.*-]",
+								chars:      []rune{'_', '.', '*', '-'},
+								ranges:     []rune{'a', 'z', 'A', 'Z', '0', '9'},
+								ignoreCase: false,
+								inverted:   false,
+							},
+						},
+					},
+					&actionExpr{
+						pos: position{line: 240, col: 3, offset: 7538},
+						run: (*parser).callonIndexName5,
+						expr: &seqExpr{
+							pos: position{line: 240, col: 3, offset: 7538},
+							exprs: []any{
+								&litMatcher{
+									pos:        position{line: 240, col: 3, offset: 7538},
+									val:        "`",
+									ignoreCase: false,
+									want:       "\"`\"",
+								},
+								&labeledExpr{
+									pos:   position{line: 240, col: 7, offset: 7542},
+									label: "name",
+									expr: &oneOrMoreExpr{
+										pos: position{line: 240, col: 12, offset: 7547},
+										expr: &charClassMatcher{
+											pos:        position{line: 240, col: 12, offset: 7547},
+											val:        "[^`]",
+											chars:      []rune{'`'},
+											ignoreCase: false,
+											inverted:   true,
+										},
+									},
+								},
+								&litMatcher{
+									pos:        position{line: 240, col: 18, offset: 7553},
+									val:        "`",
+									ignoreCase: false,
+									want:       "\"`\"",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "SearchExpr",
+			pos:  position{line: 245, col: 1, offset: 7633},
+			expr: &actionExpr{
+				pos: position{line: 245, col: 15, offset: 7647},
+				run: (*parser).callonSearchExpr1,
+				expr: &labeledExpr{
+					pos:   position{line: 245, col: 15, offset: 7647},
+					label: "expr",
+					expr: &ruleRefExpr{
+						pos:  position{line: 245, col: 20, offset: 7652},
+						name: "BoolExprLevel4",
+					},
+				},
+			},
+		},
+		{
+			name: "BoolExprLevel4",
+			pos:  position{line: 250, col: 1, offset: 7714},
+			expr: &actionExpr{
+				pos: position{line: 250, col: 19, offset: 7732},
+				run: (*parser).callonBoolExprLevel41,
+				expr: &seqExpr{
+					pos: position{line: 250, col: 19, offset: 7732},
+					exprs: []any{
+						&labeledExpr{
+							pos:   position{line: 250, col: 19, offset: 7732},
+							label: "first",
+							expr: &ruleRefExpr{
+								pos:  position{line: 250, col: 25, offset: 7738},
+								name: "BoolExprLevel3",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 250, col: 40, offset: 7753},
+							label: "rest",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 250, col: 45, offset: 7758},
+								expr: &seqExpr{
+									pos: position{line: 250, col: 46, offset: 7759},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 250, col: 46, offset: 7759},
+											name: "OR",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 250, col: 49, offset: 7762},
+											name: "BoolExprLevel3",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "BoolExprLevel3",
+			pos:  position{line: 265, col: 1, offset: 8071},
+			expr: &actionExpr{
+				pos: position{line: 265, col: 19, offset: 8089},
+				run: (*parser).callonBoolExprLevel31,
+				expr: &seqExpr{
+					pos: position{line: 265, col: 19, offset: 8089},
+					exprs: []any{
+						&labeledExpr{
+							pos:   position{line: 265, col: 19, offset: 8089},
+							label: "first",
+							expr: &ruleRefExpr{
+								pos:  position{line: 265, col: 25, offset: 8095},
+								name: "BoolExprLevel2",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 265, col: 40, offset: 8110},
+							label: "rest",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 265, col: 45, offset: 8115},
+								expr: &seqExpr{
+									pos: position{line: 265, col: 46, offset: 8116},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 265, col: 46, offset: 8116},
+											name: "AND",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 265, col: 50, offset: 8120},
+											name: "BoolExprLevel2",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "BoolExprLevel2",
+			pos:  position{line: 276, col: 1, offset: 8345},
+			expr: &choiceExpr{
+				pos: position{line: 276, col: 19, offset: 8363},
+				alternatives: []any{
+					&actionExpr{
+						pos: position{line: 276, col: 19, offset: 8363},
+						run: (*parser).callonBoolExprLevel22,
+						expr: &seqExpr{
+							pos: position{line: 276, col: 19, offset: 8363},
+							exprs: []any{
+								&ruleRefExpr{
+									pos:  position{line: 276, col: 19, offset: 8363},
+									name: "NOT",
+								},
+								&labeledExpr{
+									pos:   position{line: 276, col: 23, offset: 8367},
+									label: "expr",
+									expr: &ruleRefExpr{
+										pos:  position{line: 276, col: 28, offset: 8372},
+										name: "BoolExprLevel1",
+									},
+								},
+							},
+						},
+					},
+					&actionExpr{
+						pos: position{line: 282, col: 3, offset: 8467},
+						run: (*parser).callonBoolExprLevel27,
+						expr: &labeledExpr{
+							pos:   position{line: 282, col: 3, offset: 8467},
+							label: "expr",
+							expr: &ruleRefExpr{
+								pos:  position{line: 282, col: 8, offset: 8472},
+								name: "BoolExprLevel1",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "BoolExprLevel1",
+			pos:  position{line: 287, col: 1, offset: 8534},
+			expr: &choiceExpr{
+				pos: position{line: 287, col: 19, offset: 8552},
+				alternatives: []any{
+					&actionExpr{
+						pos: position{line: 287, col: 19, offset: 8552},
+						run: (*parser).callonBoolExprLevel12,
+						expr: &seqExpr{
+							pos: position{line: 287, col: 19, offset: 8552},
+							exprs: []any{
+								&ruleRefExpr{
+									pos:  position{line: 287, col: 19, offset: 8552},
+									name: "L_PAREN",
+								},
+								&labeledExpr{
+									pos:   position{line: 287, col: 27, offset: 8560},
+									label: "expr",
+									expr: &ruleRefExpr{
+										pos:  position{line: 287, col: 32, offset: 8565},
+										name: "BoolExprLevel4",
+									},
+								},
+								&ruleRefExpr{
+									pos:  position{line: 287, col: 47, offset: 8580},
+									name: "R_PAREN",
+								},
+							},
+						},
+					},
+					&actionExpr{
+						pos: position{line: 290, col: 3, offset: 8615},
+						run: (*parser).callonBoolExprLevel18,
+						expr: &labeledExpr{
+							pos:   position{line: 290, col: 3, offset: 8615},
+							label: "comparison",
+							expr: &ruleRefExpr{
+								pos:  position{line: 290, col: 14, offset: 8626},
+								name: "Comparison",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Comparison",
+			pos:  position{line: 294, col: 1, offset: 8669},
+			expr: &actionExpr{
+				pos: position{line: 294, col: 15, offset: 8683},
+				run: (*parser).callonComparison1,
+				expr: &seqExpr{
+					pos: position{line: 294, col: 15, offset: 8683},
+					exprs: []any{
+						&labeledExpr{
+							pos:   position{line: 294, col: 15, offset: 8683},
+							label: "field",
+							expr: &ruleRefExpr{
+								pos:  position{line: 294, col: 21, offset: 8689},
+								name: "FieldName",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 294, col: 31, offset: 8699},
+							label: "op",
+							expr: &ruleRefExpr{
+								pos:  position{line: 294, col: 34, offset: 8702},
+								name: "ComparisonOperator",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 294, col: 53, offset: 8721},
+							label: "value",
+							expr: &ruleRefExpr{
+								pos:  position{line: 294, col: 59, offset: 8727},
+								name: "Literal",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "ComparisonOperator",
+			pos:  position{line: 312, col: 1, offset: 9154},
+			expr: &actionExpr{
+				pos: position{line: 312, col: 23, offset: 9176},
+				run: (*parser).callonComparisonOperator1,
+				expr: &seqExpr{
+					pos: position{line: 312, col: 23, offset: 9176},
+					exprs: []any{
+						&ruleRefExpr{
+							pos:  position{line: 312, col: 23, offset: 9176},
+							name: "EMPTY_OR_SPACE",
+						},
+						&labeledExpr{
+							pos:   position{line: 312, col: 38, offset: 9191},
+							label: "op",
+							expr: &choiceExpr{
+								pos: position{line: 312, col: 42, offset: 9195},
+								alternatives: []any{
+									&litMatcher{
+										pos:        position{line: 312, col: 42, offset: 9195},
+										val:        "!=",
+										ignoreCase: false,
+										want:       "\"!=\"",
+									},
+									&litMatcher{
+										pos:        position{line: 312, col: 49, offset: 9202},
+										val:        "<=",
+										ignoreCase: false,
+										want:       "\"<=\"",
+									},
+									&litMatcher{
+										pos:        position{line: 312, col: 56, offset: 9209},
+										val:        ">=",
+										ignoreCase: false,
+										want:       "\">=\"",
+									},
+									&litMatcher{
+										pos:        position{line: 312, col: 63, offset: 9216},
+										val:        "=",
+										ignoreCase: false,
+										want:       "\"=\"",
+									},
+									&litMatcher{
+										pos:        position{line: 312, col: 69, offset: 9222},
+										val:        "<",
+										ignoreCase: false,
+										want:       "\"<\"",
+									},
+									&litMatcher{
+										pos:        position{line: 312, col: 75, offset: 9228},
+										val:        ">",
+										ignoreCase: false,
+										want:       "\">\"",
+									},
+								},
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 312, col: 80, offset: 9233},
+							name: "EMPTY_OR_SPACE",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Literal",
+			pos:  position{line: 317, col: 1, offset: 9354},
+			expr: &choiceExpr{
+				pos: position{line: 317, col: 12, offset: 9365},
+				alternatives: []any{
+					&actionExpr{
+						pos: position{line: 317, col: 12, offset: 9365},
+						run: (*parser).callonLiteral2,
+						expr: &labeledExpr{
+							pos:   position{line: 317, col: 12, offset: 9365},
+							label: "number",
+							expr: &ruleRefExpr{
+								pos:  position{line: 317, col: 19, offset: 9372},
+								name: "Number",
+							},
+						},
+					},
+					&actionExpr{
+						pos: position{line: 320, col: 3, offset: 9408},
+						run: (*parser).callonLiteral5,
+						expr: &labeledExpr{
+							pos:   position{line: 320, col: 3, offset: 9408},
+							label: "str",
+							expr: &ruleRefExpr{
+								pos:  position{line: 320, col: 7, offset: 9412},
+								name: "QuotedString",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "PipeBlock",
+			pos:  position{line: 325, col: 1, offset: 9513},
+			expr: &actionExpr{
+				pos: position{line: 325, col: 14, offset: 9526},
+				run: (*parser).callonPipeBlock1,
+				expr: &seqExpr{
+					pos: position{line: 325, col: 14, offset: 9526},
+					exprs: []any{
+						&ruleRefExpr{
+							pos:  position{line: 325, col: 14, offset: 9526},
+							name: "PIPE",
+						},
+						&labeledExpr{
+							pos:   position{line: 325, col: 19, offset: 9531},
+							label: "block",
+							expr: &choiceExpr{
+								pos: position{line: 325, col: 26, offset: 9538},
+								alternatives: []any{
+									&ruleRefExpr{
+										pos:  position{line: 325, col: 26, offset: 9538},
+										name: "WhereBlock",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 325, col: 39, offset: 9551},
+										name: "FieldsBlock",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 325, col: 53, offset: 9565},
+										name: "StatsBlock",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 325, col: 66, offset: 9578},
+										name: "HeadBlock",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 325, col: 78, offset: 9590},
+										name: "RenameBlock",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "WhereBlock",
+			pos:  position{line: 330, col: 1, offset: 9651},
+			expr: &actionExpr{
+				pos: position{line: 330, col: 15, offset: 9665},
+				run: (*parser).callonWhereBlock1,
+				expr: &seqExpr{
+					pos: position{line: 330, col: 15, offset: 9665},
+					exprs: []any{
+						&ruleRefExpr{
+							pos:  position{line: 330, col: 15, offset: 9665},
+							name: "CMD_WHERE",
+						},
+						&labeledExpr{
+							pos:   position{line: 330, col: 25, offset: 9675},
+							label: "condition",
+							expr: &ruleRefExpr{
+								pos:  position{line: 330, col: 35, offset: 9685},
+								name: "BoolExprLevel4",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "FieldsBlock",
+			pos:  position{line: 334, col: 1, offset: 9731},
+			expr: &actionExpr{
+				pos: position{line: 334, col: 16, offset: 9746},
+				run: (*parser).callonFieldsBlock1,
+				expr: &seqExpr{
+					pos: position{line: 334, col: 16, offset: 9746},
+					exprs: []any{
+						&ruleRefExpr{
+							pos:  position{line: 334, col: 16, offset: 9746},
+							name: "CMD_FIELDS",
+						},
+						&labeledExpr{
+							pos:   position{line: 334, col: 27, offset: 9757},
+							label: "op",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 334, col: 30, offset: 9760},
+								expr: &seqExpr{
+									pos: position{line: 334, col: 31, offset: 9761},
+									exprs: []any{
+										&choiceExpr{
+											pos: position{line: 334, col: 32, offset: 9762},
+											alternatives: []any{
+												&litMatcher{
+													pos:        position{line: 334, col: 32, offset: 9762},
+													val:        "-",
+													ignoreCase: false,
+													want:       "\"-\"",
+												},
+												&litMatcher{
+													pos:        position{line: 334, col: 38, offset: 9768},
+													val:        "+",
+													ignoreCase: false,
+													want:       "\"+\"",
+												},
+											},
+										},
+										&ruleRefExpr{
+											pos:  position{line: 334, col: 43, offset: 9773},
+											name: "EMPTY_OR_SPACE",
+										},
+									},
+								},
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 334, col: 60, offset: 9790},
+							label: "fields",
+							expr: &ruleRefExpr{
+								pos:  position{line: 334, col: 67, offset: 9797},
+								name: "FieldNameList",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "StatsBlock",
+			pos:  position{line: 352, col: 1, offset: 10312},
+			expr: &actionExpr{
+				pos: position{line: 352, col: 15, offset: 10326},
+				run: (*parser).callonStatsBlock1,
+				expr: &seqExpr{
+					pos: position{line: 352, col: 15, offset: 10326},
+					exprs: []any{
+						&ruleRefExpr{
+							pos:  position{line: 352, col: 15, offset: 10326},
+							name: "CMD_STATS",
+						},
+						&labeledExpr{
+							pos:   position{line: 352, col: 25, offset: 10336},
+							label: "aggs",
+							expr: &ruleRefExpr{
+								pos:  position{line: 352, col: 30, offset: 10341},
+								name: "AggregationList",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 352, col: 46, offset: 10357},
+							label: "byFields",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 352, col: 55, offset: 10366},
+								expr: &seqExpr{
+									pos: position{line: 352, col: 56, offset: 10367},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 352, col: 56, offset: 10367},
+											name: "BY",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 352, col: 59, offset: 10370},
+											name: "FieldNameList",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "AggregationList",
+			pos:  position{line: 404, col: 1, offset: 12124},
+			expr: &actionExpr{
+				pos: position{line: 404, col: 20, offset: 12143},
+				run: (*parser).callonAggregationList1,
+				expr: &seqExpr{
+					pos: position{line: 404, col: 20, offset: 12143},
+					exprs: []any{
+						&labeledExpr{
+							pos:   position{line: 404, col: 20, offset: 12143},
+							label: "first",
+							expr: &ruleRefExpr{
+								pos:  position{line: 404, col: 26, offset: 12149},
+								name: "Aggregation",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 404, col: 38, offset: 12161},
+							label: "rest",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 404, col: 43, offset: 12166},
+								expr: &seqExpr{
+									pos: position{line: 404, col: 44, offset: 12167},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 404, col: 44, offset: 12167},
+											name: "COMMA",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 404, col: 50, offset: 12173},
+											name: "Aggregation",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Aggregation",
+			pos:  position{line: 417, col: 1, offset: 12491},
+			expr: &actionExpr{
+				pos: position{line: 417, col: 16, offset: 12506},
+				run: (*parser).callonAggregation1,
+				expr: &seqExpr{
+					pos: position{line: 417, col: 16, offset: 12506},
+					exprs: []any{
+						&labeledExpr{
+							pos:   position{line: 417, col: 16, offset: 12506},
+							label: "aggFunc",
+							expr: &ruleRefExpr{
+								pos:  position{line: 417, col: 24, offset: 12514},
+								name: "AggFunction",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 417, col: 36, offset: 12526},
+							label: "asField",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 417, col: 44, offset: 12534},
+								expr: &seqExpr{
+									pos: position{line: 417, col: 45, offset: 12535},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 417, col: 45, offset: 12535},
+											name: "AS",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 417, col: 48, offset: 12538},
+											name: "FieldName",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "AggFunction",
+			pos:  position{line: 434, col: 1, offset: 12964},
+			expr: &choiceExpr{
+				pos: position{line: 434, col: 16, offset: 12979},
+				alternatives: []any{
+					&actionExpr{
+						pos: position{line: 434, col: 16, offset: 12979},
+						run: (*parser).callonAggFunction2,
+						expr: &seqExpr{
+							pos: position{line: 434, col: 16, offset: 12979},
+							exprs: []any{
+								&litMatcher{
+									pos:        position{line: 434, col: 16, offset: 12979},
+									val:        "count",
+									ignoreCase: true,
+									want:       "\"count\"i",
+								},
+								&ruleRefExpr{
+									pos:  position{line: 434, col: 25, offset: 12988},
+									name: "L_PAREN",
+								},
+								&ruleRefExpr{
+									pos:  position{line: 434, col: 33, offset: 12996},
+									name: "R_PAREN",
+								},
+							},
+						},
+					},
+					&actionExpr{
+						pos: position{line: 442, col: 3, offset: 13136},
+						run: (*parser).callonAggFunction7,
+						expr: &seqExpr{
+							pos: position{line: 442, col: 3, offset: 13136},
+							exprs: []any{
+								&labeledExpr{
+									pos:   position{line: 442, col: 3, offset: 13136},
+									label: "aggFunc",
+									expr: &ruleRefExpr{
+										pos:  position{line: 442, col: 11, offset: 13144},
+										name: "AggFunctionName",
+									},
+								},
+								&ruleRefExpr{
+									pos:  position{line: 442, col: 27, offset: 13160},
+									name: "L_PAREN",
+								},
+								&labeledExpr{
+									pos:   position{line: 442, col: 35, offset: 13168},
+									label: "field",
+									expr: &ruleRefExpr{
+										pos:  position{line: 442, col: 41, offset: 13174},
+										name: "FieldName",
+									},
+								},
+								&ruleRefExpr{
+									pos:  position{line: 442, col: 51, offset: 13184},
+									name: "R_PAREN",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "AggFunctionName",
+			pos:  position{line: 451, col: 1, offset: 13357},
+			expr: &actionExpr{
+				pos: position{line: 451, col: 20, offset: 13376},
+				run: (*parser).callonAggFunctionName1,
+				expr: &choiceExpr{
+					pos: position{line: 451, col: 21, offset: 13377},
+					alternatives: []any{
+						&litMatcher{
+							pos:        position{line: 451, col: 21, offset: 13377},
+							val:        "count",
+							ignoreCase: true,
+							want:       "\"count\"i",
+						},
+						&litMatcher{
+							pos:        position{line: 451, col: 32, offset: 13388},
+							val:        "sum",
+							ignoreCase: true,
+							want:       "\"sum\"i",
+						},
+						&litMatcher{
+							pos:        position{line: 451, col: 41, offset: 13397},
+							val:        "avg",
+							ignoreCase: true,
+							want:       "\"avg\"i",
+						},
+						&litMatcher{
+							pos:        position{line: 451, col: 50, offset: 13406},
+							val:        "min",
+							ignoreCase: true,
+							want:       "\"min\"i",
+						},
+						&litMatcher{
+							pos:        position{line: 451, col: 59, offset: 13415},
+							val:        "max",
+							ignoreCase: true,
+							want:       "\"max\"i",
+						},
+						&litMatcher{
+							pos:        position{line: 451, col: 68, offset: 13424},
+							val:        "distinct_count",
+							ignoreCase: true,
+							want:       "\"distinct_count\"i",
+						},
+						&litMatcher{
+							pos:        position{line: 451, col: 88, offset: 13444},
+							val:        "dc",
+							ignoreCase: true,
+							want:       "\"dc\"i",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "HeadBlock",
+			pos:  position{line: 468, col: 1, offset: 13792},
+			expr: &actionExpr{
+				pos: position{line: 468, col: 14, offset: 13805},
+				run: (*parser).callonHeadBlock1,
+				expr: &seqExpr{
+					pos: position{line: 468, col: 14, offset: 13805},
+					exprs: []any{
+						&ruleRefExpr{
+							pos:  position{line: 468, col: 14, offset: 13805},
+							name: "CMD_HEAD",
+						},
+						&labeledExpr{
+							pos:   position{line: 468, col: 23, offset: 13814},
+							label: "size",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 468, col: 28, offset: 13819},
+								expr: &seqExpr{
+									pos: position{line: 468, col: 29, offset: 13820},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 468, col: 29, offset: 13820},
+											name: "SPACE",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 468, col: 35, offset: 13826},
+											name: "IntegerAsString",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "RenameBlock",
+			pos:  position{line: 492, col: 1, offset: 14481},
+			expr: &actionExpr{
+				pos: position{line: 492, col: 16, offset: 14496},
+				run: (*parser).callonRenameBlock1,
+				expr: &seqExpr{
+					pos: position{line: 492, col: 16, offset: 14496},
+					exprs: []any{
+						&ruleRefExpr{
+							pos:  position{line: 492, col: 16, offset: 14496},
+							name: "CMD_RENAME",
+						},
+						&labeledExpr{
+							pos:   position{line: 492, col: 27, offset: 14507},
+							label: "first",
+							expr: &ruleRefExpr{
+								pos:  position{line: 492, col: 33, offset: 14513},
+								name: "RenameExpr",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 492, col: 44, offset: 14524},
+							label: "rest",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 492, col: 49, offset: 14529},
+								expr: &seqExpr{
+									pos: position{line: 492, col: 50, offset: 14530},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 492, col: 50, offset: 14530},
+											name: "COMMA",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 492, col: 56, offset: 14536},
+											name: "RenameExpr",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "RenameExpr",
+			pos:  position{line: 520, col: 1, offset: 15333},
+			expr: &actionExpr{
+				pos: position{line: 520, col: 15, offset: 15347},
+				run: (*parser).callonRenameExpr1,
+				expr: &seqExpr{
+					pos: position{line: 520, col: 15, offset: 15347},
+					exprs: []any{
+						&labeledExpr{
+							pos:   position{line: 520, col: 15, offset: 15347},
+							label: "originalPattern",
+							expr: &ruleRefExpr{
+								pos:  position{line: 520, col: 31, offset: 15363},
+								name: "FieldName",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 520, col: 41, offset: 15373},
+							name: "AS",
+						},
+						&labeledExpr{
+							pos:   position{line: 520, col: 44, offset: 15376},
+							label: "newPattern",
+							expr: &ruleRefExpr{
+								pos:  position{line: 520, col: 55, offset: 15387},
+								name: "FieldName",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "FieldNameList",
+			pos:  position{line: 544, col: 1, offset: 16054},
+			expr: &actionExpr{
+				pos: position{line: 544, col: 18, offset: 16071},
+				run: (*parser).callonFieldNameList1,
+				expr: &seqExpr{
+					pos: position{line: 544, col: 18, offset: 16071},
+					exprs: []any{
+						&labeledExpr{
+							pos:   position{line: 544, col: 18, offset: 16071},
+							label: "first",
+							expr: &ruleRefExpr{
+								pos:  position{line: 544, col: 24, offset: 16077},
+								name: "FieldName",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 544, col: 34, offset: 16087},
+							label: "rest",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 544, col: 39, offset: 16092},
+								expr: &seqExpr{
+									pos: position{line: 544, col: 40, offset: 16093},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 544, col: 40, offset: 16093},
+											name: "COMMA",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 544, col: 46, offset: 16099},
+											name: "FieldName",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "FieldName",
+			pos:  position{line: 558, col: 1, offset: 16472},
+			expr: &choiceExpr{
+				pos: position{line: 558, col: 14, offset: 16485},
+				alternatives: []any{
+					&actionExpr{
+						pos: position{line: 558, col: 14, offset: 16485},
+						run: (*parser).callonFieldName2,
+						expr: &seqExpr{
+							pos: position{line: 558, col: 14, offset: 16485},
+							exprs: []any{
+								&charClassMatcher{
+									pos:        position{line: 558, col: 14, offset: 16485},
+									val:        "[a-zA-Z0-9_@*]",
+									chars:      []rune{'_', '@', '*'},
+									ranges:     []rune{'a', 'z', 'A', 'Z', '0', '9'},
+									ignoreCase: false,
+									inverted:   false,
+								},
+								&zeroOrMoreExpr{
+									pos: position{line: 558, col: 28, offset: 16499},
+									expr: &charClassMatcher{
+										pos:        position{line: 558, col: 28, offset: 16499},
+										val:        "[a-zA-Z0-9_.@*]",
+										chars:      []rune{'_', '.', '@', '*'},
+										ranges:     []rune{'a', 'z', 'A', 'Z', '0', '9'},
+										ignoreCase: false,
+										inverted:   false,
+									},
+								},
+							},
+						},
+					},
+					&actionExpr{
+						pos: position{line: 561, col: 3, offset: 16553},
+						run: (*parser).callonFieldName7,
+						expr: &seqExpr{
+							pos: position{line: 561, col: 3, offset: 16553},
+							exprs: []any{
+								&litMatcher{
+									pos:        position{line: 561, col: 3, offset: 16553},
+									val:        "`",
+									ignoreCase: false,
+									want:       "\"`\"",
+								},
+								&labeledExpr{
+									pos:   position{line: 561, col: 7, offset: 16557},
+									label: "name",
+									expr: &oneOrMoreExpr{
+										pos: position{line: 561, col: 12, offset: 16562},
+										expr: &charClassMatcher{
+											pos:        position{line: 561, col: 12, offset: 16562},
+											val:        "[^`]",
+											chars:      []rune{'`'},
+											ignoreCase: false,
+											inverted:   true,
+										},
+									},
+								},
+								&litMatcher{
+									pos:        position{line: 561, col: 18, offset: 16568},
+									val:        "`",
+									ignoreCase: false,
+									want:       "\"`\"",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "QuotedString",
+			pos:  position{line: 567, col: 1, offset: 16768},
+			expr: &actionExpr{
+				pos: position{line: 567, col: 17, offset: 16784},
+				run: (*parser).callonQuotedString1,
+				expr: &choiceExpr{
+					pos: position{line: 567, col: 18, offset: 16785},
+					alternatives: []any{
+						&seqExpr{
+							pos: position{line: 567, col: 18, offset: 16785},
+							exprs: []any{
+								&litMatcher{
+									pos:        position{line: 567, col: 18, offset: 16785},
+									val:        "\"",
+									ignoreCase: false,
+									want:       "\"\\\"\"",
+								},
+								&zeroOrMoreExpr{
+									pos: position{line: 567, col: 22, offset: 16789},
+									expr: &charClassMatcher{
+										pos:        position{line: 567, col: 22, offset: 16789},
+										val:        "[^\"]",
+										chars:      []rune{'"'},
+										ignoreCase: false,
+										inverted:   true,
+									},
+								},
+								&litMatcher{
+									pos:        position{line: 567, col: 28, offset: 16795},
+									val:        "\"",
+									ignoreCase: false,
+									want:       "\"\\\"\"",
+								},
+							},
+						},
+						&seqExpr{
+							pos: position{line: 567, col: 34, offset: 16801},
+							exprs: []any{
+								&litMatcher{
+									pos:        position{line: 567, col: 34, offset: 16801},
+									val:        "'",
+									ignoreCase: false,
+									want:       "\"'\"",
+								},
+								&zeroOrMoreExpr{
+									pos: position{line: 567, col: 38, offset: 16805},
+									expr: &charClassMatcher{
+										pos:        position{line: 567, col: 38, offset: 16805},
+										val:        "[^']",
+										chars:      []rune{'\''},
+										ignoreCase: false,
+										inverted:   true,
+									},
+								},
+								&litMatcher{
+									pos:        position{line: 567, col: 44, offset: 16811},
+									val:        "'",
+									ignoreCase: false,
+									want:       "\"'\"",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Number",
+			pos:  position{line: 573, col: 1, offset: 16973},
+			expr: &actionExpr{
+				pos: position{line: 573, col: 11, offset: 16983},
+				run: (*parser).callonNumber1,
+				expr: &seqExpr{
+					pos: position{line: 573, col: 11, offset: 16983},
+					exprs: []any{
+						&labeledExpr{
+							pos:   position{line: 573, col: 11, offset: 16983},
+							label: "number",
+							expr: &choiceExpr{
+								pos: position{line: 573, col: 19, offset: 16991},
+								alternatives: []any{
+									&ruleRefExpr{
+										pos:  position{line: 573, col: 19, offset: 16991},
+										name: "FloatAsString",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 573, col: 35, offset: 17007},
+										name: "IntegerAsString",
+									},
+								},
+							},
+						},
+						&andExpr{
+							pos: position{line: 573, col: 52, offset: 17024},
+							expr: &choiceExpr{
+								pos: position{line: 573, col: 54, offset: 17026},
+								alternatives: []any{
+									&ruleRefExpr{
+										pos:  position{line: 573, col: 54, offset: 17026},
+										name: "SPACE",
+									},
+									&litMatcher{
+										pos:        position{line: 573, col: 62, offset: 17034},
+										val:        ")",
+										ignoreCase: false,
+										want:       "\")\"",
+									},
+									&litMatcher{
+										pos:        position{line: 573, col: 68, offset: 17040},
+										val:        "|",
+										ignoreCase: false,
+										want:       "\"|\"",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 573, col: 74, offset: 17046},
+										name: "EOF",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "FloatAsString",
+			pos:  position{line: 577, col: 1, offset: 17101},
+			expr: &actionExpr{
+				pos: position{line: 577, col: 18, offset: 17118},
+				run: (*parser).callonFloatAsString1,
+				expr: &seqExpr{
+					pos: position{line: 577, col: 18, offset: 17118},
+					exprs: []any{
+						&zeroOrOneExpr{
+							pos: position{line: 577, col: 18, offset: 17118},
+							expr: &charClassMatcher{
+								pos:        position{line: 577, col: 18, offset: 17118},
+								val:        "[-+]",
+								chars:      []rune{'-', '+'},
+								ignoreCase: false,
+								inverted:   false,
+							},
+						},
+						&zeroOrMoreExpr{
+							pos: position{line: 577, col: 24, offset: 17124},
+							expr: &charClassMatcher{
+								pos:        position{line: 577, col: 24, offset: 17124},
+								val:        "[0-9]",
+								ranges:     []rune{'0', '9'},
+								ignoreCase: false,
+								inverted:   false,
+							},
+						},
+						&litMatcher{
+							pos:        position{line: 577, col: 31, offset: 17131},
+							val:        ".",
+							ignoreCase: false,
+							want:       "\".\"",
+						},
+						&oneOrMoreExpr{
+							pos: position{line: 577, col: 35, offset: 17135},
+							expr: &charClassMatcher{
+								pos:        position{line: 577, col: 35, offset: 17135},
+								val:        "[0-9]",
+								ranges:     []rune{'0', '9'},
+								ignoreCase: false,
+								inverted:   false,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "IntegerAsString",
+			pos:  position{line: 581, col: 1, offset: 17178},
+			expr: &actionExpr{
+				pos: position{line: 581, col: 20, offset: 17197},
+				run: (*parser).callonIntegerAsString1,
+				expr: &seqExpr{
+					pos: position{line: 581, col: 20, offset: 17197},
+					exprs: []any{
+						&zeroOrOneExpr{
+							pos: position{line: 581, col: 20, offset: 17197},
+							expr: &charClassMatcher{
+								pos:        position{line: 581, col: 20, offset: 17197},
+								val:        "[-+]",
+								chars:      []rune{'-', '+'},
+								ignoreCase: false,
+								inverted:   false,
+							},
+						},
+						&oneOrMoreExpr{
+							pos: position{line: 581, col: 26, offset: 17203},
+							expr: &charClassMatcher{
+								pos:        position{line: 581, col: 26, offset: 17203},
+								val:        "[0-9]",
+								ranges:     []rune{'0', '9'},
+								ignoreCase: false,
+								inverted:   false,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "CMD_SEARCH",
+			pos:  position{line: 586, col: 1, offset: 17283},
+			expr: &seqExpr{
+				pos: position{line: 586, col: 15, offset: 17297},
+				exprs: []any{
+					&litMatcher{
+						pos:        position{line: 586, col: 15, offset: 17297},
+						val:        "search",
+						ignoreCase: true,
+						want:       "\"search\"i",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 586, col: 25, offset: 17307},
+						name: "SPACE",
+					},
+				},
+			},
+		},
+		{
+			name: "CMD_SOURCE",
+			pos:  position{line: 587, col: 1, offset: 17313},
+			expr: &litMatcher{
+				pos:        position{line: 587, col: 15, offset: 17327},
+				val:        "source",
+				ignoreCase: true,
+				want:       "\"source\"i",
+			},
+		},
+		{
+			name: "CMD_WHERE",
+			pos:  position{line: 588, col: 1, offset: 17337},
+			expr: &seqExpr{
+				pos: position{line: 588, col: 14, offset: 17350},
+				exprs: []any{
+					&litMatcher{
+						pos:        position{line: 588, col: 14, offset: 17350},
+						val:        "where",
+						ignoreCase: true,
+						want:       "\"where\"i",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 588, col: 23, offset: 17359},
+						name: "SPACE",
+					},
+				},
+			},
+		},
+		{
+			name: "CMD_FIELDS",
+			pos:  position{line: 589, col: 1, offset: 17365},
+			expr: &seqExpr{
+				pos: position{line: 589, col: 15, offset: 17379},
+				exprs: []any{
+					&litMatcher{
+						pos:        position{line: 589, col: 15, offset: 17379},
+						val:        "fields",
+						ignoreCase: true,
+						want:       "\"fields\"i",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 589, col: 25, offset: 17389},
+						name: "SPACE",
+					},
+				},
+			},
+		},
+		{
+			name: "CMD_STATS",
+			pos:  position{line: 590, col: 1, offset: 17395},
+			expr: &seqExpr{
+				pos: position{line: 590, col: 14, offset: 17408},
+				exprs: []any{
+					&litMatcher{
+						pos:        position{line: 590, col: 14, offset: 17408},
+						val:        "stats",
+						ignoreCase: true,
+						want:       "\"stats\"i",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 590, col: 23, offset: 17417},
+						name: "SPACE",
+					},
+				},
+			},
+		},
+		{
+			name: "CMD_HEAD",
+			pos:  position{line: 591, col: 1, offset: 17423},
+			expr: &litMatcher{
+				pos:        position{line: 591, col: 13, offset: 17435},
+				val:        "head",
+				ignoreCase: true,
+				want:       "\"head\"i",
+			},
+		},
+		{
+			name: "CMD_RENAME",
+			pos:  position{line: 592, col: 1, offset: 17443},
+			expr: &seqExpr{
+				pos: position{line: 592, col: 15, offset: 17457},
+				exprs: []any{
+					&litMatcher{
+						pos:        position{line: 592, col: 15, offset: 17457},
+						val:        "rename",
+						ignoreCase: true,
+						want:       "\"rename\"i",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 592, col: 25, offset: 17467},
+						name: "SPACE",
+					},
+				},
+			},
+		},
+		{
+			name: "NOT",
+			pos:  position{line: 594, col: 1, offset: 17474},
+			expr: &seqExpr{
+				pos: position{line: 594, col: 8, offset: 17481},
+				exprs: []any{
+					&litMatcher{
+						pos:        position{line: 594, col: 8, offset: 17481},
+						val:        "not",
+						ignoreCase: true,
+						want:       "\"NOT\"i",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 594, col: 15, offset: 17488},
+						name: "SPACE",
+					},
+				},
+			},
+		},
+		{
+			name: "OR",
+			pos:  position{line: 595, col: 1, offset: 17494},
+			expr: &seqExpr{
+				pos: position{line: 595, col: 7, offset: 17500},
+				exprs: []any{
+					&ruleRefExpr{
+						pos:  position{line: 595, col: 7, offset: 17500},
+						name: "SPACE",
+					},
+					&litMatcher{
+						pos:        position{line: 595, col: 13, offset: 17506},
+						val:        "or",
+						ignoreCase: true,
+						want:       "\"OR\"i",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 595, col: 19, offset: 17512},
+						name: "SPACE",
+					},
+				},
+			},
+		},
+		{
+			name: "AND",
+			pos:  position{line: 596, col: 1, offset: 17518},
+			expr: &seqExpr{
+				pos: position{line: 596, col: 8, offset: 17525},
+				exprs: []any{
+					&ruleRefExpr{
+						pos:  position{line: 596, col: 8, offset: 17525},
+						name: "SPACE",
+					},
+					&litMatcher{
+						pos:        position{line: 596, col: 14, offset: 17531},
+						val:        "and",
+						ignoreCase: true,
+						want:       "\"AND\"i",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 596, col: 21, offset: 17538},
+						name: "SPACE",
+					},
+				},
+			},
+		},
+		{
+			name: "PIPE",
+			pos:  position{line: 597, col: 1, offset: 17544},
+			expr: &seqExpr{
+				pos: position{line: 597, col: 9, offset: 17552},
+				exprs: []any{
+					&ruleRefExpr{
+						pos:  position{line: 597, col: 9, offset: 17552},
+						name: "EMPTY_OR_SPACE",
+					},
+					&litMatcher{
+						pos:        position{line: 597, col: 24, offset: 17567},
+						val:        "|",
+						ignoreCase: false,
+						want:       "\"|\"",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 597, col: 28, offset: 17571},
+						name: "EMPTY_OR_SPACE",
+					},
+				},
+			},
+		},
+		{
+			name: "AS",
+			pos:  position{line: 598, col: 1, offset: 17586},
+			expr: &seqExpr{
+				pos: position{line: 598, col: 7, offset: 17592},
+				exprs: []any{
+					&ruleRefExpr{
+						pos:  position{line: 598, col: 7, offset: 17592},
+						name: "SPACE",
+					},
+					&litMatcher{
+						pos:        position{line: 598, col: 13, offset: 17598},
+						val:        "as",
+						ignoreCase: true,
+						want:       "\"AS\"i",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 598, col: 19, offset: 17604},
+						name: "SPACE",
+					},
+				},
+			},
+		},
+		{
+			name: "BY",
+			pos:  position{line: 599, col: 1, offset: 17610},
+			expr: &seqExpr{
+				pos: position{line: 599, col: 7, offset: 17616},
+				exprs: []any{
+					&ruleRefExpr{
+						pos:  position{line: 599, col: 7, offset: 17616},
+						name: "SPACE",
+					},
+					&litMatcher{
+						pos:        position{line: 599, col: 13, offset: 17622},
+						val:        "by",
+						ignoreCase: true,
+						want:       "\"BY\"i",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 599, col: 19, offset: 17628},
+						name: "SPACE",
+					},
+				},
+			},
+		},
+		{
+			name: "EQUAL",
+			pos:  position{line: 601, col: 1, offset: 17635},
+			expr: &seqExpr{
+				pos: position{line: 601, col: 10, offset: 17644},
+				exprs: []any{
+					&ruleRefExpr{
+						pos:  position{line: 601, col: 10, offset: 17644},
+						name: "EMPTY_OR_SPACE",
+					},
+					&litMatcher{
+						pos:        position{line: 601, col: 25, offset: 17659},
+						val:        "=",
+						ignoreCase: false,
+						want:       "\"=\"",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 601, col: 29, offset: 17663},
+						name: "EMPTY_OR_SPACE",
+					},
+				},
+			},
+		},
+		{
+			name: "COMMA",
+			pos:  position{line: 602, col: 1, offset: 17678},
+			expr: &seqExpr{
+				pos: position{line: 602, col: 10, offset: 17687},
+				exprs: []any{
+					&ruleRefExpr{
+						pos:  position{line: 602, col: 10, offset: 17687},
+						name: "EMPTY_OR_SPACE",
+					},
+					&litMatcher{
+						pos:        position{line: 602, col: 25, offset: 17702},
+						val:        ",",
+						ignoreCase: false,
+						want:       "\",\"",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 602, col: 29, offset: 17706},
+						name: "EMPTY_OR_SPACE",
+					},
+				},
+			},
+		},
+		{
+			name: "L_PAREN",
+			pos:  position{line: 603, col: 1, offset: 17721},
+			expr: &seqExpr{
+				pos: position{line: 603, col: 12, offset: 17732},
+				exprs: []any{
+					&ruleRefExpr{
+						pos:  position{line: 603, col: 12, offset: 17732},
+						name: "EMPTY_OR_SPACE",
+					},
+					&litMatcher{
+						pos:        position{line: 603, col: 27, offset: 17747},
+						val:        "(",
+						ignoreCase: false,
+						want:       "\"(\"",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 603, col: 31, offset: 17751},
+						name: "EMPTY_OR_SPACE",
+					},
+				},
+			},
+		},
+		{
+			name: "R_PAREN",
+			pos:  position{line: 604, col: 1, offset: 17766},
+			expr: &seqExpr{
+				pos: position{line: 604, col: 12, offset: 17777},
+				exprs: []any{
+					&ruleRefExpr{
+						pos:  position{line: 604, col: 12, offset: 17777},
+						name: "EMPTY_OR_SPACE",
+					},
+					&litMatcher{
+						pos:        position{line: 604, col: 27, offset: 17792},
+						val:        ")",
+						ignoreCase: false,
+						want:       "\")\"",
+					},
+				},
+			},
+		},
+		{
+			name: "EOF",
+			pos:  position{line: 606, col: 1, offset: 17797},
+			expr: &notExpr{
+				pos: position{line: 606, col: 8, offset: 17804},
+				expr: &anyMatcher{
+					line: 606, col: 9, offset: 17805,
+				},
+			},
+		},
+		{
+			name: "SPACE",
+			pos:  position{line: 607, col: 1, offset: 17807},
+			expr: &oneOrMoreExpr{
+				pos: position{line: 607, col: 10, offset: 17816},
+				expr: &charClassMatcher{
+					pos:        position{line: 607, col: 10, offset: 17816},
+					val:        "[ \\t\\r\\n]",
+					chars:      []rune{' ', '\t', '\r', '\n'},
+					ignoreCase: false,
+					inverted:   false,
+				},
+			},
+		},
+		{
+			name: "EMPTY_OR_SPACE",
+			pos:  position{line: 608, col: 1, offset: 17827},
+			expr: &choiceExpr{
+				pos: position{line: 608, col: 19, offset: 17845},
+				alternatives: []any{
+					&ruleRefExpr{
+						pos:  position{line: 608, col: 19, offset: 17845},
+						name: "SPACE",
+					},
+					&litMatcher{
+						pos:        position{line: 608, col: 27, offset: 17853},
+						val:        "",
+						ignoreCase: false,
+						want:       "\"\"",
+					},
+				},
+			},
+		},
+	},
+}
+
+func (c *current) onStart1(source, search, pipeBlocks any) (any, error) {
+	var q ast.QueryStruct
+	q.TableName = source.(string)
+	if search != nil {
+		q.SearchFilter = search.([]any)[1].(*ast.Node)
+	}
+
+	// The where commands before the stats are added to the search, so that the
+	// segments are filtered while they are searched. The ones after it filter
+	// the aggregation results instead.
+	var lastQueryAgg *structs.QueryAggregators
+	hasStats := false
+	hasHead := false
+	for _, block := range pipeBlocks.([]any) {
+		var queryAgg *structs.QueryAggregators
+		switch block := block.(type) {
+		case *ast.Node:
+			if !hasStats {
+				if hasHead {
+					return nil, errors.New("a where after head is only supported after stats")
+				}
+				q.SearchFilter = andNodes(q.SearchFilter, block)
+				continue
+			}
+
+			condition, err := toBoolExpr(block)
+			if err != nil {
+				return nil, fmt.Errorf("Ppl peg: Start: %v", err)
+			}
+			queryAgg = &structs.QueryAggregators{
+				PipeCommandType: structs.OutputTransformType,
+				OutputTransforms: &structs.OutputTransforms{
+					FilterRows: condition,
+				},
+			}
+		case *structs.QueryAggregators:
+			isStats := block.PipeCommandType == structs.GroupByType || block.PipeCommandType == structs.MeasureAggsType
+			if isStats && hasStats {
+				return nil, errors.New("only one stats command is supported")
+			}
+			hasStats = hasStats || isStats
+			hasHead = hasHead || (block.OutputTransforms != nil && block.OutputTransforms.MaxRows > 0)
+			queryAgg = block
+		}
+
+		// Link the chain of this command and go to its end.
+		if lastQueryAgg == nil {
+			q.PipeCommands = queryAgg
+		} else {
+			lastQueryAgg.Next = queryAgg
+		}
+		for lastQueryAgg = queryAgg; lastQueryAgg.Next != nil; lastQueryAgg = lastQueryAgg.Next {
+		}
+	}
+
+	return q, nil
+}
+
+func (p *parser) callonStart1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onStart1(stack["source"], stack["search"], stack["pipeBlocks"])
+}
+
+func (c *current) onSourceCommand1(indices any) (any, error) {
+	return strings.Join(indices.([]string), ","), nil
+}
+
+func (p *parser) callonSourceCommand1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSourceCommand1(stack["indices"])
+}
+
+func (c *current) onIndexNameList1(first, rest any) (any, error) {
+	restSlice := rest.([]any)
+	indices := make([]string, 1+len(restSlice))
+	indices[0] = first.(string)
+
+	for i := range restSlice {
+		separatorAndIndex := restSlice[i].([]any)
+		indices[i+1] = separatorAndIndex[1].(string)
+	}
+
+	return indices, nil
+}
+
+func (p *parser) callonIndexNameList1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onIndexNameList1(stack["first"], stack["rest"])
+}
+
+func (c *current) onIndexName2() (any, error) {
+	return string(c.text), nil
+}
+
+func (p *parser) callonIndexName2() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onIndexName2()
+}
+
+func (c *current) onIndexName5(name any) (any, error) {
+	return strings.Trim(string(c.text), "`"), nil
+}
+
+func (p *parser) callonIndexName5() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onIndexName5(stack["name"])
+}
+
+func (c *current) onSearchExpr1(expr any) (any, error) {
+	return expr, nil
+}
+
+func (p *parser) callonSearchExpr1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSearchExpr1(stack["expr"])
+}
+
+func (c *current) onBoolExprLevel41(first, rest any) (any, error) {
+	cur := first.(*ast.Node)
+	for _, v := range rest.([]any) {
+		opAndClause := v.([]any)
+		cur = &ast.Node{
+			NodeType: ast.NodeOr,
+			Left:     cur,
+			Right:    opAndClause[1].(*ast.Node),
+		}
+	}
+
+	return cur, nil
+}
+
+func (p *parser) callonBoolExprLevel41() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onBoolExprLevel41(stack["first"], stack["rest"])
+}
+
+func (c *current) onBoolExprLevel31(first, rest any) (any, error) {
+	cur := first.(*ast.Node)
+	for _, v := range rest.([]any) {
+		opAndClause := v.([]any)
+		cur = andNodes(cur, opAndClause[1].(*ast.Node))
+	}
+
+	return cur, nil
+}
+
+func (p *parser) callonBoolExprLevel31() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onBoolExprLevel31(stack["first"], stack["rest"])
+}
+
+func (c *current) onBoolExprLevel22(expr any) (any, error) {
+	node := expr.(*ast.Node)
+	deMorgansLaw(node)
+
+	return node, nil
+}
+
+func (p *parser) callonBoolExprLevel22() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onBoolExprLevel22(stack["expr"])
+}
+
+func (c *current) onBoolExprLevel27(expr any) (any, error) {
+	return expr, nil
+}
+
+func (p *parser) callonBoolExprLevel27() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onBoolExprLevel27(stack["expr"])
+}
+
+func (c *current) onBoolExprLevel12(expr any) (any, error) {
+	return expr, nil
+}
+
+func (p *parser) callonBoolExprLevel12() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onBoolExprLevel12(stack["expr"])
+}
+
+func (c *current) onBoolExprLevel18(comparison any) (any, error) {
+	return comparison, nil
+}
+
+func (p *parser) callonBoolExprLevel18() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onBoolExprLevel18(stack["comparison"])
+}
+
+func (c *current) onComparison1(field, op, value any) (any, error) {
+	fieldStr := field.(string)
+	if strings.Contains(fieldStr, "*") {
+		return nil, fmt.Errorf("Ppl peg: Comparison: wildcards are not allowed in compared fields: %v", fieldStr)
+	}
+
+	node := &ast.Node{
+		NodeType: ast.NodeTerminal,
+		Comparison: ast.Comparison{
+			Op:     op.(string),
+			Field:  fieldStr,
+			Values: value,
+		},
+	}
+
+	return node, nil
+}
+
+func (p *parser) callonComparison1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onComparison1(stack["field"], stack["op"], stack["value"])
+}
+
+func (c *current) onComparisonOperator1(op any) (any, error) {
+	return string(op.([]byte)), nil
+}
+
+func (p *parser) callonComparisonOperator1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onComparisonOperator1(stack["op"])
+}
+
+func (c *current) onLiteral2(number any) (any, error) {
+	return number, nil
+}
+
+func (p *parser) callonLiteral2() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onLiteral2(stack["number"])
+}
+
+func (c *current) onLiteral5(str any) (any, error) {
+	return str, nil
+}
+
+func (p *parser) callonLiteral5() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onLiteral5(stack["str"])
+}
+
+func (c *current) onPipeBlock1(block any) (any, error) {
+	return block, nil
+}
+
+func (p *parser) callonPipeBlock1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onPipeBlock1(stack["block"])
+}
+
+func (c *current) onWhereBlock1(condition any) (any, error) {
+	return condition, nil
+}
+
+func (p *parser) callonWhereBlock1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onWhereBlock1(stack["condition"])
+}
+
+func (c *current) onFieldsBlock1(op, fields any) (any, error) {
+	columnsRequest := &structs.ColumnsRequest{}
+	if op == nil || string(op.([]any)[0].([]byte)) == "+" {
+		columnsRequest.IncludeColumns = fields.([]string)
+	} else {
+		columnsRequest.ExcludeColumns = fields.([]string)
+	}
+
+	queryAggregator := &structs.QueryAggregators{
+		PipeCommandType: structs.OutputTransformType,
+		OutputTransforms: &structs.OutputTransforms{
+			OutputColumns: columnsRequest,
+		},
+	}
+
+	return queryAggregator, nil
+}
+
+func (p *parser) callonFieldsBlock1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onFieldsBlock1(stack["op"], stack["fields"])
+}
+
+func (c *current) onStatsBlock1(aggs, byFields any) (any, error) {
+	aggNode := &structs.QueryAggregators{}
+
+	// Extract the MeasureAggregators and check if any of the aggregation fields
+	// need to be renamed.
+	aggsSlice := aggs.([]*aggregator)
+	measureAggs := make([]*structs.MeasureAggregator, len(aggsSlice))
+	columnsRequest := &structs.ColumnsRequest{}
+	columnsRequest.RenameAggregationColumns = make(map[string]string, 0)
+
+	for i, agg := range aggsSlice {
+		measureAggs[i] = agg.measureAgg
+
+		if agg.renameOutputField {
+			columnsRequest.RenameAggregationColumns[measureAggs[i].String()] = agg.outputFieldNewName
+		}
+	}
+
+	// If any agg field was renamed, make a QueryAggregators for all the renames.
+	if len(columnsRequest.RenameAggregationColumns) > 0 {
+		renameNode := &structs.QueryAggregators{
+			PipeCommandType: structs.OutputTransformType,
+			OutputTransforms: &structs.OutputTransforms{
+				OutputColumns: columnsRequest,
+			},
+		}
+
+		aggNode.Next = renameNode
+	}
+
+	if byFields == nil {
+		aggNode.PipeCommandType = structs.MeasureAggsType
+		aggNode.MeasureOperations = measureAggs
+	} else {
+		groupByColumns := byFields.([]any)[1].([]string)
+		for _, field := range groupByColumns {
+			if strings.Contains(field, "*") {
+				return nil, errors.New("BY clause cannot contain fields with wildcards")
+			}
+		}
+
+		aggNode.PipeCommandType = structs.GroupByType
+		aggNode.GroupByRequest = &structs.GroupByRequest{
+			MeasureOperations: measureAggs,
+			GroupByColumns:    groupByColumns,
+		}
+		aggNode.BucketLimit = query.MAX_GRP_BUCKS
+	}
+
+	return aggNode, nil
+}
+
+func (p *parser) callonStatsBlock1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onStatsBlock1(stack["aggs"], stack["byFields"])
+}
+
+func (c *current) onAggregationList1(first, rest any) (any, error) {
+	restSlice := rest.([]any)
+	aggsSlice := make([]*aggregator, 1+len(restSlice))
+	aggsSlice[0] = first.(*aggregator)
+
+	for i := range restSlice {
+		separatorAndAgg := restSlice[i].([]any)
+		aggsSlice[i+1] = separatorAndAgg[1].(*aggregator)
+	}
+
+	return aggsSlice, nil
+}
+
+func (p *parser) callonAggregationList1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onAggregationList1(stack["first"], stack["rest"])
+}
+
+func (c *current) onAggregation1(aggFunc, asField any) (any, error) {
+	agg := &aggregator{}
+	agg.measureAgg = aggFunc.(*structs.MeasureAggregator)
+
+	if asField != nil {
+		fieldStr := asField.([]any)[1].(string)
+		if strings.Contains(fieldStr, "*") {
+			return nil, errors.New("The field specified in `AS` cannot contain wildcards")
+		}
+
+		agg.renameOutputField = true
+		agg.outputFieldNewName = fieldStr
+	}
+
+	return agg, nil
+}
+
+func (p *parser) callonAggregation1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onAggregation1(stack["aggFunc"], stack["asField"])
+}
+
+func (c *current) onAggFunction2() (any, error) {
+	agg := &structs.MeasureAggregator{
+		MeasureCol:  "*",
+		MeasureFunc: utils.Count,
+	}
+
+	return agg, nil
+}
+
+func (p *parser) callonAggFunction2() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onAggFunction2()
+}
+
+func (c *current) onAggFunction7(aggFunc, field any) (any, error) {
+	agg := &structs.MeasureAggregator{
+		MeasureCol:  field.(string),
+		MeasureFunc: aggFunc.(utils.AggregateFunctions),
+	}
+
+	return agg, nil
+}
+
+func (p *parser) callonAggFunction7() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onAggFunction7(stack["aggFunc"], stack["field"])
+}
+
+func (c *current) onAggFunctionName1() (any, error) {
+	switch strings.ToLower(string(c.text)) {
+	case "count":
+		return utils.Count, nil
+	case "sum":
+		return utils.Sum, nil
+	case "avg":
+		return utils.Avg, nil
+	case "min":
+		return utils.Min, nil
+	case "max":
+		return utils.Max, nil
+	default:
+		return utils.Cardinality, nil
+	}
+}
+
+func (p *parser) callonAggFunctionName1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onAggFunctionName1()
+}
+
+func (c *current) onHeadBlock1(size any) (any, error) {
+	limit := uint64(10) // the PPL default
+	if size != nil {
+		sizeStr := size.([]any)[1].(string)
+		var err error
+		limit, err = strconv.ParseUint(sizeStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid head size (%v): %v", sizeStr, err)
+		}
+		if limit == 0 {
+			return nil, errors.New("The head size must be positive")
+		}
+	}
+
+	queryAgg := &structs.QueryAggregators{
+		PipeCommandType: structs.OutputTransformType,
+		OutputTransforms: &structs.OutputTransforms{
+			MaxRows: limit,
+		},
+	}
+
+	return queryAgg, nil
+}
+
+func (p *parser) callonHeadBlock1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onHeadBlock1(stack["size"])
+}
+
+func (c *current) onRenameBlock1(first, rest any) (any, error) {
+	renameExprs := []any{first}
+	for _, separatorAndExpr := range rest.([]any) {
+		renameExprs = append(renameExprs, separatorAndExpr.([]any)[1])
+	}
+
+	var root, leafQueryAgg *structs.QueryAggregators
+	for _, renameExpr := range renameExprs {
+		queryAgg := &structs.QueryAggregators{
+			PipeCommandType: structs.OutputTransformType,
+			OutputTransforms: &structs.OutputTransforms{
+				LetColumns: &structs.LetColumnsRequest{
+					RenameColRequest: renameExpr.(*structs.RenameExpr),
+				},
+			},
+		}
+
+		if root == nil {
+			root = queryAgg
+		} else {
+			leafQueryAgg.Next = queryAgg
+		}
+		leafQueryAgg = queryAgg
+	}
+
+	return root, nil
+}
+
+func (p *parser) callonRenameBlock1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onRenameBlock1(stack["first"], stack["rest"])
+}
+
+func (c *current) onRenameExpr1(originalPattern, newPattern any) (any, error) {
+	isRegex, err := isRegexRename(originalPattern.(string), newPattern.(string))
+	if err != nil {
+		return nil, fmt.Errorf("Ppl peg: RenameExpr: %v", err)
+	}
+
+	var renameExprMode structs.RenameExprMode
+	if isRegex {
+		renameExprMode = structs.REMRegex
+	} else {
+		renameExprMode = structs.REMOverride
+	}
+
+	renameExpr := &structs.RenameExpr{
+		RenameExprMode:  renameExprMode,
+		OriginalPattern: originalPattern.(string),
+		NewPattern:      newPattern.(string),
+	}
+
+	return renameExpr, nil
+}
+
+func (p *parser) callonRenameExpr1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onRenameExpr1(stack["originalPattern"], stack["newPattern"])
+}
+
+func (c *current) onFieldNameList1(first, rest any) (any, error) {
+	restSlice := rest.([]any)
+	fields := make([]string, 1+len(restSlice))
+	fields[0] = first.(string)
+
+	for i := range restSlice {
+		separatorAndField := restSlice[i].([]any)
+		fields[i+1] = separatorAndField[1].(string)
+	}
+
+	return fields, nil
+}
+
+func (p *parser) callonFieldNameList1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onFieldNameList1(stack["first"], stack["rest"])
+}
+
+func (c *current) onFieldName2() (any, error) {
+	return string(c.text), nil
+}
+
+func (p *parser) callonFieldName2() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onFieldName2()
+}
+
+func (c *current) onFieldName7(name any) (any, error) {
+	return strings.Trim(string(c.text), "`"), nil
+}
+
+func (p *parser) callonFieldName7() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onFieldName7(stack["name"])
+}
+
+func (c *current) onQuotedString1() (any, error) {
+	str := string(c.text)
+	return "\"" + str[1:len(str)-1] + "\"", nil
+}
+
+func (p *parser) callonQuotedString1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onQuotedString1()
+}
+
+func (c *current) onNumber1(number any) (any, error) {
+	return json.Number(number.(string)), nil
+}
+
+func (p *parser) callonNumber1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onNumber1(stack["number"])
+}
+
+func (c *current) onFloatAsString1() (any, error) {
+	return string(c.text), nil
+}
+
+func (p *parser) callonFloatAsString1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onFloatAsString1()
+}
+
+func (c *current) onIntegerAsString1() (any, error) {
+	return string(c.text), nil
+}
+
+func (p *parser) callonIntegerAsString1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onIntegerAsString1()
+}
+
+var (
+	// errNoRule is returned when the grammar to parse has no rule.
+	errNoRule = errors.New("grammar has no rule")
+
+	// errInvalidEntrypoint is returned when the specified entrypoint rule
+	// does not exit.
+	errInvalidEntrypoint = errors.New("invalid entrypoint")
+
+	// errInvalidEncoding is returned when the source is not properly
+	// utf8-encoded.
+	errInvalidEncoding = errors.New("invalid encoding")
+
+	// errMaxExprCnt is used to signal that the maximum number of
+	// expressions have been parsed.
+	errMaxExprCnt = errors.New("max number of expresssions parsed")
+)
+
+// Option is a function that can set an option on the parser. It returns
+// the previous setting as an Option.
+type Option func(*parser) Option
+
+// MaxExpressions creates an Option to stop parsing after the provided
+// number of expressions have been parsed, if the value is 0 then the parser will
+// parse for as many steps as needed (possibly an infinite number).
+//
+// The default for maxExprCnt is 0.
+func MaxExpressions(maxExprCnt uint64) Option {
+	return func(p *parser) Option {
+		oldMaxExprCnt := p.maxExprCnt
+		p.maxExprCnt = maxExprCnt
+		return MaxExpressions(oldMaxExprCnt)
+	}
+}
+
+// Entrypoint creates an Option to set the rule name to use as entrypoint.
+// The rule name must have been specified in the -alternate-entrypoints
+// if generating the parser with the -optimize-grammar flag, otherwise
+// it may have been optimized out. Passing an empty string sets the
+// entrypoint to the first rule in the grammar.
+//
+// The default is to start parsing at the first rule in the grammar.
+func Entrypoint(ruleName string) Option {
+	return func(p *parser) Option {
+		oldEntrypoint := p.entrypoint
+		p.entrypoint = ruleName
+		if ruleName == "" {
+			p.entrypoint = g.rules[0].name
+		}
+		return Entrypoint(oldEntrypoint)
+	}
+}
+
+// Statistics adds a user provided Stats struct to the parser to allow
+// the user to process the results after the parsing has finished.
+// Also the key for the "no match" counter is set.
+//
+// Example usage:
+//
+//	input := "input"
+//	stats := Stats{}
+//	_, err := Parse("input-file", []byte(input), Statistics(&stats, "no match"))
+//	if err != nil {
+//	    log.Panicln(err)
+//	}
+//	b, err := json.MarshalIndent(stats.ChoiceAltCnt, "", "  ")
+//	if err != nil {
+//	    log.Panicln(err)
+//	}
+//	fmt.Println(string(b))
+func Statistics(stats *Stats, choiceNoMatch string) Option {
+	return func(p *parser) Option {
+		oldStats := p.Stats
+		p.Stats = stats
+		oldChoiceNoMatch := p.choiceNoMatch
+		p.choiceNoMatch = choiceNoMatch
+		if p.Stats.ChoiceAltCnt == nil {
+			p.Stats.ChoiceAltCnt = make(map[string]map[string]int)
+		}
+		return Statistics(oldStats, oldChoiceNoMatch)
+	}
+}
+
+// Debug creates an Option to set the debug flag to b. When set to true,
+// debugging information is printed to stdout while parsing.
+//
+// The default is false.
+func Debug(b bool) Option {
+	return func(p *parser) Option {
+		old := p.debug
+		p.debug = b
+		return Debug(old)
+	}
+}
+
+// Memoize creates an Option to set the memoize flag to b. When set to true,
+// the parser will cache all results so each expression is evaluated only
+// once. This guarantees linear parsing time even for pathological cases,
+// at the expense of more memory and slower times for typical cases.
+//
+// The default is false.
+func Memoize(b bool) Option {
+	return func(p *parser) Option {
+		old := p.memoize
+		p.memoize = b
+		return Memoize(old)
+	}
+}
+
+// AllowInvalidUTF8 creates an Option to allow invalid UTF-8 bytes.
+// Every invalid UTF-8 byte is treated as a utf8.RuneError (U+FFFD)
+// by character class matchers and is matched by the any matcher.
+// The returned matched value, c.text and c.offset are NOT affected.
+//
+// The default is false.
+func AllowInvalidUTF8(b bool) Option {
+	return func(p *parser) Option {
+		old := p.allowInvalidUTF8
+		p.allowInvalidUTF8 = b
+		return AllowInvalidUTF8(old)
+	}
+}
+
+// Recover creates an Option to set the recover flag to b. When set to
+// true, this causes the parser to recover from panics and convert it
+// to an error. Setting it to false can be useful while debugging to
+// access the full stack trace.
+//
+// The default is true.
+func Recover(b bool) Option {
+	return func(p *parser) Option {
+		old := p.recover
+		p.recover = b
+		return Recover(old)
+	}
+}
+
+// GlobalStore creates an Option to set a key to a certain value in
+// the globalStore.
+func GlobalStore(key string, value any) Option {
+	return func(p *parser) Option {
+		old := p.cur.globalStore[key]
+		p.cur.globalStore[key] = value
+		return GlobalStore(key, old)
+	}
+}
+
+// InitState creates an Option to set a key to a certain value in
+// the global "state" store.
+func InitState(key string, value any) Option {
+	return func(p *parser) Option {
+		old := p.cur.state[key]
+		p.cur.state[key] = value
+		return InitState(key, old)
+	}
+}
+
+// ParseFile parses the file identified by filename.
+func ParseFile(filename string, opts ...Option) (i any, err error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := f.Close(); closeErr != nil {
+			err = closeErr
+		}
+	}()
+	return ParseReader(filename, f, opts...)
+}
+
+// ParseReader parses the data from r using filename as information in the
+// error messages.
+func ParseReader(filename string, r io.Reader, opts ...Option) (any, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(filename, b, opts...)
+}
+
+// Parse parses the data from b using filename as information in the
+// error messages.
+func Parse(filename string, b []byte, opts ...Option) (any, error) {
+	return newParser(filename, b, opts...).parse(g)
+}
+
+// position records a position in the text.
+type position struct {
+	line, col, offset int
+}
+
+func (p position) String() string {
+	return strconv.Itoa(p.line) + ":" + strconv.Itoa(p.col) + " [" + strconv.Itoa(p.offset) + "]"
+}
+
+// savepoint stores all state required to go back to this point in the
+// parser.
+type savepoint struct {
+	position
+	rn rune
+	w  int
+}
+
+type current struct {
+	pos  position // start position of the match
+	text []byte   // raw text of the match
+
+	// state is a store for arbitrary key,value pairs that the user wants to be
+	// tied to the backtracking of the parser.
+	// This is always rolled back if a parsing rule fails.
+	state storeDict
+
+	// globalStore is a general store for the user to store arbitrary key-value
+	// pairs that they need to manage and that they do not want tied to the
+	// backtracking of the parser. This is only modified by the user and never
+	// rolled back by the parser. It is always up to the user to keep this in a
+	// consistent state.
+	globalStore storeDict
+}
+
+type storeDict map[string]any
+
+// the AST types...
+
+type grammar struct {
+	pos   position
+	rules []*rule
+}
+
+type rule struct {
+	pos         position
+	name        string
+	displayName string
+	expr        any
+}
+
+type choiceExpr struct {
+	pos          position
+	alternatives []any
+}
+
+type actionExpr struct {
+	pos  position
+	expr any
+	run  func(*parser) (any, error)
+}
+
+type recoveryExpr struct {
+	pos          position
+	expr         any
+	recoverExpr  any
+	failureLabel []string
+}
+
+type seqExpr struct {
+	pos   position
+	exprs []any
+}
+
+type throwExpr struct {
+	pos   position
+	label string
+}
+
+type labeledExpr struct {
+	pos   position
+	label string
+	expr  any
+}
+
+type expr struct {
+	pos  position
+	expr any
+}
+
+type (
+	andExpr        expr
+	notExpr        expr
+	zeroOrOneExpr  expr
+	zeroOrMoreExpr expr
+	oneOrMoreExpr  expr
+)
+
+type ruleRefExpr struct {
+	pos  position
+	name string
+}
+
+type stateCodeExpr struct {
+	pos position
+	run func(*parser) error
+}
+
+type andCodeExpr struct {
+	pos position
+	run func(*parser) (bool, error)
+}
+
+type notCodeExpr struct {
+	pos position
+	run func(*parser) (bool, error)
+}
+
+type litMatcher struct {
+	pos        position
+	val        string
+	ignoreCase bool
+	want       string
+}
+
+type charClassMatcher struct {
+	pos             position
+	val             string
+	basicLatinChars [128]bool
+	chars           []rune
+	ranges          []rune
+	classes         []*unicode.RangeTable
+	ignoreCase      bool
+	inverted        bool
+}
+
+type anyMatcher position
+
+// errList cumulates the errors found by the parser.
+type errList []error
+
+func (e *errList) add(err error) {
+	*e = append(*e, err)
+}
+
+func (e errList) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	e.dedupe()
+	return e
+}
+
+func (e *errList) dedupe() {
+	var cleaned []error
+	set := make(map[string]bool)
+	for _, err := range *e {
+		if msg := err.Error(); !set[msg] {
+			set[msg] = true
+			cleaned = append(cleaned, err)
+		}
+	}
+	*e = cleaned
+}
+
+func (e errList) Error() string {
+	switch len(e) {
+	case 0:
+		return ""
+	case 1:
+		return e[0].Error()
+	default:
+		var buf bytes.Buffer
+
+		for i, err := range e {
+			if i > 0 {
+				buf.WriteRune('\n')
+			}
+			buf.WriteString(err.Error())
+		}
+		return buf.String()
+	}
+}
+
+// parserError wraps an error with a prefix indicating the rule in which
+// the error occurred. The original error is stored in the Inner field.
+type parserError struct {
+	Inner    error
+	pos      position
+	prefix   string
+	expected []string
+}
+
+// Error returns the error message.
+func (p *parserError) Error() string {
+	return p.prefix + ": " + p.Inner.Error()
+}
+
+// newParser creates a parser with the specified input source and options.
+func newParser(filename string, b []byte, opts ...Option) *parser {
+	stats := Stats{
+		ChoiceAltCnt: make(map[string]map[string]int),
+	}
+
+	p := &parser{
+		filename: filename,
+		errs:     new(errList),
+		data:     b,
+		pt:       savepoint{position: position{line: 1}},
+		recover:  true,
+		cur: current{
+			state:       make(storeDict),
+			globalStore: make(storeDict),
+		},
+		maxFailPos:      position{col: 1, line: 1},
+		maxFailExpected: make([]string, 0, 20),
+		Stats:           &stats,
+		// start rule is rule [0] unless an alternate entrypoint is specified
+		entrypoint: g.rules[0].name,
+	}
+	p.setOptions(opts)
+
+	if p.maxExprCnt == 0 {
+		p.maxExprCnt = math.MaxUint64
+	}
+
+	return p
+}
+
+// setOptions applies the options to the parser.
+func (p *parser) setOptions(opts []Option) {
+	for _, opt := range opts {
+		opt(p)
+	}
+}
+
+type resultTuple struct {
+	v   any
+	b   bool
+	end savepoint
+}
+
+const choiceNoMatch = -1
+
+// Stats stores some statistics, gathered during parsing
+type Stats struct {
+	// ExprCnt counts the number of expressions processed during parsing
+	// This value is compared to the maximum number of expressions allowed
+	// (set by the MaxExpressions option).
+	ExprCnt uint64
+
+	// ChoiceAltCnt is used to count for each ordered choice expression,
+	// which alternative is used how may times.
+	// These numbers allow to optimize the order of the ordered choice expression
+	// to increase the performance of the parser
+	//
+	// The outer key of ChoiceAltCnt is composed of the name of the rule as well
+	// as the line and the column of the ordered choice.
+	// The inner key of ChoiceAltCnt is the number (one-based) of the matching alternative.
+	// For each alternative the number of matches are counted. If an ordered choice does not
+	// match, a special counter is incremented. The name of this counter is set with
+	// the parser option Statistics.
+	// For an alternative to be included in ChoiceAltCnt, it has to match at least once.
+	ChoiceAltCnt map[string]map[string]int
+}
+
+type parser struct {
+	filename string
+	pt       savepoint
+	cur      current
+
+	data []byte
+	errs *errList
+
+	depth   int
+	recover bool
+	debug   bool
+
+	memoize bool
+	// memoization table for the packrat algorithm:
+	// map[offset in source] map[expression or rule] {value, match}
+	memo map[int]map[any]resultTuple
+
+	// rules table, maps the rule identifier to the rule node
+	rules map[string]*rule
+	// variables stack, map of label to value
+	vstack []map[string]any
+	// rule stack, allows identification of the current rule in errors
+	rstack []*rule
+
+	// parse fail
+	maxFailPos            position
+	maxFailExpected       []string
+	maxFailInvertExpected bool
+
+	// max number of expressions to be parsed
+	maxExprCnt uint64
+	// entrypoint for the parser
+	entrypoint string
+
+	allowInvalidUTF8 bool
+
+	*Stats
+
+	choiceNoMatch string
+	// recovery expression stack, keeps track of the currently available recovery expression, these are traversed in reverse
+	recoveryStack []map[string]any
+}
+
+// push a variable set on the vstack.
+func (p *parser) pushV() {
+	if cap(p.vstack) == len(p.vstack) {
+		// create new empty slot in the stack
+		p.vstack = append(p.vstack, nil)
+	} else {
+		// slice to 1 more
+		p.vstack = p.vstack[:len(p.vstack)+1]
+	}
+
+	// get the last args set
+	m := p.vstack[len(p.vstack)-1]
+	if m != nil && len(m) == 0 {
+		// empty map, all good
+		return
+	}
+
+	m = make(map[string]any)
+	p.vstack[len(p.vstack)-1] = m
+}
+
+// pop a variable set from the vstack.
+func (p *parser) popV() {
+	// if the map is not empty, clear it
+	m := p.vstack[len(p.vstack)-1]
+	if len(m) > 0 {
+		// GC that map
+		p.vstack[len(p.vstack)-1] = nil
+	}
+	p.vstack = p.vstack[:len(p.vstack)-1]
+}
+
+// push a recovery expression with its labels to the recoveryStack
+func (p *parser) pushRecovery(labels []string, expr any) {
+	if cap(p.recoveryStack) == len(p.recoveryStack) {
+		// create new empty slot in the stack
+		p.recoveryStack = append(p.recoveryStack, nil)
+	} else {
+		// slice to 1 more
+		p.recoveryStack = p.recoveryStack[:len(p.recoveryStack)+1]
+	}
+
+	m := make(map[string]any, len(labels))
+	for _, fl := range labels {
+		m[fl] = expr
+	}
+	p.recoveryStack[len(p.recoveryStack)-1] = m
+}
+
+// pop a recovery expression from the recoveryStack
+func (p *parser) popRecovery() {
+	// GC that map
+	p.recoveryStack[len(p.recoveryStack)-1] = nil
+
+	p.recoveryStack = p.recoveryStack[:len(p.recoveryStack)-1]
+}
+
+func (p *parser) print(prefix, s string) string {
+	if !p.debug {
+		return s
+	}
+
+	fmt.Printf("%s %d:%d:%d: %s [%#U]\n",
+		prefix, p.pt.line, p.pt.col, p.pt.offset, s, p.pt.rn)
+	return s
+}
+
+func (p *parser) printIndent(mark string, s string) string {
+	return p.print(strings.Repeat(" ", p.depth)+mark, s)
+}
+
+func (p *parser) in(s string) string {
+	res := p.printIndent(">", s)
+	p.depth++
+	return res
+}
+
+func (p *parser) out(s string) string {
+	p.depth--
+	return p.printIndent("<", s)
+}
+
+func (p *parser) addErr(err error) {
+	p.addErrAt(err, p.pt.position, []string{})
+}
+
+func (p *parser) addErrAt(err error, pos position, expected []string) {
+	var buf bytes.Buffer
+	if p.filename != "" {
+		buf.WriteString(p.filename)
+	}
+	if buf.Len() > 0 {
+		buf.WriteString(":")
+	}
+	buf.WriteString(fmt.Sprintf("%d:%d (%d)", pos.line, pos.col, pos.offset))
+	if len(p.rstack) > 0 {
+		if buf.Len() > 0 {
+			buf.WriteString(": ")
+		}
+		rule := p.rstack[len(p.rstack)-1]
+		if rule.displayName != "" {
+			buf.WriteString("rule " + rule.displayName)
+		} else {
+			buf.WriteString("rule " + rule.name)
+		}
+	}
+	pe := &parserError{Inner: err, pos: pos, prefix: buf.String(), expected: expected}
+	p.errs.add(pe)
+}
+
+func (p *parser) failAt(fail bool, pos position, want string) {
+	// process fail if parsing fails and not inverted or parsing succeeds and invert is set
+	if fail == p.maxFailInvertExpected {
+		if pos.offset < p.maxFailPos.offset {
+			return
+		}
+
+		if pos.offset > p.maxFailPos.offset {
+			p.maxFailPos = pos
+			p.maxFailExpected = p.maxFailExpected[:0]
+		}
+
+		if p.maxFailInvertExpected {
+			want = "!" + want
+		}
+		p.maxFailExpected = append(p.maxFailExpected, want)
+	}
+}
+
+// read advances the parser to the next rune.
+func (p *parser) read() {
+	p.pt.offset += p.pt.w
+	rn, n := utf8.DecodeRune(p.data[p.pt.offset:])
+	p.pt.rn = rn
+	p.pt.w = n
+	p.pt.col++
+	if rn == '\n' {
+		p.pt.line++
+		p.pt.col = 0
+	}
+
+	if rn == utf8.RuneError && n == 1 { // see utf8.DecodeRune
+		if !p.allowInvalidUTF8 {
+			p.addErr(errInvalidEncoding)
+		}
+	}
+}
+
+// restore parser position to the savepoint pt.
+func (p *parser) restore(pt savepoint) {
+	if p.debug {
+		defer p.out(p.in("restore"))
+	}
+	if pt.offset == p.pt.offset {
+		return
+	}
+	p.pt = pt
+}
+
+// Cloner is implemented by any value that has a Clone method, which returns a
+// copy of the value. This is mainly used for types which are not passed by
+// value (e.g map, slice, chan) or structs that contain such types.
+//
+// This is used in conjunction with the global state feature to create proper
+// copies of the state to allow the parser to properly restore the state in
+// the case of backtracking.
+type Cloner interface {
+	Clone() any
+}
+
+var statePool = &sync.Pool{
+	New: func() any { return make(storeDict) },
+}
+
+func (sd storeDict) Discard() {
+	for k := range sd {
+		delete(sd, k)
+	}
+	statePool.Put(sd)
+}
+
+// clone and return parser current state.
+func (p *parser) cloneState() storeDict {
+	if p.debug {
+		defer p.out(p.in("cloneState"))
+	}
+
+	state := statePool.Get().(storeDict)
+	for k, v := range p.cur.state {
+		if c, ok := v.(Cloner); ok {
+			state[k] = c.Clone()
+		} else {
+			state[k] = v
+		}
+	}
+	return state
+}
+
+// restore parser current state to the state storeDict.
+// every restoreState should applied only one time for every cloned state
+func (p *parser) restoreState(state storeDict) {
+	if p.debug {
+		defer p.out(p.in("restoreState"))
+	}
+	p.cur.state.Discard()
+	p.cur.state = state
+}
+
+// get the slice of bytes from the savepoint start to the current position.
+func (p *parser) sliceFrom(start savepoint) []byte {
+	return p.data[start.position.offset:p.pt.position.offset]
+}
+
+func (p *parser) getMemoized(node any) (resultTuple, bool) {
+	if len(p.memo) == 0 {
+		return resultTuple{}, false
+	}
+	m := p.memo[p.pt.offset]
+	if len(m) == 0 {
+		return resultTuple{}, false
+	}
+	res, ok := m[node]
+	return res, ok
+}
+
+func (p *parser) setMemoized(pt savepoint, node any, tuple resultTuple) {
+	if p.memo == nil {
+		p.memo = make(map[int]map[any]resultTuple)
+	}
+	m := p.memo[pt.offset]
+	if m == nil {
+		m = make(map[any]resultTuple)
+		p.memo[pt.offset] = m
+	}
+	m[node] = tuple
+}
+
+func (p *parser) buildRulesTable(g *grammar) {
+	p.rules = make(map[string]*rule, len(g.rules))
+	for _, r := range g.rules {
+		p.rules[r.name] = r
+	}
+}
+
+func (p *parser) parse(g *grammar) (val any, err error) {
+	if len(g.rules) == 0 {
+		p.addErr(errNoRule)
+		return nil, p.errs.err()
+	}
+
+	// TODO : not super critical but this could be generated
+	p.buildRulesTable(g)
+
+	if p.recover {
+		// panic can be used in action code to stop parsing immediately
+		// and return the panic as an error.
+		defer func() {
+			if e := recover(); e != nil {
+				if p.debug {
+					defer p.out(p.in("panic handler"))
+				}
+				val = nil
+				switch e := e.(type) {
+				case error:
+					p.addErr(e)
+				default:
+					p.addErr(fmt.Errorf("%v", e))
+				}
+				err = p.errs.err()
+			}
+		}()
+	}
+
+	startRule, ok := p.rules[p.entrypoint]
+	if !ok {
+		p.addErr(errInvalidEntrypoint)
+		return nil, p.errs.err()
+	}
+
+	p.read() // advance to first rune
+	val, ok = p.parseRuleWrap(startRule)
+	if !ok {
+		if len(*p.errs) == 0 {
+			// If parsing fails, but no errors have been recorded, the expected values
+			// for the farthest parser position are returned as error.
+			maxFailExpectedMap := make(map[string]struct{}, len(p.maxFailExpected))
+			for _, v := range p.maxFailExpected {
+				maxFailExpectedMap[v] = struct{}{}
+			}
+			expected := make([]string, 0, len(maxFailExpectedMap))
+			eof := false
+			if _, ok := maxFailExpectedMap["!."]; ok {
+				delete(maxFailExpectedMap, "!.")
+				eof = true
+			}
+			for k := range maxFailExpectedMap {
+				expected = append(expected, k)
+			}
+			sort.Strings(expected)
+			if eof {
+				expected = append(expected, "EOF")
+			}
+			p.addErrAt(errors.New("no match found, expected: "+listJoin(expected, ", ", "or")), p.maxFailPos, expected)
+		}
+
+		return nil, p.errs.err()
+	}
+	return val, p.errs.err()
+}
+
+func listJoin(list []string, sep string, lastSep string) string {
+	switch len(list) {
+	case 0:
+		return ""
+	case 1:
+		return list[0]
+	default:
+		return strings.Join(list[:len(list)-1], sep) + " " + lastSep + " " + list[len(list)-1]
+	}
+}
+
+func (p *parser) parseRuleMemoize(rule *rule) (any, bool) {
+	res, ok := p.getMemoized(rule)
+	if ok {
+		p.restore(res.end)
+		return res.v, res.b
+	}
+
+	startMark := p.pt
+	val, ok := p.parseRule(rule)
+	p.setMemoized(startMark, rule, resultTuple{val, ok, p.pt})
+
+	return val, ok
+}
+
+func (p *parser) parseRuleWrap(rule *rule) (any, bool) {
+	if p.debug {
+		defer p.out(p.in("parseRule " + rule.name))
+	}
+	var (
+		val       any
+		ok        bool
+		startMark = p.pt
+	)
+
+	if p.memoize {
+		val, ok = p.parseRuleMemoize(rule)
+	} else {
+		val, ok = p.parseRule(rule)
+	}
+
+	if ok && p.debug {
+		p.printIndent("MATCH", string(p.sliceFrom(startMark)))
+	}
+	return val, ok
+}
+
+func (p *parser) parseRule(rule *rule) (any, bool) {
+	p.rstack = append(p.rstack, rule)
+	p.pushV()
+	val, ok := p.parseExprWrap(rule.expr)
+	p.popV()
+	p.rstack = p.rstack[:len(p.rstack)-1]
+	return val, ok
+}
+
+func (p *parser) parseExprWrap(expr any) (any, bool) {
+	var pt savepoint
+
+	if p.memoize {
+		res, ok := p.getMemoized(expr)
+		if ok {
+			p.restore(res.end)
+			return res.v, res.b
+		}
+		pt = p.pt
+	}
+
+	val, ok := p.parseExpr(expr)
+
+	if p.memoize {
+		p.setMemoized(pt, expr, resultTuple{val, ok, p.pt})
+	}
+	return val, ok
+}
+
+func (p *parser) parseExpr(expr any) (any, bool) {
+	p.ExprCnt++
+	if p.ExprCnt > p.maxExprCnt {
+		panic(errMaxExprCnt)
+	}
+
+	var val any
+	var ok bool
+	switch expr := expr.(type) {
+	case *actionExpr:
+		val, ok = p.parseActionExpr(expr)
+	case *andCodeExpr:
+		val, ok = p.parseAndCodeExpr(expr)
+	case *andExpr:
+		val, ok = p.parseAndExpr(expr)
+	case *anyMatcher:
+		val, ok = p.parseAnyMatcher(expr)
+	case *charClassMatcher:
+		val, ok = p.parseCharClassMatcher(expr)
+	case *choiceExpr:
+		val, ok = p.parseChoiceExpr(expr)
+	case *labeledExpr:
+		val, ok = p.parseLabeledExpr(expr)
+	case *litMatcher:
+		val, ok = p.parseLitMatcher(expr)
+	case *notCodeExpr:
+		val, ok = p.parseNotCodeExpr(expr)
+	case *notExpr:
+		val, ok = p.parseNotExpr(expr)
+	case *oneOrMoreExpr:
+		val, ok = p.parseOneOrMoreExpr(expr)
+	case *recoveryExpr:
+		val, ok = p.parseRecoveryExpr(expr)
+	case *ruleRefExpr:
+		val, ok = p.parseRuleRefExpr(expr)
+	case *seqExpr:
+		val, ok = p.parseSeqExpr(expr)
+	case *stateCodeExpr:
+		val, ok = p.parseStateCodeExpr(expr)
+	case *throwExpr:
+		val, ok = p.parseThrowExpr(expr)
+	case *zeroOrMoreExpr:
+		val, ok = p.parseZeroOrMoreExpr(expr)
+	case *zeroOrOneExpr:
+		val, ok = p.parseZeroOrOneExpr(expr)
+	default:
+		panic(fmt.Sprintf("unknown expression type %T", expr))
+	}
+	return val, ok
+}
+
+func (p *parser) parseActionExpr(act *actionExpr) (any, bool) {
+	if p.debug {
+		defer p.out(p.in("parseActionExpr"))
+	}
+
+	start := p.pt
+	val, ok := p.parseExprWrap(act.expr)
+	if ok {
+		p.cur.pos = start.position
+		p.cur.text = p.sliceFrom(start)
+		state := p.cloneState()
+		actVal, err := act.run(p)
+		if err != nil {
+			p.addErrAt(err, start.position, []string{})
+		}
+		p.restoreState(state)
+
+		val = actVal
+	}
+	if ok && p.debug {
+		p.printIndent("MATCH", string(p.sliceFrom(start)))
+	}
+	return val, ok
+}
+
+func (p *parser) parseAndCodeExpr(and *andCodeExpr) (any, bool) {
+	if p.debug {
+		defer p.out(p.in("parseAndCodeExpr"))
+	}
+
+	state := p.cloneState()
+
+	ok, err := and.run(p)
+	if err != nil {
+		p.addErr(err)
+	}
+	p.restoreState(state)
+
+	return nil, ok
+}
+
+func (p *parser) parseAndExpr(and *andExpr) (any, bool) {
+	if p.debug {
+		defer p.out(p.in("parseAndExpr"))
+	}
+
+	pt := p.pt
+	state := p.cloneState()
+	p.pushV()
+	_, ok := p.parseExprWrap(and.expr)
+	p.popV()
+	p.restoreState(state)
+	p.restore(pt)
+
+	return nil, ok
+}
+
+func (p *parser) parseAnyMatcher(any *anyMatcher) (any, bool) {
+	if p.debug {
+		defer p.out(p.in("parseAnyMatcher"))
+	}
+
+	if p.pt.rn == utf8.RuneError && p.pt.w == 0 {
+		// EOF - see utf8.DecodeRune
+		p.failAt(false, p.pt.position, ".")
+		return nil, false
+	}
+	start := p.pt
+	p.read()
+	p.failAt(true, start.position, ".")
+	return p.sliceFrom(start), true
+}
+
+func (p *parser) parseCharClassMatcher(chr *charClassMatcher) (any, bool) {
+	if p.debug {
+		defer p.out(p.in("parseCharClassMatcher"))
+	}
+
+	cur := p.pt.rn
+	start := p.pt
+
+	// can't match EOF
+	if cur == utf8.RuneError && p.pt.w == 0 { // see utf8.DecodeRune
+		p.failAt(false, start.position, chr.val)
+		return nil, false
+	}
+
+	if chr.ignoreCase {
+		cur = unicode.ToLower(cur)
+	}
+
+	// try to match in the list of available chars
+	for _, rn := range chr.chars {
+		if rn == cur {
+			if chr.inverted {
+				p.failAt(false, start.position, chr.val)
+				return nil, false
+			}
+			p.read()
+			p.failAt(true, start.position, chr.val)
+			return p.sliceFrom(start), true
+		}
+	}
+
+	// try to match in the list of ranges
+	for i := 0; i < len(chr.ranges); i += 2 {
+		if cur >= chr.ranges[i] && cur <= chr.ranges[i+1] {
+			if chr.inverted {
+				p.failAt(false, start.position, chr.val)
+				return nil, false
+			}
+			p.read()
+			p.failAt(true, start.position, chr.val)
+			return p.sliceFrom(start), true
+		}
+	}
+
+	// try to match in the list of Unicode classes
+	for _, cl := range chr.classes {
+		if unicode.Is(cl, cur) {
+			if chr.inverted {
+				p.failAt(false, start.position, chr.val)
+				return nil, false
+			}
+			p.read()
+			p.failAt(true, start.position, chr.val)
+			return p.sliceFrom(start), true
+		}
+	}
+
+	if chr.inverted {
+		p.read()
+		p.failAt(true, start.position, chr.val)
+		return p.sliceFrom(start), true
+	}
+	p.failAt(false, start.position, chr.val)
+	return nil, false
+}
+
+func (p *parser) incChoiceAltCnt(ch *choiceExpr, altI int) {
+	choiceIdent := fmt.Sprintf("%s %d:%d", p.rstack[len(p.rstack)-1].name, ch.pos.line, ch.pos.col)
+	m := p.ChoiceAltCnt[choiceIdent]
+	if m == nil {
+		m = make(map[string]int)
+		p.ChoiceAltCnt[choiceIdent] = m
+	}
+	// We increment altI by 1, so the keys do not start at 0
+	alt := strconv.Itoa(altI + 1)
+	if altI == choiceNoMatch {
+		alt = p.choiceNoMatch
+	}
+	m[alt]++
+}
+
+func (p *parser) parseChoiceExpr(ch *choiceExpr) (any, bool) {
+	if p.debug {
+		defer p.out(p.in("parseChoiceExpr"))
+	}
+
+	for altI, alt := range ch.alternatives {
+		// dummy assignment to prevent compile error if optimized
+		_ = altI
+
+		state := p.cloneState()
+
+		p.pushV()
+		val, ok := p.parseExprWrap(alt)
+		p.popV()
+		if ok {
+			p.incChoiceAltCnt(ch, altI)
+			return val, ok
+		}
+		p.restoreState(state)
+	}
+	p.incChoiceAltCnt(ch, choiceNoMatch)
+	return nil, false
+}
+
+func (p *parser) parseLabeledExpr(lab *labeledExpr) (any, bool) {
+	if p.debug {
+		defer p.out(p.in("parseLabeledExpr"))
+	}
+
+	p.pushV()
+	val, ok := p.parseExprWrap(lab.expr)
+	p.popV()
+	if ok && lab.label != "" {
+		m := p.vstack[len(p.vstack)-1]
+		m[lab.label] = val
+	}
+	return val, ok
+}
+
+func (p *parser) parseLitMatcher(lit *litMatcher) (any, bool) {
+	if p.debug {
+		defer p.out(p.in("parseLitMatcher"))
+	}
+
+	start := p.pt
+	for _, want := range lit.val {
+		cur := p.pt.rn
+		if lit.ignoreCase {
+			cur = unicode.ToLower(cur)
+		}
+		if cur != want {
+			p.failAt(false, start.position, lit.want)
+			p.restore(start)
+			return nil, false
+		}
+		p.read()
+	}
+	p.failAt(true, start.position, lit.want)
+	return p.sliceFrom(start), true
+}
+
+func (p *parser) parseNotCodeExpr(not *notCodeExpr) (any, bool) {
+	if p.debug {
+		defer p.out(p.in("parseNotCodeExpr"))
+	}
+
+	state := p.cloneState()
+
+	ok, err := not.run(p)
+	if err != nil {
+		p.addErr(err)
+	}
+	p.restoreState(state)
+
+	return nil, !ok
+}
+
+func (p *parser) parseNotExpr(not *notExpr) (any, bool) {
+	if p.debug {
+		defer p.out(p.in("parseNotExpr"))
+	}
+
+	pt := p.pt
+	state := p.cloneState()
+	p.pushV()
+	p.maxFailInvertExpected = !p.maxFailInvertExpected
+	_, ok := p.parseExprWrap(not.expr)
+	p.maxFailInvertExpected = !p.maxFailInvertExpected
+	p.popV()
+	p.restoreState(state)
+	p.restore(pt)
+
+	return nil, !ok
+}
+
+func (p *parser) parseOneOrMoreExpr(expr *oneOrMoreExpr) (any, bool) {
+	if p.debug {
+		defer p.out(p.in("parseOneOrMoreExpr"))
+	}
+
+	var vals []any
+
+	for {
+		p.pushV()
+		val, ok := p.parseExprWrap(expr.expr)
+		p.popV()
+		if !ok {
+			if len(vals) == 0 {
+				// did not match once, no match
+				return nil, false
+			}
+			return vals, true
+		}
+		vals = append(vals, val)
+	}
+}
+
+func (p *parser) parseRecoveryExpr(recover *recoveryExpr) (any, bool) {
+	if p.debug {
+		defer p.out(p.in("parseRecoveryExpr (" + strings.Join(recover.failureLabel, ",") + ")"))
+	}
+
+	p.pushRecovery(recover.failureLabel, recover.recoverExpr)
+	val, ok := p.parseExprWrap(recover.expr)
+	p.popRecovery()
+
+	return val, ok
+}
+
+func (p *parser) parseRuleRefExpr(ref *ruleRefExpr) (any, bool) {
+	if p.debug {
+		defer p.out(p.in("parseRuleRefExpr " + ref.name))
+	}
+
+	if ref.name == "" {
+		panic(fmt.Sprintf("%s: invalid rule: missing name", ref.pos))
+	}
+
+	rule := p.rules[ref.name]
+	if rule == nil {
+		p.addErr(fmt.Errorf("undefined rule: %s", ref.name))
+		return nil, false
+	}
+	return p.parseRuleWrap(rule)
+}
+
+func (p *parser) parseSeqExpr(seq *seqExpr) (any, bool) {
+	if p.debug {
+		defer p.out(p.in("parseSeqExpr"))
+	}
+
+	vals := make([]any, 0, len(seq.exprs))
+
+	pt := p.pt
+	state := p.cloneState()
+	for _, expr := range seq.exprs {
+		val, ok := p.parseExprWrap(expr)
+		if !ok {
+			p.restoreState(state)
+			p.restore(pt)
+			return nil, false
+		}
+		vals = append(vals, val)
+	}
+	return vals, true
+}
+
+func (p *parser) parseStateCodeExpr(state *stateCodeExpr) (any, bool) {
+	if p.debug {
+		defer p.out(p.in("parseStateCodeExpr"))
+	}
+
+	err := state.run(p)
+	if err != nil {
+		p.addErr(err)
+	}
+	return nil, true
+}
+
+func (p *parser) parseThrowExpr(expr *throwExpr) (any, bool) {
+	if p.debug {
+		defer p.out(p.in("parseThrowExpr"))
+	}
+
+	for i := len(p.recoveryStack) - 1; i >= 0; i-- {
+		if recoverExpr, ok := p.recoveryStack[i][expr.label]; ok {
+			if val, ok := p.parseExprWrap(recoverExpr); ok {
+				return val, ok
+			}
+		}
+	}
+
+	return nil, false
+}
+
+func (p *parser) parseZeroOrMoreExpr(expr *zeroOrMoreExpr) (any, bool) {
+	if p.debug {
+		defer p.out(p.in("parseZeroOrMoreExpr"))
+	}
+
+	var vals []any
+
+	for {
+		p.pushV()
+		val, ok := p.parseExprWrap(expr.expr)
+		p.popV()
+		if !ok {
+			return vals, true
+		}
+		vals = append(vals, val)
+	}
+}
+
+func (p *parser) parseZeroOrOneExpr(expr *zeroOrOneExpr) (any, bool) {
+	if p.debug {
+		defer p.out(p.in("parseZeroOrOneExpr"))
+	}
+
+	p.pushV()
+	val, _ := p.parseExprWrap(expr.expr)
+	p.popV()
+	// whether it matched or not, consider it a match
+	return val, true
+}
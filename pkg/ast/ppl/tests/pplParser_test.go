@@ -0,0 +1,146 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/siglens/siglens/pkg/ast"
+	"github.com/siglens/siglens/pkg/ast/pipesearch"
+	"github.com/siglens/siglens/pkg/ast/ppl"
+	segquery "github.com/siglens/siglens/pkg/segment/query"
+	"github.com/siglens/siglens/pkg/segment/structs"
+	"github.com/siglens/siglens/pkg/segment/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SourceAndSearch(t *testing.T) {
+	res, err := ppl.Parse("", []byte(`source=web-logs, app* status>=500 and not (method="GET" or host='a.b')`))
+	assert.Nil(t, err)
+	query := res.(ast.QueryStruct)
+	assert.Equal(t, "web-logs,app*", query.TableName)
+	assert.Nil(t, query.PipeCommands)
+
+	// The NOT is pushed down to the comparisons.
+	filter := query.SearchFilter
+	assert.Equal(t, ast.NodeAnd, filter.NodeType)
+	assert.Equal(t, ast.Comparison{Op: ">=", Field: "status", Values: json.Number("500")}, filter.Left.Comparison)
+	assert.Equal(t, ast.NodeAnd, filter.Right.NodeType)
+	assert.Equal(t, ast.Comparison{Op: "!=", Field: "method", Values: `"GET"`}, filter.Right.Left.Comparison)
+	assert.Equal(t, ast.Comparison{Op: "!=", Field: "host", Values: `"a.b"`}, filter.Right.Right.Comparison)
+
+	res, err = ppl.Parse("", []byte("SEARCH SOURCE = `my index`"))
+	assert.Nil(t, err)
+	assert.Equal(t, "my index", res.(ast.QueryStruct).TableName)
+	assert.Nil(t, res.(ast.QueryStruct).SearchFilter)
+}
+
+func Test_WhereAndStats(t *testing.T) {
+	res, err := ppl.Parse("", []byte(`source=web-logs latency > 1.5 | where status = 500 | stats count() as c, avg(latency) by host, region | where c > 10 | head 5`))
+	assert.Nil(t, err)
+	query := res.(ast.QueryStruct)
+
+	// The where before the stats is part of the search.
+	assert.Equal(t, ast.NodeAnd, query.SearchFilter.NodeType)
+	assert.Equal(t, ast.Comparison{Op: ">", Field: "latency", Values: json.Number("1.5")}, query.SearchFilter.Left.Comparison)
+	assert.Equal(t, ast.Comparison{Op: "=", Field: "status", Values: json.Number("500")}, query.SearchFilter.Right.Comparison)
+
+	aggs := query.PipeCommands
+	assert.Equal(t, structs.GroupByType, aggs.PipeCommandType)
+	assert.Equal(t, []string{"host", "region"}, aggs.GroupByRequest.GroupByColumns)
+	measureAggs := aggs.GroupByRequest.MeasureOperations
+	assert.Len(t, measureAggs, 2)
+	assert.Equal(t, "*", measureAggs[0].MeasureCol)
+	assert.Equal(t, utils.Count, measureAggs[0].MeasureFunc)
+	assert.Equal(t, "latency", measureAggs[1].MeasureCol)
+	assert.Equal(t, utils.Avg, measureAggs[1].MeasureFunc)
+	assert.Equal(t, segquery.MAX_GRP_BUCKS, aggs.BucketLimit)
+
+	renameAgg := aggs.Next
+	assert.Equal(t, map[string]string{"count(*)": "c"}, renameAgg.OutputTransforms.OutputColumns.RenameAggregationColumns)
+
+	// The where after the stats filters the buckets.
+	filterAgg := renameAgg.Next
+	filterRows := filterAgg.OutputTransforms.FilterRows
+	assert.NotNil(t, filterRows)
+	assert.Equal(t, ">", filterRows.ValueOp)
+	assert.Equal(t, []string{"c"}, filterRows.GetFields())
+	assert.Equal(t, "10", filterRows.RightValue.NumericExpr.Value)
+
+	headAgg := filterAgg.Next
+	assert.Equal(t, uint64(5), headAgg.OutputTransforms.MaxRows)
+	assert.Nil(t, headAgg.Next)
+}
+
+func Test_FieldsRenameAndHead(t *testing.T) {
+	res, err := ppl.Parse("", []byte(`source=logs | fields - password, token | rename host as hostname, k* as key* | head`))
+	assert.Nil(t, err)
+	aggs := res.(ast.QueryStruct).PipeCommands
+	assert.Equal(t, []string{"password", "token"}, aggs.OutputTransforms.OutputColumns.ExcludeColumns)
+
+	rename := aggs.Next.OutputTransforms.LetColumns.RenameColRequest
+	assert.Equal(t, &structs.RenameExpr{RenameExprMode: structs.REMOverride, OriginalPattern: "host", NewPattern: "hostname"}, rename)
+	rename = aggs.Next.Next.OutputTransforms.LetColumns.RenameColRequest
+	assert.Equal(t, &structs.RenameExpr{RenameExprMode: structs.REMRegex, OriginalPattern: "k*", NewPattern: "key*"}, rename)
+	assert.Equal(t, uint64(10), aggs.Next.Next.Next.OutputTransforms.MaxRows)
+
+	res, err = ppl.Parse("", []byte(`source=logs | stats dc(user), max(bytes)`))
+	assert.Nil(t, err)
+	aggs = res.(ast.QueryStruct).PipeCommands
+	assert.Equal(t, structs.MeasureAggsType, aggs.PipeCommandType)
+	assert.Equal(t, []*structs.MeasureAggregator{
+		{MeasureCol: "user", MeasureFunc: utils.Cardinality},
+		{MeasureCol: "bytes", MeasureFunc: utils.Max},
+	}, aggs.MeasureOperations)
+	assert.Nil(t, aggs.Next)
+}
+
+func Test_InvalidQueries(t *testing.T) {
+	queries := []string{
+		`status=500`,
+		`source=logs | where a* = 1`,
+		`source=logs | stats count() by host* `,
+		`source=logs | stats count() | stats count()`,
+		`source=logs | head 5 | where a = 1`,
+		`source=logs | head 0`,
+		`source=logs | rename a* as b`,
+		`source=logs | where a = b`,
+	}
+	for _, query := range queries {
+		_, err := ppl.Parse("", []byte(query))
+		assert.NotNil(t, err, query)
+	}
+}
+
+func Test_ParsePPLRequest(t *testing.T) {
+	boolNode, aggs, indexName, err := pipesearch.ParsePPLRequest(`source=web-logs status=500`, 1000, 2000, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, "web-logs", indexName)
+	assert.NotNil(t, boolNode.AndFilterCondition)
+	assert.Equal(t, uint64(1000), boolNode.TimeRange.StartEpochMs)
+	assert.True(t, aggs.EarlyExit)
+
+	_, aggs, indexName, err = pipesearch.ParsePPLRequest(`source=a,b | stats count() by host`, 1000, 2000, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, "a,b", indexName)
+	assert.Equal(t, "a,b", aggs.TableName)
+	assert.Equal(t, []string{"host"}, aggs.GroupByRequest.GroupByColumns)
+
+	_, _, _, err = pipesearch.ParsePPLRequest(`*`, 1000, 2000, 0)
+	assert.NotNil(t, err)
+}
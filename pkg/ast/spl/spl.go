@@ -15,6 +15,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 	"unicode"
 	"unicode/utf8"
 
@@ -155,63 +156,88 @@ type singleAggTemp struct {
 	SplitByClause *structs.SplitByClause
 }
 
+type spanSearchTemp struct {
+	filter  *ast.Node
+	request *structs.SpanSearchRequest
+}
+
+func createSpanSearchCondition(field string, op string, value interface{}) *ast.Node {
+	return &ast.Node{
+		NodeType: ast.NodeTerminal,
+		Comparison: ast.Comparison{
+			Op:     op,
+			Field:  field,
+			Values: value,
+		},
+	}
+}
+
 var g = &grammar{
 	rules: []*rule{
 		{
 			name: "Start",
-			pos:  position{line: 146, col: 1, offset: 3973},
+			pos:  position{line: 162, col: 1, offset: 4340},
 			expr: &actionExpr{
-				pos: position{line: 146, col: 10, offset: 3982},
+				pos: position{line: 162, col: 10, offset: 4349},
 				run: (*parser).callonStart1,
 				expr: &seqExpr{
-					pos: position{line: 146, col: 10, offset: 3982},
+					pos: position{line: 162, col: 10, offset: 4349},
 					exprs: []any{
 						&zeroOrOneExpr{
-							pos: position{line: 146, col: 10, offset: 3982},
+							pos: position{line: 162, col: 10, offset: 4349},
 							expr: &ruleRefExpr{
-								pos:  position{line: 146, col: 10, offset: 3982},
+								pos:  position{line: 162, col: 10, offset: 4349},
 								name: "SPACE",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 146, col: 17, offset: 3989},
+							pos:   position{line: 162, col: 17, offset: 4356},
 							label: "initialSearch",
-							expr: &ruleRefExpr{
-								pos:  position{line: 146, col: 32, offset: 4004},
-								name: "InitialSearchBlock",
+							expr: &choiceExpr{
+								pos: position{line: 162, col: 32, offset: 4371},
+								alternatives: []any{
+									&ruleRefExpr{
+										pos:  position{line: 162, col: 32, offset: 4371},
+										name: "SpanSearchBlock",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 162, col: 50, offset: 4389},
+										name: "InitialSearchBlock",
+									},
+								},
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 146, col: 52, offset: 4024},
+							pos:   position{line: 162, col: 70, offset: 4409},
 							label: "filterBlocks",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 146, col: 65, offset: 4037},
+								pos: position{line: 162, col: 83, offset: 4422},
 								expr: &ruleRefExpr{
-									pos:  position{line: 146, col: 66, offset: 4038},
+									pos:  position{line: 162, col: 84, offset: 4423},
 									name: "FilterBlock",
 								},
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 146, col: 80, offset: 4052},
+							pos:   position{line: 162, col: 98, offset: 4437},
 							label: "queryAggBlocks",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 146, col: 95, offset: 4067},
+								pos: position{line: 162, col: 113, offset: 4452},
 								expr: &ruleRefExpr{
-									pos:  position{line: 146, col: 96, offset: 4068},
+									pos:  position{line: 162, col: 114, offset: 4453},
 									name: "QueryAggergatorBlock",
 								},
 							},
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 146, col: 119, offset: 4091},
+							pos: position{line: 162, col: 137, offset: 4476},
 							expr: &ruleRefExpr{
-								pos:  position{line: 146, col: 119, offset: 4091},
+								pos:  position{line: 162, col: 137, offset: 4476},
 								name: "SPACE",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 146, col: 126, offset: 4098},
+							pos:  position{line: 162, col: 144, offset: 4483},
 							name: "EOF",
 						},
 					},
@@ -220,25 +246,25 @@ var g = &grammar{
 		},
 		{
 			name: "InitialSearchBlock",
-			pos:  position{line: 217, col: 1, offset: 6307},
+			pos:  position{line: 237, col: 1, offset: 6845},
 			expr: &actionExpr{
-				pos: position{line: 217, col: 23, offset: 6329},
+				pos: position{line: 237, col: 23, offset: 6867},
 				run: (*parser).callonInitialSearchBlock1,
 				expr: &seqExpr{
-					pos: position{line: 217, col: 23, offset: 6329},
+					pos: position{line: 237, col: 23, offset: 6867},
 					exprs: []any{
 						&zeroOrOneExpr{
-							pos: position{line: 217, col: 23, offset: 6329},
+							pos: position{line: 237, col: 23, offset: 6867},
 							expr: &ruleRefExpr{
-								pos:  position{line: 217, col: 23, offset: 6329},
+								pos:  position{line: 237, col: 23, offset: 6867},
 								name: "CMD_SEARCH",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 217, col: 35, offset: 6341},
+							pos:   position{line: 237, col: 35, offset: 6879},
 							label: "clause",
 							expr: &ruleRefExpr{
-								pos:  position{line: 217, col: 42, offset: 6348},
+								pos:  position{line: 237, col: 42, offset: 6886},
 								name: "ClauseLevel4",
 							},
 						},
@@ -246,24 +272,343 @@ var g = &grammar{
 				},
 			},
 		},
+		{
+			name: "SpanSearchBlock",
+			pos:  position{line: 250, col: 1, offset: 7307},
+			expr: &actionExpr{
+				pos: position{line: 250, col: 20, offset: 7326},
+				run: (*parser).callonSpanSearchBlock1,
+				expr: &seqExpr{
+					pos: position{line: 250, col: 20, offset: 7326},
+					exprs: []any{
+						&ruleRefExpr{
+							pos:  position{line: 250, col: 20, offset: 7326},
+							name: "CMD_SPANSEARCH",
+						},
+						&labeledExpr{
+							pos:   position{line: 250, col: 35, offset: 7341},
+							label: "args",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 250, col: 40, offset: 7346},
+								expr: &seqExpr{
+									pos: position{line: 250, col: 41, offset: 7347},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 250, col: 41, offset: 7347},
+											name: "SPACE",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 250, col: 47, offset: 7353},
+											name: "SpanSearchArg",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "SpanSearchArg",
+			pos:  position{line: 277, col: 1, offset: 8275},
+			expr: &choiceExpr{
+				pos: position{line: 277, col: 18, offset: 8292},
+				alternatives: []any{
+					&actionExpr{
+						pos: position{line: 277, col: 18, offset: 8292},
+						run: (*parser).callonSpanSearchArg2,
+						expr: &seqExpr{
+							pos: position{line: 277, col: 18, offset: 8292},
+							exprs: []any{
+								&litMatcher{
+									pos:        position{line: 277, col: 18, offset: 8292},
+									val:        "service",
+									ignoreCase: false,
+									want:       "\"service\"",
+								},
+								&ruleRefExpr{
+									pos:  position{line: 277, col: 28, offset: 8302},
+									name: "EQUAL",
+								},
+								&labeledExpr{
+									pos:   position{line: 277, col: 34, offset: 8308},
+									label: "value",
+									expr: &ruleRefExpr{
+										pos:  position{line: 277, col: 40, offset: 8314},
+										name: "String",
+									},
+								},
+							},
+						},
+					},
+					&actionExpr{
+						pos: position{line: 279, col: 5, offset: 8392},
+						run: (*parser).callonSpanSearchArg8,
+						expr: &seqExpr{
+							pos: position{line: 279, col: 5, offset: 8392},
+							exprs: []any{
+								&litMatcher{
+									pos:        position{line: 279, col: 5, offset: 8392},
+									val:        "operation",
+									ignoreCase: false,
+									want:       "\"operation\"",
+								},
+								&ruleRefExpr{
+									pos:  position{line: 279, col: 17, offset: 8404},
+									name: "EQUAL",
+								},
+								&labeledExpr{
+									pos:   position{line: 279, col: 23, offset: 8410},
+									label: "value",
+									expr: &ruleRefExpr{
+										pos:  position{line: 279, col: 29, offset: 8416},
+										name: "String",
+									},
+								},
+							},
+						},
+					},
+					&actionExpr{
+						pos: position{line: 281, col: 5, offset: 8491},
+						run: (*parser).callonSpanSearchArg14,
+						expr: &seqExpr{
+							pos: position{line: 281, col: 5, offset: 8491},
+							exprs: []any{
+								&litMatcher{
+									pos:        position{line: 281, col: 5, offset: 8491},
+									val:        "tag.",
+									ignoreCase: false,
+									want:       "\"tag.\"",
+								},
+								&labeledExpr{
+									pos:   position{line: 281, col: 12, offset: 8498},
+									label: "key",
+									expr: &ruleRefExpr{
+										pos:  position{line: 281, col: 16, offset: 8502},
+										name: "FieldName",
+									},
+								},
+								&ruleRefExpr{
+									pos:  position{line: 281, col: 26, offset: 8512},
+									name: "EQUAL",
+								},
+								&labeledExpr{
+									pos:   position{line: 281, col: 32, offset: 8518},
+									label: "value",
+									expr: &ruleRefExpr{
+										pos:  position{line: 281, col: 38, offset: 8524},
+										name: "String",
+									},
+								},
+							},
+						},
+					},
+					&actionExpr{
+						pos: position{line: 286, col: 5, offset: 8735},
+						run: (*parser).callonSpanSearchArg22,
+						expr: &seqExpr{
+							pos: position{line: 286, col: 5, offset: 8735},
+							exprs: []any{
+								&labeledExpr{
+									pos:   position{line: 286, col: 5, offset: 8735},
+									label: "durationArg",
+									expr: &choiceExpr{
+										pos: position{line: 286, col: 18, offset: 8748},
+										alternatives: []any{
+											&litMatcher{
+												pos:        position{line: 286, col: 18, offset: 8748},
+												val:        "minduration",
+												ignoreCase: false,
+												want:       "\"minduration\"",
+											},
+											&litMatcher{
+												pos:        position{line: 286, col: 34, offset: 8764},
+												val:        "maxduration",
+												ignoreCase: false,
+												want:       "\"maxduration\"",
+											},
+										},
+									},
+								},
+								&ruleRefExpr{
+									pos:  position{line: 286, col: 49, offset: 8779},
+									name: "EQUAL",
+								},
+								&labeledExpr{
+									pos:   position{line: 286, col: 55, offset: 8785},
+									label: "duration",
+									expr: &ruleRefExpr{
+										pos:  position{line: 286, col: 64, offset: 8794},
+										name: "SpanSearchDuration",
+									},
+								},
+							},
+						},
+					},
+					&actionExpr{
+						pos: position{line: 293, col: 5, offset: 9066},
+						run: (*parser).callonSpanSearchArg31,
+						expr: &seqExpr{
+							pos: position{line: 293, col: 5, offset: 9066},
+							exprs: []any{
+								&litMatcher{
+									pos:        position{line: 293, col: 5, offset: 9066},
+									val:        "error",
+									ignoreCase: false,
+									want:       "\"error\"",
+								},
+								&ruleRefExpr{
+									pos:  position{line: 293, col: 13, offset: 9074},
+									name: "EQUAL",
+								},
+								&labeledExpr{
+									pos:   position{line: 293, col: 19, offset: 9080},
+									label: "isError",
+									expr: &choiceExpr{
+										pos: position{line: 293, col: 28, offset: 9089},
+										alternatives: []any{
+											&litMatcher{
+												pos:        position{line: 293, col: 28, offset: 9089},
+												val:        "true",
+												ignoreCase: false,
+												want:       "\"true\"",
+											},
+											&litMatcher{
+												pos:        position{line: 293, col: 37, offset: 9098},
+												val:        "false",
+												ignoreCase: false,
+												want:       "\"false\"",
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+					&actionExpr{
+						pos: position{line: 299, col: 5, offset: 9277},
+						run: (*parser).callonSpanSearchArg39,
+						expr: &seqExpr{
+							pos: position{line: 299, col: 5, offset: 9277},
+							exprs: []any{
+								&litMatcher{
+									pos:        position{line: 299, col: 5, offset: 9277},
+									val:        "return",
+									ignoreCase: false,
+									want:       "\"return\"",
+								},
+								&ruleRefExpr{
+									pos:  position{line: 299, col: 14, offset: 9286},
+									name: "EQUAL",
+								},
+								&labeledExpr{
+									pos:   position{line: 299, col: 20, offset: 9292},
+									label: "returnType",
+									expr: &choiceExpr{
+										pos: position{line: 299, col: 32, offset: 9304},
+										alternatives: []any{
+											&litMatcher{
+												pos:        position{line: 299, col: 32, offset: 9304},
+												val:        "spans",
+												ignoreCase: false,
+												want:       "\"spans\"",
+											},
+											&litMatcher{
+												pos:        position{line: 299, col: 42, offset: 9314},
+												val:        "traces",
+												ignoreCase: false,
+												want:       "\"traces\"",
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+					&actionExpr{
+						pos: position{line: 302, col: 5, offset: 9487},
+						run: (*parser).callonSpanSearchArg47,
+						expr: &seqExpr{
+							pos: position{line: 302, col: 5, offset: 9487},
+							exprs: []any{
+								&litMatcher{
+									pos:        position{line: 302, col: 5, offset: 9487},
+									val:        "maxtraces",
+									ignoreCase: false,
+									want:       "\"maxtraces\"",
+								},
+								&ruleRefExpr{
+									pos:  position{line: 302, col: 17, offset: 9499},
+									name: "EQUAL",
+								},
+								&labeledExpr{
+									pos:   position{line: 302, col: 23, offset: 9505},
+									label: "intAsStr",
+									expr: &ruleRefExpr{
+										pos:  position{line: 302, col: 32, offset: 9514},
+										name: "IntegerAsString",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "SpanSearchDuration",
+			pos:  position{line: 311, col: 1, offset: 9940},
+			expr: &actionExpr{
+				pos: position{line: 311, col: 23, offset: 9962},
+				run: (*parser).callonSpanSearchDuration1,
+				expr: &seqExpr{
+					pos: position{line: 311, col: 23, offset: 9962},
+					exprs: []any{
+						&oneOrMoreExpr{
+							pos: position{line: 311, col: 23, offset: 9962},
+							expr: &charClassMatcher{
+								pos:        position{line: 311, col: 23, offset: 9962},
+								val:        "[0-9.]",
+								chars:      []rune{'.'},
+								ranges:     []rune{'0', '9'},
+								ignoreCase: false,
+								inverted:   false,
+							},
+						},
+						&oneOrMoreExpr{
+							pos: position{line: 311, col: 31, offset: 9970},
+							expr: &charClassMatcher{
+								pos:        position{line: 311, col: 31, offset: 9970},
+								val:        "[a-zµ]",
+								chars:      []rune{'µ'},
+								ranges:     []rune{'a', 'z'},
+								ignoreCase: false,
+								inverted:   false,
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "SearchBlock",
-			pos:  position{line: 221, col: 1, offset: 6389},
+			pos:  position{line: 319, col: 1, offset: 10182},
 			expr: &actionExpr{
-				pos: position{line: 221, col: 16, offset: 6404},
+				pos: position{line: 319, col: 16, offset: 10197},
 				run: (*parser).callonSearchBlock1,
 				expr: &seqExpr{
-					pos: position{line: 221, col: 16, offset: 6404},
+					pos: position{line: 319, col: 16, offset: 10197},
 					exprs: []any{
 						&ruleRefExpr{
-							pos:  position{line: 221, col: 16, offset: 6404},
+							pos:  position{line: 319, col: 16, offset: 10197},
 							name: "CMD_SEARCH",
 						},
 						&labeledExpr{
-							pos:   position{line: 221, col: 27, offset: 6415},
+							pos:   position{line: 319, col: 27, offset: 10208},
 							label: "clause",
 							expr: &ruleRefExpr{
-								pos:  position{line: 221, col: 34, offset: 6422},
+								pos:  position{line: 319, col: 34, offset: 10215},
 								name: "ClauseLevel4",
 							},
 						},
@@ -273,29 +618,29 @@ var g = &grammar{
 		},
 		{
 			name: "FilterBlock",
-			pos:  position{line: 225, col: 1, offset: 6463},
+			pos:  position{line: 323, col: 1, offset: 10256},
 			expr: &actionExpr{
-				pos: position{line: 225, col: 16, offset: 6478},
+				pos: position{line: 323, col: 16, offset: 10271},
 				run: (*parser).callonFilterBlock1,
 				expr: &seqExpr{
-					pos: position{line: 225, col: 16, offset: 6478},
+					pos: position{line: 323, col: 16, offset: 10271},
 					exprs: []any{
 						&ruleRefExpr{
-							pos:  position{line: 225, col: 16, offset: 6478},
+							pos:  position{line: 323, col: 16, offset: 10271},
 							name: "PIPE",
 						},
 						&labeledExpr{
-							pos:   position{line: 225, col: 21, offset: 6483},
+							pos:   position{line: 323, col: 21, offset: 10276},
 							label: "block",
 							expr: &choiceExpr{
-								pos: position{line: 225, col: 28, offset: 6490},
+								pos: position{line: 323, col: 28, offset: 10283},
 								alternatives: []any{
 									&ruleRefExpr{
-										pos:  position{line: 225, col: 28, offset: 6490},
+										pos:  position{line: 323, col: 28, offset: 10283},
 										name: "SearchBlock",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 225, col: 42, offset: 6504},
+										pos:  position{line: 323, col: 42, offset: 10297},
 										name: "RegexBlock",
 									},
 								},
@@ -307,52 +652,60 @@ var g = &grammar{
 		},
 		{
 			name: "QueryAggergatorBlock",
-			pos:  position{line: 230, col: 1, offset: 6580},
+			pos:  position{line: 328, col: 1, offset: 10373},
 			expr: &actionExpr{
-				pos: position{line: 230, col: 25, offset: 6604},
+				pos: position{line: 328, col: 25, offset: 10397},
 				run: (*parser).callonQueryAggergatorBlock1,
 				expr: &labeledExpr{
-					pos:   position{line: 230, col: 25, offset: 6604},
+					pos:   position{line: 328, col: 25, offset: 10397},
 					label: "block",
 					expr: &choiceExpr{
-						pos: position{line: 230, col: 32, offset: 6611},
+						pos: position{line: 328, col: 32, offset: 10404},
 						alternatives: []any{
 							&ruleRefExpr{
-								pos:  position{line: 230, col: 32, offset: 6611},
+								pos:  position{line: 328, col: 32, offset: 10404},
 								name: "FieldSelectBlock",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 230, col: 51, offset: 6630},
+								pos:  position{line: 328, col: 51, offset: 10423},
 								name: "AggregatorBlock",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 230, col: 69, offset: 6648},
+								pos:  position{line: 328, col: 69, offset: 10441},
 								name: "EvalBlock",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 230, col: 81, offset: 6660},
+								pos:  position{line: 328, col: 81, offset: 10453},
 								name: "WhereBlock",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 230, col: 94, offset: 6673},
+								pos:  position{line: 328, col: 94, offset: 10466},
 								name: "HeadBlock",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 230, col: 106, offset: 6685},
+								pos:  position{line: 328, col: 106, offset: 10478},
 								name: "RexBlock",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 230, col: 117, offset: 6696},
+								pos:  position{line: 328, col: 117, offset: 10489},
 								name: "StatisticBlock",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 230, col: 134, offset: 6713},
+								pos:  position{line: 328, col: 134, offset: 10506},
 								name: "RenameBlock",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 230, col: 148, offset: 6727},
+								pos:  position{line: 328, col: 148, offset: 10520},
 								name: "TimechartBlock",
 							},
+							&ruleRefExpr{
+								pos:  position{line: 328, col: 165, offset: 10537},
+								name: "LookupBlock",
+							},
+							&ruleRefExpr{
+								pos:  position{line: 328, col: 179, offset: 10551},
+								name: "SimilarToBlock",
+							},
 						},
 					},
 				},
@@ -360,37 +713,37 @@ var g = &grammar{
 		},
 		{
 			name: "FieldSelectBlock",
-			pos:  position{line: 235, col: 1, offset: 6823},
+			pos:  position{line: 333, col: 1, offset: 10647},
 			expr: &actionExpr{
-				pos: position{line: 235, col: 21, offset: 6843},
+				pos: position{line: 333, col: 21, offset: 10667},
 				run: (*parser).callonFieldSelectBlock1,
 				expr: &seqExpr{
-					pos: position{line: 235, col: 21, offset: 6843},
+					pos: position{line: 333, col: 21, offset: 10667},
 					exprs: []any{
 						&ruleRefExpr{
-							pos:  position{line: 235, col: 21, offset: 6843},
+							pos:  position{line: 333, col: 21, offset: 10667},
 							name: "PIPE",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 235, col: 26, offset: 6848},
+							pos:  position{line: 333, col: 26, offset: 10672},
 							name: "CMD_FIELDS",
 						},
 						&labeledExpr{
-							pos:   position{line: 235, col: 37, offset: 6859},
+							pos:   position{line: 333, col: 37, offset: 10683},
 							label: "op",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 235, col: 40, offset: 6862},
+								pos: position{line: 333, col: 40, offset: 10686},
 								expr: &choiceExpr{
-									pos: position{line: 235, col: 41, offset: 6863},
+									pos: position{line: 333, col: 41, offset: 10687},
 									alternatives: []any{
 										&litMatcher{
-											pos:        position{line: 235, col: 41, offset: 6863},
+											pos:        position{line: 333, col: 41, offset: 10687},
 											val:        "-",
 											ignoreCase: false,
 											want:       "\"-\"",
 										},
 										&litMatcher{
-											pos:        position{line: 235, col: 47, offset: 6869},
+											pos:        position{line: 333, col: 47, offset: 10693},
 											val:        "+",
 											ignoreCase: false,
 											want:       "\"+\"",
@@ -400,14 +753,14 @@ var g = &grammar{
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 235, col: 53, offset: 6875},
+							pos:  position{line: 333, col: 53, offset: 10699},
 							name: "EMPTY_OR_SPACE",
 						},
 						&labeledExpr{
-							pos:   position{line: 235, col: 68, offset: 6890},
+							pos:   position{line: 333, col: 68, offset: 10714},
 							label: "fields",
 							expr: &ruleRefExpr{
-								pos:  position{line: 235, col: 75, offset: 6897},
+								pos:  position{line: 333, col: 75, offset: 10721},
 								name: "FieldNameList",
 							},
 						},
@@ -417,36 +770,36 @@ var g = &grammar{
 		},
 		{
 			name: "AggregatorBlock",
-			pos:  position{line: 253, col: 1, offset: 7401},
+			pos:  position{line: 351, col: 1, offset: 11225},
 			expr: &actionExpr{
-				pos: position{line: 253, col: 20, offset: 7420},
+				pos: position{line: 351, col: 20, offset: 11244},
 				run: (*parser).callonAggregatorBlock1,
 				expr: &seqExpr{
-					pos: position{line: 253, col: 20, offset: 7420},
+					pos: position{line: 351, col: 20, offset: 11244},
 					exprs: []any{
 						&ruleRefExpr{
-							pos:  position{line: 253, col: 20, offset: 7420},
+							pos:  position{line: 351, col: 20, offset: 11244},
 							name: "PIPE",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 253, col: 25, offset: 7425},
+							pos:  position{line: 351, col: 25, offset: 11249},
 							name: "CMD_STATS",
 						},
 						&labeledExpr{
-							pos:   position{line: 253, col: 35, offset: 7435},
+							pos:   position{line: 351, col: 35, offset: 11259},
 							label: "aggs",
 							expr: &ruleRefExpr{
-								pos:  position{line: 253, col: 40, offset: 7440},
+								pos:  position{line: 351, col: 40, offset: 11264},
 								name: "AggregationList",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 253, col: 56, offset: 7456},
+							pos:   position{line: 351, col: 56, offset: 11280},
 							label: "byFields",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 253, col: 65, offset: 7465},
+								pos: position{line: 351, col: 65, offset: 11289},
 								expr: &ruleRefExpr{
-									pos:  position{line: 253, col: 66, offset: 7466},
+									pos:  position{line: 351, col: 66, offset: 11290},
 									name: "GroupbyBlock",
 								},
 							},
@@ -457,22 +810,22 @@ var g = &grammar{
 		},
 		{
 			name: "GroupbyBlock",
-			pos:  position{line: 298, col: 1, offset: 8960},
+			pos:  position{line: 396, col: 1, offset: 12784},
 			expr: &actionExpr{
-				pos: position{line: 298, col: 17, offset: 8976},
+				pos: position{line: 396, col: 17, offset: 12800},
 				run: (*parser).callonGroupbyBlock1,
 				expr: &seqExpr{
-					pos: position{line: 298, col: 17, offset: 8976},
+					pos: position{line: 396, col: 17, offset: 12800},
 					exprs: []any{
 						&ruleRefExpr{
-							pos:  position{line: 298, col: 17, offset: 8976},
+							pos:  position{line: 396, col: 17, offset: 12800},
 							name: "BY",
 						},
 						&labeledExpr{
-							pos:   position{line: 298, col: 20, offset: 8979},
+							pos:   position{line: 396, col: 20, offset: 12803},
 							label: "fields",
 							expr: &ruleRefExpr{
-								pos:  position{line: 298, col: 27, offset: 8986},
+								pos:  position{line: 396, col: 27, offset: 12810},
 								name: "FieldNameList",
 							},
 						},
@@ -482,31 +835,31 @@ var g = &grammar{
 		},
 		{
 			name: "RegexBlock",
-			pos:  position{line: 309, col: 1, offset: 9335},
+			pos:  position{line: 407, col: 1, offset: 13159},
 			expr: &actionExpr{
-				pos: position{line: 309, col: 15, offset: 9349},
+				pos: position{line: 407, col: 15, offset: 13173},
 				run: (*parser).callonRegexBlock1,
 				expr: &seqExpr{
-					pos: position{line: 309, col: 15, offset: 9349},
+					pos: position{line: 407, col: 15, offset: 13173},
 					exprs: []any{
 						&ruleRefExpr{
-							pos:  position{line: 309, col: 15, offset: 9349},
+							pos:  position{line: 407, col: 15, offset: 13173},
 							name: "CMD_REGEX",
 						},
 						&labeledExpr{
-							pos:   position{line: 309, col: 25, offset: 9359},
+							pos:   position{line: 407, col: 25, offset: 13183},
 							label: "keyAndOp",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 309, col: 34, offset: 9368},
+								pos: position{line: 407, col: 34, offset: 13192},
 								expr: &seqExpr{
-									pos: position{line: 309, col: 35, offset: 9369},
+									pos: position{line: 407, col: 35, offset: 13193},
 									exprs: []any{
 										&ruleRefExpr{
-											pos:  position{line: 309, col: 35, offset: 9369},
+											pos:  position{line: 407, col: 35, offset: 13193},
 											name: "FieldName",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 309, col: 45, offset: 9379},
+											pos:  position{line: 407, col: 45, offset: 13203},
 											name: "EqualityOperator",
 										},
 									},
@@ -514,10 +867,10 @@ var g = &grammar{
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 309, col: 64, offset: 9398},
+							pos:   position{line: 407, col: 64, offset: 13222},
 							label: "str",
 							expr: &ruleRefExpr{
-								pos:  position{line: 309, col: 68, offset: 9402},
+								pos:  position{line: 407, col: 68, offset: 13226},
 								name: "QuotedString",
 							},
 						},
@@ -527,44 +880,44 @@ var g = &grammar{
 		},
 		{
 			name: "ClauseLevel4",
-			pos:  position{line: 337, col: 1, offset: 9981},
+			pos:  position{line: 435, col: 1, offset: 13805},
 			expr: &actionExpr{
-				pos: position{line: 337, col: 17, offset: 9997},
+				pos: position{line: 435, col: 17, offset: 13821},
 				run: (*parser).callonClauseLevel41,
 				expr: &seqExpr{
-					pos: position{line: 337, col: 17, offset: 9997},
+					pos: position{line: 435, col: 17, offset: 13821},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 337, col: 17, offset: 9997},
+							pos:   position{line: 435, col: 17, offset: 13821},
 							label: "first",
 							expr: &ruleRefExpr{
-								pos:  position{line: 337, col: 23, offset: 10003},
+								pos:  position{line: 435, col: 23, offset: 13827},
 								name: "ClauseLevel3",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 337, col: 36, offset: 10016},
+							pos:   position{line: 435, col: 36, offset: 13840},
 							label: "rest",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 337, col: 41, offset: 10021},
+								pos: position{line: 435, col: 41, offset: 13845},
 								expr: &seqExpr{
-									pos: position{line: 337, col: 42, offset: 10022},
+									pos: position{line: 435, col: 42, offset: 13846},
 									exprs: []any{
 										&choiceExpr{
-											pos: position{line: 337, col: 43, offset: 10023},
+											pos: position{line: 435, col: 43, offset: 13847},
 											alternatives: []any{
 												&ruleRefExpr{
-													pos:  position{line: 337, col: 43, offset: 10023},
+													pos:  position{line: 435, col: 43, offset: 13847},
 													name: "AND",
 												},
 												&ruleRefExpr{
-													pos:  position{line: 337, col: 49, offset: 10029},
+													pos:  position{line: 435, col: 49, offset: 13853},
 													name: "SPACE",
 												},
 											},
 										},
 										&ruleRefExpr{
-											pos:  position{line: 337, col: 56, offset: 10036},
+											pos:  position{line: 435, col: 56, offset: 13860},
 											name: "ClauseLevel3",
 										},
 									},
@@ -577,35 +930,35 @@ var g = &grammar{
 		},
 		{
 			name: "ClauseLevel3",
-			pos:  position{line: 355, col: 1, offset: 10413},
+			pos:  position{line: 453, col: 1, offset: 14237},
 			expr: &actionExpr{
-				pos: position{line: 355, col: 17, offset: 10429},
+				pos: position{line: 453, col: 17, offset: 14253},
 				run: (*parser).callonClauseLevel31,
 				expr: &seqExpr{
-					pos: position{line: 355, col: 17, offset: 10429},
+					pos: position{line: 453, col: 17, offset: 14253},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 355, col: 17, offset: 10429},
+							pos:   position{line: 453, col: 17, offset: 14253},
 							label: "first",
 							expr: &ruleRefExpr{
-								pos:  position{line: 355, col: 23, offset: 10435},
+								pos:  position{line: 453, col: 23, offset: 14259},
 								name: "ClauseLevel2",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 355, col: 36, offset: 10448},
+							pos:   position{line: 453, col: 36, offset: 14272},
 							label: "rest",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 355, col: 41, offset: 10453},
+								pos: position{line: 453, col: 41, offset: 14277},
 								expr: &seqExpr{
-									pos: position{line: 355, col: 42, offset: 10454},
+									pos: position{line: 453, col: 42, offset: 14278},
 									exprs: []any{
 										&ruleRefExpr{
-											pos:  position{line: 355, col: 42, offset: 10454},
+											pos:  position{line: 453, col: 42, offset: 14278},
 											name: "OR",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 355, col: 45, offset: 10457},
+											pos:  position{line: 453, col: 45, offset: 14281},
 											name: "ClauseLevel2",
 										},
 									},
@@ -618,32 +971,32 @@ var g = &grammar{
 		},
 		{
 			name: "ClauseLevel2",
-			pos:  position{line: 373, col: 1, offset: 10822},
+			pos:  position{line: 471, col: 1, offset: 14646},
 			expr: &choiceExpr{
-				pos: position{line: 373, col: 17, offset: 10838},
+				pos: position{line: 471, col: 17, offset: 14662},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 373, col: 17, offset: 10838},
+						pos: position{line: 471, col: 17, offset: 14662},
 						run: (*parser).callonClauseLevel22,
 						expr: &seqExpr{
-							pos: position{line: 373, col: 17, offset: 10838},
+							pos: position{line: 471, col: 17, offset: 14662},
 							exprs: []any{
 								&labeledExpr{
-									pos:   position{line: 373, col: 17, offset: 10838},
+									pos:   position{line: 471, col: 17, offset: 14662},
 									label: "notList",
 									expr: &oneOrMoreExpr{
-										pos: position{line: 373, col: 25, offset: 10846},
+										pos: position{line: 471, col: 25, offset: 14670},
 										expr: &ruleRefExpr{
-											pos:  position{line: 373, col: 25, offset: 10846},
+											pos:  position{line: 471, col: 25, offset: 14670},
 											name: "NOT",
 										},
 									},
 								},
 								&labeledExpr{
-									pos:   position{line: 373, col: 30, offset: 10851},
+									pos:   position{line: 471, col: 30, offset: 14675},
 									label: "first",
 									expr: &ruleRefExpr{
-										pos:  position{line: 373, col: 36, offset: 10857},
+										pos:  position{line: 471, col: 36, offset: 14681},
 										name: "ClauseLevel1",
 									},
 								},
@@ -651,13 +1004,13 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 384, col: 5, offset: 11153},
+						pos: position{line: 482, col: 5, offset: 14977},
 						run: (*parser).callonClauseLevel29,
 						expr: &labeledExpr{
-							pos:   position{line: 384, col: 5, offset: 11153},
+							pos:   position{line: 482, col: 5, offset: 14977},
 							label: "clause",
 							expr: &ruleRefExpr{
-								pos:  position{line: 384, col: 12, offset: 11160},
+								pos:  position{line: 482, col: 12, offset: 14984},
 								name: "ClauseLevel1",
 							},
 						},
@@ -667,43 +1020,43 @@ var g = &grammar{
 		},
 		{
 			name: "ClauseLevel1",
-			pos:  position{line: 388, col: 1, offset: 11201},
+			pos:  position{line: 486, col: 1, offset: 15025},
 			expr: &choiceExpr{
-				pos: position{line: 388, col: 17, offset: 11217},
+				pos: position{line: 486, col: 17, offset: 15041},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 388, col: 17, offset: 11217},
+						pos: position{line: 486, col: 17, offset: 15041},
 						run: (*parser).callonClauseLevel12,
 						expr: &seqExpr{
-							pos: position{line: 388, col: 17, offset: 11217},
+							pos: position{line: 486, col: 17, offset: 15041},
 							exprs: []any{
 								&ruleRefExpr{
-									pos:  position{line: 388, col: 17, offset: 11217},
+									pos:  position{line: 486, col: 17, offset: 15041},
 									name: "L_PAREN",
 								},
 								&labeledExpr{
-									pos:   position{line: 388, col: 25, offset: 11225},
+									pos:   position{line: 486, col: 25, offset: 15049},
 									label: "clause",
 									expr: &ruleRefExpr{
-										pos:  position{line: 388, col: 32, offset: 11232},
+										pos:  position{line: 486, col: 32, offset: 15056},
 										name: "ClauseLevel4",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 388, col: 45, offset: 11245},
+									pos:  position{line: 486, col: 45, offset: 15069},
 									name: "R_PAREN",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 390, col: 5, offset: 11282},
+						pos: position{line: 488, col: 5, offset: 15106},
 						run: (*parser).callonClauseLevel18,
 						expr: &labeledExpr{
-							pos:   position{line: 390, col: 5, offset: 11282},
+							pos:   position{line: 488, col: 5, offset: 15106},
 							label: "term",
 							expr: &ruleRefExpr{
-								pos:  position{line: 390, col: 10, offset: 11287},
+								pos:  position{line: 488, col: 10, offset: 15111},
 								name: "SearchTerm",
 							},
 						},
@@ -713,22 +1066,22 @@ var g = &grammar{
 		},
 		{
 			name: "SearchTerm",
-			pos:  position{line: 396, col: 1, offset: 11445},
+			pos:  position{line: 494, col: 1, offset: 15269},
 			expr: &actionExpr{
-				pos: position{line: 396, col: 15, offset: 11459},
+				pos: position{line: 494, col: 15, offset: 15283},
 				run: (*parser).callonSearchTerm1,
 				expr: &labeledExpr{
-					pos:   position{line: 396, col: 15, offset: 11459},
+					pos:   position{line: 494, col: 15, offset: 15283},
 					label: "term",
 					expr: &choiceExpr{
-						pos: position{line: 396, col: 21, offset: 11465},
+						pos: position{line: 494, col: 21, offset: 15289},
 						alternatives: []any{
 							&ruleRefExpr{
-								pos:  position{line: 396, col: 21, offset: 11465},
+								pos:  position{line: 494, col: 21, offset: 15289},
 								name: "FieldWithNumberValue",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 396, col: 44, offset: 11488},
+								pos:  position{line: 494, col: 44, offset: 15312},
 								name: "FieldWithStringValue",
 							},
 						},
@@ -738,47 +1091,47 @@ var g = &grammar{
 		},
 		{
 			name: "TimechartBlock",
-			pos:  position{line: 401, col: 1, offset: 11629},
+			pos:  position{line: 499, col: 1, offset: 15453},
 			expr: &actionExpr{
-				pos: position{line: 401, col: 19, offset: 11647},
+				pos: position{line: 499, col: 19, offset: 15471},
 				run: (*parser).callonTimechartBlock1,
 				expr: &seqExpr{
-					pos: position{line: 401, col: 19, offset: 11647},
+					pos: position{line: 499, col: 19, offset: 15471},
 					exprs: []any{
 						&ruleRefExpr{
-							pos:  position{line: 401, col: 19, offset: 11647},
+							pos:  position{line: 499, col: 19, offset: 15471},
 							name: "PIPE",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 401, col: 24, offset: 11652},
+							pos:  position{line: 499, col: 24, offset: 15476},
 							name: "CMD_TIMECHART",
 						},
 						&labeledExpr{
-							pos:   position{line: 401, col: 38, offset: 11666},
+							pos:   position{line: 499, col: 38, offset: 15490},
 							label: "binOptions",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 401, col: 49, offset: 11677},
+								pos: position{line: 499, col: 49, offset: 15501},
 								expr: &ruleRefExpr{
-									pos:  position{line: 401, col: 50, offset: 11678},
+									pos:  position{line: 499, col: 50, offset: 15502},
 									name: "BinOptions",
 								},
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 401, col: 63, offset: 11691},
+							pos:   position{line: 499, col: 63, offset: 15515},
 							label: "expr",
 							expr: &ruleRefExpr{
-								pos:  position{line: 401, col: 69, offset: 11697},
+								pos:  position{line: 499, col: 69, offset: 15521},
 								name: "SingleAggExpr",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 401, col: 84, offset: 11712},
+							pos:   position{line: 499, col: 84, offset: 15536},
 							label: "limitExpr",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 401, col: 94, offset: 11722},
+								pos: position{line: 499, col: 94, offset: 15546},
 								expr: &ruleRefExpr{
-									pos:  position{line: 401, col: 95, offset: 11723},
+									pos:  position{line: 499, col: 95, offset: 15547},
 									name: "LimitExpr",
 								},
 							},
@@ -789,28 +1142,28 @@ var g = &grammar{
 		},
 		{
 			name: "SingleAggExpr",
-			pos:  position{line: 462, col: 1, offset: 13764},
+			pos:  position{line: 560, col: 1, offset: 17588},
 			expr: &actionExpr{
-				pos: position{line: 462, col: 18, offset: 13781},
+				pos: position{line: 560, col: 18, offset: 17605},
 				run: (*parser).callonSingleAggExpr1,
 				expr: &seqExpr{
-					pos: position{line: 462, col: 18, offset: 13781},
+					pos: position{line: 560, col: 18, offset: 17605},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 462, col: 18, offset: 13781},
+							pos:   position{line: 560, col: 18, offset: 17605},
 							label: "aggs",
 							expr: &ruleRefExpr{
-								pos:  position{line: 462, col: 23, offset: 13786},
+								pos:  position{line: 560, col: 23, offset: 17610},
 								name: "AggregationList",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 462, col: 39, offset: 13802},
+							pos:   position{line: 560, col: 39, offset: 17626},
 							label: "splitByClause",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 462, col: 53, offset: 13816},
+								pos: position{line: 560, col: 53, offset: 17640},
 								expr: &ruleRefExpr{
-									pos:  position{line: 462, col: 54, offset: 13817},
+									pos:  position{line: 560, col: 54, offset: 17641},
 									name: "SplitByClause",
 								},
 							},
@@ -821,32 +1174,32 @@ var g = &grammar{
 		},
 		{
 			name: "SplitByClause",
-			pos:  position{line: 476, col: 1, offset: 14157},
+			pos:  position{line: 574, col: 1, offset: 17981},
 			expr: &actionExpr{
-				pos: position{line: 476, col: 18, offset: 14174},
+				pos: position{line: 574, col: 18, offset: 17998},
 				run: (*parser).callonSplitByClause1,
 				expr: &seqExpr{
-					pos: position{line: 476, col: 18, offset: 14174},
+					pos: position{line: 574, col: 18, offset: 17998},
 					exprs: []any{
 						&ruleRefExpr{
-							pos:  position{line: 476, col: 18, offset: 14174},
+							pos:  position{line: 574, col: 18, offset: 17998},
 							name: "BY",
 						},
 						&labeledExpr{
-							pos:   position{line: 476, col: 21, offset: 14177},
+							pos:   position{line: 574, col: 21, offset: 18001},
 							label: "field",
 							expr: &ruleRefExpr{
-								pos:  position{line: 476, col: 27, offset: 14183},
+								pos:  position{line: 574, col: 27, offset: 18007},
 								name: "FieldName",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 476, col: 37, offset: 14193},
+							pos:   position{line: 574, col: 37, offset: 18017},
 							label: "tcOptions",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 476, col: 47, offset: 14203},
+								pos: position{line: 574, col: 47, offset: 18027},
 								expr: &ruleRefExpr{
-									pos:  position{line: 476, col: 48, offset: 14204},
+									pos:  position{line: 574, col: 48, offset: 18028},
 									name: "TcOptions",
 								},
 							},
@@ -857,31 +1210,31 @@ var g = &grammar{
 		},
 		{
 			name: "TcOptions",
-			pos:  position{line: 487, col: 1, offset: 14432},
+			pos:  position{line: 585, col: 1, offset: 18256},
 			expr: &actionExpr{
-				pos: position{line: 487, col: 14, offset: 14445},
+				pos: position{line: 585, col: 14, offset: 18269},
 				run: (*parser).callonTcOptions1,
 				expr: &seqExpr{
-					pos: position{line: 487, col: 14, offset: 14445},
+					pos: position{line: 585, col: 14, offset: 18269},
 					exprs: []any{
 						&ruleRefExpr{
-							pos:  position{line: 487, col: 14, offset: 14445},
+							pos:  position{line: 585, col: 14, offset: 18269},
 							name: "SPACE",
 						},
 						&labeledExpr{
-							pos:   position{line: 487, col: 20, offset: 14451},
+							pos:   position{line: 585, col: 20, offset: 18275},
 							label: "option",
 							expr: &choiceExpr{
-								pos: position{line: 487, col: 28, offset: 14459},
+								pos: position{line: 585, col: 28, offset: 18283},
 								alternatives: []any{
 									&ruleRefExpr{
-										pos:  position{line: 487, col: 28, offset: 14459},
+										pos:  position{line: 585, col: 28, offset: 18283},
 										name: "BinOptions",
 									},
 									&oneOrMoreExpr{
-										pos: position{line: 487, col: 41, offset: 14472},
+										pos: position{line: 585, col: 41, offset: 18296},
 										expr: &ruleRefExpr{
-											pos:  position{line: 487, col: 42, offset: 14473},
+											pos:  position{line: 585, col: 42, offset: 18297},
 											name: "TcOption",
 										},
 									},
@@ -894,34 +1247,34 @@ var g = &grammar{
 		},
 		{
 			name: "TcOption",
-			pos:  position{line: 530, col: 1, offset: 16008},
+			pos:  position{line: 628, col: 1, offset: 19832},
 			expr: &actionExpr{
-				pos: position{line: 530, col: 13, offset: 16020},
+				pos: position{line: 628, col: 13, offset: 19844},
 				run: (*parser).callonTcOption1,
 				expr: &seqExpr{
-					pos: position{line: 530, col: 13, offset: 16020},
+					pos: position{line: 628, col: 13, offset: 19844},
 					exprs: []any{
 						&ruleRefExpr{
-							pos:  position{line: 530, col: 13, offset: 16020},
+							pos:  position{line: 628, col: 13, offset: 19844},
 							name: "SPACE",
 						},
 						&labeledExpr{
-							pos:   position{line: 530, col: 19, offset: 16026},
+							pos:   position{line: 628, col: 19, offset: 19850},
 							label: "tcOptionCMD",
 							expr: &ruleRefExpr{
-								pos:  position{line: 530, col: 31, offset: 16038},
+								pos:  position{line: 628, col: 31, offset: 19862},
 								name: "TcOptionCMD",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 530, col: 43, offset: 16050},
+							pos:  position{line: 628, col: 43, offset: 19874},
 							name: "EQUAL",
 						},
 						&labeledExpr{
-							pos:   position{line: 530, col: 49, offset: 16056},
+							pos:   position{line: 628, col: 49, offset: 19880},
 							label: "val",
 							expr: &ruleRefExpr{
-								pos:  position{line: 530, col: 53, offset: 16060},
+								pos:  position{line: 628, col: 53, offset: 19884},
 								name: "EvalFieldToRead",
 							},
 						},
@@ -931,36 +1284,36 @@ var g = &grammar{
 		},
 		{
 			name: "TcOptionCMD",
-			pos:  position{line: 535, col: 1, offset: 16173},
+			pos:  position{line: 633, col: 1, offset: 19997},
 			expr: &actionExpr{
-				pos: position{line: 535, col: 16, offset: 16188},
+				pos: position{line: 633, col: 16, offset: 20012},
 				run: (*parser).callonTcOptionCMD1,
 				expr: &labeledExpr{
-					pos:   position{line: 535, col: 16, offset: 16188},
+					pos:   position{line: 633, col: 16, offset: 20012},
 					label: "option",
 					expr: &choiceExpr{
-						pos: position{line: 535, col: 24, offset: 16196},
+						pos: position{line: 633, col: 24, offset: 20020},
 						alternatives: []any{
 							&litMatcher{
-								pos:        position{line: 535, col: 24, offset: 16196},
+								pos:        position{line: 633, col: 24, offset: 20020},
 								val:        "usenull",
 								ignoreCase: false,
 								want:       "\"usenull\"",
 							},
 							&litMatcher{
-								pos:        position{line: 535, col: 36, offset: 16208},
+								pos:        position{line: 633, col: 36, offset: 20032},
 								val:        "useother",
 								ignoreCase: false,
 								want:       "\"useother\"",
 							},
 							&litMatcher{
-								pos:        position{line: 535, col: 49, offset: 16221},
+								pos:        position{line: 633, col: 49, offset: 20045},
 								val:        "nullstr",
 								ignoreCase: false,
 								want:       "\"nullstr\"",
 							},
 							&litMatcher{
-								pos:        position{line: 535, col: 61, offset: 16233},
+								pos:        position{line: 633, col: 61, offset: 20057},
 								val:        "otherstr",
 								ignoreCase: false,
 								want:       "\"otherstr\"",
@@ -972,15 +1325,15 @@ var g = &grammar{
 		},
 		{
 			name: "BinOptions",
-			pos:  position{line: 544, col: 1, offset: 16582},
+			pos:  position{line: 642, col: 1, offset: 20406},
 			expr: &actionExpr{
-				pos: position{line: 544, col: 15, offset: 16596},
+				pos: position{line: 642, col: 15, offset: 20420},
 				run: (*parser).callonBinOptions1,
 				expr: &labeledExpr{
-					pos:   position{line: 544, col: 15, offset: 16596},
+					pos:   position{line: 642, col: 15, offset: 20420},
 					label: "spanOptions",
 					expr: &ruleRefExpr{
-						pos:  position{line: 544, col: 27, offset: 16608},
+						pos:  position{line: 642, col: 27, offset: 20432},
 						name: "SpanOptions",
 					},
 				},
@@ -988,26 +1341,26 @@ var g = &grammar{
 		},
 		{
 			name: "SpanOptions",
-			pos:  position{line: 552, col: 1, offset: 16833},
+			pos:  position{line: 650, col: 1, offset: 20657},
 			expr: &actionExpr{
-				pos: position{line: 552, col: 16, offset: 16848},
+				pos: position{line: 650, col: 16, offset: 20672},
 				run: (*parser).callonSpanOptions1,
 				expr: &seqExpr{
-					pos: position{line: 552, col: 16, offset: 16848},
+					pos: position{line: 650, col: 16, offset: 20672},
 					exprs: []any{
 						&ruleRefExpr{
-							pos:  position{line: 552, col: 16, offset: 16848},
+							pos:  position{line: 650, col: 16, offset: 20672},
 							name: "CMD_SPAN",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 552, col: 25, offset: 16857},
+							pos:  position{line: 650, col: 25, offset: 20681},
 							name: "EQUAL",
 						},
 						&labeledExpr{
-							pos:   position{line: 552, col: 31, offset: 16863},
+							pos:   position{line: 650, col: 31, offset: 20687},
 							label: "spanLength",
 							expr: &ruleRefExpr{
-								pos:  position{line: 552, col: 42, offset: 16874},
+								pos:  position{line: 650, col: 42, offset: 20698},
 								name: "SpanLength",
 							},
 						},
@@ -1017,31 +1370,31 @@ var g = &grammar{
 		},
 		{
 			name: "SpanLength",
-			pos:  position{line: 559, col: 1, offset: 17020},
+			pos:  position{line: 657, col: 1, offset: 20844},
 			expr: &actionExpr{
-				pos: position{line: 559, col: 15, offset: 17034},
+				pos: position{line: 657, col: 15, offset: 20858},
 				run: (*parser).callonSpanLength1,
 				expr: &seqExpr{
-					pos: position{line: 559, col: 15, offset: 17034},
+					pos: position{line: 657, col: 15, offset: 20858},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 559, col: 15, offset: 17034},
+							pos:   position{line: 657, col: 15, offset: 20858},
 							label: "intAsStr",
 							expr: &ruleRefExpr{
-								pos:  position{line: 559, col: 24, offset: 17043},
+								pos:  position{line: 657, col: 24, offset: 20867},
 								name: "IntegerAsString",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 559, col: 40, offset: 17059},
+							pos:   position{line: 657, col: 40, offset: 20883},
 							label: "timeScale",
 							expr: &ruleRefExpr{
-								pos:  position{line: 559, col: 50, offset: 17069},
+								pos:  position{line: 657, col: 50, offset: 20893},
 								name: "TimeScale",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 559, col: 60, offset: 17079},
+							pos:  position{line: 657, col: 60, offset: 20903},
 							name: "SPACE",
 						},
 					},
@@ -1050,46 +1403,46 @@ var g = &grammar{
 		},
 		{
 			name: "TimeScale",
-			pos:  position{line: 572, col: 1, offset: 17393},
+			pos:  position{line: 670, col: 1, offset: 21217},
 			expr: &actionExpr{
-				pos: position{line: 572, col: 14, offset: 17406},
+				pos: position{line: 670, col: 14, offset: 21230},
 				run: (*parser).callonTimeScale1,
 				expr: &labeledExpr{
-					pos:   position{line: 572, col: 14, offset: 17406},
+					pos:   position{line: 670, col: 14, offset: 21230},
 					label: "timeUnit",
 					expr: &choiceExpr{
-						pos: position{line: 572, col: 24, offset: 17416},
+						pos: position{line: 670, col: 24, offset: 21240},
 						alternatives: []any{
 							&ruleRefExpr{
-								pos:  position{line: 572, col: 24, offset: 17416},
+								pos:  position{line: 670, col: 24, offset: 21240},
 								name: "Second",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 572, col: 33, offset: 17425},
+								pos:  position{line: 670, col: 33, offset: 21249},
 								name: "Minute",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 572, col: 42, offset: 17434},
+								pos:  position{line: 670, col: 42, offset: 21258},
 								name: "Hour",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 572, col: 49, offset: 17441},
+								pos:  position{line: 670, col: 49, offset: 21265},
 								name: "Day",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 572, col: 54, offset: 17446},
+								pos:  position{line: 670, col: 54, offset: 21270},
 								name: "Week",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 572, col: 61, offset: 17453},
+								pos:  position{line: 670, col: 61, offset: 21277},
 								name: "Month",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 572, col: 69, offset: 17461},
+								pos:  position{line: 670, col: 69, offset: 21285},
 								name: "Quarter",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 572, col: 78, offset: 17470},
+								pos:  position{line: 670, col: 78, offset: 21294},
 								name: "Subseconds",
 							},
 						},
@@ -1099,43 +1452,43 @@ var g = &grammar{
 		},
 		{
 			name: "LimitExpr",
-			pos:  position{line: 577, col: 1, offset: 17592},
+			pos:  position{line: 675, col: 1, offset: 21416},
 			expr: &actionExpr{
-				pos: position{line: 577, col: 14, offset: 17605},
+				pos: position{line: 675, col: 14, offset: 21429},
 				run: (*parser).callonLimitExpr1,
 				expr: &seqExpr{
-					pos: position{line: 577, col: 14, offset: 17605},
+					pos: position{line: 675, col: 14, offset: 21429},
 					exprs: []any{
 						&ruleRefExpr{
-							pos:  position{line: 577, col: 14, offset: 17605},
+							pos:  position{line: 675, col: 14, offset: 21429},
 							name: "SPACE",
 						},
 						&litMatcher{
-							pos:        position{line: 577, col: 20, offset: 17611},
+							pos:        position{line: 675, col: 20, offset: 21435},
 							val:        "limit",
 							ignoreCase: false,
 							want:       "\"limit\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 577, col: 28, offset: 17619},
+							pos:  position{line: 675, col: 28, offset: 21443},
 							name: "EQUAL",
 						},
 						&labeledExpr{
-							pos:   position{line: 577, col: 34, offset: 17625},
+							pos:   position{line: 675, col: 34, offset: 21449},
 							label: "sortBy",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 577, col: 41, offset: 17632},
+								pos: position{line: 675, col: 41, offset: 21456},
 								expr: &choiceExpr{
-									pos: position{line: 577, col: 42, offset: 17633},
+									pos: position{line: 675, col: 42, offset: 21457},
 									alternatives: []any{
 										&litMatcher{
-											pos:        position{line: 577, col: 42, offset: 17633},
+											pos:        position{line: 675, col: 42, offset: 21457},
 											val:        "top",
 											ignoreCase: false,
 											want:       "\"top\"",
 										},
 										&litMatcher{
-											pos:        position{line: 577, col: 50, offset: 17641},
+											pos:        position{line: 675, col: 50, offset: 21465},
 											val:        "bottom",
 											ignoreCase: false,
 											want:       "\"bottom\"",
@@ -1145,14 +1498,14 @@ var g = &grammar{
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 577, col: 61, offset: 17652},
+							pos:  position{line: 675, col: 61, offset: 21476},
 							name: "EMPTY_OR_SPACE",
 						},
 						&labeledExpr{
-							pos:   position{line: 577, col: 76, offset: 17667},
+							pos:   position{line: 675, col: 76, offset: 21491},
 							label: "intAsStr",
 							expr: &ruleRefExpr{
-								pos:  position{line: 577, col: 86, offset: 17677},
+								pos:  position{line: 675, col: 86, offset: 21501},
 								name: "IntegerAsString",
 							},
 						},
@@ -1162,22 +1515,22 @@ var g = &grammar{
 		},
 		{
 			name: "StatisticBlock",
-			pos:  position{line: 603, col: 1, offset: 18269},
+			pos:  position{line: 701, col: 1, offset: 22093},
 			expr: &actionExpr{
-				pos: position{line: 603, col: 19, offset: 18287},
+				pos: position{line: 701, col: 19, offset: 22111},
 				run: (*parser).callonStatisticBlock1,
 				expr: &seqExpr{
-					pos: position{line: 603, col: 19, offset: 18287},
+					pos: position{line: 701, col: 19, offset: 22111},
 					exprs: []any{
 						&ruleRefExpr{
-							pos:  position{line: 603, col: 19, offset: 18287},
+							pos:  position{line: 701, col: 19, offset: 22111},
 							name: "PIPE",
 						},
 						&labeledExpr{
-							pos:   position{line: 603, col: 24, offset: 18292},
+							pos:   position{line: 701, col: 24, offset: 22116},
 							label: "statisticExpr",
 							expr: &ruleRefExpr{
-								pos:  position{line: 603, col: 38, offset: 18306},
+								pos:  position{line: 701, col: 38, offset: 22130},
 								name: "StatisticExpr",
 							},
 						},
@@ -1187,76 +1540,76 @@ var g = &grammar{
 		},
 		{
 			name: "StatisticExpr",
-			pos:  position{line: 636, col: 1, offset: 19284},
+			pos:  position{line: 734, col: 1, offset: 23108},
 			expr: &actionExpr{
-				pos: position{line: 636, col: 18, offset: 19301},
+				pos: position{line: 734, col: 18, offset: 23125},
 				run: (*parser).callonStatisticExpr1,
 				expr: &seqExpr{
-					pos: position{line: 636, col: 18, offset: 19301},
+					pos: position{line: 734, col: 18, offset: 23125},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 636, col: 18, offset: 19301},
+							pos:   position{line: 734, col: 18, offset: 23125},
 							label: "cmd",
 							expr: &choiceExpr{
-								pos: position{line: 636, col: 23, offset: 19306},
+								pos: position{line: 734, col: 23, offset: 23130},
 								alternatives: []any{
 									&ruleRefExpr{
-										pos:  position{line: 636, col: 23, offset: 19306},
+										pos:  position{line: 734, col: 23, offset: 23130},
 										name: "CMD_TOP",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 636, col: 33, offset: 19316},
+										pos:  position{line: 734, col: 33, offset: 23140},
 										name: "CMD_RARE",
 									},
 								},
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 636, col: 43, offset: 19326},
+							pos:   position{line: 734, col: 43, offset: 23150},
 							label: "limit",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 636, col: 49, offset: 19332},
+								pos: position{line: 734, col: 49, offset: 23156},
 								expr: &ruleRefExpr{
-									pos:  position{line: 636, col: 50, offset: 19333},
+									pos:  position{line: 734, col: 50, offset: 23157},
 									name: "StatisticLimit",
 								},
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 636, col: 67, offset: 19350},
+							pos:   position{line: 734, col: 67, offset: 23174},
 							label: "fieldList",
 							expr: &seqExpr{
-								pos: position{line: 636, col: 78, offset: 19361},
+								pos: position{line: 734, col: 78, offset: 23185},
 								exprs: []any{
 									&ruleRefExpr{
-										pos:  position{line: 636, col: 78, offset: 19361},
+										pos:  position{line: 734, col: 78, offset: 23185},
 										name: "SPACE",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 636, col: 84, offset: 19367},
+										pos:  position{line: 734, col: 84, offset: 23191},
 										name: "FieldNameList",
 									},
 								},
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 636, col: 99, offset: 19382},
+							pos:   position{line: 734, col: 99, offset: 23206},
 							label: "byClause",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 636, col: 108, offset: 19391},
+								pos: position{line: 734, col: 108, offset: 23215},
 								expr: &ruleRefExpr{
-									pos:  position{line: 636, col: 109, offset: 19392},
+									pos:  position{line: 734, col: 109, offset: 23216},
 									name: "ByClause",
 								},
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 636, col: 120, offset: 19403},
+							pos:   position{line: 734, col: 120, offset: 23227},
 							label: "options",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 636, col: 128, offset: 19411},
+								pos: position{line: 734, col: 128, offset: 23235},
 								expr: &ruleRefExpr{
-									pos:  position{line: 636, col: 129, offset: 19412},
+									pos:  position{line: 734, col: 129, offset: 23236},
 									name: "Options",
 								},
 							},
@@ -1267,25 +1620,25 @@ var g = &grammar{
 		},
 		{
 			name: "StatisticLimit",
-			pos:  position{line: 678, col: 1, offset: 20452},
+			pos:  position{line: 776, col: 1, offset: 24276},
 			expr: &choiceExpr{
-				pos: position{line: 678, col: 19, offset: 20470},
+				pos: position{line: 776, col: 19, offset: 24294},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 678, col: 19, offset: 20470},
+						pos: position{line: 776, col: 19, offset: 24294},
 						run: (*parser).callonStatisticLimit2,
 						expr: &seqExpr{
-							pos: position{line: 678, col: 19, offset: 20470},
+							pos: position{line: 776, col: 19, offset: 24294},
 							exprs: []any{
 								&ruleRefExpr{
-									pos:  position{line: 678, col: 19, offset: 20470},
+									pos:  position{line: 776, col: 19, offset: 24294},
 									name: "SPACE",
 								},
 								&labeledExpr{
-									pos:   position{line: 678, col: 25, offset: 20476},
+									pos:   position{line: 776, col: 25, offset: 24300},
 									label: "number",
 									expr: &ruleRefExpr{
-										pos:  position{line: 678, col: 32, offset: 20483},
+										pos:  position{line: 776, col: 32, offset: 24307},
 										name: "IntegerAsString",
 									},
 								},
@@ -1293,30 +1646,30 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 681, col: 3, offset: 20537},
+						pos: position{line: 779, col: 3, offset: 24361},
 						run: (*parser).callonStatisticLimit7,
 						expr: &seqExpr{
-							pos: position{line: 681, col: 3, offset: 20537},
+							pos: position{line: 779, col: 3, offset: 24361},
 							exprs: []any{
 								&ruleRefExpr{
-									pos:  position{line: 681, col: 3, offset: 20537},
+									pos:  position{line: 779, col: 3, offset: 24361},
 									name: "SPACE",
 								},
 								&litMatcher{
-									pos:        position{line: 681, col: 9, offset: 20543},
+									pos:        position{line: 779, col: 9, offset: 24367},
 									val:        "limit",
 									ignoreCase: false,
 									want:       "\"limit\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 681, col: 17, offset: 20551},
+									pos:  position{line: 779, col: 17, offset: 24375},
 									name: "EQUAL",
 								},
 								&labeledExpr{
-									pos:   position{line: 681, col: 23, offset: 20557},
+									pos:   position{line: 779, col: 23, offset: 24381},
 									label: "limit",
 									expr: &ruleRefExpr{
-										pos:  position{line: 681, col: 30, offset: 20564},
+										pos:  position{line: 779, col: 30, offset: 24388},
 										name: "IntegerAsString",
 									},
 								},
@@ -1328,17 +1681,17 @@ var g = &grammar{
 		},
 		{
 			name: "Options",
-			pos:  position{line: 686, col: 1, offset: 20662},
+			pos:  position{line: 784, col: 1, offset: 24486},
 			expr: &actionExpr{
-				pos: position{line: 686, col: 12, offset: 20673},
+				pos: position{line: 784, col: 12, offset: 24497},
 				run: (*parser).callonOptions1,
 				expr: &labeledExpr{
-					pos:   position{line: 686, col: 12, offset: 20673},
+					pos:   position{line: 784, col: 12, offset: 24497},
 					label: "option",
 					expr: &zeroOrMoreExpr{
-						pos: position{line: 686, col: 19, offset: 20680},
+						pos: position{line: 784, col: 19, offset: 24504},
 						expr: &ruleRefExpr{
-							pos:  position{line: 686, col: 20, offset: 20681},
+							pos:  position{line: 784, col: 20, offset: 24505},
 							name: "Option",
 						},
 					},
@@ -1347,34 +1700,34 @@ var g = &grammar{
 		},
 		{
 			name: "Option",
-			pos:  position{line: 735, col: 1, offset: 22228},
+			pos:  position{line: 833, col: 1, offset: 26052},
 			expr: &actionExpr{
-				pos: position{line: 735, col: 11, offset: 22238},
+				pos: position{line: 833, col: 11, offset: 26062},
 				run: (*parser).callonOption1,
 				expr: &seqExpr{
-					pos: position{line: 735, col: 11, offset: 22238},
+					pos: position{line: 833, col: 11, offset: 26062},
 					exprs: []any{
 						&ruleRefExpr{
-							pos:  position{line: 735, col: 11, offset: 22238},
+							pos:  position{line: 833, col: 11, offset: 26062},
 							name: "SPACE",
 						},
 						&labeledExpr{
-							pos:   position{line: 735, col: 17, offset: 22244},
+							pos:   position{line: 833, col: 17, offset: 26068},
 							label: "optionCMD",
 							expr: &ruleRefExpr{
-								pos:  position{line: 735, col: 27, offset: 22254},
+								pos:  position{line: 833, col: 27, offset: 26078},
 								name: "OptionCMD",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 735, col: 37, offset: 22264},
+							pos:  position{line: 833, col: 37, offset: 26088},
 							name: "EQUAL",
 						},
 						&labeledExpr{
-							pos:   position{line: 735, col: 43, offset: 22270},
+							pos:   position{line: 833, col: 43, offset: 26094},
 							label: "field",
 							expr: &ruleRefExpr{
-								pos:  position{line: 735, col: 49, offset: 22276},
+								pos:  position{line: 833, col: 49, offset: 26100},
 								name: "EvalFieldToRead",
 							},
 						},
@@ -1384,48 +1737,48 @@ var g = &grammar{
 		},
 		{
 			name: "OptionCMD",
-			pos:  position{line: 740, col: 1, offset: 22385},
+			pos:  position{line: 838, col: 1, offset: 26209},
 			expr: &actionExpr{
-				pos: position{line: 740, col: 14, offset: 22398},
+				pos: position{line: 838, col: 14, offset: 26222},
 				run: (*parser).callonOptionCMD1,
 				expr: &labeledExpr{
-					pos:   position{line: 740, col: 14, offset: 22398},
+					pos:   position{line: 838, col: 14, offset: 26222},
 					label: "option",
 					expr: &choiceExpr{
-						pos: position{line: 740, col: 22, offset: 22406},
+						pos: position{line: 838, col: 22, offset: 26230},
 						alternatives: []any{
 							&litMatcher{
-								pos:        position{line: 740, col: 22, offset: 22406},
+								pos:        position{line: 838, col: 22, offset: 26230},
 								val:        "countfield",
 								ignoreCase: false,
 								want:       "\"countfield\"",
 							},
 							&litMatcher{
-								pos:        position{line: 740, col: 37, offset: 22421},
+								pos:        position{line: 838, col: 37, offset: 26245},
 								val:        "showcount",
 								ignoreCase: false,
 								want:       "\"showcount\"",
 							},
 							&litMatcher{
-								pos:        position{line: 740, col: 51, offset: 22435},
+								pos:        position{line: 838, col: 51, offset: 26259},
 								val:        "otherstr",
 								ignoreCase: false,
 								want:       "\"otherstr\"",
 							},
 							&litMatcher{
-								pos:        position{line: 740, col: 64, offset: 22448},
+								pos:        position{line: 838, col: 64, offset: 26272},
 								val:        "useother",
 								ignoreCase: false,
 								want:       "\"useother\"",
 							},
 							&litMatcher{
-								pos:        position{line: 740, col: 76, offset: 22460},
+								pos:        position{line: 838, col: 76, offset: 26284},
 								val:        "percentfield",
 								ignoreCase: false,
 								want:       "\"percentfield\"",
 							},
 							&litMatcher{
-								pos:        position{line: 740, col: 93, offset: 22477},
+								pos:        position{line: 838, col: 93, offset: 26301},
 								val:        "showperc",
 								ignoreCase: false,
 								want:       "\"showperc\"",
@@ -1437,25 +1790,25 @@ var g = &grammar{
 		},
 		{
 			name: "ByClause",
-			pos:  position{line: 748, col: 1, offset: 22664},
+			pos:  position{line: 846, col: 1, offset: 26488},
 			expr: &choiceExpr{
-				pos: position{line: 748, col: 13, offset: 22676},
+				pos: position{line: 846, col: 13, offset: 26500},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 748, col: 13, offset: 22676},
+						pos: position{line: 846, col: 13, offset: 26500},
 						run: (*parser).callonByClause2,
 						expr: &seqExpr{
-							pos: position{line: 748, col: 13, offset: 22676},
+							pos: position{line: 846, col: 13, offset: 26500},
 							exprs: []any{
 								&ruleRefExpr{
-									pos:  position{line: 748, col: 13, offset: 22676},
+									pos:  position{line: 846, col: 13, offset: 26500},
 									name: "BY",
 								},
 								&labeledExpr{
-									pos:   position{line: 748, col: 16, offset: 22679},
+									pos:   position{line: 846, col: 16, offset: 26503},
 									label: "fieldList",
 									expr: &ruleRefExpr{
-										pos:  position{line: 748, col: 26, offset: 22689},
+										pos:  position{line: 846, col: 26, offset: 26513},
 										name: "FieldNameList",
 									},
 								},
@@ -1463,13 +1816,13 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 751, col: 3, offset: 22746},
+						pos: position{line: 849, col: 3, offset: 26570},
 						run: (*parser).callonByClause7,
 						expr: &labeledExpr{
-							pos:   position{line: 751, col: 3, offset: 22746},
+							pos:   position{line: 849, col: 3, offset: 26570},
 							label: "groupByBlock",
 							expr: &ruleRefExpr{
-								pos:  position{line: 751, col: 16, offset: 22759},
+								pos:  position{line: 849, col: 16, offset: 26583},
 								name: "GroupbyBlock",
 							},
 						},
@@ -1479,26 +1832,26 @@ var g = &grammar{
 		},
 		{
 			name: "RenameBlock",
-			pos:  position{line: 755, col: 1, offset: 22817},
+			pos:  position{line: 853, col: 1, offset: 26641},
 			expr: &actionExpr{
-				pos: position{line: 755, col: 16, offset: 22832},
+				pos: position{line: 853, col: 16, offset: 26656},
 				run: (*parser).callonRenameBlock1,
 				expr: &seqExpr{
-					pos: position{line: 755, col: 16, offset: 22832},
+					pos: position{line: 853, col: 16, offset: 26656},
 					exprs: []any{
 						&ruleRefExpr{
-							pos:  position{line: 755, col: 16, offset: 22832},
+							pos:  position{line: 853, col: 16, offset: 26656},
 							name: "PIPE",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 755, col: 21, offset: 22837},
+							pos:  position{line: 853, col: 21, offset: 26661},
 							name: "CMD_RENAME",
 						},
 						&labeledExpr{
-							pos:   position{line: 755, col: 32, offset: 22848},
+							pos:   position{line: 853, col: 32, offset: 26672},
 							label: "renameExpr",
 							expr: &ruleRefExpr{
-								pos:  position{line: 755, col: 43, offset: 22859},
+								pos:  position{line: 853, col: 43, offset: 26683},
 								name: "RenameExpr",
 							},
 						},
@@ -1507,64 +1860,327 @@ var g = &grammar{
 			},
 		},
 		{
-			name: "RenameExpr",
-			pos:  position{line: 771, col: 1, offset: 23234},
+			name: "RenameExpr",
+			pos:  position{line: 869, col: 1, offset: 27058},
+			expr: &choiceExpr{
+				pos: position{line: 869, col: 15, offset: 27072},
+				alternatives: []any{
+					&actionExpr{
+						pos: position{line: 869, col: 15, offset: 27072},
+						run: (*parser).callonRenameExpr2,
+						expr: &seqExpr{
+							pos: position{line: 869, col: 15, offset: 27072},
+							exprs: []any{
+								&labeledExpr{
+									pos:   position{line: 869, col: 15, offset: 27072},
+									label: "originalPattern",
+									expr: &ruleRefExpr{
+										pos:  position{line: 869, col: 31, offset: 27088},
+										name: "RenamePattern",
+									},
+								},
+								&ruleRefExpr{
+									pos:  position{line: 869, col: 45, offset: 27102},
+									name: "AS",
+								},
+								&labeledExpr{
+									pos:   position{line: 869, col: 48, offset: 27105},
+									label: "newPattern",
+									expr: &ruleRefExpr{
+										pos:  position{line: 869, col: 59, offset: 27116},
+										name: "QuotedString",
+									},
+								},
+							},
+						},
+					},
+					&actionExpr{
+						pos: position{line: 880, col: 3, offset: 27435},
+						run: (*parser).callonRenameExpr9,
+						expr: &seqExpr{
+							pos: position{line: 880, col: 3, offset: 27435},
+							exprs: []any{
+								&labeledExpr{
+									pos:   position{line: 880, col: 3, offset: 27435},
+									label: "originalPattern",
+									expr: &ruleRefExpr{
+										pos:  position{line: 880, col: 19, offset: 27451},
+										name: "RenamePattern",
+									},
+								},
+								&ruleRefExpr{
+									pos:  position{line: 880, col: 33, offset: 27465},
+									name: "AS",
+								},
+								&labeledExpr{
+									pos:   position{line: 880, col: 36, offset: 27468},
+									label: "newPattern",
+									expr: &ruleRefExpr{
+										pos:  position{line: 880, col: 47, offset: 27479},
+										name: "RenamePattern",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "RexBlock",
+			pos:  position{line: 902, col: 1, offset: 28045},
+			expr: &actionExpr{
+				pos: position{line: 902, col: 13, offset: 28057},
+				run: (*parser).callonRexBlock1,
+				expr: &seqExpr{
+					pos: position{line: 902, col: 13, offset: 28057},
+					exprs: []any{
+						&ruleRefExpr{
+							pos:  position{line: 902, col: 13, offset: 28057},
+							name: "PIPE",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 902, col: 18, offset: 28062},
+							name: "CMD_REX",
+						},
+						&litMatcher{
+							pos:        position{line: 902, col: 26, offset: 28070},
+							val:        "field",
+							ignoreCase: false,
+							want:       "\"field\"",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 902, col: 34, offset: 28078},
+							name: "EQUAL",
+						},
+						&labeledExpr{
+							pos:   position{line: 902, col: 40, offset: 28084},
+							label: "field",
+							expr: &ruleRefExpr{
+								pos:  position{line: 902, col: 46, offset: 28090},
+								name: "EvalFieldToRead",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 902, col: 62, offset: 28106},
+							name: "SPACE",
+						},
+						&labeledExpr{
+							pos:   position{line: 902, col: 68, offset: 28112},
+							label: "str",
+							expr: &ruleRefExpr{
+								pos:  position{line: 902, col: 72, offset: 28116},
+								name: "QuotedString",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "LookupBlock",
+			pos:  position{line: 929, col: 1, offset: 28801},
+			expr: &actionExpr{
+				pos: position{line: 929, col: 16, offset: 28816},
+				run: (*parser).callonLookupBlock1,
+				expr: &seqExpr{
+					pos: position{line: 929, col: 16, offset: 28816},
+					exprs: []any{
+						&ruleRefExpr{
+							pos:  position{line: 929, col: 16, offset: 28816},
+							name: "PIPE",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 929, col: 21, offset: 28821},
+							name: "CMD_LOOKUP",
+						},
+						&labeledExpr{
+							pos:   position{line: 929, col: 32, offset: 28832},
+							label: "lookupName",
+							expr: &ruleRefExpr{
+								pos:  position{line: 929, col: 43, offset: 28843},
+								name: "FieldName",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 929, col: 53, offset: 28853},
+							name: "SPACE",
+						},
+						&labeledExpr{
+							pos:   position{line: 929, col: 59, offset: 28859},
+							label: "matchField",
+							expr: &ruleRefExpr{
+								pos:  position{line: 929, col: 70, offset: 28870},
+								name: "FieldName",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 929, col: 80, offset: 28880},
+							label: "eventField",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 929, col: 91, offset: 28891},
+								expr: &seqExpr{
+									pos: position{line: 929, col: 92, offset: 28892},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 929, col: 92, offset: 28892},
+											name: "AS",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 929, col: 95, offset: 28895},
+											name: "FieldName",
+										},
+									},
+								},
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 929, col: 107, offset: 28907},
+							label: "outputFields",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 929, col: 120, offset: 28920},
+								expr: &seqExpr{
+									pos: position{line: 929, col: 121, offset: 28921},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 929, col: 121, offset: 28921},
+											name: "SPACE",
+										},
+										&litMatcher{
+											pos:        position{line: 929, col: 127, offset: 28927},
+											val:        "output",
+											ignoreCase: true,
+											want:       "\"OUTPUT\"i",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 929, col: 137, offset: 28937},
+											name: "SPACE",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 929, col: 143, offset: 28943},
+											name: "FieldNameList",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "SimilarToBlock",
+			pos:  position{line: 966, col: 1, offset: 30028},
+			expr: &actionExpr{
+				pos: position{line: 966, col: 19, offset: 30046},
+				run: (*parser).callonSimilarToBlock1,
+				expr: &seqExpr{
+					pos: position{line: 966, col: 19, offset: 30046},
+					exprs: []any{
+						&ruleRefExpr{
+							pos:  position{line: 966, col: 19, offset: 30046},
+							name: "PIPE",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 966, col: 24, offset: 30051},
+							name: "CMD_SIMILAR_TO",
+						},
+						&labeledExpr{
+							pos:   position{line: 966, col: 39, offset: 30066},
+							label: "example",
+							expr: &ruleRefExpr{
+								pos:  position{line: 966, col: 47, offset: 30074},
+								name: "QuotedString",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 966, col: 60, offset: 30087},
+							label: "options",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 966, col: 68, offset: 30095},
+								expr: &seqExpr{
+									pos: position{line: 966, col: 69, offset: 30096},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 966, col: 69, offset: 30096},
+											name: "SPACE",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 966, col: 75, offset: 30102},
+											name: "SimilarToOption",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "SimilarToOption",
+			pos:  position{line: 1005, col: 1, offset: 31427},
 			expr: &choiceExpr{
-				pos: position{line: 771, col: 15, offset: 23248},
+				pos: position{line: 1005, col: 20, offset: 31446},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 771, col: 15, offset: 23248},
-						run: (*parser).callonRenameExpr2,
+						pos: position{line: 1005, col: 20, offset: 31446},
+						run: (*parser).callonSimilarToOption2,
 						expr: &seqExpr{
-							pos: position{line: 771, col: 15, offset: 23248},
+							pos: position{line: 1005, col: 20, offset: 31446},
 							exprs: []any{
-								&labeledExpr{
-									pos:   position{line: 771, col: 15, offset: 23248},
-									label: "originalPattern",
-									expr: &ruleRefExpr{
-										pos:  position{line: 771, col: 31, offset: 23264},
-										name: "RenamePattern",
-									},
+								&litMatcher{
+									pos:        position{line: 1005, col: 20, offset: 31446},
+									val:        "field",
+									ignoreCase: false,
+									want:       "\"field\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 771, col: 45, offset: 23278},
-									name: "AS",
+									pos:  position{line: 1005, col: 28, offset: 31454},
+									name: "EQUAL",
 								},
 								&labeledExpr{
-									pos:   position{line: 771, col: 48, offset: 23281},
-									label: "newPattern",
+									pos:   position{line: 1005, col: 34, offset: 31460},
+									label: "field",
 									expr: &ruleRefExpr{
-										pos:  position{line: 771, col: 59, offset: 23292},
-										name: "QuotedString",
+										pos:  position{line: 1005, col: 40, offset: 31466},
+										name: "FieldName",
 									},
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 782, col: 3, offset: 23611},
-						run: (*parser).callonRenameExpr9,
+						pos: position{line: 1008, col: 3, offset: 31533},
+						run: (*parser).callonSimilarToOption8,
 						expr: &seqExpr{
-							pos: position{line: 782, col: 3, offset: 23611},
+							pos: position{line: 1008, col: 3, offset: 31533},
 							exprs: []any{
-								&labeledExpr{
-									pos:   position{line: 782, col: 3, offset: 23611},
-									label: "originalPattern",
-									expr: &ruleRefExpr{
-										pos:  position{line: 782, col: 19, offset: 23627},
-										name: "RenamePattern",
-									},
+								&litMatcher{
+									pos:        position{line: 1008, col: 3, offset: 31533},
+									val:        "threshold",
+									ignoreCase: false,
+									want:       "\"threshold\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 782, col: 33, offset: 23641},
-									name: "AS",
+									pos:  position{line: 1008, col: 15, offset: 31545},
+									name: "EQUAL",
 								},
 								&labeledExpr{
-									pos:   position{line: 782, col: 36, offset: 23644},
-									label: "newPattern",
-									expr: &ruleRefExpr{
-										pos:  position{line: 782, col: 47, offset: 23655},
-										name: "RenamePattern",
+									pos:   position{line: 1008, col: 21, offset: 31551},
+									label: "threshold",
+									expr: &choiceExpr{
+										pos: position{line: 1008, col: 32, offset: 31562},
+										alternatives: []any{
+											&ruleRefExpr{
+												pos:  position{line: 1008, col: 32, offset: 31562},
+												name: "FloatAsString",
+											},
+											&ruleRefExpr{
+												pos:  position{line: 1008, col: 48, offset: 31578},
+												name: "IntegerAsString",
+											},
+										},
 									},
 								},
 							},
@@ -1573,96 +2189,45 @@ var g = &grammar{
 				},
 			},
 		},
-		{
-			name: "RexBlock",
-			pos:  position{line: 804, col: 1, offset: 24221},
-			expr: &actionExpr{
-				pos: position{line: 804, col: 13, offset: 24233},
-				run: (*parser).callonRexBlock1,
-				expr: &seqExpr{
-					pos: position{line: 804, col: 13, offset: 24233},
-					exprs: []any{
-						&ruleRefExpr{
-							pos:  position{line: 804, col: 13, offset: 24233},
-							name: "PIPE",
-						},
-						&ruleRefExpr{
-							pos:  position{line: 804, col: 18, offset: 24238},
-							name: "CMD_REX",
-						},
-						&litMatcher{
-							pos:        position{line: 804, col: 26, offset: 24246},
-							val:        "field",
-							ignoreCase: false,
-							want:       "\"field\"",
-						},
-						&ruleRefExpr{
-							pos:  position{line: 804, col: 34, offset: 24254},
-							name: "EQUAL",
-						},
-						&labeledExpr{
-							pos:   position{line: 804, col: 40, offset: 24260},
-							label: "field",
-							expr: &ruleRefExpr{
-								pos:  position{line: 804, col: 46, offset: 24266},
-								name: "EvalFieldToRead",
-							},
-						},
-						&ruleRefExpr{
-							pos:  position{line: 804, col: 62, offset: 24282},
-							name: "SPACE",
-						},
-						&labeledExpr{
-							pos:   position{line: 804, col: 68, offset: 24288},
-							label: "str",
-							expr: &ruleRefExpr{
-								pos:  position{line: 804, col: 72, offset: 24292},
-								name: "QuotedString",
-							},
-						},
-					},
-				},
-			},
-		},
 		{
 			name: "EvalBlock",
-			pos:  position{line: 831, col: 1, offset: 24977},
+			pos:  position{line: 1013, col: 1, offset: 31696},
 			expr: &actionExpr{
-				pos: position{line: 831, col: 14, offset: 24990},
+				pos: position{line: 1013, col: 14, offset: 31709},
 				run: (*parser).callonEvalBlock1,
 				expr: &seqExpr{
-					pos: position{line: 831, col: 14, offset: 24990},
+					pos: position{line: 1013, col: 14, offset: 31709},
 					exprs: []any{
 						&ruleRefExpr{
-							pos:  position{line: 831, col: 14, offset: 24990},
+							pos:  position{line: 1013, col: 14, offset: 31709},
 							name: "PIPE",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 831, col: 19, offset: 24995},
+							pos:  position{line: 1013, col: 19, offset: 31714},
 							name: "CMD_EVAL",
 						},
 						&labeledExpr{
-							pos:   position{line: 831, col: 28, offset: 25004},
+							pos:   position{line: 1013, col: 28, offset: 31723},
 							label: "first",
 							expr: &ruleRefExpr{
-								pos:  position{line: 831, col: 34, offset: 25010},
+								pos:  position{line: 1013, col: 34, offset: 31729},
 								name: "SingleEval",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 831, col: 45, offset: 25021},
+							pos:   position{line: 1013, col: 45, offset: 31740},
 							label: "rest",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 831, col: 50, offset: 25026},
+								pos: position{line: 1013, col: 50, offset: 31745},
 								expr: &seqExpr{
-									pos: position{line: 831, col: 51, offset: 25027},
+									pos: position{line: 1013, col: 51, offset: 31746},
 									exprs: []any{
 										&ruleRefExpr{
-											pos:  position{line: 831, col: 51, offset: 25027},
+											pos:  position{line: 1013, col: 51, offset: 31746},
 											name: "COMMA",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 831, col: 57, offset: 25033},
+											pos:  position{line: 1013, col: 57, offset: 31752},
 											name: "SingleEval",
 										},
 									},
@@ -1675,30 +2240,30 @@ var g = &grammar{
 		},
 		{
 			name: "SingleEval",
-			pos:  position{line: 858, col: 1, offset: 25834},
+			pos:  position{line: 1040, col: 1, offset: 32553},
 			expr: &actionExpr{
-				pos: position{line: 858, col: 15, offset: 25848},
+				pos: position{line: 1040, col: 15, offset: 32567},
 				run: (*parser).callonSingleEval1,
 				expr: &seqExpr{
-					pos: position{line: 858, col: 15, offset: 25848},
+					pos: position{line: 1040, col: 15, offset: 32567},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 858, col: 15, offset: 25848},
+							pos:   position{line: 1040, col: 15, offset: 32567},
 							label: "field",
 							expr: &ruleRefExpr{
-								pos:  position{line: 858, col: 21, offset: 25854},
+								pos:  position{line: 1040, col: 21, offset: 32573},
 								name: "FieldName",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 858, col: 31, offset: 25864},
+							pos:  position{line: 1040, col: 31, offset: 32583},
 							name: "EQUAL",
 						},
 						&labeledExpr{
-							pos:   position{line: 858, col: 37, offset: 25870},
+							pos:   position{line: 1040, col: 37, offset: 32589},
 							label: "expr",
 							expr: &ruleRefExpr{
-								pos:  position{line: 858, col: 42, offset: 25875},
+								pos:  position{line: 1040, col: 42, offset: 32594},
 								name: "EvalExpression",
 							},
 						},
@@ -1708,15 +2273,15 @@ var g = &grammar{
 		},
 		{
 			name: "EvalExpression",
-			pos:  position{line: 871, col: 1, offset: 26276},
+			pos:  position{line: 1053, col: 1, offset: 32995},
 			expr: &actionExpr{
-				pos: position{line: 871, col: 19, offset: 26294},
+				pos: position{line: 1053, col: 19, offset: 33013},
 				run: (*parser).callonEvalExpression1,
 				expr: &labeledExpr{
-					pos:   position{line: 871, col: 19, offset: 26294},
+					pos:   position{line: 1053, col: 19, offset: 33013},
 					label: "value",
 					expr: &ruleRefExpr{
-						pos:  position{line: 871, col: 25, offset: 26300},
+						pos:  position{line: 1053, col: 25, offset: 33019},
 						name: "ValueExpr",
 					},
 				},
@@ -1724,57 +2289,57 @@ var g = &grammar{
 		},
 		{
 			name: "ConditionExpr",
-			pos:  position{line: 879, col: 1, offset: 26447},
+			pos:  position{line: 1061, col: 1, offset: 33166},
 			expr: &actionExpr{
-				pos: position{line: 879, col: 18, offset: 26464},
+				pos: position{line: 1061, col: 18, offset: 33183},
 				run: (*parser).callonConditionExpr1,
 				expr: &seqExpr{
-					pos: position{line: 879, col: 18, offset: 26464},
+					pos: position{line: 1061, col: 18, offset: 33183},
 					exprs: []any{
 						&litMatcher{
-							pos:        position{line: 879, col: 18, offset: 26464},
+							pos:        position{line: 1061, col: 18, offset: 33183},
 							val:        "if",
 							ignoreCase: false,
 							want:       "\"if\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 879, col: 23, offset: 26469},
+							pos:  position{line: 1061, col: 23, offset: 33188},
 							name: "L_PAREN",
 						},
 						&labeledExpr{
-							pos:   position{line: 879, col: 31, offset: 26477},
+							pos:   position{line: 1061, col: 31, offset: 33196},
 							label: "condition",
 							expr: &ruleRefExpr{
-								pos:  position{line: 879, col: 41, offset: 26487},
+								pos:  position{line: 1061, col: 41, offset: 33206},
 								name: "BoolExpr",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 879, col: 50, offset: 26496},
+							pos:  position{line: 1061, col: 50, offset: 33215},
 							name: "COMMA",
 						},
 						&labeledExpr{
-							pos:   position{line: 879, col: 56, offset: 26502},
+							pos:   position{line: 1061, col: 56, offset: 33221},
 							label: "trueValue",
 							expr: &ruleRefExpr{
-								pos:  position{line: 879, col: 66, offset: 26512},
+								pos:  position{line: 1061, col: 66, offset: 33231},
 								name: "ValueExpr",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 879, col: 76, offset: 26522},
+							pos:  position{line: 1061, col: 76, offset: 33241},
 							name: "COMMA",
 						},
 						&labeledExpr{
-							pos:   position{line: 879, col: 82, offset: 26528},
+							pos:   position{line: 1061, col: 82, offset: 33247},
 							label: "falseValue",
 							expr: &ruleRefExpr{
-								pos:  position{line: 879, col: 93, offset: 26539},
+								pos:  position{line: 1061, col: 93, offset: 33258},
 								name: "ValueExpr",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 879, col: 103, offset: 26549},
+							pos:  position{line: 1061, col: 103, offset: 33268},
 							name: "R_PAREN",
 						},
 					},
@@ -1783,65 +2348,65 @@ var g = &grammar{
 		},
 		{
 			name: "TextExpr",
-			pos:  position{line: 891, col: 1, offset: 26799},
+			pos:  position{line: 1073, col: 1, offset: 33518},
 			expr: &choiceExpr{
-				pos: position{line: 891, col: 13, offset: 26811},
+				pos: position{line: 1073, col: 13, offset: 33530},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 891, col: 13, offset: 26811},
+						pos: position{line: 1073, col: 13, offset: 33530},
 						run: (*parser).callonTextExpr2,
 						expr: &seqExpr{
-							pos: position{line: 891, col: 14, offset: 26812},
+							pos: position{line: 1073, col: 14, offset: 33531},
 							exprs: []any{
 								&labeledExpr{
-									pos:   position{line: 891, col: 14, offset: 26812},
+									pos:   position{line: 1073, col: 14, offset: 33531},
 									label: "opName",
 									expr: &litMatcher{
-										pos:        position{line: 891, col: 22, offset: 26820},
+										pos:        position{line: 1073, col: 22, offset: 33539},
 										val:        "lower",
 										ignoreCase: false,
 										want:       "\"lower\"",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 891, col: 31, offset: 26829},
+									pos:  position{line: 1073, col: 31, offset: 33548},
 									name: "L_PAREN",
 								},
 								&labeledExpr{
-									pos:   position{line: 891, col: 39, offset: 26837},
+									pos:   position{line: 1073, col: 39, offset: 33556},
 									label: "stringExpr",
 									expr: &ruleRefExpr{
-										pos:  position{line: 891, col: 50, offset: 26848},
+										pos:  position{line: 1073, col: 50, offset: 33567},
 										name: "StringExpr",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 891, col: 61, offset: 26859},
+									pos:  position{line: 1073, col: 61, offset: 33578},
 									name: "R_PAREN",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 905, col: 3, offset: 27171},
+						pos: position{line: 1087, col: 3, offset: 33890},
 						run: (*parser).callonTextExpr10,
 						expr: &seqExpr{
-							pos: position{line: 905, col: 4, offset: 27172},
+							pos: position{line: 1087, col: 4, offset: 33891},
 							exprs: []any{
 								&labeledExpr{
-									pos:   position{line: 905, col: 4, offset: 27172},
+									pos:   position{line: 1087, col: 4, offset: 33891},
 									label: "opName",
 									expr: &choiceExpr{
-										pos: position{line: 905, col: 12, offset: 27180},
+										pos: position{line: 1087, col: 12, offset: 33899},
 										alternatives: []any{
 											&litMatcher{
-												pos:        position{line: 905, col: 12, offset: 27180},
+												pos:        position{line: 1087, col: 12, offset: 33899},
 												val:        "max",
 												ignoreCase: false,
 												want:       "\"max\"",
 											},
 											&litMatcher{
-												pos:        position{line: 905, col: 20, offset: 27188},
+												pos:        position{line: 1087, col: 20, offset: 33907},
 												val:        "min",
 												ignoreCase: false,
 												want:       "\"min\"",
@@ -1850,31 +2415,31 @@ var g = &grammar{
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 905, col: 27, offset: 27195},
+									pos:  position{line: 1087, col: 27, offset: 33914},
 									name: "L_PAREN",
 								},
 								&labeledExpr{
-									pos:   position{line: 905, col: 35, offset: 27203},
+									pos:   position{line: 1087, col: 35, offset: 33922},
 									label: "firstVal",
 									expr: &ruleRefExpr{
-										pos:  position{line: 905, col: 44, offset: 27212},
+										pos:  position{line: 1087, col: 44, offset: 33931},
 										name: "StringExpr",
 									},
 								},
 								&labeledExpr{
-									pos:   position{line: 905, col: 55, offset: 27223},
+									pos:   position{line: 1087, col: 55, offset: 33942},
 									label: "rest",
 									expr: &zeroOrMoreExpr{
-										pos: position{line: 905, col: 60, offset: 27228},
+										pos: position{line: 1087, col: 60, offset: 33947},
 										expr: &seqExpr{
-											pos: position{line: 905, col: 61, offset: 27229},
+											pos: position{line: 1087, col: 61, offset: 33948},
 											exprs: []any{
 												&ruleRefExpr{
-													pos:  position{line: 905, col: 61, offset: 27229},
+													pos:  position{line: 1087, col: 61, offset: 33948},
 													name: "COMMA",
 												},
 												&ruleRefExpr{
-													pos:  position{line: 905, col: 67, offset: 27235},
+													pos:  position{line: 1087, col: 67, offset: 33954},
 													name: "StringExpr",
 												},
 											},
@@ -1882,148 +2447,148 @@ var g = &grammar{
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 905, col: 80, offset: 27248},
+									pos:  position{line: 1087, col: 80, offset: 33967},
 									name: "R_PAREN",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 928, col: 3, offset: 27942},
+						pos: position{line: 1110, col: 3, offset: 34661},
 						run: (*parser).callonTextExpr25,
 						expr: &seqExpr{
-							pos: position{line: 928, col: 4, offset: 27943},
+							pos: position{line: 1110, col: 4, offset: 34662},
 							exprs: []any{
 								&labeledExpr{
-									pos:   position{line: 928, col: 4, offset: 27943},
+									pos:   position{line: 1110, col: 4, offset: 34662},
 									label: "opName",
 									expr: &litMatcher{
-										pos:        position{line: 928, col: 12, offset: 27951},
+										pos:        position{line: 1110, col: 12, offset: 34670},
 										val:        "urldecode",
 										ignoreCase: false,
 										want:       "\"urldecode\"",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 928, col: 25, offset: 27964},
+									pos:  position{line: 1110, col: 25, offset: 34683},
 									name: "L_PAREN",
 								},
 								&labeledExpr{
-									pos:   position{line: 928, col: 33, offset: 27972},
+									pos:   position{line: 1110, col: 33, offset: 34691},
 									label: "url",
 									expr: &ruleRefExpr{
-										pos:  position{line: 928, col: 37, offset: 27976},
+										pos:  position{line: 1110, col: 37, offset: 34695},
 										name: "StringExpr",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 928, col: 48, offset: 27987},
+									pos:  position{line: 1110, col: 48, offset: 34706},
 									name: "R_PAREN",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 940, col: 3, offset: 28326},
+						pos: position{line: 1122, col: 3, offset: 35045},
 						run: (*parser).callonTextExpr33,
 						expr: &seqExpr{
-							pos: position{line: 940, col: 4, offset: 28327},
+							pos: position{line: 1122, col: 4, offset: 35046},
 							exprs: []any{
 								&labeledExpr{
-									pos:   position{line: 940, col: 4, offset: 28327},
+									pos:   position{line: 1122, col: 4, offset: 35046},
 									label: "opName",
 									expr: &litMatcher{
-										pos:        position{line: 940, col: 12, offset: 28335},
+										pos:        position{line: 1122, col: 12, offset: 35054},
 										val:        "split",
 										ignoreCase: false,
 										want:       "\"split\"",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 940, col: 21, offset: 28344},
+									pos:  position{line: 1122, col: 21, offset: 35063},
 									name: "L_PAREN",
 								},
 								&labeledExpr{
-									pos:   position{line: 940, col: 29, offset: 28352},
+									pos:   position{line: 1122, col: 29, offset: 35071},
 									label: "stringExpr",
 									expr: &ruleRefExpr{
-										pos:  position{line: 940, col: 40, offset: 28363},
+										pos:  position{line: 1122, col: 40, offset: 35082},
 										name: "StringExpr",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 940, col: 51, offset: 28374},
+									pos:  position{line: 1122, col: 51, offset: 35093},
 									name: "COMMA",
 								},
 								&labeledExpr{
-									pos:   position{line: 940, col: 57, offset: 28380},
+									pos:   position{line: 1122, col: 57, offset: 35099},
 									label: "delim",
 									expr: &ruleRefExpr{
-										pos:  position{line: 940, col: 63, offset: 28386},
+										pos:  position{line: 1122, col: 63, offset: 35105},
 										name: "StringExpr",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 940, col: 74, offset: 28397},
+									pos:  position{line: 1122, col: 74, offset: 35116},
 									name: "R_PAREN",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 952, col: 3, offset: 28730},
+						pos: position{line: 1134, col: 3, offset: 35449},
 						run: (*parser).callonTextExpr44,
 						expr: &seqExpr{
-							pos: position{line: 952, col: 4, offset: 28731},
+							pos: position{line: 1134, col: 4, offset: 35450},
 							exprs: []any{
 								&labeledExpr{
-									pos:   position{line: 952, col: 4, offset: 28731},
+									pos:   position{line: 1134, col: 4, offset: 35450},
 									label: "opName",
 									expr: &litMatcher{
-										pos:        position{line: 952, col: 12, offset: 28739},
+										pos:        position{line: 1134, col: 12, offset: 35458},
 										val:        "substr",
 										ignoreCase: false,
 										want:       "\"substr\"",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 952, col: 22, offset: 28749},
+									pos:  position{line: 1134, col: 22, offset: 35468},
 									name: "L_PAREN",
 								},
 								&labeledExpr{
-									pos:   position{line: 952, col: 30, offset: 28757},
+									pos:   position{line: 1134, col: 30, offset: 35476},
 									label: "stringExpr",
 									expr: &ruleRefExpr{
-										pos:  position{line: 952, col: 41, offset: 28768},
+										pos:  position{line: 1134, col: 41, offset: 35487},
 										name: "StringExpr",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 952, col: 52, offset: 28779},
+									pos:  position{line: 1134, col: 52, offset: 35498},
 									name: "COMMA",
 								},
 								&labeledExpr{
-									pos:   position{line: 952, col: 58, offset: 28785},
+									pos:   position{line: 1134, col: 58, offset: 35504},
 									label: "startIndex",
 									expr: &ruleRefExpr{
-										pos:  position{line: 952, col: 69, offset: 28796},
+										pos:  position{line: 1134, col: 69, offset: 35515},
 										name: "NumericExpr",
 									},
 								},
 								&labeledExpr{
-									pos:   position{line: 952, col: 81, offset: 28808},
+									pos:   position{line: 1134, col: 81, offset: 35527},
 									label: "lengthParam",
 									expr: &zeroOrOneExpr{
-										pos: position{line: 952, col: 93, offset: 28820},
+										pos: position{line: 1134, col: 93, offset: 35539},
 										expr: &seqExpr{
-											pos: position{line: 952, col: 94, offset: 28821},
+											pos: position{line: 1134, col: 94, offset: 35540},
 											exprs: []any{
 												&ruleRefExpr{
-													pos:  position{line: 952, col: 94, offset: 28821},
+													pos:  position{line: 1134, col: 94, offset: 35540},
 													name: "COMMA",
 												},
 												&ruleRefExpr{
-													pos:  position{line: 952, col: 100, offset: 28827},
+													pos:  position{line: 1134, col: 100, offset: 35546},
 													name: "NumericExpr",
 												},
 											},
@@ -2031,50 +2596,50 @@ var g = &grammar{
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 952, col: 114, offset: 28841},
+									pos:  position{line: 1134, col: 114, offset: 35560},
 									name: "R_PAREN",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 986, col: 3, offset: 30027},
+						pos: position{line: 1168, col: 3, offset: 36746},
 						run: (*parser).callonTextExpr60,
 						expr: &seqExpr{
-							pos: position{line: 986, col: 3, offset: 30027},
+							pos: position{line: 1168, col: 3, offset: 36746},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 986, col: 3, offset: 30027},
+									pos:        position{line: 1168, col: 3, offset: 36746},
 									val:        "tostring",
 									ignoreCase: false,
 									want:       "\"tostring\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 986, col: 14, offset: 30038},
+									pos:  position{line: 1168, col: 14, offset: 36757},
 									name: "L_PAREN",
 								},
 								&labeledExpr{
-									pos:   position{line: 986, col: 22, offset: 30046},
+									pos:   position{line: 1168, col: 22, offset: 36765},
 									label: "value",
 									expr: &ruleRefExpr{
-										pos:  position{line: 986, col: 28, offset: 30052},
+										pos:  position{line: 1168, col: 28, offset: 36771},
 										name: "ValueExpr",
 									},
 								},
 								&labeledExpr{
-									pos:   position{line: 986, col: 38, offset: 30062},
+									pos:   position{line: 1168, col: 38, offset: 36781},
 									label: "format",
 									expr: &zeroOrOneExpr{
-										pos: position{line: 986, col: 45, offset: 30069},
+										pos: position{line: 1168, col: 45, offset: 36788},
 										expr: &seqExpr{
-											pos: position{line: 986, col: 46, offset: 30070},
+											pos: position{line: 1168, col: 46, offset: 36789},
 											exprs: []any{
 												&ruleRefExpr{
-													pos:  position{line: 986, col: 46, offset: 30070},
+													pos:  position{line: 1168, col: 46, offset: 36789},
 													name: "COMMA",
 												},
 												&ruleRefExpr{
-													pos:  position{line: 986, col: 52, offset: 30076},
+													pos:  position{line: 1168, col: 52, offset: 36795},
 													name: "StringExpr",
 												},
 											},
@@ -2082,32 +2647,32 @@ var g = &grammar{
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 986, col: 66, offset: 30090},
+									pos:  position{line: 1168, col: 66, offset: 36809},
 									name: "R_PAREN",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 999, col: 3, offset: 30460},
+						pos: position{line: 1181, col: 3, offset: 37179},
 						run: (*parser).callonTextExpr72,
 						expr: &seqExpr{
-							pos: position{line: 999, col: 4, offset: 30461},
+							pos: position{line: 1181, col: 4, offset: 37180},
 							exprs: []any{
 								&labeledExpr{
-									pos:   position{line: 999, col: 4, offset: 30461},
+									pos:   position{line: 1181, col: 4, offset: 37180},
 									label: "opName",
 									expr: &choiceExpr{
-										pos: position{line: 999, col: 12, offset: 30469},
+										pos: position{line: 1181, col: 12, offset: 37188},
 										alternatives: []any{
 											&litMatcher{
-												pos:        position{line: 999, col: 12, offset: 30469},
+												pos:        position{line: 1181, col: 12, offset: 37188},
 												val:        "ltrim",
 												ignoreCase: false,
 												want:       "\"ltrim\"",
 											},
 											&litMatcher{
-												pos:        position{line: 999, col: 22, offset: 30479},
+												pos:        position{line: 1181, col: 22, offset: 37198},
 												val:        "rtrim",
 												ignoreCase: false,
 												want:       "\"rtrim\"",
@@ -2116,30 +2681,30 @@ var g = &grammar{
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 999, col: 31, offset: 30488},
+									pos:  position{line: 1181, col: 31, offset: 37207},
 									name: "L_PAREN",
 								},
 								&labeledExpr{
-									pos:   position{line: 999, col: 39, offset: 30496},
+									pos:   position{line: 1181, col: 39, offset: 37215},
 									label: "expr",
 									expr: &ruleRefExpr{
-										pos:  position{line: 999, col: 45, offset: 30502},
+										pos:  position{line: 1181, col: 45, offset: 37221},
 										name: "StringExpr",
 									},
 								},
 								&labeledExpr{
-									pos:   position{line: 999, col: 57, offset: 30514},
+									pos:   position{line: 1181, col: 57, offset: 37233},
 									label: "strToRemoveExpr",
 									expr: &zeroOrOneExpr{
-										pos: position{line: 999, col: 73, offset: 30530},
+										pos: position{line: 1181, col: 73, offset: 37249},
 										expr: &ruleRefExpr{
-											pos:  position{line: 999, col: 74, offset: 30531},
+											pos:  position{line: 1181, col: 74, offset: 37250},
 											name: "StrToRemoveExpr",
 										},
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 999, col: 92, offset: 30549},
+									pos:  position{line: 1181, col: 92, offset: 37268},
 									name: "R_PAREN",
 								},
 							},
@@ -2150,22 +2715,22 @@ var g = &grammar{
 		},
 		{
 			name: "StrToRemoveExpr",
-			pos:  position{line: 1024, col: 1, offset: 31152},
+			pos:  position{line: 1206, col: 1, offset: 37871},
 			expr: &actionExpr{
-				pos: position{line: 1024, col: 20, offset: 31171},
+				pos: position{line: 1206, col: 20, offset: 37890},
 				run: (*parser).callonStrToRemoveExpr1,
 				expr: &seqExpr{
-					pos: position{line: 1024, col: 20, offset: 31171},
+					pos: position{line: 1206, col: 20, offset: 37890},
 					exprs: []any{
 						&ruleRefExpr{
-							pos:  position{line: 1024, col: 20, offset: 31171},
+							pos:  position{line: 1206, col: 20, offset: 37890},
 							name: "COMMA",
 						},
 						&labeledExpr{
-							pos:   position{line: 1024, col: 26, offset: 31177},
+							pos:   position{line: 1206, col: 26, offset: 37896},
 							label: "strToRemove",
 							expr: &ruleRefExpr{
-								pos:  position{line: 1024, col: 38, offset: 31189},
+								pos:  position{line: 1206, col: 38, offset: 37908},
 								name: "String",
 							},
 						},
@@ -2175,20 +2740,20 @@ var g = &grammar{
 		},
 		{
 			name: "EvalFieldToRead",
-			pos:  position{line: 1030, col: 1, offset: 31374},
+			pos:  position{line: 1212, col: 1, offset: 38093},
 			expr: &choiceExpr{
-				pos: position{line: 1030, col: 20, offset: 31393},
+				pos: position{line: 1212, col: 20, offset: 38112},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 1030, col: 20, offset: 31393},
+						pos: position{line: 1212, col: 20, offset: 38112},
 						run: (*parser).callonEvalFieldToRead2,
 						expr: &seqExpr{
-							pos: position{line: 1030, col: 20, offset: 31393},
+							pos: position{line: 1212, col: 20, offset: 38112},
 							exprs: []any{
 								&oneOrMoreExpr{
-									pos: position{line: 1030, col: 20, offset: 31393},
+									pos: position{line: 1212, col: 20, offset: 38112},
 									expr: &charClassMatcher{
-										pos:        position{line: 1030, col: 20, offset: 31393},
+										pos:        position{line: 1212, col: 20, offset: 38112},
 										val:        "[a-zA-Z_]",
 										chars:      []rune{'_'},
 										ranges:     []rune{'a', 'z', 'A', 'Z'},
@@ -2197,9 +2762,9 @@ var g = &grammar{
 									},
 								},
 								&notExpr{
-									pos: position{line: 1030, col: 31, offset: 31404},
+									pos: position{line: 1212, col: 31, offset: 38123},
 									expr: &litMatcher{
-										pos:        position{line: 1030, col: 33, offset: 31406},
+										pos:        position{line: 1212, col: 33, offset: 38125},
 										val:        "(",
 										ignoreCase: false,
 										want:       "\"(\"",
@@ -2209,27 +2774,27 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 1033, col: 3, offset: 31448},
+						pos: position{line: 1215, col: 3, offset: 38167},
 						run: (*parser).callonEvalFieldToRead8,
 						expr: &seqExpr{
-							pos: position{line: 1033, col: 3, offset: 31448},
+							pos: position{line: 1215, col: 3, offset: 38167},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 1033, col: 3, offset: 31448},
+									pos:        position{line: 1215, col: 3, offset: 38167},
 									val:        "'",
 									ignoreCase: false,
 									want:       "\"'\"",
 								},
 								&labeledExpr{
-									pos:   position{line: 1033, col: 7, offset: 31452},
+									pos:   position{line: 1215, col: 7, offset: 38171},
 									label: "field",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1033, col: 13, offset: 31458},
+										pos:  position{line: 1215, col: 13, offset: 38177},
 										name: "FieldName",
 									},
 								},
 								&litMatcher{
-									pos:        position{line: 1033, col: 23, offset: 31468},
+									pos:        position{line: 1215, col: 23, offset: 38187},
 									val:        "'",
 									ignoreCase: false,
 									want:       "\"'\"",
@@ -2242,26 +2807,26 @@ var g = &grammar{
 		},
 		{
 			name: "WhereBlock",
-			pos:  position{line: 1038, col: 1, offset: 31536},
+			pos:  position{line: 1220, col: 1, offset: 38255},
 			expr: &actionExpr{
-				pos: position{line: 1038, col: 15, offset: 31550},
+				pos: position{line: 1220, col: 15, offset: 38269},
 				run: (*parser).callonWhereBlock1,
 				expr: &seqExpr{
-					pos: position{line: 1038, col: 15, offset: 31550},
+					pos: position{line: 1220, col: 15, offset: 38269},
 					exprs: []any{
 						&ruleRefExpr{
-							pos:  position{line: 1038, col: 15, offset: 31550},
+							pos:  position{line: 1220, col: 15, offset: 38269},
 							name: "PIPE",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 1038, col: 20, offset: 31555},
+							pos:  position{line: 1220, col: 20, offset: 38274},
 							name: "CMD_WHERE",
 						},
 						&labeledExpr{
-							pos:   position{line: 1038, col: 30, offset: 31565},
+							pos:   position{line: 1220, col: 30, offset: 38284},
 							label: "condition",
 							expr: &ruleRefExpr{
-								pos:  position{line: 1038, col: 40, offset: 31575},
+								pos:  position{line: 1220, col: 40, offset: 38294},
 								name: "BoolExpr",
 							},
 						},
@@ -2271,15 +2836,15 @@ var g = &grammar{
 		},
 		{
 			name: "BoolExpr",
-			pos:  position{line: 1050, col: 1, offset: 31868},
+			pos:  position{line: 1232, col: 1, offset: 38587},
 			expr: &actionExpr{
-				pos: position{line: 1050, col: 13, offset: 31880},
+				pos: position{line: 1232, col: 13, offset: 38599},
 				run: (*parser).callonBoolExpr1,
 				expr: &labeledExpr{
-					pos:   position{line: 1050, col: 13, offset: 31880},
+					pos:   position{line: 1232, col: 13, offset: 38599},
 					label: "expr",
 					expr: &ruleRefExpr{
-						pos:  position{line: 1050, col: 18, offset: 31885},
+						pos:  position{line: 1232, col: 18, offset: 38604},
 						name: "BoolExprLevel4",
 					},
 				},
@@ -2287,35 +2852,35 @@ var g = &grammar{
 		},
 		{
 			name: "BoolExprLevel4",
-			pos:  position{line: 1055, col: 1, offset: 31955},
+			pos:  position{line: 1237, col: 1, offset: 38674},
 			expr: &actionExpr{
-				pos: position{line: 1055, col: 19, offset: 31973},
+				pos: position{line: 1237, col: 19, offset: 38692},
 				run: (*parser).callonBoolExprLevel41,
 				expr: &seqExpr{
-					pos: position{line: 1055, col: 19, offset: 31973},
+					pos: position{line: 1237, col: 19, offset: 38692},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 1055, col: 19, offset: 31973},
+							pos:   position{line: 1237, col: 19, offset: 38692},
 							label: "first",
 							expr: &ruleRefExpr{
-								pos:  position{line: 1055, col: 25, offset: 31979},
+								pos:  position{line: 1237, col: 25, offset: 38698},
 								name: "BoolExprLevel3",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 1055, col: 40, offset: 31994},
+							pos:   position{line: 1237, col: 40, offset: 38713},
 							label: "rest",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 1055, col: 45, offset: 31999},
+								pos: position{line: 1237, col: 45, offset: 38718},
 								expr: &seqExpr{
-									pos: position{line: 1055, col: 46, offset: 32000},
+									pos: position{line: 1237, col: 46, offset: 38719},
 									exprs: []any{
 										&ruleRefExpr{
-											pos:  position{line: 1055, col: 46, offset: 32000},
+											pos:  position{line: 1237, col: 46, offset: 38719},
 											name: "OR",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 1055, col: 49, offset: 32003},
+											pos:  position{line: 1237, col: 49, offset: 38722},
 											name: "BoolExprLevel3",
 										},
 									},
@@ -2328,35 +2893,35 @@ var g = &grammar{
 		},
 		{
 			name: "BoolExprLevel3",
-			pos:  position{line: 1075, col: 1, offset: 32441},
+			pos:  position{line: 1257, col: 1, offset: 39160},
 			expr: &actionExpr{
-				pos: position{line: 1075, col: 19, offset: 32459},
+				pos: position{line: 1257, col: 19, offset: 39178},
 				run: (*parser).callonBoolExprLevel31,
 				expr: &seqExpr{
-					pos: position{line: 1075, col: 19, offset: 32459},
+					pos: position{line: 1257, col: 19, offset: 39178},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 1075, col: 19, offset: 32459},
+							pos:   position{line: 1257, col: 19, offset: 39178},
 							label: "first",
 							expr: &ruleRefExpr{
-								pos:  position{line: 1075, col: 25, offset: 32465},
+								pos:  position{line: 1257, col: 25, offset: 39184},
 								name: "BoolExprLevel2",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 1075, col: 40, offset: 32480},
+							pos:   position{line: 1257, col: 40, offset: 39199},
 							label: "rest",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 1075, col: 45, offset: 32485},
+								pos: position{line: 1257, col: 45, offset: 39204},
 								expr: &seqExpr{
-									pos: position{line: 1075, col: 46, offset: 32486},
+									pos: position{line: 1257, col: 46, offset: 39205},
 									exprs: []any{
 										&ruleRefExpr{
-											pos:  position{line: 1075, col: 46, offset: 32486},
+											pos:  position{line: 1257, col: 46, offset: 39205},
 											name: "AND",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 1075, col: 50, offset: 32490},
+											pos:  position{line: 1257, col: 50, offset: 39209},
 											name: "BoolExprLevel2",
 										},
 									},
@@ -2369,47 +2934,47 @@ var g = &grammar{
 		},
 		{
 			name: "BoolExprLevel2",
-			pos:  position{line: 1095, col: 1, offset: 32929},
+			pos:  position{line: 1277, col: 1, offset: 39648},
 			expr: &choiceExpr{
-				pos: position{line: 1095, col: 19, offset: 32947},
+				pos: position{line: 1277, col: 19, offset: 39666},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 1095, col: 19, offset: 32947},
+						pos: position{line: 1277, col: 19, offset: 39666},
 						run: (*parser).callonBoolExprLevel22,
 						expr: &seqExpr{
-							pos: position{line: 1095, col: 19, offset: 32947},
+							pos: position{line: 1277, col: 19, offset: 39666},
 							exprs: []any{
 								&ruleRefExpr{
-									pos:  position{line: 1095, col: 19, offset: 32947},
+									pos:  position{line: 1277, col: 19, offset: 39666},
 									name: "NOT",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1095, col: 23, offset: 32951},
+									pos:  position{line: 1277, col: 23, offset: 39670},
 									name: "L_PAREN",
 								},
 								&labeledExpr{
-									pos:   position{line: 1095, col: 31, offset: 32959},
+									pos:   position{line: 1277, col: 31, offset: 39678},
 									label: "first",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1095, col: 37, offset: 32965},
+										pos:  position{line: 1277, col: 37, offset: 39684},
 										name: "BoolExprLevel1",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1095, col: 52, offset: 32980},
+									pos:  position{line: 1277, col: 52, offset: 39699},
 									name: "R_PAREN",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 1105, col: 3, offset: 33183},
+						pos: position{line: 1287, col: 3, offset: 39902},
 						run: (*parser).callonBoolExprLevel29,
 						expr: &labeledExpr{
-							pos:   position{line: 1105, col: 3, offset: 33183},
+							pos:   position{line: 1287, col: 3, offset: 39902},
 							label: "first",
 							expr: &ruleRefExpr{
-								pos:  position{line: 1105, col: 9, offset: 33189},
+								pos:  position{line: 1287, col: 9, offset: 39908},
 								name: "BoolExprLevel1",
 							},
 						},
@@ -2419,67 +2984,67 @@ var g = &grammar{
 		},
 		{
 			name: "BoolExprLevel1",
-			pos:  position{line: 1110, col: 1, offset: 33260},
+			pos:  position{line: 1292, col: 1, offset: 39979},
 			expr: &choiceExpr{
-				pos: position{line: 1110, col: 19, offset: 33278},
+				pos: position{line: 1292, col: 19, offset: 39997},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 1110, col: 19, offset: 33278},
+						pos: position{line: 1292, col: 19, offset: 39997},
 						run: (*parser).callonBoolExprLevel12,
 						expr: &seqExpr{
-							pos: position{line: 1110, col: 19, offset: 33278},
+							pos: position{line: 1292, col: 19, offset: 39997},
 							exprs: []any{
 								&ruleRefExpr{
-									pos:  position{line: 1110, col: 19, offset: 33278},
+									pos:  position{line: 1292, col: 19, offset: 39997},
 									name: "L_PAREN",
 								},
 								&labeledExpr{
-									pos:   position{line: 1110, col: 27, offset: 33286},
+									pos:   position{line: 1292, col: 27, offset: 40005},
 									label: "first",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1110, col: 33, offset: 33292},
+										pos:  position{line: 1292, col: 33, offset: 40011},
 										name: "BoolExprLevel4",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1110, col: 48, offset: 33307},
+									pos:  position{line: 1292, col: 48, offset: 40026},
 									name: "R_PAREN",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 1113, col: 3, offset: 33343},
+						pos: position{line: 1295, col: 3, offset: 40062},
 						run: (*parser).callonBoolExprLevel18,
 						expr: &seqExpr{
-							pos: position{line: 1113, col: 4, offset: 33344},
+							pos: position{line: 1295, col: 4, offset: 40063},
 							exprs: []any{
 								&labeledExpr{
-									pos:   position{line: 1113, col: 4, offset: 33344},
+									pos:   position{line: 1295, col: 4, offset: 40063},
 									label: "op",
 									expr: &choiceExpr{
-										pos: position{line: 1113, col: 8, offset: 33348},
+										pos: position{line: 1295, col: 8, offset: 40067},
 										alternatives: []any{
 											&litMatcher{
-												pos:        position{line: 1113, col: 8, offset: 33348},
+												pos:        position{line: 1295, col: 8, offset: 40067},
 												val:        "isbool",
 												ignoreCase: false,
 												want:       "\"isbool\"",
 											},
 											&litMatcher{
-												pos:        position{line: 1113, col: 19, offset: 33359},
+												pos:        position{line: 1295, col: 19, offset: 40078},
 												val:        "isint",
 												ignoreCase: false,
 												want:       "\"isint\"",
 											},
 											&litMatcher{
-												pos:        position{line: 1113, col: 29, offset: 33369},
+												pos:        position{line: 1295, col: 29, offset: 40088},
 												val:        "isstr",
 												ignoreCase: false,
 												want:       "\"isstr\"",
 											},
 											&litMatcher{
-												pos:        position{line: 1113, col: 39, offset: 33379},
+												pos:        position{line: 1295, col: 39, offset: 40098},
 												val:        "isnull",
 												ignoreCase: false,
 												want:       "\"isnull\"",
@@ -2488,32 +3053,32 @@ var g = &grammar{
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1113, col: 49, offset: 33389},
+									pos:  position{line: 1295, col: 49, offset: 40108},
 									name: "L_PAREN",
 								},
 								&labeledExpr{
-									pos:   position{line: 1113, col: 57, offset: 33397},
+									pos:   position{line: 1295, col: 57, offset: 40116},
 									label: "value",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1113, col: 63, offset: 33403},
+										pos:  position{line: 1295, col: 63, offset: 40122},
 										name: "ValueExpr",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1113, col: 73, offset: 33413},
+									pos:  position{line: 1295, col: 73, offset: 40132},
 									name: "R_PAREN",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 1126, col: 3, offset: 33749},
+						pos: position{line: 1308, col: 3, offset: 40468},
 						run: (*parser).callonBoolExprLevel120,
 						expr: &labeledExpr{
-							pos:   position{line: 1126, col: 3, offset: 33749},
+							pos:   position{line: 1308, col: 3, offset: 40468},
 							label: "likeExpr",
 							expr: &ruleRefExpr{
-								pos:  position{line: 1126, col: 13, offset: 33759},
+								pos:  position{line: 1308, col: 13, offset: 40478},
 								name: "LikeExpr",
 							},
 						},
@@ -2523,43 +3088,43 @@ var g = &grammar{
 		},
 		{
 			name: "LikeExpr",
-			pos:  position{line: 1129, col: 1, offset: 33797},
+			pos:  position{line: 1311, col: 1, offset: 40516},
 			expr: &choiceExpr{
-				pos: position{line: 1129, col: 13, offset: 33809},
+				pos: position{line: 1311, col: 13, offset: 40528},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 1129, col: 13, offset: 33809},
+						pos: position{line: 1311, col: 13, offset: 40528},
 						run: (*parser).callonLikeExpr2,
 						expr: &seqExpr{
-							pos: position{line: 1129, col: 13, offset: 33809},
+							pos: position{line: 1311, col: 13, offset: 40528},
 							exprs: []any{
 								&labeledExpr{
-									pos:   position{line: 1129, col: 13, offset: 33809},
+									pos:   position{line: 1311, col: 13, offset: 40528},
 									label: "left",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1129, col: 18, offset: 33814},
+										pos:  position{line: 1311, col: 18, offset: 40533},
 										name: "ValueExpr",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1129, col: 28, offset: 33824},
+									pos:  position{line: 1311, col: 28, offset: 40543},
 									name: "SPACE",
 								},
 								&litMatcher{
-									pos:        position{line: 1129, col: 34, offset: 33830},
+									pos:        position{line: 1311, col: 34, offset: 40549},
 									val:        "LIKE",
 									ignoreCase: false,
 									want:       "\"LIKE\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1129, col: 41, offset: 33837},
+									pos:  position{line: 1311, col: 41, offset: 40556},
 									name: "SPACE",
 								},
 								&labeledExpr{
-									pos:   position{line: 1129, col: 47, offset: 33843},
+									pos:   position{line: 1311, col: 47, offset: 40562},
 									label: "right",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1129, col: 53, offset: 33849},
+										pos:  position{line: 1311, col: 53, offset: 40568},
 										name: "ValueExpr",
 									},
 								},
@@ -2567,154 +3132,154 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 1138, col: 3, offset: 34069},
+						pos: position{line: 1320, col: 3, offset: 40788},
 						run: (*parser).callonLikeExpr11,
 						expr: &seqExpr{
-							pos: position{line: 1138, col: 3, offset: 34069},
+							pos: position{line: 1320, col: 3, offset: 40788},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 1138, col: 3, offset: 34069},
+									pos:        position{line: 1320, col: 3, offset: 40788},
 									val:        "like",
 									ignoreCase: false,
 									want:       "\"like\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1138, col: 10, offset: 34076},
+									pos:  position{line: 1320, col: 10, offset: 40795},
 									name: "L_PAREN",
 								},
 								&labeledExpr{
-									pos:   position{line: 1138, col: 18, offset: 34084},
+									pos:   position{line: 1320, col: 18, offset: 40803},
 									label: "stringr",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1138, col: 26, offset: 34092},
+										pos:  position{line: 1320, col: 26, offset: 40811},
 										name: "ValueExpr",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1138, col: 36, offset: 34102},
+									pos:  position{line: 1320, col: 36, offset: 40821},
 									name: "COMMA",
 								},
 								&labeledExpr{
-									pos:   position{line: 1138, col: 42, offset: 34108},
+									pos:   position{line: 1320, col: 42, offset: 40827},
 									label: "pattern",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1138, col: 50, offset: 34116},
+										pos:  position{line: 1320, col: 50, offset: 40835},
 										name: "ValueExpr",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1138, col: 60, offset: 34126},
+									pos:  position{line: 1320, col: 60, offset: 40845},
 									name: "R_PAREN",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 1147, col: 3, offset: 34357},
+						pos: position{line: 1329, col: 3, offset: 41076},
 						run: (*parser).callonLikeExpr21,
 						expr: &seqExpr{
-							pos: position{line: 1147, col: 3, offset: 34357},
+							pos: position{line: 1329, col: 3, offset: 41076},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 1147, col: 3, offset: 34357},
+									pos:        position{line: 1329, col: 3, offset: 41076},
 									val:        "match",
 									ignoreCase: false,
 									want:       "\"match\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1147, col: 11, offset: 34365},
+									pos:  position{line: 1329, col: 11, offset: 41084},
 									name: "L_PAREN",
 								},
 								&labeledExpr{
-									pos:   position{line: 1147, col: 19, offset: 34373},
+									pos:   position{line: 1329, col: 19, offset: 41092},
 									label: "stringVal",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1147, col: 29, offset: 34383},
+										pos:  position{line: 1329, col: 29, offset: 41102},
 										name: "ValueExpr",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1147, col: 39, offset: 34393},
+									pos:  position{line: 1329, col: 39, offset: 41112},
 									name: "COMMA",
 								},
 								&labeledExpr{
-									pos:   position{line: 1147, col: 45, offset: 34399},
+									pos:   position{line: 1329, col: 45, offset: 41118},
 									label: "pattern",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1147, col: 53, offset: 34407},
+										pos:  position{line: 1329, col: 53, offset: 41126},
 										name: "ValueExpr",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1147, col: 63, offset: 34417},
+									pos:  position{line: 1329, col: 63, offset: 41136},
 									name: "R_PAREN",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 1156, col: 3, offset: 34651},
+						pos: position{line: 1338, col: 3, offset: 41370},
 						run: (*parser).callonLikeExpr31,
 						expr: &seqExpr{
-							pos: position{line: 1156, col: 3, offset: 34651},
+							pos: position{line: 1338, col: 3, offset: 41370},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 1156, col: 3, offset: 34651},
+									pos:        position{line: 1338, col: 3, offset: 41370},
 									val:        "cidrmatch",
 									ignoreCase: false,
 									want:       "\"cidrmatch\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1156, col: 15, offset: 34663},
+									pos:  position{line: 1338, col: 15, offset: 41382},
 									name: "L_PAREN",
 								},
 								&labeledExpr{
-									pos:   position{line: 1156, col: 23, offset: 34671},
+									pos:   position{line: 1338, col: 23, offset: 41390},
 									label: "cidr",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1156, col: 28, offset: 34676},
+										pos:  position{line: 1338, col: 28, offset: 41395},
 										name: "ValueExpr",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1156, col: 38, offset: 34686},
+									pos:  position{line: 1338, col: 38, offset: 41405},
 									name: "COMMA",
 								},
 								&labeledExpr{
-									pos:   position{line: 1156, col: 44, offset: 34692},
+									pos:   position{line: 1338, col: 44, offset: 41411},
 									label: "ip",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1156, col: 47, offset: 34695},
+										pos:  position{line: 1338, col: 47, offset: 41414},
 										name: "ValueExpr",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1156, col: 57, offset: 34705},
+									pos:  position{line: 1338, col: 57, offset: 41424},
 									name: "R_PAREN",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 1165, col: 3, offset: 34925},
+						pos: position{line: 1347, col: 3, offset: 41644},
 						run: (*parser).callonLikeExpr41,
 						expr: &labeledExpr{
-							pos:   position{line: 1165, col: 3, offset: 34925},
+							pos:   position{line: 1347, col: 3, offset: 41644},
 							label: "inExpr",
 							expr: &ruleRefExpr{
-								pos:  position{line: 1165, col: 11, offset: 34933},
+								pos:  position{line: 1347, col: 11, offset: 41652},
 								name: "InExpr",
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 1168, col: 3, offset: 34969},
+						pos: position{line: 1350, col: 3, offset: 41688},
 						run: (*parser).callonLikeExpr44,
 						expr: &labeledExpr{
-							pos:   position{line: 1168, col: 3, offset: 34969},
+							pos:   position{line: 1350, col: 3, offset: 41688},
 							label: "boolComparisonExpr",
 							expr: &ruleRefExpr{
-								pos:  position{line: 1168, col: 22, offset: 34988},
+								pos:  position{line: 1350, col: 22, offset: 41707},
 								name: "BoolComparisonExpr",
 							},
 						},
@@ -2724,34 +3289,34 @@ var g = &grammar{
 		},
 		{
 			name: "BoolComparisonExpr",
-			pos:  position{line: 1172, col: 1, offset: 35047},
+			pos:  position{line: 1354, col: 1, offset: 41766},
 			expr: &actionExpr{
-				pos: position{line: 1172, col: 23, offset: 35069},
+				pos: position{line: 1354, col: 23, offset: 41788},
 				run: (*parser).callonBoolComparisonExpr1,
 				expr: &seqExpr{
-					pos: position{line: 1172, col: 23, offset: 35069},
+					pos: position{line: 1354, col: 23, offset: 41788},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 1172, col: 23, offset: 35069},
+							pos:   position{line: 1354, col: 23, offset: 41788},
 							label: "left",
 							expr: &ruleRefExpr{
-								pos:  position{line: 1172, col: 28, offset: 35074},
+								pos:  position{line: 1354, col: 28, offset: 41793},
 								name: "ValueExpr",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 1172, col: 38, offset: 35084},
+							pos:   position{line: 1354, col: 38, offset: 41803},
 							label: "op",
 							expr: &ruleRefExpr{
-								pos:  position{line: 1172, col: 41, offset: 35087},
+								pos:  position{line: 1354, col: 41, offset: 41806},
 								name: "EqualityOrInequality",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 1172, col: 62, offset: 35108},
+							pos:   position{line: 1354, col: 62, offset: 41827},
 							label: "right",
 							expr: &ruleRefExpr{
-								pos:  position{line: 1172, col: 68, offset: 35114},
+								pos:  position{line: 1354, col: 68, offset: 41833},
 								name: "ValueExpr",
 							},
 						},
@@ -2761,60 +3326,60 @@ var g = &grammar{
 		},
 		{
 			name: "InExpr",
-			pos:  position{line: 1184, col: 1, offset: 35340},
+			pos:  position{line: 1366, col: 1, offset: 42059},
 			expr: &choiceExpr{
-				pos: position{line: 1184, col: 11, offset: 35350},
+				pos: position{line: 1366, col: 11, offset: 42069},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 1184, col: 11, offset: 35350},
+						pos: position{line: 1366, col: 11, offset: 42069},
 						run: (*parser).callonInExpr2,
 						expr: &seqExpr{
-							pos: position{line: 1184, col: 11, offset: 35350},
+							pos: position{line: 1366, col: 11, offset: 42069},
 							exprs: []any{
 								&labeledExpr{
-									pos:   position{line: 1184, col: 11, offset: 35350},
+									pos:   position{line: 1366, col: 11, offset: 42069},
 									label: "left",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1184, col: 16, offset: 35355},
+										pos:  position{line: 1366, col: 16, offset: 42074},
 										name: "ValueExpr",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1184, col: 26, offset: 35365},
+									pos:  position{line: 1366, col: 26, offset: 42084},
 									name: "SPACE",
 								},
 								&litMatcher{
-									pos:        position{line: 1184, col: 32, offset: 35371},
+									pos:        position{line: 1366, col: 32, offset: 42090},
 									val:        "in",
 									ignoreCase: false,
 									want:       "\"in\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1184, col: 37, offset: 35376},
+									pos:  position{line: 1366, col: 37, offset: 42095},
 									name: "L_PAREN",
 								},
 								&labeledExpr{
-									pos:   position{line: 1184, col: 45, offset: 35384},
+									pos:   position{line: 1366, col: 45, offset: 42103},
 									label: "valueToJudge",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1184, col: 58, offset: 35397},
+										pos:  position{line: 1366, col: 58, offset: 42116},
 										name: "ValueExpr",
 									},
 								},
 								&labeledExpr{
-									pos:   position{line: 1184, col: 68, offset: 35407},
+									pos:   position{line: 1366, col: 68, offset: 42126},
 									label: "rest",
 									expr: &zeroOrMoreExpr{
-										pos: position{line: 1184, col: 73, offset: 35412},
+										pos: position{line: 1366, col: 73, offset: 42131},
 										expr: &seqExpr{
-											pos: position{line: 1184, col: 74, offset: 35413},
+											pos: position{line: 1366, col: 74, offset: 42132},
 											exprs: []any{
 												&ruleRefExpr{
-													pos:  position{line: 1184, col: 74, offset: 35413},
+													pos:  position{line: 1366, col: 74, offset: 42132},
 													name: "COMMA",
 												},
 												&ruleRefExpr{
-													pos:  position{line: 1184, col: 80, offset: 35419},
+													pos:  position{line: 1366, col: 80, offset: 42138},
 													name: "ValueExpr",
 												},
 											},
@@ -2822,50 +3387,50 @@ var g = &grammar{
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1184, col: 92, offset: 35431},
+									pos:  position{line: 1366, col: 92, offset: 42150},
 									name: "R_PAREN",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 1203, col: 3, offset: 35982},
+						pos: position{line: 1385, col: 3, offset: 42701},
 						run: (*parser).callonInExpr17,
 						expr: &seqExpr{
-							pos: position{line: 1203, col: 3, offset: 35982},
+							pos: position{line: 1385, col: 3, offset: 42701},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 1203, col: 3, offset: 35982},
+									pos:        position{line: 1385, col: 3, offset: 42701},
 									val:        "in",
 									ignoreCase: false,
 									want:       "\"in\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1203, col: 8, offset: 35987},
+									pos:  position{line: 1385, col: 8, offset: 42706},
 									name: "L_PAREN",
 								},
 								&labeledExpr{
-									pos:   position{line: 1203, col: 16, offset: 35995},
+									pos:   position{line: 1385, col: 16, offset: 42714},
 									label: "valueToJudge",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1203, col: 29, offset: 36008},
+										pos:  position{line: 1385, col: 29, offset: 42727},
 										name: "ValueExpr",
 									},
 								},
 								&labeledExpr{
-									pos:   position{line: 1203, col: 39, offset: 36018},
+									pos:   position{line: 1385, col: 39, offset: 42737},
 									label: "rest",
 									expr: &zeroOrMoreExpr{
-										pos: position{line: 1203, col: 44, offset: 36023},
+										pos: position{line: 1385, col: 44, offset: 42742},
 										expr: &seqExpr{
-											pos: position{line: 1203, col: 45, offset: 36024},
+											pos: position{line: 1385, col: 45, offset: 42743},
 											exprs: []any{
 												&ruleRefExpr{
-													pos:  position{line: 1203, col: 45, offset: 36024},
+													pos:  position{line: 1385, col: 45, offset: 42743},
 													name: "COMMA",
 												},
 												&ruleRefExpr{
-													pos:  position{line: 1203, col: 51, offset: 36030},
+													pos:  position{line: 1385, col: 51, offset: 42749},
 													name: "ValueExpr",
 												},
 											},
@@ -2873,7 +3438,7 @@ var g = &grammar{
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1203, col: 63, offset: 36042},
+									pos:  position{line: 1385, col: 63, offset: 42761},
 									name: "R_PAREN",
 								},
 							},
@@ -2884,116 +3449,116 @@ var g = &grammar{
 		},
 		{
 			name: "ValueExpr",
-			pos:  position{line: 1228, col: 1, offset: 36832},
+			pos:  position{line: 1410, col: 1, offset: 43551},
 			expr: &choiceExpr{
-				pos: position{line: 1228, col: 14, offset: 36845},
+				pos: position{line: 1410, col: 14, offset: 43564},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 1228, col: 14, offset: 36845},
+						pos: position{line: 1410, col: 14, offset: 43564},
 						run: (*parser).callonValueExpr2,
 						expr: &labeledExpr{
-							pos:   position{line: 1228, col: 14, offset: 36845},
+							pos:   position{line: 1410, col: 14, offset: 43564},
 							label: "condition",
 							expr: &ruleRefExpr{
-								pos:  position{line: 1228, col: 24, offset: 36855},
+								pos:  position{line: 1410, col: 24, offset: 43574},
 								name: "ConditionExpr",
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 1237, col: 3, offset: 37045},
+						pos: position{line: 1419, col: 3, offset: 43764},
 						run: (*parser).callonValueExpr5,
 						expr: &seqExpr{
-							pos: position{line: 1237, col: 3, offset: 37045},
+							pos: position{line: 1419, col: 3, offset: 43764},
 							exprs: []any{
 								&ruleRefExpr{
-									pos:  position{line: 1237, col: 3, offset: 37045},
+									pos:  position{line: 1419, col: 3, offset: 43764},
 									name: "L_PAREN",
 								},
 								&labeledExpr{
-									pos:   position{line: 1237, col: 12, offset: 37054},
+									pos:   position{line: 1419, col: 12, offset: 43773},
 									label: "condition",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1237, col: 22, offset: 37064},
+										pos:  position{line: 1419, col: 22, offset: 43783},
 										name: "ConditionExpr",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1237, col: 37, offset: 37079},
+									pos:  position{line: 1419, col: 37, offset: 43798},
 									name: "R_PAREN",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 1246, col: 3, offset: 37263},
+						pos: position{line: 1428, col: 3, offset: 43982},
 						run: (*parser).callonValueExpr11,
 						expr: &labeledExpr{
-							pos:   position{line: 1246, col: 3, offset: 37263},
+							pos:   position{line: 1428, col: 3, offset: 43982},
 							label: "numeric",
 							expr: &ruleRefExpr{
-								pos:  position{line: 1246, col: 11, offset: 37271},
+								pos:  position{line: 1428, col: 11, offset: 43990},
 								name: "NumericExpr",
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 1255, col: 3, offset: 37451},
+						pos: position{line: 1437, col: 3, offset: 44170},
 						run: (*parser).callonValueExpr14,
 						expr: &labeledExpr{
-							pos:   position{line: 1255, col: 3, offset: 37451},
+							pos:   position{line: 1437, col: 3, offset: 44170},
 							label: "str",
 							expr: &ruleRefExpr{
-								pos:  position{line: 1255, col: 7, offset: 37455},
+								pos:  position{line: 1437, col: 7, offset: 44174},
 								name: "StringExpr",
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 1264, col: 3, offset: 37627},
+						pos: position{line: 1446, col: 3, offset: 44346},
 						run: (*parser).callonValueExpr17,
 						expr: &seqExpr{
-							pos: position{line: 1264, col: 3, offset: 37627},
+							pos: position{line: 1446, col: 3, offset: 44346},
 							exprs: []any{
 								&ruleRefExpr{
-									pos:  position{line: 1264, col: 3, offset: 37627},
+									pos:  position{line: 1446, col: 3, offset: 44346},
 									name: "L_PAREN",
 								},
 								&labeledExpr{
-									pos:   position{line: 1264, col: 12, offset: 37636},
+									pos:   position{line: 1446, col: 12, offset: 44355},
 									label: "str",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1264, col: 16, offset: 37640},
+										pos:  position{line: 1446, col: 16, offset: 44359},
 										name: "StringExpr",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1264, col: 28, offset: 37652},
+									pos:  position{line: 1446, col: 28, offset: 44371},
 									name: "R_PAREN",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 1273, col: 3, offset: 37821},
+						pos: position{line: 1455, col: 3, offset: 44540},
 						run: (*parser).callonValueExpr23,
 						expr: &seqExpr{
-							pos: position{line: 1273, col: 3, offset: 37821},
+							pos: position{line: 1455, col: 3, offset: 44540},
 							exprs: []any{
 								&ruleRefExpr{
-									pos:  position{line: 1273, col: 3, offset: 37821},
+									pos:  position{line: 1455, col: 3, offset: 44540},
 									name: "L_PAREN",
 								},
 								&labeledExpr{
-									pos:   position{line: 1273, col: 11, offset: 37829},
+									pos:   position{line: 1455, col: 11, offset: 44548},
 									label: "boolean",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1273, col: 19, offset: 37837},
+										pos:  position{line: 1455, col: 19, offset: 44556},
 										name: "BoolExpr",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1273, col: 28, offset: 37846},
+									pos:  position{line: 1455, col: 28, offset: 44565},
 									name: "R_PAREN",
 								},
 							},
@@ -3004,28 +3569,28 @@ var g = &grammar{
 		},
 		{
 			name: "StringExpr",
-			pos:  position{line: 1283, col: 1, offset: 38027},
+			pos:  position{line: 1465, col: 1, offset: 44746},
 			expr: &choiceExpr{
-				pos: position{line: 1283, col: 15, offset: 38041},
+				pos: position{line: 1465, col: 15, offset: 44760},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 1283, col: 15, offset: 38041},
+						pos: position{line: 1465, col: 15, offset: 44760},
 						run: (*parser).callonStringExpr2,
 						expr: &seqExpr{
-							pos: position{line: 1283, col: 15, offset: 38041},
+							pos: position{line: 1465, col: 15, offset: 44760},
 							exprs: []any{
 								&labeledExpr{
-									pos:   position{line: 1283, col: 15, offset: 38041},
+									pos:   position{line: 1465, col: 15, offset: 44760},
 									label: "text",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1283, col: 20, offset: 38046},
+										pos:  position{line: 1465, col: 20, offset: 44765},
 										name: "TextExpr",
 									},
 								},
 								&notExpr{
-									pos: position{line: 1283, col: 29, offset: 38055},
+									pos: position{line: 1465, col: 29, offset: 44774},
 									expr: &ruleRefExpr{
-										pos:  position{line: 1283, col: 31, offset: 38057},
+										pos:  position{line: 1465, col: 31, offset: 44776},
 										name: "EVAL_CONCAT",
 									},
 								},
@@ -3033,23 +3598,23 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 1291, col: 3, offset: 38227},
+						pos: position{line: 1473, col: 3, offset: 44946},
 						run: (*parser).callonStringExpr8,
 						expr: &seqExpr{
-							pos: position{line: 1291, col: 3, offset: 38227},
+							pos: position{line: 1473, col: 3, offset: 44946},
 							exprs: []any{
 								&labeledExpr{
-									pos:   position{line: 1291, col: 3, offset: 38227},
+									pos:   position{line: 1473, col: 3, offset: 44946},
 									label: "str",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1291, col: 7, offset: 38231},
+										pos:  position{line: 1473, col: 7, offset: 44950},
 										name: "QuotedString",
 									},
 								},
 								&notExpr{
-									pos: position{line: 1291, col: 20, offset: 38244},
+									pos: position{line: 1473, col: 20, offset: 44963},
 									expr: &ruleRefExpr{
-										pos:  position{line: 1291, col: 22, offset: 38246},
+										pos:  position{line: 1473, col: 22, offset: 44965},
 										name: "EVAL_CONCAT",
 									},
 								},
@@ -3057,46 +3622,46 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 1299, col: 3, offset: 38411},
+						pos: position{line: 1481, col: 3, offset: 45130},
 						run: (*parser).callonStringExpr14,
 						expr: &seqExpr{
-							pos: position{line: 1299, col: 3, offset: 38411},
+							pos: position{line: 1481, col: 3, offset: 45130},
 							exprs: []any{
 								&labeledExpr{
-									pos:   position{line: 1299, col: 3, offset: 38411},
+									pos:   position{line: 1481, col: 3, offset: 45130},
 									label: "field",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1299, col: 9, offset: 38417},
+										pos:  position{line: 1481, col: 9, offset: 45136},
 										name: "EvalFieldToRead",
 									},
 								},
 								&notExpr{
-									pos: position{line: 1299, col: 25, offset: 38433},
+									pos: position{line: 1481, col: 25, offset: 45152},
 									expr: &choiceExpr{
-										pos: position{line: 1299, col: 27, offset: 38435},
+										pos: position{line: 1481, col: 27, offset: 45154},
 										alternatives: []any{
 											&ruleRefExpr{
-												pos:  position{line: 1299, col: 27, offset: 38435},
+												pos:  position{line: 1481, col: 27, offset: 45154},
 												name: "OpPlus",
 											},
 											&ruleRefExpr{
-												pos:  position{line: 1299, col: 36, offset: 38444},
+												pos:  position{line: 1481, col: 36, offset: 45163},
 												name: "OpMinus",
 											},
 											&ruleRefExpr{
-												pos:  position{line: 1299, col: 46, offset: 38454},
+												pos:  position{line: 1481, col: 46, offset: 45173},
 												name: "OpMul",
 											},
 											&ruleRefExpr{
-												pos:  position{line: 1299, col: 54, offset: 38462},
+												pos:  position{line: 1481, col: 54, offset: 45181},
 												name: "OpDiv",
 											},
 											&ruleRefExpr{
-												pos:  position{line: 1299, col: 62, offset: 38470},
+												pos:  position{line: 1481, col: 62, offset: 45189},
 												name: "EVAL_CONCAT",
 											},
 											&litMatcher{
-												pos:        position{line: 1299, col: 76, offset: 38484},
+												pos:        position{line: 1481, col: 76, offset: 45203},
 												val:        "(",
 												ignoreCase: false,
 												want:       "\"(\"",
@@ -3108,13 +3673,13 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 1307, col: 3, offset: 38634},
+						pos: position{line: 1489, col: 3, offset: 45353},
 						run: (*parser).callonStringExpr26,
 						expr: &labeledExpr{
-							pos:   position{line: 1307, col: 3, offset: 38634},
+							pos:   position{line: 1489, col: 3, offset: 45353},
 							label: "concat",
 							expr: &ruleRefExpr{
-								pos:  position{line: 1307, col: 10, offset: 38641},
+								pos:  position{line: 1489, col: 10, offset: 45360},
 								name: "ConcatExpr",
 							},
 						},
@@ -3124,35 +3689,35 @@ var g = &grammar{
 		},
 		{
 			name: "ConcatExpr",
-			pos:  position{line: 1317, col: 1, offset: 38847},
+			pos:  position{line: 1499, col: 1, offset: 45566},
 			expr: &actionExpr{
-				pos: position{line: 1317, col: 15, offset: 38861},
+				pos: position{line: 1499, col: 15, offset: 45580},
 				run: (*parser).callonConcatExpr1,
 				expr: &seqExpr{
-					pos: position{line: 1317, col: 15, offset: 38861},
+					pos: position{line: 1499, col: 15, offset: 45580},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 1317, col: 15, offset: 38861},
+							pos:   position{line: 1499, col: 15, offset: 45580},
 							label: "first",
 							expr: &ruleRefExpr{
-								pos:  position{line: 1317, col: 21, offset: 38867},
+								pos:  position{line: 1499, col: 21, offset: 45586},
 								name: "ConcatAtom",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 1317, col: 32, offset: 38878},
+							pos:   position{line: 1499, col: 32, offset: 45597},
 							label: "rest",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 1317, col: 37, offset: 38883},
+								pos: position{line: 1499, col: 37, offset: 45602},
 								expr: &seqExpr{
-									pos: position{line: 1317, col: 38, offset: 38884},
+									pos: position{line: 1499, col: 38, offset: 45603},
 									exprs: []any{
 										&ruleRefExpr{
-											pos:  position{line: 1317, col: 38, offset: 38884},
+											pos:  position{line: 1499, col: 38, offset: 45603},
 											name: "EVAL_CONCAT",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 1317, col: 50, offset: 38896},
+											pos:  position{line: 1499, col: 50, offset: 45615},
 											name: "ConcatAtom",
 										},
 									},
@@ -3160,28 +3725,28 @@ var g = &grammar{
 							},
 						},
 						&notExpr{
-							pos: position{line: 1317, col: 63, offset: 38909},
+							pos: position{line: 1499, col: 63, offset: 45628},
 							expr: &choiceExpr{
-								pos: position{line: 1317, col: 65, offset: 38911},
+								pos: position{line: 1499, col: 65, offset: 45630},
 								alternatives: []any{
 									&ruleRefExpr{
-										pos:  position{line: 1317, col: 65, offset: 38911},
+										pos:  position{line: 1499, col: 65, offset: 45630},
 										name: "OpPlus",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 1317, col: 74, offset: 38920},
+										pos:  position{line: 1499, col: 74, offset: 45639},
 										name: "OpMinus",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 1317, col: 84, offset: 38930},
+										pos:  position{line: 1499, col: 84, offset: 45649},
 										name: "OpMul",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 1317, col: 92, offset: 38938},
+										pos:  position{line: 1499, col: 92, offset: 45657},
 										name: "OpDiv",
 									},
 									&litMatcher{
-										pos:        position{line: 1317, col: 100, offset: 38946},
+										pos:        position{line: 1499, col: 100, offset: 45665},
 										val:        "(",
 										ignoreCase: false,
 										want:       "\"(\"",
@@ -3195,54 +3760,54 @@ var g = &grammar{
 		},
 		{
 			name: "ConcatAtom",
-			pos:  position{line: 1335, col: 1, offset: 39352},
+			pos:  position{line: 1517, col: 1, offset: 46071},
 			expr: &choiceExpr{
-				pos: position{line: 1335, col: 15, offset: 39366},
+				pos: position{line: 1517, col: 15, offset: 46085},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 1335, col: 15, offset: 39366},
+						pos: position{line: 1517, col: 15, offset: 46085},
 						run: (*parser).callonConcatAtom2,
 						expr: &labeledExpr{
-							pos:   position{line: 1335, col: 15, offset: 39366},
+							pos:   position{line: 1517, col: 15, offset: 46085},
 							label: "text",
 							expr: &ruleRefExpr{
-								pos:  position{line: 1335, col: 20, offset: 39371},
+								pos:  position{line: 1517, col: 20, offset: 46090},
 								name: "TextExpr",
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 1344, col: 3, offset: 39535},
+						pos: position{line: 1526, col: 3, offset: 46254},
 						run: (*parser).callonConcatAtom5,
 						expr: &labeledExpr{
-							pos:   position{line: 1344, col: 3, offset: 39535},
+							pos:   position{line: 1526, col: 3, offset: 46254},
 							label: "str",
 							expr: &ruleRefExpr{
-								pos:  position{line: 1344, col: 7, offset: 39539},
+								pos:  position{line: 1526, col: 7, offset: 46258},
 								name: "QuotedString",
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 1352, col: 3, offset: 39678},
+						pos: position{line: 1534, col: 3, offset: 46397},
 						run: (*parser).callonConcatAtom8,
 						expr: &labeledExpr{
-							pos:   position{line: 1352, col: 3, offset: 39678},
+							pos:   position{line: 1534, col: 3, offset: 46397},
 							label: "number",
 							expr: &ruleRefExpr{
-								pos:  position{line: 1352, col: 10, offset: 39685},
+								pos:  position{line: 1534, col: 10, offset: 46404},
 								name: "NumberAsString",
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 1360, col: 3, offset: 39824},
+						pos: position{line: 1542, col: 3, offset: 46543},
 						run: (*parser).callonConcatAtom11,
 						expr: &labeledExpr{
-							pos:   position{line: 1360, col: 3, offset: 39824},
+							pos:   position{line: 1542, col: 3, offset: 46543},
 							label: "field",
 							expr: &ruleRefExpr{
-								pos:  position{line: 1360, col: 9, offset: 39830},
+								pos:  position{line: 1542, col: 9, offset: 46549},
 								name: "EvalFieldToRead",
 							},
 						},
@@ -3252,32 +3817,32 @@ var g = &grammar{
 		},
 		{
 			name: "NumericExpr",
-			pos:  position{line: 1370, col: 1, offset: 39999},
+			pos:  position{line: 1552, col: 1, offset: 46718},
 			expr: &actionExpr{
-				pos: position{line: 1370, col: 16, offset: 40014},
+				pos: position{line: 1552, col: 16, offset: 46733},
 				run: (*parser).callonNumericExpr1,
 				expr: &seqExpr{
-					pos: position{line: 1370, col: 16, offset: 40014},
+					pos: position{line: 1552, col: 16, offset: 46733},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 1370, col: 16, offset: 40014},
+							pos:   position{line: 1552, col: 16, offset: 46733},
 							label: "expr",
 							expr: &ruleRefExpr{
-								pos:  position{line: 1370, col: 21, offset: 40019},
+								pos:  position{line: 1552, col: 21, offset: 46738},
 								name: "NumericExprLevel3",
 							},
 						},
 						&notExpr{
-							pos: position{line: 1370, col: 39, offset: 40037},
+							pos: position{line: 1552, col: 39, offset: 46756},
 							expr: &choiceExpr{
-								pos: position{line: 1370, col: 41, offset: 40039},
+								pos: position{line: 1552, col: 41, offset: 46758},
 								alternatives: []any{
 									&ruleRefExpr{
-										pos:  position{line: 1370, col: 41, offset: 40039},
+										pos:  position{line: 1552, col: 41, offset: 46758},
 										name: "EVAL_CONCAT",
 									},
 									&litMatcher{
-										pos:        position{line: 1370, col: 55, offset: 40053},
+										pos:        position{line: 1552, col: 55, offset: 46772},
 										val:        "\"",
 										ignoreCase: false,
 										want:       "\"\\\"\"",
@@ -3291,44 +3856,44 @@ var g = &grammar{
 		},
 		{
 			name: "NumericExprLevel3",
-			pos:  position{line: 1375, col: 1, offset: 40118},
+			pos:  position{line: 1557, col: 1, offset: 46837},
 			expr: &actionExpr{
-				pos: position{line: 1375, col: 22, offset: 40139},
+				pos: position{line: 1557, col: 22, offset: 46858},
 				run: (*parser).callonNumericExprLevel31,
 				expr: &seqExpr{
-					pos: position{line: 1375, col: 22, offset: 40139},
+					pos: position{line: 1557, col: 22, offset: 46858},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 1375, col: 22, offset: 40139},
+							pos:   position{line: 1557, col: 22, offset: 46858},
 							label: "first",
 							expr: &ruleRefExpr{
-								pos:  position{line: 1375, col: 28, offset: 40145},
+								pos:  position{line: 1557, col: 28, offset: 46864},
 								name: "NumericExprLevel2",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 1375, col: 46, offset: 40163},
+							pos:   position{line: 1557, col: 46, offset: 46882},
 							label: "rest",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 1375, col: 51, offset: 40168},
+								pos: position{line: 1557, col: 51, offset: 46887},
 								expr: &seqExpr{
-									pos: position{line: 1375, col: 52, offset: 40169},
+									pos: position{line: 1557, col: 52, offset: 46888},
 									exprs: []any{
 										&choiceExpr{
-											pos: position{line: 1375, col: 53, offset: 40170},
+											pos: position{line: 1557, col: 53, offset: 46889},
 											alternatives: []any{
 												&ruleRefExpr{
-													pos:  position{line: 1375, col: 53, offset: 40170},
+													pos:  position{line: 1557, col: 53, offset: 46889},
 													name: "OpPlus",
 												},
 												&ruleRefExpr{
-													pos:  position{line: 1375, col: 62, offset: 40179},
+													pos:  position{line: 1557, col: 62, offset: 46898},
 													name: "OpMinus",
 												},
 											},
 										},
 										&ruleRefExpr{
-											pos:  position{line: 1375, col: 71, offset: 40188},
+											pos:  position{line: 1557, col: 71, offset: 46907},
 											name: "NumericExprLevel2",
 										},
 									},
@@ -3341,44 +3906,44 @@ var g = &grammar{
 		},
 		{
 			name: "NumericExprLevel2",
-			pos:  position{line: 1396, col: 1, offset: 40689},
+			pos:  position{line: 1578, col: 1, offset: 47408},
 			expr: &actionExpr{
-				pos: position{line: 1396, col: 22, offset: 40710},
+				pos: position{line: 1578, col: 22, offset: 47429},
 				run: (*parser).callonNumericExprLevel21,
 				expr: &seqExpr{
-					pos: position{line: 1396, col: 22, offset: 40710},
+					pos: position{line: 1578, col: 22, offset: 47429},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 1396, col: 22, offset: 40710},
+							pos:   position{line: 1578, col: 22, offset: 47429},
 							label: "first",
 							expr: &ruleRefExpr{
-								pos:  position{line: 1396, col: 28, offset: 40716},
+								pos:  position{line: 1578, col: 28, offset: 47435},
 								name: "NumericExprLevel1",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 1396, col: 46, offset: 40734},
+							pos:   position{line: 1578, col: 46, offset: 47453},
 							label: "rest",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 1396, col: 51, offset: 40739},
+								pos: position{line: 1578, col: 51, offset: 47458},
 								expr: &seqExpr{
-									pos: position{line: 1396, col: 52, offset: 40740},
+									pos: position{line: 1578, col: 52, offset: 47459},
 									exprs: []any{
 										&choiceExpr{
-											pos: position{line: 1396, col: 53, offset: 40741},
+											pos: position{line: 1578, col: 53, offset: 47460},
 											alternatives: []any{
 												&ruleRefExpr{
-													pos:  position{line: 1396, col: 53, offset: 40741},
+													pos:  position{line: 1578, col: 53, offset: 47460},
 													name: "OpMul",
 												},
 												&ruleRefExpr{
-													pos:  position{line: 1396, col: 61, offset: 40749},
+													pos:  position{line: 1578, col: 61, offset: 47468},
 													name: "OpDiv",
 												},
 											},
 										},
 										&ruleRefExpr{
-											pos:  position{line: 1396, col: 68, offset: 40756},
+											pos:  position{line: 1578, col: 68, offset: 47475},
 											name: "NumericExprLevel1",
 										},
 									},
@@ -3391,22 +3956,22 @@ var g = &grammar{
 		},
 		{
 			name: "RoundPrecisionExpr",
-			pos:  position{line: 1416, col: 1, offset: 41225},
+			pos:  position{line: 1598, col: 1, offset: 47944},
 			expr: &actionExpr{
-				pos: position{line: 1416, col: 23, offset: 41247},
+				pos: position{line: 1598, col: 23, offset: 47966},
 				run: (*parser).callonRoundPrecisionExpr1,
 				expr: &seqExpr{
-					pos: position{line: 1416, col: 23, offset: 41247},
+					pos: position{line: 1598, col: 23, offset: 47966},
 					exprs: []any{
 						&ruleRefExpr{
-							pos:  position{line: 1416, col: 23, offset: 41247},
+							pos:  position{line: 1598, col: 23, offset: 47966},
 							name: "COMMA",
 						},
 						&labeledExpr{
-							pos:   position{line: 1416, col: 29, offset: 41253},
+							pos:   position{line: 1598, col: 29, offset: 47972},
 							label: "expr",
 							expr: &ruleRefExpr{
-								pos:  position{line: 1416, col: 34, offset: 41258},
+								pos:  position{line: 1598, col: 34, offset: 47977},
 								name: "NumericExprLevel3",
 							},
 						},
@@ -3416,67 +3981,67 @@ var g = &grammar{
 		},
 		{
 			name: "NumericExprLevel1",
-			pos:  position{line: 1426, col: 1, offset: 41506},
+			pos:  position{line: 1608, col: 1, offset: 48225},
 			expr: &choiceExpr{
-				pos: position{line: 1426, col: 22, offset: 41527},
+				pos: position{line: 1608, col: 22, offset: 48246},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 1426, col: 22, offset: 41527},
+						pos: position{line: 1608, col: 22, offset: 48246},
 						run: (*parser).callonNumericExprLevel12,
 						expr: &seqExpr{
-							pos: position{line: 1426, col: 22, offset: 41527},
+							pos: position{line: 1608, col: 22, offset: 48246},
 							exprs: []any{
 								&ruleRefExpr{
-									pos:  position{line: 1426, col: 22, offset: 41527},
+									pos:  position{line: 1608, col: 22, offset: 48246},
 									name: "L_PAREN",
 								},
 								&labeledExpr{
-									pos:   position{line: 1426, col: 30, offset: 41535},
+									pos:   position{line: 1608, col: 30, offset: 48254},
 									label: "expr",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1426, col: 35, offset: 41540},
+										pos:  position{line: 1608, col: 35, offset: 48259},
 										name: "NumericExprLevel3",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1426, col: 53, offset: 41558},
+									pos:  position{line: 1608, col: 53, offset: 48277},
 									name: "R_PAREN",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 1429, col: 3, offset: 41593},
+						pos: position{line: 1611, col: 3, offset: 48312},
 						run: (*parser).callonNumericExprLevel18,
 						expr: &labeledExpr{
-							pos:   position{line: 1429, col: 3, offset: 41593},
+							pos:   position{line: 1611, col: 3, offset: 48312},
 							label: "numericEvalExpr",
 							expr: &ruleRefExpr{
-								pos:  position{line: 1429, col: 20, offset: 41610},
+								pos:  position{line: 1611, col: 20, offset: 48329},
 								name: "NumericEvalExpr",
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 1432, col: 3, offset: 41664},
+						pos: position{line: 1614, col: 3, offset: 48383},
 						run: (*parser).callonNumericExprLevel111,
 						expr: &labeledExpr{
-							pos:   position{line: 1432, col: 3, offset: 41664},
+							pos:   position{line: 1614, col: 3, offset: 48383},
 							label: "field",
 							expr: &ruleRefExpr{
-								pos:  position{line: 1432, col: 9, offset: 41670},
+								pos:  position{line: 1614, col: 9, offset: 48389},
 								name: "EvalFieldToRead",
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 1442, col: 3, offset: 41889},
+						pos: position{line: 1624, col: 3, offset: 48608},
 						run: (*parser).callonNumericExprLevel114,
 						expr: &labeledExpr{
-							pos:   position{line: 1442, col: 3, offset: 41889},
+							pos:   position{line: 1624, col: 3, offset: 48608},
 							label: "number",
 							expr: &ruleRefExpr{
-								pos:  position{line: 1442, col: 10, offset: 41896},
+								pos:  position{line: 1624, col: 10, offset: 48615},
 								name: "NumberAsString",
 							},
 						},
@@ -3486,48 +4051,48 @@ var g = &grammar{
 		},
 		{
 			name: "NumericEvalExpr",
-			pos:  position{line: 1454, col: 1, offset: 42154},
+			pos:  position{line: 1636, col: 1, offset: 48873},
 			expr: &choiceExpr{
-				pos: position{line: 1454, col: 20, offset: 42173},
+				pos: position{line: 1636, col: 20, offset: 48892},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 1454, col: 20, offset: 42173},
+						pos: position{line: 1636, col: 20, offset: 48892},
 						run: (*parser).callonNumericEvalExpr2,
 						expr: &seqExpr{
-							pos: position{line: 1454, col: 21, offset: 42174},
+							pos: position{line: 1636, col: 21, offset: 48893},
 							exprs: []any{
 								&labeledExpr{
-									pos:   position{line: 1454, col: 21, offset: 42174},
+									pos:   position{line: 1636, col: 21, offset: 48893},
 									label: "opName",
 									expr: &choiceExpr{
-										pos: position{line: 1454, col: 29, offset: 42182},
+										pos: position{line: 1636, col: 29, offset: 48901},
 										alternatives: []any{
 											&litMatcher{
-												pos:        position{line: 1454, col: 29, offset: 42182},
+												pos:        position{line: 1636, col: 29, offset: 48901},
 												val:        "abs",
 												ignoreCase: false,
 												want:       "\"abs\"",
 											},
 											&litMatcher{
-												pos:        position{line: 1454, col: 37, offset: 42190},
+												pos:        position{line: 1636, col: 37, offset: 48909},
 												val:        "ceil",
 												ignoreCase: false,
 												want:       "\"ceil\"",
 											},
 											&litMatcher{
-												pos:        position{line: 1454, col: 46, offset: 42199},
+												pos:        position{line: 1636, col: 46, offset: 48918},
 												val:        "sqrt",
 												ignoreCase: false,
 												want:       "\"sqrt\"",
 											},
 											&litMatcher{
-												pos:        position{line: 1454, col: 54, offset: 42207},
+												pos:        position{line: 1636, col: 54, offset: 48926},
 												val:        "exact",
 												ignoreCase: false,
 												want:       "\"exact\"",
 											},
 											&litMatcher{
-												pos:        position{line: 1454, col: 63, offset: 42216},
+												pos:        position{line: 1636, col: 63, offset: 48935},
 												val:        "exp",
 												ignoreCase: false,
 												want:       "\"exp\"",
@@ -3536,84 +4101,84 @@ var g = &grammar{
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1454, col: 70, offset: 42223},
+									pos:  position{line: 1636, col: 70, offset: 48942},
 									name: "L_PAREN",
 								},
 								&labeledExpr{
-									pos:   position{line: 1454, col: 78, offset: 42231},
+									pos:   position{line: 1636, col: 78, offset: 48950},
 									label: "expr",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1454, col: 84, offset: 42237},
+										pos:  position{line: 1636, col: 84, offset: 48956},
 										name: "NumericExprLevel3",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1454, col: 103, offset: 42256},
+									pos:  position{line: 1636, col: 103, offset: 48975},
 									name: "R_PAREN",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 1474, col: 3, offset: 42772},
+						pos: position{line: 1656, col: 3, offset: 49491},
 						run: (*parser).callonNumericEvalExpr15,
 						expr: &seqExpr{
-							pos: position{line: 1474, col: 3, offset: 42772},
+							pos: position{line: 1656, col: 3, offset: 49491},
 							exprs: []any{
 								&labeledExpr{
-									pos:   position{line: 1474, col: 3, offset: 42772},
+									pos:   position{line: 1656, col: 3, offset: 49491},
 									label: "roundExpr",
 									expr: &litMatcher{
-										pos:        position{line: 1474, col: 13, offset: 42782},
+										pos:        position{line: 1656, col: 13, offset: 49501},
 										val:        "round",
 										ignoreCase: false,
 										want:       "\"round\"",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1474, col: 21, offset: 42790},
+									pos:  position{line: 1656, col: 21, offset: 49509},
 									name: "L_PAREN",
 								},
 								&labeledExpr{
-									pos:   position{line: 1474, col: 29, offset: 42798},
+									pos:   position{line: 1656, col: 29, offset: 49517},
 									label: "expr",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1474, col: 35, offset: 42804},
+										pos:  position{line: 1656, col: 35, offset: 49523},
 										name: "NumericExprLevel3",
 									},
 								},
 								&labeledExpr{
-									pos:   position{line: 1474, col: 54, offset: 42823},
+									pos:   position{line: 1656, col: 54, offset: 49542},
 									label: "roundPrecision",
 									expr: &zeroOrOneExpr{
-										pos: position{line: 1474, col: 69, offset: 42838},
+										pos: position{line: 1656, col: 69, offset: 49557},
 										expr: &ruleRefExpr{
-											pos:  position{line: 1474, col: 70, offset: 42839},
+											pos:  position{line: 1656, col: 70, offset: 49558},
 											name: "RoundPrecisionExpr",
 										},
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1474, col: 91, offset: 42860},
+									pos:  position{line: 1656, col: 91, offset: 49579},
 									name: "R_PAREN",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 1495, col: 3, offset: 43484},
+						pos: position{line: 1677, col: 3, offset: 50203},
 						run: (*parser).callonNumericEvalExpr26,
 						expr: &seqExpr{
-							pos: position{line: 1495, col: 3, offset: 43484},
+							pos: position{line: 1677, col: 3, offset: 50203},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 1495, col: 3, offset: 43484},
+									pos:        position{line: 1677, col: 3, offset: 50203},
 									val:        "now",
 									ignoreCase: false,
 									want:       "\"now\"",
 								},
 								&litMatcher{
-									pos:        position{line: 1495, col: 9, offset: 43490},
+									pos:        position{line: 1677, col: 9, offset: 50209},
 									val:        "()",
 									ignoreCase: false,
 									want:       "\"()\"",
@@ -3622,43 +4187,43 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 1501, col: 3, offset: 43598},
+						pos: position{line: 1683, col: 3, offset: 50317},
 						run: (*parser).callonNumericEvalExpr30,
 						expr: &seqExpr{
-							pos: position{line: 1501, col: 3, offset: 43598},
+							pos: position{line: 1683, col: 3, offset: 50317},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 1501, col: 3, offset: 43598},
+									pos:        position{line: 1683, col: 3, offset: 50317},
 									val:        "tonumber",
 									ignoreCase: false,
 									want:       "\"tonumber\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1501, col: 14, offset: 43609},
+									pos:  position{line: 1683, col: 14, offset: 50328},
 									name: "L_PAREN",
 								},
 								&labeledExpr{
-									pos:   position{line: 1501, col: 22, offset: 43617},
+									pos:   position{line: 1683, col: 22, offset: 50336},
 									label: "stringExpr",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1501, col: 33, offset: 43628},
+										pos:  position{line: 1683, col: 33, offset: 50347},
 										name: "StringExpr",
 									},
 								},
 								&labeledExpr{
-									pos:   position{line: 1501, col: 44, offset: 43639},
+									pos:   position{line: 1683, col: 44, offset: 50358},
 									label: "baseExpr",
 									expr: &zeroOrOneExpr{
-										pos: position{line: 1501, col: 53, offset: 43648},
+										pos: position{line: 1683, col: 53, offset: 50367},
 										expr: &seqExpr{
-											pos: position{line: 1501, col: 54, offset: 43649},
+											pos: position{line: 1683, col: 54, offset: 50368},
 											exprs: []any{
 												&ruleRefExpr{
-													pos:  position{line: 1501, col: 54, offset: 43649},
+													pos:  position{line: 1683, col: 54, offset: 50368},
 													name: "COMMA",
 												},
 												&ruleRefExpr{
-													pos:  position{line: 1501, col: 60, offset: 43655},
+													pos:  position{line: 1683, col: 60, offset: 50374},
 													name: "NumericExprLevel3",
 												},
 											},
@@ -3666,42 +4231,42 @@ var g = &grammar{
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1501, col: 80, offset: 43675},
+									pos:  position{line: 1683, col: 80, offset: 50394},
 									name: "R_PAREN",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 1529, col: 3, offset: 44522},
+						pos: position{line: 1711, col: 3, offset: 51241},
 						run: (*parser).callonNumericEvalExpr42,
 						expr: &seqExpr{
-							pos: position{line: 1529, col: 3, offset: 44522},
+							pos: position{line: 1711, col: 3, offset: 51241},
 							exprs: []any{
 								&labeledExpr{
-									pos:   position{line: 1529, col: 3, offset: 44522},
+									pos:   position{line: 1711, col: 3, offset: 51241},
 									label: "lenExpr",
 									expr: &litMatcher{
-										pos:        position{line: 1529, col: 12, offset: 44531},
+										pos:        position{line: 1711, col: 12, offset: 51250},
 										val:        "len",
 										ignoreCase: false,
 										want:       "\"len\"",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1529, col: 18, offset: 44537},
+									pos:  position{line: 1711, col: 18, offset: 51256},
 									name: "L_PAREN",
 								},
 								&labeledExpr{
-									pos:   position{line: 1529, col: 26, offset: 44545},
+									pos:   position{line: 1711, col: 26, offset: 51264},
 									label: "expr",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1529, col: 31, offset: 44550},
+										pos:  position{line: 1711, col: 31, offset: 51269},
 										name: "LenExpr",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1529, col: 39, offset: 44558},
+									pos:  position{line: 1711, col: 39, offset: 51277},
 									name: "R_PAREN",
 								},
 							},
@@ -3712,28 +4277,28 @@ var g = &grammar{
 		},
 		{
 			name: "LenExpr",
-			pos:  position{line: 1533, col: 1, offset: 44592},
+			pos:  position{line: 1715, col: 1, offset: 51311},
 			expr: &choiceExpr{
-				pos: position{line: 1533, col: 12, offset: 44603},
+				pos: position{line: 1715, col: 12, offset: 51322},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 1533, col: 12, offset: 44603},
+						pos: position{line: 1715, col: 12, offset: 51322},
 						run: (*parser).callonLenExpr2,
 						expr: &seqExpr{
-							pos: position{line: 1533, col: 12, offset: 44603},
+							pos: position{line: 1715, col: 12, offset: 51322},
 							exprs: []any{
 								&labeledExpr{
-									pos:   position{line: 1533, col: 12, offset: 44603},
+									pos:   position{line: 1715, col: 12, offset: 51322},
 									label: "str",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1533, col: 16, offset: 44607},
+										pos:  position{line: 1715, col: 16, offset: 51326},
 										name: "QuotedString",
 									},
 								},
 								&notExpr{
-									pos: position{line: 1533, col: 29, offset: 44620},
+									pos: position{line: 1715, col: 29, offset: 51339},
 									expr: &ruleRefExpr{
-										pos:  position{line: 1533, col: 31, offset: 44622},
+										pos:  position{line: 1715, col: 31, offset: 51341},
 										name: "EVAL_CONCAT",
 									},
 								},
@@ -3741,46 +4306,46 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 1549, col: 3, offset: 44987},
+						pos: position{line: 1731, col: 3, offset: 51706},
 						run: (*parser).callonLenExpr8,
 						expr: &seqExpr{
-							pos: position{line: 1549, col: 3, offset: 44987},
+							pos: position{line: 1731, col: 3, offset: 51706},
 							exprs: []any{
 								&labeledExpr{
-									pos:   position{line: 1549, col: 3, offset: 44987},
+									pos:   position{line: 1731, col: 3, offset: 51706},
 									label: "field",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1549, col: 9, offset: 44993},
+										pos:  position{line: 1731, col: 9, offset: 51712},
 										name: "EvalFieldToRead",
 									},
 								},
 								&notExpr{
-									pos: position{line: 1549, col: 25, offset: 45009},
+									pos: position{line: 1731, col: 25, offset: 51728},
 									expr: &choiceExpr{
-										pos: position{line: 1549, col: 27, offset: 45011},
+										pos: position{line: 1731, col: 27, offset: 51730},
 										alternatives: []any{
 											&ruleRefExpr{
-												pos:  position{line: 1549, col: 27, offset: 45011},
+												pos:  position{line: 1731, col: 27, offset: 51730},
 												name: "OpPlus",
 											},
 											&ruleRefExpr{
-												pos:  position{line: 1549, col: 36, offset: 45020},
+												pos:  position{line: 1731, col: 36, offset: 51739},
 												name: "OpMinus",
 											},
 											&ruleRefExpr{
-												pos:  position{line: 1549, col: 46, offset: 45030},
+												pos:  position{line: 1731, col: 46, offset: 51749},
 												name: "OpMul",
 											},
 											&ruleRefExpr{
-												pos:  position{line: 1549, col: 54, offset: 45038},
+												pos:  position{line: 1731, col: 54, offset: 51757},
 												name: "OpDiv",
 											},
 											&ruleRefExpr{
-												pos:  position{line: 1549, col: 62, offset: 45046},
+												pos:  position{line: 1731, col: 62, offset: 51765},
 												name: "EVAL_CONCAT",
 											},
 											&litMatcher{
-												pos:        position{line: 1549, col: 76, offset: 45060},
+												pos:        position{line: 1731, col: 76, offset: 51779},
 												val:        "(",
 												ignoreCase: false,
 												want:       "\"(\"",
@@ -3796,47 +4361,47 @@ var g = &grammar{
 		},
 		{
 			name: "HeadBlock",
-			pos:  position{line: 1567, col: 1, offset: 45452},
+			pos:  position{line: 1749, col: 1, offset: 52171},
 			expr: &choiceExpr{
-				pos: position{line: 1567, col: 14, offset: 45465},
+				pos: position{line: 1749, col: 14, offset: 52184},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 1567, col: 14, offset: 45465},
+						pos: position{line: 1749, col: 14, offset: 52184},
 						run: (*parser).callonHeadBlock2,
 						expr: &seqExpr{
-							pos: position{line: 1567, col: 14, offset: 45465},
+							pos: position{line: 1749, col: 14, offset: 52184},
 							exprs: []any{
 								&ruleRefExpr{
-									pos:  position{line: 1567, col: 14, offset: 45465},
+									pos:  position{line: 1749, col: 14, offset: 52184},
 									name: "PIPE",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1567, col: 19, offset: 45470},
+									pos:  position{line: 1749, col: 19, offset: 52189},
 									name: "CMD_HEAD",
 								},
 								&zeroOrOneExpr{
-									pos: position{line: 1567, col: 28, offset: 45479},
+									pos: position{line: 1749, col: 28, offset: 52198},
 									expr: &seqExpr{
-										pos: position{line: 1567, col: 29, offset: 45480},
+										pos: position{line: 1749, col: 29, offset: 52199},
 										exprs: []any{
 											&litMatcher{
-												pos:        position{line: 1567, col: 29, offset: 45480},
+												pos:        position{line: 1749, col: 29, offset: 52199},
 												val:        "limit",
 												ignoreCase: false,
 												want:       "\"limit\"",
 											},
 											&ruleRefExpr{
-												pos:  position{line: 1567, col: 37, offset: 45488},
+												pos:  position{line: 1749, col: 37, offset: 52207},
 												name: "EQUAL",
 											},
 										},
 									},
 								},
 								&labeledExpr{
-									pos:   position{line: 1567, col: 45, offset: 45496},
+									pos:   position{line: 1749, col: 45, offset: 52215},
 									label: "intAsStr",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1567, col: 54, offset: 45505},
+										pos:  position{line: 1749, col: 54, offset: 52224},
 										name: "IntegerAsString",
 									},
 								},
@@ -3844,17 +4409,17 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 1582, col: 3, offset: 45921},
+						pos: position{line: 1764, col: 3, offset: 52640},
 						run: (*parser).callonHeadBlock12,
 						expr: &seqExpr{
-							pos: position{line: 1582, col: 3, offset: 45921},
+							pos: position{line: 1764, col: 3, offset: 52640},
 							exprs: []any{
 								&ruleRefExpr{
-									pos:  position{line: 1582, col: 3, offset: 45921},
+									pos:  position{line: 1764, col: 3, offset: 52640},
 									name: "PIPE",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1582, col: 8, offset: 45926},
+									pos:  position{line: 1764, col: 8, offset: 52645},
 									name: "CMD_HEAD_NO_SPACE",
 								},
 							},
@@ -3865,44 +4430,44 @@ var g = &grammar{
 		},
 		{
 			name: "AggregationList",
-			pos:  position{line: 1595, col: 1, offset: 46376},
+			pos:  position{line: 1777, col: 1, offset: 53095},
 			expr: &actionExpr{
-				pos: position{line: 1595, col: 20, offset: 46395},
+				pos: position{line: 1777, col: 20, offset: 53114},
 				run: (*parser).callonAggregationList1,
 				expr: &seqExpr{
-					pos: position{line: 1595, col: 20, offset: 46395},
+					pos: position{line: 1777, col: 20, offset: 53114},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 1595, col: 20, offset: 46395},
+							pos:   position{line: 1777, col: 20, offset: 53114},
 							label: "first",
 							expr: &ruleRefExpr{
-								pos:  position{line: 1595, col: 26, offset: 46401},
+								pos:  position{line: 1777, col: 26, offset: 53120},
 								name: "Aggregator",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 1595, col: 37, offset: 46412},
+							pos:   position{line: 1777, col: 37, offset: 53131},
 							label: "rest",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 1595, col: 42, offset: 46417},
+								pos: position{line: 1777, col: 42, offset: 53136},
 								expr: &seqExpr{
-									pos: position{line: 1595, col: 43, offset: 46418},
+									pos: position{line: 1777, col: 43, offset: 53137},
 									exprs: []any{
 										&choiceExpr{
-											pos: position{line: 1595, col: 44, offset: 46419},
+											pos: position{line: 1777, col: 44, offset: 53138},
 											alternatives: []any{
 												&ruleRefExpr{
-													pos:  position{line: 1595, col: 44, offset: 46419},
+													pos:  position{line: 1777, col: 44, offset: 53138},
 													name: "COMMA",
 												},
 												&ruleRefExpr{
-													pos:  position{line: 1595, col: 52, offset: 46427},
+													pos:  position{line: 1777, col: 52, offset: 53146},
 													name: "SPACE",
 												},
 											},
 										},
 										&ruleRefExpr{
-											pos:  position{line: 1595, col: 59, offset: 46434},
+											pos:  position{line: 1777, col: 59, offset: 53153},
 											name: "Aggregator",
 										},
 									},
@@ -3915,28 +4480,28 @@ var g = &grammar{
 		},
 		{
 			name: "Aggregator",
-			pos:  position{line: 1612, col: 1, offset: 46937},
+			pos:  position{line: 1794, col: 1, offset: 53656},
 			expr: &actionExpr{
-				pos: position{line: 1612, col: 15, offset: 46951},
+				pos: position{line: 1794, col: 15, offset: 53670},
 				run: (*parser).callonAggregator1,
 				expr: &seqExpr{
-					pos: position{line: 1612, col: 15, offset: 46951},
+					pos: position{line: 1794, col: 15, offset: 53670},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 1612, col: 15, offset: 46951},
+							pos:   position{line: 1794, col: 15, offset: 53670},
 							label: "aggFunc",
 							expr: &ruleRefExpr{
-								pos:  position{line: 1612, col: 23, offset: 46959},
+								pos:  position{line: 1794, col: 23, offset: 53678},
 								name: "AggFunction",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 1612, col: 35, offset: 46971},
+							pos:   position{line: 1794, col: 35, offset: 53690},
 							label: "asField",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 1612, col: 43, offset: 46979},
+								pos: position{line: 1794, col: 43, offset: 53698},
 								expr: &ruleRefExpr{
-									pos:  position{line: 1612, col: 43, offset: 46979},
+									pos:  position{line: 1794, col: 43, offset: 53698},
 									name: "AsField",
 								},
 							},
@@ -3947,46 +4512,46 @@ var g = &grammar{
 		},
 		{
 			name: "AggFunction",
-			pos:  position{line: 1628, col: 1, offset: 47820},
+			pos:  position{line: 1810, col: 1, offset: 54539},
 			expr: &actionExpr{
-				pos: position{line: 1628, col: 16, offset: 47835},
+				pos: position{line: 1810, col: 16, offset: 54554},
 				run: (*parser).callonAggFunction1,
 				expr: &labeledExpr{
-					pos:   position{line: 1628, col: 16, offset: 47835},
+					pos:   position{line: 1810, col: 16, offset: 54554},
 					label: "agg",
 					expr: &choiceExpr{
-						pos: position{line: 1628, col: 21, offset: 47840},
+						pos: position{line: 1810, col: 21, offset: 54559},
 						alternatives: []any{
 							&ruleRefExpr{
-								pos:  position{line: 1628, col: 21, offset: 47840},
+								pos:  position{line: 1810, col: 21, offset: 54559},
 								name: "AggCount",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 1628, col: 32, offset: 47851},
+								pos:  position{line: 1810, col: 32, offset: 54570},
 								name: "AggDistinctCount",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 1628, col: 51, offset: 47870},
+								pos:  position{line: 1810, col: 51, offset: 54589},
 								name: "AggAvg",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 1628, col: 60, offset: 47879},
+								pos:  position{line: 1810, col: 60, offset: 54598},
 								name: "AggMin",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 1628, col: 69, offset: 47888},
+								pos:  position{line: 1810, col: 69, offset: 54607},
 								name: "AggMax",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 1628, col: 78, offset: 47897},
+								pos:  position{line: 1810, col: 78, offset: 54616},
 								name: "AggRange",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 1628, col: 89, offset: 47908},
+								pos:  position{line: 1810, col: 89, offset: 54627},
 								name: "AggSum",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 1628, col: 98, offset: 47917},
+								pos:  position{line: 1810, col: 98, offset: 54636},
 								name: "AggValues",
 							},
 						},
@@ -3996,22 +4561,22 @@ var g = &grammar{
 		},
 		{
 			name: "AsField",
-			pos:  position{line: 1632, col: 1, offset: 47953},
+			pos:  position{line: 1814, col: 1, offset: 54672},
 			expr: &actionExpr{
-				pos: position{line: 1632, col: 12, offset: 47964},
+				pos: position{line: 1814, col: 12, offset: 54683},
 				run: (*parser).callonAsField1,
 				expr: &seqExpr{
-					pos: position{line: 1632, col: 12, offset: 47964},
+					pos: position{line: 1814, col: 12, offset: 54683},
 					exprs: []any{
 						&ruleRefExpr{
-							pos:  position{line: 1632, col: 12, offset: 47964},
+							pos:  position{line: 1814, col: 12, offset: 54683},
 							name: "AS",
 						},
 						&labeledExpr{
-							pos:   position{line: 1632, col: 15, offset: 47967},
+							pos:   position{line: 1814, col: 15, offset: 54686},
 							label: "field",
 							expr: &ruleRefExpr{
-								pos:  position{line: 1632, col: 21, offset: 47973},
+								pos:  position{line: 1814, col: 21, offset: 54692},
 								name: "FieldName",
 							},
 						},
@@ -4021,27 +4586,27 @@ var g = &grammar{
 		},
 		{
 			name: "AggCount",
-			pos:  position{line: 1642, col: 1, offset: 48180},
+			pos:  position{line: 1824, col: 1, offset: 54899},
 			expr: &choiceExpr{
-				pos: position{line: 1642, col: 13, offset: 48192},
+				pos: position{line: 1824, col: 13, offset: 54911},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 1642, col: 13, offset: 48192},
+						pos: position{line: 1824, col: 13, offset: 54911},
 						run: (*parser).callonAggCount2,
 						expr: &seqExpr{
-							pos: position{line: 1642, col: 13, offset: 48192},
+							pos: position{line: 1824, col: 13, offset: 54911},
 							exprs: []any{
 								&choiceExpr{
-									pos: position{line: 1642, col: 14, offset: 48193},
+									pos: position{line: 1824, col: 14, offset: 54912},
 									alternatives: []any{
 										&litMatcher{
-											pos:        position{line: 1642, col: 14, offset: 48193},
+											pos:        position{line: 1824, col: 14, offset: 54912},
 											val:        "count",
 											ignoreCase: false,
 											want:       "\"count\"",
 										},
 										&litMatcher{
-											pos:        position{line: 1642, col: 24, offset: 48203},
+											pos:        position{line: 1824, col: 24, offset: 54922},
 											val:        "c",
 											ignoreCase: false,
 											want:       "\"c\"",
@@ -4049,47 +4614,47 @@ var g = &grammar{
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1642, col: 29, offset: 48208},
+									pos:  position{line: 1824, col: 29, offset: 54927},
 									name: "L_PAREN",
 								},
 								&litMatcher{
-									pos:        position{line: 1642, col: 37, offset: 48216},
+									pos:        position{line: 1824, col: 37, offset: 54935},
 									val:        "eval",
 									ignoreCase: false,
 									want:       "\"eval\"",
 								},
 								&labeledExpr{
-									pos:   position{line: 1642, col: 44, offset: 48223},
+									pos:   position{line: 1824, col: 44, offset: 54942},
 									label: "boolExpr",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1642, col: 53, offset: 48232},
+										pos:  position{line: 1824, col: 53, offset: 54951},
 										name: "BoolExpr",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1642, col: 62, offset: 48241},
+									pos:  position{line: 1824, col: 62, offset: 54960},
 									name: "R_PAREN",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 1657, col: 3, offset: 48591},
+						pos: position{line: 1839, col: 3, offset: 55310},
 						run: (*parser).callonAggCount12,
 						expr: &seqExpr{
-							pos: position{line: 1657, col: 3, offset: 48591},
+							pos: position{line: 1839, col: 3, offset: 55310},
 							exprs: []any{
 								&choiceExpr{
-									pos: position{line: 1657, col: 4, offset: 48592},
+									pos: position{line: 1839, col: 4, offset: 55311},
 									alternatives: []any{
 										&litMatcher{
-											pos:        position{line: 1657, col: 4, offset: 48592},
+											pos:        position{line: 1839, col: 4, offset: 55311},
 											val:        "count",
 											ignoreCase: false,
 											want:       "\"count\"",
 										},
 										&litMatcher{
-											pos:        position{line: 1657, col: 14, offset: 48602},
+											pos:        position{line: 1839, col: 14, offset: 55321},
 											val:        "c",
 											ignoreCase: false,
 											want:       "\"c\"",
@@ -4097,38 +4662,38 @@ var g = &grammar{
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1657, col: 19, offset: 48607},
+									pos:  position{line: 1839, col: 19, offset: 55326},
 									name: "L_PAREN",
 								},
 								&labeledExpr{
-									pos:   position{line: 1657, col: 27, offset: 48615},
+									pos:   position{line: 1839, col: 27, offset: 55334},
 									label: "field",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1657, col: 33, offset: 48621},
+										pos:  position{line: 1839, col: 33, offset: 55340},
 										name: "FieldName",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1657, col: 43, offset: 48631},
+									pos:  position{line: 1839, col: 43, offset: 55350},
 									name: "R_PAREN",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 1664, col: 5, offset: 48782},
+						pos: position{line: 1846, col: 5, offset: 55501},
 						run: (*parser).callonAggCount21,
 						expr: &choiceExpr{
-							pos: position{line: 1664, col: 6, offset: 48783},
+							pos: position{line: 1846, col: 6, offset: 55502},
 							alternatives: []any{
 								&litMatcher{
-									pos:        position{line: 1664, col: 6, offset: 48783},
+									pos:        position{line: 1846, col: 6, offset: 55502},
 									val:        "count",
 									ignoreCase: false,
 									want:       "\"count\"",
 								},
 								&litMatcher{
-									pos:        position{line: 1664, col: 16, offset: 48793},
+									pos:        position{line: 1846, col: 16, offset: 55512},
 									val:        "c",
 									ignoreCase: false,
 									want:       "\"c\"",
@@ -4141,27 +4706,27 @@ var g = &grammar{
 		},
 		{
 			name: "AggDistinctCount",
-			pos:  position{line: 1673, col: 1, offset: 48930},
+			pos:  position{line: 1855, col: 1, offset: 55649},
 			expr: &choiceExpr{
-				pos: position{line: 1673, col: 21, offset: 48950},
+				pos: position{line: 1855, col: 21, offset: 55669},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 1673, col: 21, offset: 48950},
+						pos: position{line: 1855, col: 21, offset: 55669},
 						run: (*parser).callonAggDistinctCount2,
 						expr: &seqExpr{
-							pos: position{line: 1673, col: 21, offset: 48950},
+							pos: position{line: 1855, col: 21, offset: 55669},
 							exprs: []any{
 								&choiceExpr{
-									pos: position{line: 1673, col: 22, offset: 48951},
+									pos: position{line: 1855, col: 22, offset: 55670},
 									alternatives: []any{
 										&litMatcher{
-											pos:        position{line: 1673, col: 22, offset: 48951},
+											pos:        position{line: 1855, col: 22, offset: 55670},
 											val:        "distinct_count",
 											ignoreCase: false,
 											want:       "\"distinct_count\"",
 										},
 										&litMatcher{
-											pos:        position{line: 1673, col: 41, offset: 48970},
+											pos:        position{line: 1855, col: 41, offset: 55689},
 											val:        "dc",
 											ignoreCase: false,
 											want:       "\"dc\"",
@@ -4169,47 +4734,47 @@ var g = &grammar{
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1673, col: 47, offset: 48976},
+									pos:  position{line: 1855, col: 47, offset: 55695},
 									name: "L_PAREN",
 								},
 								&litMatcher{
-									pos:        position{line: 1673, col: 55, offset: 48984},
+									pos:        position{line: 1855, col: 55, offset: 55703},
 									val:        "eval",
 									ignoreCase: false,
 									want:       "\"eval\"",
 								},
 								&labeledExpr{
-									pos:   position{line: 1673, col: 62, offset: 48991},
+									pos:   position{line: 1855, col: 62, offset: 55710},
 									label: "valueExpr",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1673, col: 72, offset: 49001},
+										pos:  position{line: 1855, col: 72, offset: 55720},
 										name: "ValueExpr",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1673, col: 82, offset: 49011},
+									pos:  position{line: 1855, col: 82, offset: 55730},
 									name: "R_PAREN",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 1683, col: 3, offset: 49245},
+						pos: position{line: 1865, col: 3, offset: 55964},
 						run: (*parser).callonAggDistinctCount12,
 						expr: &seqExpr{
-							pos: position{line: 1683, col: 3, offset: 49245},
+							pos: position{line: 1865, col: 3, offset: 55964},
 							exprs: []any{
 								&choiceExpr{
-									pos: position{line: 1683, col: 4, offset: 49246},
+									pos: position{line: 1865, col: 4, offset: 55965},
 									alternatives: []any{
 										&litMatcher{
-											pos:        position{line: 1683, col: 4, offset: 49246},
+											pos:        position{line: 1865, col: 4, offset: 55965},
 											val:        "distinct_count",
 											ignoreCase: false,
 											want:       "\"distinct_count\"",
 										},
 										&litMatcher{
-											pos:        position{line: 1683, col: 23, offset: 49265},
+											pos:        position{line: 1865, col: 23, offset: 55984},
 											val:        "dc",
 											ignoreCase: false,
 											want:       "\"dc\"",
@@ -4217,19 +4782,19 @@ var g = &grammar{
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1683, col: 29, offset: 49271},
+									pos:  position{line: 1865, col: 29, offset: 55990},
 									name: "L_PAREN",
 								},
 								&labeledExpr{
-									pos:   position{line: 1683, col: 37, offset: 49279},
+									pos:   position{line: 1865, col: 37, offset: 55998},
 									label: "field",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1683, col: 43, offset: 49285},
+										pos:  position{line: 1865, col: 43, offset: 56004},
 										name: "FieldName",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1683, col: 53, offset: 49295},
+									pos:  position{line: 1865, col: 53, offset: 56014},
 									name: "R_PAREN",
 								},
 							},
@@ -4240,81 +4805,81 @@ var g = &grammar{
 		},
 		{
 			name: "AggAvg",
-			pos:  position{line: 1692, col: 1, offset: 49451},
+			pos:  position{line: 1874, col: 1, offset: 56170},
 			expr: &choiceExpr{
-				pos: position{line: 1692, col: 11, offset: 49461},
+				pos: position{line: 1874, col: 11, offset: 56180},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 1692, col: 11, offset: 49461},
+						pos: position{line: 1874, col: 11, offset: 56180},
 						run: (*parser).callonAggAvg2,
 						expr: &seqExpr{
-							pos: position{line: 1692, col: 11, offset: 49461},
+							pos: position{line: 1874, col: 11, offset: 56180},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 1692, col: 11, offset: 49461},
+									pos:        position{line: 1874, col: 11, offset: 56180},
 									val:        "avg",
 									ignoreCase: false,
 									want:       "\"avg\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1692, col: 17, offset: 49467},
+									pos:  position{line: 1874, col: 17, offset: 56186},
 									name: "L_PAREN",
 								},
 								&litMatcher{
-									pos:        position{line: 1692, col: 25, offset: 49475},
+									pos:        position{line: 1874, col: 25, offset: 56194},
 									val:        "eval",
 									ignoreCase: false,
 									want:       "\"eval\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1692, col: 32, offset: 49482},
+									pos:  position{line: 1874, col: 32, offset: 56201},
 									name: "L_PAREN",
 								},
 								&labeledExpr{
-									pos:   position{line: 1692, col: 40, offset: 49490},
+									pos:   position{line: 1874, col: 40, offset: 56209},
 									label: "boolComparisonExpr",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1692, col: 59, offset: 49509},
+										pos:  position{line: 1874, col: 59, offset: 56228},
 										name: "BoolComparisonExpr",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1692, col: 78, offset: 49528},
+									pos:  position{line: 1874, col: 78, offset: 56247},
 									name: "R_PAREN",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1692, col: 86, offset: 49536},
+									pos:  position{line: 1874, col: 86, offset: 56255},
 									name: "R_PAREN",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 1707, col: 3, offset: 49894},
+						pos: position{line: 1889, col: 3, offset: 56613},
 						run: (*parser).callonAggAvg12,
 						expr: &seqExpr{
-							pos: position{line: 1707, col: 3, offset: 49894},
+							pos: position{line: 1889, col: 3, offset: 56613},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 1707, col: 3, offset: 49894},
+									pos:        position{line: 1889, col: 3, offset: 56613},
 									val:        "avg",
 									ignoreCase: false,
 									want:       "\"avg\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1707, col: 9, offset: 49900},
+									pos:  position{line: 1889, col: 9, offset: 56619},
 									name: "L_PAREN",
 								},
 								&labeledExpr{
-									pos:   position{line: 1707, col: 17, offset: 49908},
+									pos:   position{line: 1889, col: 17, offset: 56627},
 									label: "field",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1707, col: 23, offset: 49914},
+										pos:  position{line: 1889, col: 23, offset: 56633},
 										name: "FieldName",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1707, col: 33, offset: 49924},
+									pos:  position{line: 1889, col: 33, offset: 56643},
 									name: "R_PAREN",
 								},
 							},
@@ -4325,81 +4890,81 @@ var g = &grammar{
 		},
 		{
 			name: "AggMin",
-			pos:  position{line: 1716, col: 1, offset: 50072},
+			pos:  position{line: 1898, col: 1, offset: 56791},
 			expr: &choiceExpr{
-				pos: position{line: 1716, col: 11, offset: 50082},
+				pos: position{line: 1898, col: 11, offset: 56801},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 1716, col: 11, offset: 50082},
+						pos: position{line: 1898, col: 11, offset: 56801},
 						run: (*parser).callonAggMin2,
 						expr: &seqExpr{
-							pos: position{line: 1716, col: 11, offset: 50082},
+							pos: position{line: 1898, col: 11, offset: 56801},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 1716, col: 11, offset: 50082},
+									pos:        position{line: 1898, col: 11, offset: 56801},
 									val:        "min",
 									ignoreCase: false,
 									want:       "\"min\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1716, col: 17, offset: 50088},
+									pos:  position{line: 1898, col: 17, offset: 56807},
 									name: "L_PAREN",
 								},
 								&litMatcher{
-									pos:        position{line: 1716, col: 25, offset: 50096},
+									pos:        position{line: 1898, col: 25, offset: 56815},
 									val:        "eval",
 									ignoreCase: false,
 									want:       "\"eval\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1716, col: 32, offset: 50103},
+									pos:  position{line: 1898, col: 32, offset: 56822},
 									name: "L_PAREN",
 								},
 								&labeledExpr{
-									pos:   position{line: 1716, col: 40, offset: 50111},
+									pos:   position{line: 1898, col: 40, offset: 56830},
 									label: "boolComparisonExpr",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1716, col: 59, offset: 50130},
+										pos:  position{line: 1898, col: 59, offset: 56849},
 										name: "BoolComparisonExpr",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1716, col: 78, offset: 50149},
+									pos:  position{line: 1898, col: 78, offset: 56868},
 									name: "R_PAREN",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1716, col: 86, offset: 50157},
+									pos:  position{line: 1898, col: 86, offset: 56876},
 									name: "R_PAREN",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 1731, col: 3, offset: 50515},
+						pos: position{line: 1913, col: 3, offset: 57234},
 						run: (*parser).callonAggMin12,
 						expr: &seqExpr{
-							pos: position{line: 1731, col: 3, offset: 50515},
+							pos: position{line: 1913, col: 3, offset: 57234},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 1731, col: 3, offset: 50515},
+									pos:        position{line: 1913, col: 3, offset: 57234},
 									val:        "min",
 									ignoreCase: false,
 									want:       "\"min\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1731, col: 9, offset: 50521},
+									pos:  position{line: 1913, col: 9, offset: 57240},
 									name: "L_PAREN",
 								},
 								&labeledExpr{
-									pos:   position{line: 1731, col: 17, offset: 50529},
+									pos:   position{line: 1913, col: 17, offset: 57248},
 									label: "field",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1731, col: 23, offset: 50535},
+										pos:  position{line: 1913, col: 23, offset: 57254},
 										name: "FieldName",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1731, col: 33, offset: 50545},
+									pos:  position{line: 1913, col: 33, offset: 57264},
 									name: "R_PAREN",
 								},
 							},
@@ -4410,81 +4975,81 @@ var g = &grammar{
 		},
 		{
 			name: "AggMax",
-			pos:  position{line: 1740, col: 1, offset: 50693},
+			pos:  position{line: 1922, col: 1, offset: 57412},
 			expr: &choiceExpr{
-				pos: position{line: 1740, col: 11, offset: 50703},
+				pos: position{line: 1922, col: 11, offset: 57422},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 1740, col: 11, offset: 50703},
+						pos: position{line: 1922, col: 11, offset: 57422},
 						run: (*parser).callonAggMax2,
 						expr: &seqExpr{
-							pos: position{line: 1740, col: 11, offset: 50703},
+							pos: position{line: 1922, col: 11, offset: 57422},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 1740, col: 11, offset: 50703},
+									pos:        position{line: 1922, col: 11, offset: 57422},
 									val:        "max",
 									ignoreCase: false,
 									want:       "\"max\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1740, col: 17, offset: 50709},
+									pos:  position{line: 1922, col: 17, offset: 57428},
 									name: "L_PAREN",
 								},
 								&litMatcher{
-									pos:        position{line: 1740, col: 25, offset: 50717},
+									pos:        position{line: 1922, col: 25, offset: 57436},
 									val:        "eval",
 									ignoreCase: false,
 									want:       "\"eval\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1740, col: 32, offset: 50724},
+									pos:  position{line: 1922, col: 32, offset: 57443},
 									name: "L_PAREN",
 								},
 								&labeledExpr{
-									pos:   position{line: 1740, col: 41, offset: 50733},
+									pos:   position{line: 1922, col: 41, offset: 57452},
 									label: "boolComparisonExpr",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1740, col: 60, offset: 50752},
+										pos:  position{line: 1922, col: 60, offset: 57471},
 										name: "BoolComparisonExpr",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1740, col: 79, offset: 50771},
+									pos:  position{line: 1922, col: 79, offset: 57490},
 									name: "R_PAREN",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1740, col: 87, offset: 50779},
+									pos:  position{line: 1922, col: 87, offset: 57498},
 									name: "R_PAREN",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 1755, col: 3, offset: 51137},
+						pos: position{line: 1937, col: 3, offset: 57856},
 						run: (*parser).callonAggMax12,
 						expr: &seqExpr{
-							pos: position{line: 1755, col: 3, offset: 51137},
+							pos: position{line: 1937, col: 3, offset: 57856},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 1755, col: 3, offset: 51137},
+									pos:        position{line: 1937, col: 3, offset: 57856},
 									val:        "max",
 									ignoreCase: false,
 									want:       "\"max\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1755, col: 9, offset: 51143},
+									pos:  position{line: 1937, col: 9, offset: 57862},
 									name: "L_PAREN",
 								},
 								&labeledExpr{
-									pos:   position{line: 1755, col: 17, offset: 51151},
+									pos:   position{line: 1937, col: 17, offset: 57870},
 									label: "field",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1755, col: 23, offset: 51157},
+										pos:  position{line: 1937, col: 23, offset: 57876},
 										name: "FieldName",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1755, col: 33, offset: 51167},
+									pos:  position{line: 1937, col: 33, offset: 57886},
 									name: "R_PAREN",
 								},
 							},
@@ -4495,81 +5060,81 @@ var g = &grammar{
 		},
 		{
 			name: "AggRange",
-			pos:  position{line: 1764, col: 1, offset: 51315},
+			pos:  position{line: 1946, col: 1, offset: 58034},
 			expr: &choiceExpr{
-				pos: position{line: 1764, col: 13, offset: 51327},
+				pos: position{line: 1946, col: 13, offset: 58046},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 1764, col: 13, offset: 51327},
+						pos: position{line: 1946, col: 13, offset: 58046},
 						run: (*parser).callonAggRange2,
 						expr: &seqExpr{
-							pos: position{line: 1764, col: 13, offset: 51327},
+							pos: position{line: 1946, col: 13, offset: 58046},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 1764, col: 13, offset: 51327},
+									pos:        position{line: 1946, col: 13, offset: 58046},
 									val:        "range",
 									ignoreCase: false,
 									want:       "\"range\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1764, col: 21, offset: 51335},
+									pos:  position{line: 1946, col: 21, offset: 58054},
 									name: "L_PAREN",
 								},
 								&litMatcher{
-									pos:        position{line: 1764, col: 29, offset: 51343},
+									pos:        position{line: 1946, col: 29, offset: 58062},
 									val:        "eval",
 									ignoreCase: false,
 									want:       "\"eval\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1764, col: 36, offset: 51350},
+									pos:  position{line: 1946, col: 36, offset: 58069},
 									name: "L_PAREN",
 								},
 								&labeledExpr{
-									pos:   position{line: 1764, col: 44, offset: 51358},
+									pos:   position{line: 1946, col: 44, offset: 58077},
 									label: "boolComparisonExpr",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1764, col: 63, offset: 51377},
+										pos:  position{line: 1946, col: 63, offset: 58096},
 										name: "BoolComparisonExpr",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1764, col: 82, offset: 51396},
+									pos:  position{line: 1946, col: 82, offset: 58115},
 									name: "R_PAREN",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1764, col: 90, offset: 51404},
+									pos:  position{line: 1946, col: 90, offset: 58123},
 									name: "R_PAREN",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 1779, col: 3, offset: 51764},
+						pos: position{line: 1961, col: 3, offset: 58483},
 						run: (*parser).callonAggRange12,
 						expr: &seqExpr{
-							pos: position{line: 1779, col: 3, offset: 51764},
+							pos: position{line: 1961, col: 3, offset: 58483},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 1779, col: 3, offset: 51764},
+									pos:        position{line: 1961, col: 3, offset: 58483},
 									val:        "range",
 									ignoreCase: false,
 									want:       "\"range\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1779, col: 11, offset: 51772},
+									pos:  position{line: 1961, col: 11, offset: 58491},
 									name: "L_PAREN",
 								},
 								&labeledExpr{
-									pos:   position{line: 1779, col: 19, offset: 51780},
+									pos:   position{line: 1961, col: 19, offset: 58499},
 									label: "field",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1779, col: 25, offset: 51786},
+										pos:  position{line: 1961, col: 25, offset: 58505},
 										name: "FieldName",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1779, col: 35, offset: 51796},
+									pos:  position{line: 1961, col: 35, offset: 58515},
 									name: "R_PAREN",
 								},
 							},
@@ -4580,81 +5145,81 @@ var g = &grammar{
 		},
 		{
 			name: "AggSum",
-			pos:  position{line: 1788, col: 1, offset: 51946},
+			pos:  position{line: 1970, col: 1, offset: 58665},
 			expr: &choiceExpr{
-				pos: position{line: 1788, col: 11, offset: 51956},
+				pos: position{line: 1970, col: 11, offset: 58675},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 1788, col: 11, offset: 51956},
+						pos: position{line: 1970, col: 11, offset: 58675},
 						run: (*parser).callonAggSum2,
 						expr: &seqExpr{
-							pos: position{line: 1788, col: 11, offset: 51956},
+							pos: position{line: 1970, col: 11, offset: 58675},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 1788, col: 11, offset: 51956},
+									pos:        position{line: 1970, col: 11, offset: 58675},
 									val:        "sum",
 									ignoreCase: false,
 									want:       "\"sum\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1788, col: 17, offset: 51962},
+									pos:  position{line: 1970, col: 17, offset: 58681},
 									name: "L_PAREN",
 								},
 								&litMatcher{
-									pos:        position{line: 1788, col: 25, offset: 51970},
+									pos:        position{line: 1970, col: 25, offset: 58689},
 									val:        "eval",
 									ignoreCase: false,
 									want:       "\"eval\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1788, col: 32, offset: 51977},
+									pos:  position{line: 1970, col: 32, offset: 58696},
 									name: "L_PAREN",
 								},
 								&labeledExpr{
-									pos:   position{line: 1788, col: 40, offset: 51985},
+									pos:   position{line: 1970, col: 40, offset: 58704},
 									label: "boolComparisonExpr",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1788, col: 59, offset: 52004},
+										pos:  position{line: 1970, col: 59, offset: 58723},
 										name: "BoolComparisonExpr",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1788, col: 78, offset: 52023},
+									pos:  position{line: 1970, col: 78, offset: 58742},
 									name: "R_PAREN",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1788, col: 86, offset: 52031},
+									pos:  position{line: 1970, col: 86, offset: 58750},
 									name: "R_PAREN",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 1803, col: 3, offset: 52389},
+						pos: position{line: 1985, col: 3, offset: 59108},
 						run: (*parser).callonAggSum12,
 						expr: &seqExpr{
-							pos: position{line: 1803, col: 3, offset: 52389},
+							pos: position{line: 1985, col: 3, offset: 59108},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 1803, col: 3, offset: 52389},
+									pos:        position{line: 1985, col: 3, offset: 59108},
 									val:        "sum",
 									ignoreCase: false,
 									want:       "\"sum\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1803, col: 9, offset: 52395},
+									pos:  position{line: 1985, col: 9, offset: 59114},
 									name: "L_PAREN",
 								},
 								&labeledExpr{
-									pos:   position{line: 1803, col: 17, offset: 52403},
+									pos:   position{line: 1985, col: 17, offset: 59122},
 									label: "field",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1803, col: 23, offset: 52409},
+										pos:  position{line: 1985, col: 23, offset: 59128},
 										name: "FieldName",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1803, col: 33, offset: 52419},
+									pos:  position{line: 1985, col: 33, offset: 59138},
 									name: "R_PAREN",
 								},
 							},
@@ -4665,73 +5230,73 @@ var g = &grammar{
 		},
 		{
 			name: "AggValues",
-			pos:  position{line: 1812, col: 1, offset: 52567},
+			pos:  position{line: 1994, col: 1, offset: 59286},
 			expr: &choiceExpr{
-				pos: position{line: 1812, col: 14, offset: 52580},
+				pos: position{line: 1994, col: 14, offset: 59299},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 1812, col: 14, offset: 52580},
+						pos: position{line: 1994, col: 14, offset: 59299},
 						run: (*parser).callonAggValues2,
 						expr: &seqExpr{
-							pos: position{line: 1812, col: 14, offset: 52580},
+							pos: position{line: 1994, col: 14, offset: 59299},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 1812, col: 14, offset: 52580},
+									pos:        position{line: 1994, col: 14, offset: 59299},
 									val:        "values",
 									ignoreCase: false,
 									want:       "\"values\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1812, col: 23, offset: 52589},
+									pos:  position{line: 1994, col: 23, offset: 59308},
 									name: "L_PAREN",
 								},
 								&litMatcher{
-									pos:        position{line: 1812, col: 31, offset: 52597},
+									pos:        position{line: 1994, col: 31, offset: 59316},
 									val:        "eval",
 									ignoreCase: false,
 									want:       "\"eval\"",
 								},
 								&labeledExpr{
-									pos:   position{line: 1812, col: 38, offset: 52604},
+									pos:   position{line: 1994, col: 38, offset: 59323},
 									label: "valueExpr",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1812, col: 48, offset: 52614},
+										pos:  position{line: 1994, col: 48, offset: 59333},
 										name: "ValueExpr",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1812, col: 58, offset: 52624},
+									pos:  position{line: 1994, col: 58, offset: 59343},
 									name: "R_PAREN",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 1822, col: 3, offset: 52853},
+						pos: position{line: 2004, col: 3, offset: 59572},
 						run: (*parser).callonAggValues10,
 						expr: &seqExpr{
-							pos: position{line: 1822, col: 3, offset: 52853},
+							pos: position{line: 2004, col: 3, offset: 59572},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 1822, col: 3, offset: 52853},
+									pos:        position{line: 2004, col: 3, offset: 59572},
 									val:        "values",
 									ignoreCase: false,
 									want:       "\"values\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1822, col: 12, offset: 52862},
+									pos:  position{line: 2004, col: 12, offset: 59581},
 									name: "L_PAREN",
 								},
 								&labeledExpr{
-									pos:   position{line: 1822, col: 20, offset: 52870},
+									pos:   position{line: 2004, col: 20, offset: 59589},
 									label: "field",
 									expr: &ruleRefExpr{
-										pos:  position{line: 1822, col: 26, offset: 52876},
+										pos:  position{line: 2004, col: 26, offset: 59595},
 										name: "FieldName",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 1822, col: 36, offset: 52886},
+									pos:  position{line: 2004, col: 36, offset: 59605},
 									name: "R_PAREN",
 								},
 							},
@@ -4742,22 +5307,22 @@ var g = &grammar{
 		},
 		{
 			name: "FieldWithNumberValue",
-			pos:  position{line: 1831, col: 1, offset: 53037},
+			pos:  position{line: 2013, col: 1, offset: 59756},
 			expr: &actionExpr{
-				pos: position{line: 1831, col: 25, offset: 53061},
+				pos: position{line: 2013, col: 25, offset: 59780},
 				run: (*parser).callonFieldWithNumberValue1,
 				expr: &labeledExpr{
-					pos:   position{line: 1831, col: 25, offset: 53061},
+					pos:   position{line: 2013, col: 25, offset: 59780},
 					label: "keyValuePair",
 					expr: &choiceExpr{
-						pos: position{line: 1831, col: 39, offset: 53075},
+						pos: position{line: 2013, col: 39, offset: 59794},
 						alternatives: []any{
 							&ruleRefExpr{
-								pos:  position{line: 1831, col: 39, offset: 53075},
+								pos:  position{line: 2013, col: 39, offset: 59794},
 								name: "NamedFieldWithNumberValue",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 1831, col: 67, offset: 53103},
+								pos:  position{line: 2013, col: 67, offset: 59822},
 								name: "UnnamedFieldWithNumberValue",
 							},
 						},
@@ -4767,43 +5332,43 @@ var g = &grammar{
 		},
 		{
 			name: "NamedFieldWithNumberValue",
-			pos:  position{line: 1835, col: 1, offset: 53166},
+			pos:  position{line: 2017, col: 1, offset: 59885},
 			expr: &actionExpr{
-				pos: position{line: 1835, col: 30, offset: 53195},
+				pos: position{line: 2017, col: 30, offset: 59914},
 				run: (*parser).callonNamedFieldWithNumberValue1,
 				expr: &seqExpr{
-					pos: position{line: 1835, col: 30, offset: 53195},
+					pos: position{line: 2017, col: 30, offset: 59914},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 1835, col: 30, offset: 53195},
+							pos:   position{line: 2017, col: 30, offset: 59914},
 							label: "key",
 							expr: &ruleRefExpr{
-								pos:  position{line: 1835, col: 34, offset: 53199},
+								pos:  position{line: 2017, col: 34, offset: 59918},
 								name: "FieldName",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 1835, col: 44, offset: 53209},
+							pos:   position{line: 2017, col: 44, offset: 59928},
 							label: "op",
 							expr: &choiceExpr{
-								pos: position{line: 1835, col: 48, offset: 53213},
+								pos: position{line: 2017, col: 48, offset: 59932},
 								alternatives: []any{
 									&ruleRefExpr{
-										pos:  position{line: 1835, col: 48, offset: 53213},
+										pos:  position{line: 2017, col: 48, offset: 59932},
 										name: "EqualityOperator",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 1835, col: 67, offset: 53232},
+										pos:  position{line: 2017, col: 67, offset: 59951},
 										name: "InequalityOperator",
 									},
 								},
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 1835, col: 87, offset: 53252},
+							pos:   position{line: 2017, col: 87, offset: 59971},
 							label: "value",
 							expr: &ruleRefExpr{
-								pos:  position{line: 1835, col: 93, offset: 53258},
+								pos:  position{line: 2017, col: 93, offset: 59977},
 								name: "Number",
 							},
 						},
@@ -4813,15 +5378,15 @@ var g = &grammar{
 		},
 		{
 			name: "UnnamedFieldWithNumberValue",
-			pos:  position{line: 1848, col: 1, offset: 53492},
+			pos:  position{line: 2030, col: 1, offset: 60211},
 			expr: &actionExpr{
-				pos: position{line: 1848, col: 32, offset: 53523},
+				pos: position{line: 2030, col: 32, offset: 60242},
 				run: (*parser).callonUnnamedFieldWithNumberValue1,
 				expr: &labeledExpr{
-					pos:   position{line: 1848, col: 32, offset: 53523},
+					pos:   position{line: 2030, col: 32, offset: 60242},
 					label: "value",
 					expr: &ruleRefExpr{
-						pos:  position{line: 1848, col: 38, offset: 53529},
+						pos:  position{line: 2030, col: 38, offset: 60248},
 						name: "Number",
 					},
 				},
@@ -4829,22 +5394,22 @@ var g = &grammar{
 		},
 		{
 			name: "FieldWithStringValue",
-			pos:  position{line: 1861, col: 1, offset: 53746},
+			pos:  position{line: 2043, col: 1, offset: 60465},
 			expr: &actionExpr{
-				pos: position{line: 1861, col: 25, offset: 53770},
+				pos: position{line: 2043, col: 25, offset: 60489},
 				run: (*parser).callonFieldWithStringValue1,
 				expr: &labeledExpr{
-					pos:   position{line: 1861, col: 25, offset: 53770},
+					pos:   position{line: 2043, col: 25, offset: 60489},
 					label: "keyValuePair",
 					expr: &choiceExpr{
-						pos: position{line: 1861, col: 39, offset: 53784},
+						pos: position{line: 2043, col: 39, offset: 60503},
 						alternatives: []any{
 							&ruleRefExpr{
-								pos:  position{line: 1861, col: 39, offset: 53784},
+								pos:  position{line: 2043, col: 39, offset: 60503},
 								name: "NamedFieldWithStringValue",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 1861, col: 67, offset: 53812},
+								pos:  position{line: 2043, col: 67, offset: 60531},
 								name: "UnnamedFieldWithStringValue",
 							},
 						},
@@ -4854,34 +5419,34 @@ var g = &grammar{
 		},
 		{
 			name: "NamedFieldWithStringValue",
-			pos:  position{line: 1865, col: 1, offset: 53875},
+			pos:  position{line: 2047, col: 1, offset: 60594},
 			expr: &actionExpr{
-				pos: position{line: 1865, col: 30, offset: 53904},
+				pos: position{line: 2047, col: 30, offset: 60623},
 				run: (*parser).callonNamedFieldWithStringValue1,
 				expr: &seqExpr{
-					pos: position{line: 1865, col: 30, offset: 53904},
+					pos: position{line: 2047, col: 30, offset: 60623},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 1865, col: 30, offset: 53904},
+							pos:   position{line: 2047, col: 30, offset: 60623},
 							label: "key",
 							expr: &ruleRefExpr{
-								pos:  position{line: 1865, col: 34, offset: 53908},
+								pos:  position{line: 2047, col: 34, offset: 60627},
 								name: "FieldName",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 1865, col: 44, offset: 53918},
+							pos:   position{line: 2047, col: 44, offset: 60637},
 							label: "op",
 							expr: &ruleRefExpr{
-								pos:  position{line: 1865, col: 47, offset: 53921},
+								pos:  position{line: 2047, col: 47, offset: 60640},
 								name: "EqualityOperator",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 1865, col: 64, offset: 53938},
+							pos:   position{line: 2047, col: 64, offset: 60657},
 							label: "value",
 							expr: &ruleRefExpr{
-								pos:  position{line: 1865, col: 70, offset: 53944},
+								pos:  position{line: 2047, col: 70, offset: 60663},
 								name: "String",
 							},
 						},
@@ -4891,15 +5456,15 @@ var g = &grammar{
 		},
 		{
 			name: "UnnamedFieldWithStringValue",
-			pos:  position{line: 1877, col: 1, offset: 54177},
+			pos:  position{line: 2059, col: 1, offset: 60896},
 			expr: &actionExpr{
-				pos: position{line: 1877, col: 32, offset: 54208},
+				pos: position{line: 2059, col: 32, offset: 60927},
 				run: (*parser).callonUnnamedFieldWithStringValue1,
 				expr: &labeledExpr{
-					pos:   position{line: 1877, col: 32, offset: 54208},
+					pos:   position{line: 2059, col: 32, offset: 60927},
 					label: "value",
 					expr: &ruleRefExpr{
-						pos:  position{line: 1877, col: 38, offset: 54214},
+						pos:  position{line: 2059, col: 38, offset: 60933},
 						name: "String",
 					},
 				},
@@ -4907,35 +5472,35 @@ var g = &grammar{
 		},
 		{
 			name: "FieldNameList",
-			pos:  position{line: 1891, col: 1, offset: 54545},
+			pos:  position{line: 2073, col: 1, offset: 61264},
 			expr: &actionExpr{
-				pos: position{line: 1891, col: 18, offset: 54562},
+				pos: position{line: 2073, col: 18, offset: 61281},
 				run: (*parser).callonFieldNameList1,
 				expr: &seqExpr{
-					pos: position{line: 1891, col: 18, offset: 54562},
+					pos: position{line: 2073, col: 18, offset: 61281},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 1891, col: 18, offset: 54562},
+							pos:   position{line: 2073, col: 18, offset: 61281},
 							label: "first",
 							expr: &ruleRefExpr{
-								pos:  position{line: 1891, col: 24, offset: 54568},
+								pos:  position{line: 2073, col: 24, offset: 61287},
 								name: "FieldName",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 1891, col: 34, offset: 54578},
+							pos:   position{line: 2073, col: 34, offset: 61297},
 							label: "rest",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 1891, col: 39, offset: 54583},
+								pos: position{line: 2073, col: 39, offset: 61302},
 								expr: &seqExpr{
-									pos: position{line: 1891, col: 40, offset: 54584},
+									pos: position{line: 2073, col: 40, offset: 61303},
 									exprs: []any{
 										&ruleRefExpr{
-											pos:  position{line: 1891, col: 40, offset: 54584},
+											pos:  position{line: 2073, col: 40, offset: 61303},
 											name: "COMMA",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 1891, col: 46, offset: 54590},
+											pos:  position{line: 2073, col: 46, offset: 61309},
 											name: "FieldName",
 										},
 									},
@@ -4948,15 +5513,15 @@ var g = &grammar{
 		},
 		{
 			name: "FieldName",
-			pos:  position{line: 1911, col: 1, offset: 55353},
+			pos:  position{line: 2093, col: 1, offset: 62072},
 			expr: &actionExpr{
-				pos: position{line: 1911, col: 14, offset: 55366},
+				pos: position{line: 2093, col: 14, offset: 62085},
 				run: (*parser).callonFieldName1,
 				expr: &seqExpr{
-					pos: position{line: 1911, col: 14, offset: 55366},
+					pos: position{line: 2093, col: 14, offset: 62085},
 					exprs: []any{
 						&charClassMatcher{
-							pos:        position{line: 1911, col: 14, offset: 55366},
+							pos:        position{line: 2093, col: 14, offset: 62085},
 							val:        "[a-zA-Z0-9:*]",
 							chars:      []rune{':', '*'},
 							ranges:     []rune{'a', 'z', 'A', 'Z', '0', '9'},
@@ -4964,9 +5529,9 @@ var g = &grammar{
 							inverted:   false,
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 1911, col: 27, offset: 55379},
+							pos: position{line: 2093, col: 27, offset: 62098},
 							expr: &charClassMatcher{
-								pos:        position{line: 1911, col: 27, offset: 55379},
+								pos:        position{line: 2093, col: 27, offset: 62098},
 								val:        "[a-zA-Z0-9:_.*]",
 								chars:      []rune{':', '_', '.', '*'},
 								ranges:     []rune{'a', 'z', 'A', 'Z', '0', '9'},
@@ -4980,22 +5545,22 @@ var g = &grammar{
 		},
 		{
 			name: "String",
-			pos:  position{line: 1915, col: 1, offset: 55432},
+			pos:  position{line: 2097, col: 1, offset: 62151},
 			expr: &actionExpr{
-				pos: position{line: 1915, col: 11, offset: 55442},
+				pos: position{line: 2097, col: 11, offset: 62161},
 				run: (*parser).callonString1,
 				expr: &labeledExpr{
-					pos:   position{line: 1915, col: 11, offset: 55442},
+					pos:   position{line: 2097, col: 11, offset: 62161},
 					label: "str",
 					expr: &choiceExpr{
-						pos: position{line: 1915, col: 16, offset: 55447},
+						pos: position{line: 2097, col: 16, offset: 62166},
 						alternatives: []any{
 							&ruleRefExpr{
-								pos:  position{line: 1915, col: 16, offset: 55447},
+								pos:  position{line: 2097, col: 16, offset: 62166},
 								name: "QuotedString",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 1915, col: 31, offset: 55462},
+								pos:  position{line: 2097, col: 31, offset: 62181},
 								name: "UnquotedString",
 							},
 						},
@@ -5005,23 +5570,23 @@ var g = &grammar{
 		},
 		{
 			name: "QuotedString",
-			pos:  position{line: 1919, col: 1, offset: 55503},
+			pos:  position{line: 2101, col: 1, offset: 62222},
 			expr: &actionExpr{
-				pos: position{line: 1919, col: 17, offset: 55519},
+				pos: position{line: 2101, col: 17, offset: 62238},
 				run: (*parser).callonQuotedString1,
 				expr: &seqExpr{
-					pos: position{line: 1919, col: 17, offset: 55519},
+					pos: position{line: 2101, col: 17, offset: 62238},
 					exprs: []any{
 						&litMatcher{
-							pos:        position{line: 1919, col: 17, offset: 55519},
+							pos:        position{line: 2101, col: 17, offset: 62238},
 							val:        "\"",
 							ignoreCase: false,
 							want:       "\"\\\"\"",
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 1919, col: 21, offset: 55523},
+							pos: position{line: 2101, col: 21, offset: 62242},
 							expr: &charClassMatcher{
-								pos:        position{line: 1919, col: 21, offset: 55523},
+								pos:        position{line: 2101, col: 21, offset: 62242},
 								val:        "[^\"]",
 								chars:      []rune{'"'},
 								ignoreCase: false,
@@ -5029,7 +5594,7 @@ var g = &grammar{
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 1919, col: 27, offset: 55529},
+							pos:        position{line: 2101, col: 27, offset: 62248},
 							val:        "\"",
 							ignoreCase: false,
 							want:       "\"\\\"\"",
@@ -5040,42 +5605,42 @@ var g = &grammar{
 		},
 		{
 			name: "UnquotedString",
-			pos:  position{line: 1924, col: 1, offset: 55640},
+			pos:  position{line: 2106, col: 1, offset: 62359},
 			expr: &actionExpr{
-				pos: position{line: 1924, col: 19, offset: 55658},
+				pos: position{line: 2106, col: 19, offset: 62377},
 				run: (*parser).callonUnquotedString1,
 				expr: &oneOrMoreExpr{
-					pos: position{line: 1924, col: 19, offset: 55658},
+					pos: position{line: 2106, col: 19, offset: 62377},
 					expr: &choiceExpr{
-						pos: position{line: 1924, col: 20, offset: 55659},
+						pos: position{line: 2106, col: 20, offset: 62378},
 						alternatives: []any{
 							&litMatcher{
-								pos:        position{line: 1924, col: 20, offset: 55659},
+								pos:        position{line: 2106, col: 20, offset: 62378},
 								val:        "*",
 								ignoreCase: false,
 								want:       "\"*\"",
 							},
 							&seqExpr{
-								pos: position{line: 1924, col: 27, offset: 55666},
+								pos: position{line: 2106, col: 27, offset: 62385},
 								exprs: []any{
 									&notExpr{
-										pos: position{line: 1924, col: 27, offset: 55666},
+										pos: position{line: 2106, col: 27, offset: 62385},
 										expr: &choiceExpr{
-											pos: position{line: 1924, col: 29, offset: 55668},
+											pos: position{line: 2106, col: 29, offset: 62387},
 											alternatives: []any{
 												&ruleRefExpr{
-													pos:  position{line: 1924, col: 29, offset: 55668},
+													pos:  position{line: 2106, col: 29, offset: 62387},
 													name: "MAJOR_BREAK",
 												},
 												&ruleRefExpr{
-													pos:  position{line: 1924, col: 43, offset: 55682},
+													pos:  position{line: 2106, col: 43, offset: 62401},
 													name: "EOF",
 												},
 											},
 										},
 									},
 									&anyMatcher{
-										line: 1924, col: 48, offset: 55687,
+										line: 2106, col: 48, offset: 62406,
 									},
 								},
 							},
@@ -5086,14 +5651,14 @@ var g = &grammar{
 		},
 		{
 			name: "RenamePattern",
-			pos:  position{line: 1931, col: 1, offset: 55861},
+			pos:  position{line: 2113, col: 1, offset: 62580},
 			expr: &actionExpr{
-				pos: position{line: 1931, col: 18, offset: 55878},
+				pos: position{line: 2113, col: 18, offset: 62597},
 				run: (*parser).callonRenamePattern1,
 				expr: &oneOrMoreExpr{
-					pos: position{line: 1931, col: 18, offset: 55878},
+					pos: position{line: 2113, col: 18, offset: 62597},
 					expr: &charClassMatcher{
-						pos:        position{line: 1931, col: 18, offset: 55878},
+						pos:        position{line: 2113, col: 18, offset: 62597},
 						val:        "[a-zA-Z0-9_*]",
 						chars:      []rune{'_', '*'},
 						ranges:     []rune{'a', 'z', 'A', 'Z', '0', '9'},
@@ -5105,15 +5670,15 @@ var g = &grammar{
 		},
 		{
 			name: "Number",
-			pos:  position{line: 1935, col: 1, offset: 55929},
+			pos:  position{line: 2117, col: 1, offset: 62648},
 			expr: &actionExpr{
-				pos: position{line: 1935, col: 11, offset: 55939},
+				pos: position{line: 2117, col: 11, offset: 62658},
 				run: (*parser).callonNumber1,
 				expr: &labeledExpr{
-					pos:   position{line: 1935, col: 11, offset: 55939},
+					pos:   position{line: 2117, col: 11, offset: 62658},
 					label: "number",
 					expr: &ruleRefExpr{
-						pos:  position{line: 1935, col: 18, offset: 55946},
+						pos:  position{line: 2117, col: 18, offset: 62665},
 						name: "NumberAsString",
 					},
 				},
@@ -5121,53 +5686,53 @@ var g = &grammar{
 		},
 		{
 			name: "NumberAsString",
-			pos:  position{line: 1941, col: 1, offset: 56135},
+			pos:  position{line: 2123, col: 1, offset: 62854},
 			expr: &actionExpr{
-				pos: position{line: 1941, col: 19, offset: 56153},
+				pos: position{line: 2123, col: 19, offset: 62872},
 				run: (*parser).callonNumberAsString1,
 				expr: &seqExpr{
-					pos: position{line: 1941, col: 19, offset: 56153},
+					pos: position{line: 2123, col: 19, offset: 62872},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 1941, col: 19, offset: 56153},
+							pos:   position{line: 2123, col: 19, offset: 62872},
 							label: "number",
 							expr: &choiceExpr{
-								pos: position{line: 1941, col: 27, offset: 56161},
+								pos: position{line: 2123, col: 27, offset: 62880},
 								alternatives: []any{
 									&ruleRefExpr{
-										pos:  position{line: 1941, col: 27, offset: 56161},
+										pos:  position{line: 2123, col: 27, offset: 62880},
 										name: "FloatAsString",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 1941, col: 43, offset: 56177},
+										pos:  position{line: 2123, col: 43, offset: 62896},
 										name: "IntegerAsString",
 									},
 								},
 							},
 						},
 						&andExpr{
-							pos: position{line: 1941, col: 60, offset: 56194},
+							pos: position{line: 2123, col: 60, offset: 62913},
 							expr: &choiceExpr{
-								pos: position{line: 1941, col: 62, offset: 56196},
+								pos: position{line: 2123, col: 62, offset: 62915},
 								alternatives: []any{
 									&ruleRefExpr{
-										pos:  position{line: 1941, col: 62, offset: 56196},
+										pos:  position{line: 2123, col: 62, offset: 62915},
 										name: "SPACE",
 									},
 									&litMatcher{
-										pos:        position{line: 1941, col: 70, offset: 56204},
+										pos:        position{line: 2123, col: 70, offset: 62923},
 										val:        ")",
 										ignoreCase: false,
 										want:       "\")\"",
 									},
 									&litMatcher{
-										pos:        position{line: 1941, col: 76, offset: 56210},
+										pos:        position{line: 2123, col: 76, offset: 62929},
 										val:        ",",
 										ignoreCase: false,
 										want:       "\",\"",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 1941, col: 82, offset: 56216},
+										pos:  position{line: 2123, col: 82, offset: 62935},
 										name: "EOF",
 									},
 								},
@@ -5179,17 +5744,17 @@ var g = &grammar{
 		},
 		{
 			name: "FloatAsString",
-			pos:  position{line: 1947, col: 1, offset: 56345},
+			pos:  position{line: 2129, col: 1, offset: 63064},
 			expr: &actionExpr{
-				pos: position{line: 1947, col: 18, offset: 56362},
+				pos: position{line: 2129, col: 18, offset: 63081},
 				run: (*parser).callonFloatAsString1,
 				expr: &seqExpr{
-					pos: position{line: 1947, col: 18, offset: 56362},
+					pos: position{line: 2129, col: 18, offset: 63081},
 					exprs: []any{
 						&zeroOrOneExpr{
-							pos: position{line: 1947, col: 18, offset: 56362},
+							pos: position{line: 2129, col: 18, offset: 63081},
 							expr: &charClassMatcher{
-								pos:        position{line: 1947, col: 18, offset: 56362},
+								pos:        position{line: 2129, col: 18, offset: 63081},
 								val:        "[-+]",
 								chars:      []rune{'-', '+'},
 								ignoreCase: false,
@@ -5197,9 +5762,9 @@ var g = &grammar{
 							},
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 1947, col: 24, offset: 56368},
+							pos: position{line: 2129, col: 24, offset: 63087},
 							expr: &charClassMatcher{
-								pos:        position{line: 1947, col: 24, offset: 56368},
+								pos:        position{line: 2129, col: 24, offset: 63087},
 								val:        "[0-9]",
 								ranges:     []rune{'0', '9'},
 								ignoreCase: false,
@@ -5207,15 +5772,15 @@ var g = &grammar{
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 1947, col: 31, offset: 56375},
+							pos:        position{line: 2129, col: 31, offset: 63094},
 							val:        ".",
 							ignoreCase: false,
 							want:       "\".\"",
 						},
 						&oneOrMoreExpr{
-							pos: position{line: 1947, col: 35, offset: 56379},
+							pos: position{line: 2129, col: 35, offset: 63098},
 							expr: &charClassMatcher{
-								pos:        position{line: 1947, col: 35, offset: 56379},
+								pos:        position{line: 2129, col: 35, offset: 63098},
 								val:        "[0-9]",
 								ranges:     []rune{'0', '9'},
 								ignoreCase: false,
@@ -5228,17 +5793,17 @@ var g = &grammar{
 		},
 		{
 			name: "IntegerAsString",
-			pos:  position{line: 1952, col: 1, offset: 56474},
+			pos:  position{line: 2134, col: 1, offset: 63193},
 			expr: &actionExpr{
-				pos: position{line: 1952, col: 20, offset: 56493},
+				pos: position{line: 2134, col: 20, offset: 63212},
 				run: (*parser).callonIntegerAsString1,
 				expr: &seqExpr{
-					pos: position{line: 1952, col: 20, offset: 56493},
+					pos: position{line: 2134, col: 20, offset: 63212},
 					exprs: []any{
 						&zeroOrOneExpr{
-							pos: position{line: 1952, col: 20, offset: 56493},
+							pos: position{line: 2134, col: 20, offset: 63212},
 							expr: &charClassMatcher{
-								pos:        position{line: 1952, col: 20, offset: 56493},
+								pos:        position{line: 2134, col: 20, offset: 63212},
 								val:        "[-+]",
 								chars:      []rune{'-', '+'},
 								ignoreCase: false,
@@ -5246,9 +5811,9 @@ var g = &grammar{
 							},
 						},
 						&oneOrMoreExpr{
-							pos: position{line: 1952, col: 26, offset: 56499},
+							pos: position{line: 2134, col: 26, offset: 63218},
 							expr: &charClassMatcher{
-								pos:        position{line: 1952, col: 26, offset: 56499},
+								pos:        position{line: 2134, col: 26, offset: 63218},
 								val:        "[0-9]",
 								ranges:     []rune{'0', '9'},
 								ignoreCase: false,
@@ -5261,31 +5826,31 @@ var g = &grammar{
 		},
 		{
 			name: "EqualityOperator",
-			pos:  position{line: 1956, col: 1, offset: 56542},
+			pos:  position{line: 2138, col: 1, offset: 63261},
 			expr: &actionExpr{
-				pos: position{line: 1956, col: 21, offset: 56562},
+				pos: position{line: 2138, col: 21, offset: 63281},
 				run: (*parser).callonEqualityOperator1,
 				expr: &seqExpr{
-					pos: position{line: 1956, col: 21, offset: 56562},
+					pos: position{line: 2138, col: 21, offset: 63281},
 					exprs: []any{
 						&ruleRefExpr{
-							pos:  position{line: 1956, col: 21, offset: 56562},
+							pos:  position{line: 2138, col: 21, offset: 63281},
 							name: "EMPTY_OR_SPACE",
 						},
 						&labeledExpr{
-							pos:   position{line: 1956, col: 36, offset: 56577},
+							pos:   position{line: 2138, col: 36, offset: 63296},
 							label: "op",
 							expr: &choiceExpr{
-								pos: position{line: 1956, col: 40, offset: 56581},
+								pos: position{line: 2138, col: 40, offset: 63300},
 								alternatives: []any{
 									&litMatcher{
-										pos:        position{line: 1956, col: 40, offset: 56581},
+										pos:        position{line: 2138, col: 40, offset: 63300},
 										val:        "=",
 										ignoreCase: false,
 										want:       "\"=\"",
 									},
 									&litMatcher{
-										pos:        position{line: 1956, col: 46, offset: 56587},
+										pos:        position{line: 2138, col: 46, offset: 63306},
 										val:        "!=",
 										ignoreCase: false,
 										want:       "\"!=\"",
@@ -5294,7 +5859,7 @@ var g = &grammar{
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 1956, col: 52, offset: 56593},
+							pos:  position{line: 2138, col: 52, offset: 63312},
 							name: "EMPTY_OR_SPACE",
 						},
 					},
@@ -5303,43 +5868,43 @@ var g = &grammar{
 		},
 		{
 			name: "InequalityOperator",
-			pos:  position{line: 1964, col: 1, offset: 56774},
+			pos:  position{line: 2146, col: 1, offset: 63493},
 			expr: &actionExpr{
-				pos: position{line: 1964, col: 23, offset: 56796},
+				pos: position{line: 2146, col: 23, offset: 63515},
 				run: (*parser).callonInequalityOperator1,
 				expr: &seqExpr{
-					pos: position{line: 1964, col: 23, offset: 56796},
+					pos: position{line: 2146, col: 23, offset: 63515},
 					exprs: []any{
 						&ruleRefExpr{
-							pos:  position{line: 1964, col: 23, offset: 56796},
+							pos:  position{line: 2146, col: 23, offset: 63515},
 							name: "EMPTY_OR_SPACE",
 						},
 						&labeledExpr{
-							pos:   position{line: 1964, col: 38, offset: 56811},
+							pos:   position{line: 2146, col: 38, offset: 63530},
 							label: "op",
 							expr: &choiceExpr{
-								pos: position{line: 1964, col: 42, offset: 56815},
+								pos: position{line: 2146, col: 42, offset: 63534},
 								alternatives: []any{
 									&litMatcher{
-										pos:        position{line: 1964, col: 42, offset: 56815},
+										pos:        position{line: 2146, col: 42, offset: 63534},
 										val:        "<=",
 										ignoreCase: false,
 										want:       "\"<=\"",
 									},
 									&litMatcher{
-										pos:        position{line: 1964, col: 49, offset: 56822},
+										pos:        position{line: 2146, col: 49, offset: 63541},
 										val:        "<",
 										ignoreCase: false,
 										want:       "\"<\"",
 									},
 									&litMatcher{
-										pos:        position{line: 1964, col: 55, offset: 56828},
+										pos:        position{line: 2146, col: 55, offset: 63547},
 										val:        ">=",
 										ignoreCase: false,
 										want:       "\">=\"",
 									},
 									&litMatcher{
-										pos:        position{line: 1964, col: 62, offset: 56835},
+										pos:        position{line: 2146, col: 62, offset: 63554},
 										val:        ">",
 										ignoreCase: false,
 										want:       "\">\"",
@@ -5348,7 +5913,7 @@ var g = &grammar{
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 1964, col: 67, offset: 56840},
+							pos:  position{line: 2146, col: 67, offset: 63559},
 							name: "EMPTY_OR_SPACE",
 						},
 					},
@@ -5357,30 +5922,30 @@ var g = &grammar{
 		},
 		{
 			name: "EqualityOrInequality",
-			pos:  position{line: 1972, col: 1, offset: 57023},
+			pos:  position{line: 2154, col: 1, offset: 63742},
 			expr: &choiceExpr{
-				pos: position{line: 1972, col: 25, offset: 57047},
+				pos: position{line: 2154, col: 25, offset: 63766},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 1972, col: 25, offset: 57047},
+						pos: position{line: 2154, col: 25, offset: 63766},
 						run: (*parser).callonEqualityOrInequality2,
 						expr: &labeledExpr{
-							pos:   position{line: 1972, col: 25, offset: 57047},
+							pos:   position{line: 2154, col: 25, offset: 63766},
 							label: "op",
 							expr: &ruleRefExpr{
-								pos:  position{line: 1972, col: 28, offset: 57050},
+								pos:  position{line: 2154, col: 28, offset: 63769},
 								name: "EqualityOperator",
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 1975, col: 3, offset: 57092},
+						pos: position{line: 2157, col: 3, offset: 63811},
 						run: (*parser).callonEqualityOrInequality5,
 						expr: &labeledExpr{
-							pos:   position{line: 1975, col: 3, offset: 57092},
+							pos:   position{line: 2157, col: 3, offset: 63811},
 							label: "op",
 							expr: &ruleRefExpr{
-								pos:  position{line: 1975, col: 6, offset: 57095},
+								pos:  position{line: 2157, col: 6, offset: 63814},
 								name: "InequalityOperator",
 							},
 						},
@@ -5390,25 +5955,25 @@ var g = &grammar{
 		},
 		{
 			name: "OpPlus",
-			pos:  position{line: 1979, col: 1, offset: 57138},
+			pos:  position{line: 2161, col: 1, offset: 63857},
 			expr: &actionExpr{
-				pos: position{line: 1979, col: 11, offset: 57148},
+				pos: position{line: 2161, col: 11, offset: 63867},
 				run: (*parser).callonOpPlus1,
 				expr: &seqExpr{
-					pos: position{line: 1979, col: 11, offset: 57148},
+					pos: position{line: 2161, col: 11, offset: 63867},
 					exprs: []any{
 						&ruleRefExpr{
-							pos:  position{line: 1979, col: 11, offset: 57148},
+							pos:  position{line: 2161, col: 11, offset: 63867},
 							name: "EMPTY_OR_SPACE",
 						},
 						&litMatcher{
-							pos:        position{line: 1979, col: 26, offset: 57163},
+							pos:        position{line: 2161, col: 26, offset: 63882},
 							val:        "+",
 							ignoreCase: false,
 							want:       "\"+\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 1979, col: 30, offset: 57167},
+							pos:  position{line: 2161, col: 30, offset: 63886},
 							name: "EMPTY_OR_SPACE",
 						},
 					},
@@ -5417,25 +5982,25 @@ var g = &grammar{
 		},
 		{
 			name: "OpMinus",
-			pos:  position{line: 1983, col: 1, offset: 57207},
+			pos:  position{line: 2165, col: 1, offset: 63926},
 			expr: &actionExpr{
-				pos: position{line: 1983, col: 12, offset: 57218},
+				pos: position{line: 2165, col: 12, offset: 63937},
 				run: (*parser).callonOpMinus1,
 				expr: &seqExpr{
-					pos: position{line: 1983, col: 12, offset: 57218},
+					pos: position{line: 2165, col: 12, offset: 63937},
 					exprs: []any{
 						&ruleRefExpr{
-							pos:  position{line: 1983, col: 12, offset: 57218},
+							pos:  position{line: 2165, col: 12, offset: 63937},
 							name: "EMPTY_OR_SPACE",
 						},
 						&litMatcher{
-							pos:        position{line: 1983, col: 27, offset: 57233},
+							pos:        position{line: 2165, col: 27, offset: 63952},
 							val:        "-",
 							ignoreCase: false,
 							want:       "\"-\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 1983, col: 31, offset: 57237},
+							pos:  position{line: 2165, col: 31, offset: 63956},
 							name: "EMPTY_OR_SPACE",
 						},
 					},
@@ -5444,25 +6009,25 @@ var g = &grammar{
 		},
 		{
 			name: "OpMul",
-			pos:  position{line: 1987, col: 1, offset: 57277},
+			pos:  position{line: 2169, col: 1, offset: 63996},
 			expr: &actionExpr{
-				pos: position{line: 1987, col: 10, offset: 57286},
+				pos: position{line: 2169, col: 10, offset: 64005},
 				run: (*parser).callonOpMul1,
 				expr: &seqExpr{
-					pos: position{line: 1987, col: 10, offset: 57286},
+					pos: position{line: 2169, col: 10, offset: 64005},
 					exprs: []any{
 						&ruleRefExpr{
-							pos:  position{line: 1987, col: 10, offset: 57286},
+							pos:  position{line: 2169, col: 10, offset: 64005},
 							name: "EMPTY_OR_SPACE",
 						},
 						&litMatcher{
-							pos:        position{line: 1987, col: 25, offset: 57301},
+							pos:        position{line: 2169, col: 25, offset: 64020},
 							val:        "*",
 							ignoreCase: false,
 							want:       "\"*\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 1987, col: 29, offset: 57305},
+							pos:  position{line: 2169, col: 29, offset: 64024},
 							name: "EMPTY_OR_SPACE",
 						},
 					},
@@ -5471,25 +6036,25 @@ var g = &grammar{
 		},
 		{
 			name: "OpDiv",
-			pos:  position{line: 1991, col: 1, offset: 57345},
+			pos:  position{line: 2173, col: 1, offset: 64064},
 			expr: &actionExpr{
-				pos: position{line: 1991, col: 10, offset: 57354},
+				pos: position{line: 2173, col: 10, offset: 64073},
 				run: (*parser).callonOpDiv1,
 				expr: &seqExpr{
-					pos: position{line: 1991, col: 10, offset: 57354},
+					pos: position{line: 2173, col: 10, offset: 64073},
 					exprs: []any{
 						&ruleRefExpr{
-							pos:  position{line: 1991, col: 10, offset: 57354},
+							pos:  position{line: 2173, col: 10, offset: 64073},
 							name: "EMPTY_OR_SPACE",
 						},
 						&litMatcher{
-							pos:        position{line: 1991, col: 25, offset: 57369},
+							pos:        position{line: 2173, col: 25, offset: 64088},
 							val:        "/",
 							ignoreCase: false,
 							want:       "\"/\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 1991, col: 29, offset: 57373},
+							pos:  position{line: 2173, col: 29, offset: 64092},
 							name: "EMPTY_OR_SPACE",
 						},
 					},
@@ -5498,39 +6063,39 @@ var g = &grammar{
 		},
 		{
 			name: "Second",
-			pos:  position{line: 1996, col: 1, offset: 57437},
+			pos:  position{line: 2178, col: 1, offset: 64156},
 			expr: &actionExpr{
-				pos: position{line: 1996, col: 11, offset: 57447},
+				pos: position{line: 2178, col: 11, offset: 64166},
 				run: (*parser).callonSecond1,
 				expr: &choiceExpr{
-					pos: position{line: 1996, col: 12, offset: 57448},
+					pos: position{line: 2178, col: 12, offset: 64167},
 					alternatives: []any{
 						&litMatcher{
-							pos:        position{line: 1996, col: 12, offset: 57448},
+							pos:        position{line: 2178, col: 12, offset: 64167},
 							val:        "seconds",
 							ignoreCase: false,
 							want:       "\"seconds\"",
 						},
 						&litMatcher{
-							pos:        position{line: 1996, col: 24, offset: 57460},
+							pos:        position{line: 2178, col: 24, offset: 64179},
 							val:        "second",
 							ignoreCase: false,
 							want:       "\"second\"",
 						},
 						&litMatcher{
-							pos:        position{line: 1996, col: 35, offset: 57471},
+							pos:        position{line: 2178, col: 35, offset: 64190},
 							val:        "secs",
 							ignoreCase: false,
 							want:       "\"secs\"",
 						},
 						&litMatcher{
-							pos:        position{line: 1996, col: 44, offset: 57480},
+							pos:        position{line: 2178, col: 44, offset: 64199},
 							val:        "sec",
 							ignoreCase: false,
 							want:       "\"sec\"",
 						},
 						&litMatcher{
-							pos:        position{line: 1996, col: 52, offset: 57488},
+							pos:        position{line: 2178, col: 52, offset: 64207},
 							val:        "s",
 							ignoreCase: false,
 							want:       "\"s\"",
@@ -5541,39 +6106,39 @@ var g = &grammar{
 		},
 		{
 			name: "Minute",
-			pos:  position{line: 2000, col: 1, offset: 57529},
+			pos:  position{line: 2182, col: 1, offset: 64248},
 			expr: &actionExpr{
-				pos: position{line: 2000, col: 11, offset: 57539},
+				pos: position{line: 2182, col: 11, offset: 64258},
 				run: (*parser).callonMinute1,
 				expr: &choiceExpr{
-					pos: position{line: 2000, col: 12, offset: 57540},
+					pos: position{line: 2182, col: 12, offset: 64259},
 					alternatives: []any{
 						&litMatcher{
-							pos:        position{line: 2000, col: 12, offset: 57540},
+							pos:        position{line: 2182, col: 12, offset: 64259},
 							val:        "minutes",
 							ignoreCase: false,
 							want:       "\"minutes\"",
 						},
 						&litMatcher{
-							pos:        position{line: 2000, col: 24, offset: 57552},
+							pos:        position{line: 2182, col: 24, offset: 64271},
 							val:        "minute",
 							ignoreCase: false,
 							want:       "\"minute\"",
 						},
 						&litMatcher{
-							pos:        position{line: 2000, col: 35, offset: 57563},
+							pos:        position{line: 2182, col: 35, offset: 64282},
 							val:        "mins",
 							ignoreCase: false,
 							want:       "\"mins\"",
 						},
 						&litMatcher{
-							pos:        position{line: 2000, col: 44, offset: 57572},
+							pos:        position{line: 2182, col: 44, offset: 64291},
 							val:        "min",
 							ignoreCase: false,
 							want:       "\"min\"",
 						},
 						&litMatcher{
-							pos:        position{line: 2000, col: 52, offset: 57580},
+							pos:        position{line: 2182, col: 52, offset: 64299},
 							val:        "m",
 							ignoreCase: false,
 							want:       "\"m\"",
@@ -5584,39 +6149,39 @@ var g = &grammar{
 		},
 		{
 			name: "Hour",
-			pos:  position{line: 2004, col: 1, offset: 57621},
+			pos:  position{line: 2186, col: 1, offset: 64340},
 			expr: &actionExpr{
-				pos: position{line: 2004, col: 9, offset: 57629},
+				pos: position{line: 2186, col: 9, offset: 64348},
 				run: (*parser).callonHour1,
 				expr: &choiceExpr{
-					pos: position{line: 2004, col: 10, offset: 57630},
+					pos: position{line: 2186, col: 10, offset: 64349},
 					alternatives: []any{
 						&litMatcher{
-							pos:        position{line: 2004, col: 10, offset: 57630},
+							pos:        position{line: 2186, col: 10, offset: 64349},
 							val:        "hours",
 							ignoreCase: false,
 							want:       "\"hours\"",
 						},
 						&litMatcher{
-							pos:        position{line: 2004, col: 20, offset: 57640},
+							pos:        position{line: 2186, col: 20, offset: 64359},
 							val:        "hour",
 							ignoreCase: false,
 							want:       "\"hour\"",
 						},
 						&litMatcher{
-							pos:        position{line: 2004, col: 29, offset: 57649},
+							pos:        position{line: 2186, col: 29, offset: 64368},
 							val:        "hrs",
 							ignoreCase: false,
 							want:       "\"hrs\"",
 						},
 						&litMatcher{
-							pos:        position{line: 2004, col: 37, offset: 57657},
+							pos:        position{line: 2186, col: 37, offset: 64376},
 							val:        "hr",
 							ignoreCase: false,
 							want:       "\"hr\"",
 						},
 						&litMatcher{
-							pos:        position{line: 2004, col: 44, offset: 57664},
+							pos:        position{line: 2186, col: 44, offset: 64383},
 							val:        "h",
 							ignoreCase: false,
 							want:       "\"h\"",
@@ -5627,27 +6192,27 @@ var g = &grammar{
 		},
 		{
 			name: "Day",
-			pos:  position{line: 2008, col: 1, offset: 57703},
+			pos:  position{line: 2190, col: 1, offset: 64422},
 			expr: &actionExpr{
-				pos: position{line: 2008, col: 8, offset: 57710},
+				pos: position{line: 2190, col: 8, offset: 64429},
 				run: (*parser).callonDay1,
 				expr: &choiceExpr{
-					pos: position{line: 2008, col: 9, offset: 57711},
+					pos: position{line: 2190, col: 9, offset: 64430},
 					alternatives: []any{
 						&litMatcher{
-							pos:        position{line: 2008, col: 9, offset: 57711},
+							pos:        position{line: 2190, col: 9, offset: 64430},
 							val:        "days",
 							ignoreCase: false,
 							want:       "\"days\"",
 						},
 						&litMatcher{
-							pos:        position{line: 2008, col: 18, offset: 57720},
+							pos:        position{line: 2190, col: 18, offset: 64439},
 							val:        "day",
 							ignoreCase: false,
 							want:       "\"day\"",
 						},
 						&litMatcher{
-							pos:        position{line: 2008, col: 26, offset: 57728},
+							pos:        position{line: 2190, col: 26, offset: 64447},
 							val:        "d",
 							ignoreCase: false,
 							want:       "\"d\"",
@@ -5658,27 +6223,27 @@ var g = &grammar{
 		},
 		{
 			name: "Week",
-			pos:  position{line: 2012, col: 1, offset: 57766},
+			pos:  position{line: 2194, col: 1, offset: 64485},
 			expr: &actionExpr{
-				pos: position{line: 2012, col: 9, offset: 57774},
+				pos: position{line: 2194, col: 9, offset: 64493},
 				run: (*parser).callonWeek1,
 				expr: &choiceExpr{
-					pos: position{line: 2012, col: 10, offset: 57775},
+					pos: position{line: 2194, col: 10, offset: 64494},
 					alternatives: []any{
 						&litMatcher{
-							pos:        position{line: 2012, col: 10, offset: 57775},
+							pos:        position{line: 2194, col: 10, offset: 64494},
 							val:        "weeks",
 							ignoreCase: false,
 							want:       "\"weeks\"",
 						},
 						&litMatcher{
-							pos:        position{line: 2012, col: 20, offset: 57785},
+							pos:        position{line: 2194, col: 20, offset: 64504},
 							val:        "week",
 							ignoreCase: false,
 							want:       "\"week\"",
 						},
 						&litMatcher{
-							pos:        position{line: 2012, col: 29, offset: 57794},
+							pos:        position{line: 2194, col: 29, offset: 64513},
 							val:        "w",
 							ignoreCase: false,
 							want:       "\"w\"",
@@ -5689,27 +6254,27 @@ var g = &grammar{
 		},
 		{
 			name: "Month",
-			pos:  position{line: 2016, col: 1, offset: 57833},
+			pos:  position{line: 2198, col: 1, offset: 64552},
 			expr: &actionExpr{
-				pos: position{line: 2016, col: 10, offset: 57842},
+				pos: position{line: 2198, col: 10, offset: 64561},
 				run: (*parser).callonMonth1,
 				expr: &choiceExpr{
-					pos: position{line: 2016, col: 11, offset: 57843},
+					pos: position{line: 2198, col: 11, offset: 64562},
 					alternatives: []any{
 						&litMatcher{
-							pos:        position{line: 2016, col: 11, offset: 57843},
+							pos:        position{line: 2198, col: 11, offset: 64562},
 							val:        "months",
 							ignoreCase: false,
 							want:       "\"months\"",
 						},
 						&litMatcher{
-							pos:        position{line: 2016, col: 22, offset: 57854},
+							pos:        position{line: 2198, col: 22, offset: 64573},
 							val:        "month",
 							ignoreCase: false,
 							want:       "\"month\"",
 						},
 						&litMatcher{
-							pos:        position{line: 2016, col: 32, offset: 57864},
+							pos:        position{line: 2198, col: 32, offset: 64583},
 							val:        "mon",
 							ignoreCase: false,
 							want:       "\"mon\"",
@@ -5720,39 +6285,39 @@ var g = &grammar{
 		},
 		{
 			name: "Quarter",
-			pos:  position{line: 2020, col: 1, offset: 57906},
+			pos:  position{line: 2202, col: 1, offset: 64625},
 			expr: &actionExpr{
-				pos: position{line: 2020, col: 12, offset: 57917},
+				pos: position{line: 2202, col: 12, offset: 64636},
 				run: (*parser).callonQuarter1,
 				expr: &choiceExpr{
-					pos: position{line: 2020, col: 13, offset: 57918},
+					pos: position{line: 2202, col: 13, offset: 64637},
 					alternatives: []any{
 						&litMatcher{
-							pos:        position{line: 2020, col: 13, offset: 57918},
+							pos:        position{line: 2202, col: 13, offset: 64637},
 							val:        "quarters",
 							ignoreCase: false,
 							want:       "\"quarters\"",
 						},
 						&litMatcher{
-							pos:        position{line: 2020, col: 26, offset: 57931},
+							pos:        position{line: 2202, col: 26, offset: 64650},
 							val:        "quarter",
 							ignoreCase: false,
 							want:       "\"quarter\"",
 						},
 						&litMatcher{
-							pos:        position{line: 2020, col: 38, offset: 57943},
+							pos:        position{line: 2202, col: 38, offset: 64662},
 							val:        "qtrs",
 							ignoreCase: false,
 							want:       "\"qtrs\"",
 						},
 						&litMatcher{
-							pos:        position{line: 2020, col: 47, offset: 57952},
+							pos:        position{line: 2202, col: 47, offset: 64671},
 							val:        "qtr",
 							ignoreCase: false,
 							want:       "\"qtr\"",
 						},
 						&litMatcher{
-							pos:        position{line: 2020, col: 55, offset: 57960},
+							pos:        position{line: 2202, col: 55, offset: 64679},
 							val:        "q",
 							ignoreCase: false,
 							want:       "\"q\"",
@@ -5763,33 +6328,33 @@ var g = &grammar{
 		},
 		{
 			name: "Subseconds",
-			pos:  position{line: 2025, col: 1, offset: 58094},
+			pos:  position{line: 2207, col: 1, offset: 64813},
 			expr: &actionExpr{
-				pos: position{line: 2025, col: 15, offset: 58108},
+				pos: position{line: 2207, col: 15, offset: 64827},
 				run: (*parser).callonSubseconds1,
 				expr: &choiceExpr{
-					pos: position{line: 2025, col: 16, offset: 58109},
+					pos: position{line: 2207, col: 16, offset: 64828},
 					alternatives: []any{
 						&litMatcher{
-							pos:        position{line: 2025, col: 16, offset: 58109},
+							pos:        position{line: 2207, col: 16, offset: 64828},
 							val:        "us",
 							ignoreCase: false,
 							want:       "\"us\"",
 						},
 						&litMatcher{
-							pos:        position{line: 2025, col: 23, offset: 58116},
+							pos:        position{line: 2207, col: 23, offset: 64835},
 							val:        "ms",
 							ignoreCase: false,
 							want:       "\"ms\"",
 						},
 						&litMatcher{
-							pos:        position{line: 2025, col: 30, offset: 58123},
+							pos:        position{line: 2207, col: 30, offset: 64842},
 							val:        "cs",
 							ignoreCase: false,
 							want:       "\"cs\"",
 						},
 						&litMatcher{
-							pos:        position{line: 2025, col: 37, offset: 58130},
+							pos:        position{line: 2207, col: 37, offset: 64849},
 							val:        "ds",
 							ignoreCase: false,
 							want:       "\"ds\"",
@@ -5800,37 +6365,68 @@ var g = &grammar{
 		},
 		{
 			name: "CMD_SEARCH",
-			pos:  position{line: 2033, col: 1, offset: 58316},
+			pos:  position{line: 2215, col: 1, offset: 65035},
 			expr: &seqExpr{
-				pos: position{line: 2033, col: 15, offset: 58330},
+				pos: position{line: 2215, col: 15, offset: 65049},
 				exprs: []any{
 					&litMatcher{
-						pos:        position{line: 2033, col: 15, offset: 58330},
+						pos:        position{line: 2215, col: 15, offset: 65049},
 						val:        "search",
 						ignoreCase: false,
 						want:       "\"search\"",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 2033, col: 24, offset: 58339},
+						pos:  position{line: 2215, col: 24, offset: 65058},
 						name: "SPACE",
 					},
 				},
 			},
 		},
+		{
+			name: "CMD_SPANSEARCH",
+			pos:  position{line: 2216, col: 1, offset: 65064},
+			expr: &seqExpr{
+				pos: position{line: 2216, col: 19, offset: 65082},
+				exprs: []any{
+					&litMatcher{
+						pos:        position{line: 2216, col: 19, offset: 65082},
+						val:        "spansearch",
+						ignoreCase: false,
+						want:       "\"spansearch\"",
+					},
+					&andExpr{
+						pos: position{line: 2216, col: 32, offset: 65095},
+						expr: &choiceExpr{
+							pos: position{line: 2216, col: 34, offset: 65097},
+							alternatives: []any{
+								&ruleRefExpr{
+									pos:  position{line: 2216, col: 34, offset: 65097},
+									name: "SPACE",
+								},
+								&ruleRefExpr{
+									pos:  position{line: 2216, col: 42, offset: 65105},
+									name: "EOF",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "CMD_REGEX",
-			pos:  position{line: 2034, col: 1, offset: 58345},
+			pos:  position{line: 2217, col: 1, offset: 65110},
 			expr: &seqExpr{
-				pos: position{line: 2034, col: 14, offset: 58358},
+				pos: position{line: 2217, col: 14, offset: 65123},
 				exprs: []any{
 					&litMatcher{
-						pos:        position{line: 2034, col: 14, offset: 58358},
+						pos:        position{line: 2217, col: 14, offset: 65123},
 						val:        "regex",
 						ignoreCase: false,
 						want:       "\"regex\"",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 2034, col: 22, offset: 58366},
+						pos:  position{line: 2217, col: 22, offset: 65131},
 						name: "SPACE",
 					},
 				},
@@ -5838,18 +6434,18 @@ var g = &grammar{
 		},
 		{
 			name: "CMD_STATS",
-			pos:  position{line: 2035, col: 1, offset: 58372},
+			pos:  position{line: 2218, col: 1, offset: 65137},
 			expr: &seqExpr{
-				pos: position{line: 2035, col: 14, offset: 58385},
+				pos: position{line: 2218, col: 14, offset: 65150},
 				exprs: []any{
 					&litMatcher{
-						pos:        position{line: 2035, col: 14, offset: 58385},
+						pos:        position{line: 2218, col: 14, offset: 65150},
 						val:        "stats",
 						ignoreCase: false,
 						want:       "\"stats\"",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 2035, col: 22, offset: 58393},
+						pos:  position{line: 2218, col: 22, offset: 65158},
 						name: "SPACE",
 					},
 				},
@@ -5857,18 +6453,18 @@ var g = &grammar{
 		},
 		{
 			name: "CMD_FIELDS",
-			pos:  position{line: 2036, col: 1, offset: 58399},
+			pos:  position{line: 2219, col: 1, offset: 65164},
 			expr: &seqExpr{
-				pos: position{line: 2036, col: 15, offset: 58413},
+				pos: position{line: 2219, col: 15, offset: 65178},
 				exprs: []any{
 					&litMatcher{
-						pos:        position{line: 2036, col: 15, offset: 58413},
+						pos:        position{line: 2219, col: 15, offset: 65178},
 						val:        "fields",
 						ignoreCase: false,
 						want:       "\"fields\"",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 2036, col: 24, offset: 58422},
+						pos:  position{line: 2219, col: 24, offset: 65187},
 						name: "SPACE",
 					},
 				},
@@ -5876,18 +6472,18 @@ var g = &grammar{
 		},
 		{
 			name: "CMD_WHERE",
-			pos:  position{line: 2037, col: 1, offset: 58428},
+			pos:  position{line: 2220, col: 1, offset: 65193},
 			expr: &seqExpr{
-				pos: position{line: 2037, col: 14, offset: 58441},
+				pos: position{line: 2220, col: 14, offset: 65206},
 				exprs: []any{
 					&litMatcher{
-						pos:        position{line: 2037, col: 14, offset: 58441},
+						pos:        position{line: 2220, col: 14, offset: 65206},
 						val:        "where",
 						ignoreCase: false,
 						want:       "\"where\"",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 2037, col: 22, offset: 58449},
+						pos:  position{line: 2220, col: 22, offset: 65214},
 						name: "SPACE",
 					},
 				},
@@ -5895,9 +6491,9 @@ var g = &grammar{
 		},
 		{
 			name: "CMD_HEAD_NO_SPACE",
-			pos:  position{line: 2038, col: 1, offset: 58455},
+			pos:  position{line: 2221, col: 1, offset: 65220},
 			expr: &litMatcher{
-				pos:        position{line: 2038, col: 22, offset: 58476},
+				pos:        position{line: 2221, col: 22, offset: 65241},
 				val:        "head",
 				ignoreCase: false,
 				want:       "\"head\"",
@@ -5905,16 +6501,16 @@ var g = &grammar{
 		},
 		{
 			name: "CMD_HEAD",
-			pos:  position{line: 2039, col: 1, offset: 58483},
+			pos:  position{line: 2222, col: 1, offset: 65248},
 			expr: &seqExpr{
-				pos: position{line: 2039, col: 13, offset: 58495},
+				pos: position{line: 2222, col: 13, offset: 65260},
 				exprs: []any{
 					&ruleRefExpr{
-						pos:  position{line: 2039, col: 13, offset: 58495},
+						pos:  position{line: 2222, col: 13, offset: 65260},
 						name: "CMD_HEAD_NO_SPACE",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 2039, col: 31, offset: 58513},
+						pos:  position{line: 2222, col: 31, offset: 65278},
 						name: "SPACE",
 					},
 				},
@@ -5922,18 +6518,18 @@ var g = &grammar{
 		},
 		{
 			name: "CMD_EVAL",
-			pos:  position{line: 2040, col: 1, offset: 58519},
+			pos:  position{line: 2223, col: 1, offset: 65284},
 			expr: &seqExpr{
-				pos: position{line: 2040, col: 13, offset: 58531},
+				pos: position{line: 2223, col: 13, offset: 65296},
 				exprs: []any{
 					&litMatcher{
-						pos:        position{line: 2040, col: 13, offset: 58531},
+						pos:        position{line: 2223, col: 13, offset: 65296},
 						val:        "eval",
 						ignoreCase: false,
 						want:       "\"eval\"",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 2040, col: 20, offset: 58538},
+						pos:  position{line: 2223, col: 20, offset: 65303},
 						name: "SPACE",
 					},
 				},
@@ -5941,18 +6537,18 @@ var g = &grammar{
 		},
 		{
 			name: "CMD_REX",
-			pos:  position{line: 2041, col: 1, offset: 58544},
+			pos:  position{line: 2224, col: 1, offset: 65309},
 			expr: &seqExpr{
-				pos: position{line: 2041, col: 12, offset: 58555},
+				pos: position{line: 2224, col: 12, offset: 65320},
 				exprs: []any{
 					&litMatcher{
-						pos:        position{line: 2041, col: 12, offset: 58555},
+						pos:        position{line: 2224, col: 12, offset: 65320},
 						val:        "rex",
 						ignoreCase: false,
 						want:       "\"rex\"",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 2041, col: 18, offset: 58561},
+						pos:  position{line: 2224, col: 18, offset: 65326},
 						name: "SPACE",
 					},
 				},
@@ -5960,9 +6556,9 @@ var g = &grammar{
 		},
 		{
 			name: "CMD_TOP",
-			pos:  position{line: 2042, col: 1, offset: 58567},
+			pos:  position{line: 2225, col: 1, offset: 65332},
 			expr: &litMatcher{
-				pos:        position{line: 2042, col: 12, offset: 58578},
+				pos:        position{line: 2225, col: 12, offset: 65343},
 				val:        "top",
 				ignoreCase: false,
 				want:       "\"top\"",
@@ -5970,9 +6566,9 @@ var g = &grammar{
 		},
 		{
 			name: "CMD_RARE",
-			pos:  position{line: 2043, col: 1, offset: 58584},
+			pos:  position{line: 2226, col: 1, offset: 65349},
 			expr: &litMatcher{
-				pos:        position{line: 2043, col: 13, offset: 58596},
+				pos:        position{line: 2226, col: 13, offset: 65361},
 				val:        "rare",
 				ignoreCase: false,
 				want:       "\"rare\"",
@@ -5980,18 +6576,56 @@ var g = &grammar{
 		},
 		{
 			name: "CMD_RENAME",
-			pos:  position{line: 2044, col: 1, offset: 58603},
+			pos:  position{line: 2227, col: 1, offset: 65368},
 			expr: &seqExpr{
-				pos: position{line: 2044, col: 15, offset: 58617},
+				pos: position{line: 2227, col: 15, offset: 65382},
 				exprs: []any{
 					&litMatcher{
-						pos:        position{line: 2044, col: 15, offset: 58617},
+						pos:        position{line: 2227, col: 15, offset: 65382},
 						val:        "rename",
 						ignoreCase: false,
 						want:       "\"rename\"",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 2044, col: 24, offset: 58626},
+						pos:  position{line: 2227, col: 24, offset: 65391},
+						name: "SPACE",
+					},
+				},
+			},
+		},
+		{
+			name: "CMD_LOOKUP",
+			pos:  position{line: 2228, col: 1, offset: 65397},
+			expr: &seqExpr{
+				pos: position{line: 2228, col: 15, offset: 65411},
+				exprs: []any{
+					&litMatcher{
+						pos:        position{line: 2228, col: 15, offset: 65411},
+						val:        "lookup",
+						ignoreCase: false,
+						want:       "\"lookup\"",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 2228, col: 24, offset: 65420},
+						name: "SPACE",
+					},
+				},
+			},
+		},
+		{
+			name: "CMD_SIMILAR_TO",
+			pos:  position{line: 2229, col: 1, offset: 65426},
+			expr: &seqExpr{
+				pos: position{line: 2229, col: 19, offset: 65444},
+				exprs: []any{
+					&litMatcher{
+						pos:        position{line: 2229, col: 19, offset: 65444},
+						val:        "similar_to",
+						ignoreCase: false,
+						want:       "\"similar_to\"",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 2229, col: 32, offset: 65457},
 						name: "SPACE",
 					},
 				},
@@ -5999,18 +6633,18 @@ var g = &grammar{
 		},
 		{
 			name: "CMD_TIMECHART",
-			pos:  position{line: 2045, col: 1, offset: 58632},
+			pos:  position{line: 2230, col: 1, offset: 65463},
 			expr: &seqExpr{
-				pos: position{line: 2045, col: 18, offset: 58649},
+				pos: position{line: 2230, col: 18, offset: 65480},
 				exprs: []any{
 					&litMatcher{
-						pos:        position{line: 2045, col: 18, offset: 58649},
+						pos:        position{line: 2230, col: 18, offset: 65480},
 						val:        "timechart",
 						ignoreCase: false,
 						want:       "\"timechart\"",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 2045, col: 30, offset: 58661},
+						pos:  position{line: 2230, col: 30, offset: 65492},
 						name: "SPACE",
 					},
 				},
@@ -6018,9 +6652,9 @@ var g = &grammar{
 		},
 		{
 			name: "CMD_SPAN",
-			pos:  position{line: 2046, col: 1, offset: 58667},
+			pos:  position{line: 2231, col: 1, offset: 65498},
 			expr: &litMatcher{
-				pos:        position{line: 2046, col: 13, offset: 58679},
+				pos:        position{line: 2231, col: 13, offset: 65510},
 				val:        "span",
 				ignoreCase: false,
 				want:       "\"span\"",
@@ -6028,27 +6662,27 @@ var g = &grammar{
 		},
 		{
 			name: "EVAL_CONCAT",
-			pos:  position{line: 2047, col: 1, offset: 58686},
+			pos:  position{line: 2232, col: 1, offset: 65517},
 			expr: &seqExpr{
-				pos: position{line: 2047, col: 16, offset: 58701},
+				pos: position{line: 2232, col: 16, offset: 65532},
 				exprs: []any{
 					&zeroOrOneExpr{
-						pos: position{line: 2047, col: 16, offset: 58701},
+						pos: position{line: 2232, col: 16, offset: 65532},
 						expr: &ruleRefExpr{
-							pos:  position{line: 2047, col: 16, offset: 58701},
+							pos:  position{line: 2232, col: 16, offset: 65532},
 							name: "SPACE",
 						},
 					},
 					&litMatcher{
-						pos:        position{line: 2047, col: 23, offset: 58708},
+						pos:        position{line: 2232, col: 23, offset: 65539},
 						val:        ".",
 						ignoreCase: false,
 						want:       "\".\"",
 					},
 					&zeroOrOneExpr{
-						pos: position{line: 2047, col: 27, offset: 58712},
+						pos: position{line: 2232, col: 27, offset: 65543},
 						expr: &ruleRefExpr{
-							pos:  position{line: 2047, col: 27, offset: 58712},
+							pos:  position{line: 2232, col: 27, offset: 65543},
 							name: "SPACE",
 						},
 					},
@@ -6057,115 +6691,115 @@ var g = &grammar{
 		},
 		{
 			name: "MAJOR_BREAK",
-			pos:  position{line: 2050, col: 1, offset: 58823},
+			pos:  position{line: 2235, col: 1, offset: 65654},
 			expr: &choiceExpr{
-				pos: position{line: 2050, col: 16, offset: 58838},
+				pos: position{line: 2235, col: 16, offset: 65669},
 				alternatives: []any{
 					&charClassMatcher{
-						pos:        position{line: 2050, col: 16, offset: 58838},
+						pos:        position{line: 2235, col: 16, offset: 65669},
 						val:        "[[\\]<>(){}|!;,'\"*\\n\\r \\t&?+]",
 						chars:      []rune{'[', ']', '<', '>', '(', ')', '{', '}', '|', '!', ';', ',', '\'', '"', '*', '\n', '\r', ' ', '\t', '&', '?', '+'},
 						ignoreCase: false,
 						inverted:   false,
 					},
 					&litMatcher{
-						pos:        position{line: 2050, col: 47, offset: 58869},
+						pos:        position{line: 2235, col: 47, offset: 65700},
 						val:        "%21",
 						ignoreCase: false,
 						want:       "\"%21\"",
 					},
 					&litMatcher{
-						pos:        position{line: 2050, col: 55, offset: 58877},
+						pos:        position{line: 2235, col: 55, offset: 65708},
 						val:        "%26",
 						ignoreCase: false,
 						want:       "\"%26\"",
 					},
 					&litMatcher{
-						pos:        position{line: 2051, col: 16, offset: 58900},
+						pos:        position{line: 2236, col: 16, offset: 65731},
 						val:        "%2526",
 						ignoreCase: false,
 						want:       "\"%2526\"",
 					},
 					&litMatcher{
-						pos:        position{line: 2051, col: 26, offset: 58910},
+						pos:        position{line: 2236, col: 26, offset: 65741},
 						val:        "%3B",
 						ignoreCase: false,
 						want:       "\"%3B\"",
 					},
 					&litMatcher{
-						pos:        position{line: 2051, col: 34, offset: 58918},
+						pos:        position{line: 2236, col: 34, offset: 65749},
 						val:        "%7C",
 						ignoreCase: false,
 						want:       "\"%7C\"",
 					},
 					&litMatcher{
-						pos:        position{line: 2051, col: 42, offset: 58926},
+						pos:        position{line: 2236, col: 42, offset: 65757},
 						val:        "%20",
 						ignoreCase: false,
 						want:       "\"%20\"",
 					},
 					&litMatcher{
-						pos:        position{line: 2051, col: 50, offset: 58934},
+						pos:        position{line: 2236, col: 50, offset: 65765},
 						val:        "%2B",
 						ignoreCase: false,
 						want:       "\"%2B\"",
 					},
 					&litMatcher{
-						pos:        position{line: 2051, col: 58, offset: 58942},
+						pos:        position{line: 2236, col: 58, offset: 65773},
 						val:        "%3D",
 						ignoreCase: false,
 						want:       "\"%3D\"",
 					},
 					&litMatcher{
-						pos:        position{line: 2051, col: 66, offset: 58950},
+						pos:        position{line: 2236, col: 66, offset: 65781},
 						val:        "--",
 						ignoreCase: false,
 						want:       "\"--\"",
 					},
 					&litMatcher{
-						pos:        position{line: 2052, col: 16, offset: 58972},
+						pos:        position{line: 2237, col: 16, offset: 65803},
 						val:        "%2520",
 						ignoreCase: false,
 						want:       "\"%2520\"",
 					},
 					&litMatcher{
-						pos:        position{line: 2052, col: 26, offset: 58982},
+						pos:        position{line: 2237, col: 26, offset: 65813},
 						val:        "%5D",
 						ignoreCase: false,
 						want:       "\"%5D\"",
 					},
 					&litMatcher{
-						pos:        position{line: 2052, col: 34, offset: 58990},
+						pos:        position{line: 2237, col: 34, offset: 65821},
 						val:        "%5B",
 						ignoreCase: false,
 						want:       "\"%5B\"",
 					},
 					&litMatcher{
-						pos:        position{line: 2052, col: 42, offset: 58998},
+						pos:        position{line: 2237, col: 42, offset: 65829},
 						val:        "%3A",
 						ignoreCase: false,
 						want:       "\"%3A\"",
 					},
 					&litMatcher{
-						pos:        position{line: 2052, col: 50, offset: 59006},
+						pos:        position{line: 2237, col: 50, offset: 65837},
 						val:        "%0A",
 						ignoreCase: false,
 						want:       "\"%0A\"",
 					},
 					&litMatcher{
-						pos:        position{line: 2052, col: 58, offset: 59014},
+						pos:        position{line: 2237, col: 58, offset: 65845},
 						val:        "%2C",
 						ignoreCase: false,
 						want:       "\"%2C\"",
 					},
 					&litMatcher{
-						pos:        position{line: 2052, col: 66, offset: 59022},
+						pos:        position{line: 2237, col: 66, offset: 65853},
 						val:        "%28",
 						ignoreCase: false,
 						want:       "\"%28\"",
 					},
 					&litMatcher{
-						pos:        position{line: 2052, col: 74, offset: 59030},
+						pos:        position{line: 2237, col: 74, offset: 65861},
 						val:        "%29",
 						ignoreCase: false,
 						want:       "\"%29\"",
@@ -6175,25 +6809,25 @@ var g = &grammar{
 		},
 		{
 			name: "MINOR_BREAK",
-			pos:  position{line: 2053, col: 1, offset: 59036},
+			pos:  position{line: 2238, col: 1, offset: 65867},
 			expr: &choiceExpr{
-				pos: position{line: 2053, col: 16, offset: 59051},
+				pos: position{line: 2238, col: 16, offset: 65882},
 				alternatives: []any{
 					&charClassMatcher{
-						pos:        position{line: 2053, col: 16, offset: 59051},
+						pos:        position{line: 2238, col: 16, offset: 65882},
 						val:        "[/:=@.$#%_]",
 						chars:      []rune{'/', ':', '=', '@', '.', '$', '#', '%', '_'},
 						ignoreCase: false,
 						inverted:   false,
 					},
 					&litMatcher{
-						pos:        position{line: 2053, col: 30, offset: 59065},
+						pos:        position{line: 2238, col: 30, offset: 65896},
 						val:        "-",
 						ignoreCase: false,
 						want:       "\"-\"",
 					},
 					&litMatcher{
-						pos:        position{line: 2053, col: 36, offset: 59071},
+						pos:        position{line: 2238, col: 36, offset: 65902},
 						val:        "\\",
 						ignoreCase: false,
 						want:       "\"\\\\\"",
@@ -6203,18 +6837,18 @@ var g = &grammar{
 		},
 		{
 			name: "NOT",
-			pos:  position{line: 2057, col: 1, offset: 59227},
+			pos:  position{line: 2242, col: 1, offset: 66058},
 			expr: &seqExpr{
-				pos: position{line: 2057, col: 8, offset: 59234},
+				pos: position{line: 2242, col: 8, offset: 66065},
 				exprs: []any{
 					&litMatcher{
-						pos:        position{line: 2057, col: 8, offset: 59234},
+						pos:        position{line: 2242, col: 8, offset: 66065},
 						val:        "NOT",
 						ignoreCase: false,
 						want:       "\"NOT\"",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 2057, col: 14, offset: 59240},
+						pos:  position{line: 2242, col: 14, offset: 66071},
 						name: "SPACE",
 					},
 				},
@@ -6222,22 +6856,22 @@ var g = &grammar{
 		},
 		{
 			name: "OR",
-			pos:  position{line: 2058, col: 1, offset: 59246},
+			pos:  position{line: 2243, col: 1, offset: 66077},
 			expr: &seqExpr{
-				pos: position{line: 2058, col: 7, offset: 59252},
+				pos: position{line: 2243, col: 7, offset: 66083},
 				exprs: []any{
 					&ruleRefExpr{
-						pos:  position{line: 2058, col: 7, offset: 59252},
+						pos:  position{line: 2243, col: 7, offset: 66083},
 						name: "SPACE",
 					},
 					&litMatcher{
-						pos:        position{line: 2058, col: 13, offset: 59258},
+						pos:        position{line: 2243, col: 13, offset: 66089},
 						val:        "OR",
 						ignoreCase: false,
 						want:       "\"OR\"",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 2058, col: 18, offset: 59263},
+						pos:  position{line: 2243, col: 18, offset: 66094},
 						name: "SPACE",
 					},
 				},
@@ -6245,22 +6879,22 @@ var g = &grammar{
 		},
 		{
 			name: "AND",
-			pos:  position{line: 2059, col: 1, offset: 59269},
+			pos:  position{line: 2244, col: 1, offset: 66100},
 			expr: &seqExpr{
-				pos: position{line: 2059, col: 8, offset: 59276},
+				pos: position{line: 2244, col: 8, offset: 66107},
 				exprs: []any{
 					&ruleRefExpr{
-						pos:  position{line: 2059, col: 8, offset: 59276},
+						pos:  position{line: 2244, col: 8, offset: 66107},
 						name: "SPACE",
 					},
 					&litMatcher{
-						pos:        position{line: 2059, col: 14, offset: 59282},
+						pos:        position{line: 2244, col: 14, offset: 66113},
 						val:        "AND",
 						ignoreCase: false,
 						want:       "\"AND\"",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 2059, col: 20, offset: 59288},
+						pos:  position{line: 2244, col: 20, offset: 66119},
 						name: "SPACE",
 					},
 				},
@@ -6268,22 +6902,22 @@ var g = &grammar{
 		},
 		{
 			name: "PIPE",
-			pos:  position{line: 2060, col: 1, offset: 59294},
+			pos:  position{line: 2245, col: 1, offset: 66125},
 			expr: &seqExpr{
-				pos: position{line: 2060, col: 9, offset: 59302},
+				pos: position{line: 2245, col: 9, offset: 66133},
 				exprs: []any{
 					&ruleRefExpr{
-						pos:  position{line: 2060, col: 9, offset: 59302},
+						pos:  position{line: 2245, col: 9, offset: 66133},
 						name: "SPACE",
 					},
 					&litMatcher{
-						pos:        position{line: 2060, col: 15, offset: 59308},
+						pos:        position{line: 2245, col: 15, offset: 66139},
 						val:        "|",
 						ignoreCase: false,
 						want:       "\"|\"",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 2060, col: 19, offset: 59312},
+						pos:  position{line: 2245, col: 19, offset: 66143},
 						name: "SPACE",
 					},
 				},
@@ -6291,22 +6925,22 @@ var g = &grammar{
 		},
 		{
 			name: "AS",
-			pos:  position{line: 2061, col: 1, offset: 59318},
+			pos:  position{line: 2246, col: 1, offset: 66149},
 			expr: &seqExpr{
-				pos: position{line: 2061, col: 7, offset: 59324},
+				pos: position{line: 2246, col: 7, offset: 66155},
 				exprs: []any{
 					&ruleRefExpr{
-						pos:  position{line: 2061, col: 7, offset: 59324},
+						pos:  position{line: 2246, col: 7, offset: 66155},
 						name: "SPACE",
 					},
 					&litMatcher{
-						pos:        position{line: 2061, col: 13, offset: 59330},
+						pos:        position{line: 2246, col: 13, offset: 66161},
 						val:        "as",
 						ignoreCase: true,
 						want:       "\"AS\"i",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 2061, col: 19, offset: 59336},
+						pos:  position{line: 2246, col: 19, offset: 66167},
 						name: "SPACE",
 					},
 				},
@@ -6314,22 +6948,22 @@ var g = &grammar{
 		},
 		{
 			name: "BY",
-			pos:  position{line: 2062, col: 1, offset: 59362},
+			pos:  position{line: 2247, col: 1, offset: 66193},
 			expr: &seqExpr{
-				pos: position{line: 2062, col: 7, offset: 59368},
+				pos: position{line: 2247, col: 7, offset: 66199},
 				exprs: []any{
 					&ruleRefExpr{
-						pos:  position{line: 2062, col: 7, offset: 59368},
+						pos:  position{line: 2247, col: 7, offset: 66199},
 						name: "SPACE",
 					},
 					&litMatcher{
-						pos:        position{line: 2062, col: 13, offset: 59374},
+						pos:        position{line: 2247, col: 13, offset: 66205},
 						val:        "by",
 						ignoreCase: true,
 						want:       "\"BY\"i",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 2062, col: 19, offset: 59380},
+						pos:  position{line: 2247, col: 19, offset: 66211},
 						name: "SPACE",
 					},
 				},
@@ -6337,22 +6971,22 @@ var g = &grammar{
 		},
 		{
 			name: "EQUAL",
-			pos:  position{line: 2064, col: 1, offset: 59407},
+			pos:  position{line: 2249, col: 1, offset: 66238},
 			expr: &seqExpr{
-				pos: position{line: 2064, col: 10, offset: 59416},
+				pos: position{line: 2249, col: 10, offset: 66247},
 				exprs: []any{
 					&ruleRefExpr{
-						pos:  position{line: 2064, col: 10, offset: 59416},
+						pos:  position{line: 2249, col: 10, offset: 66247},
 						name: "EMPTY_OR_SPACE",
 					},
 					&litMatcher{
-						pos:        position{line: 2064, col: 25, offset: 59431},
+						pos:        position{line: 2249, col: 25, offset: 66262},
 						val:        "=",
 						ignoreCase: false,
 						want:       "\"=\"",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 2064, col: 29, offset: 59435},
+						pos:  position{line: 2249, col: 29, offset: 66266},
 						name: "EMPTY_OR_SPACE",
 					},
 				},
@@ -6360,22 +6994,22 @@ var g = &grammar{
 		},
 		{
 			name: "COMMA",
-			pos:  position{line: 2065, col: 1, offset: 59450},
+			pos:  position{line: 2250, col: 1, offset: 66281},
 			expr: &seqExpr{
-				pos: position{line: 2065, col: 10, offset: 59459},
+				pos: position{line: 2250, col: 10, offset: 66290},
 				exprs: []any{
 					&ruleRefExpr{
-						pos:  position{line: 2065, col: 10, offset: 59459},
+						pos:  position{line: 2250, col: 10, offset: 66290},
 						name: "EMPTY_OR_SPACE",
 					},
 					&litMatcher{
-						pos:        position{line: 2065, col: 25, offset: 59474},
+						pos:        position{line: 2250, col: 25, offset: 66305},
 						val:        ",",
 						ignoreCase: false,
 						want:       "\",\"",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 2065, col: 29, offset: 59478},
+						pos:  position{line: 2250, col: 29, offset: 66309},
 						name: "EMPTY_OR_SPACE",
 					},
 				},
@@ -6383,18 +7017,18 @@ var g = &grammar{
 		},
 		{
 			name: "L_PAREN",
-			pos:  position{line: 2066, col: 1, offset: 59493},
+			pos:  position{line: 2251, col: 1, offset: 66324},
 			expr: &seqExpr{
-				pos: position{line: 2066, col: 12, offset: 59504},
+				pos: position{line: 2251, col: 12, offset: 66335},
 				exprs: []any{
 					&litMatcher{
-						pos:        position{line: 2066, col: 12, offset: 59504},
+						pos:        position{line: 2251, col: 12, offset: 66335},
 						val:        "(",
 						ignoreCase: false,
 						want:       "\"(\"",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 2066, col: 16, offset: 59508},
+						pos:  position{line: 2251, col: 16, offset: 66339},
 						name: "EMPTY_OR_SPACE",
 					},
 				},
@@ -6402,16 +7036,16 @@ var g = &grammar{
 		},
 		{
 			name: "R_PAREN",
-			pos:  position{line: 2067, col: 1, offset: 59523},
+			pos:  position{line: 2252, col: 1, offset: 66354},
 			expr: &seqExpr{
-				pos: position{line: 2067, col: 12, offset: 59534},
+				pos: position{line: 2252, col: 12, offset: 66365},
 				exprs: []any{
 					&ruleRefExpr{
-						pos:  position{line: 2067, col: 12, offset: 59534},
+						pos:  position{line: 2252, col: 12, offset: 66365},
 						name: "EMPTY_OR_SPACE",
 					},
 					&litMatcher{
-						pos:        position{line: 2067, col: 27, offset: 59549},
+						pos:        position{line: 2252, col: 27, offset: 66380},
 						val:        ")",
 						ignoreCase: false,
 						want:       "\")\"",
@@ -6421,40 +7055,40 @@ var g = &grammar{
 		},
 		{
 			name: "EOF",
-			pos:  position{line: 2069, col: 1, offset: 59554},
+			pos:  position{line: 2254, col: 1, offset: 66385},
 			expr: &notExpr{
-				pos: position{line: 2069, col: 8, offset: 59561},
+				pos: position{line: 2254, col: 8, offset: 66392},
 				expr: &anyMatcher{
-					line: 2069, col: 9, offset: 59562,
+					line: 2254, col: 9, offset: 66393,
 				},
 			},
 		},
 		{
 			name: "SPACE",
-			pos:  position{line: 2070, col: 1, offset: 59564},
+			pos:  position{line: 2255, col: 1, offset: 66395},
 			expr: &choiceExpr{
-				pos: position{line: 2070, col: 10, offset: 59573},
+				pos: position{line: 2255, col: 10, offset: 66404},
 				alternatives: []any{
 					&seqExpr{
-						pos: position{line: 2070, col: 11, offset: 59574},
+						pos: position{line: 2255, col: 11, offset: 66405},
 						exprs: []any{
 							&zeroOrOneExpr{
-								pos: position{line: 2070, col: 11, offset: 59574},
+								pos: position{line: 2255, col: 11, offset: 66405},
 								expr: &litMatcher{
-									pos:        position{line: 2070, col: 11, offset: 59574},
+									pos:        position{line: 2255, col: 11, offset: 66405},
 									val:        " ",
 									ignoreCase: false,
 									want:       "\" \"",
 								},
 							},
 							&ruleRefExpr{
-								pos:  position{line: 2070, col: 16, offset: 59579},
+								pos:  position{line: 2255, col: 16, offset: 66410},
 								name: "COMMENT",
 							},
 							&zeroOrOneExpr{
-								pos: position{line: 2070, col: 24, offset: 59587},
+								pos: position{line: 2255, col: 24, offset: 66418},
 								expr: &litMatcher{
-									pos:        position{line: 2070, col: 24, offset: 59587},
+									pos:        position{line: 2255, col: 24, offset: 66418},
 									val:        " ",
 									ignoreCase: false,
 									want:       "\" \"",
@@ -6463,9 +7097,9 @@ var g = &grammar{
 						},
 					},
 					&oneOrMoreExpr{
-						pos: position{line: 2070, col: 32, offset: 59595},
+						pos: position{line: 2255, col: 32, offset: 66426},
 						expr: &litMatcher{
-							pos:        position{line: 2070, col: 32, offset: 59595},
+							pos:        position{line: 2255, col: 32, offset: 66426},
 							val:        " ",
 							ignoreCase: false,
 							want:       "\" \"",
@@ -6476,38 +7110,38 @@ var g = &grammar{
 		},
 		{
 			name: "COMMENT",
-			pos:  position{line: 2071, col: 1, offset: 59600},
+			pos:  position{line: 2256, col: 1, offset: 66431},
 			expr: &seqExpr{
-				pos: position{line: 2071, col: 12, offset: 59611},
+				pos: position{line: 2256, col: 12, offset: 66442},
 				exprs: []any{
 					&litMatcher{
-						pos:        position{line: 2071, col: 12, offset: 59611},
+						pos:        position{line: 2256, col: 12, offset: 66442},
 						val:        "```",
 						ignoreCase: false,
 						want:       "\"```\"",
 					},
 					&zeroOrMoreExpr{
-						pos: position{line: 2071, col: 18, offset: 59617},
+						pos: position{line: 2256, col: 18, offset: 66448},
 						expr: &seqExpr{
-							pos: position{line: 2071, col: 19, offset: 59618},
+							pos: position{line: 2256, col: 19, offset: 66449},
 							exprs: []any{
 								&notExpr{
-									pos: position{line: 2071, col: 19, offset: 59618},
+									pos: position{line: 2256, col: 19, offset: 66449},
 									expr: &litMatcher{
-										pos:        position{line: 2071, col: 21, offset: 59620},
+										pos:        position{line: 2256, col: 21, offset: 66451},
 										val:        "```",
 										ignoreCase: false,
 										want:       "\"```\"",
 									},
 								},
 								&anyMatcher{
-									line: 2071, col: 28, offset: 59627,
+									line: 2256, col: 28, offset: 66458,
 								},
 							},
 						},
 					},
 					&litMatcher{
-						pos:        position{line: 2071, col: 32, offset: 59631},
+						pos:        position{line: 2256, col: 32, offset: 66462},
 						val:        "```",
 						ignoreCase: false,
 						want:       "\"```\"",
@@ -6517,16 +7151,16 @@ var g = &grammar{
 		},
 		{
 			name: "EMPTY_OR_SPACE",
-			pos:  position{line: 2072, col: 1, offset: 59637},
+			pos:  position{line: 2257, col: 1, offset: 66468},
 			expr: &choiceExpr{
-				pos: position{line: 2072, col: 20, offset: 59656},
+				pos: position{line: 2257, col: 20, offset: 66487},
 				alternatives: []any{
 					&ruleRefExpr{
-						pos:  position{line: 2072, col: 20, offset: 59656},
+						pos:  position{line: 2257, col: 20, offset: 66487},
 						name: "SPACE",
 					},
 					&litMatcher{
-						pos:        position{line: 2072, col: 28, offset: 59664},
+						pos:        position{line: 2257, col: 28, offset: 66495},
 						val:        "",
 						ignoreCase: false,
 						want:       "\"\"",
@@ -6539,6 +7173,10 @@ var g = &grammar{
 
 func (c *current) onStart1(initialSearch, filterBlocks, queryAggBlocks any) (any, error) {
 	var q ast.QueryStruct
+	if spanSearch, ok := initialSearch.(*spanSearchTemp); ok {
+		q.SpanSearch = spanSearch.request
+		initialSearch = spanSearch.filter
+	}
 	q.SearchFilter = initialSearch.(*ast.Node)
 
 	// Join the InitialSearchBlock with the FilterBlocks with AND nodes. For a
@@ -6626,6 +7264,140 @@ func (p *parser) callonInitialSearchBlock1() (any, error) {
 	return p.cur.onInitialSearchBlock1(stack["clause"])
 }
 
+func (c *current) onSpanSearchBlock1(args any) (any, error) {
+	spanSearch := &spanSearchTemp{
+		request: &structs.SpanSearchRequest{MaxTraces: structs.DEFAULT_SPAN_SEARCH_MAX_TRACES},
+	}
+	for _, v := range args.([]any) {
+		switch arg := v.([]any)[1].(type) {
+		case *ast.Node:
+			if spanSearch.filter == nil {
+				spanSearch.filter = arg
+			} else {
+				spanSearch.filter = &ast.Node{
+					NodeType: ast.NodeAnd,
+					Left:     spanSearch.filter,
+					Right:    arg,
+				}
+			}
+		case func(*structs.SpanSearchRequest):
+			arg(spanSearch.request)
+		}
+	}
+	if spanSearch.filter == nil {
+		spanSearch.filter = createSpanSearchCondition("*", "=", "\"*\"")
+	}
+	return spanSearch, nil
+}
+
+func (p *parser) callonSpanSearchBlock1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSpanSearchBlock1(stack["args"])
+}
+
+func (c *current) onSpanSearchArg2(value any) (any, error) {
+	return createSpanSearchCondition("service", "=", value), nil
+}
+
+func (p *parser) callonSpanSearchArg2() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSpanSearchArg2(stack["value"])
+}
+
+func (c *current) onSpanSearchArg8(value any) (any, error) {
+	return createSpanSearchCondition("name", "=", value), nil
+}
+
+func (p *parser) callonSpanSearchArg8() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSpanSearchArg8(stack["value"])
+}
+
+func (c *current) onSpanSearchArg14(key, value any) (any, error) {
+	if strings.Contains(key.(string), "*") {
+		return nil, fmt.Errorf("Spl peg: spansearch: invalid tag key %v", key)
+	}
+	return createSpanSearchCondition(key.(string), "=", value), nil
+}
+
+func (p *parser) callonSpanSearchArg14() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSpanSearchArg14(stack["key"], stack["value"])
+}
+
+func (c *current) onSpanSearchArg22(durationArg, duration any) (any, error) {
+	op := ">="
+	if string(durationArg.([]byte)) == "maxduration" {
+		op = "<="
+	}
+	nanos := strconv.FormatInt(duration.(time.Duration).Nanoseconds(), 10)
+	return createSpanSearchCondition("duration", op, json.Number(nanos)), nil
+}
+
+func (p *parser) callonSpanSearchArg22() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSpanSearchArg22(stack["durationArg"], stack["duration"])
+}
+
+func (c *current) onSpanSearchArg31(isError any) (any, error) {
+	op := "="
+	if string(isError.([]byte)) == "false" {
+		op = "!="
+	}
+	return createSpanSearchCondition("status", op, "\"STATUS_CODE_ERROR\""), nil
+}
+
+func (p *parser) callonSpanSearchArg31() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSpanSearchArg31(stack["isError"])
+}
+
+func (c *current) onSpanSearchArg39(returnType any) (any, error) {
+	returnTraces := string(returnType.([]byte)) == "traces"
+	return func(request *structs.SpanSearchRequest) { request.ReturnTraces = returnTraces }, nil
+}
+
+func (p *parser) callonSpanSearchArg39() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSpanSearchArg39(stack["returnType"])
+}
+
+func (c *current) onSpanSearchArg47(intAsStr any) (any, error) {
+	maxTraces, err := strconv.Atoi(intAsStr.(string))
+	if err != nil || maxTraces <= 0 || maxTraces > structs.MAX_SPAN_SEARCH_MAX_TRACES {
+		return nil, fmt.Errorf("Spl peg: spansearch: invalid maxtraces %v, expected a number from 1 to %v",
+			intAsStr, structs.MAX_SPAN_SEARCH_MAX_TRACES)
+	}
+	return func(request *structs.SpanSearchRequest) { request.MaxTraces = maxTraces }, nil
+}
+
+func (p *parser) callonSpanSearchArg47() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSpanSearchArg47(stack["intAsStr"])
+}
+
+func (c *current) onSpanSearchDuration1() (any, error) {
+	duration, err := time.ParseDuration(string(c.text))
+	if err != nil {
+		return nil, fmt.Errorf("Spl peg: spansearch: invalid duration %v", string(c.text))
+	}
+	return duration, nil
+}
+
+func (p *parser) callonSpanSearchDuration1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSpanSearchDuration1()
+}
+
 func (c *current) onSearchBlock1(clause any) (any, error) {
 	return clause, nil
 }
@@ -7449,6 +8221,113 @@ func (p *parser) callonRexBlock1() (any, error) {
 	return p.cur.onRexBlock1(stack["field"], stack["str"])
 }
 
+func (c *current) onLookupBlock1(lookupName, matchField, eventField, outputFields any) (any, error) {
+	lookupExpr := &structs.LookupExpr{
+		LookupName: lookupName.(string),
+		MatchField: matchField.(string),
+		EventField: matchField.(string),
+	}
+
+	if eventField != nil {
+		lookupExpr.EventField = eventField.([]any)[1].(string)
+	}
+
+	if outputFields != nil {
+		lookupExpr.OutputFields = outputFields.([]any)[3].([]string)
+	}
+
+	allFields := append([]string{lookupExpr.LookupName, lookupExpr.MatchField, lookupExpr.EventField}, lookupExpr.OutputFields...)
+	for _, field := range allFields {
+		if strings.Contains(field, "*") {
+			return nil, fmt.Errorf("Spl peg: LookupBlock: wildcards are not allowed in lookup fields: %v", field)
+		}
+	}
+
+	letColReq := &structs.LetColumnsRequest{
+		LookupColRequest: lookupExpr,
+	}
+
+	root := &structs.QueryAggregators{
+		PipeCommandType: structs.OutputTransformType,
+		OutputTransforms: &structs.OutputTransforms{
+			LetColumns: letColReq,
+		},
+	}
+
+	return root, nil
+}
+
+func (p *parser) callonLookupBlock1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onLookupBlock1(stack["lookupName"], stack["matchField"], stack["eventField"], stack["outputFields"])
+}
+
+func (c *current) onSimilarToBlock1(example, options any) (any, error) {
+	similarTo := &structs.SimilarToRequest{
+		Example:   removeQuotes(example),
+		FieldName: "message",
+		Threshold: 0.8,
+	}
+
+	// Each element of options is a slice where the second element is a [2]string of the option name and value.
+	for _, spaceAndOption := range options.([]any) {
+		option := spaceAndOption.([]any)[1].([2]string)
+		switch option[0] {
+		case "field":
+			similarTo.FieldName = option[1]
+		case "threshold":
+			threshold, err := strconv.ParseFloat(option[1], 64)
+			if err != nil {
+				return nil, fmt.Errorf("Spl peg: SimilarToBlock: invalid threshold %v, err: %v", option[1], err)
+			}
+			if threshold < -1 || threshold > 1 {
+				return nil, fmt.Errorf("Spl peg: SimilarToBlock: threshold must be between -1 and 1, got %v", threshold)
+			}
+			similarTo.Threshold = threshold
+		}
+	}
+
+	if similarTo.Example == "" {
+		return nil, errors.New("Spl peg: SimilarToBlock: example message cannot be empty")
+	}
+
+	root := &structs.QueryAggregators{
+		PipeCommandType: structs.OutputTransformType,
+		OutputTransforms: &structs.OutputTransforms{
+			SimilarTo: similarTo,
+		},
+	}
+
+	return root, nil
+}
+
+func (p *parser) callonSimilarToBlock1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSimilarToBlock1(stack["example"], stack["options"])
+}
+
+func (c *current) onSimilarToOption2(field any) (any, error) {
+	return [2]string{"field", field.(string)}, nil
+}
+
+func (p *parser) callonSimilarToOption2() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSimilarToOption2(stack["field"])
+}
+
+func (c *current) onSimilarToOption8(threshold any) (any, error) {
+	return [2]string{"threshold", threshold.(string)}, nil
+}
+
+func (p *parser) callonSimilarToOption8() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSimilarToOption8(stack["threshold"])
+}
+
 func (c *current) onEvalBlock1(first, rest any) (any, error) {
 	root := &structs.QueryAggregators{
 		PipeCommandType: structs.OutputTransformType,
@@ -4658,3 +4658,121 @@ func Test_headWithLimitKeyword(t *testing.T) {
 	assert.Equal(t, aggregator.PipeCommandType, structs.OutputTransformType)
 	assert.Equal(t, aggregator.OutputTransforms.MaxRows, uint64(15))
 }
+
+func Test_lookup(t *testing.T) {
+	query := []byte(`A=1 | lookup users user_id`)
+	res, err := spl.Parse("", query)
+	assert.Nil(t, err)
+	filterNode := res.(ast.QueryStruct).SearchFilter
+	assert.NotNil(t, filterNode)
+
+	astNode, aggregator, err := pipesearch.ParseQuery(string(query), 0, "Splunk QL")
+	assert.Nil(t, err)
+	assert.NotNil(t, astNode)
+	assert.NotNil(t, aggregator)
+	assert.Nil(t, aggregator.Next)
+
+	assert.Equal(t, aggregator.PipeCommandType, structs.OutputTransformType)
+	assert.NotNil(t, aggregator.OutputTransforms.LetColumns)
+	assert.NotNil(t, aggregator.OutputTransforms.LetColumns.LookupColRequest)
+	assert.Equal(t, aggregator.OutputTransforms.LetColumns.LookupColRequest.LookupName, "users")
+	assert.Equal(t, aggregator.OutputTransforms.LetColumns.LookupColRequest.MatchField, "user_id")
+	assert.Equal(t, aggregator.OutputTransforms.LetColumns.LookupColRequest.EventField, "user_id")
+	assert.Nil(t, aggregator.OutputTransforms.LetColumns.LookupColRequest.OutputFields)
+}
+
+func Test_lookupWithAsAndOutput(t *testing.T) {
+	query := []byte(`A=1 | lookup users id AS userId OUTPUT name, team`)
+	res, err := spl.Parse("", query)
+	assert.Nil(t, err)
+	filterNode := res.(ast.QueryStruct).SearchFilter
+	assert.NotNil(t, filterNode)
+
+	astNode, aggregator, err := pipesearch.ParseQuery(string(query), 0, "Splunk QL")
+	assert.Nil(t, err)
+	assert.NotNil(t, astNode)
+	assert.NotNil(t, aggregator)
+	assert.Nil(t, aggregator.Next)
+
+	assert.Equal(t, aggregator.PipeCommandType, structs.OutputTransformType)
+	assert.NotNil(t, aggregator.OutputTransforms.LetColumns)
+	assert.NotNil(t, aggregator.OutputTransforms.LetColumns.LookupColRequest)
+	assert.Equal(t, aggregator.OutputTransforms.LetColumns.LookupColRequest.LookupName, "users")
+	assert.Equal(t, aggregator.OutputTransforms.LetColumns.LookupColRequest.MatchField, "id")
+	assert.Equal(t, aggregator.OutputTransforms.LetColumns.LookupColRequest.EventField, "userId")
+	assert.Equal(t, aggregator.OutputTransforms.LetColumns.LookupColRequest.OutputFields, []string{"name", "team"})
+}
+
+func Test_lookupWithWildcard(t *testing.T) {
+	query := []byte(`A=1 | lookup users id OUTPUT na*`)
+	res, err := spl.Parse("", query)
+	assert.NotNil(t, err)
+	assert.Nil(t, res)
+}
+
+func Test_similarTo(t *testing.T) {
+	query := []byte(`A=1 | similar_to "connection refused by 10.0.0.1"`)
+	res, err := spl.Parse("", query)
+	assert.Nil(t, err)
+	filterNode := res.(ast.QueryStruct).SearchFilter
+	assert.NotNil(t, filterNode)
+
+	astNode, aggregator, err := pipesearch.ParseQuery(string(query), 0, "Splunk QL")
+	assert.Nil(t, err)
+	assert.NotNil(t, astNode)
+	assert.NotNil(t, aggregator)
+	assert.Nil(t, aggregator.Next)
+
+	assert.Equal(t, aggregator.PipeCommandType, structs.OutputTransformType)
+	assert.NotNil(t, aggregator.OutputTransforms.SimilarTo)
+	assert.Equal(t, aggregator.OutputTransforms.SimilarTo.Example, "connection refused by 10.0.0.1")
+	assert.Equal(t, aggregator.OutputTransforms.SimilarTo.FieldName, "message")
+	assert.Equal(t, aggregator.OutputTransforms.SimilarTo.Threshold, 0.8)
+}
+
+func Test_similarToWithOptions(t *testing.T) {
+	query := []byte(`A=1 | similar_to "disk full" threshold=0.65 field=log`)
+	res, err := spl.Parse("", query)
+	assert.Nil(t, err)
+	filterNode := res.(ast.QueryStruct).SearchFilter
+	assert.NotNil(t, filterNode)
+
+	astNode, aggregator, err := pipesearch.ParseQuery(string(query), 0, "Splunk QL")
+	assert.Nil(t, err)
+	assert.NotNil(t, astNode)
+	assert.NotNil(t, aggregator)
+	assert.Nil(t, aggregator.Next)
+
+	assert.Equal(t, aggregator.PipeCommandType, structs.OutputTransformType)
+	assert.NotNil(t, aggregator.OutputTransforms.SimilarTo)
+	assert.Equal(t, aggregator.OutputTransforms.SimilarTo.Example, "disk full")
+	assert.Equal(t, aggregator.OutputTransforms.SimilarTo.FieldName, "log")
+	assert.Equal(t, aggregator.OutputTransforms.SimilarTo.Threshold, 0.65)
+}
+
+func Test_similarToInvalidThreshold(t *testing.T) {
+	query := []byte(`A=1 | similar_to "disk full" threshold=2`)
+	res, err := spl.Parse("", query)
+	assert.NotNil(t, err)
+	assert.Nil(t, res)
+}
+
+func Test_spanSearch(t *testing.T) {
+	query := []byte(`spansearch minduration=1.5s error=false return=traces | stats count BY service`)
+	res, err := spl.Parse("", query)
+	assert.Nil(t, err)
+	assert.Equal(t, &structs.SpanSearchRequest{ReturnTraces: true, MaxTraces: structs.DEFAULT_SPAN_SEARCH_MAX_TRACES},
+		res.(ast.QueryStruct).SpanSearch)
+
+	filterNode := res.(ast.QueryStruct).SearchFilter
+	assert.NotNil(t, filterNode)
+	assert.Equal(t, ast.NodeAnd, filterNode.NodeType)
+	assert.Equal(t, ast.Comparison{Op: ">=", Field: "duration", Values: json.Number("1500000000")}, filterNode.Left.Comparison)
+	assert.Equal(t, ast.Comparison{Op: "!=", Field: "status", Values: `"STATUS_CODE_ERROR"`}, filterNode.Right.Comparison)
+
+	astNode, aggregator, err := pipesearch.ParseQuery(string(query), 0, "Splunk QL")
+	assert.Nil(t, err)
+	assert.NotNil(t, astNode)
+	assert.Equal(t, structs.GroupByType, aggregator.PipeCommandType)
+	assert.True(t, aggregator.SpanSearch.ReturnTraces)
+}
@@ -28,6 +28,8 @@ import (
 type QueryStruct struct {
 	SearchFilter *Node
 	PipeCommands *structs.QueryAggregators
+	TableName    string // set by the languages whose queries name the indices they read, like PPL
+	SpanSearch   *structs.SpanSearchRequest
 }
 
 // NodeType represents the type of a node in the parse tree
@@ -58,10 +60,16 @@ type Comparison struct {
 	ValueIsRegex bool // True if Values is a regex string. False if Values is a wildcarded string or anything else.
 }
 
+// Line filters of LogQL, they match a substring of the log line
 type GrepValue struct {
 	Field string
 }
 
+// Index and column of the log lines ingested through Loki. The LogQL line filters search all the columns, except on
+// this index where they are applied to the log lines
+const LokiIndexName = "loki-index"
+const LogLineColumn = "line"
+
 // ParseError is the exported error type for parsing errors with detailed information as to where they occurred
 type ParseError struct {
 	Inner    error    `json:"inner"`
@@ -150,3 +150,15 @@ func GetFileSize(filename string) uint64 {
 func DoesMetaFileExistInBlob(fName string) (bool, error) {
 	return false, nil
 }
+
+// Returns all the seg set files that the blob store has
+func GetAllSegSetFiles() []string {
+	return local.GetAllSegSetFiles()
+}
+
+// Removes the seg set files from the blob store, leaving the files on the disk as they are
+func ForgetSegSetFiles(files []string) {
+	for _, segSetFile := range files {
+		local.ForgetSegSetFile(segSetFile)
+	}
+}
@@ -253,3 +253,24 @@ func SetBlobAsNotInUse(segSetFile string) error {
 	return fmt.Errorf("tried to mark segSetFile: %+v as not use that does not exist in localstorage",
 		segSetFile)
 }
+
+// Returns the names of all the seg set files in the local storage
+func GetAllSegSetFiles() []string {
+	segSetKeysLock.Lock()
+	defer segSetKeysLock.Unlock()
+	retVal := make([]string, 0, len(segSetKeys))
+	for fName := range segSetKeys {
+		retVal = append(retVal, fName)
+	}
+	return retVal
+}
+
+/*
+Removes the segSetFile from the local SegSetKeys struct without deleting it from the disk, for the files that were
+already deleted or moved
+*/
+func ForgetSegSetFile(fName string) {
+	segSetKeysLock.Lock()
+	defer segSetKeysLock.Unlock()
+	delete(segSetKeys, fName)
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"encoding/json"
+
+	"github.com/siglens/siglens/pkg/utils"
+	log "github.com/sirupsen/logrus"
+	"github.com/valyala/fasthttp"
+)
+
+func setErrMsg(ctx *fasthttp.RequestCtx, statusCode int, message string) {
+	var httpResp utils.HttpServerResponse
+	ctx.SetStatusCode(statusCode)
+	httpResp.Message = message
+	httpResp.StatusCode = statusCode
+	utils.WriteResponse(ctx, httpResp)
+}
+
+// Handles GET /api/cluster/nodes
+func ProcessListNodesRequest(ctx *fasthttp.RequestCtx) {
+	utils.WriteJsonResponse(ctx, ListNodes())
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
+
+// Handles POST /api/cluster/nodes, the body is {"nodeId": "...", "address": "host:5122", "grpcAddress": "host:50051", "roles": ["query"]}
+func ProcessRegisterNodeRequest(ctx *fasthttp.RequestCtx) {
+	request := struct {
+		NodeId      string   `json:"nodeId"`
+		Address     string   `json:"address"`
+		GrpcAddress string   `json:"grpcAddress"`
+		Roles       []string `json:"roles"`
+	}{}
+	err := json.Unmarshal(ctx.PostBody(), &request)
+	if err != nil {
+		log.Errorf("ProcessRegisterNodeRequest: could not unmarshal the request, err=%v", err)
+		setErrMsg(ctx, fasthttp.StatusBadRequest, "Bad Request")
+		return
+	}
+	node, err := RegisterNode(request.NodeId, request.Address, request.GrpcAddress, request.Roles)
+	if err != nil {
+		log.Errorf("ProcessRegisterNodeRequest: could not register node %v, err=%v", request.NodeId, err)
+		setErrMsg(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	utils.WriteJsonResponse(ctx, node)
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
+
+// Handles DELETE /api/cluster/nodes/{node-id}
+func ProcessRemoveNodeRequest(ctx *fasthttp.RequestCtx) {
+	nodeId := utils.ExtractParamAsString(ctx.UserValue("node-id"))
+	err := RemoveNode(nodeId)
+	if err != nil {
+		log.Errorf("ProcessRemoveNodeRequest: could not remove node %v, err=%v", nodeId, err)
+		setErrMsg(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	utils.WriteJsonResponse(ctx, "Node removed successfully")
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
+
+/*
+Handles POST /api/cluster/nodes/{node-id}/drain, the optional body {"draining": false} puts the node back in service.
+Draining a peer is forwarded to it
+*/
+func ProcessDrainNodeRequest(ctx *fasthttp.RequestCtx) {
+	nodeId := utils.ExtractParamAsString(ctx.UserValue("node-id"))
+	request := struct {
+		Draining bool `json:"draining"`
+	}{Draining: true}
+	if len(ctx.PostBody()) > 0 {
+		err := json.Unmarshal(ctx.PostBody(), &request)
+		if err != nil {
+			log.Errorf("ProcessDrainNodeRequest: could not unmarshal the request, err=%v", err)
+			setErrMsg(ctx, fasthttp.StatusBadRequest, "Bad Request")
+			return
+		}
+	}
+	node, err := SetNodeDraining(nodeId, request.Draining, getPeerAuth(ctx))
+	if err != nil {
+		log.Errorf("ProcessDrainNodeRequest: could not set node %v to draining=%v, err=%v", nodeId, request.Draining, err)
+		setErrMsg(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	utils.WriteJsonResponse(ctx, node)
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
+
+/*
+Handles GET /api/cluster/ownership. The nodeId and indexName query parameters limit the response to a node or an
+index, and segments=true adds the keys of the segments
+*/
+func ProcessSegmentOwnershipRequest(ctx *fasthttp.RequestCtx, myid uint64) {
+	filter := OwnershipFilter{
+		NodeId:      string(ctx.QueryArgs().Peek("nodeId")),
+		IndexName:   string(ctx.QueryArgs().Peek("indexName")),
+		WithSegKeys: ctx.QueryArgs().GetBool("segments"),
+	}
+	utils.WriteJsonResponse(ctx, GetSegmentOwnership(filter, myid))
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
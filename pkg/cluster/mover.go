@@ -0,0 +1,474 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/siglens/siglens/pkg/blob"
+	"github.com/siglens/siglens/pkg/common/fileutils"
+	"github.com/siglens/siglens/pkg/config"
+	"github.com/siglens/siglens/pkg/segment/query/metadata"
+	pqsmeta "github.com/siglens/siglens/pkg/segment/query/pqs/meta"
+	"github.com/siglens/siglens/pkg/segment/structs"
+	segwriter "github.com/siglens/siglens/pkg/segment/writer"
+	"github.com/siglens/siglens/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+/*
+	Moves the rotated segments of the local node to the other nodes. A move copies the files of the segment to the
+	target, which verifies their checksums and adds the segment to its segmeta and its query metadata, so that its
+	queries search it. Only then is the segment removed from the local node. A move that fails or is cancelled has the
+	target delete the files it received. The copies of all the moves share the configured rate.
+
+	The queries are not routed to the node that has a segment, every node searches the segmetas that it reads from
+	the ingest nodes dir. The other nodes only see that a segment moved once they read the segmetas of the source and
+	the target again, until then their queries can miss the moved segments.
+*/
+
+const SEGMENT_FILE_CHUNK_SIZE = 4 * 1024 * 1024
+const DEFAULT_REBALANCE_BYTES_PER_SEC = 50 * 1024 * 1024
+const DEFAULT_MAX_CONCURRENT_MOVES = 2
+const DEFAULT_MAX_MOVES_PER_RUN = 1000
+
+const (
+	MOVE_PENDING   = "pending"
+	MOVE_COPYING   = "copying"
+	MOVE_DONE      = "done"
+	MOVE_FAILED    = "failed"
+	MOVE_CANCELLED = "cancelled"
+)
+
+type SegmentMove struct {
+	SegmentKey   string `json:"segmentKey"`
+	IndexName    string `json:"indexName"`
+	TargetNodeId string `json:"targetNodeId"`
+	Bytes        uint64 `json:"bytes"`
+	State        string `json:"state"`
+	Error        string `json:"error,omitempty"`
+}
+
+type MoverStatus struct {
+	Running        bool           `json:"running"`
+	Cancelled      bool           `json:"cancelled"`
+	StartedAt      uint64         `json:"startedAt"` // in ms
+	FinishedAt     uint64         `json:"finishedAt,omitempty"`
+	MovedSegments  uint64         `json:"movedSegments"`
+	FailedSegments uint64         `json:"failedSegments"`
+	MovedBytes     uint64         `json:"movedBytes"`
+	Moves          []*SegmentMove `json:"moves"`
+}
+
+type copyThrottle struct {
+	lock     sync.Mutex
+	nextFree time.Time
+}
+
+var moverLock = &sync.Mutex{}
+var moverStatus = &MoverStatus{Moves: make([]*SegmentMove, 0)}
+var throttle = &copyThrottle{}
+
+// Waits until numBytes more can be copied. The rate is read on every call, so that a reload of the config applies
+// to the moves in progress
+func (ct *copyThrottle) wait(numBytes uint64) {
+	bytesPerSec := config.GetRebalanceConfig().MaxBytesPerSec
+	if bytesPerSec == 0 {
+		bytesPerSec = DEFAULT_REBALANCE_BYTES_PER_SEC
+	}
+
+	ct.lock.Lock()
+	now := time.Now()
+	if ct.nextFree.Before(now) {
+		ct.nextFree = now
+	}
+	startAt := ct.nextFree
+	ct.nextFree = ct.nextFree.Add(time.Duration(float64(numBytes) / float64(bytesPerSec) * float64(time.Second)))
+	ct.lock.Unlock()
+
+	time.Sleep(time.Until(startAt))
+}
+
+func getMoverLimits() (int, int) {
+	rebalanceCfg := config.GetRebalanceConfig()
+	maxConcurrent := int(rebalanceCfg.MaxConcurrentMoves)
+	if maxConcurrent == 0 {
+		maxConcurrent = DEFAULT_MAX_CONCURRENT_MOVES
+	}
+	maxMoves := int(rebalanceCfg.MaxMovesPerRun)
+	if maxMoves == 0 {
+		maxMoves = DEFAULT_MAX_MOVES_PER_RUN
+	}
+	return maxConcurrent, maxMoves
+}
+
+/*
+Picks the segments of the index that make up the planned bytes, largest first. A segment that is larger than what
+is left is still picked when more than half of it is left, so that the moved bytes are as close to the plan as the
+sizes of the segments allow. Segments in pickedKeys are skipped and the picked ones are added to it
+*/
+func selectSegments(segmetas []*structs.SegMeta, indexName string, bytes uint64, orgid uint64,
+	pickedKeys map[string]struct{}) []*structs.SegMeta {
+
+	candidates := make([]*structs.SegMeta, 0)
+	for _, segMeta := range segmetas {
+		if segMeta.VirtualTableName != indexName || segMeta.OrgId != orgid {
+			continue
+		}
+		if _, ok := pickedKeys[segMeta.SegmentKey]; ok {
+			continue
+		}
+		candidates = append(candidates, segMeta)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].OnDiskBytes != candidates[j].OnDiskBytes {
+			return candidates[i].OnDiskBytes > candidates[j].OnDiskBytes
+		}
+		return candidates[i].SegmentKey < candidates[j].SegmentKey
+	})
+
+	retVal := make([]*structs.SegMeta, 0)
+	remaining := bytes
+	for _, segMeta := range candidates {
+		if remaining == 0 {
+			break
+		}
+		if segMeta.OnDiskBytes > remaining && remaining <= segMeta.OnDiskBytes/2 {
+			continue
+		}
+		retVal = append(retVal, segMeta)
+		pickedKeys[segMeta.SegmentKey] = struct{}{}
+		if segMeta.OnDiskBytes >= remaining {
+			remaining = 0
+		} else {
+			remaining -= segMeta.OnDiskBytes
+		}
+	}
+	return retVal
+}
+
+/*
+Starts moving the segments for the transfers whose source is the local node, the others are ignored. peerAuth is
+sent with the requests to the targets. Fails if the moves of an earlier rebalance are still running
+*/
+func StartMoves(transfers []*PlannedTransfer, peerAuth PeerAuth, orgid uint64) (*MoverStatus, error) {
+	moverLock.Lock()
+	defer moverLock.Unlock()
+	if moverStatus.Running {
+		return nil, fmt.Errorf("the segment moves of the last rebalance are still running")
+	}
+
+	localSegmetas := segwriter.ReadLocalSegmeta()
+	_, maxMoves := getMoverLimits()
+	pickedKeys := make(map[string]struct{})
+	allMoves := make([]*SegmentMove, 0)
+	segMetas := make(map[string]*structs.SegMeta)
+	for _, transfer := range transfers {
+		if transfer.SourceNodeId != config.GetHostID() {
+			continue
+		}
+		if _, ok := GetNode(transfer.TargetNodeId); !ok {
+			return nil, fmt.Errorf("target node %v is not registered", transfer.TargetNodeId)
+		}
+		for _, segMeta := range selectSegments(localSegmetas, transfer.IndexName, transfer.Bytes, orgid, pickedKeys) {
+			if len(allMoves) >= maxMoves {
+				break
+			}
+			allMoves = append(allMoves, &SegmentMove{
+				SegmentKey:   segMeta.SegmentKey,
+				IndexName:    segMeta.VirtualTableName,
+				TargetNodeId: transfer.TargetNodeId,
+				Bytes:        segMeta.OnDiskBytes,
+				State:        MOVE_PENDING,
+			})
+			segMetas[segMeta.SegmentKey] = segMeta
+		}
+	}
+
+	moverStatus = &MoverStatus{
+		Running:   len(allMoves) > 0,
+		StartedAt: utils.GetCurrentTimeInMs(),
+		Moves:     allMoves,
+	}
+	if len(allMoves) == 0 {
+		moverStatus.FinishedAt = moverStatus.StartedAt
+	} else {
+		log.Infof("StartMoves: moving %v segments to the other nodes", len(allMoves))
+		go runMoves(moverStatus, segMetas, peerAuth)
+	}
+	return moverStatus.copy(), nil
+}
+
+// Stops the moves that have not been copied yet, the segments being copied stay on the local node
+func CancelMoves() *MoverStatus {
+	moverLock.Lock()
+	defer moverLock.Unlock()
+	if moverStatus.Running {
+		moverStatus.Cancelled = true
+	}
+	return moverStatus.copy()
+}
+
+func GetMoverStatus() *MoverStatus {
+	moverLock.Lock()
+	defer moverLock.Unlock()
+	return moverStatus.copy()
+}
+
+// Needs the lock to be held
+func (status *MoverStatus) copy() *MoverStatus {
+	retVal := *status
+	retVal.Moves = make([]*SegmentMove, 0, len(status.Moves))
+	for _, move := range status.Moves {
+		moveCopy := *move
+		retVal.Moves = append(retVal.Moves, &moveCopy)
+	}
+	return &retVal
+}
+
+func isCancelled(status *MoverStatus) bool {
+	moverLock.Lock()
+	defer moverLock.Unlock()
+	return status.Cancelled
+}
+
+func setMoveState(status *MoverStatus, move *SegmentMove, state string, err error) {
+	moverLock.Lock()
+	defer moverLock.Unlock()
+	move.State = state
+	switch state {
+	case MOVE_DONE:
+		status.MovedSegments++
+		status.MovedBytes += move.Bytes
+	case MOVE_FAILED:
+		status.FailedSegments++
+		move.Error = err.Error()
+	}
+}
+
+func runMoves(status *MoverStatus, segMetas map[string]*structs.SegMeta, peerAuth PeerAuth) {
+	maxConcurrent, _ := getMoverLimits()
+	moveChan := make(chan *SegmentMove)
+	waitGroup := &sync.WaitGroup{}
+	for i := 0; i < maxConcurrent; i++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for move := range moveChan {
+				if isCancelled(status) {
+					setMoveState(status, move, MOVE_CANCELLED, nil)
+					continue
+				}
+				setMoveState(status, move, MOVE_COPYING, nil)
+				err := moveSegment(status, segMetas[move.SegmentKey], move.TargetNodeId, peerAuth)
+				if err == errMoveCancelled {
+					setMoveState(status, move, MOVE_CANCELLED, nil)
+				} else if err != nil {
+					log.Errorf("runMoves: failed to move segkey=%v to node %v, err=%v", move.SegmentKey,
+						move.TargetNodeId, err)
+					setMoveState(status, move, MOVE_FAILED, err)
+				} else {
+					setMoveState(status, move, MOVE_DONE, nil)
+				}
+			}
+		}()
+	}
+	for _, move := range status.Moves {
+		moveChan <- move
+	}
+	close(moveChan)
+	waitGroup.Wait()
+
+	moverLock.Lock()
+	defer moverLock.Unlock()
+	status.Running = false
+	status.FinishedAt = utils.GetCurrentTimeInMs()
+	log.Infof("runMoves: finished, moved %v segments with %v bytes, %v failed", status.MovedSegments,
+		status.MovedBytes, status.FailedSegments)
+}
+
+var errMoveCancelled = fmt.Errorf("the move was cancelled")
+
+// Copies the segment to the target, has the target adopt it and removes it from the local node
+func moveSegment(status *MoverStatus, segMeta *structs.SegMeta, targetNodeId string, peerAuth PeerAuth) error {
+	target, ok := GetNode(targetNodeId)
+	if !ok {
+		return fmt.Errorf("target node %v is not registered", targetNodeId)
+	}
+	segDir, err := getSegmentDir(segMeta.SegmentKey)
+	if err != nil {
+		return err
+	}
+
+	checksums := make(map[string]string)
+	for _, relFname := range getSegmentFiles(segDir) {
+		if isCancelled(status) {
+			abortOnTarget(target, segMeta.SegmentKey, peerAuth)
+			return errMoveCancelled
+		}
+		checksum, err := getFileChecksum(segDir + relFname)
+		if err != nil {
+			log.Errorf("moveSegment: failed to get the checksum of fname=%v, err=%v", segDir+relFname, err)
+			abortOnTarget(target, segMeta.SegmentKey, peerAuth)
+			return err
+		}
+		checksums[relFname] = checksum
+		err = copySegmentFile(status, target, segMeta.SegmentKey, segDir, relFname, peerAuth)
+		if err != nil {
+			abortOnTarget(target, segMeta.SegmentKey, peerAuth)
+			return err
+		}
+	}
+	if len(checksums) == 0 {
+		return fmt.Errorf("segment %v has no files", segMeta.SegmentKey)
+	}
+
+	body, err := marshalAdoptRequest(segMeta, checksums)
+	if err != nil {
+		abortOnTarget(target, segMeta.SegmentKey, peerAuth)
+		return err
+	}
+	_, err = sendToNode(target, http.MethodPost, "/api/cluster/segments/adopt", url.Values{}, body, peerAuth)
+	if err != nil {
+		// the target may have adopted the segment and only the response was lost, then the local copy has to go
+		adopted, checkErr := isSegmentOnNode(target, segMeta, peerAuth)
+		if checkErr != nil || !adopted {
+			abortOnTarget(target, segMeta.SegmentKey, peerAuth)
+			return err
+		}
+		log.Infof("moveSegment: node %v adopted segkey=%v although the adopt request failed, err=%v", targetNodeId,
+			segMeta.SegmentKey, err)
+	}
+
+	// the target searches the segment from now on
+	removeLocalSegment(segMeta)
+	log.Infof("moveSegment: moved segkey=%v with %v bytes to node %v", segMeta.SegmentKey, segMeta.OnDiskBytes,
+		targetNodeId)
+	return nil
+}
+
+// Asks the node whether the segment is in its segmeta
+func isSegmentOnNode(node *Node, segMeta *structs.SegMeta, peerAuth PeerAuth) (bool, error) {
+	params := url.Values{}
+	params.Set("nodeId", node.NodeId)
+	params.Set("indexName", segMeta.VirtualTableName)
+	params.Set("segments", "true")
+	respBody, err := sendToNode(node, http.MethodGet, "/api/cluster/ownership", params, nil, peerAuth)
+	if err != nil {
+		log.Errorf("isSegmentOnNode: failed to get the segments of node %v, err=%v", node.NodeId, err)
+		return false, err
+	}
+	allOwnership := make([]*NodeOwnership, 0)
+	err = json.Unmarshal(respBody, &allOwnership)
+	if err != nil {
+		log.Errorf("isSegmentOnNode: failed to unmarshal the segments of node %v, err=%v", node.NodeId, err)
+		return false, err
+	}
+	for _, ownership := range allOwnership {
+		if ownership.NodeId != node.NodeId {
+			continue
+		}
+		for _, index := range ownership.Indices {
+			for _, segKey := range index.SegmentKeys {
+				if segKey == segMeta.SegmentKey {
+					return true, nil
+				}
+			}
+		}
+	}
+	return false, nil
+}
+
+// Has the target delete the files it received for the segment, a failure only leaves them for the integrity check
+func abortOnTarget(target *Node, segKey string, peerAuth PeerAuth) {
+	params := url.Values{}
+	params.Set("segmentKey", segKey)
+	_, err := sendToNode(target, http.MethodPost, "/api/cluster/segments/abort", params, nil, peerAuth)
+	if err != nil {
+		log.Errorf("abortOnTarget: failed to delete the copy of segkey=%v on node %v, err=%v", segKey, target.NodeId, err)
+	}
+}
+
+func copySegmentFile(status *MoverStatus, target *Node, segKey string, segDir string, relFname string,
+	peerAuth PeerAuth) error {
+
+	fd, err := os.Open(segDir + relFname)
+	if err != nil {
+		log.Errorf("copySegmentFile: failed to open fname=%v, err=%v", segDir+relFname, err)
+		return err
+	}
+	defer fd.Close()
+
+	buf := make([]byte, SEGMENT_FILE_CHUNK_SIZE)
+	offset := uint64(0)
+	for {
+		numRead, err := io.ReadFull(fd, buf)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			log.Errorf("copySegmentFile: failed to read fname=%v, err=%v", segDir+relFname, err)
+			return err
+		}
+		// an empty file is sent as one empty chunk, so that the target creates it
+		if numRead == 0 && offset > 0 {
+			return nil
+		}
+		if isCancelled(status) {
+			return errMoveCancelled
+		}
+		throttle.wait(uint64(numRead))
+		params := url.Values{}
+		params.Set("segmentKey", segKey)
+		params.Set("file", relFname)
+		params.Set("offset", strconv.FormatUint(offset, 10))
+		_, sendErr := sendToNode(target, http.MethodPost, "/api/cluster/segments/files", params, buf[:numRead], peerAuth)
+		if sendErr != nil {
+			return sendErr
+		}
+		offset += uint64(numRead)
+		if numRead < SEGMENT_FILE_CHUNK_SIZE {
+			return nil
+		}
+	}
+}
+
+// Removes the segment from the local node the same way that the retention does
+func removeLocalSegment(segMeta *structs.SegMeta) {
+	for pqid := range segMeta.AllPQIDs {
+		pqsmeta.DeleteSegmentFromPqid(pqid, segMeta.SegmentKey)
+	}
+	metadata.DeleteSegmentKey(segMeta.SegmentKey)
+	for _, fname := range fileutils.GetAllFilesInDirectory(path.Dir(segMeta.SegmentKey) + "/") {
+		err := blob.DeleteBlob(fname)
+		if err != nil {
+			log.Infof("removeLocalSegment: failed to delete segment file %v in the blob store", fname)
+		}
+	}
+	segwriter.RemoveSegments(segwriter.GetLocalSegmetaFName(), map[string]*structs.SegMeta{segMeta.SegmentKey: segMeta})
+	err := blob.UploadIngestNodeDir()
+	if err != nil {
+		log.Errorf("removeLocalSegment: failed to upload ingest node dir, err=%v", err)
+	}
+}
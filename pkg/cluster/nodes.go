@@ -0,0 +1,293 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/siglens/siglens/pkg/blob"
+	"github.com/siglens/siglens/pkg/config"
+	"github.com/siglens/siglens/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+/*
+The nodes of the cluster, as registered by the operators. The local node always has an entry, made from its config
+on every start. Every node keeps its own registry, so draining a peer is forwarded to it. A draining node reports
+itself as not ready so that the load balancers stop sending to it.
+*/
+
+const (
+	ROLE_INGEST = "ingest"
+	ROLE_QUERY  = "query"
+)
+
+type Node struct {
+	NodeId       string   `json:"nodeId"`
+	Address      string   `json:"address"`               // host:port of the query server
+	GrpcAddress  string   `json:"grpcAddress,omitempty"` // host:port of the grpc server
+	Roles        []string `json:"roles"`
+	Draining     bool     `json:"draining"`
+	IsLocal      bool     `json:"isLocal"`
+	RegisteredAt uint64   `json:"registeredAt"` // in ms
+	UpdatedAt    uint64   `json:"updatedAt"`
+}
+
+var nodesFname string
+var nodesLock = &sync.RWMutex{}
+
+// map of node id => node
+var nodes = make(map[string]*Node)
+
+func InitCluster() error {
+	baseDir := config.GetDataPath() + "querynodes/" + config.GetHostID() + "/cluster"
+	nodesFname = baseDir + "/nodes.json"
+	err := os.MkdirAll(baseDir, 0764)
+	if err != nil {
+		log.Errorf("InitCluster: failed to create basedir=%v, err=%v", baseDir, err)
+		return err
+	}
+
+	nodesLock.Lock()
+	defer nodesLock.Unlock()
+	err = readNodes()
+	if err != nil {
+		log.Errorf("InitCluster: failed to read the nodes, err=%v", err)
+		return err
+	}
+	updateLocalNode()
+	return writeNodes()
+}
+
+// Needs the write lock to be held
+func readNodes() error {
+	data, err := os.ReadFile(nodesFname)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	allNodes := make(map[string]*Node)
+	err = json.Unmarshal(data, &allNodes)
+	if err != nil {
+		return err
+	}
+	nodes = allNodes
+	return nil
+}
+
+// Needs the write lock to be held
+func writeNodes() error {
+	data, err := json.Marshal(nodes)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(nodesFname, data, 0644)
+	if err != nil {
+		log.Errorf("writeNodes: failed to write file=%v, err=%v", nodesFname, err)
+		return err
+	}
+	err = blob.UploadQueryNodeDir()
+	if err != nil {
+		log.Errorf("writeNodes: failed to upload query nodes dir, err=%v", err)
+		return err
+	}
+	return nil
+}
+
+// Makes the entry of the local node from its config, keeping whether it was draining. Needs the write lock to be held
+func updateLocalNode() {
+	nowMs := utils.GetCurrentTimeInMs()
+	localNode, ok := nodes[config.GetHostID()]
+	if !ok {
+		localNode = &Node{NodeId: config.GetHostID(), RegisteredAt: nowMs}
+		nodes[localNode.NodeId] = localNode
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		log.Errorf("updateLocalNode: failed to get the hostname, err=%v", err)
+		hostname = "localhost"
+	}
+	localNode.Address = hostname + ":" + strconv.FormatUint(config.GetQueryPort(), 10)
+	localNode.GrpcAddress = hostname + config.GetGRPCPort()
+	localNode.Roles = make([]string, 0, 2)
+	if config.IsIngestNode() {
+		localNode.Roles = append(localNode.Roles, ROLE_INGEST)
+	}
+	if config.IsQueryNode() {
+		localNode.Roles = append(localNode.Roles, ROLE_QUERY)
+	}
+	localNode.IsLocal = true
+	localNode.UpdatedAt = nowMs
+
+	// the node id of a data dir can change, only the current one is local
+	for nodeId, node := range nodes {
+		if nodeId != localNode.NodeId {
+			node.IsLocal = false
+		}
+	}
+}
+
+func validateRoles(roles []string) error {
+	if len(roles) == 0 {
+		return fmt.Errorf("a node needs at least one role")
+	}
+	for _, role := range roles {
+		if role != ROLE_INGEST && role != ROLE_QUERY {
+			return fmt.Errorf("invalid role %v, it should be %v or %v", role, ROLE_INGEST, ROLE_QUERY)
+		}
+	}
+	return nil
+}
+
+func (node *Node) HasRole(role string) bool {
+	for _, nodeRole := range node.Roles {
+		if nodeRole == role {
+			return true
+		}
+	}
+	return false
+}
+
+func (node *Node) copy() *Node {
+	nodeCopy := *node
+	nodeCopy.Roles = append(make([]string, 0, len(node.Roles)), node.Roles...)
+	return &nodeCopy
+}
+
+// Adds a peer node, or updates its addresses and roles if it is already registered
+func RegisterNode(nodeId string, address string, grpcAddress string, roles []string) (*Node, error) {
+	if nodeId == "" {
+		return nil, fmt.Errorf("the node id is required")
+	}
+	if address == "" {
+		return nil, fmt.Errorf("the address of node %v is required", nodeId)
+	}
+	err := validateRoles(roles)
+	if err != nil {
+		return nil, err
+	}
+
+	nodesLock.Lock()
+	defer nodesLock.Unlock()
+	node, ok := nodes[nodeId]
+	if ok && node.IsLocal {
+		return nil, fmt.Errorf("node %v is the local node, its entry comes from its config", nodeId)
+	}
+	nowMs := utils.GetCurrentTimeInMs()
+	if !ok {
+		node = &Node{NodeId: nodeId, RegisteredAt: nowMs}
+		nodes[nodeId] = node
+	}
+	node.Address = address
+	node.GrpcAddress = grpcAddress
+	node.Roles = append(make([]string, 0, len(roles)), roles...)
+	node.UpdatedAt = nowMs
+	err = writeNodes()
+	if err != nil {
+		return nil, err
+	}
+	return node.copy(), nil
+}
+
+func RemoveNode(nodeId string) error {
+	nodesLock.Lock()
+	defer nodesLock.Unlock()
+	node, ok := nodes[nodeId]
+	if !ok {
+		return fmt.Errorf("node %v is not registered", nodeId)
+	}
+	if node.IsLocal {
+		return fmt.Errorf("node %v is the local node, it can not be removed", nodeId)
+	}
+	delete(nodes, nodeId)
+	return writeNodes()
+}
+
+/*
+The local node reports itself as not ready while draining. A node reads whether it drains from its own registry,
+so setting a peer to draining is first sent to the peer, peerAuth is passed on to it
+*/
+func SetNodeDraining(nodeId string, draining bool, peerAuth PeerAuth) (*Node, error) {
+	node, ok := GetNode(nodeId)
+	if !ok {
+		return nil, fmt.Errorf("node %v is not registered", nodeId)
+	}
+	if !node.IsLocal {
+		body, err := json.Marshal(map[string]bool{"draining": draining})
+		if err != nil {
+			return nil, err
+		}
+		_, err = sendToNode(node, "POST", "/api/cluster/nodes/"+url.PathEscape(nodeId)+"/drain", nil, body, peerAuth)
+		if err != nil {
+			log.Errorf("SetNodeDraining: failed to set node %v to draining=%v, err=%v", nodeId, draining, err)
+			return nil, err
+		}
+	}
+
+	nodesLock.Lock()
+	defer nodesLock.Unlock()
+	localEntry, ok := nodes[nodeId]
+	if !ok {
+		return nil, fmt.Errorf("node %v is not registered", nodeId)
+	}
+	localEntry.Draining = draining
+	localEntry.UpdatedAt = utils.GetCurrentTimeInMs()
+	err := writeNodes()
+	if err != nil {
+		return nil, err
+	}
+	return localEntry.copy(), nil
+}
+
+// Returns all the nodes, sorted by their ids
+func ListNodes() []*Node {
+	nodesLock.RLock()
+	defer nodesLock.RUnlock()
+	retVal := make([]*Node, 0, len(nodes))
+	for _, node := range nodes {
+		retVal = append(retVal, node.copy())
+	}
+	sort.Slice(retVal, func(i, j int) bool {
+		return retVal[i].NodeId < retVal[j].NodeId
+	})
+	return retVal
+}
+
+func GetNode(nodeId string) (*Node, bool) {
+	nodesLock.RLock()
+	defer nodesLock.RUnlock()
+	node, ok := nodes[nodeId]
+	if !ok {
+		return nil, false
+	}
+	return node.copy(), true
+}
+
+func IsLocalNodeDraining() bool {
+	nodesLock.RLock()
+	defer nodesLock.RUnlock()
+	node, ok := nodes[config.GetHostID()]
+	return ok && node.Draining
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/siglens/siglens/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func initTestCluster(t *testing.T) {
+	config.InitializeDefaultConfig()
+	runningConfig := config.GetRunningConfig()
+	runningConfig.DataPath = t.TempDir() + "/"
+	config.SetConfig(*runningConfig)
+	assert.Nil(t, config.InitDerivedConfig("test-node"))
+	nodes = make(map[string]*Node)
+	assert.Nil(t, InitCluster())
+}
+
+func Test_NodeLifecycle(t *testing.T) {
+	initTestCluster(t)
+
+	allNodes := ListNodes()
+	assert.Len(t, allNodes, 1)
+	assert.Equal(t, config.GetHostID(), allNodes[0].NodeId)
+	assert.True(t, allNodes[0].IsLocal)
+	assert.Equal(t, []string{ROLE_INGEST, ROLE_QUERY}, allNodes[0].Roles)
+
+	// peer-1 records the drain requests it gets
+	drainRequests := make([]string, 0)
+	peer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		drainRequests = append(drainRequests, r.Method+" "+r.URL.Path+" "+string(body))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer peer.Close()
+	peerAddress := strings.TrimPrefix(peer.URL, "http://")
+
+	_, err := RegisterNode("peer-1", peerAddress, "", []string{ROLE_QUERY})
+	assert.Nil(t, err)
+	_, err = RegisterNode("peer-2", "peer-2:5122", "", []string{ROLE_INGEST})
+	assert.Nil(t, err)
+
+	// registering again updates the node
+	node, err := RegisterNode("peer-2", "127.0.0.1:1", "", []string{ROLE_INGEST, ROLE_QUERY})
+	assert.Nil(t, err)
+	assert.Equal(t, "127.0.0.1:1", node.Address)
+	assert.Equal(t, []string{ROLE_INGEST, ROLE_QUERY}, node.Roles)
+
+	// draining a peer is forwarded to it
+	node, err = SetNodeDraining("peer-1", true, PeerAuth{})
+	assert.Nil(t, err)
+	assert.True(t, node.Draining)
+	assert.Equal(t, []string{`POST /api/cluster/nodes/peer-1/drain {"draining":true}`}, drainRequests)
+
+	// the peer stays in service if it can not be reached
+	_, err = SetNodeDraining("peer-2", true, PeerAuth{})
+	assert.NotNil(t, err)
+	node, _ = GetNode("peer-2")
+	assert.False(t, node.Draining)
+
+	assert.False(t, IsLocalNodeDraining())
+	_, err = SetNodeDraining(config.GetHostID(), true, PeerAuth{})
+	assert.Nil(t, err)
+	assert.True(t, IsLocalNodeDraining())
+	assert.Len(t, drainRequests, 1)
+
+	// the nodes and whether they drain are kept across restarts, the local node entry is remade
+	nodes = make(map[string]*Node)
+	assert.Nil(t, InitCluster())
+	assert.Len(t, ListNodes(), 3)
+	assert.True(t, IsLocalNodeDraining())
+	node, ok := GetNode("peer-1")
+	assert.True(t, ok)
+	assert.True(t, node.Draining)
+
+	assert.Nil(t, RemoveNode("peer-1"))
+	_, ok = GetNode("peer-1")
+	assert.False(t, ok)
+	assert.NotNil(t, RemoveNode("peer-1"))
+	assert.NotNil(t, RemoveNode(config.GetHostID()))
+}
+
+func Test_RegisterNodeValidation(t *testing.T) {
+	initTestCluster(t)
+
+	_, err := RegisterNode("", "peer:5122", "", []string{ROLE_QUERY})
+	assert.NotNil(t, err)
+	_, err = RegisterNode("peer", "", "", []string{ROLE_QUERY})
+	assert.NotNil(t, err)
+	_, err = RegisterNode("peer", "peer:5122", "", nil)
+	assert.NotNil(t, err)
+	_, err = RegisterNode("peer", "peer:5122", "", []string{"master"})
+	assert.NotNil(t, err)
+	_, err = RegisterNode(config.GetHostID(), "other:5122", "", []string{ROLE_QUERY})
+	assert.NotNil(t, err)
+	_, err = SetNodeDraining("unknown", true, PeerAuth{})
+	assert.NotNil(t, err)
+	assert.Len(t, ListNodes(), 1)
+}
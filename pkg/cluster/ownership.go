@@ -0,0 +1,134 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"os"
+	"path"
+	"sort"
+
+	"github.com/siglens/siglens/pkg/config"
+	"github.com/siglens/siglens/pkg/segment/structs"
+	segwriter "github.com/siglens/siglens/pkg/segment/writer"
+	log "github.com/sirupsen/logrus"
+)
+
+// The rotated segments of an index that a node ingested
+type IndexOwnership struct {
+	IndexName   string   `json:"indexName"`
+	NumSegments uint64   `json:"numSegments"`
+	RecordCount uint64   `json:"recordCount"`
+	OnDiskBytes uint64   `json:"onDiskBytes"`
+	SegmentKeys []string `json:"segmentKeys,omitempty"` // only set when the segments are asked for
+}
+
+type NodeOwnership struct {
+	NodeId       string            `json:"nodeId"`
+	IsRegistered bool              `json:"isRegistered"` // false for the segments of nodes that have been removed
+	Draining     bool              `json:"draining"`
+	Indices      []*IndexOwnership `json:"indices"`
+}
+
+type OwnershipFilter struct {
+	NodeId      string // empty for all the nodes
+	IndexName   string // empty for all the indices
+	WithSegKeys bool
+}
+
+/*
+Returns which node owns which segments of the org, sorted by node id. A node owns the segments listed in the
+segmeta file of its ingest node dir, which are the ones it rotated
+*/
+func GetSegmentOwnership(filter OwnershipFilter, orgid uint64) []*NodeOwnership {
+	ingestDir := config.GetIngestNodeBaseDir()
+	files, err := os.ReadDir(ingestDir)
+	if err != nil && !os.IsNotExist(err) {
+		log.Errorf("GetSegmentOwnership: read dir=%v failed, err=%v", ingestDir, err)
+	}
+
+	allOwnership := make(map[string]*NodeOwnership)
+	for _, file := range files {
+		nodeId := file.Name()
+		if !file.IsDir() || (filter.NodeId != "" && nodeId != filter.NodeId) {
+			continue
+		}
+		smFname := path.Join(ingestDir, nodeId, segwriter.SegmetaSuffix)
+		if _, err := os.Stat(smFname); err != nil {
+			continue
+		}
+		segmetas, err := segwriter.ReadSegmeta(smFname)
+		if err != nil {
+			log.Errorf("GetSegmentOwnership: failed to read segmeta=%v, err=%v", smFname, err)
+			continue
+		}
+		allOwnership[nodeId] = makeNodeOwnership(nodeId, segmetas, filter, orgid)
+	}
+
+	// registered nodes that have not rotated any segments yet own nothing
+	for _, node := range ListNodes() {
+		if filter.NodeId != "" && node.NodeId != filter.NodeId {
+			continue
+		}
+		if _, ok := allOwnership[node.NodeId]; !ok {
+			allOwnership[node.NodeId] = &NodeOwnership{NodeId: node.NodeId, Indices: make([]*IndexOwnership, 0)}
+		}
+		allOwnership[node.NodeId].IsRegistered = true
+		allOwnership[node.NodeId].Draining = node.Draining
+	}
+
+	retVal := make([]*NodeOwnership, 0, len(allOwnership))
+	for _, ownership := range allOwnership {
+		retVal = append(retVal, ownership)
+	}
+	sort.Slice(retVal, func(i, j int) bool {
+		return retVal[i].NodeId < retVal[j].NodeId
+	})
+	return retVal
+}
+
+// Groups the segments of the node by their index, sorted by index name
+func makeNodeOwnership(nodeId string, segmetas []*structs.SegMeta, filter OwnershipFilter, orgid uint64) *NodeOwnership {
+	indices := make(map[string]*IndexOwnership)
+	for _, segmeta := range segmetas {
+		if segmeta.OrgId != orgid || (filter.IndexName != "" && segmeta.VirtualTableName != filter.IndexName) {
+			continue
+		}
+		index, ok := indices[segmeta.VirtualTableName]
+		if !ok {
+			index = &IndexOwnership{IndexName: segmeta.VirtualTableName}
+			indices[segmeta.VirtualTableName] = index
+		}
+		index.NumSegments++
+		index.RecordCount += uint64(segmeta.RecordCount)
+		index.OnDiskBytes += segmeta.OnDiskBytes
+		if filter.WithSegKeys {
+			index.SegmentKeys = append(index.SegmentKeys, segmeta.SegmentKey)
+		}
+	}
+
+	ownership := &NodeOwnership{NodeId: nodeId, Indices: make([]*IndexOwnership, 0, len(indices))}
+	for _, index := range indices {
+		if filter.WithSegKeys {
+			sort.Strings(index.SegmentKeys)
+		}
+		ownership.Indices = append(ownership.Indices, index)
+	}
+	sort.Slice(ownership.Indices, func(i, j int) bool {
+		return ownership.Indices[i].IndexName < ownership.Indices[j].IndexName
+	})
+	return ownership
+}
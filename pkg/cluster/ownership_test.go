@@ -0,0 +1,76 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/siglens/siglens/pkg/config"
+	"github.com/siglens/siglens/pkg/segment/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GetSegmentOwnership(t *testing.T) {
+	initTestCluster(t)
+	_, err := RegisterNode("peer-1", "peer-1:5122", "", []string{ROLE_INGEST})
+	assert.Nil(t, err)
+
+	segmetas := map[string]string{
+		config.GetHostID(): `{"segmentKey":"a/2","virtualTableName":"idx-a","recordCount":10,"onDiskBytes":100}
+{"segmentKey":"a/1","virtualTableName":"idx-a","recordCount":20,"onDiskBytes":200}
+{"segmentKey":"b/1","virtualTableName":"idx-b","recordCount":5,"onDiskBytes":50}
+{"segmentKey":"o/1","virtualTableName":"idx-a","recordCount":5,"onDiskBytes":50,"orgid":3}
+`,
+		// a node that has been removed
+		"old-node": `{"segmentKey":"c/1","virtualTableName":"idx-a","recordCount":1,"onDiskBytes":10}
+`,
+	}
+	for nodeId, data := range segmetas {
+		nodeDir := path.Join(config.GetIngestNodeBaseDir(), nodeId)
+		assert.Nil(t, os.MkdirAll(nodeDir, 0764))
+		assert.Nil(t, os.WriteFile(path.Join(nodeDir, "segmeta.json"), []byte(data), 0644))
+	}
+
+	ownership := GetSegmentOwnership(OwnershipFilter{}, 0)
+	assert.Len(t, ownership, 3)
+	assert.Equal(t, "old-node", ownership[0].NodeId)
+	assert.False(t, ownership[0].IsRegistered)
+	assert.Equal(t, "peer-1", ownership[1].NodeId)
+	assert.True(t, ownership[1].IsRegistered)
+	assert.Len(t, ownership[1].Indices, 0)
+	assert.Equal(t, config.GetHostID(), ownership[2].NodeId)
+	assert.True(t, ownership[2].IsRegistered)
+	assert.Equal(t, []*IndexOwnership{
+		{IndexName: "idx-a", NumSegments: 2, RecordCount: 30, OnDiskBytes: 300},
+		{IndexName: "idx-b", NumSegments: 1, RecordCount: 5, OnDiskBytes: 50},
+	}, ownership[2].Indices)
+
+	ownership = GetSegmentOwnership(OwnershipFilter{NodeId: config.GetHostID(), IndexName: "idx-a", WithSegKeys: true}, 0)
+	assert.Len(t, ownership, 1)
+	assert.Len(t, ownership[0].Indices, 1)
+	assert.Equal(t, []string{"a/1", "a/2"}, ownership[0].Indices[0].SegmentKeys)
+
+	ownership = GetSegmentOwnership(OwnershipFilter{NodeId: config.GetHostID()}, 3)
+	assert.Equal(t, uint64(5), ownership[0].Indices[0].RecordCount)
+
+	// the segments of other orgs are left out
+	indexOwnership := makeNodeOwnership("n", []*structs.SegMeta{{SegmentKey: "x", VirtualTableName: "i", OrgId: 1}},
+		OwnershipFilter{}, 0)
+	assert.Len(t, indexOwnership.Indices, 0)
+}
@@ -0,0 +1,331 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+
+	"github.com/siglens/siglens/pkg/config"
+	log "github.com/sirupsen/logrus"
+)
+
+/*
+	Plans the moves of the rotated segments that even out the bytes on disk of the nodes, when nodes are added or
+	drained. Every node that is not draining should end up with the average bytes of the cluster, and a draining node
+	moves all of its segments out. The queries of an index search all the nodes that have its segments, so the bytes
+	of an index are moved first to the nodes that have fewer of them, which also spreads the query load of the index.
+*/
+
+// Active nodes this far over the average, in percent of the average, are left as they are so that small differences
+// do not move segments back and forth
+const REBALANCE_TOLERANCE_PERCENT = 10
+
+type NodeUsage struct {
+	NodeId     string            `json:"nodeId"`
+	Draining   bool              `json:"draining"`
+	IndexBytes map[string]uint64 `json:"indexBytes"` // on disk bytes of the rotated segments of each index
+}
+
+type PlannedTransfer struct {
+	SourceNodeId string `json:"sourceNodeId"`
+	TargetNodeId string `json:"targetNodeId"`
+	IndexName    string `json:"indexName"`
+	Bytes        uint64 `json:"bytes"`
+}
+
+type RebalanceResult struct {
+	Nodes     []*NodeUsage       `json:"nodes"`
+	Transfers []*PlannedTransfer `json:"transfers"`
+	Started   []string           `json:"started,omitempty"` // the source nodes that started their moves
+	Errors    map[string]string  `json:"errors,omitempty"`  // node id => why it could not be read or start its moves
+}
+
+func (usage *NodeUsage) totalBytes() uint64 {
+	total := uint64(0)
+	for _, bytes := range usage.IndexBytes {
+		total += bytes
+	}
+	return total
+}
+
+/*
+Returns the bytes of each index to move between the nodes, sorted by source, target and index. The segments can not
+be split, so the sources move the segments that fit in the planned bytes
+*/
+func PlanRebalance(usages []*NodeUsage) ([]*PlannedTransfer, error) {
+	indexBytes := make(map[string]map[string]uint64, len(usages))
+	numActive := uint64(0)
+	totalBytes := uint64(0)
+	for _, usage := range usages {
+		indexBytes[usage.NodeId] = make(map[string]uint64, len(usage.IndexBytes))
+		for indexName, bytes := range usage.IndexBytes {
+			indexBytes[usage.NodeId][indexName] = bytes
+		}
+		if !usage.Draining {
+			numActive++
+		}
+		totalBytes += usage.totalBytes()
+	}
+	if numActive == 0 {
+		return nil, fmt.Errorf("there are no nodes that are not draining to move the segments to")
+	}
+
+	avgBytes := totalBytes / numActive
+	tolerance := avgBytes * REBALANCE_TOLERANCE_PERCENT / 100
+	excess := make(map[string]uint64)
+	deficit := make(map[string]uint64)
+	for _, usage := range usages {
+		nodeBytes := usage.totalBytes()
+		switch {
+		case usage.Draining:
+			if nodeBytes > 0 {
+				excess[usage.NodeId] = nodeBytes
+			}
+		case nodeBytes > avgBytes+tolerance:
+			excess[usage.NodeId] = nodeBytes - avgBytes
+		case nodeBytes < avgBytes:
+			// the deficits are not reduced by the tolerance, so that there is room for all the bytes of the draining nodes
+			deficit[usage.NodeId] = avgBytes - nodeBytes
+		}
+	}
+	isDraining := make(map[string]bool, len(usages))
+	for _, usage := range usages {
+		isDraining[usage.NodeId] = usage.Draining
+	}
+
+	transfers := make(map[PlannedTransfer]uint64)
+	for {
+		sourceId := getLargestNode(excess)
+		targetId := getLargestNode(deficit)
+		if sourceId == "" || targetId == "" {
+			break
+		}
+		amount := excess[sourceId]
+		if deficit[targetId] < amount {
+			amount = deficit[targetId]
+		}
+
+		moved := planIndexMoves(indexBytes[sourceId], indexBytes[targetId], amount, isDraining[sourceId],
+			func(indexName string, bytes uint64) {
+				transfers[PlannedTransfer{SourceNodeId: sourceId, TargetNodeId: targetId, IndexName: indexName}] += bytes
+			})
+		if moved == 0 {
+			// nothing left on the source, which only happens when its bytes were counted wrong
+			delete(excess, sourceId)
+			continue
+		}
+		excess[sourceId] -= moved
+		deficit[targetId] -= moved
+		if excess[sourceId] == 0 {
+			delete(excess, sourceId)
+		}
+		if deficit[targetId] == 0 {
+			delete(deficit, targetId)
+		}
+	}
+
+	retVal := make([]*PlannedTransfer, 0, len(transfers))
+	for transfer, bytes := range transfers {
+		planned := transfer
+		planned.Bytes = bytes
+		retVal = append(retVal, &planned)
+	}
+	sort.Slice(retVal, func(i, j int) bool {
+		if retVal[i].SourceNodeId != retVal[j].SourceNodeId {
+			return retVal[i].SourceNodeId < retVal[j].SourceNodeId
+		}
+		if retVal[i].TargetNodeId != retVal[j].TargetNodeId {
+			return retVal[i].TargetNodeId < retVal[j].TargetNodeId
+		}
+		return retVal[i].IndexName < retVal[j].IndexName
+	})
+	return retVal, nil
+}
+
+// Returns the node with the most bytes, the smallest node id of the ties
+func getLargestNode(nodeBytes map[string]uint64) string {
+	largestId := ""
+	for nodeId, bytes := range nodeBytes {
+		if largestId == "" || bytes > nodeBytes[largestId] || (bytes == nodeBytes[largestId] && nodeId < largestId) {
+			largestId = nodeId
+		}
+	}
+	return largestId
+}
+
+/*
+Moves up to amount bytes from the indices of the source to the target and returns the moved bytes. The indices that
+the source has more of than the target go first, an active source only moves half of the difference of an index
+so that it is not moved back later. Whatever is left of the amount comes from any index
+*/
+func planIndexMoves(sourceBytes map[string]uint64, targetBytes map[string]uint64, amount uint64, isDraining bool,
+	addTransfer func(indexName string, bytes uint64)) uint64 {
+
+	indexNames := make([]string, 0, len(sourceBytes))
+	for indexName, bytes := range sourceBytes {
+		if bytes > 0 {
+			indexNames = append(indexNames, indexName)
+		}
+	}
+	getDiff := func(indexName string) int64 {
+		return int64(sourceBytes[indexName]) - int64(targetBytes[indexName])
+	}
+	sort.Slice(indexNames, func(i, j int) bool {
+		if getDiff(indexNames[i]) != getDiff(indexNames[j]) {
+			return getDiff(indexNames[i]) > getDiff(indexNames[j])
+		}
+		return indexNames[i] < indexNames[j]
+	})
+
+	moved := uint64(0)
+	move := func(indexName string, bytes uint64) {
+		if bytes > amount-moved {
+			bytes = amount - moved
+		}
+		if bytes == 0 {
+			return
+		}
+		sourceBytes[indexName] -= bytes
+		targetBytes[indexName] += bytes
+		moved += bytes
+		addTransfer(indexName, bytes)
+	}
+	for _, indexName := range indexNames {
+		if diff := getDiff(indexName); diff > 0 {
+			if isDraining {
+				move(indexName, sourceBytes[indexName])
+			} else {
+				move(indexName, uint64(diff)/2)
+			}
+		}
+	}
+	for _, indexName := range indexNames {
+		move(indexName, sourceBytes[indexName])
+	}
+	return moved
+}
+
+/*
+Returns the bytes of each index on the local node and the registered query nodes, the other nodes are asked for their
+own segments. The nodes without the query role do not serve the cluster api, so they can not send or adopt segments
+*/
+func getClusterUsage(peerAuth PeerAuth, orgid uint64) ([]*NodeUsage, map[string]string) {
+	usages := make([]*NodeUsage, 0)
+	errs := make(map[string]string)
+	for _, node := range ListNodes() {
+		if !node.IsLocal && !node.HasRole(ROLE_QUERY) {
+			continue
+		}
+		var ownership []*NodeOwnership
+		if node.IsLocal {
+			ownership = GetSegmentOwnership(OwnershipFilter{NodeId: node.NodeId}, orgid)
+		} else {
+			params := url.Values{}
+			params.Set("nodeId", node.NodeId)
+			respBody, err := sendToNode(node, http.MethodGet, "/api/cluster/ownership", params, nil, peerAuth)
+			if err == nil {
+				err = json.Unmarshal(respBody, &ownership)
+			}
+			if err != nil {
+				log.Errorf("getClusterUsage: failed to get the segments of node %v, err=%v", node.NodeId, err)
+				errs[node.NodeId] = err.Error()
+				continue
+			}
+		}
+
+		usage := &NodeUsage{NodeId: node.NodeId, Draining: node.Draining, IndexBytes: make(map[string]uint64)}
+		for _, nodeOwnership := range ownership {
+			if nodeOwnership.NodeId != node.NodeId {
+				continue
+			}
+			for _, index := range nodeOwnership.Indices {
+				usage.IndexBytes[index.IndexName] += index.OnDiskBytes
+			}
+		}
+		usages = append(usages, usage)
+	}
+	return usages, errs
+}
+
+// Plans the rebalance of the cluster without moving anything
+func GetRebalancePlan(peerAuth PeerAuth, orgid uint64) (*RebalanceResult, error) {
+	usages, errs := getClusterUsage(peerAuth, orgid)
+	if len(errs) > 0 {
+		return &RebalanceResult{Nodes: usages, Transfers: make([]*PlannedTransfer, 0), Errors: errs},
+			fmt.Errorf("could not get the segments of %v nodes", len(errs))
+	}
+	transfers, err := PlanRebalance(usages)
+	if err != nil {
+		return nil, err
+	}
+	return &RebalanceResult{Nodes: usages, Transfers: transfers}, nil
+}
+
+/*
+Plans the rebalance of the cluster and has every source node start its moves. The plan is only made when all the
+nodes could be read, since a node that is missing from it could not get any segments
+*/
+func StartRebalance(peerAuth PeerAuth, orgid uint64) (*RebalanceResult, error) {
+	result, err := GetRebalancePlan(peerAuth, orgid)
+	if err != nil {
+		return result, err
+	}
+
+	sourceTransfers := make(map[string][]*PlannedTransfer)
+	for _, transfer := range result.Transfers {
+		sourceTransfers[transfer.SourceNodeId] = append(sourceTransfers[transfer.SourceNodeId], transfer)
+	}
+	sourceIds := make([]string, 0, len(sourceTransfers))
+	for sourceId := range sourceTransfers {
+		sourceIds = append(sourceIds, sourceId)
+	}
+	sort.Strings(sourceIds)
+
+	result.Started = make([]string, 0, len(sourceIds))
+	result.Errors = make(map[string]string)
+	for _, sourceId := range sourceIds {
+		if sourceId == config.GetHostID() {
+			_, err = StartMoves(sourceTransfers[sourceId], peerAuth, orgid)
+		} else {
+			err = sendMovesToNode(sourceId, sourceTransfers[sourceId], peerAuth)
+		}
+		if err != nil {
+			log.Errorf("StartRebalance: node %v could not start its moves, err=%v", sourceId, err)
+			result.Errors[sourceId] = err.Error()
+			continue
+		}
+		result.Started = append(result.Started, sourceId)
+	}
+	return result, nil
+}
+
+func sendMovesToNode(nodeId string, transfers []*PlannedTransfer, peerAuth PeerAuth) error {
+	node, ok := GetNode(nodeId)
+	if !ok {
+		return fmt.Errorf("node %v is not registered", nodeId)
+	}
+	body, err := json.Marshal(transfers)
+	if err != nil {
+		return err
+	}
+	_, err = sendToNode(node, http.MethodPost, "/api/cluster/rebalance/moves", url.Values{}, body, peerAuth)
+	return err
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"encoding/json"
+
+	"github.com/siglens/siglens/pkg/apikeys"
+	"github.com/siglens/siglens/pkg/utils"
+	log "github.com/sirupsen/logrus"
+	"github.com/valyala/fasthttp"
+)
+
+/*
+The credentials of the caller, the requests to the other nodes are made with them. Both headers are passed on, as the
+api keys are read from X-API-Key before the Authorization header. The api keys of a node are stored in its own
+querynodes dir, so when they are enabled the nodes need the same keys, for example by copying the apikeys dir of a
+node to the others, otherwise the peers reject the requests
+*/
+type PeerAuth struct {
+	Authorization string
+	ApiKey        string
+}
+
+func getPeerAuth(ctx *fasthttp.RequestCtx) PeerAuth {
+	return PeerAuth{
+		Authorization: string(ctx.Request.Header.Peek("Authorization")),
+		ApiKey:        string(ctx.Request.Header.Peek(apikeys.API_KEY_HEADER)),
+	}
+}
+
+// Handles GET /api/cluster/rebalance/plan, the moves that a rebalance would make
+func ProcessRebalancePlanRequest(ctx *fasthttp.RequestCtx, myid uint64) {
+	result, err := GetRebalancePlan(getPeerAuth(ctx), myid)
+	if err != nil {
+		log.Errorf("ProcessRebalancePlanRequest: could not plan the rebalance, err=%v", err)
+		if result == nil {
+			setErrMsg(ctx, fasthttp.StatusBadRequest, err.Error())
+			return
+		}
+		ctx.SetStatusCode(fasthttp.StatusServiceUnavailable)
+		utils.WriteJsonResponse(ctx, result)
+		return
+	}
+	utils.WriteJsonResponse(ctx, result)
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
+
+// Handles POST /api/cluster/rebalance, plans the rebalance and starts the moves on the source nodes
+func ProcessStartRebalanceRequest(ctx *fasthttp.RequestCtx, myid uint64) {
+	result, err := StartRebalance(getPeerAuth(ctx), myid)
+	if err != nil {
+		log.Errorf("ProcessStartRebalanceRequest: could not start the rebalance, err=%v", err)
+		if result == nil {
+			setErrMsg(ctx, fasthttp.StatusBadRequest, err.Error())
+			return
+		}
+		ctx.SetStatusCode(fasthttp.StatusServiceUnavailable)
+		utils.WriteJsonResponse(ctx, result)
+		return
+	}
+	utils.WriteJsonResponse(ctx, result)
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
+
+// Handles GET /api/cluster/rebalance, the moves of the local node in the last rebalance
+func ProcessRebalanceStatusRequest(ctx *fasthttp.RequestCtx) {
+	utils.WriteJsonResponse(ctx, GetMoverStatus())
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
+
+// Handles POST /api/cluster/rebalance/cancel, stops the moves of the local node that have not started
+func ProcessCancelRebalanceRequest(ctx *fasthttp.RequestCtx) {
+	utils.WriteJsonResponse(ctx, CancelMoves())
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
+
+// Handles POST /api/cluster/rebalance/moves, the body is the planned transfers whose source is this node
+func ProcessStartMovesRequest(ctx *fasthttp.RequestCtx, myid uint64) {
+	transfers := make([]*PlannedTransfer, 0)
+	err := json.Unmarshal(ctx.PostBody(), &transfers)
+	if err != nil {
+		log.Errorf("ProcessStartMovesRequest: could not unmarshal the request, err=%v", err)
+		setErrMsg(ctx, fasthttp.StatusBadRequest, "Bad Request")
+		return
+	}
+	status, err := StartMoves(transfers, getPeerAuth(ctx), myid)
+	if err != nil {
+		log.Errorf("ProcessStartMovesRequest: could not start the moves, err=%v", err)
+		setErrMsg(ctx, fasthttp.StatusConflict, err.Error())
+		return
+	}
+	utils.WriteJsonResponse(ctx, status)
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
+
+// Handles POST /api/cluster/segments/files?segmentKey=...&file=...&offset=..., the body is the chunk of the file
+func ProcessReceiveSegmentFileRequest(ctx *fasthttp.RequestCtx) {
+	segKey := string(ctx.QueryArgs().Peek("segmentKey"))
+	relFname := string(ctx.QueryArgs().Peek("file"))
+	offset, err := ctx.QueryArgs().GetUint("offset")
+	if err != nil {
+		setErrMsg(ctx, fasthttp.StatusBadRequest, "offset is required")
+		return
+	}
+	err = ReceiveSegmentFile(segKey, relFname, uint64(offset), ctx.PostBody())
+	if err != nil {
+		log.Errorf("ProcessReceiveSegmentFileRequest: could not write file %v of segkey=%v, err=%v", relFname, segKey, err)
+		setErrMsg(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	utils.WriteJsonResponse(ctx, "File chunk written successfully")
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
+
+// Handles POST /api/cluster/segments/abort?segmentKey=..., after the move of the segment to this node failed
+func ProcessAbortSegmentRequest(ctx *fasthttp.RequestCtx) {
+	segKey := string(ctx.QueryArgs().Peek("segmentKey"))
+	err := AbortSegmentTransfer(segKey)
+	if err != nil {
+		log.Errorf("ProcessAbortSegmentRequest: could not abort the move of segkey=%v, err=%v", segKey, err)
+		setErrMsg(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	utils.WriteJsonResponse(ctx, "Segment move aborted successfully")
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
+
+// Handles POST /api/cluster/segments/adopt, the body is {"segMeta": {...}, "checksums": {"<file>": "<checksum>"}}
+func ProcessAdoptSegmentRequest(ctx *fasthttp.RequestCtx) {
+	request := &AdoptSegmentRequest{}
+	err := json.Unmarshal(ctx.PostBody(), request)
+	if err != nil {
+		log.Errorf("ProcessAdoptSegmentRequest: could not unmarshal the request, err=%v", err)
+		setErrMsg(ctx, fasthttp.StatusBadRequest, "Bad Request")
+		return
+	}
+	err = AdoptSegment(request)
+	if err != nil {
+		log.Errorf("ProcessAdoptSegmentRequest: could not adopt segkey=%v, err=%v", request.SegMeta.SegmentKey, err)
+		setErrMsg(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	utils.WriteJsonResponse(ctx, "Segment adopted successfully")
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/siglens/siglens/pkg/config"
+	"github.com/siglens/siglens/pkg/segment/structs"
+	segwriter "github.com/siglens/siglens/pkg/segment/writer"
+	"github.com/siglens/siglens/pkg/virtualtable"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PlanRebalanceAddedNode(t *testing.T) {
+	usages := []*NodeUsage{
+		{NodeId: "n1", IndexBytes: map[string]uint64{"idx-a": 600, "idx-b": 300}},
+		{NodeId: "n2", IndexBytes: map[string]uint64{"idx-a": 300, "idx-b": 600}},
+		{NodeId: "n3", IndexBytes: map[string]uint64{}},
+	}
+	transfers, err := PlanRebalance(usages)
+	assert.Nil(t, err)
+	// the new node gets the index of each source that the source has the most of
+	assert.Equal(t, []*PlannedTransfer{
+		{SourceNodeId: "n1", TargetNodeId: "n3", IndexName: "idx-a", Bytes: 300},
+		{SourceNodeId: "n2", TargetNodeId: "n3", IndexName: "idx-b", Bytes: 300},
+	}, transfers)
+}
+
+func Test_PlanRebalanceDrainingNode(t *testing.T) {
+	usages := []*NodeUsage{
+		{NodeId: "n1", IndexBytes: map[string]uint64{"idx-a": 100}},
+		{NodeId: "n2", IndexBytes: map[string]uint64{"idx-a": 100}},
+		{NodeId: "n3", Draining: true, IndexBytes: map[string]uint64{"idx-a": 100, "idx-b": 100}},
+	}
+	transfers, err := PlanRebalance(usages)
+	assert.Nil(t, err)
+	moved := make(map[string]uint64)
+	for _, transfer := range transfers {
+		assert.Equal(t, "n3", transfer.SourceNodeId)
+		moved[transfer.TargetNodeId] += transfer.Bytes
+	}
+	assert.Equal(t, map[string]uint64{"n1": 100, "n2": 100}, moved)
+
+	_, err = PlanRebalance([]*NodeUsage{{NodeId: "n1", Draining: true, IndexBytes: map[string]uint64{"idx-a": 1}}})
+	assert.NotNil(t, err)
+}
+
+func Test_PlanRebalanceTolerance(t *testing.T) {
+	// n1 is less than 10% over the average of 1000
+	usages := []*NodeUsage{
+		{NodeId: "n1", IndexBytes: map[string]uint64{"idx-a": 1050}},
+		{NodeId: "n2", IndexBytes: map[string]uint64{"idx-a": 950}},
+	}
+	transfers, err := PlanRebalance(usages)
+	assert.Nil(t, err)
+	assert.Len(t, transfers, 0)
+
+	usages[0].IndexBytes["idx-a"] = 1300
+	usages[1].IndexBytes["idx-a"] = 700
+	transfers, err = PlanRebalance(usages)
+	assert.Nil(t, err)
+	assert.Equal(t, []*PlannedTransfer{{SourceNodeId: "n1", TargetNodeId: "n2", IndexName: "idx-a", Bytes: 300}}, transfers)
+}
+
+func Test_selectSegments(t *testing.T) {
+	segmetas := []*structs.SegMeta{
+		{SegmentKey: "s1", VirtualTableName: "idx-a", OnDiskBytes: 100},
+		{SegmentKey: "s2", VirtualTableName: "idx-a", OnDiskBytes: 400},
+		{SegmentKey: "s3", VirtualTableName: "idx-a", OnDiskBytes: 250},
+		{SegmentKey: "s4", VirtualTableName: "idx-b", OnDiskBytes: 500},
+		{SegmentKey: "s5", VirtualTableName: "idx-a", OnDiskBytes: 500, OrgId: 1},
+	}
+	getKeys := func(selected []*structs.SegMeta) []string {
+		keys := make([]string, 0)
+		for _, segMeta := range selected {
+			keys = append(keys, segMeta.SegmentKey)
+		}
+		return keys
+	}
+
+	pickedKeys := make(map[string]struct{})
+	// s2 fits, then 100 is left which is less than half of s3 but all of s1
+	assert.Equal(t, []string{"s2", "s1"}, getKeys(selectSegments(segmetas, "idx-a", 500, 0, pickedKeys)))
+	// s3 is picked since more than half of it is left
+	assert.Equal(t, []string{"s3"}, getKeys(selectSegments(segmetas, "idx-a", 200, 0, pickedKeys)))
+	assert.Len(t, selectSegments(segmetas, "idx-a", 500, 0, pickedKeys), 0)
+	assert.Len(t, selectSegments(segmetas, "idx-b", 100, 0, pickedKeys), 0)
+}
+
+func Test_getClusterUsage(t *testing.T) {
+	initTestCluster(t)
+	peer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"nodeId":"peer-1","indices":[{"indexName":"idx-a","onDiskBytes":100}]}]`))
+	}))
+	defer peer.Close()
+	_, err := RegisterNode("peer-1", strings.TrimPrefix(peer.URL, "http://"), "", []string{ROLE_QUERY})
+	assert.Nil(t, err)
+	// an ingest node can not be asked for its segments
+	_, err = RegisterNode("peer-2", "127.0.0.1:1", "", []string{ROLE_INGEST})
+	assert.Nil(t, err)
+
+	usages, errs := getClusterUsage(PeerAuth{}, 0)
+	assert.Len(t, errs, 0)
+	nodeIds := make([]string, 0)
+	for _, usage := range usages {
+		nodeIds = append(nodeIds, usage.NodeId)
+		if usage.NodeId == "peer-1" {
+			assert.Equal(t, map[string]uint64{"idx-a": 100}, usage.IndexBytes)
+		}
+	}
+	assert.ElementsMatch(t, []string{config.GetHostID(), "peer-1"}, nodeIds)
+}
+
+func Test_moveSegmentAdoptResponseLost(t *testing.T) {
+	initTestCluster(t)
+	assert.Nil(t, virtualtable.InitVTable())
+	segKey := config.GetDataPath() + "peer-1/final/idx-a/0/1/1"
+	assert.Nil(t, ReceiveSegmentFile(segKey, "1.bsu", 0, []byte("abcdef")))
+	checksum, err := getFileChecksum(config.GetDataPath() + "peer-1/final/idx-a/0/1/1.bsu")
+	assert.Nil(t, err)
+	assert.Nil(t, AdoptSegment(&AdoptSegmentRequest{SegMeta: structs.SegMeta{SegmentKey: segKey, VirtualTableName: "idx-a"},
+		Checksums: map[string]string{"1.bsu": checksum}}))
+
+	// the adopt responses of peer-2 are lost, the ownership tells whether it adopted the segment
+	adopted := false
+	aborts := 0
+	peer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/cluster/segments/adopt":
+			w.WriteHeader(http.StatusInternalServerError)
+		case "/api/cluster/segments/abort":
+			aborts++
+		case "/api/cluster/ownership":
+			assert.Equal(t, "peer-2", r.URL.Query().Get("nodeId"))
+			assert.Equal(t, "true", r.URL.Query().Get("segments"))
+			segKeys := `[]`
+			if adopted {
+				segKeys = `["` + segKey + `"]`
+			}
+			_, _ = w.Write([]byte(`[{"nodeId":"peer-2","indices":[{"indexName":"idx-a","segmentKeys":` + segKeys + `}]}]`))
+		}
+	}))
+	defer peer.Close()
+	_, err = RegisterNode("peer-2", strings.TrimPrefix(peer.URL, "http://"), "", []string{ROLE_QUERY})
+	assert.Nil(t, err)
+
+	status := &MoverStatus{}
+	localSegmetas := segwriter.ReadLocalSegmeta()
+	assert.Len(t, localSegmetas, 1)
+	assert.NotNil(t, moveSegment(status, localSegmetas[0], "peer-2", PeerAuth{}))
+	assert.Equal(t, 1, aborts)
+	assert.Len(t, segwriter.ReadLocalSegmeta(), 1, "the segment stays if the target did not adopt it")
+
+	adopted = true
+	assert.Nil(t, moveSegment(status, localSegmetas[0], "peer-2", PeerAuth{}))
+	assert.Equal(t, 1, aborts)
+	assert.Len(t, segwriter.ReadLocalSegmeta(), 0, "only the target has the segment")
+}
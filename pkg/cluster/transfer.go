@@ -0,0 +1,291 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cespare/xxhash"
+	"github.com/siglens/siglens/pkg/apikeys"
+	"github.com/siglens/siglens/pkg/blob"
+	"github.com/siglens/siglens/pkg/common/fileutils"
+	"github.com/siglens/siglens/pkg/config"
+	"github.com/siglens/siglens/pkg/segment/query"
+	"github.com/siglens/siglens/pkg/segment/structs"
+	segwriter "github.com/siglens/siglens/pkg/segment/writer"
+	"github.com/siglens/siglens/pkg/virtualtable"
+	log "github.com/sirupsen/logrus"
+)
+
+/*
+	The receiving side of a segment move. The source sends the files of the segment in chunks, then asks the target
+	to adopt the segment with the checksums of its files. The target verifies them and adds the segment to its own
+	segmeta, from then on its queries search the segment and the source can delete it. The segment keeps its key, so
+	the nodes need the same data path. A move that fails or is cancelled has the target delete the files it received.
+*/
+
+const PEER_REQUEST_TIMEOUT = 5 * time.Minute
+
+var peerHttpClient = &http.Client{Timeout: PEER_REQUEST_TIMEOUT}
+
+type AdoptSegmentRequest struct {
+	SegMeta   structs.SegMeta   `json:"segMeta"`
+	Checksums map[string]string `json:"checksums"` // file path relative to the segment dir => checksum
+}
+
+// Sends a request to the query server of the node with the credentials of peerAuth
+func sendToNode(node *Node, method string, apiPath string, params url.Values, body []byte, peerAuth PeerAuth) ([]byte, error) {
+	scheme := "http"
+	if config.IsTlsEnabled() {
+		scheme = "https"
+	}
+	reqUrl := url.URL{Scheme: scheme, Host: node.Address, Path: apiPath, RawQuery: params.Encode()}
+	req, err := http.NewRequest(method, reqUrl.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if peerAuth.Authorization != "" {
+		req.Header.Set("Authorization", peerAuth.Authorization)
+	}
+	if peerAuth.ApiKey != "" {
+		req.Header.Set(apikeys.API_KEY_HEADER, peerAuth.ApiKey)
+	}
+	resp, err := peerHttpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, fmt.Errorf("node %v rejected the credentials of %v %v, the nodes need the same api keys", node.NodeId,
+			method, apiPath)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("node %v responded to %v %v with status %v: %v", node.NodeId, method, apiPath,
+			resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return respBody, nil
+}
+
+func getFileChecksum(fname string) (string, error) {
+	fd, err := os.Open(fname)
+	if err != nil {
+		return "", err
+	}
+	defer fd.Close()
+	digest := xxhash.New()
+	_, err = io.Copy(digest, fd)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatUint(digest.Sum64(), 16), nil
+}
+
+/*
+Returns the dir of the segment. The key has to be the key of a rotated segment,
+<<dataDir>>/<<hostname>>/final/<<index>>/<<stream>>/<<suffix>>/<<suffix>>, so that the files received for it can
+only be written in the dir of a segment
+*/
+func getSegmentDir(segKey string) (string, error) {
+	invalidErr := fmt.Errorf("invalid segment key %v, it should be %v<host>/final/<index>/<stream>/<suffix>/<suffix>",
+		segKey, config.GetDataPath())
+	if path.Clean(segKey) != segKey || !strings.HasPrefix(segKey, config.GetDataPath()) {
+		return "", invalidErr
+	}
+	parts := strings.Split(strings.TrimPrefix(segKey, config.GetDataPath()), "/")
+	if len(parts) != 6 || parts[1] != "final" || parts[4] != parts[5] {
+		return "", invalidErr
+	}
+	for _, part := range parts {
+		if part == "" || part == "." || part == ".." {
+			return "", invalidErr
+		}
+	}
+	if _, err := strconv.ParseUint(parts[4], 10, 64); err != nil {
+		return "", invalidErr
+	}
+	return path.Dir(segKey) + "/", nil
+}
+
+func getSegmentFilePath(segKey string, relFname string) (string, error) {
+	segDir, err := getSegmentDir(segKey)
+	if err != nil {
+		return "", err
+	}
+	if relFname == "" || path.IsAbs(relFname) || path.Clean(relFname) != relFname || strings.HasPrefix(relFname, "..") {
+		return "", fmt.Errorf("invalid segment file %v", relFname)
+	}
+	return segDir + relFname, nil
+}
+
+// Returns the files of the segment relative to its dir
+func getSegmentFiles(segDir string) []string {
+	allFiles := fileutils.GetAllFilesInDirectory(segDir)
+	retVal := make([]string, 0, len(allFiles))
+	for _, fname := range allFiles {
+		retVal = append(retVal, strings.TrimPrefix(fname, segDir))
+	}
+	return retVal
+}
+
+func isLocalSegment(segKey string) bool {
+	for _, segMeta := range segwriter.ReadLocalSegmeta() {
+		if segMeta.SegmentKey == segKey {
+			return true
+		}
+	}
+	return false
+}
+
+// Writes a chunk of a file of a segment that is moved to this node, a chunk at offset 0 starts the file over
+func ReceiveSegmentFile(segKey string, relFname string, offset uint64, data []byte) error {
+	fname, err := getSegmentFilePath(segKey, relFname)
+	if err != nil {
+		return err
+	}
+
+	var fd *os.File
+	if offset == 0 {
+		if isLocalSegment(segKey) {
+			return fmt.Errorf("segment %v is already on this node", segKey)
+		}
+		err = os.MkdirAll(path.Dir(fname), 0764)
+		if err != nil {
+			log.Errorf("ReceiveSegmentFile: failed to create the dir of fname=%v, err=%v", fname, err)
+			return err
+		}
+		fd, err = os.OpenFile(fname, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	} else {
+		finfo, statErr := os.Stat(fname)
+		if statErr != nil {
+			return fmt.Errorf("no chunk at offset 0 was received for file %v", relFname)
+		}
+		if uint64(finfo.Size()) != offset {
+			return fmt.Errorf("file %v has %v bytes, the chunk is at offset %v", relFname, finfo.Size(), offset)
+		}
+		fd, err = os.OpenFile(fname, os.O_WRONLY|os.O_APPEND, 0644)
+	}
+	if err != nil {
+		log.Errorf("ReceiveSegmentFile: failed to open fname=%v, err=%v", fname, err)
+		return err
+	}
+	defer fd.Close()
+
+	_, err = fd.Write(data)
+	if err != nil {
+		log.Errorf("ReceiveSegmentFile: failed to write fname=%v, err=%v", fname, err)
+		return err
+	}
+	return nil
+}
+
+/*
+Verifies the checksums of the received files of the segment and adds it to the segmeta of this node. The received
+files are deleted when a checksum does not match, so that the move can be tried again
+*/
+func AdoptSegment(request *AdoptSegmentRequest) error {
+	segMeta := request.SegMeta
+	segDir, err := getSegmentDir(segMeta.SegmentKey)
+	if err != nil {
+		return err
+	}
+	if len(request.Checksums) == 0 {
+		return fmt.Errorf("the checksums of the files of segment %v are required", segMeta.SegmentKey)
+	}
+	if isLocalSegment(segMeta.SegmentKey) {
+		return fmt.Errorf("segment %v is already on this node", segMeta.SegmentKey)
+	}
+
+	allFiles := make([]string, 0, len(request.Checksums))
+	for relFname, expected := range request.Checksums {
+		fname, err := getSegmentFilePath(segMeta.SegmentKey, relFname)
+		if err != nil {
+			return err
+		}
+		checksum, err := getFileChecksum(fname)
+		if err != nil || checksum != expected {
+			log.Errorf("AdoptSegment: checksum of fname=%v is %v instead of %v, deleting the segment, err=%v",
+				fname, checksum, expected, err)
+			if err := os.RemoveAll(segDir); err != nil {
+				log.Errorf("AdoptSegment: failed to remove dir=%v, err=%v", segDir, err)
+			}
+			return fmt.Errorf("checksum of file %v of segment %v does not match", relFname, segMeta.SegmentKey)
+		}
+		allFiles = append(allFiles, fname)
+	}
+
+	// the retention and the deletes of the index remove this dir
+	segMeta.SegbaseDir = segDir
+	err = blob.UploadSegmentFiles(allFiles)
+	if err != nil {
+		log.Errorf("AdoptSegment: failed to upload the files of segkey=%v, err=%v", segMeta.SegmentKey, err)
+	}
+	err = virtualtable.AddVirtualTable(&segMeta.VirtualTableName, segMeta.OrgId)
+	if err != nil {
+		log.Errorf("AdoptSegment: failed to add index %v, err=%v", segMeta.VirtualTableName, err)
+		return err
+	}
+	segwriter.AddNewRotatedSegment(segMeta)
+	// the source deletes its copy once this returns, the queries can not wait for the segmeta to be reread
+	query.AddRotatedSegmentMetadata(&segMeta)
+	err = blob.UploadIngestNodeDir()
+	if err != nil {
+		log.Errorf("AdoptSegment: failed to upload ingest node dir, err=%v", err)
+	}
+	log.Infof("AdoptSegment: adopted segkey=%v of index %v with %v files", segMeta.SegmentKey,
+		segMeta.VirtualTableName, len(allFiles))
+	return nil
+}
+
+// Deletes the files received for a segment whose move failed or was cancelled, an adopted segment is kept
+func AbortSegmentTransfer(segKey string) error {
+	segDir, err := getSegmentDir(segKey)
+	if err != nil {
+		return err
+	}
+	if isLocalSegment(segKey) {
+		return fmt.Errorf("segment %v was already adopted by this node", segKey)
+	}
+	if _, err := os.Stat(segDir); os.IsNotExist(err) {
+		return nil
+	}
+	err = os.RemoveAll(segDir)
+	if err != nil {
+		log.Errorf("AbortSegmentTransfer: failed to remove dir=%v, err=%v", segDir, err)
+		return err
+	}
+	fileutils.RecursivelyDeleteEmptyParentDirectories(segDir)
+	log.Infof("AbortSegmentTransfer: deleted the received files of segkey=%v", segKey)
+	return nil
+}
+
+func marshalAdoptRequest(segMeta *structs.SegMeta, checksums map[string]string) ([]byte, error) {
+	return json.Marshal(&AdoptSegmentRequest{SegMeta: *segMeta, Checksums: checksums})
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/siglens/siglens/pkg/apikeys"
+	"github.com/siglens/siglens/pkg/config"
+	"github.com/siglens/siglens/pkg/segment/query/metadata"
+	"github.com/siglens/siglens/pkg/segment/structs"
+	segwriter "github.com/siglens/siglens/pkg/segment/writer"
+	"github.com/siglens/siglens/pkg/virtualtable"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_getSegmentFilePath(t *testing.T) {
+	initTestCluster(t)
+	segKey := config.GetDataPath() + "peer-1/final/idx-a/0/1/1"
+
+	fname, err := getSegmentFilePath(segKey, "1.bsu")
+	assert.Nil(t, err)
+	assert.Equal(t, config.GetDataPath()+"peer-1/final/idx-a/0/1/1.bsu", fname)
+	fname, err = getSegmentFilePath(segKey, "rups/1.crup")
+	assert.Nil(t, err)
+	assert.Equal(t, config.GetDataPath()+"peer-1/final/idx-a/0/1/rups/1.crup", fname)
+
+	for _, relFname := range []string{"", "../1.bsu", "/etc/passwd", "rups/../../1.bsu"} {
+		_, err = getSegmentFilePath(segKey, relFname)
+		assert.NotNil(t, err, relFname)
+	}
+	badKeys := []string{"", "/tmp/1/1", config.GetDataPath() + "peer-1/../../1", config.GetDataPath() + "x",
+		config.GetDataPath() + "peer-1/final/idx-a/0/1", config.GetDataPath() + "peer-1/active/idx-a/0/1/1",
+		config.GetDataPath() + "peer-1/final/idx-a/0/1/2", config.GetDataPath() + "peer-1/final/idx-a/0/a/a",
+		config.GetDataPath() + "peer-1/final/idx-a/0/1/1/1", config.GetDataPath() + "peer-1/final/../0/1/1"}
+	for _, badKey := range badKeys {
+		_, err = getSegmentDir(badKey)
+		assert.NotNil(t, err, badKey)
+	}
+}
+
+func Test_ReceiveAndAdoptSegment(t *testing.T) {
+	initTestCluster(t)
+	assert.Nil(t, virtualtable.InitVTable())
+	segKey := config.GetDataPath() + "peer-1/final/idx-a/0/1/1"
+	segDir := config.GetDataPath() + "peer-1/final/idx-a/0/1/"
+
+	assert.Nil(t, ReceiveSegmentFile(segKey, "1.bsu", 0, []byte("abc")))
+	assert.Nil(t, ReceiveSegmentFile(segKey, "1.bsu", 3, []byte("def")))
+	// chunks have to follow each other
+	assert.NotNil(t, ReceiveSegmentFile(segKey, "1.bsu", 10, []byte("ghi")))
+	assert.NotNil(t, ReceiveSegmentFile(segKey, "1.sst", 5, []byte("ghi")))
+	assert.Nil(t, ReceiveSegmentFile(segKey, "rups/1.crup", 0, []byte{}))
+	data, err := os.ReadFile(segDir + "1.bsu")
+	assert.Nil(t, err)
+	assert.Equal(t, "abcdef", string(data))
+
+	checksum, err := getFileChecksum(segDir + "1.bsu")
+	assert.Nil(t, err)
+	emptyChecksum, err := getFileChecksum(segDir + "rups/1.crup")
+	assert.Nil(t, err)
+	segMeta := structs.SegMeta{SegmentKey: segKey, VirtualTableName: "idx-a", OnDiskBytes: 6, RecordCount: 2}
+
+	// a mismatch deletes the received files
+	err = AdoptSegment(&AdoptSegmentRequest{SegMeta: segMeta,
+		Checksums: map[string]string{"1.bsu": "bad", "rups/1.crup": emptyChecksum}})
+	assert.NotNil(t, err)
+	_, err = os.Stat(segDir)
+	assert.True(t, os.IsNotExist(err))
+	assert.Len(t, segwriter.ReadLocalSegmeta(), 0)
+
+	assert.Nil(t, ReceiveSegmentFile(segKey, "1.bsu", 0, []byte("abcdef")))
+	assert.Nil(t, ReceiveSegmentFile(segKey, "rups/1.crup", 0, []byte{}))
+	err = AdoptSegment(&AdoptSegmentRequest{SegMeta: segMeta,
+		Checksums: map[string]string{"1.bsu": checksum, "rups/1.crup": emptyChecksum}})
+	assert.Nil(t, err)
+	localSegmetas := segwriter.ReadLocalSegmeta()
+	assert.Len(t, localSegmetas, 1)
+	assert.Equal(t, segKey, localSegmetas[0].SegmentKey)
+	assert.Equal(t, segDir, localSegmetas[0].SegbaseDir)
+	assert.Equal(t, uint64(6), metadata.GetOnDiskBytesRotated(segKey), "the queries search it right away")
+	indexNames, err := virtualtable.GetVirtualTableNames(0)
+	assert.Nil(t, err)
+	assert.Contains(t, indexNames, "idx-a")
+
+	// the segment is now owned by this node
+	assert.NotNil(t, ReceiveSegmentFile(segKey, "1.bsu", 0, []byte("abcdef")))
+	assert.NotNil(t, AdoptSegment(&AdoptSegmentRequest{SegMeta: segMeta, Checksums: map[string]string{"1.bsu": checksum}}))
+	assert.NotNil(t, AbortSegmentTransfer(segKey))
+	_, err = os.Stat(segDir + "1.bsu")
+	assert.Nil(t, err)
+}
+
+func Test_AbortSegmentTransfer(t *testing.T) {
+	initTestCluster(t)
+	segKey := config.GetDataPath() + "peer-1/final/idx-a/0/2/2"
+	segDir := config.GetDataPath() + "peer-1/final/idx-a/0/2/"
+
+	assert.Nil(t, ReceiveSegmentFile(segKey, "2.bsu", 0, []byte("abc")))
+	assert.Nil(t, ReceiveSegmentFile(segKey, "rups/2.crup", 0, []byte("def")))
+	assert.Nil(t, AbortSegmentTransfer(segKey))
+	_, err := os.Stat(segDir)
+	assert.True(t, os.IsNotExist(err))
+	// nothing was received
+	assert.Nil(t, AbortSegmentTransfer(segKey))
+	assert.NotNil(t, AbortSegmentTransfer("/tmp/2/2"))
+
+	// a key that is not the key of a segment does not remove other dirs
+	assert.Nil(t, ReceiveSegmentFile(segKey, "2.bsu", 0, []byte("abc")))
+	assert.NotNil(t, AbortSegmentTransfer(config.GetDataPath()+"x"))
+	assert.NotNil(t, ReceiveSegmentFile(config.GetDataPath()+"x", "apikeys.json", 0, []byte("{}")))
+	_, err = os.Stat(segDir + "2.bsu")
+	assert.Nil(t, err)
+	assert.Nil(t, AbortSegmentTransfer(segKey))
+}
+
+func Test_sendToNodeCredentials(t *testing.T) {
+	peer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(apikeys.API_KEY_HEADER) != "slk_abc" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(r.Header.Get("Authorization")))
+	}))
+	defer peer.Close()
+	node := &Node{NodeId: "peer-1", Address: strings.TrimPrefix(peer.URL, "http://")}
+
+	respBody, err := sendToNode(node, http.MethodGet, "/api/cluster/ownership", url.Values{}, nil,
+		PeerAuth{Authorization: "Basic dXNlcjpwYXNz", ApiKey: "slk_abc"})
+	assert.Nil(t, err)
+	assert.Equal(t, "Basic dXNlcjpwYXNz", string(respBody))
+
+	_, err = sendToNode(node, http.MethodGet, "/api/cluster/ownership", url.Values{}, nil,
+		PeerAuth{Authorization: "Bearer slk_abc"})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "the nodes need the same api keys")
+}
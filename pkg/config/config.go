@@ -37,6 +37,7 @@ import (
 )
 
 const MINUTES_REREAD_CONFIG = 15
+const DEFAULT_PARTIAL_RESULTS_SEGMENTS = 5
 
 var configFileLastModified uint64
 
@@ -78,8 +79,12 @@ type LogConfig struct {
 }
 
 type TLSConfig struct {
-	Enabled    bool   `yaml:"enabled"`
-	ACMEFolder string `yaml:"acmeFolder"` // folder to store acme certificates
+	Enabled            bool   `yaml:"enabled"`
+	ACMEFolder         string `yaml:"acmeFolder"`         // folder to store acme certificates
+	CertificatePath    string `yaml:"certificatePath"`    // PEM certificate chain, used instead of acme when set
+	PrivateKeyPath     string `yaml:"privateKeyPath"`     // PEM private key of the certificate
+	IngestClientCAPath string `yaml:"ingestClientCAPath"` // PEM CA bundle the client certificates of the ingest server are verified with
+	IngestClientAuth   string `yaml:"ingestClientAuth"`   // require or verifyIfGiven, the default is require
 }
 
 type AlertConfig struct {
@@ -102,6 +107,117 @@ type DatabaseConfig struct {
 	Dbname   string `yaml:"dbname"`
 }
 
+type LookupConfig struct {
+	Name         string `yaml:"name"`         // name used by the lookup command
+	Driver       string `yaml:"driver"`       // postgres or mysql
+	DSN          string `yaml:"dsn"`          // connection string for the external database
+	Table        string `yaml:"table"`        // table (or view) to read enrichment rows from
+	CacheTTLSecs uint64 `yaml:"cacheTTLSecs"` // how long fetched rows are cached
+	MaxRows      uint64 `yaml:"maxRows"`      // max rows fetched from the database per lookup
+}
+
+type EmbeddingsConfig struct {
+	Enabled       bool   `yaml:"enabled"`
+	ModelEndpoint string `yaml:"modelEndpoint"` // OpenAI compatible /embeddings endpoint
+	Model         string `yaml:"model"`         // model name sent to the endpoint
+	APIKey        string `yaml:"apiKey"`        // optional bearer token for the endpoint
+	MaxTemplates  uint64 `yaml:"maxTemplates"`  // max number of log templates kept in the similarity index
+}
+
+type DedupConfig struct {
+	Enabled         bool              `yaml:"enabled"`
+	WindowSecs      uint64            `yaml:"windowSecs"`      // a document whose _id was ingested within this window is dropped
+	MaxIdsPerIndex  uint64            `yaml:"maxIdsPerIndex"`  // max number of recent _ids remembered per index
+	IndexWindowSecs map[string]uint64 `yaml:"indexWindowSecs"` // per index overrides of windowSecs
+}
+
+type UnitParsingConfig struct {
+	Enabled   bool              `yaml:"enabled"`
+	AllFields bool              `yaml:"allFields"` // detect units in every string field, not only the ones in fields
+	Fields    map[string]string `yaml:"fields"`    // field name to the unit its values are converted to, empty for the base unit
+}
+
+type MultilineRule struct {
+	Index        string `yaml:"index"`
+	Field        string `yaml:"field"`        // field holding the log line, defaults to message
+	StartPattern string `yaml:"startPattern"` // regex matching the first line of an event, other lines are appended to the previous event
+	MaxLines     uint64 `yaml:"maxLines"`     // an event is completed once it has this many lines
+	MaxWaitMs    uint64 `yaml:"maxWaitMs"`    // an event is completed if no line was appended to it for this long
+	GroupBy      string `yaml:"groupBy"`      // optional field, such as host, so lines from different sources aren't merged together
+}
+
+type BackpressureConfig struct {
+	Enabled           bool   `yaml:"enabled"`
+	MaxInMemoryMB     uint64 `yaml:"maxInMemoryMB"`     // ingest is rejected once the unflushed ingest buffers exceed this size
+	MaxActiveFlushes  uint64 `yaml:"maxActiveFlushes"`  // ingest is rejected once this many buffer flushes are in progress
+	MaxRetryAfterSecs uint64 `yaml:"maxRetryAfterSecs"` // upper bound of the Retry-After sent with a 429
+}
+
+type MetricsDownsamplingConfig struct {
+	Enabled           bool   `yaml:"enabled"`
+	FiveMinAfterHours uint64 `yaml:"fiveMinAfterHours"` // metrics segments older than this get 5m rollups
+	OneHourAfterHours uint64 `yaml:"oneHourAfterHours"` // metrics segments older than this get 1h rollups
+	RawRetentionHours uint64 `yaml:"rawRetentionHours"` // raw datapoints with rollups are deleted after this, 0 keeps them
+}
+
+type TailSamplingConfig struct {
+	Enabled            bool    `yaml:"enabled"`
+	DecisionWaitSecs   uint64  `yaml:"decisionWaitSecs"`   // spans of a trace are buffered this long after its first span before it is kept or dropped
+	MaxBufferedTraces  uint64  `yaml:"maxBufferedTraces"`  // the oldest traces are decided early once this many are buffered
+	KeepErrors         bool    `yaml:"keepErrors"`         // keep traces that have a span with an error status
+	LatencyThresholdMs uint64  `yaml:"latencyThresholdMs"` // keep traces that have a span at least this long, 0 disables the policy
+	SamplePercent      float64 `yaml:"samplePercent"`      // percentage of the other traces that are kept
+}
+
+// A size of 0 uses the default
+type SearchWorkersConfig struct {
+	CPUWorkers         uint64 `yaml:"cpuWorkers"`         // blocks decompressed and filtered at once across all queries, defaults to GOMAXPROCS
+	IOWorkers          uint64 `yaml:"ioWorkers"`          // column blocks read ahead from disk at once, defaults to twice the cpu workers
+	MaxWorkersPerQuery uint64 `yaml:"maxWorkersPerQuery"` // block workers of each segment a query searches, defaults to 4
+}
+
+type RebalanceConfig struct {
+	MaxBytesPerSec     uint64 `yaml:"maxBytesPerSec"`     // copy rate of the segment files to the other nodes, defaults to 50MB/s
+	MaxConcurrentMoves uint64 `yaml:"maxConcurrentMoves"` // segments copied at once, defaults to 2
+	MaxMovesPerRun     uint64 `yaml:"maxMovesPerRun"`     // segments a node moves out in a rebalance, defaults to 1000
+}
+
+type IntegrityConfig struct {
+	IntervalMins    uint64 `yaml:"intervalMins"`    // how often the segments are checked, defaults to 360
+	DeleteOrphans   bool   `yaml:"deleteOrphans"`   // delete the segment dirs that no segmeta has, otherwise they are only reported
+	OrphanGraceMins uint64 `yaml:"orphanGraceMins"` // orphaned dirs modified in this many minutes are kept, defaults to 60
+}
+
+type PanelCacheConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	TTLSecs    uint64 `yaml:"ttlSecs"`    // results are served from the cache without a refresh for this long
+	StaleSecs  uint64 `yaml:"staleSecs"`  // past the ttl, results are served for this long more while they are refreshed in the background
+	MaxEntries uint64 `yaml:"maxEntries"` // the least recently used results are evicted past this many
+}
+
+type ApiKeysConfig struct {
+	Enabled bool `yaml:"enabled"` // requests need an api key with the scope of the endpoint
+}
+
+// A limit of 0 is unlimited
+type QuotaLimits struct {
+	MaxStoredBytes          uint64 `yaml:"maxStoredBytes"`          // ingest is rejected once the logs and metrics on disk exceed this size
+	MaxDailyIngestBytes     uint64 `yaml:"maxDailyIngestBytes"`     // ingest is rejected once this many bytes were ingested in the current UTC day
+	MaxConcurrentQueries    uint64 `yaml:"maxConcurrentQueries"`    // queries past this many running ones are rejected
+	MaxScannedBytesPerQuery uint64 `yaml:"maxScannedBytesPerQuery"` // queries that would search more segment bytes than this are rejected
+}
+
+type OrgQuotaConfig struct {
+	OrgId       uint64 `yaml:"orgId"`
+	QuotaLimits `yaml:",inline"`
+}
+
+type QuotasConfig struct {
+	Enabled     bool             `yaml:"enabled"`
+	QuotaLimits `yaml:",inline"` // limits of the orgs that are not listed in orgs
+	Orgs        []OrgQuotaConfig `yaml:"orgs"`
+}
+
 /*  If you add a new config parameters to the Configuration struct below, make sure to add the default value
 assignment in the following functions
 1) ExtractConfigData function
@@ -123,6 +239,8 @@ type Configuration struct {
 	LicenseKeyPath             string   `yaml:"licenseKeyPath"`
 	ESVersion                  string   `yaml:"esVersion"`
 	Debug                      bool     `yaml:"debug"`                  // debug logging
+	MmapSegmentFiles           bool     `yaml:"mmapSegmentFiles"`       // read the column files of the segments through mmap instead of read syscalls
+	PartialResultsSegments     uint64   `yaml:"partialResultsSegments"` // the aggregations of the websocket searches are sent after every this many searched segments, 0 uses the default
 	MemoryThresholdPercent     uint64   `yaml:"memoryThresholdPercent"` // percent of all available free data allocated for loading micro indices in memory
 	DataDiskThresholdPercent   uint64   `yaml:"dataDiskThresholdPercent"`
 	GRPCPort                   uint64   `yaml:"grpcPort"` // Address to listen for GRPC connections
@@ -138,14 +256,28 @@ type Configuration struct {
 	analyticsEnabledConverted  bool
 	AgileAggsEnabled           string `yaml:"agileAggsEnabled"` // should we read/write AgileAggsTrees?
 	AgileAggsEnabledConverted  bool
-	QueryHostname              string         `yaml:"queryHostname"` // hostname of the query server. i.e. if DNS is https://cloud.siglens.com, this should be cloud.siglens.com
-	IngestUrl                  string         `yaml:"ingestUrl"`     // full address of the ingest server, including scheme and port, e.g. https://ingest.siglens.com:8080
-	S3                         S3Config       `yaml:"s3"`            // s3 related config
-	Etcd                       EtcdConfig     `yaml:"etcd"`          // Etcd related config
-	Log                        LogConfig      `yaml:"log"`           // Log related config
-	TLS                        TLSConfig      `yaml:"tls"`           // TLS related config
-	EmailConfig                EmailConfig    `yaml:"emailConfig"`
-	DatabaseConfig             DatabaseConfig `yaml:"minionSearch"`
+	QueryHostname              string                    `yaml:"queryHostname"` // hostname of the query server. i.e. if DNS is https://cloud.siglens.com, this should be cloud.siglens.com
+	IngestUrl                  string                    `yaml:"ingestUrl"`     // full address of the ingest server, including scheme and port, e.g. https://ingest.siglens.com:8080
+	S3                         S3Config                  `yaml:"s3"`            // s3 related config
+	Etcd                       EtcdConfig                `yaml:"etcd"`          // Etcd related config
+	Log                        LogConfig                 `yaml:"log"`           // Log related config
+	TLS                        TLSConfig                 `yaml:"tls"`           // TLS related config
+	EmailConfig                EmailConfig               `yaml:"emailConfig"`
+	DatabaseConfig             DatabaseConfig            `yaml:"minionSearch"`
+	Lookups                    []LookupConfig            `yaml:"lookups"`             // external databases usable by the lookup command
+	Embeddings                 EmbeddingsConfig          `yaml:"embeddings"`          // embeddings used by the similar_to command
+	Dedup                      DedupConfig               `yaml:"dedup"`               // _id based deduplication of _bulk requests
+	UnitParsing                UnitParsingConfig         `yaml:"unitParsing"`         // parsing of values like 10ms or 3.5GB into numbers at ingest
+	Backpressure               BackpressureConfig        `yaml:"backpressure"`        // rejecting ingest with a 429 when the ingest buffers are full
+	Multiline                  []MultilineRule           `yaml:"multiline"`           // per index rules for merging lines of stack traces into one event
+	MetricsDownsampling        MetricsDownsamplingConfig `yaml:"metricsDownsampling"` // 5m and 1h rollups of old metrics data
+	TailSampling               TailSamplingConfig        `yaml:"tailSampling"`        // keeping or dropping whole traces at ingest
+	PanelCache                 PanelCacheConfig          `yaml:"panelCache"`          // caching of the dashboard panel query results
+	ApiKeys                    ApiKeysConfig             `yaml:"apiKeys"`             // authentication of the http requests with scoped api keys
+	Quotas                     QuotasConfig              `yaml:"quotas"`              // per org limits on storage, ingest and queries
+	SearchWorkers              SearchWorkersConfig       `yaml:"searchWorkers"`       // worker pools that search the blocks of the segments
+	Rebalance                  RebalanceConfig           `yaml:"rebalance"`           // moving segments between the nodes of the cluster
+	Integrity                  IntegrityConfig           `yaml:"integrity"`           // checking the segments against their metadata
 }
 
 var runningConfig Configuration
@@ -284,6 +416,15 @@ func IsTlsEnabled() bool {
 	return runningConfig.TLS.Enabled
 }
 
+func GetTLSConfig() TLSConfig {
+	return runningConfig.TLS
+}
+
+// returns if the certificate comes from acme rather than from the configured certificate files
+func IsTlsAcmeEnabled() bool {
+	return runningConfig.TLS.Enabled && runningConfig.TLS.CertificatePath == "" && runningConfig.TLS.ACMEFolder != ""
+}
+
 // used by
 func GetQueryHostname() string {
 	return runningConfig.QueryHostname
@@ -311,6 +452,79 @@ func GetSiglensDBConfig() (string, string, uint64, string, string, string) {
 	return runningConfig.DatabaseConfig.Provider, runningConfig.DatabaseConfig.Host, runningConfig.DatabaseConfig.Port, runningConfig.DatabaseConfig.User, runningConfig.DatabaseConfig.Password, runningConfig.DatabaseConfig.Dbname
 }
 
+func GetUnitParsingConfig() UnitParsingConfig {
+	return runningConfig.UnitParsing
+}
+
+func GetMultilineRules() []MultilineRule {
+	return runningConfig.Multiline
+}
+
+func GetBackpressureConfig() BackpressureConfig {
+	return runningConfig.Backpressure
+}
+
+func GetMetricsDownsamplingConfig() MetricsDownsamplingConfig {
+	return runningConfig.MetricsDownsampling
+}
+
+func GetTailSamplingConfig() TailSamplingConfig {
+	return runningConfig.TailSampling
+}
+
+func GetPanelCacheConfig() PanelCacheConfig {
+	return runningConfig.PanelCache
+}
+
+func GetApiKeysConfig() ApiKeysConfig {
+	return runningConfig.ApiKeys
+}
+
+func GetSearchWorkersConfig() SearchWorkersConfig {
+	return runningConfig.SearchWorkers
+}
+
+func GetRebalanceConfig() RebalanceConfig {
+	return runningConfig.Rebalance
+}
+
+func GetIntegrityConfig() IntegrityConfig {
+	return runningConfig.Integrity
+}
+
+func GetQuotasConfig() QuotasConfig {
+	return runningConfig.Quotas
+}
+
+// Returns the quota limits of the org, which are all 0 (unlimited) if quotas are disabled
+func GetQuotaLimits(orgid uint64) QuotaLimits {
+	if !runningConfig.Quotas.Enabled {
+		return QuotaLimits{}
+	}
+	for _, orgQuota := range runningConfig.Quotas.Orgs {
+		if orgQuota.OrgId == orgid {
+			return orgQuota.QuotaLimits
+		}
+	}
+	return runningConfig.Quotas.QuotaLimits
+}
+
+func GetDedupConfig() DedupConfig {
+	return runningConfig.Dedup
+}
+
+func GetEmbeddingsConfig() EmbeddingsConfig {
+	return runningConfig.Embeddings
+}
+
+func IsEmbeddingsEnabled() bool {
+	return runningConfig.Embeddings.Enabled
+}
+
+func GetLookupsConfig() []LookupConfig {
+	return runningConfig.Lookups
+}
+
 func GetLogPrefix() string {
 	return runningConfig.Log.LogPrefix
 }
@@ -340,6 +554,17 @@ func IsSafeMode() bool {
 	return runningConfig.SafeServerStart
 }
 
+func IsMmapSegmentFilesEnabled() bool {
+	return runningConfig.MmapSegmentFiles
+}
+
+func GetPartialResultsSegments() uint64 {
+	if runningConfig.PartialResultsSegments == 0 {
+		return DEFAULT_PARTIAL_RESULTS_SEGMENTS
+	}
+	return runningConfig.PartialResultsSegments
+}
+
 func GetRunningConfigAsJsonStr() (string, error) {
 	buffer := new(bytes.Buffer)
 	encoder := json.NewEncoder(buffer)
@@ -448,6 +673,10 @@ func SetDebugMode(log bool) {
 	runningConfig.Debug = log
 }
 
+func SetMmapSegmentFiles(enabled bool) {
+	runningConfig.MmapSegmentFiles = enabled
+}
+
 func SetDataPath(path string) {
 	runningConfig.DataPath = path
 }
@@ -574,7 +803,7 @@ func InitializeDefaultConfig() {
 		AgileAggsEnabledConverted:  true,
 		QueryHostname:              "",
 		Log:                        LogConfig{"", 100, false},
-		TLS:                        TLSConfig{false, "certs/"},
+		TLS:                        TLSConfig{Enabled: false, ACMEFolder: "certs/"},
 		DatabaseConfig:             DatabaseConfig{Enabled: true, Provider: "sqlite"},
 	}
 	_ = InitDerivedConfig("test-uuid") // This is only used for testing
@@ -666,7 +895,12 @@ func ExtractConfigData(yamlData []byte) (Configuration, error) {
 	}
 
 	if len(config.IngestUrl) <= 0 {
-		config.IngestUrl = "http://localhost:" + strconv.FormatUint(config.IngestPort, 10)
+		// the ingest server serves tls when the certificate files are configured
+		if config.TLS.Enabled && config.TLS.CertificatePath != "" {
+			config.IngestUrl = "https://localhost:" + strconv.FormatUint(config.IngestPort, 10)
+		} else {
+			config.IngestUrl = "http://localhost:" + strconv.FormatUint(config.IngestPort, 10)
+		}
 	}
 
 	if !config.S3.Enabled {
@@ -737,6 +971,14 @@ func ExtractConfigData(yamlData []byte) (Configuration, error) {
 	if config.S3IngestBufferSize == 0 {
 		config.S3IngestBufferSize = 1000
 	}
+	for i := range config.Lookups {
+		if config.Lookups[i].CacheTTLSecs == 0 {
+			config.Lookups[i].CacheTTLSecs = 300
+		}
+		if config.Lookups[i].MaxRows == 0 {
+			config.Lookups[i].MaxRows = 10_000
+		}
+	}
 	if len(config.TLS.ACMEFolder) >= 0 && strings.HasPrefix(config.TLS.ACMEFolder, "./") {
 		config.TLS.ACMEFolder = strings.Trim(config.TLS.ACMEFolder, "./")
 	}
@@ -978,17 +1220,6 @@ func ProcessGetConfigAsJson(ctx *fasthttp.RequestCtx) {
 	utils.WriteJsonResponse(ctx, &runningConfig)
 }
 
-func ProcessForceReadConfig(ctx *fasthttp.RequestCtx) {
-	newConfig, err := ReadConfigFile(configFilePath)
-	if err != nil {
-		log.Errorf("refreshConfig: Cannot stat config file while re-reading, err= %v", err)
-		return
-	}
-	SetConfig(newConfig)
-	ctx.SetStatusCode(fasthttp.StatusOK)
-	utils.WriteJsonResponse(ctx, &runningConfig)
-}
-
 func refreshConfig() {
 	for {
 		time.Sleep(MINUTES_REREAD_CONFIG * time.Minute)
@@ -1000,13 +1231,11 @@ func refreshConfig() {
 		modifiedTime := fileInfo.ModTime()
 		modifiedTimeSec := uint64(modifiedTime.UTC().Unix())
 		if modifiedTimeSec > configFileLastModified {
-			newConfig, err := ReadConfigFile(configFilePath)
+			_, err := ReloadConfig()
 			if err != nil {
-				log.Errorf("refreshConfig: Cannot stat config file while re-reading, err= %v", err)
+				log.Errorf("refreshConfig: Cannot reload config file, err= %v", err)
 				continue
 			}
-			SetConfig(newConfig)
-			configFileLastModified = modifiedTimeSec
 		}
 	}
 }
@@ -0,0 +1,170 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/siglens/siglens/pkg/utils"
+	log "github.com/sirupsen/logrus"
+	"github.com/valyala/fasthttp"
+)
+
+/*
+	Reloads the settings that can change while the server runs from the config file, on a SIGHUP, a POST to
+	config/reload or when the file is modified. The other settings are only read on startup, a reload keeps their
+	running values and reports the ones that differ in the file so that the server can be restarted for them.
+*/
+
+type ReloadResult struct {
+	Applied         []string `json:"applied"`         // the settings that changed
+	RestartRequired []string `json:"restartRequired"` // the settings that changed in the file but need a restart
+}
+
+type reloadableSetting struct {
+	name      string
+	copyValue func(from *Configuration, to *Configuration)
+}
+
+var reloadableSettings = []reloadableSetting{
+	{"retentionHours", func(from *Configuration, to *Configuration) { to.RetentionHours = from.RetentionHours }},
+	{"debug", func(from *Configuration, to *Configuration) { to.Debug = from.Debug }},
+	{"eventTypeKeywords", func(from *Configuration, to *Configuration) { to.EventTypeKeywords = from.EventTypeKeywords }},
+	{"quotas", func(from *Configuration, to *Configuration) { to.Quotas = from.Quotas }},
+	{"backpressure.maxInMemoryMB", func(from *Configuration, to *Configuration) {
+		to.Backpressure.MaxInMemoryMB = from.Backpressure.MaxInMemoryMB
+	}},
+	{"backpressure.maxActiveFlushes", func(from *Configuration, to *Configuration) {
+		to.Backpressure.MaxActiveFlushes = from.Backpressure.MaxActiveFlushes
+	}},
+	{"backpressure.maxRetryAfterSecs", func(from *Configuration, to *Configuration) {
+		to.Backpressure.MaxRetryAfterSecs = from.Backpressure.MaxRetryAfterSecs
+	}},
+	{"searchWorkers.maxWorkersPerQuery", func(from *Configuration, to *Configuration) {
+		to.SearchWorkers.MaxWorkersPerQuery = from.SearchWorkers.MaxWorkersPerQuery
+	}},
+	{"rebalance.maxBytesPerSec", func(from *Configuration, to *Configuration) {
+		to.Rebalance.MaxBytesPerSec = from.Rebalance.MaxBytesPerSec
+	}},
+	{"tls.certificatePath", func(from *Configuration, to *Configuration) {
+		to.TLS.CertificatePath = from.TLS.CertificatePath
+	}},
+	{"tls.privateKeyPath", func(from *Configuration, to *Configuration) {
+		to.TLS.PrivateKeyPath = from.TLS.PrivateKeyPath
+	}},
+	{"tls.ingestClientCAPath", func(from *Configuration, to *Configuration) {
+		to.TLS.IngestClientCAPath = from.TLS.IngestClientCAPath
+	}},
+}
+
+var reloadLock sync.Mutex
+var reloadHooks = make([]func(), 0)
+
+/*
+Adds a func that applies the reloaded settings to the state that was made from them on startup. The hooks are
+called after every reload, so that the certificates are also read again when only their files changed
+*/
+func AddReloadHook(hook func()) {
+	reloadLock.Lock()
+	defer reloadLock.Unlock()
+	reloadHooks = append(reloadHooks, hook)
+}
+
+// Reads the config file and applies the settings that can be reloaded
+func ReloadConfig() (*ReloadResult, error) {
+	newConfig, err := ReadConfigFile(configFilePath)
+	if err != nil {
+		log.Errorf("ReloadConfig: failed to read config file=%v, err=%v", configFilePath, err)
+		return nil, err
+	}
+	fileInfo, err := os.Stat(configFilePath)
+	if err == nil {
+		configFileLastModified = uint64(fileInfo.ModTime().UTC().Unix())
+	}
+	return applyReloadedConfig(newConfig), nil
+}
+
+func applyReloadedConfig(newConfig Configuration) *ReloadResult {
+	reloadLock.Lock()
+	defer reloadLock.Unlock()
+
+	result := &ReloadResult{Applied: make([]string, 0), RestartRequired: make([]string, 0)}
+	mergedConfig := runningConfig
+	for _, setting := range reloadableSettings {
+		before := mergedConfig
+		setting.copyValue(&newConfig, &mergedConfig)
+		if !reflect.DeepEqual(before, mergedConfig) {
+			result.Applied = append(result.Applied, setting.name)
+		}
+	}
+	result.RestartRequired = getChangedSettings(mergedConfig, newConfig)
+	runningConfig = mergedConfig
+
+	setLogLevel()
+	for _, hook := range reloadHooks {
+		hook()
+	}
+	if len(result.Applied) > 0 || len(result.RestartRequired) > 0 {
+		log.Infof("applyReloadedConfig: applied %v, need a restart for %v", result.Applied, result.RestartRequired)
+	}
+	return result
+}
+
+// Returns the yaml names of the top level settings that differ between the two configs
+func getChangedSettings(oldConfig Configuration, newConfig Configuration) []string {
+	changed := make([]string, 0)
+	oldValue := reflect.ValueOf(oldConfig)
+	newValue := reflect.ValueOf(newConfig)
+	configType := oldValue.Type()
+	for i := 0; i < configType.NumField(); i++ {
+		field := configType.Field(i)
+		yamlName := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if field.PkgPath != "" || yamlName == "" {
+			continue
+		}
+		if !reflect.DeepEqual(oldValue.Field(i).Interface(), newValue.Field(i).Interface()) {
+			changed = append(changed, yamlName)
+		}
+	}
+	return changed
+}
+
+func setLogLevel() {
+	if IsDebugMode() {
+		log.SetLevel(log.DebugLevel)
+	} else {
+		log.SetLevel(log.InfoLevel)
+	}
+}
+
+// Handles config/reload
+func ProcessReloadConfig(ctx *fasthttp.RequestCtx) {
+	result, err := ReloadConfig()
+	if err != nil {
+		var httpResp utils.HttpServerResponse
+		ctx.SetStatusCode(fasthttp.StatusBadRequest)
+		httpResp.Message = "Failed to read the config file: " + err.Error()
+		httpResp.StatusCode = fasthttp.StatusBadRequest
+		utils.WriteResponse(ctx, httpResp)
+		return
+	}
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	utils.WriteJsonResponse(ctx, result)
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_applyReloadedConfig(t *testing.T) {
+	yamlData := []byte(`
+ queryPort: 5122
+ retentionHours: 90
+ backpressure:
+  enabled: true
+  maxInMemoryMB: 512
+ tls:
+  enabled: false
+  certificatePath: "old.crt"
+`)
+	initialConfig, err := ExtractConfigData(yamlData)
+	assert.Nil(t, err)
+	SetConfig(initialConfig)
+	defer SetConfig(initialConfig)
+
+	numHookCalls := 0
+	AddReloadHook(func() { numHookCalls++ })
+
+	result := applyReloadedConfig(initialConfig)
+	assert.Equal(t, []string{}, result.Applied)
+	assert.Equal(t, []string{}, result.RestartRequired)
+	assert.Equal(t, 1, numHookCalls, "the hooks run on every reload")
+
+	newConfig := initialConfig
+	newConfig.QueryPort = 6122
+	newConfig.RetentionHours = 24
+	newConfig.Backpressure.MaxInMemoryMB = 1024
+	newConfig.Backpressure.Enabled = false
+	newConfig.TLS.CertificatePath = "new.crt"
+	result = applyReloadedConfig(newConfig)
+	assert.Equal(t, []string{"retentionHours", "backpressure.maxInMemoryMB", "tls.certificatePath"}, result.Applied)
+	assert.Equal(t, []string{"queryPort", "backpressure"}, result.RestartRequired)
+	assert.Equal(t, 2, numHookCalls)
+
+	assert.Equal(t, 24, GetRetentionHours())
+	assert.Equal(t, uint64(1024), GetBackpressureConfig().MaxInMemoryMB)
+	assert.True(t, GetBackpressureConfig().Enabled, "enabling the backpressure needs a restart")
+	assert.Equal(t, "new.crt", GetTLSConfig().CertificatePath)
+	assert.Equal(t, initialConfig.QueryPort, GetQueryPort())
+}
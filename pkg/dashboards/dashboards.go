@@ -392,6 +392,12 @@ func updateDashboard(id string, dName string, dashboardDetails map[string]interf
 		return errors.New("updateDashboard: Error fetching dashboard details")
 	}
 
+	err = validateDashboardVariables(dashboardDetails)
+	if err != nil {
+		log.Errorf("updateDashboard: invalid variables in Dashboard=%v, err=%v", id, err)
+		return err
+	}
+
 	// Check if isFavorite is provided in the update
 	if _, exists := currentDashboardDetails["isFavorite"]; !exists {
 		// If isFavorite does not exist in currentDashboardDetails, set it to false
@@ -0,0 +1,715 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dashboards
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/valyala/fasthttp"
+
+	"github.com/siglens/siglens/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+/*
+Conversion between the SigLens dashboards and the dashboard JSON model of Grafana. The export response is in the
+format of the Grafana dashboard API, {"dashboard": {...}}, and the import accepts it as well as a bare dashboard.
+Both return "warnings" for the parts that could not be translated, e.g. a panel type SigLens does not have
+*/
+
+const GRAFANA_SCHEMA_VERSION = 39
+const GRAFANA_GRID_COLUMNS = 24
+const GRAFANA_ROW_HEIGHT_PX = 30
+
+// Width of the panel container the imported panels are laid out for
+const DEFAULT_PANEL_CONTAINER_WIDTH_PX = 1313
+
+const DEFAULT_TIME_RANGE = "Last 1 Hr"
+
+const (
+	GRAFANA_DATASOURCE_PROMETHEUS = "prometheus"
+	GRAFANA_DATASOURCE_LOKI       = "loki"
+	GRAFANA_DATASOURCE_SIGLENS    = "siglens"
+)
+
+// SigLens chart type => Grafana panel type
+var chartTypeToGrafana = map[string]string{
+	"Line Chart": "timeseries",
+	"Bar Chart":  "barchart",
+	"Pie Chart":  "piechart",
+	"number":     "stat",
+	"Data Table": "table",
+	"loglines":   "logs",
+}
+
+// Grafana panel type => SigLens chart type, including the older panel types
+var grafanaToChartType = map[string]string{
+	"timeseries": "Line Chart",
+	"graph":      "Line Chart",
+	"barchart":   "Bar Chart",
+	"bargauge":   "Bar Chart",
+	"piechart":   "Pie Chart",
+	"stat":       "number",
+	"singlestat": "number",
+	"gauge":      "number",
+	"table":      "Data Table",
+	"table-old":  "Data Table",
+	"logs":       "loglines",
+}
+
+// SigLens time range => Grafana relative time
+var timeRangeToGrafana = map[string]string{
+	"Last 5 Mins":   "now-5m",
+	"Last 15 Mins":  "now-15m",
+	"Last 30 Mins":  "now-30m",
+	"Last 1 Hr":     "now-1h",
+	"Last 3 Hrs":    "now-3h",
+	"Last 6 Hrs":    "now-6h",
+	"Last 12 Hrs":   "now-12h",
+	"Last 24 Hrs":   "now-24h",
+	"Last 2 Days":   "now-2d",
+	"Last 7 Days":   "now-7d",
+	"Last 30 Days":  "now-30d",
+	"Last 90 Days":  "now-90d",
+	"Last 180 Days": "now-180d",
+	"Last 1 Year":   "now-365d",
+}
+
+var refreshIntervals = map[string]struct{}{"5m": {}, "30m": {}, "1h": {}, "5h": {}, "1d": {}}
+
+type dashboardGridPos struct {
+	H        float64 `json:"h"`
+	W        float64 `json:"w"`
+	X        float64 `json:"x"`
+	Y        float64 `json:"y"`
+	WPercent float64 `json:"wPercent"`
+}
+
+type dashboardPanel struct {
+	Name             string                 `json:"name"`
+	PanelIndex       int                    `json:"panelIndex"`
+	PanelId          string                 `json:"panelId"`
+	Description      string                 `json:"description"`
+	ChartType        string                 `json:"chartType"`
+	Unit             string                 `json:"unit"`
+	DataType         string                 `json:"dataType"`
+	GridPos          dashboardGridPos       `json:"gridpos"`
+	QueryType        string                 `json:"queryType"`
+	QueryData        map[string]interface{} `json:"queryData,omitempty"`
+	LogLinesViewType string                 `json:"logLinesViewType,omitempty"`
+}
+
+type dashboardDetails struct {
+	Name        string               `json:"name"`
+	Description string               `json:"description"`
+	TimeRange   string               `json:"timeRange"`
+	Refresh     string               `json:"refresh"`
+	Panels      []*dashboardPanel    `json:"panels"`
+	Variables   []*DashboardVariable `json:"variables,omitempty"`
+}
+
+type grafanaDashboard struct {
+	Uid           string              `json:"uid,omitempty"`
+	Title         string              `json:"title"`
+	Description   string              `json:"description,omitempty"`
+	SchemaVersion int                 `json:"schemaVersion"`
+	Time          *grafanaTimeRange   `json:"time,omitempty"`
+	Refresh       interface{}         `json:"refresh,omitempty"` // an interval, or false when it is off
+	Panels        []*grafanaPanel     `json:"panels"`
+	Templating    grafanaTemplateList `json:"templating"`
+}
+
+type grafanaTimeRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type grafanaGridPos struct {
+	H int `json:"h"`
+	W int `json:"w"`
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type grafanaDatasource struct {
+	Type string `json:"type,omitempty"`
+	Uid  string `json:"uid,omitempty"`
+}
+
+// Older dashboards refer to a datasource by its name
+func (ds *grafanaDatasource) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(`"`)) {
+		return json.Unmarshal(data, &ds.Uid)
+	}
+	type plainDatasource grafanaDatasource
+	return json.Unmarshal(data, (*plainDatasource)(ds))
+}
+
+type grafanaTarget struct {
+	RefId         string             `json:"refId"`
+	Datasource    *grafanaDatasource `json:"datasource,omitempty"`
+	Expr          string             `json:"expr,omitempty"`  // PromQL or LogQL
+	Query         string             `json:"query,omitempty"` // the query of the other datasources
+	QueryLanguage string             `json:"queryLanguage,omitempty"`
+	IndexName     string             `json:"indexName,omitempty"`
+	Hide          bool               `json:"hide,omitempty"`
+}
+
+type grafanaPanel struct {
+	Id          int                `json:"id"`
+	Type        string             `json:"type"`
+	Title       string             `json:"title"`
+	Description string             `json:"description,omitempty"`
+	GridPos     grafanaGridPos     `json:"gridPos"`
+	Datasource  *grafanaDatasource `json:"datasource,omitempty"`
+	Targets     []*grafanaTarget   `json:"targets,omitempty"`
+	Panels      []*grafanaPanel    `json:"panels,omitempty"` // the panels of a collapsed row
+}
+
+type grafanaTemplateList struct {
+	List []*grafanaVariable `json:"list"`
+}
+
+type grafanaVariableOption struct {
+	Text     string `json:"text"`
+	Value    string `json:"value"`
+	Selected bool   `json:"selected"`
+}
+
+type grafanaVariableCurrent struct {
+	Text  interface{} `json:"text,omitempty"`
+	Value interface{} `json:"value"` // a value, or a list of them for multi value variables
+}
+
+type grafanaVariable struct {
+	Name       string                   `json:"name"`
+	Type       string                   `json:"type"`
+	Datasource *grafanaDatasource       `json:"datasource,omitempty"`
+	Query      interface{}              `json:"query"` // newer query variables have an object with the query in it
+	Multi      bool                     `json:"multi"`
+	Current    *grafanaVariableCurrent  `json:"current,omitempty"`
+	Options    []*grafanaVariableOption `json:"options"`
+}
+
+func convertViaJson(from interface{}, to interface{}) error {
+	jdata, err := json.Marshal(from)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(jdata, to)
+}
+
+func getQueryString(queryData map[string]interface{}, key string) string {
+	if val, ok := queryData[key].(string); ok {
+		return val
+	}
+	return ""
+}
+
+func toGrafanaDashboard(id string, details *dashboardDetails) (*grafanaDashboard, []string) {
+	warnings := make([]string, 0)
+	gDashboard := &grafanaDashboard{
+		Uid:           id,
+		Title:         details.Name,
+		Description:   details.Description,
+		SchemaVersion: GRAFANA_SCHEMA_VERSION,
+		Time:          &grafanaTimeRange{From: "now-1h", To: "now"},
+		Refresh:       false,
+		Panels:        make([]*grafanaPanel, 0, len(details.Panels)),
+		Templating:    grafanaTemplateList{List: make([]*grafanaVariable, 0, len(details.Variables))},
+	}
+	if details.TimeRange != "" {
+		if from, ok := timeRangeToGrafana[details.TimeRange]; ok {
+			gDashboard.Time.From = from
+		} else {
+			warnings = append(warnings, fmt.Sprintf("time range %q has no Grafana equivalent, exported as the last hour", details.TimeRange))
+		}
+	}
+	if details.Refresh != "" {
+		gDashboard.Refresh = details.Refresh
+	}
+
+	panels := make([]*dashboardPanel, len(details.Panels))
+	copy(panels, details.Panels)
+	sort.SliceStable(panels, func(i, j int) bool { return panels[i].PanelIndex < panels[j].PanelIndex })
+	for idx, panel := range panels {
+		gPanel, panelWarnings := toGrafanaPanel(panel, idx+1)
+		gDashboard.Panels = append(gDashboard.Panels, gPanel)
+		warnings = append(warnings, panelWarnings...)
+	}
+
+	for _, variable := range details.Variables {
+		gVariable := &grafanaVariable{
+			Name:    variable.Name,
+			Type:    variable.Type,
+			Multi:   variable.Multi,
+			Options: make([]*grafanaVariableOption, 0, len(variable.Options)),
+		}
+		if variable.Type == VARIABLE_TYPE_QUERY {
+			gVariable.Datasource = &grafanaDatasource{Type: GRAFANA_DATASOURCE_SIGLENS}
+			gVariable.Query = variable.Query
+			warnings = append(warnings, fmt.Sprintf("variable %v: the query is exported as a SigLens query", variable.Name))
+		} else {
+			gVariable.Query = strings.Join(variable.Options, ",")
+		}
+		selected := make(map[string]struct{}, len(variable.Current))
+		for _, value := range variable.Current {
+			selected[value] = struct{}{}
+		}
+		for _, option := range variable.Options {
+			_, isSelected := selected[option]
+			gVariable.Options = append(gVariable.Options, &grafanaVariableOption{Text: option, Value: option, Selected: isSelected})
+		}
+		if len(variable.Current) > 0 {
+			if variable.Multi {
+				gVariable.Current = &grafanaVariableCurrent{Text: variable.Current, Value: variable.Current}
+			} else {
+				gVariable.Current = &grafanaVariableCurrent{Text: variable.Current[0], Value: variable.Current[0]}
+			}
+		}
+		gDashboard.Templating.List = append(gDashboard.Templating.List, gVariable)
+	}
+	return gDashboard, warnings
+}
+
+func toGrafanaPanel(panel *dashboardPanel, id int) (*grafanaPanel, []string) {
+	warnings := make([]string, 0)
+	gPanel := &grafanaPanel{
+		Id:          id,
+		Title:       panel.Name,
+		Description: panel.Description,
+		GridPos:     toGrafanaGridPos(panel.GridPos),
+	}
+	panelType, ok := chartTypeToGrafana[panel.ChartType]
+	if !ok {
+		panelType = "table"
+		warnings = append(warnings, fmt.Sprintf("panel %q: chart type %q has no Grafana equivalent, exported as a table", panel.Name, panel.ChartType))
+	}
+	gPanel.Type = panelType
+
+	switch panel.QueryType {
+	case "metrics":
+		gPanel.Datasource = &grafanaDatasource{Type: GRAFANA_DATASOURCE_PROMETHEUS}
+		gPanel.Targets = []*grafanaTarget{{RefId: "A", Datasource: gPanel.Datasource, Expr: getQueryString(panel.QueryData, "query")}}
+	case "logs":
+		queryLanguage := getQueryString(panel.QueryData, "queryLanguage")
+		searchText := getQueryString(panel.QueryData, "searchText")
+		if queryLanguage == "Log QL" {
+			gPanel.Datasource = &grafanaDatasource{Type: GRAFANA_DATASOURCE_LOKI}
+			gPanel.Targets = []*grafanaTarget{{RefId: "A", Datasource: gPanel.Datasource, Expr: searchText}}
+		} else {
+			gPanel.Datasource = &grafanaDatasource{Type: GRAFANA_DATASOURCE_SIGLENS}
+			gPanel.Targets = []*grafanaTarget{{
+				RefId:         "A",
+				Datasource:    gPanel.Datasource,
+				Query:         searchText,
+				QueryLanguage: queryLanguage,
+				IndexName:     getQueryString(panel.QueryData, "indexName"),
+			}}
+			warnings = append(warnings, fmt.Sprintf("panel %q: %v queries have no Grafana equivalent, exported as a SigLens query", panel.Name, queryLanguage))
+		}
+	}
+	return gPanel, warnings
+}
+
+func toGrafanaGridPos(gridPos dashboardGridPos) grafanaGridPos {
+	containerWidth := float64(DEFAULT_PANEL_CONTAINER_WIDTH_PX)
+	if gridPos.WPercent > 0 && gridPos.W > 0 {
+		containerWidth = gridPos.W / gridPos.WPercent
+	}
+	columnWidth := containerWidth / GRAFANA_GRID_COLUMNS
+	gGridPos := grafanaGridPos{
+		H: int(math.Max(1, math.Round(gridPos.H/GRAFANA_ROW_HEIGHT_PX))),
+		W: int(math.Max(1, math.Round(gridPos.W/columnWidth))),
+		X: int(math.Max(0, math.Round(gridPos.X/columnWidth))),
+		Y: int(math.Max(0, math.Round(gridPos.Y/GRAFANA_ROW_HEIGHT_PX))),
+	}
+	if gGridPos.W > GRAFANA_GRID_COLUMNS {
+		gGridPos.W = GRAFANA_GRID_COLUMNS
+	}
+	if gGridPos.X+gGridPos.W > GRAFANA_GRID_COLUMNS {
+		gGridPos.X = GRAFANA_GRID_COLUMNS - gGridPos.W
+	}
+	return gGridPos
+}
+
+func fromGrafanaGridPos(gGridPos grafanaGridPos) dashboardGridPos {
+	columnWidth := float64(DEFAULT_PANEL_CONTAINER_WIDTH_PX) / GRAFANA_GRID_COLUMNS
+	return dashboardGridPos{
+		H:        float64(gGridPos.H * GRAFANA_ROW_HEIGHT_PX),
+		W:        float64(gGridPos.W) * columnWidth,
+		X:        float64(gGridPos.X) * columnWidth,
+		Y:        float64(gGridPos.Y * GRAFANA_ROW_HEIGHT_PX),
+		WPercent: float64(gGridPos.W) / GRAFANA_GRID_COLUMNS,
+	}
+}
+
+func fromGrafanaDashboard(gDashboard *grafanaDashboard) (*dashboardDetails, []string) {
+	warnings := make([]string, 0)
+	details := &dashboardDetails{
+		Name:        gDashboard.Title,
+		Description: gDashboard.Description,
+		TimeRange:   DEFAULT_TIME_RANGE,
+		Panels:      make([]*dashboardPanel, 0, len(gDashboard.Panels)),
+		Variables:   make([]*DashboardVariable, 0),
+	}
+	startEpoch, endEpoch := "now-1h", "now"
+	if gDashboard.Time != nil {
+		timeRange := ""
+		for label, from := range timeRangeToGrafana {
+			if from == gDashboard.Time.From {
+				timeRange = label
+				break
+			}
+		}
+		if timeRange == "" || gDashboard.Time.To != "now" {
+			warnings = append(warnings, fmt.Sprintf("time range %v to %v is not supported, imported as the last hour",
+				gDashboard.Time.From, gDashboard.Time.To))
+		} else {
+			details.TimeRange = timeRange
+			startEpoch = gDashboard.Time.From
+		}
+	}
+	if refresh, ok := gDashboard.Refresh.(string); ok && refresh != "" {
+		if _, supported := refreshIntervals[refresh]; supported {
+			details.Refresh = refresh
+		} else {
+			warnings = append(warnings, fmt.Sprintf("refresh interval %v is not supported, imported without a refresh", refresh))
+		}
+	}
+
+	for _, gPanel := range flattenGrafanaPanels(gDashboard.Panels) {
+		chartType, ok := grafanaToChartType[gPanel.Type]
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("panel %q: panel type %v is not supported, skipped", gPanel.Title, gPanel.Type))
+			continue
+		}
+		panel := &dashboardPanel{
+			Name:        gPanel.Title,
+			PanelIndex:  len(details.Panels),
+			PanelId:     uuid.New().String(),
+			Description: gPanel.Description,
+			ChartType:   chartType,
+			GridPos:     fromGrafanaGridPos(gPanel.GridPos),
+		}
+		if chartType == "Data Table" {
+			panel.LogLinesViewType = "Table view"
+		} else if chartType == "loglines" {
+			panel.LogLinesViewType = "Single line display view"
+		}
+		if panel.Name == "" {
+			panel.Name = fmt.Sprintf("panel%v", panel.PanelIndex)
+		}
+		warnings = append(warnings, setPanelQuery(panel, gPanel, startEpoch, endEpoch)...)
+		details.Panels = append(details.Panels, panel)
+	}
+
+	for _, gVariable := range gDashboard.Templating.List {
+		variable, warning := fromGrafanaVariable(gVariable)
+		if warning != "" {
+			warnings = append(warnings, warning)
+		}
+		if variable != nil {
+			details.Variables = append(details.Variables, variable)
+		}
+	}
+	return details, warnings
+}
+
+// Rows are not panels in SigLens, the panels of the collapsed rows are moved to the dashboard
+func flattenGrafanaPanels(gPanels []*grafanaPanel) []*grafanaPanel {
+	flattened := make([]*grafanaPanel, 0, len(gPanels))
+	for _, gPanel := range gPanels {
+		if gPanel.Type == "row" {
+			flattened = append(flattened, flattenGrafanaPanels(gPanel.Panels)...)
+			continue
+		}
+		flattened = append(flattened, gPanel)
+	}
+	return flattened
+}
+
+// Translates the first query of the Grafana panel, returns the warnings
+func setPanelQuery(panel *dashboardPanel, gPanel *grafanaPanel, startEpoch string, endEpoch string) []string {
+	warnings := make([]string, 0)
+	targets := make([]*grafanaTarget, 0, len(gPanel.Targets))
+	for _, target := range gPanel.Targets {
+		if !target.Hide {
+			targets = append(targets, target)
+		}
+	}
+	if len(targets) == 0 {
+		return append(warnings, fmt.Sprintf("panel %q: has no query", panel.Name))
+	}
+	if len(targets) > 1 {
+		warnings = append(warnings, fmt.Sprintf("panel %q: only the first of its %v queries is imported", panel.Name, len(targets)))
+	}
+	target := targets[0]
+	datasource := target.Datasource
+	if datasource == nil || datasource.Type == "" {
+		datasource = gPanel.Datasource
+	}
+	datasourceType := ""
+	if datasource != nil {
+		datasourceType = datasource.Type
+	}
+
+	switch {
+	case datasourceType == GRAFANA_DATASOURCE_PROMETHEUS,
+		datasourceType == "" && target.Expr != "":
+		if datasourceType == "" {
+			warnings = append(warnings, fmt.Sprintf("panel %q: the datasource is unknown, the query is imported as a metrics query", panel.Name))
+		}
+		panel.QueryType = "metrics"
+		panel.QueryData = map[string]interface{}{"query": target.Expr, "start": startEpoch, "end": endEpoch}
+	case datasourceType == GRAFANA_DATASOURCE_LOKI:
+		setLogsPanelQuery(panel, target.Expr, "Log QL", "*", startEpoch, endEpoch)
+	case datasourceType == GRAFANA_DATASOURCE_SIGLENS:
+		queryLanguage := target.QueryLanguage
+		if queryLanguage == "" {
+			queryLanguage = "Splunk QL"
+		}
+		indexName := target.IndexName
+		if indexName == "" {
+			indexName = "*"
+		}
+		setLogsPanelQuery(panel, target.Query, queryLanguage, indexName, startEpoch, endEpoch)
+	default:
+		warnings = append(warnings, fmt.Sprintf("panel %q: queries of the %v datasource are not supported, imported without a query",
+			panel.Name, datasourceType))
+	}
+	return warnings
+}
+
+func setLogsPanelQuery(panel *dashboardPanel, searchText string, queryLanguage string, indexName string, startEpoch string, endEpoch string) {
+	panel.QueryType = "logs"
+	panel.QueryData = map[string]interface{}{
+		"searchText":    searchText,
+		"queryLanguage": queryLanguage,
+		"indexName":     indexName,
+		"startEpoch":    startEpoch,
+		"endEpoch":      endEpoch,
+		"from":          0,
+		"state":         "query",
+	}
+}
+
+// Returns nil and a warning for the variables that can not be imported
+func fromGrafanaVariable(gVariable *grafanaVariable) (*DashboardVariable, string) {
+	if !variableNameRegex.MatchString(gVariable.Name) {
+		return nil, fmt.Sprintf("variable %q: the name is not supported, skipped", gVariable.Name)
+	}
+	variable := &DashboardVariable{
+		Name:    gVariable.Name,
+		Multi:   gVariable.Multi,
+		Options: getGrafanaVariableOptions(gVariable),
+		Current: getGrafanaVariableCurrent(gVariable),
+	}
+	warning := ""
+	switch gVariable.Type {
+	case VARIABLE_TYPE_QUERY:
+		query, _ := gVariable.Query.(string)
+		if queryObj, ok := gVariable.Query.(map[string]interface{}); ok {
+			query, _ = queryObj["query"].(string)
+		}
+		if gVariable.Datasource != nil && gVariable.Datasource.Type == GRAFANA_DATASOURCE_SIGLENS && query != "" {
+			variable.Type = VARIABLE_TYPE_QUERY
+			variable.Query = query
+			variable.Options = nil
+			break
+		}
+		if len(variable.Options) == 0 {
+			return nil, fmt.Sprintf("variable %v: the query %q can not be translated, skipped", gVariable.Name, query)
+		}
+		variable.Type = VARIABLE_TYPE_CUSTOM
+		warning = fmt.Sprintf("variable %v: the query %q can not be translated, imported with its current options", gVariable.Name, query)
+	case VARIABLE_TYPE_CUSTOM, "constant":
+		variable.Type = VARIABLE_TYPE_CUSTOM
+	case VARIABLE_TYPE_INTERVAL:
+		variable.Type = VARIABLE_TYPE_INTERVAL
+		intervals := make([]string, 0, len(variable.Options))
+		for _, option := range variable.Options {
+			if intervalRegex.MatchString(option) {
+				intervals = append(intervals, option)
+			}
+		}
+		variable.Options = intervals
+	default:
+		return nil, fmt.Sprintf("variable %v: variables of type %v are not supported, skipped", gVariable.Name, gVariable.Type)
+	}
+	if variable.Type != VARIABLE_TYPE_QUERY && len(variable.Options) == 0 {
+		return nil, fmt.Sprintf("variable %v: has no options, skipped", gVariable.Name)
+	}
+	if !variable.Multi && len(variable.Current) > 1 {
+		variable.Current = variable.Current[:1]
+	}
+	return variable, warning
+}
+
+// The options of custom, constant and interval variables are also in their comma separated query
+func getGrafanaVariableOptions(gVariable *grafanaVariable) []string {
+	options := make([]string, 0, len(gVariable.Options))
+	seen := make(map[string]struct{})
+	addOption := func(option string) {
+		option = strings.TrimSpace(option)
+		if _, ok := seen[option]; ok || option == "" || option == "$__all" || option == "$__auto_interval" {
+			return
+		}
+		seen[option] = struct{}{}
+		options = append(options, option)
+	}
+	for _, option := range gVariable.Options {
+		addOption(option.Value)
+	}
+	if query, ok := gVariable.Query.(string); ok && gVariable.Type != VARIABLE_TYPE_QUERY {
+		for _, option := range strings.Split(query, ",") {
+			addOption(option)
+		}
+	}
+	return options
+}
+
+func getGrafanaVariableCurrent(gVariable *grafanaVariable) []string {
+	current := make([]string, 0)
+	if gVariable.Current == nil {
+		return current
+	}
+	values := []interface{}{gVariable.Current.Value}
+	if list, ok := gVariable.Current.Value.([]interface{}); ok {
+		values = list
+	}
+	for _, value := range values {
+		if strVal, ok := value.(string); ok && strVal != "" && strVal != "$__all" && strVal != "$__auto_interval" {
+			current = append(current, strVal)
+		}
+	}
+	return current
+}
+
+// Handles /api/dashboards/{dashboard-id}/grafana
+func ProcessExportGrafanaDashboardRequest(ctx *fasthttp.RequestCtx) {
+	dId := utils.ExtractParamAsString(ctx.UserValue("dashboard-id"))
+	rawDetails, err := getDashboard(dId)
+	if err != nil {
+		log.Errorf("ProcessExportGrafanaDashboardRequest: could not get Dashboard=%v, err=%v", dId, err)
+		setBadMsg(ctx)
+		return
+	}
+	details := &dashboardDetails{}
+	err = convertViaJson(rawDetails, details)
+	if err != nil {
+		log.Errorf("ProcessExportGrafanaDashboardRequest: could not read Dashboard=%v, err=%v", dId, err)
+		setBadMsg(ctx)
+		return
+	}
+	if details.Name == "" {
+		allDashboardsIdsLock.RLock()
+		for _, orgDashboards := range allDashboardsIds {
+			if name, ok := orgDashboards[dId]; ok {
+				details.Name = name
+			}
+		}
+		allDashboardsIdsLock.RUnlock()
+	}
+
+	gDashboard, warnings := toGrafanaDashboard(dId, details)
+	response := map[string]interface{}{
+		"dashboard": gDashboard,
+		"warnings":  warnings,
+	}
+	utils.WriteJsonResponse(ctx, response)
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
+
+// Handles /api/dashboards/import/grafana, the body is a Grafana dashboard, bare or as {"dashboard": {...}}
+func ProcessImportGrafanaDashboardRequest(ctx *fasthttp.RequestCtx, myid uint64) {
+	rawJSON := ctx.PostBody()
+	if len(rawJSON) == 0 {
+		log.Errorf("ProcessImportGrafanaDashboardRequest: received empty request")
+		setBadMsg(ctx)
+		return
+	}
+	wrapper := struct {
+		Dashboard *grafanaDashboard `json:"dashboard"`
+	}{}
+	err := json.Unmarshal(rawJSON, &wrapper)
+	if err != nil {
+		log.Errorf("ProcessImportGrafanaDashboardRequest: could not unmarshal the dashboard, err=%v", err)
+		setBadMsg(ctx)
+		return
+	}
+	gDashboard := wrapper.Dashboard
+	if gDashboard == nil {
+		gDashboard = &grafanaDashboard{}
+		err = json.Unmarshal(rawJSON, gDashboard)
+		if err != nil {
+			log.Errorf("ProcessImportGrafanaDashboardRequest: could not unmarshal the dashboard, err=%v", err)
+			setBadMsg(ctx)
+			return
+		}
+	}
+
+	details, warnings := fromGrafanaDashboard(gDashboard)
+	detailsMap := make(map[string]interface{})
+	err = convertViaJson(details, &detailsMap)
+	if err != nil {
+		log.Errorf("ProcessImportGrafanaDashboardRequest: could not convert the dashboard, err=%v", err)
+		setBadMsg(ctx)
+		return
+	}
+	err = validateDashboardVariables(detailsMap)
+	if err != nil {
+		log.Errorf("ProcessImportGrafanaDashboardRequest: invalid variables, err=%v", err)
+		setBadMsg(ctx)
+		return
+	}
+
+	dashboardInfo, err := createDashboard(details.Name, myid)
+	if err != nil {
+		if err.Error() == "dashboard name already exists" {
+			setConflictMsg(ctx)
+			return
+		}
+		log.Errorf("ProcessImportGrafanaDashboardRequest: could not create Dashboard=%v, err=%v", details.Name, err)
+		setBadMsg(ctx)
+		return
+	}
+	dId := ""
+	for id := range dashboardInfo {
+		dId = id
+	}
+	err = updateDashboard(dId, details.Name, detailsMap, myid)
+	if err != nil {
+		log.Errorf("ProcessImportGrafanaDashboardRequest: could not save Dashboard=%v, err=%v", details.Name, err)
+		setBadMsg(ctx)
+		return
+	}
+
+	response := map[string]interface{}{
+		"id":       dId,
+		"name":     details.Name,
+		"warnings": warnings,
+	}
+	utils.WriteJsonResponse(ctx, response)
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dashboards
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_toGrafanaDashboard(t *testing.T) {
+	details := &dashboardDetails{
+		Name:      "services",
+		TimeRange: "Last 24 Hrs",
+		Refresh:   "5m",
+		Panels: []*dashboardPanel{
+			{Name: "errors", PanelIndex: 1, ChartType: "Line Chart", QueryType: "metrics",
+				GridPos:   dashboardGridPos{H: 240, W: 653, X: 660, Y: 20, WPercent: 0.5},
+				QueryData: map[string]interface{}{"query": "rate(errors[5m])", "start": "now-1h", "end": "now"}},
+			{Name: "logs", PanelIndex: 0, ChartType: "Data Table", QueryType: "logs",
+				GridPos:   dashboardGridPos{H: 250, W: 1306, X: 10, Y: 300, WPercent: 1},
+				QueryData: map[string]interface{}{"searchText": "level=error", "queryLanguage": "Splunk QL", "indexName": "app"}},
+			{Name: "loki", PanelIndex: 2, ChartType: "loglines", QueryType: "logs",
+				QueryData: map[string]interface{}{"searchText": `{app="web"}`, "queryLanguage": "Log QL"}},
+		},
+		Variables: []*DashboardVariable{
+			{Name: "env", Type: VARIABLE_TYPE_CUSTOM, Options: []string{"prod", "dev"}, Current: []string{"prod"}},
+		},
+	}
+	gDashboard, warnings := toGrafanaDashboard("abc", details)
+	assert.Equal(t, "abc", gDashboard.Uid)
+	assert.Equal(t, "services", gDashboard.Title)
+	assert.Equal(t, &grafanaTimeRange{From: "now-24h", To: "now"}, gDashboard.Time)
+	assert.Equal(t, "5m", gDashboard.Refresh)
+	assert.Len(t, gDashboard.Panels, 3)
+
+	logsPanel := gDashboard.Panels[0]
+	assert.Equal(t, 1, logsPanel.Id)
+	assert.Equal(t, "table", logsPanel.Type)
+	assert.Equal(t, grafanaGridPos{H: 8, W: 24, X: 0, Y: 10}, logsPanel.GridPos)
+	assert.Equal(t, GRAFANA_DATASOURCE_SIGLENS, logsPanel.Datasource.Type)
+	assert.Equal(t, "level=error", logsPanel.Targets[0].Query)
+	assert.Equal(t, "app", logsPanel.Targets[0].IndexName)
+
+	metricsPanel := gDashboard.Panels[1]
+	assert.Equal(t, "timeseries", metricsPanel.Type)
+	assert.Equal(t, grafanaGridPos{H: 8, W: 12, X: 12, Y: 1}, metricsPanel.GridPos)
+	assert.Equal(t, GRAFANA_DATASOURCE_PROMETHEUS, metricsPanel.Datasource.Type)
+	assert.Equal(t, "rate(errors[5m])", metricsPanel.Targets[0].Expr)
+
+	lokiPanel := gDashboard.Panels[2]
+	assert.Equal(t, "logs", lokiPanel.Type)
+	assert.Equal(t, GRAFANA_DATASOURCE_LOKI, lokiPanel.Datasource.Type)
+	assert.Equal(t, `{app="web"}`, lokiPanel.Targets[0].Expr)
+
+	assert.Len(t, gDashboard.Templating.List, 1)
+	assert.Equal(t, "prod,dev", gDashboard.Templating.List[0].Query)
+	assert.Equal(t, "prod", gDashboard.Templating.List[0].Current.Value)
+	assert.True(t, gDashboard.Templating.List[0].Options[0].Selected)
+
+	// only the Splunk QL query has no Grafana equivalent
+	assert.Len(t, warnings, 1)
+}
+
+func Test_fromGrafanaDashboard(t *testing.T) {
+	rawDashboard := `{
+		"title": "imported",
+		"time": {"from": "now-6h", "to": "now"},
+		"refresh": "10s",
+		"panels": [
+			{"id": 1, "type": "graph", "title": "cpu", "gridPos": {"h": 8, "w": 12, "x": 12, "y": 0},
+				"datasource": "Prometheus",
+				"targets": [{"refId": "A", "expr": "avg(cpu)"}, {"refId": "B", "expr": "max(cpu)"}]},
+			{"id": 2, "type": "row", "title": "logs", "collapsed": true, "panels": [
+				{"id": 3, "type": "logs", "title": "web logs", "gridPos": {"h": 10, "w": 24, "x": 0, "y": 9},
+					"datasource": {"type": "loki", "uid": "l1"}, "targets": [{"refId": "A", "expr": "{app=\"web\"}"}]}
+			]},
+			{"id": 4, "type": "heatmap", "title": "latency"},
+			{"id": 5, "type": "stat", "title": "es", "datasource": {"type": "elasticsearch"}, "targets": [{"refId": "A", "query": "*"}]}
+		],
+		"templating": {"list": [
+			{"name": "env", "type": "custom", "query": "prod, dev", "multi": true, "current": {"value": ["prod", "dev"]}},
+			{"name": "interval", "type": "interval", "query": "1m,auto,10m", "current": {"value": "1m"}},
+			{"name": "host", "type": "query", "query": {"query": "label_values(host)"},
+				"options": [{"text": "a", "value": "a"}, {"text": "b", "value": "b"}]},
+			{"name": "empty", "type": "query", "query": "label_values(x)"},
+			{"name": "ds", "type": "datasource", "query": "prometheus"}
+		]}
+	}`
+	gDashboard := &grafanaDashboard{}
+	assert.Nil(t, json.Unmarshal([]byte(rawDashboard), gDashboard))
+
+	details, warnings := fromGrafanaDashboard(gDashboard)
+	assert.Equal(t, "imported", details.Name)
+	assert.Equal(t, "Last 6 Hrs", details.TimeRange)
+	assert.Equal(t, "", details.Refresh)
+	assert.Len(t, details.Panels, 3)
+
+	cpuPanel := details.Panels[0]
+	assert.Equal(t, "Line Chart", cpuPanel.ChartType)
+	assert.Equal(t, "metrics", cpuPanel.QueryType)
+	assert.Equal(t, "avg(cpu)", cpuPanel.QueryData["query"])
+	assert.Equal(t, "now-6h", cpuPanel.QueryData["start"])
+	assert.Equal(t, 0.5, cpuPanel.GridPos.WPercent)
+	assert.Equal(t, float64(240), cpuPanel.GridPos.H)
+	assert.InDelta(t, float64(DEFAULT_PANEL_CONTAINER_WIDTH_PX)/2, cpuPanel.GridPos.X, 0.001)
+
+	logsPanel := details.Panels[1]
+	assert.Equal(t, "loglines", logsPanel.ChartType)
+	assert.Equal(t, "logs", logsPanel.QueryType)
+	assert.Equal(t, "Log QL", logsPanel.QueryData["queryLanguage"])
+	assert.Equal(t, `{app="web"}`, logsPanel.QueryData["searchText"])
+	assert.Equal(t, 1, logsPanel.PanelIndex)
+
+	esPanel := details.Panels[2]
+	assert.Equal(t, "number", esPanel.ChartType)
+	assert.Equal(t, "", esPanel.QueryType)
+
+	assert.Equal(t, []*DashboardVariable{
+		{Name: "env", Type: VARIABLE_TYPE_CUSTOM, Options: []string{"prod", "dev"}, Multi: true, Current: []string{"prod", "dev"}},
+		{Name: "interval", Type: VARIABLE_TYPE_INTERVAL, Options: []string{"1m", "10m"}, Current: []string{"1m"}},
+		{Name: "host", Type: VARIABLE_TYPE_CUSTOM, Options: []string{"a", "b"}, Current: []string{}},
+	}, details.Variables)
+
+	// refresh, the second cpu query, the unknown datasource, the heatmap, the elasticsearch query and the host,
+	// empty and ds variables
+	assert.Len(t, warnings, 8)
+
+	detailsMap := make(map[string]interface{})
+	assert.Nil(t, convertViaJson(details, &detailsMap))
+	assert.Nil(t, validateDashboardVariables(detailsMap))
+}
+
+func Test_grafanaRoundTrip(t *testing.T) {
+	details := &dashboardDetails{
+		Name:      "round trip",
+		TimeRange: "Last 7 Days",
+		Panels: []*dashboardPanel{
+			{Name: "count", ChartType: "Bar Chart", QueryType: "logs",
+				GridPos:   dashboardGridPos{H: 300, W: 656.5, X: 0, Y: 0, WPercent: 0.5},
+				QueryData: map[string]interface{}{"searchText": "* | stats count BY service", "queryLanguage": "Splunk QL", "indexName": "*"}},
+		},
+		Variables: []*DashboardVariable{
+			{Name: "service", Type: VARIABLE_TYPE_QUERY, Query: "* | stats count BY service", Multi: true},
+		},
+	}
+	gDashboard, _ := toGrafanaDashboard("id", details)
+	jdata, err := json.Marshal(gDashboard)
+	assert.Nil(t, err)
+	imported := &grafanaDashboard{}
+	assert.Nil(t, json.Unmarshal(jdata, imported))
+
+	roundTrip, warnings := fromGrafanaDashboard(imported)
+	assert.Empty(t, warnings)
+	assert.Equal(t, details.TimeRange, roundTrip.TimeRange)
+	assert.Equal(t, "Bar Chart", roundTrip.Panels[0].ChartType)
+	assert.Equal(t, details.Panels[0].QueryData["searchText"], roundTrip.Panels[0].QueryData["searchText"])
+	assert.Equal(t, details.Panels[0].GridPos, roundTrip.Panels[0].GridPos)
+	assert.Equal(t, details.Variables[0].Query, roundTrip.Variables[0].Query)
+	assert.Equal(t, VARIABLE_TYPE_QUERY, roundTrip.Variables[0].Type)
+}
@@ -0,0 +1,235 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dashboards
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/siglens/siglens/pkg/apikeys"
+	"github.com/siglens/siglens/pkg/ast/pipesearch"
+	"github.com/siglens/siglens/pkg/segment/structs"
+	"github.com/siglens/siglens/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+/*
+Template variables are defined in the "variables" list of the dashboard details. The panels send the selected values
+with their queries, which replaces the $name tokens of the queries, see pipesearch.SubstituteDashboardVariables
+*/
+
+const (
+	VARIABLE_TYPE_QUERY    = "query"    // the options are the groups of a group by query, e.g. "* | stats count BY service"
+	VARIABLE_TYPE_CUSTOM   = "custom"   // a static list of options
+	VARIABLE_TYPE_INTERVAL = "interval" // a list of time spans, e.g. 1m, 5m, 1h
+)
+
+const MAX_VARIABLE_OPTIONS = 1000
+
+var variableNameRegex = regexp.MustCompile(`^[A-Za-z]\w*$`)
+var intervalRegex = regexp.MustCompile(`^\d+[smhd]$`)
+
+type DashboardVariable struct {
+	Name          string   `json:"name"`
+	Type          string   `json:"type"`
+	Query         string   `json:"query,omitempty"`
+	QueryLanguage string   `json:"queryLanguage,omitempty"`
+	IndexName     string   `json:"indexName,omitempty"`
+	Options       []string `json:"options,omitempty"`
+	Multi         bool     `json:"multi"`
+	Current       []string `json:"current,omitempty"` // selected values
+}
+
+func getDashboardVariables(dashboardDetails map[string]interface{}) ([]*DashboardVariable, error) {
+	rawVariables, ok := dashboardDetails["variables"]
+	if !ok || rawVariables == nil {
+		return []*DashboardVariable{}, nil
+	}
+	jdata, err := json.Marshal(rawVariables)
+	if err != nil {
+		return nil, fmt.Errorf("getDashboardVariables: could not marshal variables, err=%v", err)
+	}
+	variables := make([]*DashboardVariable, 0)
+	err = json.Unmarshal(jdata, &variables)
+	if err != nil {
+		return nil, fmt.Errorf("getDashboardVariables: invalid variables, err=%v", err)
+	}
+	return variables, nil
+}
+
+func validateDashboardVariables(dashboardDetails map[string]interface{}) error {
+	variables, err := getDashboardVariables(dashboardDetails)
+	if err != nil {
+		return err
+	}
+	names := make(map[string]struct{}, len(variables))
+	for _, variable := range variables {
+		if !variableNameRegex.MatchString(variable.Name) {
+			return fmt.Errorf("invalid variable name %q", variable.Name)
+		}
+		if _, exists := names[variable.Name]; exists {
+			return fmt.Errorf("variable %v is defined more than once", variable.Name)
+		}
+		names[variable.Name] = struct{}{}
+
+		switch variable.Type {
+		case VARIABLE_TYPE_QUERY:
+			if variable.Query == "" {
+				return fmt.Errorf("query variable %v has no query", variable.Name)
+			}
+		case VARIABLE_TYPE_CUSTOM:
+			if len(variable.Options) == 0 {
+				return fmt.Errorf("custom variable %v has no options", variable.Name)
+			}
+		case VARIABLE_TYPE_INTERVAL:
+			if len(variable.Options) == 0 {
+				return fmt.Errorf("interval variable %v has no options", variable.Name)
+			}
+			for _, option := range variable.Options {
+				if !intervalRegex.MatchString(option) {
+					return fmt.Errorf("interval variable %v has an invalid interval %v", variable.Name, option)
+				}
+			}
+		default:
+			return fmt.Errorf("variable %v has an unknown type %q", variable.Name, variable.Type)
+		}
+		if !variable.Multi && len(variable.Current) > 1 {
+			return fmt.Errorf("variable %v has more than one value selected but is not multi value", variable.Name)
+		}
+	}
+	return nil
+}
+
+// Handles /api/dashboards/{dashboard-id}/variables/{variable-name}/options, startEpoch and endEpoch are optional
+func ProcessGetVariableOptionsRequest(ctx *fasthttp.RequestCtx, myid uint64) {
+	dId := utils.ExtractParamAsString(ctx.UserValue("dashboard-id"))
+	varName := utils.ExtractParamAsString(ctx.UserValue("variable-name"))
+	dashboardDetails, err := getDashboard(dId)
+	if err != nil {
+		log.Errorf("ProcessGetVariableOptionsRequest: could not get Dashboard=%v, err=%v", dId, err)
+		setBadMsg(ctx)
+		return
+	}
+	variables, err := getDashboardVariables(dashboardDetails)
+	if err != nil {
+		log.Errorf("ProcessGetVariableOptionsRequest: Dashboard=%v, err=%v", dId, err)
+		setBadMsg(ctx)
+		return
+	}
+	var variable *DashboardVariable
+	for _, v := range variables {
+		if v.Name == varName {
+			variable = v
+			break
+		}
+	}
+	if variable == nil {
+		log.Errorf("ProcessGetVariableOptionsRequest: Dashboard=%v has no variable %v", dId, varName)
+		setBadMsg(ctx)
+		return
+	}
+
+	options := variable.Options
+	if variable.Type == VARIABLE_TYPE_QUERY {
+		startEpoch := string(ctx.QueryArgs().Peek("startEpoch"))
+		endEpoch := string(ctx.QueryArgs().Peek("endEpoch"))
+		options, err = getQueryVariableOptions(variable, startEpoch, endEpoch, apikeys.GetRequestApiKey(ctx), myid)
+		if err != nil {
+			log.Errorf("ProcessGetVariableOptionsRequest: could not get the options of variable %v, err=%v", varName, err)
+			setBadMsg(ctx)
+			return
+		}
+	}
+	utils.WriteJsonResponse(ctx, map[string]interface{}{"options": options})
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
+
+// Runs the query of a query variable and returns its distinct group keys, sorted. The query can only search the
+// indices apiKey can read, when it is not nil
+func getQueryVariableOptions(variable *DashboardVariable, startEpoch string, endEpoch string, apiKey *apikeys.ApiKey,
+	myid uint64) ([]string, error) {
+	requestBody := map[string]interface{}{
+		"searchText":    variable.Query,
+		"queryLanguage": variable.QueryLanguage,
+		"indexName":     variable.IndexName,
+		"startEpoch":    startEpoch,
+		"endEpoch":      endEpoch,
+	}
+	if variable.QueryLanguage == "" {
+		requestBody["queryLanguage"] = "Splunk QL"
+	}
+	if variable.IndexName == "" {
+		requestBody["indexName"] = "*"
+	}
+	if startEpoch == "" {
+		requestBody["startEpoch"] = "now-1h"
+	}
+	if endEpoch == "" {
+		requestBody["endEpoch"] = "now"
+	}
+	requestBodyJSON, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod("POST")
+	ctx.Request.SetBody(requestBodyJSON)
+	if apiKey != nil {
+		ctx.SetUserValue(apikeys.API_KEY_CTX_KEY, apiKey)
+	}
+	pipesearch.ProcessPipeSearchRequest(ctx, myid)
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		return nil, fmt.Errorf("getQueryVariableOptions: query failed, response=%v", string(ctx.Response.Body()))
+	}
+
+	resp := pipesearch.PipeSearchResponseOuter{}
+	err = json.Unmarshal(ctx.Response.Body(), &resp)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Errors) > 0 {
+		return nil, errors.New(resp.Errors[0])
+	}
+	return getGroupKeys(resp.MeasureResults), nil
+}
+
+func getGroupKeys(measureResults []*structs.BucketHolder) []string {
+	seen := make(map[string]struct{})
+	options := make([]string, 0)
+	for _, bucket := range measureResults {
+		if bucket == nil || len(bucket.GroupByValues) == 0 {
+			continue
+		}
+		key := bucket.GroupByValues[0]
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		options = append(options, key)
+		if len(options) >= MAX_VARIABLE_OPTIONS {
+			break
+		}
+	}
+	sort.Strings(options)
+	return options
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dashboards
+
+import (
+	"testing"
+
+	"github.com/siglens/siglens/pkg/segment/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_validateDashboardVariables(t *testing.T) {
+	valid := map[string]interface{}{
+		"variables": []interface{}{
+			map[string]interface{}{"name": "service", "type": "query", "query": "* | stats count BY service", "multi": true,
+				"current": []interface{}{"frontend", "backend"}},
+			map[string]interface{}{"name": "env", "type": "custom", "options": []interface{}{"prod", "dev"}, "current": []interface{}{"prod"}},
+			map[string]interface{}{"name": "span", "type": "interval", "options": []interface{}{"1m", "5m", "1h"}},
+		},
+	}
+	assert.Nil(t, validateDashboardVariables(valid))
+	assert.Nil(t, validateDashboardVariables(map[string]interface{}{}))
+
+	invalid := []map[string]interface{}{
+		{"name": "__interval", "type": "custom", "options": []interface{}{"a"}},
+		{"name": "bad name", "type": "custom", "options": []interface{}{"a"}},
+		{"name": "x", "type": "unknown"},
+		{"name": "x", "type": "query"},
+		{"name": "x", "type": "custom"},
+		{"name": "x", "type": "interval", "options": []interface{}{"5 minutes"}},
+		{"name": "x", "type": "custom", "options": []interface{}{"a", "b"}, "current": []interface{}{"a", "b"}},
+	}
+	for _, variable := range invalid {
+		details := map[string]interface{}{"variables": []interface{}{variable}}
+		assert.NotNil(t, validateDashboardVariables(details), variable)
+	}
+
+	duplicate := map[string]interface{}{"name": "x", "type": "custom", "options": []interface{}{"a"}}
+	assert.NotNil(t, validateDashboardVariables(map[string]interface{}{"variables": []interface{}{duplicate, duplicate}}))
+	assert.NotNil(t, validateDashboardVariables(map[string]interface{}{"variables": "x"}))
+}
+
+func Test_getGroupKeys(t *testing.T) {
+	measureResults := []*structs.BucketHolder{
+		{GroupByValues: []string{"frontend", "GET"}},
+		{GroupByValues: []string{"backend", "GET"}},
+		{GroupByValues: []string{"frontend", "POST"}},
+		{GroupByValues: []string{}},
+	}
+	assert.Equal(t, []string{"backend", "frontend"}, getGroupKeys(measureResults))
+	assert.Equal(t, []string{}, getGroupKeys(nil))
+}
@@ -0,0 +1,224 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package embeddings
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+// number of random hyperplanes used to hash vectors into buckets
+const LSH_NUM_PLANES = 12
+
+// below this many entries every vector is compared, so small indexes are exact
+const LSH_EXACT_SEARCH_LIMIT = 2048
+
+const LSH_RANDOM_SEED = 7919
+
+type indexEntry struct {
+	key    string
+	vector []float32 // normalized to unit length
+	bucket uint32
+}
+
+type SearchResult struct {
+	Key   string
+	Score float64 // cosine similarity
+}
+
+// AnnIndex is an in-memory approximate nearest neighbor index over normalized vectors.
+// Vectors are bucketed by random hyperplane LSH and searches probe the query's bucket and its
+// neighbors at hamming distance one. When full, the oldest entries are evicted first.
+type AnnIndex struct {
+	lock       sync.RWMutex
+	maxEntries int
+	dimensions int
+	planes     [][]float32
+	entries    map[string]*indexEntry
+	buckets    map[uint32]map[string]*indexEntry
+	insertList []string
+}
+
+func NewAnnIndex(maxEntries int) *AnnIndex {
+	return &AnnIndex{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*indexEntry),
+		buckets:    make(map[uint32]map[string]*indexEntry),
+		insertList: make([]string, 0),
+	}
+}
+
+func (ai *AnnIndex) Len() int {
+	ai.lock.RLock()
+	defer ai.lock.RUnlock()
+	return len(ai.entries)
+}
+
+// Returns the normalized vector stored for key.
+func (ai *AnnIndex) Get(key string) ([]float32, bool) {
+	ai.lock.RLock()
+	defer ai.lock.RUnlock()
+	entry, ok := ai.entries[key]
+	if !ok {
+		return nil, false
+	}
+	return entry.vector, true
+}
+
+// Adds or replaces the vector for key. Vectors with a different dimension than the first added vector are ignored.
+func (ai *AnnIndex) Add(key string, vector []float32) bool {
+	normalized := normalize(vector)
+	if normalized == nil {
+		return false
+	}
+
+	ai.lock.Lock()
+	defer ai.lock.Unlock()
+
+	if ai.planes == nil {
+		ai.initPlanes(len(normalized))
+	}
+	if len(normalized) != ai.dimensions {
+		return false
+	}
+
+	if old, ok := ai.entries[key]; ok {
+		delete(ai.buckets[old.bucket], key)
+	} else {
+		ai.insertList = append(ai.insertList, key)
+	}
+
+	entry := &indexEntry{key: key, vector: normalized, bucket: ai.getBucket(normalized)}
+	ai.entries[key] = entry
+	bucket, ok := ai.buckets[entry.bucket]
+	if !ok {
+		bucket = make(map[string]*indexEntry)
+		ai.buckets[entry.bucket] = bucket
+	}
+	bucket[key] = entry
+
+	ai.evictOldest()
+	return true
+}
+
+// Returns up to k entries with a cosine similarity of at least minScore to vector, most similar first.
+func (ai *AnnIndex) Search(vector []float32, k int, minScore float64) []SearchResult {
+	normalized := normalize(vector)
+
+	ai.lock.RLock()
+	defer ai.lock.RUnlock()
+
+	results := make([]SearchResult, 0)
+	if normalized == nil || len(normalized) != ai.dimensions {
+		return results
+	}
+
+	addIfClose := func(entry *indexEntry) {
+		score := dotProduct(normalized, entry.vector)
+		if score >= minScore {
+			results = append(results, SearchResult{Key: entry.key, Score: score})
+		}
+	}
+
+	if len(ai.entries) <= LSH_EXACT_SEARCH_LIMIT {
+		for _, entry := range ai.entries {
+			addIfClose(entry)
+		}
+	} else {
+		queryBucket := ai.getBucket(normalized)
+		for _, entry := range ai.buckets[queryBucket] {
+			addIfClose(entry)
+		}
+		for i := 0; i < LSH_NUM_PLANES; i++ {
+			for _, entry := range ai.buckets[queryBucket^(1<<i)] {
+				addIfClose(entry)
+			}
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score == results[j].Score {
+			return results[i].Key < results[j].Key
+		}
+		return results[i].Score > results[j].Score
+	})
+	if k > 0 && len(results) > k {
+		results = results[:k]
+	}
+	return results
+}
+
+func (ai *AnnIndex) initPlanes(dimensions int) {
+	rng := rand.New(rand.NewSource(LSH_RANDOM_SEED))
+	ai.dimensions = dimensions
+	ai.planes = make([][]float32, LSH_NUM_PLANES)
+	for i := range ai.planes {
+		ai.planes[i] = make([]float32, dimensions)
+		for j := range ai.planes[i] {
+			ai.planes[i][j] = float32(rng.NormFloat64())
+		}
+	}
+}
+
+func (ai *AnnIndex) getBucket(vector []float32) uint32 {
+	var bucket uint32
+	for i, plane := range ai.planes {
+		if dotProduct(vector, plane) >= 0 {
+			bucket |= 1 << i
+		}
+	}
+	return bucket
+}
+
+func (ai *AnnIndex) evictOldest() {
+	for ai.maxEntries > 0 && len(ai.entries) > ai.maxEntries && len(ai.insertList) > 0 {
+		key := ai.insertList[0]
+		ai.insertList = ai.insertList[1:]
+		entry, ok := ai.entries[key]
+		if !ok {
+			continue
+		}
+		delete(ai.buckets[entry.bucket], key)
+		delete(ai.entries, key)
+	}
+}
+
+func normalize(vector []float32) []float32 {
+	var sumSquares float64
+	for _, val := range vector {
+		sumSquares += float64(val) * float64(val)
+	}
+	if sumSquares == 0 {
+		return nil
+	}
+	norm := math.Sqrt(sumSquares)
+	normalized := make([]float32, len(vector))
+	for i, val := range vector {
+		normalized[i] = float32(float64(val) / norm)
+	}
+	return normalized
+}
+
+func dotProduct(a []float32, b []float32) float64 {
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
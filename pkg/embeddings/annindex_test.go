@@ -0,0 +1,89 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package embeddings
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AnnIndexSearch(t *testing.T) {
+	index := NewAnnIndex(10)
+	assert.True(t, index.Add("a", []float32{1, 0, 0}))
+	assert.True(t, index.Add("b", []float32{0.9, 0.1, 0}))
+	assert.True(t, index.Add("c", []float32{0, 0, 1}))
+	assert.False(t, index.Add("zero", []float32{0, 0, 0}))
+	assert.False(t, index.Add("wrongDims", []float32{1, 0}))
+	assert.Equal(t, 3, index.Len())
+
+	results := index.Search([]float32{2, 0, 0}, 0, 0.5)
+	assert.Len(t, results, 2)
+	assert.Equal(t, "a", results[0].Key)
+	assert.InDelta(t, 1.0, results[0].Score, 1e-6)
+	assert.Equal(t, "b", results[1].Key)
+
+	results = index.Search([]float32{1, 0, 0}, 1, -1)
+	assert.Len(t, results, 1)
+	assert.Equal(t, "a", results[0].Key)
+}
+
+func Test_AnnIndexEviction(t *testing.T) {
+	index := NewAnnIndex(2)
+	index.Add("a", []float32{1, 0})
+	index.Add("b", []float32{0, 1})
+	index.Add("a", []float32{1, 1})
+	index.Add("c", []float32{1, 2})
+
+	assert.Equal(t, 2, index.Len())
+	_, ok := index.Get("a")
+	assert.False(t, ok)
+	_, ok = index.Get("b")
+	assert.True(t, ok)
+	_, ok = index.Get("c")
+	assert.True(t, ok)
+}
+
+func Test_AnnIndexApproximateSearch(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	numEntries := LSH_EXACT_SEARCH_LIMIT * 2
+	index := NewAnnIndex(numEntries)
+
+	dims := 16
+	var target []float32
+	for i := 0; i < numEntries; i++ {
+		vector := make([]float32, dims)
+		for j := range vector {
+			vector[j] = float32(rng.NormFloat64())
+		}
+		if i == 0 {
+			target = vector
+		}
+		index.Add(fmt.Sprintf("key%v", i), vector)
+	}
+
+	// a slightly perturbed copy of an entry should find it
+	query := make([]float32, dims)
+	for i := range query {
+		query[i] = target[i] + 0.01
+	}
+	results := index.Search(query, 1, 0.9)
+	assert.Len(t, results, 1)
+	assert.Equal(t, "key0", results[0].Key)
+}
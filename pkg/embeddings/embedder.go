@@ -0,0 +1,120 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package embeddings
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const EMBEDDING_REQUEST_TIMEOUT = 30 * time.Second
+
+// Embedder computes one embedding vector per input text.
+// The default Embedder calls the configured model endpoint; other models can be plugged in with SetEmbedder.
+type Embedder interface {
+	Embed(texts []string) ([][]float32, error)
+}
+
+type httpEmbedder struct {
+	endpoint string
+	model    string
+	apiKey   string
+	client   *http.Client
+}
+
+type embeddingRequest struct {
+	Model string   `json:"model,omitempty"`
+	Input []string `json:"input"`
+}
+
+type embeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+// Returns an Embedder for an OpenAI compatible embeddings endpoint.
+func NewHttpEmbedder(endpoint string, model string, apiKey string) Embedder {
+	return &httpEmbedder{
+		endpoint: endpoint,
+		model:    model,
+		apiKey:   apiKey,
+		client:   &http.Client{Timeout: EMBEDDING_REQUEST_TIMEOUT},
+	}
+}
+
+func (he *httpEmbedder) Embed(texts []string) ([][]float32, error) {
+	if he.endpoint == "" {
+		return nil, errors.New("httpEmbedder.Embed: no model endpoint is configured")
+	}
+
+	data, err := json.Marshal(embeddingRequest{Model: he.model, Input: texts})
+	if err != nil {
+		return nil, fmt.Errorf("httpEmbedder.Embed: failed to marshal request, err: %v", err)
+	}
+
+	req, err := http.NewRequest("POST", he.endpoint, bytes.NewBuffer(data))
+	if err != nil {
+		return nil, fmt.Errorf("httpEmbedder.Embed: failed to create request, err: %v", err)
+	}
+	req.Header.Add("Content-Type", "application/json")
+	if he.apiKey != "" {
+		req.Header.Add("Authorization", "Bearer "+he.apiKey)
+	}
+
+	resp, err := he.client.Do(req)
+	if err != nil {
+		log.Errorf("httpEmbedder.Embed: request to %v failed, err: %v", he.endpoint, err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("httpEmbedder.Embed: failed to read response, err: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("httpEmbedder.Embed: model endpoint returned status %v: %v", resp.StatusCode, string(body))
+	}
+
+	var embResp embeddingResponse
+	err = json.Unmarshal(body, &embResp)
+	if err != nil {
+		return nil, fmt.Errorf("httpEmbedder.Embed: failed to unmarshal response, err: %v", err)
+	}
+	if len(embResp.Data) != len(texts) {
+		return nil, fmt.Errorf("httpEmbedder.Embed: expected %v embeddings but got %v", len(texts), len(embResp.Data))
+	}
+
+	vectors := make([][]float32, len(texts))
+	for i, item := range embResp.Data {
+		idx := item.Index
+		if idx < 0 || idx >= len(texts) || vectors[idx] != nil {
+			idx = i
+		}
+		vectors[idx] = item.Embedding
+	}
+	return vectors, nil
+}
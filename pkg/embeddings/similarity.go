@@ -0,0 +1,155 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package embeddings
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/siglens/siglens/pkg/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const DEFAULT_MAX_TEMPLATES = 10_000
+
+// column added to the events returned by similar_to
+const SIMILARITY_SCORE_COL = "similarity_score"
+
+// max number of texts sent to the Embedder in a single call
+const EMBEDDING_BATCH_SIZE = 64
+
+var embedderLock sync.RWMutex
+var customEmbedder Embedder
+
+var templateIndexLock sync.Mutex
+var templateIndex *AnnIndex
+
+// Replaces the Embedder used for log templates. Passing nil restores the configured model endpoint.
+// The similarity index is reset as embeddings from different models are not comparable.
+func SetEmbedder(embedder Embedder) {
+	embedderLock.Lock()
+	customEmbedder = embedder
+	embedderLock.Unlock()
+
+	templateIndexLock.Lock()
+	templateIndex = nil
+	templateIndexLock.Unlock()
+}
+
+func getEmbedder() Embedder {
+	embedderLock.RLock()
+	defer embedderLock.RUnlock()
+	if customEmbedder != nil {
+		return customEmbedder
+	}
+	embCfg := config.GetEmbeddingsConfig()
+	return NewHttpEmbedder(embCfg.ModelEndpoint, embCfg.Model, embCfg.APIKey)
+}
+
+func getTemplateIndex() *AnnIndex {
+	templateIndexLock.Lock()
+	defer templateIndexLock.Unlock()
+	if templateIndex == nil {
+		maxTemplates := config.GetEmbeddingsConfig().MaxTemplates
+		if maxTemplates == 0 {
+			maxTemplates = DEFAULT_MAX_TEMPLATES
+		}
+		templateIndex = NewAnnIndex(int(maxTemplates))
+	}
+	return templateIndex
+}
+
+// Returns the similarity score of every message whose template is at least threshold similar to the
+// template of example, keyed by the index of the message in messages.
+// Embeddings of templates not yet in the index are computed and added to it.
+func GetSimilarMessages(example string, messages []string, threshold float64) (map[int]float64, error) {
+	if !config.IsEmbeddingsEnabled() {
+		return nil, errors.New("GetSimilarMessages: embeddings are not enabled in server.yaml")
+	}
+
+	index := getTemplateIndex()
+	exampleTemplate := ExtractTemplate(example)
+	msgTemplates := make([]string, len(messages))
+	missingTemplates := make([]string, 0)
+	seenTemplates := make(map[string]struct{})
+
+	for i, message := range append([]string{example}, messages...) {
+		template := ExtractTemplate(message)
+		if i > 0 {
+			msgTemplates[i-1] = template
+		}
+		if _, ok := seenTemplates[template]; ok {
+			continue
+		}
+		seenTemplates[template] = struct{}{}
+		if _, ok := index.Get(template); !ok {
+			missingTemplates = append(missingTemplates, template)
+		}
+	}
+
+	err := embedTemplates(index, missingTemplates)
+	if err != nil {
+		return nil, fmt.Errorf("GetSimilarMessages: %v", err)
+	}
+
+	exampleVector, ok := index.Get(exampleTemplate)
+	if !ok {
+		return nil, errors.New("GetSimilarMessages: failed to get an embedding for the example")
+	}
+
+	templateScores := make(map[string]float64)
+	for _, result := range index.Search(exampleVector, 0, threshold) {
+		templateScores[result.Key] = result.Score
+	}
+
+	similarMessages := make(map[int]float64)
+	for i, template := range msgTemplates {
+		if score, ok := templateScores[template]; ok {
+			similarMessages[i] = score
+		}
+	}
+	return similarMessages, nil
+}
+
+func embedTemplates(index *AnnIndex, templates []string) error {
+	if len(templates) == 0 {
+		return nil
+	}
+
+	embedder := getEmbedder()
+	for start := 0; start < len(templates); start += EMBEDDING_BATCH_SIZE {
+		end := start + EMBEDDING_BATCH_SIZE
+		if end > len(templates) {
+			end = len(templates)
+		}
+		vectors, err := embedder.Embed(templates[start:end])
+		if err != nil {
+			log.Errorf("embedTemplates: failed to embed %v templates, err: %v", end-start, err)
+			return err
+		}
+		if len(vectors) != end-start {
+			return fmt.Errorf("embedTemplates: expected %v embeddings but got %v", end-start, len(vectors))
+		}
+		for i, vector := range vectors {
+			if !index.Add(templates[start+i], vector) {
+				log.Warnf("embedTemplates: skipping invalid embedding of dimension %v for template %v", len(vector), templates[start+i])
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package embeddings
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/siglens/siglens/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// embeds text as a bag of words, so texts sharing words are similar
+type bagOfWordsEmbedder struct {
+	numCalls int
+}
+
+func (bw *bagOfWordsEmbedder) Embed(texts []string) ([][]float32, error) {
+	bw.numCalls++
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = make([]float32, 64)
+		for _, word := range strings.Fields(text) {
+			hasher := fnv.New32a()
+			_, _ = hasher.Write([]byte(word))
+			vectors[i][hasher.Sum32()%64] += 1
+		}
+	}
+	return vectors, nil
+}
+
+func Test_ExtractTemplate(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{"connection from 10.0.0.1:8080 closed", "connection from <*> closed"},
+		{"user=42 logged in after 35ms", "user=<*> logged in after <*>"},
+		{"request 9b2e6b0e-3c57-4b1e-8a55-0f2b0e6d0f3c failed (code 500)", "request <*> failed (code <*>)"},
+		{"no variables here", "no variables here"},
+		{"took 1.5s, retry 0x1f", "took <*>, retry <*>"},
+	}
+
+	for _, testCase := range cases {
+		assert.Equal(t, testCase.expected, ExtractTemplate(testCase.input), testCase.input)
+	}
+}
+
+func Test_GetSimilarMessages(t *testing.T) {
+	config.InitializeDefaultConfig()
+	runningConfig := config.GetRunningConfig()
+	runningConfig.Embeddings.Enabled = true
+	config.SetConfig(*runningConfig)
+
+	embedder := &bagOfWordsEmbedder{}
+	SetEmbedder(embedder)
+	defer SetEmbedder(nil)
+
+	messages := []string{
+		"connection refused by host 10.0.0.1",
+		"connection refused by host 10.0.0.2",
+		"disk is full on /dev/sda1",
+		"user logged in",
+	}
+	similar, err := GetSimilarMessages("connection refused by host 192.168.1.1", messages, 0.9)
+	assert.Nil(t, err)
+	assert.Len(t, similar, 2)
+	assert.InDelta(t, 1.0, similar[0], 1e-6)
+	assert.InDelta(t, 1.0, similar[1], 1e-6)
+	assert.Equal(t, 1, embedder.numCalls)
+
+	// the templates are already in the index, so nothing new is embedded
+	similar, err = GetSimilarMessages("connection refused by host 10.1.1.1", messages[:1], 0.9)
+	assert.Nil(t, err)
+	assert.Len(t, similar, 1)
+	assert.Equal(t, 1, embedder.numCalls)
+
+	runningConfig.Embeddings.Enabled = false
+	config.SetConfig(*runningConfig)
+	_, err = GetSimilarMessages("connection refused", messages, 0.9)
+	assert.NotNil(t, err)
+}
+
+func Test_HttpEmbedder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+		var req embeddingRequest
+		err := json.NewDecoder(r.Body).Decode(&req)
+		assert.Nil(t, err)
+		assert.Equal(t, "test-model", req.Model)
+
+		var resp embeddingResponse
+		for i := len(req.Input) - 1; i >= 0; i-- {
+			resp.Data = append(resp.Data, struct {
+				Index     int       `json:"index"`
+				Embedding []float32 `json:"embedding"`
+			}{Index: i, Embedding: []float32{float32(len(req.Input[i]))}})
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	embedder := NewHttpEmbedder(server.URL, "test-model", "secret")
+	vectors, err := embedder.Embed([]string{"a", "abc"})
+	assert.Nil(t, err)
+	assert.Equal(t, [][]float32{{1}, {3}}, vectors)
+
+	_, err = NewHttpEmbedder("", "", "").Embed([]string{"a"})
+	assert.NotNil(t, err)
+}
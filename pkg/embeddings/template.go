@@ -0,0 +1,73 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package embeddings
+
+import (
+	"regexp"
+	"strings"
+)
+
+const TEMPLATE_WILDCARD = "<*>"
+
+var variableTokenRegexes = []*regexp.Regexp{
+	regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`), // uuid
+	regexp.MustCompile(`^\d{1,3}(\.\d{1,3}){3}(:\d+)?$`),                                                // ipv4 with optional port
+	regexp.MustCompile(`^(0x)?[0-9a-fA-F]*\d[0-9a-fA-F]*$`),                                             // numbers and hex ids
+	regexp.MustCompile(`^[-+]?\d+([.,:]\d+)*[a-zA-Z%]*$`),                                               // decimals, times and numbers with units
+}
+
+// Trailing and leading characters that are stripped from a token before checking if it is a variable.
+const tokenPunctuation = `"'()[]{}<>,;=`
+
+// Returns the template of a log message: the message with variable tokens such as
+// numbers, ids and ip addresses replaced by TEMPLATE_WILDCARD.
+// Messages that only differ in these tokens have the same template, so only one
+// embedding needs to be computed for all of them.
+func ExtractTemplate(message string) string {
+	tokens := strings.Fields(message)
+	for i, token := range tokens {
+		tokens[i] = templatizeToken(token)
+	}
+	return strings.Join(tokens, " ")
+}
+
+func templatizeToken(token string) string {
+	// key=value tokens keep the key
+	if idx := strings.IndexByte(token, '='); idx > 0 && idx < len(token)-1 {
+		return token[:idx+1] + templatizeToken(token[idx+1:])
+	}
+
+	core := strings.Trim(token, tokenPunctuation)
+	if core == "" || !hasDigit(core) {
+		return token
+	}
+	for _, regex := range variableTokenRegexes {
+		if regex.MatchString(core) {
+			return strings.Replace(token, core, TEMPLATE_WILDCARD, 1)
+		}
+	}
+	return token
+}
+
+func hasDigit(str string) bool {
+	for i := 0; i < len(str); i++ {
+		if str[i] >= '0' && str[i] <= '9' {
+			return true
+		}
+	}
+	return false
+}
@@ -526,6 +526,18 @@ func processDateHistogram(params interface{}, qid uint64, aggNode *QueryAggregat
 	return errors.New("unable to extract date histogram")
 }
 
+// calendar_interval can also be given as a unit name
+var calendarIntervalUnits = map[string]string{
+	"second":  "s",
+	"minute":  "m",
+	"hour":    "h",
+	"day":     "d",
+	"week":    "w",
+	"month":   "M",
+	"quarter": "q",
+	"year":    "y",
+}
+
 func getIntervalForDateHistogram(timeHist *TimeBucket, inVal map[string]interface{}) error {
 	if inVal == nil {
 		return errors.New("inVal was null")
@@ -546,6 +558,9 @@ func getIntervalForDateHistogram(timeHist *TimeBucket, inVal map[string]interfac
 	if !ok {
 		return errors.New("key `interval` is not a string")
 	}
+	if unit, ok := calendarIntervalUnits[strVal]; ok {
+		strVal = "1" + unit
+	}
 
 	runeStr := []rune(strVal)
 	for i := 0; i < len(runeStr); i++ {
@@ -669,6 +684,12 @@ func parseQuery(json_body interface{}, qid uint64, isJaegerQuery bool) (*ASTNode
 						log.Errorf("qid=%d, parseQuery: Error in parseQuery-parseMatchPhrase: %v", qid, err)
 					}
 					return leafNode, err
+				} else {
+					leafNode, err := parseLeafQuery(key, value, qid, isJaegerQuery)
+					if err != nil {
+						log.Errorf("qid=%d, parseQuery: Error in parseQuery-parseLeafQuery: %v", qid, err)
+					}
+					return leafNode, err
 				}
 			case []map[string]interface{}:
 				if key == "bool" {
@@ -690,6 +711,25 @@ func parseQuery(json_body interface{}, qid uint64, isJaegerQuery bool) (*ASTNode
 	return nil, nil
 }
 
+/*
+Parses a query other than bool at the top level, such as {"term": {"status": "error"}}, the same way as the only
+clause of a bool must
+*/
+func parseLeafQuery(key string, json_body interface{}, qid uint64, isJaegerQuery bool) (*ASTNode, error) {
+	rootNode := &ASTNode{}
+	rootNode.TimeRange = rutils.GetESDefaultQueryTimeRange()
+	filterCond, err := parseLeafNodes(key, json_body, rootNode, qid, isJaegerQuery)
+	if err != nil {
+		return nil, err
+	}
+	if filterCond == nil || (len(filterCond.FilterCriteria) == 0 && len(filterCond.NestedNodes) == 0) {
+		// a range on the timestamp only sets the time range
+		filterCond = &Condition{FilterCriteria: []*FilterCriteria{createTermFilterCriteria("*", "*", Equals, qid)}}
+	}
+	rootNode.AndFilterCondition = filterCond
+	return rootNode, nil
+}
+
 /*
 Example Json_body for bool query
 
@@ -1608,13 +1648,23 @@ func parseRange(json_body interface{}, qid uint64, isJaegerQuery bool) ([]*Filte
 					tsKey = "startTimeMillis"
 				}
 				if key == tsKey {
-					tRange := new(dtu.TimeRange)
+					// a range without a lower bound starts at the epoch and one without an upper bound ends now
+					tRange := &dtu.TimeRange{StartEpochMs: 0, EndEpochMs: utils.GetCurrentTimeInMs()}
 					for nestedKey, nestedValue := range t {
-						if nestedKey == "format" {
+						if nestedValue == nil {
+							// an unbounded side, e.g. "gte": null
+							continue
+						}
+						if nestedKey == "format" || nestedKey == "time_zone" {
 							//todo Handle timestamp format
 							log.Infof("qid=%d, parseRange:Handle timestamp format", qid)
+						} else if nestedKey == "include_upper" || nestedKey == "include_lower" || nestedKey == "boost" {
+							continue
 						} else {
-							val, _ := getEpochFromRangeExprValue(nestedValue, qid)
+							val, err := getEpochFromRangeExprValue(nestedValue, qid)
+							if err != nil {
+								return nil, nil, err
+							}
 							switch nestedKey {
 							case "gt":
 								tRange.StartEpochMs = uint64(val)
@@ -1637,6 +1687,9 @@ func parseRange(json_body interface{}, qid uint64, isJaegerQuery bool) ([]*Filte
 					return nil, tRange, nil
 				} else {
 					for nestedKey, nestedValue := range t {
+						if nestedValue == nil {
+							continue
+						}
 						var opr FilterOperator
 						switch nestedKey {
 						case "gt":
@@ -1674,6 +1727,9 @@ func parseRange(json_body interface{}, qid uint64, isJaegerQuery bool) ([]*Filte
 	return nil, nil, nil
 }
 
+/*
+The value is epoch millis, a date like 2023-10-01 or 2023-10-01T12:00:00Z, or date math like now-15m and now/d
+*/
 func getEpochFromRangeExprValue(incoming interface{}, qid uint64) (int64, error) {
 
 	switch incomingtype := incoming.(type) {
@@ -1681,17 +1737,108 @@ func getEpochFromRangeExprValue(incoming interface{}, qid uint64) (int64, error)
 		val, _ := (incomingtype).Int64()
 		return val, nil
 	case string:
-		valTime, err := time.Parse(time.RFC3339, incomingtype)
-		if err != nil {
-			log.Errorf("qid=%d, getEpochFromRangeExprValue: failed to parse time, in=%v, err=%v", qid, incomingtype, err)
-			return 0, err
+		if val, err := strconv.ParseInt(incomingtype, 10, 64); err == nil {
+			return val, nil
+		}
+		if strings.HasPrefix(incomingtype, "now") {
+			valTime, err := parseDateMath(incomingtype, time.Now().UTC())
+			if err != nil {
+				log.Errorf("qid=%d, getEpochFromRangeExprValue: failed to parse date math, in=%v, err=%v", qid, incomingtype, err)
+				return 0, err
+			}
+			return valTime.UnixMilli(), nil
+		}
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02"} {
+			valTime, err := time.Parse(layout, incomingtype)
+			if err == nil {
+				return valTime.UnixNano() / int64(time.Millisecond), nil
+			}
 		}
-		return valTime.UnixNano() / int64(time.Millisecond), nil
+		err := fmt.Errorf("invalid date %v", incomingtype)
+		log.Errorf("qid=%d, getEpochFromRangeExprValue: failed to parse time, in=%v, err=%v", qid, incomingtype, err)
+		return 0, err
 	}
 
 	return 0, errors.New("getEpochFromRangeExprValue bad input")
 }
 
+/*
+Applies the date math of the expression to now. It is "now" followed by any number of +<n><unit> or -<n><unit>
+and an optional /<unit> that rounds down, the units are y, M, w, d, h, H, m and s
+*/
+func parseDateMath(expr string, now time.Time) (time.Time, error) {
+	result := now
+	rest := strings.TrimPrefix(expr, "now")
+	for len(rest) > 0 {
+		op := rest[0]
+		rest = rest[1:]
+		switch op {
+		case '+', '-':
+			numEnd := 0
+			for numEnd < len(rest) && unicode.IsDigit(rune(rest[numEnd])) {
+				numEnd++
+			}
+			if numEnd == len(rest) {
+				return now, fmt.Errorf("parseDateMath: missing unit in %v", expr)
+			}
+			num := 1
+			if numEnd > 0 {
+				num, _ = strconv.Atoi(rest[:numEnd])
+			}
+			if op == '-' {
+				num = -num
+			}
+			switch rest[numEnd] {
+			case 'y':
+				result = result.AddDate(num, 0, 0)
+			case 'M':
+				result = result.AddDate(0, num, 0)
+			case 'w':
+				result = result.AddDate(0, 0, 7*num)
+			case 'd':
+				result = result.AddDate(0, 0, num)
+			case 'h', 'H':
+				result = result.Add(time.Duration(num) * time.Hour)
+			case 'm':
+				result = result.Add(time.Duration(num) * time.Minute)
+			case 's':
+				result = result.Add(time.Duration(num) * time.Second)
+			default:
+				return now, fmt.Errorf("parseDateMath: invalid unit %c in %v", rest[numEnd], expr)
+			}
+			rest = rest[numEnd+1:]
+		case '/':
+			if len(rest) != 1 {
+				return now, fmt.Errorf("parseDateMath: rounding has to be last in %v", expr)
+			}
+			year, month, day := result.Date()
+			switch rest[0] {
+			case 'y':
+				result = time.Date(year, 1, 1, 0, 0, 0, 0, result.Location())
+			case 'M':
+				result = time.Date(year, month, 1, 0, 0, 0, 0, result.Location())
+			case 'w':
+				daysSinceMonday := (int(result.Weekday()) + 6) % 7
+				result = time.Date(year, month, day-daysSinceMonday, 0, 0, 0, 0, result.Location())
+			case 'd':
+				result = time.Date(year, month, day, 0, 0, 0, 0, result.Location())
+			case 'h', 'H':
+				result = result.Truncate(time.Hour)
+			case 'm':
+				result = result.Truncate(time.Minute)
+			case 's':
+				result = result.Truncate(time.Second)
+			default:
+				return now, fmt.Errorf("parseDateMath: invalid unit %c in %v", rest[0], expr)
+			}
+			rest = ""
+		default:
+			return now, fmt.Errorf("parseDateMath: invalid date math %v", expr)
+		}
+	}
+	return result, nil
+}
+
 /*
 "match": {
   <<column-name>>: {
@@ -19,6 +19,7 @@ package query
 import (
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/siglens/siglens/pkg/config"
 	"github.com/siglens/siglens/pkg/segment/structs"
@@ -2077,3 +2078,113 @@ func Test_ParseRequest_match_multi_best_fields(t *testing.T) {
 	assert.Equal(t, res.AndFilterCondition.NestedNodes[0].OrFilterCondition.FilterCriteria[1].MatchFilter.MatchWords, [][]byte{[]byte("brown"), []byte("fox")})
 	assert.NotEqual(t, res.AndFilterCondition.NestedNodes[0].OrFilterCondition.FilterCriteria[1].MatchFilter.MatchType, MATCH_PHRASE)
 }
+
+func Test_ParseRequest_topLevelLeafQuery(t *testing.T) {
+	config.InitializeDefaultConfig()
+	json_body := []byte(`{"query": {"term": {"status": "error"}}}`)
+	res, _, _, _, err := ParseRequest(json_body, 0, false)
+	assert.Nil(t, err)
+	assert.NotNil(t, res.TimeRange)
+	assert.Equal(t, "status", res.AndFilterCondition.FilterCriteria[0].ExpressionFilter.LeftInput.Expression.LeftInput.ColumnName)
+	assert.Equal(t, "error", res.AndFilterCondition.FilterCriteria[0].ExpressionFilter.RightInput.Expression.LeftInput.ColumnValue.StringVal)
+
+	json_body = []byte(`{"query": {"wildcard": {"host": {"value": "web-*"}}}}`)
+	res, _, _, _, err = ParseRequest(json_body, 0, false)
+	assert.Nil(t, err)
+	assert.Equal(t, "host", res.AndFilterCondition.FilterCriteria[0].ExpressionFilter.LeftInput.Expression.LeftInput.ColumnName)
+
+	json_body = []byte(`{"query": {"exists": {"field": "user.id"}}}`)
+	res, _, _, _, err = ParseRequest(json_body, 0, false)
+	assert.Nil(t, err)
+	assert.Len(t, res.AndFilterCondition.FilterCriteria, 1)
+
+	// a range on the timestamp only sets the time range, the missing bound is now
+	json_body = []byte(`{"query": {"range": {"timestamp": {"gte": "now-15m"}}}}`)
+	beforeMs := uint64(time.Now().UnixMilli())
+	res, _, _, _, err = ParseRequest(json_body, 0, false)
+	assert.Nil(t, err)
+	assert.Equal(t, "*", res.AndFilterCondition.FilterCriteria[0].ExpressionFilter.LeftInput.Expression.LeftInput.ColumnName)
+	assert.InDelta(t, beforeMs-15*60*1000, res.TimeRange.StartEpochMs, 1000)
+	assert.GreaterOrEqual(t, res.TimeRange.EndEpochMs, beforeMs)
+
+	// the missing lower bound is the epoch, also when it is null
+	json_body = []byte(`{"query": {"range": {"timestamp": {"gte": null, "lte": 1633000284000}}}}`)
+	res, _, _, _, err = ParseRequest(json_body, 0, false)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), res.TimeRange.StartEpochMs)
+	assert.Equal(t, uint64(1633000284000), res.TimeRange.EndEpochMs)
+
+	json_body = []byte(`{"query": {"range": {"age": {"gte": 10, "lte": null}}}}`)
+	res, _, _, _, err = ParseRequest(json_body, 0, false)
+	assert.Nil(t, err)
+	assert.Len(t, res.AndFilterCondition.FilterCriteria, 1)
+
+	json_body = []byte(`{"query": {"range": {"timestamp": {"gte": "yesterday"}}}}`)
+	_, _, _, _, err = ParseRequest(json_body, 0, false)
+	assert.NotNil(t, err)
+
+	json_body = []byte(`{"query": {"fuzzy": {"user.id": "ki"}}}`)
+	_, _, _, _, err = ParseRequest(json_body, 0, false)
+	assert.NotNil(t, err)
+}
+
+func Test_getEpochFromRangeExprValue(t *testing.T) {
+	val, err := getEpochFromRangeExprValue("1633000284000", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1633000284000), val)
+
+	val, err = getEpochFromRangeExprValue("2021-09-30", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1632960000000), val)
+
+	val, err = getEpochFromRangeExprValue("2021-09-30T11:11:24.5Z", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1633000284500), val)
+
+	now := time.Date(2023, 10, 12, 15, 30, 45, 0, time.UTC) // a thursday
+	for expr, expected := range map[string]time.Time{
+		"now":          now,
+		"now-15m":      now.Add(-15 * time.Minute),
+		"now+1h-30s":   now.Add(time.Hour - 30*time.Second),
+		"now-1d/d":     time.Date(2023, 10, 11, 0, 0, 0, 0, time.UTC),
+		"now/w":        time.Date(2023, 10, 9, 0, 0, 0, 0, time.UTC),
+		"now-1M/M":     time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC),
+		"now/y":        time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		"now-2w+3H/h":  time.Date(2023, 9, 28, 18, 0, 0, 0, time.UTC),
+		"now-1y-1s/m":  time.Date(2022, 10, 12, 15, 30, 0, 0, time.UTC),
+		"now+10d-10d":  now,
+		"now-15s/s":    now.Add(-15 * time.Second),
+		"now-1m+60s/m": time.Date(2023, 10, 12, 15, 30, 0, 0, time.UTC),
+	} {
+		result, err := parseDateMath(expr, now)
+		assert.Nil(t, err, expr)
+		assert.Equal(t, expected, result, expr)
+	}
+	for _, expr := range []string{"now-15", "now-15x", "now/d-1h", "now*2"} {
+		_, err := parseDateMath(expr, now)
+		assert.NotNil(t, err, expr)
+	}
+}
+
+func Test_aggregationParsing_termsAndCalendarInterval(t *testing.T) {
+	json_body := []byte(`{"size": 0, "aggs": {
+		"hosts": {
+			"terms": {"field": "host", "size": 5},
+			"aggs": {"avg_latency": {"avg": {"field": "latency"}}}
+		}
+	}}`)
+	_, agg, _, _, err := ParseRequest(json_body, 0, false)
+	assert.Nil(t, err)
+	assert.Equal(t, "hosts", agg.GroupByRequest.AggName)
+	assert.Equal(t, []string{"host"}, agg.GroupByRequest.GroupByColumns)
+	assert.Equal(t, 5, agg.GroupByRequest.BucketCount)
+	assert.Len(t, agg.GroupByRequest.MeasureOperations, 1)
+	assert.Equal(t, "latency", agg.GroupByRequest.MeasureOperations[0].MeasureCol)
+	assert.Equal(t, Avg, agg.GroupByRequest.MeasureOperations[0].MeasureFunc)
+
+	json_body = []byte(`{"aggs": {"per_day": {"date_histogram": {"field": "timestamp", "calendar_interval": "day"}}}}`)
+	_, agg, _, _, err = ParseRequest(json_body, 0, false)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(24*3600*1000), agg.TimeHistogram.IntervalMillis)
+	assert.Equal(t, "per_day", agg.TimeHistogram.AggName)
+}
@@ -0,0 +1,424 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reader
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cespare/xxhash"
+	"github.com/shirou/gopsutil/cpu"
+	"github.com/shirou/gopsutil/load"
+	"github.com/shirou/gopsutil/mem"
+	"github.com/siglens/siglens/pkg/config"
+	segwriter "github.com/siglens/siglens/pkg/segment/writer"
+	"github.com/siglens/siglens/pkg/utils"
+	vtable "github.com/siglens/siglens/pkg/virtualtable"
+	log "github.com/sirupsen/logrus"
+	"github.com/valyala/fasthttp"
+)
+
+/*
+	The _cat APIs of Elasticsearch, which operational scripts use to list the indices and check on the cluster. Each
+	one returns an aligned text table, or a json array of objects with format=json, and supports the v, h, s and
+	bytes parameters. Siglens does not shard the indices, so every index has a single primary and no replicas.
+*/
+
+type catColumn struct {
+	name    string
+	isBytes bool // formatted according to the bytes parameter
+}
+
+type catTable struct {
+	columns []catColumn
+	rows    [][]interface{} // the values are uint64, float64 or string
+}
+
+var catByteUnits = map[string]uint64{
+	"b":  1,
+	"kb": 1 << 10,
+	"mb": 1 << 20,
+	"gb": 1 << 30,
+	"tb": 1 << 40,
+	"pb": 1 << 50,
+}
+
+type catIndexStats struct {
+	name       string
+	docsCount  uint64
+	storeBytes uint64
+}
+
+// Handles _cat/indices and _cat/indices/{indexName}
+func ProcessCatIndicesRequest(ctx *fasthttp.RequestCtx, myid uint64) {
+	indexStats, ok := getCatIndexStats(ctx, myid)
+	if !ok {
+		return
+	}
+
+	table := &catTable{
+		columns: []catColumn{{name: "health"}, {name: "status"}, {name: "index"}, {name: "uuid"}, {name: "pri"},
+			{name: "rep"}, {name: "docs.count"}, {name: "docs.deleted"}, {name: "store.size", isBytes: true},
+			{name: "pri.store.size", isBytes: true}},
+		rows: make([][]interface{}, 0, len(indexStats)),
+	}
+	for _, stats := range indexStats {
+		uuid := fmt.Sprintf("%016x", xxhash.Sum64String(fmt.Sprintf("%v/%v", myid, stats.name)))
+		table.rows = append(table.rows, []interface{}{"green", "open", stats.name, uuid, uint64(1), uint64(0),
+			stats.docsCount, uint64(0), stats.storeBytes, stats.storeBytes})
+	}
+	writeCatResponse(ctx, table)
+}
+
+// Handles _cat/count and _cat/count/{indexName}
+func ProcessCatCountRequest(ctx *fasthttp.RequestCtx, myid uint64) {
+	indexStats, ok := getCatIndexStats(ctx, myid)
+	if !ok {
+		return
+	}
+
+	count := uint64(0)
+	for _, stats := range indexStats {
+		count += stats.docsCount
+	}
+	now := time.Now().UTC()
+	table := &catTable{
+		columns: []catColumn{{name: "epoch"}, {name: "timestamp"}, {name: "count"}},
+		rows:    [][]interface{}{{uint64(now.Unix()), now.Format("15:04:05"), count}},
+	}
+	writeCatResponse(ctx, table)
+}
+
+// Handles _cat/health, the same status as the _cluster/health api
+func ProcessCatHealthRequest(ctx *fasthttp.RequestCtx, myid uint64) {
+	health := utils.NewClusterHealthResponseInfo()
+	numShards := uint64(len(vtable.ExpandAndReturnIndexNames("*", myid, true)))
+	now := time.Now().UTC()
+	table := &catTable{
+		columns: []catColumn{{name: "epoch"}, {name: "timestamp"}, {name: "cluster"}, {name: "status"},
+			{name: "node.total"}, {name: "node.data"}, {name: "shards"}, {name: "pri"}, {name: "relo"},
+			{name: "init"}, {name: "unassign"}, {name: "pending_tasks"}, {name: "max_task_wait_time"},
+			{name: "active_shards_percent"}},
+		rows: [][]interface{}{{uint64(now.Unix()), now.Format("15:04:05"), health.ClusterName, health.Status,
+			uint64(health.NumberOfNodes), uint64(health.NumberOfDataNodes), numShards, numShards,
+			uint64(health.RelocatingShards), uint64(health.InitiliazeShards), uint64(health.UnassignedShards),
+			uint64(health.NumberOfPendingTasks), "-", fmt.Sprintf("%.1f%%", health.ActiveShardsPercent)}},
+	}
+	writeCatResponse(ctx, table)
+}
+
+// Handles _cat/nodes, the resource usage of this node
+func ProcessCatNodesRequest(ctx *fasthttp.RequestCtx) {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	heapPercent := uint64(0)
+	if memStats.HeapSys > 0 {
+		heapPercent = memStats.HeapAlloc * 100 / memStats.HeapSys
+	}
+
+	var ramPercent, cpuPercent interface{} = "-", "-"
+	if vmStat, err := mem.VirtualMemory(); err == nil {
+		ramPercent = uint64(vmStat.UsedPercent)
+	}
+	if cpuPercents, err := cpu.Percent(0, false); err == nil && len(cpuPercents) > 0 {
+		cpuPercent = uint64(cpuPercents[0])
+	}
+	var load1, load5, load15 interface{} = "-", "-", "-"
+	if loadAvg, err := load.Avg(); err == nil {
+		load1, load5, load15 = loadAvg.Load1, loadAvg.Load5, loadAvg.Load15
+	}
+
+	roles := "m"
+	if config.IsQueryNode() {
+		roles = "d" + roles
+	}
+	if config.IsIngestNode() {
+		roles += "i"
+	}
+	name, err := os.Hostname()
+	if err != nil {
+		log.Errorf("ProcessCatNodesRequest: failed to get the hostname, err=%v", err)
+		name = "siglens"
+	}
+	ip := "127.0.0.1"
+	if queryHostname := config.GetQueryHostname(); queryHostname != "" {
+		ip = strings.Split(queryHostname, ":")[0]
+	}
+
+	table := &catTable{
+		columns: []catColumn{{name: "ip"}, {name: "heap.percent"}, {name: "ram.percent"}, {name: "cpu"},
+			{name: "load_1m"}, {name: "load_5m"}, {name: "load_15m"}, {name: "node.role"}, {name: "master"},
+			{name: "name"}},
+		rows: [][]interface{}{{ip, heapPercent, ramPercent, cpuPercent, load1, load5, load15, roles, "*", name}},
+	}
+	writeCatResponse(ctx, table)
+}
+
+// Returns the document counts and sizes of the indices that the indexName path parameter names, or of all of them
+func getCatIndexStats(ctx *fasthttp.RequestCtx, myid uint64) ([]*catIndexStats, bool) {
+	indexNames, ok := getRequestedIndexNames(ctx, myid)
+	if !ok {
+		return nil, false
+	}
+
+	allCounts := segwriter.GetVTableCountsForAll(myid)
+	indexStats := make([]*catIndexStats, 0, len(indexNames))
+	for _, indexName := range indexNames {
+		_, unrotatedCount, unrotatedOnDiskBytes := segwriter.GetUnrotatedVTableCounts(indexName, myid)
+		stats := &catIndexStats{
+			name:       indexName,
+			docsCount:  uint64(unrotatedCount),
+			storeBytes: unrotatedOnDiskBytes,
+		}
+		if counts, ok := allCounts[indexName]; ok {
+			stats.docsCount += counts.RecordCount
+			stats.storeBytes += counts.OnDiskBytesCount
+		}
+		indexStats = append(indexStats, stats)
+	}
+	return indexStats, true
+}
+
+// Writes the table as text or json, with the columns and the order of the rows the query parameters ask for
+func writeCatResponse(ctx *fasthttp.RequestCtx, table *catTable) {
+	args := ctx.QueryArgs()
+	columnIdxs, err := getCatColumnIdxs(table, string(args.Peek("h")))
+	if err != nil {
+		writeEsErrorResponse(ctx, fasthttp.StatusBadRequest, "illegal_argument_exception", err.Error())
+		return
+	}
+	err = sortCatRows(table, string(args.Peek("s")))
+	if err != nil {
+		writeEsErrorResponse(ctx, fasthttp.StatusBadRequest, "illegal_argument_exception", err.Error())
+		return
+	}
+	bytesUnit := string(args.Peek("bytes"))
+	if _, ok := catByteUnits[bytesUnit]; bytesUnit != "" && !ok {
+		writeEsErrorResponse(ctx, fasthttp.StatusBadRequest, "illegal_argument_exception",
+			fmt.Sprintf("failed to parse bytes parameter [%v]", bytesUnit))
+		return
+	}
+
+	cells := make([][]string, len(table.rows))
+	for i, row := range table.rows {
+		cells[i] = make([]string, len(columnIdxs))
+		for j, colIdx := range columnIdxs {
+			cells[i][j] = formatCatValue(row[colIdx], table.columns[colIdx].isBytes, bytesUnit)
+		}
+	}
+
+	switch format := string(args.Peek("format")); format {
+	case "json":
+		resp := make([]map[string]string, len(cells))
+		for i, rowCells := range cells {
+			resp[i] = make(map[string]string, len(columnIdxs))
+			for j, colIdx := range columnIdxs {
+				resp[i][table.columns[colIdx].name] = rowCells[j]
+			}
+		}
+		ctx.SetStatusCode(fasthttp.StatusOK)
+		utils.WriteJsonResponse(ctx, resp)
+	case "", "text", "txt":
+		if args.Has("v") && string(args.Peek("v")) != "false" {
+			header := make([]string, len(columnIdxs))
+			for j, colIdx := range columnIdxs {
+				header[j] = table.columns[colIdx].name
+			}
+			cells = append([][]string{header}, cells...)
+		}
+		ctx.SetStatusCode(fasthttp.StatusOK)
+		ctx.SetContentType("text/plain; charset=UTF-8")
+		_, err := ctx.WriteString(formatCatText(cells, getCatRightAligned(table, columnIdxs)))
+		if err != nil {
+			log.Errorf("writeCatResponse: failed to write the response, err=%v", err)
+		}
+	default:
+		writeEsErrorResponse(ctx, fasthttp.StatusBadRequest, "illegal_argument_exception",
+			fmt.Sprintf("unsupported format [%v]", format))
+	}
+}
+
+// Returns the indexes of the columns named in the comma separated h parameter, or of all the columns if it is empty
+func getCatColumnIdxs(table *catTable, headers string) ([]int, error) {
+	if headers == "" {
+		columnIdxs := make([]int, len(table.columns))
+		for i := range table.columns {
+			columnIdxs[i] = i
+		}
+		return columnIdxs, nil
+	}
+
+	columnIdxs := make([]int, 0)
+	for _, header := range strings.Split(headers, ",") {
+		colIdx := getCatColumnIdx(table, strings.TrimSpace(header))
+		if colIdx < 0 {
+			return nil, fmt.Errorf("header [%v] is not an available header", header)
+		}
+		columnIdxs = append(columnIdxs, colIdx)
+	}
+	return columnIdxs, nil
+}
+
+func getCatColumnIdx(table *catTable, name string) int {
+	for i, column := range table.columns {
+		if column.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Sorts the rows by the comma separated columns of the s parameter, each of which can be suffixed with :asc or :desc
+func sortCatRows(table *catTable, sortBy string) error {
+	if sortBy == "" {
+		return nil
+	}
+
+	colIdxs := make([]int, 0)
+	descending := make([]bool, 0)
+	for _, sortCol := range strings.Split(sortBy, ",") {
+		name, order := sortCol, "asc"
+		if idx := strings.LastIndex(sortCol, ":"); idx != -1 {
+			name, order = sortCol[:idx], sortCol[idx+1:]
+		}
+		if order != "asc" && order != "desc" {
+			return fmt.Errorf("unsupported sort order [%v] for column [%v]", order, name)
+		}
+		colIdx := getCatColumnIdx(table, name)
+		if colIdx < 0 {
+			return fmt.Errorf("unable to sort by unknown sort key [%v]", name)
+		}
+		colIdxs = append(colIdxs, colIdx)
+		descending = append(descending, order == "desc")
+	}
+
+	sort.SliceStable(table.rows, func(i, j int) bool {
+		for k, colIdx := range colIdxs {
+			cmp := compareCatValues(table.rows[i][colIdx], table.rows[j][colIdx])
+			if cmp == 0 {
+				continue
+			}
+			return (cmp < 0) != descending[k]
+		}
+		return false
+	})
+	return nil
+}
+
+// Compares the numbers numerically and anything else as strings
+func compareCatValues(a interface{}, b interface{}) int {
+	aNum, aIsNum := toCatNumber(a)
+	bNum, bIsNum := toCatNumber(b)
+	if aIsNum && bIsNum {
+		if aNum < bNum {
+			return -1
+		} else if aNum > bNum {
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(fmt.Sprintf("%v", a), fmt.Sprintf("%v", b))
+}
+
+func toCatNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+func formatCatValue(value interface{}, isBytes bool, bytesUnit string) string {
+	switch v := value.(type) {
+	case uint64:
+		if isBytes {
+			return formatCatBytes(v, bytesUnit)
+		}
+		return strconv.FormatUint(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+/*
+Formats the bytes in the unit, as a whole number like Elasticsearch does. Without a unit it picks the largest unit
+that keeps the value at least 1 and keeps one decimal, like 5.2kb
+*/
+func formatCatBytes(bytes uint64, unit string) string {
+	if unit != "" {
+		return strconv.FormatUint(bytes/catByteUnits[unit], 10)
+	}
+
+	for _, unit := range []string{"pb", "tb", "gb", "mb", "kb"} {
+		if bytes >= catByteUnits[unit] {
+			value := strconv.FormatFloat(float64(bytes)/float64(catByteUnits[unit]), 'f', 1, 64)
+			return strings.TrimSuffix(value, ".0") + unit
+		}
+	}
+	return strconv.FormatUint(bytes, 10) + "b"
+}
+
+// Numbers are aligned to the right, like Elasticsearch does
+func getCatRightAligned(table *catTable, columnIdxs []int) []bool {
+	rightAligned := make([]bool, len(columnIdxs))
+	for j, colIdx := range columnIdxs {
+		rightAligned[j] = len(table.rows) > 0
+		for _, row := range table.rows {
+			if _, isNum := toCatNumber(row[colIdx]); !isNum {
+				rightAligned[j] = false
+				break
+			}
+		}
+	}
+	return rightAligned
+}
+
+func formatCatText(cells [][]string, rightAligned []bool) string {
+	widths := make([]int, len(rightAligned))
+	for _, rowCells := range cells {
+		for j, cell := range rowCells {
+			if len(cell) > widths[j] {
+				widths[j] = len(cell)
+			}
+		}
+	}
+
+	var sb strings.Builder
+	for _, rowCells := range cells {
+		line := make([]string, len(rowCells))
+		for j, cell := range rowCells {
+			if rightAligned[j] {
+				line[j] = fmt.Sprintf("%*s", widths[j], cell)
+			} else {
+				line[j] = fmt.Sprintf("%-*s", widths[j], cell)
+			}
+		}
+		sb.WriteString(strings.TrimRight(strings.Join(line, " "), " "))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
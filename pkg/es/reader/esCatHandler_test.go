@@ -0,0 +1,89 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reader
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func getTestCatTable() *catTable {
+	return &catTable{
+		columns: []catColumn{{name: "index"}, {name: "docs.count"}, {name: "store.size", isBytes: true}},
+		rows: [][]interface{}{
+			{"ind-b", uint64(9), uint64(5324)},
+			{"ind-a", uint64(120), uint64(208)},
+			{"index-c", uint64(9), uint64(3 << 20)},
+		},
+	}
+}
+
+func runCatRequest(table *catTable, queryArgs string) *fasthttp.RequestCtx {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/elastic/_cat/indices?" + queryArgs)
+	writeCatResponse(ctx, table)
+	return ctx
+}
+
+func Test_CatTextResponse(t *testing.T) {
+	ctx := runCatRequest(getTestCatTable(), "v")
+	assert.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+	expected := "index   docs.count store.size\n" +
+		"ind-b            9      5.2kb\n" +
+		"ind-a          120       208b\n" +
+		"index-c          9        3mb\n"
+	assert.Equal(t, expected, string(ctx.Response.Body()))
+
+	ctx = runCatRequest(getTestCatTable(), "h=store.size,index&bytes=kb&s=docs.count:desc,index")
+	assert.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+	expected = "   0 ind-a\n" +
+		"   5 ind-b\n" +
+		"3072 index-c\n"
+	assert.Equal(t, expected, string(ctx.Response.Body()))
+}
+
+func Test_CatJsonResponse(t *testing.T) {
+	ctx := runCatRequest(getTestCatTable(), "format=json&s=store.size&bytes=b")
+	assert.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+
+	var resp []map[string]string
+	err := json.Unmarshal(ctx.Response.Body(), &resp)
+	assert.Nil(t, err)
+	assert.Equal(t, []map[string]string{
+		{"index": "ind-a", "docs.count": "120", "store.size": "208"},
+		{"index": "ind-b", "docs.count": "9", "store.size": "5324"},
+		{"index": "index-c", "docs.count": "9", "store.size": "3145728"},
+	}, resp)
+}
+
+func Test_CatInvalidParams(t *testing.T) {
+	for _, queryArgs := range []string{"h=index,uuid", "s=uuid", "s=index:up", "bytes=kib", "format=yaml"} {
+		ctx := runCatRequest(getTestCatTable(), queryArgs)
+		assert.Equal(t, fasthttp.StatusBadRequest, ctx.Response.StatusCode(), queryArgs)
+	}
+}
+
+func Test_FormatCatBytes(t *testing.T) {
+	assert.Equal(t, "0b", formatCatBytes(0, ""))
+	assert.Equal(t, "1023b", formatCatBytes(1023, ""))
+	assert.Equal(t, "1kb", formatCatBytes(1024, ""))
+	assert.Equal(t, "1.5gb", formatCatBytes(3<<29, ""))
+	assert.Equal(t, "1", formatCatBytes(3<<19, "mb"))
+}
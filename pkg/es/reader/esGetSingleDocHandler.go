@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/nqd/flat"
+	"github.com/siglens/siglens/pkg/apikeys"
 	"github.com/siglens/siglens/pkg/es/query"
 	rutils "github.com/siglens/siglens/pkg/readerUtils"
 	"github.com/siglens/siglens/pkg/segment"
@@ -79,6 +80,7 @@ func ProcessSingleDocGetRequest(ctx *fasthttp.RequestCtx, myid uint64) {
 	segment.LogASTNode("esGetSingleDocHandler", simpleNode, qid)
 	sizeLimit := uint64(1)
 	qc := structs.InitQueryContext(indexNameConverted, sizeLimit, 0, myid, true)
+	qc.IndexFilter = apikeys.GetIndexFilter(ctx, apikeys.ACCESS_READ)
 	segment.LogQueryContext(qc, qid)
 	result := segment.ExecuteQuery(simpleNode, &QueryAggregators{}, qid, qc)
 
@@ -99,6 +101,8 @@ func ProcessSingleDocGetRequest(ctx *fasthttp.RequestCtx, myid uint64) {
 	// TODO: fix hard coded fields
 	response.Found = true
 	respSrc = queryResult.Hits.Hits[0].Source
+	masker := apikeys.GetResultMasker(apikeys.GetRequestApiKey(ctx), qc.TableInfo.GetQueryTables(), myid)
+	masker.MaskRecord(queryResult.Hits.Hits[0].Index, respSrc)
 
 	finalSrc, err := flat.Unflatten(respSrc, nil)
 	if err != nil {
@@ -0,0 +1,313 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reader
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	dtu "github.com/siglens/siglens/pkg/common/dtypeutils"
+	"github.com/siglens/siglens/pkg/config"
+	"github.com/siglens/siglens/pkg/segment/query/metadata"
+	"github.com/siglens/siglens/pkg/segment/reader/segread"
+	segutils "github.com/siglens/siglens/pkg/segment/utils"
+	segwriter "github.com/siglens/siglens/pkg/segment/writer"
+	"github.com/siglens/siglens/pkg/utils"
+	log "github.com/sirupsen/logrus"
+	"github.com/valyala/fasthttp"
+)
+
+/*
+	The _mapping and _field_caps APIs, which Kibana and OpenSearch Dashboards use to create index patterns. The type
+	of each field is derived from the segment stats of its column: the numeric columns are long or double, the
+	other ones keyword, and the timestamp column is a date. A column whose type differs between segments gets the
+	widest type of them.
+*/
+
+const (
+	esTypeLong    = "long"
+	esTypeDouble  = "double"
+	esTypeKeyword = "keyword"
+	esTypeDate    = "date"
+)
+
+// The types that the others widen to come later
+var esTypeRanks = map[string]int{
+	esTypeLong:    1,
+	esTypeDouble:  2,
+	esTypeKeyword: 3,
+	esTypeDate:    4,
+}
+
+// The metadata fields that _field_caps reports for every index
+var esMetadataFieldTypes = map[string]string{
+	"_id":    "_id",
+	"_index": "_index",
+}
+
+type esFieldCaps struct {
+	Type          string   `json:"type"`
+	MetadataField bool     `json:"metadata_field"`
+	Searchable    bool     `json:"searchable"`
+	Aggregatable  bool     `json:"aggregatable"`
+	Indices       []string `json:"indices,omitempty"`
+}
+
+type esFieldCapsResponse struct {
+	Indices []string                           `json:"indices"`
+	Fields  map[string]map[string]*esFieldCaps `json:"fields"`
+}
+
+// The column types of the rotated segments never change, so they are read only once. Keyed by the table and orgid
+var rotatedColDtypes = map[string]map[string]map[string]segutils.SS_DTYPE{}
+var rotatedColDtypesLock sync.Mutex
+
+// Handles _mapping and {indexName}/_mapping
+func ProcessGetMappingRequest(ctx *fasthttp.RequestCtx, myid uint64) {
+	indexNames, ok := getRequestedIndexNames(ctx, myid)
+	if !ok {
+		return
+	}
+
+	resp := make(map[string]interface{}, len(indexNames))
+	for _, indexName := range indexNames {
+		resp[indexName] = map[string]interface{}{
+			"mappings": map[string]interface{}{
+				"properties": toMappingProperties(getIndexFieldTypes(indexName, myid)),
+			},
+		}
+	}
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	utils.WriteJsonResponse(ctx, resp)
+}
+
+// Handles _field_caps and {indexName}/_field_caps, the fields parameter is a comma separated list of wildcard patterns
+func ProcessFieldCapsRequest(ctx *fasthttp.RequestCtx, myid uint64) {
+	fields := string(ctx.QueryArgs().Peek("fields"))
+	if fields == "" {
+		writeEsErrorResponse(ctx, fasthttp.StatusBadRequest, "illegal_argument_exception",
+			"specified fields can't be null or empty")
+		return
+	}
+	indexNames, ok := getRequestedIndexNames(ctx, myid)
+	if !ok {
+		return
+	}
+
+	allFieldTypes := make(map[string]map[string]string, len(indexNames))
+	for _, indexName := range indexNames {
+		allFieldTypes[indexName] = getIndexFieldTypes(indexName, myid)
+	}
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	utils.WriteJsonResponse(ctx, getFieldCaps(allFieldTypes, getFieldPatterns(fields)))
+}
+
+func getFieldPatterns(fields string) []*regexp.Regexp {
+	patterns := make([]*regexp.Regexp, 0)
+	for _, field := range strings.Split(fields, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		patterns = append(patterns, regexp.MustCompile("^"+dtu.ReplaceWildcardStarWithRegex(field)+"$"))
+	}
+	return patterns
+}
+
+// Takes the field types of each index. A field with different types in different indices lists the indices of each
+func getFieldCaps(allFieldTypes map[string]map[string]string, patterns []*regexp.Regexp) *esFieldCapsResponse {
+	resp := &esFieldCapsResponse{
+		Indices: make([]string, 0, len(allFieldTypes)),
+		Fields:  make(map[string]map[string]*esFieldCaps),
+	}
+	for indexName := range allFieldTypes {
+		resp.Indices = append(resp.Indices, indexName)
+	}
+	sort.Strings(resp.Indices)
+
+	isMatchingField := func(field string) bool {
+		for _, pattern := range patterns {
+			if pattern.MatchString(field) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, indexName := range resp.Indices {
+		for field, fieldType := range allFieldTypes[indexName] {
+			if !isMatchingField(field) {
+				continue
+			}
+			if resp.Fields[field] == nil {
+				resp.Fields[field] = make(map[string]*esFieldCaps)
+			}
+			caps, ok := resp.Fields[field][fieldType]
+			if !ok {
+				caps = &esFieldCaps{Type: fieldType, Searchable: true, Aggregatable: true}
+				resp.Fields[field][fieldType] = caps
+			}
+			caps.Indices = append(caps.Indices, indexName)
+		}
+	}
+	for _, typeCaps := range resp.Fields {
+		if len(typeCaps) > 1 {
+			continue
+		}
+		for _, caps := range typeCaps {
+			caps.Indices = nil
+		}
+	}
+
+	if len(resp.Indices) > 0 {
+		for field, fieldType := range esMetadataFieldTypes {
+			if isMatchingField(field) {
+				resp.Fields[field] = map[string]*esFieldCaps{
+					fieldType: {Type: fieldType, MetadataField: true, Searchable: true, Aggregatable: true},
+				}
+			}
+		}
+	}
+	return resp
+}
+
+// Returns the type of each column of the index
+func getIndexFieldTypes(indexName string, myid uint64) map[string]string {
+	allColDtypes := getRotatedColDtypes(indexName, myid)
+	allColDtypes = append(allColDtypes, segwriter.GetUnrotatedColumnDtypes(indexName, myid)...)
+
+	fieldTypes := make(map[string]string)
+	for _, colDtypes := range allColDtypes {
+		for colName, dtype := range colDtypes {
+			fieldTypes[colName] = widenFieldType(fieldTypes[colName], dtypeToFieldType(dtype))
+		}
+	}
+
+	// columns without stats, like the booleans, are searched as strings
+	allColNames := segwriter.GetUnrotatedColNamesForTable(indexName, myid)
+	for _, colName := range metadata.GetAllColNames([]string{indexName}) {
+		allColNames[colName] = true
+	}
+	for colName := range allColNames {
+		if _, ok := fieldTypes[colName]; !ok {
+			fieldTypes[colName] = esTypeKeyword
+		}
+	}
+	if len(fieldTypes) > 0 {
+		fieldTypes[config.GetTimeStampKey()] = esTypeDate
+	}
+	return fieldTypes
+}
+
+// Returns the column types of each rotated segment of the index, reading the stats of the segments not seen before
+func getRotatedColDtypes(indexName string, myid uint64) []map[string]segutils.SS_DTYPE {
+	segKeys := metadata.GetSegKeysForTable(indexName, myid)
+	cacheKey := fmt.Sprintf("%v/%v", myid, indexName)
+
+	rotatedColDtypesLock.Lock()
+	cachedColDtypes := rotatedColDtypes[cacheKey]
+	rotatedColDtypesLock.Unlock()
+
+	// the segments that were deleted are dropped from the cache
+	segColDtypes := make(map[string]map[string]segutils.SS_DTYPE, len(segKeys))
+	allColDtypes := make([]map[string]segutils.SS_DTYPE, 0, len(segKeys))
+	for _, segKey := range segKeys {
+		colDtypes, ok := cachedColDtypes[segKey]
+		if !ok {
+			segStats, err := segread.ReadSegStats(segKey, 0)
+			if err != nil {
+				log.Errorf("getRotatedColDtypes: failed to read the segment stats of segKey=%v, err=%v", segKey, err)
+				continue
+			}
+			colDtypes = make(map[string]segutils.SS_DTYPE, len(segStats))
+			for colName, sst := range segStats {
+				colDtypes[colName] = sst.GetDtype()
+			}
+		}
+		segColDtypes[segKey] = colDtypes
+		allColDtypes = append(allColDtypes, colDtypes)
+	}
+
+	rotatedColDtypesLock.Lock()
+	if len(segColDtypes) > 0 {
+		rotatedColDtypes[cacheKey] = segColDtypes
+	} else {
+		delete(rotatedColDtypes, cacheKey)
+	}
+	rotatedColDtypesLock.Unlock()
+	return allColDtypes
+}
+
+func dtypeToFieldType(dtype segutils.SS_DTYPE) string {
+	switch dtype {
+	case segutils.SS_DT_FLOAT:
+		return esTypeDouble
+	case segutils.SS_DT_SIGNED_NUM, segutils.SS_DT_UNSIGNED_NUM:
+		return esTypeLong
+	default:
+		return esTypeKeyword
+	}
+}
+
+func widenFieldType(current string, other string) string {
+	if esTypeRanks[other] > esTypeRanks[current] {
+		return other
+	}
+	return current
+}
+
+/*
+Nests the dotted field names into objects, the way Elasticsearch reports the mappings of json objects. A field
+whose parent is also a field with a value keeps its dotted name
+*/
+func toMappingProperties(fieldTypes map[string]string) map[string]interface{} {
+	fieldNames := make([]string, 0, len(fieldTypes))
+	for fieldName := range fieldTypes {
+		fieldNames = append(fieldNames, fieldName)
+	}
+	// a parent sorts before its children, so the leaves are always added before they are found to be parents
+	sort.Strings(fieldNames)
+
+	properties := make(map[string]interface{})
+	for _, fieldName := range fieldNames {
+		leaf := map[string]interface{}{"type": fieldTypes[fieldName]}
+		parts := strings.Split(fieldName, ".")
+		currProperties := properties
+		for _, part := range parts[:len(parts)-1] {
+			child, ok := currProperties[part].(map[string]interface{})
+			if !ok {
+				child = map[string]interface{}{"properties": make(map[string]interface{})}
+				currProperties[part] = child
+			}
+			childProperties, ok := child["properties"].(map[string]interface{})
+			if !ok {
+				currProperties = nil
+				break
+			}
+			currProperties = childProperties
+		}
+		if currProperties == nil {
+			properties[fieldName] = leaf
+			continue
+		}
+		currProperties[parts[len(parts)-1]] = leaf
+	}
+	return properties
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reader
+
+import (
+	"testing"
+
+	segutils "github.com/siglens/siglens/pkg/segment/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WidenFieldType(t *testing.T) {
+	assert.Equal(t, esTypeLong, widenFieldType("", dtypeToFieldType(segutils.SS_DT_SIGNED_NUM)))
+	assert.Equal(t, esTypeDouble, widenFieldType(esTypeLong, dtypeToFieldType(segutils.SS_DT_FLOAT)))
+	assert.Equal(t, esTypeDouble, widenFieldType(esTypeDouble, esTypeLong))
+	assert.Equal(t, esTypeKeyword, widenFieldType(esTypeDouble, dtypeToFieldType(segutils.SS_DT_STRING)))
+	assert.Equal(t, esTypeKeyword, widenFieldType(esTypeKeyword, esTypeLong))
+}
+
+func Test_ToMappingProperties(t *testing.T) {
+	properties := toMappingProperties(map[string]string{
+		"timestamp":      esTypeDate,
+		"http.status":    esTypeLong,
+		"http.req.bytes": esTypeDouble,
+		"host":           esTypeKeyword,
+		"host.name":      esTypeKeyword,
+	})
+	expected := map[string]interface{}{
+		"timestamp": map[string]interface{}{"type": esTypeDate},
+		"http": map[string]interface{}{
+			"properties": map[string]interface{}{
+				"status": map[string]interface{}{"type": esTypeLong},
+				"req": map[string]interface{}{
+					"properties": map[string]interface{}{
+						"bytes": map[string]interface{}{"type": esTypeDouble},
+					},
+				},
+			},
+		},
+		"host":      map[string]interface{}{"type": esTypeKeyword},
+		"host.name": map[string]interface{}{"type": esTypeKeyword},
+	}
+	assert.Equal(t, expected, properties)
+}
+
+func Test_GetFieldCaps(t *testing.T) {
+	allFieldTypes := map[string]map[string]string{
+		"ind-a": {"timestamp": esTypeDate, "latency": esTypeLong, "app": esTypeKeyword},
+		"ind-b": {"timestamp": esTypeDate, "latency": esTypeDouble},
+	}
+
+	resp := getFieldCaps(allFieldTypes, getFieldPatterns("*"))
+	assert.Equal(t, []string{"ind-a", "ind-b"}, resp.Indices)
+	assert.Len(t, resp.Fields, 5)
+	assert.Equal(t, &esFieldCaps{Type: esTypeDate, Searchable: true, Aggregatable: true}, resp.Fields["timestamp"][esTypeDate])
+	assert.Equal(t, &esFieldCaps{Type: esTypeKeyword, Searchable: true, Aggregatable: true}, resp.Fields["app"][esTypeKeyword])
+	assert.Equal(t, []string{"ind-a"}, resp.Fields["latency"][esTypeLong].Indices)
+	assert.Equal(t, []string{"ind-b"}, resp.Fields["latency"][esTypeDouble].Indices)
+	assert.True(t, resp.Fields["_id"]["_id"].MetadataField)
+
+	resp = getFieldCaps(allFieldTypes, getFieldPatterns("lat*, app"))
+	assert.Len(t, resp.Fields, 2)
+	assert.Contains(t, resp.Fields, "latency")
+	assert.Contains(t, resp.Fields, "app")
+}
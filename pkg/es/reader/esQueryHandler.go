@@ -22,6 +22,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/siglens/siglens/pkg/apikeys"
 	"github.com/siglens/siglens/pkg/es/query"
 	rutils "github.com/siglens/siglens/pkg/readerUtils"
 	"github.com/siglens/siglens/pkg/scroll"
@@ -155,9 +156,13 @@ func ProcessSearchRequest(ctx *fasthttp.RequestCtx, myid uint64) {
 			ctx.Response.Header.Set("Content-Type", "application/json")
 			if scrollRecord.Results == nil {
 				qc := structs.InitQueryContextWithTableInfo(ti, ScrollLimit, 0, myid, true)
+				qc.IndexFilter = apikeys.GetIndexFilter(ctx, apikeys.ACCESS_READ)
 				segment.LogQueryContext(qc, qid)
 				result := segment.ExecuteQuery(simpleNode, aggs, qid, qc)
+				masker := apikeys.GetResultMasker(apikeys.GetRequestApiKey(ctx), ti.GetQueryTables(), myid)
+				masker.MaskAggregationResults(result.Histogram)
 				httpRespOuter := query.GetQueryResponseJson(result, indexNameIn, queryStart, sizeLimit, qid, aggs)
+				maskESHits(masker, httpRespOuter.Hits.Hits)
 				scrollRecord.Results = &httpRespOuter
 			}
 			httpRespScroll = query.GetQueryResponseJsonScroll(indexNameIn, queryStart, sizeLimit, scrollRecord, qid)
@@ -165,13 +170,27 @@ func ProcessSearchRequest(ctx *fasthttp.RequestCtx, myid uint64) {
 		}
 	} else {
 		qc := structs.InitQueryContextWithTableInfo(ti, sizeLimit, 0, myid, true)
+		qc.IndexFilter = apikeys.GetIndexFilter(ctx, apikeys.ACCESS_READ)
 		result := segment.ExecuteQuery(simpleNode, aggs, qid, qc)
+		masker := apikeys.GetResultMasker(apikeys.GetRequestApiKey(ctx), ti.GetQueryTables(), myid)
+		masker.MaskAggregationResults(result.Histogram)
 		httpResp = query.GetQueryResponseJson(result, indexNameIn, queryStart, sizeLimit, qid, aggs)
+		maskESHits(masker, httpResp.Hits.Hits)
 		utils.WriteJsonResponse(ctx, httpResp)
 	}
 	ctx.SetStatusCode(fasthttp.StatusOK)
 }
 
+// Masks the sources of the hits with the masking policies that apply to the request
+func maskESHits(masker *apikeys.ResultMasker, hits []utils.Hits) {
+	if masker == nil {
+		return
+	}
+	for _, hit := range hits {
+		masker.MaskRecord(hit.Index, hit.Source)
+	}
+}
+
 func processHttpGetRequest(ctx *fasthttp.RequestCtx) []byte {
 	var httpResp utils.HttpServerResponse
 	queryJson := ctx.PostBody()
@@ -16,7 +16,16 @@ limitations under the License.
 
 package reader
 
-import "github.com/siglens/siglens/pkg/segment/structs"
+import (
+	"strings"
+
+	"github.com/siglens/siglens/pkg/apikeys"
+	"github.com/siglens/siglens/pkg/segment/structs"
+	"github.com/siglens/siglens/pkg/utils"
+	vtable "github.com/siglens/siglens/pkg/virtualtable"
+	log "github.com/sirupsen/logrus"
+	"github.com/valyala/fasthttp"
+)
 
 /*
 Checks if this query + aggs is the special Kibana/ES get all indices query.
@@ -42,3 +51,52 @@ func isAllIndexAggregationQuery(query *structs.ASTNode, aggs *structs.QueryAggre
 	}
 	return false, ""
 }
+
+/*
+Returns the existing indices that the indexName path parameter names and the api key can read, or all of them if
+it is empty or _all. Writes a not found response and returns false if none of the explicitly named indices exist
+*/
+func getRequestedIndexNames(ctx *fasthttp.RequestCtx, myid uint64) ([]string, bool) {
+	indexPattern := utils.ExtractParamAsString(ctx.UserValue("indexName"))
+	if indexPattern == "" || indexPattern == "_all" {
+		indexPattern = "*"
+	}
+
+	allIndices, err := vtable.GetVirtualTableNames(myid)
+	if err != nil {
+		log.Errorf("getRequestedIndexNames: failed to get the indices of myid=%v, err=%v", myid, err)
+		writeEsErrorResponse(ctx, fasthttp.StatusInternalServerError, "exception", err.Error())
+		return nil, false
+	}
+	indexFilter := apikeys.GetIndexFilter(ctx, apikeys.ACCESS_READ)
+
+	indexNames := make([]string, 0)
+	for _, indexName := range vtable.ExpandAndReturnIndexNames(indexPattern, myid, true) {
+		if !allIndices[indexName] || (indexFilter != nil && !indexFilter(indexName)) {
+			continue
+		}
+		indexNames = append(indexNames, indexName)
+	}
+
+	if len(indexNames) == 0 && !strings.Contains(indexPattern, "*") {
+		writeEsErrorResponse(ctx, fasthttp.StatusNotFound, "index_not_found_exception",
+			"no such index ["+indexPattern+"]")
+		return nil, false
+	}
+	return indexNames, true
+}
+
+// Writes an error in the format of Elasticsearch
+func writeEsErrorResponse(ctx *fasthttp.RequestCtx, statusCode int, errType string, reason string) {
+	cause := map[string]interface{}{"type": errType, "reason": reason}
+	resp := map[string]interface{}{
+		"error": map[string]interface{}{
+			"root_cause": []interface{}{cause},
+			"type":       errType,
+			"reason":     reason,
+		},
+		"status": statusCode,
+	}
+	ctx.SetStatusCode(statusCode)
+	utils.WriteJsonResponse(ctx, resp)
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package writer
+
+import (
+	"sync"
+
+	"github.com/siglens/siglens/pkg/config"
+)
+
+const DEFAULT_DEDUP_WINDOW_SECS = 300
+const DEFAULT_DEDUP_MAX_IDS_PER_INDEX = 100_000
+
+type dedupEntry struct {
+	id       string
+	expiryMs uint64
+}
+
+// Remembers the _ids ingested into one index within the dedup window.
+// Entries are kept in insertion order, so the oldest ones are evicted first when the cache is full.
+type dedupCache struct {
+	lock    sync.Mutex
+	expiry  map[string]uint64 // _id to the time in ms until which it is a duplicate
+	queue   []dedupEntry
+	queueAt int // index of the oldest entry in queue
+}
+
+var allDedupCachesLock sync.Mutex
+var allDedupCaches = make(map[string]*dedupCache)
+
+func getDedupCache(indexName string) *dedupCache {
+	allDedupCachesLock.Lock()
+	defer allDedupCachesLock.Unlock()
+
+	cache, ok := allDedupCaches[indexName]
+	if !ok {
+		cache = &dedupCache{
+			expiry: make(map[string]uint64),
+			queue:  make([]dedupEntry, 0),
+		}
+		allDedupCaches[indexName] = cache
+	}
+	return cache
+}
+
+func getDedupWindowMs(dedupCfg config.DedupConfig, indexName string) uint64 {
+	windowSecs := dedupCfg.WindowSecs
+	if indexWindowSecs, ok := dedupCfg.IndexWindowSecs[indexName]; ok {
+		windowSecs = indexWindowSecs
+	}
+	if windowSecs == 0 {
+		windowSecs = DEFAULT_DEDUP_WINDOW_SECS
+	}
+	return windowSecs * 1000
+}
+
+// Returns false if idVal was already ingested into indexName within the dedup window.
+// Otherwise remembers idVal and returns true. Always returns true if dedup is disabled or idVal is empty.
+func reserveDocumentId(indexName string, idVal string, tsNow uint64) bool {
+	dedupCfg := config.GetDedupConfig()
+	if !dedupCfg.Enabled || idVal == "" {
+		return true
+	}
+
+	maxIds := dedupCfg.MaxIdsPerIndex
+	if maxIds == 0 {
+		maxIds = DEFAULT_DEDUP_MAX_IDS_PER_INDEX
+	}
+	return getDedupCache(indexName).reserve(idVal, tsNow, getDedupWindowMs(dedupCfg, indexName), maxIds)
+}
+
+// Forgets idVal, so that a failed document can be retried.
+func releaseDocumentId(indexName string, idVal string) {
+	if !config.GetDedupConfig().Enabled || idVal == "" {
+		return
+	}
+	getDedupCache(indexName).release(idVal)
+}
+
+func (dc *dedupCache) reserve(idVal string, tsNow uint64, windowMs uint64, maxIds uint64) bool {
+	dc.lock.Lock()
+	defer dc.lock.Unlock()
+
+	dc.evict(tsNow, maxIds)
+	if expiryMs, ok := dc.expiry[idVal]; ok && expiryMs > tsNow {
+		return false
+	}
+
+	expiryMs := tsNow + windowMs
+	dc.expiry[idVal] = expiryMs
+	dc.queue = append(dc.queue, dedupEntry{id: idVal, expiryMs: expiryMs})
+	return true
+}
+
+func (dc *dedupCache) release(idVal string) {
+	dc.lock.Lock()
+	defer dc.lock.Unlock()
+
+	// the queue entry is skipped when it is evicted
+	delete(dc.expiry, idVal)
+}
+
+// Removes expired entries, and the oldest entries while there are maxIds or more.
+func (dc *dedupCache) evict(tsNow uint64, maxIds uint64) {
+	for dc.queueAt < len(dc.queue) {
+		oldest := dc.queue[dc.queueAt]
+		if oldest.expiryMs > tsNow && uint64(len(dc.expiry)) < maxIds {
+			break
+		}
+		// the id may have been released and reserved again, in which case it has a newer queue entry
+		if expiryMs, ok := dc.expiry[oldest.id]; ok && expiryMs == oldest.expiryMs {
+			delete(dc.expiry, oldest.id)
+		}
+		dc.queueAt++
+	}
+
+	// reclaim the space of the evicted entries
+	if dc.queueAt > 0 && dc.queueAt >= len(dc.queue)/2 {
+		dc.queue = append(dc.queue[:0], dc.queue[dc.queueAt:]...)
+		dc.queueAt = 0
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package writer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/siglens/siglens/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_dedupCacheWindow(t *testing.T) {
+	cache := &dedupCache{expiry: make(map[string]uint64), queue: make([]dedupEntry, 0)}
+
+	assert.True(t, cache.reserve("a", 1000, 500, 10))
+	assert.False(t, cache.reserve("a", 1200, 500, 10))
+	assert.True(t, cache.reserve("b", 1200, 500, 10))
+
+	// "a" is outside of the window now
+	assert.True(t, cache.reserve("a", 1600, 500, 10))
+	assert.False(t, cache.reserve("b", 1600, 500, 10))
+
+	cache.release("b")
+	assert.True(t, cache.reserve("b", 1650, 500, 10))
+	assert.False(t, cache.reserve("b", 1700, 500, 10))
+}
+
+func Test_dedupCacheMaxIds(t *testing.T) {
+	cache := &dedupCache{expiry: make(map[string]uint64), queue: make([]dedupEntry, 0)}
+
+	for i := 0; i < 5; i++ {
+		assert.True(t, cache.reserve(fmt.Sprintf("id%v", i), 1000, 10_000, 3))
+	}
+	assert.LessOrEqual(t, len(cache.expiry), 3)
+
+	// the oldest ids were evicted, the newest are still duplicates
+	assert.True(t, cache.reserve("id0", 1000, 10_000, 3))
+	assert.False(t, cache.reserve("id4", 1000, 10_000, 3))
+}
+
+func Test_getDedupWindowMs(t *testing.T) {
+	dedupCfg := config.DedupConfig{
+		Enabled:         true,
+		WindowSecs:      60,
+		IndexWindowSecs: map[string]uint64{"logs": 5},
+	}
+	assert.Equal(t, uint64(5_000), getDedupWindowMs(dedupCfg, "logs"))
+	assert.Equal(t, uint64(60_000), getDedupWindowMs(dedupCfg, "other"))
+
+	dedupCfg.WindowSecs = 0
+	assert.Equal(t, uint64(DEFAULT_DEDUP_WINDOW_SECS*1000), getDedupWindowMs(dedupCfg, "other"))
+}
+
+func Test_reserveDocumentId(t *testing.T) {
+	config.InitializeTestingConfig()
+	runningConfig := config.GetRunningConfig()
+	defer func() {
+		runningConfig.Dedup.Enabled = false
+		config.SetConfig(*runningConfig)
+	}()
+
+	// without dedup every document is ingested
+	assert.True(t, reserveDocumentId("dedup-test", "1", 1000))
+	assert.True(t, reserveDocumentId("dedup-test", "1", 1000))
+
+	runningConfig.Dedup.Enabled = true
+	runningConfig.Dedup.IndexWindowSecs = map[string]uint64{"dedup-test": 1}
+	config.SetConfig(*runningConfig)
+
+	assert.True(t, reserveDocumentId("dedup-test", "1", 1000))
+	assert.False(t, reserveDocumentId("dedup-test", "1", 1500))
+	assert.True(t, reserveDocumentId("dedup-test-other", "1", 1500))
+	assert.True(t, reserveDocumentId("dedup-test", "", 1500))
+	assert.True(t, reserveDocumentId("dedup-test", "1", 2000))
+
+	releaseDocumentId("dedup-test", "1")
+	assert.True(t, reserveDocumentId("dedup-test", "1", 2100))
+}
@@ -26,6 +26,7 @@ import (
 	jp "github.com/buger/jsonparser"
 	"github.com/google/uuid"
 	jsoniter "github.com/json-iterator/go"
+	"github.com/siglens/siglens/pkg/apikeys"
 	"github.com/siglens/siglens/pkg/config"
 	"github.com/siglens/siglens/pkg/segment/query/metadata"
 	segment "github.com/siglens/siglens/pkg/segment/utils"
@@ -98,8 +99,12 @@ func HandleBulkBody(postBody []byte, ctx *fasthttp.RequestCtx, myid uint64, kiba
 	var items = make([]interface{}, 0)
 	atleastOneSuccess := false
 	localIndexMap := make(map[string]string)
+	// nil when the request can write to every index
+	writeFilter := apikeys.GetIndexFilter(ctx, apikeys.ACCESS_WRITE)
 	for scanner.Scan() {
 		inCount++
+		isDuplicate := false
+		isForbidden := false
 		esAction, indexName, idVal := extractIndexAndValidateAction(scanner.Bytes())
 		switch esAction {
 
@@ -108,8 +113,11 @@ func HandleBulkBody(postBody []byte, ctx *fasthttp.RequestCtx, myid uint64, kiba
 			rawJson := scanner.Bytes()
 			numBytes := len(rawJson)
 			bytesReceived += numBytes
-			//update only if body is less than MAX_RECORD_SIZE
-			if numBytes < segment.MAX_RECORD_SIZE {
+			if writeFilter != nil && !writeFilter(getRealIndexName(indexName, myid)) {
+				success = false
+				isForbidden = true
+			} else if numBytes < segment.MAX_RECORD_SIZE {
+				//update only if body is less than MAX_RECORD_SIZE
 				processedCount++
 				success = true
 				if strings.Contains(indexName, ".kibana") {
@@ -130,9 +138,16 @@ func HandleBulkBody(postBody []byte, ctx *fasthttp.RequestCtx, myid uint64, kiba
 						success = false
 					}
 				} else {
-					err := ProcessIndexRequest(rawJson, tsNow, indexName, uint64(numBytes), false, localIndexMap, myid)
-					if err != nil {
-						success = false
+					realIndexName := addAndGetRealIndexName(indexName, localIndexMap, myid)
+					if !reserveDocumentId(realIndexName, idVal, tsNow) {
+						// already ingested within the dedup window, most likely a retry by the shipper
+						isDuplicate = true
+					} else {
+						err := ProcessIndexRequest(rawJson, tsNow, indexName, uint64(numBytes), false, localIndexMap, myid)
+						if err != nil {
+							success = false
+							releaseDocumentId(realIndexName, idVal)
+						}
 					}
 				}
 			} else {
@@ -148,7 +163,23 @@ func HandleBulkBody(postBody []byte, ctx *fasthttp.RequestCtx, myid uint64, kiba
 		}
 
 		responsebody := make(map[string]interface{})
-		if !success {
+		if isDuplicate {
+			atleastOneSuccess = true
+			statusbody := make(map[string]interface{})
+			statusbody["status"] = 200
+			statusbody["result"] = "noop"
+			statusbody["_id"] = idVal
+			responsebody["index"] = statusbody
+			items = append(items, responsebody)
+		} else if isForbidden {
+			overallError = true
+			error_response := utils.BulkErrorResponse{
+				ErrorResponse: *utils.NewBulkErrorResponseInfo("the api key can not write to index "+indexName, "security_exception"),
+			}
+			responsebody["index"] = error_response
+			responsebody["status"] = 403
+			items = append(items, responsebody)
+		} else if !success {
 			if maxRecordSizeExceeded {
 				error_response := utils.BulkErrorResponse{
 					ErrorResponse: *utils.NewBulkErrorResponseInfo("request entity too large", "request_entity_exception"),
@@ -230,6 +261,14 @@ func extractIndexAndValidateAction(rawJson []byte) (int, string, string) {
 	return DELETE, "eventType", ""
 }
 
+// Returns the index the alias points to, or the index name if it is not an alias
+func getRealIndexName(indexNameIn string, myid uint64) string {
+	if pres, idxName := vtable.IsAlias(indexNameIn, myid); pres {
+		return idxName
+	}
+	return indexNameIn
+}
+
 func addAndGetRealIndexName(indexNameIn string, localIndexMap map[string]string, myid uint64) string {
 
 	// first check localCopy of map, if it exists then avoid the lock inside vtables.
@@ -275,6 +314,12 @@ func ProcessIndexRequest(rawJson []byte, tsNow uint64, indexNameIn string,
 	}
 	streamid := utils.CreateStreamId(indexNameConverted, myid)
 
+	if docType == segment.SIGNAL_EVENTS {
+		if rule := getMultilineRule(indexNameConverted); rule != nil {
+			return processMultilineRecord(rule, rawJson, ts_millis, streamid, indexNameConverted, bytesReceived, flush, myid)
+		}
+	}
+
 	// TODO: we used to add _index in the json_source doc, since it is needed during
 	// json-rsponse formation during query-resp. We should either add it in this AddEntryToInMemBuf
 	// OR in json-resp creation we add it in the resp using the vtable name
@@ -300,6 +345,9 @@ func ProcessPutIndex(ctx *fasthttp.RequestCtx, myid uint64) {
 	r := string(ctx.PostBody())
 	indexName := ctx.UserValue("indexName").(string)
 
+	if !apikeys.AuthorizeIndexAccess(ctx, indexName, apikeys.ACCESS_ADMIN) {
+		return
+	}
 	log.Infof("ProcessPutIndex: adding index and mapping: indexName=%v", indexName)
 
 	err := vtable.AddVirtualTableAndMapping(&indexName, &r, myid)
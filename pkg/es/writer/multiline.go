@@ -0,0 +1,253 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package writer
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	jp "github.com/buger/jsonparser"
+	"github.com/siglens/siglens/pkg/config"
+	segment "github.com/siglens/siglens/pkg/segment/utils"
+	"github.com/siglens/siglens/pkg/segment/writer"
+	log "github.com/sirupsen/logrus"
+)
+
+const DEFAULT_MULTILINE_FIELD = "message"
+const DEFAULT_MULTILINE_MAX_LINES = 500
+const DEFAULT_MULTILINE_MAX_WAIT_MS = 2000
+const MULTILINE_FLUSH_INTERVAL = 100 * time.Millisecond
+
+type multilineRule struct {
+	fieldKeys    []string
+	groupByKeys  []string
+	startPattern *regexp.Regexp
+	maxLines     uint64
+	maxWaitMs    uint64
+}
+
+// An event whose first line was seen and that may still get more lines appended to it
+type pendingMultilineEvent struct {
+	rawJson       []byte // the first line's record, its field gets replaced by all lines when completed
+	lines         []string
+	tsMillis      uint64
+	streamid      string
+	indexName     string
+	bytesReceived uint64
+	myid          uint64
+	lastUpdatedMs uint64
+	maxWaitMs     uint64
+}
+
+type multilineMerger struct {
+	lock    sync.Mutex
+	pending map[string]*pendingMultilineEvent // group key to the event being merged
+}
+
+var multilineRulesOnce sync.Once
+var multilineRules map[string]*multilineRule // index name to its rule
+
+var globalMultilineMerger = &multilineMerger{pending: make(map[string]*pendingMultilineEvent)}
+
+func initMultilineRules() {
+	multilineRules = make(map[string]*multilineRule)
+	for _, ruleCfg := range config.GetMultilineRules() {
+		rule, err := newMultilineRule(ruleCfg)
+		if err != nil {
+			log.Errorf("initMultilineRules: skipping multiline rule for index %v, err: %v", ruleCfg.Index, err)
+			continue
+		}
+		multilineRules[ruleCfg.Index] = rule
+	}
+	if len(multilineRules) > 0 {
+		go multilineFlushLooper()
+	}
+}
+
+func newMultilineRule(ruleCfg config.MultilineRule) (*multilineRule, error) {
+	startPattern, err := regexp.Compile(ruleCfg.StartPattern)
+	if err != nil {
+		return nil, err
+	}
+	rule := &multilineRule{
+		fieldKeys:    strings.Split(ruleCfg.Field, "."),
+		startPattern: startPattern,
+		maxLines:     ruleCfg.MaxLines,
+		maxWaitMs:    ruleCfg.MaxWaitMs,
+	}
+	if ruleCfg.Field == "" {
+		rule.fieldKeys = []string{DEFAULT_MULTILINE_FIELD}
+	}
+	if ruleCfg.GroupBy != "" {
+		rule.groupByKeys = strings.Split(ruleCfg.GroupBy, ".")
+	}
+	if rule.maxLines == 0 {
+		rule.maxLines = DEFAULT_MULTILINE_MAX_LINES
+	}
+	if rule.maxWaitMs == 0 {
+		rule.maxWaitMs = DEFAULT_MULTILINE_MAX_WAIT_MS
+	}
+	return rule, nil
+}
+
+func getMultilineRule(indexName string) *multilineRule {
+	multilineRulesOnce.Do(initMultilineRules)
+	return multilineRules[indexName]
+}
+
+func multilineFlushLooper() {
+	for {
+		time.Sleep(MULTILINE_FLUSH_INTERVAL)
+		for _, event := range globalMultilineMerger.removeExpired(uint64(time.Now().UnixMilli())) {
+			err := writeMultilineEvent(event, false)
+			if err != nil {
+				log.Errorf("multilineFlushLooper: failed to write multiline event for index %v, err: %v", event.indexName, err)
+			}
+		}
+	}
+}
+
+// Adds a record of an index with a multiline rule. Events completed by this record are written right away,
+// the others are written once they complete or time out.
+func processMultilineRecord(rule *multilineRule, rawJson []byte, tsMillis uint64, streamid string, indexName string,
+	bytesReceived uint64, flush bool, myid uint64) error {
+
+	completed := globalMultilineMerger.add(rule, rawJson, tsMillis, streamid, indexName, bytesReceived, flush, myid,
+		uint64(time.Now().UnixMilli()))
+	for i, event := range completed {
+		err := writeMultilineEvent(event, flush && i == len(completed)-1)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+Returns the events completed by adding the record, in the order they have to be written.
+
+A record whose field matches the start pattern completes the pending event of its group and becomes the new
+pending event. Any other record is a continuation line and is appended to the pending event, keeping only its
+field. A continuation line without a pending event, e.g. the rest of a stack trace whose first line was sent
+before a restart, starts a new pending event so that the lines after it are still merged. Records without a
+string field are not merged.
+*/
+func (mm *multilineMerger) add(rule *multilineRule, rawJson []byte, tsMillis uint64, streamid string, indexName string,
+	bytesReceived uint64, flush bool, myid uint64, nowMs uint64) []*pendingMultilineEvent {
+
+	groupKey := streamid
+	if len(rule.groupByKeys) > 0 {
+		groupVal, _, _, err := jp.Get(rawJson, rule.groupByKeys...)
+		if err == nil {
+			groupKey = streamid + "|" + string(groupVal)
+		}
+	}
+
+	completed := make([]*pendingMultilineEvent, 0, 2)
+
+	mm.lock.Lock()
+	defer mm.lock.Unlock()
+
+	pending := mm.pending[groupKey]
+	line, err := jp.GetString(rawJson, rule.fieldKeys...)
+	isStart := err == nil && rule.startPattern.MatchString(line)
+
+	if err == nil && !isStart && pending != nil {
+		pending.lines = append(pending.lines, line)
+		pending.bytesReceived += bytesReceived
+		pending.lastUpdatedMs = nowMs
+		if flush || uint64(len(pending.lines)) >= rule.maxLines {
+			delete(mm.pending, groupKey)
+			completed = append(completed, pending)
+		}
+		return completed
+	}
+
+	if pending != nil {
+		delete(mm.pending, groupKey)
+		completed = append(completed, pending)
+	}
+
+	event := &pendingMultilineEvent{
+		rawJson:       append([]byte(nil), rawJson...),
+		tsMillis:      tsMillis,
+		streamid:      streamid,
+		indexName:     indexName,
+		bytesReceived: bytesReceived,
+		myid:          myid,
+		lastUpdatedMs: nowMs,
+		maxWaitMs:     rule.maxWaitMs,
+	}
+	if err != nil || flush || rule.maxLines <= 1 {
+		completed = append(completed, event)
+		return completed
+	}
+	event.lines = []string{line}
+	mm.pending[groupKey] = event
+	return completed
+}
+
+// Removes and returns the pending events that got no new line within their max wait
+func (mm *multilineMerger) removeExpired(nowMs uint64) []*pendingMultilineEvent {
+	mm.lock.Lock()
+	defer mm.lock.Unlock()
+
+	expired := make([]*pendingMultilineEvent, 0)
+	for groupKey, event := range mm.pending {
+		if nowMs-event.lastUpdatedMs >= event.maxWaitMs {
+			delete(mm.pending, groupKey)
+			expired = append(expired, event)
+		}
+	}
+	return expired
+}
+
+// Returns the record of the event with the merged lines in its field
+func (event *pendingMultilineEvent) getMergedJson(fieldKeys []string) ([]byte, error) {
+	if len(event.lines) <= 1 {
+		return event.rawJson, nil
+	}
+	mergedLines, err := json.Marshal(strings.Join(event.lines, "\n"))
+	if err != nil {
+		return nil, err
+	}
+	return jp.Set(event.rawJson, mergedLines, fieldKeys...)
+}
+
+func writeMultilineEvent(event *pendingMultilineEvent, flush bool) error {
+	rule := getMultilineRule(event.indexName)
+	rawJson := event.rawJson
+	if rule != nil {
+		mergedJson, err := event.getMergedJson(rule.fieldKeys)
+		if err != nil {
+			log.Errorf("writeMultilineEvent: failed to merge lines for index %v, err: %v", event.indexName, err)
+			return err
+		}
+		rawJson = mergedJson
+	}
+
+	err := writer.AddEntryToInMemBuf(event.streamid, rawJson, event.tsMillis, event.indexName, event.bytesReceived,
+		flush, segment.SIGNAL_EVENTS, event.myid)
+	if err != nil {
+		log.Errorf("writeMultilineEvent: failed to add entry to in mem buffer, err=%v", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package writer
+
+import (
+	"testing"
+
+	jp "github.com/buger/jsonparser"
+	"github.com/siglens/siglens/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_multilineMerger(t *testing.T) {
+	rule, err := newMultilineRule(config.MultilineRule{
+		Index:        "java",
+		StartPattern: `^\d{4}-\d{2}-\d{2}`,
+		MaxLines:     4,
+		MaxWaitMs:    1000,
+		GroupBy:      "host",
+	})
+	assert.Nil(t, err)
+	mm := &multilineMerger{pending: make(map[string]*pendingMultilineEvent)}
+
+	add := func(rawJson string, nowMs uint64) []*pendingMultilineEvent {
+		return mm.add(rule, []byte(rawJson), 1, "stream", "java", uint64(len(rawJson)), false, 0, nowMs)
+	}
+
+	assert.Len(t, add(`{"host": "a", "message": "2023-01-01 ERROR failed", "level": "error"}`, 0), 0)
+	assert.Len(t, add(`{"host": "a", "message": "java.lang.NullPointerException"}`, 10), 0)
+	// lines of another host are not merged into host a's event
+	assert.Len(t, add(`{"host": "b", "message": "2023-01-01 INFO ok"}`, 20), 0)
+	assert.Len(t, add(`{"host": "a", "message": "\tat Foo.bar(Foo.java:10)"}`, 30), 0)
+
+	completed := add(`{"host": "a", "message": "2023-01-01 INFO next"}`, 40)
+	assert.Len(t, completed, 1)
+	mergedJson, err := completed[0].getMergedJson(rule.fieldKeys)
+	assert.Nil(t, err)
+	message, err := jp.GetString(mergedJson, "message")
+	assert.Nil(t, err)
+	assert.Equal(t, "2023-01-01 ERROR failed\njava.lang.NullPointerException\n\tat Foo.bar(Foo.java:10)", message)
+	level, err := jp.GetString(mergedJson, "level")
+	assert.Nil(t, err)
+	assert.Equal(t, "error", level)
+
+	// completed once max lines is reached
+	assert.Len(t, add(`{"host": "a", "message": "line 2"}`, 50), 0)
+	assert.Len(t, add(`{"host": "a", "message": "line 3"}`, 60), 0)
+	completed = add(`{"host": "a", "message": "line 4"}`, 70)
+	assert.Len(t, completed, 1)
+	assert.Len(t, completed[0].lines, 4)
+
+	// records without the field are written as they are
+	completed = add(`{"host": "a", "other": 1}`, 80)
+	assert.Len(t, completed, 1)
+	assert.Nil(t, completed[0].lines)
+
+	// host b's event times out
+	assert.Len(t, mm.removeExpired(500), 0)
+	expired := mm.removeExpired(1020)
+	assert.Len(t, expired, 1)
+	mergedJson, err = expired[0].getMergedJson(rule.fieldKeys)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"host": "b", "message": "2023-01-01 INFO ok"}`, string(mergedJson))
+	assert.Len(t, mm.pending, 0)
+}
+
+func Test_multilineMergerOrphanLines(t *testing.T) {
+	rule, err := newMultilineRule(config.MultilineRule{
+		Index:        "java",
+		StartPattern: `^\d{4}-\d{2}-\d{2}`,
+	})
+	assert.Nil(t, err)
+	mm := &multilineMerger{pending: make(map[string]*pendingMultilineEvent)}
+
+	add := func(rawJson string) []*pendingMultilineEvent {
+		return mm.add(rule, []byte(rawJson), 1, "stream", "java", uint64(len(rawJson)), false, 0, 0)
+	}
+
+	// continuation lines without a start line are merged into an event of their own
+	assert.Len(t, add(`{"message": "\tat Foo.bar(Foo.java:10)"}`), 0)
+	assert.Len(t, add(`{"message": "\tat Foo.main(Foo.java:3)"}`), 0)
+
+	completed := add(`{"message": "2023-01-01 INFO next"}`)
+	assert.Len(t, completed, 1)
+	mergedJson, err := completed[0].getMergedJson(rule.fieldKeys)
+	assert.Nil(t, err)
+	message, err := jp.GetString(mergedJson, "message")
+	assert.Nil(t, err)
+	assert.Equal(t, "\tat Foo.bar(Foo.java:10)\n\tat Foo.main(Foo.java:3)", message)
+	assert.Len(t, mm.pending, 1)
+}
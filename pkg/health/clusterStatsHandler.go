@@ -20,7 +20,6 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"sort"
 	"strconv"
 	"strings"
 
@@ -196,23 +195,42 @@ func getIngestionStats(myid uint64) (map[string]utils.ResultPerIndex, int64, flo
 	totalOnDiskBytes := float64(0)
 
 	ingestionStats := make(map[string]utils.ResultPerIndex)
-	allVirtualTableNames, err := vtable.GetVirtualTableNames(myid)
-	sortedIndices := make([]string, 0, len(allVirtualTableNames))
+	for indexName, counts := range getIndexStoredCounts(myid) {
+		totalEventCount += int64(counts.eventCount)
+		totalIncomingBytes += float64(counts.incomingBytes)
+		totalOnDiskBytes += float64(counts.onDiskBytes)
+
+		perIndexStat := make(map[string]map[string]interface{})
+
+		perIndexStat[indexName] = make(map[string]interface{})
+
+		perIndexStat[indexName]["ingestVolume"] = float64(counts.incomingBytes)
+		perIndexStat[indexName]["eventCount"] = counts.eventCount
 
-	for k := range allVirtualTableNames {
-		sortedIndices = append(sortedIndices, k)
+		ingestionStats[indexName] = perIndexStat
 	}
-	sort.Strings(sortedIndices)
+	return ingestionStats, totalEventCount, totalIncomingBytes, totalOnDiskBytes
+}
 
+type indexStoredCounts struct {
+	eventCount    uint64
+	incomingBytes uint64
+	onDiskBytes   uint64
+}
+
+// Returns the events and bytes stored for each index of the org, including the ones of the unrotated segments
+func getIndexStoredCounts(myid uint64) map[string]*indexStoredCounts {
+	allVirtualTableNames, err := vtable.GetVirtualTableNames(myid)
 	if err != nil {
-		log.Errorf("getIngestionStats: Error in getting virtual table names, err:%v", err)
+		log.Errorf("getIndexStoredCounts: Error in getting virtual table names, err:%v", err)
 	}
 
 	allvtableCnts := segwriter.GetVTableCountsForAll(myid)
 
-	for _, indexName := range sortedIndices {
+	storedCounts := make(map[string]*indexStoredCounts, len(allVirtualTableNames))
+	for indexName := range allVirtualTableNames {
 		if indexName == "" {
-			log.Errorf("getIngestionStats: skipping an empty index name indexName=%v", indexName)
+			log.Errorf("getIndexStoredCounts: skipping an empty index name indexName=%v", indexName)
 			continue
 		}
 
@@ -223,25 +241,13 @@ func getIngestionStats(myid uint64) (map[string]utils.ResultPerIndex, int64, flo
 
 		unrotatedByteCount, unrotatedEventCount, unrotatedOnDiskBytesCount := segwriter.GetUnrotatedVTableCounts(indexName, myid)
 
-		totalEventsForIndex := uint64(cnts.RecordCount) + uint64(unrotatedEventCount)
-		totalEventCount += int64(totalEventsForIndex)
-
-		totalBytesReceivedForIndex := float64(cnts.BytesCount + unrotatedByteCount)
-		totalIncomingBytes += totalBytesReceivedForIndex
-
-		totalOnDiskBytesCountForIndex := uint64(cnts.OnDiskBytesCount + unrotatedOnDiskBytesCount)
-		totalOnDiskBytes += float64(totalOnDiskBytesCountForIndex)
-
-		perIndexStat := make(map[string]map[string]interface{})
-
-		perIndexStat[indexName] = make(map[string]interface{})
-
-		perIndexStat[indexName]["ingestVolume"] = totalBytesReceivedForIndex
-		perIndexStat[indexName]["eventCount"] = totalEventsForIndex
-
-		ingestionStats[indexName] = perIndexStat
+		storedCounts[indexName] = &indexStoredCounts{
+			eventCount:    uint64(cnts.RecordCount) + uint64(unrotatedEventCount),
+			incomingBytes: cnts.BytesCount + unrotatedByteCount,
+			onDiskBytes:   cnts.OnDiskBytesCount + unrotatedOnDiskBytesCount,
+		}
 	}
-	return ingestionStats, totalEventCount, totalIncomingBytes, totalOnDiskBytes
+	return storedCounts
 }
 
 func convertBytesToGB(bytes float64) string {
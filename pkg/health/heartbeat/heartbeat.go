@@ -0,0 +1,90 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package heartbeat
+
+import (
+	"sync"
+	"time"
+
+	"github.com/siglens/siglens/pkg/utils"
+)
+
+// The background loops of the server record every run here, so that the health endpoints can tell if they stopped or fail
+
+const (
+	SEGMENT_FLUSHER   = "segmentFlusher"
+	RETENTION_SWEEPER = "retentionSweeper"
+	BLOB_UPLOADER     = "blobUploader"
+	METRICS_STORE     = "metricsStore"
+	INTEGRITY_CHECKER = "integrityChecker"
+)
+
+type Beat struct {
+	IntervalMs uint64 // how often the loop runs
+	StartedMs  uint64 // when the loop started
+	LastRunMs  uint64 // 0 until the first run
+	LastErr    string // error of the last run, empty if it succeeded
+}
+
+var beatsLock sync.RWMutex
+var allBeats = make(map[string]*Beat)
+
+// Called when a loop starts, interval is how often it runs, including the wait before its first run
+func Register(name string, interval time.Duration) {
+	beatsLock.Lock()
+	defer beatsLock.Unlock()
+	allBeats[name] = &Beat{
+		IntervalMs: uint64(interval.Milliseconds()),
+		StartedMs:  utils.GetCurrentTimeInMs(),
+	}
+}
+
+// Called by the loops whose interval can be changed at runtime, e.g. by a config reload, before they wait for their next run
+func SetInterval(name string, interval time.Duration) {
+	beatsLock.Lock()
+	defer beatsLock.Unlock()
+	if beat, ok := allBeats[name]; ok {
+		beat.IntervalMs = uint64(interval.Milliseconds())
+	}
+}
+
+// Called after every run of a registered loop, err is the error of the run or nil
+func Record(name string, err error) {
+	beatsLock.Lock()
+	defer beatsLock.Unlock()
+	beat, ok := allBeats[name]
+	if !ok {
+		return
+	}
+	beat.LastRunMs = utils.GetCurrentTimeInMs()
+	if err != nil {
+		beat.LastErr = err.Error()
+	} else {
+		beat.LastErr = ""
+	}
+}
+
+// Returns a copy of the beats of all the registered loops
+func GetAll() map[string]Beat {
+	beatsLock.RLock()
+	defer beatsLock.RUnlock()
+	retVal := make(map[string]Beat, len(allBeats))
+	for name, beat := range allBeats {
+		retVal[name] = *beat
+	}
+	return retVal
+}
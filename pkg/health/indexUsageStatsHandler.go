@@ -0,0 +1,211 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/siglens/siglens/pkg/apikeys"
+	. "github.com/siglens/siglens/pkg/segment/utils"
+	"github.com/siglens/siglens/pkg/usageStats"
+	"github.com/siglens/siglens/pkg/utils"
+	log "github.com/sirupsen/logrus"
+	"github.com/valyala/fasthttp"
+)
+
+const DEFAULT_INDEX_USAGE_RANGE_MS = 7 * MS_IN_DAY
+
+// Ranges up to this long are bucketed hourly when the request does not set the granularity
+const MAX_DEFAULT_HOURLY_RANGE_MS = 2 * MS_IN_DAY
+
+type IndexUsageStats struct {
+	IndexName      string                   `json:"indexName"`
+	EventCount     uint64                   `json:"eventCount"`    // stored now
+	IncomingBytes  uint64                   `json:"incomingBytes"` // raw bytes of the stored events
+	OnDiskBytes    uint64                   `json:"onDiskBytes"`
+	IngestedBytes  uint64                   `json:"ingestedBytes"` // in the time range
+	IngestedEvents uint64                   `json:"ingestedEvents"`
+	BytesPerSec    float64                  `json:"bytesPerSec"` // averaged over the time range
+	EventsPerSec   float64                  `json:"eventsPerSec"`
+	QueryCount     uint64                   `json:"queryCount"`
+	Usage          []*usageStats.IndexUsage `json:"usage"`
+}
+
+type IndexUsageStatsResponse struct {
+	StartEpoch  uint64             `json:"startEpoch"`
+	EndEpoch    uint64             `json:"endEpoch"`
+	Granularity string             `json:"granularity"`
+	Indices     []*IndexUsageStats `json:"indices"`
+}
+
+type indexUsageStatsRequest struct {
+	startEpoch  uint64
+	endEpoch    uint64
+	granularity usageStats.UsageStatsGranularity
+	indexNames  map[string]struct{} // nil for all the indices
+}
+
+/*
+Handles /api/usageStats/indices. The startEpoch and endEpoch query parameters are in ms and default to the last 7
+days, granularity is hour or day, and indexName takes a comma separated list of indices to limit the response to
+*/
+func ProcessIndexUsageStatsHandler(ctx *fasthttp.RequestCtx, myid uint64) {
+	request, err := parseIndexUsageStatsRequest(ctx.QueryArgs(), utils.GetCurrentTimeInMs())
+	if err != nil {
+		log.Errorf("ProcessIndexUsageStatsHandler: invalid request, err=%v", err)
+		setBadRequestMsg(ctx, err.Error())
+		return
+	}
+
+	allUsage, err := usageStats.GetIndexUsageStats(request.startEpoch, request.endEpoch, request.granularity, myid)
+	if err != nil {
+		log.Errorf("ProcessIndexUsageStatsHandler: could not get the index usage, err=%v", err)
+		setBadRequestMsg(ctx, err.Error())
+		return
+	}
+
+	indexFilter := apikeys.GetIndexFilter(ctx, apikeys.ACCESS_READ)
+	isRequested := func(indexName string) bool {
+		if indexFilter != nil && !indexFilter(indexName) {
+			return false
+		}
+		_, ok := request.indexNames[indexName]
+		return request.indexNames == nil || ok
+	}
+
+	httpResp := &IndexUsageStatsResponse{
+		StartEpoch:  request.startEpoch,
+		EndEpoch:    request.endEpoch,
+		Granularity: getGranularityName(request.granularity),
+		Indices:     makeIndexUsageStats(getIndexStoredCounts(myid), allUsage, request, isRequested),
+	}
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	utils.WriteJsonResponse(ctx, httpResp)
+}
+
+// Combines the stored counts of the indices with their usage in the time range, sorted by index name
+func makeIndexUsageStats(storedCounts map[string]*indexStoredCounts, allUsage map[string][]*usageStats.IndexUsage,
+	request *indexUsageStatsRequest, isRequested func(indexName string) bool) []*IndexUsageStats {
+
+	allStats := make(map[string]*IndexUsageStats)
+	getStats := func(indexName string) *IndexUsageStats {
+		stats, ok := allStats[indexName]
+		if !ok {
+			stats = &IndexUsageStats{IndexName: indexName, Usage: make([]*usageStats.IndexUsage, 0)}
+			allStats[indexName] = stats
+		}
+		return stats
+	}
+
+	for indexName, counts := range storedCounts {
+		if !isRequested(indexName) {
+			continue
+		}
+		stats := getStats(indexName)
+		stats.EventCount = counts.eventCount
+		stats.IncomingBytes = counts.incomingBytes
+		stats.OnDiskBytes = counts.onDiskBytes
+	}
+
+	// the usage of deleted indices is still reported for the time range
+	rangeSecs := float64(request.endEpoch-request.startEpoch) / 1000
+	for indexName, usage := range allUsage {
+		if !isRequested(indexName) {
+			continue
+		}
+		stats := getStats(indexName)
+		stats.Usage = usage
+		for _, bucket := range usage {
+			stats.IngestedBytes += bucket.BytesCount
+			stats.IngestedEvents += bucket.EventCount
+			stats.QueryCount += bucket.QueryCount
+		}
+		if rangeSecs > 0 {
+			stats.BytesPerSec = float64(stats.IngestedBytes) / rangeSecs
+			stats.EventsPerSec = float64(stats.IngestedEvents) / rangeSecs
+		}
+	}
+
+	retVal := make([]*IndexUsageStats, 0, len(allStats))
+	for _, stats := range allStats {
+		retVal = append(retVal, stats)
+	}
+	sort.Slice(retVal, func(i, j int) bool {
+		return retVal[i].IndexName < retVal[j].IndexName
+	})
+	return retVal
+}
+
+func parseIndexUsageStatsRequest(args *fasthttp.Args, nowMs uint64) (*indexUsageStatsRequest, error) {
+	request := &indexUsageStatsRequest{endEpoch: nowMs}
+	var err error
+	if endStr := string(args.Peek("endEpoch")); endStr != "" {
+		request.endEpoch, err = strconv.ParseUint(endStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid endEpoch %v", endStr)
+		}
+	}
+	if request.endEpoch > DEFAULT_INDEX_USAGE_RANGE_MS {
+		request.startEpoch = request.endEpoch - DEFAULT_INDEX_USAGE_RANGE_MS
+	}
+	if startStr := string(args.Peek("startEpoch")); startStr != "" {
+		request.startEpoch, err = strconv.ParseUint(startStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid startEpoch %v", startStr)
+		}
+	}
+	if request.startEpoch > request.endEpoch {
+		return nil, fmt.Errorf("startEpoch %v is after endEpoch %v", request.startEpoch, request.endEpoch)
+	}
+
+	switch granularity := string(args.Peek("granularity")); granularity {
+	case "hour":
+		request.granularity = usageStats.Hourly
+	case "day":
+		request.granularity = usageStats.Daily
+	case "":
+		request.granularity = usageStats.Daily
+		if request.endEpoch-request.startEpoch <= MAX_DEFAULT_HOURLY_RANGE_MS {
+			request.granularity = usageStats.Hourly
+		}
+	default:
+		return nil, fmt.Errorf("invalid granularity %v, it should be hour or day", granularity)
+	}
+
+	if indexNames := string(args.Peek("indexName")); indexNames != "" {
+		request.indexNames = make(map[string]struct{})
+		for _, indexName := range strings.Split(indexNames, ",") {
+			request.indexNames[strings.TrimSpace(indexName)] = struct{}{}
+		}
+	}
+	return request, nil
+}
+
+func getGranularityName(granularity usageStats.UsageStatsGranularity) string {
+	if granularity == usageStats.Hourly {
+		return "hour"
+	}
+	return "day"
+}
+
+func setBadRequestMsg(ctx *fasthttp.RequestCtx, msg string) {
+	ctx.SetStatusCode(fasthttp.StatusBadRequest)
+	utils.WriteResponse(ctx, utils.HttpServerResponse{Message: msg, StatusCode: fasthttp.StatusBadRequest})
+}
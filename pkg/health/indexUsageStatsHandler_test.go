@@ -0,0 +1,101 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"testing"
+
+	. "github.com/siglens/siglens/pkg/segment/utils"
+	"github.com/siglens/siglens/pkg/usageStats"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func parseTestArgs(query string) *fasthttp.Args {
+	args := &fasthttp.Args{}
+	args.Parse(query)
+	return args
+}
+
+func Test_parseIndexUsageStatsRequest(t *testing.T) {
+	nowMs := uint64(1_700_000_000_000)
+	request, err := parseIndexUsageStatsRequest(parseTestArgs(""), nowMs)
+	assert.Nil(t, err)
+	assert.Equal(t, nowMs-7*MS_IN_DAY, request.startEpoch)
+	assert.Equal(t, nowMs, request.endEpoch)
+	assert.Equal(t, usageStats.Daily, request.granularity)
+	assert.Nil(t, request.indexNames)
+
+	// short ranges default to hourly buckets
+	request, err = parseIndexUsageStatsRequest(parseTestArgs("startEpoch=1699990000000&indexName=a,%20b"), nowMs)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1699990000000), request.startEpoch)
+	assert.Equal(t, usageStats.Hourly, request.granularity)
+	assert.Equal(t, map[string]struct{}{"a": {}, "b": {}}, request.indexNames)
+
+	request, err = parseIndexUsageStatsRequest(parseTestArgs("startEpoch=1000&endEpoch=2000&granularity=day"), nowMs)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2000), request.endEpoch)
+	assert.Equal(t, usageStats.Daily, request.granularity)
+
+	for _, query := range []string{"startEpoch=abc", "endEpoch=-1", "startEpoch=3000&endEpoch=2000", "granularity=week"} {
+		_, err = parseIndexUsageStatsRequest(parseTestArgs(query), nowMs)
+		assert.NotNil(t, err, query)
+	}
+}
+
+func Test_makeIndexUsageStats(t *testing.T) {
+	storedCounts := map[string]*indexStoredCounts{
+		"idx-a":  {eventCount: 30, incomingBytes: 3000, onDiskBytes: 300},
+		"idx-b":  {eventCount: 10, incomingBytes: 1000, onDiskBytes: 100},
+		"hidden": {eventCount: 1, incomingBytes: 1, onDiskBytes: 1},
+	}
+	allUsage := map[string][]*usageStats.IndexUsage{
+		"idx-a": {
+			{StartEpoch: 0, BytesCount: 1000, EventCount: 10, QueryCount: 2},
+			{StartEpoch: MS_IN_HOUR, BytesCount: 2000, EventCount: 20, QueryCount: 1},
+		},
+		// deleted since
+		"idx-old": {
+			{StartEpoch: 0, BytesCount: 500, EventCount: 5},
+			{StartEpoch: MS_IN_HOUR},
+		},
+	}
+	request := &indexUsageStatsRequest{startEpoch: 0, endEpoch: 2 * MS_IN_HOUR, granularity: usageStats.Hourly}
+	isRequested := func(indexName string) bool { return indexName != "hidden" }
+
+	allStats := makeIndexUsageStats(storedCounts, allUsage, request, isRequested)
+	assert.Len(t, allStats, 3)
+	assert.Equal(t, "idx-a", allStats[0].IndexName)
+	assert.Equal(t, uint64(30), allStats[0].EventCount)
+	assert.Equal(t, uint64(3000), allStats[0].IncomingBytes)
+	assert.Equal(t, uint64(300), allStats[0].OnDiskBytes)
+	assert.Equal(t, uint64(3000), allStats[0].IngestedBytes)
+	assert.Equal(t, uint64(30), allStats[0].IngestedEvents)
+	assert.Equal(t, uint64(3), allStats[0].QueryCount)
+	assert.InDelta(t, 3000.0/7200, allStats[0].BytesPerSec, 1e-9)
+	assert.InDelta(t, 30.0/7200, allStats[0].EventsPerSec, 1e-9)
+	assert.Len(t, allStats[0].Usage, 2)
+
+	assert.Equal(t, "idx-b", allStats[1].IndexName)
+	assert.Equal(t, uint64(0), allStats[1].IngestedBytes)
+	assert.Len(t, allStats[1].Usage, 0)
+
+	assert.Equal(t, "idx-old", allStats[2].IndexName)
+	assert.Equal(t, uint64(0), allStats[2].EventCount)
+	assert.Equal(t, uint64(500), allStats[2].IngestedBytes)
+}
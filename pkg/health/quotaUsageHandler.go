@@ -0,0 +1,36 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"github.com/siglens/siglens/pkg/usageStats"
+	"github.com/siglens/siglens/pkg/utils"
+	"github.com/valyala/fasthttp"
+)
+
+// Returns the bytes of the logs and metrics the org has on disk, which the stored bytes quota is enforced against
+func GetStoredBytes(myid uint64) uint64 {
+	_, _, _, logsOnDiskBytes := getIngestionStats(myid)
+	_, _, metricsOnDiskBytes := getMetricsStats(myid)
+	return uint64(logsOnDiskBytes) + metricsOnDiskBytes
+}
+
+// Handles /api/usage/quotas, the current consumption of the org along with its quota limits
+func ProcessGetQuotaUsageHandler(ctx *fasthttp.RequestCtx, myid uint64) {
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	utils.WriteJsonResponse(ctx, usageStats.GetQuotaUsage(myid))
+}
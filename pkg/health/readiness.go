@@ -0,0 +1,184 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/siglens/siglens/pkg/cluster"
+	"github.com/siglens/siglens/pkg/config"
+	"github.com/siglens/siglens/pkg/health/heartbeat"
+	"github.com/siglens/siglens/pkg/ingest"
+	"github.com/siglens/siglens/pkg/utils"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	STATUS_HEALTHY   = "healthy"
+	STATUS_DEGRADED  = "degraded"
+	STATUS_UNHEALTHY = "unhealthy"
+)
+
+const INGEST_BUFFERS = "ingestBuffers"
+const CLUSTER_MEMBERSHIP = "clusterMembership"
+
+// A loop that has not run for this many of its intervals is considered stopped
+const MISSED_INTERVALS_TO_STALL = 3
+
+/*
+The liveness probe restarts the server, so a loop has to miss many more of its intervals, and at least
+LIVENESS_MIN_STALL_MS, before it fails. A flush that is slow under load then only makes the node not ready
+*/
+const LIVENESS_MISSED_INTERVALS_TO_STALL = 10
+const LIVENESS_MIN_STALL_MS = 10 * 60 * 1000
+
+// Status of a loop that stopped, the server can not keep the ingested data without the flushers
+var stalledLoopStatus = map[string]string{
+	heartbeat.SEGMENT_FLUSHER:   STATUS_UNHEALTHY,
+	heartbeat.METRICS_STORE:     STATUS_UNHEALTHY,
+	heartbeat.RETENTION_SWEEPER: STATUS_DEGRADED,
+	heartbeat.BLOB_UPLOADER:     STATUS_DEGRADED,
+	heartbeat.INTEGRITY_CHECKER: STATUS_DEGRADED,
+}
+
+type SubsystemHealth struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Reason    string `json:"reason,omitempty"`
+	LastRunMs uint64 `json:"lastRunMs,omitempty"`
+}
+
+type HealthReport struct {
+	Status     string            `json:"status"`
+	Subsystems []SubsystemHealth `json:"subsystems"`
+}
+
+/*
+Handles /health/live. Fails only when a loop that keeps the ingested data stopped running, since restarting the
+server is what brings it back
+*/
+func ProcessLiveness(ctx *fasthttp.RequestCtx) {
+	writeHealthReport(ctx, getHealthReport(heartbeat.GetAll(), nil, utils.GetCurrentTimeInMs(), true))
+}
+
+/*
+Handles /health/ready. Fails when any subsystem is unhealthy, a degraded subsystem still serves. A draining node is
+not ready either, so that the load balancers stop sending it requests
+*/
+func ProcessReadiness(ctx *fasthttp.RequestCtx) {
+	var bpStatus *ingest.BackpressureStatus
+	if config.IsIngestNode() {
+		status := ingest.GetBackpressureStatus()
+		bpStatus = &status
+	}
+	report := getHealthReport(heartbeat.GetAll(), bpStatus, utils.GetCurrentTimeInMs(), false)
+	if cluster.IsLocalNodeDraining() {
+		report.Subsystems = append(report.Subsystems, SubsystemHealth{Name: CLUSTER_MEMBERSHIP,
+			Status: STATUS_UNHEALTHY, Reason: "the node is draining"})
+		report.Status = STATUS_UNHEALTHY
+	}
+	writeHealthReport(ctx, report)
+}
+
+func writeHealthReport(ctx *fasthttp.RequestCtx, report *HealthReport) {
+	if report.Status == STATUS_UNHEALTHY {
+		ctx.SetStatusCode(fasthttp.StatusServiceUnavailable)
+	} else {
+		ctx.SetStatusCode(fasthttp.StatusOK)
+	}
+	utils.WriteJsonResponse(ctx, report)
+}
+
+/*
+Makes the report of the loops that registered a heartbeat and of the ingest buffers, if bpStatus is not nil.
+For liveness only the loops that stopped count, the failed runs and the backpressure are left out
+*/
+func getHealthReport(beats map[string]heartbeat.Beat, bpStatus *ingest.BackpressureStatus, nowMs uint64, forLiveness bool) *HealthReport {
+	report := &HealthReport{
+		Status:     STATUS_HEALTHY,
+		Subsystems: make([]SubsystemHealth, 0, len(beats)+1),
+	}
+	if bpStatus != nil && !forLiveness {
+		report.Subsystems = append(report.Subsystems, getIngestBuffersHealth(bpStatus))
+	}
+
+	names := make([]string, 0, len(beats))
+	for name := range beats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		report.Subsystems = append(report.Subsystems, getLoopHealth(name, beats[name], nowMs, forLiveness))
+	}
+
+	for _, subsystem := range report.Subsystems {
+		if subsystem.Status == STATUS_UNHEALTHY {
+			report.Status = STATUS_UNHEALTHY
+		} else if subsystem.Status == STATUS_DEGRADED && report.Status == STATUS_HEALTHY {
+			report.Status = STATUS_DEGRADED
+		}
+	}
+	return report
+}
+
+func getLoopHealth(name string, beat heartbeat.Beat, nowMs uint64, forLiveness bool) SubsystemHealth {
+	subsystem := SubsystemHealth{Name: name, Status: STATUS_HEALTHY, LastRunMs: beat.LastRunMs}
+	if reason := getStallReason(beat, nowMs, forLiveness); reason != "" {
+		subsystem.Status = STATUS_DEGRADED
+		if status, ok := stalledLoopStatus[name]; ok {
+			subsystem.Status = status
+		}
+		subsystem.Reason = reason
+	} else if beat.LastErr != "" && !forLiveness {
+		subsystem.Status = STATUS_DEGRADED
+		subsystem.Reason = fmt.Sprintf("the last run failed: %v", beat.LastErr)
+	}
+	return subsystem
+}
+
+// Returns why the loop is considered stopped, empty if it is running
+func getStallReason(beat heartbeat.Beat, nowMs uint64, forLiveness bool) string {
+	lastMs := beat.LastRunMs
+	if lastMs == 0 {
+		lastMs = beat.StartedMs
+	}
+	stallMs := MISSED_INTERVALS_TO_STALL * beat.IntervalMs
+	if forLiveness {
+		stallMs = LIVENESS_MISSED_INTERVALS_TO_STALL * beat.IntervalMs
+		if stallMs < LIVENESS_MIN_STALL_MS {
+			stallMs = LIVENESS_MIN_STALL_MS
+		}
+	}
+	if nowMs <= lastMs+stallMs {
+		return ""
+	}
+	if beat.LastRunMs == 0 {
+		return fmt.Sprintf("has not run since it started %v seconds ago", (nowMs-beat.StartedMs)/1000)
+	}
+	return fmt.Sprintf("has not run for %v seconds, it runs every %v seconds", (nowMs-beat.LastRunMs)/1000, beat.IntervalMs/1000)
+}
+
+func getIngestBuffersHealth(bpStatus *ingest.BackpressureStatus) SubsystemHealth {
+	subsystem := SubsystemHealth{Name: INGEST_BUFFERS, Status: STATUS_HEALTHY}
+	if bpStatus.UnderPressure {
+		subsystem.Status = STATUS_DEGRADED
+		subsystem.Reason = fmt.Sprintf("rejecting ingest, %v MB of ingest buffers out of %v MB and %v active flushes out of %v",
+			bpStatus.InMemoryMB, bpStatus.MaxInMemoryMB, bpStatus.ActiveFlushes, bpStatus.MaxActiveFlushes)
+	}
+	return subsystem
+}
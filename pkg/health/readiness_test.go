@@ -0,0 +1,76 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"testing"
+
+	"github.com/siglens/siglens/pkg/health/heartbeat"
+	"github.com/siglens/siglens/pkg/ingest"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_getHealthReport(t *testing.T) {
+	nowMs := uint64(1_000_000)
+	beats := map[string]heartbeat.Beat{
+		heartbeat.SEGMENT_FLUSHER:   {IntervalMs: 10_000, StartedMs: 0, LastRunMs: nowMs - 5_000},
+		heartbeat.RETENTION_SWEEPER: {IntervalMs: 100_000, StartedMs: nowMs - 50_000},
+		heartbeat.METRICS_STORE:     {IntervalMs: 60_000, StartedMs: 0, LastRunMs: nowMs - 1_000, LastErr: "disk full"},
+	}
+
+	report := getHealthReport(beats, &ingest.BackpressureStatus{}, nowMs, false)
+	assert.Equal(t, STATUS_DEGRADED, report.Status, "a failed run degrades")
+	assert.Equal(t, []SubsystemHealth{
+		{Name: INGEST_BUFFERS, Status: STATUS_HEALTHY},
+		{Name: heartbeat.METRICS_STORE, Status: STATUS_DEGRADED, Reason: "the last run failed: disk full", LastRunMs: nowMs - 1_000},
+		{Name: heartbeat.RETENTION_SWEEPER, Status: STATUS_HEALTHY},
+		{Name: heartbeat.SEGMENT_FLUSHER, Status: STATUS_HEALTHY, LastRunMs: nowMs - 5_000},
+	}, report.Subsystems)
+
+	report = getHealthReport(beats, nil, nowMs, true)
+	assert.Equal(t, STATUS_HEALTHY, report.Status, "failed runs do not fail the liveness")
+	assert.Len(t, report.Subsystems, 3)
+
+	// the flusher stopped and the ingest is rejected
+	beats[heartbeat.SEGMENT_FLUSHER] = heartbeat.Beat{IntervalMs: 10_000, LastRunMs: nowMs - 40_000}
+	bpStatus := &ingest.BackpressureStatus{Enabled: true, UnderPressure: true, InMemoryMB: 900, MaxInMemoryMB: 800,
+		ActiveFlushes: 2, MaxActiveFlushes: 8}
+	report = getHealthReport(beats, bpStatus, nowMs, false)
+	assert.Equal(t, STATUS_UNHEALTHY, report.Status)
+	flusher := report.Subsystems[len(report.Subsystems)-1]
+	assert.Equal(t, STATUS_UNHEALTHY, flusher.Status)
+	assert.Equal(t, "has not run for 40 seconds, it runs every 10 seconds", flusher.Reason)
+	assert.Equal(t, STATUS_DEGRADED, report.Subsystems[0].Status)
+	assert.Equal(t, "rejecting ingest, 900 MB of ingest buffers out of 800 MB and 2 active flushes out of 8", report.Subsystems[0].Reason)
+
+	// the liveness only fails once the flusher missed many more intervals, and at least the min stall time
+	report = getHealthReport(beats, bpStatus, nowMs, true)
+	assert.Equal(t, STATUS_HEALTHY, report.Status)
+	beats[heartbeat.SEGMENT_FLUSHER] = heartbeat.Beat{IntervalMs: 10_000, LastRunMs: nowMs - 200_000}
+	report = getHealthReport(beats, bpStatus, nowMs, true)
+	assert.Equal(t, STATUS_HEALTHY, report.Status)
+	beats[heartbeat.SEGMENT_FLUSHER] = heartbeat.Beat{IntervalMs: 10_000, LastRunMs: nowMs - 700_000}
+	report = getHealthReport(beats, bpStatus, nowMs, true)
+	assert.Equal(t, STATUS_UNHEALTHY, report.Status)
+	assert.Equal(t, "has not run for 700 seconds, it runs every 10 seconds", report.Subsystems[len(report.Subsystems)-1].Reason)
+
+	// a sweeper that never ran after it started only degrades
+	beats = map[string]heartbeat.Beat{heartbeat.RETENTION_SWEEPER: {IntervalMs: 100_000, StartedMs: nowMs - 400_000}}
+	report = getHealthReport(beats, nil, nowMs, false)
+	assert.Equal(t, STATUS_DEGRADED, report.Status)
+	assert.Equal(t, "has not run since it started 400 seconds ago", report.Subsystems[0].Reason)
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingest
+
+import (
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/siglens/siglens/pkg/config"
+	"github.com/siglens/siglens/pkg/instrumentation"
+	segutils "github.com/siglens/siglens/pkg/segment/utils"
+	segwriter "github.com/siglens/siglens/pkg/segment/writer"
+	"github.com/siglens/siglens/pkg/utils"
+	log "github.com/sirupsen/logrus"
+	"github.com/valyala/fasthttp"
+)
+
+const BACKPRESSURE_CHECK_INTERVAL = 1 * time.Second
+
+// percent of the total memory the ingest buffers may use when maxInMemoryMB is not set
+const DEFAULT_INGEST_BUFFER_MEM_PERCENT = 30
+
+const DEFAULT_MAX_RETRY_AFTER_SECS = 60
+
+// once under pressure, ingest is accepted again only after the usage drops below this ratio of the limits
+const BACKPRESSURE_RELEASE_RATIO = 0.9
+
+type BackpressureStatus struct {
+	Enabled          bool   `json:"enabled"`
+	UnderPressure    bool   `json:"underPressure"`
+	InMemoryMB       uint64 `json:"inMemoryMB"`
+	MaxInMemoryMB    uint64 `json:"maxInMemoryMB"`
+	ActiveFlushes    uint64 `json:"activeFlushes"`
+	MaxActiveFlushes uint64 `json:"maxActiveFlushes"`
+	RetryAfterSecs   uint64 `json:"retryAfterSecs"`
+	RejectedRequests uint64 `json:"rejectedRequests"`
+}
+
+var backpressureLock sync.RWMutex
+var backpressureStatus BackpressureStatus
+
+func InitBackpressureMonitor() {
+	bpCfg := config.GetBackpressureConfig()
+	if !bpCfg.Enabled {
+		return
+	}
+
+	backpressureLock.Lock()
+	backpressureStatus = BackpressureStatus{Enabled: true}
+	backpressureLock.Unlock()
+	setBackpressureLimits(bpCfg)
+	// the limits can be reloaded, enabling or disabling the backpressure needs a restart
+	config.AddReloadHook(func() {
+		setBackpressureLimits(config.GetBackpressureConfig())
+	})
+
+	go backpressureMonitorLooper()
+}
+
+func setBackpressureLimits(bpCfg config.BackpressureConfig) {
+	maxInMemoryMB := bpCfg.MaxInMemoryMB
+	if maxInMemoryMB == 0 {
+		totalMemoryMB := segutils.ConvertUintBytesToMB(config.GetTotalMemoryAvailable())
+		maxInMemoryMB = totalMemoryMB * DEFAULT_INGEST_BUFFER_MEM_PERCENT / 100
+	}
+	maxActiveFlushes := bpCfg.MaxActiveFlushes
+	if maxActiveFlushes == 0 {
+		maxActiveFlushes = uint64(config.GetParallelism()) * 2
+	}
+
+	backpressureLock.Lock()
+	defer backpressureLock.Unlock()
+	if maxInMemoryMB == backpressureStatus.MaxInMemoryMB && maxActiveFlushes == backpressureStatus.MaxActiveFlushes {
+		return
+	}
+	backpressureStatus.MaxInMemoryMB = maxInMemoryMB
+	backpressureStatus.MaxActiveFlushes = maxActiveFlushes
+	log.Infof("setBackpressureLimits: rejecting ingest above %v MB of ingest buffers or %v active flushes",
+		maxInMemoryMB, maxActiveFlushes)
+}
+
+func backpressureMonitorLooper() {
+	for {
+		time.Sleep(BACKPRESSURE_CHECK_INTERVAL)
+		updateBackpressureStatus(segwriter.GetInMemorySize(), segwriter.GetActiveWipFlushCount())
+	}
+}
+
+func updateBackpressureStatus(inMemoryMB uint64, activeFlushes uint64) {
+	maxRetryAfterSecs := config.GetBackpressureConfig().MaxRetryAfterSecs
+	if maxRetryAfterSecs == 0 {
+		maxRetryAfterSecs = DEFAULT_MAX_RETRY_AFTER_SECS
+	}
+
+	backpressureLock.Lock()
+	defer backpressureLock.Unlock()
+
+	wasUnderPressure := backpressureStatus.UnderPressure
+	backpressureStatus.InMemoryMB = inMemoryMB
+	backpressureStatus.ActiveFlushes = activeFlushes
+	backpressureStatus.UnderPressure, backpressureStatus.RetryAfterSecs = computeBackpressure(inMemoryMB,
+		backpressureStatus.MaxInMemoryMB, activeFlushes, backpressureStatus.MaxActiveFlushes, wasUnderPressure,
+		uint64(config.GetSegFlushIntervalSecs()), maxRetryAfterSecs)
+
+	if backpressureStatus.UnderPressure != wasUnderPressure {
+		if backpressureStatus.UnderPressure {
+			log.Warnf("updateBackpressureStatus: rejecting ingest, in memory: %v MB, active flushes: %v", inMemoryMB, activeFlushes)
+			instrumentation.SetIngestPressureGauge(1)
+		} else {
+			log.Infof("updateBackpressureStatus: accepting ingest again, in memory: %v MB, active flushes: %v", inMemoryMB, activeFlushes)
+			instrumentation.SetIngestPressureGauge(0)
+		}
+	}
+}
+
+/*
+Returns whether ingest should be rejected and the Retry-After in seconds to send with the rejection.
+
+The usage ratio is the larger of the memory and flush ratios. Ingest is rejected once the ratio reaches 1
+and stays rejected until it drops below BACKPRESSURE_RELEASE_RATIO, so that clients don't flap between
+accepted and rejected. The buffers are drained once per flush interval, so the Retry-After is the flush
+interval scaled by the usage ratio, clamped to [1, maxRetryAfterSecs].
+*/
+func computeBackpressure(inMemoryMB uint64, maxInMemoryMB uint64, activeFlushes uint64, maxActiveFlushes uint64,
+	wasUnderPressure bool, flushIntervalSecs uint64, maxRetryAfterSecs uint64) (bool, uint64) {
+
+	usageRatio := float64(0)
+	if maxInMemoryMB > 0 {
+		usageRatio = float64(inMemoryMB) / float64(maxInMemoryMB)
+	}
+	if maxActiveFlushes > 0 {
+		usageRatio = math.Max(usageRatio, float64(activeFlushes)/float64(maxActiveFlushes))
+	}
+
+	underPressure := usageRatio >= 1 || (wasUnderPressure && usageRatio >= BACKPRESSURE_RELEASE_RATIO)
+	if !underPressure {
+		return false, 0
+	}
+
+	retryAfterSecs := uint64(math.Ceil(float64(flushIntervalSecs) * usageRatio))
+	if retryAfterSecs < 1 {
+		retryAfterSecs = 1
+	}
+	if retryAfterSecs > maxRetryAfterSecs {
+		retryAfterSecs = maxRetryAfterSecs
+	}
+	return true, retryAfterSecs
+}
+
+// Returns true and writes a 429 with a Retry-After header if ingest is currently rejected
+func RejectIfUnderBackpressure(ctx *fasthttp.RequestCtx) bool {
+	backpressureLock.Lock()
+	if !backpressureStatus.UnderPressure {
+		backpressureLock.Unlock()
+		return false
+	}
+	backpressureStatus.RejectedRequests++
+	retryAfterSecs := backpressureStatus.RetryAfterSecs
+	backpressureLock.Unlock()
+
+	instrumentation.IncrementInt64Counter(instrumentation.INGEST_REJECTED_COUNT, 1)
+	ctx.Response.Header.Set("Retry-After", strconv.FormatUint(retryAfterSecs, 10))
+	ctx.SetStatusCode(fasthttp.StatusTooManyRequests)
+	utils.WriteResponse(ctx, utils.HttpServerResponse{
+		Message:    "ingest buffers are full, retry after " + strconv.FormatUint(retryAfterSecs, 10) + " seconds",
+		StatusCode: fasthttp.StatusTooManyRequests,
+	})
+	return true
+}
+
+func GetBackpressureStatus() BackpressureStatus {
+	backpressureLock.RLock()
+	defer backpressureLock.RUnlock()
+	return backpressureStatus
+}
+
+func ProcessGetBackpressureStatus(ctx *fasthttp.RequestCtx) {
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	utils.WriteJsonResponse(ctx, GetBackpressureStatus())
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func Test_computeBackpressure(t *testing.T) {
+	// below both limits
+	underPressure, retryAfter := computeBackpressure(50, 100, 1, 8, false, 5, 60)
+	assert.False(t, underPressure)
+	assert.Equal(t, uint64(0), retryAfter)
+
+	// memory at the limit
+	underPressure, retryAfter = computeBackpressure(100, 100, 1, 8, false, 5, 60)
+	assert.True(t, underPressure)
+	assert.Equal(t, uint64(5), retryAfter)
+
+	// flushes over the limit, retry after scales with the overshoot
+	underPressure, retryAfter = computeBackpressure(10, 100, 16, 8, false, 5, 60)
+	assert.True(t, underPressure)
+	assert.Equal(t, uint64(10), retryAfter)
+
+	// clamped to the max
+	underPressure, retryAfter = computeBackpressure(1000, 100, 1, 8, false, 30, 60)
+	assert.True(t, underPressure)
+	assert.Equal(t, uint64(60), retryAfter)
+
+	// clamped to at least 1s
+	underPressure, retryAfter = computeBackpressure(100, 100, 0, 8, false, 0, 60)
+	assert.True(t, underPressure)
+	assert.Equal(t, uint64(1), retryAfter)
+
+	// stays under pressure until the usage drops below the release ratio
+	underPressure, _ = computeBackpressure(95, 100, 1, 8, true, 5, 60)
+	assert.True(t, underPressure)
+	underPressure, _ = computeBackpressure(95, 100, 1, 8, false, 5, 60)
+	assert.False(t, underPressure)
+	underPressure, _ = computeBackpressure(85, 100, 1, 8, true, 5, 60)
+	assert.False(t, underPressure)
+}
+
+func Test_RejectIfUnderBackpressure(t *testing.T) {
+	backpressureLock.Lock()
+	backpressureStatus = BackpressureStatus{Enabled: true, MaxInMemoryMB: 100, MaxActiveFlushes: 8}
+	backpressureLock.Unlock()
+	defer func() {
+		backpressureLock.Lock()
+		backpressureStatus = BackpressureStatus{}
+		backpressureLock.Unlock()
+	}()
+
+	updateBackpressureStatus(10, 0)
+	ctx := &fasthttp.RequestCtx{}
+	assert.False(t, RejectIfUnderBackpressure(ctx))
+
+	updateBackpressureStatus(200, 0)
+	ctx = &fasthttp.RequestCtx{}
+	assert.True(t, RejectIfUnderBackpressure(ctx))
+	assert.Equal(t, fasthttp.StatusTooManyRequests, ctx.Response.StatusCode())
+	assert.NotEmpty(t, string(ctx.Response.Header.Peek("Retry-After")))
+
+	status := GetBackpressureStatus()
+	assert.True(t, status.UnderPressure)
+	assert.Equal(t, uint64(200), status.InMemoryMB)
+	assert.Equal(t, uint64(1), status.RejectedRequests)
+}
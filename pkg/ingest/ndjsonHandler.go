@@ -0,0 +1,174 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingest
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/siglens/siglens/pkg/apikeys"
+	eswriter "github.com/siglens/siglens/pkg/es/writer"
+	segutils "github.com/siglens/siglens/pkg/segment/utils"
+	"github.com/siglens/siglens/pkg/usageStats"
+	"github.com/siglens/siglens/pkg/utils"
+	log "github.com/sirupsen/logrus"
+	"github.com/valyala/fasthttp"
+)
+
+// max number of per line errors returned in the response
+const MAX_NDJSON_LINE_ERRORS = 100
+
+const NDJSON_READ_BUFFER_SIZE = 64 * 1024
+
+type NdjsonLineError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+type NdjsonIngestResponse struct {
+	Took        int64             `json:"took"`
+	Processed   int               `json:"processed"`
+	Failed      int               `json:"failed"`
+	LineErrors  []NdjsonLineError `json:"lineErrors"`
+	MoreErrors  bool              `json:"moreErrors,omitempty"`  // more lines failed than are reported in LineErrors
+	StreamError string            `json:"streamError,omitempty"` // set if reading the body failed midway
+}
+
+// Ingests a stream of newline delimited JSON documents into the index given by the "index" query parameter.
+// The body is read incrementally, so it can be sent with chunked transfer encoding without a size limit.
+func ProcessNdjsonIngestRequest(ctx *fasthttp.RequestCtx, myid uint64) {
+	indexName := string(ctx.QueryArgs().Peek("index"))
+	if indexName == "" {
+		log.Errorf("ProcessNdjsonIngestRequest: index query parameter is required")
+		ctx.SetStatusCode(fasthttp.StatusBadRequest)
+		utils.WriteJsonResponse(ctx, map[string]interface{}{"error": "index query parameter is required"})
+		return
+	}
+	if !apikeys.AuthorizeIndexAccess(ctx, indexName, apikeys.ACCESS_WRITE) {
+		return
+	}
+
+	var body io.Reader = ctx.RequestBodyStream()
+	if body == nil {
+		body = bytes.NewReader(ctx.PostBody())
+	}
+
+	tsNow := utils.GetCurrentTimeInMs()
+	localIndexMap := make(map[string]string)
+	response, bytesReceived := processNdjsonStream(body, func(rawJson []byte) error {
+		return eswriter.ProcessIndexRequest(rawJson, tsNow, indexName, uint64(len(rawJson)), false, localIndexMap, myid)
+	})
+	usageStats.UpdateStats(bytesReceived, uint64(response.Processed), myid)
+
+	if response.Processed == 0 && (response.Failed > 0 || response.StreamError != "") {
+		ctx.SetStatusCode(fasthttp.StatusBadRequest)
+	} else {
+		ctx.SetStatusCode(fasthttp.StatusOK)
+	}
+	utils.WriteJsonResponse(ctx, response)
+}
+
+// Reads one JSON document per line from body and calls ingestFn for each valid document.
+// Returns the response to send and the number of bytes ingested.
+func processNdjsonStream(body io.Reader, ingestFn func(rawJson []byte) error) (*NdjsonIngestResponse, uint64) {
+	startTime := time.Now()
+	response := &NdjsonIngestResponse{LineErrors: make([]NdjsonLineError, 0)}
+	addLineError := func(lineNum int, err error) {
+		response.Failed++
+		if len(response.LineErrors) < MAX_NDJSON_LINE_ERRORS {
+			response.LineErrors = append(response.LineErrors, NdjsonLineError{Line: lineNum, Error: err.Error()})
+		} else {
+			response.MoreErrors = true
+		}
+	}
+
+	var bytesReceived uint64
+	reader := bufio.NewReaderSize(body, NDJSON_READ_BUFFER_SIZE)
+	lineBuf := make([]byte, 0, NDJSON_READ_BUFFER_SIZE)
+	lineNum := 0
+	for {
+		line, tooLong, readErr := readNdjsonLine(reader, lineBuf[:0])
+		if readErr != nil && readErr != io.EOF {
+			log.Errorf("processNdjsonStream: failed to read request body after line %v, err: %v", lineNum, readErr)
+			response.StreamError = readErr.Error()
+			break
+		}
+		if len(line) > 0 || tooLong || readErr == nil {
+			lineNum++
+		}
+
+		line = bytes.TrimSpace(line)
+		if tooLong {
+			addLineError(lineNum, fmt.Errorf("line is larger than the max record size of %v bytes", segutils.MAX_RECORD_SIZE))
+		} else if len(line) > 0 {
+			err := ingestNdjsonLine(line, ingestFn)
+			if err != nil {
+				addLineError(lineNum, err)
+			} else {
+				response.Processed++
+				bytesReceived += uint64(len(line))
+			}
+		}
+
+		if readErr == io.EOF {
+			break
+		}
+	}
+
+	response.Took = time.Since(startTime).Milliseconds()
+	return response, bytesReceived
+}
+
+func ingestNdjsonLine(line []byte, ingestFn func(rawJson []byte) error) error {
+	if line[0] != '{' || !jsoniter.Valid(line) {
+		return errors.New("line is not a valid JSON object")
+	}
+	err := ingestFn(line)
+	if err != nil {
+		return fmt.Errorf("failed to ingest document: %v", err)
+	}
+	return nil
+}
+
+// Reads the next line into buf without the trailing newline. Lines longer than MAX_RECORD_SIZE are
+// consumed but not returned, and tooLong is set instead. Returns io.EOF with the last line.
+func readNdjsonLine(reader *bufio.Reader, buf []byte) ([]byte, bool, error) {
+	tooLong := false
+	for {
+		fragment, err := reader.ReadSlice('\n')
+		if !tooLong {
+			if len(buf)+len(fragment) > segutils.MAX_RECORD_SIZE+1 {
+				tooLong = true
+				buf = buf[:0]
+			} else {
+				buf = append(buf, fragment...)
+			}
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if tooLong {
+			return nil, true, err
+		}
+		return bytes.TrimRight(buf, "\r\n"), false, err
+	}
+}
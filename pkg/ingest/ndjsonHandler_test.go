@@ -0,0 +1,99 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingest
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	segutils "github.com/siglens/siglens/pkg/segment/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_processNdjsonStream(t *testing.T) {
+	body := strings.Join([]string{
+		`{"a": 1}`,
+		``,
+		`not json`,
+		`{"b": "x"}` + "\r",
+		`[1, 2]`,
+		`{"fail": true}`,
+		`  {"c": 3}  `,
+	}, "\n") + "\n"
+
+	ingested := make([]string, 0)
+	response, bytesReceived := processNdjsonStream(iotest.OneByteReader(strings.NewReader(body)), func(rawJson []byte) error {
+		if strings.Contains(string(rawJson), "fail") {
+			return errors.New("buffer full")
+		}
+		ingested = append(ingested, string(rawJson))
+		return nil
+	})
+
+	assert.Equal(t, []string{`{"a": 1}`, `{"b": "x"}`, `{"c": 3}`}, ingested)
+	assert.Equal(t, 3, response.Processed)
+	assert.Equal(t, 3, response.Failed)
+	assert.Equal(t, uint64(len(`{"a": 1}`)+len(`{"b": "x"}`)+len(`{"c": 3}`)), bytesReceived)
+	assert.Len(t, response.LineErrors, 3)
+	assert.Equal(t, 3, response.LineErrors[0].Line)
+	assert.Equal(t, 5, response.LineErrors[1].Line)
+	assert.Equal(t, 6, response.LineErrors[2].Line)
+	assert.Contains(t, response.LineErrors[2].Error, "buffer full")
+	assert.Empty(t, response.StreamError)
+}
+
+func Test_processNdjsonStreamLongLine(t *testing.T) {
+	longLine := `{"a": "` + strings.Repeat("x", segutils.MAX_RECORD_SIZE) + `"}`
+	body := `{"first": 1}` + "\n" + longLine + "\n" + `{"last": 1}`
+
+	numIngested := 0
+	response, _ := processNdjsonStream(strings.NewReader(body), func(rawJson []byte) error {
+		numIngested++
+		return nil
+	})
+
+	assert.Equal(t, 2, numIngested)
+	assert.Equal(t, 2, response.Processed)
+	assert.Equal(t, 1, response.Failed)
+	assert.Equal(t, 2, response.LineErrors[0].Line)
+}
+
+func Test_processNdjsonStreamReadError(t *testing.T) {
+	body := io.MultiReader(strings.NewReader(`{"a": 1}`+"\n"), iotest.ErrReader(errors.New("connection reset")))
+
+	response, _ := processNdjsonStream(body, func(rawJson []byte) error {
+		return nil
+	})
+
+	assert.Equal(t, 1, response.Processed)
+	assert.Equal(t, "connection reset", response.StreamError)
+}
+
+func Test_processNdjsonStreamMaxErrors(t *testing.T) {
+	body := strings.Repeat("bad\n", MAX_NDJSON_LINE_ERRORS+5)
+
+	response, _ := processNdjsonStream(strings.NewReader(body), func(rawJson []byte) error {
+		return nil
+	})
+
+	assert.Equal(t, MAX_NDJSON_LINE_ERRORS+5, response.Failed)
+	assert.Len(t, response.LineErrors, MAX_NDJSON_LINE_ERRORS)
+	assert.True(t, response.MoreErrors)
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingest
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/siglens/siglens/pkg/usageStats"
+	"github.com/siglens/siglens/pkg/utils"
+	log "github.com/sirupsen/logrus"
+	"github.com/valyala/fasthttp"
+)
+
+// Returns true and writes a 429 if the org is over its ingest quotas
+func RejectIfOverIngestQuota(ctx *fasthttp.RequestCtx, myid uint64) bool {
+	retryAfter, err := usageStats.CheckIngestQuota(myid)
+	if err == nil {
+		return false
+	}
+
+	log.Infof("RejectIfOverIngestQuota: rejecting ingest of orgid=%v, %v", myid, err)
+	if retryAfter > 0 {
+		ctx.Response.Header.Set("Retry-After", strconv.FormatUint(uint64(math.Ceil(retryAfter.Seconds())), 10))
+	}
+	ctx.SetStatusCode(fasthttp.StatusTooManyRequests)
+	utils.WriteResponse(ctx, utils.HttpServerResponse{
+		Message:    err.Error(),
+		StatusCode: fasthttp.StatusTooManyRequests,
+	})
+	return true
+}
@@ -19,11 +19,14 @@ package instrumentation
 import (
 	"context"
 	"net/http"
+	"sync"
 
 	log "github.com/sirupsen/logrus"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	conf "github.com/siglens/siglens/pkg/config"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/prometheus"
@@ -73,13 +76,52 @@ func IncrementInt64UpDownCounter(metricName api.Int64UpDownCounter, value int64)
 
 func IncrementInt64CounterWithLabel(metricName api.Int64Counter, value int64,
 	labelKey string, labelVal string) {
-	attrs := []attribute.KeyValue{
-		attribute.String(labelKey, labelVal),
-	}
-
 	metricName.Add(
 		ctx,
 		value,
-		api.WithAttributes(attrs...),
+		getLabelOption(labelKey, labelVal),
 	)
 }
+
+// Counts the lookups of a cache, the hit rate is the hits out of all the lookups of the cache
+func IncrementCacheLookupsCount(cacheName string, isHit bool, value int64) {
+	if value == 0 {
+		return
+	}
+	result := "miss"
+	if isHit {
+		result = "hit"
+	}
+	pair := labelPair{key: cacheName, val: result}
+	option, ok := cacheLabelOptions.Load(pair)
+	if !ok {
+		option, _ = cacheLabelOptions.LoadOrStore(pair, api.WithAttributes(attribute.String("cache", cacheName),
+			attribute.String("result", result)))
+	}
+	CACHE_LOOKUPS_COUNT.Add(ctx, value, option.(api.MeasurementOption))
+}
+
+type labelPair struct {
+	key string
+	val string
+}
+
+// The counters of every ingested event take the same few labels, so their attribute sets are only built once
+var labelOptions sync.Map
+var cacheLabelOptions sync.Map
+
+func getLabelOption(labelKey string, labelVal string) api.MeasurementOption {
+	pair := labelPair{key: labelKey, val: labelVal}
+	if option, ok := labelOptions.Load(pair); ok {
+		return option.(api.MeasurementOption)
+	}
+	option, _ := labelOptions.LoadOrStore(pair, api.WithAttributes(attribute.String(labelKey, labelVal)))
+	return option.(api.MeasurementOption)
+}
+
+var fasthttpMetricsHandler = fasthttpadaptor.NewFastHTTPHandler(promhttp.Handler())
+
+// Serves the metrics in the prometheus exposition format, the same as the exporter on :2222
+func ProcessMetricsRequest(ctx *fasthttp.RequestCtx) {
+	fasthttpMetricsHandler(ctx)
+}
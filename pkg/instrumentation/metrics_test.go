@@ -15,3 +15,28 @@ limitations under the License.
 */
 
 package instrumentation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func Test_ProcessMetricsRequest(t *testing.T) {
+	InitMetrics()
+	IncrementCacheLookupsCount("panel", true, 3)
+	IncrementCacheLookupsCount("panel", false, 1)
+	IncrementInt64CounterWithLabel(INGEST_EVENTS_COUNT, 5, "indexname", "test-index")
+	RecordInt64HistogramWithLabel(QUERY_DURATION_MS, 12, "type", "search")
+
+	ctx := &fasthttp.RequestCtx{}
+	ProcessMetricsRequest(ctx)
+	assert.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+	body := string(ctx.Response.Body())
+	assert.Contains(t, body, `ss_cache_lookups_count_total{cache="panel",otel_scope_name="siglens",otel_scope_version="",result="hit"} 3`)
+	assert.Contains(t, body, `ss_cache_lookups_count_total{cache="panel",otel_scope_name="siglens",otel_scope_version="",result="miss"} 1`)
+	assert.Contains(t, body, `ss_ingest_events_count_total{indexname="test-index",otel_scope_name="siglens",otel_scope_version=""} 5`)
+	assert.Contains(t, body, `ss_query_duration_ms_count{otel_scope_name="siglens",otel_scope_version="",type="search"} 1`)
+	assert.Contains(t, body, "go_goroutines")
+}
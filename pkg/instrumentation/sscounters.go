@@ -45,6 +45,11 @@ var WIP_BUFFER_FLUSH_COUNT, _ = meter.Int64Counter(
 	metric.WithUnit("1"),
 	metric.WithDescription("wip flush count"))
 
+var INGEST_REJECTED_COUNT, _ = meter.Int64Counter(
+	"ss.ingest.rejected.count",
+	metric.WithUnit("1"),
+	metric.WithDescription("ingest requests rejected with a 429 due to backpressure"))
+
 var S3_UPLOADS, _ = meter.Int64Counter(
 	"ss.s3uploads.received",
 	metric.WithUnit("1"),
@@ -59,3 +64,18 @@ var S3_DELETED, _ = meter.Int64Counter(
 	"ss.s3deleted.received",
 	metric.WithUnit("1"),
 	metric.WithDescription("s3 deletes received"))
+
+var INGEST_EVENTS_COUNT, _ = meter.Int64Counter(
+	"ss.ingest.events.count",
+	metric.WithUnit("count"),
+	metric.WithDescription("events ingested per index"))
+
+var INGEST_BYTES_COUNT, _ = meter.Int64Counter(
+	"ss.ingest.bytes.count",
+	metric.WithUnit("bytes"),
+	metric.WithDescription("bytes ingested per index"))
+
+var CACHE_LOOKUPS_COUNT, _ = meter.Int64Counter(
+	"ss.cache.lookups.count",
+	metric.WithUnit("count"),
+	metric.WithDescription("cache lookups per cache and result, a hit or a miss"))
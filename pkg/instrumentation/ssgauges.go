@@ -117,6 +117,32 @@ func SetWriterSegstoreCountGauge(val int64) {
 	writerSegstoreCountLock.Unlock()
 }
 
+var ingestPressureGauge int64
+var ingestPressureLock sync.RWMutex
+var INGEST_PRESSURE, _ = meter.Int64ObservableGauge(
+	"ss.ingest.pressure",
+	metric.WithUnit("1"),
+	metric.WithDescription("1 if ingest is being rejected due to backpressure, else 0"))
+
+func SetIngestPressureGauge(val int64) {
+	ingestPressureLock.Lock()
+	ingestPressureGauge = val
+	ingestPressureLock.Unlock()
+}
+
+var ingestBufferMemoryGauge int64
+var ingestBufferMemoryLock sync.RWMutex
+var INGEST_BUFFER_MEMORY_MB, _ = meter.Int64ObservableGauge(
+	"ss.ingest.buffer.memory.mb",
+	metric.WithUnit("megabytes"),
+	metric.WithDescription("memory held in the ingest buffers that are not flushed yet"))
+
+func SetIngestBufferMemoryMB(val int64) {
+	ingestBufferMemoryLock.Lock()
+	ingestBufferMemoryGauge = val
+	ingestBufferMemoryLock.Unlock()
+}
+
 var segmentMicroindexCountGauge int64
 var segmentMicroindexCountLock sync.RWMutex
 var SEGMENT_MICROINDEX_COUNT, _ = meter.Int64ObservableGauge(
@@ -345,6 +371,26 @@ func registerGaugeCallbacks() {
 		log.Errorf("failed to register callback for gauge WRITER_SEGSTORE_COUNT, err %v", err)
 	}
 
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		ingestPressureLock.RLock()
+		defer ingestPressureLock.RUnlock()
+		o.ObserveInt64(INGEST_PRESSURE, int64(ingestPressureGauge))
+		return nil
+	}, INGEST_PRESSURE)
+	if err != nil {
+		log.Errorf("failed to register callback for gauge INGEST_PRESSURE, err %v", err)
+	}
+
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		ingestBufferMemoryLock.RLock()
+		defer ingestBufferMemoryLock.RUnlock()
+		o.ObserveInt64(INGEST_BUFFER_MEMORY_MB, int64(ingestBufferMemoryGauge))
+		return nil
+	}, INGEST_BUFFER_MEMORY_MB)
+	if err != nil {
+		log.Errorf("failed to register callback for gauge INGEST_BUFFER_MEMORY_MB, err %v", err)
+	}
+
 	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
 		segmentMicroindexCountLock.RLock()
 		defer segmentMicroindexCountLock.RUnlock()
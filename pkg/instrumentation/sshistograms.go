@@ -0,0 +1,39 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instrumentation
+
+import (
+	"go.opentelemetry.io/otel/metric"
+)
+
+var SEGMENT_FLUSH_DURATION_MS, _ = meter.Int64Histogram(
+	"ss.segment.flush.duration.ms",
+	metric.WithUnit("milliseconds"),
+	metric.WithDescription("time taken to flush an ingest buffer to its segment"))
+
+var QUERY_DURATION_MS, _ = meter.Int64Histogram(
+	"ss.query.duration.ms",
+	metric.WithUnit("milliseconds"),
+	metric.WithDescription("query duration in milliseconds per query type"))
+
+func RecordInt64Histogram(metricName metric.Int64Histogram, value int64) {
+	metricName.Record(ctx, value)
+}
+
+func RecordInt64HistogramWithLabel(metricName metric.Int64Histogram, value int64, labelKey string, labelVal string) {
+	metricName.Record(ctx, value, getLabelOption(labelKey, labelVal))
+}
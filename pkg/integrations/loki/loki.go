@@ -18,12 +18,14 @@ package loki
 
 import (
 	"encoding/json"
-	"fmt"
+	"math"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/golang/snappy"
+	"github.com/siglens/siglens/pkg/apikeys"
 	"github.com/siglens/siglens/pkg/ast"
 	"github.com/siglens/siglens/pkg/ast/pipesearch"
 	dtu "github.com/siglens/siglens/pkg/common/dtypeutils"
@@ -46,26 +48,24 @@ import (
 
 const (
 	ContentJson        = "application/json; charset=utf-8"
-	LOKIINDEX          = "loki-index"
+	LOKIINDEX          = ast.LokiIndexName
 	TimeStamp          = "timestamp"
 	Index              = "_index"
 	DefaultLimit       = 100
 	MsToNanoConversion = 1_000_000
 )
 
-func parseLabels(labelsString string) map[string]string {
-	labelsString = strings.Trim(labelsString, "{}")
-	labelPairs := strings.Split(labelsString, ", ")
+var labelPairRegex = regexp.MustCompile(`([a-zA-Z_][a-zA-Z0-9_]*)\s*=\s*("(?:[^"\\]|\\.)*")`)
 
+// Parses a label set like {filename="test.log", job="test"}
+func parseLabels(labelsString string) map[string]string {
 	labels := make(map[string]string)
-
-	for _, pair := range labelPairs {
-		parts := strings.Split(pair, "=")
-		if len(parts) == 2 {
-			key := strings.Trim(parts[0], "\"")
-			value := strings.Trim(parts[1], "\"")
-			labels[key] = value
+	for _, match := range labelPairRegex.FindAllStringSubmatch(labelsString, -1) {
+		value, err := strconv.Unquote(match[2])
+		if err != nil {
+			value = strings.Trim(match[2], "\"")
 		}
+		labels[match[1]] = value
 	}
 
 	return labels
@@ -87,6 +87,9 @@ func parseLabels(labelsString string) map[string]string {
 //		]
 //	}
 func ProcessLokiLogsIngestRequest(ctx *fasthttp.RequestCtx, myid uint64) {
+	if !apikeys.AuthorizeIndexAccess(ctx, LOKIINDEX, apikeys.ACCESS_WRITE) {
+		return
+	}
 
 	responsebody := make(map[string]interface{})
 	buf, err := snappy.Decode(nil, ctx.PostBody())
@@ -152,8 +155,15 @@ func ProcessLokiLogsIngestRequest(ctx *fasthttp.RequestCtx, myid uint64) {
 		ingestCommonFields := parseLabels(labels)
 
 		// Note: We might not need separate filename and job fields in the future
-		allIngestData["filename"] = ingestCommonFields["filename"]
-		allIngestData["job"] = ingestCommonFields["job"]
+		allIngestData = map[string]interface{}{
+			"filename": ingestCommonFields["filename"],
+			"job":      ingestCommonFields["job"],
+		}
+		for label, value := range ingestCommonFields {
+			if isLokiLabel(label) {
+				allIngestData[label] = value
+			}
+		}
 		allIngestData["labels"] = labels
 
 		entries, ok := stream["entries"].([]interface{})
@@ -220,154 +230,66 @@ func ProcessLokiLogsIngestRequest(ctx *fasthttp.RequestCtx, myid uint64) {
 	ctx.SetStatusCode(fasthttp.StatusOK)
 }
 
-func ProcessLokiLabelRequest(ctx *fasthttp.RequestCtx) {
+func ProcessLokiLabelRequest(ctx *fasthttp.RequestCtx, myid uint64) {
+	if !apikeys.AuthorizeIndexAccess(ctx, LOKIINDEX, apikeys.ACCESS_READ) {
+		return
+	}
 	indexName := []string{LOKIINDEX}
 	responsebody := make(map[string]interface{})
-	colNames := remove(metadata.GetAllColNames(indexName), "line")
+	allColNames := segwriter.GetUnrotatedColNamesForTable(LOKIINDEX, myid)
+	for _, colName := range metadata.GetAllColNames(indexName) {
+		allColNames[colName] = true
+	}
+	colNames := make([]string, 0, len(allColNames))
+	for colName := range allColNames {
+		if isLokiLabel(colName) {
+			colNames = append(colNames, colName)
+		}
+	}
+	sort.Strings(colNames)
 	responsebody["data"] = colNames
-	responsebody["status"] = "Success"
+	responsebody["status"] = "success"
 	utils.WriteJsonResponse(ctx, responsebody)
 	ctx.SetStatusCode(fasthttp.StatusOK)
 }
 
+// The start and end query parameters are Loki timestamps, the default is the last 6 hours
 func ProcessLokiLabelValuesRequest(ctx *fasthttp.RequestCtx, myid uint64) {
+	if !apikeys.AuthorizeIndexAccess(ctx, LOKIINDEX, apikeys.ACCESS_READ) {
+		return
+	}
 	responsebody := make(map[string]interface{})
 
 	labelName := utils.ExtractParamAsString(ctx.UserValue("labelName"))
-	indexName := LOKIINDEX
-	qid := rutils.GetNextQid()
-	colVals, err := ast.GetColValues(labelName, indexName, qid, myid)
-
+	nowMs := utils.GetCurrentTimeInMs()
+	endMs, err := parseLokiTime(string(ctx.QueryArgs().Peek("end")), nowMs)
 	if err != nil {
-		ctx.SetStatusCode(fasthttp.StatusUnauthorized)
-		responsebody["error"] = err.Error()
-		utils.WriteJsonResponse(ctx, responsebody)
-		return
-	}
-
-	responsebody["data"] = colVals
-	responsebody["status"] = "Success"
-	utils.WriteJsonResponse(ctx, responsebody)
-	ctx.SetStatusCode(fasthttp.StatusOK)
-}
-
-func ProcessQueryRequest(ctx *fasthttp.RequestCtx, myid uint64) {
-	query := removeUnimplementedMethods(string(ctx.QueryArgs().Peek("query")))
-	if query == "" {
-		log.Errorf(" ProcessQueryRequest: received empty search request body ")
-		responsebody := make(map[string]interface{})
-		ctx.SetStatusCode(fasthttp.StatusBadRequest)
-		responsebody["error"] = "received empty search request body"
-		utils.WriteJsonResponse(ctx, responsebody)
+		writeLokiErrorResponse(ctx, "ProcessLokiLabelValuesRequest", err)
 		return
 	}
-
-	limit, err := strconv.ParseUint(string(ctx.QueryArgs().Peek("limit")), 10, 64)
+	startMs, err := parseLokiTime(string(ctx.QueryArgs().Peek("start")), endMs-uint64(math.Min(float64(endMs), DefaultLabelLookbackMs)))
 	if err != nil {
-		responsebody := make(map[string]interface{})
-		ctx.SetStatusCode(fasthttp.StatusBadRequest)
-		responsebody["error"] = err.Error()
-		utils.WriteJsonResponse(ctx, responsebody)
+		writeLokiErrorResponse(ctx, "ProcessLokiLabelValuesRequest", err)
 		return
 	}
-
-	qid := rutils.GetNextQid()
-
-	ti := structs.InitTableInfo(LOKIINDEX, myid, false)
-	simpleNode, aggs, err := pipesearch.ParseRequest(query, 0, 0, qid, "Log QL", LOKIINDEX)
-
-	if aggs != nil && aggs.GroupByRequest != nil {
-		aggs.GroupByRequest.GroupByColumns = remove(aggs.GroupByRequest.GroupByColumns, "line")
-	}
-
+	colVals, err := getLokiLabelValues(ctx, labelName, startMs, endMs, myid)
 	if err != nil {
-		responsebody := make(map[string]interface{})
-		ctx.SetStatusCode(fasthttp.StatusBadRequest)
-		responsebody["error"] = err.Error()
-		utils.WriteJsonResponse(ctx, responsebody)
-		return
-	}
-
-	segment.LogASTNode("logql query parser", simpleNode, qid)
-	segment.LogQueryAggsNode("logql aggs parser", aggs, qid)
-	startTime := utils.GetCurrentTimeInMs()
-	qc := structs.InitQueryContextWithTableInfo(ti, limit, 0, myid, false)
-	queryResult := segment.ExecuteQuery(simpleNode, aggs, qid, qc)
-
-	allJsons, allCols, err := record.GetJsonFromAllRrc(queryResult.AllRecords, false, qid, queryResult.SegEncToKey, aggs)
-
-	if len(queryResult.MeasureResults) > 0 {
-		lokiMetricsResponse := getMetricsResponse(queryResult)
-		utils.WriteJsonResponse(ctx, lokiMetricsResponse)
-		ctx.SetStatusCode(fasthttp.StatusOK)
+		writeLokiErrorResponse(ctx, "ProcessLokiLabelValuesRequest", err)
 		return
 	}
 
-	lokiQueryResponse := LokiQueryResponse{}
-
-	if len(allJsons) > 0 {
-		lokiQueryResponse.Data = Data{ResultType: "streams"}
-		lokiQueryResponse.Data.Result = make([]StreamValue, 0)
-		for _, row := range allJsons {
-			queryResultLine := StreamValue{Values: make([][]string, 0)}
-			line, ok := row["line"].(string)
-			if !ok {
-				responsebody := make(map[string]interface{})
-				log.Errorf("ProcessLokiLogsIngestRequest: Unable to convert line to string")
-				ctx.SetStatusCode(fasthttp.StatusBadRequest)
-				responsebody["error"] = "ProcessLokiLogsIngestRequest: Unable to convert line to string"
-				utils.WriteJsonResponse(ctx, responsebody)
-			}
-			valuesRow := make([]string, 0)
-			timeStamp, ok := row["timestamp"].(uint64)
-			if !ok {
-				responsebody := make(map[string]interface{})
-				log.Errorf("ProcessLokiLogsIngestRequest: Unable to convert line to string")
-				ctx.SetStatusCode(fasthttp.StatusBadRequest)
-				responsebody["error"] = "ProcessLokiLogsIngestRequest: Unable to convert line to string"
-				utils.WriteJsonResponse(ctx, responsebody)
-			}
-			valuesRow = append(valuesRow, fmt.Sprintf("%v", timeStamp*MsToNanoConversion), line)
-			queryResultLine.Values = append(queryResultLine.Values, valuesRow)
-
-			newRow := make(map[string]interface{}, 0)
-			labelsKeys := remove(allCols, "line")
-			for _, label := range labelsKeys {
-				if label != TimeStamp && label != Index {
-					newRow[label] = row[label]
-				}
-			}
-
-			queryResultLine.Stream = newRow
-			lokiQueryResponse.Data.Result = append(lokiQueryResponse.Data.Result, queryResultLine)
-		}
-	} else {
-		lokiQueryResponse.Data.Result = make([]StreamValue, 0)
-	}
-
-	if err != nil {
-		log.Errorf(" ProcessMetricsSearchRequest: received empty search request body ")
-		responsebody := make(map[string]interface{})
-		ctx.SetStatusCode(fasthttp.StatusBadRequest)
-		responsebody["error"] = "received empty search request body"
-		utils.WriteJsonResponse(ctx, responsebody)
-		_, err = ctx.WriteString(err.Error())
-		if err != nil {
-			log.Errorf("qid=%v, ProcessMetricsSearchRequest: could not write error message err=%v", qid, err)
-		}
-		log.Errorf("qid=%v, ProcessMetricsSearchRequest: failed to decode search request body! Err=%+v", qid, err)
-		return
-	}
-
-	lokiQueryResponse.Data.Stats = getQueryStats(queryResult, startTime, myid)
-
-	utils.WriteJsonResponse(ctx, lokiQueryResponse)
+	responsebody["data"] = colVals
+	responsebody["status"] = "success"
+	utils.WriteJsonResponse(ctx, responsebody)
 	ctx.SetStatusCode(fasthttp.StatusOK)
-
 }
 
 func ProcessIndexStatsRequest(ctx *fasthttp.RequestCtx, myid uint64) {
-	query := removeUnimplementedMethods(string(ctx.QueryArgs().Peek("query")))
+	query, err := getLokiLogQuery(string(ctx.QueryArgs().Peek("query")))
+	if err != nil {
+		writeEmptyIndexStatsResponse(ctx)
+		return
+	}
 
 	qid := rutils.GetNextQid()
 
@@ -377,12 +299,18 @@ func ProcessIndexStatsRequest(ctx *fasthttp.RequestCtx, myid uint64) {
 		writeEmptyIndexStatsResponse(ctx)
 		return
 	}
+	ast.TargetLokiLineFilters(simpleNode)
+
+	if aggs == nil {
+		aggs = structs.InitDefaultQueryAggregations()
+	}
 
 	segment.LogASTNode("logql query parser", simpleNode, qid)
 	segment.LogQueryAggsNode("logql aggs parser", aggs, qid)
 
 	simpleNode.TimeRange = rutils.GetESDefaultQueryTimeRange()
 	qc := structs.InitQueryContextWithTableInfo(ti, rutils.DefaultBucketCount, 0, myid, false)
+	qc.IndexFilter = apikeys.GetIndexFilter(ctx, apikeys.ACCESS_READ)
 
 	queryResult := segment.ExecuteQuery(simpleNode, aggs, qid, qc)
 	allJsons, allCols, err := record.GetJsonFromAllRrc(queryResult.AllRecords, false, qid, queryResult.SegEncToKey, aggs)
@@ -439,6 +367,7 @@ func ProcessLokiSeriesRequest(ctx *fasthttp.RequestCtx, myid uint64) {
 		utils.WriteJsonResponse(ctx, responsebody)
 		return
 	}
+	ast.TargetLokiLineFilters(simpleNode)
 
 	segment.LogASTNode("logql query parser", simpleNode, qid)
 	segment.LogQueryAggsNode("logql aggs parser", aggs, qid)
@@ -448,6 +377,7 @@ func ProcessLokiSeriesRequest(ctx *fasthttp.RequestCtx, myid uint64) {
 		EndEpochMs:   endEpoch / MsToNanoConversion,
 	}
 	qc := structs.InitQueryContextWithTableInfo(ti, DefaultLimit, 0, myid, false)
+	qc.IndexFilter = apikeys.GetIndexFilter(ctx, apikeys.ACCESS_READ)
 	queryResult := segment.ExecuteQuery(simpleNode, aggs, qid, qc)
 	allJsons, _, err := record.GetJsonFromAllRrc(queryResult.AllRecords, false, qid, queryResult.SegEncToKey, aggs)
 	if err != nil {
@@ -480,47 +410,6 @@ func remove(slice []string, stringToRemove string) []string {
 	return slice
 }
 
-// needs to be modified as changes are made to logql parsing. Logfmt, json, label/line expression
-// and stream selectors are supported
-func removeUnimplementedMethods(queryString string) string {
-
-	// Define the regular expression pattern
-	pattern := `sum\s+by\s+\(level\)\s+\(count_over_time\(`
-	regex := regexp.MustCompile(pattern)
-	matchIndex := regex.FindStringIndex(queryString)
-	if matchIndex != nil {
-		extractedString := queryString[matchIndex[1]:]
-		return extractedString
-	} else {
-		return queryString
-	}
-}
-
-func getMetricsResponse(queryResult *structs.NodeResult) LokiMetricsResponse {
-	lokiMetricsResponse := LokiMetricsResponse{}
-	lokiMetricsResponse.Status = "success"
-	lokiMetricsResponse.Data = MetricsData{ResultType: "vector"}
-	lokiMetricsResponse.Data.MetricResult = make([]MetricValue, 0)
-	if queryResult.MeasureResults != nil {
-		metricResult := make([]MetricValue, 0)
-		for _, bucket := range queryResult.MeasureResults {
-			groupByCols := queryResult.GroupByCols
-			newMetricVal := MetricValue{}
-			newMetricVal.Stream = make(map[string]interface{})
-			for index, colName := range groupByCols {
-				newMetricVal.Stream[colName] = bucket.GroupByValues[index]
-			}
-			valResult := make([]interface{}, 0)
-			valResult = append(valResult, 1689919818.158, queryResult.MeasureFunctions[0])
-			newMetricVal.Values = valResult
-			metricResult = append(metricResult, newMetricVal)
-		}
-		lokiMetricsResponse.Data.MetricResult = metricResult
-	}
-
-	lokiMetricsResponse.Data.Stats = MetricStats{}
-	return lokiMetricsResponse
-}
 func getQueryStats(queryResult *structs.NodeResult, startTime uint64, myid uint64) Stats {
 	lokiQueryStats := Stats{}
 	if queryResult == nil {
@@ -0,0 +1,666 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loki
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/siglens/siglens/pkg/apikeys"
+	"github.com/siglens/siglens/pkg/ast"
+	"github.com/siglens/siglens/pkg/ast/pipesearch"
+	dtu "github.com/siglens/siglens/pkg/common/dtypeutils"
+	esquery "github.com/siglens/siglens/pkg/es/query"
+	rutils "github.com/siglens/siglens/pkg/readerUtils"
+	"github.com/siglens/siglens/pkg/segment"
+	segquery "github.com/siglens/siglens/pkg/segment/query"
+	"github.com/siglens/siglens/pkg/segment/reader/record"
+	"github.com/siglens/siglens/pkg/segment/structs"
+	segutils "github.com/siglens/siglens/pkg/segment/utils"
+	"github.com/siglens/siglens/pkg/utils"
+	log "github.com/sirupsen/logrus"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	DefaultLookbackMs      = 60 * 60 * 1000
+	DefaultLabelLookbackMs = 6 * 60 * 60 * 1000
+	MaxQueryPoints         = 11_000  // per series, same as Loki
+	MaxMetricBuckets       = 100_000 // time buckets of a metric query, each one is as long as the step and range allow
+	LineColumn             = ast.LogLineColumn
+	LabelsColumn           = "labels" // the raw label set of the stream, its labels are ingested as their own columns
+)
+
+// The range functions are applied to the matching logs of each stream, optionally summed over their labels:
+// sum by (level) (count_over_time({job="varlogs"} |= "error" [5m]))
+var sumPrefixRegex = regexp.MustCompile(`(?s)^sum\s*(?:by\s*\(([^()]*)\)\s*)?\((.*)\)$`)
+var sumSuffixRegex = regexp.MustCompile(`(?s)^sum\s*\((.*)\)\s*by\s*\(([^()]*)\)$`)
+var rangeFuncRegex = regexp.MustCompile(`(?s)^(rate|count_over_time)\s*\((.*)\[\s*([0-9a-zA-Z.]+)\s*\]\s*\)$`)
+
+type lokiQueryParams struct {
+	query   string
+	startMs uint64
+	endMs   uint64
+	stepMs  uint64
+	limit   uint64
+	forward bool
+}
+
+type lokiMetricQuery struct {
+	rangeFunc   string // rate or count_over_time
+	rangeMs     uint64
+	logQuery    string // the stream selector and filters the range function is applied to
+	isSum       bool
+	sumByLabels []string
+}
+
+// The number of matching logs of a stream per time bucket, keyed by the ends of the buckets
+type lokiStreamCounts struct {
+	labels map[string]interface{}
+	counts map[uint64]uint64
+}
+
+// Handles /loki/api/v1/query, which evaluates metric queries at a single point in time
+func ProcessQueryRequest(ctx *fasthttp.RequestCtx, myid uint64) {
+	params, err := parseLokiQueryParams(ctx.QueryArgs(), time.Now(), true)
+	if err != nil {
+		writeLokiErrorResponse(ctx, "ProcessQueryRequest", err)
+		return
+	}
+	processLokiQuery(ctx, params, true, myid)
+}
+
+// Handles /loki/api/v1/query_range. Log queries return the matching lines as streams and metric queries return a
+// matrix with a point per step
+func ProcessQueryRangeRequest(ctx *fasthttp.RequestCtx, myid uint64) {
+	params, err := parseLokiQueryParams(ctx.QueryArgs(), time.Now(), false)
+	if err != nil {
+		writeLokiErrorResponse(ctx, "ProcessQueryRangeRequest", err)
+		return
+	}
+	processLokiQuery(ctx, params, false, myid)
+}
+
+func processLokiQuery(ctx *fasthttp.RequestCtx, params *lokiQueryParams, isInstant bool, myid uint64) {
+	metricQuery, err := parseLokiMetricQuery(params.query)
+	if err != nil {
+		writeLokiErrorResponse(ctx, "processLokiQuery", err)
+		return
+	}
+
+	startTime := utils.GetCurrentTimeInMs()
+	if metricQuery == nil {
+		if isInstant {
+			params.startMs = params.endMs - uint64(math.Min(float64(params.endMs), DefaultLookbackMs))
+		}
+		records, queryResult, err := runLokiLogQuery(ctx, params.query, params.startMs, params.endMs, params.limit,
+			params.forward, myid)
+		if err != nil {
+			writeLokiErrorResponse(ctx, "processLokiQuery", err)
+			return
+		}
+		lokiQueryResponse := LokiQueryResponse{Status: "success"}
+		lokiQueryResponse.Data = Data{ResultType: "streams", Result: getLokiStreams(records)}
+		lokiQueryResponse.Data.Stats = getQueryStats(queryResult, startTime, myid)
+		utils.WriteJsonResponse(ctx, lokiQueryResponse)
+		ctx.SetStatusCode(fasthttp.StatusOK)
+		return
+	}
+
+	streams, err := runLokiMetricQuery(ctx, metricQuery, params, myid)
+	if err != nil {
+		writeLokiErrorResponse(ctx, "processLokiQuery", err)
+		return
+	}
+
+	steps := getLokiSteps(params.startMs, params.endMs, params.stepMs)
+	series := getLokiMatrix(streams, metricQuery, steps)
+	if isInstant {
+		lokiMetricsResponse := LokiMetricsResponse{Status: "success"}
+		lokiMetricsResponse.Data = MetricsData{ResultType: "vector", MetricResult: make([]MetricValue, 0, len(series))}
+		for _, s := range series {
+			lokiMetricsResponse.Data.MetricResult = append(lokiMetricsResponse.Data.MetricResult,
+				MetricValue{Stream: s.Metric, Values: s.Values[0]})
+		}
+		utils.WriteJsonResponse(ctx, lokiMetricsResponse)
+		ctx.SetStatusCode(fasthttp.StatusOK)
+		return
+	}
+
+	lokiMatrixResponse := LokiMatrixResponse{Status: "success"}
+	lokiMatrixResponse.Data = MatrixData{ResultType: "matrix", Result: series}
+	utils.WriteJsonResponse(ctx, lokiMatrixResponse)
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
+
+func parseLokiQueryParams(args *fasthttp.Args, now time.Time, isInstant bool) (*lokiQueryParams, error) {
+	params := &lokiQueryParams{
+		query: strings.TrimSpace(string(args.Peek("query"))),
+		limit: DefaultLimit,
+	}
+	if params.query == "" {
+		return nil, errors.New("query is required")
+	}
+
+	var err error
+	if limitStr := string(args.Peek("limit")); limitStr != "" {
+		params.limit, err = strconv.ParseUint(limitStr, 10, 64)
+		if err != nil || params.limit == 0 {
+			return nil, fmt.Errorf("invalid limit %v", limitStr)
+		}
+	}
+
+	switch direction := string(args.Peek("direction")); direction {
+	case "", "backward":
+	case "forward":
+		params.forward = true
+	default:
+		return nil, fmt.Errorf("invalid direction %v", direction)
+	}
+
+	nowMs := uint64(now.UnixMilli())
+	if isInstant {
+		params.endMs, err = parseLokiTime(string(args.Peek("time")), nowMs)
+		if err != nil {
+			return nil, err
+		}
+		params.startMs = params.endMs
+		return params, nil
+	}
+
+	params.endMs, err = parseLokiTime(string(args.Peek("end")), nowMs)
+	if err != nil {
+		return nil, err
+	}
+	defaultStartMs := params.endMs - uint64(math.Min(float64(params.endMs), DefaultLookbackMs))
+	params.startMs, err = parseLokiTime(string(args.Peek("start")), defaultStartMs)
+	if err != nil {
+		return nil, err
+	}
+	if params.startMs > params.endMs {
+		return nil, fmt.Errorf("start %v is after end %v", params.startMs, params.endMs)
+	}
+
+	// same default as Loki, which keeps the number of points per series around 250
+	params.stepMs = uint64(math.Max(float64((params.endMs-params.startMs)/250/1000), 1)) * 1000
+	if stepStr := string(args.Peek("step")); stepStr != "" {
+		step, err := parseLokiDuration(stepStr)
+		if err != nil {
+			stepSecs, floatErr := strconv.ParseFloat(stepStr, 64)
+			if floatErr != nil {
+				return nil, fmt.Errorf("invalid step %v", stepStr)
+			}
+			step = time.Duration(stepSecs * float64(time.Second))
+		}
+		if step.Milliseconds() <= 0 {
+			return nil, fmt.Errorf("step %v has to be at least a millisecond", stepStr)
+		}
+		params.stepMs = uint64(step.Milliseconds())
+	}
+	if (params.endMs-params.startMs)/params.stepMs > MaxQueryPoints {
+		return nil, fmt.Errorf("exceeded the maximum of %v points per series, try increasing the step", MaxQueryPoints)
+	}
+	return params, nil
+}
+
+/*
+Parses a Loki timestamp into epoch ms. Timestamps are in ns, or in seconds if they have at most 10 digits or a
+fraction, or RFC3339
+*/
+func parseLokiTime(value string, defaultMs uint64) (uint64, error) {
+	if value == "" {
+		return defaultMs, nil
+	}
+	if strings.Contains(value, ".") {
+		if secs, err := strconv.ParseFloat(value, 64); err == nil {
+			return uint64(secs * 1000), nil
+		}
+	}
+	if num, err := strconv.ParseUint(value, 10, 64); err == nil {
+		if len(value) <= 10 {
+			return num * 1000, nil
+		}
+		return num / MsToNanoConversion, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timestamp %v", value)
+	}
+	return uint64(t.UnixMilli()), nil
+}
+
+// Parses a LogQL duration, which besides the go durations can be in days, weeks or years
+func parseLokiDuration(value string) (time.Duration, error) {
+	units := map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour, "y": 365 * 24 * time.Hour}
+	for suffix, unit := range units {
+		if !strings.HasSuffix(value, suffix) {
+			continue
+		}
+		if num, err := strconv.ParseUint(strings.TrimSuffix(value, suffix), 10, 64); err == nil {
+			return time.Duration(num) * unit, nil
+		}
+	}
+	return time.ParseDuration(value)
+}
+
+// Returns nil if the query is a log query
+func parseLokiMetricQuery(query string) (*lokiMetricQuery, error) {
+	metricQuery := &lokiMetricQuery{}
+	inner := strings.TrimSpace(query)
+	sumByLabels := ""
+	if match := sumSuffixRegex.FindStringSubmatch(inner); match != nil {
+		metricQuery.isSum = true
+		inner, sumByLabels = strings.TrimSpace(match[1]), match[2]
+	} else if match := sumPrefixRegex.FindStringSubmatch(inner); match != nil {
+		metricQuery.isSum = true
+		inner, sumByLabels = strings.TrimSpace(match[2]), match[1]
+	}
+	for _, label := range strings.Split(sumByLabels, ",") {
+		if label = strings.TrimSpace(label); label != "" {
+			metricQuery.sumByLabels = append(metricQuery.sumByLabels, label)
+		}
+	}
+
+	match := rangeFuncRegex.FindStringSubmatch(inner)
+	if match == nil {
+		if metricQuery.isSum {
+			return nil, fmt.Errorf("only sums of rate and count_over_time are supported, query=%v", query)
+		}
+		return nil, nil
+	}
+	rangeDuration, err := parseLokiDuration(match[3])
+	if err != nil || rangeDuration.Milliseconds() <= 0 {
+		return nil, fmt.Errorf("invalid range %v, query=%v", match[3], query)
+	}
+	metricQuery.rangeFunc = match[1]
+	metricQuery.rangeMs = uint64(rangeDuration.Milliseconds())
+	metricQuery.logQuery = strings.TrimSpace(match[2])
+	return metricQuery, nil
+}
+
+// Returns the stream selector and filters of a log or a metric query
+func getLokiLogQuery(query string) (string, error) {
+	metricQuery, err := parseLokiMetricQuery(query)
+	if err != nil {
+		return "", err
+	}
+	if metricQuery == nil {
+		return query, nil
+	}
+	return metricQuery.logQuery, nil
+}
+
+// Returns the matching logs of the time range, in ms, after masking them
+func runLokiLogQuery(ctx *fasthttp.RequestCtx, logQuery string, startMs uint64, endMs uint64, limit uint64,
+	forward bool, myid uint64) ([]map[string]interface{}, *structs.NodeResult, error) {
+	qid := rutils.GetNextQid()
+	ti := structs.InitTableInfo(LOKIINDEX, myid, false)
+	simpleNode, aggs, err := pipesearch.ParseRequest(logQuery, startMs, endMs, qid, "Log QL", LOKIINDEX)
+	if err != nil {
+		log.Errorf("qid=%v, runLokiLogQuery: failed to parse query %v, err=%v", qid, logQuery, err)
+		return nil, nil, err
+	}
+	if aggs.GroupByRequest != nil || aggs.MeasureOperations != nil {
+		return nil, nil, fmt.Errorf("unsupported metric query %v", logQuery)
+	}
+	if aggs.Sort != nil {
+		aggs.Sort.Ascending = forward
+	}
+	masker := apikeys.GetResultMasker(apikeys.GetRequestApiKey(ctx), ti.GetQueryTables(), myid)
+	err = masker.CheckDerivedFields(aggs)
+	if err != nil {
+		log.Errorf("qid=%v, runLokiLogQuery: query %v derives a masked field, err=%v", qid, logQuery, err)
+		return nil, nil, err
+	}
+
+	qc := structs.InitQueryContextWithTableInfo(ti, limit, 0, myid, false)
+	qc.IndexFilter = apikeys.GetIndexFilter(ctx, apikeys.ACCESS_READ)
+	queryResult := segment.ExecuteQuery(simpleNode, aggs, qid, qc)
+	for _, err := range queryResult.ErrList {
+		log.Errorf("qid=%v, runLokiLogQuery: query %v failed, err=%v", qid, logQuery, err)
+	}
+
+	records, _, err := record.GetJsonFromAllRrc(queryResult.AllRecords, false, qid, queryResult.SegEncToKey, aggs)
+	if err != nil {
+		log.Errorf("qid=%v, runLokiLogQuery: failed to read the records, err=%v", qid, err)
+		return nil, nil, err
+	}
+	masker.MaskRecords(records)
+	return records, queryResult, nil
+}
+
+/*
+Counts the matching logs of each stream per time bucket in the engine, the labels of the streams are masked like the
+labels of the logs would be
+*/
+func runLokiMetricQuery(ctx *fasthttp.RequestCtx, metricQuery *lokiMetricQuery, params *lokiQueryParams,
+	myid uint64) ([]*lokiStreamCounts, error) {
+	timeBucket, err := getLokiMetricBuckets(metricQuery.rangeMs, params.startMs, params.endMs, params.stepMs)
+	if err != nil {
+		return nil, err
+	}
+
+	qid := rutils.GetNextQid()
+	simpleNode, aggs, err := pipesearch.ParseRequest(metricQuery.logQuery, timeBucket.StartTime-1, params.endMs, qid,
+		"Log QL", LOKIINDEX)
+	if err != nil {
+		log.Errorf("qid=%v, runLokiMetricQuery: failed to parse query %v, err=%v", qid, metricQuery.logQuery, err)
+		return nil, err
+	}
+	if aggs.GroupByRequest != nil || aggs.MeasureOperations != nil {
+		return nil, fmt.Errorf("unsupported metric query %v", metricQuery.logQuery)
+	}
+
+	// the json and logfmt parsers of the log query only change the columns of the logs, so they don't change the counts
+	numBuckets := (timeBucket.EndTime-timeBucket.StartTime)/timeBucket.IntervalMillis + 1
+	countAgg := &structs.MeasureAggregator{MeasureCol: "*", MeasureFunc: segutils.Count}
+	aggs = &structs.QueryAggregators{
+		PipeCommandType: structs.GroupByType,
+		GroupByRequest: &structs.GroupByRequest{
+			GroupByColumns:    []string{TimeStamp},
+			MeasureOperations: []*structs.MeasureAggregator{countAgg},
+			BucketCount:       int(numBuckets),
+		},
+		TimeHistogram: timeBucket,
+	}
+
+	ti := structs.InitTableInfo(LOKIINDEX, myid, false)
+	qc := structs.InitQueryContextWithTableInfo(ti, numBuckets, 0, myid, false)
+	qc.IndexFilter = apikeys.GetIndexFilter(ctx, apikeys.ACCESS_READ)
+	queryResult := segment.ExecuteQuery(simpleNode, aggs, qid, qc)
+	for _, err := range queryResult.ErrList {
+		log.Errorf("qid=%v, runLokiMetricQuery: query %v failed, err=%v", qid, metricQuery.logQuery, err)
+	}
+
+	masker := apikeys.GetResultMasker(apikeys.GetRequestApiKey(ctx), ti.GetQueryTables(), myid)
+	return getLokiStreamCounts(queryResult.Histogram, countAgg.String(), timeBucket.IntervalMillis, masker), nil
+}
+
+/*
+Returns the timechart buckets of a metric query split by the label set of the logs. The buckets are as long as the
+greatest common divisor of the step and the range, so the range of every step, (step-range, step], is made of whole
+buckets. The engine's buckets are [start, start+interval), so they start a ms after the ranges do
+*/
+func getLokiMetricBuckets(rangeMs uint64, startMs uint64, endMs uint64, stepMs uint64) (*structs.TimeBucket, error) {
+	intervalMs := rangeMs
+	for rest := stepMs; rest != 0; {
+		intervalMs, rest = rest, intervalMs%rest
+	}
+
+	// the first range starts before the first step, or at 0 on the grid of the buckets
+	rangesStartMs := startMs % intervalMs
+	if startMs >= rangeMs {
+		rangesStartMs = startMs - rangeMs
+	}
+	if (endMs-rangesStartMs)/intervalMs > MaxMetricBuckets {
+		return nil, fmt.Errorf("exceeded the maximum of %v buckets of %vms, try a step that is a multiple of the range",
+			MaxMetricBuckets, intervalMs)
+	}
+
+	return &structs.TimeBucket{
+		IntervalMillis: intervalMs,
+		StartTime:      rangesStartMs + 1,
+		EndTime:        endMs + 1,
+		Timechart:      &structs.TimechartExpr{ByField: LabelsColumn},
+	}, nil
+}
+
+// Returns the counts of the streams in the timechart buckets, whose statistics are the count of each label set
+func getLokiStreamCounts(histogram map[string]*structs.AggregationResult, countName string, intervalMs uint64,
+	masker *apikeys.ResultMasker) []*lokiStreamCounts {
+	streamIdx := make(map[string]int)
+	streams := make([]*lokiStreamCounts, 0)
+	for _, aggRes := range histogram {
+		for _, bucket := range aggRes.Results {
+			bucketStartMs, err := strconv.ParseUint(fmt.Sprintf("%v", bucket.BucketKey), 10, 64)
+			if err != nil {
+				log.Errorf("getLokiStreamCounts: invalid time bucket %v, err=%v", bucket.BucketKey, err)
+				continue
+			}
+			for statName, statValue := range bucket.StatRes {
+				count, err := statValue.GetUIntValue()
+				if err != nil {
+					log.Errorf("getLokiStreamCounts: invalid count %v of %v, err=%v", statValue, statName, err)
+					continue
+				}
+				labelsString := strings.TrimPrefix(strings.TrimPrefix(statName, countName), ": ")
+				idx, ok := streamIdx[labelsString]
+				if !ok {
+					labels := make(map[string]interface{})
+					for label, value := range parseLabels(labelsString) {
+						if isLokiLabel(label) {
+							labels[label] = value
+						}
+					}
+					masker.MaskRecord(LOKIINDEX, labels)
+					idx = len(streams)
+					streamIdx[labelsString] = idx
+					streams = append(streams, &lokiStreamCounts{labels: labels, counts: make(map[uint64]uint64)})
+				}
+				streams[idx].counts[bucketStartMs-1+intervalMs] += count
+			}
+		}
+	}
+	return streams
+}
+
+// Columns that are stored with the log lines but aren't labels of their streams
+func isLokiLabel(colName string) bool {
+	return colName != LineColumn && colName != TimeStamp && colName != Index && colName != LabelsColumn
+}
+
+// Returns the labels of the record and a key that identifies the label set
+func getLokiLabels(row map[string]interface{}, labelNames []string) (map[string]interface{}, string) {
+	if labelNames == nil {
+		labelNames = make([]string, 0, len(row))
+		for colName := range row {
+			if isLokiLabel(colName) {
+				labelNames = append(labelNames, colName)
+			}
+		}
+		sort.Strings(labelNames)
+	}
+
+	labels := make(map[string]interface{}, len(labelNames))
+	var key strings.Builder
+	for _, labelName := range labelNames {
+		value, ok := row[labelName]
+		if !ok || value == nil {
+			continue
+		}
+		labels[labelName] = fmt.Sprintf("%v", value)
+		key.WriteString(strconv.Quote(labelName) + "=" + strconv.Quote(labels[labelName].(string)) + ",")
+	}
+	return labels, key.String()
+}
+
+func getRecordTimestamp(row map[string]interface{}) uint64 {
+	switch ts := row[TimeStamp].(type) {
+	case uint64:
+		return ts
+	case int64:
+		return uint64(ts)
+	case float64:
+		return uint64(ts)
+	case json.Number:
+		val, _ := ts.Int64()
+		return uint64(val)
+	default:
+		return 0
+	}
+}
+
+// Groups the records into a stream per label set, in the order of their first records
+func getLokiStreams(records []map[string]interface{}) []StreamValue {
+	streams := make([]StreamValue, 0)
+	streamIdx := make(map[string]int)
+	for _, row := range records {
+		labels, key := getLokiLabels(row, nil)
+		idx, ok := streamIdx[key]
+		if !ok {
+			idx = len(streams)
+			streamIdx[key] = idx
+			streams = append(streams, StreamValue{Stream: labels, Values: make([][]string, 0)})
+		}
+
+		line, ok := row[LineColumn].(string)
+		if !ok {
+			lineBytes, err := json.Marshal(row)
+			if err != nil {
+				log.Errorf("getLokiStreams: failed to marshal record %v, err=%v", row, err)
+				continue
+			}
+			line = string(lineBytes)
+		}
+		tsNs := strconv.FormatUint(getRecordTimestamp(row)*MsToNanoConversion, 10)
+		streams[idx].Values = append(streams[idx].Values, []string{tsNs, line})
+	}
+	return streams
+}
+
+// Returns the evaluation times, in ms, of a query_range from start to end
+func getLokiSteps(startMs uint64, endMs uint64, stepMs uint64) []uint64 {
+	if stepMs == 0 {
+		return []uint64{endMs}
+	}
+	steps := make([]uint64, 0, (endMs-startMs)/stepMs+1)
+	for t := startMs; t <= endMs; t += stepMs {
+		steps = append(steps, t)
+	}
+	return steps
+}
+
+/*
+Evaluates the range function of the metric query at each step over the counts of the buckets that end in
+(step-range, step]. Like Loki, the steps without any log in their range have no point
+*/
+func getLokiMatrix(streams []*lokiStreamCounts, metricQuery *lokiMetricQuery, steps []uint64) []MatrixValue {
+	var labelNames []string
+	if metricQuery.isSum {
+		labelNames = append(make([]string, 0), metricQuery.sumByLabels...)
+		sort.Strings(labelNames)
+	}
+
+	seriesLabels := make(map[string]map[string]interface{})
+	seriesCounts := make(map[string]map[uint64]uint64)
+	for _, stream := range streams {
+		labels, key := getLokiLabels(stream.labels, labelNames)
+		if _, ok := seriesLabels[key]; !ok {
+			seriesLabels[key] = labels
+			seriesCounts[key] = make(map[uint64]uint64)
+		}
+		for bucketEndMs, count := range stream.counts {
+			seriesCounts[key][bucketEndMs] += count
+		}
+	}
+	keys := make([]string, 0, len(seriesLabels))
+	for key := range seriesLabels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	rangeSecs := float64(metricQuery.rangeMs) / 1000
+	matrix := make([]MatrixValue, 0, len(keys))
+	for _, key := range keys {
+		bucketEnds := make([]uint64, 0, len(seriesCounts[key]))
+		for bucketEndMs := range seriesCounts[key] {
+			bucketEnds = append(bucketEnds, bucketEndMs)
+		}
+		sort.Slice(bucketEnds, func(i, j int) bool { return bucketEnds[i] < bucketEnds[j] })
+		// the number of logs in the buckets before each one
+		countsBefore := make([]uint64, len(bucketEnds)+1)
+		for i, bucketEndMs := range bucketEnds {
+			countsBefore[i+1] = countsBefore[i] + seriesCounts[key][bucketEndMs]
+		}
+
+		values := make([][]interface{}, 0)
+		for _, step := range steps {
+			rangeStart := step - uint64(math.Min(float64(step), float64(metricQuery.rangeMs)))
+			count := countsBefore[sort.Search(len(bucketEnds), func(i int) bool { return bucketEnds[i] > step })] -
+				countsBefore[sort.Search(len(bucketEnds), func(i int) bool { return bucketEnds[i] > rangeStart })]
+			if count == 0 {
+				continue
+			}
+			value := float64(count)
+			if metricQuery.rangeFunc == "rate" {
+				value /= rangeSecs
+			}
+			values = append(values, []interface{}{float64(step) / 1000, strconv.FormatFloat(value, 'f', -1, 64)})
+		}
+		if len(values) > 0 {
+			matrix = append(matrix, MatrixValue{Metric: seriesLabels[key], Values: values})
+		}
+	}
+	return matrix
+}
+
+// Returns the distinct values of the label in the time range, in ms
+func getLokiLabelValues(ctx *fasthttp.RequestCtx, labelName string, startMs uint64, endMs uint64,
+	myid uint64) ([]string, error) {
+	qid := rutils.GetNextQid()
+	simpleNode, err := esquery.GetMatchAllASTNode(qid)
+	if err != nil {
+		log.Errorf("qid=%v, getLokiLabelValues: match all ast node failed, err=%v", qid, err)
+		return nil, err
+	}
+	simpleNode.TimeRange = &dtu.TimeRange{StartEpochMs: startMs, EndEpochMs: endMs}
+	aggs := &structs.QueryAggregators{
+		PipeCommandType: structs.GroupByType,
+		GroupByRequest: &structs.GroupByRequest{
+			GroupByColumns:    []string{labelName},
+			MeasureOperations: []*structs.MeasureAggregator{{MeasureCol: "*", MeasureFunc: segutils.Count}},
+			BucketCount:       segquery.MAX_GRP_BUCKS,
+		},
+	}
+
+	ti := structs.InitTableInfo(LOKIINDEX, myid, false)
+	qc := structs.InitQueryContextWithTableInfo(ti, segquery.MAX_GRP_BUCKS, 0, myid, false)
+	qc.IndexFilter = apikeys.GetIndexFilter(ctx, apikeys.ACCESS_READ)
+	queryResult := segment.ExecuteQuery(simpleNode, aggs, qid, qc)
+	masker := apikeys.GetResultMasker(apikeys.GetRequestApiKey(ctx), ti.GetQueryTables(), myid)
+	masker.MaskBuckets(queryResult.MeasureResults, queryResult.GroupByCols)
+
+	seenValues := make(map[string]struct{})
+	values := make([]string, 0)
+	for _, bucket := range queryResult.MeasureResults {
+		if len(bucket.GroupByValues) == 0 {
+			continue
+		}
+		if _, ok := seenValues[bucket.GroupByValues[0]]; !ok {
+			seenValues[bucket.GroupByValues[0]] = struct{}{}
+			values = append(values, bucket.GroupByValues[0])
+		}
+	}
+	sort.Strings(values)
+	return values, nil
+}
+
+func writeLokiErrorResponse(ctx *fasthttp.RequestCtx, funcName string, err error) {
+	log.Errorf("%v: %v", funcName, err)
+	responsebody := make(map[string]interface{})
+	responsebody["status"] = "error"
+	responsebody["error"] = err.Error()
+	ctx.SetStatusCode(fasthttp.StatusBadRequest)
+	utils.WriteJsonResponse(ctx, responsebody)
+}
@@ -0,0 +1,188 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loki
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/siglens/siglens/pkg/segment/structs"
+	segutils "github.com/siglens/siglens/pkg/segment/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func Test_parseLokiQueryParams(t *testing.T) {
+	now := time.UnixMilli(1_700_000_000_000)
+
+	args := &fasthttp.Args{}
+	args.Parse(`query={job="varlogs"}&start=1699999000000000000&end=1700000000000000000&step=60s&limit=20&direction=forward`)
+	params, err := parseLokiQueryParams(args, now, false)
+	assert.Nil(t, err)
+	assert.Equal(t, `{job="varlogs"}`, params.query)
+	assert.Equal(t, uint64(1_699_999_000_000), params.startMs)
+	assert.Equal(t, uint64(1_700_000_000_000), params.endMs)
+	assert.Equal(t, uint64(60_000), params.stepMs)
+	assert.Equal(t, uint64(20), params.limit)
+	assert.True(t, params.forward)
+
+	args.Parse(`query={job="varlogs"}&start=1699990000&end=2023-11-14T22:13:20Z&step=2.5`)
+	params, err = parseLokiQueryParams(args, now, false)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1_699_990_000_000), params.startMs)
+	assert.Equal(t, uint64(1_700_000_000_000), params.endMs)
+	assert.Equal(t, uint64(2_500), params.stepMs)
+	assert.Equal(t, uint64(DefaultLimit), params.limit)
+	assert.False(t, params.forward)
+
+	args.Parse(`query={job="varlogs"}`)
+	params, err = parseLokiQueryParams(args, now, false)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1_700_000_000_000-DefaultLookbackMs), params.startMs)
+	assert.Equal(t, uint64(14_000), params.stepMs)
+
+	args.Parse(`query=count_over_time({job="varlogs"}[5m])&time=1700000000.5`)
+	params, err = parseLokiQueryParams(args, now, true)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1_700_000_000_500), params.startMs)
+	assert.Equal(t, uint64(1_700_000_000_500), params.endMs)
+
+	for _, query := range []string{"limit=5", "query=a&limit=0", "query=a&direction=up", "query=a&start=200&end=100",
+		"query=a&step=abc", "query=a&start=1&end=1000000&step=1ms", "query=a&end=yesterday"} {
+		args.Parse(query)
+		_, err = parseLokiQueryParams(args, now, false)
+		assert.NotNil(t, err, query)
+	}
+}
+
+func Test_parseLokiMetricQuery(t *testing.T) {
+	metricQuery, err := parseLokiMetricQuery(`{job="varlogs"} |= "error"`)
+	assert.Nil(t, err)
+	assert.Nil(t, metricQuery)
+
+	metricQuery, err = parseLokiMetricQuery(`rate({job="varlogs"} |~ "err(or)?" [5m])`)
+	assert.Nil(t, err)
+	assert.Equal(t, &lokiMetricQuery{rangeFunc: "rate", rangeMs: 300_000, logQuery: `{job="varlogs"} |~ "err(or)?"`}, metricQuery)
+
+	metricQuery, err = parseLokiMetricQuery(`sum by (level, job) (count_over_time({job="varlogs"}[1d]))`)
+	assert.Nil(t, err)
+	assert.Equal(t, &lokiMetricQuery{rangeFunc: "count_over_time", rangeMs: 86_400_000, logQuery: `{job="varlogs"}`,
+		isSum: true, sumByLabels: []string{"level", "job"}}, metricQuery)
+
+	metricQuery, err = parseLokiMetricQuery(`sum(count_over_time({job="varlogs"}[30s])) by (level)`)
+	assert.Nil(t, err)
+	assert.Equal(t, &lokiMetricQuery{rangeFunc: "count_over_time", rangeMs: 30_000, logQuery: `{job="varlogs"}`,
+		isSum: true, sumByLabels: []string{"level"}}, metricQuery)
+
+	metricQuery, err = parseLokiMetricQuery(`sum(rate({job="varlogs"}[1m]))`)
+	assert.Nil(t, err)
+	assert.True(t, metricQuery.isSum)
+	assert.Nil(t, metricQuery.sumByLabels)
+
+	_, err = parseLokiMetricQuery(`sum(bytes_over_time({job="varlogs"}[1m]))`)
+	assert.NotNil(t, err)
+	_, err = parseLokiMetricQuery(`rate({job="varlogs"}[0s])`)
+	assert.NotNil(t, err)
+}
+
+func Test_getLokiStreams(t *testing.T) {
+	records := []map[string]interface{}{
+		{"timestamp": uint64(3), "line": "c", "job": "a", "labels": `{job="a"}`, "_index": LOKIINDEX},
+		{"timestamp": uint64(2), "line": "b", "job": "b", "labels": `{job="b"}`, "_index": LOKIINDEX},
+		{"timestamp": uint64(1), "line": "a", "job": "a", "labels": `{job="a"}`, "_index": LOKIINDEX},
+	}
+	assert.Equal(t, []StreamValue{
+		{Stream: map[string]interface{}{"job": "a"}, Values: [][]string{{"3000000", "c"}, {"1000000", "a"}}},
+		{Stream: map[string]interface{}{"job": "b"}, Values: [][]string{{"2000000", "b"}}},
+	}, getLokiStreams(records))
+}
+
+func Test_getLokiMetricBuckets(t *testing.T) {
+	timeBucket, err := getLokiMetricBuckets(300_000, 1_000_000, 2_000_000, 60_000)
+	assert.Nil(t, err)
+	assert.Equal(t, &structs.TimeBucket{IntervalMillis: 60_000, StartTime: 700_001, EndTime: 2_000_001,
+		Timechart: &structs.TimechartExpr{ByField: LabelsColumn}}, timeBucket)
+
+	// the ranges of the first steps start at 0
+	timeBucket, err = getLokiMetricBuckets(10_000, 5_000, 20_000, 4_000)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2_000), timeBucket.IntervalMillis)
+	assert.Equal(t, uint64(1_001), timeBucket.StartTime)
+
+	// an instant query has a single bucket of the range
+	timeBucket, err = getLokiMetricBuckets(300_000, 1_000_000, 1_000_000, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(300_000), timeBucket.IntervalMillis)
+	assert.Equal(t, uint64(700_001), timeBucket.StartTime)
+
+	_, err = getLokiMetricBuckets(300_001, 0, 3_600_000, 60_000)
+	assert.NotNil(t, err)
+}
+
+func Test_getLokiStreamCounts(t *testing.T) {
+	histogram := map[string]*structs.AggregationResult{
+		"": {Results: []*structs.BucketResult{
+			{BucketKey: "1", StatRes: map[string]segutils.CValueEnclosure{
+				`count(*): {job="a",line="x"}`: {Dtype: segutils.SS_DT_UNSIGNED_NUM, CVal: uint64(2)},
+				`count(*): {job="b"}`:          {Dtype: segutils.SS_DT_UNSIGNED_NUM, CVal: uint64(1)},
+			}},
+			{BucketKey: "5001", StatRes: map[string]segutils.CValueEnclosure{
+				`count(*): {job="a",line="x"}`: {Dtype: segutils.SS_DT_UNSIGNED_NUM, CVal: uint64(3)},
+			}},
+		}},
+	}
+	streams := getLokiStreamCounts(histogram, "count(*)", 5_000, nil)
+	sort.Slice(streams, func(i, j int) bool { return streams[i].labels["job"].(string) < streams[j].labels["job"].(string) })
+	assert.Equal(t, []*lokiStreamCounts{
+		{labels: map[string]interface{}{"job": "a"}, counts: map[uint64]uint64{5_000: 2, 10_000: 3}},
+		{labels: map[string]interface{}{"job": "b"}, counts: map[uint64]uint64{5_000: 1}},
+	}, streams)
+}
+
+func Test_getLokiMatrix(t *testing.T) {
+	streams := []*lokiStreamCounts{
+		{labels: map[string]interface{}{"job": "a", "level": "error"}, counts: map[uint64]uint64{5_000: 1, 10_000: 1}},
+		{labels: map[string]interface{}{"job": "a", "level": "info"}, counts: map[uint64]uint64{5_000: 1}},
+		{labels: map[string]interface{}{"job": "b", "level": "error"}, counts: map[uint64]uint64{10_000: 1}},
+	}
+	steps := getLokiSteps(0, 10_000, 5_000)
+	assert.Equal(t, []uint64{0, 5_000, 10_000}, steps)
+
+	metricQuery := &lokiMetricQuery{rangeFunc: "count_over_time", rangeMs: 5_000, isSum: true, sumByLabels: []string{"level"}}
+	assert.Equal(t, []MatrixValue{
+		{Metric: map[string]interface{}{"level": "error"}, Values: [][]interface{}{{float64(5), "1"}, {float64(10), "2"}}},
+		{Metric: map[string]interface{}{"level": "info"}, Values: [][]interface{}{{float64(5), "1"}}},
+	}, getLokiMatrix(streams, metricQuery, steps))
+
+	metricQuery = &lokiMetricQuery{rangeFunc: "rate", rangeMs: 10_000, isSum: true}
+	assert.Equal(t, []MatrixValue{
+		{Metric: map[string]interface{}{}, Values: [][]interface{}{{float64(5), "0.2"}, {float64(10), "0.4"}}},
+	}, getLokiMatrix(streams, metricQuery, steps))
+
+	metricQuery = &lokiMetricQuery{rangeFunc: "count_over_time", rangeMs: 5_000}
+	assert.Equal(t, []MatrixValue{
+		{Metric: map[string]interface{}{"job": "a", "level": "error"}, Values: [][]interface{}{{float64(5), "1"}, {float64(10), "1"}}},
+		{Metric: map[string]interface{}{"job": "a", "level": "info"}, Values: [][]interface{}{{float64(5), "1"}}},
+		{Metric: map[string]interface{}{"job": "b", "level": "error"}, Values: [][]interface{}{{float64(10), "1"}}},
+	}, getLokiMatrix(streams, metricQuery, steps))
+}
+
+func Test_parseLabels(t *testing.T) {
+	assert.Equal(t, map[string]string{"filename": "/var/log/a, b.log", "job": "varlogs", "url": "a=b"},
+		parseLabels(`{filename="/var/log/a, b.log", job="varlogs",url="a=b"}`))
+}
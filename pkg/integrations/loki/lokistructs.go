@@ -23,7 +23,7 @@ type LokiMetricsResponse struct {
 
 type MetricsData struct {
 	ResultType   string        `json:"resultType"`
-	MetricResult []MetricValue `json:"result,omitempty"`
+	MetricResult []MetricValue `json:"result"`
 	Stats        MetricStats   `json:"stats"`
 }
 
@@ -32,6 +32,22 @@ type MetricValue struct {
 	Values []interface{}          `json:"value"`
 }
 
+type LokiMatrixResponse struct {
+	Status string     `json:"status"`
+	Data   MatrixData `json:"data"`
+}
+
+type MatrixData struct {
+	ResultType string        `json:"resultType"`
+	Result     []MatrixValue `json:"result"`
+	Stats      MetricStats   `json:"stats"`
+}
+
+type MatrixValue struct {
+	Metric map[string]interface{} `json:"metric"`
+	Values [][]interface{}        `json:"values"`
+}
+
 type MetricStats struct {
 	Summary  MetricsSummary  `json:"summary"`
 	Querier  MetricsQuerier  `json:"querier"`
@@ -0,0 +1,556 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package otsdbquery
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cespare/xxhash"
+	dtu "github.com/siglens/siglens/pkg/common/dtypeutils"
+	"github.com/siglens/siglens/pkg/segment"
+	"github.com/siglens/siglens/pkg/segment/reader/metrics/tagstree"
+	tsidtracker "github.com/siglens/siglens/pkg/segment/results/mresults/tsid"
+	"github.com/siglens/siglens/pkg/segment/structs"
+	segutils "github.com/siglens/siglens/pkg/segment/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+/*
+	Executes the sub queries of /api/query the way OpenTSDB does: every series of the metric is downsampled on its
+	own, the series not matching the filters are dropped, the fill policy and the rate are applied per series and
+	the series are then aggregated into one series per combination of the values of the group by tag keys.
+*/
+
+const (
+	LITERAL_OR_FILTER      = "literal_or"
+	ILITERAL_OR_FILTER     = "iliteral_or"
+	NOT_LITERAL_OR_FILTER  = "not_literal_or"
+	NOT_ILITERAL_OR_FILTER = "not_iliteral_or"
+	WILDCARD_FILTER        = "wildcard"
+	IWILDCARD_FILTER       = "iwildcard"
+	REGEXP_FILTER          = "regexp"
+)
+
+const (
+	FILL_POLICY_NONE = "none"
+	FILL_POLICY_NAN  = "nan"
+	FILL_POLICY_NULL = "null"
+	FILL_POLICY_ZERO = "zero"
+)
+
+// OpenTSDB writes the buckets filled by the nan fill policy as NaN, which json numbers cannot hold
+const FILLED_NAN_VALUE = "NaN"
+
+const NO_AGGREGATION = "none"
+
+// limits the datapoints a fill policy can add to a series
+const MAX_FILLED_DATAPOINTS = 1_000_000
+
+const DEFAULT_COUNTER_MAX = float64(math.MaxInt64)
+
+var downsampleAggMapping = map[string]segutils.AggregateFunctions{
+	"count":  segutils.Count,
+	"avg":    segutils.Avg,
+	"min":    segutils.Min,
+	"mimmin": segutils.Min,
+	"max":    segutils.Max,
+	"mimmax": segutils.Max,
+	"sum":    segutils.Sum,
+	"zimsum": segutils.Sum,
+}
+
+// Aggregations of the values of the series of a group at a timestamp. values is never empty
+var seriesAggregators = map[string]func(values []float64) float64{
+	"sum":    sumValues,
+	"zimsum": sumValues,
+	"min":    minValue,
+	"mimmin": minValue,
+	"max":    maxValue,
+	"mimmax": maxValue,
+	"avg": func(values []float64) float64 {
+		return sumValues(values) / float64(len(values))
+	},
+	"count": func(values []float64) float64 {
+		return float64(len(values))
+	},
+	"dev": func(values []float64) float64 {
+		mean := sumValues(values) / float64(len(values))
+		variance := 0.0
+		for _, v := range values {
+			variance += (v - mean) * (v - mean)
+		}
+		return math.Sqrt(variance / float64(len(values)))
+	},
+	// each series is its own group
+	NO_AGGREGATION: func(values []float64) float64 {
+		return values[0]
+	},
+}
+
+type tagFilter struct {
+	tagKey  string
+	groupBy bool
+	matches func(tagValue string) bool
+}
+
+type otsdbQuery struct {
+	mQuery      structs.MetricsQuery // reads every series of the metric, downsampled on its own
+	timeRange   dtu.MetricsTimeRange
+	aggregator  string
+	dsSeconds   uint32 // 0 if the query didn't specify a downsample
+	fillPolicy  string
+	rate        bool
+	rateOptions structs.OTSDBRateOptions
+	filters     []*tagFilter
+}
+
+type otsdbSeries struct {
+	tags map[string]string
+	dps  map[uint32]float64 // NaN for the datapoints added by the nan and null fill policies
+}
+
+func isFilterType(filterType string) bool {
+	switch filterType {
+	case LITERAL_OR_FILTER, ILITERAL_OR_FILTER, NOT_LITERAL_OR_FILTER, NOT_ILITERAL_OR_FILTER,
+		WILDCARD_FILTER, IWILDCARD_FILTER, REGEXP_FILTER:
+		return true
+	default:
+		return false
+	}
+}
+
+func newOTSDBQuery(subQuery *structs.OTSDBSubQuery, timeRange dtu.MetricsTimeRange, myid uint64) (*otsdbQuery, error) {
+	if subQuery.Metric == "" {
+		return nil, fmt.Errorf("newOTSDBQuery: missing metric name")
+	}
+	if _, ok := seriesAggregators[subQuery.Aggregator]; !ok {
+		return nil, fmt.Errorf("newOTSDBQuery: unsupported aggregator function: %v", subQuery.Aggregator)
+	}
+	q := &otsdbQuery{
+		aggregator:  subQuery.Aggregator,
+		timeRange:   timeRange,
+		fillPolicy:  FILL_POLICY_NONE,
+		rate:        subQuery.Rate,
+		rateOptions: subQuery.RateOptions,
+		mQuery: structs.MetricsQuery{
+			MetricName:      subQuery.Metric,
+			HashedMName:     xxhash.Sum64String(subQuery.Metric),
+			SelectAllSeries: true,
+			Aggregator:      structs.Aggreation{AggregatorFunction: segutils.Avg},
+			Downsampler:     structs.Downsampler{Interval: 1, Unit: "m", Aggregator: structs.Aggreation{AggregatorFunction: segutils.Avg}},
+			OrgId:           myid,
+		},
+	}
+	if subQuery.Downsample != "" {
+		downsampler, fillPolicy, err := parseDownsampleSpec(subQuery.Downsample)
+		if err != nil {
+			return nil, err
+		}
+		q.mQuery.Downsampler = downsampler
+		q.fillPolicy = fillPolicy
+		q.dsSeconds = downsampler.GetIntervalTimeInSeconds()
+		if q.dsSeconds == 0 {
+			return nil, fmt.Errorf("newOTSDBQuery: invalid downsample interval in %v", subQuery.Downsample)
+		}
+		if q.fillPolicy != FILL_POLICY_NONE && (timeRange.EndEpochSec-timeRange.StartEpochSec)/q.dsSeconds > MAX_FILLED_DATAPOINTS {
+			return nil, fmt.Errorf("newOTSDBQuery: downsample interval %v is too small to fill the time range", subQuery.Downsample)
+		}
+	}
+
+	allFilters := subQuery.Filters
+	for tagKey, value := range subQuery.Tags {
+		allFilters = append(allFilters, parseFilterValue(tagKey, value, true))
+	}
+	pushedDown := make(map[string]*structs.TagsFilter)
+	for _, filter := range allFilters {
+		tf, err := newTagFilter(filter)
+		if err != nil {
+			return nil, err
+		}
+		q.filters = append(q.filters, tf)
+
+		// every filtered tag key is read from the tags trees, so its values are part of the series keys
+		tagsFilter := getPushedDownFilter(filter)
+		existing, ok := pushedDown[filter.Tagk]
+		if !ok {
+			pushedDown[filter.Tagk] = tagsFilter
+			q.mQuery.TagsFilters = append(q.mQuery.TagsFilters, tagsFilter)
+		} else if existing.RawTagValue == tagstree.STAR && tagsFilter.RawTagValue != tagstree.STAR {
+			*existing = *tagsFilter
+		}
+	}
+	return q, nil
+}
+
+/*
+Returns the filter the tags trees use to narrow down the series that are read. The tags trees only support
+equality and inequality, so a single literal becomes an equal or not equal filter and every other filter reads
+all values of the tag key. The filter itself is applied to the series afterwards in getMatchingSeries
+*/
+func getPushedDownFilter(filter structs.OTSDBMetricsQueryExpTags) *structs.TagsFilter {
+	tagsFilter := &structs.TagsFilter{
+		TagKey:          filter.Tagk,
+		RawTagValue:     tagstree.STAR,
+		HashTagValue:    xxhash.Sum64String(tagstree.STAR),
+		TagOperator:     segutils.Equal,
+		LogicalOperator: segutils.And,
+	}
+	if strings.Contains(filter.Filter, "|") || filter.Filter == tagstree.STAR {
+		return tagsFilter
+	}
+	switch filter.Type {
+	case LITERAL_OR_FILTER:
+		tagsFilter.TagOperator = segutils.Equal
+	case NOT_LITERAL_OR_FILTER:
+		tagsFilter.TagOperator = segutils.NotEqual
+	default:
+		return tagsFilter
+	}
+	tagsFilter.RawTagValue = filter.Filter
+	tagsFilter.HashTagValue = xxhash.Sum64String(filter.Filter)
+	return tagsFilter
+}
+
+// Parses a downsample specification like 5m-avg or 1h-sum-zero into the downsampler and the fill policy
+func parseDownsampleSpec(spec string) (structs.Downsampler, string, error) {
+	var downsampler structs.Downsampler
+	comps := strings.Split(spec, "-")
+	if len(comps) < 2 || len(comps) > 3 {
+		return downsampler, "", fmt.Errorf("parseDownsampleSpec: invalid downsample %v, expected <interval>-<aggregator>[-<fill policy>]", spec)
+	}
+	aggFn, ok := downsampleAggMapping[comps[1]]
+	if !ok {
+		return downsampler, "", fmt.Errorf("parseDownsampleSpec: unsupported downsample aggregator %v", comps[1])
+	}
+	downsampler.Aggregator = structs.Aggreation{AggregatorFunction: aggFn}
+
+	intervalStr := comps[0]
+	if strings.HasSuffix(intervalStr, "c") {
+		downsampler.CFlag = true
+		intervalStr = strings.TrimSuffix(intervalStr, "c")
+	}
+	unitIdx := strings.IndexFunc(intervalStr, func(c rune) bool { return c < '0' || c > '9' })
+	if unitIdx <= 0 {
+		return downsampler, "", fmt.Errorf("parseDownsampleSpec: invalid downsample interval %v", comps[0])
+	}
+	interval, err := strconv.Atoi(intervalStr[:unitIdx])
+	if err != nil || interval == 0 {
+		return downsampler, "", fmt.Errorf("parseDownsampleSpec: invalid downsample interval %v", comps[0])
+	}
+	downsampler.Interval = interval
+	downsampler.Unit = intervalStr[unitIdx:]
+
+	fillPolicy := FILL_POLICY_NONE
+	if len(comps) == 3 {
+		fillPolicy = comps[2]
+		switch fillPolicy {
+		case FILL_POLICY_NONE, FILL_POLICY_NAN, FILL_POLICY_NULL, FILL_POLICY_ZERO:
+		default:
+			return downsampler, "", fmt.Errorf("parseDownsampleSpec: unsupported fill policy %v", fillPolicy)
+		}
+	}
+	return downsampler, fillPolicy, nil
+}
+
+func newTagFilter(filter structs.OTSDBMetricsQueryExpTags) (*tagFilter, error) {
+	if filter.Tagk == "" {
+		return nil, fmt.Errorf("newTagFilter: missing tag key in filter %+v", filter)
+	}
+	tf := &tagFilter{tagKey: filter.Tagk, groupBy: filter.GroupBy}
+	switch filter.Type {
+	case LITERAL_OR_FILTER, ILITERAL_OR_FILTER, NOT_LITERAL_OR_FILTER, NOT_ILITERAL_OR_FILTER:
+		literals := strings.Split(filter.Filter, "|")
+		ignoreCase := filter.Type == ILITERAL_OR_FILTER || filter.Type == NOT_ILITERAL_OR_FILTER
+		negate := strings.HasPrefix(filter.Type, "not_")
+		tf.matches = func(tagValue string) bool {
+			for _, literal := range literals {
+				if literal == tagValue || (ignoreCase && strings.EqualFold(literal, tagValue)) {
+					return !negate
+				}
+			}
+			return negate
+		}
+	case WILDCARD_FILTER, IWILDCARD_FILTER:
+		parts := strings.Split(filter.Filter, "*")
+		for i, part := range parts {
+			parts[i] = regexp.QuoteMeta(part)
+		}
+		pattern := "^" + strings.Join(parts, ".*") + "$"
+		if filter.Type == IWILDCARD_FILTER {
+			pattern = "(?i)" + pattern
+		}
+		tf.matches = regexp.MustCompile(pattern).MatchString
+	case REGEXP_FILTER:
+		compiledRegex, err := regexp.Compile(filter.Filter)
+		if err != nil {
+			log.Errorf("newTagFilter: invalid regexp %v for tag key %v. Error: %v", filter.Filter, filter.Tagk, err)
+			return nil, fmt.Errorf("newTagFilter: invalid regexp %v. Error: %v", filter.Filter, err)
+		}
+		tf.matches = compiledRegex.MatchString
+	default:
+		return nil, fmt.Errorf("newTagFilter: unsupported filter type %v", filter.Type)
+	}
+	return tf, nil
+}
+
+func (q *otsdbQuery) execute(qid uint64, msResolution bool) ([]*structs.OTSDBQueryResponse, error) {
+	res := segment.ExecuteMetricsQuery(&q.mQuery, &q.timeRange, qid)
+	if len(res.ErrList) > 0 {
+		log.Errorf("qid=%v, otsdbQuery.execute: failed to query metric %v. Error: %v", qid, q.mQuery.MetricName, res.ErrList[0])
+		return nil, res.ErrList[0]
+	}
+
+	allSeries := q.getMatchingSeries(res.Results)
+	for _, series := range allSeries {
+		q.applyFillPolicy(series)
+		if q.rate {
+			series.dps = applyRate(series.dps, q.rateOptions)
+		}
+	}
+	return q.aggregateSeries(allSeries, msResolution), nil
+}
+
+// Converts the results of the metrics query, keyed by the values of all the tag keys, into the series matching all filters
+func (q *otsdbQuery) getMatchingSeries(results map[string]map[uint32]float64) []*otsdbSeries {
+	tagKeys := make([]string, 0, len(q.mQuery.TagsFilters))
+	for _, tf := range q.mQuery.TagsFilters {
+		tagKeys = append(tagKeys, tf.TagKey)
+	}
+
+	retVal := make([]*otsdbSeries, 0, len(results))
+	for grpId, dps := range results {
+		tags := make(map[string]string)
+		tagValues := strings.Split(grpId, tsidtracker.TAG_VALUE_DELIMITER_STR)
+		for i := 0; i < len(tagKeys) && i < len(tagValues)-1; i++ {
+			if tagValues[i] != "" {
+				tags[tagKeys[i]] = tagValues[i]
+			}
+		}
+
+		matched := true
+		for _, tf := range q.filters {
+			tagValue, ok := tags[tf.tagKey]
+			if !ok || !tf.matches(tagValue) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			retVal = append(retVal, &otsdbSeries{tags: tags, dps: dps})
+		}
+	}
+	return retVal
+}
+
+// Adds the downsampled buckets that have no datapoints in the time range
+func (q *otsdbQuery) applyFillPolicy(series *otsdbSeries) {
+	if q.dsSeconds == 0 || q.fillPolicy == FILL_POLICY_NONE {
+		return
+	}
+	fillValue := math.NaN()
+	if q.fillPolicy == FILL_POLICY_ZERO {
+		fillValue = 0
+	}
+	for ts := (q.timeRange.StartEpochSec / q.dsSeconds) * q.dsSeconds; ts <= q.timeRange.EndEpochSec; ts += q.dsSeconds {
+		if _, ok := series.dps[ts]; !ok {
+			series.dps[ts] = fillValue
+		}
+		if ts > math.MaxUint32-q.dsSeconds {
+			break
+		}
+	}
+}
+
+/*
+Returns the per second rate between consecutive datapoints. The first datapoint has no rate.
+
+If the series is a counter, a decrease is a reset: the counter is assumed to have wrapped at the counter max, unless
+resets are dropped. Rates above the reset value are reported as 0.
+*/
+func applyRate(dps map[uint32]float64, rateOptions structs.OTSDBRateOptions) map[uint32]float64 {
+	timestamps := make([]uint32, 0, len(dps))
+	for ts := range dps {
+		timestamps = append(timestamps, ts)
+	}
+	sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+
+	counterMax := rateOptions.CounterMax
+	if counterMax == 0 {
+		counterMax = DEFAULT_COUNTER_MAX
+	}
+	retVal := make(map[uint32]float64, len(dps))
+	hasPrev := false
+	var prevTs uint32
+	var prevVal float64
+	for _, ts := range timestamps {
+		val := dps[ts]
+		if math.IsNaN(val) {
+			// a filled bucket stays empty
+			retVal[ts] = val
+			continue
+		}
+		if !hasPrev {
+			hasPrev, prevTs, prevVal = true, ts, val
+			continue
+		}
+		delta := val - prevVal
+		if rateOptions.Counter && delta < 0 {
+			if rateOptions.DropResets {
+				prevTs, prevVal = ts, val
+				continue
+			}
+			delta = counterMax - prevVal + val
+		}
+		rate := delta / float64(ts-prevTs)
+		if rateOptions.Counter && rateOptions.ResetValue > 0 && rate > rateOptions.ResetValue {
+			rate = 0
+		}
+		retVal[ts] = rate
+		prevTs, prevVal = ts, val
+	}
+	return retVal
+}
+
+/*
+Aggregates the series that have the same values for the group by tag keys. The tags of a group are the tags all
+its series have in common, the other tag keys are its aggregate tags
+*/
+func (q *otsdbQuery) aggregateSeries(allSeries []*otsdbSeries, msResolution bool) []*structs.OTSDBQueryResponse {
+	groupByKeys := make([]string, 0)
+	seenKeys := make(map[string]struct{})
+	for _, tf := range q.filters {
+		if _, ok := seenKeys[tf.tagKey]; tf.groupBy && !ok {
+			seenKeys[tf.tagKey] = struct{}{}
+			groupByKeys = append(groupByKeys, tf.tagKey)
+		}
+	}
+	sort.Strings(groupByKeys)
+
+	groups := make(map[string][]*otsdbSeries)
+	for i, series := range allSeries {
+		var sb strings.Builder
+		if q.aggregator == NO_AGGREGATION {
+			sb.WriteString(strconv.Itoa(i))
+		} else {
+			for _, tagKey := range groupByKeys {
+				sb.WriteString(series.tags[tagKey])
+				sb.WriteString(tsidtracker.TAG_VALUE_DELIMITER_STR)
+			}
+		}
+		groups[sb.String()] = append(groups[sb.String()], series)
+	}
+	groupKeys := make([]string, 0, len(groups))
+	for groupKey := range groups {
+		groupKeys = append(groupKeys, groupKey)
+	}
+	sort.Strings(groupKeys)
+
+	aggFn := seriesAggregators[q.aggregator]
+	retVal := make([]*structs.OTSDBQueryResponse, 0, len(groups))
+	for _, groupKey := range groupKeys {
+		groupSeries := groups[groupKey]
+		resp := &structs.OTSDBQueryResponse{
+			MetricName:    q.mQuery.MetricName,
+			Tags:          make(map[string]string),
+			AggregateTags: make([]string, 0),
+			Dps:           make(map[uint64]interface{}),
+		}
+		setGroupTags(resp, groupSeries)
+
+		values := make(map[uint32][]float64)
+		for _, series := range groupSeries {
+			for ts, val := range series.dps {
+				if _, ok := values[ts]; !ok {
+					values[ts] = make([]float64, 0, len(groupSeries))
+				}
+				if !math.IsNaN(val) {
+					values[ts] = append(values[ts], val)
+				}
+			}
+		}
+		for ts, tsValues := range values {
+			dpTs := uint64(ts)
+			if msResolution {
+				dpTs *= 1000
+			}
+			if len(tsValues) == 0 {
+				if q.fillPolicy == FILL_POLICY_NAN {
+					resp.Dps[dpTs] = FILLED_NAN_VALUE
+				} else {
+					resp.Dps[dpTs] = nil
+				}
+				continue
+			}
+			resp.Dps[dpTs] = aggFn(tsValues)
+		}
+		retVal = append(retVal, resp)
+	}
+	return retVal
+}
+
+func setGroupTags(resp *structs.OTSDBQueryResponse, groupSeries []*otsdbSeries) {
+	allTagKeys := make(map[string]struct{})
+	for _, series := range groupSeries {
+		for tagKey := range series.tags {
+			allTagKeys[tagKey] = struct{}{}
+		}
+	}
+	for tagKey := range allTagKeys {
+		tagValue, common := groupSeries[0].tags[tagKey]
+		for _, series := range groupSeries[1:] {
+			if !common {
+				break
+			}
+			common = series.tags[tagKey] == tagValue
+		}
+		if common {
+			resp.Tags[tagKey] = tagValue
+		} else {
+			resp.AggregateTags = append(resp.AggregateTags, tagKey)
+		}
+	}
+	sort.Strings(resp.AggregateTags)
+}
+
+func sumValues(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum
+}
+
+func minValue(values []float64) float64 {
+	retVal := values[0]
+	for _, v := range values[1:] {
+		retVal = math.Min(retVal, v)
+	}
+	return retVal
+}
+
+func maxValue(values []float64) float64 {
+	retVal := values[0]
+	for _, v := range values[1:] {
+		retVal = math.Max(retVal, v)
+	}
+	return retVal
+}
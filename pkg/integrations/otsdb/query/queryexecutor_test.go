@@ -0,0 +1,144 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package otsdbquery
+
+import (
+	"math"
+	"testing"
+
+	dtu "github.com/siglens/siglens/pkg/common/dtypeutils"
+	"github.com/siglens/siglens/pkg/segment/reader/metrics/tagstree"
+	"github.com/siglens/siglens/pkg/segment/structs"
+	segutils "github.com/siglens/siglens/pkg/segment/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_tagFilters(t *testing.T) {
+	cases := []struct {
+		filterType  string
+		filter      string
+		matching    []string
+		notMatching []string
+	}{
+		{LITERAL_OR_FILTER, "web01|web02", []string{"web01", "web02"}, []string{"WEB01", "web03"}},
+		{ILITERAL_OR_FILTER, "web01", []string{"web01", "WEB01"}, []string{"web02"}},
+		{NOT_LITERAL_OR_FILTER, "web01|web02", []string{"web03", "WEB01"}, []string{"web01"}},
+		{NOT_ILITERAL_OR_FILTER, "web01", []string{"web02"}, []string{"Web01"}},
+		{WILDCARD_FILTER, "web*.prod", []string{"web01.prod", "web.prod"}, []string{"db01.prod", "web01.prod2"}},
+		{IWILDCARD_FILTER, "*WEB*", []string{"myweb01"}, []string{"db01"}},
+		{WILDCARD_FILTER, "*", []string{"", "anything"}, []string{}},
+		{REGEXP_FILTER, "^web[0-9]+$", []string{"web01"}, []string{"web", "myweb01"}},
+	}
+	for _, c := range cases {
+		tf, err := newTagFilter(structs.OTSDBMetricsQueryExpTags{Type: c.filterType, Tagk: "host", Filter: c.filter})
+		assert.NoError(t, err)
+		for _, v := range c.matching {
+			assert.True(t, tf.matches(v), "%v(%v) should match %v", c.filterType, c.filter, v)
+		}
+		for _, v := range c.notMatching {
+			assert.False(t, tf.matches(v), "%v(%v) should not match %v", c.filterType, c.filter, v)
+		}
+	}
+
+	_, err := newTagFilter(structs.OTSDBMetricsQueryExpTags{Type: REGEXP_FILTER, Tagk: "host", Filter: "web("})
+	assert.Error(t, err)
+	_, err = newTagFilter(structs.OTSDBMetricsQueryExpTags{Type: "not_key", Tagk: "host"})
+	assert.Error(t, err)
+	_, err = newTagFilter(structs.OTSDBMetricsQueryExpTags{Type: WILDCARD_FILTER, Filter: "*"})
+	assert.Error(t, err)
+}
+
+func Test_applyRate(t *testing.T) {
+	dps := map[uint32]float64{100: 10, 110: 30, 120: 20, 130: 50}
+
+	rates := applyRate(dps, structs.OTSDBRateOptions{})
+	assert.Equal(t, map[uint32]float64{110: 2, 120: -1, 130: 3}, rates)
+
+	// the counter wrapped at 100 between 110 and 120
+	rates = applyRate(dps, structs.OTSDBRateOptions{Counter: true, CounterMax: 100})
+	assert.Equal(t, map[uint32]float64{110: 2, 120: 9, 130: 3}, rates)
+
+	rates = applyRate(dps, structs.OTSDBRateOptions{Counter: true, CounterMax: 100, ResetValue: 5})
+	assert.Equal(t, map[uint32]float64{110: 2, 120: 0, 130: 3}, rates)
+
+	rates = applyRate(dps, structs.OTSDBRateOptions{Counter: true, DropResets: true})
+	assert.Equal(t, map[uint32]float64{110: 2, 130: 3}, rates)
+
+	rates = applyRate(map[uint32]float64{100: 10, 110: math.NaN(), 120: 30}, structs.OTSDBRateOptions{})
+	assert.True(t, math.IsNaN(rates[110]))
+	assert.Equal(t, float64(1), rates[120])
+}
+
+func Test_aggregateSeries(t *testing.T) {
+	subQuery := &structs.OTSDBSubQuery{
+		Aggregator: "sum",
+		Metric:     "cpu",
+		Downsample: "1m-avg-nan",
+		Filters: []structs.OTSDBMetricsQueryExpTags{
+			{Type: WILDCARD_FILTER, Tagk: "dc", Filter: "*", GroupBy: true},
+			{Type: REGEXP_FILTER, Tagk: "host", Filter: "^web"},
+			{Type: NOT_LITERAL_OR_FILTER, Tagk: "host", Filter: "web05"},
+		},
+	}
+	q, err := newOTSDBQuery(subQuery, dtu.MetricsTimeRange{StartEpochSec: 30, EndEpochSec: 200}, 0)
+	assert.NoError(t, err)
+	assert.Len(t, q.filters, 3)
+
+	// every filtered tag key is read from the tags trees, with the narrowest filter they support
+	assert.Len(t, q.mQuery.TagsFilters, 2)
+	assert.Equal(t, "dc", q.mQuery.TagsFilters[0].TagKey)
+	assert.Equal(t, tagstree.STAR, q.mQuery.TagsFilters[0].RawTagValue)
+	assert.Equal(t, "host", q.mQuery.TagsFilters[1].TagKey)
+	assert.Equal(t, "web05", q.mQuery.TagsFilters[1].RawTagValue)
+	assert.Equal(t, segutils.NotEqual, q.mQuery.TagsFilters[1].TagOperator)
+
+	results := map[string]map[uint32]float64{
+		"east`web01`": {60: 1, 120: 2},
+		"east`web02`": {60: 3},
+		"west`web03`": {180: 5},
+		"east`db01`":  {60: 100},
+		"`web04`":     {60: 100},
+	}
+	allSeries := q.getMatchingSeries(results)
+	assert.Len(t, allSeries, 3)
+	for _, series := range allSeries {
+		q.applyFillPolicy(series)
+	}
+
+	resp := q.aggregateSeries(allSeries, false)
+	assert.Len(t, resp, 2)
+	assert.Equal(t, map[string]string{"dc": "east"}, resp[0].Tags)
+	assert.Equal(t, []string{"host"}, resp[0].AggregateTags)
+	assert.Equal(t, map[uint64]interface{}{0: FILLED_NAN_VALUE, 60: float64(4), 120: float64(2), 180: FILLED_NAN_VALUE}, resp[0].Dps)
+	assert.Equal(t, map[string]string{"dc": "west", "host": "web03"}, resp[1].Tags)
+	assert.Equal(t, []string{}, resp[1].AggregateTags)
+	assert.Equal(t, map[uint64]interface{}{0: FILLED_NAN_VALUE, 60: FILLED_NAN_VALUE, 120: FILLED_NAN_VALUE, 180: float64(5)}, resp[1].Dps)
+
+	q.fillPolicy = FILL_POLICY_NULL
+	resp = q.aggregateSeries(allSeries, false)
+	assert.Nil(t, resp[1].Dps[0])
+
+	q.aggregator = NO_AGGREGATION
+	resp = q.aggregateSeries(allSeries, true)
+	assert.Len(t, resp, 3)
+	for _, r := range resp {
+		assert.Len(t, r.AggregateTags, 0)
+		assert.Len(t, r.Dps, 4)
+		_, ok := r.Dps[180000]
+		assert.True(t, ok)
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package otsdbquery
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
@@ -33,25 +34,17 @@ import (
 	"github.com/valyala/fasthttp"
 )
 
+/*
+Handles /api/query. The sub queries are read from the m parameters of a GET request or from the json body of a
+POST request, which is what Grafana's OpenTSDB datasource sends
+*/
 func MetricsQueryParser(ctx *fasthttp.RequestCtx, myid uint64) {
 	var httpResp toputils.HttpServerResponse
-	queryReq := ctx.QueryArgs()
-	startStr := string(queryReq.Peek("start"))
-	endStr := string(queryReq.Peek("end"))
-	m := string(queryReq.Peek("m"))
-	mQRequest, err := ParseRequest(startStr, endStr, m, myid)
-	if err != nil {
-		ctx.SetStatusCode(fasthttp.StatusBadRequest)
-		httpResp.Message = err.Error()
-		httpResp.StatusCode = fasthttp.StatusBadRequest
-		toputils.WriteResponse(ctx, httpResp)
-		return
+	queryRequest, err := getQueryRequest(ctx)
+	var queries []*otsdbQuery
+	if err == nil {
+		queries, err = parseQueryRequest(queryRequest, myid)
 	}
-	qid := rutils.GetNextQid()
-	segment.LogMetricsQuery("metrics query parser", mQRequest, qid)
-	mQRequest.MetricsQuery.OrgId = myid
-	res := segment.ExecuteMetricsQuery(&mQRequest.MetricsQuery, &mQRequest.TimeRange, qid)
-	mQResponse, err := res.GetOTSDBResults(&mQRequest.MetricsQuery)
 	if err != nil {
 		ctx.SetStatusCode(fasthttp.StatusBadRequest)
 		httpResp.Message = err.Error()
@@ -59,11 +52,263 @@ func MetricsQueryParser(ctx *fasthttp.RequestCtx, myid uint64) {
 		toputils.WriteResponse(ctx, httpResp)
 		return
 	}
+
+	mQResponse := make([]*structs.OTSDBQueryResponse, 0)
+	for _, q := range queries {
+		qid := rutils.GetNextQid()
+		segment.LogMetricsQuery("metrics query parser", &structs.MetricsQueryRequest{MetricsQuery: q.mQuery, TimeRange: q.timeRange}, qid)
+		resp, err := q.execute(qid, queryRequest.MsResolution)
+		if err != nil {
+			ctx.SetStatusCode(fasthttp.StatusBadRequest)
+			httpResp.Message = err.Error()
+			httpResp.StatusCode = fasthttp.StatusBadRequest
+			toputils.WriteResponse(ctx, httpResp)
+			return
+		}
+		mQResponse = append(mQResponse, resp...)
+	}
 	toputils.WriteJsonResponse(ctx, &mQResponse)
 	ctx.SetContentType(toputils.ContentJson)
 	ctx.SetStatusCode(fasthttp.StatusOK)
 }
 
+func getQueryRequest(ctx *fasthttp.RequestCtx) (*structs.OTSDBQueryRequest, error) {
+	queryRequest := &structs.OTSDBQueryRequest{}
+	if ctx.IsPost() && len(ctx.PostBody()) > 0 {
+		err := json.Unmarshal(ctx.PostBody(), queryRequest)
+		if err != nil {
+			log.Errorf("getQueryRequest: failed to unmarshal the query request body. Error: %v", err)
+			return nil, fmt.Errorf("Invalid query request body. Error: %v", err)
+		}
+		return queryRequest, nil
+	}
+
+	queryArgs := ctx.QueryArgs()
+	queryRequest.Start = string(queryArgs.Peek("start"))
+	queryRequest.End = string(queryArgs.Peek("end"))
+	queryRequest.MsResolution = queryArgs.Has("ms")
+	for _, m := range queryArgs.PeekMulti("m") {
+		subQuery, err := parseMParam(string(m))
+		if err != nil {
+			return nil, err
+		}
+		queryRequest.Queries = append(queryRequest.Queries, *subQuery)
+	}
+	return queryRequest, nil
+}
+
+func parseQueryRequest(queryRequest *structs.OTSDBQueryRequest, myid uint64) ([]*otsdbQuery, error) {
+	startStr := getTimeString(queryRequest.Start)
+	if startStr == "" || len(queryRequest.Queries) == 0 {
+		return nil, fmt.Errorf("Invalid query - missing 'start' or 'm' parameter")
+	}
+	start, err := parseTime(startStr)
+	if err != nil {
+		log.Errorf("parseQueryRequest: Unable to parse Start time: %v. Error: %+v", startStr, err)
+		return nil, fmt.Errorf("Unable to parse Start time. Error: %+v", err)
+	}
+	end := uint32(time.Now().Unix())
+	endStr := getTimeString(queryRequest.End)
+	if endStr != "" {
+		end, err = parseTime(endStr)
+		if err != nil {
+			log.Errorf("parseQueryRequest: Unable to parse End time: %v. Error: %+v", endStr, err)
+			return nil, fmt.Errorf("Unable to parse End time. Error: %+v", err)
+		}
+	}
+	timeRange := dtu.MetricsTimeRange{StartEpochSec: start, EndEpochSec: end}
+
+	queries := make([]*otsdbQuery, 0, len(queryRequest.Queries))
+	for i := range queryRequest.Queries {
+		q, err := newOTSDBQuery(&queryRequest.Queries[i], timeRange, myid)
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, q)
+	}
+	return queries, nil
+}
+
+// The start and end of a json request may be a number or a string
+func getTimeString(t interface{}) string {
+	switch v := t.(type) {
+	case float64:
+		return fmt.Sprintf("%d", int64(v))
+	case string:
+		return v
+	default:
+		return ""
+	}
+}
+
+/*
+Parses the m parameter of a GET query, which looks like
+<aggregator>:[<downsample>:][rate[{counter[,<counter max>[,<reset value>]]}]:]<metric>[{<group by filters>}][{<filters>}]
+*/
+func parseMParam(m string) (*structs.OTSDBSubQuery, error) {
+	parts := splitOutsideBrackets(m, ':')
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("parseMParam: expected <aggregator>:<metric> in %v", m)
+	}
+	subQuery := &structs.OTSDBSubQuery{Aggregator: parts[0]}
+	for _, part := range parts[1 : len(parts)-1] {
+		if strings.HasPrefix(part, "rate") {
+			rateOptions, err := parseRateOptions(strings.TrimPrefix(part, "rate"))
+			if err != nil {
+				return nil, err
+			}
+			subQuery.Rate = true
+			subQuery.RateOptions = rateOptions
+		} else if subQuery.Downsample == "" {
+			subQuery.Downsample = part
+		} else {
+			return nil, fmt.Errorf("parseMParam: unexpected %v in %v", part, m)
+		}
+	}
+
+	metricPart := parts[len(parts)-1]
+	braceIdx := strings.Index(metricPart, "{")
+	if braceIdx == -1 {
+		subQuery.Metric = metricPart
+		return subQuery, nil
+	}
+	subQuery.Metric = metricPart[:braceIdx]
+	filterGroups, err := getBraceGroups(metricPart[braceIdx:])
+	if err != nil || len(filterGroups) > 2 {
+		return nil, fmt.Errorf("parseMParam: invalid filters in %v", m)
+	}
+	for i, group := range filterGroups {
+		if group == "" {
+			continue
+		}
+		for _, filterStr := range splitOutsideBrackets(group, ',') {
+			pair := strings.SplitN(filterStr, "=", 2)
+			if len(pair) != 2 {
+				return nil, fmt.Errorf("parseMParam: invalid filter %v, expected <tag key>=<filter>", filterStr)
+			}
+			// filters in the first braces group by their tag key
+			subQuery.Filters = append(subQuery.Filters, parseFilterValue(strings.TrimSpace(pair[0]), strings.TrimSpace(pair[1]), i == 0))
+		}
+	}
+	return subQuery, nil
+}
+
+// Splits s on sep, ignoring the seps that are inside braces or parentheses
+func splitOutsideBrackets(s string, sep byte) []string {
+	retVal := make([]string, 0)
+	depth := 0
+	partStart := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '{', '(':
+			depth++
+		case '}', ')':
+			depth--
+		case sep:
+			if depth == 0 {
+				retVal = append(retVal, s[partStart:i])
+				partStart = i + 1
+			}
+		}
+	}
+	return append(retVal, s[partStart:])
+}
+
+// Returns the contents of the {...} groups that s consists of
+func getBraceGroups(s string) ([]string, error) {
+	groups := make([]string, 0)
+	depth := 0
+	groupStart := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '{', '(':
+			if depth == 0 {
+				if s[i] != '{' {
+					return nil, fmt.Errorf("getBraceGroups: unexpected %c at %v in %v", s[i], i, s)
+				}
+				groupStart = i + 1
+			}
+			depth++
+		case '}', ')':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("getBraceGroups: unbalanced %c at %v in %v", s[i], i, s)
+			}
+			if depth == 0 {
+				groups = append(groups, s[groupStart:i])
+			}
+		default:
+			if depth == 0 {
+				return nil, fmt.Errorf("getBraceGroups: unexpected %c at %v in %v", s[i], i, s)
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("getBraceGroups: unclosed group in %v", s)
+	}
+	return groups, nil
+}
+
+// Parses the optional {counter[,<counter max>[,<reset value>]]} that follows rate. dropcounter drops the resets
+func parseRateOptions(optionsStr string) (structs.OTSDBRateOptions, error) {
+	var rateOptions structs.OTSDBRateOptions
+	if optionsStr == "" {
+		return rateOptions, nil
+	}
+	if !strings.HasPrefix(optionsStr, "{") || !strings.HasSuffix(optionsStr, "}") {
+		return rateOptions, fmt.Errorf("parseRateOptions: invalid rate options %v", optionsStr)
+	}
+	options := strings.Split(optionsStr[1:len(optionsStr)-1], ",")
+	switch strings.TrimSpace(options[0]) {
+	case "counter":
+		rateOptions.Counter = true
+	case "dropcounter":
+		rateOptions.Counter = true
+		rateOptions.DropResets = true
+	default:
+		return rateOptions, fmt.Errorf("parseRateOptions: invalid rate options %v, expected counter or dropcounter", optionsStr)
+	}
+	var err error
+	if len(options) > 1 && strings.TrimSpace(options[1]) != "" {
+		rateOptions.CounterMax, err = strconv.ParseFloat(strings.TrimSpace(options[1]), 64)
+		if err != nil {
+			return rateOptions, fmt.Errorf("parseRateOptions: invalid counter max in %v", optionsStr)
+		}
+	}
+	if len(options) > 2 && strings.TrimSpace(options[2]) != "" {
+		rateOptions.ResetValue, err = strconv.ParseFloat(strings.TrimSpace(options[2]), 64)
+		if err != nil {
+			return rateOptions, fmt.Errorf("parseRateOptions: invalid reset value in %v", optionsStr)
+		}
+	}
+	return rateOptions, nil
+}
+
+/*
+Converts the value of a tag, e.g. regexp(web.*), into a filter. Plain values are literal_or filters, or wildcard
+filters if they are *
+*/
+func parseFilterValue(tagKey string, value string, groupBy bool) structs.OTSDBMetricsQueryExpTags {
+	filter := structs.OTSDBMetricsQueryExpTags{Tagk: tagKey, GroupBy: groupBy}
+	openIdx := strings.Index(value, "(")
+	if openIdx > 0 && strings.HasSuffix(value, ")") && isFilterType(value[:openIdx]) {
+		filter.Type = value[:openIdx]
+		filter.Filter = value[openIdx+1 : len(value)-1]
+		return filter
+	}
+	if len(value) > 1 && ((strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`)) ||
+		(strings.HasPrefix(value, `'`) && strings.HasSuffix(value, `'`))) {
+		value = value[1 : len(value)-1]
+	}
+	filter.Filter = value
+	if value == "*" {
+		filter.Type = WILDCARD_FILTER
+	} else {
+		filter.Type = LITERAL_OR_FILTER
+	}
+	return filter
+}
+
 func ParseRequest(startStr string, endStr string, m string, myid uint64) (*structs.MetricsQueryRequest, error) {
 	if startStr == "" || m == "" {
 		return nil, fmt.Errorf("Invalid query - missing 'start' or 'm' parameter")
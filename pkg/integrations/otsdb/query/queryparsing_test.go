@@ -19,6 +19,8 @@ package otsdbquery
 import (
 	"testing"
 
+	"github.com/siglens/siglens/pkg/segment/structs"
+	segutils "github.com/siglens/siglens/pkg/segment/utils"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -89,3 +91,87 @@ func Test_MetricsQueryParsing(t *testing.T) {
 		}
 	}
 }
+
+func Test_parseMParam(t *testing.T) {
+	subQuery, err := parseMParam("sum:5m-avg-nan:rate{counter,1000,50}:cpu.usage{host=wildcard(web*),dc=a|b}{env=regexp(prod.*)}")
+	assert.NoError(t, err)
+	assert.Equal(t, "sum", subQuery.Aggregator)
+	assert.Equal(t, "5m-avg-nan", subQuery.Downsample)
+	assert.True(t, subQuery.Rate)
+	assert.Equal(t, structs.OTSDBRateOptions{Counter: true, CounterMax: 1000, ResetValue: 50}, subQuery.RateOptions)
+	assert.Equal(t, "cpu.usage", subQuery.Metric)
+	assert.Equal(t, []structs.OTSDBMetricsQueryExpTags{
+		{Type: WILDCARD_FILTER, Tagk: "host", Filter: "web*", GroupBy: true},
+		{Type: LITERAL_OR_FILTER, Tagk: "dc", Filter: "a|b", GroupBy: true},
+		{Type: REGEXP_FILTER, Tagk: "env", Filter: "prod.*", GroupBy: false},
+	}, subQuery.Filters)
+
+	subQuery, err = parseMParam("avg:rate{dropcounter}:1h-max:mem{}{host='server 1'}")
+	assert.NoError(t, err)
+	assert.Equal(t, "1h-max", subQuery.Downsample)
+	assert.True(t, subQuery.RateOptions.DropResets)
+	assert.Equal(t, []structs.OTSDBMetricsQueryExpTags{{Type: LITERAL_OR_FILTER, Tagk: "host", Filter: "server 1"}}, subQuery.Filters)
+
+	subQuery, err = parseMParam("max:mem")
+	assert.NoError(t, err)
+	assert.Equal(t, "mem", subQuery.Metric)
+	assert.Len(t, subQuery.Filters, 0)
+
+	invalidMs := []string{
+		"mem{host=a}",
+		"sum:5m-avg:1h-avg:mem",
+		"sum:rate{gauge}:mem",
+		"sum:mem{host=a}x",
+		"sum:mem{host=a}{dc=b}{env=c}",
+		"sum:mem{host}",
+		"sum:mem{host=regexp(a}",
+	}
+	for _, m := range invalidMs {
+		_, err = parseMParam(m)
+		assert.Error(t, err, m)
+	}
+}
+
+func Test_parseQueryRequest(t *testing.T) {
+	queryRequest := &structs.OTSDBQueryRequest{
+		Start: float64(1700000000),
+		End:   "1700003600",
+		Queries: []structs.OTSDBSubQuery{{
+			Aggregator: "sum",
+			Metric:     "cpu",
+			Downsample: "1m-max-zero",
+			Filters:    []structs.OTSDBMetricsQueryExpTags{{Type: LITERAL_OR_FILTER, Tagk: "host", Filter: "web01", GroupBy: true}},
+			Tags:       map[string]string{"dc": "*"},
+		}},
+	}
+	queries, err := parseQueryRequest(queryRequest, 0)
+	assert.NoError(t, err)
+	assert.Len(t, queries, 1)
+	q := queries[0]
+	assert.Equal(t, uint32(1700000000), q.timeRange.StartEpochSec)
+	assert.Equal(t, uint32(1700003600), q.timeRange.EndEpochSec)
+	assert.Equal(t, uint32(60), q.dsSeconds)
+	assert.Equal(t, FILL_POLICY_ZERO, q.fillPolicy)
+	assert.Equal(t, segutils.Max, q.mQuery.Downsampler.Aggregator.AggregatorFunction)
+	assert.Len(t, q.filters, 2)
+	// the literal is pushed down as it is and the wildcard reads every value of its tag key
+	assert.Len(t, q.mQuery.TagsFilters, 2)
+	assert.Equal(t, "host", q.mQuery.TagsFilters[0].TagKey)
+	assert.Equal(t, "web01", q.mQuery.TagsFilters[0].RawTagValue)
+	assert.Equal(t, "dc", q.mQuery.TagsFilters[1].TagKey)
+	assert.Equal(t, "*", q.mQuery.TagsFilters[1].RawTagValue)
+
+	queryRequest.Queries[0].Aggregator = "p99"
+	_, err = parseQueryRequest(queryRequest, 0)
+	assert.Error(t, err)
+
+	queryRequest.Queries[0].Aggregator = "sum"
+	queryRequest.Queries[0].Downsample = "1s-avg-nan"
+	queryRequest.End = "1800000000"
+	_, err = parseQueryRequest(queryRequest, 0)
+	assert.Error(t, err)
+
+	queryRequest.Queries = nil
+	_, err = parseQueryRequest(queryRequest, 0)
+	assert.Error(t, err)
+}
@@ -18,11 +18,15 @@ package writer
 
 import (
 	"encoding/json"
-	"strconv"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
 
 	"github.com/gogo/protobuf/proto"
 	"github.com/golang/snappy"
 	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/value"
 	"github.com/prometheus/prometheus/prompb"
 	. "github.com/siglens/siglens/pkg/segment/utils"
 	"github.com/siglens/siglens/pkg/segment/writer"
@@ -78,82 +82,84 @@ func PutMetrics(ctx *fasthttp.RequestCtx) {
 	compressed := ctx.PostBody()
 	processedCount, failedCount, err = HandlePutMetrics(compressed)
 	if err != nil {
-		writePrometheusResponse(ctx, processedCount, failedCount, err.Error(), fasthttp.StatusBadRequest)
+		statusCode := fasthttp.StatusBadRequest
+		if errors.Is(err, errWriteFailed) {
+			// Prometheus retries on 5xx. Resent samples land on the same timestamps and are merged by downsampling on read
+			statusCode = fasthttp.StatusInternalServerError
+		}
+		writePrometheusResponse(ctx, processedCount, failedCount, err.Error(), statusCode)
 		return
 	}
 	writePrometheusResponse(ctx, processedCount, failedCount, "", fasthttp.StatusOK)
 }
 
+var errWriteFailed = errors.New("failed to write samples")
+
+type prometheusSample struct {
+	Metric    string            `json:"metric"`
+	Tags      map[string]string `json:"tags"`
+	Timestamp int64             `json:"timestamp"`
+	Value     float64           `json:"value"`
+}
+
+/*
+Writes the samples of a remote write request into the metrics store. All labels of a series, along with
+metric=<__name__>, become the tags of the series.
+
+Samples don't have to arrive in order: the samples of each series are sorted before they are written, and
+samples older than the ones already stored are accepted, since reads sort the samples of a series.
+Duplicate timestamps within a series are written once. Returns errWriteFailed if the store failed to
+write some samples, so the request can be retried.
+*/
 func HandlePutMetrics(compressed []byte) (uint64, uint64, error) {
 	var successCount uint64 = 0
 	var failedCount uint64 = 0
 
 	req, err := decodeWriteRequest(compressed)
 	if err != nil {
-		return successCount, failedCount, nil
+		return successCount, failedCount, err
 	}
 
+	var writeErr error
 	for _, ts := range req.Timeseries {
-		metric := make(model.Metric, len(ts.Labels))
+		sample := prometheusSample{Tags: make(map[string]string, len(ts.Labels)+1)}
 		for _, l := range ts.Labels {
-			metric[model.LabelName(l.Name)] = model.LabelValue(l.Value)
+			if l.Name == model.MetricNameLabel {
+				sample.Metric = l.Value
+			}
+			sample.Tags[l.Name] = l.Value
 		}
+		if sample.Metric == "" {
+			log.Errorf("HandlePutMetrics: skipping %v samples of a series without a metric name", len(ts.Samples))
+			failedCount += uint64(len(ts.Samples))
+			continue
+		}
+		sample.Tags["metric"] = sample.Metric
 
-		for _, s := range ts.Samples {
-			var sample model.Sample = model.Sample{
-				Metric:    metric,
-				Value:     model.SampleValue(s.Value),
-				Timestamp: model.Time(s.Timestamp),
+		for _, s := range getSortedSamples(ts.Samples) {
+			if value.IsStaleNaN(s.Value) {
+				// staleness markers only mean the series stopped being scraped
+				continue
 			}
-
-			data, err := sample.MarshalJSON()
-			if err != nil {
+			if math.IsNaN(s.Value) || math.IsInf(s.Value, 0) {
 				failedCount++
-				log.Errorf("HandlePutMetrics: failed to marshal data, err: %+v", err)
 				continue
 			}
+			sample.Timestamp = s.Timestamp
+			sample.Value = s.Value
 
-			var dataJson map[string]interface{}
-			err = json.Unmarshal(data, &dataJson)
+			data, err := json.Marshal(&sample)
 			if err != nil {
 				failedCount++
-				log.Errorf("HandlePutMetrics: failed to Unmarshal data, err: %+v", err)
+				log.Errorf("HandlePutMetrics: failed to marshal data, err: %+v", err)
 				continue
 			}
 
-			var metricName string
-			tags := "{"
-			for key, val := range dataJson {
-				if key == "metric" {
-					valMap, ok := val.(map[string]interface{})
-					if ok {
-						for k, v := range valMap {
-							if k == "__name__" {
-								valString, ok := v.(string)
-								if ok {
-									metricName = valString
-								}
-							}
-							valString, ok := v.(string)
-							if ok {
-								tags += `"` + k + `":"` + valString + `",`
-							}
-						}
-					}
-				}
-			}
-			tags += `"metric"` + `:"` + metricName + `",`
-			if tags[len(tags)-1] == ',' {
-				tags = tags[:len(tags)-1]
-			}
-			tags += "}"
-
-			modifiedData := `{"metric":"` + metricName + `","tags":` + tags + `,"timestamp":` + strconv.FormatInt(s.Timestamp, 10) + `,"value":` + strconv.FormatFloat(s.Value, 'f', -1, 64) + `}`
-
-			err = writer.AddTimeSeriesEntryToInMemBuf([]byte(modifiedData), SIGNAL_METRICS_OTSDB, uint64(0))
+			err = writer.AddTimeSeriesEntryToInMemBuf(data, SIGNAL_METRICS_OTSDB, uint64(0))
 			if err != nil {
 				log.Errorf("HandlePutMetrics: failed to add time series entry %+v", err)
 				failedCount++
+				writeErr = err
 			} else {
 				successCount++
 			}
@@ -161,9 +167,44 @@ func HandlePutMetrics(compressed []byte) (uint64, uint64, error) {
 	}
 	bytesReceived := uint64(len(compressed))
 	usageStats.UpdateMetricsStats(bytesReceived, successCount, 0)
+	if writeErr != nil {
+		return successCount, failedCount, fmt.Errorf("%w: %v", errWriteFailed, writeErr)
+	}
 	return successCount, failedCount, nil
 }
 
+// Returns the samples sorted by timestamp, keeping the last sample of each duplicated timestamp
+func getSortedSamples(samples []prompb.Sample) []prompb.Sample {
+	isSorted := sort.SliceIsSorted(samples, func(i, j int) bool { return samples[i].Timestamp < samples[j].Timestamp })
+	if isSorted && !hasDuplicateTimestamps(samples) {
+		return samples
+	}
+	sorted := make([]prompb.Sample, len(samples))
+	copy(sorted, samples)
+	if !isSorted {
+		sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp < sorted[j].Timestamp })
+	}
+
+	retVal := sorted[:0]
+	for i, s := range sorted {
+		if i+1 < len(sorted) && sorted[i+1].Timestamp == s.Timestamp {
+			continue
+		}
+		retVal = append(retVal, s)
+	}
+	return retVal
+}
+
+// Returns true if two neighbouring samples of the sorted samples have the same timestamp
+func hasDuplicateTimestamps(sortedSamples []prompb.Sample) bool {
+	for i := 1; i < len(sortedSamples); i++ {
+		if sortedSamples[i].Timestamp == sortedSamples[i-1].Timestamp {
+			return true
+		}
+	}
+	return false
+}
+
 func writePrometheusResponse(ctx *fasthttp.RequestCtx, processedCount uint64, failedCount uint64, err string, code int) {
 
 	resp := PrometheusPutResp{Success: processedCount, Failed: failedCount}
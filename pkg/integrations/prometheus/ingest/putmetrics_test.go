@@ -17,6 +17,7 @@ limitations under the License.
 package writer
 
 import (
+	"math"
 	"os"
 	"sync/atomic"
 	"testing"
@@ -25,6 +26,7 @@ import (
 	"github.com/gogo/protobuf/proto"
 	"github.com/golang/snappy"
 	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/value"
 	"github.com/prometheus/prometheus/prompb"
 	"github.com/siglens/siglens/pkg/config"
 	"github.com/siglens/siglens/pkg/segment/writer"
@@ -67,3 +69,53 @@ func Test_PutMetrics(t *testing.T) {
 	err := os.RemoveAll(config.GetDataPath())
 	assert.NoError(t, err)
 }
+
+func Test_getSortedSamples(t *testing.T) {
+	sorted := []prompb.Sample{{Timestamp: 1, Value: 1}, {Timestamp: 2, Value: 2}}
+	assert.Equal(t, sorted, getSortedSamples(sorted))
+
+	unsorted := []prompb.Sample{{Timestamp: 3, Value: 3}, {Timestamp: 1, Value: 1}, {Timestamp: 3, Value: 4}, {Timestamp: 2, Value: 2}}
+	expected := []prompb.Sample{{Timestamp: 1, Value: 1}, {Timestamp: 2, Value: 2}, {Timestamp: 3, Value: 4}}
+	assert.Equal(t, expected, getSortedSamples(unsorted))
+	assert.Equal(t, int64(3), unsorted[0].Timestamp, "input should not be modified")
+
+	sortedDups := []prompb.Sample{{Timestamp: 1, Value: 1}, {Timestamp: 1, Value: 5}, {Timestamp: 2, Value: 2}, {Timestamp: 2, Value: 6}}
+	expected = []prompb.Sample{{Timestamp: 1, Value: 5}, {Timestamp: 2, Value: 6}}
+	assert.Equal(t, expected, getSortedSamples(sortedDups))
+	assert.Equal(t, float64(1), sortedDups[0].Value, "input should not be modified")
+}
+
+func Test_HandlePutMetricsInvalidSamples(t *testing.T) {
+	config.InitializeTestingConfig()
+	writer.InitWriterNode()
+
+	now := time.Now().UnixMilli()
+	writeRequest := prompb.WriteRequest{Timeseries: []prompb.TimeSeries{
+		{
+			Labels: []prompb.Label{{Name: model.MetricNameLabel, Value: "http_requests_total"}, {Name: "path", Value: `/a"b\c`}},
+			Samples: []prompb.Sample{
+				{Timestamp: now, Value: 2},
+				{Timestamp: now - 1000, Value: 1},
+				{Timestamp: now + 1000, Value: math.Float64frombits(value.StaleNaN)},
+				{Timestamp: now + 2000, Value: math.Inf(1)},
+			},
+		},
+		{
+			Labels:  []prompb.Label{{Name: "job", Value: "no_name"}},
+			Samples: []prompb.Sample{{Timestamp: now, Value: 1}},
+		},
+	}}
+	protoBytes, err := proto.Marshal(&writeRequest)
+	assert.NoError(t, err)
+
+	success, fail, err := HandlePutMetrics(snappy.Encode(nil, protoBytes))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), success)
+	assert.Equal(t, uint64(2), fail)
+
+	_, _, err = HandlePutMetrics([]byte("not snappy"))
+	assert.Error(t, err)
+
+	err = os.RemoveAll(config.GetDataPath())
+	assert.NoError(t, err)
+}
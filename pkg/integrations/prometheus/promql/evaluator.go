@@ -0,0 +1,880 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package promql
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	pql "github.com/influxdata/promql/v2"
+	"github.com/influxdata/promql/v2/pkg/labels"
+)
+
+// how far back an instant vector selector looks for the latest sample of a series
+const DEFAULT_LOOKBACK_DELTA = 5 * time.Minute
+
+const METRIC_NAME_LABEL = "__name__"
+
+type promSample struct {
+	T int64 // ms
+	V float64
+}
+
+type promSeries struct {
+	Labels  map[string]string
+	Samples []promSample // sorted by T
+}
+
+type promVectorElem struct {
+	Labels map[string]string
+	V      float64
+}
+
+type promVector []promVectorElem
+type promMatrix []*promSeries
+type promScalar float64
+
+// An evaluated value, one of promVector, promMatrix or promScalar
+type promValue interface{}
+
+// Returns all series of the metric with samples in [startMs, endMs] that match all the matchers
+type seriesFetcher func(metricName string, matchers []*labels.Matcher, startMs int64, endMs int64) ([]*promSeries, error)
+
+/*
+Evaluates PromQL expressions at one or more timestamps.
+
+Every selector is fetched once for the whole query range, and the expression is then evaluated in memory
+at each step, so a range query reads each series only once.
+*/
+type promqlEvaluator struct {
+	startMs       int64
+	endMs         int64
+	fetchSeries   seriesFetcher
+	lookbackDelta time.Duration
+	fetched       map[string][]*promSeries // selector string to its series over the whole query range
+}
+
+func newPromqlEvaluator(startMs int64, endMs int64, fetchSeries seriesFetcher) *promqlEvaluator {
+	return &promqlEvaluator{
+		startMs:       startMs,
+		endMs:         endMs,
+		fetchSeries:   fetchSeries,
+		lookbackDelta: DEFAULT_LOOKBACK_DELTA,
+		fetched:       make(map[string][]*promSeries),
+	}
+}
+
+func (ev *promqlEvaluator) getSeries(name string, matchers []*labels.Matcher, window time.Duration,
+	offset time.Duration) ([]*promSeries, error) {
+
+	matcherStrs := make([]string, 0, len(matchers))
+	for _, matcher := range matchers {
+		matcherStrs = append(matcherStrs, matcher.String())
+	}
+	key := fmt.Sprintf("%v|%v|%v|%v", name, strings.Join(matcherStrs, ","), window, offset)
+	if series, ok := ev.fetched[key]; ok {
+		return series, nil
+	}
+	if name == "" {
+		for _, matcher := range matchers {
+			if matcher.Name == METRIC_NAME_LABEL && matcher.Type == labels.MatchEqual {
+				name = matcher.Value
+			}
+		}
+	}
+	if name == "" {
+		return nil, errors.New("getSeries: a metric name is required in every selector")
+	}
+	startMs := ev.startMs - window.Milliseconds() - offset.Milliseconds()
+	endMs := ev.endMs - offset.Milliseconds()
+	series, err := ev.fetchSeries(name, matchers, startMs, endMs)
+	if err != nil {
+		return nil, err
+	}
+	ev.fetched[key] = series
+	return series, nil
+}
+
+func (ev *promqlEvaluator) eval(expr pql.Expr, ts int64) (promValue, error) {
+	switch e := expr.(type) {
+	case *pql.NumberLiteral:
+		return promScalar(e.Val), nil
+	case *pql.ParenExpr:
+		return ev.eval(e.Expr, ts)
+	case *pql.UnaryExpr:
+		val, err := ev.eval(e.Expr, ts)
+		if err != nil {
+			return nil, err
+		}
+		if e.Op != pql.ItemSUB {
+			return val, nil
+		}
+		switch v := val.(type) {
+		case promScalar:
+			return -v, nil
+		case promVector:
+			retVal := make(promVector, 0, len(v))
+			for _, elem := range v {
+				retVal = append(retVal, promVectorElem{Labels: dropMetricName(elem.Labels), V: -elem.V})
+			}
+			return retVal, nil
+		}
+		return nil, fmt.Errorf("eval: unary minus is not supported on %T", val)
+	case *pql.VectorSelector:
+		return ev.evalVectorSelector(e, ts)
+	case *pql.MatrixSelector:
+		return ev.evalMatrixSelector(e, ts)
+	case *pql.Call:
+		return ev.evalCall(e, ts)
+	case *pql.AggregateExpr:
+		return ev.evalAggregate(e, ts)
+	case *pql.BinaryExpr:
+		return ev.evalBinary(e, ts)
+	default:
+		return nil, fmt.Errorf("eval: unsupported expression type %T", expr)
+	}
+}
+
+func (ev *promqlEvaluator) evalVectorSelector(vs *pql.VectorSelector, ts int64) (promVector, error) {
+	allSeries, err := ev.getSeries(vs.Name, vs.LabelMatchers, ev.lookbackDelta, vs.Offset)
+	if err != nil {
+		return nil, err
+	}
+	refTime := ts - vs.Offset.Milliseconds()
+	retVal := make(promVector, 0, len(allSeries))
+	for _, series := range allSeries {
+		// index of the first sample after refTime
+		idx := sort.Search(len(series.Samples), func(i int) bool { return series.Samples[i].T > refTime })
+		if idx == 0 {
+			continue
+		}
+		sample := series.Samples[idx-1]
+		if sample.T <= refTime-ev.lookbackDelta.Milliseconds() {
+			continue
+		}
+		retVal = append(retVal, promVectorElem{Labels: series.Labels, V: sample.V})
+	}
+	return retVal, nil
+}
+
+// Returns the samples of each series in (ts - range - offset, ts - offset]
+func (ev *promqlEvaluator) evalMatrixSelector(ms *pql.MatrixSelector, ts int64) (promMatrix, error) {
+	allSeries, err := ev.getSeries(ms.Name, ms.LabelMatchers, ms.Range, ms.Offset)
+	if err != nil {
+		return nil, err
+	}
+	endTime := ts - ms.Offset.Milliseconds()
+	startTime := endTime - ms.Range.Milliseconds()
+	retVal := make(promMatrix, 0, len(allSeries))
+	for _, series := range allSeries {
+		startIdx := sort.Search(len(series.Samples), func(i int) bool { return series.Samples[i].T > startTime })
+		endIdx := sort.Search(len(series.Samples), func(i int) bool { return series.Samples[i].T > endTime })
+		if startIdx >= endIdx {
+			continue
+		}
+		retVal = append(retVal, &promSeries{Labels: series.Labels, Samples: series.Samples[startIdx:endIdx]})
+	}
+	return retVal, nil
+}
+
+func (ev *promqlEvaluator) evalCall(call *pql.Call, ts int64) (promValue, error) {
+	args := make([]promValue, len(call.Args))
+	for i, arg := range call.Args {
+		val, err := ev.eval(arg, ts)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = val
+	}
+
+	switch call.Func.Name {
+	case "time":
+		return promScalar(float64(ts) / 1000), nil
+	case "vector":
+		scalar, ok := args[0].(promScalar)
+		if !ok {
+			return nil, errors.New("evalCall: vector() expects a scalar")
+		}
+		return promVector{{Labels: map[string]string{}, V: float64(scalar)}}, nil
+	case "scalar":
+		vector, ok := args[0].(promVector)
+		if !ok {
+			return nil, errors.New("evalCall: scalar() expects a vector")
+		}
+		if len(vector) != 1 {
+			return promScalar(math.NaN()), nil
+		}
+		return promScalar(vector[0].V), nil
+	case "abs", "ceil", "floor", "sqrt", "exp", "ln":
+		vector, ok := args[0].(promVector)
+		if !ok {
+			return nil, fmt.Errorf("evalCall: %v() expects a vector", call.Func.Name)
+		}
+		return applyToVector(vector, getMathFunction(call.Func.Name)), nil
+	case "histogram_quantile":
+		phi, ok := args[0].(promScalar)
+		if !ok {
+			return nil, errors.New("evalCall: histogram_quantile() expects a scalar as first argument")
+		}
+		vector, ok := args[1].(promVector)
+		if !ok {
+			return nil, errors.New("evalCall: histogram_quantile() expects a vector as second argument")
+		}
+		return histogramQuantile(float64(phi), vector), nil
+	}
+
+	matrix, ok := args[0].(promMatrix)
+	if !ok {
+		return nil, fmt.Errorf("evalCall: unsupported function %v", call.Func.Name)
+	}
+	ms, ok := call.Args[0].(*pql.MatrixSelector)
+	if !ok {
+		return nil, fmt.Errorf("evalCall: %v() expects a range vector selector", call.Func.Name)
+	}
+	rangeEnd := ts - ms.Offset.Milliseconds()
+	rangeStart := rangeEnd - ms.Range.Milliseconds()
+
+	retVal := make(promVector, 0, len(matrix))
+	for _, series := range matrix {
+		var val float64
+		var ok bool
+		switch call.Func.Name {
+		case "rate":
+			val, ok = extrapolatedRate(series.Samples, rangeStart, rangeEnd, true, true)
+		case "irate":
+			val, ok = instantRate(series.Samples)
+		case "avg_over_time", "sum_over_time", "min_over_time", "max_over_time", "count_over_time":
+			val, ok = aggregateOverTime(call.Func.Name, series.Samples)
+		default:
+			return nil, fmt.Errorf("evalCall: unsupported function %v", call.Func.Name)
+		}
+		if ok {
+			retVal = append(retVal, promVectorElem{Labels: dropMetricName(series.Labels), V: val})
+		}
+	}
+	return retVal, nil
+}
+
+func getMathFunction(name string) func(float64) float64 {
+	switch name {
+	case "abs":
+		return math.Abs
+	case "ceil":
+		return math.Ceil
+	case "floor":
+		return math.Floor
+	case "sqrt":
+		return math.Sqrt
+	case "exp":
+		return math.Exp
+	default:
+		return math.Log
+	}
+}
+
+func applyToVector(vector promVector, fn func(float64) float64) promVector {
+	retVal := make(promVector, 0, len(vector))
+	for _, elem := range vector {
+		retVal = append(retVal, promVectorElem{Labels: dropMetricName(elem.Labels), V: fn(elem.V)})
+	}
+	return retVal
+}
+
+/*
+Returns the increase of the samples over [rangeStart, rangeEnd], extrapolated to the range boundaries the
+same way Prometheus does. If isCounter is set, drops in value are treated as counter resets, and if isRate
+is set the increase is divided by the length of the range.
+*/
+func extrapolatedRate(samples []promSample, rangeStart int64, rangeEnd int64, isCounter bool, isRate bool) (float64, bool) {
+	if len(samples) < 2 {
+		return 0, false
+	}
+	first := samples[0]
+	last := samples[len(samples)-1]
+
+	resultValue := last.V - first.V
+	if isCounter {
+		var lastValue float64
+		for _, sample := range samples {
+			if sample.V < lastValue {
+				resultValue += lastValue
+			}
+			lastValue = sample.V
+		}
+	}
+
+	durationToStart := float64(first.T-rangeStart) / 1000
+	durationToEnd := float64(rangeEnd-last.T) / 1000
+	sampledInterval := float64(last.T-first.T) / 1000
+	averageDurationBetweenSamples := sampledInterval / float64(len(samples)-1)
+
+	// a counter can't have been below zero, so don't extrapolate the start further than where it would be zero
+	if isCounter && resultValue > 0 && first.V >= 0 {
+		durationToZero := sampledInterval * (first.V / resultValue)
+		if durationToZero < durationToStart {
+			durationToStart = durationToZero
+		}
+	}
+
+	// extrapolate to the range boundaries only if they are close to the first and last sample,
+	// else extrapolate by half the average interval
+	extrapolationThreshold := averageDurationBetweenSamples * 1.1
+	extrapolateToInterval := sampledInterval
+	if durationToStart < extrapolationThreshold {
+		extrapolateToInterval += durationToStart
+	} else {
+		extrapolateToInterval += averageDurationBetweenSamples / 2
+	}
+	if durationToEnd < extrapolationThreshold {
+		extrapolateToInterval += durationToEnd
+	} else {
+		extrapolateToInterval += averageDurationBetweenSamples / 2
+	}
+
+	resultValue = resultValue * (extrapolateToInterval / sampledInterval)
+	if isRate {
+		resultValue = resultValue / (float64(rangeEnd-rangeStart) / 1000)
+	}
+	return resultValue, true
+}
+
+// Returns the per second rate between the last two samples
+func instantRate(samples []promSample) (float64, bool) {
+	if len(samples) < 2 {
+		return 0, false
+	}
+	last := samples[len(samples)-1]
+	previous := samples[len(samples)-2]
+	if last.T == previous.T {
+		return 0, false
+	}
+	resultValue := last.V - previous.V
+	if last.V < previous.V {
+		// counter reset
+		resultValue = last.V
+	}
+	return resultValue / (float64(last.T-previous.T) / 1000), true
+}
+
+func aggregateOverTime(funcName string, samples []promSample) (float64, bool) {
+	if len(samples) == 0 {
+		return 0, false
+	}
+	retVal := samples[0].V
+	sum := float64(0)
+	for _, sample := range samples {
+		sum += sample.V
+		switch funcName {
+		case "min_over_time":
+			retVal = math.Min(retVal, sample.V)
+		case "max_over_time":
+			retVal = math.Max(retVal, sample.V)
+		}
+	}
+	switch funcName {
+	case "avg_over_time":
+		return sum / float64(len(samples)), true
+	case "sum_over_time":
+		return sum, true
+	case "count_over_time":
+		return float64(len(samples)), true
+	}
+	return retVal, true
+}
+
+type histogramBucket struct {
+	upperBound float64
+	count      float64
+}
+
+// Computes the quantile of each histogram in the vector, where buckets of the same histogram
+// share all labels but le
+func histogramQuantile(phi float64, vector promVector) promVector {
+	allBuckets := make(map[string][]histogramBucket)
+	allLabels := make(map[string]map[string]string)
+	for _, elem := range vector {
+		upperBound, err := strconv.ParseFloat(elem.Labels["le"], 64)
+		if err != nil {
+			continue
+		}
+		histogramLabels := dropLabels(elem.Labels, []string{"le", METRIC_NAME_LABEL})
+		key := getLabelsSignature(histogramLabels)
+		allBuckets[key] = append(allBuckets[key], histogramBucket{upperBound: upperBound, count: elem.V})
+		allLabels[key] = histogramLabels
+	}
+
+	retVal := make(promVector, 0, len(allBuckets))
+	for key, buckets := range allBuckets {
+		retVal = append(retVal, promVectorElem{Labels: allLabels[key], V: bucketQuantile(phi, buckets)})
+	}
+	return retVal
+}
+
+// Interpolates the quantile linearly within the bucket it falls in, as Prometheus does
+func bucketQuantile(phi float64, buckets []histogramBucket) float64 {
+	if phi < 0 {
+		return math.Inf(-1)
+	}
+	if phi > 1 {
+		return math.Inf(+1)
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].upperBound < buckets[j].upperBound })
+	if len(buckets) < 2 || !math.IsInf(buckets[len(buckets)-1].upperBound, +1) {
+		return math.NaN()
+	}
+	// counts of a scraped histogram can be slightly off, so make them monotonic
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i].count < buckets[i-1].count {
+			buckets[i].count = buckets[i-1].count
+		}
+	}
+
+	rank := phi * buckets[len(buckets)-1].count
+	b := sort.Search(len(buckets)-1, func(i int) bool { return buckets[i].count >= rank })
+	if b == len(buckets)-1 {
+		return buckets[len(buckets)-2].upperBound
+	}
+	if b == 0 && buckets[0].upperBound <= 0 {
+		return buckets[0].upperBound
+	}
+	bucketStart := float64(0)
+	bucketEnd := buckets[b].upperBound
+	count := buckets[b].count
+	if b > 0 {
+		bucketStart = buckets[b-1].upperBound
+		count -= buckets[b-1].count
+		rank -= buckets[b-1].count
+	}
+	return bucketStart + (bucketEnd-bucketStart)*(rank/count)
+}
+
+func (ev *promqlEvaluator) evalAggregate(agg *pql.AggregateExpr, ts int64) (promValue, error) {
+	val, err := ev.eval(agg.Expr, ts)
+	if err != nil {
+		return nil, err
+	}
+	vector, ok := val.(promVector)
+	if !ok {
+		return nil, fmt.Errorf("evalAggregate: %v expects a vector", agg.Op)
+	}
+
+	var param float64
+	if agg.Param != nil {
+		paramVal, err := ev.eval(agg.Param, ts)
+		if err != nil {
+			return nil, err
+		}
+		scalar, ok := paramVal.(promScalar)
+		if !ok {
+			return nil, fmt.Errorf("evalAggregate: the parameter of %v must be a scalar", agg.Op)
+		}
+		param = float64(scalar)
+	}
+
+	groupKeys := make([]string, 0)
+	groupLabels := make(map[string]map[string]string)
+	groupElems := make(map[string]promVector)
+	for _, elem := range vector {
+		var grpLabels map[string]string
+		if agg.Without {
+			grpLabels = dropLabels(elem.Labels, append([]string{METRIC_NAME_LABEL}, agg.Grouping...))
+		} else {
+			grpLabels = keepLabels(elem.Labels, agg.Grouping)
+		}
+		key := getLabelsSignature(grpLabels)
+		if _, ok := groupElems[key]; !ok {
+			groupKeys = append(groupKeys, key)
+			groupLabels[key] = grpLabels
+		}
+		groupElems[key] = append(groupElems[key], elem)
+	}
+
+	retVal := make(promVector, 0, len(groupKeys))
+	for _, key := range groupKeys {
+		elems := groupElems[key]
+		switch agg.Op {
+		case pql.ItemTopK, pql.ItemBottomK:
+			sort.SliceStable(elems, func(i, j int) bool {
+				if agg.Op == pql.ItemTopK {
+					return elems[i].V > elems[j].V
+				}
+				return elems[i].V < elems[j].V
+			})
+			k := int(param)
+			if k > len(elems) {
+				k = len(elems)
+			}
+			for i := 0; i < k; i++ {
+				retVal = append(retVal, elems[i])
+			}
+			continue
+		}
+
+		values := make([]float64, 0, len(elems))
+		for _, elem := range elems {
+			values = append(values, elem.V)
+		}
+		aggVal, err := aggregateValues(agg.Op, values, param)
+		if err != nil {
+			return nil, err
+		}
+		retVal = append(retVal, promVectorElem{Labels: groupLabels[key], V: aggVal})
+	}
+	return retVal, nil
+}
+
+func aggregateValues(op pql.ItemType, values []float64, param float64) (float64, error) {
+	sum := float64(0)
+	for _, value := range values {
+		sum += value
+	}
+	switch op {
+	case pql.ItemSum:
+		return sum, nil
+	case pql.ItemAvg:
+		return sum / float64(len(values)), nil
+	case pql.ItemCount:
+		return float64(len(values)), nil
+	case pql.ItemMin, pql.ItemMax:
+		retVal := values[0]
+		for _, value := range values {
+			if op == pql.ItemMin {
+				retVal = math.Min(retVal, value)
+			} else {
+				retVal = math.Max(retVal, value)
+			}
+		}
+		return retVal, nil
+	case pql.ItemStddev, pql.ItemStdvar:
+		mean := sum / float64(len(values))
+		variance := float64(0)
+		for _, value := range values {
+			variance += (value - mean) * (value - mean)
+		}
+		variance = variance / float64(len(values))
+		if op == pql.ItemStddev {
+			return math.Sqrt(variance), nil
+		}
+		return variance, nil
+	case pql.ItemQuantile:
+		if param < 0 {
+			return math.Inf(-1), nil
+		}
+		if param > 1 {
+			return math.Inf(+1), nil
+		}
+		sort.Float64s(values)
+		if len(values) == 1 {
+			return values[0], nil
+		}
+		// same interpolation between the closest ranks as Prometheus' quantile
+		rank := param * float64(len(values)-1)
+		lowerIdx := math.Max(0, math.Floor(rank))
+		upperIdx := math.Min(float64(len(values)-1), lowerIdx+1)
+		weight := rank - math.Floor(rank)
+		return values[int(lowerIdx)]*(1-weight) + values[int(upperIdx)]*weight, nil
+	default:
+		return 0, fmt.Errorf("aggregateValues: unsupported aggregation %v", op)
+	}
+}
+
+func (ev *promqlEvaluator) evalBinary(expr *pql.BinaryExpr, ts int64) (promValue, error) {
+	lhs, err := ev.eval(expr.LHS, ts)
+	if err != nil {
+		return nil, err
+	}
+	rhs, err := ev.eval(expr.RHS, ts)
+	if err != nil {
+		return nil, err
+	}
+
+	lhsScalar, lhsIsScalar := lhs.(promScalar)
+	rhsScalar, rhsIsScalar := rhs.(promScalar)
+	lhsVector, lhsIsVector := lhs.(promVector)
+	rhsVector, rhsIsVector := rhs.(promVector)
+
+	switch {
+	case lhsIsScalar && rhsIsScalar:
+		value, keep := binaryOp(expr.Op, float64(lhsScalar), float64(rhsScalar))
+		if isComparisonOp(expr.Op) {
+			value = boolToFloat(keep)
+		}
+		return promScalar(value), nil
+	case lhsIsVector && rhsIsScalar:
+		return vectorScalarOp(expr, lhsVector, float64(rhsScalar), false), nil
+	case lhsIsScalar && rhsIsVector:
+		return vectorScalarOp(expr, rhsVector, float64(lhsScalar), true), nil
+	case lhsIsVector && rhsIsVector:
+		return vectorVectorOp(expr, lhsVector, rhsVector)
+	default:
+		return nil, fmt.Errorf("evalBinary: unsupported operands %T and %T", lhs, rhs)
+	}
+}
+
+func vectorScalarOp(expr *pql.BinaryExpr, vector promVector, scalar float64, swap bool) promVector {
+	retVal := make(promVector, 0, len(vector))
+	for _, elem := range vector {
+		lv, rv := elem.V, scalar
+		if swap {
+			lv, rv = rv, lv
+		}
+		value, keep := binaryOp(expr.Op, lv, rv)
+		if isComparisonOp(expr.Op) {
+			// a comparison keeps the value of the vector, even if it is on the right
+			value = elem.V
+			if expr.ReturnBool {
+				value = boolToFloat(keep)
+				keep = true
+			}
+		}
+		if !keep {
+			continue
+		}
+		elemLabels := elem.Labels
+		if !isComparisonOp(expr.Op) || expr.ReturnBool {
+			elemLabels = dropMetricName(elemLabels)
+		}
+		retVal = append(retVal, promVectorElem{Labels: elemLabels, V: value})
+	}
+	return retVal
+}
+
+func vectorVectorOp(expr *pql.BinaryExpr, lhs promVector, rhs promVector) (promVector, error) {
+	matching := expr.VectorMatching
+	if matching == nil {
+		matching = &pql.VectorMatching{Card: pql.CardOneToOne}
+	}
+
+	getSignature := func(elemLabels map[string]string) string {
+		if matching.On {
+			return getLabelsSignature(keepLabels(elemLabels, matching.MatchingLabels))
+		}
+		return getLabelsSignature(dropLabels(elemLabels, append([]string{METRIC_NAME_LABEL}, matching.MatchingLabels...)))
+	}
+
+	switch expr.Op {
+	case pql.ItemLAND, pql.ItemLOR, pql.ItemLUnless:
+		rhsSignatures := make(map[string]struct{}, len(rhs))
+		for _, elem := range rhs {
+			rhsSignatures[getSignature(elem.Labels)] = struct{}{}
+		}
+		retVal := make(promVector, 0, len(lhs))
+		lhsSignatures := make(map[string]struct{}, len(lhs))
+		for _, elem := range lhs {
+			sig := getSignature(elem.Labels)
+			lhsSignatures[sig] = struct{}{}
+			_, inRhs := rhsSignatures[sig]
+			if (expr.Op == pql.ItemLAND && inRhs) || (expr.Op == pql.ItemLUnless && !inRhs) || expr.Op == pql.ItemLOR {
+				retVal = append(retVal, elem)
+			}
+		}
+		if expr.Op == pql.ItemLOR {
+			for _, elem := range rhs {
+				if _, ok := lhsSignatures[getSignature(elem.Labels)]; !ok {
+					retVal = append(retVal, elem)
+				}
+			}
+		}
+		return retVal, nil
+	}
+
+	// for group_right, the right side is the many side
+	manySide, oneSide := lhs, rhs
+	if matching.Card == pql.CardOneToMany {
+		manySide, oneSide = rhs, lhs
+	}
+
+	oneSideBySig := make(map[string]promVectorElem, len(oneSide))
+	for _, elem := range oneSide {
+		sig := getSignature(elem.Labels)
+		if _, ok := oneSideBySig[sig]; ok {
+			return nil, fmt.Errorf("vectorVectorOp: found duplicate series for the match group %v on the %v side",
+				keepLabels(elem.Labels, matching.MatchingLabels), getOneSideName(matching.Card))
+		}
+		oneSideBySig[sig] = elem
+	}
+
+	retVal := make(promVector, 0, len(manySide))
+	seenOneToOne := make(map[string]struct{})
+	for _, manyElem := range manySide {
+		sig := getSignature(manyElem.Labels)
+		oneElem, ok := oneSideBySig[sig]
+		if !ok {
+			continue
+		}
+		if matching.Card == pql.CardOneToOne {
+			if _, ok := seenOneToOne[sig]; ok {
+				return nil, errors.New("vectorVectorOp: many-to-many matching not allowed, use group_left or group_right")
+			}
+			seenOneToOne[sig] = struct{}{}
+		}
+
+		lv, rv := manyElem.V, oneElem.V
+		if matching.Card == pql.CardOneToMany {
+			lv, rv = rv, lv
+		}
+		value, keep := binaryOp(expr.Op, lv, rv)
+		if isComparisonOp(expr.Op) {
+			value = lv
+			if expr.ReturnBool {
+				value = boolToFloat(keep)
+				keep = true
+			}
+		}
+		if !keep {
+			continue
+		}
+		retVal = append(retVal, promVectorElem{Labels: getBinaryResultLabels(expr, matching, manyElem.Labels, oneElem.Labels), V: value})
+	}
+	return retVal, nil
+}
+
+func getOneSideName(card pql.VectorMatchCardinality) string {
+	if card == pql.CardOneToMany {
+		return "left"
+	}
+	return "right"
+}
+
+func getBinaryResultLabels(expr *pql.BinaryExpr, matching *pql.VectorMatching, manyLabels map[string]string,
+	oneLabels map[string]string) map[string]string {
+
+	resultLabels := manyLabels
+	if !isComparisonOp(expr.Op) || expr.ReturnBool {
+		resultLabels = dropMetricName(resultLabels)
+	}
+	if matching.Card == pql.CardOneToOne {
+		if matching.On {
+			return keepLabels(resultLabels, matching.MatchingLabels)
+		}
+		return dropLabels(resultLabels, matching.MatchingLabels)
+	}
+	if len(matching.Include) == 0 {
+		return resultLabels
+	}
+	resultLabels = copyLabels(resultLabels)
+	for _, name := range matching.Include {
+		if value, ok := oneLabels[name]; ok && value != "" {
+			resultLabels[name] = value
+		} else {
+			delete(resultLabels, name)
+		}
+	}
+	return resultLabels
+}
+
+// Returns the result of the operation and, for comparisons, whether it is true
+func binaryOp(op pql.ItemType, lhs float64, rhs float64) (float64, bool) {
+	switch op {
+	case pql.ItemADD:
+		return lhs + rhs, true
+	case pql.ItemSUB:
+		return lhs - rhs, true
+	case pql.ItemMUL:
+		return lhs * rhs, true
+	case pql.ItemDIV:
+		return lhs / rhs, true
+	case pql.ItemPOW:
+		return math.Pow(lhs, rhs), true
+	case pql.ItemMOD:
+		return math.Mod(lhs, rhs), true
+	case pql.ItemEQL:
+		return lhs, lhs == rhs
+	case pql.ItemNEQ:
+		return lhs, lhs != rhs
+	case pql.ItemGTR:
+		return lhs, lhs > rhs
+	case pql.ItemLSS:
+		return lhs, lhs < rhs
+	case pql.ItemGTE:
+		return lhs, lhs >= rhs
+	case pql.ItemLTE:
+		return lhs, lhs <= rhs
+	default:
+		return math.NaN(), false
+	}
+}
+
+func isComparisonOp(op pql.ItemType) bool {
+	switch op {
+	case pql.ItemEQL, pql.ItemNEQ, pql.ItemGTR, pql.ItemLSS, pql.ItemGTE, pql.ItemLTE:
+		return true
+	default:
+		return false
+	}
+}
+
+func boolToFloat(val bool) float64 {
+	if val {
+		return 1
+	}
+	return 0
+}
+
+func getLabelsSignature(lbls map[string]string) string {
+	names := make([]string, 0, len(lbls))
+	for name := range lbls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var sb strings.Builder
+	for _, name := range names {
+		sb.WriteString(name)
+		sb.WriteByte(0xff)
+		sb.WriteString(lbls[name])
+		sb.WriteByte(0xff)
+	}
+	return sb.String()
+}
+
+func copyLabels(lbls map[string]string) map[string]string {
+	retVal := make(map[string]string, len(lbls))
+	for name, value := range lbls {
+		retVal[name] = value
+	}
+	return retVal
+}
+
+func keepLabels(lbls map[string]string, names []string) map[string]string {
+	retVal := make(map[string]string, len(names))
+	for _, name := range names {
+		if value, ok := lbls[name]; ok {
+			retVal[name] = value
+		}
+	}
+	return retVal
+}
+
+func dropLabels(lbls map[string]string, names []string) map[string]string {
+	retVal := copyLabels(lbls)
+	for _, name := range names {
+		delete(retVal, name)
+	}
+	return retVal
+}
+
+func dropMetricName(lbls map[string]string) map[string]string {
+	if _, ok := lbls[METRIC_NAME_LABEL]; !ok {
+		return lbls
+	}
+	return dropLabels(lbls, []string{METRIC_NAME_LABEL})
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package promql
+
+import (
+	"math"
+	"sort"
+	"testing"
+
+	pql "github.com/influxdata/promql/v2"
+	"github.com/influxdata/promql/v2/pkg/labels"
+	"github.com/siglens/siglens/pkg/segment/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+func getTestFetcher(allSeries []*promSeries) seriesFetcher {
+	return func(metricName string, matchers []*labels.Matcher, startMs int64, endMs int64) ([]*promSeries, error) {
+		retVal := make([]*promSeries, 0)
+		for _, series := range allSeries {
+			if series.Labels[METRIC_NAME_LABEL] != metricName {
+				continue
+			}
+			matched := true
+			for _, matcher := range matchers {
+				matched = matched && matcher.Matches(series.Labels[matcher.Name])
+			}
+			if matched {
+				retVal = append(retVal, series)
+			}
+		}
+		return retVal, nil
+	}
+}
+
+// a counter increasing by step every 10s from 0s to 300s
+func getCounterSeries(lbls map[string]string, step float64) *promSeries {
+	series := &promSeries{Labels: lbls}
+	for i := int64(0); i <= 30; i++ {
+		series.Samples = append(series.Samples, promSample{T: i * 10_000, V: float64(i) * step})
+	}
+	return series
+}
+
+func getTestSeries() []*promSeries {
+	return []*promSeries{
+		getCounterSeries(map[string]string{METRIC_NAME_LABEL: "http_requests_total", "job": "api", "instance": "a"}, 10),
+		getCounterSeries(map[string]string{METRIC_NAME_LABEL: "http_requests_total", "job": "api", "instance": "b"}, 20),
+		getCounterSeries(map[string]string{METRIC_NAME_LABEL: "http_requests_total", "job": "web", "instance": "c"}, 30),
+		{Labels: map[string]string{METRIC_NAME_LABEL: "latency_bucket", "le": "0.1"}, Samples: []promSample{{T: 300_000, V: 50}}},
+		{Labels: map[string]string{METRIC_NAME_LABEL: "latency_bucket", "le": "0.5"}, Samples: []promSample{{T: 300_000, V: 90}}},
+		{Labels: map[string]string{METRIC_NAME_LABEL: "latency_bucket", "le": "+Inf"}, Samples: []promSample{{T: 300_000, V: 100}}},
+	}
+}
+
+func evalTestQuery(t *testing.T, query string, ts int64) promValue {
+	expr, err := pql.ParseExpr(query)
+	assert.Nil(t, err)
+	ev := newPromqlEvaluator(ts, ts, getTestFetcher(getTestSeries()))
+	val, err := ev.eval(expr, ts)
+	assert.Nil(t, err)
+	return val
+}
+
+func getVectorValues(vector promVector, label string) map[string]float64 {
+	retVal := make(map[string]float64)
+	for _, elem := range vector {
+		retVal[elem.Labels[label]] = elem.V
+	}
+	return retVal
+}
+
+func Test_evalSelectors(t *testing.T) {
+	vector := evalTestQuery(t, `http_requests_total{job="api"}`, 300_000).(promVector)
+	assert.Equal(t, map[string]float64{"a": 300, "b": 600}, getVectorValues(vector, "instance"))
+
+	vector = evalTestQuery(t, `http_requests_total{instance=~"b|c"}`, 155_000).(promVector)
+	assert.Equal(t, map[string]float64{"b": 300, "c": 450}, getVectorValues(vector, "instance"))
+
+	// nothing is returned once the latest sample is older than the lookback delta
+	vector = evalTestQuery(t, `http_requests_total`, 300_000+DEFAULT_LOOKBACK_DELTA.Milliseconds()).(promVector)
+	assert.Len(t, vector, 0)
+
+	matrix := evalTestQuery(t, `http_requests_total{instance="a"}[1m]`, 300_000).(promMatrix)
+	assert.Len(t, matrix, 1)
+	assert.Len(t, matrix[0].Samples, 6)
+}
+
+func Test_evalFunctionsAndAggregations(t *testing.T) {
+	vector := evalTestQuery(t, `rate(http_requests_total[1m])`, 300_000).(promVector)
+	assert.Equal(t, map[string]float64{"a": 1, "b": 2, "c": 3}, getVectorValues(vector, "instance"))
+	_, hasName := vector[0].Labels[METRIC_NAME_LABEL]
+	assert.False(t, hasName)
+
+	vector = evalTestQuery(t, `sum by (job) (rate(http_requests_total[1m]))`, 300_000).(promVector)
+	assert.Equal(t, map[string]float64{"api": 3, "web": 3}, getVectorValues(vector, "job"))
+	assert.Len(t, vector[0].Labels, 1)
+
+	vector = evalTestQuery(t, `avg(http_requests_total)`, 300_000).(promVector)
+	assert.Equal(t, map[string]float64{"": 600}, getVectorValues(vector, "job"))
+
+	vector = evalTestQuery(t, `max without (instance) (http_requests_total)`, 300_000).(promVector)
+	assert.Equal(t, map[string]float64{"api": 600, "web": 900}, getVectorValues(vector, "job"))
+
+	vector = evalTestQuery(t, `histogram_quantile(0.9, latency_bucket)`, 300_000).(promVector)
+	assert.Len(t, vector, 1)
+	assert.InDelta(t, 0.5, vector[0].V, 1e-9)
+
+	vector = evalTestQuery(t, `histogram_quantile(0.7, latency_bucket)`, 300_000).(promVector)
+	assert.InDelta(t, 0.3, vector[0].V, 1e-9)
+}
+
+func Test_evalBinary(t *testing.T) {
+	vector := evalTestQuery(t, `http_requests_total{job="api"} / 100`, 300_000).(promVector)
+	assert.Equal(t, map[string]float64{"a": 3, "b": 6}, getVectorValues(vector, "instance"))
+
+	vector = evalTestQuery(t, `http_requests_total > 500`, 300_000).(promVector)
+	assert.Equal(t, map[string]float64{"b": 600, "c": 900}, getVectorValues(vector, "instance"))
+
+	vector = evalTestQuery(t, `http_requests_total > bool 500`, 300_000).(promVector)
+	assert.Equal(t, map[string]float64{"a": 0, "b": 1, "c": 1}, getVectorValues(vector, "instance"))
+
+	vector = evalTestQuery(t, `http_requests_total - http_requests_total offset 1m`, 300_000).(promVector)
+	assert.Equal(t, map[string]float64{"a": 60, "b": 120, "c": 180}, getVectorValues(vector, "instance"))
+
+	vector = evalTestQuery(t, `http_requests_total / on(job) group_left sum by (job) (http_requests_total)`, 300_000).(promVector)
+	values := getVectorValues(vector, "instance")
+	assert.InDelta(t, 1.0/3, values["a"], 1e-9)
+	assert.InDelta(t, 2.0/3, values["b"], 1e-9)
+	assert.InDelta(t, 1.0, values["c"], 1e-9)
+
+	vector = evalTestQuery(t, `http_requests_total unless http_requests_total{job="api"}`, 300_000).(promVector)
+	assert.Equal(t, map[string]float64{"c": 900}, getVectorValues(vector, "instance"))
+
+	scalar := evalTestQuery(t, `2 * (3 + 4)`, 300_000).(promScalar)
+	assert.Equal(t, promScalar(14), scalar)
+}
+
+func Test_evalRangeQuery(t *testing.T) {
+	expr, err := pql.ParseExpr(`sum(rate(http_requests_total[1m]))`)
+	assert.Nil(t, err)
+	result, err := evalRangeQuery(expr, 120_000, 300_000, 60_000, getTestFetcher(getTestSeries()))
+	assert.Nil(t, err)
+	assert.Len(t, result, 1)
+	assert.Len(t, result[0].Values, 4)
+	for _, value := range result[0].Values {
+		assert.Equal(t, "6", value[1])
+	}
+	assert.Equal(t, float64(120), result[0].Values[0][0])
+}
+
+func Test_extrapolatedRate(t *testing.T) {
+	// counter reset between the 2nd and 3rd sample: 10 -> 20 -> 5 -> 15 is an increase of 25
+	samples := []promSample{{T: 0, V: 10}, {T: 10_000, V: 20}, {T: 20_000, V: 5}, {T: 30_000, V: 15}}
+	increase, ok := extrapolatedRate(samples, 0, 30_000, true, false)
+	assert.True(t, ok)
+	assert.InDelta(t, 25, increase, 1e-9)
+
+	_, ok = extrapolatedRate(samples[:1], 0, 30_000, true, true)
+	assert.False(t, ok)
+
+	assert.True(t, math.IsNaN(bucketQuantile(0.5, []histogramBucket{{upperBound: 1, count: 1}})))
+}
+
+func Test_getPromSeriesFromResults(t *testing.T) {
+	tagsFilters := []*structs.TagsFilter{{TagKey: "host"}, {TagKey: "region"}}
+	results := map[string]map[uint32]float64{
+		"h1`us`": {20: 2, 10: 1},
+		"h2`eu`": {10: 3},
+	}
+	matcher, err := labels.NewMatcher(labels.MatchRegexp, "region", "u.*")
+	assert.Nil(t, err)
+
+	allSeries := getPromSeriesFromResults("cpu", tagsFilters, results, []*labels.Matcher{matcher})
+	assert.Len(t, allSeries, 1)
+	assert.Equal(t, map[string]string{METRIC_NAME_LABEL: "cpu", "host": "h1", "region": "us"}, allSeries[0].Labels)
+	assert.True(t, sort.SliceIsSorted(allSeries[0].Samples, func(i, j int) bool {
+		return allSeries[0].Samples[i].T < allSeries[0].Samples[j].T
+	}))
+	assert.Equal(t, []promSample{{T: 10_000, V: 1}, {T: 20_000, V: 2}}, allSeries[0].Samples)
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package promql
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	pql "github.com/influxdata/promql/v2"
+	"github.com/influxdata/promql/v2/pkg/labels"
+	"github.com/siglens/siglens/pkg/segment/writer/metrics/labelindex"
+	"github.com/siglens/siglens/pkg/utils"
+	"github.com/valyala/fasthttp"
+)
+
+/*
+	Handlers of the Prometheus metadata APIs, used by Grafana to fill the dropdowns of dashboard variables.
+	They are answered from the label index, so the datapoints are never read.
+*/
+
+// Handles /api/v1/labels
+func ProcessGetLabelNames(ctx *fasthttp.RequestCtx, myid uint64) {
+	selectors, startTs, endTs, ok := parseMetadataParams(ctx)
+	if !ok {
+		return
+	}
+	writePromqlMetadata(ctx, labelindex.GetLabelNames(myid, selectors, startTs, endTs))
+}
+
+// Handles /api/v1/label/{name}/values. The values of __name__ are the metric names
+func ProcessGetLabelValues(ctx *fasthttp.RequestCtx, myid uint64) {
+	labelName, ok := ctx.UserValue("name").(string)
+	if !ok || labelName == "" {
+		writePromqlError(ctx, fasthttp.StatusBadRequest, "bad_data", fmt.Errorf("invalid label name: %v", ctx.UserValue("name")))
+		return
+	}
+	selectors, startTs, endTs, ok := parseMetadataParams(ctx)
+	if !ok {
+		return
+	}
+	writePromqlMetadata(ctx, labelindex.GetLabelValues(myid, labelName, selectors, startTs, endTs))
+}
+
+// Handles /api/v1/series, which requires at least one match[] selector
+func ProcessGetSeries(ctx *fasthttp.RequestCtx, myid uint64) {
+	selectors, startTs, endTs, ok := parseMetadataParams(ctx)
+	if !ok {
+		return
+	}
+	if len(selectors) == 0 {
+		writePromqlError(ctx, fasthttp.StatusBadRequest, "bad_data", fmt.Errorf("no match[] parameter provided"))
+		return
+	}
+	writePromqlMetadata(ctx, labelindex.GetSeries(myid, selectors, startTs, endTs))
+}
+
+/*
+Reads the match[] selectors and the start and end time of a metadata request, from the url or the form encoded
+body. Without start and end all series are considered. Returns false after writing the error response if a
+parameter is invalid
+*/
+func parseMetadataParams(ctx *fasthttp.RequestCtx) ([][]*labels.Matcher, uint32, uint32, bool) {
+	rawSelectors := ctx.QueryArgs().PeekMulti("match[]")
+	rawSelectors = append(rawSelectors, ctx.PostArgs().PeekMulti("match[]")...)
+	selectors := make([][]*labels.Matcher, 0, len(rawSelectors))
+	for _, rawSelector := range rawSelectors {
+		matchers, err := pql.ParseMetricSelector(string(rawSelector))
+		if err != nil {
+			writePromqlError(ctx, fasthttp.StatusBadRequest, "bad_data", fmt.Errorf("invalid parameter match[]: %v", err))
+			return nil, 0, 0, false
+		}
+		selectors = append(selectors, matchers)
+	}
+
+	start, err := parsePromqlTime(string(ctx.FormValue("start")), time.Unix(0, 0))
+	if err != nil {
+		writePromqlError(ctx, fasthttp.StatusBadRequest, "bad_data", fmt.Errorf("invalid parameter start: %v", err))
+		return nil, 0, 0, false
+	}
+	end, err := parsePromqlTime(string(ctx.FormValue("end")), time.Unix(math.MaxUint32, 0))
+	if err != nil {
+		writePromqlError(ctx, fasthttp.StatusBadRequest, "bad_data", fmt.Errorf("invalid parameter end: %v", err))
+		return nil, 0, 0, false
+	}
+	if end.Before(start) {
+		writePromqlError(ctx, fasthttp.StatusBadRequest, "bad_data", fmt.Errorf("end timestamp must not be before start time"))
+		return nil, 0, 0, false
+	}
+	return selectors, clampToEpochSecs(start), clampToEpochSecs(end), true
+}
+
+func clampToEpochSecs(t time.Time) uint32 {
+	secs := t.Unix()
+	if secs < 0 {
+		return 0
+	}
+	if secs > math.MaxUint32 {
+		return math.MaxUint32
+	}
+	return uint32(secs)
+}
+
+func writePromqlMetadata(ctx *fasthttp.RequestCtx, data interface{}) {
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	utils.WriteJsonResponse(ctx, &promqlResponse{Status: "success", Data: data})
+}
@@ -406,18 +406,23 @@ func convertPqlToMetricsQuery(searchText string, startTime, endTime uint32, myid
 		})
 	case *pql.Call:
 		pql.Inspect(expr, func(node pql.Node, path []pql.Node) error {
-			switch node.(type) {
+			switch node := node.(type) {
 			case *pql.MatrixSelector:
 				function := extractFuncFromPath(path)
 
 				if mquery.TagsFilters != nil {
 					groupby = true
 				}
+				rangeWindowSecs := uint32(node.Range.Seconds())
 				switch function {
 				case "deriv":
 					mquery.Aggregator = structs.Aggreation{RangeFunction: segutils.Derivative}
 				case "rate":
-					mquery.Aggregator = structs.Aggreation{RangeFunction: segutils.Rate}
+					mquery.Aggregator = structs.Aggreation{RangeFunction: segutils.Rate, RangeWindowSecs: rangeWindowSecs}
+				case "increase":
+					mquery.Aggregator = structs.Aggreation{RangeFunction: segutils.Increase, RangeWindowSecs: rangeWindowSecs}
+				case "delta":
+					mquery.Aggregator = structs.Aggreation{RangeFunction: segutils.Delta, RangeWindowSecs: rangeWindowSecs}
 				default:
 					return fmt.Errorf("pql.Inspect: unsupported function type %v", function)
 				}
@@ -499,7 +504,7 @@ func convertPqlToMetricsQuery(searchText string, startTime, endTime uint32, myid
 	mquery.HashedMName = xxhash.Sum64String(metricName)
 
 	if mquery.Aggregator.AggregatorFunction == 0 && !groupby {
-		mquery.Aggregator = structs.Aggreation{AggregatorFunction: segutils.Sum}
+		mquery.Aggregator.AggregatorFunction = segutils.Sum
 	}
 	mquery.Downsampler = structs.Downsampler{Interval: 1, Unit: "m", Aggregator: mquery.Aggregator}
 	mquery.SelectAllSeries = !groupby // if group by is not present, then we need to select all series
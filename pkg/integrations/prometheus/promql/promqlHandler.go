@@ -0,0 +1,328 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package promql
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cespare/xxhash"
+	pql "github.com/influxdata/promql/v2"
+	"github.com/influxdata/promql/v2/pkg/labels"
+	dtu "github.com/siglens/siglens/pkg/common/dtypeutils"
+	rutils "github.com/siglens/siglens/pkg/readerUtils"
+	"github.com/siglens/siglens/pkg/segment"
+	tsidtracker "github.com/siglens/siglens/pkg/segment/results/mresults/tsid"
+	"github.com/siglens/siglens/pkg/segment/structs"
+	segutils "github.com/siglens/siglens/pkg/segment/utils"
+	"github.com/siglens/siglens/pkg/utils"
+	log "github.com/sirupsen/logrus"
+	"github.com/valyala/fasthttp"
+)
+
+// same limit as Prometheus on the number of steps of a range query
+const MAX_RANGE_QUERY_POINTS = 11000
+
+type promqlResponse struct {
+	Status    string      `json:"status"`
+	Data      interface{} `json:"data,omitempty"`
+	ErrorType string      `json:"errorType,omitempty"`
+	Error     string      `json:"error,omitempty"`
+}
+
+type promqlData struct {
+	ResultType string      `json:"resultType"`
+	Result     interface{} `json:"result"`
+}
+
+type promqlVectorResult struct {
+	Metric map[string]string `json:"metric"`
+	Value  []interface{}     `json:"value"`
+}
+
+type promqlMatrixResult struct {
+	Metric map[string]string `json:"metric"`
+	Values [][]interface{}   `json:"values"`
+}
+
+/*
+Handles the Prometheus HTTP API instant query. The query and time are read from the url or the form encoded
+body, as sent by Grafana's Prometheus datasource.
+
+Requests with a json body are the older siglens format and are handled by ProcessMetricsSearchRequest.
+*/
+func ProcessPromqlInstantQuery(ctx *fasthttp.RequestCtx, myid uint64) {
+	if strings.HasPrefix(string(ctx.Request.Header.ContentType()), "application/json") {
+		ProcessMetricsSearchRequest(ctx, myid)
+		return
+	}
+
+	query := string(ctx.FormValue("query"))
+	evalTime, err := parsePromqlTime(string(ctx.FormValue("time")), time.Now())
+	if err != nil {
+		writePromqlError(ctx, fasthttp.StatusBadRequest, "bad_data", fmt.Errorf("invalid parameter time: %v", err))
+		return
+	}
+	expr, err := pql.ParseExpr(query)
+	if err != nil {
+		writePromqlError(ctx, fasthttp.StatusBadRequest, "bad_data", err)
+		return
+	}
+
+	ts := evalTime.UnixMilli()
+	ev := newPromqlEvaluator(ts, ts, getMetricsStoreFetcher(myid))
+	val, err := ev.eval(expr, ts)
+	if err != nil {
+		log.Errorf("ProcessPromqlInstantQuery: failed to evaluate query %v, err: %v", query, err)
+		writePromqlError(ctx, fasthttp.StatusUnprocessableEntity, "execution", err)
+		return
+	}
+
+	var data promqlData
+	switch v := val.(type) {
+	case promScalar:
+		data = promqlData{ResultType: "scalar", Result: []interface{}{float64(ts) / 1000, formatPromqlValue(float64(v))}}
+	case promVector:
+		result := make([]promqlVectorResult, 0, len(v))
+		for _, elem := range v {
+			result = append(result, promqlVectorResult{
+				Metric: elem.Labels,
+				Value:  []interface{}{float64(ts) / 1000, formatPromqlValue(elem.V)},
+			})
+		}
+		data = promqlData{ResultType: "vector", Result: result}
+	case promMatrix:
+		result := make([]promqlMatrixResult, 0, len(v))
+		for _, series := range v {
+			result = append(result, promqlMatrixResult{Metric: series.Labels, Values: formatPromqlSamples(series.Samples)})
+		}
+		data = promqlData{ResultType: "matrix", Result: result}
+	}
+	writePromqlData(ctx, &data)
+}
+
+// Handles the Prometheus HTTP API range query, evaluating the query at every step from start to end
+func ProcessPromqlRangeQuery(ctx *fasthttp.RequestCtx, myid uint64) {
+	query := string(ctx.FormValue("query"))
+	startTime, err := parsePromqlTime(string(ctx.FormValue("start")), time.Time{})
+	if err != nil || startTime.IsZero() {
+		writePromqlError(ctx, fasthttp.StatusBadRequest, "bad_data", fmt.Errorf("invalid parameter start: %v", err))
+		return
+	}
+	endTime, err := parsePromqlTime(string(ctx.FormValue("end")), time.Time{})
+	if err != nil || endTime.IsZero() {
+		writePromqlError(ctx, fasthttp.StatusBadRequest, "bad_data", fmt.Errorf("invalid parameter end: %v", err))
+		return
+	}
+	if endTime.Before(startTime) {
+		writePromqlError(ctx, fasthttp.StatusBadRequest, "bad_data", errors.New("end timestamp must not be before start time"))
+		return
+	}
+	step, err := parseDuration(string(ctx.FormValue("step")))
+	if err != nil || step <= 0 {
+		writePromqlError(ctx, fasthttp.StatusBadRequest, "bad_data",
+			errors.New("zero or negative query resolution step widths are not accepted. Try a positive integer"))
+		return
+	}
+	if endTime.Sub(startTime)/step > MAX_RANGE_QUERY_POINTS {
+		writePromqlError(ctx, fasthttp.StatusBadRequest, "bad_data",
+			errors.New("exceeded maximum resolution of 11,000 points per timeseries. Try decreasing the query resolution (?step=XX)"))
+		return
+	}
+	expr, err := pql.ParseExpr(query)
+	if err != nil {
+		writePromqlError(ctx, fasthttp.StatusBadRequest, "bad_data", err)
+		return
+	}
+	if expr.Type() != pql.ValueTypeVector && expr.Type() != pql.ValueTypeScalar {
+		writePromqlError(ctx, fasthttp.StatusBadRequest, "bad_data",
+			fmt.Errorf("invalid expression type %q for range query, must be Scalar or instant Vector", expr.Type()))
+		return
+	}
+
+	result, err := evalRangeQuery(expr, startTime.UnixMilli(), endTime.UnixMilli(), step.Milliseconds(), getMetricsStoreFetcher(myid))
+	if err != nil {
+		log.Errorf("ProcessPromqlRangeQuery: failed to evaluate query %v, err: %v", query, err)
+		writePromqlError(ctx, fasthttp.StatusUnprocessableEntity, "execution", err)
+		return
+	}
+	writePromqlData(ctx, &promqlData{ResultType: "matrix", Result: result})
+}
+
+func evalRangeQuery(expr pql.Expr, startMs int64, endMs int64, stepMs int64, fetchSeries seriesFetcher) ([]promqlMatrixResult, error) {
+	ev := newPromqlEvaluator(startMs, endMs, fetchSeries)
+	allSeries := make(map[string]*promSeries)
+	seriesOrder := make([]string, 0)
+	addSample := func(lbls map[string]string, ts int64, v float64) {
+		sig := getLabelsSignature(lbls)
+		series, ok := allSeries[sig]
+		if !ok {
+			series = &promSeries{Labels: lbls}
+			allSeries[sig] = series
+			seriesOrder = append(seriesOrder, sig)
+		}
+		series.Samples = append(series.Samples, promSample{T: ts, V: v})
+	}
+
+	for ts := startMs; ts <= endMs; ts += stepMs {
+		val, err := ev.eval(expr, ts)
+		if err != nil {
+			return nil, err
+		}
+		switch v := val.(type) {
+		case promScalar:
+			addSample(map[string]string{}, ts, float64(v))
+		case promVector:
+			for _, elem := range v {
+				addSample(elem.Labels, ts, elem.V)
+			}
+		default:
+			return nil, fmt.Errorf("evalRangeQuery: unexpected value type %T", val)
+		}
+	}
+
+	result := make([]promqlMatrixResult, 0, len(seriesOrder))
+	for _, sig := range seriesOrder {
+		series := allSeries[sig]
+		result = append(result, promqlMatrixResult{Metric: series.Labels, Values: formatPromqlSamples(series.Samples)})
+	}
+	return result, nil
+}
+
+// Returns a fetcher that reads the raw samples of all matching series from the metrics segments
+func getMetricsStoreFetcher(myid uint64) seriesFetcher {
+	return func(metricName string, matchers []*labels.Matcher, startMs int64, endMs int64) ([]*promSeries, error) {
+		if startMs < 0 {
+			startMs = 0
+		}
+		mQuery := structs.MetricsQuery{
+			MetricName:      metricName,
+			HashedMName:     xxhash.Sum64String(metricName),
+			OrgId:           myid,
+			SelectAllSeries: true,
+			Aggregator:      structs.Aggreation{AggregatorFunction: segutils.Avg},
+			Downsampler: structs.Downsampler{Interval: 1, Unit: "s",
+				Aggregator: structs.Aggreation{AggregatorFunction: segutils.Avg}},
+		}
+		// equality matchers narrow down the series read, all matchers are applied on the read series
+		for _, matcher := range matchers {
+			if matcher.Name == METRIC_NAME_LABEL || matcher.Type != labels.MatchEqual || matcher.Value == "" {
+				continue
+			}
+			mQuery.TagsFilters = append(mQuery.TagsFilters, &structs.TagsFilter{
+				TagKey:          matcher.Name,
+				RawTagValue:     matcher.Value,
+				HashTagValue:    xxhash.Sum64String(matcher.Value),
+				TagOperator:     segutils.Equal,
+				LogicalOperator: segutils.And,
+			})
+		}
+		timeRange := dtu.MetricsTimeRange{
+			StartEpochSec: uint32(startMs / 1000),
+			EndEpochSec:   uint32((endMs + 999) / 1000),
+		}
+
+		res := segment.ExecuteMetricsQuery(&mQuery, &timeRange, rutils.GetNextQid())
+		if len(res.ErrList) > 0 {
+			return nil, res.ErrList[0]
+		}
+		return getPromSeriesFromResults(metricName, mQuery.TagsFilters, res.Results, matchers), nil
+	}
+}
+
+// Converts the aggregated results of a metrics query, keyed by the values of the tag filters, into labelled series
+func getPromSeriesFromResults(metricName string, tagsFilters []*structs.TagsFilter, results map[string]map[uint32]float64,
+	matchers []*labels.Matcher) []*promSeries {
+
+	uniqueTagKeys := make(map[string]bool)
+	tagKeys := make([]string, 0)
+	for _, tag := range tagsFilters {
+		if _, ok := uniqueTagKeys[tag.TagKey]; !ok {
+			uniqueTagKeys[tag.TagKey] = true
+			tagKeys = append(tagKeys, tag.TagKey)
+		}
+	}
+
+	retVal := make([]*promSeries, 0, len(results))
+	for grpId, dps := range results {
+		lbls := map[string]string{METRIC_NAME_LABEL: metricName}
+		tagValues := strings.Split(grpId, tsidtracker.TAG_VALUE_DELIMITER_STR)
+		for i := 0; i < len(tagKeys) && i < len(tagValues)-1; i++ {
+			if tagValues[i] != "" {
+				lbls[tagKeys[i]] = tagValues[i]
+			}
+		}
+
+		matched := true
+		for _, matcher := range matchers {
+			if !matcher.Matches(lbls[matcher.Name]) {
+				matched = false
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+
+		samples := make([]promSample, 0, len(dps))
+		for ts, v := range dps {
+			samples = append(samples, promSample{T: int64(ts) * 1000, V: v})
+		}
+		sort.Slice(samples, func(i, j int) bool { return samples[i].T < samples[j].T })
+		retVal = append(retVal, &promSeries{Labels: lbls, Samples: samples})
+	}
+	return retVal
+}
+
+// Parses a unix timestamp in seconds, which may have a fraction, or an RFC3339 time
+func parsePromqlTime(timeStr string, defaultTime time.Time) (time.Time, error) {
+	if timeStr == "" {
+		return defaultTime, nil
+	}
+	if secs, err := strconv.ParseFloat(timeStr, 64); err == nil {
+		wholeSecs, fracSecs := math.Modf(secs)
+		return time.Unix(int64(wholeSecs), int64(fracSecs*float64(time.Second))).UTC(), nil
+	}
+	return time.Parse(time.RFC3339Nano, timeStr)
+}
+
+func formatPromqlValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func formatPromqlSamples(samples []promSample) [][]interface{} {
+	values := make([][]interface{}, 0, len(samples))
+	for _, sample := range samples {
+		values = append(values, []interface{}{float64(sample.T) / 1000, formatPromqlValue(sample.V)})
+	}
+	return values
+}
+
+func writePromqlData(ctx *fasthttp.RequestCtx, data *promqlData) {
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	utils.WriteJsonResponse(ctx, &promqlResponse{Status: "success", Data: data})
+}
+
+func writePromqlError(ctx *fasthttp.RequestCtx, statusCode int, errorType string, err error) {
+	ctx.SetStatusCode(statusCode)
+	utils.WriteJsonResponse(ctx, &promqlResponse{Status: "error", ErrorType: errorType, Error: err.Error()})
+}
@@ -19,6 +19,7 @@ package splunk
 import (
 	"encoding/json"
 
+	"github.com/siglens/siglens/pkg/apikeys"
 	writer "github.com/siglens/siglens/pkg/es/writer"
 	"github.com/siglens/siglens/pkg/utils"
 	vtable "github.com/siglens/siglens/pkg/virtualtable"
@@ -52,6 +53,9 @@ func ProcessSplunkHecIngestRequest(ctx *fasthttp.RequestCtx, myid uint64) {
 		utils.WriteJsonResponse(ctx, responsebody)
 		return
 	}
+	if !apikeys.AuthorizeIndexAccess(ctx, indexNameIn, apikeys.ACCESS_WRITE) {
+		return
+	}
 	tsNow := utils.GetCurrentTimeInMs()
 	if !vtable.IsVirtualTablePresent(&indexNameIn, myid) {
 		log.Infof("ProcessSplunkHecIngestRequest: Index name %v does not exist. Adding virtual table name and mapping.", indexNameIn)
@@ -0,0 +1,403 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package integrity
+
+import (
+	"errors"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/siglens/siglens/pkg/blob"
+	"github.com/siglens/siglens/pkg/common/fileutils"
+	"github.com/siglens/siglens/pkg/config"
+	"github.com/siglens/siglens/pkg/health/heartbeat"
+	"github.com/siglens/siglens/pkg/segment/inspect"
+	"github.com/siglens/siglens/pkg/segment/query/metadata"
+	pqsmeta "github.com/siglens/siglens/pkg/segment/query/pqs/meta"
+	"github.com/siglens/siglens/pkg/segment/structs"
+	"github.com/siglens/siglens/pkg/segment/writer"
+	"github.com/siglens/siglens/pkg/utils"
+	"github.com/siglens/siglens/pkg/virtualtable"
+	log "github.com/sirupsen/logrus"
+)
+
+/*
+	Cross checks the rotated segments of the local node against their metadata. The segments in the segmeta have
+	to have files that can be read, their indices have to be in the virtual tables and their files in the blob store.
+	The metadata is repaired where nothing is lost by it: a segment without any files is removed from the segmeta, a
+	missing index or blob entry is added back and the blob entries of deleted dirs are removed. A segment whose files
+	can not be read is quarantined, so that the queries stop searching it and it can be restored once fixed, until
+	it is older than the retention. The segment dirs that no segmeta has are reported, and only deleted when the
+	config allows it.
+*/
+
+const DEFAULT_CHECK_INTERVAL_MINS = 360
+const DEFAULT_ORPHAN_GRACE_MINS = 60
+
+// The metrics segments are in these dirs of the final dir, next to the indices
+var metricsDirs = map[string]struct{}{"ts": {}, "tth": {}}
+
+type SegmentIssue struct {
+	SegmentKey string `json:"segmentKey"`
+	IndexName  string `json:"indexName"`
+	Reason     string `json:"reason"`
+	Repaired   bool   `json:"repaired"` // removed from the segmeta or quarantined
+}
+
+type OrphanedDir struct {
+	Dir        string `json:"dir"`
+	Bytes      uint64 `json:"bytes"`
+	ModifiedAt uint64 `json:"modifiedAt"` // in ms, of the newest file
+	Deleted    bool   `json:"deleted"`
+}
+
+type IntegrityReport struct {
+	StartedAt         uint64          `json:"startedAt"` // in ms
+	FinishedAt        uint64          `json:"finishedAt"`
+	DryRun            bool            `json:"dryRun"` // nothing was repaired
+	CheckedSegments   uint64          `json:"checkedSegments"`
+	MissingSegments   []*SegmentIssue `json:"missingSegments"` // in the segmeta, but without any files
+	CorruptSegments   []*SegmentIssue `json:"corruptSegments"` // files that can not be read
+	OrphanedDirs      []*OrphanedDir  `json:"orphanedDirs"`
+	MissingIndices    []string        `json:"missingIndices"`    // indices of the segments that were not in the virtual tables
+	StaleBlobFiles    []string        `json:"staleBlobFiles"`    // in the blob store, but their dirs were deleted
+	UnregisteredFiles uint64          `json:"unregisteredFiles"` // files of the segments that the blob store did not have
+	ExpiredQuarantine []string        `json:"expiredQuarantine"` // quarantined segments older than the retention
+	QuarantinedBytes  uint64          `json:"quarantinedBytes"`  // on disk in the quarantine dir after the check
+}
+
+var errCheckRunning = errors.New("an integrity check is already running")
+
+var checkLock = &sync.Mutex{}
+var reportLock = &sync.RWMutex{}
+var lastReport *IntegrityReport
+
+func getCheckInterval() time.Duration {
+	intervalMins := config.GetIntegrityConfig().IntervalMins
+	if intervalMins == 0 {
+		intervalMins = DEFAULT_CHECK_INTERVAL_MINS
+	}
+	return time.Duration(intervalMins) * time.Minute
+}
+
+// Starting the periodic integrity check
+func InitIntegrityChecker() error {
+	go integrityCheckLooper()
+	return nil
+}
+
+func integrityCheckLooper() {
+	heartbeat.Register(heartbeat.INTEGRITY_CHECKER, getCheckInterval())
+	time.Sleep(5 * time.Minute) // sleep for the rest of the system to come up
+	for {
+		_, err := RunCheck(false)
+		// a check run through the api is already in progress, it is not a failure of the checker
+		if !errors.Is(err, errCheckRunning) {
+			heartbeat.Record(heartbeat.INTEGRITY_CHECKER, err)
+		}
+		heartbeat.SetInterval(heartbeat.INTEGRITY_CHECKER, getCheckInterval())
+		time.Sleep(getCheckInterval())
+	}
+}
+
+// Returns the report of the last check, nil if there was none
+func GetLastReport() *IntegrityReport {
+	reportLock.RLock()
+	defer reportLock.RUnlock()
+	return lastReport
+}
+
+// Checks the segments of the local node, dryRun only reports what would be repaired. Fails if a check is running
+func RunCheck(dryRun bool) (*IntegrityReport, error) {
+	if !checkLock.TryLock() {
+		return nil, errCheckRunning
+	}
+	defer checkLock.Unlock()
+
+	sTime := time.Now()
+	report := &IntegrityReport{
+		StartedAt:         utils.GetCurrentTimeInMs(),
+		DryRun:            dryRun,
+		MissingSegments:   make([]*SegmentIssue, 0),
+		CorruptSegments:   make([]*SegmentIssue, 0),
+		OrphanedDirs:      make([]*OrphanedDir, 0),
+		MissingIndices:    make([]string, 0),
+		StaleBlobFiles:    make([]string, 0),
+		ExpiredQuarantine: make([]string, 0),
+	}
+
+	localSegmetas := writer.ReadLocalSegmeta()
+	err := checkIndices(localSegmetas, report)
+	if err != nil {
+		log.Errorf("RunCheck: failed to check the indices, err=%v", err)
+		return nil, err
+	}
+	blobFiles := make(map[string]struct{})
+	for _, fname := range blob.GetAllSegSetFiles() {
+		blobFiles[fname] = struct{}{}
+	}
+	for _, segMeta := range localSegmetas {
+		checkSegment(segMeta, blobFiles, report)
+	}
+	// read again, the quarantined segments were removed from the blob store
+	checkBlobFiles(blob.GetAllSegSetFiles(), report)
+
+	// the segments of the other nodes in the ingest nodes dir are searched as well, so their dirs are not orphaned
+	usedDirs := make(map[string]struct{})
+	for _, segMeta := range writer.ReadAllSegmetas() {
+		usedDirs[path.Dir(segMeta.SegmentKey)+"/"] = struct{}{}
+	}
+	checkOrphanedDirs(usedDirs, report)
+	err = checkQuarantine(report)
+	if err != nil {
+		log.Errorf("RunCheck: failed to check the quarantined segments, err=%v", err)
+		return nil, err
+	}
+
+	report.FinishedAt = utils.GetCurrentTimeInMs()
+	log.Infof("RunCheck: checked %v segments in %v, missing=%v, corrupt=%v, orphaned dirs=%v, missing indices=%v, stale blob files=%v, unregistered files=%v, expired quarantine=%v, quarantined bytes=%v, dryRun=%v",
+		report.CheckedSegments, time.Since(sTime), len(report.MissingSegments), len(report.CorruptSegments),
+		len(report.OrphanedDirs), len(report.MissingIndices), len(report.StaleBlobFiles), report.UnregisteredFiles,
+		len(report.ExpiredQuarantine), report.QuarantinedBytes, dryRun)
+
+	reportLock.Lock()
+	lastReport = report
+	reportLock.Unlock()
+	return report, nil
+}
+
+// Adds the indices of the segments that are not in the virtual tables of their orgs
+func checkIndices(segmetas []*structs.SegMeta, report *IntegrityReport) error {
+	orgIndices := make(map[uint64]map[string]bool)
+	for _, segMeta := range segmetas {
+		indexNames, ok := orgIndices[segMeta.OrgId]
+		if !ok {
+			var err error
+			indexNames, err = virtualtable.GetVirtualTableNames(segMeta.OrgId)
+			if err != nil {
+				return err
+			}
+			orgIndices[segMeta.OrgId] = indexNames
+		}
+		if indexNames[segMeta.VirtualTableName] {
+			continue
+		}
+		indexNames[segMeta.VirtualTableName] = true
+		report.MissingIndices = append(report.MissingIndices, segMeta.VirtualTableName)
+		if report.DryRun {
+			continue
+		}
+		err := virtualtable.AddVirtualTable(&segMeta.VirtualTableName, segMeta.OrgId)
+		if err != nil {
+			log.Errorf("checkIndices: failed to add index %v, err=%v", segMeta.VirtualTableName, err)
+			return err
+		}
+	}
+	sort.Strings(report.MissingIndices)
+	return nil
+}
+
+func checkSegment(segMeta *structs.SegMeta, blobFiles map[string]struct{}, report *IntegrityReport) {
+	report.CheckedSegments++
+	segDir := path.Dir(segMeta.SegmentKey) + "/"
+	issue := &SegmentIssue{SegmentKey: segMeta.SegmentKey, IndexName: segMeta.VirtualTableName}
+	if _, err := os.Stat(segDir); os.IsNotExist(err) {
+		issue.Reason = "the segment dir does not exist"
+		report.MissingSegments = append(report.MissingSegments, issue)
+		if !report.DryRun && isStillInSegmeta(segMeta.SegmentKey) {
+			removeMissingSegment(segMeta)
+			issue.Repaired = true
+		}
+		return
+	}
+
+	// before the segment is read, since reading registers the files in the blob store
+	unregistered := make([]string, 0)
+	for _, fname := range fileutils.GetAllFilesInDirectory(segDir) {
+		if _, ok := blobFiles[fname]; !ok {
+			unregistered = append(unregistered, fname)
+		}
+	}
+
+	_, err := inspect.InspectSegment(segMeta, false)
+	if err != nil {
+		issue.Reason = err.Error()
+		report.CorruptSegments = append(report.CorruptSegments, issue)
+		if report.DryRun || !isStillInSegmeta(segMeta.SegmentKey) {
+			return
+		}
+		err = quarantineSegment(segMeta, issue.Reason)
+		if err != nil {
+			log.Errorf("checkSegment: failed to quarantine segkey=%v, err=%v", segMeta.SegmentKey, err)
+			return
+		}
+		issue.Repaired = true
+		return
+	}
+
+	report.UnregisteredFiles += uint64(len(unregistered))
+	if len(unregistered) > 0 && !report.DryRun {
+		err = blob.UploadSegmentFiles(unregistered)
+		if err != nil {
+			log.Errorf("checkSegment: failed to upload the files of segkey=%v, err=%v", segMeta.SegmentKey, err)
+		}
+	}
+}
+
+// The retention or a rebalance could have removed the segment while it was being checked
+func isStillInSegmeta(segKey string) bool {
+	for _, segMeta := range writer.ReadLocalSegmeta() {
+		if segMeta.SegmentKey == segKey {
+			return true
+		}
+	}
+	return false
+}
+
+// Removes the segment from the metadata, it has no files left to search
+func removeMissingSegment(segMeta *structs.SegMeta) {
+	removeFromMetadata(segMeta)
+	err := blob.UploadIngestNodeDir()
+	if err != nil {
+		log.Errorf("removeMissingSegment: failed to upload ingest node dir, err=%v", err)
+	}
+	log.Infof("removeMissingSegment: removed segkey=%v of index %v without any files from the segmeta",
+		segMeta.SegmentKey, segMeta.VirtualTableName)
+}
+
+/*
+Removes the blob store entries of the files whose dirs do not exist anymore. A missing file in a dir that exists is
+left alone, since the blob store also has the micro index files of the columns that have none
+*/
+func checkBlobFiles(blobFiles []string, report *IntegrityReport) {
+	existingDirs := make(map[string]bool)
+	for _, fname := range blobFiles {
+		if strings.Contains(fname, "/active/") {
+			continue
+		}
+		dir := path.Dir(fname)
+		exists, ok := existingDirs[dir]
+		if !ok {
+			_, err := os.Stat(dir)
+			exists = err == nil
+			existingDirs[dir] = exists
+		}
+		if !exists {
+			report.StaleBlobFiles = append(report.StaleBlobFiles, fname)
+		}
+	}
+	sort.Strings(report.StaleBlobFiles)
+	if !report.DryRun {
+		blob.ForgetSegSetFiles(report.StaleBlobFiles)
+	}
+}
+
+/*
+Reports the segment dirs in the final dirs of all the hosts that no segmeta has, they are left over from segments
+that were not fully deleted or moved. Dirs modified within the grace period could be segments that are being rotated
+or moved to this node, so they are never deleted
+*/
+func checkOrphanedDirs(usedDirs map[string]struct{}, report *IntegrityReport) {
+	integrityCfg := config.GetIntegrityConfig()
+	graceMins := integrityCfg.OrphanGraceMins
+	if graceMins == 0 {
+		graceMins = DEFAULT_ORPHAN_GRACE_MINS
+	}
+	deleteBeforeMs := uint64(time.Now().Add(-time.Duration(graceMins) * time.Minute).UnixMilli())
+
+	for _, segDir := range getFinalSegmentDirs() {
+		if _, ok := usedDirs[segDir]; ok {
+			continue
+		}
+		orphan := &OrphanedDir{Dir: segDir}
+		allFiles := fileutils.GetAllFilesInDirectory(segDir)
+		for _, fname := range allFiles {
+			finfo, err := os.Stat(fname)
+			if err != nil {
+				continue
+			}
+			orphan.Bytes += uint64(finfo.Size())
+			if modifiedAt := uint64(finfo.ModTime().UnixMilli()); modifiedAt > orphan.ModifiedAt {
+				orphan.ModifiedAt = modifiedAt
+			}
+		}
+		report.OrphanedDirs = append(report.OrphanedDirs, orphan)
+		if report.DryRun || !integrityCfg.DeleteOrphans || orphan.ModifiedAt > deleteBeforeMs {
+			continue
+		}
+
+		for _, fname := range allFiles {
+			err := blob.DeleteBlob(fname)
+			if err != nil {
+				log.Infof("checkOrphanedDirs: failed to delete file %v in the blob store", fname)
+			}
+		}
+		err := os.RemoveAll(segDir)
+		if err != nil {
+			log.Errorf("checkOrphanedDirs: failed to remove dir=%v, err=%v", segDir, err)
+			continue
+		}
+		fileutils.RecursivelyDeleteEmptyParentDirectories(segDir)
+		orphan.Deleted = true
+		log.Infof("checkOrphanedDirs: deleted orphaned dir=%v with %v bytes", segDir, orphan.Bytes)
+	}
+}
+
+// Returns the dirs of the rotated segments, <<dataDir>>/<<hostname>>/final/<<index>>/<<stream>>/<<suffix>>/
+func getFinalSegmentDirs() []string {
+	retVal := make([]string, 0)
+	for _, hostDir := range getSubDirs(config.GetDataPath()) {
+		finalDir := config.GetDataPath() + hostDir + "/final/"
+		for _, indexName := range getSubDirs(finalDir) {
+			if _, ok := metricsDirs[indexName]; ok {
+				continue
+			}
+			for _, streamId := range getSubDirs(finalDir + indexName) {
+				for _, suffix := range getSubDirs(finalDir + indexName + "/" + streamId) {
+					retVal = append(retVal, finalDir+indexName+"/"+streamId+"/"+suffix+"/")
+				}
+			}
+		}
+	}
+	return retVal
+}
+
+func getSubDirs(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	retVal := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			retVal = append(retVal, entry.Name())
+		}
+	}
+	return retVal
+}
+
+func removeFromMetadata(segMeta *structs.SegMeta) {
+	for pqid := range segMeta.AllPQIDs {
+		pqsmeta.DeleteSegmentFromPqid(pqid, segMeta.SegmentKey)
+	}
+	metadata.DeleteSegmentKey(segMeta.SegmentKey)
+	writer.RemoveSegments(writer.GetLocalSegmetaFName(), map[string]*structs.SegMeta{segMeta.SegmentKey: segMeta})
+}
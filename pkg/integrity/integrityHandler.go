@@ -0,0 +1,77 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package integrity
+
+import (
+	"github.com/siglens/siglens/pkg/utils"
+	log "github.com/sirupsen/logrus"
+	"github.com/valyala/fasthttp"
+)
+
+type IntegrityStatusResponse struct {
+	LastReport  *IntegrityReport      `json:"lastReport"` // null until the first check
+	Quarantined []*QuarantinedSegment `json:"quarantined"`
+}
+
+func setErrMsg(ctx *fasthttp.RequestCtx, statusCode int, message string) {
+	var httpResp utils.HttpServerResponse
+	ctx.SetStatusCode(statusCode)
+	httpResp.Message = message
+	httpResp.StatusCode = statusCode
+	utils.WriteResponse(ctx, httpResp)
+}
+
+// Handles GET /api/integrity, the report of the last check and the quarantined segments
+func ProcessIntegrityStatusRequest(ctx *fasthttp.RequestCtx) {
+	quarantined, err := GetQuarantinedSegments()
+	if err != nil {
+		log.Errorf("ProcessIntegrityStatusRequest: failed to read the quarantined segments, err=%v", err)
+		setErrMsg(ctx, fasthttp.StatusInternalServerError, "Failed to read the quarantined segments")
+		return
+	}
+	utils.WriteJsonResponse(ctx, &IntegrityStatusResponse{LastReport: GetLastReport(), Quarantined: quarantined})
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
+
+// Handles POST /api/integrity/check, runs a check now. dryRun=true only reports what would be repaired
+func ProcessIntegrityCheckRequest(ctx *fasthttp.RequestCtx) {
+	report, err := RunCheck(string(ctx.QueryArgs().Peek("dryRun")) == "true")
+	if err != nil {
+		log.Errorf("ProcessIntegrityCheckRequest: the check failed, err=%v", err)
+		setErrMsg(ctx, fasthttp.StatusConflict, err.Error())
+		return
+	}
+	utils.WriteJsonResponse(ctx, report)
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
+
+// Handles POST /api/integrity/quarantine/restore?segmentKey=..., after the files of the segment have been fixed
+func ProcessRestoreSegmentRequest(ctx *fasthttp.RequestCtx) {
+	segKey := string(ctx.QueryArgs().Peek("segmentKey"))
+	if segKey == "" {
+		setErrMsg(ctx, fasthttp.StatusBadRequest, "segmentKey is required")
+		return
+	}
+	restored, err := RestoreSegment(segKey)
+	if err != nil {
+		log.Errorf("ProcessRestoreSegmentRequest: could not restore segkey=%v, err=%v", segKey, err)
+		setErrMsg(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	utils.WriteJsonResponse(ctx, restored)
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package integrity
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/cespare/xxhash"
+	"github.com/siglens/siglens/pkg/blob"
+	"github.com/siglens/siglens/pkg/config"
+	"github.com/siglens/siglens/pkg/segment/structs"
+	"github.com/siglens/siglens/pkg/segment/writer"
+	"github.com/siglens/siglens/pkg/utils"
+	"github.com/siglens/siglens/pkg/virtualtable"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTestSegment(t *testing.T, indexName string, suffix int) *structs.SegMeta {
+	segDir := fmt.Sprintf("%v%v/final/%v/0/%v/", config.GetDataPath(), config.GetHostID(), indexName, suffix)
+	assert.Nil(t, os.MkdirAll(segDir, 0764))
+	segKey := fmt.Sprintf("%v%v", segDir, suffix)
+	_, blockSummaries, _, _, allBmh, allColsSizes := writer.WriteMockColSegFile(segKey, 2, 10)
+	writer.WriteMockBlockSummary(structs.GetBsuFnameFromSegKey(segKey), blockSummaries, allBmh)
+	segMeta := structs.SegMeta{
+		SegmentKey:       segKey,
+		SegbaseDir:       segDir,
+		VirtualTableName: indexName,
+		RecordCount:      20,
+		NumBlocks:        2,
+		ColumnNames:      allColsSizes,
+		LatestEpochMS:    utils.GetCurrentTimeInMs(),
+	}
+	writer.AddNewRotatedSegment(segMeta)
+	return &segMeta
+}
+
+func getLocalSegKeys() []string {
+	segKeys := make([]string, 0)
+	for _, segMeta := range writer.ReadLocalSegmeta() {
+		segKeys = append(segKeys, segMeta.SegmentKey)
+	}
+	return segKeys
+}
+
+func Test_RunCheck(t *testing.T) {
+	config.InitializeDefaultConfig()
+	runningConfig := config.GetRunningConfig()
+	runningConfig.DataPath = t.TempDir() + "/"
+	runningConfig.Integrity.DeleteOrphans = true
+	config.SetConfig(*runningConfig)
+	assert.Nil(t, config.InitDerivedConfig("test-node"))
+	assert.Nil(t, os.MkdirAll(config.GetSmrBaseDir(), 0764))
+	assert.Nil(t, virtualtable.InitVTable())
+	indexName := "idx-a"
+	assert.Nil(t, virtualtable.AddVirtualTable(&indexName, 0))
+
+	goodSeg := writeTestSegment(t, "idx-a", 1)
+	corruptSeg := writeTestSegment(t, "idx-a", 2)
+	otherIndexSeg := writeTestSegment(t, "idx-b", 3)
+	missingSeg := structs.SegMeta{SegmentKey: config.GetDataPath() + "gone/final/idx-a/0/4/4", VirtualTableName: "idx-a"}
+	writer.AddNewRotatedSegment(missingSeg)
+
+	csgFname := fmt.Sprintf("%v_%v.csg", corruptSeg.SegmentKey, xxhash.Sum64String("key0"))
+	csgData, err := os.ReadFile(csgFname)
+	assert.Nil(t, err)
+	assert.Nil(t, os.Truncate(csgFname, 5))
+
+	finalDir := config.GetDataPath() + config.GetHostID() + "/final/"
+	oldOrphanDir := finalDir + "idx-a/0/5/"
+	newOrphanDir := finalDir + "idx-a/0/6/"
+	metricsDir := finalDir + "ts/0/7/"
+	for _, dir := range []string{oldOrphanDir, newOrphanDir, metricsDir} {
+		assert.Nil(t, os.MkdirAll(dir, 0764))
+		assert.Nil(t, os.WriteFile(dir+"file", []byte("data"), 0644))
+	}
+	oldTime := time.Now().Add(-2 * time.Hour)
+	assert.Nil(t, os.Chtimes(oldOrphanDir+"file", oldTime, oldTime))
+	staleFname := config.GetDataPath() + "deleted/final/idx-a/0/8/8.bsu"
+	assert.Nil(t, blob.UploadSegmentFiles([]string{staleFname}))
+
+	report, err := RunCheck(true)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(4), report.CheckedSegments)
+	assert.Len(t, report.MissingSegments, 1)
+	assert.Equal(t, missingSeg.SegmentKey, report.MissingSegments[0].SegmentKey)
+	assert.False(t, report.MissingSegments[0].Repaired)
+	assert.Len(t, report.CorruptSegments, 1)
+	assert.Equal(t, corruptSeg.SegmentKey, report.CorruptSegments[0].SegmentKey)
+	assert.Equal(t, []string{"idx-b"}, report.MissingIndices)
+	assert.Contains(t, report.StaleBlobFiles, staleFname)
+	assert.Greater(t, report.UnregisteredFiles, uint64(0))
+	assert.Len(t, report.OrphanedDirs, 2, "the metrics dirs are not segment dirs")
+	assert.Equal(t, oldOrphanDir, report.OrphanedDirs[0].Dir)
+	assert.Equal(t, uint64(4), report.OrphanedDirs[0].Bytes)
+	assert.False(t, report.OrphanedDirs[0].Deleted)
+	assert.Len(t, getLocalSegKeys(), 4, "a dry run changes nothing")
+	assert.Equal(t, report, GetLastReport())
+
+	report, err = RunCheck(false)
+	assert.Nil(t, err)
+	assert.True(t, report.MissingSegments[0].Repaired)
+	assert.True(t, report.CorruptSegments[0].Repaired)
+	assert.True(t, report.OrphanedDirs[0].Deleted)
+	assert.False(t, report.OrphanedDirs[1].Deleted, "the dir was modified within the grace period")
+	assert.ElementsMatch(t, []string{goodSeg.SegmentKey, otherIndexSeg.SegmentKey}, getLocalSegKeys())
+	indexNames, err := virtualtable.GetVirtualTableNames(0)
+	assert.Nil(t, err)
+	assert.True(t, indexNames["idx-b"])
+	assert.NotContains(t, blob.GetAllSegSetFiles(), staleFname)
+	_, err = os.Stat(oldOrphanDir)
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(metricsDir + "file")
+	assert.Nil(t, err)
+
+	quarantined, err := GetQuarantinedSegments()
+	assert.Nil(t, err)
+	assert.Len(t, quarantined, 1)
+	assert.Equal(t, corruptSeg.SegmentKey, quarantined[0].SegMeta.SegmentKey)
+	_, err = os.Stat(corruptSeg.SegbaseDir)
+	assert.True(t, os.IsNotExist(err))
+
+	report, err = RunCheck(false)
+	assert.Nil(t, err)
+	assert.Len(t, report.MissingSegments, 0)
+	assert.Len(t, report.CorruptSegments, 0)
+	assert.Len(t, report.MissingIndices, 0)
+	assert.Equal(t, uint64(0), report.UnregisteredFiles)
+
+	// stays quarantined until its files are fixed
+	_, err = RestoreSegment(corruptSeg.SegmentKey)
+	assert.NotNil(t, err)
+	assert.Nil(t, os.WriteFile(getQuarantineDir(corruptSeg.SegbaseDir)+fmt.Sprintf("2_%v.csg", xxhash.Sum64String("key0")),
+		csgData, 0644))
+	restored, err := RestoreSegment(corruptSeg.SegmentKey)
+	assert.Nil(t, err)
+	assert.Equal(t, corruptSeg.SegmentKey, restored.SegMeta.SegmentKey)
+	assert.ElementsMatch(t, []string{goodSeg.SegmentKey, otherIndexSeg.SegmentKey, corruptSeg.SegmentKey}, getLocalSegKeys())
+	quarantined, err = GetQuarantinedSegments()
+	assert.Nil(t, err)
+	assert.Len(t, quarantined, 0)
+	_, err = RestoreSegment(corruptSeg.SegmentKey)
+	assert.NotNil(t, err)
+}
+
+func Test_checkQuarantine(t *testing.T) {
+	config.InitializeDefaultConfig()
+	runningConfig := config.GetRunningConfig()
+	runningConfig.DataPath = t.TempDir() + "/"
+	runningConfig.RetentionHours = 24
+	config.SetConfig(*runningConfig)
+	assert.Nil(t, config.InitDerivedConfig("test-node"))
+	assert.Nil(t, os.MkdirAll(config.GetSmrBaseDir(), 0764))
+
+	expiredSeg := writeTestSegment(t, "idx-a", 1)
+	expiredSeg.LatestEpochMS = uint64(time.Now().Add(-25 * time.Hour).UnixMilli())
+	recentSeg := writeTestSegment(t, "idx-a", 2)
+	assert.Nil(t, quarantineSegment(expiredSeg, "test"))
+	assert.Nil(t, quarantineSegment(recentSeg, "test"))
+
+	report := &IntegrityReport{DryRun: true, ExpiredQuarantine: make([]string, 0)}
+	assert.Nil(t, checkQuarantine(report))
+	assert.Equal(t, []string{expiredSeg.SegmentKey}, report.ExpiredQuarantine)
+	assert.Greater(t, report.QuarantinedBytes, uint64(0))
+	quarantined, err := GetQuarantinedSegments()
+	assert.Nil(t, err)
+	assert.Len(t, quarantined, 2, "a dry run changes nothing")
+
+	report = &IntegrityReport{ExpiredQuarantine: make([]string, 0)}
+	assert.Nil(t, checkQuarantine(report))
+	assert.Equal(t, []string{expiredSeg.SegmentKey}, report.ExpiredQuarantine)
+	assert.Greater(t, report.QuarantinedBytes, uint64(0))
+	quarantined, err = GetQuarantinedSegments()
+	assert.Nil(t, err)
+	assert.Len(t, quarantined, 1)
+	assert.Equal(t, recentSeg.SegmentKey, quarantined[0].SegMeta.SegmentKey)
+	_, err = os.Stat(getQuarantineDir(expiredSeg.SegbaseDir))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(getQuarantineDir(recentSeg.SegbaseDir))
+	assert.Nil(t, err)
+}
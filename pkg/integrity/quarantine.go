@@ -0,0 +1,238 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package integrity
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/siglens/siglens/pkg/blob"
+	"github.com/siglens/siglens/pkg/common/fileutils"
+	"github.com/siglens/siglens/pkg/config"
+	"github.com/siglens/siglens/pkg/segment/inspect"
+	"github.com/siglens/siglens/pkg/segment/structs"
+	"github.com/siglens/siglens/pkg/segment/writer"
+	"github.com/siglens/siglens/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+// A segment whose files could not be read, moved out of the final dir and the segmeta
+type QuarantinedSegment struct {
+	SegMeta       structs.SegMeta `json:"segMeta"`
+	Reason        string          `json:"reason"`
+	QuarantineDir string          `json:"quarantineDir"`
+	QuarantinedAt uint64          `json:"quarantinedAt"` // in ms
+}
+
+var quarantineLock = &sync.Mutex{}
+
+func getQuarantineFname() string {
+	return config.GetSmrBaseDir() + "quarantine.json"
+}
+
+// Returns <<dataDir>>/<<hostname>>/quarantine/ followed by the segment dir relative to the data dir
+func getQuarantineDir(segDir string) string {
+	return config.GetDataPath() + config.GetHostID() + "/quarantine/" + strings.TrimPrefix(segDir, config.GetDataPath())
+}
+
+// Needs the lock to be held
+func readQuarantine() ([]*QuarantinedSegment, error) {
+	data, err := os.ReadFile(getQuarantineFname())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return make([]*QuarantinedSegment, 0), nil
+		}
+		return nil, err
+	}
+	allQuarantined := make([]*QuarantinedSegment, 0)
+	err = json.Unmarshal(data, &allQuarantined)
+	if err != nil {
+		return nil, err
+	}
+	return allQuarantined, nil
+}
+
+// Needs the lock to be held
+func writeQuarantine(allQuarantined []*QuarantinedSegment) error {
+	data, err := json.Marshal(allQuarantined)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(getQuarantineFname(), data, 0644)
+	if err != nil {
+		log.Errorf("writeQuarantine: failed to write file=%v, err=%v", getQuarantineFname(), err)
+		return err
+	}
+	return blob.UploadIngestNodeDir()
+}
+
+func GetQuarantinedSegments() ([]*QuarantinedSegment, error) {
+	quarantineLock.Lock()
+	defer quarantineLock.Unlock()
+	return readQuarantine()
+}
+
+// Moves the files of the segment to the quarantine dir and removes the segment from the metadata
+func quarantineSegment(segMeta *structs.SegMeta, reason string) error {
+	quarantineLock.Lock()
+	defer quarantineLock.Unlock()
+	allQuarantined, err := readQuarantine()
+	if err != nil {
+		log.Errorf("quarantineSegment: failed to read the quarantined segments, err=%v", err)
+		return err
+	}
+
+	segDir := path.Dir(segMeta.SegmentKey) + "/"
+	quarantineDir := getQuarantineDir(segDir)
+	allFiles := fileutils.GetAllFilesInDirectory(segDir)
+	err = os.MkdirAll(path.Dir(path.Clean(quarantineDir)), 0764)
+	if err != nil {
+		log.Errorf("quarantineSegment: failed to create the parent of dir=%v, err=%v", quarantineDir, err)
+		return err
+	}
+	err = os.Rename(segDir, quarantineDir)
+	if err != nil {
+		log.Errorf("quarantineSegment: failed to move dir=%v to %v, err=%v", segDir, quarantineDir, err)
+		return err
+	}
+	blob.ForgetSegSetFiles(allFiles)
+	removeFromMetadata(segMeta)
+
+	allQuarantined = append(allQuarantined, &QuarantinedSegment{
+		SegMeta:       *segMeta,
+		Reason:        reason,
+		QuarantineDir: quarantineDir,
+		QuarantinedAt: utils.GetCurrentTimeInMs(),
+	})
+	err = writeQuarantine(allQuarantined)
+	if err != nil {
+		return err
+	}
+	log.Warnf("quarantineSegment: quarantined segkey=%v of index %v to dir=%v, reason=%v", segMeta.SegmentKey,
+		segMeta.VirtualTableName, quarantineDir, reason)
+	return nil
+}
+
+/*
+Deletes the quarantined segments that the retention would have deleted, their records are older than the retention
+hours, and adds up the bytes that the remaining ones take on disk
+*/
+func checkQuarantine(report *IntegrityReport) error {
+	quarantineLock.Lock()
+	defer quarantineLock.Unlock()
+	allQuarantined, err := readQuarantine()
+	if err != nil {
+		return err
+	}
+	deleteBeforeMs := uint64(time.Now().Add(-time.Duration(config.GetRetentionHours()) * time.Hour).UnixMilli())
+
+	remaining := make([]*QuarantinedSegment, 0, len(allQuarantined))
+	for _, quarantined := range allQuarantined {
+		if quarantined.SegMeta.LatestEpochMS <= deleteBeforeMs {
+			report.ExpiredQuarantine = append(report.ExpiredQuarantine, quarantined.SegMeta.SegmentKey)
+			if !report.DryRun {
+				err := os.RemoveAll(quarantined.QuarantineDir)
+				if err != nil {
+					log.Errorf("checkQuarantine: failed to remove dir=%v, err=%v", quarantined.QuarantineDir, err)
+					remaining = append(remaining, quarantined)
+					continue
+				}
+				fileutils.RecursivelyDeleteEmptyParentDirectories(quarantined.QuarantineDir)
+				log.Infof("checkQuarantine: deleted quarantined segkey=%v of index %v past the retention",
+					quarantined.SegMeta.SegmentKey, quarantined.SegMeta.VirtualTableName)
+				continue
+			}
+		}
+		remaining = append(remaining, quarantined)
+		for _, fname := range fileutils.GetAllFilesInDirectory(quarantined.QuarantineDir) {
+			finfo, err := os.Stat(fname)
+			if err == nil {
+				report.QuarantinedBytes += uint64(finfo.Size())
+			}
+		}
+	}
+	if len(remaining) == len(allQuarantined) {
+		return nil
+	}
+	return writeQuarantine(remaining)
+}
+
+/*
+Moves the segment back to its dir and adds it to the segmeta again, once its files have been fixed. The segment is
+read first and stays quarantined if that still fails
+*/
+func RestoreSegment(segKey string) (*QuarantinedSegment, error) {
+	quarantineLock.Lock()
+	defer quarantineLock.Unlock()
+	allQuarantined, err := readQuarantine()
+	if err != nil {
+		log.Errorf("RestoreSegment: failed to read the quarantined segments, err=%v", err)
+		return nil, err
+	}
+	idx := -1
+	for i, quarantined := range allQuarantined {
+		if quarantined.SegMeta.SegmentKey == segKey {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		return nil, fmt.Errorf("segment %v is not quarantined", segKey)
+	}
+	quarantined := allQuarantined[idx]
+	segDir := path.Dir(segKey) + "/"
+	if _, err := os.Stat(segDir); err == nil {
+		return nil, fmt.Errorf("dir %v of segment %v already exists", segDir, segKey)
+	}
+
+	err = os.MkdirAll(path.Dir(path.Clean(segDir)), 0764)
+	if err != nil {
+		log.Errorf("RestoreSegment: failed to create the parent of dir=%v, err=%v", segDir, err)
+		return nil, err
+	}
+	err = os.Rename(quarantined.QuarantineDir, segDir)
+	if err != nil {
+		log.Errorf("RestoreSegment: failed to move dir=%v to %v, err=%v", quarantined.QuarantineDir, segDir, err)
+		return nil, err
+	}
+	_, err = inspect.InspectSegment(&quarantined.SegMeta, false)
+	if err != nil {
+		blob.ForgetSegSetFiles(fileutils.GetAllFilesInDirectory(segDir))
+		if renameErr := os.Rename(segDir, quarantined.QuarantineDir); renameErr != nil {
+			log.Errorf("RestoreSegment: failed to move dir=%v back to %v, err=%v", segDir, quarantined.QuarantineDir, renameErr)
+		}
+		return nil, fmt.Errorf("segment %v still can not be read: %v", segKey, err)
+	}
+
+	err = blob.UploadSegmentFiles(fileutils.GetAllFilesInDirectory(segDir))
+	if err != nil {
+		log.Errorf("RestoreSegment: failed to upload the files of segkey=%v, err=%v", segKey, err)
+	}
+	writer.AddNewRotatedSegment(quarantined.SegMeta)
+	allQuarantined = append(allQuarantined[:idx], allQuarantined[idx+1:]...)
+	err = writeQuarantine(allQuarantined)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("RestoreSegment: restored segkey=%v of index %v", segKey, quarantined.SegMeta.VirtualTableName)
+	return quarantined, nil
+}
@@ -0,0 +1,330 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lookups
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+
+	"github.com/siglens/siglens/pkg/config"
+	"github.com/siglens/siglens/pkg/instrumentation"
+	log "github.com/sirupsen/logrus"
+)
+
+// max number of keys sent in a single IN (...) clause
+const LOOKUP_BATCH_SIZE = 500
+
+var identifierRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*(\.[a-zA-Z_][a-zA-Z0-9_]*)?$`)
+
+type cachedRow struct {
+	row        map[string]interface{} // nil if the key has no matching row
+	expiry     time.Time
+	lastAccess time.Time
+}
+
+type sqlConnector struct {
+	lookupCfg config.LookupConfig
+	db        *sql.DB
+	cacheLock sync.Mutex
+	cache     map[string]*cachedRow
+}
+
+var allConnectorsLock sync.Mutex
+var allConnectors = make(map[string]*sqlConnector)
+
+func getConnector(lookupName string) (*sqlConnector, error) {
+	allConnectorsLock.Lock()
+	defer allConnectorsLock.Unlock()
+
+	if conn, ok := allConnectors[lookupName]; ok {
+		return conn, nil
+	}
+
+	for _, lookupCfg := range config.GetLookupsConfig() {
+		if lookupCfg.Name != lookupName {
+			continue
+		}
+		conn, err := newSqlConnector(lookupCfg)
+		if err != nil {
+			return nil, err
+		}
+		allConnectors[lookupName] = conn
+		return conn, nil
+	}
+	return nil, fmt.Errorf("getConnector: lookup %v is not configured in server.yaml", lookupName)
+}
+
+func newSqlConnector(lookupCfg config.LookupConfig) (*sqlConnector, error) {
+	if !identifierRegex.MatchString(lookupCfg.Table) {
+		return nil, fmt.Errorf("newSqlConnector: invalid table name %v for lookup %v", lookupCfg.Table, lookupCfg.Name)
+	}
+	db, err := sql.Open(lookupCfg.Driver, lookupCfg.DSN)
+	if err != nil {
+		log.Errorf("newSqlConnector: failed to open connection for lookup %v, err: %v", lookupCfg.Name, err)
+		return nil, err
+	}
+	return &sqlConnector{
+		lookupCfg: lookupCfg,
+		db:        db,
+		cache:     make(map[string]*cachedRow),
+	}, nil
+}
+
+// Closes all open lookup connections. A later lookup will reconnect with the current config.
+func CloseAllConnectors() {
+	allConnectorsLock.Lock()
+	defer allConnectorsLock.Unlock()
+
+	for name, conn := range allConnectors {
+		err := conn.db.Close()
+		if err != nil {
+			log.Errorf("CloseAllConnectors: failed to close connection for lookup %v, err: %v", name, err)
+		}
+		delete(allConnectors, name)
+	}
+}
+
+// Returns the rows of the lookup table whose matchCol is one of keys, keyed by the value of matchCol.
+// Keys with no matching row are absent from the returned map.
+// If outputCols is empty, all columns of the lookup table are returned.
+func GetLookupRows(lookupName string, matchCol string, outputCols []string, keys []string) (map[string]map[string]interface{}, error) {
+	conn, err := getConnector(lookupName)
+	if err != nil {
+		return nil, err
+	}
+	if !identifierRegex.MatchString(matchCol) {
+		return nil, fmt.Errorf("GetLookupRows: invalid match column %v", matchCol)
+	}
+	for _, col := range outputCols {
+		if !identifierRegex.MatchString(col) {
+			return nil, fmt.Errorf("GetLookupRows: invalid output column %v", col)
+		}
+	}
+	return conn.getRows(matchCol, outputCols, keys)
+}
+
+func (sc *sqlConnector) getRows(matchCol string, outputCols []string, keys []string) (map[string]map[string]interface{}, error) {
+	retVal := make(map[string]map[string]interface{})
+	missingKeys := make([]string, 0)
+	seenKeys := make(map[string]struct{}, len(keys))
+	now := time.Now()
+
+	sc.cacheLock.Lock()
+	for _, key := range keys {
+		if _, ok := seenKeys[key]; ok {
+			continue
+		}
+		seenKeys[key] = struct{}{}
+		cached, ok := sc.cache[getCacheKey(matchCol, outputCols, key)]
+		if ok && now.Before(cached.expiry) {
+			cached.lastAccess = now
+			if cached.row != nil {
+				retVal[key] = cached.row
+			}
+			continue
+		}
+		missingKeys = append(missingKeys, key)
+	}
+	sc.cacheLock.Unlock()
+	instrumentation.IncrementCacheLookupsCount("sqlLookup", true, int64(len(seenKeys)-len(missingKeys)))
+	instrumentation.IncrementCacheLookupsCount("sqlLookup", false, int64(len(missingKeys)))
+
+	if len(missingKeys) == 0 {
+		return retVal, nil
+	}
+
+	fetchedRows := make(map[string]map[string]interface{})
+	remainingRows := sc.lookupCfg.MaxRows
+	for start := 0; start < len(missingKeys) && remainingRows > 0; start += LOOKUP_BATCH_SIZE {
+		end := start + LOOKUP_BATCH_SIZE
+		if end > len(missingKeys) {
+			end = len(missingKeys)
+		}
+		numRows, err := sc.fetchBatch(matchCol, outputCols, missingKeys[start:end], remainingRows, fetchedRows)
+		if err != nil {
+			return nil, err
+		}
+		remainingRows -= numRows
+	}
+	if remainingRows == 0 {
+		log.Warnf("getRows: lookup %v reached the max of %v rows, some events may not be enriched",
+			sc.lookupCfg.Name, sc.lookupCfg.MaxRows)
+	}
+
+	expiry := now.Add(time.Duration(sc.lookupCfg.CacheTTLSecs) * time.Second)
+	sc.cacheLock.Lock()
+	defer sc.cacheLock.Unlock()
+	for _, key := range missingKeys {
+		row := fetchedRows[key]
+		if row == nil && remainingRows == 0 {
+			// the key may not have been fetched because of the row limit, so don't cache the miss
+			continue
+		}
+		sc.cache[getCacheKey(matchCol, outputCols, key)] = &cachedRow{row: row, expiry: expiry, lastAccess: now}
+		if row != nil {
+			retVal[key] = row
+		}
+	}
+	sc.evictEntries(now)
+	return retVal, nil
+}
+
+// Runs a single SELECT for the batch of keys and adds the rows to fetchedRows. Returns the number of rows read.
+func (sc *sqlConnector) fetchBatch(matchCol string, outputCols []string, keys []string, maxRows uint64,
+	fetchedRows map[string]map[string]interface{}) (uint64, error) {
+
+	selectCols := "*"
+	if len(outputCols) > 0 {
+		quotedCols := make([]string, 0, len(outputCols)+1)
+		quotedCols = append(quotedCols, sc.quoteIdentifier(matchCol))
+		for _, col := range outputCols {
+			quotedCols = append(quotedCols, sc.quoteIdentifier(col))
+		}
+		selectCols = strings.Join(quotedCols, ", ")
+	}
+
+	placeholders := make([]string, len(keys))
+	args := make([]interface{}, len(keys))
+	for i, key := range keys {
+		placeholders[i] = sc.getPlaceholder(i + 1)
+		args[i] = key
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s IN (%s) LIMIT %d", selectCols, sc.quoteIdentifier(sc.lookupCfg.Table),
+		sc.quoteIdentifier(matchCol), strings.Join(placeholders, ", "), maxRows)
+
+	rows, err := sc.db.Query(query, args...)
+	if err != nil {
+		log.Errorf("fetchBatch: lookup %v query failed, err: %v", sc.lookupCfg.Name, err)
+		return 0, err
+	}
+	defer rows.Close()
+
+	colNames, err := rows.Columns()
+	if err != nil {
+		log.Errorf("fetchBatch: failed to get columns for lookup %v, err: %v", sc.lookupCfg.Name, err)
+		return 0, err
+	}
+	matchIdx := -1
+	for i, colName := range colNames {
+		if colName == matchCol || strings.HasSuffix(matchCol, "."+colName) {
+			matchIdx = i
+			break
+		}
+	}
+	if matchIdx == -1 {
+		return 0, fmt.Errorf("fetchBatch: column %v not found in lookup %v", matchCol, sc.lookupCfg.Name)
+	}
+
+	numRows := uint64(0)
+	for rows.Next() {
+		values := make([]interface{}, len(colNames))
+		valuePtrs := make([]interface{}, len(colNames))
+		for i := range values {
+			valuePtrs[i] = &values[i]
+		}
+		err := rows.Scan(valuePtrs...)
+		if err != nil {
+			log.Errorf("fetchBatch: failed to scan row for lookup %v, err: %v", sc.lookupCfg.Name, err)
+			return numRows, err
+		}
+		row := make(map[string]interface{}, len(colNames))
+		for i, colName := range colNames {
+			if i == matchIdx {
+				continue
+			}
+			row[colName] = convertSqlValue(values[i])
+		}
+		key := fmt.Sprintf("%v", convertSqlValue(values[matchIdx]))
+		if _, ok := fetchedRows[key]; !ok {
+			fetchedRows[key] = row
+		}
+		numRows++
+	}
+	return numRows, rows.Err()
+}
+
+/*
+Once the cache holds more than MaxRows entries, removes the expired ones and then the least recently used ones until
+it is back to MaxRows. Needs the cacheLock to be held
+*/
+func (sc *sqlConnector) evictEntries(now time.Time) {
+	if uint64(len(sc.cache)) <= sc.lookupCfg.MaxRows {
+		return
+	}
+	for key, cached := range sc.cache {
+		if now.After(cached.expiry) {
+			delete(sc.cache, key)
+		}
+	}
+	if uint64(len(sc.cache)) <= sc.lookupCfg.MaxRows {
+		return
+	}
+
+	keys := make([]string, 0, len(sc.cache))
+	for key := range sc.cache {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return sc.cache[keys[i]].lastAccess.Before(sc.cache[keys[j]].lastAccess)
+	})
+	numToEvict := uint64(len(sc.cache)) - sc.lookupCfg.MaxRows
+	for _, key := range keys[:numToEvict] {
+		delete(sc.cache, key)
+	}
+}
+
+func (sc *sqlConnector) getPlaceholder(idx int) string {
+	if sc.lookupCfg.Driver == "postgres" {
+		return fmt.Sprintf("$%d", idx)
+	}
+	return "?"
+}
+
+func (sc *sqlConnector) quoteIdentifier(identifier string) string {
+	quote := `"`
+	if sc.lookupCfg.Driver == "mysql" {
+		quote = "`"
+	}
+	parts := strings.Split(identifier, ".")
+	for i, part := range parts {
+		parts[i] = quote + part + quote
+	}
+	return strings.Join(parts, ".")
+}
+
+func getCacheKey(matchCol string, outputCols []string, key string) string {
+	return matchCol + "|" + strings.Join(outputCols, ",") + "|" + key
+}
+
+func convertSqlValue(value interface{}) interface{} {
+	switch val := value.(type) {
+	case []byte:
+		return string(val)
+	case time.Time:
+		return val.UTC().Format(time.RFC3339)
+	default:
+		return val
+	}
+}
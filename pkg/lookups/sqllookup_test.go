@@ -0,0 +1,134 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lookups
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/siglens/siglens/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupLookupDB(t *testing.T, maxRows uint64) string {
+	dbPath := filepath.Join(t.TempDir(), "lookup.db")
+	db, err := sql.Open("sqlite3", dbPath)
+	assert.Nil(t, err)
+	defer db.Close()
+
+	_, err = db.Exec(`CREATE TABLE users (id TEXT, name TEXT, team TEXT, age INTEGER)`)
+	assert.Nil(t, err)
+	_, err = db.Exec(`INSERT INTO users VALUES ('u1', 'alice', 'infra', 30), ('u2', 'bob', 'web', 40), ('u3', 'carol', 'infra', 50)`)
+	assert.Nil(t, err)
+
+	config.InitializeDefaultConfig()
+	runningConfig := config.GetRunningConfig()
+	runningConfig.Lookups = []config.LookupConfig{{
+		Name:         "users",
+		Driver:       "sqlite3",
+		DSN:          dbPath,
+		Table:        "users",
+		CacheTTLSecs: 300,
+		MaxRows:      maxRows,
+	}}
+	config.SetConfig(*runningConfig)
+	CloseAllConnectors()
+	t.Cleanup(CloseAllConnectors)
+
+	return dbPath
+}
+
+func Test_GetLookupRows(t *testing.T) {
+	setupLookupDB(t, 100)
+
+	rows, err := GetLookupRows("users", "id", []string{"name", "age"}, []string{"u1", "u3", "u1", "missing"})
+	assert.Nil(t, err)
+	assert.Len(t, rows, 2)
+	assert.Equal(t, map[string]interface{}{"name": "alice", "age": int64(30)}, rows["u1"])
+	assert.Equal(t, map[string]interface{}{"name": "carol", "age": int64(50)}, rows["u3"])
+
+	// All columns are returned without output columns.
+	rows, err = GetLookupRows("users", "id", nil, []string{"u2"})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "bob", "team": "web", "age": int64(40)}, rows["u2"])
+}
+
+func Test_GetLookupRowsUsesCache(t *testing.T) {
+	dbPath := setupLookupDB(t, 100)
+
+	rows, err := GetLookupRows("users", "id", []string{"name"}, []string{"u1", "missing"})
+	assert.Nil(t, err)
+	assert.Len(t, rows, 1)
+
+	db, err := sql.Open("sqlite3", dbPath)
+	assert.Nil(t, err)
+	_, err = db.Exec(`UPDATE users SET name = 'changed'`)
+	assert.Nil(t, err)
+	_, err = db.Exec(`INSERT INTO users VALUES ('missing', 'dave', 'web', 60)`)
+	assert.Nil(t, err)
+	db.Close()
+
+	// Both the hit and the miss should be served from the cache.
+	rows, err = GetLookupRows("users", "id", []string{"name"}, []string{"u1", "missing"})
+	assert.Nil(t, err)
+	assert.Len(t, rows, 1)
+	assert.Equal(t, "alice", rows["u1"]["name"])
+}
+
+func Test_GetLookupRowsMaxRows(t *testing.T) {
+	setupLookupDB(t, 2)
+
+	rows, err := GetLookupRows("users", "id", []string{"name"}, []string{"u1", "u2", "u3"})
+	assert.Nil(t, err)
+	assert.Len(t, rows, 2)
+}
+
+func Test_GetLookupRowsEvictsLeastRecentlyUsed(t *testing.T) {
+	setupLookupDB(t, 2)
+
+	for _, keys := range [][]string{{"u1", "u2"}, {"u1"}, {"u3"}} {
+		rows, err := GetLookupRows("users", "id", []string{"name"}, keys)
+		assert.Nil(t, err)
+		assert.Len(t, rows, len(keys))
+		time.Sleep(time.Millisecond)
+	}
+
+	// None of the entries has expired, so u2, which was used the longest time ago, is evicted.
+	conn, err := getConnector("users")
+	assert.Nil(t, err)
+	conn.cacheLock.Lock()
+	defer conn.cacheLock.Unlock()
+	assert.Len(t, conn.cache, 2)
+	assert.Contains(t, conn.cache, getCacheKey("id", []string{"name"}, "u1"))
+	assert.Contains(t, conn.cache, getCacheKey("id", []string{"name"}, "u3"))
+}
+
+func Test_GetLookupRowsInvalid(t *testing.T) {
+	setupLookupDB(t, 100)
+
+	_, err := GetLookupRows("unknown", "id", nil, []string{"u1"})
+	assert.NotNil(t, err)
+
+	_, err = GetLookupRows("users", "id; DROP TABLE users", nil, []string{"u1"})
+	assert.NotNil(t, err)
+
+	_, err = GetLookupRows("users", "id", []string{"name\""}, []string{"u1"})
+	assert.NotNil(t, err)
+}
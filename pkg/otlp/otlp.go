@@ -8,7 +8,9 @@ import (
 	"fmt"
 	"io/ioutil"
 
+	"github.com/siglens/siglens/pkg/apikeys"
 	"github.com/siglens/siglens/pkg/es/writer"
+	tracinghandler "github.com/siglens/siglens/pkg/segment/tracing/handler"
 	"github.com/siglens/siglens/pkg/utils"
 	log "github.com/sirupsen/logrus"
 	"github.com/valyala/fasthttp"
@@ -27,6 +29,10 @@ func ProcessTraceIngest(ctx *fasthttp.RequestCtx) {
 		setFailureResponse(ctx, fasthttp.StatusBadRequest, "Expected a protobuf request")
 		return
 	}
+	if indexFilter := apikeys.GetIndexFilter(ctx, apikeys.ACCESS_WRITE); indexFilter != nil && !indexFilter("traces") {
+		setFailureResponse(ctx, fasthttp.StatusForbidden, "The api key can not write to index traces")
+		return
+	}
 
 	// Get the data from the request.
 	data := ctx.PostBody()
@@ -84,6 +90,18 @@ func ProcessTraceIngest(ctx *fasthttp.RequestCtx) {
 					continue
 				}
 
+				// RED metrics are of all the spans, including the ones dropped by sampling
+				isError := span.Status != nil && span.Status.Code == tracepb.Status_STATUS_CODE_ERROR
+				duration := span.EndTimeUnixNano - span.StartTimeUnixNano
+				tracinghandler.RecordSpanRedMetrics(service, span.Name, span.Kind.String(), duration, isError, now)
+
+				if sampler := getTailSampler(); sampler != nil {
+					if !sampler.add(hex.EncodeToString(span.TraceId), jsonData, isError, duration, now) {
+						// buffered until the trace is decided, or dropped
+						continue
+					}
+				}
+
 				lenJsonData := uint64(len(jsonData))
 				err = writer.ProcessIndexRequest(jsonData, now, indexName, lenJsonData, shouldFlush, localIndexMap, orgId)
 				if err != nil {
@@ -0,0 +1,198 @@
+package otlp
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cespare/xxhash"
+	"github.com/siglens/siglens/pkg/config"
+	"github.com/siglens/siglens/pkg/es/writer"
+	"github.com/siglens/siglens/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+/*
+	Tail based sampling of the ingested traces. The spans of a trace are buffered until the decision window after
+	its first span has passed, then the whole trace is either written or dropped. The decision is remembered for a
+	while so that the late spans of a trace follow it.
+*/
+
+const DEFAULT_DECISION_WAIT_SECS = 10
+const DEFAULT_MAX_BUFFERED_TRACES = 50_000
+const TAIL_SAMPLING_FLUSH_INTERVAL = time.Second
+
+// Decisions are remembered for this many decision windows
+const DECISION_TTL_WINDOWS = 6
+
+type bufferedTrace struct {
+	traceId     string
+	spans       [][]byte
+	firstSeenMs uint64
+	hasError    bool
+	maxDuration uint64 // in ns
+}
+
+type traceDecision struct {
+	traceId  string
+	keep     bool
+	expiryMs uint64
+}
+
+type tailSampler struct {
+	lock               sync.Mutex
+	decisionWaitMs     uint64
+	maxBufferedTraces  int
+	keepErrors         bool
+	latencyThresholdNs uint64
+	samplePercent      float64
+	pending            map[string]*bufferedTrace
+	pendingOrder       []*bufferedTrace // in the order of the first spans, which is also the order of the decisions
+	decided            map[string]*traceDecision
+	decidedOrder       []*traceDecision
+}
+
+var tailSamplerOnce sync.Once
+var globalTailSampler *tailSampler
+
+// Returns nil if tail sampling is disabled
+func getTailSampler() *tailSampler {
+	tailSamplerOnce.Do(func() {
+		samplingCfg := config.GetTailSamplingConfig()
+		if !samplingCfg.Enabled {
+			return
+		}
+		globalTailSampler = newTailSampler(samplingCfg)
+		go tailSamplingFlushLooper()
+	})
+	return globalTailSampler
+}
+
+func newTailSampler(samplingCfg config.TailSamplingConfig) *tailSampler {
+	ts := &tailSampler{
+		decisionWaitMs:     samplingCfg.DecisionWaitSecs * 1000,
+		maxBufferedTraces:  int(samplingCfg.MaxBufferedTraces),
+		keepErrors:         samplingCfg.KeepErrors,
+		latencyThresholdNs: samplingCfg.LatencyThresholdMs * uint64(time.Millisecond),
+		samplePercent:      samplingCfg.SamplePercent,
+		pending:            make(map[string]*bufferedTrace),
+		pendingOrder:       make([]*bufferedTrace, 0),
+		decided:            make(map[string]*traceDecision),
+		decidedOrder:       make([]*traceDecision, 0),
+	}
+	if ts.decisionWaitMs == 0 {
+		ts.decisionWaitMs = DEFAULT_DECISION_WAIT_SECS * 1000
+	}
+	if ts.maxBufferedTraces == 0 {
+		ts.maxBufferedTraces = DEFAULT_MAX_BUFFERED_TRACES
+	}
+	return ts
+}
+
+func tailSamplingFlushLooper() {
+	for {
+		time.Sleep(TAIL_SAMPLING_FLUSH_INTERVAL)
+		writeSampledSpans(globalTailSampler.removeDecided(utils.GetCurrentTimeInMs(), false))
+	}
+}
+
+// Decides all the buffered traces and writes the kept ones, used on shutdown
+func FlushTailSampler() {
+	sampler := getTailSampler()
+	if sampler == nil {
+		return
+	}
+	writeSampledSpans(sampler.removeDecided(utils.GetCurrentTimeInMs(), true))
+}
+
+func writeSampledSpans(spans [][]byte) {
+	now := utils.GetCurrentTimeInMs()
+	localIndexMap := make(map[string]string)
+	for _, jsonData := range spans {
+		err := writer.ProcessIndexRequest(jsonData, now, "traces", uint64(len(jsonData)), false, localIndexMap, 0)
+		if err != nil {
+			log.Errorf("writeSampledSpans: failed to process ingest request: %v", err)
+		}
+	}
+}
+
+/*
+Adds a span to its trace. Returns true if the span should be written right away, which is when the trace was
+already decided to be kept. Other spans are buffered or, if the trace was already dropped, discarded
+*/
+func (ts *tailSampler) add(traceId string, jsonData []byte, isError bool, duration uint64, nowMs uint64) bool {
+	ts.lock.Lock()
+	defer ts.lock.Unlock()
+
+	if decision, ok := ts.decided[traceId]; ok {
+		return decision.keep
+	}
+
+	trace, ok := ts.pending[traceId]
+	if !ok {
+		trace = &bufferedTrace{traceId: traceId, spans: make([][]byte, 0), firstSeenMs: nowMs}
+		ts.pending[traceId] = trace
+		ts.pendingOrder = append(ts.pendingOrder, trace)
+	}
+	trace.spans = append(trace.spans, jsonData)
+	trace.hasError = trace.hasError || isError
+	if duration > trace.maxDuration {
+		trace.maxDuration = duration
+	}
+	return false
+}
+
+/*
+Decides the traces whose decision window has passed, or all of them if decideAll is set, and the oldest ones past
+maxBufferedTraces. Returns the spans of the kept traces
+*/
+func (ts *tailSampler) removeDecided(nowMs uint64, decideAll bool) [][]byte {
+	ts.lock.Lock()
+	defer ts.lock.Unlock()
+
+	keptSpans := make([][]byte, 0)
+	numDecided := 0
+	for _, trace := range ts.pendingOrder {
+		isExpired := trace.firstSeenMs+ts.decisionWaitMs <= nowMs
+		isOverLimit := len(ts.pendingOrder)-numDecided > ts.maxBufferedTraces
+		if !decideAll && !isExpired && !isOverLimit {
+			break
+		}
+		numDecided++
+
+		decision := &traceDecision{
+			traceId:  trace.traceId,
+			keep:     ts.shouldKeep(trace),
+			expiryMs: nowMs + DECISION_TTL_WINDOWS*ts.decisionWaitMs,
+		}
+		ts.decided[trace.traceId] = decision
+		ts.decidedOrder = append(ts.decidedOrder, decision)
+		delete(ts.pending, trace.traceId)
+		if decision.keep {
+			keptSpans = append(keptSpans, trace.spans...)
+		}
+	}
+	ts.pendingOrder = ts.pendingOrder[numDecided:]
+
+	numExpired := 0
+	for _, decision := range ts.decidedOrder {
+		if decision.expiryMs > nowMs {
+			break
+		}
+		delete(ts.decided, decision.traceId)
+		numExpired++
+	}
+	ts.decidedOrder = ts.decidedOrder[numExpired:]
+
+	return keptSpans
+}
+
+func (ts *tailSampler) shouldKeep(trace *bufferedTrace) bool {
+	if ts.keepErrors && trace.hasError {
+		return true
+	}
+	if ts.latencyThresholdNs > 0 && trace.maxDuration >= ts.latencyThresholdNs {
+		return true
+	}
+	// hashing the trace id keeps the same traces no matter which node ingests them
+	return float64(xxhash.Sum64String(trace.traceId)%10_000) < ts.samplePercent*100
+}
@@ -0,0 +1,67 @@
+package otlp
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/siglens/siglens/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_tailSamplerPolicies(t *testing.T) {
+	sampler := newTailSampler(config.TailSamplingConfig{
+		Enabled:            true,
+		DecisionWaitSecs:   10,
+		KeepErrors:         true,
+		LatencyThresholdMs: 500,
+		SamplePercent:      0,
+	})
+
+	assert.False(t, sampler.add("errors", []byte("e1"), false, 1000, 1000))
+	assert.False(t, sampler.add("errors", []byte("e2"), true, 1000, 2000))
+	assert.False(t, sampler.add("slow", []byte("s1"), false, 600_000_000, 3000))
+	assert.False(t, sampler.add("fast", []byte("f1"), false, 1000, 4000))
+
+	// nothing is decided before the decision window of the first span has passed
+	assert.Empty(t, sampler.removeDecided(5000, false))
+	assert.Equal(t, [][]byte{[]byte("e1"), []byte("e2")}, sampler.removeDecided(11_000, false))
+	assert.Equal(t, [][]byte{[]byte("s1")}, sampler.removeDecided(14_000, false))
+	assert.Empty(t, sampler.pending)
+
+	// late spans follow the decision of their trace
+	assert.True(t, sampler.add("errors", []byte("e3"), false, 1000, 15_000))
+	assert.False(t, sampler.add("fast", []byte("f2"), false, 1000, 15_000))
+	assert.Empty(t, sampler.pending)
+
+	// decisions are forgotten after their ttl
+	sampler.removeDecided(100_000, false)
+	assert.Empty(t, sampler.decided)
+	assert.Empty(t, sampler.decidedOrder)
+}
+
+func Test_tailSamplerLimits(t *testing.T) {
+	sampler := newTailSampler(config.TailSamplingConfig{Enabled: true, MaxBufferedTraces: 2, SamplePercent: 100})
+
+	assert.False(t, sampler.add("a", []byte("a1"), false, 1, 1000))
+	assert.False(t, sampler.add("b", []byte("b1"), false, 1, 1000))
+	assert.False(t, sampler.add("c", []byte("c1"), false, 1, 1000))
+	assert.Equal(t, [][]byte{[]byte("a1")}, sampler.removeDecided(1000, false))
+	assert.Len(t, sampler.pending, 2)
+
+	assert.Equal(t, [][]byte{[]byte("b1"), []byte("c1")}, sampler.removeDecided(1000, true))
+	assert.Empty(t, sampler.pending)
+}
+
+func Test_tailSamplerProbabilistic(t *testing.T) {
+	sampler := newTailSampler(config.TailSamplingConfig{Enabled: true, SamplePercent: 25})
+	numKept := 0
+	for i := 0; i < 10_000; i++ {
+		if sampler.shouldKeep(&bufferedTrace{traceId: strconv.Itoa(i) + "-trace"}) {
+			numKept++
+		}
+	}
+	assert.InDelta(t, 2500, numKept, 300)
+
+	trace := &bufferedTrace{traceId: "0af7651916cd43dd8448eb211c80319c"}
+	assert.Equal(t, sampler.shouldKeep(trace), sampler.shouldKeep(trace))
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/siglens/siglens/pkg/blob"
 	"github.com/siglens/siglens/pkg/common/fileutils"
 	"github.com/siglens/siglens/pkg/config"
+	"github.com/siglens/siglens/pkg/health/heartbeat"
 	"github.com/siglens/siglens/pkg/segment/query/metadata"
 	pqsmeta "github.com/siglens/siglens/pkg/segment/query/pqs/meta"
 	"github.com/siglens/siglens/pkg/segment/structs"
@@ -42,10 +43,12 @@ func InitRetentionCleaner() error {
 }
 
 func internalRetentionCleaner() {
+	heartbeat.Register(heartbeat.RETENTION_SWEEPER, 1*time.Hour)
 	time.Sleep(1 * time.Minute) // sleep for 1min for the rest of the system to come up
 	deletionWarningCounter := 0
 	for {
 		doRetentionBasedDeletion(config.GetCurrentNodeIngestDir(), config.GetRetentionHours(), 0)
+		heartbeat.Record(heartbeat.RETENTION_SWEEPER, nil)
 		deletionWarningCounter++
 		time.Sleep(1 * time.Hour)
 	}
@@ -23,6 +23,8 @@ import (
 	"sort"
 	"strconv"
 
+	"github.com/siglens/siglens/pkg/embeddings"
+	"github.com/siglens/siglens/pkg/lookups"
 	"github.com/siglens/siglens/pkg/segment/structs"
 	segutils "github.com/siglens/siglens/pkg/segment/utils"
 	"github.com/siglens/siglens/pkg/utils"
@@ -89,7 +91,9 @@ func PostQueryBucketCleaning(nodeResult *structs.NodeResult, post *structs.Query
 
 		if err != nil {
 			log.Errorf("PostQueryBucketCleaning: %v", err)
-			nodeResult.ErrList = append(nodeResult.ErrList, err)
+			if nodeResult != nil {
+				nodeResult.ErrList = append(nodeResult.ErrList, err)
+			}
 		}
 	}
 
@@ -127,6 +131,14 @@ func performAggOnResult(nodeResult *structs.NodeResult, agg *structs.QueryAggreg
 				return fmt.Errorf("performAggOnResult: %v", err)
 			}
 		}
+
+		if agg.OutputTransforms.SimilarTo != nil {
+			err := performSimilarToRequest(nodeResult, agg.OutputTransforms.SimilarTo, recs, finalCols)
+
+			if err != nil {
+				return fmt.Errorf("performAggOnResult: %v", err)
+			}
+		}
 	default:
 		return errors.New("performAggOnResult: multiple QueryAggregators is currently only supported for OutputTransformType")
 	}
@@ -213,6 +225,10 @@ func performLetColumnsRequest(nodeResult *structs.NodeResult, aggs *structs.Quer
 		if err := performStatisticColRequest(nodeResult, aggs, letColReq, recs); err != nil {
 			return fmt.Errorf("performLetColumnsRequest: %v", err)
 		}
+	} else if letColReq.LookupColRequest != nil {
+		if err := performLookupColRequest(nodeResult, letColReq, recs, finalCols); err != nil {
+			return fmt.Errorf("performLetColumnsRequest: %v", err)
+		}
 	} else {
 		return errors.New("performLetColumnsRequest: expected one of MultiColsRequest, SingleColRequest, ValueColRequest, RexColRequest to have a value")
 	}
@@ -885,6 +901,162 @@ func performRexColRequestOnMeasureResults(nodeResult *structs.NodeResult, letCol
 	return nil
 }
 
+func performLookupColRequest(nodeResult *structs.NodeResult, letColReq *structs.LetColumnsRequest, recs map[string]map[string]interface{}, finalCols map[string]bool) error {
+
+	//Without following group by
+	if recs != nil {
+		if err := performLookupColRequestWithoutGroupby(letColReq, recs, finalCols); err != nil {
+			return fmt.Errorf("performLookupColRequest: %v", err)
+		}
+		return nil
+	}
+
+	//Follow group by
+	if err := performLookupColRequestOnHistogram(nodeResult, letColReq); err != nil {
+		return fmt.Errorf("performLookupColRequest: %v", err)
+	}
+	if err := performLookupColRequestOnMeasureResults(nodeResult, letColReq); err != nil {
+		return fmt.Errorf("performLookupColRequest: %v", err)
+	}
+
+	return nil
+}
+
+func getLookupRows(lookupExpr *structs.LookupExpr, keys []string) (map[string]map[string]interface{}, []string, error) {
+	lookupRows, err := lookups.GetLookupRows(lookupExpr.LookupName, lookupExpr.MatchField, lookupExpr.OutputFields, keys)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	outputCols := lookupExpr.OutputFields
+	if len(outputCols) == 0 {
+		// Without an explicit OUTPUT list, add every column returned by the lookup table.
+		allCols := make(map[string]bool)
+		for _, row := range lookupRows {
+			for colName := range row {
+				allCols[colName] = true
+			}
+		}
+		outputCols = make([]string, 0, len(allCols))
+		for colName := range allCols {
+			outputCols = append(outputCols, colName)
+		}
+		sort.Strings(outputCols)
+	}
+
+	return lookupRows, outputCols, nil
+}
+
+func performLookupColRequestWithoutGroupby(letColReq *structs.LetColumnsRequest, recs map[string]map[string]interface{}, finalCols map[string]bool) error {
+	lookupExpr := letColReq.LookupColRequest
+
+	keys := make([]string, 0, len(recs))
+	for _, record := range recs {
+		fieldValue, exists := record[lookupExpr.EventField]
+		if !exists || fieldValue == nil {
+			continue
+		}
+		keys = append(keys, fmt.Sprintf("%v", fieldValue))
+	}
+
+	lookupRows, outputCols, err := getLookupRows(lookupExpr, keys)
+	if err != nil {
+		return fmt.Errorf("performLookupColRequestWithoutGroupby: %v", err)
+	}
+
+	for _, record := range recs {
+		fieldValue, exists := record[lookupExpr.EventField]
+		if !exists || fieldValue == nil {
+			continue
+		}
+		row, exists := lookupRows[fmt.Sprintf("%v", fieldValue)]
+		if !exists {
+			continue
+		}
+		for _, colName := range outputCols {
+			record[colName] = row[colName]
+		}
+	}
+
+	for _, colName := range outputCols {
+		finalCols[colName] = true
+	}
+
+	return nil
+}
+
+func performLookupColRequestOnHistogram(nodeResult *structs.NodeResult, letColReq *structs.LetColumnsRequest) error {
+	lookupExpr := letColReq.LookupColRequest
+	fieldsInExpr := lookupExpr.GetFields()
+	fieldToValue := make(map[string]segutils.CValueEnclosure, 0)
+
+	for _, aggregationResult := range nodeResult.Histogram {
+		rowKeys := make([]string, len(aggregationResult.Results))
+		for rowIndex := range aggregationResult.Results {
+			err := getAggregationResultFieldValues(fieldToValue, fieldsInExpr, aggregationResult, rowIndex)
+			if err != nil {
+				return fmt.Errorf("performLookupColRequestOnHistogram: %v", err)
+			}
+			rowKeys[rowIndex] = fmt.Sprintf("%v", fieldToValue[lookupExpr.EventField].CVal)
+		}
+
+		lookupRows, outputCols, err := getLookupRows(lookupExpr, rowKeys)
+		if err != nil {
+			return fmt.Errorf("performLookupColRequestOnHistogram: %v", err)
+		}
+
+		for rowIndex, bucketResult := range aggregationResult.Results {
+			row := lookupRows[rowKeys[rowIndex]]
+			for _, colName := range outputCols {
+				var enclosure segutils.CValueEnclosure
+				err := enclosure.ConvertValue(row[colName])
+				if err != nil {
+					enclosure.Dtype = segutils.SS_DT_STRING
+					enclosure.CVal = fmt.Sprintf("%v", row[colName])
+				}
+				bucketResult.StatRes[colName] = enclosure
+			}
+		}
+	}
+
+	return nil
+}
+
+func performLookupColRequestOnMeasureResults(nodeResult *structs.NodeResult, letColReq *structs.LetColumnsRequest) error {
+	lookupExpr := letColReq.LookupColRequest
+	fieldsInExpr := lookupExpr.GetFields()
+	fieldToValue := make(map[string]segutils.CValueEnclosure, 0)
+
+	rowKeys := make([]string, len(nodeResult.MeasureResults))
+	for rowIndex := range nodeResult.MeasureResults {
+		err := getMeasureResultsFieldValues(fieldToValue, fieldsInExpr, nodeResult, rowIndex)
+		if err != nil {
+			return fmt.Errorf("performLookupColRequestOnMeasureResults: %v", err)
+		}
+		rowKeys[rowIndex] = fmt.Sprintf("%v", fieldToValue[lookupExpr.EventField].CVal)
+	}
+
+	lookupRows, outputCols, err := getLookupRows(lookupExpr, rowKeys)
+	if err != nil {
+		return fmt.Errorf("performLookupColRequestOnMeasureResults: %v", err)
+	}
+
+	for _, colName := range outputCols {
+		if !utils.SliceContainsString(nodeResult.MeasureFunctions, colName) {
+			nodeResult.MeasureFunctions = append(nodeResult.MeasureFunctions, colName)
+		}
+	}
+
+	for rowIndex, bucketHolder := range nodeResult.MeasureResults {
+		row := lookupRows[rowKeys[rowIndex]]
+		for _, colName := range outputCols {
+			bucketHolder.MeasureVal[colName] = row[colName]
+		}
+	}
+
+	return nil
+}
+
 func performValueColRequest(nodeResult *structs.NodeResult, letColReq *structs.LetColumnsRequest) error {
 	if len(nodeResult.AllRecords) > 0 {
 		return errors.New("performValueColRequest: ValueColRequest is only implemented for aggregation fields")
@@ -1053,6 +1225,44 @@ func performValueColRequestOnMeasureResults(nodeResult *structs.NodeResult, letC
 	return nil
 }
 
+func performSimilarToRequest(nodeResult *structs.NodeResult, similarTo *structs.SimilarToRequest, recs map[string]map[string]interface{}, finalCols map[string]bool) error {
+	if recs == nil {
+		if len(nodeResult.MeasureResults) > 0 || len(nodeResult.Histogram) > 0 {
+			return errors.New("performSimilarToRequest: similar_to is only implemented for raw events")
+		}
+		return nil
+	}
+
+	recordKeys := make([]string, 0, len(recs))
+	messages := make([]string, 0, len(recs))
+	for recordKey, record := range recs {
+		fieldValue, exists := record[similarTo.FieldName]
+		if !exists || fieldValue == nil {
+			delete(recs, recordKey)
+			continue
+		}
+		recordKeys = append(recordKeys, recordKey)
+		messages = append(messages, fmt.Sprintf("%v", fieldValue))
+	}
+
+	similarMessages, err := embeddings.GetSimilarMessages(similarTo.Example, messages, similarTo.Threshold)
+	if err != nil {
+		return fmt.Errorf("performSimilarToRequest: %v", err)
+	}
+
+	for i, recordKey := range recordKeys {
+		score, ok := similarMessages[i]
+		if !ok {
+			delete(recs, recordKey)
+			continue
+		}
+		recs[recordKey][embeddings.SIMILARITY_SCORE_COL] = score
+	}
+	finalCols[embeddings.SIMILARITY_SCORE_COL] = true
+
+	return nil
+}
+
 func performFilterRows(nodeResult *structs.NodeResult, filterRows *structs.BoolExpr) error {
 	// Ensure all referenced columns are valid.
 	for _, field := range filterRows.GetFields() {
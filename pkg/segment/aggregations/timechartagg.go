@@ -1,7 +1,7 @@
 package aggregations
 
 import (
-	"sort"
+	"container/heap"
 	"time"
 
 	"github.com/axiomhq/hyperloglog"
@@ -13,7 +13,76 @@ import (
 type scorePair struct {
 	groupByColVal string
 	score         float64
-	index         int
+}
+
+// Ranks a before b if it has the higher score, or the lower score if isTop is not set. Ties go to the smaller value
+func isBetterScore(a scorePair, b scorePair, isTop bool) bool {
+	if a.score != b.score {
+		if isTop {
+			return a.score > b.score
+		}
+		return a.score < b.score
+	}
+	return a.groupByColVal < b.groupByColVal
+}
+
+// Heap of the best scores seen so far, with the worst of them at the root so that it is the one replaced
+type scorePairHeap struct {
+	pairs []scorePair
+	isTop bool
+}
+
+func (h *scorePairHeap) Len() int { return len(h.pairs) }
+
+func (h *scorePairHeap) Less(i, j int) bool {
+	return isBetterScore(h.pairs[j], h.pairs[i], h.isTop)
+}
+
+func (h *scorePairHeap) Swap(i, j int) {
+	h.pairs[i], h.pairs[j] = h.pairs[j], h.pairs[i]
+}
+
+func (h *scorePairHeap) Push(x interface{}) {
+	h.pairs = append(h.pairs, x.(scorePair))
+}
+
+func (h *scorePairHeap) Pop() interface{} {
+	n := len(h.pairs)
+	pair := h.pairs[n-1]
+	h.pairs = h.pairs[:n-1]
+	return pair
+}
+
+// Keeps the limit best scores it is given in a bounded heap, which avoids sorting the scores of every value
+type topKScores struct {
+	limit int
+	heap  *scorePairHeap
+}
+
+func newTopKScores(limit int, isTop bool) *topKScores {
+	if limit < 0 {
+		limit = 0
+	}
+	return &topKScores{
+		limit: limit,
+		heap:  &scorePairHeap{pairs: make([]scorePair, 0, limit), isTop: isTop},
+	}
+}
+
+func (tk *topKScores) add(pair scorePair) {
+	if tk.heap.Len() < tk.limit {
+		heap.Push(tk.heap, pair)
+	} else if tk.limit > 0 && isBetterScore(pair, tk.heap.pairs[0], tk.heap.isTop) {
+		tk.heap.pairs[0] = pair
+		heap.Fix(tk.heap, 0)
+	}
+}
+
+// Marks the values of the kept scores as in the limit
+func (tk *topKScores) markInLimit(valIsInLimit map[string]bool) {
+	for _, pair := range tk.heap.pairs {
+		valIsInLimit[pair.groupByColVal] = true
+	}
 }
 
 func GenerateTimeRangeBuckets(timeHistogram *structs.TimeBucket) []uint64 {
@@ -124,18 +193,17 @@ func AddAggAvgToTimechartRunningStats(m *structs.MeasureAggregator, allConverted
 // Timechart will only display N highest/lowest scoring distinct values of the split-by field
 // For Single agg, the score is based on the sum of the values in the aggregation. Therefore, we can only know groupByColVal's ranking after processing all the runningStats
 // For multiple aggs, the score is based on the freq of the field. Which means we can rank groupByColVal at this time.
+// Equal scores are ranked by the smaller groupByColVal, so the same values are kept no matter the order of the maps
 func CheckGroupByColValsAgainstLimit(timechart *structs.TimechartExpr, groupByColValCnt map[string]int, groupValScoreMap map[string]*utils.CValueEnclosure) map[string]bool {
 
 	if timechart == nil || timechart.LimitExpr == nil {
 		return nil
 	}
 
-	index := 0
 	valIsInLimit := make(map[string]bool)
+	topScores := newTopKScores(timechart.LimitExpr.Num, timechart.LimitExpr.IsTop)
 	isRankBySum := IsRankBySum(timechart)
 	if isRankBySum {
-		scorePairs := make([]scorePair, 0)
-		// []float64, 0: score; 1: index
 		for groupByColVal, cVal := range groupValScoreMap {
 			valIsInLimit[groupByColVal] = false
 			score, err := cVal.GetFloatValue()
@@ -143,65 +211,15 @@ func CheckGroupByColValsAgainstLimit(timechart *structs.TimechartExpr, groupByCo
 				log.Errorf("CheckGroupByColValsAgainstLimit: %v does not have a score", groupByColVal)
 				continue
 			}
-			scorePairs = append(scorePairs, scorePair{
-				groupByColVal: groupByColVal,
-				score:         score,
-				index:         index,
-			})
-			index++
+			topScores.add(scorePair{groupByColVal: groupByColVal, score: score})
 		}
-
-		if timechart.LimitExpr.IsTop {
-			sort.Slice(scorePairs, func(i, j int) bool {
-				return scorePairs[i].score > scorePairs[j].score
-			})
-		} else {
-			sort.Slice(scorePairs, func(i, j int) bool {
-				return scorePairs[i].score < scorePairs[j].score
-			})
-		}
-
-		limit := timechart.LimitExpr.Num
-		if limit > len(scorePairs) {
-			limit = len(scorePairs)
-		}
-
-		for i := 0; i < limit; i++ {
-			valIsInLimit[scorePairs[i].groupByColVal] = true
-		}
-
 	} else { // rank by freq
-		// []int, 0: cnt; 1: index
-		cnts := make([][]int, 0)
-		vals := make([]string, 0)
-
 		for groupByColVal, cnt := range groupByColValCnt {
-			vals = append(vals, groupByColVal)
-			cnts = append(cnts, []int{cnt, index})
 			valIsInLimit[groupByColVal] = false
-			index++
-		}
-
-		if timechart.LimitExpr.IsTop {
-			sort.Slice(cnts, func(i, j int) bool {
-				return cnts[i][0] > cnts[j][0]
-			})
-		} else {
-			sort.Slice(cnts, func(i, j int) bool {
-				return cnts[i][0] < cnts[j][0]
-			})
-		}
-
-		limit := timechart.LimitExpr.Num
-		if limit > len(vals) {
-			limit = len(vals)
-		}
-
-		for i := 0; i < limit; i++ {
-			valIndex := cnts[i][1]
-			valIsInLimit[vals[valIndex]] = true
+			topScores.add(scorePair{groupByColVal: groupByColVal, score: float64(cnt)})
 		}
 	}
+	topScores.markInLimit(valIsInLimit)
 
 	return valIsInLimit
 }
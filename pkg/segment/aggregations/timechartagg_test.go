@@ -0,0 +1,107 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aggregations
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/siglens/siglens/pkg/segment/structs"
+	"github.com/siglens/siglens/pkg/segment/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func getValsInLimit(valIsInLimit map[string]bool) []string {
+	vals := make([]string, 0)
+	for val, inLimit := range valIsInLimit {
+		if inLimit {
+			vals = append(vals, val)
+		}
+	}
+	return vals
+}
+
+func Test_CheckGroupByColValsAgainstLimit_BySum(t *testing.T) {
+	timechart := &structs.TimechartExpr{
+		LimitExpr: &structs.LimitExpr{IsTop: true, Num: 2, LimitScoreMode: structs.LSMBySum},
+	}
+	scores := map[string]*utils.CValueEnclosure{
+		"a": {Dtype: utils.SS_DT_FLOAT, CVal: float64(5)},
+		"b": {Dtype: utils.SS_DT_SIGNED_NUM, CVal: int64(9)},
+		"c": {Dtype: utils.SS_DT_FLOAT, CVal: float64(1)},
+		"d": {Dtype: utils.SS_DT_FLOAT, CVal: float64(7)},
+		"e": {Dtype: utils.SS_INVALID, CVal: nil},
+	}
+
+	valIsInLimit := CheckGroupByColValsAgainstLimit(timechart, nil, scores)
+	assert.Len(t, valIsInLimit, 5)
+	assert.ElementsMatch(t, []string{"b", "d"}, getValsInLimit(valIsInLimit))
+
+	timechart.LimitExpr.IsTop = false
+	valIsInLimit = CheckGroupByColValsAgainstLimit(timechart, nil, scores)
+	assert.ElementsMatch(t, []string{"a", "c"}, getValsInLimit(valIsInLimit))
+
+	timechart.LimitExpr.Num = 10
+	valIsInLimit = CheckGroupByColValsAgainstLimit(timechart, nil, scores)
+	assert.ElementsMatch(t, []string{"a", "b", "c", "d"}, getValsInLimit(valIsInLimit))
+
+	timechart.LimitExpr.Num = 0
+	valIsInLimit = CheckGroupByColValsAgainstLimit(timechart, nil, scores)
+	assert.Len(t, getValsInLimit(valIsInLimit), 0)
+}
+
+func Test_CheckGroupByColValsAgainstLimit_ByFreq(t *testing.T) {
+	timechart := &structs.TimechartExpr{
+		LimitExpr: &structs.LimitExpr{IsTop: true, Num: 3, LimitScoreMode: structs.LSMByFreq},
+	}
+	counts := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		counts[fmt.Sprintf("val%04d", i)] = i
+	}
+
+	valIsInLimit := CheckGroupByColValsAgainstLimit(timechart, counts, nil)
+	assert.Len(t, valIsInLimit, 1000)
+	assert.ElementsMatch(t, []string{"val0999", "val0998", "val0997"}, getValsInLimit(valIsInLimit))
+
+	timechart.LimitExpr.IsTop = false
+	valIsInLimit = CheckGroupByColValsAgainstLimit(timechart, counts, nil)
+	assert.ElementsMatch(t, []string{"val0000", "val0001", "val0002"}, getValsInLimit(valIsInLimit))
+}
+
+func Test_CheckGroupByColValsAgainstLimit_Ties(t *testing.T) {
+	timechart := &structs.TimechartExpr{
+		LimitExpr: &structs.LimitExpr{IsTop: true, Num: 2, LimitScoreMode: structs.LSMByFreq},
+	}
+	counts := map[string]int{"d": 4, "c": 4, "b": 4, "a": 1, "e": 4}
+
+	// the smaller values win the ties, on every run
+	for i := 0; i < 20; i++ {
+		valIsInLimit := CheckGroupByColValsAgainstLimit(timechart, counts, nil)
+		assert.ElementsMatch(t, []string{"b", "c"}, getValsInLimit(valIsInLimit))
+	}
+
+	timechart.LimitExpr.IsTop = false
+	for i := 0; i < 20; i++ {
+		valIsInLimit := CheckGroupByColValsAgainstLimit(timechart, counts, nil)
+		assert.ElementsMatch(t, []string{"a", "b"}, getValsInLimit(valIsInLimit))
+	}
+
+	counts = map[string]int{"z": 2, "y": 9, "x": 2}
+	timechart.LimitExpr.IsTop = true
+	valIsInLimit := CheckGroupByColValsAgainstLimit(timechart, counts, nil)
+	assert.ElementsMatch(t, []string{"y", "x"}, getValsInLimit(valIsInLimit))
+}
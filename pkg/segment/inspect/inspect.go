@@ -0,0 +1,361 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inspect
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+
+	"github.com/cespare/xxhash"
+	"github.com/klauspost/compress/zstd"
+	"github.com/siglens/siglens/pkg/blob"
+	"github.com/siglens/siglens/pkg/config"
+	"github.com/siglens/siglens/pkg/segment/reader/microreader"
+	"github.com/siglens/siglens/pkg/segment/reader/segread"
+	"github.com/siglens/siglens/pkg/segment/structs"
+	"github.com/siglens/siglens/pkg/segment/utils"
+	toputils "github.com/siglens/siglens/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+/*
+	Dumps the metadata of a rotated segment from its files, to debug why a segment is large or why a query can't
+	prune it: the time ranges of the segment and its blocks, and for every column its type, the encodings of its
+	blocks, its compressed and uncompressed sizes and which micro indices it has.
+*/
+
+type SegmentInspection struct {
+	SegmentKey        string              `json:"segmentKey"`
+	IndexName         string              `json:"indexName"`
+	EarliestEpochMs   uint64              `json:"earliestEpochMs"`
+	LatestEpochMs     uint64              `json:"latestEpochMs"`
+	RecordCount       int                 `json:"recordCount"`
+	NumBlocks         uint16              `json:"numBlocks"`
+	BytesReceived     uint64              `json:"bytesReceived"`
+	OnDiskBytes       uint64              `json:"onDiskBytes"`
+	CompressedBytes   uint64              `json:"compressedBytes"` // of the column blocks
+	UncompressedBytes uint64              `json:"uncompressedBytes"`
+	MicroIndexBytes   uint64              `json:"microIndexBytes"`
+	HasRollups        bool                `json:"hasRollups"`
+	HasAgileTree      bool                `json:"hasAgileTree"`
+	PQIDs             []string            `json:"pqids"`
+	Columns           []*ColumnInspection `json:"columns"` // the largest first
+	Blocks            []*BlockInspection  `json:"blocks,omitempty"`
+}
+
+type ColumnInspection struct {
+	Name              string            `json:"name"`
+	Type              string            `json:"type"`
+	Encodings         map[string]uint16 `json:"encodings"` // number of blocks per encoding
+	NumBlocks         uint16            `json:"numBlocks"` // blocks with values of the column
+	CompressedBytes   uint64            `json:"compressedBytes"`
+	UncompressedBytes uint64            `json:"uncompressedBytes"`
+	MicroIndexBytes   uint64            `json:"microIndexBytes"`
+	HasBloomIndex     bool              `json:"hasBloomIndex"`
+	HasRangeIndex     bool              `json:"hasRangeIndex"`
+	BloomIndexBlocks  uint16            `json:"bloomIndexBlocks"`
+	RangeIndexBlocks  uint16            `json:"rangeIndexBlocks"`
+}
+
+type BlockInspection struct {
+	BlockNum        uint16 `json:"blockNum"`
+	LowTs           uint64 `json:"lowTs"`
+	HighTs          uint64 `json:"highTs"`
+	RecordCount     uint16 `json:"recordCount"`
+	CompressedBytes uint64 `json:"compressedBytes"`
+}
+
+type microIndexInfo struct {
+	bloomBlocks uint16
+	rangeBlocks uint16
+	size        uint64
+}
+
+var decoder, _ = zstd.NewReader(nil)
+
+// Reads the files of the segment, withBlocks also returns the time range and size of every block
+func InspectSegment(segMeta *structs.SegMeta, withBlocks bool) (*SegmentInspection, error) {
+	blockSummaries, allBmh, _, err := microreader.ReadBlockSummaries(structs.GetBsuFnameFromSegKey(segMeta.SegmentKey), []byte{})
+	if err != nil {
+		log.Errorf("InspectSegment: failed to read the block summaries of segkey=%v, err=%v", segMeta.SegmentKey, err)
+		return nil, fmt.Errorf("failed to read the block summaries: %v", err)
+	}
+
+	inspection := &SegmentInspection{
+		SegmentKey:      segMeta.SegmentKey,
+		IndexName:       segMeta.VirtualTableName,
+		EarliestEpochMs: segMeta.EarliestEpochMS,
+		LatestEpochMs:   segMeta.LatestEpochMS,
+		RecordCount:     segMeta.RecordCount,
+		NumBlocks:       segMeta.NumBlocks,
+		BytesReceived:   segMeta.BytesReceivedCount,
+		OnDiskBytes:     segMeta.OnDiskBytes,
+		HasRollups:      fileExists(fmt.Sprintf("%v/rups", path.Dir(segMeta.SegmentKey))),
+		HasAgileTree:    fileExists(segMeta.SegmentKey + ".strl"),
+		PQIDs:           make([]string, 0, len(segMeta.AllPQIDs)),
+		Columns:         make([]*ColumnInspection, 0),
+	}
+	for pqid := range segMeta.AllPQIDs {
+		inspection.PQIDs = append(inspection.PQIDs, pqid)
+	}
+	sort.Strings(inspection.PQIDs)
+
+	// the types are only known for the columns with stats
+	segStats, err := segread.ReadSegStats(segMeta.SegmentKey, 0)
+	if err != nil {
+		log.Errorf("InspectSegment: failed to read the stats of segkey=%v, continuing without them, err=%v",
+			segMeta.SegmentKey, err)
+	}
+
+	// the segmeta does not list the timestamp column, the blocks have all the columns
+	allCols := make(map[string]struct{}, len(segMeta.ColumnNames)+1)
+	for cname := range segMeta.ColumnNames {
+		allCols[cname] = struct{}{}
+	}
+	for _, bmh := range allBmh {
+		for cname := range bmh.ColumnBlockLen {
+			allCols[cname] = struct{}{}
+		}
+	}
+
+	bulkDownloadFiles := make(map[string]string)
+	for cname := range allCols {
+		bulkDownloadFiles[getCsgFname(segMeta.SegmentKey, cname)] = cname
+		bulkDownloadFiles[getCmiFname(segMeta.SegmentKey, cname)] = cname
+	}
+	err = blob.BulkDownloadSegmentBlob(bulkDownloadFiles, false)
+	if err != nil {
+		log.Errorf("InspectSegment: failed to download the files of segkey=%v, err=%v", segMeta.SegmentKey, err)
+		return nil, err
+	}
+
+	for cname := range allCols {
+		colInspection, err := inspectColumn(segMeta.SegmentKey, cname, allBmh)
+		if err != nil {
+			log.Errorf("InspectSegment: failed to inspect column %v of segkey=%v, err=%v", cname, segMeta.SegmentKey, err)
+			return nil, fmt.Errorf("failed to read column %v: %v", cname, err)
+		}
+		colInspection.Type = getColumnType(cname, segStats[cname], colInspection)
+		inspection.CompressedBytes += colInspection.CompressedBytes
+		inspection.UncompressedBytes += colInspection.UncompressedBytes
+		inspection.MicroIndexBytes += colInspection.MicroIndexBytes
+		inspection.Columns = append(inspection.Columns, colInspection)
+	}
+	sort.Slice(inspection.Columns, func(i, j int) bool {
+		if inspection.Columns[i].CompressedBytes != inspection.Columns[j].CompressedBytes {
+			return inspection.Columns[i].CompressedBytes > inspection.Columns[j].CompressedBytes
+		}
+		return inspection.Columns[i].Name < inspection.Columns[j].Name
+	})
+
+	if withBlocks {
+		inspection.Blocks = makeBlockInspections(blockSummaries, allBmh)
+	}
+	return inspection, nil
+}
+
+func getCsgFname(segKey string, cname string) string {
+	return fmt.Sprintf("%v_%v.csg", segKey, xxhash.Sum64String(cname))
+}
+
+func getCmiFname(segKey string, cname string) string {
+	return fmt.Sprintf("%v_%v.cmi", segKey, xxhash.Sum64String(cname))
+}
+
+func fileExists(fname string) bool {
+	_, err := os.Stat(fname)
+	return err == nil
+}
+
+// the block summaries are in the order of the blocks
+func makeBlockInspections(blockSummaries []*structs.BlockSummary,
+	allBmh map[uint16]*structs.BlockMetadataHolder) []*BlockInspection {
+
+	blocks := make([]*BlockInspection, 0, len(blockSummaries))
+	for blkNum, blockSummary := range blockSummaries {
+		block := &BlockInspection{
+			BlockNum:    uint16(blkNum),
+			LowTs:       blockSummary.LowTs,
+			HighTs:      blockSummary.HighTs,
+			RecordCount: blockSummary.RecCount,
+		}
+		if bmh, ok := allBmh[uint16(blkNum)]; ok {
+			for _, blkLen := range bmh.ColumnBlockLen {
+				block.CompressedBytes += uint64(blkLen)
+			}
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+func inspectColumn(segKey string, cname string, allBmh map[uint16]*structs.BlockMetadataHolder) (*ColumnInspection, error) {
+	colInspection := &ColumnInspection{
+		Name:      cname,
+		Encodings: make(map[string]uint16),
+	}
+
+	csgFname := getCsgFname(segKey, cname)
+	fd, err := os.OpenFile(csgFname, os.O_RDONLY, 0644)
+	if err != nil {
+		log.Errorf("inspectColumn: failed to open fname=%v, err=%v", csgFname, err)
+		return nil, err
+	}
+	defer fd.Close()
+
+	for blkNum, bmh := range allBmh {
+		blkLen, ok := bmh.ColumnBlockLen[cname]
+		if !ok || blkLen == 0 {
+			continue
+		}
+		blkOffset := bmh.ColumnBlockOffset[cname]
+		encoding, uncompressedLen, err := readBlockEncoding(fd, blkOffset, blkLen)
+		if err != nil {
+			log.Errorf("inspectColumn: failed to read block %v of fname=%v, err=%v", blkNum, csgFname, err)
+			return nil, err
+		}
+		colInspection.NumBlocks++
+		colInspection.Encodings[getEncodingName(encoding)]++
+		colInspection.CompressedBytes += uint64(blkLen)
+		colInspection.UncompressedBytes += uncompressedLen
+	}
+
+	cmiInfo, err := readMicroIndexInfo(getCmiFname(segKey, cname))
+	if err != nil {
+		return nil, err
+	}
+	colInspection.MicroIndexBytes = cmiInfo.size
+	colInspection.BloomIndexBlocks = cmiInfo.bloomBlocks
+	colInspection.RangeIndexBlocks = cmiInfo.rangeBlocks
+	colInspection.HasBloomIndex = cmiInfo.bloomBlocks > 0
+	colInspection.HasRangeIndex = cmiInfo.rangeBlocks > 0
+	return colInspection, nil
+}
+
+/*
+Returns the encoding of the block and the length of its values when uncompressed. The zstd blocks have their
+uncompressed length in the frame header, the other encodings are not compressed
+*/
+func readBlockEncoding(fd *os.File, blkOffset int64, blkLen uint32) (byte, uint64, error) {
+	headerLen := uint32(1 + zstd.HeaderMaxSize)
+	if headerLen > blkLen {
+		headerLen = blkLen
+	}
+	header := make([]byte, headerLen)
+	_, err := fd.ReadAt(header, blkOffset)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	encoding := header[0]
+	if encoding != utils.ZSTD_COMLUNAR_BLOCK[0] {
+		return encoding, uint64(blkLen - 1), nil
+	}
+	var frameHeader zstd.Header
+	err = frameHeader.Decode(header[1:])
+	if err == nil && frameHeader.HasFCS {
+		return encoding, frameHeader.FrameContentSize, nil
+	}
+
+	compressed := make([]byte, blkLen-1)
+	_, err = fd.ReadAt(compressed, blkOffset+1)
+	if err != nil {
+		return 0, 0, err
+	}
+	uncompressed, err := decoder.DecodeAll(compressed, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	return encoding, uint64(len(uncompressed)), nil
+}
+
+func getEncodingName(encoding byte) string {
+	switch encoding {
+	case utils.ZSTD_COMLUNAR_BLOCK[0]:
+		return "zstd"
+	case utils.ZSTD_DICTIONARY_BLOCK[0]:
+		return "dictionary"
+	case utils.TIMESTAMP_TOPDIFF_VARENC[0]:
+		return "timestampDiff"
+	default:
+		return fmt.Sprintf("unknown(%v)", encoding)
+	}
+}
+
+// Counts the blocks with a bloom or a range index in the cmi file, the columns without a cmi file have neither
+func readMicroIndexInfo(cmiFname string) (*microIndexInfo, error) {
+	cmiInfo := &microIndexInfo{}
+	fd, err := os.OpenFile(cmiFname, os.O_RDONLY, 0644)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cmiInfo, nil
+		}
+		log.Errorf("readMicroIndexInfo: failed to open fname=%v, err=%v", cmiFname, err)
+		return nil, err
+	}
+	defer fd.Close()
+
+	// every cmi is its len, the block number and the cmi type followed by the index
+	cmiHeader := make([]byte, utils.LEN_BLOCK_CMI_SIZE+utils.LEN_BLKNUM_CMI_SIZE+1)
+	offset := int64(0)
+	for {
+		_, err := fd.ReadAt(cmiHeader, offset)
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			log.Errorf("readMicroIndexInfo: failed to read the cmi at offset %v of fname=%v, err=%v", offset, cmiFname, err)
+			return nil, err
+		}
+		cmiLen := toputils.BytesToUint32LittleEndian(cmiHeader[:utils.LEN_BLOCK_CMI_SIZE])
+		switch cmiHeader[utils.LEN_BLOCK_CMI_SIZE+utils.LEN_BLKNUM_CMI_SIZE] {
+		case utils.CMI_BLOOM_INDEX[0]:
+			cmiInfo.bloomBlocks++
+		case utils.CMI_RANGE_INDEX[0]:
+			cmiInfo.rangeBlocks++
+		}
+		offset += int64(utils.LEN_BLOCK_CMI_SIZE) + int64(cmiLen)
+	}
+	cmiInfo.size = uint64(offset)
+	return cmiInfo, nil
+}
+
+func getColumnType(cname string, segStats *structs.SegStats, colInspection *ColumnInspection) string {
+	switch {
+	case cname == config.GetTimeStampKey():
+		return "timestamp"
+	case segStats != nil:
+		switch segStats.GetDtype() {
+		case utils.SS_DT_FLOAT:
+			return "float"
+		case utils.SS_DT_SIGNED_NUM:
+			return "integer"
+		default:
+			return "string"
+		}
+	case colInspection.RangeIndexBlocks > 0:
+		return "numeric"
+	case colInspection.BloomIndexBlocks > 0:
+		return "string"
+	default:
+		return "unknown"
+	}
+}
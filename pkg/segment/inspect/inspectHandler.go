@@ -0,0 +1,127 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inspect
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/siglens/siglens/pkg/apikeys"
+	"github.com/siglens/siglens/pkg/segment/structs"
+	"github.com/siglens/siglens/pkg/segment/writer"
+	"github.com/siglens/siglens/pkg/utils"
+	log "github.com/sirupsen/logrus"
+	"github.com/valyala/fasthttp"
+)
+
+// Number of segments inspected by an indexName request without a limit
+const DEFAULT_INSPECT_LIMIT = 100
+
+type SegmentInspectResponse struct {
+	Segments      []*SegmentInspection `json:"segments"`
+	TotalSegments int                  `json:"totalSegments"` // number of segments found, more than Segments if they were limited
+}
+
+func setErrMsg(ctx *fasthttp.RequestCtx, statusCode int, message string) {
+	var httpResp utils.HttpServerResponse
+	ctx.SetStatusCode(statusCode)
+	httpResp.Message = message
+	httpResp.StatusCode = statusCode
+	utils.WriteResponse(ctx, httpResp)
+}
+
+/*
+Handles /api/segments/inspect. Inspects the segment of the segmentKey query parameter, or the segments of the index
+of indexName with the largest first, up to limit of them. withBlocks=true also returns the time range and size of
+every block
+*/
+func ProcessSegmentInspectRequest(ctx *fasthttp.RequestCtx, myid uint64) {
+	segKey := string(ctx.QueryArgs().Peek("segmentKey"))
+	indexName := string(ctx.QueryArgs().Peek("indexName"))
+	withBlocks := string(ctx.QueryArgs().Peek("withBlocks")) == "true"
+	if segKey == "" && indexName == "" {
+		setErrMsg(ctx, fasthttp.StatusBadRequest, "segmentKey or indexName is required")
+		return
+	}
+	limit := DEFAULT_INSPECT_LIMIT
+	if ctx.QueryArgs().Has("limit") {
+		var err error
+		limit, err = ctx.QueryArgs().GetUint("limit")
+		if err != nil || limit == 0 {
+			setErrMsg(ctx, fasthttp.StatusBadRequest, "limit should be a positive number")
+			return
+		}
+	}
+
+	segMetas := FindSegments(writer.ReadAllSegmetas(), segKey, indexName, myid)
+	indexFilter := apikeys.GetIndexFilter(ctx, apikeys.ACCESS_READ)
+	if indexFilter != nil {
+		allowedSegMetas := make([]*structs.SegMeta, 0, len(segMetas))
+		for _, segMeta := range segMetas {
+			if indexFilter(segMeta.VirtualTableName) {
+				allowedSegMetas = append(allowedSegMetas, segMeta)
+			}
+		}
+		segMetas = allowedSegMetas
+	}
+	if len(segMetas) == 0 {
+		setErrMsg(ctx, fasthttp.StatusNotFound, "No rotated segment found")
+		return
+	}
+
+	httpResp := &SegmentInspectResponse{TotalSegments: len(segMetas)}
+	if len(segMetas) > limit {
+		segMetas = segMetas[:limit]
+	}
+	httpResp.Segments = make([]*SegmentInspection, 0, len(segMetas))
+	for _, segMeta := range segMetas {
+		inspection, err := InspectSegment(segMeta, withBlocks)
+		if err != nil {
+			log.Errorf("ProcessSegmentInspectRequest: failed to inspect segkey=%v, err=%v", segMeta.SegmentKey, err)
+			setErrMsg(ctx, fasthttp.StatusInternalServerError,
+				fmt.Sprintf("Failed to inspect segment %v: %v", segMeta.SegmentKey, err))
+			return
+		}
+		httpResp.Segments = append(httpResp.Segments, inspection)
+	}
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	utils.WriteJsonResponse(ctx, httpResp)
+}
+
+// Returns the segment with segKey, or all the segments of indexName sorted by their size on disk, of the orgid
+func FindSegments(allSegMetas []*structs.SegMeta, segKey string, indexName string, orgid uint64) []*structs.SegMeta {
+	segMetas := make([]*structs.SegMeta, 0)
+	for _, segMeta := range allSegMetas {
+		if segMeta.OrgId != orgid {
+			continue
+		}
+		if segKey != "" && segMeta.SegmentKey != segKey {
+			continue
+		}
+		if indexName != "" && segMeta.VirtualTableName != indexName {
+			continue
+		}
+		segMetas = append(segMetas, segMeta)
+	}
+	sort.Slice(segMetas, func(i, j int) bool {
+		if segMetas[i].OnDiskBytes != segMetas[j].OnDiskBytes {
+			return segMetas[i].OnDiskBytes > segMetas[j].OnDiskBytes
+		}
+		return segMetas[i].SegmentKey < segMetas[j].SegmentKey
+	})
+	return segMetas
+}
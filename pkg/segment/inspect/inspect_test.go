@@ -0,0 +1,136 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inspect
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/siglens/siglens/pkg/config"
+	"github.com/siglens/siglens/pkg/segment/structs"
+	"github.com/siglens/siglens/pkg/segment/utils"
+	"github.com/siglens/siglens/pkg/segment/writer"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func Test_InspectSegment(t *testing.T) {
+	config.InitializeDefaultConfig()
+	segKey := filepath.Join(t.TempDir(), "0")
+	numBlocks := 3
+	_, blockSummaries, _, _, allBmh, allColsSizes := writer.WriteMockColSegFile(segKey, numBlocks, 20)
+	writer.WriteMockBlockSummary(structs.GetBsuFnameFromSegKey(segKey), blockSummaries, allBmh)
+
+	// a range index for every block of key2
+	fd, err := os.Create(getCmiFname(segKey, "key2"))
+	assert.Nil(t, err)
+	for blkNum := 0; blkNum < numBlocks; blkNum++ {
+		ranges := map[string]*structs.Numbers{
+			"key2": {Min_uint64: 0, Max_uint64: 19, NumType: utils.RNT_UNSIGNED_INT},
+		}
+		packedLen, blkRIBuf, err := writer.EncodeRIBlock(ranges, uint16(blkNum))
+		assert.Nil(t, err)
+		_, err = fd.Write(blkRIBuf[:packedLen])
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, fd.Close())
+
+	segMeta := &structs.SegMeta{
+		SegmentKey:       segKey,
+		VirtualTableName: "test",
+		RecordCount:      numBlocks * 20,
+		NumBlocks:        uint16(numBlocks),
+		ColumnNames:      allColsSizes,
+		AllPQIDs:         map[string]bool{"pqid-2": true, "pqid-1": true},
+	}
+	inspection, err := InspectSegment(segMeta, true)
+	assert.Nil(t, err)
+	assert.Equal(t, "test", inspection.IndexName)
+	assert.Equal(t, []string{"pqid-1", "pqid-2"}, inspection.PQIDs)
+	assert.False(t, inspection.HasRollups)
+	assert.Len(t, inspection.Columns, 13, "the 12 mock columns and the timestamp")
+	for i := 1; i < len(inspection.Columns); i++ {
+		assert.GreaterOrEqual(t, inspection.Columns[i-1].CompressedBytes, inspection.Columns[i].CompressedBytes)
+	}
+
+	columns := make(map[string]*ColumnInspection)
+	for _, colInspection := range inspection.Columns {
+		columns[colInspection.Name] = colInspection
+	}
+	tsColumn, ok := columns[config.GetTimeStampKey()]
+	assert.True(t, ok)
+	assert.Equal(t, "timestamp", tsColumn.Type)
+	assert.Equal(t, map[string]uint16{"timestampDiff": uint16(numBlocks)}, tsColumn.Encodings)
+	assert.False(t, tsColumn.HasBloomIndex || tsColumn.HasRangeIndex)
+
+	key0 := columns["key0"]
+	assert.Equal(t, "unknown", key0.Type, "there are no stats or micro indices for the column")
+	assert.Equal(t, map[string]uint16{"zstd": uint16(numBlocks)}, key0.Encodings)
+	assert.Equal(t, uint16(numBlocks), key0.NumBlocks)
+	assert.Greater(t, key0.UncompressedBytes, key0.CompressedBytes, "the repeated values compress")
+
+	key2 := columns["key2"]
+	assert.Equal(t, "numeric", key2.Type)
+	assert.True(t, key2.HasRangeIndex)
+	assert.False(t, key2.HasBloomIndex)
+	assert.Equal(t, uint16(numBlocks), key2.RangeIndexBlocks)
+	cmiInfo, err := os.Stat(getCmiFname(segKey, "key2"))
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(cmiInfo.Size()), key2.MicroIndexBytes)
+
+	assert.Len(t, inspection.Blocks, numBlocks)
+	blocksBytes := uint64(0)
+	for blkNum, block := range inspection.Blocks {
+		assert.Equal(t, uint16(blkNum), block.BlockNum)
+		assert.Equal(t, uint16(20), block.RecordCount)
+		blocksBytes += block.CompressedBytes
+	}
+	assert.Equal(t, inspection.CompressedBytes, blocksBytes)
+
+	_, err = InspectSegment(&structs.SegMeta{SegmentKey: segKey + "-missing"}, false)
+	assert.NotNil(t, err)
+}
+
+func Test_FindSegments(t *testing.T) {
+	allSegMetas := []*structs.SegMeta{
+		{SegmentKey: "a/1", VirtualTableName: "a", OnDiskBytes: 10},
+		{SegmentKey: "a/2", VirtualTableName: "a", OnDiskBytes: 30},
+		{SegmentKey: "b/1", VirtualTableName: "b", OnDiskBytes: 20},
+		{SegmentKey: "a/3", VirtualTableName: "a", OnDiskBytes: 50, OrgId: 1},
+	}
+
+	segMetas := FindSegments(allSegMetas, "", "a", 0)
+	assert.Len(t, segMetas, 2)
+	assert.Equal(t, "a/2", segMetas[0].SegmentKey, "the largest segment is first")
+	assert.Equal(t, "a/1", segMetas[1].SegmentKey)
+
+	segMetas = FindSegments(allSegMetas, "b/1", "", 0)
+	assert.Len(t, segMetas, 1)
+	assert.Len(t, FindSegments(allSegMetas, "b/1", "a", 0), 0)
+	assert.Len(t, FindSegments(allSegMetas, "a/3", "", 0), 0, "the segments of other orgs are not returned")
+	assert.Len(t, FindSegments(allSegMetas, "", "", 0), 3)
+}
+
+func Test_ProcessSegmentInspectRequest_badLimit(t *testing.T) {
+	for _, limit := range []string{"0", "-1", "abc"} {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI("/api/segments/inspect?indexName=test&limit=" + limit)
+		ProcessSegmentInspectRequest(ctx, 0)
+		assert.Equal(t, fasthttp.StatusBadRequest, ctx.Response.StatusCode(), limit)
+	}
+}
@@ -468,6 +468,16 @@ func GetNumOfSearchedRecordsRotated(segKey string) uint64 {
 	return uint64(smi.RecordCount)
 }
 
+func GetOnDiskBytesRotated(segKey string) uint64 {
+	globalMetadata.updateLock.RLock()
+	smi, segKeyOk := globalMetadata.segmentMetadataReverseIndex[segKey]
+	globalMetadata.updateLock.RUnlock()
+	if !segKeyOk {
+		return 0
+	}
+	return smi.OnDiskBytes
+}
+
 // returns the time range of the blocks in the segment that do not exist in spqmr
 // if the timeRange is nil, no blocks were found in metadata that do not exist in spqmr
 func GetTSRangeForMissingBlocks(segKey string, tRange *dtu.TimeRange, spqmr *pqmr.SegmentPQMRResults) *dtu.TimeRange {
@@ -594,6 +604,20 @@ func DoesColumnExistForTable(cName string, indices []string) bool {
 	return false
 }
 
+// Returns the keys of the rotated segments of the table
+func GetSegKeysForTable(tableName string, orgid uint64) []string {
+	globalMetadata.updateLock.RLock()
+	defer globalMetadata.updateLock.RUnlock()
+
+	segKeys := make([]string, 0)
+	for _, smi := range globalMetadata.tableSortedMetadata[tableName] {
+		if smi.OrgId == orgid {
+			segKeys = append(segKeys, smi.SegmentKey)
+		}
+	}
+	return segKeys
+}
+
 func GetAllColNames(indices []string) []string {
 	globalMetadata.updateLock.RLock()
 	defer globalMetadata.updateLock.RUnlock()
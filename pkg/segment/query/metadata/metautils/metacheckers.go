@@ -58,28 +58,22 @@ func CheckRangeIndex(filterCol map[string]string, allRangeEntries map[string]*st
 func checkRangeIndexHelper(ri *structs.Numbers, colVal string, operator utils.FilterOperator, qid uint64) bool {
 	var valueInRangeIndex bool
 	if operator == utils.Equals || operator == utils.NotEquals || operator == utils.LessThan || operator == utils.LessThanOrEqualTo || operator == utils.GreaterThan || operator == utils.GreaterThanOrEqualTo {
+		// a literal that does not fit the type of the range, like 2.5 or -1 for unsigned ints, is compared as a float
 		switch ri.NumType {
 		case utils.RNT_UNSIGNED_INT:
 			convertedVal, err := dtu.ConvertToUInt(colVal, 64)
 			if err != nil {
-				log.Errorf("qid=%d checkRangeIndexHelper: Got an invalid literal for range filter: %s", qid, err)
-				return false
+				return checkFloatRangeIndex(colVal, operator, float64(ri.Min_uint64), float64(ri.Max_uint64), qid)
 			}
 			valueInRangeIndex = doesUintPassRangeFilter(operator, convertedVal, ri.Min_uint64, ri.Max_uint64)
 		case utils.RNT_SIGNED_INT:
 			convertedVal, err := dtu.ConvertToInt(colVal, 64)
 			if err != nil {
-				log.Errorf("qid=%d checkRangeIndexHelper: Got an invalid literal for range filter: %s", qid, err)
-				return false
+				return checkFloatRangeIndex(colVal, operator, float64(ri.Min_int64), float64(ri.Max_int64), qid)
 			}
 			valueInRangeIndex = doesIntPassRangeFilter(operator, convertedVal, ri.Min_int64, ri.Max_int64)
 		case utils.RNT_FLOAT64:
-			convertedVal, err := dtu.ConvertToFloat(colVal, 64)
-			if err != nil {
-				log.Errorf("qid=%d checkRangeIndexHelper: Got an invalid literal for range filter: %s", qid, err)
-				return false
-			}
-			valueInRangeIndex = doesFloatPassRangeFilter(operator, convertedVal, ri.Min_float64, ri.Max_float64)
+			valueInRangeIndex = checkFloatRangeIndex(colVal, operator, ri.Min_float64, ri.Max_float64, qid)
 		default:
 			log.Errorf("qid=%d checkRangeIndexHelper: Got an invalid range index type: %d", qid, ri.NumType)
 		}
@@ -87,6 +81,15 @@ func checkRangeIndexHelper(ri *structs.Numbers, colVal string, operator utils.Fi
 	return valueInRangeIndex
 }
 
+func checkFloatRangeIndex(colVal string, operator utils.FilterOperator, minVal float64, maxVal float64, qid uint64) bool {
+	convertedVal, err := dtu.ConvertToFloat(colVal, 64)
+	if err != nil {
+		log.Errorf("qid=%d checkRangeIndexHelper: Got an invalid literal for range filter: %s", qid, err)
+		return false
+	}
+	return doesFloatPassRangeFilter(operator, convertedVal, minVal, maxVal)
+}
+
 func FilterBlocksByTime(bSum []*structs.BlockSummary, blkTracker *structs.BlockTracker,
 	timeRange *dtu.TimeRange) map[uint16]map[string]bool {
 
@@ -105,10 +108,8 @@ func doesUintPassRangeFilter(op utils.FilterOperator, lookupValue uint64, minVal
 	case utils.Equals:
 		return lookupValue >= minVal && lookupValue <= maxVal
 	case utils.NotEquals:
-		if lookupValue == minVal || lookupValue == maxVal {
-			return false
-		}
-		return true
+		// only a block whose values all equal the literal has no match
+		return lookupValue != minVal || lookupValue != maxVal
 	case utils.GreaterThan:
 		return lookupValue < minVal || lookupValue < maxVal
 	case utils.GreaterThanOrEqualTo:
@@ -127,10 +128,7 @@ func doesIntPassRangeFilter(op utils.FilterOperator, lookupValue int64, minVal i
 	case utils.Equals:
 		return lookupValue >= minVal && lookupValue <= maxVal
 	case utils.NotEquals:
-		if lookupValue == minVal || lookupValue == maxVal {
-			return false
-		}
-		return true
+		return lookupValue != minVal || lookupValue != maxVal
 	case utils.GreaterThan:
 		return lookupValue < minVal || lookupValue < maxVal
 	case utils.GreaterThanOrEqualTo:
@@ -149,10 +147,7 @@ func doesFloatPassRangeFilter(op utils.FilterOperator, lookupValue float64, minV
 	case utils.Equals:
 		return lookupValue >= minVal && lookupValue <= maxVal
 	case utils.NotEquals:
-		if lookupValue == minVal || lookupValue == maxVal {
-			return false
-		}
-		return true
+		return lookupValue != minVal || lookupValue != maxVal
 	case utils.GreaterThan:
 		return lookupValue < minVal || lookupValue < maxVal
 	case utils.GreaterThanOrEqualTo:
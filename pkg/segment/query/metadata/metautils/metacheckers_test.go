@@ -54,3 +54,37 @@ func Test_CheckRangeIndex(t *testing.T) {
 	pass = CheckRangeIndex(filter, testRange, utils.GreaterThan, 1)
 	assert.True(t, pass)
 }
+
+func Test_CheckRangeIndexLiteralTypes(t *testing.T) {
+	testRange := make(map[string]*structs.Numbers)
+	testRange["latency"] = &structs.Numbers{
+		Min_uint64: 10,
+		Max_uint64: 20,
+		NumType:    utils.RNT_UNSIGNED_INT,
+	}
+	testRange["delta"] = &structs.Numbers{
+		Min_int64: -20,
+		Max_int64: -10,
+		NumType:   utils.RNT_SIGNED_INT,
+	}
+
+	// literals that are not unsigned ints are compared as floats
+	assert.True(t, CheckRangeIndex(map[string]string{"latency": "19.5"}, testRange, utils.GreaterThan, 1))
+	assert.False(t, CheckRangeIndex(map[string]string{"latency": "20.5"}, testRange, utils.GreaterThan, 1))
+	assert.True(t, CheckRangeIndex(map[string]string{"latency": "-5"}, testRange, utils.GreaterThan, 1))
+	assert.False(t, CheckRangeIndex(map[string]string{"latency": "-5"}, testRange, utils.LessThan, 1))
+	assert.True(t, CheckRangeIndex(map[string]string{"delta": "-10.5"}, testRange, utils.GreaterThanOrEqualTo, 1))
+	assert.False(t, CheckRangeIndex(map[string]string{"delta": "-9.5"}, testRange, utils.GreaterThanOrEqualTo, 1))
+	assert.False(t, CheckRangeIndex(map[string]string{"latency": "abc"}, testRange, utils.GreaterThan, 1))
+
+	// a block is only skipped for != if all of its values equal the literal
+	assert.True(t, CheckRangeIndex(map[string]string{"latency": "10"}, testRange, utils.NotEquals, 1))
+	assert.True(t, CheckRangeIndex(map[string]string{"latency": "20"}, testRange, utils.NotEquals, 1))
+	testRange["status"] = &structs.Numbers{
+		Min_uint64: 500,
+		Max_uint64: 500,
+		NumType:    utils.RNT_UNSIGNED_INT,
+	}
+	assert.False(t, CheckRangeIndex(map[string]string{"status": "500"}, testRange, utils.NotEquals, 1))
+	assert.True(t, CheckRangeIndex(map[string]string{"status": "404"}, testRange, utils.NotEquals, 1))
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/siglens/siglens/pkg/segment/structs"
 	"github.com/siglens/siglens/pkg/segment/utils"
 	"github.com/siglens/siglens/pkg/segment/writer/metrics"
+	"github.com/siglens/siglens/pkg/segment/writer/metrics/rollup"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -56,6 +57,7 @@ func ApplyMetricsQuery(mQuery *structs.MetricsQuery, timeRange *dtu.MetricsTimeR
 	}
 
 	mSegments = mergeRotatedAndUnrotatedRequests(unrotatedMSegments, mSegments)
+	setRollupResolutions(mQuery, mSegments)
 	allTagKeys := make(map[string]bool)
 
 	for _, allMSearchReqs := range mSegments {
@@ -124,6 +126,19 @@ func mergeRotatedAndUnrotatedRequests(unrotatedMSegments map[string][]*structs.M
 	return mSegments
 }
 
+// Makes the rotated segments that have a rollup matching the downsample interval of the query search the rollup
+func setRollupResolutions(mQuery *structs.MetricsQuery, mSegments map[string][]*structs.MetricsSearchRequest) {
+	dsIntervalSecs := mQuery.Downsampler.GetIntervalTimeInSeconds()
+	for _, allMSearchReqs := range mSegments {
+		for _, mSeg := range allMSearchReqs {
+			if mSeg.QueryType != structs.METRICS_SEARCH {
+				continue
+			}
+			mSeg.RollupResolution = rollup.GetSearchResolution(mSeg.MetricsKeyBaseDir, dsIntervalSecs)
+		}
+	}
+}
+
 func applyMetricsOperatorOnSegments(mQuery *structs.MetricsQuery, allSearchReqests map[string][]*structs.MetricsSearchRequest,
 	mRes *mresults.MetricsResult, timeRange *dtu.MetricsTimeRange, qid uint64, querySummary *summary.QuerySummary) {
 	// for each metrics segment, apply a single metrics segment search
@@ -344,6 +344,11 @@ func updateLastModifiedTimeForMetaFile(metaFilename string, newTime uint64) {
 	metaFileLastModified[metaFilename] = newTime
 }
 
+// Adds a rotated segment that is in the local segmeta to the metadata of the queries, before the segmeta is reread
+func AddRotatedSegmentMetadata(segMetaInfo *structs.SegMeta) {
+	metadata.BulkAddSegmentMicroIndex([]*metadata.SegmentMicroIndex{processSegmetaInfo(segMetaInfo)})
+}
+
 func processSegmetaInfo(segMetaInfo *structs.SegMeta) *metadata.SegmentMicroIndex {
 	for pqid := range segMetaInfo.AllPQIDs {
 		pqs.AddPersistentQueryResult(segMetaInfo.SegmentKey, segMetaInfo.VirtualTableName, pqid)
@@ -44,15 +44,17 @@ const (
 )
 
 type QueryUpdate struct {
-	QUpdate   QueryUpdateType
-	SegKeyEnc uint16
-	RemoteID  string
+	QUpdate     QueryUpdateType
+	SegKeyEnc   uint16
+	RemoteID    string
+	PulledBucks bool // the group by buckets were merged for this update, so they can be sent as partial results
 }
 
 type QueryStateChanData struct {
 	StateName       QueryState
 	QueryUpdate     *QueryUpdate
 	PercentComplete float64
+	Error           error // set for the ERROR state
 }
 
 const (
@@ -110,6 +112,10 @@ func (rQuery *RunningQueryState) SendQueryStateComplete() {
 	rQuery.StateChan <- &QueryStateChanData{StateName: COMPLETE}
 }
 
+func (rQuery *RunningQueryState) SendQueryStateError(err error) {
+	rQuery.StateChan <- &QueryStateChanData{StateName: ERROR, Error: err}
+}
+
 // Starts tracking the query state. If async is true, the RunningQueryState.StateChan will be defined & will be sent updates
 // If async, updates will be sent for any update to RunningQueryState. Caller is responsible to call DeleteQuery
 func StartQuery(qid uint64, async bool) (*RunningQueryState, error) {
@@ -213,8 +219,9 @@ func incrementNumFinishedSegments(incr int, qid uint64, recsSearched uint64,
 	if rQuery.isAsync {
 		rQuery.StateChan <- &QueryStateChanData{StateName: QUERY_UPDATE,
 			QueryUpdate: &QueryUpdate{
-				QUpdate:   QUERY_UPDATE_LOCAL,
-				SegKeyEnc: skEnc,
+				QUpdate:     QUERY_UPDATE_LOCAL,
+				SegKeyEnc:   skEnc,
+				PulledBucks: doBuckPull,
 			},
 			PercentComplete: perComp}
 	}
@@ -252,6 +259,21 @@ func setQidAsFinished(qid uint64) {
 	}
 }
 
+// Async queries send the error that stopped them as the ERROR state
+func setQidAsErrored(qid uint64, err error) {
+	arqMapLock.RLock()
+	rQuery, ok := allRunningQueries[qid]
+	arqMapLock.RUnlock()
+	if !ok {
+		log.Errorf("setQidAsErrored: qid %+v does not exist!", qid)
+		return
+	}
+
+	if rQuery.isAsync {
+		rQuery.StateChan <- &QueryStateChanData{StateName: ERROR, Error: err}
+	}
+}
+
 func CancelQuery(qid uint64) {
 	arqMapLock.RLock()
 	rQuery, ok := allRunningQueries[qid]
@@ -376,6 +398,17 @@ func toFixed(num float64, precision int) float64 {
 	return float64(round(num*output)) / output
 }
 
+// Only the async queries send updates while they run, so only they need partial results
+func isAsyncQuery(qid uint64) bool {
+	arqMapLock.RLock()
+	rQuery, ok := allRunningQueries[qid]
+	arqMapLock.RUnlock()
+	if !ok {
+		return false
+	}
+	return rQuery.IsAsync()
+}
+
 func checkForCancelledQuery(qid uint64) (bool, error) {
 	arqMapLock.RLock()
 	rQuery, ok := allRunningQueries[qid]
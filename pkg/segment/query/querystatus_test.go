@@ -0,0 +1,53 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_partialBucketUpdates(t *testing.T) {
+	qid := uint64(8801)
+	rQuery, err := StartQuery(qid, true)
+	assert.Nil(t, err)
+	defer DeleteQuery(qid)
+	assert.True(t, isAsyncQuery(qid))
+	assert.Equal(t, RUNNING, (<-rQuery.StateChan).StateName)
+
+	err = setTotalSegmentsToSearch(qid, 4)
+	assert.Nil(t, err)
+
+	incrementNumFinishedSegments(1, qid, 10, 0, false, nil)
+	update := <-rQuery.StateChan
+	assert.Equal(t, QUERY_UPDATE, update.StateName)
+	assert.False(t, update.QueryUpdate.PulledBucks)
+	assert.Equal(t, float64(25), update.PercentComplete)
+
+	incrementNumFinishedSegments(1, qid, 10, 0, true, nil)
+	update = <-rQuery.StateChan
+	assert.True(t, update.QueryUpdate.PulledBucks)
+	assert.Equal(t, float64(50), update.PercentComplete)
+
+	syncQid := uint64(8802)
+	_, err = StartQuery(syncQid, false)
+	assert.Nil(t, err)
+	defer DeleteQuery(syncQid)
+	assert.False(t, isAsyncQuery(syncQid))
+	assert.False(t, isAsyncQuery(8803))
+}
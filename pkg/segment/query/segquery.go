@@ -37,7 +37,9 @@ import (
 	"github.com/siglens/siglens/pkg/segment/search"
 	"github.com/siglens/siglens/pkg/segment/structs"
 	segutils "github.com/siglens/siglens/pkg/segment/utils"
+	"github.com/siglens/siglens/pkg/segment/workerpool"
 	"github.com/siglens/siglens/pkg/segment/writer"
+	"github.com/siglens/siglens/pkg/usageStats"
 	"github.com/siglens/siglens/pkg/utils"
 	log "github.com/sirupsen/logrus"
 )
@@ -123,7 +125,8 @@ func ApplyFilterOperator(node *structs.ASTNode, timeRange *dtu.TimeRange, aggs *
 		containsKibana = true
 	}
 	querytracker.UpdateQTUsage(nonKibanaIndices, searchNode, aggs)
-	parallelismPerFile := int64(1)
+	usageStats.UpdateIndexQueryStats(nonKibanaIndices, qc.Orgid)
+	parallelismPerFile := workerpool.GetWorkersPerSegment(qc.Parallelism)
 	_, qType := getQueryType(searchNode, aggs)
 	querySummary := summary.InitQuerySummary(summary.LOGS, qid)
 	pqid := querytracker.GetHashForQuery(searchNode)
@@ -204,7 +207,7 @@ func ApplyUnrotatedQuery(sNode *structs.SearchNode, timeRange *dtu.TimeRange, ag
 		}
 	}
 	querySummary := summary.InitQuerySummary(summary.LOGS, qid)
-	parallelismPerFile := int64(1)
+	parallelismPerFile := workerpool.GetWorkersPerSegment(0)
 	queryInfo, err := InitQueryInformation(sNode, aggs, timeRange, indexInfo,
 		sizeLimit, parallelismPerFile, qid, nil, orgid)
 	defer querySummary.LogSummaryAndEmitMetrics(qid, queryInfo.pqid, false, orgid)
@@ -257,7 +260,7 @@ func ApplyRotatedQuery(reqs []*grpc_query.SegkeyRequest, sNode *structs.SearchNo
 		}
 	}
 	querySummary := summary.InitQuerySummary(summary.LOGS, qid)
-	parallelismPerFile := int64(1)
+	parallelismPerFile := workerpool.GetWorkersPerSegment(0)
 	queryInfo, err := InitQueryInformation(sNode, aggs, timeRange, indexInfo,
 		sizeLimit, parallelismPerFile, qid, nil, orgid)
 	defer querySummary.LogSummaryAndEmitMetrics(qid, queryInfo.pqid, false, orgid)
@@ -365,6 +368,12 @@ func getNodeResultsForRRCCmd(queryInfo *queryInformation, sTime time.Time, allSe
 	}
 	log.Infof("qid=%d, Received %+v query segment requests. %+v raw search %+v pqs and %+v distribued query elapsed time: %+v",
 		queryInfo.qid, len(sortedQSRSlice), numRawSearch, numPQS, distributedQueries, time.Since(sTime))
+	err = checkScannedBytesQuota(queryInfo.qid, sortedQSRSlice, orgid)
+	if err != nil {
+		return &structs.NodeResult{
+			ErrList: []error{err},
+		}
+	}
 	err = setTotalSegmentsToSearch(queryInfo.qid, numRawSearch+numPQS+distributedQueries)
 	if err != nil {
 		log.Errorf("qid=%d Failed to set total segments to search! Error: %+v", queryInfo.qid, err)
@@ -377,7 +386,13 @@ func getNodeResultsForSegmentStatsCmd(queryInfo *queryInformation, sTime time.Ti
 	reqs []*grpc_query.SegkeyRequest, querySummary *summary.QuerySummary, unrotatedOnly bool, orgid uint64) *structs.NodeResult {
 	sortedQSRSlice, numRawSearch, numDistributed := getAllSegmentsInAggs(queryInfo, reqs, queryInfo.aggs, queryInfo.queryRange, queryInfo.indexInfo.GetQueryTables(),
 		queryInfo.qid, unrotatedOnly, sTime, orgid)
-	err := setTotalSegmentsToSearch(queryInfo.qid, numRawSearch)
+	err := checkScannedBytesQuota(queryInfo.qid, sortedQSRSlice, orgid)
+	if err != nil {
+		return &structs.NodeResult{
+			ErrList: []error{err},
+		}
+	}
+	err = setTotalSegmentsToSearch(queryInfo.qid, numRawSearch)
 	if err != nil {
 		log.Errorf("qid=%d Failed to set total segments to search! Error: %+v", queryInfo.qid, err)
 	}
@@ -411,6 +426,34 @@ func getNodeResultsForSegmentStatsCmd(queryInfo *queryInformation, sTime time.Ti
 	}
 }
 
+// Rejects the query before any segment is searched if it is over the scanned bytes quota of the org
+func checkScannedBytesQuota(qid uint64, qsrs []*querySegmentRequest, orgid uint64) error {
+	err := usageStats.CheckScannedBytesQuota(getScannedBytes(qsrs), orgid)
+	if err != nil {
+		log.Infof("qid=%d, checkScannedBytesQuota: rejecting the query, %v", qid, err)
+		setQidAsErrored(qid, err)
+		return err
+	}
+	return nil
+}
+
+// Returns the on disk size of the segments the query reads, pqs segments are not counted as only their matches are read
+func getScannedBytes(qsrs []*querySegmentRequest) uint64 {
+	scannedBytes := uint64(0)
+	unrotatedSegKeys := make(map[string]bool)
+	for _, qsr := range qsrs {
+		switch qsr.sType {
+		case structs.PQS, structs.UNROTATED_PQS:
+			continue
+		case structs.UNROTATED_RAW_SEARCH, structs.UNROTATED_SEGMENT_STATS_SEARCH:
+			unrotatedSegKeys[qsr.segKey] = true
+		default:
+			scannedBytes += metadata.GetOnDiskBytesRotated(qsr.segKey)
+		}
+	}
+	return scannedBytes + writer.GetUnrotatedOnDiskBytes(unrotatedSegKeys)
+}
+
 func getSortedQSRResult(aggs *structs.QueryAggregators, allQSRs []*querySegmentRequest) []*querySegmentRequest {
 	if aggs != nil && aggs.Sort != nil {
 		if aggs.Sort.Ascending {
@@ -467,6 +510,13 @@ func applyFopAllRequests(sortedQSRSlice []*querySegmentRequest, queryInfo *query
 		agileTreeBuf = make([]byte, 300_000_000)
 	}
 
+	// For the group by queries from the UI, the buckets are merged and sent as partial results every few segments
+	partialResultsSegments := 0
+	if queryInfo.qType == structs.GroupByCmd && isAsyncQuery(queryInfo.qid) {
+		partialResultsSegments = int(config.GetPartialResultsSegments())
+	}
+	bucksNotPulled := false
+
 	doBuckPull := false
 	rrcsCompleted := false
 	segsNotSent := int(0)
@@ -556,12 +606,19 @@ func applyFopAllRequests(sortedQSRSlice []*querySegmentRequest, queryInfo *query
 		}
 		recsSearchedSinceLastUpdate += recsSearched
 		if segReq.HasMatchedRrc {
+			bucksNotPulled = true
+		}
+		pullPartialBucks := partialResultsSegments > 0 && bucksNotPulled && (idx+1)%partialResultsSegments == 0
+		if segReq.HasMatchedRrc || pullPartialBucks {
 			segsNotSent++
 			segenc := allSegFileResults.SegKeyToEnc[segReq.segKey]
 			incrementNumFinishedSegments(segsNotSent, queryInfo.qid, recsSearchedSinceLastUpdate, segenc,
-				doBuckPull, nil)
+				doBuckPull || pullPartialBucks, nil)
 			segsNotSent = 0
 			recsSearchedSinceLastUpdate = 0
+			if doBuckPull || pullPartialBucks {
+				bucksNotPulled = false
+			}
 		} else {
 			segsNotSent++
 		}
@@ -732,7 +789,7 @@ func applyAggOpOnSegments(sortedQSRSlice []*querySegmentRequest, allSegFileResul
 		if isCancelled {
 			break
 		}
-		isSegmentFullyEnclosed := segReq.segKeyTsRange.AreTimesFullyEnclosed(segReq.segKeyTsRange.StartEpochMs, segReq.segKeyTsRange.EndEpochMs)
+		isSegmentFullyEnclosed := segReq.queryRange.AreTimesFullyEnclosed(segReq.segKeyTsRange.StartEpochMs, segReq.segKeyTsRange.EndEpochMs)
 
 		// Because segment only store statistical data such as min, max..., for some functions we should recompute raw data to get the results
 		// If agg has evaluation functions, we should recompute raw data instead of using the previously stored statistical data in the segment
@@ -768,7 +825,7 @@ func applyAggOpOnSegments(sortedQSRSlice []*querySegmentRequest, allSegFileResul
 
 			// rawSearchSSR should be of size 1 or 0
 			for _, req := range rawSearchSSR {
-				sstMap, err = search.RawComputeSegmentStats(req, segReq.parallelismPerFile, segReq.sNode, segReq.segKeyTsRange, segReq.aggs.MeasureOperations, allSegFileResults, qid, qs)
+				sstMap, err = search.RawComputeSegmentStats(req, segReq.parallelismPerFile, segReq.sNode, segReq.queryRange, segReq.aggs.MeasureOperations, allSegFileResults, qid, qs)
 				if err != nil {
 					log.Errorf("qid=%d,  applyAggOpOnSegments : ReadSegStats: Failed to get segment level stats for segKey %+v! Error: %v", qid, segReq.segKey, err)
 					allSegFileResults.AddError(err)
@@ -330,6 +330,17 @@ func (qs *QuerySummary) getTotalTime(searchType SearchTypeEnum) time.Duration {
 	return qs.allQuerySummaries[searchType].totalTime
 }
 
+// Label of the query type in the query duration histogram, the logs queries that make buckets are aggregations
+func (qs *QuerySummary) getQueryTypeLabel() string {
+	if qs.queryType == METRICS {
+		return "metrics"
+	}
+	if qs.getNumBuckets() > 0 {
+		return "aggregation"
+	}
+	return "search"
+}
+
 func (qs *QuerySummary) getQueryTotalTime() time.Duration {
 	qs.updateLock.Lock()
 	defer qs.updateLock.Unlock()
@@ -518,6 +529,7 @@ func (qs *QuerySummary) LogSummaryAndEmitMetrics(qid uint64, pqid string, contai
 
 	if !containsKibana {
 		instrumentation.SetQueryLatencyMs(int64(qs.getQueryTotalTime()/1_000_000), "pqid", pqid)
+		instrumentation.RecordInt64HistogramWithLabel(instrumentation.QUERY_DURATION_MS, qs.getQueryTotalTime().Milliseconds(), "type", qs.getQueryTypeLabel())
 		instrumentation.SetEventsSearchedGauge(int64(qs.getTotNumRecordsSearched()))
 		instrumentation.SetEventsMatchedGauge(int64(qs.getTotNumRecordsMatched()))
 	}
@@ -593,6 +605,7 @@ func (qs *QuerySummary) LogSummaryAndEmitMetrics(qid uint64, pqid string, contai
 }
 
 func (qs *QuerySummary) LogMetricsQuerySummary(orgid uint64) {
+	instrumentation.RecordInt64HistogramWithLabel(instrumentation.QUERY_DURATION_MS, time.Since(qs.startTime).Milliseconds(), "type", qs.getQueryTypeLabel())
 	log.Warnf("qid=%d, MetricsQuerySummary: Finished in %+vms time. Searched a total of %+v TSIDs. Total number of series searched=%+v. Returned number of series=%+v",
 		qs.qid, time.Since(qs.startTime).Milliseconds(), qs.getNumTSIDsMatched(), qs.getNumSeriesSearched(), qs.getNumResultSeries())
 	log.Warnf("qid=%d, MetricsQuerySummary: Time taken to get rotated search requests=%+vms. Time taken to get unrotated search requests=%+vms. Total number of metrics segments searched=%+v.",
@@ -220,6 +220,17 @@ func (tsbr *TimeSeriesBlockReader) GetTimeSeriesIterator(tsid uint64) (*compress
 	return it, true, nil
 }
 
+// Returns all tsids of the block in ascending order
+func (tsbr *TimeSeriesBlockReader) GetAllTSIDs() []uint64 {
+	retVal := make([]uint64, tsbr.numTSIDs)
+	for i := range retVal {
+		// skipping the version byte and the number of entries, every tsid info takes 12 bytes
+		offset := 3 + i*12
+		retVal[i] = utils.BytesToUint64LittleEndian(tsbr.rawTSO[offset : offset+8])
+	}
+	return retVal
+}
+
 // returns bool if found. If true, returns the tsidx and offset in the TSG file
 func getOffsetFromTsoFile(low uint32, high uint32, nTsids uint32, tsid uint64, tsoBuf []byte) (bool, uint32, uint32) {
 	for low <= high {
@@ -106,12 +106,11 @@ func GetJsonFromAllRrc(allrrc []*utils.RecordResultContainer, esResponse bool, q
 				finalCols[key] = true
 			}
 
-			for recInden, record := range recs {
-
-				if hasQueryAggergatorBlock {
-					agg.PostQueryBucketCleaning(nil, aggs, recs, finalCols)
-				}
+			if hasQueryAggergatorBlock {
+				agg.PostQueryBucketCleaning(nil, aggs, recs, finalCols)
+			}
 
+			for recInden, record := range recs {
 				for key, val := range renameHardcodedColumns {
 					record[key] = val
 				}
@@ -157,6 +156,17 @@ func GetJsonFromAllRrc(allrrc []*utils.RecordResultContainer, esResponse bool, q
 
 			}
 		}
+
+		// some aggregator blocks (e.g. similar_to) drop records, remove their empty slots
+		if hasQueryAggergatorBlock {
+			finalRecords := make([]map[string]interface{}, 0, len(allRecords))
+			for _, record := range allRecords {
+				if record != nil {
+					finalRecords = append(finalRecords, record)
+				}
+			}
+			allRecords = finalRecords
+		}
 	} else {
 		if len(hardcodedArray) > 0 {
 			for key := range renameHardcodedColumns {
@@ -0,0 +1,76 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package segread
+
+import (
+	"errors"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+)
+
+var pageSize = os.Getpagesize()
+
+/*
+Maps the whole segment file read only, so that the blocks are decoded straight from the page cache instead of being
+copied into a read buffer with a syscall per block. The file is expected to be scanned in order, the kernel is told
+to read ahead aggressively and to drop the pages behind
+*/
+func mmapSegmentFile(fd *os.File) ([]byte, error) {
+	finfo, err := fd.Stat()
+	if err != nil {
+		log.Errorf("mmapSegmentFile: failed to stat file %v, err=%v", fd.Name(), err)
+		return nil, err
+	}
+	if finfo.Size() == 0 {
+		return nil, errors.New("mmapSegmentFile: can not map an empty file")
+	}
+	data, err := unix.Mmap(int(fd.Fd()), 0, int(finfo.Size()), unix.PROT_READ, unix.MAP_SHARED)
+	if err != nil {
+		log.Errorf("mmapSegmentFile: failed to mmap file %v, err=%v", fd.Name(), err)
+		return nil, err
+	}
+	err = unix.Madvise(data, unix.MADV_SEQUENTIAL)
+	if err != nil {
+		// the hint is only an optimization, the mapping is usable without it
+		log.Debugf("mmapSegmentFile: madvise failed for file %v, err=%v", fd.Name(), err)
+	}
+	return data, nil
+}
+
+// Asks the kernel to start reading the pages of the range in the background
+func adviseWillNeed(data []byte, offset int64, length uint32) {
+	start := offset - offset%int64(pageSize)
+	end := offset + int64(length)
+	if start < 0 || end > int64(len(data)) || start >= end {
+		return
+	}
+	err := unix.Madvise(data[start:end], unix.MADV_WILLNEED)
+	if err != nil {
+		log.Debugf("adviseWillNeed: madvise failed, err=%v", err)
+	}
+}
+
+func munmapSegmentFile(data []byte) error {
+	err := unix.Munmap(data)
+	if err != nil {
+		log.Errorf("munmapSegmentFile: failed to unmap, err=%v", err)
+		return err
+	}
+	return nil
+}
@@ -267,28 +267,51 @@ func (mcsr *MultiColSegmentReader) ReadRawRecordFromColumnFile(col string, block
 // Reads the request value and converts it to a *utils.CValueEnclosure
 func (mcsr *MultiColSegmentReader) ExtractValueFromColumnFile(col string, blockNum uint16, recordNum uint16,
 	qid uint64) (*utils.CValueEnclosure, error) {
+	cval := &utils.CValueEnclosure{}
+	err := mcsr.ExtractValueIntoCval(col, blockNum, recordNum, qid, cval)
+	return cval, err
+}
+
+// Same as ExtractValueFromColumnFile, but decodes into cval so that the per record loops don't allocate an enclosure
+func (mcsr *MultiColSegmentReader) ExtractValueIntoCval(col string, blockNum uint16, recordNum uint16,
+	qid uint64, cval *utils.CValueEnclosure) error {
 	if col == mcsr.timeStampKey {
 		ts, err := mcsr.GetTimeStampForRecord(blockNum, recordNum, qid)
 		if err != nil {
-			return &utils.CValueEnclosure{}, err
+			*cval = utils.CValueEnclosure{}
+			return err
 		}
 
-		return &utils.CValueEnclosure{
-			Dtype: utils.SS_DT_UNSIGNED_NUM,
-			CVal:  ts,
-		}, nil
+		cval.Dtype = utils.SS_DT_UNSIGNED_NUM
+		cval.CVal = ts
+		return nil
 	}
 
 	rawVal, err := mcsr.ReadRawRecordFromColumnFile(col, blockNum, recordNum, qid)
 	if err != nil {
-		return &utils.CValueEnclosure{
-			Dtype: utils.SS_DT_BACKFILL,
-			CVal:  nil,
-		}, err
+		cval.Dtype = utils.SS_DT_BACKFILL
+		cval.CVal = nil
+		return err
 	}
 
-	cval, _, err := writer.GetCvalFromRec(rawVal, qid)
-	return &cval, err
+	*cval, _, err = writer.GetCvalFromRec(rawVal, qid)
+	return err
+}
+
+/*
+Decodes the numeric values of the given records of the column in a batch, see
+SegmentFileReader.ReadNumericValuesFromBlock. Returns false for the timestamp column
+*/
+func (mcsr *MultiColSegmentReader) ReadNumericValuesFromColumnFile(col string, blockNum uint16, sortedRecNums []uint16,
+	intVals []int64, fltVals []float64) ([]int64, []float64, bool, error) {
+	if col == mcsr.timeStampKey {
+		return intVals, fltVals, false, nil
+	}
+	keyIndex, ok := mcsr.allColsReverseIndex[col]
+	if !ok {
+		return intVals, fltVals, false, errors.New("column not found in MultipleColumnSegmentReader")
+	}
+	return mcsr.allFileReaders[keyIndex].ReadNumericValuesFromBlock(blockNum, sortedRecNums, intVals, fltVals)
 }
 
 func (mcsr *MultiColSegmentReader) returnBuffers() {
@@ -305,6 +328,15 @@ func (mcsr *MultiColSegmentReader) returnBuffers() {
 	}
 }
 
+// Starts reading the block of every column ahead of its search, see SegmentFileReader.PrefetchBlock
+func (mcsr *MultiColSegmentReader) PrefetchBlock(blockNum uint16) {
+	for _, reader := range mcsr.allFileReaders {
+		if reader != nil {
+			reader.PrefetchBlock(blockNum)
+		}
+	}
+}
+
 func (mcsr *MultiColSegmentReader) IncrementColumnUsage(colName string) {
 	mcsr.allColInfoReverseIndex[colName].count++
 }
@@ -19,12 +19,15 @@ package segread
 import (
 	"errors"
 	"fmt"
+	"math"
 	"os"
 	"sync"
 
 	"github.com/klauspost/compress/zstd"
+	"github.com/siglens/siglens/pkg/config"
 	"github.com/siglens/siglens/pkg/segment/structs"
 	"github.com/siglens/siglens/pkg/segment/utils"
+	"github.com/siglens/siglens/pkg/segment/workerpool"
 	toputils "github.com/siglens/siglens/pkg/utils"
 	log "github.com/sirupsen/logrus"
 )
@@ -54,10 +57,22 @@ var fileReadBufferPool = sync.Pool{
 // smaller.
 var decoder, _ = zstd.NewReader(nil, zstd.WithDecoderConcurrency(0))
 
+// Reads of the next blocks that a reader keeps, the older ones are dropped
+const MAX_PREFETCHED_BLOCKS = 2
+
+// A read of a column block from disk that runs ahead of the search of the block, on an io worker
+type blockPrefetch struct {
+	blockNum uint16
+	buf      []byte
+	err      error
+	done     chan struct{}
+}
+
 type SegmentFileReader struct {
 	ColName       string   // column name this file references
 	fileName      string   // file name to iterate
 	currFD        *os.File // current file descriptor
+	mmapData      []byte   // the mapped file if the segment files are mmapped, the blocks are then not read into currFileBuffer
 	blockMetadata map[uint16]*structs.BlockMetadataHolder
 
 	currBlockNum             uint16
@@ -74,16 +89,28 @@ type SegmentFileReader struct {
 	deTlv                [][]byte // deTlv[dWordIdx] --> []byte (the TLV byte slice)
 	deRecToTlv           []uint16 // deRecToTlv[recNum] --> dWordIdx
 	blockSummaries       []*structs.BlockSummary
+	prefetches           []*blockPrefetch
+	spareBuffers         [][]byte // file read buffers of the consumed and dropped prefetches
 }
 
 // returns a new SegmentFileReader and any errors encountered
 // The returned SegmentFileReader must call .Close() when finished using it to close the fd
 func InitNewSegFileReader(fd *os.File, colName string, blockMetadata map[uint16]*structs.BlockMetadataHolder,
 	qid uint64, blockSummaries []*structs.BlockSummary) (*SegmentFileReader, error) {
+	var mmapData []byte
+	if config.IsMmapSegmentFilesEnabled() {
+		var err error
+		mmapData, err = mmapSegmentFile(fd)
+		if err != nil {
+			log.Debugf("qid=%d, InitNewSegFileReader: reading file %v without mmap, err=%v", qid, fd.Name(), err)
+			mmapData = nil
+		}
+	}
 	return &SegmentFileReader{
 		ColName:              colName,
 		fileName:             fd.Name(),
 		currFD:               fd,
+		mmapData:             mmapData,
 		blockMetadata:        blockMetadata,
 		currOffset:           0,
 		currFileBuffer:       *fileReadBufferPool.Get().(*[]byte),
@@ -101,14 +128,115 @@ func (sfr *SegmentFileReader) Close() error {
 		return errors.New("tried to close an unopened segment file reader")
 	}
 	sfr.returnBuffers()
+	if sfr.mmapData != nil {
+		err := munmapSegmentFile(sfr.mmapData)
+		sfr.mmapData = nil
+		if err != nil {
+			_ = sfr.currFD.Close()
+			return err
+		}
+	}
 	return sfr.currFD.Close()
 }
 
 func (sfr *SegmentFileReader) returnBuffers() {
-	uncompressedReadBufferPool.Put(&sfr.currRawBlockBuffer)
+	// the reads in flight must finish before the buffers are reused and the fd is closed
+	for len(sfr.prefetches) > 0 {
+		sfr.dropPrefetch(0)
+	}
+	for i := range sfr.spareBuffers {
+		fileReadBufferPool.Put(&sfr.spareBuffers[i])
+	}
+	sfr.spareBuffers = nil
+	uncompressedReadBufferPool.Put(&sfr.currUncompressBuffer)
 	fileReadBufferPool.Put(&sfr.currFileBuffer)
 }
 
+/*
+Starts reading the column block from disk on an io worker, so that the read is done by the time the block is
+searched. Nothing is read if the io workers are busy, the block is then read when it is loaded. A mapped file is
+read ahead by the kernel instead
+*/
+func (sfr *SegmentFileReader) PrefetchBlock(blockNum uint16) {
+	if sfr.isBlockLoaded && sfr.currBlockNum == blockNum {
+		return
+	}
+	for _, prefetch := range sfr.prefetches {
+		if prefetch.blockNum == blockNum {
+			return
+		}
+	}
+	blockMetadata, ok := sfr.blockMetadata[blockNum]
+	if !ok {
+		return
+	}
+	colBlockLen, ok := blockMetadata.ColumnBlockLen[sfr.ColName]
+	if !ok {
+		return
+	}
+	colBlockOffset, ok := blockMetadata.ColumnBlockOffset[sfr.ColName]
+	if !ok {
+		return
+	}
+	if sfr.mmapData != nil {
+		adviseWillNeed(sfr.mmapData, colBlockOffset, colBlockLen)
+		return
+	}
+
+	if len(sfr.prefetches) >= MAX_PREFETCHED_BLOCKS {
+		sfr.dropPrefetch(0)
+	}
+	prefetch := &blockPrefetch{
+		blockNum: blockNum,
+		buf:      sfr.getSpareBuffer(colBlockLen),
+		done:     make(chan struct{}),
+	}
+	fd := sfr.currFD
+	submitted := workerpool.TrySubmitRead(func() {
+		_, prefetch.err = fd.ReadAt(prefetch.buf[:colBlockLen], colBlockOffset)
+		close(prefetch.done)
+	})
+	if !submitted {
+		sfr.spareBuffers = append(sfr.spareBuffers, prefetch.buf)
+		return
+	}
+	sfr.prefetches = append(sfr.prefetches, prefetch)
+}
+
+// Returns a file read buffer of at least minLen bytes
+func (sfr *SegmentFileReader) getSpareBuffer(minLen uint32) []byte {
+	var buf []byte
+	if numSpare := len(sfr.spareBuffers); numSpare > 0 {
+		buf = sfr.spareBuffers[numSpare-1]
+		sfr.spareBuffers = sfr.spareBuffers[:numSpare-1]
+	} else {
+		buf = *fileReadBufferPool.Get().(*[]byte)
+	}
+	if uint32(len(buf)) < minLen {
+		buf = append(buf, make([]byte, minLen-uint32(len(buf)))...)
+	}
+	return buf
+}
+
+// Waits for the read of the block if it was prefetched and returns it, or nil if it wasn't
+func (sfr *SegmentFileReader) takePrefetch(blockNum uint16) *blockPrefetch {
+	for i, prefetch := range sfr.prefetches {
+		if prefetch.blockNum == blockNum {
+			<-prefetch.done
+			sfr.prefetches = append(sfr.prefetches[:i], sfr.prefetches[i+1:]...)
+			return prefetch
+		}
+	}
+	return nil
+}
+
+func (sfr *SegmentFileReader) dropPrefetch(idx int) {
+	prefetch := sfr.prefetches[idx]
+	<-prefetch.done
+	sfr.spareBuffers = append(sfr.spareBuffers, prefetch.buf)
+	sfr.prefetches = append(sfr.prefetches[:idx], sfr.prefetches[idx+1:]...)
+}
+
 // returns a bool indicating if blockNum is valid, and any error encountered
 func (sfr *SegmentFileReader) readBlock(blockNum uint16) (bool, error) {
 	validBlock, err := sfr.loadBlockUsingBuffer(blockNum)
@@ -146,24 +274,19 @@ func (sfr *SegmentFileReader) loadBlockUsingBuffer(blockNum uint16) (bool, error
 		return false, nil
 	}
 
-	if uint32(len(sfr.currFileBuffer)) < colBlockLen {
-		newArr := make([]byte, colBlockLen-uint32(len(sfr.currFileBuffer)))
-		sfr.currFileBuffer = append(sfr.currFileBuffer, newArr...)
-	}
-	_, err := sfr.currFD.ReadAt(sfr.currFileBuffer[:colBlockLen], colBlockOffset)
+	blockBuf, err := sfr.readRawBlock(blockNum, colBlockOffset, colBlockLen)
 	if err != nil {
-		log.Errorf("loadBlockUsingBuffer read file error: %+v", err)
 		return true, err
 	}
 	oPtr := uint32(0)
-	sfr.encType = sfr.currFileBuffer[oPtr]
+	sfr.encType = blockBuf[oPtr]
 	oPtr++
 
 	if sfr.encType == utils.ZSTD_COMLUNAR_BLOCK[0] {
-		err := sfr.unpackRawCsg(sfr.currFileBuffer[oPtr:colBlockLen], blockNum)
+		err := sfr.unpackRawCsg(blockBuf[oPtr:colBlockLen], blockNum)
 		return true, err
 	} else if sfr.encType == utils.ZSTD_DICTIONARY_BLOCK[0] {
-		err := sfr.readDictEnc(sfr.currFileBuffer[oPtr:colBlockLen], blockNum)
+		err := sfr.readDictEnc(blockBuf[oPtr:colBlockLen], blockNum)
 		return true, err
 	} else {
 		log.Errorf("received an unknown encoding type for %v column! expected zstd or dictenc got %+v",
@@ -173,6 +296,38 @@ func (sfr *SegmentFileReader) loadBlockUsingBuffer(blockNum uint16) (bool, error
 	}
 }
 
+// Returns the bytes of the column block as stored in the file, which are mapped or read into currFileBuffer
+func (sfr *SegmentFileReader) readRawBlock(blockNum uint16, colBlockOffset int64, colBlockLen uint32) ([]byte, error) {
+	if sfr.mmapData != nil {
+		if colBlockOffset < 0 || colBlockOffset+int64(colBlockLen) > int64(len(sfr.mmapData)) {
+			return nil, fmt.Errorf("readRawBlock: block %v at offset %v with len %v is past the end of file %v",
+				blockNum, colBlockOffset, colBlockLen, sfr.fileName)
+		}
+		return sfr.mmapData[colBlockOffset : colBlockOffset+int64(colBlockLen)], nil
+	}
+
+	// the decoded block may point into the file buffer, so a prefetched buffer replaces it rather than being copied
+	prefetch := sfr.takePrefetch(blockNum)
+	if prefetch != nil && prefetch.err == nil {
+		sfr.spareBuffers = append(sfr.spareBuffers, sfr.currFileBuffer)
+		sfr.currFileBuffer = prefetch.buf
+	} else {
+		if prefetch != nil {
+			sfr.spareBuffers = append(sfr.spareBuffers, prefetch.buf)
+		}
+		if uint32(len(sfr.currFileBuffer)) < colBlockLen {
+			newArr := make([]byte, colBlockLen-uint32(len(sfr.currFileBuffer)))
+			sfr.currFileBuffer = append(sfr.currFileBuffer, newArr...)
+		}
+		_, err := sfr.currFD.ReadAt(sfr.currFileBuffer[:colBlockLen], colBlockOffset)
+		if err != nil {
+			log.Errorf("readRawBlock: read file error: %+v", err)
+			return nil, err
+		}
+	}
+	return sfr.currFileBuffer[:colBlockLen], nil
+}
+
 // returns the raw bytes of the blockNum:recordNum combination in the current segfile
 // optimized for subsequent calls to have the same blockNum
 // returns : encodedVal, error
@@ -229,6 +384,125 @@ func (sfr *SegmentFileReader) ReadRecordFromBlock(blockNum uint16, recordNum uin
 	return nil, errors.New(errStr)
 }
 
+/*
+Decodes the numeric values of the given records of the block, whose numbers must be sorted. The values are appended
+to intVals while they are all integers, and all of them to fltVals once one is a float or an uint64 above the int64
+range, so only one of the slices grows. Records without a value are skipped. Returns false if the block is dict
+encoded or has a value that isn't a number, the records then have to be read one at a time with ReadRecordFromBlock
+*/
+func (sfr *SegmentFileReader) ReadNumericValuesFromBlock(blockNum uint16, sortedRecNums []uint16, intVals []int64,
+	fltVals []float64) ([]int64, []float64, bool, error) {
+
+	if !sfr.isBlockLoaded || sfr.currBlockNum != blockNum {
+		valid, err := sfr.readBlock(blockNum)
+		if !valid {
+			return intVals, fltVals, false, err
+		}
+		if err != nil {
+			log.Errorf("ReadNumericValuesFromBlock: error loading blockNum: %v. Error: %+v", blockNum, err)
+			return intVals, fltVals, false, err
+		}
+	}
+	if sfr.encType == utils.ZSTD_DICTIONARY_BLOCK[0] {
+		return intVals, fltVals, false, nil
+	}
+
+	// walks the records without moving the offset of ReadRecordFromBlock
+	block := sfr.currRawBlockBuffer[:sfr.currUncompressedBlockLen]
+	offset := uint32(0)
+	recNum := uint16(0)
+	numInts := len(intVals)
+	isInt := true
+	toFloats := func() {
+		for _, intVal := range intVals[numInts:] {
+			fltVals = append(fltVals, float64(intVal))
+		}
+		intVals = intVals[:numInts]
+		isInt = false
+	}
+	appendInt := func(intVal int64) {
+		if isInt {
+			intVals = append(intVals, intVal)
+		} else {
+			fltVals = append(fltVals, float64(intVal))
+		}
+	}
+	for _, wantedRecNum := range sortedRecNums {
+		for ; recNum < wantedRecNum; recNum++ {
+			if offset >= uint32(len(block)) {
+				return intVals, fltVals, false, fmt.Errorf("ReadNumericValuesFromBlock: block %v of %v ends before record %v",
+					blockNum, sfr.ColName, wantedRecNum)
+			}
+			recLen, isNumeric := getNumericRecordLength(block[offset])
+			if !isNumeric {
+				return intVals, fltVals, false, nil
+			}
+			offset += recLen
+		}
+		if offset >= uint32(len(block)) {
+			return intVals, fltVals, false, fmt.Errorf("ReadNumericValuesFromBlock: block %v of %v ends before record %v",
+				blockNum, sfr.ColName, wantedRecNum)
+		}
+		rec := block[offset:]
+		switch rec[0] {
+		case utils.VALTYPE_ENC_INT8[0]:
+			appendInt(int64(int8(rec[1])))
+		case utils.VALTYPE_ENC_INT16[0]:
+			appendInt(int64(toputils.BytesToInt16LittleEndian(rec[1:])))
+		case utils.VALTYPE_ENC_INT32[0]:
+			appendInt(int64(toputils.BytesToInt32LittleEndian(rec[1:])))
+		case utils.VALTYPE_ENC_INT64[0]:
+			appendInt(toputils.BytesToInt64LittleEndian(rec[1:]))
+		case utils.VALTYPE_ENC_UINT8[0]:
+			appendInt(int64(rec[1]))
+		case utils.VALTYPE_ENC_UINT16[0]:
+			appendInt(int64(toputils.BytesToUint16LittleEndian(rec[1:])))
+		case utils.VALTYPE_ENC_UINT32[0]:
+			appendInt(int64(toputils.BytesToUint32LittleEndian(rec[1:])))
+		case utils.VALTYPE_ENC_UINT64[0]:
+			uintVal := toputils.BytesToUint64LittleEndian(rec[1:])
+			if uintVal <= math.MaxInt64 {
+				appendInt(int64(uintVal))
+				break
+			}
+			if isInt {
+				toFloats()
+			}
+			fltVals = append(fltVals, float64(uintVal))
+		case utils.VALTYPE_ENC_FLOAT64[0]:
+			if isInt {
+				toFloats()
+			}
+			fltVals = append(fltVals, toputils.BytesToFloat64LittleEndian(rec[1:]))
+		case utils.VALTYPE_ENC_BACKFILL[0]:
+		default:
+			return intVals, fltVals, false, nil
+		}
+		recLen, _ := getNumericRecordLength(rec[0])
+		offset += recLen
+		recNum++
+	}
+	return intVals, fltVals, true, nil
+}
+
+// Returns the length of a record with the type, and false if the type isn't a number or a backfilled value
+func getNumericRecordLength(valType byte) (uint32, bool) {
+	switch valType {
+	case utils.VALTYPE_ENC_INT8[0], utils.VALTYPE_ENC_UINT8[0]:
+		return 2, true
+	case utils.VALTYPE_ENC_INT16[0], utils.VALTYPE_ENC_UINT16[0]:
+		return 3, true
+	case utils.VALTYPE_ENC_INT32[0], utils.VALTYPE_ENC_UINT32[0]:
+		return 5, true
+	case utils.VALTYPE_ENC_INT64[0], utils.VALTYPE_ENC_UINT64[0], utils.VALTYPE_ENC_FLOAT64[0]:
+		return 9, true
+	case utils.VALTYPE_ENC_BACKFILL[0]:
+		return 1, true
+	default:
+		return 0, false
+	}
+}
+
 // returns the new record number and if any errors are encountered
 // an error will be returned if no more records are available
 func (sfr *SegmentFileReader) iterateNextRecord() error {
@@ -370,6 +644,8 @@ func (sfr *SegmentFileReader) unpackRawCsg(buf []byte, blockNum uint16) error {
 		log.Errorf("unpackRawCsg decompress error: %+v", err)
 		return err
 	}
+	// keep the buffer if decoding had to grow it, so that the larger one goes back to the pool
+	sfr.currUncompressBuffer = uncompressed[:0]
 	sfr.currRawBlockBuffer = uncompressed
 	sfr.currOffset = 0
 
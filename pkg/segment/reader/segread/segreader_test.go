@@ -81,6 +81,175 @@ func Test_segReader(t *testing.T) {
 	os.RemoveAll(segDir)
 }
 
+func Test_prefetchBlock(t *testing.T) {
+
+	segDir := "data/"
+	_ = os.MkdirAll(segDir, 0755)
+	segKey := segDir + "prefetch"
+	numBlocks := 10
+	numEntriesInBlock := 10
+	_, bsm, _, cols, blockmeta, _ := writer.WriteMockColSegFile(segKey, numBlocks, numEntriesInBlock)
+
+	for queryCol := range cols {
+		if queryCol == config.GetTimeStampKey() {
+			continue
+		}
+		fileName := fmt.Sprintf("%s_%v.csg", segKey, xxhash.Sum64String(queryCol))
+		fd, err := os.Open(fileName)
+		assert.NoError(t, err)
+		plainReader, err := InitNewSegFileReader(fd, queryCol, blockmeta, 0, bsm)
+		assert.Nil(t, err)
+		prefetchFd, err := os.Open(fileName)
+		assert.NoError(t, err)
+		prefetchReader, err := InitNewSegFileReader(prefetchFd, queryCol, blockmeta, 0, bsm)
+		assert.Nil(t, err)
+
+		// blocks that don't exist are not read
+		prefetchReader.PrefetchBlock(uint16(numBlocks))
+		assert.Len(t, prefetchReader.prefetches, 0)
+
+		prefetchReader.PrefetchBlock(0)
+		for blkNum := uint16(0); blkNum < uint16(numBlocks); blkNum++ {
+			prefetchReader.PrefetchBlock(blkNum + 1)
+			assert.LessOrEqual(t, len(prefetchReader.prefetches), MAX_PREFETCHED_BLOCKS)
+			for recNum := uint16(0); recNum < uint16(numEntriesInBlock); recNum++ {
+				expected, err := plainReader.ReadRecordFromBlock(blkNum, recNum)
+				assert.Nil(t, err)
+				actual, err := prefetchReader.ReadRecordFromBlock(blkNum, recNum)
+				assert.Nil(t, err)
+				assert.Equal(t, expected, actual, "col %s block %v record %v", queryCol, blkNum, recNum)
+			}
+		}
+
+		// closing waits for the reads in flight
+		prefetchReader.PrefetchBlock(1)
+		assert.Nil(t, plainReader.Close())
+		assert.Nil(t, prefetchReader.Close())
+		assert.Len(t, prefetchReader.prefetches, 0)
+		assert.Len(t, prefetchReader.spareBuffers, 0)
+	}
+
+	os.RemoveAll(segDir)
+}
+
+func Test_mmapSegFileReader(t *testing.T) {
+
+	segDir := "data/"
+	_ = os.MkdirAll(segDir, 0755)
+	segKey := segDir + "mmap"
+	numBlocks := 10
+	numEntriesInBlock := 10
+	_, bsm, _, cols, blockmeta, _ := writer.WriteMockColSegFile(segKey, numBlocks, numEntriesInBlock)
+	defer config.SetMmapSegmentFiles(false)
+
+	for queryCol := range cols {
+		if queryCol == config.GetTimeStampKey() {
+			continue
+		}
+		fileName := fmt.Sprintf("%s_%v.csg", segKey, xxhash.Sum64String(queryCol))
+		config.SetMmapSegmentFiles(false)
+		fd, err := os.Open(fileName)
+		assert.NoError(t, err)
+		plainReader, err := InitNewSegFileReader(fd, queryCol, blockmeta, 0, bsm)
+		assert.Nil(t, err)
+		assert.Nil(t, plainReader.mmapData)
+
+		config.SetMmapSegmentFiles(true)
+		mmapFd, err := os.Open(fileName)
+		assert.NoError(t, err)
+		mmapReader, err := InitNewSegFileReader(mmapFd, queryCol, blockmeta, 0, bsm)
+		assert.Nil(t, err)
+		assert.NotNil(t, mmapReader.mmapData)
+
+		// a mapped file is read ahead by the kernel, not by the io workers
+		mmapReader.PrefetchBlock(1)
+		assert.Len(t, mmapReader.prefetches, 0)
+
+		for blkNum := uint16(0); blkNum < uint16(numBlocks); blkNum++ {
+			for recNum := uint16(0); recNum < uint16(numEntriesInBlock); recNum++ {
+				expected, err := plainReader.ReadRecordFromBlock(blkNum, recNum)
+				assert.Nil(t, err)
+				actual, err := mmapReader.ReadRecordFromBlock(blkNum, recNum)
+				assert.Nil(t, err)
+				assert.Equal(t, expected, actual, "col %s block %v record %v", queryCol, blkNum, recNum)
+			}
+		}
+
+		assert.Nil(t, plainReader.Close())
+		assert.Nil(t, mmapReader.Close())
+		assert.Nil(t, mmapReader.mmapData)
+	}
+
+	os.RemoveAll(segDir)
+}
+
+func Test_readNumericValuesFromBlock(t *testing.T) {
+
+	segDir := "data/"
+	_ = os.MkdirAll(segDir, 0755)
+	segKey := segDir + "numeric"
+	numBlocks := 3
+	numEntriesInBlock := 10
+	_, bsm, _, cols, blockmeta, _ := writer.WriteMockColSegFile(segKey, numBlocks, numEntriesInBlock)
+
+	recNums := []uint16{0, 3, 4, 9}
+	for queryCol := range cols {
+		if queryCol == config.GetTimeStampKey() {
+			continue
+		}
+		fileName := fmt.Sprintf("%s_%v.csg", segKey, xxhash.Sum64String(queryCol))
+		fd, err := os.Open(fileName)
+		assert.NoError(t, err)
+		sReader, err := InitNewSegFileReader(fd, queryCol, blockmeta, 0, bsm)
+		assert.Nil(t, err)
+
+		for blkNum := uint16(0); blkNum < uint16(numBlocks); blkNum++ {
+			intVals, fltVals, isNumeric, err := sReader.ReadNumericValuesFromBlock(blkNum, recNums, make([]int64, 0),
+				make([]float64, 0))
+			assert.Nil(t, err)
+
+			expectedInts := make([]int64, 0)
+			expectedFlts := make([]float64, 0)
+			expectNumeric := true
+			hasFloat := false
+			for _, recNum := range recNums {
+				rec, err := sReader.ReadRecordFromBlock(blkNum, recNum)
+				assert.Nil(t, err)
+				cVal, _, err := writer.GetCvalFromRec(rec, 0)
+				assert.Nil(t, err)
+				fltVal, err := cVal.GetFloatValue()
+				if cVal.Dtype == segutils.SS_DT_STRING || err != nil {
+					expectNumeric = false
+					break
+				}
+				switch intVal := cVal.CVal.(type) {
+				case int64:
+					expectedInts = append(expectedInts, intVal)
+				case uint64:
+					expectedInts = append(expectedInts, int64(intVal))
+				default:
+					hasFloat = true
+				}
+				expectedFlts = append(expectedFlts, fltVal)
+			}
+			assert.Equal(t, expectNumeric, isNumeric, "col %s", queryCol)
+			if !expectNumeric {
+				continue
+			}
+			if hasFloat {
+				assert.Len(t, intVals, 0, "col %s", queryCol)
+				assert.Equal(t, expectedFlts, fltVals, "col %s", queryCol)
+			} else {
+				assert.Equal(t, expectedInts, intVals, "col %s", queryCol)
+				assert.Len(t, fltVals, 0, "col %s", queryCol)
+			}
+		}
+		assert.Nil(t, sReader.Close())
+	}
+
+	os.RemoveAll(segDir)
+}
+
 func Test_timeReader(t *testing.T) {
 
 	config.InitializeTestingConfig()
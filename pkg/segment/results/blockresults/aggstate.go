@@ -0,0 +1,524 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blockresults
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/axiomhq/hyperloglog"
+	"github.com/siglens/siglens/pkg/segment/structs"
+	"github.com/siglens/siglens/pkg/segment/utils"
+	toputils "github.com/siglens/siglens/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+/*
+	Compact binary encoding of the partial aggregation results of a query, so that the results of other processes
+	can be merged into them. The layout is:
+
+	[version 1B][flags 1B][time buckets, if AGG_STATE_HAS_TIME_BUCKETS][group by buckets, if AGG_STATE_HAS_GROUPBY]
+
+	time buckets:     [numBuckets uvarint] then [bucket key uvarint][bucket] for every bucket
+	group by buckets: [numBuckets uvarint] then [bucket key string][bucket] for every bucket,
+	                  [numColVals uvarint] then [col val string][count varint] for the group by col val counts
+	bucket:           [count uvarint][running stats][numGroups uvarint] then [group string][running stats] per timechart group
+	running stats:    [numStats uvarint] then [hasHll 1B][hll bytes, if hasHll][raw value] for every stat
+	raw value:        [dtype 1B][value, by dtype]
+
+	Strings and byte slices are prefixed with their length as an uvarint, other integers are varints and floats are
+	8 bytes little endian. Decoders reject the versions they do not know, so the layout can change with the version
+*/
+
+const AGG_STATE_VERSION = uint8(1)
+
+const (
+	AGG_STATE_HAS_TIME_BUCKETS = uint8(1 << iota)
+	AGG_STATE_HAS_GROUPBY
+)
+
+var errBadAggState = errors.New("malformed aggregation state")
+
+// Encodes the time and group by buckets of the block results
+func (b *BlockResults) EncodeAggState() ([]byte, error) {
+	w := &aggStateWriter{}
+	flags := uint8(0)
+	if b.TimeAggregation != nil {
+		flags |= AGG_STATE_HAS_TIME_BUCKETS
+	}
+	if b.GroupByAggregation != nil {
+		flags |= AGG_STATE_HAS_GROUPBY
+	}
+	w.buf.WriteByte(AGG_STATE_VERSION)
+	w.buf.WriteByte(flags)
+
+	if b.TimeAggregation != nil {
+		err := b.TimeAggregation.encode(w)
+		if err != nil {
+			log.Errorf("BlockResults.EncodeAggState: failed to encode the time buckets: %v", err)
+			return nil, err
+		}
+	}
+	if b.GroupByAggregation != nil {
+		err := b.GroupByAggregation.encode(w)
+		if err != nil {
+			log.Errorf("BlockResults.EncodeAggState: failed to encode the group by buckets: %v", err)
+			return nil, err
+		}
+	}
+	return w.buf.Bytes(), nil
+}
+
+// Decodes an aggregation state encoded by EncodeAggState for the same query and merges it into the block results
+func (b *BlockResults) MergeEncodedAggState(data []byte) error {
+	timeBuckets, grpBuckets, err := b.DecodeAggState(data)
+	if err != nil {
+		return err
+	}
+	if timeBuckets != nil {
+		if b.TimeAggregation == nil {
+			b.TimeAggregation = timeBuckets
+		} else {
+			b.TimeAggregation.MergeBuckets(timeBuckets)
+		}
+	}
+	if grpBuckets != nil {
+		if b.GroupByAggregation == nil {
+			b.GroupByAggregation = grpBuckets
+		} else {
+			b.GroupByAggregation.MergeBuckets(grpBuckets)
+		}
+	}
+	return nil
+}
+
+// Decodes an aggregation state encoded by EncodeAggState, the measure functions of the buckets come from the aggs of the block results
+func (b *BlockResults) DecodeAggState(data []byte) (*TimeBuckets, *GroupByBuckets, error) {
+	r := &aggStateReader{r: bytes.NewReader(data)}
+	version, err := r.r.ReadByte()
+	if err != nil {
+		log.Errorf("BlockResults.DecodeAggState: failed to read the version: %v", err)
+		return nil, nil, errBadAggState
+	}
+	if version != AGG_STATE_VERSION {
+		log.Errorf("BlockResults.DecodeAggState: unsupported aggregation state version %v", version)
+		return nil, nil, fmt.Errorf("unsupported aggregation state version %v", version)
+	}
+	flags, err := r.r.ReadByte()
+	if err != nil {
+		log.Errorf("BlockResults.DecodeAggState: failed to read the flags: %v", err)
+		return nil, nil, errBadAggState
+	}
+
+	var timeBuckets *TimeBuckets
+	if flags&AGG_STATE_HAS_TIME_BUCKETS != 0 {
+		timeBuckets, err = decodeTimeBuckets(r)
+		if err != nil {
+			log.Errorf("BlockResults.DecodeAggState: failed to decode the time buckets: %v", err)
+			return nil, nil, err
+		}
+	}
+
+	var grpBuckets *GroupByBuckets
+	if flags&AGG_STATE_HAS_GROUPBY != 0 {
+		if b.aggs == nil || b.aggs.GroupByRequest == nil {
+			log.Errorf("BlockResults.DecodeAggState: the aggregation state has group by buckets but the query has no group by")
+			return nil, nil, errBadAggState
+		}
+		grpBuckets, err = decodeGroupByBuckets(r, b.aggs.GroupByRequest, b.aggs.UsedByTimechart())
+		if err != nil {
+			log.Errorf("BlockResults.DecodeAggState: failed to decode the group by buckets: %v", err)
+			return nil, nil, err
+		}
+	}
+
+	if r.r.Len() != 0 {
+		log.Errorf("BlockResults.DecodeAggState: %v bytes left after decoding", r.r.Len())
+		return nil, nil, errBadAggState
+	}
+	return timeBuckets, grpBuckets, nil
+}
+
+func (tb *TimeBuckets) encode(w *aggStateWriter) error {
+	w.writeUvarint(uint64(len(tb.UnsignedBucketIdx)))
+	for key, idx := range tb.UnsignedBucketIdx {
+		w.writeUvarint(key)
+		err := tb.AllRunningBuckets[idx].encode(w)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodeTimeBuckets(r *aggStateReader) (*TimeBuckets, error) {
+	numBuckets, err := r.readCount()
+	if err != nil {
+		return nil, err
+	}
+	retVal := &TimeBuckets{
+		AllRunningBuckets: make([]*RunningBucketResults, 0, numBuckets),
+		UnsignedBucketIdx: make(map[uint64]int, numBuckets),
+	}
+	for i := 0; i < numBuckets; i++ {
+		key, err := r.readUvarint()
+		if err != nil {
+			return nil, err
+		}
+		bucket, err := decodeRunningBucket(r, nil)
+		if err != nil {
+			return nil, err
+		}
+		retVal.AllRunningBuckets = append(retVal.AllRunningBuckets, bucket)
+		retVal.UnsignedBucketIdx[key] = i
+	}
+	return retVal, nil
+}
+
+func (gb *GroupByBuckets) encode(w *aggStateWriter) error {
+	w.writeUvarint(uint64(len(gb.StringBucketIdx)))
+	for key, idx := range gb.StringBucketIdx {
+		w.writeString(key)
+		err := gb.AllRunningBuckets[idx].encode(w)
+		if err != nil {
+			return err
+		}
+	}
+	w.writeUvarint(uint64(len(gb.GroupByColValCnt)))
+	for colVal, cnt := range gb.GroupByColValCnt {
+		w.writeString(colVal)
+		w.writeVarint(int64(cnt))
+	}
+	return nil
+}
+
+func decodeGroupByBuckets(r *aggStateReader, req *structs.GroupByRequest, usedByTimechart bool) (*GroupByBuckets, error) {
+	mCols, mFuns, revIndex := convertRequestToInternalStats(req, usedByTimechart)
+	numBuckets, err := r.readCount()
+	if err != nil {
+		return nil, err
+	}
+	retVal := &GroupByBuckets{
+		AllRunningBuckets:   make([]*RunningBucketResults, 0, numBuckets),
+		StringBucketIdx:     make(map[string]int, numBuckets),
+		allMeasureCols:      mCols,
+		internalMeasureFns:  mFuns,
+		reverseMeasureIndex: revIndex,
+		maxBuckets:          req.BucketCount,
+		interner:            toputils.NewStringInterner(),
+	}
+	for i := 0; i < numBuckets; i++ {
+		key, err := r.readString()
+		if err != nil {
+			return nil, err
+		}
+		bucket, err := decodeRunningBucket(r, mFuns)
+		if err != nil {
+			return nil, err
+		}
+		retVal.AllRunningBuckets = append(retVal.AllRunningBuckets, bucket)
+		retVal.StringBucketIdx[retVal.intern(key)] = i
+	}
+
+	numColVals, err := r.readCount()
+	if err != nil {
+		return nil, err
+	}
+	retVal.GroupByColValCnt = make(map[string]int, numColVals)
+	for i := 0; i < numColVals; i++ {
+		colVal, err := r.readString()
+		if err != nil {
+			return nil, err
+		}
+		cnt, err := r.readVarint()
+		if err != nil {
+			return nil, err
+		}
+		retVal.GroupByColValCnt[retVal.intern(colVal)] = int(cnt)
+	}
+	return retVal, nil
+}
+
+func (rr *RunningBucketResults) encode(w *aggStateWriter) error {
+	w.writeUvarint(rr.count)
+	err := encodeRunningStats(w, rr.runningStats)
+	if err != nil {
+		return err
+	}
+	w.writeUvarint(uint64(len(rr.groupedRunningStats)))
+	for group, stats := range rr.groupedRunningStats {
+		w.writeString(group)
+		err := encodeRunningStats(w, stats)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// currStats are the measure functions of the bucket, nil for the time buckets which only count
+func decodeRunningBucket(r *aggStateReader, currStats []*structs.MeasureAggregator) (*RunningBucketResults, error) {
+	count, err := r.readUvarint()
+	if err != nil {
+		return nil, err
+	}
+	stats, err := decodeRunningStats(r, len(currStats))
+	if err != nil {
+		return nil, err
+	}
+	bucket := &RunningBucketResults{
+		count:        count,
+		runningStats: stats,
+		currStats:    currStats,
+	}
+
+	numGroups, err := r.readCount()
+	if err != nil {
+		return nil, err
+	}
+	if currStats != nil {
+		bucket.groupedRunningStats = make(map[string][]runningStats, numGroups)
+	} else if numGroups > 0 {
+		return nil, fmt.Errorf("time bucket has %v timechart groups", numGroups)
+	}
+	for i := 0; i < numGroups; i++ {
+		group, err := r.readString()
+		if err != nil {
+			return nil, err
+		}
+		groupStats, err := decodeRunningStats(r, len(currStats))
+		if err != nil {
+			return nil, err
+		}
+		bucket.groupedRunningStats[group] = groupStats
+	}
+	return bucket, nil
+}
+
+func encodeRunningStats(w *aggStateWriter, stats []runningStats) error {
+	w.writeUvarint(uint64(len(stats)))
+	for _, rs := range stats {
+		if rs.hll != nil {
+			hllBytes, err := rs.hll.MarshalBinary()
+			if err != nil {
+				return err
+			}
+			w.buf.WriteByte(1)
+			w.writeBytes(hllBytes)
+		} else {
+			w.buf.WriteByte(0)
+		}
+		err := w.writeCValue(rs.rawVal)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Empty stats, like those of the timechart buckets that only had grouped stats, are accepted for any number of measure functions
+func decodeRunningStats(r *aggStateReader, numMeasureFns int) ([]runningStats, error) {
+	numStats, err := r.readCount()
+	if err != nil {
+		return nil, err
+	}
+	if numStats != numMeasureFns && numStats != 0 {
+		return nil, fmt.Errorf("got %v running stats for %v measure functions", numStats, numMeasureFns)
+	}
+	stats := make([]runningStats, numStats)
+	for i := 0; i < numStats; i++ {
+		hasHll, err := r.r.ReadByte()
+		if err != nil {
+			return nil, errBadAggState
+		}
+		if hasHll == 1 {
+			hllBytes, err := r.readBytes()
+			if err != nil {
+				return nil, err
+			}
+			hll := hyperloglog.New()
+			err = hll.UnmarshalBinary(hllBytes)
+			if err != nil {
+				return nil, err
+			}
+			stats[i].hll = hll
+		} else if hasHll != 0 {
+			return nil, errBadAggState
+		}
+		stats[i].rawVal, err = r.readCValue()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return stats, nil
+}
+
+type aggStateWriter struct {
+	buf     bytes.Buffer
+	scratch [binary.MaxVarintLen64]byte
+}
+
+func (w *aggStateWriter) writeUvarint(val uint64) {
+	n := binary.PutUvarint(w.scratch[:], val)
+	w.buf.Write(w.scratch[:n])
+}
+
+func (w *aggStateWriter) writeVarint(val int64) {
+	n := binary.PutVarint(w.scratch[:], val)
+	w.buf.Write(w.scratch[:n])
+}
+
+func (w *aggStateWriter) writeString(val string) {
+	w.writeUvarint(uint64(len(val)))
+	w.buf.WriteString(val)
+}
+
+func (w *aggStateWriter) writeBytes(val []byte) {
+	w.writeUvarint(uint64(len(val)))
+	w.buf.Write(val)
+}
+
+func (w *aggStateWriter) writeCValue(val utils.CValueEnclosure) error {
+	w.buf.WriteByte(uint8(val.Dtype))
+	switch val.Dtype {
+	case utils.SS_INVALID, utils.SS_DT_BACKFILL:
+	case utils.SS_DT_BOOL:
+		w.buf.Write(toputils.BoolToBytesLittleEndian(val.CVal.(bool)))
+	case utils.SS_DT_SIGNED_NUM:
+		w.writeVarint(val.CVal.(int64))
+	case utils.SS_DT_UNSIGNED_NUM:
+		w.writeUvarint(val.CVal.(uint64))
+	case utils.SS_DT_FLOAT:
+		binary.LittleEndian.PutUint64(w.scratch[:8], math.Float64bits(val.CVal.(float64)))
+		w.buf.Write(w.scratch[:8])
+	case utils.SS_DT_STRING:
+		w.writeString(val.CVal.(string))
+	case utils.SS_DT_STRING_SET:
+		strSet := val.CVal.(map[string]struct{})
+		w.writeUvarint(uint64(len(strSet)))
+		for str := range strSet {
+			w.writeString(str)
+		}
+	default:
+		return fmt.Errorf("cannot encode a running stat of dtype %v", val.Dtype)
+	}
+	return nil
+}
+
+type aggStateReader struct {
+	r *bytes.Reader
+}
+
+func (r *aggStateReader) readUvarint() (uint64, error) {
+	val, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return 0, errBadAggState
+	}
+	return val, nil
+}
+
+func (r *aggStateReader) readVarint() (int64, error) {
+	val, err := binary.ReadVarint(r.r)
+	if err != nil {
+		return 0, errBadAggState
+	}
+	return val, nil
+}
+
+// Reads the number of the elements that follow, which can not be more than the bytes left
+func (r *aggStateReader) readCount() (int, error) {
+	val, err := r.readUvarint()
+	if err != nil {
+		return 0, err
+	}
+	if val > uint64(r.r.Len()) {
+		return 0, errBadAggState
+	}
+	return int(val), nil
+}
+
+func (r *aggStateReader) readBytes() ([]byte, error) {
+	size, err := r.readCount()
+	if err != nil {
+		return nil, err
+	}
+	val := make([]byte, size)
+	_, err = io.ReadFull(r.r, val)
+	if err != nil {
+		return nil, errBadAggState
+	}
+	return val, nil
+}
+
+func (r *aggStateReader) readString() (string, error) {
+	val, err := r.readBytes()
+	if err != nil {
+		return "", err
+	}
+	return string(val), nil
+}
+
+func (r *aggStateReader) readCValue() (utils.CValueEnclosure, error) {
+	dtype, err := r.r.ReadByte()
+	if err != nil {
+		return utils.CValueEnclosure{}, errBadAggState
+	}
+	val := utils.CValueEnclosure{Dtype: utils.SS_DTYPE(dtype)}
+	switch val.Dtype {
+	case utils.SS_INVALID, utils.SS_DT_BACKFILL:
+	case utils.SS_DT_BOOL:
+		b, err := r.r.ReadByte()
+		if err != nil {
+			return val, errBadAggState
+		}
+		val.CVal = toputils.BytesToBoolLittleEndian([]byte{b})
+	case utils.SS_DT_SIGNED_NUM:
+		val.CVal, err = r.readVarint()
+	case utils.SS_DT_UNSIGNED_NUM:
+		val.CVal, err = r.readUvarint()
+	case utils.SS_DT_FLOAT:
+		var floatBytes [8]byte
+		_, err = io.ReadFull(r.r, floatBytes[:])
+		if err != nil {
+			return val, errBadAggState
+		}
+		val.CVal = math.Float64frombits(binary.LittleEndian.Uint64(floatBytes[:]))
+	case utils.SS_DT_STRING:
+		val.CVal, err = r.readString()
+	case utils.SS_DT_STRING_SET:
+		numStrs, err := r.readCount()
+		if err != nil {
+			return val, err
+		}
+		strSet := make(map[string]struct{}, numStrs)
+		for i := 0; i < numStrs; i++ {
+			str, err := r.readString()
+			if err != nil {
+				return val, err
+			}
+			strSet[str] = struct{}{}
+		}
+		val.CVal = strSet
+	default:
+		return val, fmt.Errorf("cannot decode a running stat of dtype %v", val.Dtype)
+	}
+	return val, err
+}
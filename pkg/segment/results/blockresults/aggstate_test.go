@@ -0,0 +1,168 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blockresults
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/siglens/siglens/pkg/segment/structs"
+	"github.com/siglens/siglens/pkg/segment/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AggStateRoundTrip(t *testing.T) {
+	aggs := &structs.QueryAggregators{
+		TimeHistogram: &structs.TimeBucket{},
+		GroupByRequest: &structs.GroupByRequest{
+			GroupByColumns: []string{"a"},
+			MeasureOperations: []*structs.MeasureAggregator{
+				{MeasureCol: "b", MeasureFunc: utils.Min},
+				{MeasureCol: "c", MeasureFunc: utils.Sum},
+				{MeasureCol: "d", MeasureFunc: utils.Cardinality},
+			},
+			BucketCount: 100,
+		},
+	}
+	bRes, err := InitBlockResults(10, aggs, 0)
+	assert.NoError(t, err)
+	for i := uint64(0); i < 10; i++ {
+		var buf bytes.Buffer
+		buf.WriteString(fmt.Sprintf("key-%v", i%3))
+		mRes := []utils.CValueEnclosure{
+			{CVal: int64(i) - 5, Dtype: utils.SS_DT_SIGNED_NUM},
+			{CVal: float64(i) / 2, Dtype: utils.SS_DT_FLOAT},
+			{CVal: i, Dtype: utils.SS_DT_UNSIGNED_NUM},
+		}
+		bRes.AddMeasureResultsToKey(buf, mRes, "", false, 0)
+		bRes.AddKeyToTimeBucket(i%4, 2)
+	}
+	bRes.GroupByAggregation.GroupByColValCnt["key-0"] = 4
+
+	encoded, err := bRes.EncodeAggState()
+	assert.NoError(t, err)
+
+	merged, err := InitBlockResults(10, aggs, 0)
+	assert.NoError(t, err)
+	assert.NoError(t, merged.MergeEncodedAggState(encoded))
+	assert.NoError(t, merged.MergeEncodedAggState(encoded))
+
+	assert.Len(t, merged.TimeAggregation.AllRunningBuckets, 4)
+	for key, idx := range merged.TimeAggregation.UnsignedBucketIdx {
+		expected := bRes.TimeAggregation.AllRunningBuckets[bRes.TimeAggregation.UnsignedBucketIdx[key]].count
+		assert.Equal(t, 2*expected, merged.TimeAggregation.AllRunningBuckets[idx].count)
+	}
+
+	assert.Len(t, merged.GroupByAggregation.AllRunningBuckets, 3)
+	assert.Equal(t, map[string]int{"key-0": 8}, merged.GroupByAggregation.GroupByColValCnt)
+	for key, idx := range bRes.GroupByAggregation.StringBucketIdx {
+		expected := bRes.GroupByAggregation.AllRunningBuckets[idx]
+		mergedIdx, ok := merged.GroupByAggregation.StringBucketIdx[key]
+		assert.True(t, ok)
+		actual := merged.GroupByAggregation.AllRunningBuckets[mergedIdx]
+
+		assert.Equal(t, 2*expected.count, actual.count)
+		assert.Equal(t, expected.runningStats[0].rawVal, actual.runningStats[0].rawVal, "min stays the same")
+		assert.Equal(t, utils.CValueEnclosure{Dtype: utils.SS_DT_FLOAT, CVal: 2 * expected.runningStats[1].rawVal.CVal.(float64)},
+			actual.runningStats[1].rawVal)
+		assert.Equal(t, expected.runningStats[2].hll.Estimate(), actual.runningStats[2].hll.Estimate(), "merging the same hll changes nothing")
+	}
+}
+
+func Test_AggStateTimechartGroups(t *testing.T) {
+	aggs := &structs.QueryAggregators{
+		TimeHistogram: &structs.TimeBucket{Timechart: &structs.TimechartExpr{ByField: "host"}},
+		GroupByRequest: &structs.GroupByRequest{
+			GroupByColumns: []string{"timestamp"},
+			MeasureOperations: []*structs.MeasureAggregator{
+				{MeasureCol: "latency", MeasureFunc: utils.Max},
+				{MeasureCol: "user", MeasureFunc: utils.Values},
+			},
+			BucketCount: 100,
+		},
+	}
+	bRes, err := InitBlockResults(10, aggs, 0)
+	assert.NoError(t, err)
+	for i := uint64(0); i < 6; i++ {
+		var buf bytes.Buffer
+		buf.WriteString("1000")
+		mRes := []utils.CValueEnclosure{
+			{CVal: i, Dtype: utils.SS_DT_UNSIGNED_NUM},
+			{CVal: fmt.Sprintf("user-%v", i), Dtype: utils.SS_DT_STRING},
+		}
+		bRes.AddMeasureResultsToKey(buf, mRes, fmt.Sprintf("host-%v", i%2), true, 0)
+	}
+
+	encoded, err := bRes.EncodeAggState()
+	assert.NoError(t, err)
+	timeBuckets, grpBuckets, err := bRes.DecodeAggState(encoded)
+	assert.NoError(t, err)
+	assert.NotNil(t, timeBuckets)
+	assert.Len(t, grpBuckets.AllRunningBuckets, 1)
+
+	expected := bRes.GroupByAggregation.AllRunningBuckets[0]
+	actual := grpBuckets.AllRunningBuckets[grpBuckets.StringBucketIdx["1000"]]
+	assert.Equal(t, expected.count, actual.count)
+	assert.Len(t, actual.groupedRunningStats, 2)
+	for host, stats := range expected.groupedRunningStats {
+		assert.Equal(t, stats, actual.groupedRunningStats[host])
+	}
+	assert.Equal(t, utils.CValueEnclosure{Dtype: utils.SS_DT_UNSIGNED_NUM, CVal: uint64(5)}, actual.groupedRunningStats["host-1"][0].rawVal)
+	assert.Equal(t, utils.CValueEnclosure{Dtype: utils.SS_DT_STRING_SET, CVal: map[string]struct{}{"user-0": {}, "user-2": {}, "user-4": {}}},
+		actual.groupedRunningStats["host-0"][1].rawVal)
+}
+
+func Test_AggStateRejectsBadInput(t *testing.T) {
+	aggs := &structs.QueryAggregators{
+		GroupByRequest: &structs.GroupByRequest{
+			GroupByColumns:    []string{"a"},
+			MeasureOperations: []*structs.MeasureAggregator{{MeasureCol: "b", MeasureFunc: utils.Max}},
+			BucketCount:       100,
+		},
+	}
+	bRes, err := InitBlockResults(10, aggs, 0)
+	assert.NoError(t, err)
+	var buf bytes.Buffer
+	buf.WriteString("key")
+	bRes.AddMeasureResultsToKey(buf, []utils.CValueEnclosure{{CVal: uint64(1), Dtype: utils.SS_DT_UNSIGNED_NUM}}, "", false, 0)
+	encoded, err := bRes.EncodeAggState()
+	assert.NoError(t, err)
+
+	for size := 0; size < len(encoded); size++ {
+		_, _, err := bRes.DecodeAggState(encoded[:size])
+		assert.Error(t, err, "truncated to %v bytes", size)
+	}
+
+	newerVersion := append([]byte{AGG_STATE_VERSION + 1}, encoded[1:]...)
+	_, _, err = bRes.DecodeAggState(newerVersion)
+	assert.Error(t, err)
+
+	otherAggs := &structs.QueryAggregators{
+		GroupByRequest: &structs.GroupByRequest{
+			GroupByColumns: []string{"a"},
+			MeasureOperations: []*structs.MeasureAggregator{
+				{MeasureCol: "b", MeasureFunc: utils.Max},
+				{MeasureCol: "c", MeasureFunc: utils.Min},
+			},
+			BucketCount: 100,
+		},
+	}
+	otherRes, err := InitBlockResults(10, otherAggs, 0)
+	assert.NoError(t, err)
+	assert.Error(t, otherRes.MergeEncodedAggState(encoded), "the state of a query with other measure functions")
+}
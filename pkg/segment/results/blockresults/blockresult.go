@@ -39,6 +39,7 @@ type GroupByBuckets struct {
 	reverseMeasureIndex []int                        // reverse index, so idx of original measure will store the index in internalMeasureFns. -1 is reserved for count
 	maxBuckets          int                          // maximum number of buckets to create
 	GroupByColValCnt    map[string]int               // calculate freq for group by col val
+	interner            *toputils.StringInterner     // copies of the bucket keys and group by col vals, shared by the block results of a query
 }
 
 type TimeBuckets struct {
@@ -97,6 +98,7 @@ func InitBlockResults(count uint64, aggs *structs.QueryAggregators, qid uint64)
 				reverseMeasureIndex: revIndex,
 				maxBuckets:          aggs.GroupByRequest.BucketCount,
 				GroupByColValCnt:    make(map[string]int),
+				interner:            toputils.NewStringInterner(),
 			}
 		}
 	}
@@ -366,6 +368,7 @@ func (b *BlockResults) AddMeasureResultsToKey(currKey bytes.Buffer, measureResul
 	if b.GroupByAggregation == nil {
 		return
 	}
+	// the key bytes may be reused for the next record, so only the interned copy is kept
 	bKey := toputils.UnsafeByteSliceToString(currKey.Bytes())
 	bucketIdx, ok := b.GroupByAggregation.StringBucketIdx[bKey]
 
@@ -377,7 +380,7 @@ func (b *BlockResults) AddMeasureResultsToKey(currKey bytes.Buffer, measureResul
 		}
 		bucket = initRunningGroupByBucket(b.GroupByAggregation.internalMeasureFns)
 		b.GroupByAggregation.AllRunningBuckets = append(b.GroupByAggregation.AllRunningBuckets, bucket)
-		b.GroupByAggregation.StringBucketIdx[bKey] = nBuckets
+		b.GroupByAggregation.StringBucketIdx[b.GroupByAggregation.internBytes(currKey.Bytes())] = nBuckets
 	} else {
 		bucket = b.GroupByAggregation.AllRunningBuckets[bucketIdx]
 	}
@@ -387,7 +390,7 @@ func (b *BlockResults) AddMeasureResultsToKey(currKey bytes.Buffer, measureResul
 		_, exists := bucket.groupedRunningStats[groupByColVal]
 		if !exists {
 			gRunningStats = initRunningStats(b.GroupByAggregation.internalMeasureFns)
-			bucket.groupedRunningStats[groupByColVal] = gRunningStats
+			bucket.groupedRunningStats[b.GroupByAggregation.intern(groupByColVal)] = gRunningStats
 		}
 		gRunningStats = bucket.groupedRunningStats[groupByColVal]
 		bucket.AddMeasureResults(&gRunningStats, measureResults, qid, 1, true)
@@ -412,13 +415,44 @@ func (b *BlockResults) AddMeasureResultsToKeyAgileTree(bKey string,
 		}
 		bucket = initRunningGroupByBucket(b.GroupByAggregation.internalMeasureFns)
 		b.GroupByAggregation.AllRunningBuckets = append(b.GroupByAggregation.AllRunningBuckets, bucket)
-		b.GroupByAggregation.StringBucketIdx[bKey] = nBuckets
+		b.GroupByAggregation.StringBucketIdx[b.GroupByAggregation.intern(bKey)] = nBuckets
 	} else {
 		bucket = b.GroupByAggregation.AllRunningBuckets[bucketIdx]
 	}
 	bucket.AddMeasureResults(&bucket.runningStats, measureResults, qid, cnt, false)
 }
 
+// Returns the interner of the group by keys, nil if there is no group by
+func (b *BlockResults) GetInterner() *toputils.StringInterner {
+	if b.GroupByAggregation == nil {
+		return nil
+	}
+	return b.GroupByAggregation.interner
+}
+
+// Makes the block results copy the group by keys into the interner of the query, so that the keys seen by every block are shared
+func (b *BlockResults) SetInterner(interner *toputils.StringInterner) {
+	if b == nil || b.GroupByAggregation == nil || interner == nil {
+		return
+	}
+	b.GroupByAggregation.interner = interner
+}
+
+// Returns a copy of the group by key or col val that outlives the bytes
+func (gb *GroupByBuckets) internBytes(val []byte) string {
+	if gb.interner == nil {
+		return string(val)
+	}
+	return gb.interner.InternBytes(val)
+}
+
+func (gb *GroupByBuckets) intern(val string) string {
+	if gb.interner == nil {
+		return val
+	}
+	return gb.interner.Intern(val)
+}
+
 func (b *BlockResults) AddKeyToTimeBucket(bucketKey uint64, count uint16) {
 	if b.TimeAggregation == nil {
 		return
@@ -18,6 +18,7 @@ package blockresults
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/axiomhq/hyperloglog"
 	"github.com/siglens/siglens/pkg/segment/structs"
@@ -37,16 +38,42 @@ type runningStats struct {
 	hll    *hyperloglog.Sketch
 }
 
+// The running stats of the block buckets that were merged into the results of a query are reused by the next blocks
+var runningStatsPool = sync.Pool{
+	New: func() interface{} {
+		slice := make([]runningStats, 0)
+		return &slice
+	},
+}
+
 func initRunningStats(internalMeasureFns []*structs.MeasureAggregator) []runningStats {
-	retVal := make([]runningStats, len(internalMeasureFns))
+	retVal := *runningStatsPool.Get().(*[]runningStats)
+	if cap(retVal) < len(internalMeasureFns) {
+		retVal = make([]runningStats, len(internalMeasureFns))
+	}
+	retVal = retVal[:len(internalMeasureFns)]
 	for i := 0; i < len(internalMeasureFns); i++ {
 		if internalMeasureFns[i].MeasureFunc == utils.Cardinality {
 			retVal[i] = runningStats{hll: hyperloglog.New()}
+		} else {
+			retVal[i] = runningStats{}
 		}
 	}
 	return retVal
 }
 
+// The caller must not use the running stats afterwards, nor merge them anywhere they would be kept
+func returnRunningStats(stats []runningStats) {
+	if cap(stats) == 0 {
+		return
+	}
+	for i := range stats {
+		stats[i] = runningStats{}
+	}
+	stats = stats[:0]
+	runningStatsPool.Put(&stats)
+}
+
 func initRunningGroupByBucket(internalMeasureFns []*structs.MeasureAggregator) *RunningBucketResults {
 
 	return &RunningBucketResults{
@@ -121,7 +148,11 @@ func (rr *RunningBucketResults) AddMeasureResults(runningStats *[]runningStats,
 	rr.count += cnt
 }
 
-// This assumes the order of bucketResults.RunningStats are in the same order, referencing the same measure request
+/*
+This assumes the order of bucketResults.RunningStats are in the same order, referencing the same measure request
+
+The running stats of toJoin that were merged are returned to the pool, so toJoin must not be used afterwards
+*/
 func (rr *RunningBucketResults) MergeRunningBuckets(toJoin *RunningBucketResults) {
 
 	if toJoin == nil {
@@ -132,23 +163,20 @@ func (rr *RunningBucketResults) MergeRunningBuckets(toJoin *RunningBucketResults
 	if toJoin.groupedRunningStats != nil && rr.groupedRunningStats == nil {
 		rr.groupedRunningStats = toJoin.groupedRunningStats
 	} else if rr.groupedRunningStats != nil && len(rr.groupedRunningStats) > 0 {
-		for groupByColVal, runningStats := range rr.groupedRunningStats {
-			toJoinRunningStats, exists := toJoin.groupedRunningStats[groupByColVal]
-			if !exists {
-				continue
-			}
-			rr.mergeRunningStats(&runningStats, toJoinRunningStats)
-		}
-
 		for groupByColVal, toJoinRunningStats := range toJoin.groupedRunningStats {
-			_, exists := rr.groupedRunningStats[groupByColVal]
+			runningStats, exists := rr.groupedRunningStats[groupByColVal]
 			if !exists {
 				rr.groupedRunningStats[groupByColVal] = toJoinRunningStats
+				continue
 			}
+			rr.mergeRunningStats(&runningStats, toJoinRunningStats)
+			returnRunningStats(toJoinRunningStats)
 		}
 	}
 
 	rr.mergeRunningStats(&rr.runningStats, toJoin.runningStats)
+	returnRunningStats(toJoin.runningStats)
+	toJoin.runningStats = nil
 	rr.count += toJoin.count
 }
 
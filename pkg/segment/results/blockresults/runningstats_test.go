@@ -104,3 +104,85 @@ func Test_JoinStats(t *testing.T) {
 		assert.Equal(t, bucket.runningStats[1].rawVal, utils.CValueEnclosure{Dtype: utils.SS_DT_UNSIGNED_NUM, CVal: max}, "max should be i or 1 if i==0")
 	}
 }
+
+func Test_GroupByKeysInterned(t *testing.T) {
+	aggs := &structs.QueryAggregators{
+		GroupByRequest: &structs.GroupByRequest{
+			GroupByColumns: []string{"a"},
+			MeasureOperations: []*structs.MeasureAggregator{
+				{MeasureCol: "c", MeasureFunc: utils.Count},
+			},
+			BucketCount: 100,
+		},
+	}
+	bRes, err := InitBlockResults(10, aggs, 0)
+	assert.NoError(t, err)
+	otherRes, err := InitBlockResults(10, aggs, 0)
+	assert.NoError(t, err)
+	otherRes.SetInterner(bRes.GetInterner())
+	assert.Same(t, bRes.GetInterner(), otherRes.GetInterner())
+
+	mRes := []utils.CValueEnclosure{{CVal: uint64(1), Dtype: utils.SS_DT_UNSIGNED_NUM}}
+	var buf bytes.Buffer
+	for i := 0; i < 10; i++ {
+		// the buffer is reused for every key, the buckets must not keep its bytes
+		buf.Reset()
+		buf.WriteString(fmt.Sprintf("key-%v", i%5))
+		bRes.AddMeasureResultsToKey(buf, mRes, "", false, 0)
+		otherRes.AddMeasureResultsToKey(buf, mRes, "", false, 0)
+	}
+	buf.Reset()
+	buf.WriteString("overwritten")
+
+	assert.Len(t, bRes.GroupByAggregation.StringBucketIdx, 5)
+	for i := 0; i < 5; i++ {
+		key := fmt.Sprintf("key-%v", i)
+		idx, ok := bRes.GroupByAggregation.StringBucketIdx[key]
+		assert.True(t, ok)
+		assert.Equal(t, uint64(2), bRes.GroupByAggregation.AllRunningBuckets[idx].count)
+		_, ok = otherRes.GroupByAggregation.StringBucketIdx[key]
+		assert.True(t, ok)
+	}
+	assert.Equal(t, 5, bRes.GetInterner().Len(), "both block results store their keys in the same interner")
+}
+
+func Test_MergeRecyclesRunningStats(t *testing.T) {
+	aggs := &structs.QueryAggregators{
+		GroupByRequest: &structs.GroupByRequest{
+			GroupByColumns: []string{"a"},
+			MeasureOperations: []*structs.MeasureAggregator{
+				{MeasureCol: "c", MeasureFunc: utils.Sum},
+				{MeasureCol: "d", MeasureFunc: utils.Max},
+			},
+			BucketCount: 100,
+		},
+	}
+	bRes, err := InitBlockResults(10, aggs, 0)
+	assert.NoError(t, err)
+
+	// the running stats of every merged block are reused by the next one, which must start from empty stats
+	for i := uint64(1); i <= 5; i++ {
+		blkRes, err := InitBlockResults(10, aggs, 0)
+		assert.NoError(t, err)
+		var buf bytes.Buffer
+		buf.WriteString("key")
+		mRes := []utils.CValueEnclosure{
+			{CVal: i, Dtype: utils.SS_DT_UNSIGNED_NUM},
+			{CVal: float64(i) + 0.5, Dtype: utils.SS_DT_FLOAT},
+		}
+		blkRes.AddMeasureResultsToKey(buf, mRes, "", false, 0)
+		bRes.MergeBuckets(blkRes)
+	}
+
+	idx, ok := bRes.GroupByAggregation.StringBucketIdx["key"]
+	assert.True(t, ok)
+	bucket := bRes.GroupByAggregation.AllRunningBuckets[idx]
+	assert.Equal(t, uint64(5), bucket.count)
+	assert.Equal(t, utils.CValueEnclosure{Dtype: utils.SS_DT_UNSIGNED_NUM, CVal: uint64(15)}, bucket.runningStats[0].rawVal)
+	assert.Equal(t, utils.CValueEnclosure{Dtype: utils.SS_DT_FLOAT, CVal: 5.5}, bucket.runningStats[1].rawVal)
+
+	mixed, err := utils.Reduce(utils.CValueEnclosure{Dtype: utils.SS_DT_SIGNED_NUM, CVal: int64(-2)},
+		utils.CValueEnclosure{Dtype: utils.SS_DT_FLOAT, CVal: 0.5}, utils.Sum)
+	assert.NoError(t, err)
+	assert.Equal(t, utils.CValueEnclosure{Dtype: utils.SS_DT_FLOAT, CVal: -1.5}, mixed)
+}
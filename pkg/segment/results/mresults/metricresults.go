@@ -215,6 +215,10 @@ func (r *MetricsResult) AggregateResults(parallelism int) []error {
 Apply range function to results for series sharing a groupid.
 */
 func (r *MetricsResult) ApplyRangeFunctionsToResults(parallelism int, function segutils.RangeFunctions) error {
+	if function.IsAppliedPerSeries() {
+		// already applied to each series when downsampling
+		return nil
+	}
 
 	lock := &sync.Mutex{}
 	wg := &sync.WaitGroup{}
@@ -42,6 +42,10 @@ type Series struct {
 	// if original Downsampler Aggregator is `Avg`, convertedDownsampleAggFn is equal to `Sum` else equal to original Downsampler Aggregator
 	convertedDownsampleAggFn utils.AggregateFunctions
 	aggregationConstant      float64
+
+	// Rate, Increase and Delta are computed from the raw datapoints, so entries keep their raw timestamps until Downsample
+	rangeFunction   utils.RangeFunctions
+	rangeWindowSecs uint32
 }
 
 type DownsampleSeries struct {
@@ -65,6 +69,7 @@ type RunningEntry struct {
 type Entry struct {
 	downsampledTime uint32
 	dpVal           float64
+	rollupCount     uint32 // number of raw datapoints of a rollup bucket, 0 if the entry is a raw datapoint
 }
 
 var initial_len = 10
@@ -86,7 +91,7 @@ func InitSeriesHolder(mQuery *structs.MetricsQuery, tsGroupId *bytebufferpool.By
 	aggregationConstant := mQuery.Aggregator.FuncConstant
 
 	retVal := make([]Entry, initial_len, extend_capacity)
-	return &Series{
+	series := &Series{
 		idx:                      0,
 		len:                      initial_len,
 		entries:                  retVal,
@@ -96,6 +101,14 @@ func InitSeriesHolder(mQuery *structs.MetricsQuery, tsGroupId *bytebufferpool.By
 		aggregationConstant:      aggregationConstant,
 		grpID:                    tsGroupId,
 	}
+	if mQuery.Aggregator.RangeFunction.IsAppliedPerSeries() {
+		series.rangeFunction = mQuery.Aggregator.RangeFunction
+		series.rangeWindowSecs = mQuery.Aggregator.RangeWindowSecs
+		if series.rangeWindowSecs == 0 {
+			series.rangeWindowSecs = series.dsSeconds
+		}
+	}
+	return series
 }
 
 func (s *Series) GetIdx() int {
@@ -103,8 +116,42 @@ func (s *Series) GetIdx() int {
 }
 
 func (s *Series) AddEntry(ts uint32, dp float64) {
-	s.entries[s.idx].downsampledTime = (ts / s.dsSeconds) * s.dsSeconds
+	s.addEntryWithCount(ts, dp, 0)
+}
+
+/*
+Adds a bucket of a metrics rollup. The value of the entry is picked by the downsample aggregation, so that
+reducing rollup entries gives the same result as reducing the raw datapoints of the bucket. Aggregations that
+can't be computed from a rollup, such as quantiles, use the average of the bucket
+*/
+func (s *Series) AddRollupEntry(ts uint32, min float64, max float64, sum float64, count uint32) {
+	var dp float64
+	switch {
+	case s.rangeFunction == utils.Rate || s.rangeFunction == utils.Increase:
+		// the max of a bucket is the closest to the counter value at the end of the bucket
+		dp = max
+	case s.rangeFunction == utils.Delta:
+		dp = sum / float64(count)
+	case s.convertedDownsampleAggFn == utils.Sum:
+		dp = sum
+	case s.convertedDownsampleAggFn == utils.Min:
+		dp = min
+	case s.convertedDownsampleAggFn == utils.Max:
+		dp = max
+	default:
+		dp = sum / float64(count)
+	}
+	s.addEntryWithCount(ts, dp, count)
+}
+
+func (s *Series) addEntryWithCount(ts uint32, dp float64, rollupCount uint32) {
+	if s.rangeFunction != 0 {
+		s.entries[s.idx].downsampledTime = ts
+	} else {
+		s.entries[s.idx].downsampledTime = (ts / s.dsSeconds) * s.dsSeconds
+	}
 	s.entries[s.idx].dpVal = dp
+	s.entries[s.idx].rollupCount = rollupCount
 	s.idx++
 	if s.idx >= s.len {
 		if cap(s.entries)-len(s.entries) > 0 {
@@ -130,6 +177,106 @@ func (s *Series) sortEntries() {
 	s.sorted = true
 }
 
+/*
+Replaces the raw entries of the series with one entry per downsample interval holding the rate, increase or
+delta over the range window that ends with the interval.
+
+Like Prometheus, counter resets are detected when a value drops, and the result is extrapolated to the
+boundaries of the window when the first and last datapoints are close enough to them
+*/
+func (s *Series) applyCounterFunction() {
+	entries := s.entries[:s.idx]
+	if len(entries) == 0 {
+		return
+	}
+	isCounter := s.rangeFunction != utils.Delta
+	isRate := s.rangeFunction == utils.Rate
+
+	firstBucket := (entries[0].downsampledTime / s.dsSeconds) * s.dsSeconds
+	lastBucket := (entries[len(entries)-1].downsampledTime / s.dsSeconds) * s.dsSeconds
+	retVal := make([]Entry, 0, (lastBucket-firstBucket)/s.dsSeconds+1)
+	var low, high int
+	for bucket := firstBucket; bucket <= lastBucket; bucket += s.dsSeconds {
+		rangeEnd := bucket + s.dsSeconds
+		var rangeStart uint32
+		if rangeEnd > s.rangeWindowSecs {
+			rangeStart = rangeEnd - s.rangeWindowSecs
+		}
+		// datapoints in [rangeStart, rangeEnd), so that with the default window each interval only sees its own datapoints
+		for low < len(entries) && entries[low].downsampledTime < rangeStart {
+			low++
+		}
+		for high < len(entries) && entries[high].downsampledTime < rangeEnd {
+			high++
+		}
+		val, ok := extrapolatedDelta(entries[low:high], rangeStart, rangeEnd, s.rangeWindowSecs, isCounter, isRate)
+		if ok {
+			retVal = append(retVal, Entry{downsampledTime: bucket, dpVal: val})
+		}
+	}
+	s.entries = retVal
+	s.idx = len(retVal)
+	s.len = len(retVal)
+}
+
+/*
+Returns the increase of a counter, or the delta of a gauge, over the datapoints of a range window, and false
+if there are less than two datapoints. The datapoints need to be sorted by time.
+
+This follows the extrapolatedRate function of Prometheus so results match rate(), increase() and delta() there
+*/
+func extrapolatedDelta(entries []Entry, rangeStart uint32, rangeEnd uint32, windowSecs uint32, isCounter bool, isRate bool) (float64, bool) {
+	if len(entries) < 2 {
+		return 0, false
+	}
+	first := entries[0]
+	last := entries[len(entries)-1]
+	resultValue := last.dpVal - first.dpVal
+	if isCounter {
+		var lastValue float64
+		for i := range entries {
+			if entries[i].dpVal < lastValue {
+				resultValue += lastValue
+			}
+			lastValue = entries[i].dpVal
+		}
+	}
+
+	durationToStart := float64(first.downsampledTime - rangeStart)
+	durationToEnd := float64(rangeEnd - last.downsampledTime)
+	sampledInterval := float64(last.downsampledTime - first.downsampledTime)
+	if sampledInterval == 0 {
+		return 0, false
+	}
+	averageDurationBetweenSamples := sampledInterval / float64(len(entries)-1)
+
+	if isCounter && resultValue > 0 && first.dpVal >= 0 {
+		// counters can't be negative, so don't extrapolate past the time the counter would have been zero
+		durationToZero := sampledInterval * (first.dpVal / resultValue)
+		if durationToZero < durationToStart {
+			durationToStart = durationToZero
+		}
+	}
+
+	extrapolationThreshold := averageDurationBetweenSamples * 1.1
+	extrapolateToInterval := sampledInterval
+	if durationToStart < extrapolationThreshold {
+		extrapolateToInterval += durationToStart
+	} else {
+		extrapolateToInterval += averageDurationBetweenSamples / 2
+	}
+	if durationToEnd < extrapolationThreshold {
+		extrapolateToInterval += durationToEnd
+	} else {
+		extrapolateToInterval += averageDurationBetweenSamples / 2
+	}
+	resultValue = resultValue * (extrapolateToInterval / sampledInterval)
+	if isRate {
+		resultValue = resultValue / float64(windowSecs)
+	}
+	return resultValue, true
+}
+
 func (s *Series) Merge(toJoin *Series) {
 	toJoinEntries := toJoin.entries[:toJoin.idx]
 	s.entries = s.entries[:s.idx]
@@ -143,6 +290,9 @@ func (s *Series) Merge(toJoin *Series) {
 func (s *Series) Downsample(downsampler structs.Downsampler) (*DownsampleSeries, error) {
 	// get downsampled series
 	s.sortEntries()
+	if s.rangeFunction != 0 {
+		s.applyCounterFunction()
+	}
 	ds := initDownsampleSeries(downsampler.Aggregator)
 	for i := 0; i < s.idx; i++ {
 		currDSTime := s.entries[i].downsampledTime
@@ -352,7 +502,13 @@ func reduceEntries(entries []Entry, fn utils.AggregateFunctions, fnConstant floa
 			}
 		}
 	case utils.Count:
-		ret += float64(len(entries))
+		for i := range entries {
+			if entries[i].rollupCount > 0 {
+				ret += float64(entries[i].rollupCount)
+			} else {
+				ret++
+			}
+		}
 	case utils.Quantile: //valid range for fnConstant is 0 <= fnConstant <= 1
 		// TODO: calculate the quantile without needing to sort the elements.
 
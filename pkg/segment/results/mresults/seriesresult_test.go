@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	"github.com/siglens/siglens/pkg/common/dtypeutils"
+	"github.com/siglens/siglens/pkg/segment/structs"
 	segutils "github.com/siglens/siglens/pkg/segment/utils"
 	"github.com/stretchr/testify/assert"
 )
@@ -209,3 +210,99 @@ func Test_reduceRunningEntries(t *testing.T) {
 	_, err = reduceRunningEntries(entries, segutils.Cardinality, functionConstant)
 	assert.NotNil(t, err)
 }
+
+func Test_downsampleRollupEntries(t *testing.T) {
+	for _, tc := range []struct {
+		fn       segutils.AggregateFunctions
+		expected float64
+	}{
+		{segutils.Sum, 16.0},
+		{segutils.Min, 1.0},
+		{segutils.Max, 5.0},
+		{segutils.Count, 5.0},
+	} {
+		mQuery := &structs.MetricsQuery{
+			Downsampler: structs.Downsampler{Interval: 10, Unit: "m", Aggregator: structs.Aggreation{AggregatorFunction: tc.fn}},
+		}
+		series := InitSeriesHolder(mQuery, nil)
+		series.AddRollupEntry(0, 1, 5, 10, 3)
+		series.AddRollupEntry(300, 2, 4, 6, 2)
+
+		ds, err := series.Downsample(mQuery.Downsampler)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, ds.idx)
+		assert.Equal(t, uint32(0), ds.runningEntries[0].downsampledTime)
+		assert.Equal(t, tc.expected, ds.runningEntries[0].runningVal)
+	}
+}
+
+func Test_extrapolatedDelta(t *testing.T) {
+	entries := []Entry{
+		{downsampledTime: 0, dpVal: 0},
+		{downsampledTime: 15, dpVal: 10},
+		{downsampledTime: 30, dpVal: 20},
+		{downsampledTime: 45, dpVal: 30},
+	}
+	// the datapoints cover 45s of the 60s window, the end is extrapolated by one sample interval
+	val, ok := extrapolatedDelta(entries, 0, 60, 60, true, false)
+	assert.True(t, ok)
+	assert.True(t, dtypeutils.AlmostEquals(val, 40.0))
+
+	val, ok = extrapolatedDelta(entries, 0, 60, 60, true, true)
+	assert.True(t, ok)
+	assert.True(t, dtypeutils.AlmostEquals(val, 40.0/60))
+
+	// the drop from 10 to 2 is a counter reset
+	entries[2].dpVal = 2
+	entries[3].dpVal = 12
+	val, ok = extrapolatedDelta(entries, 0, 60, 60, true, false)
+	assert.True(t, ok)
+	assert.True(t, dtypeutils.AlmostEquals(val, 22.0*60/45))
+
+	// gauges have no resets
+	val, ok = extrapolatedDelta(entries, 0, 60, 60, false, false)
+	assert.True(t, ok)
+	assert.True(t, dtypeutils.AlmostEquals(val, 12.0*60/45))
+
+	_, ok = extrapolatedDelta(entries[:1], 0, 60, 60, true, false)
+	assert.False(t, ok)
+}
+
+func Test_seriesCounterFunctions(t *testing.T) {
+	mQuery := &structs.MetricsQuery{
+		Aggregator:  structs.Aggreation{RangeFunction: segutils.Increase, RangeWindowSecs: 120},
+		Downsampler: structs.Downsampler{Interval: 1, Unit: "m", Aggregator: structs.Aggreation{AggregatorFunction: segutils.Sum}},
+	}
+	series := InitSeriesHolder(mQuery, nil)
+	// out of order datapoints of a counter that increases by 1 every 10s
+	for i := 11; i >= 0; i-- {
+		series.AddEntry(uint32(1000+i*10), float64(100+i))
+	}
+	ds, err := series.Downsample(mQuery.Downsampler)
+	assert.NoError(t, err)
+
+	entries := ds.runningEntries[:ds.idx]
+	assert.Len(t, entries, 3)
+	assert.Equal(t, uint32(960), entries[0].downsampledTime)
+	assert.Equal(t, uint32(1020), entries[1].downsampledTime)
+	assert.Equal(t, uint32(1080), entries[2].downsampledTime)
+
+	// window [900, 1020) has the datapoints at 1000 and 1010. The start is too far away and is extrapolated
+	// by half a sample interval, the end is extrapolated to the end of the window
+	assert.True(t, dtypeutils.AlmostEquals(entries[0].runningVal, 1.0*25/10))
+	// window [960, 1080) has the datapoints from 1000 to 1070
+	assert.True(t, dtypeutils.AlmostEquals(entries[1].runningVal, 7.0*85/70))
+	// window [1020, 1140) starts with a datapoint and ends 30s after the last one
+	assert.True(t, dtypeutils.AlmostEquals(entries[2].runningVal, 9.0*95/90))
+
+	// a reset within a window isn't seen as a decrease
+	mQuery.Aggregator = structs.Aggreation{RangeFunction: segutils.Rate}
+	series = InitSeriesHolder(mQuery, nil)
+	series.AddEntry(1020, 5)
+	series.AddEntry(1040, 2)
+	series.AddEntry(1060, 8)
+	ds, err = series.Downsample(mQuery.Downsampler)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, ds.idx)
+	assert.True(t, dtypeutils.AlmostEquals(ds.runningEntries[0].runningVal, 8.0*60/40/60))
+}
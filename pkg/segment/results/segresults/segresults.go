@@ -32,6 +32,7 @@ import (
 	"github.com/siglens/siglens/pkg/segment/structs"
 	"github.com/siglens/siglens/pkg/segment/utils"
 	"github.com/siglens/siglens/pkg/segment/writer/stats"
+	toputils "github.com/siglens/siglens/pkg/utils"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -153,6 +154,16 @@ func (sr *SearchResults) ShouldContinueRRCSearch() bool {
 	return sr.resultCount <= sr.sizeLimit
 }
 
+// Returns the interner of the group by keys, which the block results of the query share. Nil if there is no group by
+func (sr *SearchResults) GetGroupByInterner() *toputils.StringInterner {
+	sr.updateLock.Lock()
+	defer sr.updateLock.Unlock()
+	if sr.BlockResults == nil {
+		return nil
+	}
+	return sr.BlockResults.GetInterner()
+}
+
 // Adds local results to the search results
 func (sr *SearchResults) AddBlockResults(blockRes *blockresults.BlockResults) {
 	sr.updateLock.Lock()
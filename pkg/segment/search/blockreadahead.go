@@ -0,0 +1,79 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package search
+
+import (
+	"github.com/siglens/siglens/pkg/segment/reader/segread"
+	"github.com/siglens/siglens/pkg/segment/workerpool"
+)
+
+/*
+Hands the blocks of a channel to a block worker one at a time. The block after the current one is taken off the
+channel early so that its columns are read from disk while the current block is searched, and the worker holds a
+cpu worker of the pool while it searches a block
+*/
+type blockReadAhead struct {
+	blockChan   chan *BlockSearchStatus
+	multiReader *segread.MultiColSegmentReader // nil if the blocks are searched without reading their columns
+	next        *BlockSearchStatus
+	holdsCPU    bool
+}
+
+func newBlockReadAhead(blockChan chan *BlockSearchStatus, multiReader *segread.MultiColSegmentReader) *blockReadAhead {
+	readAhead := &blockReadAhead{
+		blockChan:   blockChan,
+		multiReader: multiReader,
+	}
+	readAhead.next = readAhead.receive()
+	return readAhead
+}
+
+func (ra *blockReadAhead) receive() *BlockSearchStatus {
+	blockStatus, ok := <-ra.blockChan
+	if !ok {
+		return nil
+	}
+	if ra.multiReader != nil {
+		ra.multiReader.PrefetchBlock(blockStatus.BlockNum)
+	}
+	return blockStatus
+}
+
+// Returns the block to search next, or nil once the channel is closed and drained
+func (ra *blockReadAhead) nextBlock() *BlockSearchStatus {
+	ra.releaseCPU()
+	curr := ra.next
+	if curr == nil {
+		return nil
+	}
+	ra.next = ra.receive()
+	workerpool.AcquireCPUWorker()
+	ra.holdsCPU = true
+	return curr
+}
+
+// Releases the cpu worker of the last block, must be called once the worker stops searching
+func (ra *blockReadAhead) close() {
+	ra.releaseCPU()
+}
+
+func (ra *blockReadAhead) releaseCPU() {
+	if ra.holdsCPU {
+		workerpool.ReleaseCPUWorker()
+		ra.holdsCPU = false
+	}
+}
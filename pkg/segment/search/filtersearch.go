@@ -92,8 +92,16 @@ func filterBlockRequestFromQuery(multiColReader *segread.MultiColSegmentReader,
 
 	defer runningBlockManagers.Done() // defer in case of panics
 
+	// only the column value queries read the columns of the blocks
+	var readAheadReader *segread.MultiColSegmentReader
+	if queryType == structs.ColumnValueQuery {
+		readAheadReader = multiColReader
+	}
+	readAhead := newBlockReadAhead(resultsChan, readAheadReader)
+	defer readAhead.close()
+
 	holderDte := &utils.DtypeEnclosure{}
-	for blockReq := range resultsChan {
+	for blockReq := readAhead.nextBlock(); blockReq != nil; blockReq = readAhead.nextBlock() {
 		blockHelper.ResetBlockHelper()
 		recIT, err := segmentSearch.GetRecordIteratorForBlock(op, blockReq.BlockNum)
 		if err != nil {
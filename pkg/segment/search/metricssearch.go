@@ -29,6 +29,7 @@ import (
 	tsidtracker "github.com/siglens/siglens/pkg/segment/results/mresults/tsid"
 	"github.com/siglens/siglens/pkg/segment/structs"
 	"github.com/siglens/siglens/pkg/segment/utils"
+	"github.com/siglens/siglens/pkg/segment/writer/metrics/rollup"
 	"github.com/siglens/siglens/pkg/utils/semaphore"
 	log "github.com/sirupsen/logrus"
 )
@@ -67,6 +68,11 @@ func RawSearchMetricsSegment(mQuery *structs.MetricsQuery, tsidInfo *tsidtracker
 		return
 	}
 
+	if req.RollupResolution > 0 {
+		searchRollup(mQuery, tsidInfo, req, res, timeRange, qid, querySummary)
+		return
+	}
+
 	sharedBlockIterators, err := series.InitSharedTimeSeriesSegmentReader(req.MetricsKeyBaseDir, int(req.Parallelism))
 	if err != nil {
 		log.Errorf("qid=%d, RawSearchMetricsSegment: Error initialising a time series reader. Error: %v", qid, err)
@@ -142,3 +148,42 @@ func blockWorker(workerID int, sharedReader *series.TimeSeriesSegmentReader, blo
 	queryMetrics.IncrementNumMetricsSegmentsSearched(1)
 	querySummary.UpdateMetricsSummary(queryMetrics)
 }
+
+func searchRollup(mQuery *structs.MetricsQuery, tsidInfo *tsidtracker.AllMatchedTSIDs, req *structs.MetricsSearchRequest, res *mresults.MetricsResult,
+	timeRange *dtu.MetricsTimeRange, qid uint64, querySummary *summary.QuerySummary) {
+	queryMetrics := &structs.MetricsQueryProcessingMetrics{
+		UpdateLock: &sync.Mutex{},
+	}
+	rollupReader, err := rollup.ReadRollupFile(req.MetricsKeyBaseDir, req.RollupResolution)
+	if err != nil {
+		log.Errorf("qid=%d, searchRollup: failed to read the %vs rollup of %v. Error: %v", qid, req.RollupResolution, req.MetricsKeyBaseDir, err)
+		res.AddError(err)
+		return
+	}
+
+	localRes := mresults.InitMetricResults(mQuery, qid)
+	for tsid, tsGroupId := range tsidInfo.GetAllTSIDs() {
+		queryMetrics.IncrementNumSeriesSearched(1)
+		buckets, found := rollupReader.GetBuckets(tsid)
+		if !found {
+			continue
+		}
+		series := mresults.InitSeriesHolder(mQuery, tsGroupId)
+		for _, bucket := range buckets {
+			if !timeRange.CheckInRange(bucket.Ts) {
+				continue
+			}
+			series.AddRollupEntry(bucket.Ts, bucket.Min, bucket.Max, bucket.Sum, bucket.Count)
+		}
+		if series.GetIdx() > 0 {
+			localRes.AddSeries(series, tsid, tsGroupId)
+		}
+	}
+	err = res.Merge(localRes)
+	if err != nil {
+		res.AddError(err)
+		log.Errorf("qid=%d, searchRollup: failed to merge local results to global results!", qid)
+	}
+	queryMetrics.IncrementNumMetricsSegmentsSearched(1)
+	querySummary.UpdateMetricsSummary(queryMetrics)
+}
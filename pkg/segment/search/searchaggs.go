@@ -18,9 +18,11 @@ package search
 
 import (
 	"bytes"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"sort"
+	"strconv"
 	"sync"
 
 	"github.com/axiomhq/hyperloglog"
@@ -118,9 +120,12 @@ func applyAggregationsSingleBlock(multiReader *segread.MultiColSegmentReader, ag
 		log.Errorf("applyAggregationsSingleBlock: failed to initialize block results reader for %s. Err: %v", searchReq.SegmentKey, err)
 		allSearchResults.AddError(err)
 	}
+	blkResults.SetInterner(allSearchResults.GetGroupByInterner())
 	defer wg.Done()
 
-	for blockStatus := range blockChan {
+	readAhead := newBlockReadAhead(blockChan, multiReader)
+	defer readAhead.close()
+	for blockStatus := readAhead.nextBlock(); blockStatus != nil; blockStatus = readAhead.nextBlock() {
 		if !blockStatus.hasAnyMatched {
 			continue
 		}
@@ -180,12 +185,17 @@ func addRecordToAggregations(grpReq *structs.GroupByRequest, timeHistogram *stru
 		timeRangeBuckets = aggregations.GenerateTimeRangeBuckets(timeHistogram)
 		hasLimitOption = timeHistogram.Timechart.LimitExpr != nil
 	}
+	interner := blockRes.GetInterner()
+	var currKey bytes.Buffer
+	var rawVal utils.CValueEnclosure
+	var timePointKey [9]byte
+	copy(timePointKey[:], utils.VALTYPE_ENC_UINT64[:])
 	for recNum := uint16(0); recNum < recIT.AllRecLen; recNum++ {
 		if !recIT.ShouldProcessRecord(uint(recNum)) {
 			continue
 		}
 
-		var currKey bytes.Buffer
+		currKey.Reset()
 		groupByColVal := ""
 
 		if usedByTimechart {
@@ -200,10 +210,8 @@ func addRecordToAggregations(grpReq *structs.GroupByRequest, timeHistogram *stru
 			}
 			timePoint := aggregations.FindTimeRangeBucket(timeRangeBuckets, ts, timeHistogram.IntervalMillis)
 
-			retVal := make([]byte, 9)
-			copy(retVal[0:], utils.VALTYPE_ENC_UINT64[:])
-			copy(retVal[1:], toputils.Uint64ToBytesLittleEndian(timePoint))
-			currKey.Write(retVal)
+			binary.LittleEndian.PutUint64(timePointKey[1:], timePoint)
+			currKey.Write(timePointKey[:])
 
 			// Get timechart's group by col val, each different val will be a bucket inside each time range bucket
 			byField := timeHistogram.Timechart.ByField
@@ -212,15 +220,7 @@ func addRecordToAggregations(grpReq *structs.GroupByRequest, timeHistogram *stru
 				if err != nil {
 					log.Errorf("addRecordToAggregations: Failed to get key for column %v: %v", byField, err)
 				} else {
-					strs, err := utils.ConvertGroupByKey(rawVal)
-					if err != nil {
-						log.Errorf("addRecordToAggregations: failed to extract raw key: %v", err)
-					}
-					if len(strs) == 1 {
-						groupByColVal = strs[0]
-					} else {
-						log.Errorf("addRecordToAggregations: invalid length of groupByColVal")
-					}
+					groupByColVal = getInternedGroupByColVal(rawVal, interner)
 				}
 				if hasLimitOption {
 					cnt, exists := groupByColValCnt[groupByColVal]
@@ -244,13 +244,13 @@ func addRecordToAggregations(grpReq *structs.GroupByRequest, timeHistogram *stru
 		}
 
 		for cName, indices := range measureInfo {
-			rawVal, err := multiColReader.ExtractValueFromColumnFile(cName, blockNum, recNum, qid)
+			err := multiColReader.ExtractValueIntoCval(cName, blockNum, recNum, qid, &rawVal)
 			if err != nil {
 				log.Errorf("addRecordToAggregations: Failed to extract measure value from column %+v: %v", cName, err)
-				rawVal = &utils.CValueEnclosure{Dtype: utils.SS_DT_BACKFILL}
+				rawVal = utils.CValueEnclosure{Dtype: utils.SS_DT_BACKFILL}
 			}
 			for _, idx := range indices {
-				measureResults[idx] = *rawVal
+				measureResults[idx] = rawVal
 			}
 		}
 		blockRes.AddMeasureResultsToKey(currKey, measureResults, groupByColVal, usedByTimechart, qid)
@@ -264,6 +264,28 @@ func addRecordToAggregations(grpReq *structs.GroupByRequest, timeHistogram *stru
 	}
 }
 
+// Returns the split-by value of a record, the records with the same value share the copy of it in the interner
+func getInternedGroupByColVal(rawVal []byte, interner *toputils.StringInterner) string {
+	if interner != nil && len(rawVal) >= 3 && rawVal[0] == utils.VALTYPE_ENC_SMALL_STRING[0] {
+		strLen := int(toputils.BytesToUint16LittleEndian(rawVal[1:3]))
+		if len(rawVal) == 3+strLen {
+			return interner.InternBytes(rawVal[3:])
+		}
+	}
+	strs, err := utils.ConvertGroupByKey(rawVal)
+	if err != nil {
+		log.Errorf("addRecordToAggregations: failed to extract raw key: %v", err)
+	}
+	if len(strs) != 1 {
+		log.Errorf("addRecordToAggregations: invalid length of groupByColVal")
+		return ""
+	}
+	if interner == nil {
+		return strs[0]
+	}
+	return interner.Intern(strs[0])
+}
+
 // returns all columns in aggs and the timestamp column
 func GetAggColsAndTimestamp(aggs *structs.QueryAggregators) (map[string]bool, map[string]utils.AggColUsageMode, map[string]bool) {
 	aggCols := make(map[string]bool)
@@ -338,6 +360,7 @@ func applyAggsSingleBlockFastPath(aggs *structs.QueryAggregators,
 		log.Errorf("applyAggsSingleBlockFastPath: failed to initialize block results reader for %s. Err: %v", searchReq.SegmentKey, err)
 		allSearchResults.AddError(err)
 	}
+	blkResults.SetInterner(allSearchResults.GetGroupByInterner())
 
 	defer wg.Done()
 
@@ -378,9 +401,10 @@ func applySegStatsToMatchedRecords(ops []*structs.MeasureAggregator, segmentSear
 
 	statRes := segresults.InitStatsResults()
 	delete(measureColAndTS, config.GetTimeStampKey())
+	cardinalityCols := getCardinalityCols(ops)
 	for i := int64(0); i < fileParallelism; i++ {
 		blkWG.Add(1)
-		go segmentStatsWorker(statRes, measureColAndTS, aggColUsage, valuesUsage, sharedReader.MultiColReaders[i], allBlocksChan,
+		go segmentStatsWorker(statRes, measureColAndTS, aggColUsage, valuesUsage, cardinalityCols, sharedReader.MultiColReaders[i], allBlocksChan,
 			searchReq, blockSummaries, queryRange, &blkWG, queryMetrics, qid)
 	}
 
@@ -415,8 +439,19 @@ func getSegStatsMeasureCols(ops []*structs.MeasureAggregator) (map[string]bool,
 	return aggCols, aggColUsage, valuesUsage
 }
 
+// returns the columns whose cardinality is computed by the measure operations
+func getCardinalityCols(ops []*structs.MeasureAggregator) map[string]bool {
+	cardinalityCols := make(map[string]bool)
+	for _, op := range ops {
+		if op.MeasureFunc == utils.Cardinality {
+			cardinalityCols[op.MeasureCol] = true
+		}
+	}
+	return cardinalityCols
+}
+
 func segmentStatsWorker(statRes *segresults.StatsResults, mCols map[string]bool, aggColUsage map[string]utils.AggColUsageMode, valuesUsage map[string]bool,
-	multiReader *segread.MultiColSegmentReader, blockChan chan *BlockSearchStatus, searchReq *structs.SegmentSearchRequest, blockSummaries []*structs.BlockSummary,
+	cardinalityCols map[string]bool, multiReader *segread.MultiColSegmentReader, blockChan chan *BlockSearchStatus, searchReq *structs.SegmentSearchRequest, blockSummaries []*structs.BlockSummary,
 	queryRange *dtu.TimeRange, wg *sync.WaitGroup, queryMetrics *structs.QueryProcessingMetrics, qid uint64) {
 
 	defer wg.Done()
@@ -424,7 +459,11 @@ func segmentStatsWorker(statRes *segresults.StatsResults, mCols map[string]bool,
 	defer bbp.Put(bb)
 
 	localStats := make(map[string]*structs.SegStats)
-	for blockStatus := range blockChan {
+	intVals := make([]int64, 0)
+	fltVals := make([]float64, 0)
+	readAhead := newBlockReadAhead(blockChan, multiReader)
+	defer readAhead.close()
+	for blockStatus := readAhead.nextBlock(); blockStatus != nil; blockStatus = readAhead.nextBlock() {
 		isBlkFullyEncosed := queryRange.AreTimesFullyEnclosed(blockSummaries[blockStatus.BlockNum].LowTs,
 			blockSummaries[blockStatus.BlockNum].HighTs)
 		recIT, err := blockStatus.GetRecordIteratorForBlock(utils.And)
@@ -457,9 +496,12 @@ func segmentStatsWorker(statRes *segresults.StatsResults, mCols map[string]bool,
 		}
 		sortedMatchedRecs = sortedMatchedRecs[:idx]
 		nonDeCols := applySegmentStatsUsingDictEncoding(multiReader, sortedMatchedRecs, mCols, aggColUsage, valuesUsage, blockStatus.BlockNum, recIT, localStats, bb, qid)
+		intVals, fltVals = applySegmentStatsToNumericBlocks(multiReader, sortedMatchedRecs, nonDeCols, aggColUsage, valuesUsage,
+			cardinalityCols, blockStatus.BlockNum, localStats, intVals, fltVals, bb, qid)
+		var val utils.CValueEnclosure
 		for _, recNum := range sortedMatchedRecs {
 			for colName := range nonDeCols {
-				val, err := multiReader.ExtractValueFromColumnFile(colName, blockStatus.BlockNum, recNum, qid)
+				err := multiReader.ExtractValueIntoCval(colName, blockStatus.BlockNum, recNum, qid, &val)
 				if err != nil {
 					log.Errorf("qid=%d, segmentStatsWorker failed to extract value for column %+v. Err: %v", qid, colName, err)
 					continue
@@ -477,6 +519,11 @@ func segmentStatsWorker(statRes *segresults.StatsResults, mCols map[string]bool,
 						continue
 					}
 					stats.AddSegStatsStr(localStats, colName, str, bb, aggColUsage, hasValuesFunc)
+				} else if intVal, ok := val.CVal.(int64); ok && val.Dtype == utils.SS_DT_SIGNED_NUM {
+					// keep integers as integers so their stats and cardinality match the ones of the batch path
+					stats.AddSegStatsNums(localStats, colName, utils.SS_INT64, intVal, 0, 0, strconv.FormatInt(intVal, 10), bb, aggColUsage, hasValuesFunc)
+				} else if uintVal, ok := val.CVal.(uint64); ok && val.Dtype == utils.SS_DT_UNSIGNED_NUM {
+					stats.AddSegStatsNums(localStats, colName, utils.SS_UINT64, 0, uintVal, 0, strconv.FormatUint(uintVal, 10), bb, aggColUsage, hasValuesFunc)
 				} else {
 					fVal, err := val.GetFloatValue()
 					if err != nil {
@@ -491,6 +538,42 @@ func segmentStatsWorker(statRes *segresults.StatsResults, mCols map[string]bool,
 	statRes.MergeSegStats(localStats)
 }
 
+/*
+Adds the stats of the columns whose values in the block are all numbers with a batch kernel, and removes them from
+nonDeCols. Blocks of integers keep their integer stats, blocks with a float are added as floats. Columns with eval
+or values usage, and blocks with other values, are left to the per record path.
+Returns intVals and fltVals to be reused for the next block
+*/
+func applySegmentStatsToNumericBlocks(mcr *segread.MultiColSegmentReader, filterdRecNums []uint16, nonDeCols map[string]bool,
+	aggColUsage map[string]utils.AggColUsageMode, valuesUsage map[string]bool, cardinalityCols map[string]bool,
+	blockNum uint16, lStats map[string]*structs.SegStats, intVals []int64, fltVals []float64, bb *bbp.ByteBuffer,
+	qid uint64) ([]int64, []float64) {
+	for colName := range nonDeCols {
+		if colUsage, exists := aggColUsage[colName]; exists && colUsage != utils.NoEvalUsage {
+			continue
+		}
+		if valuesUsage[colName] {
+			continue
+		}
+		var isNumeric bool
+		var err error
+		intVals, fltVals, isNumeric, err = mcr.ReadNumericValuesFromColumnFile(colName, blockNum, filterdRecNums,
+			intVals[:0], fltVals[:0])
+		if err != nil {
+			log.Debugf("qid=%d, applySegmentStatsToNumericBlocks: failed to read the values of column %v in a batch. Err: %v",
+				qid, colName, err)
+			continue
+		}
+		if !isNumeric {
+			continue
+		}
+		stats.AddSegStatsIntsBatch(lStats, colName, intVals, bb, cardinalityCols[colName])
+		stats.AddSegStatsNumsBatch(lStats, colName, fltVals, bb, cardinalityCols[colName])
+		delete(nonDeCols, colName)
+	}
+	return intVals, fltVals
+}
+
 // returns all columns that are not dict encoded
 func applySegmentStatsUsingDictEncoding(mcr *segread.MultiColSegmentReader, filterdRecNums []uint16, mCols map[string]bool, aggColUsage map[string]utils.AggColUsageMode, valuesUsage map[string]bool,
 	blockNum uint16, bri *BlockRecordIterator, lStats map[string]*structs.SegStats, bb *bbp.ByteBuffer, qid uint64) map[string]bool {
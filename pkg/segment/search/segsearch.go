@@ -312,11 +312,12 @@ func RawSearchPQMResults(req *structs.SegmentSearchRequest, fileParallelism int6
 	}
 	defer segread.ReturnTimeBuffers(allTimestamps)
 
-	sharedReader, err := segread.InitSharedMultiColumnReaders(req.SegmentKey, req.AllPossibleColumns, req.AllBlocksToSearch,
+	colsToRead := getColsNeededForPQSSearch(aggs, req.AllPossibleColumns)
+	sharedReader, err := segread.InitSharedMultiColumnReaders(req.SegmentKey, colsToRead, req.AllBlocksToSearch,
 		req.SearchMetadata.BlockSummaries, int(fileParallelism), qid)
 	if err != nil {
 		log.Errorf("qid=%v, RawSearchPQMResults: failed to load all column files reader for %s. Needed cols %+v. Err: %+v",
-			qid, req.SegmentKey, req.AllPossibleColumns, err)
+			qid, req.SegmentKey, colsToRead, err)
 		allSearchResults.AddError(err)
 		return
 	}
@@ -360,6 +361,21 @@ func RawSearchPQMResults(req *structs.SegmentSearchRequest, fileParallelism int6
 	querySummary.UpdateSummary(summary.PQS, timeElapsed, queryMetrics)
 }
 
+/*
+The PQS search only reads the sort column and the columns of the aggregations, the records themselves are read later
+by the record reader. So the other columns of the segment, like _raw, are not downloaded or opened
+*/
+func getColsNeededForPQSSearch(aggs *structs.QueryAggregators, allCols map[string]bool) map[string]bool {
+	aggCols, _, _ := GetAggColsAndTimestamp(aggs)
+	neededCols := make(map[string]bool, len(aggCols))
+	for cname := range aggCols {
+		if allCols[cname] {
+			neededCols[cname] = true
+		}
+	}
+	return neededCols
+}
+
 func rawSearchSingleSPQMR(multiReader *segread.MultiColSegmentReader, req *structs.SegmentSearchRequest, aggs *structs.QueryAggregators,
 	runningWG *sync.WaitGroup, filterBlockRequestsChan chan uint16, sqpmr *pqmr.SegmentPQMRResults, allSearchResults *segresults.SearchResults,
 	allTimestamps map[uint16][]uint64, tRange *dtu.TimeRange, sizeLimit uint64, queryMetrics *structs.QueryProcessingMetrics,
@@ -367,6 +383,7 @@ func rawSearchSingleSPQMR(multiReader *segread.MultiColSegmentReader, req *struc
 	defer runningWG.Done()
 
 	blkResults, err := blockresults.InitBlockResults(sizeLimit, aggs, qid)
+	blkResults.SetInterner(allSearchResults.GetGroupByInterner())
 	measureInfo, internalMops := blkResults.GetConvertedMeasureInfo()
 	for blockNum := range filterBlockRequestsChan {
 		if req.SearchMetadata == nil || int(blockNum) >= len(req.SearchMetadata.BlockSummaries) {
@@ -422,9 +422,9 @@ func testAggsQuery(t *testing.T, numEntriesForBuffer int, searchReq *structs.Seg
 	key6Block0Stats := block0["key6"]
 	assert.True(t, key6Block0Stats.IsNumeric)
 	assert.Equal(t, key6Block0Stats.Count, uint64(numEntriesForBuffer))
-	assert.Equal(t, key6Block0Stats.NumStats.Dtype, utils.SS_DT_FLOAT)
-	assert.Equal(t, key6Block0Stats.NumStats.Min.FloatVal, float64(0))
-	assert.Equal(t, key6Block0Stats.NumStats.Max.FloatVal, float64(numEntriesForBuffer-1)*2)
+	assert.Equal(t, key6Block0Stats.NumStats.Dtype, utils.SS_DT_SIGNED_NUM)
+	assert.Equal(t, key6Block0Stats.NumStats.Min.IntgrVal, int64(0))
+	assert.Equal(t, key6Block0Stats.NumStats.Max.IntgrVal, int64(numEntriesForBuffer-1)*2)
 }
 
 type BenchQueryConds struct {
@@ -616,3 +616,21 @@ func createBenchQuery(b *testing.B, segKey string,
 	return node, searchReq, fullTimeRange, agg
 
 }
+
+func Test_getColsNeededForPQSSearch(t *testing.T) {
+	config.InitializeDefaultConfig()
+	allCols := map[string]bool{"timestamp": true, "_raw": true, "app": true, "latency": true, "status": true}
+
+	aggs := &QueryAggregators{
+		GroupByRequest: &GroupByRequest{
+			GroupByColumns:    []string{"app", "missing"},
+			MeasureOperations: []*MeasureAggregator{{MeasureCol: "latency", MeasureFunc: Avg}},
+		},
+	}
+	assert.Equal(t, map[string]bool{"timestamp": true, "app": true, "latency": true}, getColsNeededForPQSSearch(aggs, allCols))
+
+	aggs = &QueryAggregators{Sort: &SortRequest{ColName: "status"}}
+	assert.Equal(t, map[string]bool{"timestamp": true, "status": true}, getColsNeededForPQSSearch(aggs, allCols))
+
+	assert.Equal(t, map[string]bool{"timestamp": true}, getColsNeededForPQSSearch(nil, allCols))
+}
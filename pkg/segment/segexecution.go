@@ -29,14 +29,24 @@ import (
 	"github.com/siglens/siglens/pkg/segment/results/mresults"
 	"github.com/siglens/siglens/pkg/segment/structs"
 	"github.com/siglens/siglens/pkg/segment/utils"
+	"github.com/siglens/siglens/pkg/usageStats"
 
 	log "github.com/sirupsen/logrus"
 )
 
 func ExecuteMetricsQuery(mQuery *structs.MetricsQuery, timeRange *dtu.MetricsTimeRange, qid uint64) *mresults.MetricsResult {
+	err := usageStats.AcquireQuerySlot(mQuery.OrgId)
+	if err != nil {
+		log.Infof("qid=%d, ExecuteMetricsQuery: rejecting the query, %v", qid, err)
+		return &mresults.MetricsResult{
+			ErrList: []error{err},
+		}
+	}
+	defer usageStats.ReleaseQuerySlot(mQuery.OrgId)
+
 	querySummary := summary.InitQuerySummary(summary.METRICS, qid)
 	defer querySummary.LogMetricsQuerySummary(mQuery.OrgId)
-	_, err := query.StartQuery(qid, false)
+	_, err = query.StartQuery(qid, false)
 	if err != nil {
 		log.Errorf("ExecuteAsyncQuery: Error initializing query status! %+v", err)
 		return &mresults.MetricsResult{
@@ -52,10 +62,18 @@ func ExecuteMetricsQuery(mQuery *structs.MetricsQuery, timeRange *dtu.MetricsTim
 func ExecuteMultipleMetricsQuery(hashList []uint64, mQueries []*structs.MetricsQuery, queryOps []structs.QueryArithmetic, timeRange *dtu.MetricsTimeRange, qid uint64) *mresults.MetricsResult {
 	resMap := make(map[uint64]*mresults.MetricsResult)
 	for index, mQuery := range mQueries {
+		err := usageStats.AcquireQuerySlot(mQuery.OrgId)
+		if err != nil {
+			log.Infof("qid=%d, ExecuteMultipleMetricsQuery: rejecting the query, %v", qid, err)
+			return &mresults.MetricsResult{
+				ErrList: []error{err},
+			}
+		}
 		querySummary := summary.InitQuerySummary(summary.METRICS, qid)
 		defer querySummary.LogMetricsQuerySummary(mQuery.OrgId)
-		_, err := query.StartQuery(qid, false)
+		_, err = query.StartQuery(qid, false)
 		if err != nil {
+			usageStats.ReleaseQuerySlot(mQuery.OrgId)
 			log.Errorf("ExecuteAsyncQuery: Error initializing query status! %+v", err)
 			return &mresults.MetricsResult{
 				ErrList: []error{err},
@@ -63,6 +81,7 @@ func ExecuteMultipleMetricsQuery(hashList []uint64, mQueries []*structs.MetricsQ
 		}
 		res := query.ApplyMetricsQuery(mQuery, timeRange, qid, querySummary)
 		query.DeleteQuery(qid)
+		usageStats.ReleaseQuerySlot(mQuery.OrgId)
 		querySummary.IncrementNumResultSeries(res.GetNumSeries())
 		qid = rutils.GetNextQid()
 		resMap[hashList[index]] = res
@@ -144,6 +163,14 @@ func helperQueryArithmetic(queryOps []structs.QueryArithmetic, resMap map[uint64
 }
 
 func ExecuteQuery(root *structs.ASTNode, aggs *structs.QueryAggregators, qid uint64, qc *structs.QueryContext) *structs.NodeResult {
+	err := usageStats.AcquireQuerySlot(qc.Orgid)
+	if err != nil {
+		log.Infof("qid=%d, ExecuteQuery: rejecting the query, %v", qid, err)
+		return &structs.NodeResult{
+			ErrList: []error{err},
+		}
+	}
+	defer usageStats.ReleaseQuerySlot(qc.Orgid)
 
 	rQuery, err := query.StartQuery(qid, false)
 	if err != nil {
@@ -168,13 +195,20 @@ func ExecuteQuery(root *structs.ASTNode, aggs *structs.QueryAggregators, qid uin
 // The caller of this function is responsible for calling query.DeleteQuery(qid) to remove the qid info from memory.
 // Returns a channel that will have events for query status or any error. An error means the query was not successfully started
 func ExecuteAsyncQuery(root *structs.ASTNode, aggs *structs.QueryAggregators, qid uint64, qc *structs.QueryContext) (chan *query.QueryStateChanData, error) {
+	err := usageStats.AcquireQuerySlot(qc.Orgid)
+	if err != nil {
+		log.Infof("qid=%d, ExecuteAsyncQuery: rejecting the query, %v", qid, err)
+		return nil, err
+	}
 	rQuery, err := query.StartQuery(qid, true)
 	if err != nil {
+		usageStats.ReleaseQuerySlot(qc.Orgid)
 		log.Errorf("ExecuteAsyncQuery: Error initializing query status! %+v", err)
 		return nil, err
 	}
 
 	go func() {
+		defer usageStats.ReleaseQuerySlot(qc.Orgid)
 		_ = executeQueryInternal(root, aggs, qid, qc, rQuery)
 	}()
 	return rQuery.StateChan, nil
@@ -185,11 +219,16 @@ func executeQueryInternal(root *structs.ASTNode, aggs *structs.QueryAggregators,
 
 	startTime := time.Now()
 
+	if qc.IndexFilter != nil {
+		numRemoved := qc.TableInfo.FilterIndices(qc.IndexFilter)
+		if numRemoved > 0 && qc.GetNumTables() == 0 {
+			log.Infof("qid=%d, ExecuteQuery: not allowed to search any of the %v indices", qid, numRemoved)
+			return getEarlyErrorResult(rQuery, errors.New("not allowed to search the requested indices"))
+		}
+	}
 	if qc.GetNumTables() == 0 {
 		log.Infof("qid=%d, ExecuteQuery: empty array of Index Names provided", qid)
-		return &structs.NodeResult{
-			ErrList: []error{errors.New("empty array of Index Names provided")},
-		}
+		return getEarlyErrorResult(rQuery, errors.New("empty array of Index Names provided"))
 	}
 	// if query aggregations exist, get all results then truncate after
 	nodeRes := query.ApplyFilterOperator(root, root.TimeRange, aggs, qid, qc)
@@ -212,6 +251,14 @@ func executeQueryInternal(root *structs.ASTNode, aggs *structs.QueryAggregators,
 	return nodeRes
 }
 
+// Async queries wait for a COMPLETE or ERROR state, so the error of a query that is not run is sent to them as well
+func getEarlyErrorResult(rQuery *query.RunningQueryState, err error) *structs.NodeResult {
+	if rQuery.IsAsync() {
+		rQuery.SendQueryStateError(err)
+	}
+	return &structs.NodeResult{ErrList: []error{err}}
+}
+
 func LogSearchNode(prefix string, sNode *structs.SearchNode, qid uint64) {
 
 	sNodeJSON, _ := json.Marshal(sNode)
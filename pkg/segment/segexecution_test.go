@@ -1474,6 +1474,35 @@ func getMyIds() []uint64 {
 	return myids
 }
 
+func asyncQueryNoAllowedIndicesTest(t *testing.T) {
+	simpleNode := &ASTNode{
+		AndFilterCondition: &Condition{FilterCriteria: []*FilterCriteria{}},
+		TimeRange:          &dtu.TimeRange{StartEpochMs: 1, EndEpochMs: 100},
+	}
+	ti := structs.InitTableInfo("evts", 0, false)
+	qid := uint64(10102)
+	queryContext := structs.InitQueryContextWithTableInfo(ti, 10, 0, 0, false)
+	queryContext.IndexFilter = func(indexName string) bool { return false }
+	result, err := ExecuteAsyncQuery(simpleNode, nil, qid, queryContext)
+	assert.Nil(t, err)
+	defer query.DeleteQuery(qid)
+
+	// the query ends with an error instead of leaving the caller waiting
+	for {
+		select {
+		case update := <-result:
+			if update.StateName == query.RUNNING {
+				continue
+			}
+			assert.Equal(t, query.ERROR, update.StateName)
+			assert.NotNil(t, update.Error)
+			return
+		case <-time.After(5 * time.Second):
+			t.Fatal("asyncQueryNoAllowedIndicesTest: the query did not complete")
+		}
+	}
+}
+
 func Test_Query(t *testing.T) {
 	config.InitializeDefaultConfig()
 	_ = localstorage.InitLocalStorage()
@@ -1501,6 +1530,7 @@ func Test_Query(t *testing.T) {
 	nestedAggsNumericColRequestWithGroupByTest(t, numBuffers, numEntriesForBuffer, fileCount)
 	nestedAggsFilterRowsWithGroupByTest(t, numBuffers, numEntriesForBuffer, fileCount)
 	asyncQueryTest(t, numBuffers, numEntriesForBuffer, fileCount)
+	asyncQueryNoAllowedIndicesTest(t)
 
 	groupByQueryTestsForAsteriskQueries(t, numBuffers, numEntriesForBuffer, fileCount)
 
@@ -65,6 +65,13 @@ type RexExpr struct {
 	RexColNames []string
 }
 
+type LookupExpr struct {
+	LookupName   string   // name of the lookup in server.yaml
+	MatchField   string   // column of the lookup table to match on
+	EventField   string   // field of the events that is matched against MatchField
+	OutputFields []string // columns of the lookup table to add; all columns if empty
+}
+
 type StatisticExpr struct {
 	StatisticFunctionMode StatisticFunctionMode
 	Limit                 string
@@ -594,6 +601,12 @@ func (self *RexExpr) GetFields() []string {
 	return fields
 }
 
+func (self *LookupExpr) GetFields() []string {
+	var fields []string
+	fields = append(fields, self.EventField)
+	return fields
+}
+
 func (self *RenameExpr) GetFields() []string {
 	fields := make([]string, 0)
 
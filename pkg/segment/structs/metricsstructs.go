@@ -50,6 +50,7 @@ type Aggreation struct {
 	AggregatorFunction utils.AggregateFunctions //aggregator function
 	RangeFunction      utils.RangeFunctions     //range function to apply, only one of these will be non nil
 	FuncConstant       float64
+	RangeWindowSecs    uint32 // window of rate, increase and delta, e.g. 300 for rate(x[5m]). Defaults to the downsample interval
 }
 
 type Downsampler struct {
@@ -166,12 +167,44 @@ type OTSDBMetricsQueryExpRequest struct {
 	Outputs     []OTSDBMetricsQueryExpOutput   `json:"outputs"`
 }
 
+type OTSDBRateOptions struct {
+	Counter    bool    `json:"counter"`    // the metric is a monotonically increasing counter that may reset
+	CounterMax float64 `json:"counterMax"` // the value the counter wraps at, defaults to the max int64
+	ResetValue float64 `json:"resetValue"` // rates above this value are reported as 0
+	DropResets bool    `json:"dropResets"` // drop the rate of the datapoints after a counter reset
+}
+
+type OTSDBSubQuery struct {
+	Aggregator  string                     `json:"aggregator"`
+	Metric      string                     `json:"metric"`
+	Downsample  string                     `json:"downsample"` // e.g. 5m-avg-nan
+	Rate        bool                       `json:"rate"`
+	RateOptions OTSDBRateOptions           `json:"rateOptions"`
+	Filters     []OTSDBMetricsQueryExpTags `json:"filters"`
+	Tags        map[string]string          `json:"tags"` // pre 2.2 tag filters, all of them group by
+}
+
+type OTSDBQueryRequest struct {
+	Start        interface{}     `json:"start"`
+	End          interface{}     `json:"end"`
+	Queries      []OTSDBSubQuery `json:"queries"`
+	MsResolution bool            `json:"msResolution"`
+}
+
+type OTSDBQueryResponse struct {
+	MetricName    string                 `json:"metric"`
+	Tags          map[string]string      `json:"tags"`
+	AggregateTags []string               `json:"aggregateTags"`
+	Dps           map[uint64]interface{} `json:"dps"` // values filled by the null fill policy are nil and by the nan fill policy are "NaN"
+}
+
 type MetricsSearchRequest struct {
 	MetricsKeyBaseDir string
 	BlocksToSearch    map[uint16]bool
 	Parallelism       uint
 	QueryType         SegType
 	AllTagKeys        map[string]bool
+	RollupResolution  uint32 // if non zero, the rollup of this resolution is searched instead of the raw datapoints
 }
 
 /*
@@ -33,10 +33,12 @@ import (
 
 // New struct for passin query params
 type QueryContext struct {
-	TableInfo *TableInfo
-	SizeLimit uint64
-	Scroll    int
-	Orgid     uint64
+	TableInfo   *TableInfo
+	SizeLimit   uint64
+	Scroll      int
+	Orgid       uint64
+	IndexFilter func(indexName string) bool // indices it returns false for are not searched, nil searches every index
+	Parallelism int64                       // block workers of each segment search, 0 uses the configured number
 }
 
 // Input for filter operator can either be the result of a ASTNode or an expression
@@ -188,6 +190,30 @@ func (ti *TableInfo) GetNumIndices() int {
 	return ti.numIndices
 }
 
+// Removes the indices the filter returns false for, returns the number of indices removed
+func (ti *TableInfo) FilterIndices(indexFilter func(indexName string) bool) int {
+	if ti == nil {
+		return 0
+	}
+	queryTables := make([]string, 0, len(ti.queryTables))
+	for _, indexName := range ti.queryTables {
+		if indexFilter(indexName) {
+			queryTables = append(queryTables, indexName)
+		}
+	}
+	kibanaTables := make([]string, 0, len(ti.kibanaTables))
+	for _, indexName := range ti.kibanaTables {
+		if indexFilter(indexName) {
+			kibanaTables = append(kibanaTables, indexName)
+		}
+	}
+	numRemoved := len(ti.queryTables) + len(ti.kibanaTables) - len(queryTables) - len(kibanaTables)
+	ti.queryTables = queryTables
+	ti.kibanaTables = kibanaTables
+	ti.numIndices -= numRemoved
+	return numRemoved
+}
+
 // gets the number of tables that will be queried
 func (qc *QueryContext) GetNumTables() int {
 	if qc.TableInfo == nil {
@@ -0,0 +1,48 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package structs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FilterIndices(t *testing.T) {
+	ti := &TableInfo{
+		rawRequest:   "*",
+		queryTables:  []string{"team-a-logs", "team-b-logs", "team-a-metrics"},
+		kibanaTables: []string{".kibana", ".kibana_1"},
+		numIndices:   5,
+	}
+	numRemoved := ti.FilterIndices(func(indexName string) bool {
+		return strings.HasPrefix(indexName, "team-a-") || indexName == ".kibana"
+	})
+	assert.Equal(t, 2, numRemoved)
+	assert.Equal(t, []string{"team-a-logs", "team-a-metrics"}, ti.GetQueryTables())
+	assert.Equal(t, []string{".kibana"}, ti.GetKibanaIndices())
+	assert.Equal(t, 3, ti.GetNumIndices())
+
+	numRemoved = ti.FilterIndices(func(indexName string) bool { return false })
+	assert.Equal(t, 3, numRemoved)
+	qc := &QueryContext{TableInfo: ti}
+	assert.Equal(t, 0, qc.GetNumTables())
+
+	var nilTableInfo *TableInfo
+	assert.Equal(t, 0, nilTableInfo.FilterIndices(func(indexName string) bool { return true }))
+}
@@ -123,9 +123,18 @@ type QueryAggregators struct {
 	BucketLimit       int
 	ShowRequest       *ShowRequest
 	TableName         string
+	SpanSearch        *SpanSearchRequest // set by the spansearch command, which searches the spans of the traces index
 	Next              *QueryAggregators
 }
 
+const DEFAULT_SPAN_SEARCH_MAX_TRACES = 100
+const MAX_SPAN_SEARCH_MAX_TRACES = 1000
+
+type SpanSearchRequest struct {
+	ReturnTraces bool // return the spans of the traces that have a matching span instead of the matching spans
+	MaxTraces    int  // the number of traces returned with ReturnTraces
+}
+
 type ShowRequest struct {
 	ShowTables     bool
 	ShowFilter     *ShowFilter
@@ -147,9 +156,16 @@ type OutputTransforms struct {
 	OutputColumns          *ColumnsRequest    // post processing on output columns
 	LetColumns             *LetColumnsRequest // let columns processing on output columns
 	FilterRows             *BoolExpr          // discard rows failing some condition
+	SimilarTo              *SimilarToRequest  // keep only rows semantically similar to an example message
 	MaxRows                uint64             // if 0, get all results; else, get at most this many
 }
 
+type SimilarToRequest struct {
+	Example   string  // example message to compare against
+	FieldName string  // field containing the log message
+	Threshold float64 // min cosine similarity of the returned messages
+}
+
 type GroupByRequest struct {
 	MeasureOperations []*MeasureAggregator
 	GroupByColumns    []string
@@ -187,6 +203,7 @@ type LetColumnsRequest struct {
 	RexColRequest       *RexExpr
 	StatisticColRequest *StatisticExpr
 	RenameColRequest    *RenameExpr
+	LookupColRequest    *LookupExpr
 	NewColName          string
 }
 
@@ -251,6 +268,17 @@ type SegStats struct {
 	Records     []*utils.CValueEnclosure
 }
 
+// Returns SS_DT_FLOAT or SS_DT_SIGNED_NUM for the numeric columns and SS_DT_STRING for the others
+func (ss *SegStats) GetDtype() utils.SS_DTYPE {
+	if !ss.IsNumeric || ss.NumStats == nil {
+		return utils.SS_DT_STRING
+	}
+	if ss.NumStats.Sum.Ntype == utils.SS_DT_FLOAT {
+		return utils.SS_DT_FLOAT
+	}
+	return utils.SS_DT_SIGNED_NUM
+}
+
 type NumericStats struct {
 	Min   utils.NumTypeEnclosure `json:"min,omitempty"`
 	Max   utils.NumTypeEnclosure `json:"max,omitempty"`
@@ -436,10 +464,17 @@ func (qa *QueryAggregators) IsStatisticBlockEmpty() bool {
 		qa.OutputTransforms.LetColumns.StatisticColRequest == nil)
 }
 
-// To determine whether it contains certain specific AggregatorBlocks, such as: Rename Block, Rex Block...
+// To determine whether it contains certain specific AggregatorBlocks, such as: Rename Block, Rex Block, Lookup Block, SimilarTo Block...
 func (qa *QueryAggregators) HasQueryAggergatorBlock() bool {
-	return qa != nil && qa.OutputTransforms != nil && qa.OutputTransforms.LetColumns != nil &&
-		(qa.OutputTransforms.LetColumns.RexColRequest != nil || qa.OutputTransforms.LetColumns.RenameColRequest != nil)
+	if qa == nil || qa.OutputTransforms == nil {
+		return false
+	}
+	if qa.OutputTransforms.SimilarTo != nil {
+		return true
+	}
+	return qa.OutputTransforms.LetColumns != nil &&
+		(qa.OutputTransforms.LetColumns.RexColRequest != nil || qa.OutputTransforms.LetColumns.RenameColRequest != nil ||
+			qa.OutputTransforms.LetColumns.LookupColRequest != nil)
 }
 
 func (qa *QueryAggregators) HasQueryAggergatorBlockInChain() bool {
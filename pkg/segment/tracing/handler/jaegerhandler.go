@@ -0,0 +1,575 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/siglens/siglens/pkg/apikeys"
+	"github.com/siglens/siglens/pkg/ast/pipesearch"
+	"github.com/siglens/siglens/pkg/config"
+	"github.com/siglens/siglens/pkg/segment/tracing/structs"
+	putils "github.com/siglens/siglens/pkg/utils"
+	log "github.com/sirupsen/logrus"
+	"github.com/valyala/fasthttp"
+)
+
+/*
+	Jaeger query APIs over the spans of the traces index, so that the Jaeger UI and the Jaeger datasource of
+	Grafana can query the traces stored in SigLens.
+*/
+
+const JAEGER_DEFAULT_LIMIT = 20
+const JAEGER_MAX_LIMIT = 1500
+const JAEGER_DEFAULT_LOOKBACK = time.Hour
+
+// Number of traces whose spans are read by a single search
+const JAEGER_TRACES_PER_SEARCH = 50
+const SPANS_PAGE_SIZE = 1000
+
+var jaegerTagKeyRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9:_.]*$`)
+var traceIdRegex = regexp.MustCompile(`^[a-fA-F0-9]+$`)
+
+// Fields of a stored span that are not attributes of the span
+var spanFields = map[string]struct{}{
+	"trace_id": {}, "span_id": {}, "parent_span_id": {}, "service": {}, "trace_state": {}, "name": {}, "kind": {},
+	"start_time": {}, "end_time": {}, "duration": {}, "dropped_attributes_count": {}, "dropped_events_count": {},
+	"dropped_links_count": {}, "status": {}, "events": {}, "links": {}, "_index": {}, "timestamp": {},
+}
+
+type jaegerSearchParams struct {
+	service     string
+	operation   string
+	tags        map[string]string
+	startUs     uint64
+	endUs       uint64
+	minDuration time.Duration // 0 if not set
+	maxDuration time.Duration // 0 if not set
+	limit       int
+}
+
+// Event of a span as it is stored by the otlp ingestion
+type storedSpanEvent struct {
+	TimeUnixNano uint64 `json:"time_unix_nano"`
+	Name         string `json:"name"`
+	Attributes   []struct {
+		Key   string `json:"key"`
+		Value struct {
+			// has a single entry, keyed by the type of the value, e.g. StringValue
+			Value map[string]interface{} `json:"Value"`
+		} `json:"value"`
+	} `json:"attributes"`
+}
+
+// Handles /api/services
+func ProcessJaegerServicesRequest(ctx *fasthttp.RequestCtx, myid uint64) {
+	if !apikeys.AuthorizeIndexAccess(ctx, "traces", apikeys.ACCESS_READ) {
+		return
+	}
+	nowTs := putils.GetCurrentTimeInMs()
+	services, err := getDistinctSpanValues("*", "service", getRetentionStartMs(nowTs), nowTs, myid)
+	if err != nil {
+		writeJaegerError(ctx, fasthttp.StatusInternalServerError, err)
+		return
+	}
+	writeJaegerResponse(ctx, services, len(services))
+}
+
+// Handles /api/services/{service}/operations
+func ProcessJaegerOperationsRequest(ctx *fasthttp.RequestCtx, myid uint64) {
+	if !apikeys.AuthorizeIndexAccess(ctx, "traces", apikeys.ACCESS_READ) {
+		return
+	}
+	service, _ := ctx.UserValue("service").(string)
+	if service == "" || strings.Contains(service, `"`) {
+		writeJaegerError(ctx, fasthttp.StatusBadRequest, fmt.Errorf("invalid service name: %v", service))
+		return
+	}
+	nowTs := putils.GetCurrentTimeInMs()
+	operations, err := getDistinctSpanValues(`service="`+service+`"`, "name", getRetentionStartMs(nowTs), nowTs, myid)
+	if err != nil {
+		writeJaegerError(ctx, fasthttp.StatusInternalServerError, err)
+		return
+	}
+	writeJaegerResponse(ctx, operations, len(operations))
+}
+
+// Handles /api/traces/{traceID}
+func ProcessJaegerTraceRequest(ctx *fasthttp.RequestCtx, myid uint64) {
+	if !apikeys.AuthorizeIndexAccess(ctx, "traces", apikeys.ACCESS_READ) {
+		return
+	}
+	traceId, _ := ctx.UserValue("traceID").(string)
+	if !traceIdRegex.MatchString(traceId) {
+		writeJaegerError(ctx, fasthttp.StatusBadRequest, fmt.Errorf("invalid trace id: %v", traceId))
+		return
+	}
+	nowTs := putils.GetCurrentTimeInMs()
+	traces, err := getJaegerTraces([]string{strings.ToLower(traceId)}, getRetentionStartMs(nowTs), nowTs, myid)
+	if err != nil {
+		writeJaegerError(ctx, fasthttp.StatusInternalServerError, err)
+		return
+	}
+	if len(traces) == 0 {
+		writeJaegerError(ctx, fasthttp.StatusNotFound, fmt.Errorf("trace not found"))
+		return
+	}
+	writeJaegerResponse(ctx, traces, len(traces))
+}
+
+/*
+Handles /api/traces. Returns the latest traces having a span that matches the service, operation, tags and
+duration of the request
+*/
+func ProcessJaegerSearchRequest(ctx *fasthttp.RequestCtx, myid uint64) {
+	if !apikeys.AuthorizeIndexAccess(ctx, "traces", apikeys.ACCESS_READ) {
+		return
+	}
+	params, err := parseJaegerSearchParams(ctx.QueryArgs(), time.Now())
+	if err != nil {
+		writeJaegerError(ctx, fasthttp.StatusBadRequest, err)
+		return
+	}
+	searchText, err := buildJaegerSearchText(params)
+	if err != nil {
+		writeJaegerError(ctx, fasthttp.StatusBadRequest, err)
+		return
+	}
+
+	startMs := params.startUs / 1000
+	endMs := (params.endUs + 999) / 1000
+	resp, err := searchSpans(searchText+" | stats max(start_time) BY trace_id", startMs, endMs, 0, 0, myid)
+	if err != nil {
+		writeJaegerError(ctx, fasthttp.StatusInternalServerError, err)
+		return
+	}
+	traceIds := getLatestTraceIds(resp, params.limit)
+
+	// the spans of a trace may have been ingested outside of the time range of the request
+	nowTs := putils.GetCurrentTimeInMs()
+	traces, err := getJaegerTraces(traceIds, getRetentionStartMs(nowTs), nowTs, myid)
+	if err != nil {
+		writeJaegerError(ctx, fasthttp.StatusInternalServerError, err)
+		return
+	}
+	writeJaegerResponse(ctx, traces, len(traces))
+}
+
+func parseJaegerSearchParams(args *fasthttp.Args, now time.Time) (*jaegerSearchParams, error) {
+	params := &jaegerSearchParams{
+		service:   string(args.Peek("service")),
+		operation: string(args.Peek("operation")),
+		tags:      make(map[string]string),
+		limit:     JAEGER_DEFAULT_LIMIT,
+	}
+	if params.service == "" {
+		return nil, fmt.Errorf("parameter 'service' is required")
+	}
+
+	var err error
+	params.endUs = uint64(now.UnixMicro())
+	if endStr := string(args.Peek("end")); endStr != "" {
+		params.endUs, err = strconv.ParseUint(endStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid parameter 'end': %v", endStr)
+		}
+	}
+	lookback := JAEGER_DEFAULT_LOOKBACK
+	if lookbackStr := string(args.Peek("lookback")); lookbackStr != "" && lookbackStr != "custom" {
+		lookback, err = parseJaegerDuration(lookbackStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid parameter 'lookback': %v", lookbackStr)
+		}
+	}
+	if uint64(lookback.Microseconds()) < params.endUs {
+		params.startUs = params.endUs - uint64(lookback.Microseconds())
+	}
+	if startStr := string(args.Peek("start")); startStr != "" {
+		params.startUs, err = strconv.ParseUint(startStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid parameter 'start': %v", startStr)
+		}
+	}
+	if params.startUs > params.endUs {
+		return nil, fmt.Errorf("parameter 'start' must not be after 'end'")
+	}
+
+	if limitStr := string(args.Peek("limit")); limitStr != "" {
+		params.limit, err = strconv.Atoi(limitStr)
+		if err != nil || params.limit <= 0 {
+			return nil, fmt.Errorf("invalid parameter 'limit': %v", limitStr)
+		}
+		if params.limit > JAEGER_MAX_LIMIT {
+			params.limit = JAEGER_MAX_LIMIT
+		}
+	}
+	if durationStr := string(args.Peek("minDuration")); durationStr != "" {
+		params.minDuration, err = parseJaegerDuration(durationStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid parameter 'minDuration': %v", durationStr)
+		}
+	}
+	if durationStr := string(args.Peek("maxDuration")); durationStr != "" {
+		params.maxDuration, err = parseJaegerDuration(durationStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid parameter 'maxDuration': %v", durationStr)
+		}
+	}
+
+	// tags are sent as a json object by the Jaeger UI, or as key:value tag parameters
+	if tagsStr := args.Peek("tags"); len(tagsStr) > 0 {
+		tags := make(map[string]interface{})
+		err = json.Unmarshal(tagsStr, &tags)
+		if err != nil {
+			return nil, fmt.Errorf("invalid parameter 'tags': %v", err)
+		}
+		for key, value := range tags {
+			params.tags[key] = fmt.Sprintf("%v", value)
+		}
+	}
+	for _, tag := range args.PeekMulti("tag") {
+		pair := strings.SplitN(string(tag), ":", 2)
+		if len(pair) != 2 {
+			return nil, fmt.Errorf("invalid parameter 'tag': %v, expected key:value", string(tag))
+		}
+		params.tags[pair[0]] = pair[1]
+	}
+	return params, nil
+}
+
+// Parses a go duration, which may also be in days, e.g. 2d
+func parseJaegerDuration(durationStr string) (time.Duration, error) {
+	if strings.HasSuffix(durationStr, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(durationStr, "d"))
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(durationStr)
+}
+
+/*
+Returns the Splunk QL search matching the spans of the request. The error, span.kind and otel.status_code tags
+that are added to the Jaeger spans are searched on the kind and status fields they are derived from
+*/
+func buildJaegerSearchText(params *jaegerSearchParams) (string, error) {
+	conditions := make([]string, 0)
+	addCondition := func(field string, value string) error {
+		if strings.Contains(value, `"`) {
+			return fmt.Errorf("invalid value for %v: %v", field, value)
+		}
+		conditions = append(conditions, field+`="`+value+`"`)
+		return nil
+	}
+
+	err := addCondition("service", params.service)
+	if err != nil {
+		return "", err
+	}
+	if params.operation != "" {
+		err = addCondition("name", params.operation)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	tagKeys := make([]string, 0, len(params.tags))
+	for key := range params.tags {
+		tagKeys = append(tagKeys, key)
+	}
+	sort.Strings(tagKeys)
+	for _, key := range tagKeys {
+		value := params.tags[key]
+		switch key {
+		case "error":
+			if value == "true" {
+				err = addCondition("status", string(structs.Status_STATUS_CODE_ERROR))
+			} else {
+				conditions = append(conditions, `NOT status="`+string(structs.Status_STATUS_CODE_ERROR)+`"`)
+			}
+		case "span.kind":
+			err = addCondition("kind", "SPAN_KIND_"+strings.ToUpper(value))
+		case "otel.status_code":
+			err = addCondition("status", "STATUS_CODE_"+strings.ToUpper(value))
+		default:
+			if !jaegerTagKeyRegex.MatchString(key) {
+				return "", fmt.Errorf("invalid tag key: %v", key)
+			}
+			err = addCondition(key, value)
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if params.minDuration > 0 {
+		conditions = append(conditions, fmt.Sprintf("duration>=%d", params.minDuration.Nanoseconds()))
+	}
+	if params.maxDuration > 0 {
+		conditions = append(conditions, fmt.Sprintf("duration<=%d", params.maxDuration.Nanoseconds()))
+	}
+	return strings.Join(conditions, " AND "), nil
+}
+
+// Returns the ids of the traces with the latest spans, from the results of a stats max(start_time) BY trace_id
+func getLatestTraceIds(resp *pipesearch.PipeSearchResponseOuter, limit int) []string {
+	type traceStart struct {
+		traceId   string
+		startTime float64
+	}
+	traceStarts := make([]traceStart, 0)
+	for _, bucket := range resp.Aggs[""].Buckets {
+		traceId, ok := bucket["key"].(string)
+		if !ok {
+			continue
+		}
+		var startTime float64
+		if maxMap, ok := bucket["max(start_time)"].(map[string]interface{}); ok {
+			startTime, _ = maxMap["value"].(float64)
+		}
+		traceStarts = append(traceStarts, traceStart{traceId: traceId, startTime: startTime})
+	}
+	sort.SliceStable(traceStarts, func(i, j int) bool {
+		return traceStarts[i].startTime > traceStarts[j].startTime
+	})
+	if len(traceStarts) > limit {
+		traceStarts = traceStarts[:limit]
+	}
+
+	traceIds := make([]string, 0, len(traceStarts))
+	for _, ts := range traceStarts {
+		traceIds = append(traceIds, ts.traceId)
+	}
+	return traceIds
+}
+
+// Returns the distinct non empty values of the field across the matching spans
+func getDistinctSpanValues(searchText string, field string, startMs uint64, endMs uint64, myid uint64) ([]string, error) {
+	resp, err := searchSpans(searchText+" | stats count BY "+field, startMs, endMs, 0, 0, myid)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]string, 0)
+	for _, bucket := range resp.Aggs[""].Buckets {
+		if value, ok := bucket["key"].(string); ok && value != "" {
+			values = append(values, value)
+		}
+	}
+	sort.Strings(values)
+	return values, nil
+}
+
+// Reads all spans of the traces and returns the traces that have spans, in the order of traceIds
+func getJaegerTraces(traceIds []string, startMs uint64, endMs uint64, myid uint64) ([]*structs.JaegerTrace, error) {
+	traceIdToSpans := make(map[string][]map[string]interface{})
+	for batchStart := 0; batchStart < len(traceIds); batchStart += JAEGER_TRACES_PER_SEARCH {
+		batchEnd := batchStart + JAEGER_TRACES_PER_SEARCH
+		if batchEnd > len(traceIds) {
+			batchEnd = len(traceIds)
+		}
+		conditions := make([]string, 0, batchEnd-batchStart)
+		for _, traceId := range traceIds[batchStart:batchEnd] {
+			conditions = append(conditions, `trace_id="`+traceId+`"`)
+		}
+		searchText := strings.Join(conditions, " OR ")
+
+		for from := 0; ; from += SPANS_PAGE_SIZE {
+			resp, err := searchSpans(searchText, startMs, endMs, from, SPANS_PAGE_SIZE, myid)
+			if err != nil {
+				return nil, err
+			}
+			for _, rawSpan := range resp.Hits.Hits {
+				traceId, _ := rawSpan["trace_id"].(string)
+				traceIdToSpans[traceId] = append(traceIdToSpans[traceId], rawSpan)
+			}
+			if len(resp.Hits.Hits) < SPANS_PAGE_SIZE {
+				break
+			}
+		}
+	}
+
+	traces := make([]*structs.JaegerTrace, 0, len(traceIds))
+	for _, traceId := range traceIds {
+		rawSpans, ok := traceIdToSpans[traceId]
+		if !ok {
+			continue
+		}
+		traces = append(traces, toJaegerTrace(traceId, rawSpans))
+	}
+	return traces, nil
+}
+
+func searchSpans(searchText string, startMs uint64, endMs uint64, from int, size int, myid uint64) (*pipesearch.PipeSearchResponseOuter, error) {
+	requestBody := map[string]interface{}{
+		"indexName":     "traces",
+		"startEpoch":    startMs,
+		"endEpoch":      endMs,
+		"searchText":    searchText,
+		"queryLanguage": "Splunk QL",
+		"from":          from,
+	}
+	if size > 0 {
+		requestBody["size"] = size
+	}
+	requestBodyJSON, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("searchSpans: could not marshal to json body, err=%v", err)
+	}
+
+	searchCtx := &fasthttp.RequestCtx{}
+	searchCtx.Request.Header.SetMethod("POST")
+	searchCtx.Request.SetBody(requestBodyJSON)
+	pipesearch.ProcessPipeSearchRequest(searchCtx, myid)
+
+	resp := &pipesearch.PipeSearchResponseOuter{}
+	if err := json.Unmarshal(searchCtx.Response.Body(), resp); err != nil {
+		log.Errorf("searchSpans: could not unmarshal the response of search %v, err=%v", searchText, err)
+		return nil, fmt.Errorf("searchSpans: could not unmarshal json body, err=%v", err)
+	}
+	if len(resp.Errors) > 0 {
+		return nil, fmt.Errorf("searchSpans: search %v failed, err=%v", searchText, resp.Errors[0])
+	}
+	return resp, nil
+}
+
+// Converts the stored spans of a trace into a Jaeger trace with one process per service
+func toJaegerTrace(traceId string, rawSpans []map[string]interface{}) *structs.JaegerTrace {
+	services := make([]string, 0)
+	serviceToProcessId := make(map[string]string)
+	for _, rawSpan := range rawSpans {
+		service, _ := rawSpan["service"].(string)
+		if _, ok := serviceToProcessId[service]; !ok {
+			serviceToProcessId[service] = ""
+			services = append(services, service)
+		}
+	}
+	sort.Strings(services)
+
+	trace := &structs.JaegerTrace{
+		TraceID:   traceId,
+		Spans:     make([]*structs.JaegerSpan, 0, len(rawSpans)),
+		Processes: make(map[string]*structs.JaegerProcess, len(services)),
+	}
+	for i, service := range services {
+		processId := "p" + strconv.Itoa(i+1)
+		serviceToProcessId[service] = processId
+		trace.Processes[processId] = &structs.JaegerProcess{ServiceName: service, Tags: make([]*structs.JaegerKeyValue, 0)}
+	}
+	for _, rawSpan := range rawSpans {
+		service, _ := rawSpan["service"].(string)
+		trace.Spans = append(trace.Spans, toJaegerSpan(rawSpan, serviceToProcessId[service]))
+	}
+	sort.SliceStable(trace.Spans, func(i, j int) bool {
+		return trace.Spans[i].StartTime < trace.Spans[j].StartTime
+	})
+	return trace
+}
+
+func toJaegerSpan(rawSpan map[string]interface{}, processId string) *structs.JaegerSpan {
+	span := &structs.JaegerSpan{
+		References: make([]*structs.JaegerReference, 0),
+		Tags:       make([]*structs.JaegerKeyValue, 0),
+		Logs:       make([]*structs.JaegerLog, 0),
+		ProcessID:  processId,
+	}
+	span.TraceID, _ = rawSpan["trace_id"].(string)
+	span.SpanID, _ = rawSpan["span_id"].(string)
+	span.OperationName, _ = rawSpan["name"].(string)
+	if startTime, ok := rawSpan["start_time"].(float64); ok {
+		span.StartTime = uint64(startTime) / 1000
+	}
+	if duration, ok := rawSpan["duration"].(float64); ok {
+		span.Duration = uint64(duration) / 1000
+	}
+	if parentSpanId, ok := rawSpan["parent_span_id"].(string); ok && parentSpanId != "" {
+		span.References = append(span.References, &structs.JaegerReference{RefType: "CHILD_OF", TraceID: span.TraceID, SpanID: parentSpanId})
+	}
+
+	for key, value := range rawSpan {
+		if _, ok := spanFields[key]; ok || value == nil {
+			continue
+		}
+		span.Tags = append(span.Tags, toJaegerKeyValue(key, value))
+	}
+	if kind, ok := rawSpan["kind"].(string); ok && kind != "" && kind != "SPAN_KIND_UNSPECIFIED" {
+		span.Tags = append(span.Tags, toJaegerKeyValue("span.kind", strings.ToLower(strings.TrimPrefix(kind, "SPAN_KIND_"))))
+	}
+	if status, ok := rawSpan["status"].(string); ok && strings.HasPrefix(status, "STATUS_CODE_") && status != string(structs.Status_STATUS_CODE_UNSET) {
+		span.Tags = append(span.Tags, toJaegerKeyValue("otel.status_code", strings.TrimPrefix(status, "STATUS_CODE_")))
+		if status == string(structs.Status_STATUS_CODE_ERROR) {
+			span.Tags = append(span.Tags, toJaegerKeyValue("error", true))
+		}
+	}
+	sort.Slice(span.Tags, func(i, j int) bool { return span.Tags[i].Key < span.Tags[j].Key })
+
+	if events, ok := rawSpan["events"].(string); ok && events != "" {
+		span.Logs = getJaegerLogs(events)
+	}
+	return span
+}
+
+func getJaegerLogs(eventsJson string) []*structs.JaegerLog {
+	logs := make([]*structs.JaegerLog, 0)
+	var events []storedSpanEvent
+	err := json.Unmarshal([]byte(eventsJson), &events)
+	if err != nil {
+		log.Errorf("getJaegerLogs: could not unmarshal the span events %v, err=%v", eventsJson, err)
+		return logs
+	}
+	for _, event := range events {
+		jaegerLog := &structs.JaegerLog{
+			Timestamp: event.TimeUnixNano / 1000,
+			Fields:    []*structs.JaegerKeyValue{toJaegerKeyValue("event", event.Name)},
+		}
+		for _, attr := range event.Attributes {
+			for _, value := range attr.Value.Value {
+				jaegerLog.Fields = append(jaegerLog.Fields, toJaegerKeyValue(attr.Key, value))
+			}
+		}
+		logs = append(logs, jaegerLog)
+	}
+	return logs
+}
+
+func toJaegerKeyValue(key string, value interface{}) *structs.JaegerKeyValue {
+	switch v := value.(type) {
+	case string:
+		return &structs.JaegerKeyValue{Key: key, Type: "string", Value: v}
+	case bool:
+		return &structs.JaegerKeyValue{Key: key, Type: "bool", Value: v}
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < (1<<53) {
+			return &structs.JaegerKeyValue{Key: key, Type: "int64", Value: int64(v)}
+		}
+		return &structs.JaegerKeyValue{Key: key, Type: "float64", Value: v}
+	default:
+		return &structs.JaegerKeyValue{Key: key, Type: "string", Value: fmt.Sprintf("%v", v)}
+	}
+}
+
+func getRetentionStartMs(nowTs uint64) uint64 {
+	retentionMs := uint64(config.GetRetentionHours()) * OneHourInMs
+	if retentionMs > nowTs {
+		return 0
+	}
+	return nowTs - retentionMs
+}
+
+func writeJaegerResponse(ctx *fasthttp.RequestCtx, data interface{}, total int) {
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	putils.WriteJsonResponse(ctx, &structs.JaegerResponse{Data: data, Total: total})
+}
+
+func writeJaegerError(ctx *fasthttp.RequestCtx, statusCode int, err error) {
+	log.Errorf("writeJaegerError: request %v failed, err=%v", string(ctx.RequestURI()), err)
+	ctx.SetStatusCode(statusCode)
+	putils.WriteJsonResponse(ctx, &structs.JaegerResponse{
+		Errors: []*structs.JaegerError{{Code: statusCode, Message: err.Error()}},
+	})
+}
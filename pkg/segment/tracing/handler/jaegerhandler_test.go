@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/siglens/siglens/pkg/ast/pipesearch"
+	"github.com/siglens/siglens/pkg/segment/tracing/structs"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func Test_parseJaegerSearchParams(t *testing.T) {
+	now := time.UnixMicro(1_000_000_000_000_000)
+
+	args := &fasthttp.Args{}
+	args.Parse("service=frontend&operation=GET&lookback=2h&limit=5&minDuration=10ms&tags=%7B%22http.status_code%22%3A500%7D&tag=region:us-east")
+	params, err := parseJaegerSearchParams(args, now)
+	assert.Nil(t, err)
+	assert.Equal(t, "frontend", params.service)
+	assert.Equal(t, "GET", params.operation)
+	assert.Equal(t, uint64(1_000_000_000_000_000), params.endUs)
+	assert.Equal(t, uint64(1_000_000_000_000_000-2*3600*1_000_000), params.startUs)
+	assert.Equal(t, 5, params.limit)
+	assert.Equal(t, 10*time.Millisecond, params.minDuration)
+	assert.Equal(t, time.Duration(0), params.maxDuration)
+	assert.Equal(t, map[string]string{"http.status_code": "500", "region": "us-east"}, params.tags)
+
+	args.Parse("service=frontend&start=100&end=200&limit=100000")
+	params, err = parseJaegerSearchParams(args, now)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(100), params.startUs)
+	assert.Equal(t, uint64(200), params.endUs)
+	assert.Equal(t, JAEGER_MAX_LIMIT, params.limit)
+
+	args.Parse("service=frontend&lookback=2d")
+	params, err = parseJaegerSearchParams(args, now)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1_000_000_000_000_000-48*3600*1_000_000), params.startUs)
+	assert.Equal(t, JAEGER_DEFAULT_LIMIT, params.limit)
+
+	for _, query := range []string{"operation=GET", "service=a&limit=0", "service=a&start=300&end=200",
+		"service=a&minDuration=abc", "service=a&tag=nocolon", "service=a&tags=notjson"} {
+		args.Parse(query)
+		_, err = parseJaegerSearchParams(args, now)
+		assert.NotNil(t, err, query)
+	}
+}
+
+func Test_buildJaegerSearchText(t *testing.T) {
+	params := &jaegerSearchParams{
+		service:     "frontend",
+		operation:   "HTTP GET",
+		tags:        map[string]string{"http.method": "GET", "error": "true", "span.kind": "server"},
+		minDuration: time.Millisecond,
+		maxDuration: time.Second,
+	}
+	searchText, err := buildJaegerSearchText(params)
+	assert.Nil(t, err)
+	assert.Equal(t, `service="frontend" AND name="HTTP GET" AND status="STATUS_CODE_ERROR" AND http.method="GET" AND `+
+		`kind="SPAN_KIND_SERVER" AND duration>=1000000 AND duration<=1000000000`, searchText)
+
+	params = &jaegerSearchParams{service: "frontend", tags: map[string]string{}}
+	searchText, err = buildJaegerSearchText(params)
+	assert.Nil(t, err)
+	assert.Equal(t, `service="frontend"`, searchText)
+
+	params = &jaegerSearchParams{service: `front"end`, tags: map[string]string{}}
+	_, err = buildJaegerSearchText(params)
+	assert.NotNil(t, err)
+
+	params = &jaegerSearchParams{service: "frontend", tags: map[string]string{"bad key*": "x"}}
+	_, err = buildJaegerSearchText(params)
+	assert.NotNil(t, err)
+}
+
+func Test_getLatestTraceIds(t *testing.T) {
+	resp := &pipesearch.PipeSearchResponseOuter{
+		Aggs: map[string]pipesearch.AggregationResults{
+			"": {Buckets: []map[string]interface{}{
+				{"key": "a", "max(start_time)": map[string]interface{}{"value": float64(10)}},
+				{"key": "b", "max(start_time)": map[string]interface{}{"value": float64(30)}},
+				{"key": "c", "max(start_time)": map[string]interface{}{"value": float64(20)}},
+			}},
+		},
+	}
+	assert.Equal(t, []string{"b", "c"}, getLatestTraceIds(resp, 2))
+	assert.Equal(t, []string{"b", "c", "a"}, getLatestTraceIds(resp, 10))
+}
+
+func Test_toJaegerTrace(t *testing.T) {
+	rawSpans := []map[string]interface{}{
+		{
+			"trace_id": "abc", "span_id": "2", "parent_span_id": "1", "service": "backend", "name": "query",
+			"kind": "SPAN_KIND_CLIENT", "status": "STATUS_CODE_ERROR", "start_time": float64(3000000), "end_time": float64(5000000),
+			"duration": float64(2000000), "db.rows": float64(12), "db.ratio": 0.5, "db.cached": false,
+			"events": `[{"time_unix_nano":4000000,"name":"exception","attributes":[{"key":"exception.message","value":{"Value":{"StringValue":"timeout"}}}]}]`,
+		},
+		{
+			"trace_id": "abc", "span_id": "1", "parent_span_id": "", "service": "frontend", "name": "GET /",
+			"kind": "SPAN_KIND_SERVER", "status": "STATUS_CODE_UNSET", "start_time": float64(1000000), "end_time": float64(9000000),
+			"duration": float64(8000000), "http.method": "GET",
+		},
+	}
+	trace := toJaegerTrace("abc", rawSpans)
+	assert.Equal(t, "abc", trace.TraceID)
+	assert.Equal(t, map[string]*structs.JaegerProcess{
+		"p1": {ServiceName: "backend", Tags: []*structs.JaegerKeyValue{}},
+		"p2": {ServiceName: "frontend", Tags: []*structs.JaegerKeyValue{}},
+	}, trace.Processes)
+	assert.Len(t, trace.Spans, 2)
+
+	root := trace.Spans[0]
+	assert.Equal(t, "1", root.SpanID)
+	assert.Equal(t, "p2", root.ProcessID)
+	assert.Equal(t, uint64(1000), root.StartTime)
+	assert.Equal(t, uint64(8000), root.Duration)
+	assert.Empty(t, root.References)
+	assert.Equal(t, []*structs.JaegerKeyValue{
+		{Key: "http.method", Type: "string", Value: "GET"},
+		{Key: "span.kind", Type: "string", Value: "server"},
+	}, root.Tags)
+
+	child := trace.Spans[1]
+	assert.Equal(t, "query", child.OperationName)
+	assert.Equal(t, "p1", child.ProcessID)
+	assert.Equal(t, []*structs.JaegerReference{{RefType: "CHILD_OF", TraceID: "abc", SpanID: "1"}}, child.References)
+	assert.Equal(t, []*structs.JaegerKeyValue{
+		{Key: "db.cached", Type: "bool", Value: false},
+		{Key: "db.ratio", Type: "float64", Value: 0.5},
+		{Key: "db.rows", Type: "int64", Value: int64(12)},
+		{Key: "error", Type: "bool", Value: true},
+		{Key: "otel.status_code", Type: "string", Value: "ERROR"},
+		{Key: "span.kind", Type: "string", Value: "client"},
+	}, child.Tags)
+	assert.Equal(t, []*structs.JaegerLog{{
+		Timestamp: 4000,
+		Fields: []*structs.JaegerKeyValue{
+			{Key: "event", Type: "string", Value: "exception"},
+			{Key: "exception.message", Type: "string", Value: "timeout"},
+		},
+	}}, child.Logs)
+}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"encoding/json"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/siglens/siglens/pkg/segment/tracing/utils"
+	segutils "github.com/siglens/siglens/pkg/segment/utils"
+	segwriter "github.com/siglens/siglens/pkg/segment/writer"
+	log "github.com/sirupsen/logrus"
+)
+
+/*
+	RED (rate, errors, duration) metrics of the ingested spans, per service, operation and span kind. They are
+	aggregated in memory as the spans are ingested and written to the metrics store once per interval, so that
+	dashboards can query them instead of aggregating the spans.
+*/
+
+const RED_METRICS_INTERVAL_SECS = 60
+
+// Max number of span durations kept per series and interval, past it the percentiles are computed over a sample
+const RED_METRICS_MAX_DURATIONS = 10_000
+
+const (
+	RED_METRIC_RATE       = "traces_red_rate"       // Number of spans per second
+	RED_METRIC_ERROR_RATE = "traces_red_error_rate" // Percentage of the spans that errored
+	RED_METRIC_P50        = "traces_red_p50"        // p50, p90, p95, p99 durations of the spans, in nanoseconds
+	RED_METRIC_P90        = "traces_red_p90"
+	RED_METRIC_P95        = "traces_red_p95"
+	RED_METRIC_P99        = "traces_red_p99"
+)
+
+type redMetricsKey struct {
+	intervalStart uint32 // in seconds
+	service       string
+	operation     string
+	kind          string
+}
+
+type redMetricsAgg struct {
+	spanCount    uint64
+	errSpanCount uint64
+	durations    []uint64
+}
+
+type redMetricsDatapoint struct {
+	Metric    string            `json:"metric"`
+	Tags      map[string]string `json:"tags"`
+	Timestamp uint32            `json:"timestamp"`
+	Value     float64           `json:"value"`
+}
+
+var redMetricsLock sync.Mutex
+var redMetricsAggs = make(map[redMetricsKey]*redMetricsAgg)
+
+// Adds a span ingested at ingestTs (in ms) to the RED metrics of its interval
+func RecordSpanRedMetrics(service string, operation string, kind string, duration uint64, isError bool, ingestTs uint64) {
+	ingestSecs := uint32(ingestTs / 1000)
+	key := redMetricsKey{
+		intervalStart: ingestSecs - ingestSecs%RED_METRICS_INTERVAL_SECS,
+		service:       service,
+		operation:     operation,
+		kind:          kind,
+	}
+
+	redMetricsLock.Lock()
+	defer redMetricsLock.Unlock()
+	agg, ok := redMetricsAggs[key]
+	if !ok {
+		agg = &redMetricsAgg{durations: make([]uint64, 0)}
+		redMetricsAggs[key] = agg
+	}
+	agg.spanCount++
+	if isError {
+		agg.errSpanCount++
+	}
+	if len(agg.durations) < RED_METRICS_MAX_DURATIONS {
+		agg.durations = append(agg.durations, duration)
+	} else if idx := rand.Int63n(int64(agg.spanCount)); idx < RED_METRICS_MAX_DURATIONS {
+		// reservoir sampling, so that every span of the interval is equally likely to be kept
+		agg.durations[idx] = duration
+	}
+}
+
+// Writes the RED metrics of every interval once it has ended
+func RedMetricsFlushThread() {
+	for {
+		now := time.Now()
+		nextInterval := now.Truncate(RED_METRICS_INTERVAL_SECS * time.Second).Add(RED_METRICS_INTERVAL_SECS * time.Second)
+		time.Sleep(time.Until(nextInterval))
+		flushRedMetrics(uint32(time.Now().Unix()))
+	}
+}
+
+// Writes and removes the RED metrics of the intervals that ended before nowSecs
+func flushRedMetrics(nowSecs uint32) {
+	currIntervalStart := nowSecs - nowSecs%RED_METRICS_INTERVAL_SECS
+	endedAggs := make(map[redMetricsKey]*redMetricsAgg)
+
+	redMetricsLock.Lock()
+	for key, agg := range redMetricsAggs {
+		if key.intervalStart < currIntervalStart {
+			endedAggs[key] = agg
+			delete(redMetricsAggs, key)
+		}
+	}
+	redMetricsLock.Unlock()
+
+	for key, agg := range endedAggs {
+		for _, dp := range getRedMetricsDatapoints(key, agg) {
+			data, err := json.Marshal(dp)
+			if err != nil {
+				log.Errorf("flushRedMetrics: failed to marshal datapoint %+v, err=%v", dp, err)
+				continue
+			}
+			err = segwriter.AddTimeSeriesEntryToInMemBuf(data, segutils.SIGNAL_METRICS_OTSDB, uint64(0))
+			if err != nil {
+				log.Errorf("flushRedMetrics: failed to add time series entry %+v, err=%v", dp, err)
+			}
+		}
+	}
+}
+
+func getRedMetricsDatapoints(key redMetricsKey, agg *redMetricsAgg) []*redMetricsDatapoint {
+	tags := make(map[string]string)
+	if key.service != "" {
+		tags["service"] = key.service
+	}
+	if key.operation != "" {
+		tags["operation"] = key.operation
+	}
+	if key.kind != "" {
+		tags["span_kind"] = key.kind
+	}
+
+	values := map[string]float64{
+		RED_METRIC_RATE:       float64(agg.spanCount) / float64(RED_METRICS_INTERVAL_SECS),
+		RED_METRIC_ERROR_RATE: float64(agg.errSpanCount) / float64(agg.spanCount) * 100,
+		RED_METRIC_P50:        float64(utils.FindPercentileData(agg.durations, 50)),
+		RED_METRIC_P90:        float64(utils.FindPercentileData(agg.durations, 90)),
+		RED_METRIC_P95:        float64(utils.FindPercentileData(agg.durations, 95)),
+		RED_METRIC_P99:        float64(utils.FindPercentileData(agg.durations, 99)),
+	}
+	datapoints := make([]*redMetricsDatapoint, 0, len(values))
+	for _, metric := range []string{RED_METRIC_RATE, RED_METRIC_ERROR_RATE, RED_METRIC_P50, RED_METRIC_P90, RED_METRIC_P95, RED_METRIC_P99} {
+		datapoints = append(datapoints, &redMetricsDatapoint{
+			Metric:    metric,
+			Tags:      tags,
+			Timestamp: key.intervalStart,
+			Value:     values[metric],
+		})
+	}
+	return datapoints
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RecordSpanRedMetrics(t *testing.T) {
+	redMetricsAggs = make(map[redMetricsKey]*redMetricsAgg)
+
+	ingestTs := uint64(1_700_000_030_000)
+	for i := uint64(1); i <= 4; i++ {
+		RecordSpanRedMetrics("frontend", "GET /", "SPAN_KIND_SERVER", i*1000, i == 4, ingestTs)
+	}
+	RecordSpanRedMetrics("frontend", "GET /", "SPAN_KIND_SERVER", 5000, false, ingestTs+60_000)
+	assert.Len(t, redMetricsAggs, 2)
+
+	key := redMetricsKey{intervalStart: 1_699_999_980, service: "frontend", operation: "GET /", kind: "SPAN_KIND_SERVER"}
+	agg, ok := redMetricsAggs[key]
+	assert.True(t, ok)
+	assert.Equal(t, uint64(4), agg.spanCount)
+	assert.Equal(t, uint64(1), agg.errSpanCount)
+
+	datapoints := getRedMetricsDatapoints(key, agg)
+	assert.Len(t, datapoints, 6)
+	values := make(map[string]float64)
+	for _, dp := range datapoints {
+		assert.Equal(t, uint32(1_699_999_980), dp.Timestamp)
+		assert.Equal(t, map[string]string{"service": "frontend", "operation": "GET /", "span_kind": "SPAN_KIND_SERVER"}, dp.Tags)
+		values[dp.Metric] = dp.Value
+	}
+	assert.Equal(t, 4.0/RED_METRICS_INTERVAL_SECS, values[RED_METRIC_RATE])
+	assert.Equal(t, 25.0, values[RED_METRIC_ERROR_RATE])
+	assert.Equal(t, 3000.0, values[RED_METRIC_P50])
+	assert.Equal(t, 4000.0, values[RED_METRIC_P99])
+}
+
+func Test_RecordSpanRedMetricsSampling(t *testing.T) {
+	redMetricsAggs = make(map[redMetricsKey]*redMetricsAgg)
+
+	for i := 0; i < RED_METRICS_MAX_DURATIONS+500; i++ {
+		RecordSpanRedMetrics("backend", "query", "SPAN_KIND_CLIENT", 100, false, 1_700_000_000_000)
+	}
+	for _, agg := range redMetricsAggs {
+		assert.Equal(t, uint64(RED_METRICS_MAX_DURATIONS+500), agg.spanCount)
+		assert.Len(t, agg.durations, RED_METRICS_MAX_DURATIONS)
+	}
+}
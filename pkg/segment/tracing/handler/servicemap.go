@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/siglens/siglens/pkg/apikeys"
+	pipesearch "github.com/siglens/siglens/pkg/ast/pipesearch"
+	"github.com/siglens/siglens/pkg/segment/tracing/structs"
+	"github.com/siglens/siglens/pkg/segment/tracing/utils"
+	putils "github.com/siglens/siglens/pkg/utils"
+	log "github.com/sirupsen/logrus"
+	"github.com/valyala/fasthttp"
+)
+
+// Handles /api/traces/serviceMap. The startEpoch and endEpoch query parameters are in ms, the default is the last hour
+func ProcessServiceMapRequest(ctx *fasthttp.RequestCtx, myid uint64) {
+	if !apikeys.AuthorizeIndexAccess(ctx, "traces", apikeys.ACCESS_READ) {
+		return
+	}
+	endEpoch := putils.GetCurrentTimeInMs()
+	startEpoch := endEpoch - OneHourInMs
+	var err error
+	if endStr := string(ctx.QueryArgs().Peek("endEpoch")); endStr != "" {
+		endEpoch, err = strconv.ParseUint(endStr, 10, 64)
+		if err != nil {
+			writeErrMsg(ctx, "ProcessServiceMapRequest", "invalid endEpoch", err)
+			return
+		}
+	}
+	if startStr := string(ctx.QueryArgs().Peek("startEpoch")); startStr != "" {
+		startEpoch, err = strconv.ParseUint(startStr, 10, 64)
+		if err != nil {
+			writeErrMsg(ctx, "ProcessServiceMapRequest", "invalid startEpoch", err)
+			return
+		}
+	}
+	if startEpoch > endEpoch {
+		writeErrMsg(ctx, "ProcessServiceMapRequest", "invalid time range",
+			fmt.Errorf("startEpoch %v is after endEpoch %v", startEpoch, endEpoch))
+		return
+	}
+
+	spans, err := getAllSpans(startEpoch, endEpoch, myid)
+	if err != nil {
+		writeErrMsg(ctx, "ProcessServiceMapRequest", "could not read the spans", err)
+		ctx.SetStatusCode(fasthttp.StatusInternalServerError)
+		return
+	}
+	serviceMap := MakeServiceMap(spans)
+	serviceMap.StartEpoch = startEpoch
+	serviceMap.EndEpoch = endEpoch
+
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	putils.WriteJsonResponse(ctx, serviceMap)
+}
+
+// Reads all the spans ingested in the time range, a page at a time
+func getAllSpans(startEpoch uint64, endEpoch uint64, myid uint64) ([]*structs.Span, error) {
+	spans := make([]*structs.Span, 0)
+	for from := 0; ; from += SPANS_PAGE_SIZE {
+		requestBody := map[string]interface{}{
+			"indexName":     "traces",
+			"startEpoch":    startEpoch,
+			"endEpoch":      endEpoch,
+			"searchText":    "*",
+			"queryLanguage": "Splunk QL",
+			"from":          from,
+			"size":          SPANS_PAGE_SIZE,
+		}
+		requestBodyJSON, err := json.Marshal(requestBody)
+		if err != nil {
+			return nil, fmt.Errorf("getAllSpans: could not marshal to json body, err=%v", err)
+		}
+
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.Header.SetMethod("POST")
+		ctx.Request.SetBody(requestBodyJSON)
+		pipesearch.ProcessPipeSearchRequest(ctx, myid)
+
+		rawSpanData := structs.RawSpanData{}
+		if err := json.Unmarshal(ctx.Response.Body(), &rawSpanData); err != nil {
+			log.Errorf("getAllSpans: could not unmarshal json body, err=%v", err)
+			return nil, err
+		}
+		spans = append(spans, rawSpanData.Hits.Spans...)
+		if len(rawSpanData.Hits.Spans) < SPANS_PAGE_SIZE {
+			break
+		}
+	}
+	return spans, nil
+}
+
+/*
+Derives the caller to callee edges between services from the parent and child relationships of the spans. A span
+whose parent span belongs to a different service is a call from the service of the parent to its own service
+*/
+func MakeServiceMap(spans []*structs.Span) *structs.ServiceMap {
+	spanIdToService := make(map[string]string, len(spans))
+	services := make([]string, 0)
+	for _, span := range spans {
+		if _, ok := spanIdToService[span.SpanID]; !ok {
+			spanIdToService[span.SpanID] = span.Service
+		}
+	}
+
+	seenServices := make(map[string]struct{})
+	edges := make(map[string]map[string]*structs.ServiceEdge)
+	durations := make(map[*structs.ServiceEdge][]uint64)
+	for _, span := range spans {
+		if _, ok := seenServices[span.Service]; !ok {
+			seenServices[span.Service] = struct{}{}
+			services = append(services, span.Service)
+		}
+		if span.ParentSpanID == "" {
+			continue
+		}
+		parentService, exists := spanIdToService[span.ParentSpanID]
+		if !exists || parentService == span.Service {
+			continue
+		}
+
+		if edges[parentService] == nil {
+			edges[parentService] = make(map[string]*structs.ServiceEdge)
+		}
+		edge, exists := edges[parentService][span.Service]
+		if !exists {
+			edge = &structs.ServiceEdge{Caller: parentService, Callee: span.Service}
+			edges[parentService][span.Service] = edge
+		}
+		edge.CallCount++
+		if span.Status == string(structs.Status_STATUS_CODE_ERROR) {
+			edge.ErrorCount++
+		}
+		durations[edge] = append(durations[edge], span.Duration)
+	}
+
+	serviceMap := &structs.ServiceMap{
+		Services: services,
+		Edges:    make([]*structs.ServiceEdge, 0),
+	}
+	for _, calleeEdges := range edges {
+		for _, edge := range calleeEdges {
+			edge.P50 = utils.FindPercentileData(durations[edge], 50)
+			edge.P90 = utils.FindPercentileData(durations[edge], 90)
+			edge.P95 = utils.FindPercentileData(durations[edge], 95)
+			edge.P99 = utils.FindPercentileData(durations[edge], 99)
+			serviceMap.Edges = append(serviceMap.Edges, edge)
+		}
+	}
+	sort.Strings(serviceMap.Services)
+	sort.Slice(serviceMap.Edges, func(i, j int) bool {
+		if serviceMap.Edges[i].Caller != serviceMap.Edges[j].Caller {
+			return serviceMap.Edges[i].Caller < serviceMap.Edges[j].Caller
+		}
+		return serviceMap.Edges[i].Callee < serviceMap.Edges[j].Callee
+	})
+	return serviceMap
+}
@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/siglens/siglens/pkg/segment/tracing/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_MakeServiceMap(t *testing.T) {
+	spans := []*structs.Span{
+		{SpanID: "1", Service: "frontend", Duration: 1000},
+		{SpanID: "2", ParentSpanID: "1", Service: "frontend", Duration: 900},
+		{SpanID: "3", ParentSpanID: "2", Service: "backend", Duration: 100},
+		{SpanID: "4", ParentSpanID: "2", Service: "backend", Duration: 300, Status: string(structs.Status_STATUS_CODE_ERROR)},
+		{SpanID: "5", ParentSpanID: "3", Service: "db", Duration: 50},
+		{SpanID: "6", ParentSpanID: "missing", Service: "cache", Duration: 10},
+	}
+	serviceMap := MakeServiceMap(spans)
+	assert.Equal(t, []string{"backend", "cache", "db", "frontend"}, serviceMap.Services)
+	assert.Equal(t, []*structs.ServiceEdge{
+		{Caller: "backend", Callee: "db", CallCount: 1, P50: 50, P90: 50, P95: 50, P99: 50},
+		{Caller: "frontend", Callee: "backend", CallCount: 2, ErrorCount: 1, P50: 300, P90: 300, P95: 300, P99: 300},
+	}, serviceMap.Edges)
+
+	serviceMap = MakeServiceMap([]*structs.Span{})
+	assert.Empty(t, serviceMap.Services)
+	assert.Empty(t, serviceMap.Edges)
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	jsoniter "github.com/json-iterator/go"
+	"github.com/siglens/siglens/pkg/apikeys"
 	pipesearch "github.com/siglens/siglens/pkg/ast/pipesearch"
 	"github.com/siglens/siglens/pkg/es/writer"
 	"github.com/siglens/siglens/pkg/segment/tracing/structs"
@@ -21,6 +22,9 @@ import (
 const OneHourInMs = 60 * 60 * 1000
 
 func ProcessSearchTracesRequest(ctx *fasthttp.RequestCtx, myid uint64) {
+	if !apikeys.AuthorizeIndexAccess(ctx, "traces", apikeys.ACCESS_READ) {
+		return
+	}
 
 	rawJSON := ctx.PostBody()
 	if rawJSON == nil {
@@ -449,50 +453,17 @@ func MakeTracesDependancyGraph() map[string]map[string]int {
 	startEpoch := nowTs - OneHourInMs
 	endEpoch := nowTs
 
-	requestBody := map[string]interface{}{
-		"indexName":     "traces",
-		"startEpoch":    startEpoch,
-		"endEpoch":      endEpoch,
-		"searchText":    "*",
-		"queryLanguage": "Splunk QL",
-	}
-	requestBodyJSON, err := json.Marshal(requestBody)
+	spans, err := getAllSpans(startEpoch, endEpoch, 0)
 	if err != nil {
-		fmt.Println("Error marshaling request body:", err)
-		return nil
-	}
-	ctx := &fasthttp.RequestCtx{}
-	ctx.Request.SetBody(requestBodyJSON)
-
-	ctx.Request.Header.SetMethod("POST")
-	pipesearch.ProcessPipeSearchRequest(ctx, 0)
-
-	rawSpanData := structs.RawSpanData{}
-	if err := json.Unmarshal(ctx.Response.Body(), &rawSpanData); err != nil {
-		log.Errorf("MakeTracesDependancyGraph: could not unmarshal json body, err=%v", err)
+		log.Errorf("MakeTracesDependancyGraph: could not read the spans, err=%v", err)
 		return nil
 	}
-	spanIdToServiceName := make(map[string]string)
 	dependencyMatrix := make(map[string]map[string]int)
-
-	for _, span := range rawSpanData.Hits.Spans {
-		spanIdToServiceName[span.SpanID] = span.Service
-	}
-	for _, span := range rawSpanData.Hits.Spans {
-		if span.ParentSpanID == "" {
-			continue
+	for _, edge := range MakeServiceMap(spans).Edges {
+		if dependencyMatrix[edge.Caller] == nil {
+			dependencyMatrix[edge.Caller] = make(map[string]int)
 		}
-		parentService, parentExists := spanIdToServiceName[span.ParentSpanID]
-		if !parentExists {
-			continue
-		}
-		if parentService == span.Service {
-			continue
-		}
-		if dependencyMatrix[parentService] == nil {
-			dependencyMatrix[parentService] = make(map[string]int)
-		}
-		dependencyMatrix[parentService][span.Service]++
+		dependencyMatrix[edge.Caller][edge.Callee] = edge.CallCount
 	}
 	return dependencyMatrix
 }
@@ -521,6 +492,9 @@ func writeDependencyMatrix(dependencyMatrix map[string]map[string]int) {
 }
 
 func ProcessDependencyRequest(ctx *fasthttp.RequestCtx, myid uint64) {
+	if !apikeys.AuthorizeIndexAccess(ctx, "service-dependency", apikeys.ACCESS_READ) {
+		return
+	}
 	searchRequestBody := &structs.SearchRequestBody{}
 	searchRequestBody.QueryLanguage = "Splunk QL"
 	searchRequestBody.IndexName = "service-dependency"
@@ -585,6 +559,9 @@ func ProcessDependencyRequest(ctx *fasthttp.RequestCtx, myid uint64) {
 }
 
 func ProcessGanttChartRequest(ctx *fasthttp.RequestCtx, myid uint64) {
+	if !apikeys.AuthorizeIndexAccess(ctx, "traces", apikeys.ACCESS_READ) {
+		return
+	}
 
 	rawJSON := ctx.PostBody()
 	if rawJSON == nil {
@@ -74,3 +74,78 @@ type GanttChartSpan struct {
 	Tags            map[string]interface{} `json:"tags"`
 	Children        []*GanttChartSpan      `json:"children"`
 }
+
+// Response of the Jaeger query APIs, used by the Jaeger UI and the Jaeger datasource of Grafana
+type JaegerResponse struct {
+	Data   interface{}    `json:"data"`
+	Total  int            `json:"total"`
+	Limit  int            `json:"limit"`
+	Offset int            `json:"offset"`
+	Errors []*JaegerError `json:"errors"`
+}
+
+type JaegerError struct {
+	Code    int    `json:"code"`
+	Message string `json:"msg"`
+}
+
+type JaegerTrace struct {
+	TraceID   string                    `json:"traceID"`
+	Spans     []*JaegerSpan             `json:"spans"`
+	Processes map[string]*JaegerProcess `json:"processes"`
+	Warnings  []string                  `json:"warnings"`
+}
+
+type JaegerSpan struct {
+	TraceID       string             `json:"traceID"`
+	SpanID        string             `json:"spanID"`
+	OperationName string             `json:"operationName"`
+	References    []*JaegerReference `json:"references"`
+	StartTime     uint64             `json:"startTime"` // in microseconds
+	Duration      uint64             `json:"duration"`  // in microseconds
+	Tags          []*JaegerKeyValue  `json:"tags"`
+	Logs          []*JaegerLog       `json:"logs"`
+	ProcessID     string             `json:"processID"`
+	Warnings      []string           `json:"warnings"`
+}
+
+type JaegerReference struct {
+	RefType string `json:"refType"`
+	TraceID string `json:"traceID"`
+	SpanID  string `json:"spanID"`
+}
+
+type JaegerKeyValue struct {
+	Key   string      `json:"key"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+type JaegerLog struct {
+	Timestamp uint64            `json:"timestamp"` // in microseconds
+	Fields    []*JaegerKeyValue `json:"fields"`
+}
+
+type JaegerProcess struct {
+	ServiceName string            `json:"serviceName"`
+	Tags        []*JaegerKeyValue `json:"tags"`
+}
+
+// Calls from the spans of the caller service to the spans of the callee service
+type ServiceEdge struct {
+	Caller     string `json:"caller"`
+	Callee     string `json:"callee"`
+	CallCount  int    `json:"call_count"`
+	ErrorCount int    `json:"error_count"` // Number of callee spans that errored
+	P50        uint64 `json:"p50"`         // p50, p90, p95, p99 latencies of the callee spans, in nanoseconds
+	P90        uint64 `json:"p90"`
+	P95        uint64 `json:"p95"`
+	P99        uint64 `json:"p99"`
+}
+
+type ServiceMap struct {
+	StartEpoch uint64         `json:"startEpoch"`
+	EndEpoch   uint64         `json:"endEpoch"`
+	Services   []string       `json:"services"`
+	Edges      []*ServiceEdge `json:"edges"`
+}
@@ -38,22 +38,12 @@ func Reduce(e1 CValueEnclosure, e2 CValueEnclosure, fun AggregateFunctions) (CVa
 		return e1, nil
 	}
 
-	// Convert to float if needed
-	if e1.Dtype == SS_DT_FLOAT && e2.Dtype != SS_DT_FLOAT {
-		switch e2.Dtype {
-		case SS_DT_UNSIGNED_NUM:
-			e2 = CValueEnclosure{Dtype: SS_DT_FLOAT, CVal: float64(e2.CVal.(uint64))}
-		case SS_DT_SIGNED_NUM:
-			e2 = CValueEnclosure{Dtype: SS_DT_FLOAT, CVal: float64(e2.CVal.(int64))}
-		}
-	}
-
-	if e2.Dtype == SS_DT_FLOAT && e1.Dtype != SS_DT_FLOAT {
-		switch e1.Dtype {
-		case SS_DT_UNSIGNED_NUM:
-			e1 = CValueEnclosure{Dtype: SS_DT_FLOAT, CVal: float64(e1.CVal.(uint64))}
-		case SS_DT_SIGNED_NUM:
-			e1 = CValueEnclosure{Dtype: SS_DT_FLOAT, CVal: float64(e1.CVal.(int64))}
+	// Reduce as floats if needed, without boxing the converted values
+	if e1.Dtype == SS_DT_FLOAT || e2.Dtype == SS_DT_FLOAT {
+		f1, ok1 := numToFloat64(e1)
+		f2, ok2 := numToFloat64(e2)
+		if ok1 && ok2 {
+			return reduceFloats(f1, f2, fun)
 		}
 	}
 
@@ -114,6 +104,30 @@ func Reduce(e1 CValueEnclosure, e2 CValueEnclosure, fun AggregateFunctions) (CVa
 	return e1, fmt.Errorf("Reduce: unsupported reduce function: %v", fun)
 }
 
+func numToFloat64(e CValueEnclosure) (float64, bool) {
+	switch e.Dtype {
+	case SS_DT_FLOAT:
+		return e.CVal.(float64), true
+	case SS_DT_UNSIGNED_NUM:
+		return float64(e.CVal.(uint64)), true
+	case SS_DT_SIGNED_NUM:
+		return float64(e.CVal.(int64)), true
+	}
+	return 0, false
+}
+
+func reduceFloats(f1 float64, f2 float64, fun AggregateFunctions) (CValueEnclosure, error) {
+	switch fun {
+	case Sum, Count:
+		return CValueEnclosure{Dtype: SS_DT_FLOAT, CVal: f1 + f2}, nil
+	case Min:
+		return CValueEnclosure{Dtype: SS_DT_FLOAT, CVal: math.Min(f1, f2)}, nil
+	case Max:
+		return CValueEnclosure{Dtype: SS_DT_FLOAT, CVal: math.Max(f1, f2)}, nil
+	}
+	return CValueEnclosure{Dtype: SS_DT_FLOAT, CVal: f1}, fmt.Errorf("Reduce: unsupported reduce function: %v", fun)
+}
+
 func (self *NumTypeEnclosure) ReduceFast(e2Dtype SS_DTYPE, e2int64 int64,
 	e2float64 float64, fun AggregateFunctions) error {
 
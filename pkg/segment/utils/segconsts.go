@@ -290,8 +290,15 @@ type RangeFunctions int
 const (
 	Derivative RangeFunctions = iota + 1
 	Rate
+	Increase
+	Delta
 )
 
+// Rate, Increase and Delta are computed per series from the raw datapoints, before series are aggregated
+func (rf RangeFunctions) IsAppliedPerSeries() bool {
+	return rf == Rate || rf == Increase || rf == Delta
+}
+
 // For columns used by aggs with eval statements, we should keep their raw values because we need to evaluate them
 // For columns only used by aggs without eval statements, we should not keep their raw values because it is a waste of performance
 // If we only use two modes. Later occurring aggs will overwrite earlier occurring aggs' usage status. E.g. stats dc(eval(lower(state))), dc(state)
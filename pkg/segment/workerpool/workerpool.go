@@ -0,0 +1,133 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workerpool
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/siglens/siglens/pkg/config"
+	log "github.com/sirupsen/logrus"
+)
+
+/*
+	The worker pools shared by the segment searches of all the queries. Each segment search runs a number of block
+	workers, and a worker holds one of the cpu workers while it decompresses and filters a block, which bounds the cpu
+	used by the searches no matter how many queries run. The disk reads of the next block of a worker run on the io
+	workers meanwhile, so that its reads overlap with the search of the current block.
+*/
+
+const DEFAULT_MAX_WORKERS_PER_QUERY = 4
+
+// Reads waiting for an io worker, per io worker. Past this many the blocks are read when they are searched
+const IO_QUEUE_DEPTH_PER_WORKER = 2
+
+type searchWorkerPool struct {
+	cpuSlots           chan struct{}
+	numIOWorkers       int
+	ioJobs             chan func()
+	maxWorkersPerQuery int64
+}
+
+var poolOnce sync.Once
+var globalPool *searchWorkerPool
+
+func getPool() *searchWorkerPool {
+	poolOnce.Do(func() {
+		globalPool = newSearchWorkerPool(config.GetSearchWorkersConfig())
+		for i := 0; i < globalPool.numIOWorkers; i++ {
+			go ioWorker(globalPool.ioJobs)
+		}
+		log.Infof("getPool: searching with %v cpu workers, %v io workers and up to %v workers per query",
+			cap(globalPool.cpuSlots), globalPool.numIOWorkers, globalPool.maxWorkersPerQuery)
+		// only the workers per query can be reloaded, the pools are sized on startup
+		config.AddReloadHook(func() {
+			maxWorkersPerQuery := getMaxWorkersPerQuery(config.GetSearchWorkersConfig(), cap(globalPool.cpuSlots))
+			atomic.StoreInt64(&globalPool.maxWorkersPerQuery, maxWorkersPerQuery)
+		})
+	})
+	return globalPool
+}
+
+func newSearchWorkerPool(workersCfg config.SearchWorkersConfig) *searchWorkerPool {
+	cpuWorkers := int(workersCfg.CPUWorkers)
+	if cpuWorkers == 0 {
+		cpuWorkers = runtime.GOMAXPROCS(0)
+	}
+	ioWorkers := int(workersCfg.IOWorkers)
+	if ioWorkers == 0 {
+		ioWorkers = 2 * cpuWorkers
+	}
+	return &searchWorkerPool{
+		cpuSlots:           make(chan struct{}, cpuWorkers),
+		numIOWorkers:       ioWorkers,
+		ioJobs:             make(chan func(), ioWorkers*IO_QUEUE_DEPTH_PER_WORKER),
+		maxWorkersPerQuery: getMaxWorkersPerQuery(workersCfg, cpuWorkers),
+	}
+}
+
+func getMaxWorkersPerQuery(workersCfg config.SearchWorkersConfig, cpuWorkers int) int64 {
+	maxWorkersPerQuery := int64(workersCfg.MaxWorkersPerQuery)
+	if maxWorkersPerQuery == 0 {
+		maxWorkersPerQuery = DEFAULT_MAX_WORKERS_PER_QUERY
+	}
+	if maxWorkersPerQuery > int64(cpuWorkers) {
+		maxWorkersPerQuery = int64(cpuWorkers)
+	}
+	return maxWorkersPerQuery
+}
+
+func ioWorker(ioJobs chan func()) {
+	for read := range ioJobs {
+		read()
+	}
+}
+
+/*
+Returns the number of block workers each segment search of a query runs. A query can ask for the number it wants
+with a hint, it is at most the number of cpu workers. A hint of 0 uses the configured number
+*/
+func GetWorkersPerSegment(hint int64) int64 {
+	pool := getPool()
+	if hint <= 0 {
+		return atomic.LoadInt64(&pool.maxWorkersPerQuery)
+	}
+	if hint > int64(cap(pool.cpuSlots)) {
+		return int64(cap(pool.cpuSlots))
+	}
+	return hint
+}
+
+// Blocks until a cpu worker is free. Every call must be followed by a ReleaseCPUWorker
+func AcquireCPUWorker() {
+	getPool().cpuSlots <- struct{}{}
+}
+
+func ReleaseCPUWorker() {
+	<-getPool().cpuSlots
+}
+
+// Runs the read on an io worker. Returns false without running it if too many reads are already waiting
+func TrySubmitRead(read func()) bool {
+	select {
+	case getPool().ioJobs <- read:
+		return true
+	default:
+		return false
+	}
+}
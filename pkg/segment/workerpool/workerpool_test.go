@@ -0,0 +1,61 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workerpool
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/siglens/siglens/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_newSearchWorkerPool(t *testing.T) {
+	pool := newSearchWorkerPool(config.SearchWorkersConfig{})
+	numCPU := runtime.GOMAXPROCS(0)
+	assert.Equal(t, numCPU, cap(pool.cpuSlots))
+	assert.Equal(t, 2*numCPU, pool.numIOWorkers)
+	assert.Equal(t, 2*numCPU*IO_QUEUE_DEPTH_PER_WORKER, cap(pool.ioJobs))
+	expectedPerQuery := int64(DEFAULT_MAX_WORKERS_PER_QUERY)
+	if expectedPerQuery > int64(numCPU) {
+		expectedPerQuery = int64(numCPU)
+	}
+	assert.Equal(t, expectedPerQuery, pool.maxWorkersPerQuery)
+
+	pool = newSearchWorkerPool(config.SearchWorkersConfig{CPUWorkers: 3, IOWorkers: 5, MaxWorkersPerQuery: 8})
+	assert.Equal(t, 3, cap(pool.cpuSlots))
+	assert.Equal(t, 5, pool.numIOWorkers)
+	assert.Equal(t, int64(3), pool.maxWorkersPerQuery, "a query can't run more workers than there are cpu workers")
+}
+
+func Test_GetWorkersPerSegment(t *testing.T) {
+	pool := getPool()
+	numCPU := int64(cap(pool.cpuSlots))
+	assert.Equal(t, pool.maxWorkersPerQuery, GetWorkersPerSegment(0))
+	assert.Equal(t, pool.maxWorkersPerQuery, GetWorkersPerSegment(-2))
+	assert.Equal(t, int64(1), GetWorkersPerSegment(1))
+	assert.Equal(t, numCPU, GetWorkersPerSegment(numCPU+10))
+}
+
+func Test_TrySubmitRead(t *testing.T) {
+	done := make(chan struct{})
+	assert.True(t, TrySubmitRead(func() { close(done) }))
+	<-done
+
+	AcquireCPUWorker()
+	ReleaseCPUWorker()
+}
@@ -0,0 +1,481 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package labelindex
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/promql/v2/pkg/labels"
+	"github.com/siglens/siglens/pkg/config"
+	log "github.com/sirupsen/logrus"
+)
+
+/*
+	Inverted index over the labels of all metric series, used to answer label names, label values and series
+	requests without reading the tags trees or the datapoints.
+
+	Each org has its own index mapping a label name and value to the tsids of the series with that label. The
+	index is snapshotted to disk every minute and loaded on startup.
+*/
+
+const METRIC_NAME_LABEL = "__name__"
+
+const LABEL_INDEX_FLUSH_SLEEP_DURATION = 1 * time.Minute
+
+type seriesInfo struct {
+	Labels map[string]string `json:"labels"`
+	MinTs  uint32            `json:"minTs"` // earliest datapoint seen for the series
+	MaxTs  uint32            `json:"maxTs"` // latest datapoint seen for the series
+}
+
+type orgLabelIndex struct {
+	rwLock *sync.RWMutex
+	// maps a tsid to its labels and time range
+	series map[uint64]*seriesInfo
+	// maps a label name to its values, and the values to the tsids having the label
+	postings map[string]map[string]map[uint64]struct{}
+	// set when series are added or their time ranges change after the last snapshot
+	dirty uint32
+}
+
+var allIndexesLock *sync.RWMutex = &sync.RWMutex{}
+var allIndexes = make(map[uint64]*orgLabelIndex)
+
+// Loads the snapshots of the label indexes and starts flushing them periodically
+func InitLabelIndex() {
+	loadSnapshots()
+	go timeBasedLabelIndexFlush()
+}
+
+func newOrgLabelIndex() *orgLabelIndex {
+	return &orgLabelIndex{
+		rwLock:   &sync.RWMutex{},
+		series:   make(map[uint64]*seriesInfo),
+		postings: make(map[string]map[string]map[uint64]struct{}),
+	}
+}
+
+func getOrgIndex(orgid uint64, create bool) *orgLabelIndex {
+	allIndexesLock.RLock()
+	idx, ok := allIndexes[orgid]
+	allIndexesLock.RUnlock()
+	if ok || !create {
+		return idx
+	}
+
+	allIndexesLock.Lock()
+	defer allIndexesLock.Unlock()
+	idx, ok = allIndexes[orgid]
+	if !ok {
+		idx = newOrgLabelIndex()
+		allIndexes[orgid] = idx
+	}
+	return idx
+}
+
+/*
+Extends the time range of an indexed series to include timestamp.
+
+Returns false if the series is not in the index yet, in which case the caller needs to call AddSeries
+*/
+func UpdateSeries(orgid uint64, tsid uint64, timestamp uint32) bool {
+	idx := getOrgIndex(orgid, false)
+	if idx == nil {
+		return false
+	}
+	idx.rwLock.RLock()
+	info, ok := idx.series[tsid]
+	idx.rwLock.RUnlock()
+	if !ok {
+		return false
+	}
+	if extendTimeRange(info, timestamp) {
+		atomic.StoreUint32(&idx.dirty, 1)
+	}
+	return true
+}
+
+// Returns true if the time range of the series changed
+func extendTimeRange(info *seriesInfo, timestamp uint32) bool {
+	changed := false
+	for {
+		maxTs := atomic.LoadUint32(&info.MaxTs)
+		if timestamp <= maxTs || atomic.CompareAndSwapUint32(&info.MaxTs, maxTs, timestamp) {
+			changed = changed || timestamp > maxTs
+			break
+		}
+	}
+	for {
+		minTs := atomic.LoadUint32(&info.MinTs)
+		if timestamp >= minTs || atomic.CompareAndSwapUint32(&info.MinTs, minTs, timestamp) {
+			changed = changed || timestamp < minTs
+			break
+		}
+	}
+	return changed
+}
+
+// Adds a series to the index. seriesLabels must include the metric name as __name__
+func AddSeries(orgid uint64, tsid uint64, seriesLabels map[string]string, timestamp uint32) {
+	idx := getOrgIndex(orgid, true)
+	idx.rwLock.Lock()
+	defer idx.rwLock.Unlock()
+	if info, ok := idx.series[tsid]; ok {
+		extendTimeRange(info, timestamp)
+		return
+	}
+	idx.addSeries(tsid, &seriesInfo{Labels: seriesLabels, MinTs: timestamp, MaxTs: timestamp})
+	atomic.StoreUint32(&idx.dirty, 1)
+}
+
+// Caller is responsible for holding the write lock
+func (idx *orgLabelIndex) addSeries(tsid uint64, info *seriesInfo) {
+	idx.series[tsid] = info
+	for name, value := range info.Labels {
+		values, ok := idx.postings[name]
+		if !ok {
+			values = make(map[string]map[uint64]struct{})
+			idx.postings[name] = values
+		}
+		tsids, ok := values[value]
+		if !ok {
+			tsids = make(map[uint64]struct{})
+			values[value] = tsids
+		}
+		tsids[tsid] = struct{}{}
+	}
+}
+
+// Caller is responsible for holding the write lock
+func (idx *orgLabelIndex) removeSeries(tsid uint64) {
+	info, ok := idx.series[tsid]
+	if !ok {
+		return
+	}
+	delete(idx.series, tsid)
+	for name, value := range info.Labels {
+		tsids := idx.postings[name][value]
+		delete(tsids, tsid)
+		if len(tsids) == 0 {
+			delete(idx.postings[name], value)
+		}
+		if len(idx.postings[name]) == 0 {
+			delete(idx.postings, name)
+		}
+	}
+}
+
+/*
+Returns the tsids of the series matching any of the selectors with datapoints in [startTs, endTs].
+All series in the time range match if there are no selectors.
+
+Caller is responsible for holding the read lock
+*/
+func (idx *orgLabelIndex) getMatchingTsids(selectors [][]*labels.Matcher, startTs uint32, endTs uint32) map[uint64]struct{} {
+	retVal := make(map[uint64]struct{})
+	if len(selectors) == 0 {
+		for tsid, info := range idx.series {
+			if inTimeRange(info, startTs, endTs) {
+				retVal[tsid] = struct{}{}
+			}
+		}
+		return retVal
+	}
+
+	for _, matchers := range selectors {
+		for tsid := range idx.getCandidates(matchers) {
+			if _, ok := retVal[tsid]; ok {
+				continue
+			}
+			info := idx.series[tsid]
+			if inTimeRange(info, startTs, endTs) && matchesAll(info, matchers) {
+				retVal[tsid] = struct{}{}
+			}
+		}
+	}
+	return retVal
+}
+
+/*
+Returns the tsids that have to be checked against the matchers: the smallest posting list of the equality
+matchers, or all tsids if there are none
+*/
+func (idx *orgLabelIndex) getCandidates(matchers []*labels.Matcher) map[uint64]struct{} {
+	var candidates map[uint64]struct{}
+	found := false
+	for _, m := range matchers {
+		if m.Type != labels.MatchEqual || m.Value == "" {
+			continue
+		}
+		tsids := idx.postings[m.Name][m.Value]
+		if !found || len(tsids) < len(candidates) {
+			candidates = tsids
+			found = true
+		}
+	}
+	if found {
+		return candidates
+	}
+
+	candidates = make(map[uint64]struct{}, len(idx.series))
+	for tsid := range idx.series {
+		candidates[tsid] = struct{}{}
+	}
+	return candidates
+}
+
+func inTimeRange(info *seriesInfo, startTs uint32, endTs uint32) bool {
+	return atomic.LoadUint32(&info.MinTs) <= endTs && atomic.LoadUint32(&info.MaxTs) >= startTs
+}
+
+// Labels that a series doesn't have are matched as empty values, like in Prometheus
+func matchesAll(info *seriesInfo, matchers []*labels.Matcher) bool {
+	for _, m := range matchers {
+		if !m.Matches(info.Labels[m.Name]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the sorted names of the labels of the matching series
+func GetLabelNames(orgid uint64, selectors [][]*labels.Matcher, startTs uint32, endTs uint32) []string {
+	retVal := make([]string, 0)
+	idx := getOrgIndex(orgid, false)
+	if idx == nil {
+		return retVal
+	}
+	idx.rwLock.RLock()
+	defer idx.rwLock.RUnlock()
+
+	names := make(map[string]struct{})
+	for tsid := range idx.getMatchingTsids(selectors, startTs, endTs) {
+		for name := range idx.series[tsid].Labels {
+			names[name] = struct{}{}
+		}
+	}
+	for name := range names {
+		retVal = append(retVal, name)
+	}
+	sort.Strings(retVal)
+	return retVal
+}
+
+// Returns the sorted values of the label across the matching series
+func GetLabelValues(orgid uint64, labelName string, selectors [][]*labels.Matcher, startTs uint32, endTs uint32) []string {
+	retVal := make([]string, 0)
+	idx := getOrgIndex(orgid, false)
+	if idx == nil {
+		return retVal
+	}
+	idx.rwLock.RLock()
+	defer idx.rwLock.RUnlock()
+
+	values := make(map[string]struct{})
+	if len(selectors) == 0 {
+		// only the series with the label need to be checked
+		for value, tsids := range idx.postings[labelName] {
+			for tsid := range tsids {
+				if inTimeRange(idx.series[tsid], startTs, endTs) {
+					values[value] = struct{}{}
+					break
+				}
+			}
+		}
+	} else {
+		for tsid := range idx.getMatchingTsids(selectors, startTs, endTs) {
+			if value, ok := idx.series[tsid].Labels[labelName]; ok {
+				values[value] = struct{}{}
+			}
+		}
+	}
+	for value := range values {
+		retVal = append(retVal, value)
+	}
+	sort.Strings(retVal)
+	return retVal
+}
+
+// Returns the label sets of the series matching any of the selectors
+func GetSeries(orgid uint64, selectors [][]*labels.Matcher, startTs uint32, endTs uint32) []map[string]string {
+	retVal := make([]map[string]string, 0)
+	idx := getOrgIndex(orgid, false)
+	if idx == nil {
+		return retVal
+	}
+	idx.rwLock.RLock()
+	defer idx.rwLock.RUnlock()
+
+	for tsid := range idx.getMatchingTsids(selectors, startTs, endTs) {
+		seriesLabels := make(map[string]string, len(idx.series[tsid].Labels))
+		for name, value := range idx.series[tsid].Labels {
+			seriesLabels[name] = value
+		}
+		retVal = append(retVal, seriesLabels)
+	}
+	sort.Slice(retVal, func(i, j int) bool {
+		return retVal[i][METRIC_NAME_LABEL] < retVal[j][METRIC_NAME_LABEL]
+	})
+	return retVal
+}
+
+func getLabelIndexDir() string {
+	return config.GetDataPath() + config.GetHostID() + "/labelindex/"
+}
+
+func timeBasedLabelIndexFlush() {
+	for {
+		time.Sleep(LABEL_INDEX_FLUSH_SLEEP_DURATION)
+		removeExpiredSeries(time.Now())
+		flushSnapshots()
+	}
+}
+
+// Drops the series whose latest datapoint is older than the retention
+func removeExpiredSeries(now time.Time) {
+	minTs := uint32(now.Add(-time.Duration(config.GetRetentionHours()) * time.Hour).Unix())
+	allIndexesLock.RLock()
+	defer allIndexesLock.RUnlock()
+	for _, idx := range allIndexes {
+		idx.rwLock.Lock()
+		for tsid, info := range idx.series {
+			if atomic.LoadUint32(&info.MaxTs) < minTs {
+				idx.removeSeries(tsid)
+				atomic.StoreUint32(&idx.dirty, 1)
+			}
+		}
+		idx.rwLock.Unlock()
+	}
+}
+
+type snapshotEntry struct {
+	Tsid uint64 `json:"tsid"`
+	seriesInfo
+}
+
+func flushSnapshots() {
+	allIndexesLock.RLock()
+	defer allIndexesLock.RUnlock()
+	for orgid, idx := range allIndexes {
+		if !atomic.CompareAndSwapUint32(&idx.dirty, 1, 0) {
+			continue
+		}
+		err := idx.writeSnapshot(path.Join(getLabelIndexDir(), strconv.FormatUint(orgid, 10)+".json"))
+		if err != nil {
+			log.Errorf("flushSnapshots: failed to write the label index of orgid %v, err: %v", orgid, err)
+			atomic.StoreUint32(&idx.dirty, 1)
+		}
+	}
+}
+
+// Writes one json line per series to a temporary file that replaces fileName once it is complete
+func (idx *orgLabelIndex) writeSnapshot(fileName string) error {
+	err := os.MkdirAll(path.Dir(fileName), 0764)
+	if err != nil {
+		log.Errorf("writeSnapshot: failed to create directory for %v, err: %v", fileName, err)
+		return err
+	}
+	tmpFileName := fileName + ".tmp"
+	fd, err := os.OpenFile(tmpFileName, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		log.Errorf("writeSnapshot: failed to open %v, err: %v", tmpFileName, err)
+		return err
+	}
+	defer fd.Close()
+
+	w := bufio.NewWriter(fd)
+	encoder := json.NewEncoder(w)
+	idx.rwLock.RLock()
+	for tsid, info := range idx.series {
+		entry := snapshotEntry{Tsid: tsid}
+		entry.Labels = info.Labels
+		entry.MinTs = atomic.LoadUint32(&info.MinTs)
+		entry.MaxTs = atomic.LoadUint32(&info.MaxTs)
+		err = encoder.Encode(&entry)
+		if err != nil {
+			break
+		}
+	}
+	idx.rwLock.RUnlock()
+	if err != nil {
+		log.Errorf("writeSnapshot: failed to encode a series to %v, err: %v", tmpFileName, err)
+		return err
+	}
+
+	err = w.Flush()
+	if err != nil {
+		log.Errorf("writeSnapshot: failed to flush %v, err: %v", tmpFileName, err)
+		return err
+	}
+	return os.Rename(tmpFileName, fileName)
+}
+
+func loadSnapshots() {
+	files, err := os.ReadDir(getLabelIndexDir())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Errorf("loadSnapshots: failed to read the label index directory, err: %v", err)
+		}
+		return
+	}
+	for _, file := range files {
+		name := file.Name()
+		if path.Ext(name) != ".json" {
+			continue
+		}
+		orgid, err := strconv.ParseUint(name[:len(name)-len(".json")], 10, 64)
+		if err != nil {
+			continue
+		}
+		err = getOrgIndex(orgid, true).readSnapshot(path.Join(getLabelIndexDir(), name))
+		if err != nil {
+			log.Errorf("loadSnapshots: failed to read the label index of orgid %v, err: %v", orgid, err)
+		}
+	}
+}
+
+func (idx *orgLabelIndex) readSnapshot(fileName string) error {
+	fd, err := os.Open(fileName)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	idx.rwLock.Lock()
+	defer idx.rwLock.Unlock()
+	scanner := bufio.NewScanner(fd)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var entry snapshotEntry
+		err := json.Unmarshal(scanner.Bytes(), &entry)
+		if err != nil {
+			log.Errorf("readSnapshot: failed to unmarshal a series of %v, err: %v", fileName, err)
+			continue
+		}
+		info := entry.seriesInfo
+		idx.addSeries(entry.Tsid, &info)
+	}
+	return scanner.Err()
+}
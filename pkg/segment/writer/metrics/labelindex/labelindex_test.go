@@ -0,0 +1,122 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package labelindex
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/influxdata/promql/v2/pkg/labels"
+	"github.com/stretchr/testify/assert"
+)
+
+func addTestSeries(orgid uint64) {
+	AddSeries(orgid, 1, map[string]string{METRIC_NAME_LABEL: "cpu", "host": "a", "dc": "east"}, 100)
+	AddSeries(orgid, 2, map[string]string{METRIC_NAME_LABEL: "cpu", "host": "b"}, 100)
+	AddSeries(orgid, 3, map[string]string{METRIC_NAME_LABEL: "mem", "host": "a", "dc": "west"}, 500)
+	UpdateSeries(orgid, 2, 300)
+}
+
+func Test_getLabelNamesAndValues(t *testing.T) {
+	orgid := uint64(1001)
+	assert.False(t, UpdateSeries(orgid, 1, 100))
+	addTestSeries(orgid)
+
+	assert.Equal(t, []string{METRIC_NAME_LABEL, "dc", "host"}, GetLabelNames(orgid, nil, 0, 1000))
+	assert.Equal(t, []string{METRIC_NAME_LABEL, "host"}, GetLabelNames(orgid, nil, 200, 400))
+	assert.Equal(t, []string{"a", "b"}, GetLabelValues(orgid, "host", nil, 0, 1000))
+	assert.Equal(t, []string{"east", "west"}, GetLabelValues(orgid, "dc", nil, 0, 1000))
+	assert.Equal(t, []string{"west"}, GetLabelValues(orgid, "dc", nil, 400, 1000))
+	assert.Equal(t, []string{}, GetLabelValues(orgid, "missing", nil, 0, 1000))
+	assert.Equal(t, []string{}, GetLabelNames(orgid+1, nil, 0, 1000))
+
+	cpuSelector := [][]*labels.Matcher{{newTestMatcher(labels.MatchEqual, METRIC_NAME_LABEL, "cpu")}}
+	assert.Equal(t, []string{"east"}, GetLabelValues(orgid, "dc", cpuSelector, 0, 1000))
+	assert.Equal(t, []string{"a", "b"}, GetLabelValues(orgid, "host", cpuSelector, 0, 1000))
+}
+
+func Test_getSeries(t *testing.T) {
+	orgid := uint64(1002)
+	addTestSeries(orgid)
+
+	selectors := [][]*labels.Matcher{{newTestMatcher(labels.MatchEqual, "host", "a")}}
+	series := GetSeries(orgid, selectors, 0, 1000)
+	assert.Len(t, series, 2)
+	assert.Equal(t, "cpu", series[0][METRIC_NAME_LABEL])
+	assert.Equal(t, "mem", series[1][METRIC_NAME_LABEL])
+
+	// series without the dc label match an empty value
+	selectors = [][]*labels.Matcher{{
+		newTestMatcher(labels.MatchEqual, METRIC_NAME_LABEL, "cpu"),
+		newTestMatcher(labels.MatchEqual, "dc", ""),
+	}}
+	series = GetSeries(orgid, selectors, 0, 1000)
+	assert.Equal(t, []map[string]string{{METRIC_NAME_LABEL: "cpu", "host": "b"}}, series)
+
+	selectors = [][]*labels.Matcher{{newTestMatcher(labels.MatchRegexp, "dc", "e.*")}}
+	series = GetSeries(orgid, selectors, 0, 1000)
+	assert.Len(t, series, 1)
+	assert.Equal(t, "east", series[0]["dc"])
+
+	selectors = [][]*labels.Matcher{{newTestMatcher(labels.MatchNotEqual, "host", "a")}}
+	series = GetSeries(orgid, selectors, 0, 1000)
+	assert.Len(t, series, 1)
+	assert.Equal(t, "b", series[0]["host"])
+
+	// selectors are or'ed
+	selectors = [][]*labels.Matcher{
+		{newTestMatcher(labels.MatchEqual, "host", "b")},
+		{newTestMatcher(labels.MatchEqual, METRIC_NAME_LABEL, "mem")},
+	}
+	assert.Len(t, GetSeries(orgid, selectors, 0, 1000), 2)
+	assert.Len(t, GetSeries(orgid, selectors, 0, 50), 0)
+}
+
+func Test_labelIndexSnapshot(t *testing.T) {
+	orgid := uint64(1003)
+	addTestSeries(orgid)
+	fileName := path.Join(t.TempDir(), "1003.json")
+
+	err := getOrgIndex(orgid, false).writeSnapshot(fileName)
+	assert.Nil(t, err)
+	_, err = os.Stat(fileName + ".tmp")
+	assert.True(t, os.IsNotExist(err))
+
+	loaded := newOrgLabelIndex()
+	err = loaded.readSnapshot(fileName)
+	assert.Nil(t, err)
+	assert.Len(t, loaded.series, 3)
+	assert.Equal(t, uint32(100), loaded.series[2].MinTs)
+	assert.Equal(t, uint32(300), loaded.series[2].MaxTs)
+	assert.Equal(t, map[string]string{METRIC_NAME_LABEL: "mem", "host": "a", "dc": "west"}, loaded.series[3].Labels)
+	assert.Len(t, loaded.postings["host"]["a"], 2)
+
+	loaded.removeSeries(1)
+	loaded.removeSeries(3)
+	_, ok := loaded.postings["dc"]
+	assert.False(t, ok)
+	assert.Len(t, loaded.postings["host"]["a"], 0)
+}
+
+func newTestMatcher(t labels.MatchType, name string, value string) *labels.Matcher {
+	m, err := labels.NewMatcher(t, name, value)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
@@ -39,12 +39,14 @@ import (
 	"github.com/siglens/siglens/pkg/blob"
 	dtu "github.com/siglens/siglens/pkg/common/dtypeutils"
 	"github.com/siglens/siglens/pkg/config"
+	"github.com/siglens/siglens/pkg/health/heartbeat"
 	"github.com/siglens/siglens/pkg/segment/memory"
 	"github.com/siglens/siglens/pkg/segment/query/summary"
 	"github.com/siglens/siglens/pkg/segment/reader/microreader"
 	"github.com/siglens/siglens/pkg/segment/structs"
 	"github.com/siglens/siglens/pkg/segment/utils"
 	"github.com/siglens/siglens/pkg/segment/writer/metrics/compress"
+	"github.com/siglens/siglens/pkg/segment/writer/metrics/labelindex"
 	"github.com/siglens/siglens/pkg/segment/writer/metrics/meta"
 	"github.com/siglens/siglens/pkg/segment/writer/suffix"
 	toputils "github.com/siglens/siglens/pkg/utils"
@@ -149,6 +151,7 @@ func InitMetricsSegStore() {
 	go timeBasedMetricsFlush()
 	go timeBasedRotate()
 	go timeBasedTagsTreeFlush()
+	labelindex.InitLabelIndex()
 }
 
 func initOrgMetrics(orgid uint64) error {
@@ -244,8 +247,10 @@ func timeBasedRotate() {
 }
 
 func timeBasedMetricsFlush() {
+	heartbeat.Register(heartbeat.METRICS_STORE, METRICS_BLK_FLUSH_SLEEP_DURATION*time.Second)
 	for {
 		time.Sleep(METRICS_BLK_FLUSH_SLEEP_DURATION * time.Second)
+		var flushErr error
 		for _, ms := range GetAllMetricsSegments() {
 			encSize := atomic.LoadUint64(&ms.mBlock.encodedSize)
 			if encSize > 0 {
@@ -253,10 +258,12 @@ func timeBasedMetricsFlush() {
 				err := ms.mBlock.flushBlock(ms.metricsKeyBase, ms.Suffix, ms.currBlockNum)
 				if err != nil {
 					log.Errorf("timeBasedRotateMetricsBlock: flush block %d for metric segment %s due to time failed", ms.currBlockNum, ms.metricsKeyBase)
+					flushErr = err
 				}
 				ms.rwLock.Unlock()
 			}
 		}
+		heartbeat.Record(heartbeat.METRICS_STORE, flushErr)
 	}
 }
 
@@ -447,6 +454,9 @@ func EncodeDatapoint(mName []byte, tags *TagsHolder, dp float64, timestamp uint3
 	atomic.AddUint64(&mSeg.bytesReceived, nBytes)
 	atomic.AddUint64(&mSeg.datapointCount, 1)
 
+	if !labelindex.UpdateSeries(orgid, tsid, timestamp) {
+		labelindex.AddSeries(orgid, tsid, tags.getLabels(mName), timestamp)
+	}
 	return nil
 }
 
@@ -0,0 +1,268 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollup
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/siglens/siglens/pkg/config"
+	"github.com/siglens/siglens/pkg/segment/structs"
+	"github.com/siglens/siglens/pkg/segment/writer/metrics/meta"
+	log "github.com/sirupsen/logrus"
+)
+
+const DOWNSAMPLE_SLEEP_DURATION = 10 * time.Minute
+
+const DEFAULT_FIVE_MIN_AFTER_HOURS = 24
+const DEFAULT_ONE_HOUR_AFTER_HOURS = 24 * 7
+
+// rollups that exist for a rotated metrics segment
+type segmentRollups struct {
+	resolutions map[uint32]bool
+	rawDeleted  bool
+}
+
+var rollupStatesLock *sync.RWMutex = &sync.RWMutex{}
+
+// maps a metrics segment key to its rollups. Segments without rollups have no entry
+var rollupStates = make(map[string]*segmentRollups)
+
+/*
+Loads the rollups of the local metrics segments and starts the background downsampler, which rolls up
+metrics segments once they are older than the configured ages
+*/
+func InitMetricsDownsampler() {
+	allMetas, err := meta.ReadMetricsMeta(getMetricsMetaFileName())
+	if err != nil {
+		log.Errorf("InitMetricsDownsampler: failed to read metrics meta, err: %v", err)
+	}
+	for mKey := range allMetas {
+		loadRollupState(mKey)
+	}
+	go runDownsampler()
+}
+
+func runDownsampler() {
+	time.Sleep(1 * time.Minute) // sleep for 1min for the rest of the system to come up
+	for {
+		if config.GetMetricsDownsamplingConfig().Enabled {
+			downsampleSegments(time.Now())
+		}
+		time.Sleep(DOWNSAMPLE_SLEEP_DURATION)
+	}
+}
+
+func getMetricsMetaFileName() string {
+	return path.Join(config.GetCurrentNodeIngestDir(), meta.MetricsMetaSuffix)
+}
+
+func getRawDeletedMarkerName(mKey string) string {
+	return fmt.Sprintf("%s.rawdeleted", mKey)
+}
+
+func loadRollupState(mKey string) {
+	state := &segmentRollups{resolutions: make(map[uint32]bool)}
+	for _, resolution := range ALL_RESOLUTIONS {
+		if _, err := os.Stat(GetRollupFileName(mKey, resolution)); err == nil {
+			state.resolutions[resolution] = true
+		}
+	}
+	if _, err := os.Stat(getRawDeletedMarkerName(mKey)); err == nil {
+		state.rawDeleted = true
+	}
+	if len(state.resolutions) == 0 {
+		return
+	}
+	rollupStatesLock.Lock()
+	rollupStates[mKey] = state
+	rollupStatesLock.Unlock()
+}
+
+// Returns a copy of the rollups of the metrics segment
+func getRollupState(mKey string) segmentRollups {
+	rollupStatesLock.RLock()
+	defer rollupStatesLock.RUnlock()
+	state, ok := rollupStates[mKey]
+	if !ok {
+		return segmentRollups{}
+	}
+	resolutions := make(map[uint32]bool, len(state.resolutions))
+	for resolution := range state.resolutions {
+		resolutions[resolution] = true
+	}
+	return segmentRollups{resolutions: resolutions, rawDeleted: state.rawDeleted}
+}
+
+func getTierAges() map[uint32]time.Duration {
+	dsConfig := config.GetMetricsDownsamplingConfig()
+	fiveMinAfterHours := dsConfig.FiveMinAfterHours
+	if fiveMinAfterHours == 0 {
+		fiveMinAfterHours = DEFAULT_FIVE_MIN_AFTER_HOURS
+	}
+	oneHourAfterHours := dsConfig.OneHourAfterHours
+	if oneHourAfterHours == 0 {
+		oneHourAfterHours = DEFAULT_ONE_HOUR_AFTER_HOURS
+	}
+	return map[uint32]time.Duration{
+		FIVE_MIN_RESOLUTION: time.Duration(fiveMinAfterHours) * time.Hour,
+		ONE_HOUR_RESOLUTION: time.Duration(oneHourAfterHours) * time.Hour,
+	}
+}
+
+/*
+Returns the resolutions the segment still needs rollups for, given the age of its latest datapoint.
+If the raw datapoints will be deleted, all rollups are created first so that no resolution is lost
+*/
+func getMissingResolutions(state segmentRollups, age time.Duration, tierAges map[uint32]time.Duration, deleteRaw bool) []uint32 {
+	retVal := make([]uint32, 0)
+	for _, resolution := range ALL_RESOLUTIONS {
+		if state.resolutions[resolution] {
+			continue
+		}
+		if deleteRaw || age >= tierAges[resolution] {
+			retVal = append(retVal, resolution)
+		}
+	}
+	return retVal
+}
+
+func downsampleSegments(now time.Time) {
+	allMetas, err := meta.ReadMetricsMeta(getMetricsMetaFileName())
+	if err != nil {
+		log.Errorf("downsampleSegments: failed to read metrics meta, err: %v", err)
+		return
+	}
+
+	tierAges := getTierAges()
+	rawRetention := time.Duration(config.GetMetricsDownsamplingConfig().RawRetentionHours) * time.Hour
+	for mKey, mMeta := range allMetas {
+		age := now.Sub(time.Unix(int64(mMeta.LatestEpochSec), 0))
+		deleteRaw := rawRetention > 0 && age >= rawRetention
+		state := getRollupState(mKey)
+		missing := getMissingResolutions(state, age, tierAges, deleteRaw)
+		if len(missing) > 0 && !state.rawDeleted {
+			err := rollupSegment(mMeta, missing)
+			if err != nil {
+				log.Errorf("downsampleSegments: failed to roll up metrics segment %v, err: %v", mKey, err)
+				continue
+			}
+		}
+		if deleteRaw && !state.rawDeleted {
+			deleteRawDatapoints(mMeta)
+		}
+	}
+	removeDeletedSegments(allMetas)
+}
+
+func rollupSegment(mMeta *structs.MetricsMeta, resolutions []uint32) error {
+	sTime := time.Now()
+	allRollups, err := computeRollups(mMeta.MSegmentDir, resolutions)
+	if err != nil {
+		return err
+	}
+	for _, resolution := range resolutions {
+		err := writeRollupFile(GetRollupFileName(mMeta.MSegmentDir, resolution), allRollups[resolution])
+		if err != nil {
+			return err
+		}
+		rollupStatesLock.Lock()
+		state, ok := rollupStates[mMeta.MSegmentDir]
+		if !ok {
+			state = &segmentRollups{resolutions: make(map[uint32]bool)}
+			rollupStates[mMeta.MSegmentDir] = state
+		}
+		state.resolutions[resolution] = true
+		rollupStatesLock.Unlock()
+	}
+	log.Infof("rollupSegment: created %v rollups of metrics segment %v in %v", resolutions, mMeta.MSegmentDir, time.Since(sTime))
+	return nil
+}
+
+/*
+Deletes the tso and tsg files of a metrics segment that has all rollups. The block summaries are kept,
+as the search requests of the segment are still built from them
+*/
+func deleteRawDatapoints(mMeta *structs.MetricsMeta) {
+	rollupStatesLock.Lock()
+	state, ok := rollupStates[mMeta.MSegmentDir]
+	if !ok || len(state.resolutions) != len(ALL_RESOLUTIONS) {
+		rollupStatesLock.Unlock()
+		return
+	}
+	state.rawDeleted = true
+	rollupStatesLock.Unlock()
+
+	fd, err := os.Create(getRawDeletedMarkerName(mMeta.MSegmentDir))
+	if err != nil {
+		log.Errorf("deleteRawDatapoints: failed to create the marker of %v, err: %v", mMeta.MSegmentDir, err)
+		return
+	}
+	fd.Close()
+
+	for blkNum := uint16(0); blkNum < mMeta.NumBlocks; blkNum++ {
+		for _, ext := range []string{"tso", "tsg"} {
+			fileName := fmt.Sprintf("%s_%d.%s", mMeta.MSegmentDir, blkNum, ext)
+			err := os.Remove(fileName)
+			if err != nil && !os.IsNotExist(err) {
+				log.Errorf("deleteRawDatapoints: failed to delete %v, err: %v", fileName, err)
+			}
+		}
+	}
+	log.Infof("deleteRawDatapoints: deleted the raw datapoints of metrics segment %v", mMeta.MSegmentDir)
+}
+
+// Forgets the rollups of segments deleted by retention
+func removeDeletedSegments(allMetas map[string]*structs.MetricsMeta) {
+	rollupStatesLock.Lock()
+	defer rollupStatesLock.Unlock()
+	for mKey := range rollupStates {
+		if _, ok := allMetas[mKey]; !ok {
+			delete(rollupStates, mKey)
+		}
+	}
+}
+
+/*
+Returns the rollup resolution a search of the rotated metrics segment should read, or 0 to read the raw datapoints.
+
+The coarsest rollup whose resolution evenly divides the downsample interval of the query is used. Segments whose
+raw datapoints were deleted fall back to their finest rollup.
+*/
+func GetSearchResolution(mKey string, dsIntervalSecs uint32) uint32 {
+	state := getRollupState(mKey)
+	if len(state.resolutions) == 0 {
+		return 0
+	}
+	var retVal uint32
+	for _, resolution := range ALL_RESOLUTIONS {
+		if state.resolutions[resolution] && dsIntervalSecs >= resolution && dsIntervalSecs%resolution == 0 {
+			retVal = resolution
+		}
+	}
+	if retVal == 0 && state.rawDeleted {
+		for _, resolution := range ALL_RESOLUTIONS {
+			if state.resolutions[resolution] {
+				return resolution
+			}
+		}
+	}
+	return retVal
+}
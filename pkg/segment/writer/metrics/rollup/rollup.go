@@ -0,0 +1,305 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollup
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/siglens/siglens/pkg/segment/reader/metrics/series"
+	"github.com/siglens/siglens/pkg/segment/reader/microreader"
+	"github.com/siglens/siglens/pkg/segment/structs"
+	"github.com/siglens/siglens/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+/*
+	Defines the rollup files of a rotated metrics segment. A rollup file holds, for every series of the segment,
+	the min/max/sum/count of the datapoints in each time bucket of a resolution.
+
+	File layout, all values little endian:
+	[version 1B][numTsids 4B]
+	numTsids x [tsid 8B][offset of the first bucket 4B][numBuckets 4B], sorted by tsid
+	buckets  x [bucket start 4B][min 8B][max 8B][sum 8B][count 4B], sorted by bucket start per tsid
+*/
+
+const FIVE_MIN_RESOLUTION uint32 = 300
+const ONE_HOUR_RESOLUTION uint32 = 3600
+
+// all rollup resolutions, finest first
+var ALL_RESOLUTIONS = []uint32{FIVE_MIN_RESOLUTION, ONE_HOUR_RESOLUTION}
+
+var VERSION_ROLLUPFILE = []byte{1}
+
+const rollupIndexEntrySize = 16
+const rollupBucketSize = 32
+
+type Bucket struct {
+	Ts    uint32 // start of the bucket
+	Min   float64
+	Max   float64
+	Sum   float64
+	Count uint32
+}
+
+type RollupReader struct {
+	raw     []byte
+	numTsid uint32
+}
+
+func GetRollupFileName(mKey string, resolution uint32) string {
+	return fmt.Sprintf("%s_%ds.mrup", mKey, resolution)
+}
+
+func addToBucket(buckets map[uint32]*Bucket, ts uint32, dp float64, resolution uint32) {
+	bucketTs := (ts / resolution) * resolution
+	b, ok := buckets[bucketTs]
+	if !ok {
+		buckets[bucketTs] = &Bucket{Ts: bucketTs, Min: dp, Max: dp, Sum: dp, Count: 1}
+		return
+	}
+	if dp < b.Min {
+		b.Min = dp
+	}
+	if dp > b.Max {
+		b.Max = dp
+	}
+	b.Sum += dp
+	b.Count++
+}
+
+/*
+Reads all raw datapoints of the rotated metrics segment and returns the buckets of every tsid for each of
+the given resolutions
+*/
+func computeRollups(mKey string, resolutions []uint32) (map[uint32]map[uint64][]Bucket, error) {
+	blockSummaries, err := microreader.ReadMetricsBlockSummaries(fmt.Sprintf("%s.mbsu", mKey))
+	if err != nil {
+		log.Errorf("computeRollups: failed to read block summaries of %v, err: %v", mKey, err)
+		return nil, err
+	}
+
+	tssr, err := series.InitTimeSeriesReader(mKey)
+	if err != nil {
+		log.Errorf("computeRollups: failed to init a time series reader for %v, err: %v", mKey, err)
+		return nil, err
+	}
+	defer tssr.Close()
+
+	queryMetrics := &structs.MetricsQueryProcessingMetrics{
+		UpdateLock: &sync.Mutex{},
+	}
+	// maps a resolution to the buckets of each tsid
+	allBuckets := make(map[uint32]map[uint64]map[uint32]*Bucket, len(resolutions))
+	for _, resolution := range resolutions {
+		allBuckets[resolution] = make(map[uint64]map[uint32]*Bucket)
+	}
+	for _, blockSummary := range blockSummaries {
+		tsbr, err := tssr.InitReaderForBlock(blockSummary.Blknum, queryMetrics)
+		if err != nil {
+			log.Errorf("computeRollups: failed to init a reader for block %v of %v, err: %v", blockSummary.Blknum, mKey, err)
+			return nil, err
+		}
+		for _, tsid := range tsbr.GetAllTSIDs() {
+			tsitr, found, err := tsbr.GetTimeSeriesIterator(tsid)
+			if err != nil {
+				log.Errorf("computeRollups: failed to get the iterator of tsid %v in %v, err: %v", tsid, mKey, err)
+				return nil, err
+			}
+			if !found {
+				continue
+			}
+			tsidBuckets := make([]map[uint32]*Bucket, len(resolutions))
+			for i, resolution := range resolutions {
+				tsidBuckets[i] = allBuckets[resolution][tsid]
+				if tsidBuckets[i] == nil {
+					tsidBuckets[i] = make(map[uint32]*Bucket)
+					allBuckets[resolution][tsid] = tsidBuckets[i]
+				}
+			}
+			for tsitr.Next() {
+				ts, dp := tsitr.At()
+				for i, resolution := range resolutions {
+					addToBucket(tsidBuckets[i], ts, dp, resolution)
+				}
+			}
+			err = tsitr.Err()
+			if err != nil {
+				log.Errorf("computeRollups: iterator of tsid %v in %v failed, err: %v", tsid, mKey, err)
+				return nil, err
+			}
+		}
+	}
+
+	retVal := make(map[uint32]map[uint64][]Bucket, len(resolutions))
+	for resolution, tsidBuckets := range allBuckets {
+		retVal[resolution] = make(map[uint64][]Bucket, len(tsidBuckets))
+		for tsid, buckets := range tsidBuckets {
+			sortedBuckets := make([]Bucket, 0, len(buckets))
+			for _, b := range buckets {
+				sortedBuckets = append(sortedBuckets, *b)
+			}
+			sort.Slice(sortedBuckets, func(i, j int) bool {
+				return sortedBuckets[i].Ts < sortedBuckets[j].Ts
+			})
+			retVal[resolution][tsid] = sortedBuckets
+		}
+	}
+	return retVal, nil
+}
+
+/*
+Writes the buckets of all tsids to fileName. The file is written to a temporary file first and renamed,
+so readers never see a partially written rollup
+*/
+func writeRollupFile(fileName string, tsidBuckets map[uint64][]Bucket) error {
+	allTsids := make([]uint64, 0, len(tsidBuckets))
+	for tsid := range tsidBuckets {
+		allTsids = append(allTsids, tsid)
+	}
+	sort.Slice(allTsids, func(i, j int) bool { return allTsids[i] < allTsids[j] })
+
+	tmpFileName := fileName + ".tmp"
+	fd, err := os.OpenFile(tmpFileName, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		log.Errorf("writeRollupFile: failed to open %v, err: %v", tmpFileName, err)
+		return err
+	}
+	defer fd.Close()
+	w := bufio.NewWriter(fd)
+
+	_, err = w.Write(VERSION_ROLLUPFILE)
+	if err != nil {
+		log.Errorf("writeRollupFile: failed to write to %v, err: %v", tmpFileName, err)
+		return err
+	}
+	_, err = w.Write(utils.Uint32ToBytesLittleEndian(uint32(len(allTsids))))
+	if err != nil {
+		log.Errorf("writeRollupFile: failed to write to %v, err: %v", tmpFileName, err)
+		return err
+	}
+	offset := uint32(1 + 4 + len(allTsids)*rollupIndexEntrySize)
+	for _, tsid := range allTsids {
+		numBuckets := uint32(len(tsidBuckets[tsid]))
+		_, err = w.Write(utils.Uint64ToBytesLittleEndian(tsid))
+		if err == nil {
+			_, err = w.Write(utils.Uint32ToBytesLittleEndian(offset))
+		}
+		if err == nil {
+			_, err = w.Write(utils.Uint32ToBytesLittleEndian(numBuckets))
+		}
+		if err != nil {
+			log.Errorf("writeRollupFile: failed to write to %v, err: %v", tmpFileName, err)
+			return err
+		}
+		offset += numBuckets * rollupBucketSize
+	}
+	for _, tsid := range allTsids {
+		for _, b := range tsidBuckets[tsid] {
+			_, err = w.Write(encodeBucket(b))
+			if err != nil {
+				log.Errorf("writeRollupFile: failed to write to %v, err: %v", tmpFileName, err)
+				return err
+			}
+		}
+	}
+	err = w.Flush()
+	if err != nil {
+		log.Errorf("writeRollupFile: failed to flush %v, err: %v", tmpFileName, err)
+		return err
+	}
+	err = fd.Sync()
+	if err != nil {
+		log.Errorf("writeRollupFile: failed to sync %v, err: %v", tmpFileName, err)
+		return err
+	}
+	err = os.Rename(tmpFileName, fileName)
+	if err != nil {
+		log.Errorf("writeRollupFile: failed to rename %v to %v, err: %v", tmpFileName, fileName, err)
+		return err
+	}
+	return nil
+}
+
+func encodeBucket(b Bucket) []byte {
+	retVal := make([]byte, 0, rollupBucketSize)
+	retVal = append(retVal, utils.Uint32ToBytesLittleEndian(b.Ts)...)
+	retVal = append(retVal, utils.Float64ToBytesLittleEndian(b.Min)...)
+	retVal = append(retVal, utils.Float64ToBytesLittleEndian(b.Max)...)
+	retVal = append(retVal, utils.Float64ToBytesLittleEndian(b.Sum)...)
+	retVal = append(retVal, utils.Uint32ToBytesLittleEndian(b.Count)...)
+	return retVal
+}
+
+func decodeBucket(raw []byte) Bucket {
+	return Bucket{
+		Ts:    utils.BytesToUint32LittleEndian(raw[0:4]),
+		Min:   utils.BytesToFloat64LittleEndian(raw[4:12]),
+		Max:   utils.BytesToFloat64LittleEndian(raw[12:20]),
+		Sum:   utils.BytesToFloat64LittleEndian(raw[20:28]),
+		Count: utils.BytesToUint32LittleEndian(raw[28:32]),
+	}
+}
+
+// Reads the rollup file of the given resolution of the rotated metrics segment
+func ReadRollupFile(mKey string, resolution uint32) (*RollupReader, error) {
+	fileName := GetRollupFileName(mKey, resolution)
+	raw, err := os.ReadFile(fileName)
+	if err != nil {
+		log.Errorf("ReadRollupFile: failed to read %v, err: %v", fileName, err)
+		return nil, err
+	}
+	if len(raw) < 5 || raw[0] != VERSION_ROLLUPFILE[0] {
+		return nil, fmt.Errorf("ReadRollupFile: invalid rollup file %v", fileName)
+	}
+	numTsid := utils.BytesToUint32LittleEndian(raw[1:5])
+	if uint64(len(raw)) < 5+uint64(numTsid)*rollupIndexEntrySize {
+		return nil, fmt.Errorf("ReadRollupFile: rollup file %v is truncated", fileName)
+	}
+	return &RollupReader{raw: raw, numTsid: numTsid}, nil
+}
+
+// Returns the buckets of the tsid and whether the tsid is in the rollup
+func (rr *RollupReader) GetBuckets(tsid uint64) ([]Bucket, bool) {
+	idx := sort.Search(int(rr.numTsid), func(i int) bool {
+		offset := 5 + i*rollupIndexEntrySize
+		return utils.BytesToUint64LittleEndian(rr.raw[offset:offset+8]) >= tsid
+	})
+	if idx >= int(rr.numTsid) {
+		return nil, false
+	}
+	entryOffset := 5 + idx*rollupIndexEntrySize
+	if utils.BytesToUint64LittleEndian(rr.raw[entryOffset:entryOffset+8]) != tsid {
+		return nil, false
+	}
+	offset := utils.BytesToUint32LittleEndian(rr.raw[entryOffset+8 : entryOffset+12])
+	numBuckets := utils.BytesToUint32LittleEndian(rr.raw[entryOffset+12 : entryOffset+16])
+	if uint64(offset)+uint64(numBuckets)*rollupBucketSize > uint64(len(rr.raw)) {
+		log.Errorf("GetBuckets: buckets of tsid %v are out of bounds of the rollup file", tsid)
+		return nil, false
+	}
+
+	retVal := make([]Bucket, numBuckets)
+	for i := range retVal {
+		start := offset + uint32(i)*rollupBucketSize
+		retVal[i] = decodeBucket(rr.raw[start : start+rollupBucketSize])
+	}
+	return retVal, true
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollup
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_addToBucket(t *testing.T) {
+	buckets := make(map[uint32]*Bucket)
+	addToBucket(buckets, 1000, 5, FIVE_MIN_RESOLUTION)
+	addToBucket(buckets, 1100, 2, FIVE_MIN_RESOLUTION)
+	addToBucket(buckets, 1199, 8, FIVE_MIN_RESOLUTION)
+	addToBucket(buckets, 1200, 1, FIVE_MIN_RESOLUTION)
+
+	assert.Len(t, buckets, 2)
+	assert.Equal(t, Bucket{Ts: 900, Min: 2, Max: 8, Sum: 15, Count: 3}, *buckets[900])
+	assert.Equal(t, Bucket{Ts: 1200, Min: 1, Max: 1, Sum: 1, Count: 1}, *buckets[1200])
+}
+
+func Test_ReadWriteRollupFile(t *testing.T) {
+	dir := t.TempDir()
+	mKey := dir + "/1"
+	tsidBuckets := map[uint64][]Bucket{
+		42: {{Ts: 0, Min: 1, Max: 3, Sum: 6, Count: 3}, {Ts: 300, Min: -1.5, Max: 2.5, Sum: 1, Count: 2}},
+		7:  {{Ts: 600, Min: 4, Max: 4, Sum: 4, Count: 1}},
+		99: {},
+	}
+	fileName := GetRollupFileName(mKey, FIVE_MIN_RESOLUTION)
+	err := writeRollupFile(fileName, tsidBuckets)
+	assert.NoError(t, err)
+	_, err = os.Stat(fileName + ".tmp")
+	assert.True(t, os.IsNotExist(err))
+
+	rollupReader, err := ReadRollupFile(mKey, FIVE_MIN_RESOLUTION)
+	assert.NoError(t, err)
+	for tsid, expected := range tsidBuckets {
+		buckets, found := rollupReader.GetBuckets(tsid)
+		assert.True(t, found)
+		assert.Equal(t, expected, buckets)
+	}
+	_, found := rollupReader.GetBuckets(8)
+	assert.False(t, found)
+	_, found = rollupReader.GetBuckets(100)
+	assert.False(t, found)
+
+	_, err = ReadRollupFile(mKey, ONE_HOUR_RESOLUTION)
+	assert.Error(t, err)
+}
+
+func Test_getMissingResolutions(t *testing.T) {
+	tierAges := map[uint32]time.Duration{
+		FIVE_MIN_RESOLUTION: 24 * time.Hour,
+		ONE_HOUR_RESOLUTION: 7 * 24 * time.Hour,
+	}
+	none := segmentRollups{resolutions: map[uint32]bool{}}
+	fiveMin := segmentRollups{resolutions: map[uint32]bool{FIVE_MIN_RESOLUTION: true}}
+
+	assert.Empty(t, getMissingResolutions(none, time.Hour, tierAges, false))
+	assert.Equal(t, []uint32{FIVE_MIN_RESOLUTION}, getMissingResolutions(none, 2*24*time.Hour, tierAges, false))
+	assert.Equal(t, []uint32{FIVE_MIN_RESOLUTION, ONE_HOUR_RESOLUTION}, getMissingResolutions(none, 8*24*time.Hour, tierAges, false))
+	assert.Empty(t, getMissingResolutions(fiveMin, 2*24*time.Hour, tierAges, false))
+	assert.Equal(t, []uint32{ONE_HOUR_RESOLUTION}, getMissingResolutions(fiveMin, 2*24*time.Hour, tierAges, true))
+}
+
+func Test_GetSearchResolution(t *testing.T) {
+	rollupStatesLock.Lock()
+	rollupStates["both"] = &segmentRollups{resolutions: map[uint32]bool{FIVE_MIN_RESOLUTION: true, ONE_HOUR_RESOLUTION: true}}
+	rollupStates["rawDeleted"] = &segmentRollups{resolutions: map[uint32]bool{FIVE_MIN_RESOLUTION: true, ONE_HOUR_RESOLUTION: true}, rawDeleted: true}
+	rollupStatesLock.Unlock()
+	defer removeDeletedSegments(nil)
+
+	assert.Equal(t, uint32(0), GetSearchResolution("none", 3600))
+	assert.Equal(t, uint32(0), GetSearchResolution("both", 60))
+	assert.Equal(t, uint32(0), GetSearchResolution("both", 450))
+	assert.Equal(t, FIVE_MIN_RESOLUTION, GetSearchResolution("both", 600))
+	assert.Equal(t, ONE_HOUR_RESOLUTION, GetSearchResolution("both", 86400))
+	assert.Equal(t, FIVE_MIN_RESOLUTION, GetSearchResolution("rawDeleted", 60))
+	assert.Equal(t, ONE_HOUR_RESOLUTION, GetSearchResolution("rawDeleted", 7200))
+}
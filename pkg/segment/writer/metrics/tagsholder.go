@@ -22,6 +22,7 @@ import (
 
 	jp "github.com/buger/jsonparser"
 	"github.com/cespare/xxhash"
+	"github.com/siglens/siglens/pkg/segment/writer/metrics/labelindex"
 	"github.com/valyala/bytebufferpool"
 )
 
@@ -153,3 +154,14 @@ func (th *TagsHolder) GetTSID(mName []byte) (uint64, error) {
 func (th *TagsHolder) getEntries() []tagEntry {
 	return th.entries[:th.idx]
 }
+
+// Returns the tags along with the metric name as a label set, as used by the label index
+func (th *TagsHolder) getLabels(mName []byte) map[string]string {
+	entries := th.getEntries()
+	retVal := make(map[string]string, len(entries)+1)
+	for _, val := range entries {
+		retVal[val.tagKey] = string(val.tagValue)
+	}
+	retVal[labelindex.METRIC_NAME_LABEL] = string(mName)
+	return retVal
+}
@@ -139,6 +139,10 @@ func (ss *SegStore) encodeRawJsonObject(currKey string, data []byte, maxIdx uint
 			if err != nil {
 				return fmt.Errorf("encodeRawJsonObject: singstr currKey: %v, err: %v", currKey, err)
 			}
+			maxIdx, matchedCol, err = ss.encodeValueWithUnit(finalKey, strVal, maxIdx, tsKey, matchedCol)
+			if err != nil {
+				return fmt.Errorf("encodeRawJsonObject: unit currKey: %v, err: %v", currKey, err)
+			}
 		case jp.Number:
 			numVal, err := jp.ParseInt(value)
 			if err != nil {
@@ -469,6 +473,31 @@ func (ss *SegStore) encodeSingleString(key string, value string, maxIdx uint32,
 	return maxIdx, matchedCol, nil
 }
 
+// If unit parsing applies to key and value is a number with a unit, adds the number and the unit as two
+// more columns. The original string column is kept as is.
+func (ss *SegStore) encodeValueWithUnit(key string, value string, maxIdx uint32,
+	tsKey *string, matchedCol bool) (uint32, bool, error) {
+	unitCfg := config.GetUnitParsingConfig()
+	if !unitCfg.Enabled || key == *tsKey {
+		return maxIdx, matchedCol, nil
+	}
+	targetUnit, ok := getUnitParsingTarget(&unitCfg, key)
+	if !ok {
+		return maxIdx, matchedCol, nil
+	}
+	number, unit, ok := parseValueWithUnit(value, targetUnit)
+	if !ok {
+		return maxIdx, matchedCol, nil
+	}
+
+	var err error
+	maxIdx, matchedCol, err = ss.encodeSingleNumber(key+UNIT_VALUE_COL_SUFFIX, number, maxIdx, tsKey, matchedCol)
+	if err != nil {
+		return maxIdx, matchedCol, err
+	}
+	return ss.encodeSingleString(key+UNIT_NAME_COL_SUFFIX, unit, maxIdx, tsKey, matchedCol)
+}
+
 func (ss *SegStore) encodeSingleBool(key string, val bool, maxIdx uint32,
 	tsKey *string, matchedCol bool) (uint32, bool, error) {
 	if key == *tsKey {
@@ -52,6 +52,14 @@ import (
 
 const MaxAgileTreeNodeCount = 8_000_000
 
+// number of wip blocks currently being written to segfiles
+var activeWipFlushes int64
+
+// Returns the number of wip blocks currently being written to segfiles
+func GetActiveWipFlushCount() uint64 {
+	return uint64(atomic.LoadInt64(&activeWipFlushes))
+}
+
 // SegStore Individual stream buffer
 type SegStore struct {
 	lock              sync.Mutex
@@ -389,6 +397,13 @@ func (segstore *SegStore) appendWipToSegfile(streamid string, forceRotate bool,
 	consolidateColumnTypes(&segstore.wipBlock, segstore.SegmentKey)
 
 	if segstore.wipBlock.maxIdx > 0 {
+		atomic.AddInt64(&activeWipFlushes, 1)
+		defer atomic.AddInt64(&activeWipFlushes, -1)
+		flushStartTime := time.Now()
+		defer func() {
+			instrumentation.RecordInt64Histogram(instrumentation.SEGMENT_FLUSH_DURATION_MS, time.Since(flushStartTime).Milliseconds())
+		}()
+
 		var totalBytesWritten uint64 = 0
 		var totalMetadata uint64 = 0
 		allColsToFlush := &sync.WaitGroup{}
@@ -34,11 +34,13 @@ import (
 	"github.com/klauspost/compress/zstd"
 	"github.com/siglens/siglens/pkg/blob"
 	"github.com/siglens/siglens/pkg/common/fileutils"
+	"github.com/siglens/siglens/pkg/health/heartbeat"
 	"github.com/siglens/siglens/pkg/segment/pqmr"
 	"github.com/siglens/siglens/pkg/segment/structs"
 	. "github.com/siglens/siglens/pkg/segment/utils"
 	"github.com/siglens/siglens/pkg/segment/writer/metrics"
 	"github.com/siglens/siglens/pkg/segment/writer/suffix"
+	"github.com/siglens/siglens/pkg/usageStats"
 	"github.com/siglens/siglens/pkg/utils"
 
 	log "github.com/sirupsen/logrus"
@@ -200,12 +202,14 @@ func initSmr() {
 
 // TODO: this should be pushed based & we should have checks in uploadingestnode function to prevent uploading unupdated files.
 func timeBasedUploadIngestNodeDir() {
+	heartbeat.Register(heartbeat.BLOB_UPLOADER, UPLOAD_INGESTNODE_DIR)
 	for {
 		time.Sleep(UPLOAD_INGESTNODE_DIR)
 		err := blob.UploadIngestNodeDir()
 		if err != nil {
 			log.Errorf("timeBasedUploadIngestNodeDir: failed to upload ingestnode dir: err=%v", err)
 		}
+		heartbeat.Record(heartbeat.BLOB_UPLOADER, err)
 	}
 }
 
@@ -259,6 +263,9 @@ func AddEntryToInMemBuf(streamid string, rawJson []byte, ts_millis uint64,
 		return err
 	}
 	segstore.BytesReceivedCount += bytesReceived
+	instrumentation.IncrementInt64CounterWithLabel(instrumentation.INGEST_EVENTS_COUNT, 1, "indexname", indexName)
+	instrumentation.IncrementInt64CounterWithLabel(instrumentation.INGEST_BYTES_COUNT, int64(bytesReceived), "indexname", indexName)
+	usageStats.UpdateIndexStats(indexName, bytesReceived, 1, orgid)
 
 	if flush {
 		err = segstore.appendWipToSegfile(streamid, false, false, false)
@@ -328,10 +335,14 @@ func updateValuesFromConfig() {
 }
 
 func timeBasedWIPFlushToFile() {
+	heartbeat.Register(heartbeat.SEGMENT_FLUSHER, time.Duration(config.GetSegFlushIntervalSecs())*time.Second)
 	for {
 		sleepDuration := time.Duration(config.GetSegFlushIntervalSecs()) * time.Second
+		heartbeat.SetInterval(heartbeat.SEGMENT_FLUSHER, sleepDuration)
 		time.Sleep(sleepDuration)
+		instrumentation.SetIngestBufferMemoryMB(int64(GetInMemorySize()))
 		FlushWipBufferToFile(&sleepDuration)
+		heartbeat.Record(heartbeat.SEGMENT_FLUSHER, nil)
 	}
 }
 
@@ -748,6 +759,39 @@ func GetUnrotatedVTableCounts(vtable string, orgid uint64) (uint64, int, uint64)
 	return bytesCount, recCount, onDiskBytesCount
 }
 
+// Returns the sum of the bytes written so far of the unrotated segments in segKeys
+func GetUnrotatedOnDiskBytes(segKeys map[string]bool) uint64 {
+	onDiskBytesCount := uint64(0)
+	allSegStoresLock.RLock()
+	defer allSegStoresLock.RUnlock()
+	for _, segstore := range allSegStores {
+		if segKeys[segstore.SegmentKey] {
+			onDiskBytesCount += segstore.OnDiskBytes
+		}
+	}
+	return onDiskBytesCount
+}
+
+// Returns the data types of the columns of each unrotated segment of the table
+func GetUnrotatedColumnDtypes(vtable string, orgid uint64) []map[string]SS_DTYPE {
+	allColDtypes := make([]map[string]SS_DTYPE, 0)
+	allSegStoresLock.RLock()
+	defer allSegStoresLock.RUnlock()
+	for _, segstore := range allSegStores {
+		if segstore.VirtualTableName != vtable || segstore.OrgId != orgid {
+			continue
+		}
+		segstore.lock.Lock()
+		colDtypes := make(map[string]SS_DTYPE, len(segstore.AllSst))
+		for colName, sst := range segstore.AllSst {
+			colDtypes[colName] = sst.GetDtype()
+		}
+		segstore.lock.Unlock()
+		allColDtypes = append(allColDtypes, colDtypes)
+	}
+	return allColDtypes
+}
+
 func getActiveBaseDirVTable(virtualTableName string) string {
 	var sb strings.Builder
 	sb.WriteString(config.GetRunningConfig().DataPath)
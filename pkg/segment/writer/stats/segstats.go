@@ -69,6 +69,125 @@ func AddSegStatsNums(segstats map[string]*SegStats, cname string,
 	processStats(stats, inNumType, intVal, uintVal, fltVal, colUsage, hasValuesFunc)
 }
 
+/*
+Adds the float values of a column block to its stats in tight loops, it gives the same stats as calling
+AddSegStatsNums for each value with SS_FLOAT64 and no eval or values usage. The values are only added to the
+cardinality sketch if withHll is set
+*/
+func AddSegStatsNumsBatch(segstats map[string]*SegStats, cname string, fltVals []float64, bb *bbp.ByteBuffer,
+	withHll bool) {
+
+	if len(fltVals) == 0 {
+		return
+	}
+	stats := getBatchSegStats(segstats, cname)
+	if withHll {
+		for _, fltVal := range fltVals {
+			// the same string as the %v formatting of the values added one at a time
+			bb.B = strconv.AppendFloat(bb.B[:0], fltVal, 'g', -1, 64)
+			stats.Hll.Insert(bb.B)
+		}
+	}
+
+	numStats := stats.NumStats
+	if numStats.Min.Ntype != SS_DT_FLOAT {
+		numStats.Min.FloatVal = float64(numStats.Min.IntgrVal)
+		numStats.Min.Ntype = SS_DT_FLOAT
+		numStats.Max.FloatVal = float64(numStats.Max.IntgrVal)
+		numStats.Max.Ntype = SS_DT_FLOAT
+		numStats.Sum.FloatVal = float64(numStats.Sum.IntgrVal)
+		numStats.Sum.Ntype = SS_DT_FLOAT
+	}
+	minVal, maxVal, sumVal := numStats.Min.FloatVal, numStats.Max.FloatVal, numStats.Sum.FloatVal
+	for _, fltVal := range fltVals {
+		if fltVal < minVal {
+			minVal = fltVal
+		}
+		if fltVal > maxVal {
+			maxVal = fltVal
+		}
+		sumVal += fltVal
+	}
+	numStats.Min.FloatVal = minVal
+	numStats.Max.FloatVal = maxVal
+	numStats.Sum.FloatVal = sumVal
+	numStats.Dtype = SS_DT_FLOAT
+	stats.Count += uint64(len(fltVals))
+}
+
+/*
+Adds the integer values of a column block to its stats like AddSegStatsNumsBatch, it gives the same stats as calling
+AddSegStatsNums for each value with SS_INT64. The stats stay integers unless a float was added before
+*/
+func AddSegStatsIntsBatch(segstats map[string]*SegStats, cname string, intVals []int64, bb *bbp.ByteBuffer,
+	withHll bool) {
+
+	if len(intVals) == 0 {
+		return
+	}
+	stats := getBatchSegStats(segstats, cname)
+	if withHll {
+		for _, intVal := range intVals {
+			bb.B = strconv.AppendInt(bb.B[:0], intVal, 10)
+			stats.Hll.Insert(bb.B)
+		}
+	}
+
+	numStats := stats.NumStats
+	if numStats.Min.Ntype == SS_DT_FLOAT {
+		minVal, maxVal, sumVal := numStats.Min.FloatVal, numStats.Max.FloatVal, numStats.Sum.FloatVal
+		for _, intVal := range intVals {
+			minVal = math.Min(minVal, float64(intVal))
+			maxVal = math.Max(maxVal, float64(intVal))
+			sumVal += float64(intVal)
+		}
+		numStats.Min.FloatVal = minVal
+		numStats.Max.FloatVal = maxVal
+		numStats.Sum.FloatVal = sumVal
+		numStats.Dtype = SS_DT_FLOAT
+	} else {
+		minVal, maxVal, sumVal := numStats.Min.IntgrVal, numStats.Max.IntgrVal, numStats.Sum.IntgrVal
+		for _, intVal := range intVals {
+			if intVal < minVal {
+				minVal = intVal
+			}
+			if intVal > maxVal {
+				maxVal = intVal
+			}
+			sumVal += intVal
+		}
+		numStats.Min.IntgrVal = minVal
+		numStats.Max.IntgrVal = maxVal
+		numStats.Sum.IntgrVal = sumVal
+		numStats.Dtype = SS_DT_SIGNED_NUM
+	}
+	stats.Count += uint64(len(intVals))
+}
+
+func getBatchSegStats(segstats map[string]*SegStats, cname string) *SegStats {
+	stats, ok := segstats[cname]
+	if !ok {
+		numStats := &NumericStats{
+			Min: NumTypeEnclosure{Ntype: SS_DT_SIGNED_NUM,
+				IntgrVal: math.MaxInt64},
+			Max: NumTypeEnclosure{Ntype: SS_DT_SIGNED_NUM,
+				IntgrVal: math.MinInt64},
+			Sum: NumTypeEnclosure{Ntype: SS_DT_SIGNED_NUM,
+				IntgrVal: 0},
+			Dtype: SS_DT_SIGNED_NUM,
+		}
+		stats = &SegStats{
+			IsNumeric: true,
+			Count:     0,
+			Hll:       hyperloglog.New16(),
+			NumStats:  numStats,
+			Records:   make([]*CValueEnclosure, 0),
+		}
+		segstats[cname] = stats
+	}
+	return stats
+}
+
 func AddSegStatsCount(segstats map[string]*SegStats, cname string,
 	count uint64) {
 
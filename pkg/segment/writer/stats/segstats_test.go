@@ -61,6 +61,71 @@ func Test_addSegStatsNums(t *testing.T) {
 
 }
 
+func Test_addSegStatsNumsBatch(t *testing.T) {
+
+	fltVals := []float64{2345, 345.1, -7, 1e21, 12, 345.1}
+	scalarSst := make(map[string]*SegStats)
+	batchSst := make(map[string]*SegStats)
+	bb := bbp.Get()
+
+	AddSegStatsNums(scalarSst, "mycol1", SS_UINT64, 0, uint64(10), 0, "10", bb, nil, false)
+	AddSegStatsNums(batchSst, "mycol1", SS_UINT64, 0, uint64(10), 0, "10", bb, nil, false)
+	for _, fltVal := range fltVals {
+		AddSegStatsNums(scalarSst, "mycol1", SS_FLOAT64, 0, 0, fltVal, fmt.Sprintf("%v", fltVal), bb, nil, false)
+	}
+	AddSegStatsNumsBatch(batchSst, "mycol1", fltVals[:2], bb, true)
+	AddSegStatsNumsBatch(batchSst, "mycol1", fltVals[2:], bb, true)
+
+	assert.Equal(t, scalarSst["mycol1"].Count, batchSst["mycol1"].Count)
+	assert.Equal(t, *scalarSst["mycol1"].NumStats, *batchSst["mycol1"].NumStats)
+	assert.Equal(t, scalarSst["mycol1"].Hll.Estimate(), batchSst["mycol1"].Hll.Estimate())
+
+	AddSegStatsNumsBatch(batchSst, "mycol2", []float64{3, 1, 2}, bb, false)
+	assert.Equal(t, uint64(3), batchSst["mycol2"].Count)
+	assert.Equal(t, float64(1), batchSst["mycol2"].NumStats.Min.FloatVal)
+	assert.Equal(t, float64(3), batchSst["mycol2"].NumStats.Max.FloatVal)
+	assert.Equal(t, float64(6), batchSst["mycol2"].NumStats.Sum.FloatVal)
+	assert.Equal(t, uint64(0), batchSst["mycol2"].Hll.Estimate())
+}
+
+func Test_addSegStatsIntsBatch(t *testing.T) {
+
+	intVals := []int64{9007199254740993, -7, 12, 345, 12}
+	scalarSst := make(map[string]*SegStats)
+	batchSst := make(map[string]*SegStats)
+	bb := bbp.Get()
+
+	for _, intVal := range intVals {
+		AddSegStatsNums(scalarSst, "mycol1", SS_INT64, intVal, 0, 0, fmt.Sprintf("%v", intVal), bb, nil, false)
+	}
+	// a batch block and a scalar block of the same values
+	AddSegStatsIntsBatch(batchSst, "mycol1", intVals[:3], bb, true)
+	for _, intVal := range intVals[3:] {
+		AddSegStatsNums(batchSst, "mycol1", SS_INT64, intVal, 0, 0, fmt.Sprintf("%v", intVal), bb, nil, false)
+	}
+
+	numStats := batchSst["mycol1"].NumStats
+	assert.Equal(t, uint64(5), batchSst["mycol1"].Count)
+	assert.Equal(t, SS_DT_SIGNED_NUM, numStats.Dtype)
+	assert.Equal(t, SS_DT_SIGNED_NUM, numStats.Min.Ntype)
+	assert.Equal(t, SS_DT_SIGNED_NUM, numStats.Max.Ntype)
+	assert.Equal(t, SS_DT_SIGNED_NUM, numStats.Sum.Ntype)
+	assert.Equal(t, int64(-7), numStats.Min.IntgrVal)
+	assert.Equal(t, int64(9007199254740993), numStats.Max.IntgrVal)
+	assert.Equal(t, int64(9007199254741355), numStats.Sum.IntgrVal)
+	assert.Equal(t, *scalarSst["mycol1"].NumStats, *numStats)
+	assert.Equal(t, uint64(4), batchSst["mycol1"].Hll.Estimate())
+	assert.Equal(t, scalarSst["mycol1"].Hll.Estimate(), batchSst["mycol1"].Hll.Estimate())
+
+	// integers added after a float are added as floats
+	AddSegStatsNumsBatch(batchSst, "mycol2", []float64{1.5}, bb, false)
+	AddSegStatsIntsBatch(batchSst, "mycol2", []int64{3, -2}, bb, false)
+	assert.Equal(t, SS_DT_FLOAT, batchSst["mycol2"].NumStats.Min.Ntype)
+	assert.Equal(t, float64(-2), batchSst["mycol2"].NumStats.Min.FloatVal)
+	assert.Equal(t, float64(3), batchSst["mycol2"].NumStats.Max.FloatVal)
+	assert.Equal(t, float64(2.5), batchSst["mycol2"].NumStats.Sum.FloatVal)
+}
+
 func Test_addSegStatsNumsForEvalFunc(t *testing.T) {
 
 	cname := "duration"
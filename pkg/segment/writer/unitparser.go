@@ -0,0 +1,128 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package writer
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/siglens/siglens/pkg/config"
+)
+
+// suffixes of the columns added for a value with a unit, e.g. latency=10ms adds latency_value=10 and latency_unit=ms
+const UNIT_VALUE_COL_SUFFIX = "_value"
+const UNIT_NAME_COL_SUFFIX = "_unit"
+
+type unitFamily uint8
+
+const (
+	unitFamilyDuration unitFamily = iota
+	unitFamilyBytes
+	unitFamilyPercent
+)
+
+type unitInfo struct {
+	family     unitFamily
+	multiplier float64 // value in the base unit of the family for 1 of this unit
+}
+
+// the base units are ms for durations, B for sizes and % for percentages
+var baseUnits = map[unitFamily]string{
+	unitFamilyDuration: "ms",
+	unitFamilyBytes:    "B",
+	unitFamilyPercent:  "%",
+}
+
+var knownUnits = map[string]unitInfo{
+	"ns":      {unitFamilyDuration, 1e-6},
+	"us":      {unitFamilyDuration, 1e-3},
+	"µs":      {unitFamilyDuration, 1e-3},
+	"ms":      {unitFamilyDuration, 1},
+	"s":       {unitFamilyDuration, 1e3},
+	"sec":     {unitFamilyDuration, 1e3},
+	"min":     {unitFamilyDuration, 60e3},
+	"h":       {unitFamilyDuration, 3600e3},
+	"hr":      {unitFamilyDuration, 3600e3},
+	"B":       {unitFamilyBytes, 1},
+	"KB":      {unitFamilyBytes, 1e3},
+	"kB":      {unitFamilyBytes, 1e3},
+	"MB":      {unitFamilyBytes, 1e6},
+	"GB":      {unitFamilyBytes, 1e9},
+	"TB":      {unitFamilyBytes, 1e12},
+	"PB":      {unitFamilyBytes, 1e15},
+	"KiB":     {unitFamilyBytes, 1 << 10},
+	"MiB":     {unitFamilyBytes, 1 << 20},
+	"GiB":     {unitFamilyBytes, 1 << 30},
+	"TiB":     {unitFamilyBytes, 1 << 40},
+	"PiB":     {unitFamilyBytes, 1 << 50},
+	"%":       {unitFamilyPercent, 1},
+	"percent": {unitFamilyPercent, 1},
+}
+
+// Returns the unit that values of the field should be converted to, and whether the
+// field's values should be checked for units at all.
+func getUnitParsingTarget(unitCfg *config.UnitParsingConfig, key string) (string, bool) {
+	targetUnit, ok := unitCfg.Fields[key]
+	if ok {
+		return targetUnit, true
+	}
+	return "", unitCfg.AllFields
+}
+
+// Parses strings like "10ms", "3.5 GB" or "45%" into a number and a unit.
+// If targetUnit is a known unit of the same kind, the number is converted to it, otherwise
+// it is converted to the base unit of its kind. Returns false if value is not a number with a known unit.
+func parseValueWithUnit(value string, targetUnit string) (float64, string, bool) {
+	value = strings.TrimSpace(value)
+	if len(value) < 2 {
+		return 0, "", false
+	}
+	// quick check so most strings are rejected without any parsing
+	if first := value[0]; (first < '0' || first > '9') && first != '-' && first != '+' && first != '.' {
+		return 0, "", false
+	}
+
+	unitStart := len(value)
+	for unitStart > 0 {
+		ch := value[unitStart-1]
+		if (ch >= '0' && ch <= '9') || ch == '.' {
+			break
+		}
+		unitStart--
+	}
+	if unitStart == 0 || unitStart == len(value) {
+		return 0, "", false
+	}
+
+	unit := strings.TrimSpace(value[unitStart:])
+	srcUnit, ok := knownUnits[unit]
+	if !ok {
+		return 0, "", false
+	}
+	number, err := strconv.ParseFloat(strings.TrimSpace(value[:unitStart]), 64)
+	if err != nil {
+		return 0, "", false
+	}
+
+	dstUnitName := baseUnits[srcUnit.family]
+	dstMultiplier := float64(1)
+	if dstUnit, ok := knownUnits[targetUnit]; ok && dstUnit.family == srcUnit.family {
+		dstUnitName = targetUnit
+		dstMultiplier = dstUnit.multiplier
+	}
+	return number * srcUnit.multiplier / dstMultiplier, dstUnitName, true
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package writer
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/siglens/siglens/pkg/config"
+	. "github.com/siglens/siglens/pkg/segment/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseValueWithUnit(t *testing.T) {
+	cases := []struct {
+		input        string
+		targetUnit   string
+		expectedOk   bool
+		expectedNum  float64
+		expectedUnit string
+	}{
+		{"10ms", "", true, 10, "ms"},
+		{"1.5s", "", true, 1500, "ms"},
+		{"250us", "", true, 0.25, "ms"},
+		{"2min", "s", true, 120, "s"},
+		{"3.5GB", "", true, 3.5e9, "B"},
+		{"3.5 GB", "MB", true, 3500, "MB"},
+		{"1KiB", "", true, 1024, "B"},
+		{"45%", "", true, 45, "%"},
+		{"-3h", "", true, -3 * 3600e3, "ms"},
+		{"10ms", "GB", true, 10, "ms"}, // target unit of another kind is ignored
+		{"10.0.0.1", "", false, 0, ""},
+		{"10", "", false, 0, ""},
+		{"ms", "", false, 0, ""},
+		{"10 apples", "", false, 0, ""},
+		{"v1.2s", "", false, 0, ""},
+		{"1.2.3s", "", false, 0, ""},
+		{"2023-01-01T00:00:00Z", "", false, 0, ""},
+	}
+
+	for _, testCase := range cases {
+		num, unit, ok := parseValueWithUnit(testCase.input, testCase.targetUnit)
+		assert.Equal(t, testCase.expectedOk, ok, testCase.input)
+		if ok {
+			assert.InDelta(t, testCase.expectedNum, num, 1e-9, testCase.input)
+			assert.Equal(t, testCase.expectedUnit, unit, testCase.input)
+		}
+	}
+}
+
+func Test_encodeValueWithUnit(t *testing.T) {
+	config.InitializeTestingConfig()
+	defer os.RemoveAll(config.GetDataPath())
+	runningConfig := config.GetRunningConfig()
+	runningConfig.UnitParsing = config.UnitParsingConfig{
+		Enabled: true,
+		Fields:  map[string]string{"latency": "s", "size": ""},
+	}
+	config.SetConfig(*runningConfig)
+	defer func() {
+		runningConfig.UnitParsing = config.UnitParsingConfig{}
+		config.SetConfig(*runningConfig)
+	}()
+
+	cTime := uint64(time.Now().UnixMilli())
+	segstore, err := getSegStore("test-unitparsing", cTime, "test", 0)
+	assert.Nil(t, err)
+	tsKey := config.GetTimeStampKey()
+	_, _, err = segstore.EncodeColumns([]byte(`{"latency": "1500ms", "size": "2KB", "other": "45%"}`), cTime, &tsKey, SIGNAL_EVENTS)
+	assert.Nil(t, err)
+
+	colWips := allSegStores["test-unitparsing"].wipBlock.colWips
+	getCval := func(colName string) CValueEnclosure {
+		colWip, ok := colWips[colName]
+		assert.True(t, ok, colName)
+		if !ok {
+			return CValueEnclosure{}
+		}
+		val, _, err := GetCvalFromRec(colWip.cbuf[colWip.cstartidx:colWip.cbufidx], 0)
+		assert.Nil(t, err)
+		return val
+	}
+
+	assert.Equal(t, "1500ms", getCval("latency").CVal)
+	assert.Equal(t, 1.5, getCval("latency"+UNIT_VALUE_COL_SUFFIX).CVal)
+	assert.Equal(t, "s", getCval("latency"+UNIT_NAME_COL_SUFFIX).CVal)
+	assert.Equal(t, 2000.0, getCval("size"+UNIT_VALUE_COL_SUFFIX).CVal)
+	assert.Equal(t, "B", getCval("size"+UNIT_NAME_COL_SUFFIX).CVal)
+
+	// only the configured fields are parsed without allFields
+	_, ok := colWips["other"+UNIT_VALUE_COL_SUFFIX]
+	assert.False(t, ok)
+}
@@ -215,6 +215,22 @@ func CheckAndGetColsForUnrotatedSegKey(key string) (map[string]bool, bool) {
 	return colsCopy, true
 }
 
+// Returns the columns seen in the unrotated segments of the table
+func GetUnrotatedColNamesForTable(tableName string, orgid uint64) map[string]bool {
+	UnrotatedInfoLock.RLock()
+	defer UnrotatedInfoLock.RUnlock()
+	colNames := make(map[string]bool)
+	for _, usi := range AllUnrotatedSegmentInfo {
+		if usi.TableName != tableName || usi.orgid != orgid {
+			continue
+		}
+		for colName := range usi.allColumns {
+			colNames[colName] = true
+		}
+	}
+	return colNames
+}
+
 // returns a copy of the unrotated block search info. This is to prevent concurrent modification
 func GetBlockSearchInfoForKey(key string) (map[uint16]*structs.BlockMetadataHolder, error) {
 	UnrotatedInfoLock.RLock()
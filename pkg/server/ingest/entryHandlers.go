@@ -22,6 +22,7 @@ import (
 	eswriter "github.com/siglens/siglens/pkg/es/writer"
 	"github.com/siglens/siglens/pkg/health"
 	influxwriter "github.com/siglens/siglens/pkg/influx/writer"
+	"github.com/siglens/siglens/pkg/ingest"
 	"github.com/siglens/siglens/pkg/instrumentation"
 	"github.com/siglens/siglens/pkg/integrations/loki"
 	otsdbwriter "github.com/siglens/siglens/pkg/integrations/otsdb/writer"
@@ -50,6 +51,24 @@ func getHealthHandler() func(ctx *fasthttp.RequestCtx) {
 	}
 }
 
+func getLivenessHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		health.ProcessLiveness(ctx)
+	}
+}
+
+func getReadinessHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		health.ProcessReadiness(ctx)
+	}
+}
+
+func getMetricsHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		instrumentation.ProcessMetricsRequest(ctx)
+	}
+}
+
 func getSafeHealthHandler() func(ctx *fasthttp.RequestCtx) {
 	return func(ctx *fasthttp.RequestCtx) {
 		health.ProcessSafeHealth(ctx)
@@ -100,6 +119,19 @@ func otlpIngestTracesHandler() func(ctx *fasthttp.RequestCtx) {
 	}
 }
 
+func ndjsonIngestHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		instrumentation.IncrementInt64Counter(instrumentation.POST_REQUESTS_COUNT, 1)
+		ingest.ProcessNdjsonIngestRequest(ctx, 0)
+	}
+}
+
+func getBackpressureStatusHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		ingest.ProcessGetBackpressureStatus(ctx)
+	}
+}
+
 func sampleDatasetBulkHandler() func(ctx *fasthttp.RequestCtx) {
 	return func(ctx *fasthttp.RequestCtx) {
 		instrumentation.IncrementInt64Counter(instrumentation.POST_REQUESTS_COUNT, 1)
@@ -121,7 +153,7 @@ func getConfigHandler() func(ctx *fasthttp.RequestCtx) {
 
 func getConfigReloadHandler() func(ctx *fasthttp.RequestCtx) {
 	return func(ctx *fasthttp.RequestCtx) {
-		config.ProcessForceReadConfig(ctx)
+		config.ProcessReloadConfig(ctx)
 	}
 }
 
@@ -17,6 +17,14 @@ limitations under the License.
 package ingestserver
 
 import (
+	"io"
+	"strings"
+
+	"github.com/siglens/siglens/pkg/apikeys"
+	"github.com/siglens/siglens/pkg/ingest"
+	server_utils "github.com/siglens/siglens/pkg/server/utils"
+	"github.com/siglens/siglens/pkg/utils"
+	log "github.com/sirupsen/logrus"
 	"github.com/valyala/fasthttp"
 )
 
@@ -30,3 +38,71 @@ func (hs *ingestionServerCfg) Recovery(next func(ctx *fasthttp.RequestCtx)) func
 	}
 	return fn
 }
+
+// Rejects the request with a 429 while the ingest buffers are over their limits or the org is over its ingest quotas
+func (hs *ingestionServerCfg) Backpressure(next func(ctx *fasthttp.RequestCtx)) func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		if ingest.RejectIfUnderBackpressure(ctx) || ingest.RejectIfOverIngestQuota(ctx, 0) {
+			return
+		}
+		next(ctx)
+	}
+}
+
+// The server streams request bodies so that the ndjson endpoint can read them incrementally. Every other endpoint
+// reads the whole body into memory, so it is read here up to maxBodySize and the request is rejected with a 413 if it is larger
+func limitBody(next fasthttp.RequestHandler, maxBodySize int) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		bodyStream := ctx.RequestBodyStream()
+		if bodyStream == nil || isStreamingPath(string(ctx.Path())) {
+			next(ctx)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(bodyStream, int64(maxBodySize)+1))
+		if err != nil {
+			log.Errorf("limitBody: failed to read request body for path %v, err=%v", string(ctx.Path()), err)
+			ctx.SetStatusCode(fasthttp.StatusBadRequest)
+			utils.WriteJsonResponse(ctx, map[string]interface{}{"error": "failed to read request body"})
+			return
+		}
+		if len(body) > maxBodySize {
+			log.Errorf("limitBody: request body for path %v is larger than %v bytes", string(ctx.Path()), maxBodySize)
+			ctx.SetStatusCode(fasthttp.StatusRequestEntityTooLarge)
+			utils.WriteJsonResponse(ctx, map[string]interface{}{"error": "request body is too large"})
+			return
+		}
+		ctx.Request.SetBody(body)
+		next(ctx)
+	}
+}
+
+func isStreamingPath(path string) bool {
+	return path == server_utils.API_PREFIX+"/ingest/ndjson"
+}
+
+// Responds with a 401 or 403 to the requests that do not have an api key with the scope of the endpoint
+func authenticate(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if !apikeys.AuthorizeRequest(ctx, getRequiredScope(string(ctx.Method()), string(ctx.Path()))) {
+			return
+		}
+		next(ctx)
+	}
+}
+
+// Every endpoint of the ingest server needs the ingest scope, except for the health, config and metrics endpoints
+func getRequiredScope(method string, path string) string {
+	switch {
+	case method == fasthttp.MethodOptions,
+		path == "/health",
+		strings.HasPrefix(path, "/health/"),
+		path == server_utils.API_PREFIX+"/health",
+		strings.HasPrefix(path, server_utils.SPLUNK_PREFIX+"/services/collector/health"):
+		return ""
+	case strings.HasPrefix(path, "/setconfig/"), path == "/config", strings.HasPrefix(path, "/config/"), path == "/metrics":
+		return apikeys.SCOPE_ADMIN
+	default:
+		return apikeys.SCOPE_INGEST
+	}
+}
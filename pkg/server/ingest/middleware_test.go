@@ -0,0 +1,75 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingestserver
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	server_utils "github.com/siglens/siglens/pkg/server/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+)
+
+func Test_limitBody(t *testing.T) {
+	ln := fasthttputil.NewInmemoryListener()
+	defer ln.Close()
+
+	handler := func(ctx *fasthttp.RequestCtx) {
+		if ctx.RequestBodyStream() != nil && isStreamingPath(string(ctx.Path())) {
+			var buf bytes.Buffer
+			_, _ = buf.ReadFrom(ctx.RequestBodyStream())
+			ctx.SetBody(buf.Bytes())
+			return
+		}
+		ctx.SetBody(ctx.PostBody())
+	}
+	s := &fasthttp.Server{
+		Handler:            limitBody(handler, 1024),
+		MaxRequestBodySize: 1024,
+		StreamRequestBody:  true,
+	}
+	go func() { _ = s.Serve(ln) }()
+
+	client := &fasthttp.Client{Dial: func(addr string) (net.Conn, error) { return ln.Dial() }}
+	send := func(path string, body []byte) *fasthttp.Response {
+		req := fasthttp.AcquireRequest()
+		resp := fasthttp.AcquireResponse()
+		req.SetRequestURI("http://test" + path)
+		req.Header.SetMethod(fasthttp.MethodPost)
+		req.SetBody(body)
+		err := client.Do(req, resp)
+		assert.Nil(t, err)
+		return resp
+	}
+
+	small := bytes.Repeat([]byte("a"), 100)
+	large := bytes.Repeat([]byte("a"), 4096)
+
+	resp := send(server_utils.ELASTIC_PREFIX+"/_bulk", small)
+	assert.Equal(t, fasthttp.StatusOK, resp.StatusCode())
+	assert.Equal(t, small, resp.Body())
+
+	resp = send(server_utils.ELASTIC_PREFIX+"/_bulk", large)
+	assert.Equal(t, fasthttp.StatusRequestEntityTooLarge, resp.StatusCode())
+
+	resp = send(server_utils.API_PREFIX+"/ingest/ndjson", large)
+	assert.Equal(t, fasthttp.StatusOK, resp.StatusCode())
+	assert.Equal(t, large, resp.Body())
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package ingestserver
 
 import (
+	"crypto/tls"
 	"net"
 	"time"
 
@@ -39,6 +40,7 @@ type ingestionServerCfg struct {
 	Addr   string
 	//	Log    *zap.Logger //ToDo implement debug logger
 	ln     net.Listener
+	lnTls  net.Listener
 	router *router.Router
 	debug  bool
 }
@@ -75,6 +77,7 @@ func (hs *ingestionServerCfg) Run() (err error) {
 
 	//Register all the method handlers here
 	ingest.InitIngestionMetrics()
+	ingest.InitBackpressureMonitor()
 	writer.InitWriterNode()
 
 	if !config.IsQueryNode() && config.IsIngestNode() {
@@ -82,7 +85,12 @@ func (hs *ingestionServerCfg) Run() (err error) {
 	}
 
 	hs.router.GET(server_utils.API_PREFIX+"/health", hs.Recovery(getHealthHandler()))
-	hs.router.POST(server_utils.API_PREFIX+"/sampledataset_bulk", hs.Recovery(sampleDatasetBulkHandler()))
+	hs.router.GET("/health/live", hs.Recovery(getLivenessHandler()))
+	hs.router.GET("/health/ready", hs.Recovery(getReadinessHandler()))
+	hs.router.GET("/metrics", hs.Recovery(getMetricsHandler()))
+	hs.router.POST(server_utils.API_PREFIX+"/sampledataset_bulk", hs.Recovery(hs.Backpressure(sampleDatasetBulkHandler())))
+	hs.router.GET(server_utils.API_PREFIX+"/ingest/backpressure", hs.Recovery(getBackpressureStatusHandler()))
+	hs.router.POST(server_utils.API_PREFIX+"/ingest/ndjson", hs.Recovery(hs.Backpressure(ndjsonIngestHandler())))
 
 	hs.router.POST("/setconfig/transient", hs.Recovery(postSetconfigHandler(false)))
 	hs.router.POST("/setconfig/persistent", hs.Recovery(postSetconfigHandler(true)))
@@ -91,29 +99,30 @@ func (hs *ingestionServerCfg) Run() (err error) {
 
 	//elasticsearch endpoints
 	hs.router.GET(server_utils.ELASTIC_PREFIX+"/", hs.Recovery(esGreetHandler()))
-	hs.router.POST(server_utils.ELASTIC_PREFIX+"/_bulk", hs.Recovery(esPostBulkHandler()))
+	hs.router.POST(server_utils.ELASTIC_PREFIX+"/_bulk", hs.Recovery(hs.Backpressure(esPostBulkHandler())))
 	hs.router.PUT(server_utils.ELASTIC_PREFIX+"/{indexName}", hs.Recovery(esPutIndexHandler()))
 
 	// Loki endpoints
-	hs.router.POST(server_utils.LOKI_PREFIX+"/api/v1/push", hs.Recovery(lokiPostBulkHandler()))
+	hs.router.POST(server_utils.LOKI_PREFIX+"/api/v1/push", hs.Recovery(hs.Backpressure(lokiPostBulkHandler())))
 
 	// Splunk Handlers
-	hs.router.POST(server_utils.SPLUNK_PREFIX+"/services/collector/event", hs.Recovery(splunkHecIngestHandler()))
+	hs.router.POST(server_utils.SPLUNK_PREFIX+"/services/collector/event", hs.Recovery(hs.Backpressure(splunkHecIngestHandler())))
 	hs.router.GET(server_utils.SPLUNK_PREFIX+"/services/collector/health", hs.Recovery(getHealthHandler()))
 	hs.router.GET(server_utils.SPLUNK_PREFIX+"/services/collector/health/1.0", hs.Recovery(getHealthHandler()))
 
 	// OpenTSDB Handlers
-	hs.router.PUT(server_utils.OTSDB_PREFIX+"/api/put", hs.Recovery(otsdbPutMetricsHandler()))
-	hs.router.POST(server_utils.OTSDB_PREFIX+"/api/put", hs.Recovery(otsdbPutMetricsHandler()))
+	hs.router.PUT(server_utils.OTSDB_PREFIX+"/api/put", hs.Recovery(hs.Backpressure(otsdbPutMetricsHandler())))
+	hs.router.POST(server_utils.OTSDB_PREFIX+"/api/put", hs.Recovery(hs.Backpressure(otsdbPutMetricsHandler())))
 
 	// Influx Handlers
-	hs.router.POST(server_utils.INFLUX_PREFIX+"/api/v2/write", hs.Recovery(influxPutMetricsHandler()))
+	hs.router.POST(server_utils.INFLUX_PREFIX+"/api/v2/write", hs.Recovery(hs.Backpressure(influxPutMetricsHandler())))
 
 	// Prometheus Handlers
-	hs.router.POST(server_utils.PROMQL_PREFIX+"/api/v1/write", hs.Recovery(prometheusPutMetricsHandler()))
+	hs.router.POST(server_utils.PROMQL_PREFIX+"/api/v1/write", hs.Recovery(hs.Backpressure(prometheusPutMetricsHandler())))
+	hs.router.POST(server_utils.API_PREFIX+"/v1/write", hs.Recovery(hs.Backpressure(prometheusPutMetricsHandler())))
 
 	// OTLP Handlers
-	hs.router.POST(server_utils.OTLP_PREFIX+"/v1/traces", hs.Recovery(otlpIngestTracesHandler()))
+	hs.router.POST(server_utils.OTLP_PREFIX+"/v1/traces", hs.Recovery(hs.Backpressure(otlpIngestTracesHandler())))
 
 	if config.IsDebugMode() {
 		hs.router.GET("/debug/pprof/{profile:*}", pprofhandler.PprofHandler)
@@ -124,22 +133,42 @@ func (hs *ingestionServerCfg) Run() (err error) {
 	}
 
 	s := &fasthttp.Server{
-		Handler:            cors(hs.router.Handler),
+		Handler:            limitBody(cors(authenticate(hs.router.Handler)), hs.Config.MaxRequestBodySize),
 		Name:               hs.Config.Name,
 		ReadBufferSize:     hs.Config.ReadBufferSize,
 		MaxConnsPerIP:      hs.Config.MaxConnsPerIP,
 		MaxRequestsPerConn: hs.Config.MaxRequestsPerConn,
 		MaxRequestBodySize: hs.Config.MaxRequestBodySize, //  100 << 20, // 100MB // 1024 * 4, // MaxRequestBodySize:
 		Concurrency:        hs.Config.Concurrency,
+		StreamRequestBody:  true, // lets the ndjson endpoint read bodies incrementally, limitBody caps the other endpoints
 	}
 
-	// run fasthttp server
 	var g run.Group
-	g.Add(func() error {
-		return s.Serve(hs.ln)
-	}, func(e error) {
-		_ = hs.ln.Close()
-	})
+	if config.IsTlsEnabled() && config.GetTLSConfig().CertificatePath != "" {
+		// the client certificates are verified when an ingest client CA is configured
+		cfg, err := server_utils.GetServerTlsConfig(true)
+		if err != nil {
+			log.Errorf("Run: failed to set up tls, err=%v", err)
+			return err
+		}
+		hs.lnTls = tls.NewListener(hs.ln, cfg)
+		// run fasthttp server
+		g.Add(func() error {
+			return s.Serve(hs.lnTls)
+		}, func(e error) {
+			_ = hs.ln.Close()
+		})
+	} else {
+		if config.IsTlsAcmeEnabled() {
+			log.Infof("Run: the ingest server serves tls only with the certificate files of the tls config")
+		}
+		// run fasthttp server
+		g.Add(func() error {
+			return s.Serve(hs.ln)
+		}, func(e error) {
+			_ = hs.ln.Close()
+		})
+	}
 	return g.Run()
 }
 
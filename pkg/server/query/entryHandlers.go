@@ -25,7 +25,9 @@ import (
 	"github.com/fasthttp/websocket"
 
 	"github.com/siglens/siglens/pkg/alerts/alertsHandler"
+	"github.com/siglens/siglens/pkg/apikeys"
 	"github.com/siglens/siglens/pkg/ast/pipesearch"
+	"github.com/siglens/siglens/pkg/cluster"
 	"github.com/siglens/siglens/pkg/config"
 	"github.com/siglens/siglens/pkg/dashboards"
 	esreader "github.com/siglens/siglens/pkg/es/reader"
@@ -36,8 +38,10 @@ import (
 	"github.com/siglens/siglens/pkg/integrations/loki"
 	otsdbquery "github.com/siglens/siglens/pkg/integrations/otsdb/query"
 	prom "github.com/siglens/siglens/pkg/integrations/prometheus/promql"
+	"github.com/siglens/siglens/pkg/integrity"
 	"github.com/siglens/siglens/pkg/querytracker"
 	"github.com/siglens/siglens/pkg/sampledataset"
+	"github.com/siglens/siglens/pkg/segment/inspect"
 	tracinghandler "github.com/siglens/siglens/pkg/segment/tracing/handler"
 	usq "github.com/siglens/siglens/pkg/usersavedqueries"
 	log "github.com/sirupsen/logrus"
@@ -60,6 +64,24 @@ func getHealthHandler() func(ctx *fasthttp.RequestCtx) {
 	}
 }
 
+func getLivenessHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		health.ProcessLiveness(ctx)
+	}
+}
+
+func getReadinessHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		health.ProcessReadiness(ctx)
+	}
+}
+
+func getMetricsHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		instrumentation.ProcessMetricsRequest(ctx)
+	}
+}
+
 func esGetSearchHandler() func(ctx *fasthttp.RequestCtx) {
 	return func(ctx *fasthttp.RequestCtx) {
 		instrumentation.IncrementInt64Counter(instrumentation.QUERY_COUNT, 1)
@@ -87,9 +109,34 @@ func otsdbMetricQueryExpHandler() func(ctx *fasthttp.RequestCtx) {
 
 func metricsSearchHandler() func(ctx *fasthttp.RequestCtx) {
 	return func(ctx *fasthttp.RequestCtx) {
-		prom.ProcessMetricsSearchRequest(ctx, 0)
+		prom.ProcessPromqlInstantQuery(ctx, 0)
 	}
 }
+
+func metricsRangeSearchHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		prom.ProcessPromqlRangeQuery(ctx, 0)
+	}
+}
+
+func metricsLabelNamesHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		prom.ProcessGetLabelNames(ctx, 0)
+	}
+}
+
+func metricsLabelValuesHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		prom.ProcessGetLabelValues(ctx, 0)
+	}
+}
+
+func metricsSeriesHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		prom.ProcessGetSeries(ctx, 0)
+	}
+}
+
 func uiMetricsSearchHandler() func(ctx *fasthttp.RequestCtx) {
 	return func(ctx *fasthttp.RequestCtx) {
 		prom.ProcessUiMetricsSearchRequest(ctx, 0)
@@ -134,7 +181,7 @@ func getConfigHandler() func(ctx *fasthttp.RequestCtx) {
 
 func getConfigReloadHandler() func(ctx *fasthttp.RequestCtx) {
 	return func(ctx *fasthttp.RequestCtx) {
-		config.ProcessForceReadConfig(ctx)
+		config.ProcessReloadConfig(ctx)
 	}
 }
 
@@ -183,6 +230,42 @@ func esGetAllAliasesHandler() func(ctx *fasthttp.RequestCtx) {
 	}
 }
 
+func esCatIndicesHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		esreader.ProcessCatIndicesRequest(ctx, 0)
+	}
+}
+
+func esCatCountHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		esreader.ProcessCatCountRequest(ctx, 0)
+	}
+}
+
+func esCatHealthHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		esreader.ProcessCatHealthRequest(ctx, 0)
+	}
+}
+
+func esCatNodesHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		esreader.ProcessCatNodesRequest(ctx)
+	}
+}
+
+func esGetMappingHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		esreader.ProcessGetMappingRequest(ctx, 0)
+	}
+}
+
+func esFieldCapsHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		esreader.ProcessFieldCapsRequest(ctx, 0)
+	}
+}
+
 func esGetIndexAliasExistsHandler() func(ctx *fasthttp.RequestCtx) {
 	return func(ctx *fasthttp.RequestCtx) {
 		eswriter.ProcessIndexAliasExist(ctx, 0)
@@ -195,6 +278,12 @@ func pipeSearchHandler() func(ctx *fasthttp.RequestCtx) {
 	}
 }
 
+func sqlQueryHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		pipesearch.ProcessSqlQueryRequest(ctx, 0)
+	}
+}
+
 func dashboardPipeSearchHandler() func(ctx *fasthttp.RequestCtx) {
 	return func(ctx *fasthttp.RequestCtx) {
 		pipesearch.ProcessPipeSearchRequest(ctx, 0)
@@ -212,6 +301,9 @@ func pipeSearchWebsocketHandler(myid uint64) func(ctx *fasthttp.RequestCtx) {
 
 	return func(ctx *fasthttp.RequestCtx) {
 		startTime := time.Now()
+		// the request ctx can not be used once the connection has been upgraded
+		indexFilter := apikeys.GetIndexFilter(ctx, apikeys.ACCESS_READ)
+		apiKey := apikeys.GetRequestApiKey(ctx)
 		err := upgrader.Upgrade(ctx, func(conn *websocket.Conn) {
 			defer func() {
 				deadline := time.Now().Add(time.Second * 5)
@@ -230,7 +322,7 @@ func pipeSearchWebsocketHandler(myid uint64) func(ctx *fasthttp.RequestCtx) {
 					return
 				}
 			}()
-			pipesearch.ProcessPipeSearchWebsocket(conn, myid)
+			pipesearch.ProcessPipeSearchWebsocket(conn, myid, indexFilter, apiKey)
 		})
 		if err != nil {
 			log.Errorf("PipeSearchWebsocketHandler: Error upgrading websocket connection %+v", err)
@@ -263,6 +355,18 @@ func getClusterIngestStatsHandler() func(ctx *fasthttp.RequestCtx) {
 	}
 }
 
+func getQuotaUsageHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		health.ProcessGetQuotaUsageHandler(ctx, 0)
+	}
+}
+
+func getIndexUsageStatsHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		health.ProcessIndexUsageStatsHandler(ctx, 0)
+	}
+}
+
 func saveUserSavedQueriesHandler() func(ctx *fasthttp.RequestCtx) {
 	return func(ctx *fasthttp.RequestCtx) {
 		usq.SaveUserQueries(ctx)
@@ -353,6 +457,204 @@ func deleteDashboardHandler() func(ctx *fasthttp.RequestCtx) {
 	}
 }
 
+func getDashboardVariableOptionsHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		dashboards.ProcessGetVariableOptionsRequest(ctx, 0)
+	}
+}
+
+func exportGrafanaDashboardHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		dashboards.ProcessExportGrafanaDashboardRequest(ctx)
+	}
+}
+
+func importGrafanaDashboardHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		dashboards.ProcessImportGrafanaDashboardRequest(ctx, 0)
+	}
+}
+
+func createApiKeyHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		apikeys.ProcessCreateApiKeyRequest(ctx, 0)
+	}
+}
+
+func listApiKeysHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		apikeys.ProcessListApiKeysRequest(ctx, 0)
+	}
+}
+
+func rotateApiKeyHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		apikeys.ProcessRotateApiKeyRequest(ctx, 0)
+	}
+}
+
+func revokeApiKeyHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		apikeys.ProcessRevokeApiKeyRequest(ctx, 0)
+	}
+}
+
+func setApiKeyRolesHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		apikeys.ProcessSetApiKeyRolesRequest(ctx, 0)
+	}
+}
+
+func createRoleHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		apikeys.ProcessCreateRoleRequest(ctx, 0)
+	}
+}
+
+func updateRoleHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		apikeys.ProcessUpdateRoleRequest(ctx, 0)
+	}
+}
+
+func listRolesHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		apikeys.ProcessListRolesRequest(ctx, 0)
+	}
+}
+
+func deleteRoleHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		apikeys.ProcessDeleteRoleRequest(ctx, 0)
+	}
+}
+
+func createMaskingPolicyHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		apikeys.ProcessCreateMaskingPolicyRequest(ctx, 0)
+	}
+}
+
+func updateMaskingPolicyHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		apikeys.ProcessUpdateMaskingPolicyRequest(ctx, 0)
+	}
+}
+
+func listMaskingPoliciesHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		apikeys.ProcessListMaskingPoliciesRequest(ctx, 0)
+	}
+}
+
+func deleteMaskingPolicyHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		apikeys.ProcessDeleteMaskingPolicyRequest(ctx, 0)
+	}
+}
+
+func listClusterNodesHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		cluster.ProcessListNodesRequest(ctx)
+	}
+}
+
+func registerClusterNodeHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		cluster.ProcessRegisterNodeRequest(ctx)
+	}
+}
+
+func removeClusterNodeHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		cluster.ProcessRemoveNodeRequest(ctx)
+	}
+}
+
+func drainClusterNodeHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		cluster.ProcessDrainNodeRequest(ctx)
+	}
+}
+
+func getSegmentOwnershipHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		cluster.ProcessSegmentOwnershipRequest(ctx, 0)
+	}
+}
+
+func getRebalancePlanHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		cluster.ProcessRebalancePlanRequest(ctx, 0)
+	}
+}
+
+func startRebalanceHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		cluster.ProcessStartRebalanceRequest(ctx, 0)
+	}
+}
+
+func getRebalanceStatusHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		cluster.ProcessRebalanceStatusRequest(ctx)
+	}
+}
+
+func cancelRebalanceHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		cluster.ProcessCancelRebalanceRequest(ctx)
+	}
+}
+
+func startSegmentMovesHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		cluster.ProcessStartMovesRequest(ctx, 0)
+	}
+}
+
+func receiveSegmentFileHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		cluster.ProcessReceiveSegmentFileRequest(ctx)
+	}
+}
+
+func adoptSegmentHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		cluster.ProcessAdoptSegmentRequest(ctx)
+	}
+}
+
+func abortSegmentHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		cluster.ProcessAbortSegmentRequest(ctx)
+	}
+}
+
+func getSegmentInspectHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		inspect.ProcessSegmentInspectRequest(ctx, 0)
+	}
+}
+
+func getIntegrityStatusHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		integrity.ProcessIntegrityStatusRequest(ctx)
+	}
+}
+
+func runIntegrityCheckHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		integrity.ProcessIntegrityCheckRequest(ctx)
+	}
+}
+
+func restoreQuarantinedSegmentHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		integrity.ProcessRestoreSegmentRequest(ctx)
+	}
+}
+
 func getSafeHealthHandler() func(ctx *fasthttp.RequestCtx) {
 	return func(ctx *fasthttp.RequestCtx) {
 		health.ProcessSafeHealth(ctx)
@@ -368,7 +670,7 @@ func sampleDatasetBulkHandler() func(ctx *fasthttp.RequestCtx) {
 
 func lokiLabelsHandler() func(ctx *fasthttp.RequestCtx) {
 	return func(ctx *fasthttp.RequestCtx) {
-		loki.ProcessLokiLabelRequest(ctx)
+		loki.ProcessLokiLabelRequest(ctx, 0)
 	}
 }
 
@@ -384,6 +686,12 @@ func lokiQueryHandler() func(ctx *fasthttp.RequestCtx) {
 	}
 }
 
+func lokiQueryRangeHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		loki.ProcessQueryRangeRequest(ctx, 0)
+	}
+}
+
 func lokiIndexStatsHandler() func(ctx *fasthttp.RequestCtx) {
 	return func(ctx *fasthttp.RequestCtx) {
 		loki.ProcessIndexStatsRequest(ctx, 0)
@@ -415,6 +723,12 @@ func getAlertHandler() func(ctx *fasthttp.RequestCtx) {
 	}
 }
 
+func getAlertHistoryHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		alertsHandler.ProcessGetAlertHistoryRequest(ctx)
+	}
+}
+
 func getAllAlertsHandler() func(ctx *fasthttp.RequestCtx) {
 	return func(ctx *fasthttp.RequestCtx) {
 		alertsHandler.ProcessGetAllAlertsRequest(ctx)
@@ -483,6 +797,9 @@ func getMinionSearchHandler() func(ctx *fasthttp.RequestCtx) {
 
 func liveTailHandler(myid uint64) func(ctx *fasthttp.RequestCtx) {
 	return func(ctx *fasthttp.RequestCtx) {
+		// the request ctx can not be used once the connection has been upgraded
+		indexFilter := apikeys.GetIndexFilter(ctx, apikeys.ACCESS_READ)
+		apiKey := apikeys.GetRequestApiKey(ctx)
 		err := upgrader.Upgrade(ctx, func(conn *websocket.Conn) {
 			defer func() {
 				deadline := time.Now().Add(time.Second * 5)
@@ -501,7 +818,7 @@ func liveTailHandler(myid uint64) func(ctx *fasthttp.RequestCtx) {
 					return
 				}
 			}()
-			pipesearch.ProcessPipeSearchWebsocket(conn, myid)
+			pipesearch.ProcessPipeSearchWebsocket(conn, myid, indexFilter, apiKey)
 		})
 		if err != nil {
 			log.Errorf("liveTailHandler: Error upgrading websocket connection %+v", err)
@@ -523,8 +840,38 @@ func getDependencyGraphHandler() func(ctx *fasthttp.RequestCtx) {
 	}
 }
 
+func getServiceMapHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		tracinghandler.ProcessServiceMapRequest(ctx, 0)
+	}
+}
+
 func ganttChartHandler() func(ctx *fasthttp.RequestCtx) {
 	return func(ctx *fasthttp.RequestCtx) {
 		tracinghandler.ProcessGanttChartRequest(ctx, 0)
 	}
 }
+
+func jaegerServicesHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		tracinghandler.ProcessJaegerServicesRequest(ctx, 0)
+	}
+}
+
+func jaegerOperationsHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		tracinghandler.ProcessJaegerOperationsRequest(ctx, 0)
+	}
+}
+
+func jaegerSearchHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		tracinghandler.ProcessJaegerSearchRequest(ctx, 0)
+	}
+}
+
+func jaegerTraceHandler() func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		tracinghandler.ProcessJaegerTraceRequest(ctx, 0)
+	}
+}
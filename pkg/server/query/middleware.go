@@ -17,6 +17,12 @@ limitations under the License.
 package queryserver
 
 import (
+	"strings"
+
+	"github.com/siglens/siglens/pkg/apikeys"
+	"github.com/siglens/siglens/pkg/ingest"
+	server_utils "github.com/siglens/siglens/pkg/server/utils"
+	"github.com/siglens/siglens/pkg/utils"
 	"github.com/valyala/fasthttp"
 )
 
@@ -32,6 +38,34 @@ func (hs *queryserverCfg) Recovery(next func(ctx *fasthttp.RequestCtx)) func(ctx
 	return fn
 }
 
+// Rejects the request with a 429 while the ingest buffers are over their limits or the org is over its ingest quotas
+func (hs *queryserverCfg) Backpressure(next func(ctx *fasthttp.RequestCtx)) func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		if ingest.RejectIfUnderBackpressure(ctx) || ingest.RejectIfOverIngestQuota(ctx, 0) {
+			return
+		}
+		next(ctx)
+	}
+}
+
+/*
+Rejects the ingest requests with a 403 while the ingest server requires client certificates, as the query server does
+not ask for them. The clients have to send them to the ingest server instead
+*/
+func (hs *queryserverCfg) RequireIngestClientCert(next func(ctx *fasthttp.RequestCtx)) func(ctx *fasthttp.RequestCtx) {
+	return func(ctx *fasthttp.RequestCtx) {
+		if server_utils.IsIngestClientCertRequired() {
+			ctx.SetStatusCode(fasthttp.StatusForbidden)
+			utils.WriteResponse(ctx, utils.HttpServerResponse{
+				Message:    "ingest requires a client certificate, send it to the ingest server",
+				StatusCode: fasthttp.StatusForbidden,
+			})
+			return
+		}
+		next(ctx)
+	}
+}
+
 func cors(next fasthttp.RequestHandler) fasthttp.RequestHandler {
 	return func(ctx *fasthttp.RequestCtx) {
 		ctx.Response.Header.Set("Access-Control-Allow-Headers", corsAllowHeaders)
@@ -40,3 +74,54 @@ func cors(next fasthttp.RequestHandler) fasthttp.RequestHandler {
 		next(ctx)
 	}
 }
+
+// Responds with a 401 or 403 to the requests that do not have an api key with the scope of the endpoint
+func authenticate(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if !apikeys.AuthorizeRequest(ctx, getRequiredScope(string(ctx.Method()), string(ctx.Path()))) {
+			return
+		}
+		next(ctx)
+	}
+}
+
+func hasPathPrefix(path string, prefix string) bool {
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// Returns the api key scope of the endpoint, the pages of the ui and the health endpoints need none
+func getRequiredScope(method string, path string) string {
+	if method == fasthttp.MethodOptions {
+		return ""
+	}
+	switch {
+	case hasPathPrefix(path, server_utils.API_PREFIX+"/apikeys"),
+		hasPathPrefix(path, server_utils.API_PREFIX+"/roles"),
+		hasPathPrefix(path, server_utils.API_PREFIX+"/masking"),
+		hasPathPrefix(path, server_utils.API_PREFIX+"/setconfig"),
+		hasPathPrefix(path, server_utils.API_PREFIX+"/config"),
+		hasPathPrefix(path, server_utils.API_PREFIX+"/cluster"),
+		hasPathPrefix(path, server_utils.API_PREFIX+"/segments/inspect"),
+		hasPathPrefix(path, server_utils.API_PREFIX+"/integrity"),
+		hasPathPrefix(path, "/debug"),
+		path == "/metrics":
+		return apikeys.SCOPE_ADMIN
+	case path == server_utils.API_PREFIX+"/health",
+		hasPathPrefix(path, "/health"),
+		path == server_utils.API_PREFIX+"/version/info",
+		hasPathPrefix(path, server_utils.SPLUNK_PREFIX+"/services/collector/health"):
+		return ""
+	case path == server_utils.API_PREFIX+"/sampledataset_bulk",
+		path == server_utils.ELASTIC_PREFIX+"/_bulk",
+		// creating an index
+		method == fasthttp.MethodPut && hasPathPrefix(path, server_utils.ELASTIC_PREFIX) && strings.Count(path, "/") == 2:
+		return apikeys.SCOPE_INGEST
+	}
+	for _, prefix := range []string{server_utils.API_PREFIX, server_utils.ELASTIC_PREFIX, server_utils.LOKI_PREFIX,
+		server_utils.OTSDB_PREFIX, server_utils.PROMQL_PREFIX, server_utils.SPLUNK_PREFIX} {
+		if hasPathPrefix(path, prefix) {
+			return apikeys.SCOPE_QUERY
+		}
+	}
+	return ""
+}
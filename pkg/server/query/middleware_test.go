@@ -0,0 +1,91 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queryserver
+
+import (
+	"testing"
+
+	"github.com/siglens/siglens/pkg/apikeys"
+	"github.com/siglens/siglens/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func Test_getRequiredScope(t *testing.T) {
+	cases := []struct {
+		method string
+		path   string
+		scope  string
+	}{
+		{"GET", "/index.html", ""},
+		{"GET", "/js/dashboard.js", ""},
+		{"GET", "/api/health", ""},
+		{"GET", "/health/ready", ""},
+		{"OPTIONS", "/api/search", ""},
+		{"POST", "/api/search", apikeys.SCOPE_QUERY},
+		{"POST", "/api/search/panel-1", apikeys.SCOPE_QUERY},
+		{"GET", "/promql/api/v1/query", apikeys.SCOPE_QUERY},
+		{"POST", "/elastic/_search", apikeys.SCOPE_QUERY},
+		{"PUT", "/elastic/myindex/_alias/myalias", apikeys.SCOPE_QUERY},
+		{"POST", "/elastic/_bulk", apikeys.SCOPE_INGEST},
+		{"PUT", "/elastic/myindex", apikeys.SCOPE_INGEST},
+		{"POST", "/api/sampledataset_bulk", apikeys.SCOPE_INGEST},
+		{"POST", "/api/apikeys/create", apikeys.SCOPE_ADMIN},
+		{"POST", "/api/roles/create", apikeys.SCOPE_ADMIN},
+		{"POST", "/api/setconfig/persistent", apikeys.SCOPE_ADMIN},
+		{"GET", "/api/config", apikeys.SCOPE_ADMIN},
+		{"GET", "/debug/pprof/heap", apikeys.SCOPE_ADMIN},
+		{"GET", "/metrics", apikeys.SCOPE_ADMIN},
+		{"GET", "/api/cluster/nodes", apikeys.SCOPE_ADMIN},
+		{"POST", "/api/cluster/nodes/node-2/drain", apikeys.SCOPE_ADMIN},
+		{"POST", "/api/cluster/segments/adopt", apikeys.SCOPE_ADMIN},
+		{"POST", "/api/cluster/segments/abort", apikeys.SCOPE_ADMIN},
+		{"GET", "/api/segments/inspect", apikeys.SCOPE_ADMIN},
+		{"POST", "/api/integrity/check", apikeys.SCOPE_ADMIN},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.scope, getRequiredScope(c.method, c.path), c.method+" "+c.path)
+	}
+}
+
+func Test_RequireIngestClientCert(t *testing.T) {
+	config.InitializeTestingConfig()
+	hs := &queryserverCfg{}
+	handler := hs.RequireIngestClientCert(func(ctx *fasthttp.RequestCtx) {
+		ctx.SetStatusCode(fasthttp.StatusOK)
+	})
+	getStatusCode := func() int {
+		ctx := &fasthttp.RequestCtx{}
+		handler(ctx)
+		return ctx.Response.StatusCode()
+	}
+	assert.Equal(t, fasthttp.StatusOK, getStatusCode())
+
+	runningConfig := config.GetRunningConfig()
+	runningConfig.TLS = config.TLSConfig{Enabled: true, CertificatePath: "server.crt", PrivateKeyPath: "server.key",
+		IngestClientCAPath: "ca.crt"}
+	config.SetConfig(*runningConfig)
+	assert.Equal(t, fasthttp.StatusForbidden, getStatusCode())
+
+	// the ingest server lets the clients without a certificate in too
+	runningConfig.TLS.IngestClientAuth = "verifyIfGiven"
+	config.SetConfig(*runningConfig)
+	assert.Equal(t, fasthttp.StatusOK, getStatusCode())
+
+	runningConfig.TLS = config.TLSConfig{}
+	config.SetConfig(*runningConfig)
+}
@@ -99,14 +99,18 @@ func (hs *queryserverCfg) Run(tpl *template.Template) error {
 	hs.Router.POST(server_utils.API_PREFIX+"/search/live_tail", hs.Recovery(liveTailHandler(0)))
 	hs.Router.POST(server_utils.API_PREFIX+"/search", hs.Recovery(pipeSearchHandler()))
 	hs.Router.POST(server_utils.API_PREFIX+"/search/{dbPanel-id}", hs.Recovery(dashboardPipeSearchHandler()))
+	hs.Router.POST(server_utils.API_PREFIX+"/sql", hs.Recovery(sqlQueryHandler()))
 	hs.Router.GET(server_utils.API_PREFIX+"/search/ws", hs.Recovery(pipeSearchWebsocketHandler(0)))
 
 	hs.Router.POST(server_utils.API_PREFIX+"/search/ws", hs.Recovery(pipeSearchWebsocketHandler(0)))
-	hs.Router.POST(server_utils.API_PREFIX+"/sampledataset_bulk", hs.Recovery(sampleDatasetBulkHandler()))
+	hs.Router.POST(server_utils.API_PREFIX+"/sampledataset_bulk", hs.Recovery(hs.RequireIngestClientCert(hs.Backpressure(sampleDatasetBulkHandler()))))
 
 	// common routes
 
 	hs.Router.GET(server_utils.API_PREFIX+"/health", hs.Recovery(getHealthHandler()))
+	hs.Router.GET("/health/live", hs.Recovery(getLivenessHandler()))
+	hs.Router.GET("/health/ready", hs.Recovery(getReadinessHandler()))
+	hs.Router.GET("/metrics", hs.Recovery(getMetricsHandler()))
 	hs.Router.POST(server_utils.API_PREFIX+"/setconfig/transient", hs.Recovery(postSetconfigHandler(false)))
 	hs.Router.POST(server_utils.API_PREFIX+"/setconfig/persistent", hs.Recovery(postSetconfigHandler(true)))
 	hs.Router.GET(server_utils.API_PREFIX+"/config", hs.Recovery(getConfigHandler()))
@@ -146,6 +150,19 @@ func (hs *queryserverCfg) Run(tpl *template.Template) error {
 
 	hs.Router.GET(server_utils.ELASTIC_PREFIX+"/_aliases", hs.Recovery(esGetAllAliasesHandler()))
 	hs.Router.GET(server_utils.ELASTIC_PREFIX+"/_cat/aliases", hs.Recovery(esGetAllAliasesHandler()))
+	hs.Router.GET(server_utils.ELASTIC_PREFIX+"/_cat/indices", hs.Recovery(esCatIndicesHandler()))
+	hs.Router.GET(server_utils.ELASTIC_PREFIX+"/_cat/indices/{indexName}", hs.Recovery(esCatIndicesHandler()))
+	hs.Router.GET(server_utils.ELASTIC_PREFIX+"/_cat/count", hs.Recovery(esCatCountHandler()))
+	hs.Router.GET(server_utils.ELASTIC_PREFIX+"/_cat/count/{indexName}", hs.Recovery(esCatCountHandler()))
+	hs.Router.GET(server_utils.ELASTIC_PREFIX+"/_cat/health", hs.Recovery(esCatHealthHandler()))
+	hs.Router.GET(server_utils.ELASTIC_PREFIX+"/_cat/nodes", hs.Recovery(esCatNodesHandler()))
+
+	hs.Router.GET(server_utils.ELASTIC_PREFIX+"/_mapping", hs.Recovery(esGetMappingHandler()))
+	hs.Router.GET(server_utils.ELASTIC_PREFIX+"/{indexName}/_mapping", hs.Recovery(esGetMappingHandler()))
+	hs.Router.GET(server_utils.ELASTIC_PREFIX+"/_field_caps", hs.Recovery(esFieldCapsHandler()))
+	hs.Router.POST(server_utils.ELASTIC_PREFIX+"/_field_caps", hs.Recovery(esFieldCapsHandler()))
+	hs.Router.GET(server_utils.ELASTIC_PREFIX+"/{indexName}/_field_caps", hs.Recovery(esFieldCapsHandler()))
+	hs.Router.POST(server_utils.ELASTIC_PREFIX+"/{indexName}/_field_caps", hs.Recovery(esFieldCapsHandler()))
 
 	hs.Router.HEAD(server_utils.ELASTIC_PREFIX+"/{indexName}", hs.Recovery(esGetIndexAliasExistsHandler()))
 	/*
@@ -156,7 +173,7 @@ func (hs *queryserverCfg) Run(tpl *template.Template) error {
 	hs.Router.GET(server_utils.LOKI_PREFIX+"/api/v1/labels", hs.Recovery(lokiLabelsHandler()))
 	hs.Router.GET(server_utils.LOKI_PREFIX+"/api/v1/label/{labelName}/values", hs.Recovery(lokiLabelValueHandler()))
 	hs.Router.GET(server_utils.LOKI_PREFIX+"/api/v1/query", hs.Recovery(lokiQueryHandler()))
-	hs.Router.GET(server_utils.LOKI_PREFIX+"/api/v1/query_range", hs.Recovery(lokiQueryHandler()))
+	hs.Router.GET(server_utils.LOKI_PREFIX+"/api/v1/query_range", hs.Recovery(lokiQueryRangeHandler()))
 	hs.Router.GET(server_utils.LOKI_PREFIX+"/api/v1/index/stats", hs.Recovery(lokiIndexStatsHandler()))
 	hs.Router.GET(server_utils.LOKI_PREFIX+"/api/v1/series", hs.Recovery(lokiSeriesHandler()))
 	hs.Router.POST(server_utils.LOKI_PREFIX+"/api/v1/series", hs.Recovery(lokiSeriesHandler()))
@@ -173,6 +190,13 @@ func (hs *queryserverCfg) Run(tpl *template.Template) error {
 	//prometheus query endpoint
 	hs.Router.POST(server_utils.PROMQL_PREFIX+"/api/v1/query", hs.Recovery(metricsSearchHandler()))
 	hs.Router.GET(server_utils.PROMQL_PREFIX+"/api/v1/query", hs.Recovery(metricsSearchHandler()))
+	hs.Router.POST(server_utils.PROMQL_PREFIX+"/api/v1/query_range", hs.Recovery(metricsRangeSearchHandler()))
+	hs.Router.GET(server_utils.PROMQL_PREFIX+"/api/v1/query_range", hs.Recovery(metricsRangeSearchHandler()))
+	hs.Router.GET(server_utils.PROMQL_PREFIX+"/api/v1/labels", hs.Recovery(metricsLabelNamesHandler()))
+	hs.Router.POST(server_utils.PROMQL_PREFIX+"/api/v1/labels", hs.Recovery(metricsLabelNamesHandler()))
+	hs.Router.GET(server_utils.PROMQL_PREFIX+"/api/v1/label/{name}/values", hs.Recovery(metricsLabelValuesHandler()))
+	hs.Router.GET(server_utils.PROMQL_PREFIX+"/api/v1/series", hs.Recovery(metricsSeriesHandler()))
+	hs.Router.POST(server_utils.PROMQL_PREFIX+"/api/v1/series", hs.Recovery(metricsSeriesHandler()))
 	hs.Router.POST(server_utils.PROMQL_PREFIX+"/api/ui/query", hs.Recovery(uiMetricsSearchHandler()))
 
 	// search api Handlers
@@ -180,6 +204,8 @@ func (hs *queryserverCfg) Run(tpl *template.Template) error {
 	hs.Router.GET(server_utils.API_PREFIX+"/listIndices", hs.Recovery(listIndicesHandler()))
 	hs.Router.GET(server_utils.API_PREFIX+"/clusterStats", hs.Recovery(getClusterStatsHandler()))
 	hs.Router.POST(server_utils.API_PREFIX+"/clusterIngestStats", hs.Recovery(getClusterIngestStatsHandler()))
+	hs.Router.GET(server_utils.API_PREFIX+"/usage/quotas", hs.Recovery(getQuotaUsageHandler()))
+	hs.Router.GET(server_utils.API_PREFIX+"/usageStats/indices", hs.Recovery(getIndexUsageStatsHandler()))
 	hs.Router.POST(server_utils.API_PREFIX+"/usersavedqueries/save", hs.Recovery(saveUserSavedQueriesHandler()))
 	hs.Router.GET(server_utils.API_PREFIX+"/usersavedqueries/getall", hs.Recovery(getUserSavedQueriesAllHandler()))
 	hs.Router.GET(server_utils.API_PREFIX+"/usersavedqueries/deleteone/{qname}", hs.Recovery(deleteUserSavedQueryHandler()))
@@ -195,12 +221,50 @@ func (hs *queryserverCfg) Run(tpl *template.Template) error {
 	hs.Router.GET(server_utils.API_PREFIX+"/dashboards/{dashboard-id}", hs.Recovery(getDashboardIdHandler()))
 	hs.Router.GET(server_utils.API_PREFIX+"/dashboards/delete/{dashboard-id}", hs.Recovery(deleteDashboardHandler()))
 	hs.Router.PUT(server_utils.API_PREFIX+"/dashboards/favorite/{dashboard-id}", hs.Recovery(favoriteDashboardHandler()))
+	hs.Router.GET(server_utils.API_PREFIX+"/dashboards/{dashboard-id}/variables/{variable-name}/options", hs.Recovery(getDashboardVariableOptionsHandler()))
+	hs.Router.GET(server_utils.API_PREFIX+"/dashboards/{dashboard-id}/grafana", hs.Recovery(exportGrafanaDashboardHandler()))
+	hs.Router.POST(server_utils.API_PREFIX+"/dashboards/import/grafana", hs.Recovery(importGrafanaDashboardHandler()))
 	hs.Router.GET(server_utils.API_PREFIX+"/version/info", hs.Recovery(getVersionHandler()))
 
+	// api key endpoints
+	hs.Router.POST(server_utils.API_PREFIX+"/apikeys/create", hs.Recovery(createApiKeyHandler()))
+	hs.Router.GET(server_utils.API_PREFIX+"/apikeys/listall", hs.Recovery(listApiKeysHandler()))
+	hs.Router.POST(server_utils.API_PREFIX+"/apikeys/rotate/{key-id}", hs.Recovery(rotateApiKeyHandler()))
+	hs.Router.DELETE(server_utils.API_PREFIX+"/apikeys/{key-id}", hs.Recovery(revokeApiKeyHandler()))
+	hs.Router.POST(server_utils.API_PREFIX+"/apikeys/{key-id}/roles", hs.Recovery(setApiKeyRolesHandler()))
+	hs.Router.POST(server_utils.API_PREFIX+"/roles/create", hs.Recovery(createRoleHandler()))
+	hs.Router.POST(server_utils.API_PREFIX+"/roles/update", hs.Recovery(updateRoleHandler()))
+	hs.Router.GET(server_utils.API_PREFIX+"/roles/listall", hs.Recovery(listRolesHandler()))
+	hs.Router.DELETE(server_utils.API_PREFIX+"/roles/{role-name}", hs.Recovery(deleteRoleHandler()))
+	hs.Router.POST(server_utils.API_PREFIX+"/masking/create", hs.Recovery(createMaskingPolicyHandler()))
+	hs.Router.POST(server_utils.API_PREFIX+"/masking/update", hs.Recovery(updateMaskingPolicyHandler()))
+	hs.Router.GET(server_utils.API_PREFIX+"/masking/listall", hs.Recovery(listMaskingPoliciesHandler()))
+	hs.Router.DELETE(server_utils.API_PREFIX+"/masking/{policy-name}", hs.Recovery(deleteMaskingPolicyHandler()))
+
+	// cluster membership api endpoints
+	hs.Router.GET(server_utils.API_PREFIX+"/cluster/nodes", hs.Recovery(listClusterNodesHandler()))
+	hs.Router.POST(server_utils.API_PREFIX+"/cluster/nodes", hs.Recovery(registerClusterNodeHandler()))
+	hs.Router.DELETE(server_utils.API_PREFIX+"/cluster/nodes/{node-id}", hs.Recovery(removeClusterNodeHandler()))
+	hs.Router.POST(server_utils.API_PREFIX+"/cluster/nodes/{node-id}/drain", hs.Recovery(drainClusterNodeHandler()))
+	hs.Router.GET(server_utils.API_PREFIX+"/cluster/ownership", hs.Recovery(getSegmentOwnershipHandler()))
+	hs.Router.GET(server_utils.API_PREFIX+"/cluster/rebalance/plan", hs.Recovery(getRebalancePlanHandler()))
+	hs.Router.POST(server_utils.API_PREFIX+"/cluster/rebalance", hs.Recovery(startRebalanceHandler()))
+	hs.Router.GET(server_utils.API_PREFIX+"/cluster/rebalance", hs.Recovery(getRebalanceStatusHandler()))
+	hs.Router.POST(server_utils.API_PREFIX+"/cluster/rebalance/cancel", hs.Recovery(cancelRebalanceHandler()))
+	hs.Router.POST(server_utils.API_PREFIX+"/cluster/rebalance/moves", hs.Recovery(startSegmentMovesHandler()))
+	hs.Router.POST(server_utils.API_PREFIX+"/cluster/segments/files", hs.Recovery(receiveSegmentFileHandler()))
+	hs.Router.POST(server_utils.API_PREFIX+"/cluster/segments/adopt", hs.Recovery(adoptSegmentHandler()))
+	hs.Router.POST(server_utils.API_PREFIX+"/cluster/segments/abort", hs.Recovery(abortSegmentHandler()))
+	hs.Router.GET(server_utils.API_PREFIX+"/segments/inspect", hs.Recovery(getSegmentInspectHandler()))
+	hs.Router.GET(server_utils.API_PREFIX+"/integrity", hs.Recovery(getIntegrityStatusHandler()))
+	hs.Router.POST(server_utils.API_PREFIX+"/integrity/check", hs.Recovery(runIntegrityCheckHandler()))
+	hs.Router.POST(server_utils.API_PREFIX+"/integrity/quarantine/restore", hs.Recovery(restoreQuarantinedSegmentHandler()))
+
 	// alerting api endpoints
 	hs.Router.POST(server_utils.API_PREFIX+"/alerts/create", hs.Recovery(createAlertHandler()))
 	hs.Router.GET(server_utils.API_PREFIX+"/alerts/{alertID}", hs.Recovery(getAlertHandler()))
 	hs.Router.GET(server_utils.API_PREFIX+"/allalerts", hs.Recovery(getAllAlertsHandler()))
+	hs.Router.GET(server_utils.API_PREFIX+"/alerts/history", hs.Recovery(getAlertHistoryHandler()))
 	hs.Router.POST(server_utils.API_PREFIX+"/alerts/update", hs.Recovery(updateAlertHandler()))
 	hs.Router.DELETE(server_utils.API_PREFIX+"/alerts/delete", hs.Recovery(deleteAlertHandler()))
 	hs.Router.POST(server_utils.API_PREFIX+"/alerts/createContact", hs.Recovery(createContactHandler()))
@@ -217,11 +281,18 @@ func (hs *queryserverCfg) Run(tpl *template.Template) error {
 	// tracing api endpoints
 	hs.Router.POST(server_utils.API_PREFIX+"/traces/search", hs.Recovery(searchTracesHandler()))
 	hs.Router.GET(server_utils.API_PREFIX+"/traces/dependencies", hs.Recovery(getDependencyGraphHandler()))
+	hs.Router.GET(server_utils.API_PREFIX+"/traces/serviceMap", hs.Recovery(getServiceMapHandler()))
 	hs.Router.POST(server_utils.API_PREFIX+"/traces/ganttChart", hs.Recovery(ganttChartHandler()))
 
+	// jaeger compatible trace query api endpoints
+	hs.Router.GET(server_utils.API_PREFIX+"/services", hs.Recovery(jaegerServicesHandler()))
+	hs.Router.GET(server_utils.API_PREFIX+"/services/{service}/operations", hs.Recovery(jaegerOperationsHandler()))
+	hs.Router.GET(server_utils.API_PREFIX+"/traces", hs.Recovery(jaegerSearchHandler()))
+	hs.Router.GET(server_utils.API_PREFIX+"/traces/{traceID}", hs.Recovery(jaegerTraceHandler()))
+
 	// query server should still setup ES APIs for Kibana integration
-	hs.Router.POST(server_utils.ELASTIC_PREFIX+"/_bulk", hs.Recovery(esPostBulkHandler()))
-	hs.Router.PUT(server_utils.ELASTIC_PREFIX+"/{indexName}", hs.Recovery(esPutIndexHandler()))
+	hs.Router.POST(server_utils.ELASTIC_PREFIX+"/_bulk", hs.Recovery(hs.RequireIngestClientCert(hs.Backpressure(esPostBulkHandler()))))
+	hs.Router.PUT(server_utils.ELASTIC_PREFIX+"/{indexName}", hs.Recovery(hs.RequireIngestClientCert(esPutIndexHandler())))
 
 	if config.IsDebugMode() {
 		hs.Router.GET("/debug/pprof/{profile:*}", pprofhandler.PprofHandler)
@@ -236,7 +307,7 @@ func (hs *queryserverCfg) Run(tpl *template.Template) error {
 	}
 
 	s := &fasthttp.Server{
-		Handler:            cors(hs.Router.Handler),
+		Handler:            cors(authenticate(hs.Router.Handler)),
 		Name:               hs.Config.Name,
 		ReadBufferSize:     hs.Config.ReadBufferSize,
 		MaxConnsPerIP:      hs.Config.MaxConnsPerIP,
@@ -245,7 +316,20 @@ func (hs *queryserverCfg) Run(tpl *template.Template) error {
 		Concurrency:        hs.Config.Concurrency,
 	}
 	var g run.Group
-	if config.IsTlsEnabled() && config.GetTLSACMEDir() != "" {
+	if config.IsTlsEnabled() && config.GetTLSConfig().CertificatePath != "" {
+		cfg, err := server_utils.GetServerTlsConfig(false)
+		if err != nil {
+			log.Errorf("Run: failed to set up tls, err=%v", err)
+			return err
+		}
+		hs.lnTls = tls.NewListener(hs.ln, cfg)
+		// run fasthttp server
+		g.Add(func() error {
+			return s.Serve(hs.lnTls)
+		}, func(e error) {
+			_ = hs.ln.Close()
+		})
+	} else if config.IsTlsAcmeEnabled() {
 		m := &autocert.Manager{
 			Prompt:     autocert.AcceptTOS,
 			HostPolicy: autocert.HostWhitelist(config.GetQueryHostname()),
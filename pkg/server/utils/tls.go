@@ -0,0 +1,235 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_utils
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/siglens/siglens/pkg/config"
+	log "github.com/sirupsen/logrus"
+)
+
+/*
+Serves the certificate files of the tls config and reloads them when they change, so that a rotated certificate is
+picked up without a restart. The files are checked every TLS_RELOAD_INTERVAL and on every config reload, which can
+also point to new files. A file that fails to load keeps the previous certificate in use.
+*/
+
+const TLS_RELOAD_INTERVAL = 30 * time.Second
+
+const (
+	CLIENT_AUTH_REQUIRE         = "require"
+	CLIENT_AUTH_VERIFY_IF_GIVEN = "verifyIfGiven"
+)
+
+type certReloader struct {
+	lock           sync.RWMutex
+	certPath       string
+	keyPath        string
+	caPath         string // empty when the client certificates are not verified
+	cert           *tls.Certificate
+	clientCAs      *x509.CertPool
+	certModTime    time.Time
+	keyModTime     time.Time
+	caModTime      time.Time
+	loadedCertPath string // the files that cert and clientCAs were loaded from
+	loadedKeyPath  string
+	loadedCAPath   string
+	baseTlsCfg     *tls.Config
+}
+
+/*
+Returns the tls config of a server that uses the certificate files of the tls config. With verifyClients, the clients
+have to present a certificate signed by the ingest client CA, or may present one for verifyIfGiven
+*/
+func GetServerTlsConfig(verifyClients bool) (*tls.Config, error) {
+	cr, err := newCertReloader(config.GetTLSConfig(), verifyClients)
+	if err != nil {
+		log.Errorf("GetServerTlsConfig: failed to load the certificates, err=%v", err)
+		return nil, err
+	}
+	go cr.reloadLooper()
+	config.AddReloadHook(func() {
+		cr.setPaths(config.GetTLSConfig())
+		err := cr.reload()
+		if err != nil {
+			log.Errorf("certReloader: failed to reload the certificates, the previous ones are still used, err=%v", err)
+		}
+	})
+
+	// the config is built per connection so that the connections use the latest client CAs
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return cr.getTlsConfig(), nil
+		},
+	}, nil
+}
+
+// Returns true if the ingest server only accepts the clients with a certificate signed by the ingest client CA
+func IsIngestClientCertRequired() bool {
+	tlsCfg := config.GetTLSConfig()
+	return tlsCfg.Enabled && tlsCfg.CertificatePath != "" && tlsCfg.IngestClientCAPath != "" &&
+		(tlsCfg.IngestClientAuth == "" || tlsCfg.IngestClientAuth == CLIENT_AUTH_REQUIRE)
+}
+
+func newCertReloader(tlsCfg config.TLSConfig, verifyClients bool) (*certReloader, error) {
+	if tlsCfg.CertificatePath == "" || tlsCfg.PrivateKeyPath == "" {
+		return nil, errors.New("certificatePath and privateKeyPath are both required")
+	}
+	cr := &certReloader{
+		certPath: tlsCfg.CertificatePath,
+		keyPath:  tlsCfg.PrivateKeyPath,
+	}
+	cr.baseTlsCfg = &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: cr.getCertificate,
+		NextProtos:     []string{"http/1.1"},
+	}
+	if verifyClients && tlsCfg.IngestClientCAPath != "" {
+		switch tlsCfg.IngestClientAuth {
+		case "", CLIENT_AUTH_REQUIRE:
+			cr.baseTlsCfg.ClientAuth = tls.RequireAndVerifyClientCert
+		case CLIENT_AUTH_VERIFY_IF_GIVEN:
+			cr.baseTlsCfg.ClientAuth = tls.VerifyClientCertIfGiven
+		default:
+			return nil, fmt.Errorf("invalid ingestClientAuth %v, it is %v or %v",
+				tlsCfg.IngestClientAuth, CLIENT_AUTH_REQUIRE, CLIENT_AUTH_VERIFY_IF_GIVEN)
+		}
+		cr.caPath = tlsCfg.IngestClientCAPath
+	}
+
+	err := cr.reload()
+	if err != nil {
+		return nil, err
+	}
+	return cr, nil
+}
+
+func (cr *certReloader) reloadLooper() {
+	for {
+		time.Sleep(TLS_RELOAD_INTERVAL)
+		// the cert and key may be replaced one after the other, a mismatch is retried on the next interval
+		err := cr.reload()
+		if err != nil {
+			log.Errorf("certReloader: failed to reload the certificates, the previous ones are still used, err=%v", err)
+		}
+	}
+}
+
+// Points to the files of the reloaded tls config, the client CA is only changed when the clients are verified
+func (cr *certReloader) setPaths(tlsCfg config.TLSConfig) {
+	cr.lock.Lock()
+	defer cr.lock.Unlock()
+	if tlsCfg.CertificatePath != "" && tlsCfg.PrivateKeyPath != "" {
+		cr.certPath = tlsCfg.CertificatePath
+		cr.keyPath = tlsCfg.PrivateKeyPath
+	}
+	if cr.caPath != "" && tlsCfg.IngestClientCAPath != "" {
+		cr.caPath = tlsCfg.IngestClientCAPath
+	}
+}
+
+func getModTime(fname string) (time.Time, error) {
+	info, err := os.Stat(fname)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
+
+// Loads the files that changed since they were last loaded, or that are new
+func (cr *certReloader) reload() error {
+	cr.lock.RLock()
+	certPath, keyPath, caPath := cr.certPath, cr.keyPath, cr.caPath
+	loadedCertPath, loadedKeyPath, loadedCAPath := cr.loadedCertPath, cr.loadedKeyPath, cr.loadedCAPath
+	cr.lock.RUnlock()
+
+	certModTime, err := getModTime(certPath)
+	if err != nil {
+		return err
+	}
+	keyModTime, err := getModTime(keyPath)
+	if err != nil {
+		return err
+	}
+	var caModTime time.Time
+	if caPath != "" {
+		caModTime, err = getModTime(caPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	cr.lock.RLock()
+	certChanged := cr.cert == nil || !certModTime.Equal(cr.certModTime) || !keyModTime.Equal(cr.keyModTime) ||
+		certPath != loadedCertPath || keyPath != loadedKeyPath
+	caChanged := caPath != "" && (cr.clientCAs == nil || !caModTime.Equal(cr.caModTime) || caPath != loadedCAPath)
+	cr.lock.RUnlock()
+
+	if certChanged {
+		cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+		if err != nil {
+			return fmt.Errorf("failed to load certificate=%v and key=%v, err=%v", certPath, keyPath, err)
+		}
+		cr.lock.Lock()
+		cr.cert = &cert
+		cr.certModTime = certModTime
+		cr.keyModTime = keyModTime
+		cr.loadedCertPath = certPath
+		cr.loadedKeyPath = keyPath
+		cr.lock.Unlock()
+		log.Infof("certReloader: loaded certificate %v", certPath)
+	}
+	if caChanged {
+		caData, err := os.ReadFile(caPath)
+		if err != nil {
+			return err
+		}
+		clientCAs := x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(caData) {
+			return fmt.Errorf("no certificate found in %v", caPath)
+		}
+		cr.lock.Lock()
+		cr.clientCAs = clientCAs
+		cr.caModTime = caModTime
+		cr.loadedCAPath = caPath
+		cr.lock.Unlock()
+		log.Infof("certReloader: loaded client CAs %v", caPath)
+	}
+	return nil
+}
+
+func (cr *certReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cr.lock.RLock()
+	defer cr.lock.RUnlock()
+	return cr.cert, nil
+}
+
+func (cr *certReloader) getTlsConfig() *tls.Config {
+	tlsCfg := cr.baseTlsCfg.Clone()
+	cr.lock.RLock()
+	tlsCfg.ClientCAs = cr.clientCAs
+	cr.lock.RUnlock()
+	return tlsCfg
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server_utils
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/siglens/siglens/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// Returns the PEM encoded certificate and key, the certificate is self signed when parent is nil
+func createTestCert(t *testing.T, commonName string, isCA bool, parent *x509.Certificate,
+	parentKey *ecdsa.PrivateKey) ([]byte, []byte, *x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	assert.Nil(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.Nil(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), cert, key
+}
+
+func writeTestFile(t *testing.T, fname string, data []byte, modTime time.Time) {
+	assert.Nil(t, os.WriteFile(fname, data, 0600))
+	assert.Nil(t, os.Chtimes(fname, modTime, modTime))
+}
+
+func Test_certReloader(t *testing.T) {
+	dir := t.TempDir()
+	tlsCfg := config.TLSConfig{
+		Enabled:         true,
+		CertificatePath: filepath.Join(dir, "server.crt"),
+		PrivateKeyPath:  filepath.Join(dir, "server.key"),
+	}
+	_, err := newCertReloader(config.TLSConfig{Enabled: true, CertificatePath: tlsCfg.CertificatePath}, false)
+	assert.NotNil(t, err)
+	_, err = newCertReloader(tlsCfg, false)
+	assert.NotNil(t, err)
+
+	certPem, keyPem, cert, _ := createTestCert(t, "first", false, nil, nil)
+	modTime := time.Now().Add(-time.Minute)
+	writeTestFile(t, tlsCfg.CertificatePath, certPem, modTime)
+	writeTestFile(t, tlsCfg.PrivateKeyPath, keyPem, modTime)
+	cr, err := newCertReloader(tlsCfg, false)
+	assert.Nil(t, err)
+	served, err := cr.getCertificate(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, cert.Raw, served.Certificate[0])
+	assert.Equal(t, tls.NoClientCert, cr.getTlsConfig().ClientAuth)
+
+	// a rotated certificate is picked up
+	certPem, keyPem, cert, _ = createTestCert(t, "second", false, nil, nil)
+	modTime = modTime.Add(time.Second)
+	writeTestFile(t, tlsCfg.CertificatePath, certPem, modTime)
+	writeTestFile(t, tlsCfg.PrivateKeyPath, keyPem, modTime)
+	assert.Nil(t, cr.reload())
+	served, _ = cr.getCertificate(nil)
+	assert.Equal(t, cert.Raw, served.Certificate[0])
+
+	// a broken key keeps the previous certificate
+	writeTestFile(t, tlsCfg.PrivateKeyPath, []byte("not a key"), modTime.Add(time.Second))
+	assert.NotNil(t, cr.reload())
+	served, _ = cr.getCertificate(nil)
+	assert.Equal(t, cert.Raw, served.Certificate[0])
+
+	// new paths from a config reload are loaded even when the files are older
+	certPem, keyPem, cert, _ = createTestCert(t, "third", false, nil, nil)
+	tlsCfg.CertificatePath = filepath.Join(dir, "third.crt")
+	tlsCfg.PrivateKeyPath = filepath.Join(dir, "third.key")
+	writeTestFile(t, tlsCfg.CertificatePath, certPem, modTime.Add(-time.Hour))
+	writeTestFile(t, tlsCfg.PrivateKeyPath, keyPem, modTime.Add(-time.Hour))
+	cr.setPaths(tlsCfg)
+	assert.Nil(t, cr.reload())
+	served, _ = cr.getCertificate(nil)
+	assert.Equal(t, cert.Raw, served.Certificate[0])
+}
+
+func Test_certReloaderClientAuth(t *testing.T) {
+	dir := t.TempDir()
+	caPem, _, caCert, caKey := createTestCert(t, "clients-ca", true, nil, nil)
+	serverPem, serverKeyPem, _, _ := createTestCert(t, "server", false, caCert, caKey)
+	clientPem, clientKeyPem, _, _ := createTestCert(t, "client", false, caCert, caKey)
+	otherPem, otherKeyPem, _, _ := createTestCert(t, "other", false, nil, nil)
+	tlsCfg := config.TLSConfig{
+		Enabled:            true,
+		CertificatePath:    filepath.Join(dir, "server.crt"),
+		PrivateKeyPath:     filepath.Join(dir, "server.key"),
+		IngestClientCAPath: filepath.Join(dir, "ca.crt"),
+	}
+	writeTestFile(t, tlsCfg.CertificatePath, serverPem, time.Now())
+	writeTestFile(t, tlsCfg.PrivateKeyPath, serverKeyPem, time.Now())
+	writeTestFile(t, tlsCfg.IngestClientCAPath, caPem, time.Now())
+
+	tlsCfg.IngestClientAuth = "sometimes"
+	_, err := newCertReloader(tlsCfg, true)
+	assert.NotNil(t, err)
+	tlsCfg.IngestClientAuth = ""
+	cr, err := newCertReloader(tlsCfg, true)
+	assert.Nil(t, err)
+	assert.Equal(t, tls.RequireAndVerifyClientCert, cr.getTlsConfig().ClientAuth)
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) { return cr.getTlsConfig(), nil },
+	})
+	assert.Nil(t, err)
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.(*tls.Conn).Handshake()
+			_, _ = conn.Write([]byte("ok"))
+			_ = conn.Close()
+		}
+	}()
+
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(caPem)
+	dial := func(certPem []byte, keyPem []byte) error {
+		clientCfg := &tls.Config{RootCAs: roots, ServerName: "127.0.0.1"}
+		if certPem != nil {
+			clientCert, err := tls.X509KeyPair(certPem, keyPem)
+			assert.Nil(t, err)
+			// sent even when it is not signed by one of the CAs the server asks for
+			clientCfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+				return &clientCert, nil
+			}
+		}
+		conn, err := tls.Dial("tcp", ln.Addr().String(), clientCfg)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		// with tls 1.3 a rejected client certificate only shows up on the first read
+		buf := make([]byte, 2)
+		_, err = conn.Read(buf)
+		return err
+	}
+	assert.Nil(t, dial(clientPem, clientKeyPem))
+	assert.NotNil(t, dial(nil, nil))
+	assert.NotNil(t, dial(otherPem, otherKeyPem))
+
+	tlsCfg.IngestClientAuth = CLIENT_AUTH_VERIFY_IF_GIVEN
+	cr, err = newCertReloader(tlsCfg, true)
+	assert.Nil(t, err)
+	assert.Equal(t, tls.VerifyClientCertIfGiven, cr.getTlsConfig().ClientAuth)
+	assert.Nil(t, dial(nil, nil))
+	assert.NotNil(t, dial(otherPem, otherKeyPem))
+}
@@ -0,0 +1,256 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usageStats
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/siglens/siglens/pkg/segment/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+/*
+	The bytes and events ingested into each index and the queries that searched it are counted in memory and
+	appended to a csv file next to the org usage stats every minute, one row per index with
+	indexName,bytes,events,queries,epoch. Summing the rows of a time range gives the ingest rate of an index over time
+*/
+
+const INDEX_USAGE_STATS_FILENAME = "index_usage_stats.csv"
+
+// An index usage request over more buckets than this has to use a coarser granularity
+const MAX_INDEX_USAGE_BUCKETS = 10_000
+
+type indexCounts struct {
+	bytesCount uint64
+	eventCount uint64
+	queryCount uint64
+}
+
+// The usage of an index in the bucket of time starting at StartEpoch
+type IndexUsage struct {
+	StartEpoch uint64 `json:"startEpoch"` // in ms
+	BytesCount uint64 `json:"bytesCount"`
+	EventCount uint64 `json:"eventCount"`
+	QueryCount uint64 `json:"queryCount"`
+}
+
+// The counts are only added to while holding the read lock, so that a flush swapping them out sees all of them
+var indexCountsLock sync.RWMutex
+var orgIndexCounts = make(map[uint64]map[string]*indexCounts)
+
+func addIndexCounts(indexName string, bytesCount uint64, eventCount uint64, queryCount uint64, orgid uint64) {
+	indexCountsLock.RLock()
+	counts, ok := orgIndexCounts[orgid][indexName]
+	if ok {
+		atomic.AddUint64(&counts.bytesCount, bytesCount)
+		atomic.AddUint64(&counts.eventCount, eventCount)
+		atomic.AddUint64(&counts.queryCount, queryCount)
+		indexCountsLock.RUnlock()
+		return
+	}
+	indexCountsLock.RUnlock()
+
+	indexCountsLock.Lock()
+	defer indexCountsLock.Unlock()
+	counts = getOrCreateIndexCounts(indexName, orgid)
+	counts.bytesCount += bytesCount
+	counts.eventCount += eventCount
+	counts.queryCount += queryCount
+}
+
+// Caller must hold the write lock of indexCountsLock
+func getOrCreateIndexCounts(indexName string, orgid uint64) *indexCounts {
+	allCounts, ok := orgIndexCounts[orgid]
+	if !ok {
+		allCounts = make(map[string]*indexCounts)
+		orgIndexCounts[orgid] = allCounts
+	}
+	counts, ok := allCounts[indexName]
+	if !ok {
+		counts = &indexCounts{}
+		allCounts[indexName] = counts
+	}
+	return counts
+}
+
+func UpdateIndexStats(indexName string, bytesCount uint64, eventCount uint64, orgid uint64) {
+	addIndexCounts(indexName, bytesCount, eventCount, 0, orgid)
+}
+
+func UpdateIndexQueryStats(indexNames []string, orgid uint64) {
+	for _, indexName := range indexNames {
+		addIndexCounts(indexName, 0, 0, 1, orgid)
+	}
+}
+
+func flushIndexStatsToFile(orgid uint64) error {
+	indexCountsLock.Lock()
+	allCounts := orgIndexCounts[orgid]
+	delete(orgIndexCounts, orgid)
+	indexCountsLock.Unlock()
+	if len(allCounts) == 0 {
+		return nil
+	}
+
+	indexNames := make([]string, 0, len(allCounts))
+	for indexName := range allCounts {
+		indexNames = append(indexNames, indexName)
+	}
+	sort.Strings(indexNames)
+	epochAsString := strconv.FormatInt(time.Now().Unix(), 10)
+	records := make([][]string, 0, len(indexNames))
+	for _, indexName := range indexNames {
+		counts := allCounts[indexName]
+		records = append(records, []string{indexName, strconv.FormatUint(counts.bytesCount, 10),
+			strconv.FormatUint(counts.eventCount, 10), strconv.FormatUint(counts.queryCount, 10), epochAsString})
+	}
+
+	err := appendIndexStatsRecords(path.Join(getBaseStatsDir(orgid), INDEX_USAGE_STATS_FILENAME), records)
+	if err != nil {
+		// keep the counts for the next flush
+		indexCountsLock.Lock()
+		for indexName, counts := range allCounts {
+			addTo := getOrCreateIndexCounts(indexName, orgid)
+			addTo.bytesCount += counts.bytesCount
+			addTo.eventCount += counts.eventCount
+			addTo.queryCount += counts.queryCount
+		}
+		indexCountsLock.Unlock()
+		return err
+	}
+	log.Debugf("flushIndexStatsToFile: flushed stats of %v indices", len(records))
+	return nil
+}
+
+func appendIndexStatsRecords(filename string, records [][]string) error {
+	err := os.MkdirAll(path.Dir(filename), 0764)
+	if err != nil {
+		log.Errorf("appendIndexStatsRecords: mkdirall failed, filename=%v, err=%v", filename, err)
+		return err
+	}
+	fd, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		log.Errorf("appendIndexStatsRecords: open failed, filename=%v, err=%v", filename, err)
+		return err
+	}
+	defer fd.Close()
+	err = csv.NewWriter(fd).WriteAll(records)
+	if err != nil {
+		log.Errorf("appendIndexStatsRecords: write records failed, filename=%v, err=%v", filename, err)
+		return err
+	}
+	return nil
+}
+
+func getGranularityMs(granularity UsageStatsGranularity) uint64 {
+	if granularity == Hourly {
+		return MS_IN_HOUR
+	}
+	return MS_IN_DAY
+}
+
+/*
+Sums the flushed usage of each index into buckets of the granularity between startEpoch and endEpoch, both in ms.
+Every index with usage in the range gets all the buckets of the range, in order, so the ones without usage are zero
+*/
+func GetIndexUsageStats(startEpoch uint64, endEpoch uint64, granularity UsageStatsGranularity,
+	orgid uint64) (map[string][]*IndexUsage, error) {
+
+	if startEpoch > endEpoch {
+		return nil, fmt.Errorf("GetIndexUsageStats: startEpoch %v is after endEpoch %v", startEpoch, endEpoch)
+	}
+	granularityMs := getGranularityMs(granularity)
+	firstBucketEpoch := startEpoch / granularityMs * granularityMs
+	numBuckets := (endEpoch/granularityMs*granularityMs-firstBucketEpoch)/granularityMs + 1
+	if numBuckets > MAX_INDEX_USAGE_BUCKETS {
+		return nil, fmt.Errorf("GetIndexUsageStats: the time range has %v buckets, over the limit of %v",
+			numBuckets, MAX_INDEX_USAGE_BUCKETS)
+	}
+
+	indexUsage := make(map[string][]*IndexUsage)
+	addRecord := func(indexName string, usage *IndexUsage) {
+		buckets, ok := indexUsage[indexName]
+		if !ok {
+			buckets = make([]*IndexUsage, numBuckets)
+			for i := range buckets {
+				buckets[i] = &IndexUsage{StartEpoch: firstBucketEpoch + uint64(i)*granularityMs}
+			}
+			indexUsage[indexName] = buckets
+		}
+		bucket := buckets[(usage.StartEpoch-firstBucketEpoch)/granularityMs]
+		bucket.BytesCount += usage.BytesCount
+		bucket.EventCount += usage.EventCount
+		bucket.QueryCount += usage.QueryCount
+	}
+
+	startTime := time.UnixMilli(int64(startEpoch))
+	endTime := time.UnixMilli(int64(endEpoch))
+	for _, statsDir := range getBaseStatsDirs(startTime, endTime, orgid) {
+		readIndexStatsFile(path.Join(statsDir, INDEX_USAGE_STATS_FILENAME), startEpoch, endEpoch, addRecord)
+	}
+	return indexUsage, nil
+}
+
+// Calls addRecord with the usage of each row of the file flushed between startEpoch and endEpoch
+func readIndexStatsFile(filename string, startEpoch uint64, endEpoch uint64,
+	addRecord func(indexName string, usage *IndexUsage)) {
+
+	fd, err := os.Open(filename)
+	if err != nil {
+		return
+	}
+	defer fd.Close()
+
+	r := csv.NewReader(fd)
+	r.FieldsPerRecord = -1
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Errorf("readIndexStatsFile: error reading stats file=%v, err=%v", filename, err)
+			break
+		}
+		if len(record) != 5 {
+			log.Errorf("readIndexStatsFile: invalid stats entry in file=%v, entry=%v", filename, record)
+			continue
+		}
+		epochSecs, err := strconv.ParseUint(record[4], 10, 64)
+		if err != nil {
+			log.Errorf("readIndexStatsFile: invalid epoch in file=%v, entry=%v", filename, record)
+			continue
+		}
+		usage := &IndexUsage{StartEpoch: epochSecs * 1000}
+		if usage.StartEpoch < startEpoch || usage.StartEpoch > endEpoch {
+			continue
+		}
+		usage.BytesCount, _ = strconv.ParseUint(record[1], 10, 64)
+		usage.EventCount, _ = strconv.ParseUint(record[2], 10, 64)
+		usage.QueryCount, _ = strconv.ParseUint(record[3], 10, 64)
+		addRecord(record[0], usage)
+	}
+}
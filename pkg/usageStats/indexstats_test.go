@@ -0,0 +1,82 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usageStats
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/siglens/siglens/pkg/config"
+	. "github.com/siglens/siglens/pkg/segment/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func initTestIndexStats(t *testing.T) {
+	config.InitializeDefaultConfig()
+	runningConfig := config.GetRunningConfig()
+	runningConfig.DataPath = t.TempDir() + "/"
+	config.SetConfig(*runningConfig)
+	orgIndexCounts = make(map[uint64]map[string]*indexCounts)
+}
+
+func Test_FlushAndGetIndexUsageStats(t *testing.T) {
+	initTestIndexStats(t)
+
+	UpdateIndexStats("idx-a", 100, 2, 0)
+	UpdateIndexStats("idx-a", 50, 1, 0)
+	UpdateIndexStats("idx-b", 10, 1, 0)
+	UpdateIndexQueryStats([]string{"idx-a", "idx-c"}, 0)
+	// other orgs are flushed separately
+	UpdateIndexStats("idx-a", 1000, 10, 5)
+	assert.Nil(t, flushIndexStatsToFile(0))
+	assert.Len(t, orgIndexCounts[0], 0)
+	assert.Len(t, orgIndexCounts[5], 1)
+
+	statsFile := path.Join(getBaseStatsDir(0), INDEX_USAGE_STATS_FILENAME)
+	// a row an hour ago, one outside of the range and an invalid one
+	nowSecs := uint64(time.Now().Unix())
+	nowMs := nowSecs * 1000
+	extraRows := fmt.Sprintf("idx-a,7,1,0,%v\nidx-a,9,9,9,1000\nbad,row\n", nowSecs-3600)
+	fd, err := os.OpenFile(statsFile, os.O_APPEND|os.O_WRONLY, 0644)
+	assert.Nil(t, err)
+	_, err = fd.WriteString(extraRows)
+	assert.Nil(t, err)
+	assert.Nil(t, fd.Close())
+
+	usage, err := GetIndexUsageStats(nowMs-2*MS_IN_HOUR, nowMs, Hourly, 0)
+	assert.Nil(t, err)
+	assert.Len(t, usage, 3)
+	assert.Len(t, usage["idx-a"], 3)
+	firstBucket := (nowMs - 2*MS_IN_HOUR) / MS_IN_HOUR * MS_IN_HOUR
+	for i, bucket := range usage["idx-a"] {
+		assert.Equal(t, firstBucket+uint64(i)*MS_IN_HOUR, bucket.StartEpoch)
+	}
+	assert.Equal(t, IndexUsage{StartEpoch: firstBucket + 2*MS_IN_HOUR, BytesCount: 150, EventCount: 3, QueryCount: 1},
+		*usage["idx-a"][2])
+	assert.Equal(t, IndexUsage{StartEpoch: firstBucket + MS_IN_HOUR, BytesCount: 7, EventCount: 1},
+		*usage["idx-a"][1])
+	assert.Equal(t, uint64(10), usage["idx-b"][2].BytesCount)
+	assert.Equal(t, uint64(1), usage["idx-c"][2].QueryCount)
+
+	_, err = GetIndexUsageStats(nowMs, nowMs-1, Hourly, 0)
+	assert.NotNil(t, err)
+	_, err = GetIndexUsageStats(0, nowMs, Hourly, 0)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usageStats
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/siglens/siglens/pkg/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// The stored bytes are recomputed at most this often, as it walks the metadata of every index
+const STORED_BYTES_REFRESH_INTERVAL = time.Minute
+
+type QuotaUsage struct {
+	OrgId              uint64             `json:"orgId"`
+	StoredBytes        uint64             `json:"storedBytes"`
+	DailyIngestedBytes uint64             `json:"dailyIngestedBytes"`
+	RunningQueries     uint64             `json:"runningQueries"`
+	Limits             config.QuotaLimits `json:"limits"`
+}
+
+type orgQuotaState struct {
+	day                string // UTC day of dailyIngestedBytes
+	dailyIngestedBytes uint64
+	runningQueries     uint64
+	storedBytes        uint64
+	storedBytesTime    time.Time
+}
+
+var quotaLock sync.Mutex
+var quotaStates = make(map[uint64]*orgQuotaState)
+
+// Returns the bytes of the logs and metrics the org has on disk, set by SetStoredBytesFunc
+var storedBytesFn func(orgid uint64) uint64
+
+func SetStoredBytesFunc(fn func(orgid uint64) uint64) {
+	quotaLock.Lock()
+	defer quotaLock.Unlock()
+	storedBytesFn = fn
+	for _, state := range quotaStates {
+		state.storedBytesTime = time.Time{}
+	}
+}
+
+func getUTCDay(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
+
+// Caller must hold quotaLock
+func getOrgQuotaState(orgid uint64) *orgQuotaState {
+	today := getUTCDay(time.Now())
+	state, ok := quotaStates[orgid]
+	if !ok {
+		// the ingest of the day before a restart was flushed to the stats file of the day
+		state = &orgQuotaState{day: today, dailyIngestedBytes: readFlushedIngestedBytes(orgid)}
+		quotaStates[orgid] = state
+	}
+	if state.day != today {
+		state.day = today
+		state.dailyIngestedBytes = 0
+	}
+	return state
+}
+
+// Sums the bytes ingested by this node in the current UTC day, as flushed to its stats file
+func readFlushedIngestedBytes(orgid uint64) uint64 {
+	filename := getStatsFilename(getBaseStatsDir(orgid))
+	fd, err := os.Open(filename)
+	if err != nil {
+		return 0
+	}
+	defer fd.Close()
+
+	totalBytes := uint64(0)
+	r := csv.NewReader(fd)
+	r.FieldsPerRecord = -1
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Errorf("readFlushedIngestedBytes: error reading stats file=%v, err=%v", filename, err)
+			break
+		}
+		bytesCount, err := strconv.ParseUint(record[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		totalBytes += bytesCount
+	}
+	return totalBytes
+}
+
+func addDailyIngestedBytes(bytesCount uint64, orgid uint64) {
+	quotaLock.Lock()
+	defer quotaLock.Unlock()
+	getOrgQuotaState(orgid).dailyIngestedBytes += bytesCount
+}
+
+// Caller must hold quotaLock
+func (state *orgQuotaState) getStoredBytes(orgid uint64) uint64 {
+	if storedBytesFn == nil {
+		return 0
+	}
+	if time.Since(state.storedBytesTime) >= STORED_BYTES_REFRESH_INTERVAL {
+		state.storedBytes = storedBytesFn(orgid)
+		state.storedBytesTime = time.Now()
+	}
+	return state.storedBytes
+}
+
+/*
+Returns an error if the org is over its stored bytes or daily ingest quota, along with the time until the quota is
+reset, which is 0 for the stored bytes as they only free up once data is deleted
+*/
+func CheckIngestQuota(orgid uint64) (time.Duration, error) {
+	limits := config.GetQuotaLimits(orgid)
+	if limits.MaxStoredBytes == 0 && limits.MaxDailyIngestBytes == 0 {
+		return 0, nil
+	}
+
+	quotaLock.Lock()
+	defer quotaLock.Unlock()
+	state := getOrgQuotaState(orgid)
+	if limits.MaxDailyIngestBytes > 0 && state.dailyIngestedBytes >= limits.MaxDailyIngestBytes {
+		return getTimeUntilNextUTCDay(time.Now()), fmt.Errorf("daily ingest quota of %v bytes is used up", limits.MaxDailyIngestBytes)
+	}
+	if limits.MaxStoredBytes > 0 && state.getStoredBytes(orgid) >= limits.MaxStoredBytes {
+		return 0, fmt.Errorf("stored bytes quota of %v bytes is used up", limits.MaxStoredBytes)
+	}
+	return 0, nil
+}
+
+func getTimeUntilNextUTCDay(now time.Time) time.Duration {
+	return now.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour).Sub(now)
+}
+
+/*
+Takes one of the concurrent query slots of the org. Returns an error if they are all taken, otherwise the caller
+must call ReleaseQuerySlot once the query is done
+*/
+func AcquireQuerySlot(orgid uint64) error {
+	limits := config.GetQuotaLimits(orgid)
+
+	quotaLock.Lock()
+	defer quotaLock.Unlock()
+	state := getOrgQuotaState(orgid)
+	if limits.MaxConcurrentQueries > 0 && state.runningQueries >= limits.MaxConcurrentQueries {
+		return fmt.Errorf("concurrent queries quota of %v queries is used up", limits.MaxConcurrentQueries)
+	}
+	state.runningQueries++
+	return nil
+}
+
+func ReleaseQuerySlot(orgid uint64) {
+	quotaLock.Lock()
+	defer quotaLock.Unlock()
+	state := getOrgQuotaState(orgid)
+	if state.runningQueries == 0 {
+		log.Errorf("ReleaseQuerySlot: no running queries for orgid=%v", orgid)
+		return
+	}
+	state.runningQueries--
+}
+
+// Returns an error if a query that searches scannedBytes of segments is over the quota of the org
+func CheckScannedBytesQuota(scannedBytes uint64, orgid uint64) error {
+	limits := config.GetQuotaLimits(orgid)
+	if limits.MaxScannedBytesPerQuery > 0 && scannedBytes > limits.MaxScannedBytesPerQuery {
+		return fmt.Errorf("query would scan %v bytes, over the quota of %v bytes per query", scannedBytes,
+			limits.MaxScannedBytesPerQuery)
+	}
+	return nil
+}
+
+func GetQuotaUsage(orgid uint64) QuotaUsage {
+	quotaLock.Lock()
+	defer quotaLock.Unlock()
+	state := getOrgQuotaState(orgid)
+	return QuotaUsage{
+		OrgId:              orgid,
+		StoredBytes:        state.getStoredBytes(orgid),
+		DailyIngestedBytes: state.dailyIngestedBytes,
+		RunningQueries:     state.runningQueries,
+		Limits:             config.GetQuotaLimits(orgid),
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usageStats
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/siglens/siglens/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func initTestQuotas(t *testing.T, quotasConfig config.QuotasConfig) {
+	config.InitializeDefaultConfig()
+	runningConfig := config.GetRunningConfig()
+	runningConfig.DataPath = t.TempDir() + "/"
+	runningConfig.Quotas = quotasConfig
+	config.SetConfig(*runningConfig)
+	quotaStates = make(map[uint64]*orgQuotaState)
+	SetStoredBytesFunc(nil)
+}
+
+func Test_GetQuotaLimits(t *testing.T) {
+	initTestQuotas(t, config.QuotasConfig{
+		Enabled:     true,
+		QuotaLimits: config.QuotaLimits{MaxConcurrentQueries: 5},
+		Orgs:        []config.OrgQuotaConfig{{OrgId: 7, QuotaLimits: config.QuotaLimits{MaxConcurrentQueries: 1}}},
+	})
+	assert.Equal(t, uint64(5), config.GetQuotaLimits(0).MaxConcurrentQueries)
+	assert.Equal(t, uint64(1), config.GetQuotaLimits(7).MaxConcurrentQueries)
+
+	initTestQuotas(t, config.QuotasConfig{QuotaLimits: config.QuotaLimits{MaxConcurrentQueries: 5}})
+	assert.Equal(t, config.QuotaLimits{}, config.GetQuotaLimits(0))
+}
+
+func Test_IngestQuota(t *testing.T) {
+	initTestQuotas(t, config.QuotasConfig{
+		Enabled:     true,
+		QuotaLimits: config.QuotaLimits{MaxDailyIngestBytes: 100, MaxStoredBytes: 1000},
+	})
+
+	// the bytes flushed before a restart count towards the daily quota
+	statsFile := getStatsFilename(getBaseStatsDir(3))
+	assert.Nil(t, os.WriteFile(statsFile, []byte("40,2,0,1700000000\n30,1,1700000060\n"), 0644))
+	addDailyIngestedBytes(20, 3)
+	assert.Equal(t, uint64(90), GetQuotaUsage(3).DailyIngestedBytes)
+	_, err := CheckIngestQuota(3)
+	assert.Nil(t, err)
+
+	addDailyIngestedBytes(10, 3)
+	retryAfter, err := CheckIngestQuota(3)
+	assert.NotNil(t, err)
+	assert.True(t, retryAfter > 0 && retryAfter <= 24*time.Hour)
+
+	// the daily quota is reset on the next UTC day
+	quotaStates[3].day = "2000-01-01"
+	_, err = CheckIngestQuota(3)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), GetQuotaUsage(3).DailyIngestedBytes)
+
+	storedBytes := uint64(999)
+	SetStoredBytesFunc(func(orgid uint64) uint64 { return storedBytes })
+	_, err = CheckIngestQuota(3)
+	assert.Nil(t, err)
+	storedBytes = 1000
+	// the stored bytes are cached
+	_, err = CheckIngestQuota(3)
+	assert.Nil(t, err)
+	quotaStates[3].storedBytesTime = time.Time{}
+	retryAfter, err = CheckIngestQuota(3)
+	assert.NotNil(t, err)
+	assert.Equal(t, time.Duration(0), retryAfter)
+	assert.Equal(t, uint64(1000), GetQuotaUsage(3).StoredBytes)
+}
+
+func Test_QueryQuotas(t *testing.T) {
+	initTestQuotas(t, config.QuotasConfig{
+		Enabled:     true,
+		QuotaLimits: config.QuotaLimits{MaxConcurrentQueries: 2, MaxScannedBytesPerQuery: 500},
+	})
+
+	assert.Nil(t, AcquireQuerySlot(0))
+	assert.Nil(t, AcquireQuerySlot(0))
+	assert.NotNil(t, AcquireQuerySlot(0))
+	assert.Equal(t, uint64(2), GetQuotaUsage(0).RunningQueries)
+	// the slots are per org
+	assert.Nil(t, AcquireQuerySlot(1))
+
+	ReleaseQuerySlot(0)
+	assert.Nil(t, AcquireQuerySlot(0))
+
+	assert.Nil(t, CheckScannedBytesQuota(500, 0))
+	assert.NotNil(t, CheckScannedBytesQuota(501, 0))
+}
+
+func Test_getTimeUntilNextUTCDay(t *testing.T) {
+	now := time.Date(2024, 3, 10, 22, 30, 0, 0, time.UTC)
+	assert.Equal(t, 90*time.Minute, getTimeUntilNextUTCDay(now))
+	assert.Equal(t, 90*time.Minute, getTimeUntilNextUTCDay(now.In(time.FixedZone("east", 5*3600))))
+}
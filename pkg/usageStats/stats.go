@@ -238,6 +238,10 @@ func writeUsageStats() {
 			if errC != nil {
 				log.Errorf("WriteUsageStats failed:%v\n", errC)
 			}
+			errI := flushIndexStatsToFile(0)
+			if errI != nil {
+				log.Errorf("WriteUsageStats failed:%v\n", errI)
+			}
 
 		}()
 		time.Sleep(1 * time.Minute)
@@ -249,6 +253,10 @@ func ForceFlushStatstoFile() {
 	if err != nil {
 		log.Errorf("ForceFlushStatstoFile failed:%v\n", err)
 	}
+	err = flushIndexStatsToFile(0)
+	if err != nil {
+		log.Errorf("ForceFlushStatstoFile failed:%v\n", err)
+	}
 }
 
 func logStatSummary(orgid uint64) {
@@ -340,6 +348,7 @@ func UpdateStats(bytesCount uint64, logLinesCount uint64, orgid uint64) {
 	atomic.AddUint64(&ustats[orgid].LogLinesCount, logLinesCount)
 	atomic.AddUint64(&ustats[orgid].TotalBytesCount, bytesCount)
 	atomic.AddUint64(&ustats[orgid].TotalLogLinesCount, logLinesCount)
+	addDailyIngestedBytes(bytesCount, orgid)
 }
 
 func UpdateMetricsStats(bytesCount uint64, incomingMetrics uint64, orgid uint64) {
@@ -355,6 +364,7 @@ func UpdateMetricsStats(bytesCount uint64, incomingMetrics uint64, orgid uint64)
 	atomic.AddUint64(&ustats[orgid].MetricsDatapointsCount, incomingMetrics)
 	atomic.AddUint64(&ustats[orgid].TotalBytesCount, bytesCount)
 	atomic.AddUint64(&ustats[orgid].TotalMetricsDatapointsCount, incomingMetrics)
+	addDailyIngestedBytes(bytesCount, orgid)
 }
 
 func GetQueryStats(orgid uint64) (uint64, float64, uint64) {
@@ -0,0 +1,101 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import "sync"
+
+// Size of the chunks the interned strings are copied into
+const INTERNER_CHUNK_SIZE = 64 * 1024
+
+// Strings longer than this get their own allocation rather than a part of a chunk
+const INTERNER_MAX_CHUNKED_LEN = INTERNER_CHUNK_SIZE / 8
+
+/*
+Keeps a single copy of each distinct string it is given, so that the maps keyed by the returned strings share them.
+The copies are carved out of large chunks, the arena, which is freed with the interner once nothing references it
+*/
+type StringInterner struct {
+	lock  sync.RWMutex
+	strs  map[string]string
+	chunk []byte
+}
+
+func NewStringInterner() *StringInterner {
+	return &StringInterner{
+		strs: make(map[string]string),
+	}
+}
+
+// Returns the copy of the bytes as a string, without allocating if they were already interned
+func (si *StringInterner) InternBytes(b []byte) string {
+	si.lock.RLock()
+	str, ok := si.strs[string(b)]
+	si.lock.RUnlock()
+	if ok {
+		return str
+	}
+
+	si.lock.Lock()
+	defer si.lock.Unlock()
+	if str, ok := si.strs[string(b)]; ok {
+		return str
+	}
+	str = si.copyToArena(b)
+	si.strs[str] = str
+	return str
+}
+
+func (si *StringInterner) Intern(s string) string {
+	si.lock.RLock()
+	str, ok := si.strs[s]
+	si.lock.RUnlock()
+	if ok {
+		return str
+	}
+
+	si.lock.Lock()
+	defer si.lock.Unlock()
+	if str, ok := si.strs[s]; ok {
+		return str
+	}
+	str = si.copyToArena([]byte(s))
+	si.strs[str] = str
+	return str
+}
+
+// Returns the number of distinct strings interned
+func (si *StringInterner) Len() int {
+	si.lock.RLock()
+	defer si.lock.RUnlock()
+	return len(si.strs)
+}
+
+// the bytes of a chunk are never written again once a string is carved out of them
+func (si *StringInterner) copyToArena(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	if len(b) > INTERNER_MAX_CHUNKED_LEN {
+		return string(b)
+	}
+	if cap(si.chunk)-len(si.chunk) < len(b) {
+		si.chunk = make([]byte, 0, INTERNER_CHUNK_SIZE)
+	}
+	start := len(si.chunk)
+	si.chunk = append(si.chunk, b...)
+	return UnsafeByteSliceToString(si.chunk[start:len(si.chunk):len(si.chunk)])
+}
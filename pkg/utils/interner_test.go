@@ -0,0 +1,86 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func stringDataPtr(s string) uintptr {
+	return *(*uintptr)(unsafe.Pointer(&s))
+}
+
+func Test_StringInterner(t *testing.T) {
+	interner := NewStringInterner()
+
+	buf := []byte("host-1")
+	first := interner.InternBytes(buf)
+	assert.Equal(t, "host-1", first)
+
+	// the interned copy doesn't change with the buffer it came from
+	copy(buf, "host-2")
+	assert.Equal(t, "host-1", first)
+	second := interner.InternBytes(buf)
+	assert.Equal(t, "host-2", second)
+
+	again := interner.Intern(strings.Repeat("host-1", 1))
+	assert.Equal(t, stringDataPtr(first), stringDataPtr(again))
+	again = interner.InternBytes([]byte("host-2"))
+	assert.Equal(t, stringDataPtr(second), stringDataPtr(again))
+	assert.Equal(t, 2, interner.Len())
+
+	assert.Equal(t, "", interner.InternBytes([]byte{}))
+	long := strings.Repeat("x", INTERNER_MAX_CHUNKED_LEN+1)
+	assert.Equal(t, long, interner.Intern(long))
+	assert.Equal(t, 4, interner.Len())
+}
+
+func Test_StringInternerChunks(t *testing.T) {
+	interner := NewStringInterner()
+	numStrs := 3 * INTERNER_CHUNK_SIZE / 10
+	for i := 0; i < numStrs; i++ {
+		interner.InternBytes([]byte(fmt.Sprintf("val%07d", i)))
+	}
+	assert.Equal(t, numStrs, interner.Len())
+	for i := 0; i < numStrs; i += 997 {
+		str := fmt.Sprintf("val%07d", i)
+		assert.Equal(t, str, interner.Intern(str))
+	}
+	assert.Equal(t, numStrs, interner.Len())
+}
+
+func Test_StringInternerConcurrent(t *testing.T) {
+	interner := NewStringInterner()
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				interner.InternBytes([]byte(fmt.Sprintf("val%v", i%100)))
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 100, interner.Len())
+}
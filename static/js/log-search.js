@@ -149,6 +149,9 @@ function displayQueryLangToolTip(selectedQueryLangID) {
             $('#info-icon-spl').show();
             $("#filter-input").attr("placeholder", "Enter your SPL query here, or click the 'i' icon for examples");
             break;
+        case "4":
+            $("#filter-input").attr("placeholder", "Enter your PPL query here, starting with source=<index>");
+            break;
     }
 }
 
@@ -369,6 +369,8 @@ function getColumns() {
        $("#option-2").addClass("active");
      } else if (queryLanguage == "Splunk QL") {
        $("#option-3").addClass("active");
+     } else if (queryLanguage == "PPL") {
+       $("#option-4").addClass("active");
      }
      let filterTab = queryParams.get("filterTab");
      let filterValue = queryParams.get('searchText');
@@ -735,20 +737,28 @@ function getColumns() {
              totalHits = res.hits.totalMatched
          }
      } else if (res.measure && (res.qtype === "aggs-query" || res.qtype === "segstats-query")) {
+         let columnOrder =[]
          if (res.groupByCols ) {
              columnOrder = _.uniq(_.concat(
                  res.groupByCols));
          }
-         let columnOrder =[]
          if (res.measureFunctions ) {
+             measureFunctions = res.measureFunctions;
              columnOrder = _.uniq(_.concat(
                  columnOrder,res.measureFunctions));
          }
+         measureInfo = res.measure;
  
+         $("#logs-result-container").hide();
+         $("#custom-chart-tab").show();
+         $("#agg-result-container").show();
          aggsColumnDefs=[];
          segStatsRowData=[]; 
          renderMeasuresGrid(columnOrder, res.measure);
- 
+         // the partial results of a long search fill in the chart until it completes
+         if ($("#custom-chart-tab").tabs("option", "active") == 1) {
+             timeChart();
+         }
      }
      let totalTime = (new Date()).getTime() - startQueryTime;
      let percentComplete = res.percent_complete;